@@ -10,6 +10,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"flag"
 	"log/slog"
@@ -52,8 +53,10 @@ type AppDeps struct {
 	InitServices       func(setup.ServiceConfig) (*setup.Services, *setup.Workers, error)
 	InitHandlers       func(*setup.Services, *platform.Config, *slog.Logger) *setup.Handlers
 	SetupRouter        func(*platform.Config, *slog.Logger, *setup.Handlers, *setup.Services, ports.NetworkBackend) *gin.Engine
+	SetupGatewayRouter func(*platform.Config, *slog.Logger, *setup.Handlers) *gin.Engine
 	NewHTTPServer      func(string, http.Handler) *http.Server
 	StartHTTPServer    func(*http.Server) error
+	StartHTTPSServer   func(*http.Server) error
 	ShutdownHTTPServer func(context.Context, *http.Server) error
 	NotifySignals      func(chan<- os.Signal, ...os.Signal)
 }
@@ -73,6 +76,7 @@ func DefaultDeps() AppDeps {
 		InitServices:       setup.InitServices,
 		InitHandlers:       setup.InitHandlers,
 		SetupRouter:        setup.SetupRouter,
+		SetupGatewayRouter: setup.SetupGatewayRouter,
 		NewHTTPServer: func(addr string, handler http.Handler) *http.Server {
 			return &http.Server{
 				Addr:              addr,
@@ -83,6 +87,10 @@ func DefaultDeps() AppDeps {
 		StartHTTPServer: func(s *http.Server) error {
 			return s.ListenAndServe()
 		},
+		StartHTTPSServer: func(s *http.Server) error {
+			// Certificates come from s.TLSConfig.GetCertificate.
+			return s.ListenAndServeTLS("", "")
+		},
 		ShutdownHTTPServer: func(ctx context.Context, s *http.Server) error {
 			return s.Shutdown(ctx)
 		},
@@ -137,13 +145,15 @@ func main() {
 
 	handlers := deps.InitHandlers(svcs, cfg, logger)
 	r := deps.SetupRouter(cfg, logger, handlers, svcs, network)
+	gw := deps.SetupGatewayRouter(cfg, logger, handlers)
 
 	// Add Tracing Middleware if enabled
 	if os.Getenv("TRACING_ENABLED") == "true" {
 		r.Use(otelgin.Middleware("thecloud-api"))
+		gw.Use(otelgin.Middleware("thecloud-api"))
 	}
 
-	runApplication(deps, cfg, logger, r, workers)
+	runApplication(deps, cfg, logger, r, gw, workers, svcs.Gateway.GetCertificate)
 }
 
 func initTracing(logger *slog.Logger) *sdktrace.TracerProvider {
@@ -241,7 +251,10 @@ func initBackends(deps AppDeps, cfg *platform.Config, logger *slog.Logger, db po
 	return compute, storage, network, lbProxy, nil
 }
 
-func runApplication(deps AppDeps, cfg *platform.Config, logger *slog.Logger, r *gin.Engine, workers *setup.Workers) {
+// runApplication serves the API, and when GatewayTLSPort is set an HTTPS
+// listener that serves only the gateway router gw, with certificates
+// selected by getCertificate, until a termination signal arrives.
+func runApplication(deps AppDeps, cfg *platform.Config, logger *slog.Logger, r, gw http.Handler, workers *setup.Workers, getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)) {
 	role := os.Getenv("ROLE")
 	if role == "" {
 		role = "all"
@@ -255,6 +268,7 @@ func runApplication(deps AppDeps, cfg *platform.Config, logger *slog.Logger, r *
 	}
 
 	srv := deps.NewHTTPServer(":"+cfg.Port, r)
+	var tlsSrv *http.Server
 
 	if role == "api" || role == "all" {
 		go func() {
@@ -264,6 +278,18 @@ func runApplication(deps AppDeps, cfg *platform.Config, logger *slog.Logger, r *
 				os.Exit(1)
 			}
 		}()
+
+		if cfg.GatewayTLSPort != "" && getCertificate != nil {
+			tlsSrv = deps.NewHTTPServer(":"+cfg.GatewayTLSPort, gw)
+			tlsSrv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, GetCertificate: getCertificate}
+			go func() {
+				logger.Info("starting gateway https listener", "port", cfg.GatewayTLSPort)
+				if err := deps.StartHTTPSServer(tlsSrv); err != nil && err != http.ErrServerClosed {
+					logger.Error("failed to start https server", "error", err)
+					os.Exit(1)
+				}
+			}()
+		}
 	} else {
 		logger.Info("running in worker-only mode")
 	}
@@ -280,6 +306,11 @@ func runApplication(deps AppDeps, cfg *platform.Config, logger *slog.Logger, r *
 	if err := deps.ShutdownHTTPServer(ctx, srv); err != nil {
 		logger.Error("server forced to shutdown", "error", err)
 	}
+	if tlsSrv != nil {
+		if err := deps.ShutdownHTTPServer(ctx, tlsSrv); err != nil {
+			logger.Error("https server forced to shutdown", "error", err)
+		}
+	}
 
 	workerCancel()
 	wg.Wait()
@@ -306,9 +337,20 @@ func runWorkers(ctx context.Context, wg *sync.WaitGroup, workers *setup.Workers)
 	startWorker(ctx, wg, workers.Accounting)
 	startWorker(ctx, wg, workers.Cluster)
 	startWorker(ctx, wg, workers.Lifecycle)
+	startWorker(ctx, wg, workers.StorageEvents)
+	startWorker(ctx, wg, workers.Replication)
+	startWorker(ctx, wg, workers.Reencryption)
+	startWorker(ctx, wg, workers.ErasureRepair)
 	startWorker(ctx, wg, workers.ReplicaMonitor)
 	startWorker(ctx, wg, workers.ClusterReconciler)
 	startWorker(ctx, wg, workers.Healing)
 	startWorker(ctx, wg, workers.DatabaseFailover)
 	startWorker(ctx, wg, workers.Log)
+	startWorker(ctx, wg, workers.FlowLog)
+	startWorker(ctx, wg, workers.GlobalLBHealth)
+	startWorker(ctx, wg, workers.GatewayAccessLog)
+	startWorker(ctx, wg, workers.CertificateExpiry)
+	if workers.DNSServer != nil {
+		startWorker(ctx, wg, workers.DNSServer)
+	}
 }
@@ -2,11 +2,13 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
@@ -158,7 +160,7 @@ func TestRunApplicationApiRoleStartsAndShutsDown(t *testing.T) {
 		}()
 	}
 
-	runApplication(deps, &platform.Config{Port: "0"}, logger, gin.New(), &setup.Workers{})
+	runApplication(deps, &platform.Config{Port: "0"}, logger, gin.New(), gin.New(), &setup.Workers{}, nil)
 
 	select {
 	case <-shutdownCalled:
@@ -167,6 +169,49 @@ func TestRunApplicationApiRoleStartsAndShutsDown(t *testing.T) {
 	}
 }
 
+func TestRunApplicationStartsGatewayHTTPSListener(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	t.Setenv("ROLE", "api")
+
+	gw := gin.New()
+	started := make(chan *http.Server, 1)
+	var mu sync.Mutex
+	var shutdown []string
+	deps := DefaultDeps()
+	deps.StartHTTPServer = func(*http.Server) error { return http.ErrServerClosed }
+	deps.StartHTTPSServer = func(s *http.Server) error {
+		started <- s
+		return http.ErrServerClosed
+	}
+	deps.ShutdownHTTPServer = func(_ context.Context, s *http.Server) error {
+		mu.Lock()
+		shutdown = append(shutdown, s.Addr)
+		mu.Unlock()
+		return nil
+	}
+	deps.NotifySignals = func(c chan<- os.Signal, _ ...os.Signal) {
+		go func() {
+			srv := <-started
+			if srv.Addr != ":8443" || srv.TLSConfig == nil || srv.TLSConfig.GetCertificate == nil {
+				t.Errorf("unexpected https server: addr=%s tls=%v", srv.Addr, srv.TLSConfig)
+			}
+			if srv.Handler != gw {
+				t.Errorf("expected the https server to serve only the gateway router")
+			}
+			c <- syscall.SIGTERM
+		}()
+	}
+
+	getCertificate := func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return nil, nil }
+	runApplication(deps, &platform.Config{Port: "0", GatewayTLSPort: "8443"}, logger, gin.New(), gw, &setup.Workers{}, getCertificate)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(shutdown) != 2 || shutdown[1] != ":8443" {
+		t.Fatalf("expected both servers to shut down, got %v", shutdown)
+	}
+}
+
 // Stub helpers below keep main.go testable without altering production behavior.
 
 type stubDB struct{ closed bool }
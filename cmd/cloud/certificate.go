@@ -0,0 +1,173 @@
+// Package main provides the cloud CLI entrypoint.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/poyrazk/thecloud/pkg/sdk"
+	"github.com/spf13/cobra"
+)
+
+const certErrorFormat = "Error: %v\n"
+
+var certCmd = &cobra.Command{
+	Use:   "cert",
+	Short: "Manage TLS certificates for load balancers and the API gateway",
+}
+
+var certCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Import a PEM certificate or issue one from the internal CA",
+	Long: `Import a certificate with --cert-file and --key-file, or issue one for
+--domain (repeatable) from the platform's internal CA. Clients trust issued
+certificates by installing the CA certificate from "cloud cert ca".`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		certFile, _ := cmd.Flags().GetString("cert-file")
+		keyFile, _ := cmd.Flags().GetString("key-file")
+		domains, _ := cmd.Flags().GetStringSlice("domain")
+		days, _ := cmd.Flags().GetInt("days")
+		topic, _ := cmd.Flags().GetString("notify-topic")
+
+		input := sdk.CreateCertificateInput{Name: args[0], Domains: domains, ValidityDays: days, NotifyTopicID: topic}
+		if certFile != "" || keyFile != "" {
+			if certFile == "" || keyFile == "" {
+				fmt.Printf(certErrorFormat, "--cert-file and --key-file must be used together")
+				return
+			}
+			certPEM, err := os.ReadFile(filepath.Clean(certFile))
+			if err != nil {
+				fmt.Printf(certErrorFormat, err)
+				return
+			}
+			keyPEM, err := os.ReadFile(filepath.Clean(keyFile))
+			if err != nil {
+				fmt.Printf(certErrorFormat, err)
+				return
+			}
+			input.CertificatePEM, input.PrivateKeyPEM = string(certPEM), string(keyPEM)
+		}
+
+		client := getClient()
+		cert, err := client.CreateCertificate(input)
+		if err != nil {
+			fmt.Printf(certErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Certificate %s %s (ID: %s)\n", cert.Name, cert.Source, cert.ID)
+		fmt.Printf("Domains: %s, expires %s\n", strings.Join(cert.Domains, ", "), cert.NotAfter.Format("2006-01-02"))
+	},
+}
+
+var certListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List certificates",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		certs, err := client.ListCertificates()
+		if err != nil {
+			fmt.Printf(certErrorFormat, err)
+			return
+		}
+
+		if outputJSON {
+			data, _ := json.MarshalIndent(certs, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"ID", "NAME", "DOMAINS", "SOURCE", "STATUS", "EXPIRES"})
+		for _, c := range certs {
+			id := c.ID
+			if len(id) > 8 {
+				id = id[:8]
+			}
+			_ = table.Append([]string{
+				id,
+				c.Name,
+				strings.Join(c.Domains, ","),
+				c.Source,
+				c.Status,
+				c.NotAfter.Format("2006-01-02"),
+			})
+		}
+		_ = table.Render()
+	},
+}
+
+var certGetCmd = &cobra.Command{
+	Use:   "get [id]",
+	Short: "Show a certificate",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		cert, err := client.GetCertificate(args[0])
+		if err != nil {
+			fmt.Printf(certErrorFormat, err)
+			return
+		}
+
+		if outputJSON {
+			data, _ := json.MarshalIndent(cert, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		fmt.Printf("ID:       %s\n", cert.ID)
+		fmt.Printf("Name:     %s\n", cert.Name)
+		fmt.Printf("Domains:  %s\n", strings.Join(cert.Domains, ", "))
+		fmt.Printf("Source:   %s\n", cert.Source)
+		fmt.Printf("Status:   %s\n", cert.Status)
+		fmt.Printf("Issuer:   %s\n", cert.Issuer)
+		fmt.Printf("Valid:    %s to %s\n", cert.NotBefore.Format("2006-01-02"), cert.NotAfter.Format("2006-01-02"))
+	},
+}
+
+var certRmCmd = &cobra.Command{
+	Use:   "rm [id]",
+	Short: "Delete a certificate that no listener uses",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.DeleteCertificate(args[0]); err != nil {
+			fmt.Printf(certErrorFormat, err)
+			return
+		}
+		fmt.Printf("[SUCCESS] Certificate %s deleted.\n", args[0])
+	},
+}
+
+var certCACmd = &cobra.Command{
+	Use:   "ca",
+	Short: "Print the internal CA certificate",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		pem, err := client.GetCertificateAuthority()
+		if err != nil {
+			fmt.Printf(certErrorFormat, err)
+			return
+		}
+		fmt.Print(pem)
+	},
+}
+
+func init() {
+	certCreateCmd.Flags().String("cert-file", "", "PEM certificate (and chain) to import")
+	certCreateCmd.Flags().String("key-file", "", "PEM private key of the imported certificate")
+	certCreateCmd.Flags().StringSlice("domain", nil, "Domain to issue the certificate for (repeatable, wildcards allowed)")
+	certCreateCmd.Flags().Int("days", 0, "Validity of an issued certificate in days (default 90)")
+	certCreateCmd.Flags().String("notify-topic", "", "CloudNotify topic ID for expiry alerts")
+
+	certCmd.AddCommand(certCreateCmd)
+	certCmd.AddCommand(certListCmd)
+	certCmd.AddCommand(certGetCmd)
+	certCmd.AddCommand(certRmCmd)
+	certCmd.AddCommand(certCACmd)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const certTestAPIKey = "cert-key"
+
+func setCertTestServer(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	oldURL, oldKey := apiURL, apiKey
+	apiURL, apiKey = server.URL, certTestAPIKey
+	t.Cleanup(func() {
+		server.Close()
+		apiURL, apiKey = oldURL, oldKey
+	})
+}
+
+func TestCertCreateImportsPEMFiles(t *testing.T) {
+	var body map[string]interface{}
+	setCertTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/certificates" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": "cert-1", "name": "web", "source": "imported", "domains": []string{"app.example.com"}},
+		})
+	})
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, []byte("CERT"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, []byte("KEY"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_ = certCreateCmd.Flags().Set("cert-file", certFile)
+	_ = certCreateCmd.Flags().Set("key-file", keyFile)
+	defer func() {
+		_ = certCreateCmd.Flags().Set("cert-file", "")
+		_ = certCreateCmd.Flags().Set("key-file", "")
+	}()
+
+	out := captureStdout(t, func() {
+		certCreateCmd.Run(certCreateCmd, []string{"web"})
+	})
+	if !strings.Contains(out, "[SUCCESS] Certificate web imported") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	if body["certificate_pem"] != "CERT" || body["private_key_pem"] != "KEY" || body["name"] != "web" {
+		t.Fatalf("unexpected request body: %v", body)
+	}
+}
+
+func TestCertListAndCA(t *testing.T) {
+	setCertTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/certificates":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]interface{}{{"id": "cert-123456789", "name": "web", "domains": []string{"app.example.com"}, "status": "EXPIRING"}},
+			})
+		case "/certificates/ca":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"certificate_pem": "-----BEGIN CERTIFICATE-----\n"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	out := captureStdout(t, func() {
+		certListCmd.Run(certListCmd, nil)
+	})
+	if !strings.Contains(out, "app.example.com") || !strings.Contains(out, "EXPIRING") {
+		t.Fatalf("unexpected list output: %s", out)
+	}
+
+	out = captureStdout(t, func() {
+		certCACmd.Run(certCACmd, nil)
+	})
+	if out != "-----BEGIN CERTIFICATE-----\n" {
+		t.Fatalf("unexpected CA output: %q", out)
+	}
+}
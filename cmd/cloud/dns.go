@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 
@@ -196,6 +197,63 @@ var dnsDeleteRecordCmd = &cobra.Command{
 	},
 }
 
+var dnsImportCmd = &cobra.Command{
+	Use:   "import [zone-id] [file]",
+	Short: "Import records from a BIND zone file",
+	Long: `Import records from a BIND (RFC 1035) zone file. Each name and type in the
+file replaces the zone's existing records of that name and type; SOA and apex
+NS records are ignored. The import is atomic: if any record is rejected,
+nothing is changed. Use "-" to read the zone file from stdin.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var data []byte
+		var err error
+		if args[1] == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(args[1])
+		}
+		if err != nil {
+			fmt.Printf("Error: failed to read zone file: %v\n", err)
+			return
+		}
+
+		client := getClient()
+		records, err := client.ImportDNSZone(args[0], string(data))
+		if err != nil {
+			fmt.Printf(dnsErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Imported %d DNS records.\n", len(records))
+	},
+}
+
+var dnsExportCmd = &cobra.Command{
+	Use:   "export [zone-id]",
+	Short: "Export a DNS zone as a BIND zone file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		zoneFile, err := client.ExportDNSZone(args[0])
+		if err != nil {
+			fmt.Printf(dnsErrorFormat, err)
+			return
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			fmt.Print(zoneFile)
+			return
+		}
+		if err := os.WriteFile(output, []byte(zoneFile), 0o644); err != nil {
+			fmt.Printf("Error: failed to write zone file: %v\n", err)
+			return
+		}
+		fmt.Printf("[SUCCESS] Zone exported to %s\n", output)
+	},
+}
+
 func init() {
 	dnsCreateZoneCmd.Flags().String("description", "", "Description of the zone")
 	dnsCreateZoneCmd.Flags().String("vpc-id", "", "Associate with a VPC for private DNS")
@@ -209,10 +267,14 @@ func init() {
 	_ = dnsCreateRecordCmd.MarkFlagRequired("name")
 	_ = dnsCreateRecordCmd.MarkFlagRequired("content")
 
+	dnsExportCmd.Flags().StringP("output", "o", "", "Write the zone file to this path instead of stdout")
+
 	dnsCmd.AddCommand(dnsListZonesCmd)
 	dnsCmd.AddCommand(dnsCreateZoneCmd)
 	dnsCmd.AddCommand(dnsDeleteZoneCmd)
 	dnsCmd.AddCommand(dnsListRecordsCmd)
 	dnsCmd.AddCommand(dnsCreateRecordCmd)
 	dnsCmd.AddCommand(dnsDeleteRecordCmd)
+	dnsCmd.AddCommand(dnsImportCmd)
+	dnsCmd.AddCommand(dnsExportCmd)
 }
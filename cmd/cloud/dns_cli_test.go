@@ -71,3 +71,32 @@ func TestDNSCreateZoneFormat(t *testing.T) {
 		t.Fatalf("expected success message, got: %s", out)
 	}
 }
+
+func TestDNSExportZone(t *testing.T) {
+	zoneID := uuid.New().String()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dns/zones/"+zoneID+"/export" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		payload := map[string]interface{}{
+			"data": map[string]interface{}{
+				"zone_file": "$ORIGIN example.com.\nwww\t300\tIN\tA\t10.0.0.2\n",
+			},
+		}
+		_ = json.NewEncoder(w).Encode(payload)
+	}))
+	defer server.Close()
+
+	apiURL = server.URL
+	apiKey = "test-key"
+
+	out := captureStdout(t, func() {
+		dnsExportCmd.Run(dnsExportCmd, []string{zoneID})
+	})
+
+	if !strings.Contains(out, "$ORIGIN example.com.") {
+		t.Fatalf("expected zone file output, got: %s", out)
+	}
+}
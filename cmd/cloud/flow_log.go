@@ -0,0 +1,85 @@
+// Package main provides the cloud CLI entrypoint.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var flowLogCmd = &cobra.Command{
+	Use:   "flow-log",
+	Short: "Capture VPC traffic into CloudLogs",
+	Long:  "Flow records are ingested about once a minute. Read them with 'cloud logs show <flow-log-id>'.",
+}
+
+var flowLogListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List flow logs",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		logs, err := client.ListFlowLogs()
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		if outputJSON {
+			data, _ := json.MarshalIndent(logs, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"ID", "RESOURCE TYPE", "RESOURCE", "TRAFFIC", "VPC"})
+		for _, fl := range logs {
+			_ = table.Append([]string{fl.ID, fl.ResourceType, fl.ResourceID, fl.TrafficType, fl.VPCID})
+		}
+		_ = table.Render()
+	},
+}
+
+var flowLogCreateCmd = &cobra.Command{
+	Use:   "create [vpc|subnet|instance] [resource-id]",
+	Short: "Start capturing a VPC, subnet or instance's flows",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		traffic, _ := cmd.Flags().GetString("traffic")
+
+		client := getClient()
+		fl, err := client.CreateFlowLog(args[0], args[1], traffic)
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Flow log %s capturing %s traffic of %s %s\n", fl.ID, fl.TrafficType, fl.ResourceType, fl.ResourceID)
+	},
+}
+
+var flowLogRmCmd = &cobra.Command{
+	Use:   "rm [flow-log-id]",
+	Short: "Stop a flow log; ingested records are kept",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.DeleteFlowLog(args[0]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Flow log %s removed.\n", args[0])
+	},
+}
+
+func init() {
+	flowLogCreateCmd.Flags().String("traffic", "all", "Flows to record (accept/reject/all)")
+
+	vpcCmd.AddCommand(flowLogCmd)
+	flowLogCmd.AddCommand(flowLogListCmd)
+	flowLogCmd.AddCommand(flowLogCreateCmd)
+	flowLogCmd.AddCommand(flowLogRmCmd)
+}
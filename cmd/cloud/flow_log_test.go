@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFlowLogCreate(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/flow-logs" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":            "fl-1",
+				"resource_type": got["resource_type"],
+				"resource_id":   got["resource_id"],
+				"traffic_type":  got["traffic_type"],
+			},
+		})
+	}))
+	defer server.Close()
+
+	oldURL, oldKey := apiURL, apiKey
+	apiURL, apiKey = server.URL, "flow-key"
+	defer func() { apiURL, apiKey = oldURL, oldKey }()
+
+	_ = flowLogCreateCmd.Flags().Set("traffic", "reject")
+	defer func() { _ = flowLogCreateCmd.Flags().Set("traffic", "all") }()
+
+	out := captureStdout(t, func() {
+		flowLogCreateCmd.Run(flowLogCreateCmd, []string{"subnet", "sub-1"})
+	})
+	if got["resource_type"] != "subnet" || got["traffic_type"] != "reject" {
+		t.Fatalf("unexpected request body: %v", got)
+	}
+	if !strings.Contains(out, "Flow log fl-1 capturing reject traffic of subnet sub-1") {
+		t.Fatalf("expected flow log in output, got: %s", out)
+	}
+}
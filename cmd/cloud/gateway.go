@@ -2,10 +2,18 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/poyrazk/thecloud/pkg/sdk"
 	"github.com/spf13/cobra"
 )
 
@@ -29,25 +37,146 @@ Pattern Syntax:
 
 Examples:
   cloud gateway create-route users-api "/users/{id}" http://user-service:8080
-  cloud gateway create-route files "/files/*" http://storage:8080 --strip`,
-	Args: cobra.ExactArgs(3),
+  cloud gateway create-route files "/files/*" http://storage:8080 --strip
+  cloud gateway create-route orders "/orders/*" http://orders:8080 --plugins-file plugins.json
+  cloud gateway create-route shop "/shop/*" --upstream url=http://v1:8080,weight=90 --upstream url=http://v2:8080,weight=10
+  cloud gateway create-route web "/web/*" --upstream lb=<lb-id> --upstream deployment=<id>,port=8080 --retries 2
+  cloud gateway create-route hello "/hello/{name}" --upstream function=<function-id>
+
+The plugins file is a JSON object configuring "auth", "cors",
+"request_headers", "response_headers" and "max_body_bytes".
+
+Upstreams replace the target argument and take one of url=, lb=,
+deployment= or function=, with optional port= and weight= (default 1). A
+function upstream invokes the function with each request and must be the
+route's only upstream.`,
+	Args: cobra.RangeArgs(2, 3),
 	Run: func(cmd *cobra.Command, args []string) {
 		strip, _ := cmd.Flags().GetBool("strip")
 		limit, _ := cmd.Flags().GetInt("rate-limit")
 		priority, _ := cmd.Flags().GetInt("priority")
 		methods, _ := cmd.Flags().GetStringSlice("methods")
+		pluginsFile, _ := cmd.Flags().GetString("plugins-file")
+		upstreamSpecs, _ := cmd.Flags().GetStringArray("upstream")
+		retries, _ := cmd.Flags().GetInt("retries")
+		timeoutMs, _ := cmd.Flags().GetInt("timeout-ms")
+		breakerThreshold, _ := cmd.Flags().GetInt("breaker-threshold")
+		breakerReset, _ := cmd.Flags().GetInt("breaker-reset-sec")
+		certificateID, _ := cmd.Flags().GetString("certificate")
+		httpsRedirect, _ := cmd.Flags().GetBool("https-redirect")
+
+		req := sdk.CreateGatewayRouteRequest{
+			Name:          args[0],
+			PathPrefix:    args[1],
+			Methods:       methods,
+			StripPrefix:   strip,
+			RateLimit:     limit,
+			Priority:      priority,
+			CertificateID: certificateID,
+			HTTPSRedirect: httpsRedirect,
+			TrafficPolicy: sdk.GatewayTrafficPolicy{
+				Retries:   retries,
+				TimeoutMs: timeoutMs,
+			},
+		}
+		if len(args) == 3 {
+			req.TargetURL = args[2]
+		}
+		for _, spec := range upstreamSpecs {
+			upstream, err := parseGatewayUpstream(spec)
+			if err != nil {
+				fmt.Printf(gatewayErrorFormat, err)
+				return
+			}
+			req.Upstreams = append(req.Upstreams, upstream)
+		}
+		if req.TargetURL == "" && len(req.Upstreams) == 0 {
+			fmt.Printf(gatewayErrorFormat, "a target or at least one --upstream is required")
+			return
+		}
+		if breakerThreshold > 0 || breakerReset > 0 {
+			breaker := &sdk.GatewayCircuitBreakerConfig{FailureThreshold: 5, ResetTimeoutSec: 30}
+			if breakerThreshold > 0 {
+				breaker.FailureThreshold = breakerThreshold
+			}
+			if breakerReset > 0 {
+				breaker.ResetTimeoutSec = breakerReset
+			}
+			req.TrafficPolicy.CircuitBreaker = breaker
+		}
+		if pluginsFile != "" {
+			data, err := os.ReadFile(filepath.Clean(pluginsFile))
+			if err != nil {
+				fmt.Printf(gatewayErrorFormat, err)
+				return
+			}
+			if err := json.Unmarshal(data, &req.Plugins); err != nil {
+				fmt.Printf(gatewayErrorFormat, fmt.Errorf("invalid plugins file: %w", err))
+				return
+			}
+		}
 
 		client := getClient()
-		route, err := client.CreateGatewayRoute(args[0], args[1], args[2], methods, strip, limit, priority)
+		route, err := client.CreateGatewayRouteWithRequest(req)
 		if err != nil {
 			fmt.Printf(gatewayErrorFormat, err)
 			return
 		}
 
-		fmt.Printf("[SUCCESS] Route created: %s (Pattern: %s -> %s, Methods: %v)\n", route.Name, route.PathPattern, route.TargetURL, route.Methods)
+		fmt.Printf("[SUCCESS] Route created: %s (Pattern: %s -> %s, Methods: %v)\n", route.Name, route.PathPattern, routeTargets(*route), route.Methods)
 	},
 }
 
+// parseGatewayUpstream parses an --upstream value such as
+// "url=http://v2:8080,weight=10" or "deployment=<id>,port=8080".
+func parseGatewayUpstream(spec string) (sdk.GatewayUpstream, error) {
+	upstream := sdk.GatewayUpstream{Weight: 1}
+	for _, part := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return upstream, fmt.Errorf("invalid upstream %q: expected key=value pairs", spec)
+		}
+		switch key {
+		case "url":
+			upstream.Type, upstream.URL = "url", value
+		case "lb", "deployment", "function":
+			upstream.Type, upstream.TargetID = key, value
+		case "port", "weight":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return upstream, fmt.Errorf("invalid upstream %q: %s must be a number", spec, key)
+			}
+			if key == "port" {
+				upstream.Port = n
+			} else {
+				upstream.Weight = n
+			}
+		default:
+			return upstream, fmt.Errorf("invalid upstream %q: unknown key %q", spec, key)
+		}
+	}
+	if upstream.Type == "" {
+		return upstream, fmt.Errorf("invalid upstream %q: one of url=, lb=, deployment= or function= is required", spec)
+	}
+	return upstream, nil
+}
+
+// routeTargets summarises where a route sends traffic.
+func routeTargets(r sdk.GatewayRoute) string {
+	if len(r.Upstreams) == 0 {
+		return r.TargetURL
+	}
+	targets := make([]string, 0, len(r.Upstreams))
+	for _, u := range r.Upstreams {
+		target := u.URL
+		if target == "" {
+			target = u.Type + ":" + u.TargetID
+		}
+		targets = append(targets, fmt.Sprintf("%s (%d)", target, u.Weight))
+	}
+	return strings.Join(targets, ", ")
+}
+
 var listRoutesCmd = &cobra.Command{
 	Use:   "list-routes",
 	Short: "List all gateway routes",
@@ -62,7 +191,7 @@ var listRoutesCmd = &cobra.Command{
 		table := tablewriter.NewWriter(os.Stdout)
 		table.Header([]string{"ID", "NAME", "PATTERN", "TARGET", "STRIP"})
 		for _, r := range routes {
-			_ = table.Append([]string{r.ID, r.Name, r.PathPattern, r.TargetURL, fmt.Sprintf("%v", r.StripPrefix)})
+			_ = table.Append([]string{r.ID, r.Name, r.PathPattern, routeTargets(r), fmt.Sprintf("%v", r.StripPrefix)})
 		}
 		_ = table.Render()
 	},
@@ -83,14 +212,130 @@ var deleteRouteCmd = &cobra.Command{
 	},
 }
 
+// gatewayLogsPollInterval is how often --follow checks for new access logs.
+var gatewayLogsPollInterval = 2 * time.Second
+
+var gatewayLogsCmd = &cobra.Command{
+	Use:   "logs [route-id]",
+	Short: "Show access logs for a gateway route",
+	Long: `Show a route's access logs, oldest first. Logs are ingested in
+batches, so the newest requests may take a few seconds to appear.
+
+Examples:
+  cloud gateway logs <route-id>
+  cloud gateway logs <route-id> --limit 20 --follow`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		limit, _ := cmd.Flags().GetInt("limit")
+		follow, _ := cmd.Flags().GetBool("follow")
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		client := getClient()
+		query := sdk.LogQuery{ResourceType: "gateway-route", ResourceID: args[0], Limit: limit}
+		seen := make(map[string]time.Time)
+		printed := false
+		for {
+			res, err := client.SearchLogs(ctx, query)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				fmt.Printf(gatewayErrorFormat, err)
+				return
+			}
+
+			// Results are newest first; print them in arrival order.
+			entries := slices.Clone(res.Entries)
+			slices.Reverse(entries)
+			for _, e := range entries {
+				if _, ok := seen[e.ID]; ok {
+					continue
+				}
+				seen[e.ID] = e.Timestamp
+				printed = true
+				printGatewayAccessLog(e)
+				if query.StartTime == nil || e.Timestamp.After(*query.StartTime) {
+					ts := e.Timestamp
+					query.StartTime = &ts
+				}
+			}
+			if !follow {
+				if !printed {
+					fmt.Println("No access logs found.")
+				}
+				return
+			}
+			// The API filters by whole seconds, so only logs from the last
+			// second can be returned again.
+			for id, ts := range seen {
+				if ts.Before(query.StartTime.Truncate(time.Second)) {
+					delete(seen, id)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(gatewayLogsPollInterval):
+			}
+		}
+	},
+}
+
+// gatewayAccessLog mirrors the access log message the gateway records.
+type gatewayAccessLog struct {
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	Status    int     `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Upstream  string  `json:"upstream"`
+	ClientIP  string  `json:"client_ip"`
+	APIKeyID  string  `json:"api_key_id"`
+}
+
+func printGatewayAccessLog(e sdk.LogEntry) {
+	if outputJSON {
+		fmt.Println(e.Message)
+		return
+	}
+	var l gatewayAccessLog
+	if err := json.Unmarshal([]byte(e.Message), &l); err != nil {
+		fmt.Printf("%s %s\n", e.Timestamp.Format(time.RFC3339), e.Message)
+		return
+	}
+	line := fmt.Sprintf("%s %d %s %s %.1fms client=%s", e.Timestamp.Format(time.RFC3339), l.Status, l.Method, l.Path, l.LatencyMs, l.ClientIP)
+	if l.Upstream != "" {
+		line += " upstream=" + l.Upstream
+	}
+	if l.APIKeyID != "" {
+		line += " key=" + l.APIKeyID
+	}
+	fmt.Println(line)
+}
+
 func init() {
 	createRouteCmd.Flags().Bool("strip", true, "Strip prefix from target request")
 	createRouteCmd.Flags().Int("rate-limit", 100, "Rate limit (req/sec)")
 	createRouteCmd.Flags().Int("priority", 0, "Relative priority for overlapping routes (higher wins)")
 	createRouteCmd.Flags().StringSlice("methods", []string{}, "HTTP methods to match (comma-separated, empty = all)")
+	createRouteCmd.Flags().String("plugins-file", "", "JSON file with auth, CORS, header rewrite and body size plugins")
+	createRouteCmd.Flags().StringArray("upstream", nil, "Weighted upstream: url=URL|lb=ID|deployment=ID|function=ID[,port=N][,weight=N] (repeatable)")
+	createRouteCmd.Flags().Int("retries", 0, "Retries for failed idempotent requests on another upstream")
+	createRouteCmd.Flags().Int("timeout-ms", 0, "Per-attempt upstream response timeout in milliseconds (0 = none)")
+	createRouteCmd.Flags().Int("breaker-threshold", 0, "Consecutive failures before an upstream's circuit opens (default 5)")
+	createRouteCmd.Flags().Int("breaker-reset-sec", 0, "Seconds an open circuit waits before retrying the upstream (default 30)")
+	createRouteCmd.Flags().String("certificate", "", "Certificate ID served for the route's domains on the gateway HTTPS listener")
+	createRouteCmd.Flags().Bool("https-redirect", false, "Redirect plain HTTP requests to the gateway HTTPS listener")
 
 	gatewayCmd.AddCommand(createRouteCmd)
 	gatewayCmd.AddCommand(listRoutesCmd)
 	gatewayCmd.AddCommand(deleteRouteCmd)
 
+	gatewayLogsCmd.Flags().Int("limit", 100, "Number of recent access logs to show")
+	gatewayLogsCmd.Flags().BoolP("follow", "f", false, "Keep polling for new access logs")
+	gatewayCmd.AddCommand(gatewayLogsCmd)
+
 }
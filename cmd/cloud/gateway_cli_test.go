@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
 )
 
 const (
@@ -52,6 +59,105 @@ func TestGatewayCreateRouteCmd(t *testing.T) {
 	}
 }
 
+func TestGatewayCreateRouteCmdWithPlugins(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": gatewayTestID, "name": "api"},
+		})
+	}))
+	defer server.Close()
+
+	oldURL := apiURL
+	oldKey := apiKey
+	apiURL = server.URL
+	apiKey = gatewayTestAPIKey
+	defer func() {
+		apiURL = oldURL
+		apiKey = oldKey
+	}()
+
+	path := filepath.Join(t.TempDir(), "plugins.json")
+	if err := os.WriteFile(path, []byte(`{"cors":{"allow_origins":["*"]},"max_body_bytes":1024}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_ = createRouteCmd.Flags().Set("plugins-file", path)
+	defer func() { _ = createRouteCmd.Flags().Set("plugins-file", "") }()
+
+	out := captureStdout(t, func() {
+		createRouteCmd.Run(createRouteCmd, []string{"api", "/api", "https://example.com"})
+	})
+	if !strings.Contains(out, "Route created") {
+		t.Fatalf("expected create output, got: %s", out)
+	}
+	plugins, ok := got["plugins"].(map[string]interface{})
+	if !ok || plugins["max_body_bytes"] != float64(1024) {
+		t.Fatalf("expected plugins in request, got: %v", got)
+	}
+}
+
+func TestGatewayCreateRouteCmdWithUpstreams(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": gatewayTestID, "name": "shop", "upstreams": got["upstreams"],
+		})
+	}))
+	defer server.Close()
+
+	oldURL := apiURL
+	oldKey := apiKey
+	apiURL = server.URL
+	apiKey = gatewayTestAPIKey
+	defer func() {
+		apiURL = oldURL
+		apiKey = oldKey
+	}()
+
+	_ = createRouteCmd.Flags().Set("upstream", "url=http://v1:8080,weight=90")
+	_ = createRouteCmd.Flags().Set("upstream", "deployment="+gatewayTestID+",port=8080")
+	_ = createRouteCmd.Flags().Set("retries", "2")
+	defer func() {
+		_ = createRouteCmd.Flags().Lookup("upstream").Value.(pflag.SliceValue).Replace(nil)
+		_ = createRouteCmd.Flags().Set("retries", "0")
+	}()
+
+	out := captureStdout(t, func() {
+		createRouteCmd.Run(createRouteCmd, []string{"shop", "/shop"})
+	})
+	if !strings.Contains(out, "http://v1:8080 (90)") || !strings.Contains(out, "deployment:"+gatewayTestID+" (1)") {
+		t.Fatalf("expected upstreams in output, got: %s", out)
+	}
+	upstreams, ok := got["upstreams"].([]interface{})
+	if !ok || len(upstreams) != 2 {
+		t.Fatalf("expected two upstreams in request, got: %v", got)
+	}
+	if port := upstreams[1].(map[string]interface{})["port"]; port != float64(8080) {
+		t.Fatalf("expected deployment port 8080, got: %v", port)
+	}
+	if policy := got["traffic_policy"].(map[string]interface{}); policy["retries"] != float64(2) {
+		t.Fatalf("expected retries in traffic policy, got: %v", policy)
+	}
+}
+
+func TestParseGatewayUpstreamFunction(t *testing.T) {
+	upstream, err := parseGatewayUpstream("function=" + gatewayTestID)
+	if err != nil || upstream.Type != "function" || upstream.TargetID != gatewayTestID {
+		t.Fatalf("unexpected function upstream %+v: %v", upstream, err)
+	}
+}
+
+func TestParseGatewayUpstreamErrors(t *testing.T) {
+	for _, spec := range []string{"weight=2", "url", "lb=x,weight=heavy", "host=x"} {
+		if _, err := parseGatewayUpstream(spec); err == nil {
+			t.Fatalf("expected error for %q", spec)
+		}
+	}
+}
+
 func TestGatewayDeleteRouteCmd(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/gateway/routes/"+gatewayTestID || r.Method != http.MethodDelete {
@@ -78,3 +184,108 @@ func TestGatewayDeleteRouteCmd(t *testing.T) {
 		t.Fatalf("expected delete output, got: %s", out)
 	}
 }
+
+func gatewayLogEntry(id string, ts time.Time, status int) map[string]interface{} {
+	msg, _ := json.Marshal(map[string]interface{}{
+		"method": "GET", "path": "/api/" + id, "status": status, "latency_ms": 1.5,
+		"upstream": "10.0.0.1:80", "client_ip": "198.51.100.4", "api_key_id": "abc123",
+	})
+	return map[string]interface{}{
+		"id": id, "resource_type": "gateway-route", "resource_id": gatewayTestID,
+		"level": "INFO", "message": string(msg), "timestamp": ts.Format(time.RFC3339Nano),
+	}
+}
+
+func TestGatewayLogsCmd(t *testing.T) {
+	now := time.Now().UTC()
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		query = r.URL.Query()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{
+			"entries": []map[string]interface{}{
+				gatewayLogEntry("second", now, http.StatusBadGateway),
+				gatewayLogEntry("first", now.Add(-time.Second), http.StatusOK),
+			},
+		}})
+	}))
+	defer server.Close()
+
+	oldURL := apiURL
+	oldKey := apiKey
+	apiURL = server.URL
+	apiKey = gatewayTestAPIKey
+	defer func() {
+		apiURL = oldURL
+		apiKey = oldKey
+	}()
+
+	_ = gatewayLogsCmd.Flags().Set("limit", "20")
+	defer func() { _ = gatewayLogsCmd.Flags().Set("limit", "100") }()
+	out := captureStdout(t, func() {
+		gatewayLogsCmd.Run(gatewayLogsCmd, []string{gatewayTestID})
+	})
+
+	if query.Get("resource_type") != "gateway-route" || query.Get("resource_id") != gatewayTestID || query.Get("limit") != "20" {
+		t.Fatalf("unexpected query: %v", query)
+	}
+	first, second := strings.Index(out, "/api/first"), strings.Index(out, "/api/second")
+	if first < 0 || second < first {
+		t.Fatalf("expected logs oldest first, got: %s", out)
+	}
+	if !strings.Contains(out, "502 GET") || !strings.Contains(out, "upstream=10.0.0.1:80") || !strings.Contains(out, "key=abc123") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+}
+
+func TestGatewayLogsCmdFollow(t *testing.T) {
+	now := time.Now().UTC()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var polls int
+	var startTimes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		polls++
+		startTimes = append(startTimes, r.URL.Query().Get("start_time"))
+		entries := []map[string]interface{}{gatewayLogEntry("first", now, http.StatusOK)}
+		if polls > 1 {
+			entries = append([]map[string]interface{}{gatewayLogEntry("second", now.Add(time.Second), http.StatusOK)}, entries...)
+		}
+		if polls > 2 {
+			cancel()
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"entries": entries}})
+	}))
+	defer server.Close()
+
+	oldURL := apiURL
+	oldKey := apiKey
+	oldInterval := gatewayLogsPollInterval
+	apiURL = server.URL
+	apiKey = gatewayTestAPIKey
+	gatewayLogsPollInterval = time.Millisecond
+	defer func() {
+		apiURL = oldURL
+		apiKey = oldKey
+		gatewayLogsPollInterval = oldInterval
+		gatewayLogsCmd.SetContext(context.Background())
+	}()
+
+	_ = gatewayLogsCmd.Flags().Set("follow", "true")
+	defer func() { _ = gatewayLogsCmd.Flags().Set("follow", "false") }()
+	gatewayLogsCmd.SetContext(ctx)
+	out := captureStdout(t, func() {
+		gatewayLogsCmd.Run(gatewayLogsCmd, []string{gatewayTestID})
+	})
+
+	if strings.Count(out, "/api/first") != 1 || strings.Count(out, "/api/second") != 1 {
+		t.Fatalf("expected each log once, got: %s", out)
+	}
+	if strings.Contains(out, "Error") {
+		t.Fatalf("stopping should not report an error, got: %s", out)
+	}
+	if len(startTimes) != 3 || startTimes[0] != "" || startTimes[1] != now.Format(time.RFC3339) || startTimes[2] != now.Add(time.Second).Format(time.RFC3339) {
+		t.Fatalf("unexpected start times: %v", startTimes)
+	}
+}
@@ -208,6 +208,12 @@ var showCmd = &cobra.Command{
 		fmt.Printf(fmtDetailRow, "Status:", inst.Status)
 		fmt.Printf(fmtDetailRow, "Image:", inst.Image)
 		fmt.Printf(fmtDetailRow, "Ports:", inst.Ports)
+		if inst.PrivateIP != "" {
+			fmt.Printf(fmtDetailRow, "Private IP:", inst.PrivateIP)
+		}
+		if inst.PrivateIPv6 != "" {
+			fmt.Printf(fmtDetailRow, "Private IPv6:", inst.PrivateIPv6)
+		}
 		fmt.Printf(fmtDetailRow, "Created At:", inst.CreatedAt)
 		fmt.Printf(fmtDetailRow, "Version:", inst.Version)
 		fmt.Printf(fmtDetailRow, "Container ID:", inst.ContainerID)
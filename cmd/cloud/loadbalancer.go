@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/poyrazk/thecloud/pkg/sdk"
 	"github.com/spf13/cobra"
 )
 
@@ -98,9 +100,16 @@ var lbAddTargetCmd = &cobra.Command{
 		instID, _ := cmd.Flags().GetString("instance")
 		port, _ := cmd.Flags().GetInt("port")
 		weight, _ := cmd.Flags().GetInt("weight")
+		group, _ := cmd.Flags().GetString("group")
 
 		client := getClient()
-		if err := client.AddLBTarget(lbID, instID, port, weight); err != nil {
+		var err error
+		if group != "" {
+			err = client.AddLBTargetToGroup(lbID, instID, port, weight, group)
+		} else {
+			err = client.AddLBTarget(lbID, instID, port, weight)
+		}
+		if err != nil {
 			fmt.Printf(loadBalancerErrorFormat, err)
 			return
 		}
@@ -116,8 +125,16 @@ var lbRemoveTargetCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		lbID := args[0]
 		instID := args[1]
+		group, _ := cmd.Flags().GetString("group")
+
 		client := getClient()
-		if err := client.RemoveLBTarget(lbID, instID); err != nil {
+		var err error
+		if group != "" {
+			err = client.RemoveLBTargetFromGroup(lbID, instID, group)
+		} else {
+			err = client.RemoveLBTarget(lbID, instID)
+		}
+		if err != nil {
 			fmt.Printf(loadBalancerErrorFormat, err)
 			return
 		}
@@ -126,6 +143,168 @@ var lbRemoveTargetCmd = &cobra.Command{
 	},
 }
 
+var lbTLSCmd = &cobra.Command{
+	Use:   "tls [lb-id]",
+	Short: "Configure the HTTPS listener of a load balancer",
+	Long: `Terminate HTTPS on --port with one or more certificates; the listener
+serves the certificate matching the client's SNI server name and falls back
+to the first. Use --disable to remove the listener.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		disable, _ := cmd.Flags().GetBool("disable")
+		port, _ := cmd.Flags().GetInt("port")
+		certIDs, _ := cmd.Flags().GetStringSlice("certificate")
+		redirect, _ := cmd.Flags().GetBool("redirect-http")
+
+		client := getClient()
+		if disable {
+			if _, err := client.DisableLBTLS(args[0]); err != nil {
+				fmt.Printf(loadBalancerErrorFormat, err)
+				return
+			}
+			fmt.Printf("[SUCCESS] HTTPS listener of LB %s removal initiated.\n", args[0])
+			return
+		}
+		if len(certIDs) == 0 {
+			fmt.Printf(loadBalancerErrorFormat, "at least one --certificate is required")
+			return
+		}
+
+		lb, err := client.ConfigureLBTLS(args[0], sdk.LBTLSConfig{Port: port, CertificateIDs: certIDs, RedirectHTTP: redirect})
+		if err != nil {
+			fmt.Printf(loadBalancerErrorFormat, err)
+			return
+		}
+		fmt.Printf("[SUCCESS] HTTPS listener on port %d configured for LB %s.\n", port, lb.Name)
+		fmt.Printf("Status: %s (It will be ACTIVE shortly)\n", lb.Status)
+	},
+}
+
+var lbListenersCmd = &cobra.Command{
+	Use:   "listeners [lb-id]",
+	Short: "Configure the listeners and routing rules of a load balancer",
+	Long: `Replace the load balancer's HTTP listeners with those in --file, a JSON
+array of listeners. Each listener has a port, an optional default_target_group
+and rules routing requests by host, path_prefix or header to a target_group;
+lower priorities match first. Use --clear to restore the single default
+listener.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		reset, _ := cmd.Flags().GetBool("clear")
+
+		var listeners []sdk.LBListener
+		if !reset {
+			if file == "" {
+				fmt.Printf(loadBalancerErrorFormat, "--file or --clear is required")
+				return
+			}
+			data, err := os.ReadFile(filepath.Clean(file))
+			if err != nil {
+				fmt.Printf(loadBalancerErrorFormat, err)
+				return
+			}
+			if err := json.Unmarshal(data, &listeners); err != nil {
+				fmt.Printf(loadBalancerErrorFormat, fmt.Errorf("invalid listeners file: %w", err))
+				return
+			}
+		}
+
+		client := getClient()
+		lb, err := client.ConfigureLBListeners(args[0], listeners)
+		if err != nil {
+			fmt.Printf(loadBalancerErrorFormat, err)
+			return
+		}
+		fmt.Printf("[SUCCESS] %d listener(s) configured for LB %s.\n", len(lb.Listeners), lb.Name)
+		fmt.Printf("Status: %s (It will be ACTIVE shortly)\n", lb.Status)
+	},
+}
+
+var lbAttributesCmd = &cobra.Command{
+	Use:   "attributes [lb-id]",
+	Short: "Configure health checks, connection draining and sticky sessions",
+	Long: `Update the target policies of a load balancer; settings whose flags are
+not given are kept. Health check flags switch from the TCP port check to the
+configured check (HTTP when --health-path is set); --tcp-health-check restores
+the port check. --deregistration-delay drains removed targets for that many
+seconds before they are dropped. --sticky pins clients to a target with a
+cookie.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		lb, err := client.GetLB(args[0])
+		if err != nil {
+			fmt.Printf(loadBalancerErrorFormat, err)
+			return
+		}
+		attrs := sdk.LBAttributes{
+			HealthCheck:                lb.HealthCheck,
+			DeregistrationDelaySeconds: lb.DeregistrationDelaySeconds,
+			StickySessions:             lb.StickySessions,
+		}
+
+		flags := cmd.Flags()
+		if tcp, _ := flags.GetBool("tcp-health-check"); tcp {
+			attrs.HealthCheck = nil
+		}
+		for _, name := range []string{"health-path", "health-interval", "health-timeout", "healthy-threshold", "unhealthy-threshold"} {
+			if !flags.Changed(name) {
+				continue
+			}
+			if attrs.HealthCheck == nil {
+				attrs.HealthCheck = &sdk.LBHealthCheck{}
+			}
+			switch name {
+			case "health-path":
+				attrs.HealthCheck.Path, _ = flags.GetString(name)
+			case "health-interval":
+				attrs.HealthCheck.IntervalSeconds, _ = flags.GetInt(name)
+			case "health-timeout":
+				attrs.HealthCheck.TimeoutSeconds, _ = flags.GetInt(name)
+			case "healthy-threshold":
+				attrs.HealthCheck.HealthyThreshold, _ = flags.GetInt(name)
+			case "unhealthy-threshold":
+				attrs.HealthCheck.UnhealthyThreshold, _ = flags.GetInt(name)
+			}
+		}
+		if flags.Changed("deregistration-delay") {
+			attrs.DeregistrationDelaySeconds, _ = flags.GetInt("deregistration-delay")
+		}
+		if sticky, _ := flags.GetBool("sticky"); flags.Changed("sticky") && !sticky {
+			attrs.StickySessions = nil
+		} else if sticky || flags.Changed("sticky-cookie") || flags.Changed("sticky-duration") {
+			cookie, _ := flags.GetString("sticky-cookie")
+			duration, _ := flags.GetInt("sticky-duration")
+			if attrs.StickySessions == nil {
+				attrs.StickySessions = &sdk.LBStickySessions{CookieName: cookie, DurationSeconds: duration}
+			}
+			if flags.Changed("sticky-cookie") {
+				attrs.StickySessions.CookieName = cookie
+			}
+			if flags.Changed("sticky-duration") {
+				attrs.StickySessions.DurationSeconds = duration
+			}
+		}
+
+		lb, err = client.ConfigureLBAttributes(args[0], attrs)
+		if err != nil {
+			fmt.Printf(loadBalancerErrorFormat, err)
+			return
+		}
+		check := "tcp"
+		if lb.HealthCheck != nil && lb.HealthCheck.Path != "" {
+			check = "http " + lb.HealthCheck.Path
+		}
+		sticky := "off"
+		if lb.StickySessions != nil {
+			sticky = lb.StickySessions.CookieName
+		}
+		fmt.Printf("[SUCCESS] Attributes of LB %s updated.\n", lb.Name)
+		fmt.Printf("Health check: %s, deregistration delay: %ds, sticky sessions: %s\n", check, lb.DeregistrationDelaySeconds, sticky)
+	},
+}
+
 func init() {
 	lbCreateCmd.Flags().String("name", "", "Name of the load balancer")
 	cobra.CheckErr(lbCreateCmd.MarkFlagRequired("name"))
@@ -139,6 +318,27 @@ func init() {
 	lbAddTargetCmd.Flags().Int("port", 80, "Port on the instance")
 	cobra.CheckErr(lbAddTargetCmd.MarkFlagRequired("port"))
 	lbAddTargetCmd.Flags().Int("weight", 1, "Weight for the target (optional)")
+	lbAddTargetCmd.Flags().String("group", "", "Target group listener rules route to (default \"default\")")
+	lbRemoveTargetCmd.Flags().String("group", "", "Target group to remove the instance from (default \"default\")")
+
+	lbListenersCmd.Flags().String("file", "", "JSON file with the listeners")
+	lbListenersCmd.Flags().Bool("clear", false, "Remove all listeners and rules")
+
+	lbAttributesCmd.Flags().String("health-path", "", "HTTP path to check; 2xx and 3xx responses pass")
+	lbAttributesCmd.Flags().Int("health-interval", 10, "Seconds between health checks")
+	lbAttributesCmd.Flags().Int("health-timeout", 5, "Seconds to wait for a health check")
+	lbAttributesCmd.Flags().Int("healthy-threshold", 2, "Consecutive passes to mark a target healthy")
+	lbAttributesCmd.Flags().Int("unhealthy-threshold", 2, "Consecutive failures to mark a target unhealthy")
+	lbAttributesCmd.Flags().Bool("tcp-health-check", false, "Only check that the target port accepts connections")
+	lbAttributesCmd.Flags().Int("deregistration-delay", 0, "Seconds removed targets drain before they are dropped")
+	lbAttributesCmd.Flags().Bool("sticky", false, "Pin clients to a target with a cookie")
+	lbAttributesCmd.Flags().String("sticky-cookie", "THECLOUD_LB", "Sticky session cookie name")
+	lbAttributesCmd.Flags().Int("sticky-duration", 86400, "Sticky session cookie lifetime in seconds")
+
+	lbTLSCmd.Flags().Int("port", 443, "HTTPS listener port")
+	lbTLSCmd.Flags().StringSlice("certificate", nil, "Certificate ID to serve (repeatable; the first is the default)")
+	lbTLSCmd.Flags().Bool("redirect-http", false, "Redirect the HTTP listener to HTTPS")
+	lbTLSCmd.Flags().Bool("disable", false, "Remove the HTTPS listener")
 
 	lbCmd.AddCommand(lbListCmd)
 	lbCmd.AddCommand(lbCreateCmd)
@@ -146,6 +346,9 @@ func init() {
 	lbCmd.AddCommand(lbAddTargetCmd)
 	lbCmd.AddCommand(lbRemoveTargetCmd)
 	lbCmd.AddCommand(lbListTargetsCmd)
+	lbCmd.AddCommand(lbTLSCmd)
+	lbCmd.AddCommand(lbListenersCmd)
+	lbCmd.AddCommand(lbAttributesCmd)
 }
 
 var lbListTargetsCmd = &cobra.Command{
@@ -167,7 +370,7 @@ var lbListTargetsCmd = &cobra.Command{
 		}
 
 		table := tablewriter.NewWriter(os.Stdout)
-		table.Header([]string{"INSTANCE ID", "PORT", "WEIGHT", "HEALTH"})
+		table.Header([]string{"INSTANCE ID", "GROUP", "PORT", "WEIGHT", "HEALTH"})
 		for _, t := range targets {
 			id := t.InstanceID
 			if len(id) > 8 {
@@ -175,6 +378,7 @@ var lbListTargetsCmd = &cobra.Command{
 			}
 			_ = table.Append([]string{
 				id,
+				t.TargetGroup,
 				fmt.Sprintf("%d", t.Port),
 				fmt.Sprintf("%d", t.Weight),
 				t.Health,
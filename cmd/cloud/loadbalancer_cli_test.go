@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -86,3 +88,132 @@ func TestLBAddTargetCmd(t *testing.T) {
 		t.Fatalf("expected add target output, got: %s", out)
 	}
 }
+
+func TestLBTLSCmd(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/lb/"+lbTestID+"/tls" || r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": lbTestID, "name": "public", "status": "CREATING"},
+		})
+	}))
+	defer server.Close()
+
+	oldURL := apiURL
+	oldKey := apiKey
+	apiURL = server.URL
+	apiKey = lbTestAPIKey
+	defer func() {
+		apiURL = oldURL
+		apiKey = oldKey
+	}()
+
+	_ = lbTLSCmd.Flags().Set("certificate", "cert-1,cert-2")
+	_ = lbTLSCmd.Flags().Set("redirect-http", "true")
+
+	out := captureStdout(t, func() {
+		lbTLSCmd.Run(lbTLSCmd, []string{lbTestID})
+	})
+	if !strings.Contains(out, "HTTPS listener on port 443 configured") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	if body["port"] != float64(443) || body["redirect_http"] != true || len(body["certificate_ids"].([]interface{})) != 2 {
+		t.Fatalf("unexpected request body: %v", body)
+	}
+}
+
+func TestLBListenersCmd(t *testing.T) {
+	var body map[string][]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/lb/"+lbTestID+"/listeners" || r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": lbTestID, "name": "public", "status": "CREATING", "listeners": body["listeners"]},
+		})
+	}))
+	defer server.Close()
+
+	oldURL := apiURL
+	oldKey := apiKey
+	apiURL = server.URL
+	apiKey = lbTestAPIKey
+	defer func() {
+		apiURL = oldURL
+		apiKey = oldKey
+	}()
+
+	file := filepath.Join(t.TempDir(), "listeners.json")
+	if err := os.WriteFile(file, []byte(`[{"port":80,"rules":[{"priority":1,"path_prefix":"/api","target_group":"api"}]}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_ = lbListenersCmd.Flags().Set("file", file)
+
+	out := captureStdout(t, func() {
+		lbListenersCmd.Run(lbListenersCmd, []string{lbTestID})
+	})
+	if !strings.Contains(out, "1 listener(s) configured for LB public") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	rules, _ := body["listeners"][0]["rules"].([]interface{})
+	if len(body["listeners"]) != 1 || len(rules) != 1 {
+		t.Fatalf("unexpected request body: %v", body)
+	}
+}
+
+func TestLBAttributesCmd(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/lb/"+lbTestID && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"id": lbTestID, "name": "public", "deregistration_delay_seconds": 30},
+			})
+		case r.URL.Path == "/lb/"+lbTestID+"/attributes" && r.Method == http.MethodPut:
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			data := map[string]interface{}{"id": lbTestID, "name": "public"}
+			for k, v := range body {
+				data[k] = v
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	oldURL := apiURL
+	oldKey := apiKey
+	apiURL = server.URL
+	apiKey = lbTestAPIKey
+	defer func() {
+		apiURL = oldURL
+		apiKey = oldKey
+	}()
+
+	_ = lbAttributesCmd.Flags().Set("health-path", "/healthz")
+	_ = lbAttributesCmd.Flags().Set("healthy-threshold", "3")
+	_ = lbAttributesCmd.Flags().Set("sticky", "true")
+
+	out := captureStdout(t, func() {
+		lbAttributesCmd.Run(lbAttributesCmd, []string{lbTestID})
+	})
+	if !strings.Contains(out, "Health check: http /healthz, deregistration delay: 30s, sticky sessions: THECLOUD_LB") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	health, _ := body["health_check"].(map[string]interface{})
+	if health["path"] != "/healthz" || health["healthy_threshold"] != float64(3) || body["deregistration_delay_seconds"] != float64(30) {
+		t.Fatalf("unexpected request body: %v", body)
+	}
+}
@@ -120,7 +120,7 @@ func init() {
 	cloudLogsCmd.AddCommand(logsShowCmd)
 
 	logsSearchCmd.Flags().String("resource-id", "", "Filter by resource ID")
-	logsSearchCmd.Flags().String("resource-type", "", "Filter by resource type (instance, function)")
+	logsSearchCmd.Flags().String("resource-type", "", "Filter by resource type (instance, function, vpc-flow-log, gateway-route)")
 	logsSearchCmd.Flags().String("level", "", "Filter by log level (INFO, WARN, ERROR)")
 	logsSearchCmd.Flags().String("query", "", "Search keyword in message")
 	logsSearchCmd.Flags().Int("limit", 100, "Limit number of logs")
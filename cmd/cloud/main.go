@@ -50,6 +50,7 @@ func init() {
 	rootCmd.AddCommand(kubernetesCmd)
 	rootCmd.AddCommand(dnsCmd)
 	rootCmd.AddCommand(cloudLogsCmd)
+	rootCmd.AddCommand(certCmd)
 }
 
 func main() {
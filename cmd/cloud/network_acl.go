@@ -0,0 +1,230 @@
+// Package main provides the cloud CLI entrypoint.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/poyrazk/thecloud/pkg/sdk"
+	"github.com/spf13/cobra"
+)
+
+var networkACLCmd = &cobra.Command{
+	Use:   "network-acl",
+	Short: "Manage subnet network ACLs",
+}
+
+var networkACLListCmd = &cobra.Command{
+	Use:   "list [vpc-id]",
+	Short: "List a VPC's network ACLs",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		acls, err := client.ListNetworkACLs(args[0])
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		if outputJSON {
+			data, _ := json.MarshalIndent(acls, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"ID", "NAME", "RULES", "SUBNETS"})
+
+		for _, acl := range acls {
+			_ = table.Append([]string{
+				acl.ID,
+				acl.Name,
+				strconv.Itoa(len(acl.Rules)),
+				strings.Join(acl.SubnetIDs, ", "),
+			})
+		}
+		_ = table.Render()
+	},
+}
+
+var networkACLShowCmd = &cobra.Command{
+	Use:   "show [acl-id]",
+	Short: "Show a network ACL's rules",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		acl, err := client.GetNetworkACL(args[0])
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+		printNetworkACL(acl)
+	},
+}
+
+var networkACLCreateCmd = &cobra.Command{
+	Use:   "create [vpc-id] [name]",
+	Short: "Create an empty network ACL in a VPC",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		acl, err := client.CreateNetworkACL(args[0], args[1])
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Network ACL %s created (%s)\n", acl.Name, acl.ID)
+	},
+}
+
+var networkACLRmCmd = &cobra.Command{
+	Use:   "rm [acl-id]",
+	Short: "Delete a network ACL no subnet uses",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.DeleteNetworkACL(args[0]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Network ACL %s removed.\n", args[0])
+	},
+}
+
+var networkACLAddRuleCmd = &cobra.Command{
+	Use:   "add-rule [acl-id] [rule-number]",
+	Short: "Add a numbered allow or deny rule",
+	Long:  "Rules are evaluated in ascending number order per direction; the first match decides and unmatched traffic is denied.",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		number, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Printf("Error: invalid rule number %q\n", args[1])
+			return
+		}
+		direction, _ := cmd.Flags().GetString("direction")
+		protocol, _ := cmd.Flags().GetString("protocol")
+		portMin, _ := cmd.Flags().GetInt("port-min")
+		portMax, _ := cmd.Flags().GetInt("port-max")
+		cidr, _ := cmd.Flags().GetString("cidr")
+		action, _ := cmd.Flags().GetString("action")
+		if portMax == 0 {
+			portMax = portMin
+		}
+
+		client := getClient()
+		rule, err := client.AddNetworkACLRule(args[0], sdk.NetworkACLRule{
+			RuleNumber: number,
+			Direction:  direction,
+			Protocol:   protocol,
+			PortMin:    portMin,
+			PortMax:    portMax,
+			CIDR:       cidr,
+			Action:     action,
+		})
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] %s rule %d (%s) added: %s\n", rule.Direction, rule.RuleNumber, rule.Action, rule.ID)
+	},
+}
+
+var networkACLDelRuleCmd = &cobra.Command{
+	Use:   "del-rule [acl-id] [rule-id]",
+	Short: "Remove a rule from a network ACL",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.RemoveNetworkACLRule(args[0], args[1]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Rule %s removed.\n", args[1])
+	},
+}
+
+var networkACLAssociateCmd = &cobra.Command{
+	Use:   "associate [acl-id] [subnet-id]",
+	Short: "Filter a subnet's traffic with a network ACL",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.AssociateNetworkACL(args[0], args[1]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Subnet %s now uses network ACL %s.\n", args[1], args[0])
+	},
+}
+
+var networkACLDisassociateCmd = &cobra.Command{
+	Use:   "disassociate [subnet-id]",
+	Short: "Stop filtering a subnet with its network ACL",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.DisassociateNetworkACL(args[0]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Subnet %s no longer uses a network ACL.\n", args[0])
+	},
+}
+
+func printNetworkACL(acl *sdk.NetworkACL) {
+	if outputJSON {
+		data, _ := json.MarshalIndent(acl, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Printf("Network ACL %s (%s)\n", acl.Name, acl.ID)
+	if len(acl.SubnetIDs) > 0 {
+		fmt.Printf("Subnets: %s\n", strings.Join(acl.SubnetIDs, ", "))
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"ID", "DIRECTION", "RULE #", "PROTOCOL", "PORTS", "CIDR", "ACTION"})
+	for _, r := range acl.Rules {
+		ports := "all"
+		if r.PortMin != 0 {
+			ports = fmt.Sprintf("%d-%d", r.PortMin, r.PortMax)
+			if r.PortMin == r.PortMax {
+				ports = strconv.Itoa(r.PortMin)
+			}
+		}
+		_ = table.Append([]string{r.ID, r.Direction, strconv.Itoa(r.RuleNumber), r.Protocol, ports, r.CIDR, strings.ToUpper(r.Action)})
+	}
+	_ = table.Render()
+	fmt.Println("Traffic matching no rule is denied.")
+}
+
+func init() {
+	networkACLAddRuleCmd.Flags().String("direction", "ingress", "Rule direction (ingress/egress)")
+	networkACLAddRuleCmd.Flags().String("protocol", "tcp", "Protocol (tcp/udp/icmp/all)")
+	networkACLAddRuleCmd.Flags().Int("port-min", 0, "Minimum port")
+	networkACLAddRuleCmd.Flags().Int("port-max", 0, "Maximum port (defaults to --port-min)")
+	networkACLAddRuleCmd.Flags().String("cidr", "0.0.0.0/0", "CIDR block of the remote side")
+	networkACLAddRuleCmd.Flags().String("action", sdk.NetworkACLAllow, "Rule action (allow/deny)")
+
+	vpcCmd.AddCommand(networkACLCmd)
+	networkACLCmd.AddCommand(networkACLListCmd)
+	networkACLCmd.AddCommand(networkACLShowCmd)
+	networkACLCmd.AddCommand(networkACLCreateCmd)
+	networkACLCmd.AddCommand(networkACLRmCmd)
+	networkACLCmd.AddCommand(networkACLAddRuleCmd)
+	networkACLCmd.AddCommand(networkACLDelRuleCmd)
+	networkACLCmd.AddCommand(networkACLAssociateCmd)
+	networkACLCmd.AddCommand(networkACLDisassociateCmd)
+}
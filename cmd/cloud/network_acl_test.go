@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNetworkACLAddRule(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/network-acls/acl-1/rules" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		got["id"] = "rule-1"
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": got})
+	}))
+	defer server.Close()
+
+	oldURL, oldKey := apiURL, apiKey
+	apiURL, apiKey = server.URL, "acl-key"
+	defer func() { apiURL, apiKey = oldURL, oldKey }()
+
+	_ = networkACLAddRuleCmd.Flags().Set("port-min", "22")
+	_ = networkACLAddRuleCmd.Flags().Set("action", "deny")
+	defer func() {
+		_ = networkACLAddRuleCmd.Flags().Set("port-min", "0")
+		_ = networkACLAddRuleCmd.Flags().Set("action", "allow")
+	}()
+
+	out := captureStdout(t, func() {
+		networkACLAddRuleCmd.Run(networkACLAddRuleCmd, []string{"acl-1", "90"})
+	})
+	if got["rule_number"] != float64(90) || got["port_max"] != float64(22) || got["action"] != "deny" {
+		t.Fatalf("unexpected request body: %v", got)
+	}
+	if !strings.Contains(out, "ingress rule 90 (deny) added: rule-1") {
+		t.Fatalf("expected rule in output, got: %s", out)
+	}
+}
+
+func TestNetworkACLAddRuleRejectsBadNumber(t *testing.T) {
+	out := captureStdout(t, func() {
+		networkACLAddRuleCmd.Run(networkACLAddRuleCmd, []string{"acl-1", "first"})
+	})
+	if !strings.Contains(out, "invalid rule number") {
+		t.Fatalf("expected rule number error, got: %s", out)
+	}
+}
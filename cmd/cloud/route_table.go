@@ -0,0 +1,212 @@
+// Package main provides the cloud CLI entrypoint.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/poyrazk/thecloud/pkg/sdk"
+	"github.com/spf13/cobra"
+)
+
+var routeTableCmd = &cobra.Command{
+	Use:   "route-table",
+	Short: "Manage VPC route tables",
+}
+
+var routeTableListCmd = &cobra.Command{
+	Use:   "list [vpc-id]",
+	Short: "List a VPC's route tables",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		tables, err := client.ListRouteTables(args[0])
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		if outputJSON {
+			data, _ := json.MarshalIndent(tables, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"ID", "NAME", "MAIN", "ROUTES", "SUBNETS"})
+
+		for _, rt := range tables {
+			_ = table.Append([]string{
+				rt.ID,
+				rt.Name,
+				strconv.FormatBool(rt.Main),
+				strconv.Itoa(len(rt.Routes)),
+				strings.Join(rt.SubnetIDs, ", "),
+			})
+		}
+		_ = table.Render()
+	},
+}
+
+var routeTableShowCmd = &cobra.Command{
+	Use:   "show [route-table-id]",
+	Short: "Show a route table's routes",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		rt, err := client.GetRouteTable(args[0])
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+		printRouteTable(rt)
+	},
+}
+
+var routeTableCreateCmd = &cobra.Command{
+	Use:   "create [vpc-id] [name]",
+	Short: "Create a custom route table in a VPC",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		rt, err := client.CreateRouteTable(args[0], args[1])
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Route table %s created (%s)\n", rt.Name, rt.ID)
+	},
+}
+
+var routeTableRmCmd = &cobra.Command{
+	Use:   "rm [route-table-id]",
+	Short: "Delete a custom route table no subnet uses",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.DeleteRouteTable(args[0]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Route table %s removed.\n", args[0])
+	},
+}
+
+var routeTableAddRouteCmd = &cobra.Command{
+	Use:   "add-route [route-table-id] [destination-cidr]",
+	Short: "Route a destination range to an internet or NAT gateway",
+	Long:  "Exactly one of --igw or --nat selects the target. Use 0.0.0.0/0 for a default route.",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		igw, _ := cmd.Flags().GetString("igw")
+		nat, _ := cmd.Flags().GetString("nat")
+		if (igw == "") == (nat == "") {
+			fmt.Println("Error: specify exactly one of --igw or --nat")
+			return
+		}
+		targetType, targetID := sdk.RouteTargetInternetGateway, igw
+		if nat != "" {
+			targetType, targetID = sdk.RouteTargetNATGateway, nat
+		}
+
+		client := getClient()
+		route, err := client.AddRoute(args[0], args[1], targetType, targetID)
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Route %s -> %s %s added.\n", route.DestinationCIDR, route.TargetType, targetID)
+	},
+}
+
+var routeTableDelRouteCmd = &cobra.Command{
+	Use:   "del-route [route-table-id] [destination-cidr]",
+	Short: "Remove the route for a destination range",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.RemoveRoute(args[0], args[1]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Route %s removed.\n", args[1])
+	},
+}
+
+var routeTableAssociateCmd = &cobra.Command{
+	Use:   "associate [route-table-id] [subnet-id]",
+	Short: "Apply a route table to a subnet",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.AssociateRouteTable(args[0], args[1]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Subnet %s now uses route table %s.\n", args[1], args[0])
+	},
+}
+
+var routeTableDisassociateCmd = &cobra.Command{
+	Use:   "disassociate [subnet-id]",
+	Short: "Return a subnet to its VPC's main route table",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.DisassociateRouteTable(args[0]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Subnet %s now uses the main route table.\n", args[0])
+	},
+}
+
+func printRouteTable(rt *sdk.RouteTable) {
+	if outputJSON {
+		data, _ := json.MarshalIndent(rt, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	kind := "custom"
+	if rt.Main {
+		kind = "main"
+	}
+	fmt.Printf("Route table %s (%s, %s)\n", rt.Name, rt.ID, kind)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"DESTINATION", "TARGET TYPE", "TARGET"})
+	for _, r := range rt.Routes {
+		target := r.TargetID
+		if target == "" {
+			target = "-"
+		}
+		_ = table.Append([]string{r.DestinationCIDR, r.TargetType, target})
+	}
+	_ = table.Render()
+}
+
+func init() {
+	routeTableAddRouteCmd.Flags().String("igw", "", "Internet gateway ID to route to")
+	routeTableAddRouteCmd.Flags().String("nat", "", "NAT gateway ID to route to")
+
+	vpcCmd.AddCommand(routeTableCmd)
+	routeTableCmd.AddCommand(routeTableListCmd)
+	routeTableCmd.AddCommand(routeTableShowCmd)
+	routeTableCmd.AddCommand(routeTableCreateCmd)
+	routeTableCmd.AddCommand(routeTableRmCmd)
+	routeTableCmd.AddCommand(routeTableAddRouteCmd)
+	routeTableCmd.AddCommand(routeTableDelRouteCmd)
+	routeTableCmd.AddCommand(routeTableAssociateCmd)
+	routeTableCmd.AddCommand(routeTableDisassociateCmd)
+}
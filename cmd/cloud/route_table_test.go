@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouteTableAddRoute(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/route-tables/rt-1/routes" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"destination_cidr": got["destination_cidr"],
+				"target_type":      got["target_type"],
+				"target_id":        got["target_id"],
+			},
+		})
+	}))
+	defer server.Close()
+
+	oldURL, oldKey := apiURL, apiKey
+	apiURL, apiKey = server.URL, "route-key"
+	defer func() { apiURL, apiKey = oldURL, oldKey }()
+
+	_ = routeTableAddRouteCmd.Flags().Set("nat", "nat-1")
+	defer func() { _ = routeTableAddRouteCmd.Flags().Set("nat", "") }()
+
+	out := captureStdout(t, func() {
+		routeTableAddRouteCmd.Run(routeTableAddRouteCmd, []string{"rt-1", "0.0.0.0/0"})
+	})
+	if got["target_type"] != "nat-gateway" || got["target_id"] != "nat-1" {
+		t.Fatalf("unexpected request body: %v", got)
+	}
+	if !strings.Contains(out, "0.0.0.0/0 -> nat-gateway nat-1") {
+		t.Fatalf("expected route in output, got: %s", out)
+	}
+}
+
+func TestRouteTableAddRouteRequiresOneTarget(t *testing.T) {
+	out := captureStdout(t, func() {
+		routeTableAddRouteCmd.Run(routeTableAddRouteCmd, []string{"rt-1", "0.0.0.0/0"})
+	})
+	if !strings.Contains(out, "exactly one of --igw or --nat") {
+		t.Fatalf("expected target error, got: %s", out)
+	}
+}
@@ -98,16 +98,23 @@ var sgAddRuleCmd = &cobra.Command{
 		portMin, _ := cmd.Flags().GetInt("port-min")
 		portMax, _ := cmd.Flags().GetInt("port-max")
 		cidr, _ := cmd.Flags().GetString("cidr")
+		sourceGroup, _ := cmd.Flags().GetString("source-group")
 		priority, _ := cmd.Flags().GetInt("priority")
 
+		// A source group replaces the default CIDR unless one was given explicitly.
+		if sourceGroup != "" && !cmd.Flags().Changed("cidr") {
+			cidr = ""
+		}
+
 		client := getClient()
 		rule := sdk.SecurityRule{
-			Direction: direction,
-			Protocol:  protocol,
-			PortMin:   portMin,
-			PortMax:   portMax,
-			CIDR:      cidr,
-			Priority:  priority,
+			Direction:     direction,
+			Protocol:      protocol,
+			PortMin:       portMin,
+			PortMax:       portMax,
+			CIDR:          cidr,
+			SourceGroupID: sourceGroup,
+			Priority:      priority,
 		}
 
 		res, err := client.AddSecurityRule(args[0], rule)
@@ -188,19 +195,23 @@ var sgGetCmd = &cobra.Command{
 		fmt.Println("\nRules:")
 
 		table := tablewriter.NewWriter(os.Stdout)
-		table.Header([]string{"Rule ID", "Direction", "Protocol", "Ports", "CIDR", "Priority"})
+		table.Header([]string{"Rule ID", "Direction", "Protocol", "Ports", "Peer", "Priority"})
 
 		for _, r := range sg.Rules {
 			ports := fmt.Sprintf("%d-%d", r.PortMin, r.PortMax)
 			if r.PortMin == r.PortMax {
 				ports = fmt.Sprintf("%d", r.PortMin)
 			}
+			peer := r.CIDR
+			if r.SourceGroupID != "" {
+				peer = "sg:" + r.SourceGroupID
+			}
 			_ = table.Append([]string{
 				truncateID(r.ID, 8),
 				r.Direction,
 				r.Protocol,
 				ports,
-				r.CIDR,
+				peer,
 				fmt.Sprintf("%d", r.Priority),
 			})
 		}
@@ -233,6 +244,7 @@ func init() {
 	sgAddRuleCmd.Flags().Int("port-min", 0, "Minimum port")
 	sgAddRuleCmd.Flags().Int("port-max", 0, "Maximum port")
 	sgAddRuleCmd.Flags().String("cidr", "0.0.0.0/0", "CIDR block")
+	sgAddRuleCmd.Flags().String("source-group", "", "Security group whose instances are the peer, instead of a CIDR")
 	sgAddRuleCmd.Flags().Int("priority", 100, "Priority")
 
 	sgCmd.AddCommand(sgCreateCmd, sgListCmd, sgGetCmd, sgDeleteCmd, sgAddRuleCmd, sgRemoveRuleCmd, sgAttachCmd, sgDetachCmd)
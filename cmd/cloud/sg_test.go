@@ -19,7 +19,7 @@ func TestSGCommands(t *testing.T) {
 		"list":        {"vpc-id"},
 		"get":         {},
 		"delete":      {},
-		"add-rule":    {"direction", "protocol", "port-min", "port-max", "cidr", "priority"},
+		"add-rule":    {"direction", "protocol", "port-min", "port-max", "cidr", "source-group", "priority"},
 		"remove-rule": {},
 		"attach":      {},
 		"detach":      {},
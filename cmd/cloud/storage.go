@@ -7,9 +7,12 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/poyrazk/thecloud/pkg/sdk"
 	"github.com/spf13/cobra"
 )
 
@@ -59,14 +62,21 @@ var storageListCmd = &cobra.Command{
 
 		// List Objects
 		bucket := args[0]
-		objects, err := client.ListObjects(bucket)
+		opts := sdk.ListObjectsOptions{}
+		opts.Prefix, _ = cmd.Flags().GetString("prefix")
+		opts.Delimiter, _ = cmd.Flags().GetString("delimiter")
+		opts.StartAfter, _ = cmd.Flags().GetString("start-after")
+		opts.ContinuationToken, _ = cmd.Flags().GetString("continuation-token")
+		opts.MaxKeys, _ = cmd.Flags().GetInt("max-keys")
+
+		page, err := client.ListObjectsPage(bucket, opts)
 		if err != nil {
 			fmt.Printf(errFmt, err)
 			return
 		}
 
 		if outputJSON {
-			data, _ := json.MarshalIndent(objects, "", "  ")
+			data, _ := json.MarshalIndent(page, "", "  ")
 			fmt.Println(string(data))
 			return
 		}
@@ -74,7 +84,10 @@ var storageListCmd = &cobra.Command{
 		table := tablewriter.NewWriter(os.Stdout)
 		table.Header([]string{"KEY", "SIZE", headerCreatedAt, "ARN"})
 
-		for _, obj := range objects {
+		for _, prefix := range page.CommonPrefixes {
+			_ = table.Append([]string{prefix, "PRE", "", ""})
+		}
+		for _, obj := range page.Objects {
 			_ = table.Append([]string{
 				obj.Key,
 				fmt.Sprintf("%d", obj.SizeBytes),
@@ -83,6 +96,10 @@ var storageListCmd = &cobra.Command{
 			})
 		}
 		_ = table.Render()
+
+		if page.IsTruncated {
+			fmt.Printf("More results available. Continue with --continuation-token %s\n", page.NextContinuationToken)
+		}
 	},
 }
 
@@ -105,8 +122,19 @@ var storageUploadCmd = &cobra.Command{
 		}
 		defer func() { _ = f.Close() }()
 
+		customerKey, err := customerKeyFlag(cmd)
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+
 		client := getClient()
-		obj, err := client.UploadObject(bucket, key, f)
+		var obj *sdk.Object
+		if customerKey != nil {
+			obj, err = client.UploadObjectWithCustomerKey(bucket, key, f, customerKey)
+		} else {
+			obj, err = client.UploadObject(bucket, key, f)
+		}
 		if err != nil {
 			fmt.Printf(errFmt, err)
 			return
@@ -126,11 +154,37 @@ var storageDownloadCmd = &cobra.Command{
 		dest := args[2]
 
 		versionID, _ := cmd.Flags().GetString("version")
+		byteRange, _ := cmd.Flags().GetString("range")
+		customerKey, err := customerKeyFlag(cmd)
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
 
 		client := getClient()
 		var body io.ReadCloser
-		var err error
-		if versionID != "" {
+		if customerKey != nil {
+			if byteRange != "" {
+				fmt.Println("Error: --range cannot be combined with --sse-c-key")
+				return
+			}
+			if versionID != "" {
+				body, err = client.DownloadObjectWithCustomerKey(bucket, key, customerKey, versionID)
+			} else {
+				body, err = client.DownloadObjectWithCustomerKey(bucket, key, customerKey)
+			}
+		} else if byteRange != "" {
+			if versionID != "" {
+				fmt.Println("Error: --range cannot be combined with --version")
+				return
+			}
+			start, end, perr := parseByteRangeFlag(byteRange)
+			if perr != nil {
+				fmt.Printf(errFmt, perr)
+				return
+			}
+			body, err = client.DownloadObjectRange(bucket, key, start, end)
+		} else if versionID != "" {
 			body, err = client.DownloadObject(bucket, key, versionID)
 		} else {
 			body, err = client.DownloadObject(bucket, key)
@@ -158,6 +212,26 @@ var storageDownloadCmd = &cobra.Command{
 	},
 }
 
+// parseByteRangeFlag parses a "START-END" or "START-" range into inclusive offsets; an open end is -1.
+func parseByteRangeFlag(v string) (int64, int64, error) {
+	startStr, endStr, ok := strings.Cut(v, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid range %q, expected START-END or START-", v)
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("invalid range start %q", startStr)
+	}
+	if endStr == "" {
+		return start, -1, nil
+	}
+	end, err := strconv.ParseInt(endStr, 10, 64)
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf("invalid range end %q", endStr)
+	}
+	return start, end, nil
+}
+
 var storageDeleteCmd = &cobra.Command{
 	Use:   "delete [bucket] [key]",
 	Short: "Delete an object from a bucket",
@@ -167,12 +241,20 @@ var storageDeleteCmd = &cobra.Command{
 		key := args[1]
 
 		versionID, _ := cmd.Flags().GetString("version")
+		bypass, _ := cmd.Flags().GetBool("bypass-governance")
+		if bypass && versionID == "" {
+			fmt.Println("--bypass-governance requires --version")
+			return
+		}
 
 		client := getClient()
 		var err error
-		if versionID != "" {
+		switch {
+		case bypass:
+			err = client.DeleteObjectVersionBypassingGovernance(bucket, key, versionID)
+		case versionID != "":
 			err = client.DeleteObject(bucket, key, versionID)
-		} else {
+		default:
 			err = client.DeleteObject(bucket, key)
 		}
 
@@ -270,6 +352,7 @@ func init() {
 	storageCmd.AddCommand(storageDeleteCmd)
 	storageCmd.AddCommand(storageVersionsCmd)
 	storageCmd.AddCommand(storageVersioningCmd)
+	storageCmd.AddCommand(storageClassCmd)
 	storageCmd.AddCommand(createBucketCmd)
 	storageCmd.AddCommand(deleteBucketCmd)
 	storageCmd.AddCommand(storageClusterStatusCmd)
@@ -277,9 +360,20 @@ func init() {
 
 	createBucketCmd.Flags().Bool("public", false, "Make bucket public")
 
+	storageListCmd.Flags().String("prefix", "", "Only list keys starting with this prefix")
+	storageListCmd.Flags().String("delimiter", "", "Group keys into common prefixes up to this delimiter (e.g. /)")
+	storageListCmd.Flags().String("start-after", "", "Start listing after this key")
+	storageListCmd.Flags().String("continuation-token", "", "Resume a truncated listing")
+	storageListCmd.Flags().Int("max-keys", 0, "Maximum entries per page (default 1000)")
 	storageUploadCmd.Flags().String("key", "", "Custom key for the object")
+	storageUploadCmd.Flags().String("sse-c-key", "", "File holding a 32-byte key (raw or base64) to encrypt the object with")
+	storageDownloadCmd.Flags().String("sse-c-key", "", "File holding the 32-byte key (raw or base64) the object was encrypted with")
 	storageDownloadCmd.Flags().String("version", "", "Specific version to download")
+	storageDownloadCmd.Flags().String("range", "", "Download only a byte range, e.g. 0-1023 or 1024-")
 	storageDeleteCmd.Flags().String("version", "", "Specific version to delete")
+	storageDeleteCmd.Flags().Bool("bypass-governance", false, "Delete a version under GOVERNANCE retention")
+	storageClassCmd.Flags().Int("data-shards", 0, "Data shards for erasure coding (default 4)")
+	storageClassCmd.Flags().Int("parity-shards", 0, "Parity shards for erasure coding (default 2)")
 	storagePresignCmd.Flags().String("method", "GET", "HTTP method (GET or PUT)")
 	storagePresignCmd.Flags().Int("expires", 900, "Expiration in seconds (default 15 mins)")
 }
@@ -350,6 +444,43 @@ var storageVersioningCmd = &cobra.Command{
 	},
 }
 
+var storageClassCmd = &cobra.Command{
+	Use:   "storage-class [bucket] [class]",
+	Short: "Set the storage class for new objects in a bucket",
+	Long:  "class can be 'standard' (replicated) or 'erasure' (Reed-Solomon shards)",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		bucket := args[0]
+
+		var class string
+		switch strings.ToLower(args[1]) {
+		case "standard", "replicated":
+			class = sdk.StorageClassStandard
+		case "erasure", "erasure_coded", "ec":
+			class = sdk.StorageClassErasureCoded
+		default:
+			fmt.Printf("Invalid storage class: %s. Use 'standard' or 'erasure'.\n", args[1])
+			return
+		}
+
+		dataShards, _ := cmd.Flags().GetInt("data-shards")
+		parityShards, _ := cmd.Flags().GetInt("parity-shards")
+
+		client := getClient()
+		b, err := client.SetBucketStorageClass(bucket, class, dataShards, parityShards)
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+
+		if b.StorageClass == sdk.StorageClassErasureCoded {
+			fmt.Printf("[SUCCESS] Bucket %s now stores new objects as %d+%d erasure-coded shards\n", bucket, b.DataShards, b.ParityShards)
+			return
+		}
+		fmt.Printf("[SUCCESS] Bucket %s now stores new objects with full replication\n", bucket)
+	},
+}
+
 var storagePresignCmd = &cobra.Command{
 	Use:   "presign [bucket] [key]",
 	Short: "Generate a pre-signed URL for an object",
@@ -63,3 +63,71 @@ func TestStorageVersioningInvalidStatus(t *testing.T) {
 		t.Fatalf("expected invalid status message, got: %s", out)
 	}
 }
+
+func TestStorageClassInvalidClass(t *testing.T) {
+	out := captureStdout(t, func() {
+		storageClassCmd.Run(storageClassCmd, []string{storageTestBucket, "glacier"})
+	})
+	if !strings.Contains(out, "Invalid storage class") {
+		t.Fatalf("expected invalid storage class message, got: %s", out)
+	}
+}
+
+func TestStorageListObjectsWithPrefix(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/storage/"+storageTestBucket || r.URL.Query().Get("prefix") != "photos/" || r.URL.Query().Get("delimiter") != "/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		payload := map[string]interface{}{
+			"data": map[string]interface{}{
+				"bucket":                  storageTestBucket,
+				"objects":                 []map[string]interface{}{{"key": "photos/cat.jpg", "size_bytes": 42, "created_at": time.Now().UTC().Format(time.RFC3339)}},
+				"common_prefixes":         []string{"photos/2024/"},
+				"is_truncated":            true,
+				"next_continuation_token": "next-page",
+			},
+		}
+		_ = json.NewEncoder(w).Encode(payload)
+	}))
+	defer server.Close()
+
+	oldURL := apiURL
+	oldKey := apiKey
+	apiURL = server.URL
+	apiKey = storageTestAPIKey
+	defer func() {
+		apiURL = oldURL
+		apiKey = oldKey
+		_ = storageListCmd.Flags().Set("prefix", "")
+		_ = storageListCmd.Flags().Set("delimiter", "")
+	}()
+
+	_ = storageListCmd.Flags().Set("prefix", "photos/")
+	_ = storageListCmd.Flags().Set("delimiter", "/")
+	out := captureStdout(t, func() {
+		storageListCmd.Run(storageListCmd, []string{storageTestBucket})
+	})
+	for _, want := range []string{"photos/2024/", "photos/cat.jpg", "next-page"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to include %q, got: %s", want, out)
+		}
+	}
+}
+
+func TestParseByteRangeFlag(t *testing.T) {
+	start, end, err := parseByteRangeFlag("10-19")
+	if err != nil || start != 10 || end != 19 {
+		t.Fatalf("unexpected result %d-%d, %v", start, end, err)
+	}
+	start, end, err = parseByteRangeFlag("1024-")
+	if err != nil || start != 1024 || end != -1 {
+		t.Fatalf("unexpected open range %d-%d, %v", start, end, err)
+	}
+	for _, bad := range []string{"", "10", "a-b", "20-10", "-5"} {
+		if _, _, err := parseByteRangeFlag(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCustomerKeyFlag(t *testing.T) {
+	key := strings.Repeat("k", customerKeySize)
+	dir := t.TempDir()
+	raw := filepath.Join(dir, "raw.key")
+	encoded := filepath.Join(dir, "b64.key")
+	short := filepath.Join(dir, "short.key")
+	_ = os.WriteFile(raw, []byte(key), 0o600)
+	_ = os.WriteFile(encoded, []byte(base64.StdEncoding.EncodeToString([]byte(key))+"\n"), 0o600)
+	_ = os.WriteFile(short, []byte("short"), 0o600)
+
+	newCmd := func(path string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("sse-c-key", "", "")
+		_ = cmd.Flags().Set("sse-c-key", path)
+		return cmd
+	}
+
+	for _, path := range []string{raw, encoded} {
+		got, err := customerKeyFlag(newCmd(path))
+		if err != nil || string(got) != key {
+			t.Fatalf("unexpected key from %s: %q, %v", path, got, err)
+		}
+	}
+	if _, err := customerKeyFlag(newCmd(short)); err == nil {
+		t.Fatal("expected error for short key")
+	}
+	if got, err := customerKeyFlag(newCmd("")); got != nil || err != nil {
+		t.Fatalf("expected no key without the flag, got %q, %v", got, err)
+	}
+}
+
+func TestStorageEncryptionRotate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/storage/buckets/vault/encryption/rotate" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var payload map[string]bool
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if !payload["reencrypt"] {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"name": "vault", "encryption_enabled": true},
+		})
+	}))
+	defer server.Close()
+
+	oldURL, oldKey := apiURL, apiKey
+	apiURL, apiKey = server.URL, "encryption-key"
+	defer func() { apiURL, apiKey = oldURL, oldKey }()
+
+	_ = storageEncryptionRotateCmd.Flags().Set("reencrypt", "true")
+	defer func() { _ = storageEncryptionRotateCmd.Flags().Set("reencrypt", "false") }()
+
+	out := captureStdout(t, func() {
+		storageEncryptionRotateCmd.Run(storageEncryptionRotateCmd, []string{"vault"})
+	})
+	if !strings.Contains(out, "being re-encrypted") {
+		t.Fatalf("expected success output, got: %s", out)
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/poyrazk/thecloud/pkg/sdk"
 	"github.com/spf13/cobra"
 )
 
@@ -18,34 +21,55 @@ var lifecycleCmd = &cobra.Command{
 
 var lifecycleSetCmd = &cobra.Command{
 	Use:   "set [bucket]",
-	Short: "Create or update a lifecycle rule (expiration)",
-	Args:  cobra.ExactArgs(1),
+	Short: "Create a lifecycle rule",
+	Long: `Create a lifecycle rule for a bucket. A rule can expire current versions (--days),
+expire noncurrent versions (--noncurrent-days, --keep-versions), move old versions
+to erasure coding (--transition-days) and abort stale multipart uploads
+(--abort-multipart-days). --prefix and --tag limit the objects the rule applies to.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		bucket := args[0]
-		prefix, _ := cmd.Flags().GetString("prefix")
-		days, _ := cmd.Flags().GetInt("days")
-		enabled, _ := cmd.Flags().GetBool("enabled")
+		rule := sdk.LifecycleRule{}
+		rule.Prefix, _ = cmd.Flags().GetString("prefix")
+		rule.ExpirationDays, _ = cmd.Flags().GetInt("days")
+		rule.NoncurrentExpirationDays, _ = cmd.Flags().GetInt("noncurrent-days")
+		rule.NoncurrentVersionsToKeep, _ = cmd.Flags().GetInt("keep-versions")
+		rule.AbortIncompleteMultipartDays, _ = cmd.Flags().GetInt("abort-multipart-days")
+		rule.TransitionDays, _ = cmd.Flags().GetInt("transition-days")
+		rule.Enabled, _ = cmd.Flags().GetBool("enabled")
+		if rule.TransitionDays > 0 {
+			rule.TransitionStorageClass, _ = cmd.Flags().GetString("transition-class")
+		}
+		tagStrs, _ := cmd.Flags().GetStringSlice("tag")
+		tags, err := parseTags(tagStrs)
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+		rule.Tags = tags
 
-		if days < 1 {
-			fmt.Println("Error: --days must be at least 1")
+		otherAction := rule.NoncurrentExpirationDays > 0 || rule.NoncurrentVersionsToKeep > 0 ||
+			rule.AbortIncompleteMultipartDays > 0 || rule.TransitionDays > 0
+		if rule.ExpirationDays < 0 || (rule.ExpirationDays < 1 && !otherAction) {
+			fmt.Println("Error: --days must be at least 1 unless another action is set")
 			return
 		}
 
 		client := getClient()
-		rule, err := client.CreateLifecycleRule(bucket, prefix, days, enabled)
+		created, err := client.PutLifecycleRule(bucket, rule)
 		if err != nil {
 			fmt.Printf(errFmt, err)
 			return
 		}
 
 		if outputJSON {
-			data, _ := json.MarshalIndent(rule, "", "  ")
+			data, _ := json.MarshalIndent(created, "", "  ")
 			fmt.Println(string(data))
 			return
 		}
 
 		fmt.Printf("[SUCCESS] Lifecycle rule created for bucket %s\n", bucket)
-		fmt.Printf("ID: %s\nPrefix: %s\nExpires: %d days\nEnabled: %v\n", rule.ID, rule.Prefix, rule.ExpirationDays, rule.Enabled)
+		fmt.Printf("ID: %s\nPrefix: %s\nActions: %s\nEnabled: %v\n", created.ID, created.Prefix, describeLifecycleActions(created), created.Enabled)
 	},
 }
 
@@ -69,13 +93,14 @@ var lifecycleListCmd = &cobra.Command{
 		}
 
 		table := tablewriter.NewWriter(os.Stdout)
-		table.Header([]string{"ID", "PREFIX", "DAYS", "ENABLED", "CREATED AT"})
+		table.Header([]string{"ID", "PREFIX", "ACTIONS", "ENABLED", "CREATED AT"})
 
-		for _, r := range rules {
+		for i := range rules {
+			r := &rules[i]
 			_ = table.Append([]string{
 				r.ID,
 				r.Prefix,
-				fmt.Sprintf("%d", r.ExpirationDays),
+				describeLifecycleActions(r),
 				fmt.Sprintf("%v", r.Enabled),
 				r.CreatedAt.Format(time.RFC3339),
 			})
@@ -100,13 +125,124 @@ var lifecycleDeleteCmd = &cobra.Command{
 	},
 }
 
+var lifecyclePreviewCmd = &cobra.Command{
+	Use:   "preview [bucket] [rule-id]",
+	Short: "Show what a lifecycle rule would do now, without applying it",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		report, err := client.PreviewLifecycleRule(args[0], args[1])
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+
+		if outputJSON {
+			data, _ := json.MarshalIndent(report, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		fmt.Printf("Expired objects: %d\nExpired noncurrent versions: %d\nTransitioned objects: %d\nAborted uploads: %d\nBytes freed: %d\n",
+			report.ObjectsExpired, report.NoncurrentExpired, report.ObjectsTransitioned, report.UploadsAborted, report.BytesExpired)
+		if len(report.Actions) == 0 {
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"ACTION", "KEY", "VERSION", "SIZE"})
+		for _, a := range report.Actions {
+			version := a.VersionID
+			if a.UploadID != "" {
+				version = a.UploadID
+			}
+			_ = table.Append([]string{a.Type, a.Key, version, fmt.Sprintf("%d", a.SizeBytes)})
+		}
+		_ = table.Render()
+	},
+}
+
+var storageTagCmd = &cobra.Command{
+	Use:   "tag [bucket] [key] [key=value...]",
+	Short: "Replace the tags of an object",
+	Long:  "Replace the tags of an object. Pass no tags to clear them.",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		bucket, key := args[0], args[1]
+		tags, err := parseTags(args[2:])
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+		versionID, _ := cmd.Flags().GetString("version")
+
+		client := getClient()
+		if err := client.SetObjectTags(bucket, key, tags, versionID); err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+		fmt.Printf("[SUCCESS] Set %d tag(s) on %s/%s\n", len(tags), bucket, key)
+	},
+}
+
+// parseTags turns key=value pairs into a tag map.
+func parseTags(pairs []string) (map[string]string, error) {
+	tags := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		parts := strings.SplitN(p, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid tag %q, expected key=value", p)
+		}
+		tags[parts[0]] = parts[1]
+	}
+	return tags, nil
+}
+
+// describeLifecycleActions summarizes the actions and tag filter of a rule.
+func describeLifecycleActions(r *sdk.LifecycleRule) string {
+	var parts []string
+	if r.ExpirationDays > 0 {
+		parts = append(parts, fmt.Sprintf("expire after %dd", r.ExpirationDays))
+	}
+	if r.NoncurrentExpirationDays > 0 {
+		parts = append(parts, fmt.Sprintf("expire noncurrent after %dd", r.NoncurrentExpirationDays))
+	}
+	if r.NoncurrentVersionsToKeep > 0 {
+		parts = append(parts, fmt.Sprintf("keep %d noncurrent", r.NoncurrentVersionsToKeep))
+	}
+	if r.TransitionDays > 0 {
+		parts = append(parts, fmt.Sprintf("%s after %dd", r.TransitionStorageClass, r.TransitionDays))
+	}
+	if r.AbortIncompleteMultipartDays > 0 {
+		parts = append(parts, fmt.Sprintf("abort uploads after %dd", r.AbortIncompleteMultipartDays))
+	}
+	if len(r.Tags) > 0 {
+		tags := make([]string, 0, len(r.Tags))
+		for k, v := range r.Tags {
+			tags = append(tags, k+"="+v)
+		}
+		sort.Strings(tags)
+		parts = append(parts, "tags "+strings.Join(tags, ","))
+	}
+	return strings.Join(parts, "; ")
+}
+
 func init() {
 	storageCmd.AddCommand(lifecycleCmd)
+	storageCmd.AddCommand(storageTagCmd)
 	lifecycleCmd.AddCommand(lifecycleSetCmd)
 	lifecycleCmd.AddCommand(lifecycleListCmd)
 	lifecycleCmd.AddCommand(lifecycleDeleteCmd)
+	lifecycleCmd.AddCommand(lifecyclePreviewCmd)
 
 	lifecycleSetCmd.Flags().String("prefix", "", "Object key prefix")
-	lifecycleSetCmd.Flags().Int("days", 30, "Expiration days")
+	lifecycleSetCmd.Flags().StringSlice("tag", nil, "Only apply to objects carrying this tag (key=value, repeatable)")
+	lifecycleSetCmd.Flags().Int("days", 30, "Expire current versions after this many days (0 to disable)")
+	lifecycleSetCmd.Flags().Int("noncurrent-days", 0, "Delete versions this many days after they become noncurrent")
+	lifecycleSetCmd.Flags().Int("keep-versions", 0, "Always keep this many newest noncurrent versions")
+	lifecycleSetCmd.Flags().Int("transition-days", 0, "Move versions to --transition-class after this many days")
+	lifecycleSetCmd.Flags().String("transition-class", sdk.StorageClassErasureCoded, "Storage class to transition to")
+	lifecycleSetCmd.Flags().Int("abort-multipart-days", 0, "Abort multipart uploads left incomplete for this many days")
 	lifecycleSetCmd.Flags().Bool("enabled", true, "Enable rule immediately")
+	storageTagCmd.Flags().String("version", "", "Specific version to update (default latest)")
 }
@@ -6,6 +6,8 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/spf13/pflag"
 )
 
 const (
@@ -98,3 +100,92 @@ func TestLifecycleDeleteSuccess(t *testing.T) {
 		t.Fatalf("expected success output, got: %s", out)
 	}
 }
+
+func TestLifecycleSetNoncurrentOnly(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/storage/buckets/"+lifecycleTestBucket+"/lifecycle" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set(lifecycleTestContent, lifecycleTestAppJSON)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": lifecycleTestRuleID, "noncurrent_versions_to_keep": 2, "tags": map[string]string{"env": "dev"}},
+		})
+	}))
+	defer server.Close()
+
+	oldURL := apiURL
+	oldKey := apiKey
+	apiURL = server.URL
+	apiKey = lifecycleTestAPIKey
+	defer func() {
+		apiURL = oldURL
+		apiKey = oldKey
+		_ = lifecycleSetCmd.Flags().Set("days", "30")
+		_ = lifecycleSetCmd.Flags().Set("keep-versions", "0")
+		_ = lifecycleSetCmd.Flags().Lookup("tag").Value.(pflag.SliceValue).Replace(nil)
+	}()
+
+	out := captureStdout(t, func() {
+		_ = lifecycleSetCmd.Flags().Set("days", "0")
+		_ = lifecycleSetCmd.Flags().Set("keep-versions", "2")
+		_ = lifecycleSetCmd.Flags().Set("tag", "env=dev")
+		lifecycleSetCmd.Run(lifecycleSetCmd, []string{lifecycleTestBucket})
+	})
+	if !strings.Contains(out, "keep 2 noncurrent; tags env=dev") {
+		t.Fatalf("expected action summary, got: %s", out)
+	}
+	if got["noncurrent_versions_to_keep"] != float64(2) {
+		t.Fatalf("expected keep-versions in request, got: %v", got)
+	}
+	if _, ok := got["transition_storage_class"]; ok {
+		t.Fatalf("transition class sent without transition days: %v", got)
+	}
+}
+
+func TestLifecyclePreview(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/storage/buckets/"+lifecycleTestBucket+"/lifecycle/"+lifecycleTestRuleID+"/preview" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set(lifecycleTestContent, lifecycleTestAppJSON)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"rule_id":         lifecycleTestRuleID,
+				"actions":         []map[string]interface{}{{"type": "EXPIRE", "key": "logs/old.log", "size_bytes": 42}},
+				"objects_expired": 1,
+				"bytes_expired":   42,
+			},
+		})
+	}))
+	defer server.Close()
+
+	oldURL := apiURL
+	oldKey := apiKey
+	apiURL = server.URL
+	apiKey = lifecycleTestAPIKey
+	defer func() {
+		apiURL = oldURL
+		apiKey = oldKey
+	}()
+
+	out := captureStdout(t, func() {
+		lifecyclePreviewCmd.Run(lifecyclePreviewCmd, []string{lifecycleTestBucket, lifecycleTestRuleID})
+	})
+	if !strings.Contains(out, "Expired objects: 1") || !strings.Contains(out, "logs/old.log") {
+		t.Fatalf("expected preview output, got: %s", out)
+	}
+}
+
+func TestStorageTagRejectsMalformedTag(t *testing.T) {
+	out := captureStdout(t, func() {
+		storageTagCmd.Run(storageTagCmd, []string{lifecycleTestBucket, "app.log", "env"})
+	})
+	if !strings.Contains(out, "expected key=value") {
+		t.Fatalf("expected parse error, got: %s", out)
+	}
+}
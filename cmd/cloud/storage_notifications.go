@@ -0,0 +1,128 @@
+// Package main provides the cloud CLI commands.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/poyrazk/thecloud/pkg/sdk"
+	"github.com/spf13/cobra"
+)
+
+var storageNotificationsCmd = &cobra.Command{
+	Use:   "notifications",
+	Short: "Manage bucket event notifications",
+}
+
+var storageNotificationsGetCmd = &cobra.Command{
+	Use:   "get [bucket]",
+	Short: "Show the notification rules of a bucket",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		cfg, err := client.GetBucketNotifications(args[0])
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+
+		if outputJSON {
+			data, _ := json.MarshalIndent(cfg, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"ID", "EVENTS", "FILTER", "TARGET"})
+		for _, rule := range cfg.Rules {
+			_ = table.Append([]string{
+				rule.ID,
+				strings.Join(rule.Events, ", "),
+				describeKeyFilter(rule.Prefix, rule.Suffix),
+				rule.TargetType + " " + rule.TargetID,
+			})
+		}
+		_ = table.Render()
+	},
+}
+
+var storageNotificationsSetCmd = &cobra.Command{
+	Use:   "set [bucket] [config-file]",
+	Short: "Replace a bucket's notification rules with the rules in a JSON file",
+	Long:  "The file holds a JSON array of rules, or an object with a \"rules\" array.",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		bucket := args[0]
+		data, err := os.ReadFile(filepath.Clean(args[1]))
+		if err != nil {
+			fmt.Printf("Error reading notification file: %v\n", err)
+			return
+		}
+
+		rules, err := parseNotificationRules(data)
+		if err != nil {
+			fmt.Printf("Error parsing notification file: %v\n", err)
+			return
+		}
+
+		client := getClient()
+		cfg, err := client.PutBucketNotifications(bucket, rules)
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Configured %d notification rule(s) on bucket %s\n", len(cfg.Rules), bucket)
+	},
+}
+
+var storageNotificationsDeleteCmd = &cobra.Command{
+	Use:   "delete [bucket]",
+	Short: "Remove all notification rules from a bucket",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.DeleteBucketNotifications(args[0]); err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+		fmt.Printf("[SUCCESS] Removed notifications from bucket %s\n", args[0])
+	},
+}
+
+// parseNotificationRules accepts either a bare rule array or a {"rules": [...]} document.
+func parseNotificationRules(data []byte) ([]sdk.BucketNotificationRule, error) {
+	var rules []sdk.BucketNotificationRule
+	if err := json.Unmarshal(data, &rules); err == nil {
+		return rules, nil
+	}
+
+	var doc struct {
+		Rules []sdk.BucketNotificationRule `json:"rules"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Rules) == 0 {
+		return nil, fmt.Errorf("configuration has no rules")
+	}
+	return doc.Rules, nil
+}
+
+func describeKeyFilter(prefix, suffix string) string {
+	if prefix == "" && suffix == "" {
+		return "-"
+	}
+	return prefix + "*" + suffix
+}
+
+func init() {
+	storageCmd.AddCommand(storageNotificationsCmd)
+	storageNotificationsCmd.AddCommand(storageNotificationsGetCmd)
+	storageNotificationsCmd.AddCommand(storageNotificationsSetCmd)
+	storageNotificationsCmd.AddCommand(storageNotificationsDeleteCmd)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const notificationsTestBucket = "ingest"
+
+func TestParseNotificationRules(t *testing.T) {
+	bare := `[{"events":["ObjectCreated:*"],"suffix":".csv","target_type":"QUEUE","target_id":"7f1c1c8e-0f7e-4a4e-9f59-3d2f8d7b1a10"}]`
+	rules, err := parseNotificationRules([]byte(bare))
+	if err != nil || len(rules) != 1 || rules[0].Suffix != ".csv" {
+		t.Fatalf("unexpected result for bare array: %v, %v", rules, err)
+	}
+
+	rules, err = parseNotificationRules([]byte(`{"rules":` + bare + `}`))
+	if err != nil || len(rules) != 1 || rules[0].TargetType != "QUEUE" {
+		t.Fatalf("unexpected result for wrapped document: %v, %v", rules, err)
+	}
+
+	if _, err := parseNotificationRules([]byte(`{"rules":[]}`)); err == nil {
+		t.Fatal("expected error for empty configuration")
+	}
+}
+
+func TestStorageNotificationsSetSendsRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/storage/buckets/"+notificationsTestBucket+"/notifications" || r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"bucket": notificationsTestBucket, "rules": payload["rules"]},
+		})
+	}))
+	defer server.Close()
+
+	oldURL, oldKey := apiURL, apiKey
+	apiURL, apiKey = server.URL, "notify-key"
+	defer func() { apiURL, apiKey = oldURL, oldKey }()
+
+	path := filepath.Join(t.TempDir(), "notifications.json")
+	doc := `{"rules":[{"events":["ObjectRemoved:*"],"target_type":"TOPIC","target_id":"7f1c1c8e-0f7e-4a4e-9f59-3d2f8d7b1a10"}]}`
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		storageNotificationsSetCmd.Run(storageNotificationsSetCmd, []string{notificationsTestBucket, path})
+	})
+	if !strings.Contains(out, "1 notification rule(s)") {
+		t.Fatalf("expected success output, got: %s", out)
+	}
+}
+
+func TestDescribeKeyFilter(t *testing.T) {
+	if got := describeKeyFilter("", ""); got != "-" {
+		t.Fatalf("expected '-', got %q", got)
+	}
+	if got := describeKeyFilter("logs/", ".gz"); got != "logs/*.gz" {
+		t.Fatalf("unexpected filter %q", got)
+	}
+}
@@ -0,0 +1,150 @@
+// Package main provides the cloud CLI commands.
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/poyrazk/thecloud/pkg/sdk"
+	"github.com/spf13/cobra"
+)
+
+var storageObjectLockCmd = &cobra.Command{
+	Use:   "object-lock [bucket]",
+	Short: "Enable object lock on a versioned bucket",
+	Long:  "Enables write-once-read-many protection on a versioned bucket. Object lock cannot be disabled once enabled. Use --mode and --days to retain every new version by default.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mode, _ := cmd.Flags().GetString("mode")
+		days, _ := cmd.Flags().GetInt("days")
+		mode = strings.ToUpper(mode)
+		if mode != "" && !validRetentionMode(mode) {
+			fmt.Printf("Invalid retention mode: %s. Use 'governance' or 'compliance'.\n", mode)
+			return
+		}
+
+		client := getClient()
+		bucket, err := client.SetBucketObjectLock(args[0], sdk.ObjectLockConfig{Enabled: true, DefaultMode: mode, DefaultDays: days})
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+
+		msg := fmt.Sprintf("Enabled object lock on bucket %s", bucket.Name)
+		if bucket.DefaultRetentionMode != "" {
+			msg += fmt.Sprintf(" (default %s retention for %d days)", bucket.DefaultRetentionMode, bucket.DefaultRetentionDays)
+		}
+		fmt.Printf("[SUCCESS] %s\n", msg)
+	},
+}
+
+var storageRetentionCmd = &cobra.Command{
+	Use:   "retention [bucket] [key]",
+	Short: "Set or remove the retention of an object",
+	Long:  "Retains an object until --until (RFC 3339 date or duration such as 720h). --clear removes GOVERNANCE retention and needs --bypass-governance.",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		bucket, key := args[0], args[1]
+		mode, _ := cmd.Flags().GetString("mode")
+		until, _ := cmd.Flags().GetString("until")
+		clearRetention, _ := cmd.Flags().GetBool("clear")
+		versionID, _ := cmd.Flags().GetString("version")
+		bypass, _ := cmd.Flags().GetBool("bypass-governance")
+
+		var retainUntil *time.Time
+		mode = strings.ToUpper(mode)
+		if clearRetention {
+			mode = ""
+		} else {
+			if !validRetentionMode(mode) {
+				fmt.Println("--mode must be 'governance' or 'compliance'")
+				return
+			}
+			t, err := parseRetainUntil(until, time.Now())
+			if err != nil {
+				fmt.Printf("Invalid --until: %v\n", err)
+				return
+			}
+			retainUntil = &t
+		}
+
+		client := getClient()
+		if err := client.PutObjectRetention(bucket, key, mode, retainUntil, versionID, bypass); err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+		if retainUntil == nil {
+			fmt.Printf("[SUCCESS] Removed retention from %s/%s\n", bucket, key)
+			return
+		}
+		fmt.Printf("[SUCCESS] Retained %s/%s in %s mode until %s\n", bucket, key, mode, retainUntil.Format(time.RFC3339))
+	},
+}
+
+var storageLegalHoldCmd = &cobra.Command{
+	Use:   "legal-hold [bucket] [key] [on|off]",
+	Short: "Place or release a legal hold on an object",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		bucket, key := args[0], args[1]
+		var on bool
+		switch strings.ToLower(args[2]) {
+		case "on":
+			on = true
+		case "off":
+		default:
+			fmt.Printf("Invalid legal hold state: %s. Use 'on' or 'off'.\n", args[2])
+			return
+		}
+		versionID, _ := cmd.Flags().GetString("version")
+
+		client := getClient()
+		if err := client.PutObjectLegalHold(bucket, key, on, versionID); err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+		state := "Released legal hold on"
+		if on {
+			state = "Placed legal hold on"
+		}
+		fmt.Printf("[SUCCESS] %s %s/%s\n", state, bucket, key)
+	},
+}
+
+func validRetentionMode(mode string) bool {
+	return mode == sdk.RetentionGovernance || mode == sdk.RetentionCompliance
+}
+
+// parseRetainUntil accepts an RFC 3339 timestamp or a duration relative to now.
+func parseRetainUntil(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("a date or duration is required")
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 date nor a duration", value)
+	}
+	if d <= 0 {
+		return time.Time{}, fmt.Errorf("duration must be positive")
+	}
+	return now.Add(d), nil
+}
+
+func init() {
+	storageCmd.AddCommand(storageObjectLockCmd)
+	storageCmd.AddCommand(storageRetentionCmd)
+	storageCmd.AddCommand(storageLegalHoldCmd)
+
+	storageObjectLockCmd.Flags().String("mode", "", "Default retention mode for new versions (governance or compliance)")
+	storageObjectLockCmd.Flags().Int("days", 0, "Default retention period in days")
+	storageRetentionCmd.Flags().String("mode", "", "Retention mode (governance or compliance)")
+	storageRetentionCmd.Flags().String("until", "", "Retain until this RFC 3339 date or for this duration (e.g. 720h)")
+	storageRetentionCmd.Flags().Bool("clear", false, "Remove GOVERNANCE retention")
+	storageRetentionCmd.Flags().String("version", "", "Specific version to update (default latest)")
+	storageRetentionCmd.Flags().Bool("bypass-governance", false, "Allow shortening or removing GOVERNANCE retention")
+	storageLegalHoldCmd.Flags().String("version", "", "Specific version to update (default latest)")
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRetainUntil(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	got, err := parseRetainUntil("2027-01-01T00:00:00Z", now)
+	if err != nil || !got.Equal(now.AddDate(1, 0, 0)) {
+		t.Fatalf("unexpected result for date: %v, %v", got, err)
+	}
+	got, err = parseRetainUntil("48h", now)
+	if err != nil || !got.Equal(now.Add(48*time.Hour)) {
+		t.Fatalf("unexpected result for duration: %v, %v", got, err)
+	}
+	for _, bad := range []string{"", "-1h", "tomorrow"} {
+		if _, err := parseRetainUntil(bad, now); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestStorageRetentionSendsRequest(t *testing.T) {
+	var payload map[string]interface{}
+	var bypass string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/storage/retention/"+policyTestBucket+"/report.pdf" || r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		bypass = r.Header.Get("X-Bypass-Governance-Retention")
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	oldURL, oldKey := apiURL, apiKey
+	apiURL, apiKey = server.URL, policyTestAPIKey
+	defer func() { apiURL, apiKey = oldURL, oldKey }()
+
+	cmd := storageRetentionCmd
+	_ = cmd.Flags().Set("mode", "compliance")
+	_ = cmd.Flags().Set("until", "2030-01-01T00:00:00Z")
+	_ = cmd.Flags().Set("bypass-governance", "true")
+	defer func() {
+		_ = cmd.Flags().Set("mode", "")
+		_ = cmd.Flags().Set("until", "")
+		_ = cmd.Flags().Set("bypass-governance", "false")
+	}()
+
+	out := captureStdout(t, func() {
+		cmd.Run(cmd, []string{policyTestBucket, "report.pdf"})
+	})
+	if !strings.Contains(out, "COMPLIANCE mode until 2030-01-01T00:00:00Z") {
+		t.Fatalf("expected success output, got: %s", out)
+	}
+	if payload["mode"] != "COMPLIANCE" || payload["retain_until"] != "2030-01-01T00:00:00Z" || bypass != "true" {
+		t.Fatalf("unexpected request: %v (bypass %q)", payload, bypass)
+	}
+}
+
+func TestStorageLegalHoldRejectsUnknownState(t *testing.T) {
+	out := captureStdout(t, func() {
+		storageLegalHoldCmd.Run(storageLegalHoldCmd, []string{policyTestBucket, "report.pdf", "maybe"})
+	})
+	if !strings.Contains(out, "Invalid legal hold state") {
+		t.Fatalf("expected validation error, got: %s", out)
+	}
+}
@@ -0,0 +1,148 @@
+// Package main provides the cloud CLI commands.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/poyrazk/thecloud/pkg/sdk"
+	"github.com/spf13/cobra"
+)
+
+var storagePolicyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Manage bucket access policies",
+}
+
+var storagePolicyGetCmd = &cobra.Command{
+	Use:   "get [bucket]",
+	Short: "Show the policy attached to a bucket",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		policy, err := client.GetBucketPolicy(args[0])
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+
+		if outputJSON {
+			data, _ := json.MarshalIndent(policy, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"EFFECT", "ACTIONS", "RESOURCES", "PRINCIPALS"})
+		for _, st := range policy.Statements {
+			_ = table.Append([]string{
+				st.Effect,
+				strings.Join(st.Action, ", "),
+				strings.Join(st.Resource, ", "),
+				strings.Join(st.Principal, ", "),
+			})
+		}
+		_ = table.Render()
+	},
+}
+
+var storagePolicySetCmd = &cobra.Command{
+	Use:   "set [bucket] [policy-file]",
+	Short: "Replace a bucket's policy with the statements in a JSON file",
+	Long:  "The file holds a JSON array of statements, or an object with a \"statements\" array.",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		bucket := args[0]
+		data, err := os.ReadFile(filepath.Clean(args[1]))
+		if err != nil {
+			fmt.Printf("Error reading policy file: %v\n", err)
+			return
+		}
+
+		statements, err := parsePolicyStatements(data)
+		if err != nil {
+			fmt.Printf("Error parsing policy file: %v\n", err)
+			return
+		}
+
+		client := getClient()
+		policy, err := client.PutBucketPolicy(bucket, statements)
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Attached policy with %d statement(s) to bucket %s\n", len(policy.Statements), bucket)
+	},
+}
+
+var storagePolicyDeleteCmd = &cobra.Command{
+	Use:   "delete [bucket]",
+	Short: "Remove the policy attached to a bucket",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.DeleteBucketPolicy(args[0]); err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+		fmt.Printf("[SUCCESS] Removed policy from bucket %s\n", args[0])
+	},
+}
+
+var storageACLCmd = &cobra.Command{
+	Use:   "acl [bucket] [key] [acl]",
+	Short: "Apply a canned ACL to an object",
+	Long:  "acl can be 'private', 'public-read' or 'authenticated-read'",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		bucket, key, acl := args[0], args[1], args[2]
+		switch acl {
+		case sdk.ObjectACLPrivate, sdk.ObjectACLPublicRead, sdk.ObjectACLAuthenticatedRead:
+		default:
+			fmt.Printf("Invalid ACL: %s. Use 'private', 'public-read' or 'authenticated-read'.\n", acl)
+			return
+		}
+		versionID, _ := cmd.Flags().GetString("version")
+
+		client := getClient()
+		if err := client.SetObjectACL(bucket, key, acl, versionID); err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+		fmt.Printf("[SUCCESS] Set ACL of %s/%s to %s\n", bucket, key, acl)
+	},
+}
+
+// parsePolicyStatements accepts either a bare statement array or a {"statements": [...]} document.
+func parsePolicyStatements(data []byte) ([]sdk.BucketPolicyStatement, error) {
+	var statements []sdk.BucketPolicyStatement
+	if err := json.Unmarshal(data, &statements); err == nil {
+		return statements, nil
+	}
+
+	var doc struct {
+		Statements []sdk.BucketPolicyStatement `json:"statements"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Statements) == 0 {
+		return nil, fmt.Errorf("policy has no statements")
+	}
+	return doc.Statements, nil
+}
+
+func init() {
+	storageCmd.AddCommand(storagePolicyCmd)
+	storageCmd.AddCommand(storageACLCmd)
+	storagePolicyCmd.AddCommand(storagePolicyGetCmd)
+	storagePolicyCmd.AddCommand(storagePolicySetCmd)
+	storagePolicyCmd.AddCommand(storagePolicyDeleteCmd)
+
+	storageACLCmd.Flags().String("version", "", "Specific version to update (default latest)")
+}
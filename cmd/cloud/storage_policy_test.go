@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	policyTestBucket = "assets"
+	policyTestAPIKey = "policy-key"
+)
+
+func TestParsePolicyStatements(t *testing.T) {
+	bare := `[{"effect":"Allow","action":["storage:GetObject"],"resource":["*"],"principal":["*"]}]`
+	statements, err := parsePolicyStatements([]byte(bare))
+	if err != nil || len(statements) != 1 || statements[0].Effect != "Allow" {
+		t.Fatalf("unexpected result for bare array: %v, %v", statements, err)
+	}
+
+	wrapped := `{"statements":` + bare + `}`
+	statements, err = parsePolicyStatements([]byte(wrapped))
+	if err != nil || len(statements) != 1 || statements[0].Principal[0] != "*" {
+		t.Fatalf("unexpected result for wrapped document: %v, %v", statements, err)
+	}
+
+	if _, err := parsePolicyStatements([]byte(`{"statements":[]}`)); err == nil {
+		t.Fatal("expected error for empty policy")
+	}
+	if _, err := parsePolicyStatements([]byte(`not json`)); err == nil {
+		t.Fatal("expected error for invalid json")
+	}
+}
+
+func TestStoragePolicySetSendsStatements(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/storage/buckets/"+policyTestBucket+"/policy" || r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"bucket": policyTestBucket, "statements": payload["statements"]},
+		})
+	}))
+	defer server.Close()
+
+	oldURL, oldKey := apiURL, apiKey
+	apiURL, apiKey = server.URL, policyTestAPIKey
+	defer func() { apiURL, apiKey = oldURL, oldKey }()
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	doc := `[{"effect":"Allow","action":["storage:GetObject"],"resource":["public/*"],"principal":["anonymous"]}]`
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		storagePolicySetCmd.Run(storagePolicySetCmd, []string{policyTestBucket, path})
+	})
+	if !strings.Contains(out, "1 statement(s)") {
+		t.Fatalf("expected success output, got: %s", out)
+	}
+}
+
+func TestStorageACLRejectsUnknownACL(t *testing.T) {
+	out := captureStdout(t, func() {
+		storageACLCmd.Run(storageACLCmd, []string{policyTestBucket, "a.txt", "public-read-write"})
+	})
+	if !strings.Contains(out, "Invalid ACL") {
+		t.Fatalf("expected validation error, got: %s", out)
+	}
+}
@@ -0,0 +1,185 @@
+// Package main provides the cloud CLI commands.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/poyrazk/thecloud/pkg/sdk"
+	"github.com/spf13/cobra"
+)
+
+var storageReplicationCmd = &cobra.Command{
+	Use:   "replication",
+	Short: "Manage bucket replication to other installations",
+}
+
+var storageReplicationGetCmd = &cobra.Command{
+	Use:   "get [bucket]",
+	Short: "Show the replication rules of a bucket",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		cfg, err := client.GetBucketReplication(args[0])
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+
+		if outputJSON {
+			data, _ := json.MarshalIndent(cfg, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"ID", "PREFIX", "DESTINATION", "DELETES"})
+		for _, rule := range cfg.Rules {
+			prefix := rule.Prefix
+			if prefix == "" {
+				prefix = "-"
+			}
+			_ = table.Append([]string{
+				rule.ID,
+				prefix,
+				rule.Destination.Endpoint + " " + rule.Destination.Bucket,
+				strconv.FormatBool(rule.ReplicateDeletes),
+			})
+		}
+		_ = table.Render()
+	},
+}
+
+var storageReplicationSetCmd = &cobra.Command{
+	Use:   "set [bucket] [config-file]",
+	Short: "Replace a bucket's replication rules with the rules in a JSON file",
+	Long: "The file holds a JSON array of rules, or an object with a \"rules\" array.\n" +
+		"A rule without an api_key keeps the key already stored for the same rule id.",
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		bucket := args[0]
+		data, err := os.ReadFile(filepath.Clean(args[1]))
+		if err != nil {
+			fmt.Printf("Error reading replication file: %v\n", err)
+			return
+		}
+
+		rules, err := parseReplicationRules(data)
+		if err != nil {
+			fmt.Printf("Error parsing replication file: %v\n", err)
+			return
+		}
+
+		client := getClient()
+		cfg, err := client.PutBucketReplication(bucket, rules)
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Configured %d replication rule(s) on bucket %s\n", len(cfg.Rules), bucket)
+	},
+}
+
+var storageReplicationDeleteCmd = &cobra.Command{
+	Use:   "delete [bucket]",
+	Short: "Stop replicating a bucket",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.DeleteBucketReplication(args[0]); err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+		fmt.Printf("[SUCCESS] Removed replication from bucket %s\n", args[0])
+	},
+}
+
+var storageReplicationStatusCmd = &cobra.Command{
+	Use:   "status [bucket] [key]",
+	Short: "Show the replication backlog of a bucket, or the replication state of one object",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if len(args) == 2 {
+			printObjectReplication(client, args[0], args[1])
+			return
+		}
+
+		backlog, err := client.GetReplicationBacklog(args[0])
+		if err != nil {
+			fmt.Printf(errFmt, err)
+			return
+		}
+		if outputJSON {
+			data, _ := json.MarshalIndent(backlog, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+		fmt.Printf("Pending: %d\nFailed:  %d\nLag:     %s\n", backlog.Pending, backlog.Failed,
+			(time.Duration(backlog.LagSeconds) * time.Second).String())
+	},
+}
+
+func printObjectReplication(client *sdk.Client, bucket, key string) {
+	reps, err := client.GetObjectReplication(bucket, key)
+	if err != nil {
+		fmt.Printf(errFmt, err)
+		return
+	}
+	if outputJSON {
+		data, _ := json.MarshalIndent(reps, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"VERSION", "RULE", "OPERATION", "STATUS", "ATTEMPTS", "LAST ERROR"})
+	for _, rep := range reps {
+		version := rep.VersionID
+		if version == "" {
+			version = "-"
+		}
+		_ = table.Append([]string{
+			version,
+			rep.RuleID,
+			rep.Operation,
+			rep.Status,
+			strconv.Itoa(rep.Attempts),
+			rep.LastError,
+		})
+	}
+	_ = table.Render()
+}
+
+// parseReplicationRules accepts either a bare rule array or a {"rules": [...]} document.
+func parseReplicationRules(data []byte) ([]sdk.ReplicationRule, error) {
+	var rules []sdk.ReplicationRule
+	if err := json.Unmarshal(data, &rules); err == nil {
+		return rules, nil
+	}
+
+	var doc struct {
+		Rules []sdk.ReplicationRule `json:"rules"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Rules) == 0 {
+		return nil, fmt.Errorf("configuration has no rules")
+	}
+	return doc.Rules, nil
+}
+
+func init() {
+	storageCmd.AddCommand(storageReplicationCmd)
+	storageReplicationCmd.AddCommand(storageReplicationGetCmd)
+	storageReplicationCmd.AddCommand(storageReplicationSetCmd)
+	storageReplicationCmd.AddCommand(storageReplicationDeleteCmd)
+	storageReplicationCmd.AddCommand(storageReplicationStatusCmd)
+}
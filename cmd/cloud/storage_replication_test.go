@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const replicationTestBucket = "photos"
+
+func TestParseReplicationRules(t *testing.T) {
+	bare := `[{"prefix":"raw/","destination":{"endpoint":"https://dr.example.com","bucket":"backup","api_key":"k"},"replicate_deletes":true}]`
+	rules, err := parseReplicationRules([]byte(bare))
+	if err != nil || len(rules) != 1 || !rules[0].ReplicateDeletes || rules[0].Destination.Bucket != "backup" {
+		t.Fatalf("unexpected result for bare array: %v, %v", rules, err)
+	}
+
+	rules, err = parseReplicationRules([]byte(`{"rules":` + bare + `}`))
+	if err != nil || len(rules) != 1 || rules[0].Prefix != "raw/" {
+		t.Fatalf("unexpected result for wrapped document: %v, %v", rules, err)
+	}
+
+	if _, err := parseReplicationRules([]byte(`{"rules":[]}`)); err == nil {
+		t.Fatal("expected error for empty configuration")
+	}
+}
+
+func TestStorageReplicationSetSendsRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/storage/buckets/"+replicationTestBucket+"/replication" || r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"bucket": replicationTestBucket, "rules": payload["rules"]},
+		})
+	}))
+	defer server.Close()
+
+	oldURL, oldKey := apiURL, apiKey
+	apiURL, apiKey = server.URL, "replication-key"
+	defer func() { apiURL, apiKey = oldURL, oldKey }()
+
+	path := filepath.Join(t.TempDir(), "replication.json")
+	doc := `{"rules":[{"destination":{"endpoint":"https://dr.example.com","bucket":"backup","api_key":"k"}}]}`
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		storageReplicationSetCmd.Run(storageReplicationSetCmd, []string{replicationTestBucket, path})
+	})
+	if !strings.Contains(out, "1 replication rule(s)") {
+		t.Fatalf("expected success output, got: %s", out)
+	}
+}
+
+func TestStorageReplicationStatusShowsBacklog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/storage/buckets/"+replicationTestBucket+"/replication/status" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"bucket": replicationTestBucket, "pending": 3, "failed": 1, "lag_seconds": 90},
+		})
+	}))
+	defer server.Close()
+
+	oldURL, oldKey := apiURL, apiKey
+	apiURL, apiKey = server.URL, "replication-key"
+	defer func() { apiURL, apiKey = oldURL, oldKey }()
+
+	out := captureStdout(t, func() {
+		storageReplicationStatusCmd.Run(storageReplicationStatusCmd, []string{replicationTestBucket})
+	})
+	if !strings.Contains(out, "Pending: 3") || !strings.Contains(out, "1m30s") {
+		t.Fatalf("unexpected status output: %s", out)
+	}
+}
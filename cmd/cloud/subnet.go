@@ -37,13 +37,14 @@ var subnetListCmd = &cobra.Command{
 		}
 
 		table := tablewriter.NewWriter(os.Stdout)
-		table.Header([]string{"ID", "NAME", "CIDR", "AZ", "GATEWAY", "STATUS", "CREATED AT"})
+		table.Header([]string{"ID", "NAME", "CIDR", "IPV6 CIDR", "AZ", "GATEWAY", "STATUS", "CREATED AT"})
 
 		for _, s := range subnets {
 			_ = table.Append([]string{
 				s.ID[:8],
 				s.Name,
 				s.CIDRBlock,
+				s.IPv6CIDRBlock,
 				s.AZ,
 				s.GatewayIP,
 				s.Status,
@@ -64,8 +65,9 @@ var subnetCreateCmd = &cobra.Command{
 		name := args[1]
 		cidr := args[2]
 		az, _ := cmd.Flags().GetString("az")
+		ipv6CIDR, _ := cmd.Flags().GetString("ipv6-cidr-block")
 
-		subnet, err := client.CreateSubnet(vpcID, name, cidr, az)
+		subnet, err := client.CreateDualStackSubnet(vpcID, name, cidr, ipv6CIDR, az)
 		if err != nil {
 			fmt.Printf(subnetErrorFormat, err)
 			return
@@ -95,6 +97,7 @@ var subnetDeleteCmd = &cobra.Command{
 
 func init() {
 	subnetCreateCmd.Flags().String("az", "us-east-1a", "Availability zone")
+	subnetCreateCmd.Flags().String("ipv6-cidr-block", "", "Optional IPv6 /64 from the VPC's IPv6 range")
 
 	subnetCmd.AddCommand(subnetListCmd)
 	subnetCmd.AddCommand(subnetCreateCmd)
@@ -35,13 +35,14 @@ var vpcListCmd = &cobra.Command{
 		}
 
 		table := tablewriter.NewWriter(os.Stdout)
-		table.Header([]string{"ID", "NAME", "CIDR", "VXLAN", "STATUS", "CREATED AT"})
+		table.Header([]string{"ID", "NAME", "CIDR", "IPV6 CIDR", "VXLAN", "STATUS", "CREATED AT"})
 
 		for _, v := range vpcs {
 			_ = table.Append([]string{
 				v.ID[:8],
 				v.Name,
 				v.CIDRBlock,
+				v.IPv6CIDRBlock,
 				fmt.Sprintf("%d", v.VXLANID),
 				v.Status,
 				v.CreatedAt.Format("2006-01-02 15:04:05"),
@@ -58,8 +59,9 @@ var vpcCreateCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
 		cidr, _ := cmd.Flags().GetString("cidr-block")
+		ipv6CIDR, _ := cmd.Flags().GetString("ipv6-cidr-block")
 		client := getClient()
-		vpc, err := client.CreateVPC(name, cidr)
+		vpc, err := client.CreateDualStackVPC(name, cidr, ipv6CIDR)
 		if err != nil {
 			fmt.Printf(vpcErrorFormat, err)
 			return
@@ -68,6 +70,9 @@ var vpcCreateCmd = &cobra.Command{
 		fmt.Printf("[SUCCESS] VPC %s created successfully!\n", vpc.Name)
 		fmt.Printf("ID: %s\n", vpc.ID)
 		fmt.Printf("CIDR: %s\n", vpc.CIDRBlock)
+		if vpc.IPv6CIDRBlock != "" {
+			fmt.Printf("IPv6 CIDR: %s\n", vpc.IPv6CIDRBlock)
+		}
 		fmt.Printf("VXLAN ID: %d\n", vpc.VXLANID)
 		fmt.Printf("Network ID: %s\n", vpc.NetworkID)
 	},
@@ -91,6 +96,7 @@ var vpcRmCmd = &cobra.Command{
 
 func init() {
 	vpcCreateCmd.Flags().String("cidr-block", "10.0.0.0/16", "CIDR block for the VPC")
+	vpcCreateCmd.Flags().String("ipv6-cidr-block", "", "Optional IPv6 CIDR block (/64 or larger) to make the VPC dual-stack")
 	vpcCmd.AddCommand(vpcListCmd)
 	vpcCmd.AddCommand(vpcCreateCmd)
 	vpcCmd.AddCommand(vpcRmCmd)
@@ -0,0 +1,194 @@
+// Package main provides the cloud CLI entrypoint.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var igwCmd = &cobra.Command{
+	Use:   "igw",
+	Short: "Manage internet gateways",
+}
+
+var igwListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List internet gateways",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		gateways, err := client.ListInternetGateways()
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		if outputJSON {
+			data, _ := json.MarshalIndent(gateways, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"ID", "NAME", "VPC", "STATUS"})
+
+		for _, g := range gateways {
+			vpc := g.VPCID
+			if vpc == "" {
+				vpc = "-"
+			}
+			_ = table.Append([]string{g.ID, g.Name, vpc, g.Status})
+		}
+		_ = table.Render()
+	},
+}
+
+var igwCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a detached internet gateway",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		igw, err := client.CreateInternetGateway(args[0])
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Internet gateway %s created (%s)\n", igw.Name, igw.ID)
+	},
+}
+
+var igwAttachCmd = &cobra.Command{
+	Use:   "attach [igw-id] [vpc-id]",
+	Short: "Attach an internet gateway to a VPC",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if _, err := client.AttachInternetGateway(args[0], args[1]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Internet gateway %s attached to VPC %s.\n", args[0], args[1])
+	},
+}
+
+var igwDetachCmd = &cobra.Command{
+	Use:   "detach [igw-id]",
+	Short: "Detach an internet gateway from its VPC",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if _, err := client.DetachInternetGateway(args[0]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Internet gateway %s detached.\n", args[0])
+	},
+}
+
+var igwRmCmd = &cobra.Command{
+	Use:   "rm [igw-id]",
+	Short: "Delete a detached internet gateway",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.DeleteInternetGateway(args[0]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Internet gateway %s removed.\n", args[0])
+	},
+}
+
+var natCmd = &cobra.Command{
+	Use:   "nat",
+	Short: "Manage NAT gateways",
+}
+
+var natListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List NAT gateways",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		gateways, err := client.ListNATGateways()
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		if outputJSON {
+			data, _ := json.MarshalIndent(gateways, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"ID", "NAME", "SUBNET", "PRIVATE IP", "PUBLIC IP", "STATUS"})
+
+		for _, g := range gateways {
+			_ = table.Append([]string{g.ID, g.Name, g.SubnetID, g.PrivateIP, g.PublicIP, g.Status})
+		}
+		_ = table.Render()
+	},
+}
+
+var natCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Launch a NAT gateway in a public subnet",
+	Long:  "The subnet's route table must route to an internet gateway, and the Elastic IP must be allocated but not associated.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		subnetID, _ := cmd.Flags().GetString("subnet")
+		eipID, _ := cmd.Flags().GetString("eip")
+
+		client := getClient()
+		nat, err := client.CreateNATGateway(args[0], subnetID, eipID)
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] NAT gateway %s created (%s): %s -> %s\n", nat.Name, nat.ID, nat.PrivateIP, nat.PublicIP)
+	},
+}
+
+var natRmCmd = &cobra.Command{
+	Use:   "rm [nat-id]",
+	Short: "Delete a NAT gateway and free its Elastic IP",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.DeleteNATGateway(args[0]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] NAT gateway %s removed.\n", args[0])
+	},
+}
+
+func init() {
+	natCreateCmd.Flags().String("subnet", "", "Public subnet ID (required)")
+	natCreateCmd.Flags().String("eip", "", "Allocated Elastic IP ID (required)")
+	_ = natCreateCmd.MarkFlagRequired("subnet")
+	_ = natCreateCmd.MarkFlagRequired("eip")
+
+	vpcCmd.AddCommand(igwCmd)
+	igwCmd.AddCommand(igwListCmd)
+	igwCmd.AddCommand(igwCreateCmd)
+	igwCmd.AddCommand(igwAttachCmd)
+	igwCmd.AddCommand(igwDetachCmd)
+	igwCmd.AddCommand(igwRmCmd)
+
+	vpcCmd.AddCommand(natCmd)
+	natCmd.AddCommand(natListCmd)
+	natCmd.AddCommand(natCreateCmd)
+	natCmd.AddCommand(natRmCmd)
+}
@@ -0,0 +1,109 @@
+// Package main provides the cloud CLI entrypoint.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var vpcPeeringCmd = &cobra.Command{
+	Use:   "peering",
+	Short: "Manage peering connections between VPCs",
+}
+
+var vpcPeeringListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List VPC peering connections",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		peerings, err := client.ListVPCPeerings()
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		if outputJSON {
+			data, _ := json.MarshalIndent(peerings, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.Header([]string{"ID", "REQUESTER VPC", "REQUESTER CIDR", "ACCEPTER VPC", "ACCEPTER CIDR", "STATUS"})
+
+		for _, p := range peerings {
+			_ = table.Append([]string{
+				p.ID,
+				p.RequesterVPCID,
+				p.RequesterCIDR,
+				p.AccepterVPCID,
+				p.AccepterCIDR,
+				p.Status,
+			})
+		}
+		_ = table.Render()
+	},
+}
+
+var vpcPeeringRequestCmd = &cobra.Command{
+	Use:   "request [requester-vpc-id] [accepter-vpc-id]",
+	Short: "Request a peering from one of your VPCs to another VPC",
+	Long:  "The accepter VPC may belong to another tenant, named with --accepter-tenant. The peering stays pending until the accepter's tenant accepts it.",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		accepterTenant, _ := cmd.Flags().GetString("accepter-tenant")
+		peering, err := client.CreateVPCPeering(args[0], args[1], accepterTenant)
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Peering %s requested (%s)\n", peering.ID, peering.Status)
+	},
+}
+
+var vpcPeeringAcceptCmd = &cobra.Command{
+	Use:   "accept [peering-id]",
+	Short: "Accept a peering request addressed to one of your VPCs",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		peering, err := client.AcceptVPCPeering(args[0])
+		if err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Peering %s is %s: %s <-> %s\n", peering.ID, peering.Status, peering.RequesterCIDR, peering.AccepterCIDR)
+	},
+}
+
+var vpcPeeringRmCmd = &cobra.Command{
+	Use:   "rm [peering-id]",
+	Short: "Delete a peering connection or reject a pending request",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client := getClient()
+		if err := client.DeleteVPCPeering(args[0]); err != nil {
+			fmt.Printf(vpcErrorFormat, err)
+			return
+		}
+
+		fmt.Printf("[SUCCESS] Peering %s removed.\n", args[0])
+	},
+}
+
+func init() {
+	vpcCmd.AddCommand(vpcPeeringCmd)
+	vpcPeeringCmd.AddCommand(vpcPeeringListCmd)
+	vpcPeeringCmd.AddCommand(vpcPeeringRequestCmd)
+	vpcPeeringCmd.AddCommand(vpcPeeringAcceptCmd)
+	vpcPeeringCmd.AddCommand(vpcPeeringRmCmd)
+
+	vpcPeeringRequestCmd.Flags().String("accepter-tenant", "", "Tenant ID owning the accepter VPC, when it is not your own")
+}
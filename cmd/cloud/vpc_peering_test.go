@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVpcPeeringAccept(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/vpc-peerings/pcx-1/accept" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":             "pcx-1",
+				"status":         "active",
+				"requester_cidr": "10.0.0.0/16",
+				"accepter_cidr":  "10.1.0.0/16",
+			},
+		})
+	}))
+	defer server.Close()
+
+	oldURL, oldKey := apiURL, apiKey
+	apiURL, apiKey = server.URL, "peering-key"
+	defer func() { apiURL, apiKey = oldURL, oldKey }()
+
+	out := captureStdout(t, func() {
+		vpcPeeringAcceptCmd.Run(vpcPeeringAcceptCmd, []string{"pcx-1"})
+	})
+	if !strings.Contains(out, "10.0.0.0/16 <-> 10.1.0.0/16") {
+		t.Fatalf("expected peered CIDRs in output, got: %s", out)
+	}
+}
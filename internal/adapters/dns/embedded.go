@@ -0,0 +1,448 @@
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+)
+
+// Ensure EmbeddedDNSServer implements both DNS backend ports.
+var (
+	_ ports.DNSBackend    = (*EmbeddedDNSServer)(nil)
+	_ ports.GeoDNSBackend = (*EmbeddedDNSServer)(nil)
+	_ ports.VPCDNSBackend = (*EmbeddedDNSServer)(nil)
+)
+
+const (
+	// maxUDPSize is the largest UDP response sent to clients without EDNS0.
+	maxUDPSize = 512
+	// maxEDNSSize caps the UDP payload size a client may advertise.
+	maxEDNSSize = 4096
+	// queryTimeout bounds the repository lookups made for one query.
+	queryTimeout = 2 * time.Second
+	// tcpIdleTimeout closes TCP connections that stop sending queries.
+	tcpIdleTimeout = 10 * time.Second
+)
+
+// EmbeddedDNSServer is an authoritative DNS server that answers straight from
+// the DNSRepository, so CloudDNS works without an external PowerDNS.
+//
+// Private zones belong to a VPC and are only answered on listeners scoped to
+// that VPC (see AddVPCListener); the main listener answers global load
+// balancer hostnames. A VPC's listener is only open while the VPC has a zone. Zone serials, nameservers and geo records are kept in
+// memory: the repository is the source of truth for zone contents, and geo
+// records are rebuilt when a global load balancer next syncs its endpoints.
+type EmbeddedDNSServer struct {
+	repo   ports.DNSRepository
+	addr   string
+	logger *slog.Logger
+
+	mu           sync.RWMutex
+	vpcListeners map[uuid.UUID]string
+	serials      map[string]uint32
+	nameservers  map[string][]string
+	geoRecords   map[string][]string
+
+	// listenMu guards the open VPC listeners and the context they run under,
+	// which is nil until Run starts.
+	listenMu   sync.Mutex
+	runCtx     context.Context
+	vpcServing map[uuid.UUID]context.CancelFunc
+}
+
+// NewEmbeddedDNSServer creates an embedded DNS server listening on addr over UDP and TCP.
+func NewEmbeddedDNSServer(repo ports.DNSRepository, addr string, logger *slog.Logger) *EmbeddedDNSServer {
+	return &EmbeddedDNSServer{
+		repo:         repo,
+		addr:         addr,
+		logger:       logger,
+		vpcListeners: make(map[uuid.UUID]string),
+		serials:      make(map[string]uint32),
+		nameservers:  make(map[string][]string),
+		geoRecords:   make(map[string][]string),
+		vpcServing:   make(map[uuid.UUID]context.CancelFunc),
+	}
+}
+
+// AddVPCListener registers an address on which the VPC's private zone is answered.
+// The listener is opened while the VPC has a zone, see ServeVPCZone.
+func (s *EmbeddedDNSServer) AddVPCListener(vpcID uuid.UUID, addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vpcListeners[vpcID] = addr
+}
+
+// Run serves DNS on the main and VPC-scoped listeners until the context is cancelled.
+func (s *EmbeddedDNSServer) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	if _, err := s.listen(ctx, s.addr, nil); err != nil {
+		s.logger.Error("failed to start embedded DNS server", "addr", s.addr, "error", err)
+		return
+	}
+	s.logger.Info("embedded DNS server started", "addr", s.addr)
+
+	s.mu.RLock()
+	scoped := make([]uuid.UUID, 0, len(s.vpcListeners))
+	for vpcID := range s.vpcListeners {
+		scoped = append(scoped, vpcID)
+	}
+	s.mu.RUnlock()
+
+	s.listenMu.Lock()
+	s.runCtx = ctx
+	for _, vpcID := range scoped {
+		if _, err := s.repo.GetZoneByVPC(ctx, vpcID); err != nil {
+			continue
+		}
+		if err := s.openVPCListener(vpcID); err != nil {
+			s.logger.Error("failed to start VPC DNS listener", "vpc_id", vpcID, "error", err)
+		}
+	}
+	s.listenMu.Unlock()
+
+	<-ctx.Done()
+	s.logger.Info("embedded DNS server stopping")
+}
+
+// ServeVPCZone opens the VPC's listener once the VPC has a private zone.
+// Before Run starts it is a no-op: Run opens listeners for existing zones.
+func (s *EmbeddedDNSServer) ServeVPCZone(ctx context.Context, vpcID uuid.UUID) error {
+	s.listenMu.Lock()
+	defer s.listenMu.Unlock()
+	if s.runCtx == nil || s.runCtx.Err() != nil {
+		return nil
+	}
+	return s.openVPCListener(vpcID)
+}
+
+// StopVPCZone closes the VPC's listener after its private zone is deleted.
+func (s *EmbeddedDNSServer) StopVPCZone(ctx context.Context, vpcID uuid.UUID) error {
+	s.listenMu.Lock()
+	defer s.listenMu.Unlock()
+	if cancel, ok := s.vpcServing[vpcID]; ok {
+		cancel()
+		delete(s.vpcServing, vpcID)
+		s.logger.Info("VPC DNS listener stopped", "vpc_id", vpcID)
+	}
+	return nil
+}
+
+// openVPCListener opens the listener registered for vpcID under the Run
+// context. It must be called with listenMu held.
+func (s *EmbeddedDNSServer) openVPCListener(vpcID uuid.UUID) error {
+	if _, ok := s.vpcServing[vpcID]; ok {
+		return nil
+	}
+	s.mu.RLock()
+	addr, ok := s.vpcListeners[vpcID]
+	s.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no DNS listener address configured for VPC %s", vpcID)
+	}
+
+	ctx, cancel := context.WithCancel(s.runCtx)
+	if _, err := s.listen(ctx, addr, &vpcID); err != nil {
+		cancel()
+		return err
+	}
+	s.vpcServing[vpcID] = cancel
+	s.logger.Info("VPC DNS listener started", "vpc_id", vpcID, "addr", addr)
+	return nil
+}
+
+// listen opens UDP and TCP sockets on addr and serves them until ctx is done.
+// It returns the bound UDP address.
+func (s *EmbeddedDNSServer) listen(ctx context.Context, addr string, vpcID *uuid.UUID) (net.Addr, error) {
+	pc, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on udp %s: %w", addr, err)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		_ = pc.Close()
+		return nil, fmt.Errorf("failed to listen on tcp %s: %w", addr, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = pc.Close()
+		_ = ln.Close()
+	}()
+	go s.serveUDP(ctx, pc, vpcID)
+	go s.serveTCP(ctx, ln, vpcID)
+
+	return pc.LocalAddr(), nil
+}
+
+func (s *EmbeddedDNSServer) serveUDP(ctx context.Context, pc net.PacketConn, vpcID *uuid.UUID) {
+	buf := make([]byte, maxEDNSSize)
+	for {
+		n, peer, err := pc.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+				s.logger.Warn("dns udp read failed", "error", err)
+			}
+			return
+		}
+
+		resp := s.handle(ctx, buf[:n], vpcID, true)
+		if resp == nil {
+			continue
+		}
+		if _, err := pc.WriteTo(resp, peer); err != nil {
+			s.logger.Warn("dns udp write failed", "peer", peer.String(), "error", err)
+		}
+	}
+}
+
+func (s *EmbeddedDNSServer) serveTCP(ctx context.Context, ln net.Listener, vpcID *uuid.UUID) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+				s.logger.Warn("dns tcp accept failed", "error", err)
+			}
+			return
+		}
+		go s.serveTCPConn(ctx, conn, vpcID)
+	}
+}
+
+// serveTCPConn answers length-prefixed queries (RFC 1035 4.2.2) until the client goes idle.
+func (s *EmbeddedDNSServer) serveTCPConn(ctx context.Context, conn net.Conn, vpcID *uuid.UUID) {
+	defer func() { _ = conn.Close() }()
+
+	for {
+		_ = conn.SetDeadline(time.Now().Add(tcpIdleTimeout))
+
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return
+		}
+		query := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, query); err != nil {
+			return
+		}
+
+		resp := s.handle(ctx, query, vpcID, false)
+		if resp == nil {
+			return
+		}
+		binary.BigEndian.PutUint16(length[:], uint16(len(resp)))
+		if _, err := conn.Write(append(length[:], resp...)); err != nil {
+			return
+		}
+	}
+}
+
+// --- ports.DNSBackend ---
+
+// CreateZone starts serving a zone. Its contents are read from the repository.
+func (s *EmbeddedDNSServer) CreateZone(ctx context.Context, zoneName string, nameservers []string) error {
+	zoneName = canonicalName(zoneName)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.serials[zoneName] = initialSerial()
+	if len(nameservers) > 0 {
+		ns := make([]string, len(nameservers))
+		for i, n := range nameservers {
+			ns[i] = canonicalName(n)
+		}
+		s.nameservers[zoneName] = ns
+	}
+
+	s.logger.Info("created zone in embedded DNS", "zone", zoneName)
+	return nil
+}
+
+// DeleteZone forgets the zone's serial and nameservers.
+func (s *EmbeddedDNSServer) DeleteZone(ctx context.Context, zoneName string) error {
+	zoneName = canonicalName(zoneName)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.serials, zoneName)
+	delete(s.nameservers, zoneName)
+
+	s.logger.Info("deleted zone from embedded DNS", "zone", zoneName)
+	return nil
+}
+
+// GetZone returns the zone's current serial.
+func (s *EmbeddedDNSServer) GetZone(ctx context.Context, zoneName string) (*ports.ZoneInfo, error) {
+	zoneName = canonicalName(zoneName)
+	serial := s.serial(zoneName)
+	return &ports.ZoneInfo{
+		Name:           zoneName,
+		Kind:           "Native",
+		Serial:         serial,
+		NotifiedSerial: serial,
+	}, nil
+}
+
+// AddRecords bumps the zone serial. The records themselves are served from the repository.
+func (s *EmbeddedDNSServer) AddRecords(ctx context.Context, zoneName string, records []ports.RecordSet) error {
+	s.bumpSerial(canonicalName(zoneName))
+	return nil
+}
+
+// UpdateRecords bumps the zone serial.
+func (s *EmbeddedDNSServer) UpdateRecords(ctx context.Context, zoneName string, records []ports.RecordSet) error {
+	return s.AddRecords(ctx, zoneName, records)
+}
+
+// DeleteRecords bumps the zone serial.
+func (s *EmbeddedDNSServer) DeleteRecords(ctx context.Context, zoneName, name, recordType string) error {
+	s.bumpSerial(canonicalName(zoneName))
+	return nil
+}
+
+// ReplaceRecordSets bumps the zone serial once for the whole batch.
+func (s *EmbeddedDNSServer) ReplaceRecordSets(ctx context.Context, zoneName string, records []ports.RecordSet) error {
+	s.bumpSerial(canonicalName(zoneName))
+	return nil
+}
+
+// ListRecords returns the record sets served for a zone of the caller's tenant,
+// including the synthesized SOA and NS records.
+func (s *EmbeddedDNSServer) ListRecords(ctx context.Context, zoneName string) ([]ports.RecordSet, error) {
+	zone, err := s.repo.GetZoneByName(ctx, strings.TrimSuffix(zoneName, "."))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zone: %w", err)
+	}
+	records, err := s.repo.ListRecordsByZone(ctx, zone.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zone records: %w", err)
+	}
+
+	apex := canonicalName(zone.Name)
+	results := []ports.RecordSet{
+		{Name: apex, Type: "SOA", TTL: soaTTL, Records: []string{s.soaContent(apex)}},
+		{Name: apex, Type: "NS", TTL: soaTTL, Records: s.zoneNameservers(apex)},
+	}
+
+	index := make(map[string]int)
+	for _, r := range records {
+		if r.Disabled {
+			continue
+		}
+		name := recordFQDN(r.Name, zone.Name)
+		key := name + " " + string(r.Type)
+		i, ok := index[key]
+		if !ok {
+			results = append(results, ports.RecordSet{Name: name, Type: string(r.Type), TTL: r.TTL, Priority: r.Priority})
+			i = len(results) - 1
+			index[key] = i
+		}
+		results[i].Records = append(results[i].Records, r.Content)
+	}
+
+	return results, nil
+}
+
+// --- ports.GeoDNSBackend ---
+
+// CreateGeoRecord serves the healthy IP endpoints of a global load balancer as A records.
+func (s *EmbeddedDNSServer) CreateGeoRecord(ctx context.Context, hostname string, endpoints []domain.GlobalEndpoint) error {
+	var ips []string
+	for _, ep := range endpoints {
+		if ep.Healthy && ep.TargetType == "IP" && ep.TargetIP != nil && *ep.TargetIP != "" {
+			ips = append(ips, *ep.TargetIP)
+		}
+	}
+	if len(ips) == 0 {
+		return s.DeleteGeoRecord(ctx, hostname)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.geoRecords[canonicalName(hostname)] = ips
+	return nil
+}
+
+// DeleteGeoRecord stops serving a global load balancer hostname.
+func (s *EmbeddedDNSServer) DeleteGeoRecord(ctx context.Context, hostname string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.geoRecords, canonicalName(hostname))
+	return nil
+}
+
+// --- zone state ---
+
+// initialSerial seeds zone serials from the clock so they keep increasing across restarts.
+func initialSerial() uint32 {
+	return uint32(time.Now().Unix())
+}
+
+func (s *EmbeddedDNSServer) serial(zoneName string) uint32 {
+	s.mu.RLock()
+	serial, ok := s.serials[zoneName]
+	s.mu.RUnlock()
+	if ok {
+		return serial
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if serial, ok = s.serials[zoneName]; !ok {
+		serial = initialSerial()
+		s.serials[zoneName] = serial
+	}
+	return serial
+}
+
+func (s *EmbeddedDNSServer) bumpSerial(zoneName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	serial, ok := s.serials[zoneName]
+	if !ok {
+		serial = initialSerial()
+	}
+	s.serials[zoneName] = serial + 1
+}
+
+func (s *EmbeddedDNSServer) zoneNameservers(apex string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if ns, ok := s.nameservers[apex]; ok {
+		return ns
+	}
+	// Same defaults DNSService passes to CreateZone.
+	return []string{"ns1." + apex, "ns2." + apex}
+}
+
+func (s *EmbeddedDNSServer) soaContent(apex string) string {
+	return fmt.Sprintf("%s hostmaster.%s %d %d %d %d %d",
+		s.zoneNameservers(apex)[0], apex, s.serial(apex), soaRefresh, soaRetry, soaExpire, soaMinTTL)
+}
+
+// canonicalName lower-cases a DNS name and makes it fully qualified.
+func canonicalName(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
+
+// recordFQDN expands a zone-relative record name; "@" is the zone apex.
+func recordFQDN(name, zoneName string) string {
+	if name == "@" || name == "" {
+		return canonicalName(zoneName)
+	}
+	return canonicalName(name + "." + strings.TrimSuffix(zoneName, "."))
+}
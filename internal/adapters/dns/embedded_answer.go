@@ -0,0 +1,385 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	cerrors "github.com/poyrazk/thecloud/internal/errors"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// SOA timers, matching the SOA PowerDNSBackend creates for new zones.
+const (
+	soaTTL     = 3600
+	soaRefresh = 10800
+	soaRetry   = 3600
+	soaExpire  = 604800
+	soaMinTTL  = 3600
+)
+
+// maxTXTChunk is the longest character-string a TXT record can hold.
+const maxTXTChunk = 255
+
+// handle answers one DNS query. It returns nil when the query is not worth answering.
+func (s *EmbeddedDNSServer) handle(ctx context.Context, query []byte, vpcID *uuid.UUID, udp bool) []byte {
+	var p dnsmessage.Parser
+	hdr, err := p.Start(query)
+	if err != nil || hdr.Response {
+		return nil
+	}
+
+	resp := &dnsmessage.Message{Header: dnsmessage.Header{
+		ID:               hdr.ID,
+		Response:         true,
+		OpCode:           hdr.OpCode,
+		RecursionDesired: hdr.RecursionDesired,
+	}}
+
+	q, err := p.Question()
+	if err != nil {
+		resp.RCode = dnsmessage.RCodeFormatError
+		return s.pack(resp, maxUDPSize, udp)
+	}
+	resp.Questions = []dnsmessage.Question{q}
+	if hdr.OpCode != 0 {
+		resp.RCode = dnsmessage.RCodeNotImplemented
+		return s.pack(resp, maxUDPSize, udp)
+	}
+
+	size := maxUDPSize
+	if p.SkipAllQuestions() == nil && p.SkipAllAnswers() == nil && p.SkipAllAuthorities() == nil {
+		extra, _ := p.AllAdditionals()
+		for _, r := range extra {
+			if r.Header.Type != dnsmessage.TypeOPT {
+				continue
+			}
+			size = min(max(int(r.Header.Class), maxUDPSize), maxEDNSSize)
+			var opt dnsmessage.ResourceHeader
+			_ = opt.SetEDNS0(size, dnsmessage.RCodeSuccess, false)
+			resp.Additionals = append(resp.Additionals, dnsmessage.Resource{Header: opt, Body: &dnsmessage.OPTResource{}})
+			break
+		}
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	s.answer(lookupCtx, resp, q, vpcID)
+
+	return s.pack(resp, size, udp)
+}
+
+// pack serializes a response, truncating UDP answers that exceed the client's buffer.
+func (s *EmbeddedDNSServer) pack(resp *dnsmessage.Message, size int, udp bool) []byte {
+	msg, err := resp.Pack()
+	if err != nil {
+		s.logger.Error("failed to pack dns response", "error", err)
+		resp.RCode = dnsmessage.RCodeServerFailure
+		resp.Answers, resp.Authorities = nil, nil
+		if msg, err = resp.Pack(); err != nil {
+			return nil
+		}
+	}
+	if udp && len(msg) > size {
+		resp.Truncated = true
+		resp.Answers, resp.Authorities = nil, nil
+		if msg, err = resp.Pack(); err != nil {
+			return nil
+		}
+	}
+	return msg
+}
+
+// answer fills in the response: the VPC's private zone first, then global load balancer hostnames.
+func (s *EmbeddedDNSServer) answer(ctx context.Context, resp *dnsmessage.Message, q dnsmessage.Question, vpcID *uuid.UUID) {
+	qname := strings.ToLower(q.Name.String())
+
+	if vpcID != nil {
+		zone, err := s.repo.GetZoneByVPC(ctx, *vpcID)
+		if err != nil && !cerrors.Is(err, cerrors.NotFound) {
+			s.logger.Error("failed to look up vpc dns zone", "vpc_id", *vpcID, "error", err)
+			resp.RCode = dnsmessage.RCodeServerFailure
+			return
+		}
+		if zone != nil && inZone(qname, zone.Name) {
+			records, err := s.repo.ListRecordsByZone(ctx, zone.ID)
+			if err != nil {
+				s.logger.Error("failed to list dns records", "zone", zone.Name, "error", err)
+				resp.RCode = dnsmessage.RCodeServerFailure
+				return
+			}
+			resp.Authoritative = true
+			s.answerFromZone(resp, q, qname, zone, records)
+			return
+		}
+	}
+
+	s.mu.RLock()
+	ips, ok := s.geoRecords[qname]
+	s.mu.RUnlock()
+	if !ok {
+		resp.RCode = dnsmessage.RCodeRefused
+		return
+	}
+
+	resp.Authoritative = true
+	if q.Type != dnsmessage.TypeA && q.Type != dnsmessage.TypeALL {
+		return
+	}
+	for _, ip := range ips {
+		if r, err := toResource(qname, domain.RecordTypeA, MaxDNSRecordTTL, ip, nil); err == nil {
+			resp.Answers = append(resp.Answers, r)
+		}
+	}
+}
+
+// answerFromZone answers a name inside an authoritative zone, following a CNAME
+// once when it points back into the zone.
+func (s *EmbeddedDNSServer) answerFromZone(resp *dnsmessage.Message, q dnsmessage.Question, qname string, zone *domain.DNSZone, records []*domain.DNSRecord) {
+	apex := canonicalName(zone.Name)
+
+	resp.Answers = s.matchRecords(qname, q.Type, apex, zone, records)
+	if len(resp.Answers) == 0 && q.Type != dnsmessage.TypeCNAME {
+		for _, cname := range s.matchRecords(qname, dnsmessage.TypeCNAME, apex, zone, records) {
+			resp.Answers = append(resp.Answers, cname)
+			target := strings.ToLower(cname.Body.(*dnsmessage.CNAMEResource).CNAME.String())
+			if inZone(target, zone.Name) {
+				resp.Answers = append(resp.Answers, s.matchRecords(target, q.Type, apex, zone, records)...)
+			}
+		}
+	}
+	if len(resp.Answers) > 0 {
+		return
+	}
+
+	if !nameExists(qname, apex, zone, records) {
+		resp.RCode = dnsmessage.RCodeNameError
+	}
+	if soa, err := s.soaResource(apex); err == nil {
+		resp.Authorities = []dnsmessage.Resource{soa}
+	}
+}
+
+// matchRecords returns the zone's records for a name and type, including the
+// SOA and NS records synthesized at the apex.
+func (s *EmbeddedDNSServer) matchRecords(name string, qtype dnsmessage.Type, apex string, zone *domain.DNSZone, records []*domain.DNSRecord) []dnsmessage.Resource {
+	var out []dnsmessage.Resource
+
+	if name == apex {
+		if qtype == dnsmessage.TypeSOA || qtype == dnsmessage.TypeALL {
+			if soa, err := s.soaResource(apex); err == nil {
+				out = append(out, soa)
+			}
+		}
+		if qtype == dnsmessage.TypeNS || qtype == dnsmessage.TypeALL {
+			for _, ns := range s.zoneNameservers(apex) {
+				if r, err := nsResource(apex, ns); err == nil {
+					out = append(out, r)
+				}
+			}
+		}
+	}
+
+	for _, rec := range records {
+		if rec.Disabled || recordFQDN(rec.Name, zone.Name) != name {
+			continue
+		}
+		if qtype != dnsmessage.TypeALL && recordTypes[rec.Type] != qtype {
+			continue
+		}
+		r, err := toResource(name, rec.Type, rec.TTL, rec.Content, rec.Priority)
+		if err != nil {
+			s.logger.Warn("skipping malformed dns record", "record_id", rec.ID, "type", rec.Type, "error", err)
+			continue
+		}
+		out = append(out, r)
+	}
+
+	return out
+}
+
+// nameExists reports whether a name owns records or is an empty non-terminal,
+// which decides between NODATA and NXDOMAIN.
+func nameExists(name, apex string, zone *domain.DNSZone, records []*domain.DNSRecord) bool {
+	if name == apex {
+		return true
+	}
+	for _, rec := range records {
+		if rec.Disabled {
+			continue
+		}
+		fqdn := recordFQDN(rec.Name, zone.Name)
+		if fqdn == name || strings.HasSuffix(fqdn, "."+name) {
+			return true
+		}
+	}
+	return false
+}
+
+func inZone(name, zoneName string) bool {
+	apex := canonicalName(zoneName)
+	return name == apex || strings.HasSuffix(name, "."+apex)
+}
+
+var recordTypes = map[domain.RecordType]dnsmessage.Type{
+	domain.RecordTypeA:     dnsmessage.TypeA,
+	domain.RecordTypeAAAA:  dnsmessage.TypeAAAA,
+	domain.RecordTypeCNAME: dnsmessage.TypeCNAME,
+	domain.RecordTypeMX:    dnsmessage.TypeMX,
+	domain.RecordTypeTXT:   dnsmessage.TypeTXT,
+	domain.RecordTypeSRV:   dnsmessage.TypeSRV,
+}
+
+func (s *EmbeddedDNSServer) soaResource(apex string) (dnsmessage.Resource, error) {
+	name, err := dnsmessage.NewName(apex)
+	if err != nil {
+		return dnsmessage.Resource{}, err
+	}
+	ns, err := dnsmessage.NewName(s.zoneNameservers(apex)[0])
+	if err != nil {
+		return dnsmessage.Resource{}, err
+	}
+	mbox, err := dnsmessage.NewName("hostmaster." + apex)
+	if err != nil {
+		return dnsmessage.Resource{}, err
+	}
+
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET, TTL: soaTTL},
+		Body: &dnsmessage.SOAResource{
+			NS: ns, MBox: mbox, Serial: s.serial(apex),
+			Refresh: soaRefresh, Retry: soaRetry, Expire: soaExpire, MinTTL: soaMinTTL,
+		},
+	}, nil
+}
+
+func nsResource(apex, ns string) (dnsmessage.Resource, error) {
+	name, err := dnsmessage.NewName(apex)
+	if err != nil {
+		return dnsmessage.Resource{}, err
+	}
+	target, err := dnsmessage.NewName(canonicalName(ns))
+	if err != nil {
+		return dnsmessage.Resource{}, err
+	}
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name, Type: dnsmessage.TypeNS, Class: dnsmessage.ClassINET, TTL: soaTTL},
+		Body:   &dnsmessage.NSResource{NS: target},
+	}, nil
+}
+
+// toResource converts a stored record into its wire form.
+func toResource(owner string, recordType domain.RecordType, ttl int, content string, priority *int) (dnsmessage.Resource, error) {
+	name, err := dnsmessage.NewName(owner)
+	if err != nil {
+		return dnsmessage.Resource{}, err
+	}
+	header := dnsmessage.ResourceHeader{Name: name, Type: recordTypes[recordType], Class: dnsmessage.ClassINET, TTL: uint32(max(ttl, 0))}
+
+	var body dnsmessage.ResourceBody
+	switch recordType {
+	case domain.RecordTypeA:
+		ip := net.ParseIP(content).To4()
+		if ip == nil {
+			return dnsmessage.Resource{}, fmt.Errorf("invalid IPv4 address %q", content)
+		}
+		a := &dnsmessage.AResource{}
+		copy(a.A[:], ip)
+		body = a
+	case domain.RecordTypeAAAA:
+		ip := net.ParseIP(content)
+		if ip == nil || ip.To4() != nil {
+			return dnsmessage.Resource{}, fmt.Errorf("invalid IPv6 address %q", content)
+		}
+		aaaa := &dnsmessage.AAAAResource{}
+		copy(aaaa.AAAA[:], ip.To16())
+		body = aaaa
+	case domain.RecordTypeCNAME:
+		target, err := dnsmessage.NewName(canonicalName(content))
+		if err != nil {
+			return dnsmessage.Resource{}, err
+		}
+		body = &dnsmessage.CNAMEResource{CNAME: target}
+	case domain.RecordTypeMX:
+		body, err = mxBody(content, priority)
+	case domain.RecordTypeTXT:
+		body = &dnsmessage.TXTResource{TXT: txtChunks(content)}
+	case domain.RecordTypeSRV:
+		body, err = srvBody(content, priority)
+	default:
+		return dnsmessage.Resource{}, fmt.Errorf("unsupported record type %q", recordType)
+	}
+	if err != nil {
+		return dnsmessage.Resource{}, err
+	}
+
+	return dnsmessage.Resource{Header: header, Body: body}, nil
+}
+
+// mxBody accepts "host" with a separate priority, or "preference host".
+func mxBody(content string, priority *int) (dnsmessage.ResourceBody, error) {
+	fields := strings.Fields(content)
+	pref := 0
+	if priority != nil {
+		pref = *priority
+	}
+	if len(fields) == 2 {
+		p, err := strconv.ParseUint(fields[0], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MX preference %q", fields[0])
+		}
+		pref, fields = int(p), fields[1:]
+	}
+	if len(fields) != 1 {
+		return nil, fmt.Errorf("invalid MX content %q", content)
+	}
+	host, err := dnsmessage.NewName(canonicalName(fields[0]))
+	if err != nil {
+		return nil, err
+	}
+	return &dnsmessage.MXResource{Pref: uint16(pref), MX: host}, nil
+}
+
+// srvBody accepts "weight port target" with a separate priority, or
+// "priority weight port target".
+func srvBody(content string, priority *int) (dnsmessage.ResourceBody, error) {
+	fields := strings.Fields(content)
+	if len(fields) == 3 && priority != nil {
+		fields = append([]string{strconv.Itoa(*priority)}, fields...)
+	}
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("invalid SRV content %q", content)
+	}
+
+	var nums [3]uint16
+	for i := range nums {
+		n, err := strconv.ParseUint(fields[i], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SRV content %q", content)
+		}
+		nums[i] = uint16(n)
+	}
+	target, err := dnsmessage.NewName(canonicalName(fields[3]))
+	if err != nil {
+		return nil, err
+	}
+	return &dnsmessage.SRVResource{Priority: nums[0], Weight: nums[1], Port: nums[2], Target: target}, nil
+}
+
+// txtChunks splits TXT content into character-strings of at most 255 bytes.
+func txtChunks(content string) []string {
+	content = strings.TrimSuffix(strings.TrimPrefix(content, `"`), `"`)
+	if content == "" {
+		return []string{""}
+	}
+	var chunks []string
+	for len(content) > maxTXTChunk {
+		chunks = append(chunks, content[:maxTXTChunk])
+		content = content[maxTXTChunk:]
+	}
+	return append(chunks, content)
+}
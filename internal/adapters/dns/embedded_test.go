@@ -0,0 +1,304 @@
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/core/ports/mocks"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const testEmbeddedZone = "myapp.internal"
+
+func intPtr(i int) *int { return &i }
+
+func setupEmbeddedDNS(t *testing.T) (*EmbeddedDNSServer, uuid.UUID, net.Addr, net.Addr) {
+	t.Helper()
+	vpcID := uuid.New()
+	zone := &domain.DNSZone{ID: uuid.New(), VpcID: vpcID, Name: testEmbeddedZone, DefaultTTL: 300}
+	records := []*domain.DNSRecord{
+		{ID: uuid.New(), ZoneID: zone.ID, Name: "web", Type: domain.RecordTypeA, Content: "10.0.1.5", TTL: 300},
+		{ID: uuid.New(), ZoneID: zone.ID, Name: "web", Type: domain.RecordTypeAAAA, Content: "fd00:10:0:1::5", TTL: 300},
+		{ID: uuid.New(), ZoneID: zone.ID, Name: "www", Type: domain.RecordTypeCNAME, Content: "web.myapp.internal", TTL: 300},
+		{ID: uuid.New(), ZoneID: zone.ID, Name: "@", Type: domain.RecordTypeMX, Content: "mail.myapp.internal", TTL: 300, Priority: intPtr(10)},
+		{ID: uuid.New(), ZoneID: zone.ID, Name: "@", Type: domain.RecordTypeTXT, Content: "v=spf1 -all", TTL: 300},
+		{ID: uuid.New(), ZoneID: zone.ID, Name: "_sip._tcp", Type: domain.RecordTypeSRV, Content: "5 5060 sip.myapp.internal", TTL: 300, Priority: intPtr(1)},
+		{ID: uuid.New(), ZoneID: zone.ID, Name: "old", Type: domain.RecordTypeA, Content: "10.0.1.9", TTL: 300, Disabled: true},
+	}
+
+	repo := mocks.NewDNSRepository(t)
+	repo.On("GetZoneByVPC", mock.Anything, vpcID).Return(zone, nil).Maybe()
+	repo.On("GetZoneByVPC", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "dns zone for vpc not found")).Maybe()
+	repo.On("GetZoneByName", mock.Anything, testEmbeddedZone).Return(zone, nil).Maybe()
+	repo.On("ListRecordsByZone", mock.Anything, zone.ID).Return(records, nil).Maybe()
+
+	srv := NewEmbeddedDNSServer(repo, "127.0.0.1:0", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	scoped, err := srv.listen(ctx, "127.0.0.1:0", &vpcID)
+	require.NoError(t, err)
+	global, err := srv.listen(ctx, "127.0.0.1:0", nil)
+	require.NoError(t, err)
+	return srv, vpcID, scoped, global
+}
+
+func buildQuery(t *testing.T, name string, qtype dnsmessage.Type) []byte {
+	t.Helper()
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 42, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName(name), Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	b, err := msg.Pack()
+	require.NoError(t, err)
+	return b
+}
+
+func queryUDP(t *testing.T, addr net.Addr, name string, qtype dnsmessage.Type) dnsmessage.Message {
+	t.Helper()
+	conn, err := net.Dial("udp", addr.String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	_, err = conn.Write(buildQuery(t, name, qtype))
+	require.NoError(t, err)
+	buf := make([]byte, maxEDNSSize)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	var resp dnsmessage.Message
+	require.NoError(t, resp.Unpack(buf[:n]))
+	assert.Equal(t, uint16(42), resp.ID)
+	return resp
+}
+
+func TestEmbeddedDNSAnswersPrivateZone(t *testing.T) {
+	_, _, scoped, _ := setupEmbeddedDNS(t)
+
+	resp := queryUDP(t, scoped, "web.myapp.internal.", dnsmessage.TypeA)
+	assert.Equal(t, dnsmessage.RCodeSuccess, resp.RCode)
+	assert.True(t, resp.Authoritative)
+	require.Len(t, resp.Answers, 1)
+	assert.Equal(t, [4]byte{10, 0, 1, 5}, resp.Answers[0].Body.(*dnsmessage.AResource).A)
+
+	resp = queryUDP(t, scoped, "WEB.myapp.internal.", dnsmessage.TypeAAAA)
+	require.Len(t, resp.Answers, 1)
+	assert.Equal(t, net.ParseIP("fd00:10:0:1::5").To16(), net.IP(resp.Answers[0].Body.(*dnsmessage.AAAAResource).AAAA[:]))
+
+	resp = queryUDP(t, scoped, "www.myapp.internal.", dnsmessage.TypeA)
+	require.Len(t, resp.Answers, 2)
+	assert.Equal(t, "web.myapp.internal.", resp.Answers[0].Body.(*dnsmessage.CNAMEResource).CNAME.String())
+	assert.Equal(t, dnsmessage.TypeA, resp.Answers[1].Header.Type)
+
+	resp = queryUDP(t, scoped, "myapp.internal.", dnsmessage.TypeMX)
+	require.Len(t, resp.Answers, 1)
+	mx := resp.Answers[0].Body.(*dnsmessage.MXResource)
+	assert.Equal(t, uint16(10), mx.Pref)
+	assert.Equal(t, "mail.myapp.internal.", mx.MX.String())
+
+	resp = queryUDP(t, scoped, "myapp.internal.", dnsmessage.TypeTXT)
+	require.Len(t, resp.Answers, 1)
+	assert.Equal(t, []string{"v=spf1 -all"}, resp.Answers[0].Body.(*dnsmessage.TXTResource).TXT)
+
+	resp = queryUDP(t, scoped, "_sip._tcp.myapp.internal.", dnsmessage.TypeSRV)
+	require.Len(t, resp.Answers, 1)
+	srv := resp.Answers[0].Body.(*dnsmessage.SRVResource)
+	assert.Equal(t, dnsmessage.SRVResource{Priority: 1, Weight: 5, Port: 5060, Target: dnsmessage.MustNewName("sip.myapp.internal.")}, *srv)
+
+	resp = queryUDP(t, scoped, "myapp.internal.", dnsmessage.TypeNS)
+	require.Len(t, resp.Answers, 2)
+	assert.Equal(t, "ns1.myapp.internal.", resp.Answers[0].Body.(*dnsmessage.NSResource).NS.String())
+}
+
+func TestEmbeddedDNSNegativeAnswers(t *testing.T) {
+	_, _, scoped, global := setupEmbeddedDNS(t)
+
+	resp := queryUDP(t, scoped, "missing.myapp.internal.", dnsmessage.TypeA)
+	assert.Equal(t, dnsmessage.RCodeNameError, resp.RCode)
+	require.Len(t, resp.Authorities, 1)
+	assert.Equal(t, dnsmessage.TypeSOA, resp.Authorities[0].Header.Type)
+
+	resp = queryUDP(t, scoped, "old.myapp.internal.", dnsmessage.TypeA)
+	assert.Equal(t, dnsmessage.RCodeNameError, resp.RCode, "disabled records are not served")
+
+	resp = queryUDP(t, scoped, "web.myapp.internal.", dnsmessage.TypeMX)
+	assert.Equal(t, dnsmessage.RCodeSuccess, resp.RCode)
+	assert.Empty(t, resp.Answers)
+	assert.Len(t, resp.Authorities, 1)
+
+	resp = queryUDP(t, scoped, "_tcp.myapp.internal.", dnsmessage.TypeA)
+	assert.Equal(t, dnsmessage.RCodeSuccess, resp.RCode, "empty non-terminal is NODATA")
+
+	resp = queryUDP(t, scoped, "example.com.", dnsmessage.TypeA)
+	assert.Equal(t, dnsmessage.RCodeRefused, resp.RCode)
+
+	resp = queryUDP(t, global, "web.myapp.internal.", dnsmessage.TypeA)
+	assert.Equal(t, dnsmessage.RCodeRefused, resp.RCode, "private zones are only served to their VPC")
+}
+
+func TestEmbeddedDNSSerialBumps(t *testing.T) {
+	srv, _, scoped, _ := setupEmbeddedDNS(t)
+	ctx := context.Background()
+
+	require.NoError(t, srv.CreateZone(ctx, testEmbeddedZone+".", []string{"ns1.myapp.internal."}))
+	before, err := srv.GetZone(ctx, testEmbeddedZone)
+	require.NoError(t, err)
+
+	require.NoError(t, srv.AddRecords(ctx, testEmbeddedZone+".", []ports.RecordSet{{Name: "db.myapp.internal.", Type: "A"}}))
+	require.NoError(t, srv.DeleteRecords(ctx, testEmbeddedZone+".", "db.myapp.internal.", "A"))
+	after, err := srv.GetZone(ctx, testEmbeddedZone+".")
+	require.NoError(t, err)
+	assert.Equal(t, before.Serial+2, after.Serial)
+
+	resp := queryUDP(t, scoped, "myapp.internal.", dnsmessage.TypeSOA)
+	require.Len(t, resp.Answers, 1)
+	soa := resp.Answers[0].Body.(*dnsmessage.SOAResource)
+	assert.Equal(t, after.Serial, soa.Serial)
+	assert.Equal(t, "ns1.myapp.internal.", soa.NS.String())
+}
+
+func TestEmbeddedDNSGeoRecords(t *testing.T) {
+	srv, _, _, global := setupEmbeddedDNS(t)
+	ctx := context.Background()
+	ip1, ip2 := "203.0.113.10", "203.0.113.11"
+
+	require.NoError(t, srv.CreateGeoRecord(ctx, "app.example.com", []domain.GlobalEndpoint{
+		{TargetType: "IP", TargetIP: &ip1, Healthy: true},
+		{TargetType: "IP", TargetIP: &ip2, Healthy: false},
+	}))
+	resp := queryUDP(t, global, "app.example.com.", dnsmessage.TypeA)
+	require.Len(t, resp.Answers, 1)
+	assert.Equal(t, uint32(MaxDNSRecordTTL), resp.Answers[0].Header.TTL)
+	assert.Equal(t, [4]byte{203, 0, 113, 10}, resp.Answers[0].Body.(*dnsmessage.AResource).A)
+
+	require.NoError(t, srv.DeleteGeoRecord(ctx, "app.example.com"))
+	resp = queryUDP(t, global, "app.example.com.", dnsmessage.TypeA)
+	assert.Equal(t, dnsmessage.RCodeRefused, resp.RCode)
+}
+
+func TestEmbeddedDNSTCP(t *testing.T) {
+	repo := mocks.NewDNSRepository(t)
+	srv := NewEmbeddedDNSServer(repo, "127.0.0.1:0", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+	go srv.serveTCP(ctx, ln, nil)
+
+	ip := "198.51.100.7"
+	require.NoError(t, srv.CreateGeoRecord(ctx, "api.example.com.", []domain.GlobalEndpoint{{TargetType: "IP", TargetIP: &ip, Healthy: true}}))
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	query := buildQuery(t, "api.example.com.", dnsmessage.TypeA)
+	frame := binary.BigEndian.AppendUint16(nil, uint16(len(query)))
+	_, err = conn.Write(append(frame, query...))
+	require.NoError(t, err)
+
+	var length [2]byte
+	_, err = io.ReadFull(conn, length[:])
+	require.NoError(t, err)
+	buf := make([]byte, binary.BigEndian.Uint16(length[:]))
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+
+	var resp dnsmessage.Message
+	require.NoError(t, resp.Unpack(buf))
+	require.Len(t, resp.Answers, 1)
+	assert.Equal(t, [4]byte{198, 51, 100, 7}, resp.Answers[0].Body.(*dnsmessage.AResource).A)
+}
+
+func TestEmbeddedDNSListRecords(t *testing.T) {
+	srv, _, _, _ := setupEmbeddedDNS(t)
+
+	sets, err := srv.ListRecords(context.Background(), testEmbeddedZone+".")
+	require.NoError(t, err)
+
+	byKey := make(map[string]ports.RecordSet)
+	for _, rs := range sets {
+		byKey[rs.Name+" "+rs.Type] = rs
+	}
+	assert.Contains(t, byKey, "myapp.internal. SOA")
+	assert.Equal(t, []string{"ns1.myapp.internal.", "ns2.myapp.internal."}, byKey["myapp.internal. NS"].Records)
+	assert.Equal(t, []string{"10.0.1.5"}, byKey["web.myapp.internal. A"].Records)
+	assert.NotContains(t, byKey, "old.myapp.internal. A")
+}
+
+func freeUDPAddr(t *testing.T) string {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := pc.LocalAddr().String()
+	require.NoError(t, pc.Close())
+	return addr
+}
+
+func (s *EmbeddedDNSServer) servingVPC(vpcID uuid.UUID) bool {
+	s.listenMu.Lock()
+	defer s.listenMu.Unlock()
+	_, ok := s.vpcServing[vpcID]
+	return ok
+}
+
+func TestEmbeddedDNSVPCListenersFollowZones(t *testing.T) {
+	withZone, withoutZone := uuid.New(), uuid.New()
+	repo := mocks.NewDNSRepository(t)
+	repo.On("GetZoneByVPC", mock.Anything, withZone).Return(&domain.DNSZone{ID: uuid.New(), VpcID: withZone, Name: testEmbeddedZone}, nil)
+	repo.On("GetZoneByVPC", mock.Anything, withoutZone).Return(nil, errors.New(errors.NotFound, "dns zone for vpc not found"))
+
+	srv := NewEmbeddedDNSServer(repo, "127.0.0.1:0", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	addr := freeUDPAddr(t)
+	srv.AddVPCListener(withZone, freeUDPAddr(t))
+	srv.AddVPCListener(withoutZone, addr)
+
+	assert.NoError(t, srv.ServeVPCZone(context.Background(), withoutZone), "no-op before Run")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go srv.Run(ctx, &wg)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	require.Eventually(t, func() bool { return srv.servingVPC(withZone) }, 2*time.Second, 10*time.Millisecond)
+	assert.False(t, srv.servingVPC(withoutZone))
+
+	require.NoError(t, srv.ServeVPCZone(context.Background(), withoutZone))
+	assert.True(t, srv.servingVPC(withoutZone))
+	_, err := net.ListenPacket("udp", addr)
+	assert.Error(t, err, "listener is bound while the VPC has a zone")
+
+	require.NoError(t, srv.StopVPCZone(context.Background(), withoutZone))
+	assert.False(t, srv.servingVPC(withoutZone))
+	assert.Eventually(t, func() bool {
+		pc, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			return false
+		}
+		_ = pc.Close()
+		return true
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Error(t, srv.ServeVPCZone(context.Background(), uuid.New()), "VPC without a configured address")
+}
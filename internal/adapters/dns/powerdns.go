@@ -188,6 +188,55 @@ func (b *PowerDNSBackend) DeleteRecords(ctx context.Context, zoneName, name, rec
 	return nil
 }
 
+// ReplaceRecordSets replaces several record sets in one PATCH, which PowerDNS
+// applies atomically. Record sets without records are deleted.
+func (b *PowerDNSBackend) ReplaceRecordSets(ctx context.Context, zoneName string, records []ports.RecordSet) error {
+	zoneName = b.ensureTrailingDot(zoneName)
+
+	rrsets := make([]map[string]interface{}, len(records))
+	for i, rec := range records {
+		if len(rec.Records) == 0 {
+			rrsets[i] = map[string]interface{}{
+				"name":       b.ensureTrailingDot(rec.Name),
+				"type":       rec.Type,
+				"changetype": "DELETE",
+			}
+			continue
+		}
+
+		recordEntries := make([]map[string]interface{}, len(rec.Records))
+		for j, content := range rec.Records {
+			recordEntries[j] = map[string]interface{}{
+				"content":  content,
+				"disabled": false,
+			}
+		}
+		rrsets[i] = map[string]interface{}{
+			"name":       b.ensureTrailingDot(rec.Name),
+			"type":       rec.Type,
+			"ttl":        rec.TTL,
+			"changetype": "REPLACE",
+			"records":    recordEntries,
+		}
+	}
+
+	resp, err := b.restyClient.R().
+		SetContext(ctx).
+		SetBody(map[string]interface{}{"rrsets": rrsets}).
+		Patch(fmt.Sprintf("servers/%s/zones/%s", b.serverID, zoneName))
+
+	if err != nil {
+		return fmt.Errorf("failed to replace record sets: %w", err)
+	}
+
+	if resp.IsError() {
+		b.logger.Error("PowerDNS API error", "status", resp.StatusCode(), "body", resp.String())
+		return fmt.Errorf("failed to replace record sets: %s", resp.String())
+	}
+
+	return nil
+}
+
 // ListRecords lists all records in a zone.
 func (b *PowerDNSBackend) ListRecords(ctx context.Context, zoneName string) ([]ports.RecordSet, error) {
 	zoneName = b.ensureTrailingDot(zoneName)
@@ -169,6 +169,34 @@ func TestPowerDNSDeleteRecords(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestPowerDNSReplaceRecordSets(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/servers/localhost/zones/"+testPDNSZone, r.URL.Path)
+		assert.Equal(t, "PATCH", r.Method)
+
+		var reqBody map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&reqBody)
+		rrsets := reqBody["rrsets"].([]interface{})
+		assert.Len(t, rrsets, 2)
+		assert.Equal(t, "REPLACE", rrsets[0].(map[string]interface{})["changetype"])
+		assert.Len(t, rrsets[0].(map[string]interface{})["records"], 2)
+		assert.Equal(t, "DELETE", rrsets[1].(map[string]interface{})["changetype"])
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	backend, err := NewPowerDNSBackend(ts.URL, testPDNSKey, "localhost", logger)
+	assert.NoError(t, err)
+
+	err = backend.ReplaceRecordSets(context.Background(), testPDNSZone, []ports.RecordSet{
+		{Name: "www." + testPDNSZone, Type: "A", TTL: 300, Records: []string{"1.1.1.1", "2.2.2.2"}},
+		{Name: "old." + testPDNSZone, Type: "CNAME"},
+	})
+	assert.NoError(t, err)
+}
+
 func TestPowerDNSGetZone(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/api/v1/servers/localhost/zones/"+testPDNSZone, r.URL.Path)
@@ -0,0 +1,77 @@
+// Package replication copies storage objects to buckets on other installations.
+package replication
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/poyrazk/thecloud/internal/core/domain"
+)
+
+// HTTPClient replicates objects through the destination installation's storage API.
+type HTTPClient struct {
+	client *http.Client
+}
+
+// NewHTTPClient creates an HTTPClient whose requests time out after timeout.
+func NewHTTPClient(timeout time.Duration) *HTTPClient {
+	return &HTTPClient{client: &http.Client{Timeout: timeout}}
+}
+
+// PutObject streams the object to the destination bucket, marking it as a replica
+// so that the destination does not replicate it again.
+func (c *HTTPClient) PutObject(ctx context.Context, dest domain.ReplicationDestination, key string, r io.Reader, size int64, contentType string) error {
+	req, err := c.newRequest(ctx, http.MethodPut, dest, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.do(req, false)
+}
+
+// DeleteObject deletes the key from the destination bucket.
+func (c *HTTPClient) DeleteObject(ctx context.Context, dest domain.ReplicationDestination, key string) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, dest, key, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, true)
+}
+
+func (c *HTTPClient) newRequest(ctx context.Context, method string, dest domain.ReplicationDestination, key string, body io.Reader) (*http.Request, error) {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	target := fmt.Sprintf("%s/storage/%s/%s", strings.TrimSuffix(dest.Endpoint, "/"), url.PathEscape(dest.Bucket), strings.Join(segments, "/"))
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build replication request: %w", err)
+	}
+	req.Header.Set("X-API-Key", dest.APIKey)
+	req.Header.Set("X-Object-Replica", "true")
+	return req, nil
+}
+
+func (c *HTTPClient) do(req *http.Request, notFoundOK bool) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("replication request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 300 || (notFoundOK && resp.StatusCode == http.StatusNotFound) {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("destination returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,59 @@
+package replication
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testReplicationKey = "dr-key"
+
+func TestHTTPClientPutObject(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/storage/backup/reports/q1%20final.csv", r.URL.EscapedPath())
+		assert.Equal(t, testReplicationKey, r.Header.Get("X-API-Key"))
+		assert.Equal(t, "true", r.Header.Get("X-Object-Replica"))
+		assert.Equal(t, "text/csv", r.Header.Get("Content-Type"))
+		assert.Equal(t, int64(5), r.ContentLength)
+
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "a,b,c", string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	client := NewHTTPClient(5 * time.Second)
+	dest := domain.ReplicationDestination{Endpoint: ts.URL + "/", Bucket: "backup", APIKey: testReplicationKey}
+	err := client.PutObject(context.Background(), dest, "reports/q1 final.csv", strings.NewReader("a,b,c"), 5, "text/csv")
+	require.NoError(t, err)
+}
+
+func TestHTTPClientErrors(t *testing.T) {
+	status := http.StatusForbidden
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"error":"denied"}`))
+	}))
+	defer ts.Close()
+
+	client := NewHTTPClient(5 * time.Second)
+	dest := domain.ReplicationDestination{Endpoint: ts.URL, Bucket: "backup", APIKey: testReplicationKey}
+
+	err := client.PutObject(context.Background(), dest, "a.txt", strings.NewReader("x"), 1, "")
+	assert.ErrorContains(t, err, "destination returned 403")
+	assert.ErrorContains(t, client.DeleteObject(context.Background(), dest, "a.txt"), "403")
+
+	// Deleting a key the destination never had is not an error.
+	status = http.StatusNotFound
+	assert.NoError(t, client.DeleteObject(context.Background(), dest, "a.txt"))
+	assert.Error(t, client.PutObject(context.Background(), dest, "a.txt", strings.NewReader("x"), 1, ""))
+}
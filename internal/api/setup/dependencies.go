@@ -6,8 +6,11 @@ import (
 	"log/slog"
 
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	dnsadapter "github.com/poyrazk/thecloud/internal/adapters/dns"
+	"github.com/poyrazk/thecloud/internal/adapters/replication"
 	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/poyrazk/thecloud/internal/core/services"
 	"github.com/poyrazk/thecloud/internal/handlers/ws"
@@ -38,6 +41,12 @@ type Repositories struct {
 	Volume        ports.VolumeRepository
 	SecurityGroup ports.SecurityGroupRepository
 	Subnet        ports.SubnetRepository
+	VPCPeering    ports.VPCPeeringRepository
+	RouteTable    ports.RouteTableRepository
+	NetworkACL    ports.NetworkACLRepository
+	FlowLog       ports.FlowLogRepository
+	InternetGW    ports.InternetGatewayRepository
+	NATGateway    ports.NATGatewayRepository
 	LB            ports.LBRepository
 	Snapshot      ports.SnapshotRepository
 	Stack         ports.StackRepository
@@ -64,6 +73,7 @@ type Repositories struct {
 	ElasticIP     ports.ElasticIPRepository
 	Log           ports.LogRepository
 	IAM           ports.IAMRepository
+	Certificate   ports.CertificateRepository
 }
 
 // InitRepositories constructs repositories using the provided database clients.
@@ -81,6 +91,12 @@ func InitRepositories(db postgres.DB, rdb *redisv9.Client) *Repositories {
 		Volume:        postgres.NewVolumeRepository(db),
 		SecurityGroup: postgres.NewSecurityGroupRepository(db),
 		Subnet:        postgres.NewSubnetRepository(db),
+		VPCPeering:    postgres.NewVPCPeeringRepository(db),
+		RouteTable:    postgres.NewRouteTableRepository(db),
+		NetworkACL:    postgres.NewNetworkACLRepository(db),
+		FlowLog:       postgres.NewFlowLogRepository(db),
+		InternetGW:    postgres.NewInternetGatewayRepository(db),
+		NATGateway:    postgres.NewNATGatewayRepository(db),
 		LB:            postgres.NewLBRepository(db),
 		Snapshot:      postgres.NewSnapshotRepository(db),
 		Stack:         postgres.NewStackRepository(db),
@@ -107,6 +123,7 @@ func InitRepositories(db postgres.DB, rdb *redisv9.Client) *Repositories {
 		ElasticIP:     postgres.NewElasticIPRepository(db),
 		Log:           postgres.NewLogRepository(db),
 		IAM:           postgres.NewIAMRepository(db),
+		Certificate:   postgres.NewCertificateRepository(db),
 	}
 }
 
@@ -121,6 +138,11 @@ type Services struct {
 	RBAC          ports.RBACService
 	Vpc           ports.VpcService
 	Subnet        ports.SubnetService
+	VPCPeering    ports.VPCPeeringService
+	RouteTable    ports.RouteTableService
+	NetworkACL    ports.NetworkACLService
+	FlowLog       ports.FlowLogService
+	VPCGateway    ports.VPCGatewayService
 	Event         ports.EventService
 	Volume        ports.VolumeService
 	Instance      ports.InstanceService
@@ -152,6 +174,7 @@ type Services struct {
 	ElasticIP     ports.ElasticIPService
 	Log           ports.LogService
 	IAM           ports.IAMService
+	Certificate   ports.CertificateService
 }
 
 // Workers struct to return background workers
@@ -164,11 +187,20 @@ type Workers struct {
 	Accounting        *workers.AccountingWorker
 	Cluster           *workers.ClusterWorker
 	Lifecycle         *workers.LifecycleWorker
+	StorageEvents     *workers.StorageNotificationWorker
+	Replication       *workers.StorageReplicationWorker
+	Reencryption      *workers.StorageReencryptionWorker
+	ErasureRepair     *workers.ErasureRepairWorker
 	ReplicaMonitor    *workers.ReplicaMonitor
 	ClusterReconciler *workers.ClusterReconciler
 	Healing           *workers.HealingWorker
 	DatabaseFailover  *workers.DatabaseFailoverWorker
 	Log               *workers.LogWorker
+	FlowLog           *workers.FlowLogWorker
+	GlobalLBHealth    *workers.GlobalLBHealthWorker
+	GatewayAccessLog  *workers.GatewayAccessLogWorker
+	CertificateExpiry *workers.CertificateExpiryWorker
+	DNSServer         *dnsadapter.EmbeddedDNSServer
 }
 
 // ServiceConfig holds the dependencies required to initialize services
@@ -202,25 +234,43 @@ func InitServices(c ServiceConfig) (*Services, *Workers, error) {
 	// 3. Cloud Infrastructure Services (VPC, Subnet, Instance, Volume, SG, LB)
 	vpcSvc := services.NewVpcService(c.Repos.Vpc, c.Repos.LB, c.Network, auditSvc, c.Logger, c.Config.DefaultVPCCIDR)
 	subnetSvc := services.NewSubnetService(c.Repos.Subnet, c.Repos.Vpc, auditSvc, c.Logger)
+	peeringSvc := services.NewVPCPeeringService(c.Repos.VPCPeering, c.Repos.Vpc, c.Network, auditSvc, c.Logger)
+	routeTableSvc := services.NewRouteTableService(services.RouteTableServiceParams{
+		Repo: c.Repos.RouteTable, VpcRepo: c.Repos.Vpc, SubnetRepo: c.Repos.Subnet, IGWRepo: c.Repos.InternetGW,
+		NATRepo: c.Repos.NATGateway, Network: c.Network, AuditSvc: auditSvc, Logger: c.Logger,
+	})
+	networkACLSvc := services.NewNetworkACLService(services.NetworkACLServiceParams{
+		Repo: c.Repos.NetworkACL, VpcRepo: c.Repos.Vpc, SubnetRepo: c.Repos.Subnet, Network: c.Network, AuditSvc: auditSvc, Logger: c.Logger,
+	})
+	vpcGatewaySvc := services.NewVPCGatewayService(services.VPCGatewayServiceParams{
+		IGWRepo: c.Repos.InternetGW, NATRepo: c.Repos.NATGateway, RouteRepo: c.Repos.RouteTable, VpcRepo: c.Repos.Vpc,
+		SubnetRepo: c.Repos.Subnet, EIPRepo: c.Repos.ElasticIP, InstanceRepo: c.Repos.Instance, Network: c.Network,
+		AuditSvc: auditSvc, Logger: c.Logger,
+	})
 	volumeSvc := services.NewVolumeService(c.Repos.Volume, c.Storage, eventSvc, auditSvc, c.Logger)
 
 	// DNS Service
-	pdnsBackend, err := dnsadapter.NewPowerDNSBackend(c.Config.PowerDNSAPIURL, c.Config.PowerDNSAPIKey, c.Config.PowerDNSServerID, c.Logger)
+	dnsBackend, dnsServer, err := initDNSBackend(c)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to init powerdns backend: %w", err)
+		return nil, nil, err
 	}
 	dnsSvc := services.NewDNSService(services.DNSServiceParams{
-		Repo: c.Repos.DNS, Backend: pdnsBackend, VpcRepo: c.Repos.Vpc,
+		Repo: c.Repos.DNS, Backend: dnsBackend, VpcRepo: c.Repos.Vpc,
 		AuditSvc: auditSvc, EventSvc: eventSvc, Logger: c.Logger,
 	})
 
 	sshKeySvc := services.NewSSHKeyService(c.Repos.SSHKey)
 
 	logSvc := services.NewCloudLogsService(c.Repos.Log, c.Logger)
+	flowLogSvc := services.NewFlowLogService(services.FlowLogServiceParams{
+		Repo: c.Repos.FlowLog, VpcRepo: c.Repos.Vpc, SubnetRepo: c.Repos.Subnet, InstanceRepo: c.Repos.Instance,
+		Network: c.Network, LogSvc: logSvc, AuditSvc: auditSvc, Logger: c.Logger,
+	})
 
+	sgSvc := services.NewSecurityGroupService(c.Repos.SecurityGroup, c.Repos.Vpc, c.Network, auditSvc, c.Logger)
 	instSvcConcrete := services.NewInstanceService(services.InstanceServiceParams{
 		Repo: c.Repos.Instance, VpcRepo: c.Repos.Vpc, SubnetRepo: c.Repos.Subnet, VolumeRepo: c.Repos.Volume,
-		InstanceTypeRepo: c.Repos.InstanceType,
+		InstanceTypeRepo: c.Repos.InstanceType, NATRepo: c.Repos.NATGateway, SecurityGroupSvc: sgSvc,
 		Compute:          c.Compute, Network: c.Network, EventSvc: eventSvc, AuditSvc: auditSvc, DNSSvc: dnsSvc, TaskQueue: c.Repos.TaskQueue,
 		DockerNetwork:    c.Config.DockerDefaultNetwork,
 		Logger:           c.Logger,
@@ -228,26 +278,27 @@ func InitServices(c ServiceConfig) (*Services, *Workers, error) {
 		SSHKeySvc:        sshKeySvc,
 		LogSvc:           logSvc,
 	})
-	sgSvc := services.NewSecurityGroupService(c.Repos.SecurityGroup, c.Repos.Vpc, c.Network, auditSvc, c.Logger)
-
-	lbSvc := services.NewLBService(c.Repos.LB, c.Repos.Vpc, c.Repos.Instance, auditSvc)
-	lbWorker := services.NewLBWorker(c.Repos.LB, c.Repos.Instance, c.LBProxy)
 
 	// Global LB Service
-	// We use the same PowerDNS backend which now implements GeoDNSBackend
+	// We use the same DNS backend, which also implements GeoDNSBackend
 	glbSvc := services.NewGlobalLBService(services.GlobalLBServiceParams{
-		Repo: c.Repos.GlobalLB, LBRepo: c.Repos.LB, GeoDNS: pdnsBackend, AuditSvc: auditSvc, Logger: c.Logger,
+		Repo: c.Repos.GlobalLB, LBRepo: c.Repos.LB, GeoDNS: dnsBackend, AuditSvc: auditSvc, Logger: c.Logger,
 	})
 
 	// Encryption Service
 	encryptionRepo := postgres.NewEncryptionRepository(c.DB)
-	encryptionSvc, err := services.NewEncryptionService(encryptionRepo, c.Config.SecretsEncryptionKey)
+	masterKey := c.Config.StorageMasterKey
+	if masterKey == "" {
+		masterKey = c.Config.SecretsEncryptionKey
+	}
+	encryptionSvc, err := services.NewEncryptionService(encryptionRepo, masterKey, strings.Split(c.Config.StorageRetiredMasterKeys, ",")...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to init encryption service: %w", err)
 	}
 
 	// 4. Advanced Services (Storage, DB, Secrets, FaaS, Cache, Queue)
-	storageSvc, fileStore, err := initStorageServices(c, auditSvc, encryptionSvc)
+	secretSvc := services.NewSecretService(c.Repos.Secret, eventSvc, auditSvc, c.Logger, c.Config.SecretsEncryptionKey, c.Config.Environment)
+	storageSvc, fileStore, err := initStorageServices(c, auditSvc, encryptionSvc, secretSvc)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -260,16 +311,30 @@ func InitServices(c ServiceConfig) (*Services, *Workers, error) {
 		AuditSvc: auditSvc,
 		Logger:   c.Logger,
 	})
-	secretSvc := services.NewSecretService(c.Repos.Secret, eventSvc, auditSvc, c.Logger, c.Config.SecretsEncryptionKey, c.Config.Environment)
 	fnSvc := services.NewFunctionService(c.Repos.Function, c.Compute, fileStore, auditSvc, c.Logger)
 	cacheSvc := services.NewCacheService(c.Repos.Cache, c.Compute, c.Repos.Vpc, eventSvc, auditSvc, c.Logger)
 	queueSvc := services.NewQueueService(c.Repos.Queue, eventSvc, auditSvc)
 	notifySvc := services.NewNotifyService(c.Repos.Notify, queueSvc, eventSvc, auditSvc, c.Logger)
 
+	certSvc := services.NewCertificateService(services.CertificateServiceParams{
+		Repo: c.Repos.Certificate, SecretSvc: secretSvc, NotifySvc: notifySvc, LBRepo: c.Repos.LB, GatewayRepo: c.Repos.Gateway,
+		AuditSvc: auditSvc, Logger: c.Logger,
+	})
+	lbSvc := services.NewLBService(services.LBServiceParams{
+		LBRepo: c.Repos.LB, VpcRepo: c.Repos.Vpc, InstanceRepo: c.Repos.Instance, CertSvc: certSvc, AuditSvc: auditSvc,
+	})
+	lbWorker := services.NewLBWorker(services.LBWorkerParams{
+		LBRepo: c.Repos.LB, InstanceRepo: c.Repos.Instance, ProxyAdapter: c.LBProxy, CertSvc: certSvc,
+	})
+
 	// 5. DevOps & Automation Services
 	cronSvc := services.NewCronService(c.Repos.Cron, eventSvc, auditSvc)
 	cronWorker := services.NewCronWorker(c.Repos.Cron)
-	gwSvc := services.NewGatewayService(c.Repos.Gateway, auditSvc)
+	gwSvc := services.NewGatewayService(services.GatewayServiceParams{
+		Repo: c.Repos.Gateway, LBRepo: c.Repos.LB, ContainerRepo: c.Repos.Container, InstanceRepo: c.Repos.Instance, FunctionSvc: fnSvc,
+		CertificateSvc: certSvc, TLSPort: c.Config.GatewayTLSPort,
+		LogSvc: logSvc, AuditSvc: auditSvc, Logger: c.Logger,
+	})
 	containerSvc := services.NewContainerService(c.Repos.Container, eventSvc, auditSvc)
 	containerWorker := services.NewContainerWorker(c.Repos.Container, instSvcConcrete, eventSvc)
 	snapshotSvc := services.NewSnapshotService(c.Repos.Snapshot, c.Repos.Volume, c.Storage, eventSvc, auditSvc, c.Logger)
@@ -292,7 +357,7 @@ func InitServices(c ServiceConfig) (*Services, *Workers, error) {
 
 	svcs := &Services{
 		WsHub: wsHub, Audit: auditSvc, Identity: identitySvc, Tenant: tenantSvc, Auth: authSvc, PasswordReset: pwdResetSvc, RBAC: rbacSvc,
-		Vpc: vpcSvc, Subnet: subnetSvc, Event: eventSvc, Volume: volumeSvc, Instance: instSvcConcrete,
+		Vpc: vpcSvc, Subnet: subnetSvc, VPCPeering: peeringSvc, RouteTable: routeTableSvc, NetworkACL: networkACLSvc, VPCGateway: vpcGatewaySvc, Event: eventSvc, Volume: volumeSvc, Instance: instSvcConcrete,
 		SecurityGroup: sgSvc, LB: lbSvc, Snapshot: snapshotSvc, Stack: stackSvc,
 		Storage: storageSvc, Database: databaseSvc, Secret: secretSvc, Function: fnSvc, Cache: cacheSvc,
 		Queue: queueSvc, Notify: notifySvc, Cron: cronSvc, Gateway: gwSvc, Container: containerSvc,
@@ -307,11 +372,15 @@ func InitServices(c ServiceConfig) (*Services, *Workers, error) {
 		ElasticIP: services.NewElasticIPService(services.ElasticIPServiceParams{
 			Repo:         c.Repos.ElasticIP,
 			InstanceRepo: c.Repos.Instance,
+			IGWRepo:      c.Repos.InternetGW,
+			Network:      c.Network,
 			AuditSvc:     auditSvc,
 			Logger:       c.Logger,
 		}),
-		Log: logSvc,
-		IAM: iamSvc,
+		Log:     logSvc,
+		FlowLog: flowLogSvc,
+		IAM:     iamSvc,
+		Certificate: certSvc,
 	}
 
 	// 7. High Availability & Monitoring
@@ -321,17 +390,61 @@ func InitServices(c ServiceConfig) (*Services, *Workers, error) {
 		LB: lbWorker, AutoScaling: asgWorker, Cron: cronWorker, Container: containerWorker,
 		Provision: provisionWorker, Accounting: accountingWorker,
 		Cluster:           workers.NewClusterWorker(c.Repos.Cluster, clusterProvisioner, c.Repos.TaskQueue, c.Logger),
-		Lifecycle:         workers.NewLifecycleWorker(c.Repos.Lifecycle, storageSvc, c.Repos.Storage, c.Logger),
+		Lifecycle:         workers.NewLifecycleWorker(c.Repos.Lifecycle, svcs.Lifecycle, storageSvc, c.Repos.Storage, c.Logger),
+		StorageEvents:     workers.NewStorageNotificationWorker(c.Repos.TaskQueue, queueSvc, notifySvc, fnSvc, c.Logger),
+		Replication:       workers.NewStorageReplicationWorker(c.Repos.Storage, storageSvc, secretSvc, replication.NewHTTPClient(10*time.Minute), c.Logger),
+		Reencryption:      workers.NewStorageReencryptionWorker(c.Repos.TaskQueue, storageSvc, encryptionSvc, c.Logger),
+		ErasureRepair:     workers.NewErasureRepairWorker(storageSvc, c.Logger),
 		ReplicaMonitor:    replicaMonitor,
 		ClusterReconciler: workers.NewClusterReconciler(c.Repos.Cluster, clusterProvisioner, c.Logger),
 		Healing:           healingWorker,
 		DatabaseFailover:  workers.NewDatabaseFailoverWorker(databaseSvc, c.Repos.Database, c.Logger),
 		Log:               workers.NewLogWorker(logSvc, c.Logger),
+		FlowLog:           workers.NewFlowLogWorker(flowLogSvc, c.Logger),
+		GlobalLBHealth:    workers.NewGlobalLBHealthWorker(c.Repos.GlobalLB, c.Repos.LB, dnsBackend, c.Logger),
+		GatewayAccessLog:  workers.NewGatewayAccessLogWorker(gwSvc, c.Logger),
+		CertificateExpiry: workers.NewCertificateExpiryWorker(c.Repos.Certificate, notifySvc, c.Logger),
+		DNSServer:         dnsServer,
 	}
 
 	return svcs, workersCollection, nil
 }
 
+// dnsBackend is a DNS backend that also serves global load balancer records.
+type dnsBackend interface {
+	ports.DNSBackend
+	ports.GeoDNSBackend
+}
+
+// initDNSBackend selects the CloudDNS backend. The embedded server is also
+// returned so it can be run alongside the workers.
+func initDNSBackend(c ServiceConfig) (dnsBackend, *dnsadapter.EmbeddedDNSServer, error) {
+	switch c.Config.DNSBackend {
+	case "embedded":
+		server := dnsadapter.NewEmbeddedDNSServer(c.Repos.DNS, c.Config.DNSListenAddr, c.Logger)
+		for _, pair := range strings.Split(c.Config.DNSVPCListeners, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			vpc, addr, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			vpcID, err := uuid.Parse(vpc)
+			if !ok || err != nil || addr == "" {
+				return nil, nil, fmt.Errorf("invalid DNS VPC listener %q, expected vpc-id=addr", pair)
+			}
+			server.AddVPCListener(vpcID, addr)
+		}
+		return server, server, nil
+	case "powerdns", "":
+		backend, err := dnsadapter.NewPowerDNSBackend(c.Config.PowerDNSAPIURL, c.Config.PowerDNSAPIKey, c.Config.PowerDNSServerID, c.Logger)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to init powerdns backend: %w", err)
+		}
+		return backend, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown DNS backend %q", c.Config.DNSBackend)
+	}
+}
+
 func initIdentityServices(c ServiceConfig, audit ports.AuditService) ports.IdentityService {
 	base := services.NewIdentityService(c.Repos.Identity, audit)
 	return services.NewCachedIdentityService(base, c.RDB, c.Logger)
@@ -344,7 +457,7 @@ func initRBACServices(c ServiceConfig) ports.RBACService {
 	return services.NewCachedRBACService(base, c.RDB, c.Logger)
 }
 
-func initStorageServices(c ServiceConfig, audit ports.AuditService, encryption ports.EncryptionService) (ports.StorageService, ports.FileStore, error) {
+func initStorageServices(c ServiceConfig, audit ports.AuditService, encryption ports.EncryptionService, secrets ports.SecretService) (ports.StorageService, ports.FileStore, error) {
 	var fileStore ports.FileStore
 	var err error
 
@@ -378,7 +491,7 @@ func initStorageServices(c ServiceConfig, audit ports.AuditService, encryption p
 		}
 	}
 
-	storageSvc := services.NewStorageService(c.Repos.Storage, fileStore, audit, encryption, c.Config)
+	storageSvc := services.NewStorageService(c.Repos.Storage, fileStore, audit, encryption, c.Repos.TaskQueue, secrets, c.Config)
 	return storageSvc, fileStore, nil
 }
 
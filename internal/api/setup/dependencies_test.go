@@ -38,3 +38,26 @@ func TestInitRBACServices(t *testing.T) {
 
 	assert.NotNil(t, svc)
 }
+
+func TestInitDNSBackend(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	repos := &Repositories{}
+
+	backend, server, err := initDNSBackend(ServiceConfig{Config: &platform.Config{DNSBackend: "powerdns"}, Repos: repos, Logger: logger})
+	assert.NoError(t, err)
+	assert.NotNil(t, backend)
+	assert.Nil(t, server)
+
+	backend, server, err = initDNSBackend(ServiceConfig{Config: &platform.Config{
+		DNSBackend: "embedded", DNSListenAddr: "127.0.0.1:0", DNSVPCListeners: "3f1c6f4e-3f2a-4d47-9a61-0d6f1f3c2b10=127.0.0.1:0",
+	}, Repos: repos, Logger: logger})
+	assert.NoError(t, err)
+	assert.NotNil(t, backend)
+	assert.NotNil(t, server)
+
+	_, _, err = initDNSBackend(ServiceConfig{Config: &platform.Config{DNSBackend: "embedded", DNSVPCListeners: "not-a-vpc"}, Repos: repos, Logger: logger})
+	assert.Error(t, err)
+
+	_, _, err = initDNSBackend(ServiceConfig{Config: &platform.Config{DNSBackend: "bind"}, Repos: repos, Logger: logger})
+	assert.Error(t, err)
+}
@@ -35,6 +35,11 @@ type Handlers struct {
 	Auth          *httphandlers.AuthHandler
 	Vpc           *httphandlers.VpcHandler
 	Subnet        *httphandlers.SubnetHandler
+	VPCPeering    *httphandlers.VPCPeeringHandler
+	RouteTable    *httphandlers.RouteTableHandler
+	NetworkACL    *httphandlers.NetworkACLHandler
+	FlowLog       *httphandlers.FlowLogHandler
+	VPCGateway    *httphandlers.VPCGatewayHandler
 	Instance      *httphandlers.InstanceHandler
 	Event         *httphandlers.EventHandler
 	Volume        *httphandlers.VolumeHandler
@@ -67,6 +72,7 @@ type Handlers struct {
 	ElasticIP     *httphandlers.ElasticIPHandler
 	Log           *httphandlers.LogHandler
 	IAM           *httphandlers.IAMHandler
+	Certificate   *httphandlers.CertificateHandler
 	Ws            *ws.Handler
 }
 
@@ -82,6 +88,11 @@ func InitHandlers(svcs *Services, cfg *platform.Config, logger *slog.Logger) *Ha
 		Auth:          httphandlers.NewAuthHandler(svcs.Auth, svcs.PasswordReset),
 		Vpc:           httphandlers.NewVpcHandler(svcs.Vpc),
 		Subnet:        httphandlers.NewSubnetHandler(svcs.Subnet),
+		VPCPeering:    httphandlers.NewVPCPeeringHandler(svcs.VPCPeering),
+		RouteTable:    httphandlers.NewRouteTableHandler(svcs.RouteTable),
+		NetworkACL:    httphandlers.NewNetworkACLHandler(svcs.NetworkACL),
+		FlowLog:       httphandlers.NewFlowLogHandler(svcs.FlowLog),
+		VPCGateway:    httphandlers.NewVPCGatewayHandler(svcs.VPCGateway),
 		Instance:      httphandlers.NewInstanceHandler(svcs.Instance),
 		Event:         httphandlers.NewEventHandler(svcs.Event),
 		Volume:        httphandlers.NewVolumeHandler(svcs.Volume),
@@ -114,6 +125,7 @@ func InitHandlers(svcs *Services, cfg *platform.Config, logger *slog.Logger) *Ha
 		ElasticIP:     httphandlers.NewElasticIPHandler(svcs.ElasticIP),
 		Log:           httphandlers.NewLogHandler(svcs.Log),
 		IAM:           httphandlers.NewIAMHandler(svcs.IAM),
+		Certificate:   httphandlers.NewCertificateHandler(svcs.Certificate),
 		Ws:            ws.NewHandler(hub, svcs.Identity, logger),
 	}
 }
@@ -201,6 +213,9 @@ func SetupRouter(cfg *platform.Config, logger *slog.Logger, handlers *Handlers,
 
 		dns.POST("/zones/:id/records", handlers.DNS.CreateRecord)
 		dns.GET("/zones/:id/records", handlers.DNS.ListRecords)
+		dns.POST("/zones/:id/changes", handlers.DNS.ChangeRecords)
+		dns.POST("/zones/:id/import", handlers.DNS.ImportZone)
+		dns.GET("/zones/:id/export", handlers.DNS.ExportZone)
 		dns.GET("/records/:id", handlers.DNS.GetRecord)
 		dns.PUT("/records/:id", handlers.DNS.UpdateRecord)
 		dns.DELETE("/records/:id", handlers.DNS.DeleteRecord)
@@ -209,6 +224,26 @@ func SetupRouter(cfg *platform.Config, logger *slog.Logger, handlers *Handlers,
 	return r
 }
 
+// SetupGatewayRouter builds the router for the gateway's HTTPS listener. It
+// serves only the gateway proxy, so the API itself is never reachable under
+// a route certificate's host names.
+func SetupGatewayRouter(cfg *platform.Config, logger *slog.Logger, handlers *Handlers) *gin.Engine {
+	if cfg.Environment == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	r := gin.New()
+	r.Use(httputil.RequestID())
+	r.Use(httputil.Logger(logger))
+	r.Use(gin.Recovery())
+	r.Use(httputil.SecurityHeadersMiddleware())
+	r.Use(httputil.Metrics())
+
+	r.Any("/gw/*proxy", handlers.Gateway.Proxy)
+
+	return r
+}
+
 func registerIAMRoutes(r *gin.Engine, handlers *Handlers, svcs *Services) {
 	iamGroup := r.Group("/iam")
 	iamGroup.Use(httputil.Auth(svcs.Identity, svcs.Tenant), httputil.RequireTenant(), httputil.Permission(svcs.RBAC, domain.PermissionFullAccess))
@@ -272,6 +307,16 @@ func registerComputeRoutes(r *gin.Engine, handlers *Handlers, svcs *Services) {
 		sshKeyGroup.DELETE("/:id", httputil.Permission(svcs.RBAC, domain.PermissionSSHKeyDelete), handlers.SSHKey.Delete)
 	}
 
+	certGroup := r.Group("/certificates")
+	certGroup.Use(httputil.Auth(svcs.Identity, svcs.Tenant), httputil.RequireTenant(), httputil.TenantMember(svcs.Tenant))
+	{
+		certGroup.POST("", httputil.Permission(svcs.RBAC, domain.PermissionCertificateCreate), handlers.Certificate.Create)
+		certGroup.GET("", httputil.Permission(svcs.RBAC, domain.PermissionCertificateRead), handlers.Certificate.List)
+		certGroup.GET("/ca", httputil.Permission(svcs.RBAC, domain.PermissionCertificateRead), handlers.Certificate.GetAuthority)
+		certGroup.GET("/:id", httputil.Permission(svcs.RBAC, domain.PermissionCertificateRead), handlers.Certificate.Get)
+		certGroup.DELETE("/:id", httputil.Permission(svcs.RBAC, domain.PermissionCertificateDelete), handlers.Certificate.Delete)
+	}
+
 	typeGroup := r.Group("/instance-types")
 	typeGroup.Use(httputil.Auth(svcs.Identity, svcs.Tenant))
 	{
@@ -327,6 +372,70 @@ func registerNetworkRoutes(r *gin.Engine, handlers *Handlers, svcs *Services) {
 
 		vpcGroup.POST("/:id/subnets", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.Subnet.Create)
 		vpcGroup.GET("/:id/subnets", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.Subnet.List)
+
+		vpcGroup.POST("/:id/route-tables", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.RouteTable.Create)
+		vpcGroup.GET("/:id/route-tables", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.RouteTable.List)
+
+		vpcGroup.POST("/:id/network-acls", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.NetworkACL.Create)
+		vpcGroup.GET("/:id/network-acls", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.NetworkACL.List)
+	}
+
+	peeringGroup := r.Group("/vpc-peerings")
+	peeringGroup.Use(httputil.Auth(svcs.Identity, svcs.Tenant), httputil.RequireTenant(), httputil.TenantMember(svcs.Tenant))
+	{
+		peeringGroup.POST("", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.VPCPeering.Create)
+		peeringGroup.GET("", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.VPCPeering.List)
+		peeringGroup.GET("/:id", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.VPCPeering.Get)
+		peeringGroup.POST("/:id/accept", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.VPCPeering.Accept)
+		peeringGroup.DELETE("/:id", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.VPCPeering.Delete)
+	}
+
+	routeTableGroup := r.Group("/route-tables")
+	routeTableGroup.Use(httputil.Auth(svcs.Identity, svcs.Tenant), httputil.RequireTenant(), httputil.TenantMember(svcs.Tenant))
+	{
+		routeTableGroup.GET("/:id", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.RouteTable.Get)
+		routeTableGroup.DELETE("/:id", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.RouteTable.Delete)
+		routeTableGroup.POST("/:id/routes", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.RouteTable.AddRoute)
+		routeTableGroup.DELETE("/:id/routes", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.RouteTable.RemoveRoute)
+		routeTableGroup.POST("/:id/associations", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.RouteTable.Associate)
+	}
+
+	naclGroup := r.Group("/network-acls")
+	naclGroup.Use(httputil.Auth(svcs.Identity, svcs.Tenant), httputil.RequireTenant(), httputil.TenantMember(svcs.Tenant))
+	{
+		naclGroup.GET("/:id", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.NetworkACL.Get)
+		naclGroup.DELETE("/:id", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.NetworkACL.Delete)
+		naclGroup.POST("/:id/rules", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.NetworkACL.AddRule)
+		naclGroup.DELETE("/:id/rules/:rule_id", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.NetworkACL.RemoveRule)
+		naclGroup.POST("/:id/associations", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.NetworkACL.Associate)
+	}
+
+	flowLogGroup := r.Group("/flow-logs")
+	flowLogGroup.Use(httputil.Auth(svcs.Identity, svcs.Tenant), httputil.RequireTenant(), httputil.TenantMember(svcs.Tenant))
+	{
+		flowLogGroup.POST("", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.FlowLog.Create)
+		flowLogGroup.GET("", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.FlowLog.List)
+		flowLogGroup.GET("/:id", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.FlowLog.Get)
+		flowLogGroup.DELETE("/:id", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.FlowLog.Delete)
+	}
+
+	igwGroup := r.Group("/internet-gateways")
+	igwGroup.Use(httputil.Auth(svcs.Identity, svcs.Tenant), httputil.RequireTenant(), httputil.TenantMember(svcs.Tenant))
+	{
+		igwGroup.POST("", httputil.Permission(svcs.RBAC, domain.PermissionVpcCreate), handlers.VPCGateway.CreateInternetGateway)
+		igwGroup.GET("", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.VPCGateway.ListInternetGateways)
+		igwGroup.POST("/:id/attach", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.VPCGateway.AttachInternetGateway)
+		igwGroup.POST("/:id/detach", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.VPCGateway.DetachInternetGateway)
+		igwGroup.DELETE("/:id", httputil.Permission(svcs.RBAC, domain.PermissionVpcDelete), handlers.VPCGateway.DeleteInternetGateway)
+	}
+
+	natGroup := r.Group("/nat-gateways")
+	natGroup.Use(httputil.Auth(svcs.Identity, svcs.Tenant), httputil.RequireTenant(), httputil.TenantMember(svcs.Tenant))
+	{
+		natGroup.POST("", httputil.Permission(svcs.RBAC, domain.PermissionVpcCreate), handlers.VPCGateway.CreateNATGateway)
+		natGroup.GET("", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.VPCGateway.ListNATGateways)
+		natGroup.GET("/:id", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.VPCGateway.GetNATGateway)
+		natGroup.DELETE("/:id", httputil.Permission(svcs.RBAC, domain.PermissionVpcDelete), handlers.VPCGateway.DeleteNATGateway)
 	}
 
 	subnetGroup := r.Group("/subnets")
@@ -334,6 +443,9 @@ func registerNetworkRoutes(r *gin.Engine, handlers *Handlers, svcs *Services) {
 	{
 		subnetGroup.GET("/:id", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.Subnet.Get)
 		subnetGroup.DELETE("/:id", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.Subnet.Delete)
+		subnetGroup.GET("/:id/route-table", httputil.Permission(svcs.RBAC, domain.PermissionVpcRead), handlers.RouteTable.GetForSubnet)
+		subnetGroup.DELETE("/:id/route-table", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.RouteTable.Disassociate)
+		subnetGroup.DELETE("/:id/network-acl", httputil.Permission(svcs.RBAC, domain.PermissionVpcUpdate), handlers.NetworkACL.Disassociate)
 	}
 
 	sgGroup := r.Group("/security-groups")
@@ -359,6 +471,10 @@ func registerNetworkRoutes(r *gin.Engine, handlers *Handlers, svcs *Services) {
 		lbGroup.POST("/:id/targets", httputil.Permission(svcs.RBAC, domain.PermissionLbUpdate), handlers.LB.AddTarget)
 		lbGroup.GET("/:id/targets", httputil.Permission(svcs.RBAC, domain.PermissionLbRead), handlers.LB.ListTargets)
 		lbGroup.DELETE("/:id/targets/:instanceId", httputil.Permission(svcs.RBAC, domain.PermissionLbUpdate), handlers.LB.RemoveTarget)
+		lbGroup.PUT("/:id/tls", httputil.Permission(svcs.RBAC, domain.PermissionLbUpdate), handlers.LB.ConfigureTLS)
+		lbGroup.DELETE("/:id/tls", httputil.Permission(svcs.RBAC, domain.PermissionLbUpdate), handlers.LB.DisableTLS)
+		lbGroup.PUT("/:id/listeners", httputil.Permission(svcs.RBAC, domain.PermissionLbUpdate), handlers.LB.ConfigureListeners)
+		lbGroup.PUT("/:id/attributes", httputil.Permission(svcs.RBAC, domain.PermissionLbUpdate), handlers.LB.ConfigureAttributes)
 	}
 
 	eipGroup := r.Group("/elastic-ips")
@@ -383,6 +499,7 @@ func registerGlobalLBRoutes(r *gin.Engine, handlers *Handlers, svcs *Services) {
 		glbGroup.DELETE("/:id", httputil.Permission(svcs.RBAC, domain.PermissionLbDelete), handlers.GlobalLB.Delete)
 		glbGroup.POST("/:id/endpoints", httputil.Permission(svcs.RBAC, domain.PermissionLbUpdate), handlers.GlobalLB.AddEndpoint)
 		glbGroup.DELETE("/:id/endpoints/:epID", httputil.Permission(svcs.RBAC, domain.PermissionLbUpdate), handlers.GlobalLB.RemoveEndpoint)
+		glbGroup.GET("/:id/endpoints/:epID/health", httputil.Permission(svcs.RBAC, domain.PermissionLbRead), handlers.GlobalLB.GetEndpointHealth)
 	}
 }
 
@@ -398,11 +515,33 @@ func registerDataRoutes(r *gin.Engine, handlers *Handlers, svcs *Services) {
 		storageGroup.GET("/buckets", handlers.Storage.ListBuckets)
 		storageGroup.DELETE("/buckets/:bucket", handlers.Storage.DeleteBucket)
 		storageGroup.PATCH("/buckets/:bucket/versioning", handlers.Storage.SetBucketVersioning)
+		storageGroup.PATCH("/buckets/:bucket/storage-class", handlers.Storage.SetBucketStorageClass)
+
+		// Access Control
+		storageGroup.GET("/buckets/:bucket/policy", handlers.Storage.GetBucketPolicy)
+		storageGroup.PUT("/buckets/:bucket/policy", handlers.Storage.PutBucketPolicy)
+		storageGroup.DELETE("/buckets/:bucket/policy", handlers.Storage.DeleteBucketPolicy)
+		storageGroup.GET("/buckets/:bucket/notifications", handlers.Storage.GetBucketNotifications)
+		storageGroup.PUT("/buckets/:bucket/notifications", handlers.Storage.PutBucketNotifications)
+		storageGroup.DELETE("/buckets/:bucket/notifications", handlers.Storage.DeleteBucketNotifications)
+		storageGroup.PUT("/acl"+bucketKeyRoute, handlers.Storage.SetObjectACL)
+		storageGroup.PUT("/tags"+bucketKeyRoute, handlers.Storage.SetObjectTags)
+		storageGroup.PUT("/buckets/:bucket/object-lock", handlers.Storage.SetBucketObjectLock)
+		storageGroup.PUT("/buckets/:bucket/encryption", handlers.Storage.SetBucketEncryption)
+		storageGroup.POST("/buckets/:bucket/encryption/rotate", handlers.Storage.RotateBucketKey)
+		storageGroup.PUT("/retention"+bucketKeyRoute, handlers.Storage.PutObjectRetention)
+		storageGroup.PUT("/legal-hold"+bucketKeyRoute, handlers.Storage.PutObjectLegalHold)
+		storageGroup.GET("/buckets/:bucket/replication", handlers.Storage.GetBucketReplication)
+		storageGroup.PUT("/buckets/:bucket/replication", handlers.Storage.PutBucketReplication)
+		storageGroup.DELETE("/buckets/:bucket/replication", handlers.Storage.DeleteBucketReplication)
+		storageGroup.GET("/buckets/:bucket/replication/status", handlers.Storage.GetReplicationBacklog)
+		storageGroup.GET("/replication"+bucketKeyRoute, handlers.Storage.GetObjectReplication)
 
 		// Lifecycle Management
 		storageGroup.POST("/buckets/:bucket/lifecycle", handlers.Lifecycle.CreateRule)
 		storageGroup.GET("/buckets/:bucket/lifecycle", handlers.Lifecycle.ListRules)
 		storageGroup.DELETE("/buckets/:bucket/lifecycle/:id", handlers.Lifecycle.DeleteRule)
+		storageGroup.GET("/buckets/:bucket/lifecycle/:id/preview", handlers.Lifecycle.PreviewRule)
 
 		// Versioning
 		storageGroup.GET("/versions/:bucket/*key", handlers.Storage.ListVersions)
@@ -419,6 +558,7 @@ func registerDataRoutes(r *gin.Engine, handlers *Handlers, svcs *Services) {
 		// Parameterized object routes last
 		storageGroup.PUT(bucketKeyRoute, handlers.Storage.Upload)
 		storageGroup.GET(bucketKeyRoute, handlers.Storage.Download)
+		storageGroup.HEAD(bucketKeyRoute, handlers.Storage.Head)
 		storageGroup.DELETE(bucketKeyRoute, handlers.Storage.Delete)
 		storageGroup.GET("/:bucket", handlers.Storage.List)
 	}
@@ -427,6 +567,12 @@ func registerDataRoutes(r *gin.Engine, handlers *Handlers, svcs *Services) {
 	r.GET("/storage/presigned"+bucketKeyRoute, handlers.Storage.ServePresignedDownload)
 	r.PUT("/storage/presigned"+bucketKeyRoute, handlers.Storage.ServePresignedUpload)
 
+	// Anonymous Read Routes (No Auth Middleware); access is decided by bucket policies,
+	// public buckets and object ACLs
+	r.GET("/storage/public"+bucketKeyRoute, handlers.Storage.Download)
+	r.HEAD("/storage/public"+bucketKeyRoute, handlers.Storage.Head)
+	r.GET("/storage/public/:bucket", handlers.Storage.List)
+
 	volumeGroup := r.Group("/volumes")
 	volumeGroup.Use(httputil.Auth(svcs.Identity, svcs.Tenant), httputil.RequireTenant(), httputil.TenantMember(svcs.Tenant))
 	{
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/poyrazk/thecloud/internal/platform"
 	"github.com/poyrazk/thecloud/internal/repositories/noop"
@@ -25,6 +26,28 @@ func (s stubNetworkBackend) DeleteBridge(_ context.Context, _ string) error
 func (s stubNetworkBackend) ListBridges(_ context.Context) ([]string, error)        { return []string{}, nil }
 func (s stubNetworkBackend) AddPort(_ context.Context, _ string, _ string) error    { return nil }
 func (s stubNetworkBackend) DeletePort(_ context.Context, _ string, _ string) error { return nil }
+func (s stubNetworkBackend) CreatePatchPort(_ context.Context, _, _, _ string) error {
+	return nil
+}
+func (s stubNetworkBackend) AttachInternetGateway(_ context.Context, _, _, _ string) error {
+	return nil
+}
+func (s stubNetworkBackend) DetachInternetGateway(_ context.Context, _, _ string) error {
+	return nil
+}
+func (s stubNetworkBackend) MapElasticIP(_ context.Context, _, _, _ string) error   { return nil }
+func (s stubNetworkBackend) UnmapElasticIP(_ context.Context, _, _, _ string) error { return nil }
+func (s stubNetworkBackend) CreateNATGateway(_ context.Context, _, _, _, _, _ string) error {
+	return nil
+}
+func (s stubNetworkBackend) DeleteNATGateway(_ context.Context, _, _ string) error { return nil }
+func (s stubNetworkBackend) ApplyNetworkACL(_ context.Context, _, _ string, _ []domain.NetworkACLRule) error {
+	return nil
+}
+func (s stubNetworkBackend) RemoveNetworkACL(_ context.Context, _, _ string) error { return nil }
+func (s stubNetworkBackend) SampleFlows(_ context.Context, _ string) ([]domain.FlowRecord, error) {
+	return nil, nil
+}
 func (s stubNetworkBackend) CreateVXLANTunnel(_ context.Context, _ string, _ int, _ string) error {
 	return nil
 }
@@ -109,3 +132,17 @@ func TestOVSHealthHealthyBackend(t *testing.T) {
 	router.ServeHTTP(resp, req)
 	assert.Equal(t, http.StatusOK, resp.Code)
 }
+
+func TestGatewayRouterServesOnlyTheProxy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &platform.Config{Environment: "test"}
+	handlers := InitHandlers(&Services{}, cfg, logger)
+
+	router := SetupGatewayRouter(cfg, logger, handlers)
+	for _, path := range []string{"/health", "/certificates/ca", "/auth/login", "/gateway/routes"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusNotFound, resp.Code, path)
+	}
+}
@@ -10,8 +10,14 @@ import (
 type contextKey string
 
 const (
-	userIDKey   contextKey = "user_id"
-	tenantIDKey contextKey = "tenant_id"
+	userIDKey           contextKey = "user_id"
+	tenantIDKey         contextKey = "tenant_id"
+	presignedAccessKey  contextKey = "presigned_access"
+	bypassGovernanceKey contextKey = "bypass_governance_retention"
+	replicaWriteKey     contextKey = "replica_write"
+	customerKeyKey      contextKey = "sse_customer_key"
+	clientIPKey         contextKey = "client_ip"
+	routeParamsKey      contextKey = "route_params"
 )
 
 // WithUserID returns a new context with the given userID.
@@ -41,3 +47,71 @@ func TenantIDFromContext(ctx context.Context) uuid.UUID {
 	}
 	return tenantID
 }
+
+// WithPresignedAccess marks the context as authorized by a verified presigned URL signature.
+func WithPresignedAccess(ctx context.Context) context.Context {
+	return context.WithValue(ctx, presignedAccessKey, true)
+}
+
+// HasPresignedAccess reports whether the request was authorized by a presigned URL.
+func HasPresignedAccess(ctx context.Context) bool {
+	ok, _ := ctx.Value(presignedAccessKey).(bool)
+	return ok
+}
+
+// WithGovernanceBypass marks the context as requesting to bypass GOVERNANCE object retention.
+// The request is only honored for callers allowed to bypass it.
+func WithGovernanceBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassGovernanceKey, true)
+}
+
+// HasGovernanceBypass reports whether the request asked to bypass GOVERNANCE object retention.
+func HasGovernanceBypass(ctx context.Context) bool {
+	ok, _ := ctx.Value(bypassGovernanceKey).(bool)
+	return ok
+}
+
+// WithReplicaWrite marks the context as a write made by bucket replication from another installation.
+// Replica writes are not replicated again, so two-way replication does not loop.
+func WithReplicaWrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaWriteKey, true)
+}
+
+// IsReplicaWrite reports whether the request was made by bucket replication.
+func IsReplicaWrite(ctx context.Context) bool {
+	ok, _ := ctx.Value(replicaWriteKey).(bool)
+	return ok
+}
+
+// WithCustomerKey attaches the customer-provided key (SSE-C) an object is encrypted or decrypted with.
+func WithCustomerKey(ctx context.Context, key []byte) context.Context {
+	return context.WithValue(ctx, customerKeyKey, key)
+}
+
+// CustomerKeyFromContext returns the customer-provided key of the request, or nil.
+func CustomerKeyFromContext(ctx context.Context) []byte {
+	key, _ := ctx.Value(customerKeyKey).([]byte)
+	return key
+}
+
+// WithClientIP attaches the caller's IP address, as resolved by the API router's trusted proxy settings.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey, ip)
+}
+
+// ClientIPFromContext returns the caller's IP address, or an empty string if not set.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+// WithRouteParams attaches the path parameters extracted by a gateway route match.
+func WithRouteParams(ctx context.Context, params map[string]string) context.Context {
+	return context.WithValue(ctx, routeParamsKey, params)
+}
+
+// RouteParamsFromContext returns the gateway route's path parameters, or nil if not set.
+func RouteParamsFromContext(ctx context.Context) map[string]string {
+	params, _ := ctx.Value(routeParamsKey).(map[string]string)
+	return params
+}
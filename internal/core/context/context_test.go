@@ -58,3 +58,19 @@ func TestTenantIDContext(t *testing.T) {
 		assert.Equal(t, uuid.Nil, tenantID)
 	})
 }
+
+func TestPresignedAccessContext(t *testing.T) {
+	assert.False(t, appcontext.HasPresignedAccess(context.Background()))
+	assert.True(t, appcontext.HasPresignedAccess(appcontext.WithPresignedAccess(context.Background())))
+}
+
+func TestClientIPContext(t *testing.T) {
+	assert.Empty(t, appcontext.ClientIPFromContext(context.Background()))
+	assert.Equal(t, "10.0.0.1", appcontext.ClientIPFromContext(appcontext.WithClientIP(context.Background(), "10.0.0.1")))
+}
+
+func TestRouteParamsContext(t *testing.T) {
+	assert.Nil(t, appcontext.RouteParamsFromContext(context.Background()))
+	params := map[string]string{"id": "42"}
+	assert.Equal(t, params, appcontext.RouteParamsFromContext(appcontext.WithRouteParams(context.Background(), params)))
+}
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Storage actions that bucket policies can grant or deny.
+const (
+	StorageActionGetObject        = "storage:GetObject"
+	StorageActionPutObject        = "storage:PutObject"
+	StorageActionDeleteObject     = "storage:DeleteObject"
+	StorageActionListBucket       = "storage:ListBucket"
+	StorageActionPutObjectACL     = "storage:PutObjectAcl"
+	StorageActionPutObjectTagging = "storage:PutObjectTagging"
+	StorageActionDeleteBucket     = "storage:DeleteBucket"
+
+	StorageActionPutObjectRetention        = "storage:PutObjectRetention"
+	StorageActionPutObjectLegalHold        = "storage:PutObjectLegalHold"
+	StorageActionBypassGovernanceRetention = "storage:BypassGovernanceRetention"
+)
+
+// Principal forms accepted in a bucket policy statement.
+const (
+	// PrincipalAll matches every caller, including anonymous ones.
+	PrincipalAll = "*"
+	// PrincipalAnonymous matches unauthenticated callers only.
+	PrincipalAnonymous = "anonymous"
+	// PrincipalUserPrefix prefixes a user ID, e.g. "user:<uuid>".
+	PrincipalUserPrefix = "user:"
+	// PrincipalTenantPrefix prefixes a tenant ID and matches every member acting in that tenant.
+	PrincipalTenantPrefix = "tenant:"
+)
+
+// StoragePrincipal identifies the caller a storage request is evaluated for.
+type StoragePrincipal struct {
+	UserID   uuid.UUID
+	TenantID uuid.UUID
+}
+
+// IsAnonymous reports whether the caller did not authenticate.
+func (p StoragePrincipal) IsAnonymous() bool {
+	return p.UserID == uuid.Nil
+}
+
+// BucketPolicyStatement is an IAM statement scoped to a bucket. Resources are object key
+// patterns relative to the bucket ("*", "public/*"); Principal lists who the statement applies to.
+type BucketPolicyStatement struct {
+	Statement
+	Principal []string `json:"principal"`
+}
+
+// AppliesTo reports whether the statement names the given caller.
+func (s BucketPolicyStatement) AppliesTo(p StoragePrincipal) bool {
+	for _, entry := range s.Principal {
+		switch {
+		case entry == PrincipalAll:
+			return true
+		case entry == PrincipalAnonymous:
+			if p.IsAnonymous() {
+				return true
+			}
+		case strings.HasPrefix(entry, PrincipalUserPrefix):
+			if !p.IsAnonymous() && strings.TrimPrefix(entry, PrincipalUserPrefix) == p.UserID.String() {
+				return true
+			}
+		case strings.HasPrefix(entry, PrincipalTenantPrefix):
+			if p.TenantID != uuid.Nil && strings.TrimPrefix(entry, PrincipalTenantPrefix) == p.TenantID.String() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// BucketPolicy is a resource policy attached to a bucket, granting access to callers other than its owner.
+type BucketPolicy struct {
+	Bucket     string                  `json:"bucket"`
+	Statements []BucketPolicyStatement `json:"statements"`
+	UpdatedAt  time.Time               `json:"updated_at"`
+}
+
+// Validate checks that every statement is well formed.
+func (p *BucketPolicy) Validate() error {
+	if len(p.Statements) == 0 {
+		return fmt.Errorf("policy must contain at least one statement")
+	}
+	for i, st := range p.Statements {
+		if st.Effect != EffectAllow && st.Effect != EffectDeny {
+			return fmt.Errorf("statement %d: effect must be %q or %q", i, EffectAllow, EffectDeny)
+		}
+		if len(st.Action) == 0 {
+			return fmt.Errorf("statement %d: at least one action is required", i)
+		}
+		for _, a := range st.Action {
+			if a != "*" && !strings.HasPrefix(a, "storage:") {
+				return fmt.Errorf("statement %d: action %q is not a storage action", i, a)
+			}
+		}
+		if len(st.Resource) == 0 {
+			return fmt.Errorf("statement %d: at least one resource is required", i)
+		}
+		if len(st.Condition) > 0 {
+			return fmt.Errorf("statement %d: conditions are not supported in bucket policies", i)
+		}
+		if len(st.Principal) == 0 {
+			return fmt.Errorf("statement %d: at least one principal is required", i)
+		}
+		for _, pr := range st.Principal {
+			if err := validatePrincipal(pr); err != nil {
+				return fmt.Errorf("statement %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validatePrincipal(entry string) error {
+	switch {
+	case entry == PrincipalAll, entry == PrincipalAnonymous:
+		return nil
+	case strings.HasPrefix(entry, PrincipalUserPrefix):
+		_, err := uuid.Parse(strings.TrimPrefix(entry, PrincipalUserPrefix))
+		if err != nil {
+			return fmt.Errorf("invalid user principal %q", entry)
+		}
+		return nil
+	case strings.HasPrefix(entry, PrincipalTenantPrefix):
+		_, err := uuid.Parse(strings.TrimPrefix(entry, PrincipalTenantPrefix))
+		if err != nil {
+			return fmt.Errorf("invalid tenant principal %q", entry)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown principal %q", entry)
+}
+
+// ObjectACL is a canned access control list applied to a single object.
+type ObjectACL string
+
+const (
+	// ACLPrivate grants nothing beyond the bucket owner and bucket policy.
+	ACLPrivate ObjectACL = "private"
+	// ACLPublicRead lets anyone, including anonymous callers, read the object.
+	ACLPublicRead ObjectACL = "public-read"
+	// ACLAuthenticatedRead lets any authenticated caller read the object.
+	ACLAuthenticatedRead ObjectACL = "authenticated-read"
+)
+
+// Valid reports whether the ACL is a known canned ACL.
+func (a ObjectACL) Valid() bool {
+	switch a {
+	case ACLPrivate, ACLPublicRead, ACLAuthenticatedRead:
+		return true
+	}
+	return false
+}
+
+// AllowsRead reports whether the ACL lets the caller read the object.
+func (a ObjectACL) AllowsRead(p StoragePrincipal) bool {
+	switch a {
+	case ACLPublicRead:
+		return true
+	case ACLAuthenticatedRead:
+		return !p.IsAnonymous()
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketPolicyStatementAppliesTo(t *testing.T) {
+	t.Parallel()
+	user := uuid.New()
+	tenant := uuid.New()
+	anonymous := domain.StoragePrincipal{}
+	member := domain.StoragePrincipal{UserID: user, TenantID: tenant}
+	stranger := domain.StoragePrincipal{UserID: uuid.New()}
+
+	tests := []struct {
+		name      string
+		principal []string
+		caller    domain.StoragePrincipal
+		want      bool
+	}{
+		{"everyone matches anonymous", []string{domain.PrincipalAll}, anonymous, true},
+		{"anonymous only", []string{domain.PrincipalAnonymous}, anonymous, true},
+		{"anonymous excludes users", []string{domain.PrincipalAnonymous}, member, false},
+		{"user", []string{"user:" + user.String()}, member, true},
+		{"other user", []string{"user:" + user.String()}, stranger, false},
+		{"tenant", []string{"tenant:" + tenant.String()}, member, true},
+		{"tenant excludes outsiders", []string{"tenant:" + tenant.String()}, stranger, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := domain.BucketPolicyStatement{Principal: tt.principal}
+			assert.Equal(t, tt.want, st.AppliesTo(tt.caller))
+		})
+	}
+}
+
+func TestBucketPolicyValidate(t *testing.T) {
+	t.Parallel()
+	valid := domain.BucketPolicyStatement{
+		Statement: domain.Statement{Effect: domain.EffectAllow, Action: []string{domain.StorageActionGetObject}, Resource: []string{"*"}},
+		Principal: []string{domain.PrincipalAll},
+	}
+	mutate := func(f func(*domain.BucketPolicyStatement)) domain.BucketPolicyStatement {
+		st := valid
+		f(&st)
+		return st
+	}
+
+	tests := []struct {
+		name    string
+		stmts   []domain.BucketPolicyStatement
+		wantErr bool
+	}{
+		{"valid", []domain.BucketPolicyStatement{valid}, false},
+		{"empty", nil, true},
+		{"bad effect", []domain.BucketPolicyStatement{mutate(func(s *domain.BucketPolicyStatement) { s.Effect = "Maybe" })}, true},
+		{"non storage action", []domain.BucketPolicyStatement{mutate(func(s *domain.BucketPolicyStatement) { s.Action = []string{"instance:launch"} })}, true},
+		{"no resource", []domain.BucketPolicyStatement{mutate(func(s *domain.BucketPolicyStatement) { s.Resource = nil })}, true},
+		{"no principal", []domain.BucketPolicyStatement{mutate(func(s *domain.BucketPolicyStatement) { s.Principal = nil })}, true},
+		{"bad principal", []domain.BucketPolicyStatement{mutate(func(s *domain.BucketPolicyStatement) { s.Principal = []string{"user:nope"} })}, true},
+		{"condition", []domain.BucketPolicyStatement{mutate(func(s *domain.BucketPolicyStatement) {
+			s.Condition = domain.Condition{"IpAddress": {"source_ip": "10.0.0.0/8"}}
+		})}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&domain.BucketPolicy{Statements: tt.stmts}).Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestObjectACLAllowsRead(t *testing.T) {
+	t.Parallel()
+	anonymous := domain.StoragePrincipal{}
+	user := domain.StoragePrincipal{UserID: uuid.New()}
+
+	assert.False(t, domain.ACLPrivate.AllowsRead(user))
+	assert.True(t, domain.ACLPublicRead.AllowsRead(anonymous))
+	assert.True(t, domain.ACLAuthenticatedRead.AllowsRead(user))
+	assert.False(t, domain.ACLAuthenticatedRead.AllowsRead(anonymous))
+	assert.False(t, domain.ObjectACL("bogus").Valid())
+}
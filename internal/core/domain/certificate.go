@@ -0,0 +1,114 @@
+// Package domain defines core business entities.
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CertificateSource records how a certificate was obtained.
+type CertificateSource string
+
+const (
+	// CertificateSourceImported marks a certificate uploaded as PEM.
+	CertificateSourceImported CertificateSource = "imported"
+	// CertificateSourceIssued marks a certificate issued by the internal CA.
+	CertificateSourceIssued CertificateSource = "issued"
+)
+
+// CertificateStatus reflects how close a certificate is to expiry.
+type CertificateStatus string
+
+const (
+	// CertificateStatusActive indicates the certificate is valid.
+	CertificateStatusActive CertificateStatus = "ACTIVE"
+	// CertificateStatusExpiring indicates the certificate expires within CertificateExpiringWindow.
+	CertificateStatusExpiring CertificateStatus = "EXPIRING"
+	// CertificateStatusExpired indicates the certificate is no longer valid.
+	CertificateStatusExpired CertificateStatus = "EXPIRED"
+)
+
+// CertificateExpiringWindow is how long before expiry a certificate is
+// reported as expiring.
+const CertificateExpiringWindow = 30 * 24 * time.Hour
+
+// CertificateExpiryAlertDays are the days before expiry at which alerts are
+// sent; 0 alerts once the certificate has expired.
+var CertificateExpiryAlertDays = []int{30, 7, 1, 0}
+
+// Certificate is a TLS certificate that load balancers and gateway routes
+// terminate HTTPS with. The private key is stored encrypted and never
+// returned by the API.
+type Certificate struct {
+	ID                  uuid.UUID         `json:"id"`
+	UserID              uuid.UUID         `json:"user_id"`
+	TenantID            uuid.UUID         `json:"tenant_id"`
+	Name                string            `json:"name"`
+	Domains             []string          `json:"domains"`
+	Source              CertificateSource `json:"source"`
+	Status              CertificateStatus `json:"status"`
+	Issuer              string            `json:"issuer"`
+	SerialNumber        string            `json:"serial_number"`
+	NotBefore           time.Time         `json:"not_before"`
+	NotAfter            time.Time         `json:"not_after"`
+	CertificatePEM      string            `json:"certificate_pem"` // Leaf certificate followed by its chain
+	EncryptedPrivateKey string            `json:"-"`
+	NotifyTopicID       *uuid.UUID        `json:"notify_topic_id,omitempty"` // CloudNotify topic for expiry alerts
+	ExpiryAlertDays     *int              `json:"-"`                         // Last CertificateExpiryAlertDays entry alerted
+	CreatedAt           time.Time         `json:"created_at"`
+}
+
+// CertificateStatusAt returns the status of a certificate expiring at
+// notAfter as of now.
+func CertificateStatusAt(notAfter, now time.Time) CertificateStatus {
+	switch {
+	case !now.Before(notAfter):
+		return CertificateStatusExpired
+	case notAfter.Sub(now) <= CertificateExpiringWindow:
+		return CertificateStatusExpiring
+	default:
+		return CertificateStatusActive
+	}
+}
+
+// Covers reports whether the certificate is valid for host, honouring
+// single-label wildcard domains such as *.example.com.
+func (c *Certificate) Covers(host string) bool {
+	for _, d := range c.Domains {
+		if CertificateDomainMatches(d, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// CertificateDomainMatches reports whether a certificate domain matches host.
+func CertificateDomainMatches(pattern, host string) bool {
+	pattern, host = strings.ToLower(pattern), strings.ToLower(strings.TrimSuffix(host, "."))
+	if rest, ok := strings.CutPrefix(pattern, "*."); ok {
+		label, parent, found := strings.Cut(host, ".")
+		return found && label != "" && parent == rest
+	}
+	return pattern == host
+}
+
+// CertificateKeyPair is the PEM material a listener serves a certificate
+// with.
+type CertificateKeyPair struct {
+	CertificateID  uuid.UUID `json:"certificate_id"`
+	Domains        []string  `json:"domains"`
+	CertificatePEM string    `json:"-"`
+	PrivateKeyPEM  string    `json:"-"`
+}
+
+// CertificateAuthority is the platform's internal CA, which issues
+// certificates that clients trust by installing its certificate.
+type CertificateAuthority struct {
+	Name                string    `json:"name"`
+	CertificatePEM      string    `json:"certificate_pem"`
+	EncryptedPrivateKey string    `json:"-"`
+	NotAfter            time.Time `json:"not_after"`
+	CreatedAt           time.Time `json:"created_at"`
+}
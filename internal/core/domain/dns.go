@@ -71,3 +71,27 @@ func IsValidRecordType(t RecordType) bool {
 	}
 	return false
 }
+
+// DNSChangeAction is the operation a DNSRecordChange applies.
+type DNSChangeAction string
+
+const (
+	// DNSChangeCreate adds a record and fails if an identical one exists.
+	DNSChangeCreate DNSChangeAction = "CREATE"
+	// DNSChangeUpsert replaces all records of a name and type. Several upserts of
+	// the same name and type in one batch together form the new record set.
+	DNSChangeUpsert DNSChangeAction = "UPSERT"
+	// DNSChangeDelete removes the records of a name and type, or only the one
+	// with matching content when content is set.
+	DNSChangeDelete DNSChangeAction = "DELETE"
+)
+
+// DNSRecordChange is one entry of an atomic change batch.
+type DNSRecordChange struct {
+	Action   DNSChangeAction `json:"action"`
+	Name     string          `json:"name"`
+	Type     RecordType      `json:"type"`
+	Content  string          `json:"content,omitempty"`
+	TTL      int             `json:"ttl,omitempty"`
+	Priority *int            `json:"priority,omitempty"`
+}
@@ -0,0 +1,93 @@
+// Package domain defines core business entities.
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FlowLogResourceType is the kind of resource whose traffic a flow log captures.
+type FlowLogResourceType string
+
+const (
+	// FlowLogVPC captures every flow on a VPC's bridge.
+	FlowLogVPC FlowLogResourceType = "vpc"
+	// FlowLogSubnet captures flows to or from a subnet's CIDR.
+	FlowLogSubnet FlowLogResourceType = "subnet"
+	// FlowLogInstance captures flows to or from an instance's private IP.
+	FlowLogInstance FlowLogResourceType = "instance"
+)
+
+// FlowLogTrafficType selects which flows a flow log records.
+type FlowLogTrafficType string
+
+const (
+	// FlowTrafficAccept records only flows the VPC let through.
+	FlowTrafficAccept FlowLogTrafficType = "accept"
+	// FlowTrafficReject records only flows dropped by a network ACL or security group.
+	FlowTrafficReject FlowLogTrafficType = "reject"
+	// FlowTrafficAll records both.
+	FlowTrafficAll FlowLogTrafficType = "all"
+)
+
+// FlowLogResourceTypeLog is the CloudLogs resource type under which flow records are ingested.
+const FlowLogResourceTypeLog = "vpc-flow-log"
+
+// FlowAction is the verdict the network reached for a flow.
+type FlowAction string
+
+const (
+	// FlowAccept marks traffic that was forwarded.
+	FlowAccept FlowAction = "accept"
+	// FlowReject marks traffic that was dropped.
+	FlowReject FlowAction = "reject"
+)
+
+// FlowLog configures the capture of a VPC, subnet or instance's traffic into CloudLogs.
+type FlowLog struct {
+	ID           uuid.UUID           `json:"id"`
+	UserID       uuid.UUID           `json:"user_id"`
+	TenantID     uuid.UUID           `json:"tenant_id"`
+	VPCID        uuid.UUID           `json:"vpc_id"`
+	ResourceType FlowLogResourceType `json:"resource_type"`
+	ResourceID   uuid.UUID           `json:"resource_id"`
+	TrafficType  FlowLogTrafficType  `json:"traffic_type"`
+	ARN          string              `json:"arn"`
+	CreatedAt    time.Time           `json:"created_at"`
+}
+
+// Validate checks the flow log's resource and traffic types.
+func (f *FlowLog) Validate() error {
+	switch f.ResourceType {
+	case FlowLogVPC, FlowLogSubnet, FlowLogInstance:
+	default:
+		return fmt.Errorf("invalid flow log resource type: %s", f.ResourceType)
+	}
+	switch f.TrafficType {
+	case FlowTrafficAccept, FlowTrafficReject, FlowTrafficAll:
+	default:
+		return fmt.Errorf("invalid flow log traffic type: %s", f.TrafficType)
+	}
+	return nil
+}
+
+// Records reports whether the flow log captures flows with the given verdict.
+func (f *FlowLog) Records(action FlowAction) bool {
+	return f.TrafficType == FlowTrafficAll || string(f.TrafficType) == string(action)
+}
+
+// FlowRecord is one sampled flow: its 5-tuple, the traffic seen since the previous
+// sample and the verdict. Addresses or ports the datapath did not need to inspect
+// are left empty or zero.
+type FlowRecord struct {
+	SrcIP    string     `json:"src_ip"`
+	DstIP    string     `json:"dst_ip"`
+	SrcPort  int        `json:"src_port,omitempty"`
+	DstPort  int        `json:"dst_port,omitempty"`
+	Protocol string     `json:"protocol"`
+	Packets  uint64     `json:"packets"`
+	Bytes    uint64     `json:"bytes"`
+	Action   FlowAction `json:"action"`
+}
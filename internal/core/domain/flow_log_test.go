@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlowLogValidate(t *testing.T) {
+	t.Parallel()
+	fl := FlowLog{ResourceType: FlowLogSubnet, TrafficType: FlowTrafficReject}
+	assert.NoError(t, fl.Validate())
+
+	fl.ResourceType = "bucket"
+	assert.ErrorContains(t, fl.Validate(), "resource type")
+
+	fl.ResourceType = FlowLogVPC
+	fl.TrafficType = "dropped"
+	assert.ErrorContains(t, fl.Validate(), "traffic type")
+}
+
+func TestFlowLogRecords(t *testing.T) {
+	t.Parallel()
+	reject := FlowLog{TrafficType: FlowTrafficReject}
+	assert.True(t, reject.Records(FlowReject))
+	assert.False(t, reject.Records(FlowAccept))
+
+	all := FlowLog{TrafficType: FlowTrafficAll}
+	assert.True(t, all.Records(FlowAccept))
+	assert.True(t, all.Records(FlowReject))
+}
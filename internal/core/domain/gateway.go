@@ -2,6 +2,8 @@
 package domain
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,20 +11,305 @@ import (
 
 // GatewayRoute defines an ingress rule for mapping external HTTP traffic to internal resources.
 type GatewayRoute struct {
-	ID          uuid.UUID `json:"id"`
-	UserID      uuid.UUID `json:"user_id"`
-	Name        string    `json:"name"`
-	PathPrefix  string    `json:"path_prefix"`  // Legacy: Request path to match (e.g., "/api/v1")
-	PathPattern string    `json:"path_pattern"` // New: Pattern with {params}
-	PatternType string    `json:"pattern_type"` // "prefix" or "pattern"
-	ParamNames  []string  `json:"param_names"`  // Extracted parameter names
-	TargetURL   string    `json:"target_url"`   // Internal destination (e.g., "http://service-a:8080")
-	Methods     []string  `json:"methods"`      // New: HTTP methods to match (empty = all)
-	StripPrefix bool      `json:"strip_prefix"` // If true, removes path_prefix from request before forwarding
-	RateLimit   int       `json:"rate_limit"`   // Maximum allowed requests per second per IP
-	Priority    int       `json:"priority"`     // Manual priority for tie-breaking
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uuid.UUID           `json:"id"`
+	UserID      uuid.UUID           `json:"user_id"`
+	Name        string              `json:"name"`
+	PathPrefix  string              `json:"path_prefix"`  // Legacy: Request path to match (e.g., "/api/v1")
+	PathPattern string              `json:"path_pattern"` // New: Pattern with {params}
+	PatternType string              `json:"pattern_type"` // "prefix" or "pattern"
+	ParamNames  []string            `json:"param_names"`  // Extracted parameter names
+	TargetURL   string              `json:"target_url"`   // Internal destination (e.g., "http://service-a:8080")
+	Methods     []string            `json:"methods"`      // New: HTTP methods to match (empty = all)
+	StripPrefix bool                `json:"strip_prefix"` // If true, removes path_prefix from request before forwarding
+	RateLimit   int                 `json:"rate_limit"`   // Maximum allowed requests per second per IP
+	Priority    int                 `json:"priority"`     // Manual priority for tie-breaking
+	Plugins     GatewayRoutePlugins `json:"plugins"`
+	// Upstreams replace TargetURL when set: traffic is split across them by weight.
+	Upstreams     []GatewayUpstream    `json:"upstreams,omitempty"`
+	TrafficPolicy GatewayTrafficPolicy `json:"traffic_policy"`
+	// TenantID scopes upstream lookups to the tenant the route was created in.
+	TenantID uuid.UUID `json:"tenant_id,omitempty"`
+	// CertificateID is served on the gateway's HTTPS listener for the
+	// certificate's domains.
+	CertificateID *uuid.UUID `json:"certificate_id,omitempty"`
+	// HTTPSRedirect redirects plain HTTP requests to the HTTPS listener.
+	HTTPSRedirect bool      `json:"https_redirect,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// GatewayUpstreamType is the kind of backend an upstream points at.
+type GatewayUpstreamType string
+
+const (
+	// GatewayUpstreamURL is a static http(s) URL.
+	GatewayUpstreamURL GatewayUpstreamType = "url"
+	// GatewayUpstreamLB is a regional load balancer, reached on its IP and listener port.
+	GatewayUpstreamLB GatewayUpstreamType = "lb"
+	// GatewayUpstreamDeployment is a container deployment; its replicas share the upstream's weight.
+	GatewayUpstreamDeployment GatewayUpstreamType = "deployment"
+	// GatewayUpstreamFunction invokes a CloudFunction with each request; it must be the route's only upstream.
+	GatewayUpstreamFunction GatewayUpstreamType = "function"
+)
+
+// MaxGatewayRetries bounds how often a request is retried on another upstream.
+const MaxGatewayRetries = 5
+
+// GatewayUpstream is one backend of a route.
+type GatewayUpstream struct {
+	Type     GatewayUpstreamType `json:"type"`
+	URL      string              `json:"url,omitempty"`       // url upstreams
+	TargetID *uuid.UUID          `json:"target_id,omitempty"` // lb, deployment and function upstreams
+	Port     int                 `json:"port,omitempty"`      // deployment container port; defaults to the deployment's first exposed port
+	Weight   int                 `json:"weight"`              // relative share of traffic; 0 = no traffic
+}
+
+// GatewayTrafficPolicy controls how a route talks to its upstreams.
+type GatewayTrafficPolicy struct {
+	Retries        int                          `json:"retries,omitempty"`    // extra attempts for idempotent requests
+	TimeoutMs      int                          `json:"timeout_ms,omitempty"` // per-attempt time to response headers; 0 = none
+	CircuitBreaker *GatewayCircuitBreakerConfig `json:"circuit_breaker,omitempty"`
+}
+
+// GatewayCircuitBreakerConfig tunes the passive circuit breaker kept per upstream target.
+type GatewayCircuitBreakerConfig struct {
+	FailureThreshold int `json:"failure_threshold"` // consecutive failures that open the circuit
+	ResetTimeoutSec  int `json:"reset_timeout_sec"` // how long an open circuit rejects traffic
+}
+
+// ValidateUpstreams checks a route's upstreams and traffic policy.
+func (r *GatewayRoute) ValidateUpstreams() error {
+	if len(r.Upstreams) == 0 && r.TargetURL == "" {
+		return fmt.Errorf("a target url or at least one upstream is required")
+	}
+	total := 0
+	for i, u := range r.Upstreams {
+		if u.Weight < 0 {
+			return fmt.Errorf("upstream %d: weight must not be negative", i+1)
+		}
+		total += u.Weight
+		switch u.Type {
+		case GatewayUpstreamURL:
+			if !strings.HasPrefix(u.URL, "http://") && !strings.HasPrefix(u.URL, "https://") {
+				return fmt.Errorf("upstream %d: url must be an http or https url", i+1)
+			}
+		case GatewayUpstreamLB, GatewayUpstreamDeployment:
+			if u.TargetID == nil {
+				return fmt.Errorf("upstream %d: target_id is required for %s upstreams", i+1, u.Type)
+			}
+		case GatewayUpstreamFunction:
+			if u.TargetID == nil {
+				return fmt.Errorf("upstream %d: target_id is required for %s upstreams", i+1, u.Type)
+			}
+			if len(r.Upstreams) > 1 || r.TargetURL != "" {
+				return fmt.Errorf("a function upstream cannot be combined with other upstreams")
+			}
+		default:
+			return fmt.Errorf("upstream %d: invalid upstream type: %s", i+1, u.Type)
+		}
+		if u.Port < 0 || u.Port > 65535 {
+			return fmt.Errorf("upstream %d: invalid port %d", i+1, u.Port)
+		}
+	}
+	if len(r.Upstreams) > 0 && total == 0 {
+		return fmt.Errorf("at least one upstream must have a positive weight")
+	}
+
+	p := r.TrafficPolicy
+	if p.Retries < 0 || p.Retries > MaxGatewayRetries {
+		return fmt.Errorf("retries must be between 0 and %d", MaxGatewayRetries)
+	}
+	if p.TimeoutMs < 0 {
+		return fmt.Errorf("timeout_ms must not be negative")
+	}
+	if cb := p.CircuitBreaker; cb != nil && (cb.FailureThreshold < 1 || cb.ResetTimeoutSec < 1) {
+		return fmt.Errorf("circuit breaker threshold and reset timeout must be positive")
+	}
+	return nil
+}
+
+// FunctionUpstream returns the route's function upstream, or nil if the route
+// proxies to HTTP backends.
+func (r *GatewayRoute) FunctionUpstream() *GatewayUpstream {
+	for i := range r.Upstreams {
+		if r.Upstreams[i].Type == GatewayUpstreamFunction {
+			return &r.Upstreams[i]
+		}
+	}
+	return nil
+}
+
+// GatewayFunctionEvent is the invocation payload a function upstream receives
+// for each HTTP request.
+type GatewayFunctionEvent struct {
+	Method          string              `json:"method"`
+	Path            string              `json:"path"`
+	Query           map[string][]string `json:"query,omitempty"`
+	Params          map[string]string   `json:"params,omitempty"` // path parameters of the matched route pattern
+	Headers         map[string]string   `json:"headers,omitempty"`
+	Body            string              `json:"body,omitempty"`
+	IsBase64Encoded bool                `json:"is_base64_encoded,omitempty"` // set when the body is not valid UTF-8
+}
+
+// GatewayFunctionResponse is the HTTP response a function writes to stdout.
+// Output that is not a response object is returned as a plain-text 200.
+type GatewayFunctionResponse struct {
+	StatusCode      int               `json:"status_code"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Body            string            `json:"body,omitempty"`
+	IsBase64Encoded bool              `json:"is_base64_encoded,omitempty"`
+}
+
+// GatewayAccessLogResourceType is the CloudLogs resource type under which
+// gateway access logs are ingested, keyed by route ID.
+const GatewayAccessLogResourceType = "gateway-route"
+
+// GatewayAccessLog is one request served by a gateway route.
+type GatewayAccessLog struct {
+	RouteID   uuid.UUID `json:"route_id"`
+	RouteName string    `json:"route_name"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	LatencyMs float64   `json:"latency_ms"`
+	BytesSent int64     `json:"bytes_sent"`
+	Upstream  string    `json:"upstream,omitempty"` // host or function that served the last attempt
+	ClientIP  string    `json:"client_ip"`
+	APIKeyID  string    `json:"api_key_id,omitempty"` // first 12 hex characters of the API key's SHA-256 hash
+}
+
+// GatewayAuthType selects how a route authenticates callers.
+type GatewayAuthType string
+
+const (
+	// GatewayAuthAPIKey requires one of the route's API keys in a request header.
+	GatewayAuthAPIKey GatewayAuthType = "api_key"
+	// GatewayAuthJWT requires a valid bearer JSON Web Token.
+	GatewayAuthJWT GatewayAuthType = "jwt"
+)
+
+// Supported JWT signing algorithms.
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+)
+
+// DefaultGatewayAPIKeyHeader is the header API keys are read from unless a route overrides it.
+const DefaultGatewayAPIKeyHeader = "X-API-Key"
+
+// GatewayRoutePlugins configures the request processing a route applies before
+// and after proxying. A zero value disables every plugin.
+type GatewayRoutePlugins struct {
+	Auth            *GatewayAuthConfig    `json:"auth,omitempty"`
+	CORS            *GatewayCORSConfig    `json:"cors,omitempty"`
+	RequestHeaders  *GatewayHeaderRewrite `json:"request_headers,omitempty"`
+	ResponseHeaders *GatewayHeaderRewrite `json:"response_headers,omitempty"`
+	MaxBodyBytes    int64                 `json:"max_body_bytes,omitempty"` // 0 = unlimited
+}
+
+// GatewayAuthConfig authenticates callers of a route.
+type GatewayAuthConfig struct {
+	Type GatewayAuthType `json:"type"`
+
+	// API key authentication. Keys are accepted in plain text and only their
+	// SHA-256 hashes are stored.
+	Header       string   `json:"header,omitempty"`
+	APIKeys      []string `json:"api_keys,omitempty"`
+	APIKeyHashes []string `json:"api_key_hashes,omitempty"`
+
+	// JWT authentication. HS256 tokens are verified with Secret, RS256 tokens
+	// with the PEM encoded PublicKey. Issuer and Audience are checked when set.
+	Algorithm string `json:"algorithm,omitempty"`
+	Secret    string `json:"secret,omitempty"`
+	PublicKey string `json:"public_key,omitempty"`
+	Issuer    string `json:"issuer,omitempty"`
+	Audience  string `json:"audience,omitempty"`
+}
+
+// GatewayCORSConfig is the cross-origin resource sharing policy of a route.
+type GatewayCORSConfig struct {
+	AllowOrigins     []string `json:"allow_origins"` // "*" allows any origin
+	AllowMethods     []string `json:"allow_methods,omitempty"`
+	AllowHeaders     []string `json:"allow_headers,omitempty"`
+	ExposeHeaders    []string `json:"expose_headers,omitempty"`
+	AllowCredentials bool     `json:"allow_credentials,omitempty"`
+	MaxAgeSec        int      `json:"max_age_sec,omitempty"`
+}
+
+// GatewayHeaderRewrite sets and removes headers. Removals are applied first.
+type GatewayHeaderRewrite struct {
+	Set    map[string]string `json:"set,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+}
+
+// Validate checks that every configured plugin is complete.
+func (p *GatewayRoutePlugins) Validate() error {
+	if p.MaxBodyBytes < 0 {
+		return fmt.Errorf("max_body_bytes must not be negative")
+	}
+	if p.Auth != nil {
+		if err := p.Auth.validate(); err != nil {
+			return err
+		}
+	}
+	if p.CORS != nil {
+		if len(p.CORS.AllowOrigins) == 0 {
+			return fmt.Errorf("cors requires at least one allowed origin")
+		}
+		if p.CORS.AllowCredentials {
+			for _, o := range p.CORS.AllowOrigins {
+				if o == "*" {
+					return fmt.Errorf("cors cannot allow credentials for any origin")
+				}
+			}
+		}
+		if p.CORS.MaxAgeSec < 0 {
+			return fmt.Errorf("cors max_age_sec must not be negative")
+		}
+	}
+	return nil
+}
+
+func (a *GatewayAuthConfig) validate() error {
+	switch a.Type {
+	case GatewayAuthAPIKey:
+		if len(a.APIKeys) == 0 && len(a.APIKeyHashes) == 0 {
+			return fmt.Errorf("api_key auth requires at least one key")
+		}
+		for _, k := range a.APIKeys {
+			if strings.TrimSpace(k) == "" {
+				return fmt.Errorf("api keys must not be empty")
+			}
+		}
+	case GatewayAuthJWT:
+		switch a.Algorithm {
+		case JWTAlgorithmHS256:
+			if a.Secret == "" {
+				return fmt.Errorf("HS256 jwt auth requires a secret")
+			}
+		case JWTAlgorithmRS256:
+			if a.PublicKey == "" {
+				return fmt.Errorf("RS256 jwt auth requires a public key")
+			}
+		default:
+			return fmt.Errorf("unsupported jwt algorithm: %s", a.Algorithm)
+		}
+	default:
+		return fmt.Errorf("invalid gateway auth type: %s", a.Type)
+	}
+	return nil
+}
+
+// Redacted returns a copy of the plugins without credentials, for API responses.
+func (p GatewayRoutePlugins) Redacted() GatewayRoutePlugins {
+	if p.Auth != nil {
+		auth := *p.Auth
+		auth.APIKeys = nil
+		auth.APIKeyHashes = nil
+		auth.Secret = ""
+		p.Auth = &auth
+	}
+	return p
 }
 
 // RouteMatch represents a successful route pattern match.
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGatewayRoutePluginsValidate(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name    string
+		plugins GatewayRoutePlugins
+		wantErr bool
+	}{
+		{"Empty", GatewayRoutePlugins{}, false},
+		{"APIKey", GatewayRoutePlugins{Auth: &GatewayAuthConfig{Type: GatewayAuthAPIKey, APIKeys: []string{"k"}}}, false},
+		{"APIKeyWithoutKeys", GatewayRoutePlugins{Auth: &GatewayAuthConfig{Type: GatewayAuthAPIKey}}, true},
+		{"JWTWithoutSecret", GatewayRoutePlugins{Auth: &GatewayAuthConfig{Type: GatewayAuthJWT, Algorithm: JWTAlgorithmHS256}}, true},
+		{"JWTUnsupportedAlgorithm", GatewayRoutePlugins{Auth: &GatewayAuthConfig{Type: GatewayAuthJWT, Algorithm: "none", Secret: "s"}}, true},
+		{"UnknownAuth", GatewayRoutePlugins{Auth: &GatewayAuthConfig{Type: "basic"}}, true},
+		{"CORSWithoutOrigins", GatewayRoutePlugins{CORS: &GatewayCORSConfig{}}, true},
+		{"CORSCredentialsAnyOrigin", GatewayRoutePlugins{CORS: &GatewayCORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}}, true},
+		{"NegativeBodyLimit", GatewayRoutePlugins{MaxBodyBytes: -1}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.plugins.Validate()
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGatewayRoutePluginsRedacted(t *testing.T) {
+	t.Parallel()
+	p := GatewayRoutePlugins{Auth: &GatewayAuthConfig{Type: GatewayAuthJWT, Algorithm: JWTAlgorithmHS256, Secret: "s", APIKeyHashes: []string{"h"}}}
+	r := p.Redacted()
+	assert.Empty(t, r.Auth.Secret)
+	assert.Empty(t, r.Auth.APIKeyHashes)
+	assert.Equal(t, "s", p.Auth.Secret, "the original is not modified")
+}
+
+func TestGatewayRouteValidateUpstreams(t *testing.T) {
+	t.Parallel()
+	id := uuid.New()
+	url := func(u string, w int) GatewayUpstream {
+		return GatewayUpstream{Type: GatewayUpstreamURL, URL: u, Weight: w}
+	}
+	cases := []struct {
+		name    string
+		route   GatewayRoute
+		wantErr bool
+	}{
+		{"TargetURL", GatewayRoute{TargetURL: "http://svc"}, false},
+		{"NoTarget", GatewayRoute{}, true},
+		{"Weighted", GatewayRoute{Upstreams: []GatewayUpstream{url("http://v1", 90), url("https://v2", 10)}}, false},
+		{"Drained", GatewayRoute{Upstreams: []GatewayUpstream{url("http://v1", 1), url("http://v2", 0)}}, false},
+		{"AllZeroWeights", GatewayRoute{Upstreams: []GatewayUpstream{url("http://v1", 0)}}, true},
+		{"NegativeWeight", GatewayRoute{Upstreams: []GatewayUpstream{url("http://v1", -1)}}, true},
+		{"BadURL", GatewayRoute{Upstreams: []GatewayUpstream{url("ftp://v1", 1)}}, true},
+		{"LB", GatewayRoute{Upstreams: []GatewayUpstream{{Type: GatewayUpstreamLB, TargetID: &id, Weight: 1}}}, false},
+		{"DeploymentWithoutID", GatewayRoute{Upstreams: []GatewayUpstream{{Type: GatewayUpstreamDeployment, Weight: 1}}}, true},
+		{"BadPort", GatewayRoute{Upstreams: []GatewayUpstream{{Type: GatewayUpstreamLB, TargetID: &id, Port: 70000, Weight: 1}}}, true},
+		{"UnknownType", GatewayRoute{Upstreams: []GatewayUpstream{{Type: "dns", Weight: 1}}}, true},
+		{"Function", GatewayRoute{Upstreams: []GatewayUpstream{{Type: GatewayUpstreamFunction, TargetID: &id, Weight: 1}}}, false},
+		{"FunctionWithTarget", GatewayRoute{TargetURL: "http://svc", Upstreams: []GatewayUpstream{{Type: GatewayUpstreamFunction, TargetID: &id, Weight: 1}}}, true},
+		{"FunctionWithOthers", GatewayRoute{Upstreams: []GatewayUpstream{{Type: GatewayUpstreamFunction, TargetID: &id, Weight: 1}, url("http://v1", 1)}}, true},
+		{"TooManyRetries", GatewayRoute{TargetURL: "http://svc", TrafficPolicy: GatewayTrafficPolicy{Retries: MaxGatewayRetries + 1}}, true},
+		{"NegativeTimeout", GatewayRoute{TargetURL: "http://svc", TrafficPolicy: GatewayTrafficPolicy{TimeoutMs: -1}}, true},
+		{"BreakerWithoutReset", GatewayRoute{TargetURL: "http://svc", TrafficPolicy: GatewayTrafficPolicy{CircuitBreaker: &GatewayCircuitBreakerConfig{FailureThreshold: 3}}}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.route.ValidateUpstreams()
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -66,3 +66,41 @@ type GlobalHealthCheckConfig struct {
 	HealthyCount   int    `json:"healthy_count"`
 	UnhealthyCount int    `json:"unhealthy_count"`
 }
+
+// GlobalEndpointHealthCheck is the result of one active probe of a global endpoint.
+type GlobalEndpointHealthCheck struct {
+	ID         uuid.UUID `json:"id"`
+	EndpointID uuid.UUID `json:"endpoint_id"`
+	GlobalLBID uuid.UUID `json:"global_lb_id"`
+	Healthy    bool      `json:"healthy"`               // Probe outcome, not the endpoint's health state
+	LatencyMs  int64     `json:"latency_ms"`            // Time until the probe succeeded or failed
+	StatusCode int       `json:"status_code,omitempty"` // HTTP status for HTTP/HTTPS probes
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// ServingEndpoints returns the endpoints DNS should answer with: the healthy
+// ones, narrowed to the best (lowest) priority for the FAILOVER policy.
+func (glb *GlobalLoadBalancer) ServingEndpoints(endpoints []*GlobalEndpoint) []GlobalEndpoint {
+	var healthy []GlobalEndpoint
+	for _, ep := range endpoints {
+		if ep.Healthy {
+			healthy = append(healthy, *ep)
+		}
+	}
+	if glb.Policy != RoutingFailover || len(healthy) == 0 {
+		return healthy
+	}
+
+	best := healthy[0].Priority
+	for _, ep := range healthy[1:] {
+		best = min(best, ep.Priority)
+	}
+	var primary []GlobalEndpoint
+	for _, ep := range healthy {
+		if ep.Priority == best {
+			primary = append(primary, ep)
+		}
+	}
+	return primary
+}
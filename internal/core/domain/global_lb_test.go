@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobalLoadBalancerServingEndpoints(t *testing.T) {
+	t.Parallel()
+	eps := []*GlobalEndpoint{
+		{Region: "primary-down", Priority: 1, Healthy: false},
+		{Region: "secondary-a", Priority: 2, Healthy: true},
+		{Region: "secondary-b", Priority: 2, Healthy: true},
+		{Region: "tertiary", Priority: 3, Healthy: true},
+	}
+
+	failover := &GlobalLoadBalancer{Policy: RoutingFailover}
+	serving := failover.ServingEndpoints(eps)
+	require.Len(t, serving, 2)
+	assert.Equal(t, "secondary-a", serving[0].Region)
+	assert.Equal(t, "secondary-b", serving[1].Region)
+
+	weighted := &GlobalLoadBalancer{Policy: RoutingWeighted}
+	assert.Len(t, weighted.ServingEndpoints(eps), 3)
+}
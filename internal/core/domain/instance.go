@@ -68,8 +68,9 @@ type Instance struct {
 	Ports        string            `json:"ports,omitempty"`  // "host:container" mappings
 	VpcID        *uuid.UUID        `json:"vpc_id,omitempty"` // Optional VPC attachment
 	SubnetID     *uuid.UUID        `json:"subnet_id,omitempty"`
-	PrivateIP    string            `json:"private_ip,omitempty"` // VPC private IP
-	OvsPort      string            `json:"ovs_port,omitempty"`   // OVS port name
+	PrivateIP    string            `json:"private_ip,omitempty"`   // VPC private IP
+	PrivateIPv6  string            `json:"private_ipv6,omitempty"` // VPC private IPv6 address, when the subnet is dual-stack
+	OvsPort      string            `json:"ovs_port,omitempty"`     // OVS port name
 	InstanceType string            `json:"instance_type,omitempty"`
 	VolumeBinds  []string          `json:"volume_binds,omitempty"`
 	Env          []string          `json:"env,omitempty"`
@@ -2,19 +2,227 @@
 package domain
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// LifecycleRule describes expiration and prefix rules for bucket objects.
+// LifecycleRule describes the expiration, transition and cleanup actions applied to
+// bucket objects whose key starts with Prefix and which carry all of Tags.
+// A zero day count disables the corresponding action.
 type LifecycleRule struct {
-	ID             uuid.UUID `json:"id"`
-	UserID         uuid.UUID `json:"user_id"`
-	BucketName     string    `json:"bucket_name"`
-	Prefix         string    `json:"prefix"`
-	ExpirationDays int       `json:"expiration_days"`
-	Enabled        bool      `json:"enabled"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID         uuid.UUID         `json:"id"`
+	UserID     uuid.UUID         `json:"user_id"`
+	BucketName string            `json:"bucket_name"`
+	Prefix     string            `json:"prefix"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	// ExpirationDays deletes the current version of an object this many days after it was written.
+	ExpirationDays int `json:"expiration_days"`
+	// NoncurrentExpirationDays deletes a version this many days after a newer version replaced it.
+	NoncurrentExpirationDays int `json:"noncurrent_expiration_days,omitempty"`
+	// NoncurrentVersionsToKeep retains the newest noncurrent versions of each key. On its own it
+	// deletes every older noncurrent version; with NoncurrentExpirationDays it exempts the newest
+	// versions from expiry.
+	NoncurrentVersionsToKeep int `json:"noncurrent_versions_to_keep,omitempty"`
+	// AbortIncompleteMultipartDays aborts multipart uploads left incomplete for this many days.
+	AbortIncompleteMultipartDays int `json:"abort_incomplete_multipart_days,omitempty"`
+	// TransitionDays moves versions to TransitionStorageClass this many days after they were written.
+	TransitionDays         int          `json:"transition_days,omitempty"`
+	TransitionStorageClass StorageClass `json:"transition_storage_class,omitempty"`
+	Enabled                bool         `json:"enabled"`
+	CreatedAt              time.Time    `json:"created_at"`
+	UpdatedAt              time.Time    `json:"updated_at"`
+}
+
+// Validate checks that the rule defines at least one action and that its actions are consistent.
+func (r *LifecycleRule) Validate() error {
+	days := []struct {
+		field string
+		n     int
+	}{
+		{"expiration_days", r.ExpirationDays},
+		{"noncurrent_expiration_days", r.NoncurrentExpirationDays},
+		{"noncurrent_versions_to_keep", r.NoncurrentVersionsToKeep},
+		{"abort_incomplete_multipart_days", r.AbortIncompleteMultipartDays},
+		{"transition_days", r.TransitionDays},
+	}
+	hasAction := false
+	for _, d := range days {
+		if d.n < 0 {
+			return fmt.Errorf("%s must not be negative", d.field)
+		}
+		if d.n > 0 {
+			hasAction = true
+		}
+	}
+	if !hasAction {
+		return fmt.Errorf("rule must define at least one action")
+	}
+
+	if r.TransitionDays > 0 || r.TransitionStorageClass != "" {
+		if r.TransitionDays == 0 {
+			return fmt.Errorf("transition_days is required with transition_storage_class")
+		}
+		// Erasure coding is the only class colder than the replicated default.
+		if r.TransitionStorageClass != StorageClassErasureCoded {
+			return fmt.Errorf("transition_storage_class must be %s", StorageClassErasureCoded)
+		}
+		if r.ExpirationDays > 0 && r.TransitionDays >= r.ExpirationDays {
+			return fmt.Errorf("transition_days must be less than expiration_days")
+		}
+	}
+
+	for k := range r.Tags {
+		if strings.TrimSpace(k) == "" {
+			return fmt.Errorf("tag keys must not be empty")
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the rule's prefix and tag filters select the object version.
+func (r *LifecycleRule) Matches(obj *Object) bool {
+	if !strings.HasPrefix(obj.Key, r.Prefix) {
+		return false
+	}
+	for k, v := range r.Tags {
+		if tag, ok := obj.Tags[k]; !ok || tag != v {
+			return false
+		}
+	}
+	return true
+}
+
+// PlanVersions returns the actions the rule takes on the versions of a single key,
+// which must be ordered newest first. Expiring the current version only replaces it with a
+// delete marker on versioned buckets, so it then counts as the newest noncurrent version.
+// Locked versions are never expired, and the key is not expired while any of its versions
+// is locked; they can still transition. Delete markers hold no data and are left alone.
+func (r *LifecycleRule) PlanVersions(versions []*Object, now time.Time) []LifecycleAction {
+	anyLocked := false
+	for _, v := range versions {
+		if v.IsLocked(now) {
+			anyLocked = true
+			break
+		}
+	}
+
+	var actions []LifecycleAction
+	noncurrent := 0
+	for i, v := range versions {
+		if v.IsDeleteMarker {
+			continue
+		}
+		if !v.IsLatest {
+			// A version becomes noncurrent when the next newer one is written.
+			since := v.CreatedAt
+			if i > 0 {
+				since = versions[i-1].CreatedAt
+			}
+			retained := noncurrent < r.NoncurrentVersionsToKeep
+			noncurrent++
+			if r.Matches(v) && !retained && !v.IsLocked(now) && r.expiresNoncurrent(since, now) {
+				actions = append(actions, newLifecycleAction(LifecycleActionExpireNoncurrent, v))
+				continue
+			}
+		} else if r.Matches(v) && !anyLocked && r.ExpirationDays > 0 && olderThanDays(v.CreatedAt, r.ExpirationDays, now) {
+			actions = append(actions, newLifecycleAction(LifecycleActionExpire, v))
+			noncurrent++
+			continue
+		}
+
+		if r.Matches(v) && r.TransitionDays > 0 && v.StorageClass != r.TransitionStorageClass &&
+			olderThanDays(v.CreatedAt, r.TransitionDays, now) {
+			action := newLifecycleAction(LifecycleActionTransition, v)
+			action.StorageClass = r.TransitionStorageClass
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+func (r *LifecycleRule) expiresNoncurrent(since, now time.Time) bool {
+	if r.NoncurrentExpirationDays > 0 {
+		return olderThanDays(since, r.NoncurrentExpirationDays, now)
+	}
+	return r.NoncurrentVersionsToKeep > 0
+}
+
+// AbortsUpload reports whether the rule aborts an incomplete multipart upload.
+func (r *LifecycleRule) AbortsUpload(upload *MultipartUpload, now time.Time) bool {
+	return r.AbortIncompleteMultipartDays > 0 &&
+		strings.HasPrefix(upload.Key, r.Prefix) &&
+		olderThanDays(upload.CreatedAt, r.AbortIncompleteMultipartDays, now)
+}
+
+func olderThanDays(t time.Time, days int, now time.Time) bool {
+	return now.Sub(t) > time.Duration(days)*24*time.Hour
+}
+
+// LifecycleActionType identifies what a lifecycle rule does to an object version or upload.
+type LifecycleActionType string
+
+const (
+	// LifecycleActionExpire removes the current version of an object, behind a delete marker on versioned buckets.
+	LifecycleActionExpire LifecycleActionType = "EXPIRE"
+	// LifecycleActionExpireNoncurrent permanently deletes a noncurrent version.
+	LifecycleActionExpireNoncurrent LifecycleActionType = "EXPIRE_NONCURRENT"
+	// LifecycleActionTransition rewrites a version in a colder storage class.
+	LifecycleActionTransition LifecycleActionType = "TRANSITION"
+	// LifecycleActionAbortMultipart aborts an incomplete multipart upload.
+	LifecycleActionAbortMultipart LifecycleActionType = "ABORT_MULTIPART_UPLOAD"
+)
+
+// LifecycleAction is a single action a lifecycle rule takes (or would take, in a dry run).
+type LifecycleAction struct {
+	Type         LifecycleActionType `json:"type"`
+	Key          string              `json:"key"`
+	VersionID    string              `json:"version_id,omitempty"`
+	UploadID     *uuid.UUID          `json:"upload_id,omitempty"`
+	SizeBytes    int64               `json:"size_bytes,omitempty"`
+	StorageClass StorageClass        `json:"storage_class,omitempty"` // Target class of a transition
+}
+
+func newLifecycleAction(t LifecycleActionType, obj *Object) LifecycleAction {
+	return LifecycleAction{Type: t, Key: obj.Key, VersionID: obj.VersionID, SizeBytes: obj.SizeBytes}
+}
+
+// NewAbortMultipartAction builds the action aborting an incomplete multipart upload.
+func NewAbortMultipartAction(upload *MultipartUpload) LifecycleAction {
+	id := upload.ID
+	return LifecycleAction{Type: LifecycleActionAbortMultipart, Key: upload.Key, UploadID: &id}
+}
+
+// LifecycleReport lists the actions a rule selects at EvaluatedAt, with per-type totals.
+type LifecycleReport struct {
+	RuleID              uuid.UUID         `json:"rule_id"`
+	Bucket              string            `json:"bucket"`
+	EvaluatedAt         time.Time         `json:"evaluated_at"`
+	Actions             []LifecycleAction `json:"actions"`
+	ObjectsExpired      int               `json:"objects_expired"`
+	NoncurrentExpired   int               `json:"noncurrent_versions_expired"`
+	ObjectsTransitioned int               `json:"objects_transitioned"`
+	UploadsAborted      int               `json:"multipart_uploads_aborted"`
+	BytesExpired        int64             `json:"bytes_expired"`
+}
+
+// Add records an action and updates the report's totals.
+func (r *LifecycleReport) Add(actions ...LifecycleAction) {
+	for _, a := range actions {
+		r.Actions = append(r.Actions, a)
+		switch a.Type {
+		case LifecycleActionExpire:
+			r.ObjectsExpired++
+			r.BytesExpired += a.SizeBytes
+		case LifecycleActionExpireNoncurrent:
+			r.NoncurrentExpired++
+			r.BytesExpired += a.SizeBytes
+		case LifecycleActionTransition:
+			r.ObjectsTransitioned++
+		case LifecycleActionAbortMultipart:
+			r.UploadsAborted++
+		}
+	}
 }
@@ -0,0 +1,178 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleRuleValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		rule    domain.LifecycleRule
+		wantErr string
+	}{
+		{"expiration only", domain.LifecycleRule{ExpirationDays: 30}, ""},
+		{"keep versions only", domain.LifecycleRule{NoncurrentVersionsToKeep: 3}, ""},
+		{"abort multipart only", domain.LifecycleRule{AbortIncompleteMultipartDays: 7}, ""},
+		{"transition before expiry", domain.LifecycleRule{TransitionDays: 30, TransitionStorageClass: domain.StorageClassErasureCoded, ExpirationDays: 90}, ""},
+		{"no action", domain.LifecycleRule{Prefix: "logs/"}, "at least one action"},
+		{"negative days", domain.LifecycleRule{ExpirationDays: 30, NoncurrentExpirationDays: -1}, "must not be negative"},
+		{"class without days", domain.LifecycleRule{ExpirationDays: 30, TransitionStorageClass: domain.StorageClassErasureCoded}, "transition_days is required"},
+		{"unsupported class", domain.LifecycleRule{TransitionDays: 30, TransitionStorageClass: domain.StorageClassStandard}, "must be ERASURE_CODED"},
+		{"transition after expiry", domain.LifecycleRule{TransitionDays: 90, TransitionStorageClass: domain.StorageClassErasureCoded, ExpirationDays: 30}, "less than expiration_days"},
+		{"empty tag key", domain.LifecycleRule{ExpirationDays: 30, Tags: map[string]string{" ": "x"}}, "tag keys"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLifecycleRuleMatches(t *testing.T) {
+	t.Parallel()
+	rule := domain.LifecycleRule{Prefix: "logs/", Tags: map[string]string{"env": "dev"}}
+
+	assert.True(t, rule.Matches(&domain.Object{Key: "logs/a", Tags: map[string]string{"env": "dev", "team": "x"}}))
+	assert.False(t, rule.Matches(&domain.Object{Key: "logs/a", Tags: map[string]string{"env": "prod"}}))
+	assert.False(t, rule.Matches(&domain.Object{Key: "logs/a"}))
+	assert.False(t, rule.Matches(&domain.Object{Key: "data/a", Tags: map[string]string{"env": "dev"}}))
+}
+
+func lifecycleVersions(now time.Time, ages ...int) []*domain.Object {
+	versions := make([]*domain.Object, len(ages))
+	for i, days := range ages {
+		versions[i] = &domain.Object{
+			Key:          "logs/app.log",
+			VersionID:    "v" + strings.Repeat("i", len(ages)-i),
+			IsLatest:     i == 0,
+			SizeBytes:    10,
+			StorageClass: domain.StorageClassStandard,
+			CreatedAt:    now.Add(-time.Duration(days) * 24 * time.Hour),
+		}
+	}
+	return versions
+}
+
+func actionTypes(actions []domain.LifecycleAction) []domain.LifecycleActionType {
+	types := make([]domain.LifecycleActionType, len(actions))
+	for i, a := range actions {
+		types[i] = a.Type
+	}
+	return types
+}
+
+func TestLifecycleRulePlanVersions(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+
+	t.Run("expired current version counts as noncurrent", func(t *testing.T) {
+		rule := domain.LifecycleRule{ExpirationDays: 30, NoncurrentVersionsToKeep: 2}
+		versions := lifecycleVersions(now, 40, 50, 60)
+		actions := rule.PlanVersions(versions, now)
+		assert.Equal(t, []domain.LifecycleActionType{domain.LifecycleActionExpire, domain.LifecycleActionExpireNoncurrent}, actionTypes(actions))
+		assert.Equal(t, versions[0].VersionID, actions[0].VersionID)
+		assert.Equal(t, versions[2].VersionID, actions[1].VersionID)
+	})
+
+	t.Run("delete markers are skipped", func(t *testing.T) {
+		rule := domain.LifecycleRule{ExpirationDays: 1, NoncurrentExpirationDays: 10}
+		// The marker hid the current version 5 days ago; the oldest was replaced 40 days ago.
+		versions := lifecycleVersions(now, 5, 40, 100)
+		versions[0].IsDeleteMarker = true
+		actions := rule.PlanVersions(versions, now)
+		assert.Len(t, actions, 1)
+		assert.Equal(t, domain.LifecycleActionExpireNoncurrent, actions[0].Type)
+		assert.Equal(t, versions[2].VersionID, actions[0].VersionID)
+	})
+
+	t.Run("noncurrent age counts from the newer version", func(t *testing.T) {
+		rule := domain.LifecycleRule{NoncurrentExpirationDays: 10}
+		// v2 was replaced 5 days ago, v1 was replaced 20 days ago.
+		versions := lifecycleVersions(now, 5, 20, 100)
+		actions := rule.PlanVersions(versions, now)
+		assert.Len(t, actions, 1)
+		assert.Equal(t, domain.LifecycleActionExpireNoncurrent, actions[0].Type)
+		assert.Equal(t, versions[2].VersionID, actions[0].VersionID)
+	})
+
+	t.Run("keeps newest noncurrent versions", func(t *testing.T) {
+		rule := domain.LifecycleRule{NoncurrentVersionsToKeep: 2}
+		versions := lifecycleVersions(now, 1, 2, 3, 4, 5)
+		actions := rule.PlanVersions(versions, now)
+		assert.Len(t, actions, 2)
+		assert.Equal(t, versions[3].VersionID, actions[0].VersionID)
+		assert.Equal(t, versions[4].VersionID, actions[1].VersionID)
+	})
+
+	t.Run("transitions old versions", func(t *testing.T) {
+		rule := domain.LifecycleRule{TransitionDays: 30, TransitionStorageClass: domain.StorageClassErasureCoded}
+		versions := lifecycleVersions(now, 10, 40)
+		versions = append(versions, &domain.Object{Key: "logs/app.log", VersionID: "old", StorageClass: domain.StorageClassErasureCoded, CreatedAt: now.AddDate(0, 0, -90)})
+		actions := rule.PlanVersions(versions, now)
+		assert.Len(t, actions, 1)
+		assert.Equal(t, domain.LifecycleActionTransition, actions[0].Type)
+		assert.Equal(t, domain.StorageClassErasureCoded, actions[0].StorageClass)
+		assert.Equal(t, versions[1].VersionID, actions[0].VersionID)
+	})
+
+	t.Run("tag filter", func(t *testing.T) {
+		rule := domain.LifecycleRule{ExpirationDays: 1, Tags: map[string]string{"env": "dev"}}
+		assert.Empty(t, rule.PlanVersions(lifecycleVersions(now, 10), now))
+	})
+
+	t.Run("locked versions are not expired", func(t *testing.T) {
+		until := now.Add(time.Hour)
+		versions := lifecycleVersions(now, 40, 50, 60)
+		versions[2].RetainUntil = &until
+		versions[2].RetentionMode = domain.RetentionCompliance
+
+		// The locked noncurrent version blocks expiring the key; the other noncurrent one still goes.
+		rule := domain.LifecycleRule{ExpirationDays: 30, NoncurrentVersionsToKeep: 0, NoncurrentExpirationDays: 1}
+		actions := rule.PlanVersions(versions, now)
+		assert.Len(t, actions, 1)
+		assert.Equal(t, domain.LifecycleActionExpireNoncurrent, actions[0].Type)
+		assert.Equal(t, versions[1].VersionID, actions[0].VersionID)
+	})
+}
+
+func TestLifecycleRuleAbortsUpload(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	rule := domain.LifecycleRule{Prefix: "big/", AbortIncompleteMultipartDays: 7}
+
+	assert.True(t, rule.AbortsUpload(&domain.MultipartUpload{Key: "big/a", CreatedAt: now.AddDate(0, 0, -8)}, now))
+	assert.False(t, rule.AbortsUpload(&domain.MultipartUpload{Key: "big/a", CreatedAt: now.AddDate(0, 0, -1)}, now))
+	assert.False(t, rule.AbortsUpload(&domain.MultipartUpload{Key: "small/a", CreatedAt: now.AddDate(0, 0, -8)}, now))
+	assert.False(t, (&domain.LifecycleRule{ExpirationDays: 1}).AbortsUpload(&domain.MultipartUpload{Key: "a", CreatedAt: now.AddDate(0, 0, -30)}, now))
+}
+
+func TestLifecycleReportAdd(t *testing.T) {
+	t.Parallel()
+	report := domain.LifecycleReport{RuleID: uuid.New()}
+	report.Add(
+		domain.LifecycleAction{Type: domain.LifecycleActionExpire, SizeBytes: 10},
+		domain.LifecycleAction{Type: domain.LifecycleActionExpireNoncurrent, SizeBytes: 5},
+		domain.LifecycleAction{Type: domain.LifecycleActionTransition, SizeBytes: 100},
+		domain.NewAbortMultipartAction(&domain.MultipartUpload{ID: uuid.New(), Key: "a"}),
+	)
+
+	assert.Len(t, report.Actions, 4)
+	assert.Equal(t, 1, report.ObjectsExpired)
+	assert.Equal(t, 1, report.NoncurrentExpired)
+	assert.Equal(t, 1, report.ObjectsTransitioned)
+	assert.Equal(t, 1, report.UploadsAborted)
+	assert.Equal(t, int64(15), report.BytesExpired)
+}
@@ -2,6 +2,10 @@
 package domain
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -35,16 +39,224 @@ type LoadBalancer struct {
 	Status         LBStatus  `json:"status"`
 	Version        int       `json:"version"` // Optimistic locking
 	CreatedAt      time.Time `json:"created_at"`
+
+	TLS       *LBTLSConfig `json:"tls,omitempty"`       // HTTPS listener, if enabled
+	Listeners []LBListener `json:"listeners,omitempty"` // Layer-7 listeners; see EffectiveListeners
+
+	HealthCheck                *HealthCheckConfig `json:"health_check,omitempty"`       // Nil checks that the target port accepts connections
+	DeregistrationDelaySeconds int                `json:"deregistration_delay_seconds"` // How long removed targets drain
+	StickySessions             *LBStickySessions  `json:"sticky_sessions,omitempty"`
+}
+
+// LBStickySessions pins each client to one target with a cookie the load
+// balancer issues. Clients stay on their target while the target set is
+// unchanged.
+type LBStickySessions struct {
+	CookieName      string `json:"cookie_name"`
+	DurationSeconds int    `json:"duration_seconds"` // Cookie lifetime
+}
+
+const (
+	// DefaultLBStickyCookie is the sticky session cookie unless one is named.
+	DefaultLBStickyCookie = "THECLOUD_LB"
+	// DefaultLBStickyDuration is the sticky session cookie lifetime in seconds.
+	DefaultLBStickyDuration = 86400
+	// MaxLBDeregistrationDelay bounds how long removed targets drain, in seconds.
+	MaxLBDeregistrationDelay = 3600
+)
+
+var lbCookieNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,64}$`)
+
+// Validate checks the sticky session settings, filling in defaults.
+func (s *LBStickySessions) Validate() error {
+	if s.CookieName == "" {
+		s.CookieName = DefaultLBStickyCookie
+	}
+	if s.DurationSeconds == 0 {
+		s.DurationSeconds = DefaultLBStickyDuration
+	}
+	if !lbCookieNamePattern.MatchString(s.CookieName) {
+		return fmt.Errorf("cookie name must be 1-64 letters, digits or underscores")
+	}
+	if s.DurationSeconds < 1 || s.DurationSeconds > 7*24*3600 {
+		return fmt.Errorf("sticky session duration must be between 1 second and 7 days")
+	}
+	return nil
+}
+
+// LBTLSConfig configures a load balancer's HTTPS listener. The listener
+// terminates TLS with the certificate matching the client's SNI server name,
+// falling back to the first certificate.
+type LBTLSConfig struct {
+	Port           int         `json:"port"`
+	CertificateIDs []uuid.UUID `json:"certificate_ids"`
+	RedirectHTTP   bool        `json:"redirect_http"` // Redirect the plain HTTP listener to HTTPS
+
+	// Certificates holds the key pairs of CertificateIDs. The LB worker
+	// resolves them for the proxy adapter; they are never stored.
+	Certificates []*CertificateKeyPair `json:"-"`
+}
+
+// DefaultLBTargetGroup is the target group targets join when none is named,
+// and the group a listener forwards to when no rule matches.
+const DefaultLBTargetGroup = "default"
+
+const (
+	// MaxLBListeners limits the additional listeners of a load balancer.
+	MaxLBListeners = 10
+	// MaxLBListenerRules limits the rules of a single listener.
+	MaxLBListenerRules = 50
+)
+
+// LBListener accepts plain HTTP traffic on a port and forwards each request
+// to the target group of the first matching rule, in priority order, or to
+// DefaultTargetGroup. A listener on the load balancer's own port replaces its
+// default routing; the HTTPS listener applies the same rules.
+type LBListener struct {
+	Port               int              `json:"port"`
+	DefaultTargetGroup string           `json:"default_target_group,omitempty"`
+	Rules              []LBListenerRule `json:"rules,omitempty"`
+}
+
+// LBListenerRule routes requests matching all of its conditions to a target
+// group. At least one condition is required; lower priorities match first.
+type LBListenerRule struct {
+	Priority    int            `json:"priority"`
+	Host        string         `json:"host,omitempty"`        // Host header, e.g. "api.example.com" or "*.example.com"
+	PathPrefix  string         `json:"path_prefix,omitempty"` // e.g. "/api"
+	Header      *LBHeaderMatch `json:"header,omitempty"`      // Exact header value
+	TargetGroup string         `json:"target_group"`
 }
 
+// LBHeaderMatch matches requests whose header Name equals Value.
+type LBHeaderMatch struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+var (
+	lbTargetGroupPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,62})$`)
+	lbHostPattern        = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	lbPathPrefixPattern  = regexp.MustCompile(`^/[A-Za-z0-9._~!&'()*+,=:@%/-]*$`)
+	lbHeaderNamePattern  = regexp.MustCompile(`^[A-Za-z0-9-]{1,64}$`)
+	lbHeaderValuePattern = regexp.MustCompile(`^[A-Za-z0-9 ._~!&'()*+,=:@%/;?#<>|^-]{1,256}$`)
+)
+
+// ValidTargetGroupName reports whether name can name a target group.
+func ValidTargetGroupName(name string) bool {
+	return lbTargetGroupPattern.MatchString(name)
+}
+
+// ValidateListeners checks the load balancer's listeners. Values end up in
+// the proxy configuration, so hosts, paths and headers are restricted to
+// characters that need no escaping there.
+func (lb *LoadBalancer) ValidateListeners() error {
+	if len(lb.Listeners) > MaxLBListeners {
+		return fmt.Errorf("at most %d listeners are allowed", MaxLBListeners)
+	}
+	ports := make(map[int]bool, len(lb.Listeners))
+	for _, l := range lb.Listeners {
+		if l.Port < 1 || l.Port > 65535 {
+			return fmt.Errorf("listener port %d must be between 1 and 65535", l.Port)
+		}
+		if ports[l.Port] {
+			return fmt.Errorf("duplicate listener port %d", l.Port)
+		}
+		ports[l.Port] = true
+		if lb.TLS != nil && lb.TLS.Port == l.Port {
+			return fmt.Errorf("listener port %d is used by the https listener", l.Port)
+		}
+		if l.DefaultTargetGroup != "" && !ValidTargetGroupName(l.DefaultTargetGroup) {
+			return fmt.Errorf("listener %d: invalid default target group %q", l.Port, l.DefaultTargetGroup)
+		}
+		if err := l.validateRules(); err != nil {
+			return fmt.Errorf("listener %d: %w", l.Port, err)
+		}
+	}
+	return nil
+}
+
+func (l *LBListener) validateRules() error {
+	if len(l.Rules) > MaxLBListenerRules {
+		return fmt.Errorf("at most %d rules are allowed", MaxLBListenerRules)
+	}
+	priorities := make(map[int]bool, len(l.Rules))
+	for _, r := range l.Rules {
+		if r.Priority < 1 {
+			return fmt.Errorf("rule priority must be positive")
+		}
+		if priorities[r.Priority] {
+			return fmt.Errorf("duplicate rule priority %d", r.Priority)
+		}
+		priorities[r.Priority] = true
+		if !ValidTargetGroupName(r.TargetGroup) {
+			return fmt.Errorf("rule %d: invalid target group %q", r.Priority, r.TargetGroup)
+		}
+		if r.Host == "" && r.PathPrefix == "" && r.Header == nil {
+			return fmt.Errorf("rule %d: a host, path prefix or header condition is required", r.Priority)
+		}
+		if r.Host != "" && (len(r.Host) > 253 || !lbHostPattern.MatchString(r.Host)) {
+			return fmt.Errorf("rule %d: invalid host %q", r.Priority, r.Host)
+		}
+		if r.PathPrefix != "" && (len(r.PathPrefix) > 1024 || !lbPathPrefixPattern.MatchString(r.PathPrefix)) {
+			return fmt.Errorf("rule %d: invalid path prefix %q", r.Priority, r.PathPrefix)
+		}
+		if h := r.Header; h != nil && (!lbHeaderNamePattern.MatchString(h.Name) || !lbHeaderValuePattern.MatchString(h.Value)) {
+			return fmt.Errorf("rule %d: invalid header condition", r.Priority)
+		}
+	}
+	return nil
+}
+
+// EffectiveListeners returns the plain HTTP listeners the proxy serves: the
+// configured listeners plus, unless one of them uses the load balancer's
+// port, a listener there forwarding everything to the default target group.
+// Group names are defaulted, host names lowercased and rules sorted by
+// priority. The primary listener comes first.
+func (lb *LoadBalancer) EffectiveListeners() []LBListener {
+	primary := LBListener{Port: lb.Port}
+	var others []LBListener
+	for _, l := range lb.Listeners {
+		l.Rules = append([]LBListenerRule(nil), l.Rules...)
+		for i := range l.Rules {
+			l.Rules[i].Host = strings.ToLower(l.Rules[i].Host)
+		}
+		sort.SliceStable(l.Rules, func(i, j int) bool { return l.Rules[i].Priority < l.Rules[j].Priority })
+		if l.Port == lb.Port {
+			primary = l
+			continue
+		}
+		others = append(others, l)
+	}
+	listeners := append([]LBListener{primary}, others...)
+	for i := range listeners {
+		if listeners[i].DefaultTargetGroup == "" {
+			listeners[i].DefaultTargetGroup = DefaultLBTargetGroup
+		}
+	}
+	return listeners
+}
+
+// Target health states. Only healthy targets receive traffic.
+const (
+	LBTargetHealthUnknown   = "unknown"
+	LBTargetHealthHealthy   = "healthy"
+	LBTargetHealthUnhealthy = "unhealthy"
+	// LBTargetHealthDraining marks a removed target finishing its in-flight
+	// requests until DrainUntil, when it is deregistered.
+	LBTargetHealthDraining = "draining"
+)
+
 // LBTarget represents a backend instance that receives traffic.
 type LBTarget struct {
-	ID         uuid.UUID `json:"id"`
-	LBID       uuid.UUID `json:"lb_id"`
-	InstanceID uuid.UUID `json:"instance_id"`
-	Port       int       `json:"port"`
-	Weight     int       `json:"weight"` // Traffic share for weighted algorithms
-	Health     string    `json:"health"` // "healthy" | "unhealthy" | "unknown"
+	ID          uuid.UUID  `json:"id"`
+	LBID        uuid.UUID  `json:"lb_id"`
+	InstanceID  uuid.UUID  `json:"instance_id"`
+	Port        int        `json:"port"`
+	Weight      int        `json:"weight"`       // Traffic share for weighted algorithms
+	Health      string     `json:"health"`       // "healthy" | "unhealthy" | "unknown" | "draining"
+	TargetGroup string     `json:"target_group"` // Group listener rules route to
+	DrainUntil  *time.Time `json:"drain_until,omitempty"`
 }
 
 // HealthCheckConfig defines how the load balancer checks target health.
@@ -55,3 +267,43 @@ type HealthCheckConfig struct {
 	HealthyThreshold   int    `json:"healthy_threshold"`   // Successes needed for "healthy"
 	UnhealthyThreshold int    `json:"unhealthy_threshold"` // Failures needed for "unhealthy"
 }
+
+// Health check defaults for fields left zero.
+const (
+	DefaultLBHealthCheckInterval  = 10
+	DefaultLBHealthCheckTimeout   = 5
+	DefaultLBHealthCheckThreshold = 2
+)
+
+var lbHealthCheckPathPattern = regexp.MustCompile(`^/[A-Za-z0-9._~!$&'()*+,;=:@%/?-]*$`)
+
+// Validate checks the health check settings, filling in defaults. An empty
+// Path checks that the target port accepts TCP connections; otherwise the
+// path is requested over HTTP and 2xx and 3xx responses pass.
+func (h *HealthCheckConfig) Validate() error {
+	if h.IntervalSeconds == 0 {
+		h.IntervalSeconds = DefaultLBHealthCheckInterval
+	}
+	if h.TimeoutSeconds == 0 {
+		h.TimeoutSeconds = DefaultLBHealthCheckTimeout
+	}
+	if h.HealthyThreshold == 0 {
+		h.HealthyThreshold = DefaultLBHealthCheckThreshold
+	}
+	if h.UnhealthyThreshold == 0 {
+		h.UnhealthyThreshold = DefaultLBHealthCheckThreshold
+	}
+	if h.Path != "" && (len(h.Path) > 1024 || !lbHealthCheckPathPattern.MatchString(h.Path)) {
+		return fmt.Errorf("health check path must be an absolute url path")
+	}
+	if h.IntervalSeconds < 5 || h.IntervalSeconds > 300 {
+		return fmt.Errorf("health check interval must be between 5 and 300 seconds")
+	}
+	if h.TimeoutSeconds < 1 || h.TimeoutSeconds >= h.IntervalSeconds {
+		return fmt.Errorf("health check timeout must be at least 1 second and less than the interval")
+	}
+	if h.HealthyThreshold < 1 || h.HealthyThreshold > 10 || h.UnhealthyThreshold < 1 || h.UnhealthyThreshold > 10 {
+		return fmt.Errorf("health check thresholds must be between 1 and 10")
+	}
+	return nil
+}
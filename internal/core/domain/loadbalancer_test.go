@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadBalancerValidateListeners(t *testing.T) {
+	t.Parallel()
+	rule := func(r LBListenerRule) []LBListener {
+		if r.Priority == 0 {
+			r.Priority = 1
+		}
+		if r.TargetGroup == "" {
+			r.TargetGroup = "api"
+		}
+		return []LBListener{{Port: 8080, Rules: []LBListenerRule{r}}}
+	}
+	cases := []struct {
+		name      string
+		listeners []LBListener
+		wantErr   bool
+	}{
+		{"None", nil, false},
+		{"Host", rule(LBListenerRule{Host: "api.example.com"}), false},
+		{"WildcardHost", rule(LBListenerRule{Host: "*.example.com"}), false},
+		{"PathAndHeader", rule(LBListenerRule{PathPrefix: "/v1/", Header: &LBHeaderMatch{Name: "X-Canary", Value: "true"}}), false},
+		{"NoCondition", rule(LBListenerRule{}), true},
+		{"BadGroup", rule(LBListenerRule{Host: "a.example.com", TargetGroup: "API_v1"}), true},
+		{"HostInjection", rule(LBListenerRule{Host: "a.example.com\"; return 200;"}), true},
+		{"RelativePath", rule(LBListenerRule{PathPrefix: "api"}), true},
+		{"PathWithSpace", rule(LBListenerRule{PathPrefix: "/a b"}), true},
+		{"HeaderValueQuote", rule(LBListenerRule{Header: &LBHeaderMatch{Name: "X-A", Value: `a"b`}}), true},
+		{"HeaderNameUnderscore", rule(LBListenerRule{Header: &LBHeaderMatch{Name: "X_A", Value: "b"}}), true},
+		{"BadPort", []LBListener{{Port: 0}}, true},
+		{"DuplicatePort", []LBListener{{Port: 8080}, {Port: 8080}}, true},
+		{"TLSPort", []LBListener{{Port: 443}}, true},
+		{"DuplicatePriority", []LBListener{{Port: 8080, Rules: []LBListenerRule{
+			{Priority: 1, Host: "a.example.com", TargetGroup: "a"},
+			{Priority: 1, Host: "b.example.com", TargetGroup: "b"},
+		}}}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			lb := LoadBalancer{Port: 80, TLS: &LBTLSConfig{Port: 443}, Listeners: tc.listeners}
+			err := lb.ValidateListeners()
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoadBalancerEffectiveListeners(t *testing.T) {
+	t.Parallel()
+	lb := LoadBalancer{Port: 80}
+	assert.Equal(t, []LBListener{{Port: 80, DefaultTargetGroup: DefaultLBTargetGroup}}, lb.EffectiveListeners())
+
+	lb.Listeners = []LBListener{
+		{Port: 8080, DefaultTargetGroup: "admin"},
+		{Port: 80, Rules: []LBListenerRule{
+			{Priority: 20, PathPrefix: "/api", TargetGroup: "api"},
+			{Priority: 10, Host: "Static.Example.com", TargetGroup: "static"},
+		}},
+	}
+	got := lb.EffectiveListeners()
+	assert.Len(t, got, 2)
+	assert.Equal(t, 80, got[0].Port, "the primary listener comes first")
+	assert.Equal(t, DefaultLBTargetGroup, got[0].DefaultTargetGroup)
+	assert.Equal(t, "static.example.com", got[0].Rules[0].Host)
+	assert.Equal(t, "api", got[0].Rules[1].TargetGroup)
+	assert.Equal(t, "admin", got[1].DefaultTargetGroup)
+	assert.Equal(t, 20, lb.Listeners[1].Rules[0].Priority, "the configured listeners are not modified")
+}
+
+func TestHealthCheckConfigValidate(t *testing.T) {
+	t.Parallel()
+	hc := HealthCheckConfig{Path: "/healthz?full=1"}
+	assert.NoError(t, hc.Validate())
+	assert.Equal(t, HealthCheckConfig{
+		Path: "/healthz?full=1", IntervalSeconds: DefaultLBHealthCheckInterval, TimeoutSeconds: DefaultLBHealthCheckTimeout,
+		HealthyThreshold: DefaultLBHealthCheckThreshold, UnhealthyThreshold: DefaultLBHealthCheckThreshold,
+	}, hc)
+
+	for name, bad := range map[string]HealthCheckConfig{
+		"RelativePath":     {Path: "healthz"},
+		"PathWithSpace":    {Path: "/a b"},
+		"ShortInterval":    {IntervalSeconds: 1},
+		"TimeoutInterval":  {IntervalSeconds: 10, TimeoutSeconds: 10},
+		"ZeroThreshold":    {HealthyThreshold: -1},
+		"ThresholdTooHigh": {UnhealthyThreshold: 11},
+	} {
+		assert.Error(t, bad.Validate(), name)
+	}
+}
+
+func TestLBStickySessionsValidate(t *testing.T) {
+	t.Parallel()
+	s := LBStickySessions{}
+	assert.NoError(t, s.Validate())
+	assert.Equal(t, LBStickySessions{CookieName: DefaultLBStickyCookie, DurationSeconds: DefaultLBStickyDuration}, s)
+
+	assert.Error(t, (&LBStickySessions{CookieName: "a;b"}).Validate())
+	assert.Error(t, (&LBStickySessions{DurationSeconds: 8 * 24 * 3600}).Validate())
+}
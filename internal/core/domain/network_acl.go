@@ -0,0 +1,85 @@
+// Package domain defines core business entities.
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxACLRuleNumber is the highest rule number a network ACL accepts. Rule numbers map to a
+// fixed OVS priority band above security group flows, which bounds how many fit.
+const MaxACLRuleNumber = 400
+
+// ACLAction decides what happens to traffic matching a network ACL rule.
+type ACLAction string
+
+const (
+	// ACLAllow lets matching traffic continue to security group evaluation.
+	ACLAllow ACLAction = "allow"
+	// ACLDeny drops matching traffic.
+	ACLDeny ACLAction = "deny"
+)
+
+// NetworkACLRule is a numbered, stateless allow or deny entry. Rules are evaluated in
+// ascending number order per direction and the first match decides; traffic matching no
+// rule is denied.
+type NetworkACLRule struct {
+	ID         uuid.UUID     `json:"id"`
+	ACLID      uuid.UUID     `json:"acl_id"`
+	RuleNumber int           `json:"rule_number"`
+	Direction  RuleDirection `json:"direction"`
+	Protocol   string        `json:"protocol"` // "tcp", "udp", "icmp" or "all"
+	PortMin    int           `json:"port_min,omitempty"`
+	PortMax    int           `json:"port_max,omitempty"`
+	CIDR       string        `json:"cidr"`
+	Action     ACLAction     `json:"action"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// Validate checks the rule's number, direction, protocol, ports, CIDR and action.
+func (r *NetworkACLRule) Validate() error {
+	if r.RuleNumber < 1 || r.RuleNumber > MaxACLRuleNumber {
+		return fmt.Errorf("rule number must be between 1 and %d", MaxACLRuleNumber)
+	}
+	if r.Direction != RuleIngress && r.Direction != RuleEgress {
+		return fmt.Errorf("invalid rule direction: %s", r.Direction)
+	}
+	switch r.Protocol {
+	case "tcp", "udp":
+		if r.PortMin < 1 || r.PortMax > 65535 || r.PortMin > r.PortMax {
+			return fmt.Errorf("invalid port range %d-%d", r.PortMin, r.PortMax)
+		}
+	case "icmp", "all":
+		if r.PortMin != 0 || r.PortMax != 0 {
+			return fmt.Errorf("%s rules take no ports", r.Protocol)
+		}
+	default:
+		return fmt.Errorf("invalid protocol: %s", r.Protocol)
+	}
+	if _, _, err := net.ParseCIDR(r.CIDR); err != nil {
+		return fmt.Errorf("invalid CIDR: %w", err)
+	}
+	if r.Action != ACLAllow && r.Action != ACLDeny {
+		return errors.New("action must be allow or deny")
+	}
+	return nil
+}
+
+// NetworkACL is a stateless firewall applied at the subnet boundary, a second layer beside
+// the security groups of the subnet's instances. A subnet uses at most one ACL; subnets
+// without one are not filtered at this layer.
+type NetworkACL struct {
+	ID        uuid.UUID        `json:"id"`
+	UserID    uuid.UUID        `json:"user_id"`
+	TenantID  uuid.UUID        `json:"tenant_id"`
+	VPCID     uuid.UUID        `json:"vpc_id"`
+	Name      string           `json:"name"`
+	Rules     []NetworkACLRule `json:"rules"`
+	SubnetIDs []uuid.UUID      `json:"subnet_ids"`
+	ARN       string           `json:"arn"`
+	CreatedAt time.Time        `json:"created_at"`
+}
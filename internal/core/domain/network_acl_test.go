@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkACLRuleValidate(t *testing.T) {
+	t.Parallel()
+	valid := NetworkACLRule{
+		RuleNumber: 100, Direction: RuleIngress, Protocol: "tcp", PortMin: 1024, PortMax: 65535, CIDR: anyIPv4, Action: ACLAllow,
+	}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		mutate func(r *NetworkACLRule)
+		msg    string
+	}{
+		{"rule number too low", func(r *NetworkACLRule) { r.RuleNumber = 0 }, "rule number"},
+		{"rule number too high", func(r *NetworkACLRule) { r.RuleNumber = MaxACLRuleNumber + 1 }, "rule number"},
+		{"bad direction", func(r *NetworkACLRule) { r.Direction = "sideways" }, "direction"},
+		{"bad protocol", func(r *NetworkACLRule) { r.Protocol = "sctp" }, "protocol"},
+		{"inverted ports", func(r *NetworkACLRule) { r.PortMin, r.PortMax = 90, 80 }, "port range"},
+		{"ports on icmp", func(r *NetworkACLRule) { r.Protocol = "icmp" }, "take no ports"},
+		{"bad cidr", func(r *NetworkACLRule) { r.CIDR = "10.0.0.0" }, "invalid CIDR"},
+		{"bad action", func(r *NetworkACLRule) { r.Action = "reject" }, "allow or deny"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			tt.mutate(&r)
+			err := r.Validate()
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetentionMode controls who can shorten or remove an object version's retention.
+type RetentionMode string
+
+const (
+	// RetentionGovernance protects a version from deletion unless the caller explicitly
+	// bypasses governance retention and is allowed to.
+	RetentionGovernance RetentionMode = "GOVERNANCE"
+	// RetentionCompliance protects a version from deletion by anyone, the bucket owner
+	// included, until the retain-until date. The date can be extended but never shortened.
+	RetentionCompliance RetentionMode = "COMPLIANCE"
+)
+
+// MaxRetentionDays caps the default retention period of a bucket (100 years).
+const MaxRetentionDays = 36500
+
+// ObjectLockConfig is the object lock setting of a bucket. When DefaultDays is set, every
+// new object version is retained in DefaultMode for that many days.
+type ObjectLockConfig struct {
+	Enabled     bool          `json:"enabled"`
+	DefaultMode RetentionMode `json:"default_mode,omitempty"`
+	DefaultDays int           `json:"default_days,omitempty"`
+}
+
+// Validate checks the default retention. Defaults require object lock to be enabled.
+func (c ObjectLockConfig) Validate() error {
+	if c.DefaultDays == 0 && c.DefaultMode == "" {
+		return nil
+	}
+	if !c.Enabled {
+		return fmt.Errorf("default retention requires object lock to be enabled")
+	}
+	if err := c.DefaultMode.validate(); err != nil {
+		return err
+	}
+	if c.DefaultDays < 1 || c.DefaultDays > MaxRetentionDays {
+		return fmt.Errorf("default_days must be between 1 and %d", MaxRetentionDays)
+	}
+	return nil
+}
+
+// DefaultRetention returns the retention a version written at t receives, or nil when the
+// bucket has no default retention.
+func (c ObjectLockConfig) DefaultRetention(t time.Time) *ObjectRetention {
+	if !c.Enabled || c.DefaultDays == 0 {
+		return nil
+	}
+	return &ObjectRetention{Mode: c.DefaultMode, RetainUntil: t.AddDate(0, 0, c.DefaultDays)}
+}
+
+func (m RetentionMode) validate() error {
+	switch m {
+	case RetentionGovernance, RetentionCompliance:
+		return nil
+	}
+	return fmt.Errorf("retention mode must be %s or %s", RetentionGovernance, RetentionCompliance)
+}
+
+// ObjectRetention is the retention applied to a single object version.
+type ObjectRetention struct {
+	Mode        RetentionMode `json:"mode"`
+	RetainUntil time.Time     `json:"retain_until"`
+}
+
+// Validate checks the mode and that the retain-until date lies in the future.
+func (r ObjectRetention) Validate(now time.Time) error {
+	if err := r.Mode.validate(); err != nil {
+		return err
+	}
+	if !r.RetainUntil.After(now) {
+		return fmt.Errorf("retain_until must be in the future")
+	}
+	return nil
+}
+
+// Retention returns the version's retention, or nil when it has none.
+func (o *Object) Retention() *ObjectRetention {
+	if o.RetainUntil == nil {
+		return nil
+	}
+	return &ObjectRetention{Mode: o.RetentionMode, RetainUntil: *o.RetainUntil}
+}
+
+// RetentionActive reports whether the version is under retention at now.
+func (o *Object) RetentionActive(now time.Time) bool {
+	return o.RetainUntil != nil && now.Before(*o.RetainUntil)
+}
+
+// IsLocked reports whether the version is under a legal hold or active retention at now.
+func (o *Object) IsLocked(now time.Time) bool {
+	return o.LegalHold || o.RetentionActive(now)
+}
+
+// CheckDelete returns an error when the version cannot be permanently deleted at now.
+// bypassGovernance lifts GOVERNANCE retention but never a legal hold or COMPLIANCE retention.
+func (o *Object) CheckDelete(now time.Time, bypassGovernance bool) error {
+	if o.LegalHold {
+		return fmt.Errorf("version %s of %s is under a legal hold", o.VersionID, o.Key)
+	}
+	if !o.RetentionActive(now) {
+		return nil
+	}
+	if o.RetentionMode == RetentionGovernance && bypassGovernance {
+		return nil
+	}
+	return fmt.Errorf("version %s of %s is retained in %s mode until %s",
+		o.VersionID, o.Key, o.RetentionMode, o.RetainUntil.UTC().Format(time.RFC3339))
+}
+
+// CheckRetentionChange returns an error when the version's retention cannot be replaced by
+// next (nil removes it) at now. Active retention may only be extended, or tightened from
+// GOVERNANCE to COMPLIANCE, unless governance retention is bypassed.
+func (o *Object) CheckRetentionChange(next *ObjectRetention, now time.Time, bypassGovernance bool) error {
+	if !o.RetentionActive(now) {
+		return nil
+	}
+	if o.RetentionMode == RetentionGovernance && bypassGovernance {
+		return nil
+	}
+	if next == nil {
+		return fmt.Errorf("retention of %s cannot be removed before %s", o.Key, o.RetainUntil.UTC().Format(time.RFC3339))
+	}
+	if o.RetentionMode == RetentionCompliance && next.Mode != RetentionCompliance {
+		return fmt.Errorf("compliance retention cannot be changed to %s", next.Mode)
+	}
+	if next.RetainUntil.Before(*o.RetainUntil) {
+		return fmt.Errorf("retention of %s cannot be shortened", o.Key)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectLockConfigValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		cfg     domain.ObjectLockConfig
+		wantErr string
+	}{
+		{"disabled", domain.ObjectLockConfig{}, ""},
+		{"enabled without default", domain.ObjectLockConfig{Enabled: true}, ""},
+		{"seven year compliance", domain.ObjectLockConfig{Enabled: true, DefaultMode: domain.RetentionCompliance, DefaultDays: 2557}, ""},
+		{"default while disabled", domain.ObjectLockConfig{DefaultMode: domain.RetentionGovernance, DefaultDays: 1}, "requires object lock"},
+		{"unknown mode", domain.ObjectLockConfig{Enabled: true, DefaultMode: "STRICT", DefaultDays: 1}, "retention mode"},
+		{"mode without days", domain.ObjectLockConfig{Enabled: true, DefaultMode: domain.RetentionGovernance}, "default_days"},
+		{"too long", domain.ObjectLockConfig{Enabled: true, DefaultMode: domain.RetentionGovernance, DefaultDays: domain.MaxRetentionDays + 1}, "default_days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestObjectCheckDelete(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	future, past := now.Add(time.Hour), now.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		obj    domain.Object
+		bypass bool
+		locked bool
+	}{
+		{"unlocked", domain.Object{}, false, false},
+		{"expired retention", domain.Object{RetentionMode: domain.RetentionCompliance, RetainUntil: &past}, false, false},
+		{"governance", domain.Object{RetentionMode: domain.RetentionGovernance, RetainUntil: &future}, false, true},
+		{"governance bypassed", domain.Object{RetentionMode: domain.RetentionGovernance, RetainUntil: &future}, true, false},
+		{"compliance ignores bypass", domain.Object{RetentionMode: domain.RetentionCompliance, RetainUntil: &future}, true, true},
+		{"legal hold ignores bypass", domain.Object{LegalHold: true}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.obj.CheckDelete(now, tt.bypass)
+			assert.Equal(t, tt.locked, err != nil, "error: %v", err)
+		})
+	}
+}
+
+func TestObjectCheckRetentionChange(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	until := now.Add(24 * time.Hour)
+	compliance := domain.Object{Key: "k", RetentionMode: domain.RetentionCompliance, RetainUntil: &until}
+	governance := domain.Object{Key: "k", RetentionMode: domain.RetentionGovernance, RetainUntil: &until}
+	later := &domain.ObjectRetention{Mode: domain.RetentionCompliance, RetainUntil: until.Add(time.Hour)}
+	earlier := &domain.ObjectRetention{Mode: domain.RetentionGovernance, RetainUntil: until.Add(-time.Hour)}
+
+	assert.NoError(t, compliance.CheckRetentionChange(later, now, false))
+	assert.ErrorContains(t, compliance.CheckRetentionChange(nil, now, true), "cannot be removed")
+	assert.ErrorContains(t, compliance.CheckRetentionChange(&domain.ObjectRetention{Mode: domain.RetentionGovernance, RetainUntil: until.Add(time.Hour)}, now, true), "cannot be changed")
+	assert.NoError(t, governance.CheckRetentionChange(later, now, false), "governance can be tightened to compliance")
+	assert.ErrorContains(t, governance.CheckRetentionChange(earlier, now, false), "cannot be shortened")
+	assert.NoError(t, governance.CheckRetentionChange(nil, now, true))
+}
+
+func TestObjectLockConfigDefaultRetention(t *testing.T) {
+	t.Parallel()
+	written := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, domain.ObjectLockConfig{Enabled: true}.DefaultRetention(written))
+	r := domain.ObjectLockConfig{Enabled: true, DefaultMode: domain.RetentionCompliance, DefaultDays: 10}.DefaultRetention(written)
+	assert.Equal(t, domain.RetentionCompliance, r.Mode)
+	assert.Equal(t, written.AddDate(0, 0, 10), r.RetainUntil)
+}
@@ -22,6 +22,11 @@ const (
 	PermissionSSHKeyRead   Permission = "ssh_key:read"
 	PermissionSSHKeyDelete Permission = "ssh_key:delete"
 
+	// Certificate Permissions
+	PermissionCertificateCreate Permission = "certificate:create"
+	PermissionCertificateRead   Permission = "certificate:read"
+	PermissionCertificateDelete Permission = "certificate:delete"
+
 	// VPC Permissions
 	PermissionVpcCreate Permission = "vpc:create"
 	PermissionVpcDelete Permission = "vpc:delete"
@@ -0,0 +1,90 @@
+// Package domain defines core business entities.
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RouteTargetType identifies where a route sends matching traffic.
+type RouteTargetType string
+
+const (
+	// RouteTargetLocal keeps traffic inside the VPC. Every route table has one, for the VPC's CIDR.
+	RouteTargetLocal RouteTargetType = "local"
+	// RouteTargetInternetGateway sends traffic out through the VPC's internet gateway.
+	RouteTargetInternetGateway RouteTargetType = "internet-gateway"
+	// RouteTargetNATGateway sends traffic through a NAT gateway for outbound-only access.
+	RouteTargetNATGateway RouteTargetType = "nat-gateway"
+)
+
+// Route sends traffic for a destination range to a target.
+type Route struct {
+	ID              uuid.UUID       `json:"id"`
+	RouteTableID    uuid.UUID       `json:"route_table_id"`
+	DestinationCIDR string          `json:"destination_cidr"`
+	TargetType      RouteTargetType `json:"target_type"`
+	TargetID        *uuid.UUID      `json:"target_id,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// Validate checks the route's destination and target.
+func (r *Route) Validate() error {
+	if _, _, err := net.ParseCIDR(r.DestinationCIDR); err != nil {
+		return fmt.Errorf("invalid destination CIDR: %w", err)
+	}
+	switch r.TargetType {
+	case RouteTargetLocal:
+		if r.TargetID != nil {
+			return errors.New("local routes have no target ID")
+		}
+	case RouteTargetInternetGateway, RouteTargetNATGateway:
+		if r.TargetID == nil || *r.TargetID == uuid.Nil {
+			return fmt.Errorf("%s routes require a target ID", r.TargetType)
+		}
+	default:
+		return fmt.Errorf("invalid route target type: %s", r.TargetType)
+	}
+	return nil
+}
+
+// PrefixLength returns the number of leading bits in the route's destination, used to
+// prefer the most specific route.
+func (r *Route) PrefixLength() int {
+	_, n, err := net.ParseCIDR(r.DestinationCIDR)
+	if err != nil {
+		return 0
+	}
+	ones, _ := n.Mask.Size()
+	return ones
+}
+
+// RouteTable holds the routes applied to traffic leaving its associated subnets. Each VPC
+// has one main table, used by subnets without an explicit association.
+type RouteTable struct {
+	ID        uuid.UUID   `json:"id"`
+	UserID    uuid.UUID   `json:"user_id"`
+	TenantID  uuid.UUID   `json:"tenant_id"`
+	VPCID     uuid.UUID   `json:"vpc_id"`
+	Name      string      `json:"name"`
+	Main      bool        `json:"main"`
+	Routes    []Route     `json:"routes"`
+	SubnetIDs []uuid.UUID `json:"subnet_ids"`
+	ARN       string      `json:"arn"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// IsPublic reports whether the table routes traffic to an internet gateway. Subnets using
+// a public table are public subnets; all others are private.
+func (rt *RouteTable) IsPublic() bool {
+	for _, r := range rt.Routes {
+		if r.TargetType == RouteTargetInternetGateway {
+			return true
+		}
+	}
+	return false
+}
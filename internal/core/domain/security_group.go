@@ -39,15 +39,16 @@ func (sg *SecurityGroup) Validate() error {
 
 // SecurityRule defines a single traffic filtering criteria.
 type SecurityRule struct {
-	ID        uuid.UUID     `json:"id"`
-	GroupID   uuid.UUID     `json:"group_id"`
-	Direction RuleDirection `json:"direction"`
-	Protocol  string        `json:"protocol"` // Traffic protocol (e.g., "tcp", "udp", "icmp", "all")
-	PortMin   int           `json:"port_min,omitempty"`
-	PortMax   int           `json:"port_max,omitempty"`
-	CIDR      string        `json:"cidr"`     // Targeted IPv4 range (e.g., "0.0.0.0/0")
-	Priority  int           `json:"priority"` // Evaluation order (lower values evaluated first)
-	CreatedAt time.Time     `json:"created_at"`
+	ID            uuid.UUID     `json:"id"`
+	GroupID       uuid.UUID     `json:"group_id"`
+	Direction     RuleDirection `json:"direction"`
+	Protocol      string        `json:"protocol"` // Traffic protocol (e.g., "tcp", "udp", "icmp", "all")
+	PortMin       int           `json:"port_min,omitempty"`
+	PortMax       int           `json:"port_max,omitempty"`
+	CIDR          string        `json:"cidr"`                      // Targeted IPv4 range (e.g., "0.0.0.0/0")
+	SourceGroupID *uuid.UUID    `json:"source_group_id,omitempty"` // Peer group used instead of CIDR: source for ingress, destination for egress
+	Priority      int           `json:"priority"`                  // Evaluation order (lower values evaluated first)
+	CreatedAt     time.Time     `json:"created_at"`
 }
 
 // Validate checks if the security rule fields are valid.
@@ -99,8 +100,14 @@ func (sr *SecurityRule) validatePorts() error {
 }
 
 func (sr *SecurityRule) validateCIDR() error {
+	if sr.SourceGroupID != nil {
+		if sr.CIDR != "" {
+			return errors.New("a rule takes either a CIDR or a source group, not both")
+		}
+		return nil
+	}
 	if sr.CIDR == "" {
-		return errors.New("CIDR is required")
+		return errors.New("CIDR is required unless a source group is given")
 	}
 	_, _, err := net.ParseCIDR(sr.CIDR)
 	if err != nil {
@@ -78,6 +78,7 @@ func TestSecurityGroupValidate(t *testing.T) {
 
 func TestSecurityRuleValidate(t *testing.T) {
 	t.Parallel()
+	groupID := uuid.New()
 	tests := []struct {
 		name    string
 		rule    SecurityRule
@@ -204,6 +205,30 @@ func TestSecurityRuleValidate(t *testing.T) {
 			wantErr: true,
 			msg:     "CIDR is required",
 		},
+		{
+			name: "source group instead of cidr",
+			rule: SecurityRule{
+				Direction:     RuleIngress,
+				Protocol:      "tcp",
+				PortMin:       5432,
+				PortMax:       5432,
+				SourceGroupID: &groupID,
+			},
+			wantErr: false,
+		},
+		{
+			name: "source group with cidr",
+			rule: SecurityRule{
+				Direction:     RuleIngress,
+				Protocol:      "tcp",
+				PortMin:       5432,
+				PortMax:       5432,
+				CIDR:          anyIPv4,
+				SourceGroupID: &groupID,
+			},
+			wantErr: true,
+			msg:     "not both",
+		},
 	}
 
 	for _, tt := range tests {
@@ -2,38 +2,409 @@
 package domain
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"io"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// StorageClass identifies how an object's bytes are laid out across the storage cluster.
+type StorageClass string
+
+const (
+	// StorageClassStandard keeps full replicas of every object on the ring.
+	StorageClassStandard StorageClass = "STANDARD"
+	// StorageClassErasureCoded splits objects into Reed-Solomon data and parity shards.
+	StorageClassErasureCoded StorageClass = "ERASURE_CODED"
+)
+
+// Default erasure layout used when a bucket opts into erasure coding without specifying one.
+const (
+	DefaultDataShards   = 4
+	DefaultParityShards = 2
+	// MaxErasureShards bounds data+parity shards to keep shard placement on the ring practical.
+	MaxErasureShards = 32
+)
+
+// ErasureLayout describes how an erasure-coded object is split into shards.
+// Any DataShards of the DataShards+ParityShards shards are enough to rebuild the object.
+type ErasureLayout struct {
+	DataShards   int `json:"data_shards"`
+	ParityShards int `json:"parity_shards"`
+}
+
+// TotalShards returns the number of shards (and therefore nodes) an object is spread across.
+func (l ErasureLayout) TotalShards() int {
+	return l.DataShards + l.ParityShards
+}
+
+// ShardSize returns the size of each shard for an object of the given size.
+func (l ErasureLayout) ShardSize(size int64) int64 {
+	if l.DataShards <= 0 || size <= 0 {
+		return 0
+	}
+	return (size + int64(l.DataShards) - 1) / int64(l.DataShards)
+}
+
+// StoredBytes returns the raw bytes occupied on storage nodes, parity included.
+func (l ErasureLayout) StoredBytes(size int64) int64 {
+	return l.ShardSize(size) * int64(l.TotalShards())
+}
+
+// Validate checks that the layout can be encoded and tolerates at least one lost shard.
+func (l ErasureLayout) Validate() error {
+	if l.DataShards < 1 {
+		return fmt.Errorf("data_shards must be at least 1, got %d", l.DataShards)
+	}
+	if l.ParityShards < 1 {
+		return fmt.Errorf("parity_shards must be at least 1, got %d", l.ParityShards)
+	}
+	if l.TotalShards() > MaxErasureShards {
+		return fmt.Errorf("data_shards + parity_shards must not exceed %d", MaxErasureShards)
+	}
+	return nil
+}
+
 // Object represents stored object metadata in the storage subsystem.
 type Object struct {
-	ID          uuid.UUID  `json:"id"`
-	UserID      uuid.UUID  `json:"user_id"`
-	ARN         string     `json:"arn"`
-	Bucket      string     `json:"bucket"`
-	Key         string     `json:"key"`
-	VersionID   string     `json:"version_id"`
-	IsLatest    bool       `json:"is_latest"`
-	SizeBytes   int64      `json:"size_bytes"`
-	ContentType string     `json:"content_type"`
-	CreatedAt   time.Time  `json:"created_at"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
-	Data        io.Reader  `json:"-"` // Stream for reading/writing
+	ID             uuid.UUID         `json:"id"`
+	UserID         uuid.UUID         `json:"user_id"`
+	ARN            string            `json:"arn"`
+	Bucket         string            `json:"bucket"`
+	Key            string            `json:"key"`
+	VersionID      string            `json:"version_id"`
+	IsLatest       bool              `json:"is_latest"`
+	IsDeleteMarker bool              `json:"is_delete_marker,omitempty"` // Version without data that hides the key while current
+	SizeBytes      int64             `json:"size_bytes"`
+	StorageClass   StorageClass      `json:"storage_class"`
+	DataShards     int               `json:"data_shards,omitempty"`
+	ParityShards   int               `json:"parity_shards,omitempty"`
+	StoredBytes    int64             `json:"stored_bytes"` // Bytes held by storage nodes, including parity shards
+	ETag           string            `json:"etag"`         // Hex MD5 of the content, or "<md5-of-part-md5s>-<parts>" for multipart uploads
+	ChecksumSHA256 string            `json:"checksum_sha256,omitempty"`
+	ACL            ObjectACL         `json:"acl"`
+	Tags           map[string]string `json:"tags,omitempty"`
+	RetentionMode  RetentionMode     `json:"retention_mode,omitempty"`
+	RetainUntil    *time.Time        `json:"retain_until,omitempty"`
+	LegalHold      bool              `json:"legal_hold,omitempty"`
+	Encryption     ObjectEncryption  `json:"encryption,omitempty"`
+	KeyVersion     int               `json:"key_version,omitempty"`      // Bucket data key version an SSE object is sealed with
+	CustomerKeyMD5 string            `json:"customer_key_md5,omitempty"` // Base64 MD5 of the SSE-C key
+	ContentType    string            `json:"content_type"`
+	CreatedAt      time.Time         `json:"created_at"`
+	DeletedAt      *time.Time        `json:"deleted_at,omitempty"`
+	Data           io.Reader         `json:"-"` // Stream for reading/writing
+}
+
+// IsErasureCoded reports whether the object's data is stored as Reed-Solomon shards.
+func (o *Object) IsErasureCoded() bool {
+	return o.StorageClass == StorageClassErasureCoded
+}
+
+// ErasureLayout returns the shard layout the object was written with.
+func (o *Object) ErasureLayout() ErasureLayout {
+	return ErasureLayout{DataShards: o.DataShards, ParityShards: o.ParityShards}
+}
+
+// Limits on the tags an object version can carry.
+const (
+	MaxObjectTags        = 10
+	MaxObjectTagKeyLen   = 128
+	MaxObjectTagValueLen = 256
+)
+
+// ValidateObjectTags checks the number of tags and the length of their keys and values.
+func ValidateObjectTags(tags map[string]string) error {
+	if len(tags) > MaxObjectTags {
+		return fmt.Errorf("an object can carry at most %d tags", MaxObjectTags)
+	}
+	for k, v := range tags {
+		if k == "" || len(k) > MaxObjectTagKeyLen {
+			return fmt.Errorf("tag keys must be 1-%d characters", MaxObjectTagKeyLen)
+		}
+		if len(v) > MaxObjectTagValueLen {
+			return fmt.Errorf("tag %q: values must be at most %d characters", k, MaxObjectTagValueLen)
+		}
+	}
+	return nil
+}
+
+// LastModified returns the object's modification time at HTTP-date (second) precision.
+func (o *Object) LastModified() time.Time {
+	return o.CreatedAt.UTC().Truncate(time.Second)
+}
+
+// ErrRangeNotSatisfiable is returned by ParseByteRange when no requested byte lies within the object.
+var ErrRangeNotSatisfiable = errors.New("requested range not satisfiable")
+
+// ByteRange is an inclusive byte range of an object, as used by HTTP Range requests.
+type ByteRange struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// Length returns the number of bytes covered by the range.
+func (r ByteRange) Length() int64 {
+	return r.End - r.Start + 1
+}
+
+// ParseByteRange resolves a Range header value against an object of the given size.
+// It returns nil when the whole object should be served: no header, an unsupported
+// unit, multiple ranges or a malformed spec (which RFC 9110 says to ignore).
+func ParseByteRange(spec string, size int64) (*ByteRange, error) {
+	const unit = "bytes="
+	if !strings.HasPrefix(spec, unit) {
+		return nil, nil
+	}
+	spec = strings.TrimSpace(strings.TrimPrefix(spec, unit))
+	if strings.Contains(spec, ",") {
+		return nil, nil
+	}
+
+	first, last, ok := strings.Cut(spec, "-")
+	if !ok {
+		return nil, nil
+	}
+
+	if first == "" {
+		// Suffix range: the final N bytes.
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return nil, nil
+		}
+		if n == 0 || size == 0 {
+			return nil, ErrRangeNotSatisfiable
+		}
+		if n > size {
+			n = size
+		}
+		return &ByteRange{Start: size - n, End: size - 1}, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return nil, nil
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return nil, nil
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+	if start >= size {
+		return nil, ErrRangeNotSatisfiable
+	}
+	return &ByteRange{Start: start, End: end}, nil
+}
+
+// PreconditionResult is the outcome of evaluating conditional request headers.
+type PreconditionResult int
+
+const (
+	// PreconditionPassed means the request should proceed.
+	PreconditionPassed PreconditionResult = iota
+	// PreconditionNotModified means a read should answer 304 Not Modified.
+	PreconditionNotModified
+	// PreconditionFailed means the request must be rejected with 412 Precondition Failed.
+	PreconditionFailed
+)
+
+// Preconditions holds the HTTP conditional headers sent with an object request.
+type Preconditions struct {
+	IfMatch           string     `json:"if_match,omitempty"`
+	IfNoneMatch       string     `json:"if_none_match,omitempty"`
+	IfModifiedSince   *time.Time `json:"if_modified_since,omitempty"`
+	IfUnmodifiedSince *time.Time `json:"if_unmodified_since,omitempty"`
+}
+
+// IsEmpty reports whether no condition is set.
+func (p Preconditions) IsEmpty() bool {
+	return p.IfMatch == "" && p.IfNoneMatch == "" && p.IfModifiedSince == nil && p.IfUnmodifiedSince == nil
+}
+
+// Evaluate applies the conditions to the current object, which is nil when the key
+// does not exist, following the evaluation order of RFC 9110 section 13.2.2. Reads
+// (GET/HEAD) answer a matching If-None-Match or an unmodified If-Modified-Since with
+// PreconditionNotModified; writes fail instead.
+func (p Preconditions) Evaluate(obj *Object, read bool) PreconditionResult {
+	if p.IfMatch != "" {
+		if obj == nil || !etagListMatches(p.IfMatch, obj.ETag, true) {
+			return PreconditionFailed
+		}
+	} else if p.IfUnmodifiedSince != nil && obj != nil && obj.LastModified().After(*p.IfUnmodifiedSince) {
+		return PreconditionFailed
+	}
+
+	if p.IfNoneMatch != "" {
+		if obj != nil && etagListMatches(p.IfNoneMatch, obj.ETag, false) {
+			if read {
+				return PreconditionNotModified
+			}
+			return PreconditionFailed
+		}
+	} else if read && p.IfModifiedSince != nil && obj != nil && !obj.LastModified().After(*p.IfModifiedSince) {
+		return PreconditionNotModified
+	}
+
+	return PreconditionPassed
+}
+
+// etagListMatches reports whether a comma-separated If-Match/If-None-Match list
+// contains etag or "*". Strong comparison (If-Match) never matches weak validators.
+func etagListMatches(list, etag string, strong bool) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if strong {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if etag != "" && strings.Trim(candidate, `"`) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ObjectContent is an object's metadata together with a stream of its bytes.
+type ObjectContent struct {
+	Object *Object       `json:"object"`
+	Body   io.ReadCloser `json:"-"`
+	// Size is the full length of the object's content.
+	Size int64 `json:"size"`
+	// Range is the slice of the content Body streams, or nil for the whole object.
+	Range *ByteRange `json:"range,omitempty"`
+}
+
+// GetObjectOptions controls a conditional and/or ranged object read.
+type GetObjectOptions struct {
+	VersionID     string        `json:"version_id,omitempty"`
+	Range         string        `json:"range,omitempty"` // Raw Range header, e.g. "bytes=0-1023"
+	Preconditions Preconditions `json:"preconditions"`
 }
 
 // Bucket represents a storage bucket configuration and metadata.
 type Bucket struct {
-	ID                uuid.UUID `json:"id"`
-	Name              string    `json:"name"`
-	UserID            uuid.UUID `json:"user_id"`
-	IsPublic          bool      `json:"is_public"`
-	VersioningEnabled bool      `json:"versioning_enabled"`
-	EncryptionEnabled bool      `json:"encryption_enabled"`
-	EncryptionKeyID   string    `json:"encryption_key_id,omitempty"`
-	CreatedAt         time.Time `json:"created_at"`
+	ID                uuid.UUID    `json:"id"`
+	Name              string       `json:"name"`
+	UserID            uuid.UUID    `json:"user_id"`
+	IsPublic          bool         `json:"is_public"`
+	VersioningEnabled bool         `json:"versioning_enabled"`
+	EncryptionEnabled bool         `json:"encryption_enabled"`
+	EncryptionKeyID   string       `json:"encryption_key_id,omitempty"`
+	StorageClass      StorageClass `json:"storage_class"`
+	DataShards        int          `json:"data_shards,omitempty"`
+	ParityShards      int          `json:"parity_shards,omitempty"`
+	// Object lock can only be enabled on versioned buckets and cannot be disabled again.
+	ObjectLockEnabled    bool          `json:"object_lock_enabled"`
+	DefaultRetentionMode RetentionMode `json:"default_retention_mode,omitempty"`
+	DefaultRetentionDays int           `json:"default_retention_days,omitempty"`
+	CreatedAt            time.Time     `json:"created_at"`
+}
+
+// ObjectLock returns the bucket's object lock configuration.
+func (b *Bucket) ObjectLock() ObjectLockConfig {
+	return ObjectLockConfig{Enabled: b.ObjectLockEnabled, DefaultMode: b.DefaultRetentionMode, DefaultDays: b.DefaultRetentionDays}
+}
+
+// ErasureLayout returns the shard layout new objects in the bucket are written with.
+func (b *Bucket) ErasureLayout() ErasureLayout {
+	return ErasureLayout{DataShards: b.DataShards, ParityShards: b.ParityShards}
+}
+
+const (
+	// DefaultMaxKeys is the page size used when a listing does not request one.
+	DefaultMaxKeys = 1000
+	// MaxListKeys caps the number of entries returned by a single listing page.
+	MaxListKeys = 1000
+)
+
+// ObjectListOptions controls a paginated object listing.
+type ObjectListOptions struct {
+	// Prefix restricts the listing to keys beginning with it.
+	Prefix string `json:"prefix,omitempty"`
+	// Delimiter groups keys sharing a prefix up to the next delimiter into CommonPrefixes.
+	Delimiter string `json:"delimiter,omitempty"`
+	// StartAfter begins the listing after this key. Ignored when ContinuationToken is set.
+	StartAfter string `json:"start_after,omitempty"`
+	// ContinuationToken resumes a listing from a previous page's NextContinuationToken.
+	ContinuationToken string `json:"continuation_token,omitempty"`
+	// MaxKeys limits the number of objects and common prefixes returned.
+	MaxKeys int `json:"max_keys,omitempty"`
+}
+
+// Limit returns MaxKeys clamped to the allowed page size.
+func (o ObjectListOptions) Limit() int {
+	switch {
+	case o.MaxKeys <= 0:
+		return DefaultMaxKeys
+	case o.MaxKeys > MaxListKeys:
+		return MaxListKeys
+	default:
+		return o.MaxKeys
+	}
+}
+
+// Marker returns the key after which the listing resumes.
+func (o ObjectListOptions) Marker() (string, error) {
+	if o.ContinuationToken == "" {
+		return o.StartAfter, nil
+	}
+	return DecodeContinuationToken(o.ContinuationToken)
+}
+
+// CommonPrefix returns the prefix that groups key under the listing's delimiter,
+// or an empty string when the key is listed on its own.
+func (o ObjectListOptions) CommonPrefix(key string) string {
+	if o.Delimiter == "" || len(key) < len(o.Prefix) {
+		return ""
+	}
+	rest := key[len(o.Prefix):]
+	idx := strings.Index(rest, o.Delimiter)
+	if idx < 0 {
+		return ""
+	}
+	return key[:len(o.Prefix)+idx+len(o.Delimiter)]
+}
+
+// ObjectListResult is one page of an object listing.
+type ObjectListResult struct {
+	Bucket                string    `json:"bucket"`
+	Prefix                string    `json:"prefix,omitempty"`
+	Delimiter             string    `json:"delimiter,omitempty"`
+	MaxKeys               int       `json:"max_keys"`
+	KeyCount              int       `json:"key_count"`
+	Objects               []*Object `json:"objects"`
+	CommonPrefixes        []string  `json:"common_prefixes,omitempty"`
+	IsTruncated           bool      `json:"is_truncated"`
+	NextContinuationToken string    `json:"next_continuation_token,omitempty"`
+}
+
+// EncodeContinuationToken turns the last listed key into an opaque pagination token.
+func EncodeContinuationToken(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// DecodeContinuationToken recovers the key encoded by EncodeContinuationToken.
+func DecodeContinuationToken(token string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", fmt.Errorf("invalid continuation token")
+	}
+	return string(raw), nil
 }
 
 // StorageNode describes a node in the storage cluster.
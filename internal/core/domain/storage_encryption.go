@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"crypto/md5" // #nosec G501 -- SSE-C key digests are integrity checks, as in S3
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ObjectEncryption is how an object version is encrypted at rest.
+type ObjectEncryption string
+
+const (
+	// EncryptionNone means the object is stored as uploaded.
+	EncryptionNone ObjectEncryption = ""
+	// EncryptionSSE means the object is sealed with a data key of its bucket.
+	EncryptionSSE ObjectEncryption = "SSE"
+	// EncryptionSSEC means the object is sealed with a key the client supplies on every request.
+	EncryptionSSEC ObjectEncryption = "SSE-C"
+)
+
+// CustomerKeySize is the length of an SSE-C key (AES-256).
+const CustomerKeySize = 32
+
+// ParseCustomerKey decodes a base64 SSE-C key and checks it against the optional base64 MD5
+// digest sent alongside it. It returns the key and its digest.
+func ParseCustomerKey(keyB64, md5B64 string) ([]byte, string, error) {
+	key, err := base64.StdEncoding.DecodeString(keyB64)
+	if err != nil || len(key) != CustomerKeySize {
+		return nil, "", fmt.Errorf("customer key must be %d base64-encoded bytes", CustomerKeySize)
+	}
+	digest := CustomerKeyMD5(key)
+	if md5B64 != "" && subtle.ConstantTimeCompare([]byte(md5B64), []byte(digest)) != 1 {
+		return nil, "", fmt.Errorf("customer key MD5 does not match the key")
+	}
+	return key, digest, nil
+}
+
+// CustomerKeyMD5 returns the base64 MD5 digest an SSE-C object records for its key.
+func CustomerKeyMD5(key []byte) string {
+	sum := md5.Sum(key) // #nosec G401
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// StorageReencryptionQueue is the task queue bucket re-encryption jobs are delivered through.
+const StorageReencryptionQueue = "storage_reencrypt"
+
+// StorageReencryptionJob asks for every SSE object of a bucket to be sealed again
+// with the bucket's latest data key.
+type StorageReencryptionJob struct {
+	Bucket string    `json:"bucket"`
+	UserID uuid.UUID `json:"user_id"` // Bucket owner the objects are read as
+}
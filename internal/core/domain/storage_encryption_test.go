@@ -0,0 +1,34 @@
+package domain_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCustomerKey(t *testing.T) {
+	t.Parallel()
+	key := []byte(strings.Repeat("k", domain.CustomerKeySize))
+	keyB64 := base64.StdEncoding.EncodeToString(key)
+
+	got, digest, err := domain.ParseCustomerKey(keyB64, "")
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+	assert.Equal(t, domain.CustomerKeyMD5(key), digest)
+
+	_, _, err = domain.ParseCustomerKey(keyB64, digest)
+	assert.NoError(t, err)
+
+	_, _, err = domain.ParseCustomerKey(keyB64, domain.CustomerKeyMD5([]byte("other")))
+	assert.ErrorContains(t, err, "MD5")
+
+	_, _, err = domain.ParseCustomerKey(base64.StdEncoding.EncodeToString([]byte("short")), "")
+	assert.Error(t, err)
+
+	_, _, err = domain.ParseCustomerKey("not base64!", "")
+	assert.Error(t, err)
+}
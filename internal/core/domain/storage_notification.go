@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StorageEventName identifies an object change that bucket notifications can report.
+// Names ending in ":*" select every event of that kind.
+type StorageEventName string
+
+const (
+	// StorageEventObjectCreated matches every ObjectCreated event.
+	StorageEventObjectCreated StorageEventName = "ObjectCreated:*"
+	// StorageEventObjectCreatedPut is emitted when an object is uploaded in a single request.
+	StorageEventObjectCreatedPut StorageEventName = "ObjectCreated:Put"
+	// StorageEventObjectCreatedMultipart is emitted when a multipart upload completes.
+	StorageEventObjectCreatedMultipart StorageEventName = "ObjectCreated:CompleteMultipartUpload"
+	// StorageEventObjectRemoved matches every ObjectRemoved event.
+	StorageEventObjectRemoved StorageEventName = "ObjectRemoved:*"
+	// StorageEventObjectRemovedDelete is emitted when an object or one of its versions is deleted.
+	StorageEventObjectRemovedDelete StorageEventName = "ObjectRemoved:Delete"
+)
+
+// StorageNotificationQueue is the task queue bucket notifications are delivered through.
+const StorageNotificationQueue = "storage_notifications"
+
+// MaxNotificationRules caps the number of rules in a bucket notification configuration.
+const MaxNotificationRules = 100
+
+// Matches reports whether the event name (possibly a wildcard) selects the concrete event.
+func (e StorageEventName) Matches(event StorageEventName) bool {
+	if strings.HasSuffix(string(e), ":*") {
+		return strings.HasPrefix(string(event), strings.TrimSuffix(string(e), "*"))
+	}
+	return e == event
+}
+
+func (e StorageEventName) valid() bool {
+	switch e {
+	case StorageEventObjectCreated, StorageEventObjectCreatedPut, StorageEventObjectCreatedMultipart,
+		StorageEventObjectRemoved, StorageEventObjectRemovedDelete:
+		return true
+	}
+	return false
+}
+
+// NotificationTargetType is the kind of resource bucket events are delivered to.
+type NotificationTargetType string
+
+const (
+	// NotificationTargetQueue sends each event as a CloudQueue message.
+	NotificationTargetQueue NotificationTargetType = "QUEUE"
+	// NotificationTargetTopic publishes each event to a CloudNotify topic.
+	NotificationTargetTopic NotificationTargetType = "TOPIC"
+	// NotificationTargetFunction invokes a CloudFunction asynchronously with the event as payload.
+	NotificationTargetFunction NotificationTargetType = "FUNCTION"
+)
+
+// BucketNotificationRule routes the selected events on keys matching Prefix and Suffix to a target.
+// The target must belong to the bucket owner; events are delivered with the owner's identity.
+type BucketNotificationRule struct {
+	ID         string                 `json:"id"`
+	Events     []StorageEventName     `json:"events"`
+	Prefix     string                 `json:"prefix,omitempty"`
+	Suffix     string                 `json:"suffix,omitempty"`
+	TargetType NotificationTargetType `json:"target_type"`
+	TargetID   uuid.UUID              `json:"target_id"`
+}
+
+// Matches reports whether the rule selects the event on key.
+func (r BucketNotificationRule) Matches(event StorageEventName, key string) bool {
+	if !strings.HasPrefix(key, r.Prefix) || !strings.HasSuffix(key, r.Suffix) {
+		return false
+	}
+	for _, e := range r.Events {
+		if e.Matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// BucketNotificationConfig lists the notification rules of a bucket.
+type BucketNotificationConfig struct {
+	Bucket    string                   `json:"bucket"`
+	Rules     []BucketNotificationRule `json:"rules"`
+	UpdatedAt time.Time                `json:"updated_at"`
+}
+
+// Validate checks that every rule is well formed and that rule IDs are unique.
+func (c *BucketNotificationConfig) Validate() error {
+	if len(c.Rules) == 0 {
+		return fmt.Errorf("configuration must contain at least one rule")
+	}
+	if len(c.Rules) > MaxNotificationRules {
+		return fmt.Errorf("configuration can contain at most %d rules", MaxNotificationRules)
+	}
+	ids := make(map[string]bool, len(c.Rules))
+	for i, r := range c.Rules {
+		if r.ID != "" {
+			if ids[r.ID] {
+				return fmt.Errorf("rule %d: duplicate id %q", i, r.ID)
+			}
+			ids[r.ID] = true
+		}
+		if len(r.Events) == 0 {
+			return fmt.Errorf("rule %d: at least one event is required", i)
+		}
+		for _, e := range r.Events {
+			if !e.valid() {
+				return fmt.Errorf("rule %d: unknown event %q", i, e)
+			}
+		}
+		switch r.TargetType {
+		case NotificationTargetQueue, NotificationTargetTopic, NotificationTargetFunction:
+		default:
+			return fmt.Errorf("rule %d: target_type must be %s, %s or %s", i, NotificationTargetQueue, NotificationTargetTopic, NotificationTargetFunction)
+		}
+		if r.TargetID == uuid.Nil {
+			return fmt.Errorf("rule %d: target_id is required", i)
+		}
+	}
+	return nil
+}
+
+// StorageEvent is the S3-style event document delivered to notification targets.
+type StorageEvent struct {
+	Records []StorageEventRecord `json:"Records"`
+}
+
+// StorageEventRecord describes a single object change.
+type StorageEventRecord struct {
+	EventVersion string              `json:"eventVersion"`
+	EventSource  string              `json:"eventSource"`
+	EventTime    time.Time           `json:"eventTime"`
+	EventName    StorageEventName    `json:"eventName"`
+	UserIdentity StorageEventUser    `json:"userIdentity"`
+	S3           StorageEventDetails `json:"s3"`
+}
+
+// StorageEventUser identifies who caused an event.
+type StorageEventUser struct {
+	PrincipalID string `json:"principalId"`
+}
+
+// StorageEventDetails carries the rule, bucket and object an event refers to.
+type StorageEventDetails struct {
+	SchemaVersion   string             `json:"s3SchemaVersion"`
+	ConfigurationID string             `json:"configurationId"`
+	Bucket          StorageEventBucket `json:"bucket"`
+	Object          StorageEventObject `json:"object"`
+}
+
+// StorageEventBucket identifies the bucket of an event.
+type StorageEventBucket struct {
+	Name          string           `json:"name"`
+	OwnerIdentity StorageEventUser `json:"ownerIdentity"`
+	ARN           string           `json:"arn"`
+}
+
+// StorageEventObject identifies the object of an event. Size and ETag are omitted for removals.
+type StorageEventObject struct {
+	Key       string `json:"key"`
+	Size      int64  `json:"size,omitempty"`
+	ETag      string `json:"eTag,omitempty"`
+	VersionID string `json:"versionId,omitempty"`
+	Sequencer string `json:"sequencer"`
+}
+
+// StorageNotificationJob is a single event queued for delivery to a notification target.
+type StorageNotificationJob struct {
+	UserID     uuid.UUID              `json:"user_id"` // Bucket owner the target is accessed as
+	TargetType NotificationTargetType `json:"target_type"`
+	TargetID   uuid.UUID              `json:"target_id"`
+	Event      StorageEvent           `json:"event"`
+}
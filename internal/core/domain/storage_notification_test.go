@@ -0,0 +1,86 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageEventNameMatches(t *testing.T) {
+	t.Parallel()
+	assert.True(t, domain.StorageEventObjectCreated.Matches(domain.StorageEventObjectCreatedPut))
+	assert.True(t, domain.StorageEventObjectCreated.Matches(domain.StorageEventObjectCreatedMultipart))
+	assert.False(t, domain.StorageEventObjectCreated.Matches(domain.StorageEventObjectRemovedDelete))
+	assert.True(t, domain.StorageEventObjectCreatedPut.Matches(domain.StorageEventObjectCreatedPut))
+	assert.False(t, domain.StorageEventObjectCreatedPut.Matches(domain.StorageEventObjectCreatedMultipart))
+}
+
+func TestBucketNotificationRuleMatches(t *testing.T) {
+	t.Parallel()
+	rule := domain.BucketNotificationRule{
+		Events: []domain.StorageEventName{domain.StorageEventObjectCreated},
+		Prefix: "images/",
+		Suffix: ".jpg",
+	}
+
+	assert.True(t, rule.Matches(domain.StorageEventObjectCreatedPut, "images/cat.jpg"))
+	assert.False(t, rule.Matches(domain.StorageEventObjectCreatedPut, "docs/cat.jpg"))
+	assert.False(t, rule.Matches(domain.StorageEventObjectCreatedPut, "images/cat.png"))
+	assert.False(t, rule.Matches(domain.StorageEventObjectRemovedDelete, "images/cat.jpg"))
+}
+
+func TestBucketNotificationConfigValidate(t *testing.T) {
+	t.Parallel()
+	valid := func() domain.BucketNotificationRule {
+		return domain.BucketNotificationRule{
+			ID:         "r1",
+			Events:     []domain.StorageEventName{domain.StorageEventObjectRemoved},
+			TargetType: domain.NotificationTargetQueue,
+			TargetID:   uuid.New(),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		rules   func() []domain.BucketNotificationRule
+		wantErr string
+	}{
+		{"valid", func() []domain.BucketNotificationRule { return []domain.BucketNotificationRule{valid()} }, ""},
+		{"empty", func() []domain.BucketNotificationRule { return nil }, "at least one rule"},
+		{"duplicate id", func() []domain.BucketNotificationRule { return []domain.BucketNotificationRule{valid(), valid()} }, "duplicate id"},
+		{"no events", func() []domain.BucketNotificationRule {
+			r := valid()
+			r.Events = nil
+			return []domain.BucketNotificationRule{r}
+		}, "at least one event"},
+		{"unknown event", func() []domain.BucketNotificationRule {
+			r := valid()
+			r.Events = []domain.StorageEventName{"ObjectRestore:*"}
+			return []domain.BucketNotificationRule{r}
+		}, "unknown event"},
+		{"bad target type", func() []domain.BucketNotificationRule {
+			r := valid()
+			r.TargetType = "WEBHOOK"
+			return []domain.BucketNotificationRule{r}
+		}, "target_type"},
+		{"missing target", func() []domain.BucketNotificationRule {
+			r := valid()
+			r.TargetID = uuid.Nil
+			return []domain.BucketNotificationRule{r}
+		}, "target_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := domain.BucketNotificationConfig{Rules: tt.rules()}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
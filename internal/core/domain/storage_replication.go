@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// MaxReplicationRules caps the number of rules in a bucket replication configuration.
+const MaxReplicationRules = 20
+
+// MaxReplicationAttempts is how often a replication is tried before it is marked FAILED.
+const MaxReplicationAttempts = 8
+
+// ReplicationStatus tracks the copy of one object version to one replication destination.
+type ReplicationStatus string
+
+const (
+	// ReplicationPending means the replication is waiting for (or retrying) delivery.
+	ReplicationPending ReplicationStatus = "PENDING"
+	// ReplicationCompleted means the destination holds the change.
+	ReplicationCompleted ReplicationStatus = "COMPLETED"
+	// ReplicationFailed means every attempt failed; the change is not retried automatically.
+	ReplicationFailed ReplicationStatus = "FAILED"
+)
+
+// ReplicationOperation is the change copied to the destination.
+type ReplicationOperation string
+
+const (
+	// ReplicationOperationPut copies a new object version.
+	ReplicationOperationPut ReplicationOperation = "PUT"
+	// ReplicationOperationDelete deletes the key at the destination.
+	ReplicationOperationDelete ReplicationOperation = "DELETE"
+)
+
+// ReplicationDestination is a bucket on another installation, reached through its storage API.
+type ReplicationDestination struct {
+	Endpoint string `json:"endpoint"`
+	Bucket   string `json:"bucket"`
+	// APIKey authenticates against the destination. It is stored encrypted with the
+	// bucket owner's key and is never returned by the API.
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// ReplicationRule copies new versions of keys under Prefix to a destination bucket.
+type ReplicationRule struct {
+	ID               string                 `json:"id"`
+	Prefix           string                 `json:"prefix,omitempty"`
+	Destination      ReplicationDestination `json:"destination"`
+	ReplicateDeletes bool                   `json:"replicate_deletes"`
+}
+
+// Matches reports whether the rule replicates key.
+func (r ReplicationRule) Matches(key string) bool {
+	return strings.HasPrefix(key, r.Prefix)
+}
+
+// BucketReplicationConfig lists the replication rules of a bucket.
+type BucketReplicationConfig struct {
+	Bucket    string            `json:"bucket"`
+	Rules     []ReplicationRule `json:"rules"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// Validate checks that every rule is well formed and that rule IDs are unique.
+func (c *BucketReplicationConfig) Validate() error {
+	if len(c.Rules) == 0 {
+		return fmt.Errorf("configuration must contain at least one rule")
+	}
+	if len(c.Rules) > MaxReplicationRules {
+		return fmt.Errorf("configuration can contain at most %d rules", MaxReplicationRules)
+	}
+	ids := make(map[string]bool, len(c.Rules))
+	for i, r := range c.Rules {
+		if r.ID != "" {
+			if ids[r.ID] {
+				return fmt.Errorf("rule %d: duplicate id %q", i, r.ID)
+			}
+			ids[r.ID] = true
+		}
+		u, err := url.Parse(r.Destination.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("rule %d: destination endpoint must be an http(s) URL", i)
+		}
+		if r.Destination.Bucket == "" {
+			return fmt.Errorf("rule %d: destination bucket is required", i)
+		}
+		if r.Destination.APIKey == "" {
+			return fmt.Errorf("rule %d: destination api_key is required", i)
+		}
+	}
+	return nil
+}
+
+// Redacted returns a copy of the configuration without destination credentials.
+func (c *BucketReplicationConfig) Redacted() *BucketReplicationConfig {
+	out := *c
+	out.Rules = make([]ReplicationRule, len(c.Rules))
+	for i, r := range c.Rules {
+		r.Destination.APIKey = ""
+		out.Rules[i] = r
+	}
+	return &out
+}
+
+// Rule returns the rule with the given ID, or nil.
+func (c *BucketReplicationConfig) Rule(id string) *ReplicationRule {
+	for i := range c.Rules {
+		if c.Rules[i].ID == id {
+			return &c.Rules[i]
+		}
+	}
+	return nil
+}
+
+// ObjectReplication is the replication state of one change to one destination.
+// Deletes are tracked per key, with an empty VersionID.
+type ObjectReplication struct {
+	Bucket        string               `json:"bucket"`
+	Key           string               `json:"key"`
+	VersionID     string               `json:"version_id,omitempty"`
+	RuleID        string               `json:"rule_id"`
+	Operation     ReplicationOperation `json:"operation"`
+	Status        ReplicationStatus    `json:"status"`
+	Attempts      int                  `json:"attempts"`
+	LastError     string               `json:"last_error,omitempty"`
+	NextAttemptAt time.Time            `json:"next_attempt_at"`
+	CreatedAt     time.Time            `json:"created_at"`
+	ReplicatedAt  *time.Time           `json:"replicated_at,omitempty"`
+}
+
+// RecordFailure counts a failed attempt and schedules a retry with exponential backoff,
+// marking the replication FAILED once MaxReplicationAttempts is reached.
+func (r *ObjectReplication) RecordFailure(err error, now time.Time) {
+	r.Attempts++
+	r.LastError = err.Error()
+	if r.Attempts >= MaxReplicationAttempts {
+		r.Status = ReplicationFailed
+		return
+	}
+	backoff := time.Duration(1<<r.Attempts) * 15 * time.Second
+	if backoff > time.Hour {
+		backoff = time.Hour
+	}
+	r.NextAttemptAt = now.Add(backoff)
+}
+
+// RecordSuccess marks the replication COMPLETED.
+func (r *ObjectReplication) RecordSuccess(now time.Time) {
+	r.Status = ReplicationCompleted
+	r.LastError = ""
+	r.ReplicatedAt = &now
+}
+
+// ReplicationBacklog summarises outstanding replications of a bucket.
+type ReplicationBacklog struct {
+	Bucket        string     `json:"bucket"`
+	Pending       int        `json:"pending"`
+	Failed        int        `json:"failed"`
+	OldestPending *time.Time `json:"oldest_pending,omitempty"`
+	LagSeconds    float64    `json:"lag_seconds"`
+}
+
+// Lag is how long the oldest pending change has been waiting.
+func (b *ReplicationBacklog) Lag(now time.Time) time.Duration {
+	if b.OldestPending == nil {
+		return 0
+	}
+	return now.Sub(*b.OldestPending)
+}
@@ -0,0 +1,96 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketReplicationConfigValidate(t *testing.T) {
+	t.Parallel()
+	dest := domain.ReplicationDestination{Endpoint: "https://dr.example.com", Bucket: "backup", APIKey: "key"}
+	rule := func(id string, d domain.ReplicationDestination) domain.ReplicationRule {
+		return domain.ReplicationRule{ID: id, Prefix: "logs/", Destination: d}
+	}
+	tooMany := make([]domain.ReplicationRule, domain.MaxReplicationRules+1)
+	for i := range tooMany {
+		tooMany[i] = rule("", dest)
+	}
+
+	tests := []struct {
+		name    string
+		rules   []domain.ReplicationRule
+		wantErr string
+	}{
+		{"valid", []domain.ReplicationRule{rule("a", dest), rule("", dest)}, ""},
+		{"empty", nil, "at least one rule"},
+		{"too many", tooMany, "at most"},
+		{"duplicate id", []domain.ReplicationRule{rule("a", dest), rule("a", dest)}, "duplicate id"},
+		{"bad endpoint", []domain.ReplicationRule{rule("a", domain.ReplicationDestination{Endpoint: "ftp://x", Bucket: "b", APIKey: "k"})}, "endpoint"},
+		{"missing bucket", []domain.ReplicationRule{rule("a", domain.ReplicationDestination{Endpoint: "http://x", APIKey: "k"})}, "bucket is required"},
+		{"missing key", []domain.ReplicationRule{rule("a", domain.ReplicationDestination{Endpoint: "http://x", Bucket: "b"})}, "api_key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := domain.BucketReplicationConfig{Rules: tt.rules}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBucketReplicationConfigRedacted(t *testing.T) {
+	t.Parallel()
+	cfg := &domain.BucketReplicationConfig{Rules: []domain.ReplicationRule{{
+		ID:          "r1",
+		Destination: domain.ReplicationDestination{Endpoint: "https://dr.example.com", Bucket: "backup", APIKey: "secret"},
+	}}}
+
+	redacted := cfg.Redacted()
+	assert.Empty(t, redacted.Rules[0].Destination.APIKey)
+	assert.Equal(t, "secret", cfg.Rules[0].Destination.APIKey, "original must be left untouched")
+	assert.NotNil(t, cfg.Rule("r1"))
+	assert.Nil(t, cfg.Rule("r2"))
+}
+
+func TestObjectReplicationRecordFailure(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	rep := &domain.ObjectReplication{Status: domain.ReplicationPending}
+
+	rep.RecordFailure(errors.New("connection refused"), now)
+	assert.Equal(t, 1, rep.Attempts)
+	assert.Equal(t, domain.ReplicationPending, rep.Status)
+	assert.Equal(t, now.Add(30*time.Second), rep.NextAttemptAt)
+	assert.Equal(t, "connection refused", rep.LastError)
+
+	rep.Attempts = 6
+	rep.RecordFailure(errors.New("timeout"), now)
+	assert.Equal(t, now.Add(32*time.Minute), rep.NextAttemptAt)
+
+	rep.RecordFailure(errors.New("timeout"), now)
+	assert.Equal(t, domain.MaxReplicationAttempts, rep.Attempts)
+	assert.Equal(t, domain.ReplicationFailed, rep.Status)
+
+	rep.RecordSuccess(now)
+	assert.Equal(t, domain.ReplicationCompleted, rep.Status)
+	assert.Empty(t, rep.LastError)
+	assert.NotNil(t, rep.ReplicatedAt)
+}
+
+func TestReplicationBacklogLag(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	oldest := now.Add(-90 * time.Second)
+
+	assert.Zero(t, (&domain.ReplicationBacklog{}).Lag(now))
+	assert.Equal(t, 90*time.Second, (&domain.ReplicationBacklog{OldestPending: &oldest}).Lag(now))
+}
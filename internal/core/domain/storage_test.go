@@ -0,0 +1,156 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectListOptionsLimit(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		maxKeys int
+		want    int
+	}{
+		{"default", 0, domain.DefaultMaxKeys},
+		{"explicit", 50, 50},
+		{"clamped", 5000, domain.MaxListKeys},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, domain.ObjectListOptions{MaxKeys: tt.maxKeys}.Limit())
+		})
+	}
+}
+
+func TestObjectListOptionsCommonPrefix(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts domain.ObjectListOptions
+		key  string
+		want string
+	}{
+		{"no delimiter", domain.ObjectListOptions{}, "a/b/c", ""},
+		{"top level folder", domain.ObjectListOptions{Delimiter: "/"}, "a/b/c", "a/"},
+		{"top level file", domain.ObjectListOptions{Delimiter: "/"}, "a.txt", ""},
+		{"nested folder", domain.ObjectListOptions{Prefix: "a/", Delimiter: "/"}, "a/b/c", "a/b/"},
+		{"nested file", domain.ObjectListOptions{Prefix: "a/", Delimiter: "/"}, "a/c", ""},
+		{"multi-char delimiter", domain.ObjectListOptions{Delimiter: "--"}, "x--y--z", "x--"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.opts.CommonPrefix(tt.key))
+		})
+	}
+}
+
+func TestObjectListOptionsMarker(t *testing.T) {
+	t.Parallel()
+
+	marker, err := domain.ObjectListOptions{StartAfter: "b"}.Marker()
+	require.NoError(t, err)
+	assert.Equal(t, "b", marker)
+
+	token := domain.EncodeContinuationToken("photos/2024/")
+	marker, err = domain.ObjectListOptions{StartAfter: "b", ContinuationToken: token}.Marker()
+	require.NoError(t, err)
+	assert.Equal(t, "photos/2024/", marker)
+
+	_, err = domain.ObjectListOptions{ContinuationToken: "not*base64"}.Marker()
+	assert.Error(t, err)
+}
+
+func TestParseByteRange(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		spec    string
+		size    int64
+		want    *domain.ByteRange
+		wantErr bool
+	}{
+		{"empty", "", 100, nil, false},
+		{"not bytes", "items=0-1", 100, nil, false},
+		{"multiple ranges", "bytes=0-1,5-6", 100, nil, false},
+		{"malformed", "bytes=a-b", 100, nil, false},
+		{"closed", "bytes=0-9", 100, &domain.ByteRange{Start: 0, End: 9}, false},
+		{"open ended", "bytes=90-", 100, &domain.ByteRange{Start: 90, End: 99}, false},
+		{"clamped end", "bytes=50-500", 100, &domain.ByteRange{Start: 50, End: 99}, false},
+		{"suffix", "bytes=-10", 100, &domain.ByteRange{Start: 90, End: 99}, false},
+		{"suffix larger than object", "bytes=-500", 100, &domain.ByteRange{Start: 0, End: 99}, false},
+		{"start past end", "bytes=100-", 100, nil, true},
+		{"zero suffix", "bytes=-0", 100, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.ParseByteRange(tt.spec, tt.size)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrRangeNotSatisfiable)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPreconditionsEvaluate(t *testing.T) {
+	t.Parallel()
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := modified.Add(-time.Hour)
+	after := modified.Add(time.Hour)
+	obj := &domain.Object{ETag: "abc", CreatedAt: modified.Add(300 * time.Millisecond)}
+
+	tests := []struct {
+		name string
+		cond domain.Preconditions
+		obj  *domain.Object
+		read bool
+		want domain.PreconditionResult
+	}{
+		{"none", domain.Preconditions{}, obj, true, domain.PreconditionPassed},
+		{"if-match hit", domain.Preconditions{IfMatch: `"abc"`}, obj, false, domain.PreconditionPassed},
+		{"if-match miss", domain.Preconditions{IfMatch: `"xyz"`}, obj, false, domain.PreconditionFailed},
+		{"if-match weak is not strong", domain.Preconditions{IfMatch: `W/"abc"`}, obj, false, domain.PreconditionFailed},
+		{"if-match any on missing", domain.Preconditions{IfMatch: "*"}, nil, false, domain.PreconditionFailed},
+		{"if-none-match read hit", domain.Preconditions{IfNoneMatch: `W/"abc"`}, obj, true, domain.PreconditionNotModified},
+		{"if-none-match write hit", domain.Preconditions{IfNoneMatch: `"abc"`}, obj, false, domain.PreconditionFailed},
+		{"if-none-match any on missing", domain.Preconditions{IfNoneMatch: "*"}, nil, false, domain.PreconditionPassed},
+		{"if-none-match list", domain.Preconditions{IfNoneMatch: `"x", "abc"`}, obj, true, domain.PreconditionNotModified},
+		{"if-modified-since unchanged", domain.Preconditions{IfModifiedSince: &modified}, obj, true, domain.PreconditionNotModified},
+		{"if-modified-since changed", domain.Preconditions{IfModifiedSince: &before}, obj, true, domain.PreconditionPassed},
+		{"if-unmodified-since ok", domain.Preconditions{IfUnmodifiedSince: &after}, obj, false, domain.PreconditionPassed},
+		{"if-unmodified-since failed", domain.Preconditions{IfUnmodifiedSince: &before}, obj, false, domain.PreconditionFailed},
+		{"if-match wins over unmodified-since", domain.Preconditions{IfMatch: `"abc"`, IfUnmodifiedSince: &before}, obj, false, domain.PreconditionPassed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cond.Evaluate(tt.obj, tt.read))
+		})
+	}
+}
+
+func TestValidateObjectTags(t *testing.T) {
+	t.Parallel()
+	tooMany := map[string]string{}
+	for i := 0; i <= domain.MaxObjectTags; i++ {
+		tooMany[string(rune('a'+i))] = "x"
+	}
+
+	assert.NoError(t, domain.ValidateObjectTags(nil))
+	assert.NoError(t, domain.ValidateObjectTags(map[string]string{"env": "dev", "empty": ""}))
+	assert.Error(t, domain.ValidateObjectTags(tooMany))
+	assert.Error(t, domain.ValidateObjectTags(map[string]string{"": "x"}))
+	assert.Error(t, domain.ValidateObjectTags(map[string]string{strings.Repeat("k", domain.MaxObjectTagKeyLen+1): "x"}))
+	assert.Error(t, domain.ValidateObjectTags(map[string]string{"k": strings.Repeat("v", domain.MaxObjectTagValueLen+1)}))
+}
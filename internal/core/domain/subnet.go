@@ -14,10 +14,11 @@ type Subnet struct {
 	UserID           uuid.UUID `json:"user_id"`
 	VPCID            uuid.UUID `json:"vpc_id"`
 	Name             string    `json:"name"`
-	CIDRBlock        string    `json:"cidr_block"`        // IPv4 range (e.g. "10.0.1.0/24")
-	AvailabilityZone string    `json:"availability_zone"` // Physical zone (e.g. "us-east-1a")
-	GatewayIP        string    `json:"gateway_ip"`        // Router IP (usually first IP in block)
-	ARN              string    `json:"arn"`               // Amazon Resource Name compatible ID
-	Status           string    `json:"status"`            // e.g. "AVAILABLE"
+	CIDRBlock        string    `json:"cidr_block"`                // IPv4 range (e.g. "10.0.1.0/24")
+	IPv6CIDRBlock    string    `json:"ipv6_cidr_block,omitempty"` // Optional IPv6 /64 (e.g. "fd00:10:0:1::/64")
+	AvailabilityZone string    `json:"availability_zone"`         // Physical zone (e.g. "us-east-1a")
+	GatewayIP        string    `json:"gateway_ip"`                // Router IP (usually first IP in block)
+	ARN              string    `json:"arn"`                       // Amazon Resource Name compatible ID
+	Status           string    `json:"status"`                    // e.g. "AVAILABLE"
 	CreatedAt        time.Time `json:"created_at"`
 }
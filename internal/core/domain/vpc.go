@@ -2,6 +2,8 @@
 package domain
 
 import (
+	"fmt"
+	"net"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,14 +12,38 @@ import (
 // VPC represents a Virtual Private Cloud (isolated network).
 // It acts as a container for subnets and other network resources.
 type VPC struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
-	TenantID  uuid.UUID `json:"tenant_id"`
-	Name      string    `json:"name"`
-	CIDRBlock string    `json:"cidr_block"` // IPv4 range (e.g. "10.0.0.0/16")
-	NetworkID string    `json:"network_id"` // OVS bridge name or backend ID
-	VXLANID   int       `json:"vxlan_id"`   // Tunnel ID for isolation
-	Status    string    `json:"status"`     // e.g. "ACTIVE"
-	ARN       string    `json:"arn"`        // Amazon Resource Name compatible ID
-	CreatedAt time.Time `json:"created_at"`
+	ID            uuid.UUID `json:"id"`
+	UserID        uuid.UUID `json:"user_id"`
+	TenantID      uuid.UUID `json:"tenant_id"`
+	Name          string    `json:"name"`
+	CIDRBlock     string    `json:"cidr_block"`                // IPv4 range (e.g. "10.0.0.0/16")
+	IPv6CIDRBlock string    `json:"ipv6_cidr_block,omitempty"` // Optional IPv6 range (e.g. "fd00:10::/56")
+	NetworkID     string    `json:"network_id"`                // OVS bridge name or backend ID
+	VXLANID       int       `json:"vxlan_id"`                  // Tunnel ID for isolation
+	Status        string    `json:"status"`                    // e.g. "ACTIVE"
+	ARN           string    `json:"arn"`                       // Amazon Resource Name compatible ID
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// SubnetIPv6PrefixLen is the only prefix length accepted for IPv6 subnets, so that
+// every subnet can use stateless address autoconfiguration.
+const SubnetIPv6PrefixLen = 64
+
+// ParseIPv6CIDR parses an IPv6 network and rejects IPv4 ranges, host bits set
+// past the prefix, and prefixes longer than maxPrefixLen.
+func ParseIPv6CIDR(cidr string, maxPrefixLen int) (*net.IPNet, error) {
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IPv6 CIDR %q: %w", cidr, err)
+	}
+	if ip.To4() != nil {
+		return nil, fmt.Errorf("%q is not an IPv6 CIDR", cidr)
+	}
+	if !ip.Equal(ipNet.IP) {
+		return nil, fmt.Errorf("%q has host bits set; use %s", cidr, ipNet.String())
+	}
+	if ones, _ := ipNet.Mask.Size(); ones > maxPrefixLen {
+		return nil, fmt.Errorf("IPv6 CIDR %q must be /%d or larger", cidr, maxPrefixLen)
+	}
+	return ipNet, nil
 }
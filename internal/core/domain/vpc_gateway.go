@@ -0,0 +1,113 @@
+// Package domain defines core business entities.
+package domain
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InternetGatewayStatus describes whether an internet gateway is attached to a VPC.
+type InternetGatewayStatus string
+
+const (
+	// IGWStatusDetached means the gateway exists but serves no VPC.
+	IGWStatusDetached InternetGatewayStatus = "detached"
+	// IGWStatusAttached means the gateway is the VPC's path to and from the internet.
+	IGWStatusAttached InternetGatewayStatus = "attached"
+)
+
+// InternetGateway connects a VPC to the internet. Instances in public subnets reach the
+// internet through it, and instances with an Elastic IP are reachable from it.
+type InternetGateway struct {
+	ID        uuid.UUID             `json:"id"`
+	UserID    uuid.UUID             `json:"user_id"`
+	TenantID  uuid.UUID             `json:"tenant_id"`
+	VPCID     *uuid.UUID            `json:"vpc_id,omitempty"`
+	Name      string                `json:"name"`
+	Status    InternetGatewayStatus `json:"status"`
+	ARN       string                `json:"arn"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+// NamespaceName is the gateway's network namespace, whose name also prefixes its interfaces.
+func (g *InternetGateway) NamespaceName() string {
+	return "igw-" + g.ID.String()[:8]
+}
+
+// PortName is the name of the gateway's port on the VPC bridge.
+func (g *InternetGateway) PortName() string {
+	return g.NamespaceName() + "-b"
+}
+
+// NATGatewayStatus describes the lifecycle state of a NAT gateway.
+type NATGatewayStatus string
+
+const (
+	// NATStatusAvailable means the gateway is forwarding traffic.
+	NATStatusAvailable NATGatewayStatus = "available"
+	// NATStatusFailed means the network backend could not set the gateway up.
+	NATStatusFailed NATGatewayStatus = "failed"
+)
+
+// NATGateway gives instances in private subnets outbound-only internet access. It lives in
+// a public subnet and masquerades traffic behind its Elastic IP.
+type NATGateway struct {
+	ID          uuid.UUID        `json:"id"`
+	UserID      uuid.UUID        `json:"user_id"`
+	TenantID    uuid.UUID        `json:"tenant_id"`
+	VPCID       uuid.UUID        `json:"vpc_id"`
+	SubnetID    uuid.UUID        `json:"subnet_id"`
+	ElasticIPID uuid.UUID        `json:"elastic_ip_id"`
+	Name        string           `json:"name"`
+	PublicIP    string           `json:"public_ip"`
+	PrivateIP   string           `json:"private_ip"`
+	Status      NATGatewayStatus `json:"status"`
+	ARN         string           `json:"arn"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// NamespaceName is the gateway's network namespace, whose name also prefixes its interfaces.
+func (g *NATGateway) NamespaceName() string {
+	return "nat-" + g.ID.String()[:8]
+}
+
+// PortName is the name of the gateway's port on the VPC bridge.
+func (g *NATGateway) PortName() string {
+	return g.NamespaceName() + "-b"
+}
+
+// GatewayMAC derives the MAC address of a gateway's VPC-facing interface from its
+// namespace name. Route flows rewrite dl_dst to it so the gateway's kernel accepts packets
+// that instances addressed to their subnet router.
+func GatewayMAC(namespace string) string {
+	sum := sha256.Sum256([]byte(namespace))
+	// 0x02: locally administered, unicast.
+	return fmt.Sprintf("02:%02x:%02x:%02x:%02x:%02x", sum[0], sum[1], sum[2], sum[3], sum[4])
+}
+
+// LastUsableIP returns the highest host address of an IPv4 range, which NAT gateways take
+// so they stay clear of instance addresses allocated from the bottom of the subnet.
+func LastUsableIP(cidr string) (string, error) {
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	ip4 := n.IP.To4()
+	if ip4 == nil {
+		return "", fmt.Errorf("%s is not an IPv4 range", cidr)
+	}
+	ones, bits := n.Mask.Size()
+	if bits-ones < 2 {
+		return "", fmt.Errorf("%s has no usable host addresses", cidr)
+	}
+	last := make(net.IP, 4)
+	for i := range ip4 {
+		last[i] = ip4[i] | ^n.Mask[i]
+	}
+	last[3]--
+	return last.String(), nil
+}
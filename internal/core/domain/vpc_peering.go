@@ -0,0 +1,51 @@
+// Package domain defines core business entities.
+package domain
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VPCPeeringStatus describes where a peering connection is in its lifecycle.
+type VPCPeeringStatus string
+
+const (
+	// PeeringPendingAcceptance means the accepter side has not yet accepted the request.
+	PeeringPendingAcceptance VPCPeeringStatus = "pending-acceptance"
+	// PeeringActive means the two VPCs are connected and routing traffic.
+	PeeringActive VPCPeeringStatus = "active"
+	// PeeringFailed means the network backend could not connect the two VPCs.
+	PeeringFailed VPCPeeringStatus = "failed"
+)
+
+// VPCPeering connects two VPCs, possibly owned by different tenants, so instances in
+// one can reach instances in the other over private addresses.
+type VPCPeering struct {
+	ID                uuid.UUID        `json:"id"`
+	RequesterVPCID    uuid.UUID        `json:"requester_vpc_id"`
+	AccepterVPCID     uuid.UUID        `json:"accepter_vpc_id"`
+	RequesterTenantID uuid.UUID        `json:"requester_tenant_id"`
+	AccepterTenantID  uuid.UUID        `json:"accepter_tenant_id"`
+	RequesterCIDR     string           `json:"requester_cidr"`
+	AccepterCIDR      string           `json:"accepter_cidr"`
+	Status            VPCPeeringStatus `json:"status"`
+	ARN               string           `json:"arn"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
+
+// CIDRsOverlap reports whether two IPv4 or IPv6 ranges share any address.
+func CIDRsOverlap(a, b string) (bool, error) {
+	_, netA, err := net.ParseCIDR(a)
+	if err != nil {
+		return false, fmt.Errorf("invalid CIDR %q: %w", a, err)
+	}
+	_, netB, err := net.ParseCIDR(b)
+	if err != nil {
+		return false, fmt.Errorf("invalid CIDR %q: %w", b, err)
+	}
+	return netA.Contains(netB.IP) || netB.Contains(netA.IP), nil
+}
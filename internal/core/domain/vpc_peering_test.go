@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCIDRsOverlap(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"10.0.0.0/16", "10.1.0.0/16", false},
+		{"10.0.0.0/16", "10.0.5.0/24", true},
+		{"10.0.5.0/24", "10.0.0.0/8", true},
+		{"192.168.0.0/24", "192.168.0.0/24", true},
+	}
+	for _, tc := range cases {
+		got, err := CIDRsOverlap(tc.a, tc.b)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got, "%s vs %s", tc.a, tc.b)
+	}
+
+	_, err := CIDRsOverlap("10.0.0.0/16", "not-a-cidr")
+	assert.Error(t, err)
+}
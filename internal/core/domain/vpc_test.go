@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIPv6CIDR(t *testing.T) {
+	ipNet, err := ParseIPv6CIDR("fd00:10::/56", SubnetIPv6PrefixLen)
+	require.NoError(t, err)
+	assert.Equal(t, "fd00:10::/56", ipNet.String())
+
+	for _, cidr := range []string{"10.0.0.0/16", "fd00:10::1/64", "fd00:10::/80", "not-a-cidr"} {
+		_, err := ParseIPv6CIDR(cidr, SubnetIPv6PrefixLen)
+		assert.Error(t, err, cidr)
+	}
+}
@@ -0,0 +1,58 @@
+// Package ports defines service and repository interfaces.
+package ports
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+)
+
+// CertificateRepository manages persistent storage for TLS certificates and
+// the internal certificate authority.
+type CertificateRepository interface {
+	Create(ctx context.Context, cert *domain.Certificate) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Certificate, error)
+	List(ctx context.Context, userID uuid.UUID) ([]*domain.Certificate, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListAll returns every certificate across users, for expiry tracking.
+	ListAll(ctx context.Context) ([]*domain.Certificate, error)
+	// UpdateExpiryState records a certificate's status and the last expiry alert sent.
+	UpdateExpiryState(ctx context.Context, id uuid.UUID, status domain.CertificateStatus, alertDays *int) error
+
+	// GetAuthority returns the named certificate authority.
+	GetAuthority(ctx context.Context, name string) (*domain.CertificateAuthority, error)
+	// CreateAuthority stores a certificate authority unless one with the same
+	// name already exists.
+	CreateAuthority(ctx context.Context, ca *domain.CertificateAuthority) error
+}
+
+// CreateCertificateParams describes a certificate to import or issue. When
+// CertificatePEM is set the certificate and PrivateKeyPEM are imported;
+// otherwise a certificate for Domains is issued by the internal CA.
+type CreateCertificateParams struct {
+	Name           string
+	CertificatePEM string
+	PrivateKeyPEM  string
+	Domains        []string
+	ValidityDays   int
+	NotifyTopicID  *uuid.UUID
+}
+
+// CertificateService manages TLS certificates for load balancer and gateway
+// HTTPS listeners.
+type CertificateService interface {
+	// CreateCertificate imports a PEM certificate or issues one from the internal CA.
+	CreateCertificate(ctx context.Context, params CreateCertificateParams) (*domain.Certificate, error)
+	// GetCertificate returns one of the caller's certificates.
+	GetCertificate(ctx context.Context, id uuid.UUID) (*domain.Certificate, error)
+	// ListCertificates returns the caller's certificates.
+	ListCertificates(ctx context.Context) ([]*domain.Certificate, error)
+	// DeleteCertificate removes a certificate that no listener uses.
+	DeleteCertificate(ctx context.Context, id uuid.UUID) error
+	// GetKeyPair returns the decrypted certificate and key of one of the
+	// caller's certificates, for serving it.
+	GetKeyPair(ctx context.Context, id uuid.UUID) (*domain.CertificateKeyPair, error)
+	// GetAuthorityCertificate returns the caller's tenant CA certificate in PEM form.
+	GetAuthorityCertificate(ctx context.Context) (string, error)
+}
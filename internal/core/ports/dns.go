@@ -26,6 +26,8 @@ type DNSRepository interface {
 	UpdateRecord(ctx context.Context, record *domain.DNSRecord) error
 	DeleteRecord(ctx context.Context, id uuid.UUID) error
 	DeleteRecordsByInstance(ctx context.Context, instanceID uuid.UUID) error
+	// ApplyRecordChanges deletes and creates records of a zone in one transaction.
+	ApplyRecordChanges(ctx context.Context, zoneID uuid.UUID, deletes []uuid.UUID, creates []*domain.DNSRecord) error
 }
 
 // DNSBackend abstracts the actual DNS server (PowerDNS).
@@ -40,6 +42,18 @@ type DNSBackend interface {
 	UpdateRecords(ctx context.Context, zoneName string, records []RecordSet) error
 	DeleteRecords(ctx context.Context, zoneName string, name string, recordType string) error
 	ListRecords(ctx context.Context, zoneName string) ([]RecordSet, error)
+	// ReplaceRecordSets atomically replaces several record sets. A set without records is deleted.
+	ReplaceRecordSets(ctx context.Context, zoneName string, records []RecordSet) error
+}
+
+// VPCDNSBackend is implemented by DNS backends that answer each VPC's private
+// zone on a listener of its own. DNSService calls it after a zone is created
+// or deleted so the listener is only open while the VPC has a zone.
+type VPCDNSBackend interface {
+	// ServeVPCZone starts answering the VPC's private zone.
+	ServeVPCZone(ctx context.Context, vpcID uuid.UUID) error
+	// StopVPCZone stops answering the VPC's private zone.
+	StopVPCZone(ctx context.Context, vpcID uuid.UUID) error
 }
 
 // ZoneInfo represents zone information from PowerDNS.
@@ -75,6 +89,11 @@ type DNSService interface {
 	UpdateRecord(ctx context.Context, id uuid.UUID, content string, ttl int, priority *int) (*domain.DNSRecord, error)
 	DeleteRecord(ctx context.Context, id uuid.UUID) error
 
+	// Bulk operations
+	ChangeRecords(ctx context.Context, zoneID uuid.UUID, changes []domain.DNSRecordChange) ([]*domain.DNSRecord, error)
+	ImportZone(ctx context.Context, zoneID uuid.UUID, zoneFile string) ([]*domain.DNSRecord, error)
+	ExportZone(ctx context.Context, zoneID uuid.UUID) (string, error)
+
 	// Instance auto-registration (called by InstanceService)
 	RegisterInstance(ctx context.Context, instance *domain.Instance, ipAddress string) error
 	UnregisterInstance(ctx context.Context, instanceID uuid.UUID) error
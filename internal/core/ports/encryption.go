@@ -3,31 +3,54 @@ package ports
 
 import (
 	"context"
+	"time"
 )
 
 // EncryptionService handles encryption and decryption of data streams.
 type EncryptionService interface {
-	// Encrypt returns an encrypted version of the key and an initialization vector.
-	// For object storage, it might return a key ID or wrapper.
+	// Encrypt seals data with the bucket's latest data key. The ciphertext header
+	// records the key version, so rotating the key does not strand older data.
 	Encrypt(ctx context.Context, bucket string, data []byte) ([]byte, error)
+	// Decrypt opens data sealed by Encrypt with whichever key version sealed it.
 	Decrypt(ctx context.Context, bucket string, encryptedData []byte) ([]byte, error)
+	// KeyVersion reports the data key version recorded in a ciphertext header.
+	KeyVersion(encryptedData []byte) int
+	// LatestKeyVersion returns the version of the data key Encrypt currently uses for a bucket.
+	LatestKeyVersion(ctx context.Context, bucket string) (int, error)
 
 	// CreateKey creates a new encryption key for a bucket
 	CreateKey(ctx context.Context, bucket string) (string, error)
-	// RotateKey rotates the key for a bucket (re-encryption is separate process)
+	// RotateKey adds a new data key version for a bucket. Earlier versions remain
+	// available for decryption; re-encrypting existing data is a separate process.
 	RotateKey(ctx context.Context, bucket string) (string, error)
+	// RewrapKeys re-encrypts every data key wrapped by a retired master key with the
+	// active master key and returns how many keys were re-wrapped.
+	RewrapKeys(ctx context.Context) (int, error)
 }
 
 // EncryptionKey stores metadata for a bucket encryption key.
 type EncryptionKey struct {
 	ID           string
 	BucketName   string
+	Version      int
 	EncryptedKey []byte
 	Algorithm    string
+	// MasterKeyID identifies the master key the data key is wrapped with;
+	// empty for keys created before master keys were tracked.
+	MasterKeyID string
+	CreatedAt   time.Time
 }
 
 // EncryptionRepository persists encryption keys for buckets.
 type EncryptionRepository interface {
+	// SaveKey stores a new data key version.
 	SaveKey(ctx context.Context, key EncryptionKey) error
+	// GetKey returns the latest data key version of a bucket.
 	GetKey(ctx context.Context, bucketName string) (*EncryptionKey, error)
+	// GetKeyVersion returns a specific data key version of a bucket.
+	GetKeyVersion(ctx context.Context, bucketName string, version int) (*EncryptionKey, error)
+	// ListKeysNotWrappedBy returns data keys wrapped by any master key other than masterKeyID.
+	ListKeysNotWrappedBy(ctx context.Context, masterKeyID string, limit int) ([]EncryptionKey, error)
+	// UpdateWrappedKey replaces the wrapped form of a data key after re-wrapping it.
+	UpdateWrappedKey(ctx context.Context, id string, encryptedKey []byte, masterKeyID string) error
 }
@@ -0,0 +1,37 @@
+// Package ports defines service and repository interfaces.
+package ports
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+)
+
+// FlowLogRepository manages the persistent state of flow log configurations.
+type FlowLogRepository interface {
+	// Create saves a new flow log.
+	Create(ctx context.Context, fl *domain.FlowLog) error
+	// GetByID retrieves a flow log of the caller's tenant.
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.FlowLog, error)
+	// List returns the caller's tenant's flow logs.
+	List(ctx context.Context) ([]*domain.FlowLog, error)
+	// ListAll returns every flow log across tenants, for the collector.
+	ListAll(ctx context.Context) ([]*domain.FlowLog, error)
+	// Delete removes a flow log.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// FlowLogService provides business logic for capturing VPC traffic into CloudLogs.
+type FlowLogService interface {
+	// CreateFlowLog starts capturing a VPC, subnet or instance's flows.
+	CreateFlowLog(ctx context.Context, resourceType domain.FlowLogResourceType, resourceID uuid.UUID, trafficType domain.FlowLogTrafficType) (*domain.FlowLog, error)
+	// GetFlowLog retrieves a flow log.
+	GetFlowLog(ctx context.Context, id uuid.UUID) (*domain.FlowLog, error)
+	// ListFlowLogs returns the caller's flow logs.
+	ListFlowLogs(ctx context.Context) ([]*domain.FlowLog, error)
+	// DeleteFlowLog stops a capture. Records already ingested are kept.
+	DeleteFlowLog(ctx context.Context, id uuid.UUID) error
+	// CollectFlows samples every bridge with a flow log and ingests the matching records.
+	CollectFlows(ctx context.Context) error
+}
@@ -3,7 +3,8 @@ package ports
 
 import (
 	"context"
-	"net/http/httputil"
+	"crypto/tls"
+	"net/http"
 
 	"github.com/google/uuid"
 	"github.com/poyrazk/thecloud/internal/core/domain"
@@ -35,6 +36,14 @@ type CreateRouteParams struct {
 	StripPrefix bool
 	RateLimit   int
 	Priority    int
+	Plugins     domain.GatewayRoutePlugins
+	// Upstreams, when set, replace Target with a weighted set of backends.
+	Upstreams     []domain.GatewayUpstream
+	TrafficPolicy domain.GatewayTrafficPolicy
+	// CertificateID serves the route's domains on the HTTPS listener.
+	CertificateID *uuid.UUID
+	// HTTPSRedirect redirects plain HTTP requests to the HTTPS listener.
+	HTTPSRedirect bool
 }
 
 // GatewayService provides business logic for managing the API gateway and ingress traffic.
@@ -47,6 +56,12 @@ type GatewayService interface {
 	DeleteRoute(ctx context.Context, id uuid.UUID) error
 	// RefreshRoutes reloads all routes and pre-compiles matchers.
 	RefreshRoutes(ctx context.Context) error
-	// GetProxy finds the appropriate backend for the given path and method.
-	GetProxy(method, path string) (*httputil.ReverseProxy, map[string]string, bool)
+	// GetProxy finds the handler for the given path and method: the route's
+	// reverse proxy wrapped with its rate limit and plugins.
+	GetProxy(method, path string) (http.Handler, map[string]string, bool)
+	// FlushAccessLogs ingests the access logs buffered since the last flush into CloudLogs.
+	FlushAccessLogs(ctx context.Context) error
+	// GetCertificate selects the route certificate for a TLS handshake's
+	// server name, for use as tls.Config.GetCertificate.
+	GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
 }
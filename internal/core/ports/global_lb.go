@@ -3,6 +3,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/poyrazk/thecloud/internal/core/domain"
@@ -23,6 +24,14 @@ type GlobalLBRepository interface {
 	GetEndpointByID(ctx context.Context, endpointID uuid.UUID) (*domain.GlobalEndpoint, error)
 	ListEndpoints(ctx context.Context, glbID uuid.UUID) ([]*domain.GlobalEndpoint, error)
 	UpdateEndpointHealth(ctx context.Context, epID uuid.UUID, healthy bool) error
+
+	// ListAll returns every global load balancer across users, for background health checking.
+	ListAll(ctx context.Context) ([]*domain.GlobalLoadBalancer, error)
+
+	// Health check history
+	RecordHealthCheck(ctx context.Context, check *domain.GlobalEndpointHealthCheck) error
+	ListHealthChecks(ctx context.Context, endpointID uuid.UUID, limit int) ([]*domain.GlobalEndpointHealthCheck, error)
+	DeleteHealthChecksBefore(ctx context.Context, before time.Time) error
 }
 
 // GlobalLBService provides business logic for multi-region routing.
@@ -35,6 +44,8 @@ type GlobalLBService interface {
 	AddEndpoint(ctx context.Context, glbID uuid.UUID, region string, targetType string, targetID *uuid.UUID, targetIP *string, weight, priority int) (*domain.GlobalEndpoint, error)
 	RemoveEndpoint(ctx context.Context, glbID, endpointID uuid.UUID) error
 	ListEndpoints(ctx context.Context, glbID uuid.UUID) ([]*domain.GlobalEndpoint, error)
+	// GetEndpointHealth returns the most recent health check results for an endpoint, newest first.
+	GetEndpointHealth(ctx context.Context, glbID, endpointID uuid.UUID, limit int) ([]*domain.GlobalEndpointHealthCheck, error)
 }
 
 // GeoDNSBackend abstracts the underlying DNS provider capable of geo-routing.
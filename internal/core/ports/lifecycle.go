@@ -3,6 +3,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/poyrazk/thecloud/internal/core/domain"
@@ -19,7 +20,13 @@ type LifecycleRepository interface {
 
 // LifecycleService manages lifecycle rules for storage buckets.
 type LifecycleService interface {
-	CreateRule(ctx context.Context, bucket string, prefix string, expirationDays int, enabled bool) (*domain.LifecycleRule, error)
+	// CreateRule validates and stores a rule for a bucket the caller owns.
+	CreateRule(ctx context.Context, bucket string, rule *domain.LifecycleRule) (*domain.LifecycleRule, error)
 	ListRules(ctx context.Context, bucket string) ([]*domain.LifecycleRule, error)
 	DeleteRule(ctx context.Context, bucket string, ruleID string) error
+	// PreviewRule reports what a rule would do if it ran now, without changing anything.
+	PreviewRule(ctx context.Context, bucket string, ruleID string) (*domain.LifecycleReport, error)
+	// EvaluateRule lists the actions a rule selects at now. It performs no ownership
+	// checks and is intended for background workers.
+	EvaluateRule(ctx context.Context, rule *domain.LifecycleRule, now time.Time) (*domain.LifecycleReport, error)
 }
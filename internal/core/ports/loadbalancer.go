@@ -3,6 +3,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/poyrazk/thecloud/internal/core/domain"
@@ -29,12 +30,14 @@ type LBRepository interface {
 
 	// AddTarget links a compute instance to a load balancer's target pool.
 	AddTarget(ctx context.Context, target *domain.LBTarget) error
-	// RemoveTarget unlinks an instance from a load balancer's target pool.
-	RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID) error
+	// RemoveTarget unlinks an instance from one target group of a load balancer.
+	RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID, group string) error
 	// ListTargets retrieves all backend members associated with a load balancer.
 	ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain.LBTarget, error)
-	// UpdateTargetHealth updates the operational state of a specific target.
-	UpdateTargetHealth(ctx context.Context, lbID, instanceID uuid.UUID, health string) error
+	// UpdateTargetHealth updates the operational state of a specific target. Draining targets are left untouched.
+	UpdateTargetHealth(ctx context.Context, lbID, instanceID uuid.UUID, group string, port int, health string) error
+	// DrainTarget marks an instance's targets in a group as draining until the given deadline, after which they are removed.
+	DrainTarget(ctx context.Context, lbID, instanceID uuid.UUID, group string, until time.Time) error
 	// GetTargetsForInstance retrieves all load balancers that a specific instance is a member of.
 	GetTargetsForInstance(ctx context.Context, instanceID uuid.UUID) ([]*domain.LBTarget, error)
 }
@@ -49,15 +52,33 @@ type LBService interface {
 	List(ctx context.Context) ([]*domain.LoadBalancer, error)
 	// Delete decommission a load balancer and stops its proxy traffic distribution.
 	Delete(ctx context.Context, idOrName string) error
+	// ConfigureTLS enables or replaces the HTTPS listener; a nil cfg disables it.
+	ConfigureTLS(ctx context.Context, idOrName string, cfg *domain.LBTLSConfig) (*domain.LoadBalancer, error)
+	// ConfigureListeners replaces the layer-7 listeners and their routing rules.
+	ConfigureListeners(ctx context.Context, idOrName string, listeners []domain.LBListener) (*domain.LoadBalancer, error)
+	// ConfigureAttributes replaces the health check, connection draining and sticky session settings.
+	ConfigureAttributes(ctx context.Context, idOrName string, params UpdateLBAttributesParams) (*domain.LoadBalancer, error)
 
 	// AddTarget registers a new backend instance into the load balancer's rotation.
 	AddTarget(ctx context.Context, lbID, instanceID uuid.UUID, port int, weight int) error
-	// RemoveTarget unregisters an instance from the load balancer.
+	// AddTargetToGroup registers a backend instance into a named target group.
+	AddTargetToGroup(ctx context.Context, lbID, instanceID uuid.UUID, port int, weight int, group string) error
+	// RemoveTarget unregisters an instance from the load balancer's default target group.
 	RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID) error
+	// RemoveTargetFromGroup unregisters an instance from a named target group.
+	RemoveTargetFromGroup(ctx context.Context, lbID, instanceID uuid.UUID, group string) error
 	// ListTargets returns all current members of the load balancer's pool.
 	ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain.LBTarget, error)
 }
 
+// UpdateLBAttributesParams holds the target policies of a load balancer.
+// A nil HealthCheck falls back to a TCP port check and a nil StickySessions disables affinity.
+type UpdateLBAttributesParams struct {
+	HealthCheck                *domain.HealthCheckConfig
+	DeregistrationDelaySeconds int
+	StickySessions             *domain.LBStickySessions
+}
+
 // LBProxyAdapter abstracts the platform-specific implementation of the traffic proxy (e.g., Nginx, HAProxy).
 type LBProxyAdapter interface {
 	// DeployProxy configures and starts the physical or virtual proxy process.
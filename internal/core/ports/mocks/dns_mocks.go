@@ -111,6 +111,11 @@ func (m *DNSRepository) DeleteRecordsByInstance(ctx context.Context, instanceID
 	return args.Error(0)
 }
 
+func (m *DNSRepository) ApplyRecordChanges(ctx context.Context, zoneID uuid.UUID, deletes []uuid.UUID, creates []*domain.DNSRecord) error {
+	args := m.Called(ctx, zoneID, deletes, creates)
+	return args.Error(0)
+}
+
 // DNSBackend is a mock for ports.DNSBackend
 type DNSBackend struct {
 	mock.Mock
@@ -163,6 +168,11 @@ func (m *DNSBackend) ListRecords(ctx context.Context, zoneName string) ([]ports.
 	return args.Get(0).([]ports.RecordSet), args.Error(1)
 }
 
+func (m *DNSBackend) ReplaceRecordSets(ctx context.Context, zoneName string, records []ports.RecordSet) error {
+	args := m.Called(ctx, zoneName, records)
+	return args.Error(0)
+}
+
 // DNSService is a mock for ports.DNSService
 type DNSService struct {
 	mock.Mock
@@ -248,6 +258,27 @@ func (m *DNSService) DeleteRecord(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *DNSService) ChangeRecords(ctx context.Context, zoneID uuid.UUID, changes []domain.DNSRecordChange) ([]*domain.DNSRecord, error) {
+	args := m.Called(ctx, zoneID, changes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.DNSRecord), args.Error(1)
+}
+
+func (m *DNSService) ImportZone(ctx context.Context, zoneID uuid.UUID, zoneFile string) ([]*domain.DNSRecord, error) {
+	args := m.Called(ctx, zoneID, zoneFile)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.DNSRecord), args.Error(1)
+}
+
+func (m *DNSService) ExportZone(ctx context.Context, zoneID uuid.UUID) (string, error) {
+	args := m.Called(ctx, zoneID)
+	return args.String(0), args.Error(1)
+}
+
 func (m *DNSService) RegisterInstance(ctx context.Context, instance *domain.Instance, ipAddress string) error {
 	args := m.Called(ctx, instance, ipAddress)
 	return args.Error(0)
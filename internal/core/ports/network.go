@@ -3,6 +3,8 @@ package ports
 
 import (
 	"context"
+
+	"github.com/poyrazk/thecloud/internal/core/domain"
 )
 
 // FlowRule represents an OpenFlow entry for Open vSwitch (OVS) to control packet forwarding.
@@ -31,6 +33,9 @@ type NetworkBackend interface {
 	AddPort(ctx context.Context, bridge, portName string) error
 	// DeletePort disconnects an interface from a bridge.
 	DeletePort(ctx context.Context, bridge, portName string) error
+	// CreatePatchPort adds a patch port to a bridge that is wired to peerPort on another
+	// bridge on the same host. Both ends must be created for traffic to flow.
+	CreatePatchPort(ctx context.Context, bridge, portName, peerPort string) error
 
 	// VXLAN Tunnels (multi-node overlay networks)
 
@@ -48,6 +53,39 @@ type NetworkBackend interface {
 	// ListFlowRules retrieves all active OpenFlow rules for a bridge.
 	ListFlowRules(ctx context.Context, bridge string) ([]FlowRule, error)
 
+	// Gateways
+
+	// AttachInternetGateway starts an internet gateway in its own network namespace, attached
+	// to the bridge, that forwards traffic between vpcCIDR and the host's external network
+	// for the Elastic IPs mapped onto it.
+	AttachInternetGateway(ctx context.Context, bridge, name, vpcCIDR string) error
+	// DetachInternetGateway removes an internet gateway's namespace and bridge port.
+	DetachInternetGateway(ctx context.Context, bridge, name string) error
+	// MapElasticIP makes an internet gateway translate publicIP to privateIP for inbound
+	// traffic and privateIP to publicIP for outbound traffic.
+	MapElasticIP(ctx context.Context, gateway, publicIP, privateIP string) error
+	// UnmapElasticIP removes a translation added by MapElasticIP.
+	UnmapElasticIP(ctx context.Context, gateway, publicIP, privateIP string) error
+	// CreateNATGateway starts a NAT gateway in its own network namespace, attached to the
+	// bridge at privateIP, that masquerades traffic from sourceCIDR behind publicIP.
+	CreateNATGateway(ctx context.Context, bridge, name, privateIP, publicIP, sourceCIDR string) error
+	// DeleteNATGateway removes a NAT gateway's namespace and bridge port.
+	DeleteNATGateway(ctx context.Context, bridge, name string) error
+
+	// Network ACLs
+
+	// ApplyNetworkACL compiles a subnet's ACL rules into stateless flows ranked above the
+	// security group flows, replacing any flows previously applied for that subnet.
+	ApplyNetworkACL(ctx context.Context, bridge, subnetCIDR string, rules []domain.NetworkACLRule) error
+	// RemoveNetworkACL deletes the ACL flows applied for a subnet.
+	RemoveNetworkACL(ctx context.Context, bridge, subnetCIDR string) error
+
+	// Flow Logs
+
+	// SampleFlows returns the flows the bridge forwarded or dropped since the previous call
+	// for that bridge, with their packet and byte counts.
+	SampleFlows(ctx context.Context, bridge string) ([]domain.FlowRecord, error)
+
 	// Veth Pair Management (used to link instance namespaces to the bridge)
 
 	// CreateVethPair creates a linked pair of virtual ethernet interfaces.
@@ -0,0 +1,56 @@
+// Package ports defines service and repository interfaces.
+package ports
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+)
+
+// NetworkACLRepository manages the persistent state of network ACLs, their rules and
+// their subnet associations.
+type NetworkACLRepository interface {
+	// Create saves a new, empty network ACL.
+	Create(ctx context.Context, acl *domain.NetworkACL) error
+	// GetByID retrieves a network ACL with its rules and associated subnets.
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.NetworkACL, error)
+	// GetBySubnet retrieves the network ACL associated with a subnet.
+	GetBySubnet(ctx context.Context, subnetID uuid.UUID) (*domain.NetworkACL, error)
+	// ListByVPC returns every network ACL of a VPC.
+	ListByVPC(ctx context.Context, vpcID uuid.UUID) ([]*domain.NetworkACL, error)
+	// Delete removes a network ACL and its rules.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// AddRule appends a rule to an ACL.
+	AddRule(ctx context.Context, rule *domain.NetworkACLRule) error
+	// DeleteRule removes a rule from an ACL.
+	DeleteRule(ctx context.Context, aclID, ruleID uuid.UUID) error
+
+	// AssociateSubnet makes an ACL the one used by a subnet, replacing any previous association.
+	AssociateSubnet(ctx context.Context, aclID, subnetID uuid.UUID) error
+	// DisassociateSubnet removes a subnet's ACL association.
+	DisassociateSubnet(ctx context.Context, subnetID uuid.UUID) error
+}
+
+// NetworkACLService provides business logic for subnet-level stateless firewalls.
+type NetworkACLService interface {
+	// CreateACL adds an empty network ACL to a VPC.
+	CreateACL(ctx context.Context, vpcID uuid.UUID, name string) (*domain.NetworkACL, error)
+	// GetACL retrieves a network ACL.
+	GetACL(ctx context.Context, id uuid.UUID) (*domain.NetworkACL, error)
+	// ListACLs returns a VPC's network ACLs.
+	ListACLs(ctx context.Context, vpcID uuid.UUID) ([]*domain.NetworkACL, error)
+	// DeleteACL removes a network ACL no subnet uses.
+	DeleteACL(ctx context.Context, id uuid.UUID) error
+
+	// AddRule adds a numbered rule and reapplies the ACL to its subnets.
+	AddRule(ctx context.Context, aclID uuid.UUID, rule domain.NetworkACLRule) (*domain.NetworkACLRule, error)
+	// RemoveRule deletes a rule and reapplies the ACL to its subnets.
+	RemoveRule(ctx context.Context, aclID, ruleID uuid.UUID) error
+
+	// AssociateSubnet applies an ACL to a subnet.
+	AssociateSubnet(ctx context.Context, aclID, subnetID uuid.UUID) error
+	// DisassociateSubnet stops filtering a subnet's traffic with an ACL.
+	DisassociateSubnet(ctx context.Context, subnetID uuid.UUID) error
+}
@@ -0,0 +1,62 @@
+// Package ports defines service and repository interfaces.
+package ports
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+)
+
+// RouteTableRepository manages the persistent state of VPC route tables, their routes and
+// their subnet associations.
+type RouteTableRepository interface {
+	// Create saves a new route table along with its initial routes.
+	Create(ctx context.Context, rt *domain.RouteTable) error
+	// GetByID retrieves a route table with its routes and associated subnets.
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.RouteTable, error)
+	// GetMain retrieves a VPC's main route table.
+	GetMain(ctx context.Context, vpcID uuid.UUID) (*domain.RouteTable, error)
+	// GetBySubnet retrieves the route table explicitly associated with a subnet.
+	GetBySubnet(ctx context.Context, subnetID uuid.UUID) (*domain.RouteTable, error)
+	// ListByVPC returns every route table of a VPC.
+	ListByVPC(ctx context.Context, vpcID uuid.UUID) ([]*domain.RouteTable, error)
+	// Delete removes a route table, its routes and its associations.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// AddRoute appends a route to a table.
+	AddRoute(ctx context.Context, route *domain.Route) error
+	// DeleteRoute removes the route for a destination from a table.
+	DeleteRoute(ctx context.Context, routeTableID uuid.UUID, destinationCIDR string) error
+	// ListRoutesByTarget returns every route sending traffic to a gateway.
+	ListRoutesByTarget(ctx context.Context, targetID uuid.UUID) ([]*domain.Route, error)
+
+	// AssociateSubnet makes a table the one used by a subnet, replacing any previous association.
+	AssociateSubnet(ctx context.Context, routeTableID, subnetID uuid.UUID) error
+	// DisassociateSubnet returns a subnet to its VPC's main table.
+	DisassociateSubnet(ctx context.Context, subnetID uuid.UUID) error
+}
+
+// RouteTableService provides business logic for routing traffic out of VPC subnets.
+type RouteTableService interface {
+	// CreateRouteTable adds a route table to a VPC, seeded with the VPC's local route.
+	CreateRouteTable(ctx context.Context, vpcID uuid.UUID, name string) (*domain.RouteTable, error)
+	// GetRouteTable retrieves a route table.
+	GetRouteTable(ctx context.Context, id uuid.UUID) (*domain.RouteTable, error)
+	// ListRouteTables returns a VPC's route tables, creating its main table on first use.
+	ListRouteTables(ctx context.Context, vpcID uuid.UUID) ([]*domain.RouteTable, error)
+	// DeleteRouteTable removes a route table that no subnet uses.
+	DeleteRouteTable(ctx context.Context, id uuid.UUID) error
+
+	// AddRoute sends traffic for a destination range to a gateway.
+	AddRoute(ctx context.Context, routeTableID uuid.UUID, destinationCIDR string, targetType domain.RouteTargetType, targetID *uuid.UUID) (*domain.Route, error)
+	// RemoveRoute deletes the route for a destination range.
+	RemoveRoute(ctx context.Context, routeTableID uuid.UUID, destinationCIDR string) error
+
+	// AssociateSubnet applies a route table to a subnet.
+	AssociateSubnet(ctx context.Context, routeTableID, subnetID uuid.UUID) error
+	// DisassociateSubnet returns a subnet to its VPC's main route table.
+	DisassociateSubnet(ctx context.Context, subnetID uuid.UUID) error
+	// GetSubnetRouteTable returns the route table in effect for a subnet.
+	GetSubnetRouteTable(ctx context.Context, subnetID uuid.UUID) (*domain.RouteTable, error)
+}
@@ -35,6 +35,10 @@ type SecurityGroupRepository interface {
 	RemoveInstanceFromGroup(ctx context.Context, instanceID, groupID uuid.UUID) error
 	// ListInstanceGroups retrieves all security groups currently protecting a specific instance.
 	ListInstanceGroups(ctx context.Context, instanceID uuid.UUID) ([]*domain.SecurityGroup, error)
+	// ListGroupMemberIPs returns the private IPs of the instances attached to a group.
+	ListGroupMemberIPs(ctx context.Context, groupID uuid.UUID) ([]string, error)
+	// ListRulesReferencingGroup returns every rule that names the group as its source.
+	ListRulesReferencingGroup(ctx context.Context, groupID uuid.UUID) ([]domain.SecurityRule, error)
 }
 
 // SecurityGroupService provides business logic for managing virtual networking firewalls.
@@ -57,4 +61,8 @@ type SecurityGroupService interface {
 	AttachToInstance(ctx context.Context, instanceID, groupID uuid.UUID) error
 	// DetachFromInstance removes a security group's rules from a compute instance.
 	DetachFromInstance(ctx context.Context, instanceID, groupID uuid.UUID) error
+	// DetachAllFromInstance removes every security group from an instance, e.g. before it is deleted.
+	DetachAllFromInstance(ctx context.Context, instanceID uuid.UUID) error
+	// SyncInstanceAddresses moves an instance's firewall flows from its previous to its current addresses.
+	SyncInstanceAddresses(ctx context.Context, instanceID uuid.UUID, previous, current []string) error
 }
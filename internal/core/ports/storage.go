@@ -14,14 +14,24 @@ import (
 type StorageRepository interface {
 	// SaveMeta persists or updates metadata for a storage object.
 	SaveMeta(ctx context.Context, obj *domain.Object) error
+	// SaveMetaIf saves a new latest version only if the key's latest version is still current
+	// (nil: the key has no object), failing with PreconditionFailed otherwise.
+	SaveMetaIf(ctx context.Context, obj *domain.Object, current *domain.Object) error
 	// GetMeta retrieves metadata for a specific object in a bucket.
 	GetMeta(ctx context.Context, bucket, key string) (*domain.Object, error)
-	// List returns metadata for all objects currently stored in a specific bucket.
-	List(ctx context.Context, bucket string) ([]*domain.Object, error)
+	// List returns one page of the latest object versions in a bucket, filtered by prefix
+	// and grouped into common prefixes when a delimiter is given.
+	List(ctx context.Context, bucket string, opts domain.ObjectListOptions) (*domain.ObjectListResult, error)
 	// SoftDelete marks an object as deleted without immediately removing its underlying binary data.
 	SoftDelete(ctx context.Context, bucket, key string) error
+	// SoftDeleteIf soft deletes an object only if its latest version is still current,
+	// failing with PreconditionFailed otherwise.
+	SoftDeleteIf(ctx context.Context, bucket, key string, current *domain.Object) error
 	// DeleteVersion permanently deletes a specific version's metadata.
 	DeleteVersion(ctx context.Context, bucket, key, versionID string) error
+	// DeleteVersionIf permanently deletes a version only if it is still current,
+	// failing with PreconditionFailed otherwise.
+	DeleteVersionIf(ctx context.Context, bucket, key, versionID string, current *domain.Object) error
 	// GetMetaByVersion retrieves metadata for a specific version of an object.
 	GetMetaByVersion(ctx context.Context, bucket, key, versionID string) (*domain.Object, error)
 	// ListVersions returns all versions of a specific object.
@@ -30,14 +40,70 @@ type StorageRepository interface {
 	ListDeleted(ctx context.Context, limit int) ([]*domain.Object, error)
 	// HardDelete permanently removes metadata for a specific object version.
 	HardDelete(ctx context.Context, bucket, key, versionID string) error
+	// ListVersionsPage returns all live versions of up to maxKeys keys under prefix, after startAfter,
+	// ordered by key and newest version first.
+	ListVersionsPage(ctx context.Context, bucket, prefix, startAfter string, maxKeys int) ([]*domain.Object, error)
 
 	// Bucket operations
 	CreateBucket(ctx context.Context, bucket *domain.Bucket) error
 	GetBucket(ctx context.Context, name string) (*domain.Bucket, error)
 	DeleteBucket(ctx context.Context, name string) error
+	// BucketHasObjects reports whether a bucket still holds any object version or delete marker.
+	BucketHasObjects(ctx context.Context, bucket string) (bool, error)
+	// ListLockedVersions returns versions of a bucket under a legal hold or retention, deleted ones included.
+	ListLockedVersions(ctx context.Context, bucket string, limit int) ([]*domain.Object, error)
 	ListBuckets(ctx context.Context, userID string) ([]*domain.Bucket, error)
 	// SetBucketVersioning enables or disables versioning for a bucket.
 	SetBucketVersioning(ctx context.Context, name string, enabled bool) error
+	// SetBucketStorageClass changes the storage class (and erasure layout) used for new objects in a bucket.
+	SetBucketStorageClass(ctx context.Context, name string, class domain.StorageClass, layout domain.ErasureLayout) error
+
+	// Access control
+	GetBucketPolicy(ctx context.Context, bucket string) (*domain.BucketPolicy, error)
+	PutBucketPolicy(ctx context.Context, policy *domain.BucketPolicy) error
+	DeleteBucketPolicy(ctx context.Context, bucket string) error
+	// SetObjectACL changes the canned ACL of a specific object version.
+	SetObjectACL(ctx context.Context, bucket, key, versionID string, acl domain.ObjectACL) error
+	// SetObjectTags replaces the tags of a specific object version.
+	SetObjectTags(ctx context.Context, bucket, key, versionID string, tags map[string]string) error
+
+	// Event notifications
+	GetBucketNotifications(ctx context.Context, bucket string) (*domain.BucketNotificationConfig, error)
+	PutBucketNotifications(ctx context.Context, cfg *domain.BucketNotificationConfig) error
+	DeleteBucketNotifications(ctx context.Context, bucket string) error
+
+	// Object lock
+	// SetBucketObjectLock updates a bucket's object lock setting and default retention.
+	SetBucketObjectLock(ctx context.Context, name string, cfg domain.ObjectLockConfig) error
+	// SetObjectRetention replaces the retention of a specific object version; nil removes it.
+	SetObjectRetention(ctx context.Context, bucket, key, versionID string, retention *domain.ObjectRetention) error
+	// SetObjectLegalHold places or releases a legal hold on a specific object version.
+	SetObjectLegalHold(ctx context.Context, bucket, key, versionID string, on bool) error
+
+	// Encryption
+	// SetBucketEncryption updates a bucket's server-side encryption setting and current data key.
+	SetBucketEncryption(ctx context.Context, name string, enabled bool, keyID string) error
+	// ListObjectsBelowKeyVersion returns up to limit SSE object versions of a bucket sealed with a data key older than version.
+	ListObjectsBelowKeyVersion(ctx context.Context, bucket string, version, limit int) ([]*domain.Object, error)
+
+	// Erasure coding
+	// ListErasureObjects returns up to limit live erasure-coded object versions with an ID after afterID, ordered by ID.
+	ListErasureObjects(ctx context.Context, afterID uuid.UUID, limit int) ([]*domain.Object, error)
+
+	// Replication
+	GetBucketReplication(ctx context.Context, bucket string) (*domain.BucketReplicationConfig, error)
+	PutBucketReplication(ctx context.Context, cfg *domain.BucketReplicationConfig) error
+	DeleteBucketReplication(ctx context.Context, bucket string) error
+	// EnqueueReplication records changes to replicate, resetting any earlier state of the same change.
+	EnqueueReplication(ctx context.Context, reps []*domain.ObjectReplication) error
+	// ListPendingReplication returns up to limit pending replications that are due, oldest first.
+	ListPendingReplication(ctx context.Context, limit int) ([]*domain.ObjectReplication, error)
+	// UpdateReplication saves the outcome of a replication attempt.
+	UpdateReplication(ctx context.Context, rep *domain.ObjectReplication) error
+	// ListObjectReplication returns the replication state of every version of a key.
+	ListObjectReplication(ctx context.Context, bucket, key string) ([]*domain.ObjectReplication, error)
+	// ListReplicationBacklog summarises pending and failed replications per bucket; an empty bucket lists all.
+	ListReplicationBacklog(ctx context.Context, bucket string) ([]*domain.ReplicationBacklog, error)
 
 	// Multipart operations
 	SaveMultipartUpload(ctx context.Context, upload *domain.MultipartUpload) error
@@ -45,6 +111,8 @@ type StorageRepository interface {
 	DeleteMultipartUpload(ctx context.Context, uploadID uuid.UUID) error
 	SavePart(ctx context.Context, part *domain.Part) error
 	ListParts(ctx context.Context, uploadID uuid.UUID) ([]*domain.Part, error)
+	// ListMultipartUploads returns incomplete uploads under prefix that were started before initiatedBefore.
+	ListMultipartUploads(ctx context.Context, bucket, prefix string, initiatedBefore time.Time) ([]*domain.MultipartUpload, error)
 }
 
 // FileStore abstracts the low-level binary data operations for object storage (e.g., Local disk, S3).
@@ -59,18 +127,45 @@ type FileStore interface {
 	GetClusterStatus(ctx context.Context) (*domain.StorageCluster, error)
 	// Assemble combines multiple parts into a single object and removes the parts.
 	Assemble(ctx context.Context, bucket, key string, parts []string) (int64, error)
+	// WriteErasure splits data into Reed-Solomon shards spread across nodes and returns the logical and stored sizes.
+	WriteErasure(ctx context.Context, bucket, key string, r io.Reader, layout domain.ErasureLayout) (int64, int64, error)
+	// ReadErasure rebuilds an erasure-coded object of the given size from any DataShards of its shards.
+	ReadErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout, size int64) (io.ReadCloser, error)
+	// DeleteErasure removes every shard of an erasure-coded object.
+	DeleteErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout) error
+	// RepairErasure rebuilds the missing or stale shards of an erasure-coded object and returns how many it rewrote.
+	RepairErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout, size int64) (int, error)
+}
+
+// ReplicationClient copies object changes to a bucket on another installation.
+type ReplicationClient interface {
+	// PutObject uploads size bytes from r to key in the destination bucket as a replica.
+	PutObject(ctx context.Context, dest domain.ReplicationDestination, key string, r io.Reader, size int64, contentType string) error
+	// DeleteObject deletes key from the destination bucket; a missing key is not an error.
+	DeleteObject(ctx context.Context, dest domain.ReplicationDestination, key string) error
 }
 
 // StorageService provides business logic for managing bucket-based object storage resources (e.g., Cloud Storage).
 type StorageService interface {
 	// Upload manages the metadata registration and binary data transfer of a new object.
 	Upload(ctx context.Context, bucket, key string, r io.Reader) (*domain.Object, error)
+	// PutObject uploads an object, rejecting the write when the preconditions do not hold.
+	PutObject(ctx context.Context, bucket, key string, r io.Reader, cond domain.Preconditions) (*domain.Object, error)
 	// Download retrieves both the binary content and metadata for a specified object.
 	Download(ctx context.Context, bucket, key string) (io.ReadCloser, *domain.Object, error)
-	// ListObjects returns metadata for all accessible objects in a bucket.
-	ListObjects(ctx context.Context, bucket string) ([]*domain.Object, error)
+	// GetObject retrieves an object (or a byte range of it), honouring conditional read headers.
+	GetObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.ObjectContent, error)
+	// HeadObject returns an object's metadata, honouring conditional read headers.
+	HeadObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.Object, error)
+	// ListObjects returns one page of accessible objects in a bucket.
+	ListObjects(ctx context.Context, bucket string, opts domain.ObjectListOptions) (*domain.ObjectListResult, error)
 	// DeleteObject manages the coordinated removal of an object's metadata and its binary data.
 	DeleteObject(ctx context.Context, bucket, key string) error
+	// DeleteObjectIf deletes the latest object (or a specific version) only if the preconditions hold.
+	DeleteObjectIf(ctx context.Context, bucket, key, versionID string, cond domain.Preconditions) error
+	// ExpireObject removes the current version as lifecycle expiration does, leaving a delete
+	// marker on versioned buckets.
+	ExpireObject(ctx context.Context, bucket, key string) error
 	// DownloadVersion retrieves both the binary content and metadata for a specific version of an object.
 	DownloadVersion(ctx context.Context, bucket, key, versionID string) (io.ReadCloser, *domain.Object, error)
 	// ListVersions returns all versions for a specific object.
@@ -85,9 +180,64 @@ type StorageService interface {
 	ListBuckets(ctx context.Context) ([]*domain.Bucket, error)
 	// SetBucketVersioning enables or disables versioning for a bucket.
 	SetBucketVersioning(ctx context.Context, name string, enabled bool) error
+	// SetBucketStorageClass selects replicated or erasure-coded storage for objects written to a bucket from now on.
+	SetBucketStorageClass(ctx context.Context, name string, class domain.StorageClass, layout domain.ErasureLayout) (*domain.Bucket, error)
 	// GetClusterStatus returns the current state of the storage cluster.
 	GetClusterStatus(ctx context.Context) (*domain.StorageCluster, error)
 
+	// Access control
+	// GetBucketPolicy returns a bucket's policy; only the bucket owner may read it.
+	GetBucketPolicy(ctx context.Context, bucket string) (*domain.BucketPolicy, error)
+	// PutBucketPolicy validates and replaces a bucket's policy; only the bucket owner may change it.
+	PutBucketPolicy(ctx context.Context, bucket string, statements []domain.BucketPolicyStatement) (*domain.BucketPolicy, error)
+	// DeleteBucketPolicy removes a bucket's policy, leaving only owner and ACL-based access.
+	DeleteBucketPolicy(ctx context.Context, bucket string) error
+	// SetObjectACL applies a canned ACL to the latest object (or a specific version).
+	SetObjectACL(ctx context.Context, bucket, key, versionID string, acl domain.ObjectACL) error
+	// SetObjectTags replaces the tags of the latest object (or a specific version).
+	SetObjectTags(ctx context.Context, bucket, key, versionID string, tags map[string]string) error
+
+	// Event notifications
+	// GetBucketNotifications returns a bucket's notification rules; only the bucket owner may read them.
+	GetBucketNotifications(ctx context.Context, bucket string) (*domain.BucketNotificationConfig, error)
+	// PutBucketNotifications validates and replaces a bucket's notification rules; only the bucket owner may change them.
+	PutBucketNotifications(ctx context.Context, bucket string, rules []domain.BucketNotificationRule) (*domain.BucketNotificationConfig, error)
+	// DeleteBucketNotifications stops all event notifications for a bucket.
+	DeleteBucketNotifications(ctx context.Context, bucket string) error
+
+	// Object lock
+	// SetBucketObjectLock enables object lock on a versioned bucket and sets its default retention;
+	// only the bucket owner may change it and it cannot be disabled once enabled.
+	SetBucketObjectLock(ctx context.Context, bucket string, cfg domain.ObjectLockConfig) (*domain.Bucket, error)
+	// PutObjectRetention sets the retention of the latest object (or a specific version); nil removes it.
+	PutObjectRetention(ctx context.Context, bucket, key, versionID string, retention *domain.ObjectRetention) error
+	// PutObjectLegalHold places or releases a legal hold on the latest object (or a specific version).
+	PutObjectLegalHold(ctx context.Context, bucket, key, versionID string, on bool) error
+
+	// Encryption
+	// SetBucketEncryption turns server-side encryption of new objects on or off; only the bucket owner may change it.
+	SetBucketEncryption(ctx context.Context, bucket string, enabled bool) (*domain.Bucket, error)
+	// RotateBucketKey adds a new data key version for a bucket, optionally scheduling re-encryption of existing objects.
+	RotateBucketKey(ctx context.Context, bucket string, reencrypt bool) (*domain.Bucket, error)
+	// ReencryptObjects seals up to limit objects still using an older data key with the latest one.
+	ReencryptObjects(ctx context.Context, bucket string, limit int) (int, error)
+
+	// Replication
+	// GetBucketReplication returns a bucket's replication rules without credentials; only the bucket owner may read them.
+	GetBucketReplication(ctx context.Context, bucket string) (*domain.BucketReplicationConfig, error)
+	// PutBucketReplication validates and replaces a bucket's replication rules; only the bucket owner may change them.
+	// A rule without an api_key keeps the key of the existing rule with the same ID.
+	PutBucketReplication(ctx context.Context, bucket string, rules []domain.ReplicationRule) (*domain.BucketReplicationConfig, error)
+	// DeleteBucketReplication stops replicating changes of a bucket, including those still pending.
+	DeleteBucketReplication(ctx context.Context, bucket string) error
+	// GetObjectReplication returns the replication state of every version of an object.
+	GetObjectReplication(ctx context.Context, bucket, key string) ([]*domain.ObjectReplication, error)
+	// GetReplicationBacklog returns how many replications of a bucket are pending or failed and how far behind they are.
+	GetReplicationBacklog(ctx context.Context, bucket string) (*domain.ReplicationBacklog, error)
+
+	// TransitionObject rewrites an object version's data in another storage class.
+	TransitionObject(ctx context.Context, bucket, key, versionID string, class domain.StorageClass) error
+
 	// Multipart operations
 	CreateMultipartUpload(ctx context.Context, bucket, key string) (*domain.MultipartUpload, error)
 	UploadPart(ctx context.Context, uploadID uuid.UUID, partNumber int, r io.Reader) (*domain.Part, error)
@@ -96,6 +246,9 @@ type StorageService interface {
 
 	// Cleanup
 	CleanupDeleted(ctx context.Context, limit int) (int, error)
+	// RepairErasureObjects repairs the shards of up to limit erasure-coded object versions with an ID after
+	// afterID. It returns the ID of the last version checked, or uuid.Nil when none remain, and the shards rewritten.
+	RepairErasureObjects(ctx context.Context, afterID uuid.UUID, limit int) (uuid.UUID, int, error)
 
 	// Presigned URLs
 	GeneratePresignedURL(ctx context.Context, bucket, key, method string, expiry time.Duration) (*domain.PresignedURL, error)
@@ -25,7 +25,8 @@ type SubnetRepository interface {
 // SubnetService provides business logic for managing virtual network subdivisions.
 type SubnetService interface {
 	// CreateSubnet provisions a new address range subdivision within a VPC.
-	CreateSubnet(ctx context.Context, vpcID uuid.UUID, name, cidrBlock, az string) (*domain.Subnet, error)
+	// ipv6CIDRBlock is optional and must be a /64 inside the VPC's IPv6 block.
+	CreateSubnet(ctx context.Context, vpcID uuid.UUID, name, cidrBlock, ipv6CIDRBlock, az string) (*domain.Subnet, error)
 	// GetSubnet retrieves details for a specific virtual network subdivision.
 	GetSubnet(ctx context.Context, idOrName string, vpcID uuid.UUID) (*domain.Subnet, error)
 	// ListSubnets returns all subdivisions within a specified authorized VPC.
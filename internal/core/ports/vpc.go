@@ -25,7 +25,8 @@ type VpcRepository interface {
 // VpcService provides business logic for managing isolated virtual networks.
 type VpcService interface {
 	// CreateVPC establishes a new isolated virtual network with a specific address space.
-	CreateVPC(ctx context.Context, name, cidrBlock string) (*domain.VPC, error)
+	// ipv6CIDRBlock is optional; when set the VPC becomes dual-stack.
+	CreateVPC(ctx context.Context, name, cidrBlock, ipv6CIDRBlock string) (*domain.VPC, error)
 	// GetVPC retrieves detailed information for a specific virtual network.
 	GetVPC(ctx context.Context, idOrName string) (*domain.VPC, error)
 	// ListVPCs returns all virtual networks registered to the current authorized user.
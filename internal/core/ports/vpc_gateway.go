@@ -0,0 +1,64 @@
+// Package ports defines service and repository interfaces.
+package ports
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+)
+
+// InternetGatewayRepository manages the persistent state of internet gateways.
+type InternetGatewayRepository interface {
+	// Create saves a new internet gateway.
+	Create(ctx context.Context, igw *domain.InternetGateway) error
+	// GetByID retrieves an internet gateway by its unique ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.InternetGateway, error)
+	// GetByVPC retrieves the internet gateway attached to a VPC.
+	GetByVPC(ctx context.Context, vpcID uuid.UUID) (*domain.InternetGateway, error)
+	// List returns every internet gateway of the caller's tenant.
+	List(ctx context.Context) ([]*domain.InternetGateway, error)
+	// Update records a gateway's attachment and status.
+	Update(ctx context.Context, igw *domain.InternetGateway) error
+	// Delete removes an internet gateway.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// NATGatewayRepository manages the persistent state of NAT gateways.
+type NATGatewayRepository interface {
+	// Create saves a new NAT gateway.
+	Create(ctx context.Context, nat *domain.NATGateway) error
+	// GetByID retrieves a NAT gateway by its unique ID.
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.NATGateway, error)
+	// GetBySubnet retrieves the NAT gateway placed in a subnet; a subnet holds at most one.
+	GetBySubnet(ctx context.Context, subnetID uuid.UUID) (*domain.NATGateway, error)
+	// List returns every NAT gateway of the caller's tenant.
+	List(ctx context.Context) ([]*domain.NATGateway, error)
+	// UpdateStatus records a NAT gateway's lifecycle state.
+	UpdateStatus(ctx context.Context, id uuid.UUID, status domain.NATGatewayStatus) error
+	// Delete removes a NAT gateway.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// VPCGatewayService provides business logic for internet and NAT gateways.
+type VPCGatewayService interface {
+	// CreateInternetGateway creates a detached internet gateway.
+	CreateInternetGateway(ctx context.Context, name string) (*domain.InternetGateway, error)
+	// AttachInternetGateway connects a gateway to a VPC; a VPC has at most one.
+	AttachInternetGateway(ctx context.Context, id, vpcID uuid.UUID) (*domain.InternetGateway, error)
+	// DetachInternetGateway disconnects a gateway no route uses from its VPC.
+	DetachInternetGateway(ctx context.Context, id uuid.UUID) (*domain.InternetGateway, error)
+	// ListInternetGateways returns the caller's internet gateways.
+	ListInternetGateways(ctx context.Context) ([]*domain.InternetGateway, error)
+	// DeleteInternetGateway removes a detached internet gateway.
+	DeleteInternetGateway(ctx context.Context, id uuid.UUID) error
+
+	// CreateNATGateway launches a NAT gateway in a public subnet behind an allocated Elastic IP.
+	CreateNATGateway(ctx context.Context, subnetID, elasticIPID uuid.UUID, name string) (*domain.NATGateway, error)
+	// GetNATGateway retrieves a NAT gateway.
+	GetNATGateway(ctx context.Context, id uuid.UUID) (*domain.NATGateway, error)
+	// ListNATGateways returns the caller's NAT gateways.
+	ListNATGateways(ctx context.Context) ([]*domain.NATGateway, error)
+	// DeleteNATGateway tears down a NAT gateway no route uses and releases its Elastic IP association.
+	DeleteNATGateway(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,44 @@
+// Package ports defines service and repository interfaces.
+package ports
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+)
+
+// VPCPeeringRepository manages the persistent state of VPC peering connections.
+// Lookups are visible to both the requester and the accepter tenant.
+type VPCPeeringRepository interface {
+	// Create saves a new peering connection.
+	Create(ctx context.Context, peering *domain.VPCPeering) error
+	// GetByID retrieves a peering connection the caller's tenant takes part in.
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.VPCPeering, error)
+	// List returns every peering connection the caller's tenant takes part in.
+	List(ctx context.Context) ([]*domain.VPCPeering, error)
+	// ListByVPC returns the peering connections on either side of a VPC.
+	ListByVPC(ctx context.Context, vpcID uuid.UUID) ([]*domain.VPCPeering, error)
+	// UpdateStatus records a peering connection's new lifecycle state.
+	UpdateStatus(ctx context.Context, id uuid.UUID, status domain.VPCPeeringStatus) error
+	// Delete removes a peering connection.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// GetVPC retrieves a VPC owned by the given tenant, so a peering can name a VPC
+	// outside the caller's tenant.
+	GetVPC(ctx context.Context, id, tenantID uuid.UUID) (*domain.VPC, error)
+}
+
+// VPCPeeringService provides business logic for connecting VPCs to each other.
+type VPCPeeringService interface {
+	// CreatePeering requests a connection from one of the caller's VPCs to a VPC of the
+	// accepter tenant, or of the caller's own tenant when accepterTenantID is uuid.Nil.
+	CreatePeering(ctx context.Context, requesterVPCID, accepterVPCID, accepterTenantID uuid.UUID) (*domain.VPCPeering, error)
+	// AcceptPeering connects the two VPCs; only the accepter's tenant may accept.
+	AcceptPeering(ctx context.Context, id uuid.UUID) (*domain.VPCPeering, error)
+	// GetPeering retrieves a peering connection the caller takes part in.
+	GetPeering(ctx context.Context, id uuid.UUID) (*domain.VPCPeering, error)
+	// ListPeerings returns every peering connection the caller takes part in.
+	ListPeerings(ctx context.Context) ([]*domain.VPCPeering, error)
+	// DeletePeering disconnects the two VPCs; either side may delete.
+	DeletePeering(ctx context.Context, id uuid.UUID) error
+}
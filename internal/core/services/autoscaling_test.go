@@ -178,11 +178,26 @@ func (s *NoopLBService) AddTarget(ctx context.Context, lbID, instanceID uuid.UUI
 func (s *NoopLBService) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID) error {
 	return nil
 }
+func (s *NoopLBService) RemoveTargetFromGroup(ctx context.Context, lbID, instanceID uuid.UUID, group string) error {
+	return nil
+}
 func (s *NoopLBService) ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain.LBTarget, error) {
 	return nil, nil
 }
 func (s *NoopLBService) List(ctx context.Context) ([]*domain.LoadBalancer, error) { return nil, nil }
 func (s *NoopLBService) Delete(ctx context.Context, id string) error           { return nil }
+func (s *NoopLBService) ConfigureTLS(ctx context.Context, id string, cfg *domain.LBTLSConfig) (*domain.LoadBalancer, error) {
+	return nil, nil
+}
+func (s *NoopLBService) ConfigureListeners(ctx context.Context, id string, listeners []domain.LBListener) (*domain.LoadBalancer, error) {
+	return nil, nil
+}
+func (s *NoopLBService) ConfigureAttributes(ctx context.Context, id string, params ports.UpdateLBAttributesParams) (*domain.LoadBalancer, error) {
+	return nil, nil
+}
+func (s *NoopLBService) AddTargetToGroup(ctx context.Context, lbID, instanceID uuid.UUID, port int, weight int, group string) error {
+	return nil
+}
 func (s *NoopLBService) CreateListener(ctx context.Context, lbID uuid.UUID, port int, protocol string) error {
 	return nil
 }
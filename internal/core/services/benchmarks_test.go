@@ -311,13 +311,13 @@ func BenchmarkStorageServiceList(b *testing.B) {
 	fileStore := &noop.NoopFileStore{}
 	auditSvc := &noop.NoopAuditService{}
 
-	svc := services.NewStorageService(repo, fileStore, auditSvc, nil, nil)
+	svc := services.NewStorageService(repo, fileStore, auditSvc, nil, nil, nil, nil)
 
-	ctx := context.Background()
+	ctx := appcontext.WithUserID(context.Background(), uuid.New())
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = svc.ListObjects(ctx, "test-bucket")
+		_, _ = svc.ListObjects(ctx, "test-bucket", domain.ObjectListOptions{})
 	}
 }
 
@@ -0,0 +1,491 @@
+// Package services implements core business workflows.
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
+)
+
+const (
+	// internalCAPrefix is followed by the tenant ID in the name of each tenant's CA.
+	internalCAPrefix           = "tenant-"
+	internalCAValidity         = 10 * 365 * 24 * time.Hour
+	defaultCertificateValidity = 90
+	maxCertificateValidity     = 397
+	maxCertificateDomains      = 100
+	// certificateBackdate tolerates clock skew between the platform and clients.
+	certificateBackdate = 5 * time.Minute
+)
+
+var certificateDomainPattern = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// issuingCA is the parsed internal certificate authority.
+type issuingCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+	pem  string
+}
+
+// CertificateService imports TLS certificates and issues them from the
+// platform's internal CA. Every tenant has a CA of its own, so trusting a
+// tenant's CA never extends trust to names another tenant had issued.
+type CertificateService struct {
+	repo        ports.CertificateRepository
+	secretSvc   ports.SecretService
+	notifySvc   ports.NotifyService
+	lbRepo      ports.LBRepository
+	gatewayRepo ports.GatewayRepository
+	auditSvc    ports.AuditService
+	logger      *slog.Logger
+
+	caMu sync.Mutex
+	cas  map[uuid.UUID]*issuingCA
+}
+
+// CertificateServiceParams holds the dependencies of CertificateService.
+// Private keys are encrypted with the secret service. The load balancer and
+// gateway repositories are used to refuse deleting certificates in use.
+type CertificateServiceParams struct {
+	Repo        ports.CertificateRepository
+	SecretSvc   ports.SecretService
+	NotifySvc   ports.NotifyService
+	LBRepo      ports.LBRepository
+	GatewayRepo ports.GatewayRepository
+	AuditSvc    ports.AuditService
+	Logger      *slog.Logger
+}
+
+// NewCertificateService constructs a CertificateService.
+func NewCertificateService(params CertificateServiceParams) *CertificateService {
+	logger := params.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &CertificateService{
+		repo:        params.Repo,
+		secretSvc:   params.SecretSvc,
+		notifySvc:   params.NotifySvc,
+		lbRepo:      params.LBRepo,
+		gatewayRepo: params.GatewayRepo,
+		auditSvc:    params.AuditSvc,
+		logger:      logger,
+		cas:         make(map[uuid.UUID]*issuingCA),
+	}
+}
+
+func (s *CertificateService) CreateCertificate(ctx context.Context, params ports.CreateCertificateParams) (*domain.Certificate, error) {
+	userID := appcontext.UserIDFromContext(ctx)
+	if userID == uuid.Nil {
+		return nil, errors.New(errors.Unauthorized, "unauthorized")
+	}
+	if strings.TrimSpace(params.Name) == "" {
+		return nil, errors.New(errors.InvalidInput, "certificate name is required")
+	}
+	if params.NotifyTopicID != nil {
+		if err := s.checkTopic(ctx, *params.NotifyTopicID); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		cert   *domain.Certificate
+		keyPEM string
+		err    error
+	)
+	if params.CertificatePEM != "" {
+		cert, keyPEM, err = importCertificate(params)
+	} else {
+		cert, keyPEM, err = s.issueCertificate(ctx, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := s.secretSvc.Encrypt(ctx, userID, keyPEM)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to encrypt private key", err)
+	}
+
+	cert.ID = uuid.New()
+	cert.UserID = userID
+	cert.TenantID = appcontext.TenantIDFromContext(ctx)
+	cert.Name = params.Name
+	cert.Status = domain.CertificateStatusAt(cert.NotAfter, time.Now())
+	cert.EncryptedPrivateKey = encryptedKey
+	cert.NotifyTopicID = params.NotifyTopicID
+	cert.CreatedAt = time.Now()
+
+	if err := s.repo.Create(ctx, cert); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, userID, "certificate.create", "certificate", cert.ID.String(), map[string]interface{}{
+		"name":    cert.Name,
+		"source":  cert.Source,
+		"domains": cert.Domains,
+	})
+
+	return cert, nil
+}
+
+func (s *CertificateService) GetCertificate(ctx context.Context, id uuid.UUID) (*domain.Certificate, error) {
+	cert, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cert.UserID != appcontext.UserIDFromContext(ctx) {
+		return nil, errors.New(errors.NotFound, "certificate not found")
+	}
+	return cert, nil
+}
+
+func (s *CertificateService) ListCertificates(ctx context.Context) ([]*domain.Certificate, error) {
+	return s.repo.List(ctx, appcontext.UserIDFromContext(ctx))
+}
+
+func (s *CertificateService) DeleteCertificate(ctx context.Context, id uuid.UUID) error {
+	cert, err := s.GetCertificate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkUnused(ctx, cert.ID); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, cert.ID); err != nil {
+		return err
+	}
+
+	_ = s.auditSvc.Log(ctx, cert.UserID, "certificate.delete", "certificate", cert.ID.String(), map[string]interface{}{
+		"name": cert.Name,
+	})
+	return nil
+}
+
+func (s *CertificateService) GetKeyPair(ctx context.Context, id uuid.UUID) (*domain.CertificateKeyPair, error) {
+	cert, err := s.GetCertificate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := s.secretSvc.Decrypt(ctx, cert.UserID, cert.EncryptedPrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to decrypt private key", err)
+	}
+	return &domain.CertificateKeyPair{
+		CertificateID:  cert.ID,
+		Domains:        cert.Domains,
+		CertificatePEM: cert.CertificatePEM,
+		PrivateKeyPEM:  keyPEM,
+	}, nil
+}
+
+func (s *CertificateService) GetAuthorityCertificate(ctx context.Context) (string, error) {
+	ca, err := s.authority(ctx, appcontext.TenantIDFromContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	return ca.pem, nil
+}
+
+// checkTopic verifies that a notification topic belongs to the caller.
+func (s *CertificateService) checkTopic(ctx context.Context, topicID uuid.UUID) error {
+	if s.notifySvc == nil {
+		return errors.New(errors.InvalidInput, "expiry notifications are not supported")
+	}
+	topics, err := s.notifySvc.ListTopics(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range topics {
+		if t.ID == topicID {
+			return nil
+		}
+	}
+	return errors.New(errors.NotFound, "notification topic not found")
+}
+
+// checkUnused refuses to delete a certificate that a load balancer or gateway
+// route still serves.
+func (s *CertificateService) checkUnused(ctx context.Context, id uuid.UUID) error {
+	if s.lbRepo != nil {
+		lbs, err := s.lbRepo.List(ctx)
+		if err != nil {
+			return err
+		}
+		for _, lb := range lbs {
+			if lb.TLS != nil && slices.Contains(lb.TLS.CertificateIDs, id) {
+				return errors.New(errors.Conflict, fmt.Sprintf("certificate is used by load balancer %s", lb.Name))
+			}
+		}
+	}
+	if s.gatewayRepo != nil {
+		routes, err := s.gatewayRepo.ListRoutes(ctx, appcontext.UserIDFromContext(ctx))
+		if err != nil {
+			return err
+		}
+		for _, r := range routes {
+			if r.CertificateID != nil && *r.CertificateID == id {
+				return errors.New(errors.Conflict, fmt.Sprintf("certificate is used by gateway route %s", r.Name))
+			}
+		}
+	}
+	return nil
+}
+
+// importCertificate validates an uploaded certificate chain and its key.
+func importCertificate(params ports.CreateCertificateParams) (*domain.Certificate, string, error) {
+	pair, err := tls.X509KeyPair([]byte(params.CertificatePEM), []byte(params.PrivateKeyPEM))
+	if err != nil {
+		return nil, "", errors.New(errors.InvalidInput, "invalid certificate or private key: "+err.Error())
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, "", errors.New(errors.InvalidInput, "invalid certificate: "+err.Error())
+	}
+	if !time.Now().Before(leaf.NotAfter) {
+		return nil, "", errors.New(errors.InvalidInput, "certificate has expired")
+	}
+
+	domains := append([]string(nil), leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		domains = append(domains, ip.String())
+	}
+	if len(domains) == 0 && leaf.Subject.CommonName != "" {
+		domains = []string{leaf.Subject.CommonName}
+	}
+	if len(domains) == 0 {
+		return nil, "", errors.New(errors.InvalidInput, "certificate does not name any domains")
+	}
+
+	var chain strings.Builder
+	for _, der := range pair.Certificate {
+		_ = pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	return &domain.Certificate{
+		Domains:        domains,
+		Source:         domain.CertificateSourceImported,
+		Issuer:         leaf.Issuer.String(),
+		SerialNumber:   leaf.SerialNumber.Text(16),
+		NotBefore:      leaf.NotBefore,
+		NotAfter:       leaf.NotAfter,
+		CertificatePEM: chain.String(),
+	}, params.PrivateKeyPEM, nil
+}
+
+// issueCertificate issues a server certificate for the requested domains
+// from the caller's tenant CA.
+func (s *CertificateService) issueCertificate(ctx context.Context, params ports.CreateCertificateParams) (*domain.Certificate, string, error) {
+	if params.PrivateKeyPEM != "" {
+		return nil, "", errors.New(errors.InvalidInput, "a private key requires a certificate")
+	}
+	domains, err := normalizeCertificateDomains(params.Domains)
+	if err != nil {
+		return nil, "", err
+	}
+	validity := params.ValidityDays
+	if validity == 0 {
+		validity = defaultCertificateValidity
+	}
+	if validity < 1 || validity > maxCertificateValidity {
+		return nil, "", errors.New(errors.InvalidInput, fmt.Sprintf("validity must be between 1 and %d days", maxCertificateValidity))
+	}
+
+	ca, err := s.authority(ctx, appcontext.TenantIDFromContext(ctx))
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, "", errors.Wrap(errors.Internal, "failed to generate private key", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: domains[0]},
+		NotBefore:    now.Add(-certificateBackdate),
+		NotAfter:     now.AddDate(0, 0, validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, d := range domains {
+		if ip := net.ParseIP(d); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, d)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, "", errors.Wrap(errors.Internal, "failed to issue certificate", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, "", errors.Wrap(errors.Internal, "failed to encode private key", err)
+	}
+
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})) + ca.pem
+	return &domain.Certificate{
+		Domains:        domains,
+		Source:         domain.CertificateSourceIssued,
+		Issuer:         ca.cert.Subject.String(),
+		SerialNumber:   serial.Text(16),
+		NotBefore:      tmpl.NotBefore,
+		NotAfter:       tmpl.NotAfter,
+		CertificatePEM: certPEM,
+	}, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})), nil
+}
+
+// normalizeCertificateDomains lower-cases, de-duplicates and validates the
+// domains of a certificate to issue.
+func normalizeCertificateDomains(domains []string) ([]string, error) {
+	var out []string
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(d), "."))
+		if d == "" || slices.Contains(out, d) {
+			continue
+		}
+		if net.ParseIP(d) == nil && (len(d) > 253 || !certificateDomainPattern.MatchString(d)) {
+			return nil, errors.New(errors.InvalidInput, fmt.Sprintf("invalid certificate domain %q", d))
+		}
+		out = append(out, d)
+	}
+	if len(out) == 0 {
+		return nil, errors.New(errors.InvalidInput, "at least one domain is required to issue a certificate")
+	}
+	if len(out) > maxCertificateDomains {
+		return nil, errors.New(errors.InvalidInput, fmt.Sprintf("a certificate can name at most %d domains", maxCertificateDomains))
+	}
+	return out, nil
+}
+
+// authority returns a tenant's CA, creating it on first use. Concurrent
+// creators race on the repository and all adopt the stored CA.
+func (s *CertificateService) authority(ctx context.Context, tenantID uuid.UUID) (*issuingCA, error) {
+	if tenantID == uuid.Nil {
+		return nil, errors.New(errors.Unauthorized, "a tenant is required to use the internal CA")
+	}
+	s.caMu.Lock()
+	defer s.caMu.Unlock()
+	if ca, ok := s.cas[tenantID]; ok {
+		return ca, nil
+	}
+
+	name := internalCAPrefix + tenantID.String()
+	stored, err := s.repo.GetAuthority(ctx, name)
+	if errors.Is(err, errors.NotFound) {
+		var created *domain.CertificateAuthority
+		if created, err = s.newAuthority(ctx, name, tenantID); err != nil {
+			return nil, err
+		}
+		if err = s.repo.CreateAuthority(ctx, created); err != nil {
+			return nil, err
+		}
+		stored, err = s.repo.GetAuthority(ctx, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ca, err := s.parseAuthority(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+	s.cas[tenantID] = ca
+	return ca, nil
+}
+
+func (s *CertificateService) newAuthority(ctx context.Context, name string, tenantID uuid.UUID) (*domain.CertificateAuthority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to generate CA key", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "The Cloud Internal CA", Organization: []string{"The Cloud"}, OrganizationalUnit: []string{tenantID.String()}},
+		NotBefore:             now.Add(-certificateBackdate),
+		NotAfter:              now.Add(internalCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to create CA certificate", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to encode CA key", err)
+	}
+	encrypted, err := s.secretSvc.Encrypt(ctx, uuid.Nil, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})))
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to encrypt CA key", err)
+	}
+	s.logger.Info("created internal certificate authority", "tenant_id", tenantID, "not_after", tmpl.NotAfter)
+	return &domain.CertificateAuthority{
+		Name:                name,
+		CertificatePEM:      string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		EncryptedPrivateKey: encrypted,
+		NotAfter:            tmpl.NotAfter,
+		CreatedAt:           now,
+	}, nil
+}
+
+func (s *CertificateService) parseAuthority(ctx context.Context, stored *domain.CertificateAuthority) (*issuingCA, error) {
+	keyPEM, err := s.secretSvc.Decrypt(ctx, uuid.Nil, stored.EncryptedPrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to decrypt CA key", err)
+	}
+	pair, err := tls.X509KeyPair([]byte(stored.CertificatePEM), []byte(keyPEM))
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "invalid CA key pair", err)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "invalid CA certificate", err)
+	}
+	signer, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New(errors.Internal, "CA key cannot sign")
+	}
+	return &issuingCA{cert: cert, key: signer, pem: stored.CertificatePEM}, nil
+}
+
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to generate serial number", err)
+	}
+	return serial, nil
+}
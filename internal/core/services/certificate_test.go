@@ -0,0 +1,245 @@
+package services_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// reversibleSecrets "encrypts" by prefixing, so tests can recover keys.
+type reversibleSecrets struct {
+	ports.SecretService
+}
+
+func (reversibleSecrets) Encrypt(_ context.Context, _ uuid.UUID, plain string) (string, error) {
+	return "enc:" + plain, nil
+}
+
+func (reversibleSecrets) Decrypt(_ context.Context, _ uuid.UUID, cipher string) (string, error) {
+	return strings.TrimPrefix(cipher, "enc:"), nil
+}
+
+// selfSignedPEM returns a self-signed certificate for domains and its key.
+func selfSignedPEM(t *testing.T, domains []string, notAfter time.Time) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: domains[0]},
+		Issuer:       pkix.Name{CommonName: domains[0]},
+		DNSNames:     domains,
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+}
+
+func setupCertificateServiceTest(t *testing.T) (*services.CertificateService, *MockCertificateRepo, *MockLBRepo, *MockGatewayRepo, context.Context) {
+	t.Helper()
+	repo := new(MockCertificateRepo)
+	lbRepo := new(MockLBRepo)
+	gwRepo := new(MockGatewayRepo)
+	auditSvc := new(MockAuditService)
+	auditSvc.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	svc := services.NewCertificateService(services.CertificateServiceParams{
+		Repo: repo, SecretSvc: reversibleSecrets{}, LBRepo: lbRepo, GatewayRepo: gwRepo, AuditSvc: auditSvc,
+	})
+	tenantID := uuid.New()
+	expectTenantCA(repo, tenantID)
+	ctx := appcontext.WithTenantID(appcontext.WithUserID(context.Background(), uuid.New()), tenantID)
+	return svc, repo, lbRepo, gwRepo, ctx
+}
+
+// expectTenantCA lets a tenant's CA be created on first use and read back.
+func expectTenantCA(repo *MockCertificateRepo, tenantID uuid.UUID) {
+	name := "tenant-" + tenantID.String()
+	stored := &domain.CertificateAuthority{}
+	repo.On("GetAuthority", mock.Anything, name).Return(nil, errors.New(errors.NotFound, "not found")).Once()
+	repo.On("CreateAuthority", mock.Anything, mock.MatchedBy(func(ca *domain.CertificateAuthority) bool {
+		return ca.Name == name
+	})).Run(func(args mock.Arguments) {
+		*stored = *args.Get(1).(*domain.CertificateAuthority)
+	}).Return(nil).Once()
+	repo.On("GetAuthority", mock.Anything, name).Return(stored, nil)
+}
+
+func TestCertificateServiceIssue(t *testing.T) {
+	svc, repo, _, _, ctx := setupCertificateServiceTest(t)
+	var created *domain.Certificate
+	repo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*domain.Certificate)
+	}).Return(nil)
+
+	cert, err := svc.CreateCertificate(ctx, ports.CreateCertificateParams{
+		Name: "web", Domains: []string{"App.Example.com.", "*.example.com", "app.example.com", "10.0.0.5"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.CertificateSourceIssued, cert.Source)
+	assert.Equal(t, domain.CertificateStatusActive, cert.Status)
+	assert.Equal(t, []string{"app.example.com", "*.example.com", "10.0.0.5"}, cert.Domains)
+	assert.Equal(t, appcontext.UserIDFromContext(ctx), cert.UserID)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 90), cert.NotAfter, time.Minute)
+	assert.True(t, strings.HasPrefix(created.EncryptedPrivateKey, "enc:"), "keys are stored encrypted")
+
+	caPEM, err := svc.GetAuthorityCertificate(ctx)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM([]byte(caPEM)))
+
+	block, _ := pem.Decode([]byte(cert.CertificatePEM))
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	for _, host := range []string{"app.example.com", "api.example.com", "10.0.0.5"} {
+		_, err = leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		assert.NoError(t, err, host)
+	}
+	assert.True(t, strings.HasSuffix(cert.CertificatePEM, caPEM), "the chain includes the CA")
+
+	repo.On("GetByID", mock.Anything, cert.ID).Return(created, nil)
+	pair, err := svc.GetKeyPair(ctx, cert.ID)
+	require.NoError(t, err)
+	_, err = tls.X509KeyPair([]byte(pair.CertificatePEM), []byte(pair.PrivateKeyPEM))
+	assert.NoError(t, err)
+
+	// The CA is created once and then cached.
+	_, err = svc.CreateCertificate(ctx, ports.CreateCertificateParams{Name: "api", Domains: []string{"api.example.com"}, ValidityDays: 7})
+	require.NoError(t, err)
+	repo.AssertNumberOfCalls(t, "CreateAuthority", 1)
+}
+
+func TestCertificateServiceIssueValidation(t *testing.T) {
+	svc, _, _, _, ctx := setupCertificateServiceTest(t)
+
+	cases := map[string]ports.CreateCertificateParams{
+		"no name":          {Domains: []string{"example.com"}},
+		"no domains":       {Name: "web"},
+		"invalid domain":   {Name: "web", Domains: []string{"bad_domain!"}},
+		"nested wildcard":  {Name: "web", Domains: []string{"*.*.example.com"}},
+		"too long":         {Name: "web", Domains: []string{"example.com"}, ValidityDays: 398},
+		"key without cert": {Name: "web", Domains: []string{"example.com"}, PrivateKeyPEM: "key"},
+	}
+	for name, params := range cases {
+		_, err := svc.CreateCertificate(ctx, params)
+		assert.True(t, errors.Is(err, errors.InvalidInput), name)
+	}
+
+	_, err := svc.CreateCertificate(context.Background(), ports.CreateCertificateParams{Name: "web", Domains: []string{"example.com"}})
+	assert.True(t, errors.Is(err, errors.Unauthorized))
+}
+
+func TestCertificateServiceImport(t *testing.T) {
+	svc, repo, _, _, ctx := setupCertificateServiceTest(t)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	notAfter := time.Now().Add(10 * 24 * time.Hour).Truncate(time.Second)
+	certPEM, keyPEM := selfSignedPEM(t, []string{"shop.example.org"}, notAfter)
+
+	cert, err := svc.CreateCertificate(ctx, ports.CreateCertificateParams{Name: "shop", CertificatePEM: certPEM, PrivateKeyPEM: keyPEM})
+	require.NoError(t, err)
+	assert.Equal(t, domain.CertificateSourceImported, cert.Source)
+	assert.Equal(t, domain.CertificateStatusExpiring, cert.Status)
+	assert.Equal(t, []string{"shop.example.org"}, cert.Domains)
+	assert.Equal(t, "CN=shop.example.org", cert.Issuer)
+	assert.True(t, notAfter.Equal(cert.NotAfter))
+
+	_, otherKey := selfSignedPEM(t, []string{"shop.example.org"}, notAfter)
+	_, err = svc.CreateCertificate(ctx, ports.CreateCertificateParams{Name: "shop", CertificatePEM: certPEM, PrivateKeyPEM: otherKey})
+	assert.True(t, errors.Is(err, errors.InvalidInput), "mismatched key")
+
+	expiredPEM, expiredKey := selfSignedPEM(t, []string{"old.example.org"}, time.Now().Add(-time.Hour))
+	_, err = svc.CreateCertificate(ctx, ports.CreateCertificateParams{Name: "old", CertificatePEM: expiredPEM, PrivateKeyPEM: expiredKey})
+	assert.True(t, errors.Is(err, errors.InvalidInput), "expired certificate")
+}
+
+func TestCertificateServiceTenantAuthorities(t *testing.T) {
+	svc, repo, _, _, ctx := setupCertificateServiceTest(t)
+	otherTenant := uuid.New()
+	expectTenantCA(repo, otherTenant)
+	otherCtx := appcontext.WithTenantID(appcontext.WithUserID(context.Background(), uuid.New()), otherTenant)
+
+	caPEM, err := svc.GetAuthorityCertificate(ctx)
+	require.NoError(t, err)
+	otherPEM, err := svc.GetAuthorityCertificate(otherCtx)
+	require.NoError(t, err)
+	assert.NotEqual(t, caPEM, otherPEM)
+
+	// A certificate issued to one tenant does not verify against another tenant's CA.
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	cert, err := svc.CreateCertificate(otherCtx, ports.CreateCertificateParams{Name: "web", Domains: []string{"app.example.com"}, ValidityDays: 7})
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM([]byte(caPEM)))
+	block, _ := pem.Decode([]byte(cert.CertificatePEM))
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	_, err = leaf.Verify(x509.VerifyOptions{DNSName: "app.example.com", Roots: roots})
+	assert.Error(t, err)
+
+	noTenant := appcontext.WithUserID(context.Background(), uuid.New())
+	_, err = svc.GetAuthorityCertificate(noTenant)
+	assert.True(t, errors.Is(err, errors.Unauthorized))
+}
+
+func TestCertificateServiceOwnership(t *testing.T) {
+	svc, repo, _, _, ctx := setupCertificateServiceTest(t)
+	other := &domain.Certificate{ID: uuid.New(), UserID: uuid.New(), Name: "theirs"}
+	repo.On("GetByID", mock.Anything, other.ID).Return(other, nil)
+
+	_, err := svc.GetCertificate(ctx, other.ID)
+	assert.True(t, errors.Is(err, errors.NotFound))
+	_, err = svc.GetKeyPair(ctx, other.ID)
+	assert.True(t, errors.Is(err, errors.NotFound))
+	assert.True(t, errors.Is(svc.DeleteCertificate(ctx, other.ID), errors.NotFound))
+	repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestCertificateServiceDeleteInUse(t *testing.T) {
+	svc, repo, lbRepo, gwRepo, ctx := setupCertificateServiceTest(t)
+	userID := appcontext.UserIDFromContext(ctx)
+	cert := &domain.Certificate{ID: uuid.New(), UserID: userID, Name: "web"}
+	repo.On("GetByID", mock.Anything, cert.ID).Return(cert, nil)
+
+	lbRepo.On("List", mock.Anything).Return([]*domain.LoadBalancer{
+		{Name: "front", TLS: &domain.LBTLSConfig{Port: 443, CertificateIDs: []uuid.UUID{cert.ID}}},
+	}, nil).Once()
+	err := svc.DeleteCertificate(ctx, cert.ID)
+	assert.True(t, errors.Is(err, errors.Conflict))
+	assert.Contains(t, err.Error(), "front")
+
+	lbRepo.On("List", mock.Anything).Return([]*domain.LoadBalancer{{Name: "plain"}}, nil)
+	gwRepo.On("ListRoutes", mock.Anything, userID).Return([]*domain.GatewayRoute{{Name: "api", CertificateID: &cert.ID}}, nil).Once()
+	err = svc.DeleteCertificate(ctx, cert.ID)
+	assert.True(t, errors.Is(err, errors.Conflict))
+	assert.Contains(t, err.Error(), "api")
+
+	gwRepo.On("ListRoutes", mock.Anything, userID).Return([]*domain.GatewayRoute{}, nil)
+	repo.On("Delete", mock.Anything, cert.ID).Return(nil)
+	require.NoError(t, svc.DeleteCertificate(ctx, cert.ID))
+	repo.AssertCalled(t, "Delete", mock.Anything, cert.ID)
+}
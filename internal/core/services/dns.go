@@ -106,6 +106,11 @@ func (s *DNSService) CreateZone(ctx context.Context, vpcID uuid.UUID, name, desc
 		_ = s.backend.DeleteZone(ctx, powerdnsZone)
 		return nil, errors.Wrap(errors.Internal, "failed to save zone", err)
 	}
+	if vpcBackend, ok := s.backend.(ports.VPCDNSBackend); ok {
+		if err := vpcBackend.ServeVPCZone(ctx, vpcID); err != nil {
+			s.logger.Warn("failed to serve zone on VPC listener", "zone", name, "vpc_id", vpcID, "error", err)
+		}
+	}
 
 	// 6. Audit log
 	_ = s.auditSvc.Log(ctx, userID, "dns.zone.create", "dns_zone", zone.ID.String(), map[string]interface{}{
@@ -150,6 +155,11 @@ func (s *DNSService) DeleteZone(ctx context.Context, idOrName string) error {
 	if err := s.repo.DeleteZone(ctx, zone.ID); err != nil {
 		return err
 	}
+	if vpcBackend, ok := s.backend.(ports.VPCDNSBackend); ok {
+		if err := vpcBackend.StopVPCZone(ctx, zone.VpcID); err != nil {
+			s.logger.Warn("failed to stop VPC zone listener", "zone", zone.Name, "vpc_id", zone.VpcID, "error", err)
+		}
+	}
 
 	_ = s.auditSvc.Log(ctx, zone.UserID, "dns.zone.delete", "dns_zone", zone.ID.String(), map[string]interface{}{
 		"name": zone.Name,
@@ -326,7 +336,8 @@ func (s *DNSService) DeleteRecord(ctx context.Context, id uuid.UUID) error {
 	return s.repo.DeleteRecord(ctx, id)
 }
 
-// RegisterInstance creates an A record for an instance in its VPC's private zone.
+// RegisterInstance creates an A record for an instance in its VPC's private zone,
+// plus an AAAA record when the instance has an IPv6 address.
 func (s *DNSService) RegisterInstance(ctx context.Context, instance *domain.Instance, ipAddress string) error {
 	s.logger.Info("RegisterInstance called", "instance", instance.Name, "vpc_id", instance.VpcID, "ip", ipAddress)
 	if instance.VpcID == nil {
@@ -343,37 +354,49 @@ func (s *DNSService) RegisterInstance(ctx context.Context, instance *domain.Inst
 
 	fqdn := fmt.Sprintf("%s.%s.", instance.Name, zone.Name)
 
-	// Add record to PowerDNS
-	recordSet := ports.RecordSet{
-		Name:    fqdn,
-		Type:    "A",
-		TTL:     zone.DefaultTTL,
-		Records: []string{ipAddress},
+	addresses := map[domain.RecordType]string{domain.RecordTypeA: ipAddress}
+	if instance.PrivateIPv6 != "" {
+		addresses[domain.RecordTypeAAAA] = instance.PrivateIPv6
 	}
 
-	if err := s.backend.AddRecords(ctx, zone.PowerDNSID, []ports.RecordSet{recordSet}); err != nil {
-		return errors.Wrap(errors.Internal, "failed to register instance DNS in backend", err)
-	}
+	for _, recordType := range []domain.RecordType{domain.RecordTypeA, domain.RecordTypeAAAA} {
+		content, ok := addresses[recordType]
+		if !ok {
+			continue
+		}
 
-	// Save to database
-	record := &domain.DNSRecord{
-		ID:          uuid.New(),
-		ZoneID:      zone.ID,
-		Name:        instance.Name,
-		Type:        domain.RecordTypeA,
-		Content:     ipAddress,
-		TTL:         zone.DefaultTTL,
-		AutoManaged: true,
-		InstanceID:  &instance.ID,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-	}
+		// Add record to PowerDNS
+		recordSet := ports.RecordSet{
+			Name:    fqdn,
+			Type:    string(recordType),
+			TTL:     zone.DefaultTTL,
+			Records: []string{content},
+		}
 
-	if err := s.repo.CreateRecord(ctx, record); err != nil {
-		s.logger.Warn("failed to save DNS record to database", "error", err)
+		if err := s.backend.AddRecords(ctx, zone.PowerDNSID, []ports.RecordSet{recordSet}); err != nil {
+			return errors.Wrap(errors.Internal, "failed to register instance DNS in backend", err)
+		}
+
+		// Save to database
+		record := &domain.DNSRecord{
+			ID:          uuid.New(),
+			ZoneID:      zone.ID,
+			Name:        instance.Name,
+			Type:        recordType,
+			Content:     content,
+			TTL:         zone.DefaultTTL,
+			AutoManaged: true,
+			InstanceID:  &instance.ID,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+
+		if err := s.repo.CreateRecord(ctx, record); err != nil {
+			s.logger.Warn("failed to save DNS record to database", "error", err)
+		}
 	}
 
-	s.logger.Info("registered instance DNS", "instance", instance.Name, "fqdn", fqdn, "ip", ipAddress)
+	s.logger.Info("registered instance DNS", "instance", instance.Name, "fqdn", fqdn, "ip", ipAddress, "ipv6", instance.PrivateIPv6)
 	return nil
 }
 
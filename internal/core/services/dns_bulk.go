@@ -0,0 +1,372 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
+)
+
+// maxRecordChanges caps the size of one change batch or zone import.
+const maxRecordChanges = 1000
+
+// recordSetKey identifies the records of one name and type.
+type recordSetKey struct {
+	name       string
+	recordType domain.RecordType
+}
+
+// recordChangePlan is the outcome of applying a change batch to a zone's current records.
+type recordChangePlan struct {
+	deletes []uuid.UUID
+	creates []*domain.DNSRecord
+	// before and after hold the backend record sets of every name and type the
+	// batch touches, so the backend can be rolled back if the database write fails.
+	before []ports.RecordSet
+	after  []ports.RecordSet
+}
+
+// ChangeRecords applies a batch of record changes to a zone with all-or-nothing
+// semantics: either every change is applied, in the backend and the database, or none is.
+func (s *DNSService) ChangeRecords(ctx context.Context, zoneID uuid.UUID, changes []domain.DNSRecordChange) ([]*domain.DNSRecord, error) {
+	if len(changes) == 0 {
+		return nil, errors.New(errors.InvalidInput, "change batch is empty")
+	}
+	if len(changes) > maxRecordChanges {
+		return nil, errors.New(errors.InvalidInput, fmt.Sprintf("change batch exceeds %d changes", maxRecordChanges))
+	}
+
+	zone, err := s.tenantZone(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.ListRecordsByZone(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := planRecordChanges(zone, existing, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.backend.ReplaceRecordSets(ctx, zone.PowerDNSID, plan.after); err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to apply record changes in backend", err)
+	}
+
+	if err := s.repo.ApplyRecordChanges(ctx, zoneID, plan.deletes, plan.creates); err != nil {
+		if rbErr := s.backend.ReplaceRecordSets(ctx, zone.PowerDNSID, plan.before); rbErr != nil {
+			s.logger.Error("failed to roll back record changes in backend", "zone", zone.Name, "error", rbErr)
+		}
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "dns.records.change", "dns_zone", zone.ID.String(), map[string]interface{}{
+		"changes": len(changes),
+		"created": len(plan.creates),
+		"deleted": len(plan.deletes),
+	})
+
+	s.logger.Info("applied dns record changes", "zone", zone.Name, "created", len(plan.creates), "deleted", len(plan.deletes))
+	return plan.creates, nil
+}
+
+// ImportZone loads an RFC 1035 master file into a zone. Each name and type in
+// the file replaces the zone's records of that name and type; other records are kept.
+func (s *DNSService) ImportZone(ctx context.Context, zoneID uuid.UUID, zoneFile string) ([]*domain.DNSRecord, error) {
+	zone, err := s.tenantZone(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := zone.DefaultTTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	changes, err := parseZoneFile(zoneFile, zone.Name, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, errors.New(errors.InvalidInput, "zone file contains no records to import")
+	}
+
+	return s.ChangeRecords(ctx, zoneID, changes)
+}
+
+// ExportZone renders a zone's records as an RFC 1035 master file.
+func (s *DNSService) ExportZone(ctx context.Context, zoneID uuid.UUID) (string, error) {
+	zone, err := s.tenantZone(ctx, zoneID)
+	if err != nil {
+		return "", err
+	}
+	records, err := s.repo.ListRecordsByZone(ctx, zoneID)
+	if err != nil {
+		return "", err
+	}
+
+	serial := uint32(1)
+	if info, err := s.backend.GetZone(ctx, zone.PowerDNSID); err == nil && info.Serial > 0 {
+		serial = info.Serial
+	}
+
+	return formatZoneFile(zone, records, serial), nil
+}
+
+// tenantZone loads a zone of the caller's tenant. A zone of another tenant is reported
+// as missing, the way GetZoneByName does not find it.
+func (s *DNSService) tenantZone(ctx context.Context, zoneID uuid.UUID) (*domain.DNSZone, error) {
+	zone, err := s.repo.GetZoneByID(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	if zone.TenantID != appcontext.TenantIDFromContext(ctx) {
+		return nil, errors.New(errors.NotFound, "dns zone not found")
+	}
+	return zone, nil
+}
+
+// planRecordChanges applies changes, in order, to an in-memory copy of the
+// zone's records and works out which rows to delete and create.
+func planRecordChanges(zone *domain.DNSZone, existing []*domain.DNSRecord, changes []domain.DNSRecordChange) (*recordChangePlan, error) {
+	apex := absoluteName(zone.Name)
+	now := time.Now()
+
+	state := make(map[recordSetKey][]*domain.DNSRecord)
+	existingIDs := make(map[uuid.UUID]bool, len(existing))
+	for _, r := range existing {
+		key := recordSetKey{name: strings.ToLower(r.Name), recordType: r.Type}
+		state[key] = append(state[key], r)
+		existingIDs[r.ID] = true
+	}
+
+	touched := make(map[recordSetKey]bool)
+	upserted := make(map[recordSetKey]bool)
+	var created []*domain.DNSRecord
+
+	for i, c := range changes {
+		changeErr := func(msg string) error {
+			return errors.New(errors.InvalidInput, fmt.Sprintf("change %d (%s %s): %s", i+1, c.Name, c.Type, msg))
+		}
+
+		if !domain.IsValidRecordType(c.Type) {
+			return nil, changeErr("invalid record type")
+		}
+		name, ok := changeRecordName(c.Name, apex)
+		if !ok {
+			return nil, changeErr("name is outside the zone")
+		}
+		key := recordSetKey{name: name, recordType: c.Type}
+		current := state[key]
+
+		switch c.Action {
+		case domain.DNSChangeCreate, domain.DNSChangeUpsert:
+			if err := validateRecordContent(c.Type, c.Content); err != nil {
+				return nil, changeErr(err.Error())
+			}
+			if conflict := cnameConflict(state, name, c.Type); conflict != "" {
+				return nil, errors.New(errors.Conflict, fmt.Sprintf("change %d (%s %s): %s", i+1, c.Name, c.Type, conflict))
+			}
+
+			if c.Action == domain.DNSChangeUpsert && !upserted[key] {
+				for _, r := range current {
+					if r.AutoManaged {
+						return nil, errors.New(errors.Conflict, fmt.Sprintf("change %d (%s %s): record is managed by instance auto-registration", i+1, c.Name, c.Type))
+					}
+				}
+				current = nil
+				upserted[key] = true
+			}
+			duplicate := false
+			for _, r := range current {
+				if r.Content == c.Content {
+					duplicate = true
+				}
+			}
+			if duplicate {
+				if c.Action == domain.DNSChangeCreate {
+					return nil, errors.New(errors.Conflict, fmt.Sprintf("change %d (%s %s): record already exists", i+1, c.Name, c.Type))
+				}
+				continue
+			}
+
+			record := &domain.DNSRecord{
+				ID:        uuid.New(),
+				ZoneID:    zone.ID,
+				Name:      name,
+				Type:      c.Type,
+				Content:   c.Content,
+				TTL:       clampTTL(c.TTL, zone.DefaultTTL),
+				Priority:  c.Priority,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			state[key] = append(current, record)
+			created = append(created, record)
+
+		case domain.DNSChangeDelete:
+			var kept []*domain.DNSRecord
+			matched := false
+			for _, r := range current {
+				if c.Content != "" && r.Content != c.Content {
+					kept = append(kept, r)
+					continue
+				}
+				if r.AutoManaged {
+					return nil, errors.New(errors.Conflict, fmt.Sprintf("change %d (%s %s): record is managed by instance auto-registration", i+1, c.Name, c.Type))
+				}
+				matched = true
+			}
+			if !matched {
+				return nil, errors.New(errors.NotFound, fmt.Sprintf("change %d (%s %s): no matching record to delete", i+1, c.Name, c.Type))
+			}
+			state[key] = kept
+
+		default:
+			return nil, changeErr("action must be CREATE, UPSERT or DELETE")
+		}
+
+		touched[key] = true
+	}
+
+	plan := &recordChangePlan{}
+	final := make(map[uuid.UUID]bool)
+	for _, records := range state {
+		for _, r := range records {
+			final[r.ID] = true
+		}
+	}
+	for _, r := range existing {
+		if !final[r.ID] {
+			plan.deletes = append(plan.deletes, r.ID)
+		}
+	}
+	for _, r := range created {
+		if final[r.ID] && !existingIDs[r.ID] {
+			plan.creates = append(plan.creates, r)
+		}
+	}
+
+	keys := make([]recordSetKey, 0, len(touched))
+	for key := range touched {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].recordType < keys[j].recordType
+	})
+
+	original := make(map[recordSetKey][]*domain.DNSRecord)
+	for _, r := range existing {
+		key := recordSetKey{name: strings.ToLower(r.Name), recordType: r.Type}
+		original[key] = append(original[key], r)
+	}
+	for _, key := range keys {
+		plan.before = append(plan.before, backendRecordSet(key, original[key], apex))
+		plan.after = append(plan.after, backendRecordSet(key, state[key], apex))
+	}
+
+	return plan, nil
+}
+
+// backendRecordSet builds the backend view of a name and type. Disabled
+// records are left out; a set with no records tells the backend to delete it.
+func backendRecordSet(key recordSetKey, records []*domain.DNSRecord, apex string) ports.RecordSet {
+	name := apex
+	if key.name != "@" {
+		name = key.name + "." + apex
+	}
+
+	rs := ports.RecordSet{Name: name, Type: string(key.recordType), TTL: defaultTTL}
+	for _, r := range records {
+		if r.Disabled {
+			continue
+		}
+		rs.TTL = r.TTL
+		rs.Records = append(rs.Records, backendContent(r))
+	}
+	return rs
+}
+
+// backendContent prefixes MX and SRV content with the record's priority, as
+// the backends expect it in the record data.
+func backendContent(r *domain.DNSRecord) string {
+	if r.Priority == nil {
+		return r.Content
+	}
+	switch {
+	case r.Type == domain.RecordTypeMX && len(strings.Fields(r.Content)) == 1,
+		r.Type == domain.RecordTypeSRV && len(strings.Fields(r.Content)) == 3:
+		return fmt.Sprintf("%d %s", *r.Priority, r.Content)
+	}
+	return r.Content
+}
+
+// changeRecordName normalizes a change's name to the zone-relative form
+// records are stored with. Fully qualified names must be inside the zone.
+func changeRecordName(name, apex string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch {
+	case name == "" || name == "@":
+		return "@", true
+	case strings.HasSuffix(name, "."):
+		return relativeRecordName(name, apex)
+	case name == strings.TrimSuffix(apex, "."):
+		return "@", true
+	default:
+		if rel, ok := relativeRecordName(name+".", apex); ok {
+			return rel, true
+		}
+		return name, true
+	}
+}
+
+// cnameConflict reports why a record cannot coexist with the records at its name.
+func cnameConflict(state map[recordSetKey][]*domain.DNSRecord, name string, recordType domain.RecordType) string {
+	for key, records := range state {
+		if key.name != name || len(records) == 0 || key.recordType == recordType {
+			continue
+		}
+		if recordType == domain.RecordTypeCNAME || key.recordType == domain.RecordTypeCNAME {
+			return "a CNAME cannot coexist with other records of the same name"
+		}
+	}
+	return ""
+}
+
+func validateRecordContent(recordType domain.RecordType, content string) error {
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("content is required")
+	}
+	switch recordType {
+	case domain.RecordTypeA:
+		if ip := net.ParseIP(content); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("content must be an IPv4 address")
+		}
+	case domain.RecordTypeAAAA:
+		if ip := net.ParseIP(content); ip == nil || ip.To4() != nil {
+			return fmt.Errorf("content must be an IPv6 address")
+		}
+	}
+	return nil
+}
+
+func clampTTL(ttl, zoneDefault int) int {
+	if ttl <= 0 {
+		ttl = zoneDefault
+	}
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	return min(max(ttl, minTTL), maxTTL)
+}
@@ -194,12 +194,13 @@ func TestDNSServiceRegisterInstance(t *testing.T) {
 	require.NoError(t, err)
 
 	inst := &domain.Instance{
-		ID:       uuid.New(),
-		UserID:   userID,
-		TenantID: tenantID,
-		VpcID:    &vpc.ID,
-		Name:     "my-inst",
-		Status:   domain.StatusRunning,
+		ID:          uuid.New(),
+		UserID:      userID,
+		TenantID:    tenantID,
+		VpcID:       &vpc.ID,
+		Name:        "my-inst",
+		Status:      domain.StatusRunning,
+		PrivateIPv6: "fd00:10:0:1::2",
 	}
 	err = instRepo.Create(ctx, inst)
 	require.NoError(t, err)
@@ -226,6 +227,15 @@ func TestDNSServiceRegisterInstance(t *testing.T) {
 	}
 	assert.True(t, found, "Expected A record for instance")
 
+	foundAAAA := false
+	for _, r := range records {
+		if r.Name == "my-inst" && r.Type == domain.RecordTypeAAAA && r.Content == "fd00:10:0:1::2" {
+			foundAAAA = true
+			break
+		}
+	}
+	assert.True(t, foundAAAA, "Expected AAAA record for dual-stack instance")
+
 	// Unregister
 	err = svc.UnregisterInstance(ctx, inst.ID)
 	assert.NoError(t, err)
@@ -10,6 +10,7 @@ import (
 	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -88,6 +89,9 @@ func (m *MockDNSRepository) DeleteRecord(ctx context.Context, id uuid.UUID) erro
 func (m *MockDNSRepository) DeleteRecordsByInstance(ctx context.Context, instanceID uuid.UUID) error {
 	return m.Called(ctx, instanceID).Error(0)
 }
+func (m *MockDNSRepository) ApplyRecordChanges(ctx context.Context, zoneID uuid.UUID, deletes []uuid.UUID, creates []*domain.DNSRecord) error {
+	return m.Called(ctx, zoneID, deletes, creates).Error(0)
+}
 
 type MockDNSBackend struct {
 	mock.Mock
@@ -119,6 +123,9 @@ func (m *MockDNSBackend) ListRecords(ctx context.Context, zoneID string) ([]port
 	args := m.Called(ctx, zoneID)
 	return args.Get(0).([]ports.RecordSet), args.Error(1)
 }
+func (m *MockDNSBackend) ReplaceRecordSets(ctx context.Context, zoneID string, records []ports.RecordSet) error {
+	return m.Called(ctx, zoneID, records).Error(0)
+}
 
 func TestDNSService_Unit_Extended(t *testing.T) {
 	repo := new(MockDNSRepository)
@@ -211,4 +218,144 @@ func TestDNSService_Unit_Extended(t *testing.T) {
 		err := svc.UnregisterInstance(ctx, instID)
 		assert.NoError(t, err)
 	})
+
+	t.Run("ChangeRecords", func(t *testing.T) {
+		zoneID := uuid.New()
+		zone := &domain.DNSZone{ID: zoneID, Name: "example.com", PowerDNSID: "example.com.", DefaultTTL: 300}
+		old := &domain.DNSRecord{ID: uuid.New(), ZoneID: zoneID, Name: "www", Type: domain.RecordTypeA, Content: "10.0.0.1", TTL: 300}
+		stale := &domain.DNSRecord{ID: uuid.New(), ZoneID: zoneID, Name: "old", Type: domain.RecordTypeA, Content: "10.0.0.9", TTL: 300}
+		repo.On("GetZoneByID", mock.Anything, zoneID).Return(zone, nil).Once()
+		repo.On("ListRecordsByZone", mock.Anything, zoneID).Return([]*domain.DNSRecord{old, stale}, nil).Once()
+		backend.On("ReplaceRecordSets", mock.Anything, "example.com.", mock.MatchedBy(func(sets []ports.RecordSet) bool {
+			return len(sets) == 2 &&
+				sets[0].Name == "old.example.com." && len(sets[0].Records) == 0 &&
+				sets[1].Name == "www.example.com." && len(sets[1].Records) == 2
+		})).Return(nil).Once()
+		repo.On("ApplyRecordChanges", mock.Anything, zoneID, []uuid.UUID{old.ID, stale.ID}, mock.MatchedBy(func(creates []*domain.DNSRecord) bool {
+			return len(creates) == 2 && creates[0].Content == "10.0.0.2" && creates[1].Content == "10.0.0.3"
+		})).Return(nil).Once()
+		auditSvc.On("Log", mock.Anything, userID, "dns.records.change", "dns_zone", zoneID.String(), mock.Anything).Return(nil).Once()
+
+		created, err := svc.ChangeRecords(ctx, zoneID, []domain.DNSRecordChange{
+			{Action: domain.DNSChangeUpsert, Name: "www", Type: domain.RecordTypeA, Content: "10.0.0.2"},
+			{Action: domain.DNSChangeUpsert, Name: "www.example.com.", Type: domain.RecordTypeA, Content: "10.0.0.3"},
+			{Action: domain.DNSChangeDelete, Name: "old", Type: domain.RecordTypeA},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, created, 2)
+	})
+
+	t.Run("ChangeRecords_RejectsBatchAtomically", func(t *testing.T) {
+		zoneID := uuid.New()
+		zone := &domain.DNSZone{ID: zoneID, Name: "atomic.com", PowerDNSID: "atomic.com."}
+		repo.On("GetZoneByID", mock.Anything, zoneID).Return(zone, nil).Once()
+		repo.On("ListRecordsByZone", mock.Anything, zoneID).Return([]*domain.DNSRecord{}, nil).Once()
+
+		_, err := svc.ChangeRecords(ctx, zoneID, []domain.DNSRecordChange{
+			{Action: domain.DNSChangeCreate, Name: "www", Type: domain.RecordTypeA, Content: "10.0.0.2"},
+			{Action: domain.DNSChangeCreate, Name: "www", Type: domain.RecordTypeCNAME, Content: "web.example.com."},
+		})
+		assert.Error(t, err)
+		backend.AssertNotCalled(t, "ReplaceRecordSets", mock.Anything, "atomic.com.", mock.Anything)
+	})
+
+	t.Run("ChangeRecords_RollsBackBackendOnRepoFailure", func(t *testing.T) {
+		zoneID := uuid.New()
+		zone := &domain.DNSZone{ID: zoneID, Name: "rollback.com", PowerDNSID: "rollback.com."}
+		repo.On("GetZoneByID", mock.Anything, zoneID).Return(zone, nil).Once()
+		repo.On("ListRecordsByZone", mock.Anything, zoneID).Return([]*domain.DNSRecord{}, nil).Once()
+		backend.On("ReplaceRecordSets", mock.Anything, "rollback.com.", mock.MatchedBy(func(sets []ports.RecordSet) bool {
+			return len(sets) == 1 && len(sets[0].Records) == 1
+		})).Return(nil).Once()
+		repo.On("ApplyRecordChanges", mock.Anything, zoneID, mock.Anything, mock.Anything).Return(assert.AnError).Once()
+		backend.On("ReplaceRecordSets", mock.Anything, "rollback.com.", mock.MatchedBy(func(sets []ports.RecordSet) bool {
+			return len(sets) == 1 && len(sets[0].Records) == 0
+		})).Return(nil).Once()
+
+		_, err := svc.ChangeRecords(ctx, zoneID, []domain.DNSRecordChange{
+			{Action: domain.DNSChangeCreate, Name: "www", Type: domain.RecordTypeA, Content: "10.0.0.2"},
+		})
+		assert.Error(t, err)
+		backend.AssertExpectations(t)
+	})
+
+	t.Run("ExportZone", func(t *testing.T) {
+		zoneID := uuid.New()
+		zone := &domain.DNSZone{ID: zoneID, Name: "export.com", PowerDNSID: "export.com.", DefaultTTL: 300}
+		repo.On("GetZoneByID", mock.Anything, zoneID).Return(zone, nil).Once()
+		repo.On("ListRecordsByZone", mock.Anything, zoneID).Return([]*domain.DNSRecord{
+			{Name: "www", Type: domain.RecordTypeA, Content: "10.0.0.2", TTL: 300},
+		}, nil).Once()
+		backend.On("GetZone", mock.Anything, "export.com.").Return(&ports.ZoneInfo{Name: "export.com.", Serial: 7}, nil).Once()
+
+		out, err := svc.ExportZone(ctx, zoneID)
+		assert.NoError(t, err)
+		assert.Contains(t, out, "$ORIGIN export.com.")
+		assert.Contains(t, out, "10.0.0.2")
+	})
+
+	t.Run("BulkOperationsHideOtherTenantsZones", func(t *testing.T) {
+		zoneID := uuid.New()
+		zone := &domain.DNSZone{ID: zoneID, Name: "other.com", PowerDNSID: "other.com.", TenantID: uuid.New()}
+		repo.On("GetZoneByID", mock.Anything, zoneID).Return(zone, nil).Times(3)
+		tenantCtx := appcontext.WithTenantID(ctx, uuid.New())
+
+		_, err := svc.ChangeRecords(tenantCtx, zoneID, []domain.DNSRecordChange{
+			{Action: domain.DNSChangeCreate, Name: "www", Type: domain.RecordTypeA, Content: "10.0.0.2"},
+		})
+		assert.True(t, errors.Is(err, errors.NotFound))
+		_, err = svc.ImportZone(tenantCtx, zoneID, "www 300 IN A 10.0.0.2\n")
+		assert.True(t, errors.Is(err, errors.NotFound))
+		_, err = svc.ExportZone(tenantCtx, zoneID)
+		assert.True(t, errors.Is(err, errors.NotFound))
+		repo.AssertNotCalled(t, "ListRecordsByZone", mock.Anything, zoneID)
+		backend.AssertNotCalled(t, "ReplaceRecordSets", mock.Anything, "other.com.", mock.Anything)
+	})
+}
+
+type MockVPCDNSBackend struct {
+	MockDNSBackend
+}
+
+func (m *MockVPCDNSBackend) ServeVPCZone(ctx context.Context, vpcID uuid.UUID) error {
+	return m.Called(ctx, vpcID).Error(0)
+}
+func (m *MockVPCDNSBackend) StopVPCZone(ctx context.Context, vpcID uuid.UUID) error {
+	return m.Called(ctx, vpcID).Error(0)
+}
+
+func TestDNSService_VPCListeners(t *testing.T) {
+	repo := new(MockDNSRepository)
+	backend := new(MockVPCDNSBackend)
+	vpcRepo := new(MockVpcRepo)
+	auditSvc := new(MockAuditService)
+	auditSvc.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	svc := services.NewDNSService(services.DNSServiceParams{
+		Repo:     repo,
+		Backend:  backend,
+		VpcRepo:  vpcRepo,
+		AuditSvc: auditSvc,
+		EventSvc: new(MockEventService),
+		Logger:   slog.Default(),
+	})
+	ctx := appcontext.WithUserID(context.Background(), uuid.New())
+	vpcID := uuid.New()
+
+	vpcRepo.On("GetByID", mock.Anything, vpcID).Return(&domain.VPC{ID: vpcID, Name: "test-vpc"}, nil).Once()
+	repo.On("GetZoneByVPC", mock.Anything, vpcID).Return(nil, nil).Once()
+	backend.On("CreateZone", mock.Anything, "internal.", mock.Anything).Return(nil).Once()
+	repo.On("CreateZone", mock.Anything, mock.Anything).Return(nil).Once()
+	backend.On("ServeVPCZone", mock.Anything, vpcID).Return(nil).Once()
+
+	zone, err := svc.CreateZone(ctx, vpcID, "internal", "")
+	assert.NoError(t, err)
+
+	repo.On("GetZoneByID", mock.Anything, zone.ID).Return(zone, nil).Once()
+	backend.On("DeleteZone", mock.Anything, "internal.").Return(nil).Once()
+	repo.On("DeleteZone", mock.Anything, zone.ID).Return(nil).Once()
+	backend.On("StopVPCZone", mock.Anything, vpcID).Return(nil).Once()
+
+	assert.NoError(t, svc.DeleteZone(ctx, zone.ID.String()))
+	backend.AssertExpectations(t)
 }
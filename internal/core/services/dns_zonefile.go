@@ -0,0 +1,461 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+)
+
+// SOA timers written to exported zone files. They match the SOA the DNS backends serve.
+const (
+	zoneFileSOATTL  = 3600
+	zoneFileRefresh = 10800
+	zoneFileRetry   = 3600
+	zoneFileExpire  = 604800
+	zoneFileMinTTL  = 3600
+	maxTXTString    = 255
+)
+
+// zoneToken is one field of a master file entry.
+type zoneToken struct {
+	text   string
+	quoted bool
+}
+
+// zoneLine is one logical master file entry; parentheses may span several physical lines.
+type zoneLine struct {
+	tokens     []zoneToken
+	blankOwner bool
+	lineNo     int
+}
+
+// parseZoneFile parses an RFC 1035 master file into UPSERT changes for a zone.
+// $ORIGIN and $TTL are honoured; SOA and apex NS records are skipped because
+// the DNS backend manages them.
+func parseZoneFile(zoneFile, zoneName string, defaultTTL int) ([]domain.DNSRecordChange, error) {
+	lines, err := splitZoneLines(zoneFile)
+	if err != nil {
+		return nil, err
+	}
+
+	apex := absoluteName(zoneName)
+	origin := apex
+	dollarTTL, lastTTL := 0, defaultTTL
+	lastOwner := ""
+
+	var changes []domain.DNSRecordChange
+	for _, line := range lines {
+		lineErr := func(format string, args ...interface{}) error {
+			return errors.New(errors.InvalidInput, fmt.Sprintf("zone file line %d: ", line.lineNo)+fmt.Sprintf(format, args...))
+		}
+		tokens := line.tokens
+
+		if !line.blankOwner && strings.HasPrefix(tokens[0].text, "$") && !tokens[0].quoted {
+			switch strings.ToUpper(tokens[0].text) {
+			case "$ORIGIN":
+				if len(tokens) != 2 {
+					return nil, lineErr("$ORIGIN takes one domain name")
+				}
+				origin = expandName(tokens[1].text, origin)
+			case "$TTL":
+				if len(tokens) != 2 {
+					return nil, lineErr("$TTL takes one value")
+				}
+				ttl, ok := parseZoneTTL(tokens[1].text)
+				if !ok {
+					return nil, lineErr("invalid $TTL %q", tokens[1].text)
+				}
+				dollarTTL = ttl
+			default:
+				return nil, lineErr("unsupported directive %s", tokens[0].text)
+			}
+			continue
+		}
+
+		owner := lastOwner
+		if !line.blankOwner {
+			owner = expandName(tokens[0].text, origin)
+			tokens = tokens[1:]
+		}
+		if owner == "" {
+			return nil, lineErr("record has no owner name")
+		}
+		lastOwner = owner
+
+		ttl := 0
+		for i := 0; i < 2 && len(tokens) > 0; i++ {
+			if v, ok := parseZoneTTL(tokens[0].text); ok && ttl == 0 {
+				ttl = v
+			} else if isZoneClass(tokens[0].text) {
+				if !strings.EqualFold(tokens[0].text, "IN") {
+					return nil, lineErr("only class IN is supported")
+				}
+			} else {
+				break
+			}
+			tokens = tokens[1:]
+		}
+		switch {
+		case ttl > 0:
+			lastTTL = ttl
+		case dollarTTL > 0:
+			ttl = dollarTTL
+		default:
+			ttl = lastTTL
+		}
+
+		if len(tokens) == 0 {
+			return nil, lineErr("missing record type")
+		}
+		recordType := strings.ToUpper(tokens[0].text)
+		rdata := tokens[1:]
+
+		if recordType == "SOA" || (recordType == "NS" && owner == apex) {
+			if owner != apex {
+				return nil, lineErr("SOA record must be at the zone apex")
+			}
+			continue
+		}
+
+		name, ok := relativeRecordName(owner, apex)
+		if !ok {
+			return nil, lineErr("%s is outside zone %s", owner, apex)
+		}
+
+		change := domain.DNSRecordChange{Action: domain.DNSChangeUpsert, Name: name, Type: domain.RecordType(recordType), TTL: ttl}
+		switch change.Type {
+		case domain.RecordTypeA, domain.RecordTypeAAAA:
+			if len(rdata) != 1 {
+				return nil, lineErr("%s record takes one address", recordType)
+			}
+			change.Content = rdata[0].text
+		case domain.RecordTypeCNAME:
+			if len(rdata) != 1 {
+				return nil, lineErr("CNAME record takes one target")
+			}
+			change.Content = expandName(rdata[0].text, origin)
+		case domain.RecordTypeMX:
+			if len(rdata) != 2 {
+				return nil, lineErr("MX record takes a preference and an exchange")
+			}
+			pref, err := strconv.ParseUint(rdata[0].text, 10, 16)
+			if err != nil {
+				return nil, lineErr("invalid MX preference %q", rdata[0].text)
+			}
+			priority := int(pref)
+			change.Priority = &priority
+			change.Content = expandName(rdata[1].text, origin)
+		case domain.RecordTypeSRV:
+			if len(rdata) != 4 {
+				return nil, lineErr("SRV record takes priority, weight, port and target")
+			}
+			var nums [3]uint64
+			for i := range nums {
+				if nums[i], err = strconv.ParseUint(rdata[i].text, 10, 16); err != nil {
+					return nil, lineErr("invalid SRV field %q", rdata[i].text)
+				}
+			}
+			priority := int(nums[0])
+			change.Priority = &priority
+			change.Content = fmt.Sprintf("%d %d %s", nums[1], nums[2], expandName(rdata[3].text, origin))
+		case domain.RecordTypeTXT:
+			if len(rdata) == 0 {
+				return nil, lineErr("TXT record has no text")
+			}
+			var sb strings.Builder
+			for _, t := range rdata {
+				sb.WriteString(t.text)
+			}
+			change.Content = sb.String()
+		default:
+			return nil, lineErr("unsupported record type %s", recordType)
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// splitZoneLines tokenizes a master file: it strips comments, joins
+// parenthesized continuations and unescapes quoted strings.
+func splitZoneLines(zoneFile string) ([]zoneLine, error) {
+	var (
+		lines   []zoneLine
+		current zoneLine
+		token   strings.Builder
+		inToken bool
+		quoted  bool
+		inQuote bool
+		comment bool
+		depth   int
+		lineNo  = 1
+	)
+	atLineStart := true
+	current.lineNo = 1
+
+	flushToken := func() {
+		if inToken {
+			current.tokens = append(current.tokens, zoneToken{text: token.String(), quoted: quoted})
+			token.Reset()
+			inToken, quoted = false, false
+		}
+	}
+	flushLine := func() {
+		flushToken()
+		if len(current.tokens) > 0 {
+			lines = append(lines, current)
+		}
+		current = zoneLine{lineNo: lineNo}
+		atLineStart = true
+	}
+
+	runes := []rune(zoneFile)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		if comment {
+			if c != '\n' {
+				continue
+			}
+			comment = false
+		}
+
+		if inQuote {
+			switch c {
+			case '"':
+				inQuote = false
+			case '\\':
+				if i+1 >= len(runes) {
+					return nil, errors.New(errors.InvalidInput, fmt.Sprintf("zone file line %d: dangling escape", lineNo))
+				}
+				if i+3 < len(runes) && isDigits(runes[i+1:i+4]) {
+					v, _ := strconv.Atoi(string(runes[i+1 : i+4]))
+					token.WriteByte(byte(v))
+					i += 3
+				} else {
+					i++
+					token.WriteRune(runes[i])
+				}
+			case '\n':
+				return nil, errors.New(errors.InvalidInput, fmt.Sprintf("zone file line %d: unterminated quoted string", lineNo))
+			default:
+				token.WriteRune(c)
+			}
+			continue
+		}
+
+		switch {
+		case c == ';':
+			comment = true
+		case c == '"':
+			inToken, quoted, inQuote = true, true, true
+			atLineStart = false
+		case c == '(':
+			flushToken()
+			depth++
+			atLineStart = false
+		case c == ')':
+			if depth == 0 {
+				return nil, errors.New(errors.InvalidInput, fmt.Sprintf("zone file line %d: unbalanced parenthesis", lineNo))
+			}
+			flushToken()
+			depth--
+		case c == '\n':
+			lineNo++
+			if depth == 0 {
+				flushLine()
+			} else {
+				flushToken()
+			}
+		case unicode.IsSpace(c):
+			if atLineStart && len(current.tokens) == 0 && !inToken {
+				current.blankOwner = true
+			}
+			flushToken()
+			atLineStart = false
+		default:
+			token.WriteRune(c)
+			inToken = true
+			atLineStart = false
+		}
+	}
+
+	if inQuote {
+		return nil, errors.New(errors.InvalidInput, fmt.Sprintf("zone file line %d: unterminated quoted string", lineNo))
+	}
+	if depth != 0 {
+		return nil, errors.New(errors.InvalidInput, fmt.Sprintf("zone file line %d: unbalanced parenthesis", lineNo))
+	}
+	flushLine()
+	return lines, nil
+}
+
+// formatZoneFile renders a zone and its enabled records as an RFC 1035 master file.
+func formatZoneFile(zone *domain.DNSZone, records []*domain.DNSRecord, serial uint32) string {
+	apex := absoluteName(zone.Name)
+	ttl := zone.DefaultTTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "$ORIGIN %s\n$TTL %d\n", apex, ttl)
+	fmt.Fprintf(&sb, "@\t%d\tIN\tSOA\tns1.%s hostmaster.%s %d %d %d %d %d\n",
+		zoneFileSOATTL, apex, apex, serial, zoneFileRefresh, zoneFileRetry, zoneFileExpire, zoneFileMinTTL)
+	fmt.Fprintf(&sb, "@\t%d\tIN\tNS\tns1.%s\n", zoneFileSOATTL, apex)
+	fmt.Fprintf(&sb, "@\t%d\tIN\tNS\tns2.%s\n", zoneFileSOATTL, apex)
+
+	sorted := make([]*domain.DNSRecord, 0, len(records))
+	for _, r := range records {
+		if !r.Disabled {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name == "@" || (sorted[j].Name != "@" && sorted[i].Name < sorted[j].Name)
+		}
+		return sorted[i].Type < sorted[j].Type
+	})
+
+	for _, r := range sorted {
+		fmt.Fprintf(&sb, "%s\t%d\tIN\t%s\t%s\n", r.Name, r.TTL, r.Type, zoneFileRData(r))
+	}
+	return sb.String()
+}
+
+// zoneFileRData renders a record's data in master file syntax.
+func zoneFileRData(r *domain.DNSRecord) string {
+	switch r.Type {
+	case domain.RecordTypeCNAME:
+		return absoluteName(r.Content)
+	case domain.RecordTypeMX:
+		fields := strings.Fields(r.Content)
+		if len(fields) == 2 {
+			return fields[0] + " " + absoluteName(fields[1])
+		}
+		return fmt.Sprintf("%d %s", priorityOrZero(r.Priority), absoluteName(r.Content))
+	case domain.RecordTypeSRV:
+		fields := strings.Fields(r.Content)
+		if len(fields) == 3 {
+			return fmt.Sprintf("%d %s %s %s", priorityOrZero(r.Priority), fields[0], fields[1], absoluteName(fields[2]))
+		}
+		if len(fields) == 4 {
+			return strings.Join(fields[:3], " ") + " " + absoluteName(fields[3])
+		}
+		return r.Content
+	case domain.RecordTypeTXT:
+		return quoteTXT(r.Content)
+	default:
+		return r.Content
+	}
+}
+
+// quoteTXT splits TXT content into escaped character-strings of at most 255 bytes.
+func quoteTXT(content string) string {
+	var parts []string
+	for {
+		chunk := content
+		if len(chunk) > maxTXTString {
+			chunk = chunk[:maxTXTString]
+		}
+		escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(chunk)
+		parts = append(parts, `"`+escaped+`"`)
+		content = content[len(chunk):]
+		if content == "" {
+			return strings.Join(parts, " ")
+		}
+	}
+}
+
+// expandName makes a master file name absolute: "@" is the origin and names
+// without a trailing dot are relative to it.
+func expandName(name, origin string) string {
+	switch {
+	case name == "@":
+		return origin
+	case strings.HasSuffix(name, "."):
+		return strings.ToLower(name)
+	default:
+		return strings.ToLower(name) + "." + origin
+	}
+}
+
+// relativeRecordName converts an absolute owner name into the zone-relative form records are stored with.
+func relativeRecordName(owner, apex string) (string, bool) {
+	if owner == apex {
+		return "@", true
+	}
+	if strings.HasSuffix(owner, "."+apex) {
+		return strings.TrimSuffix(owner, "."+apex), true
+	}
+	return "", false
+}
+
+// parseZoneTTL parses a TTL in seconds or BIND's unit form, e.g. "1h30m".
+func parseZoneTTL(s string) (int, bool) {
+	if s == "" || !unicode.IsDigit(rune(s[0])) {
+		return 0, false
+	}
+	if v, err := strconv.Atoi(s); err == nil {
+		return v, true
+	}
+
+	units := map[byte]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+	total, n, digits := 0, 0, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= '0' && c <= '9' {
+			n, digits = n*10+int(c-'0'), true
+			continue
+		}
+		mult, ok := units[byte(unicode.ToLower(rune(c)))]
+		if !ok || !digits {
+			return 0, false
+		}
+		total += n * mult
+		n, digits = 0, false
+	}
+	if digits {
+		return 0, false
+	}
+	return total, true
+}
+
+func isZoneClass(s string) bool {
+	switch strings.ToUpper(s) {
+	case "IN", "CH", "HS", "CS":
+		return true
+	}
+	return false
+}
+
+func isDigits(rs []rune) bool {
+	for _, r := range rs {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func absoluteName(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
+
+func priorityOrZero(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseZoneFile(t *testing.T) {
+	t.Run("records and directives", func(t *testing.T) {
+		zoneFile := `$ORIGIN example.com.
+$TTL 1h
+@	IN SOA ns1.example.com. admin.example.com. (
+		2024010101 ; serial
+		10800 3600 604800 3600 )
+@	IN NS ns1.example.com.
+@	IN A 10.0.0.1
+www	300 IN A 10.0.0.2
+	IN AAAA fd00::2
+mail.example.com. IN MX 10 mx.example.com.
+_sip._tcp IN SRV 5 10 5060 sip.example.com.
+txt IN TXT "v=spf1 -all" "second; part"
+alias IN CNAME www
+`
+		changes, err := parseZoneFile(zoneFile, "example.com", 300)
+		require.NoError(t, err)
+		require.Len(t, changes, 7)
+
+		assert.Equal(t, domain.DNSRecordChange{Action: domain.DNSChangeUpsert, Name: "@", Type: domain.RecordTypeA, Content: "10.0.0.1", TTL: 3600}, changes[0])
+		assert.Equal(t, "www", changes[1].Name)
+		assert.Equal(t, 300, changes[1].TTL)
+		assert.Equal(t, "www", changes[2].Name)
+		assert.Equal(t, domain.RecordTypeAAAA, changes[2].Type)
+
+		assert.Equal(t, "mail", changes[3].Name)
+		assert.Equal(t, "mx.example.com.", changes[3].Content)
+		require.NotNil(t, changes[3].Priority)
+		assert.Equal(t, 10, *changes[3].Priority)
+
+		assert.Equal(t, "_sip._tcp", changes[4].Name)
+		assert.Equal(t, "10 5060 sip.example.com.", changes[4].Content)
+		assert.Equal(t, 5, *changes[4].Priority)
+
+		assert.Equal(t, "v=spf1 -allsecond; part", changes[5].Content)
+		assert.Equal(t, "www.example.com.", changes[6].Content)
+	})
+
+	t.Run("name outside zone", func(t *testing.T) {
+		_, err := parseZoneFile("www.other.com. IN A 10.0.0.1\n", "example.com", 300)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 1")
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := parseZoneFile("@ IN\n@ IN PTR host.example.com.\n", "example.com", 300)
+		require.Error(t, err)
+	})
+
+	t.Run("unbalanced parentheses", func(t *testing.T) {
+		_, err := parseZoneFile("@ IN A ( 10.0.0.1\n", "example.com", 300)
+		require.Error(t, err)
+	})
+}
+
+func TestFormatZoneFileRoundTrip(t *testing.T) {
+	zone := &domain.DNSZone{ID: uuid.New(), Name: "example.com", DefaultTTL: 300}
+	priority := 10
+	records := []*domain.DNSRecord{
+		{Name: "www", Type: domain.RecordTypeA, Content: "10.0.0.2", TTL: 300},
+		{Name: "@", Type: domain.RecordTypeA, Content: "10.0.0.1", TTL: 600},
+		{Name: "@", Type: domain.RecordTypeMX, Content: "mx.example.com.", TTL: 300, Priority: &priority},
+		{Name: "txt", Type: domain.RecordTypeTXT, Content: `say "hi"`, TTL: 300},
+	}
+
+	out := formatZoneFile(zone, records, 42)
+	assert.True(t, strings.HasPrefix(out, "$ORIGIN example.com.\n"))
+	assert.Contains(t, out, "SOA")
+	assert.Contains(t, out, " 42 ")
+
+	changes, err := parseZoneFile(out, "example.com", 300)
+	require.NoError(t, err)
+	require.Len(t, changes, len(records))
+
+	got := make(map[string]domain.DNSRecordChange)
+	for _, c := range changes {
+		got[c.Name+" "+string(c.Type)] = c
+	}
+	assert.Equal(t, 600, got["@ A"].TTL)
+	assert.Equal(t, "10.0.0.2", got["www A"].Content)
+	assert.Equal(t, "mx.example.com.", got["@ MX"].Content)
+	assert.Equal(t, 10, *got["@ MX"].Priority)
+	assert.Equal(t, `say "hi"`, got["txt TXT"].Content)
+}
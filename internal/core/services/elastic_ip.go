@@ -18,14 +18,20 @@ import (
 type elasticIPService struct {
 	repo         ports.ElasticIPRepository
 	instanceRepo ports.InstanceRepository
+	igwRepo      ports.InternetGatewayRepository
+	network      ports.NetworkBackend
 	auditSvc     ports.AuditService
 	logger       *slog.Logger
 }
 
 // ElasticIPServiceParams holds the dependencies for creating an ElasticIPService.
+// IGWRepo and Network are optional; without them associations are only recorded and no
+// translation is installed on the VPC's internet gateway.
 type ElasticIPServiceParams struct {
 	Repo         ports.ElasticIPRepository
 	InstanceRepo ports.InstanceRepository
+	IGWRepo      ports.InternetGatewayRepository
+	Network      ports.NetworkBackend
 	AuditSvc     ports.AuditService
 	Logger       *slog.Logger
 }
@@ -35,6 +41,8 @@ func NewElasticIPService(params ElasticIPServiceParams) ports.ElasticIPService {
 	return &elasticIPService{
 		repo:         params.Repo,
 		instanceRepo: params.InstanceRepo,
+		igwRepo:      params.IGWRepo,
+		network:      params.Network,
 		auditSvc:     params.AuditSvc,
 		logger:       params.Logger,
 	}
@@ -129,13 +137,30 @@ func (s *elasticIPService) AssociateIP(ctx context.Context, id uuid.UUID, instan
 		return nil, errors.New(errors.Conflict, "elastic ip is already associated with another instance")
 	}
 
-	// 3. Update EIP mapping
+	// 3. Translate the address on the VPC's internet gateway
+	igw, err := s.internetGateway(ctx, inst.VpcID)
+	if err != nil {
+		return nil, err
+	}
+	privateIP := stripPrefixLen(inst.PrivateIP)
+	if igw != nil {
+		if err := s.network.MapElasticIP(ctx, igw.NamespaceName(), eip.PublicIP, privateIP); err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to map elastic ip on internet gateway", err)
+		}
+	}
+
+	// 4. Update EIP mapping
 	eip.InstanceID = &instanceID
 	eip.VpcID = inst.VpcID
 	eip.Status = domain.EIPStatusAssociated
 	eip.UpdatedAt = time.Now()
 
 	if err := s.repo.Update(ctx, eip); err != nil {
+		if igw != nil {
+			if uErr := s.network.UnmapElasticIP(ctx, igw.NamespaceName(), eip.PublicIP, privateIP); uErr != nil {
+				s.logger.Error("failed to roll back elastic ip mapping", "eip_id", id, "error", uErr)
+			}
+		}
 		return nil, err
 	}
 
@@ -158,8 +183,12 @@ func (s *elasticIPService) DisassociateIP(ctx context.Context, id uuid.UUID) (*d
 	if eip.Status != domain.EIPStatusAssociated {
 		return nil, errors.New(errors.InvalidInput, "elastic ip is not associated")
 	}
+	if eip.InstanceID == nil {
+		return nil, errors.New(errors.Conflict, "elastic ip is held by a nat gateway; delete the gateway instead")
+	}
 
 	oldInstanceID := eip.InstanceID
+	s.unmapElasticIP(ctx, eip)
 
 	eip.InstanceID = nil
 	eip.VpcID = nil
@@ -180,6 +209,39 @@ func (s *elasticIPService) DisassociateIP(ctx context.Context, id uuid.UUID) (*d
 	return eip, nil
 }
 
+// internetGateway returns the internet gateway attached to a VPC, or nil when the VPC has
+// none or the service has no network backend to program.
+func (s *elasticIPService) internetGateway(ctx context.Context, vpcID *uuid.UUID) (*domain.InternetGateway, error) {
+	if s.igwRepo == nil || s.network == nil || vpcID == nil {
+		return nil, nil
+	}
+	igw, err := s.igwRepo.GetByVPC(ctx, *vpcID)
+	if errors.Is(err, errors.NotFound) {
+		return nil, nil
+	}
+	return igw, err
+}
+
+// unmapElasticIP removes an instance association's translation from the internet gateway.
+// Failures are logged rather than returned so a broken data plane never pins an address.
+func (s *elasticIPService) unmapElasticIP(ctx context.Context, eip *domain.ElasticIP) {
+	igw, err := s.internetGateway(ctx, eip.VpcID)
+	if err != nil || igw == nil {
+		if err != nil {
+			s.logger.Warn("failed to look up internet gateway for elastic ip", "eip_id", eip.ID, "error", err)
+		}
+		return
+	}
+	inst, err := s.instanceRepo.GetByID(ctx, *eip.InstanceID)
+	if err != nil {
+		s.logger.Warn("instance not found while unmapping elastic ip", "eip_id", eip.ID, "error", err)
+		return
+	}
+	if err := s.network.UnmapElasticIP(ctx, igw.NamespaceName(), eip.PublicIP, stripPrefixLen(inst.PrivateIP)); err != nil {
+		s.logger.Warn("failed to unmap elastic ip from internet gateway", "eip_id", eip.ID, "error", err)
+	}
+}
+
 func (s *elasticIPService) ListElasticIPs(ctx context.Context) ([]*domain.ElasticIP, error) {
 	return s.repo.List(ctx)
 }
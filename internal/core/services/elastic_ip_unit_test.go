@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	appcontext "github.com/poyrazk/thecloud/internal/core/context"
 	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/poyrazk/thecloud/internal/core/services"
 	"github.com/poyrazk/thecloud/internal/errors"
 	"github.com/stretchr/testify/assert"
@@ -64,3 +65,81 @@ func TestElasticIPService_Unit(t *testing.T) {
 		assert.Equal(t, domain.EIPStatusAssociated, res.Status)
 	})
 }
+
+func TestElasticIPServiceInternetGatewayMapping(t *testing.T) {
+	ctx := appcontext.WithTenantID(appcontext.WithUserID(context.Background(), uuid.New()), uuid.New())
+	vpcID := uuid.New()
+	igw := &domain.InternetGateway{ID: uuid.New(), VPCID: &vpcID, Status: domain.IGWStatusAttached}
+
+	setup := func() (ports.ElasticIPService, *MockElasticIPRepo, *MockInstanceRepo, *MockInternetGatewayRepo, *MockNetworkBackend) {
+		repo, instRepo, igwRepo, network := new(MockElasticIPRepo), new(MockInstanceRepo), new(MockInternetGatewayRepo), new(MockNetworkBackend)
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		svc := services.NewElasticIPService(services.ElasticIPServiceParams{
+			Repo: repo, InstanceRepo: instRepo, IGWRepo: igwRepo, Network: network, AuditSvc: audit, Logger: slog.Default(),
+		})
+		return svc, repo, instRepo, igwRepo, network
+	}
+
+	t.Run("AssociateIP maps the address on the VPC's internet gateway", func(t *testing.T) {
+		svc, repo, instRepo, igwRepo, network := setup()
+		eip := &domain.ElasticIP{ID: uuid.New(), PublicIP: "100.64.0.7", Status: domain.EIPStatusAllocated}
+		inst := &domain.Instance{ID: uuid.New(), VpcID: &vpcID, PrivateIP: "10.0.1.5/24", Status: domain.StatusRunning}
+		repo.On("GetByID", mock.Anything, eip.ID).Return(eip, nil)
+		repo.On("GetByInstanceID", mock.Anything, inst.ID).Return(nil, errors.New(errors.NotFound, "not found"))
+		repo.On("Update", mock.Anything, eip).Return(nil)
+		instRepo.On("GetByID", mock.Anything, inst.ID).Return(inst, nil)
+		igwRepo.On("GetByVPC", mock.Anything, vpcID).Return(igw, nil)
+		network.On("MapElasticIP", mock.Anything, igw.NamespaceName(), "100.64.0.7", "10.0.1.5").Return(nil).Once()
+
+		_, err := svc.AssociateIP(ctx, eip.ID, inst.ID)
+		assert.NoError(t, err)
+		network.AssertExpectations(t)
+	})
+
+	t.Run("AssociateIP fails without recording when the mapping fails", func(t *testing.T) {
+		svc, repo, instRepo, igwRepo, network := setup()
+		eip := &domain.ElasticIP{ID: uuid.New(), PublicIP: "100.64.0.7", Status: domain.EIPStatusAllocated}
+		inst := &domain.Instance{ID: uuid.New(), VpcID: &vpcID, PrivateIP: "10.0.1.5", Status: domain.StatusRunning}
+		repo.On("GetByID", mock.Anything, eip.ID).Return(eip, nil)
+		repo.On("GetByInstanceID", mock.Anything, inst.ID).Return(nil, errors.New(errors.NotFound, "not found"))
+		instRepo.On("GetByID", mock.Anything, inst.ID).Return(inst, nil)
+		igwRepo.On("GetByVPC", mock.Anything, vpcID).Return(igw, nil)
+		network.On("MapElasticIP", mock.Anything, igw.NamespaceName(), "100.64.0.7", "10.0.1.5").Return(errors.New(errors.Internal, "boom"))
+
+		_, err := svc.AssociateIP(ctx, eip.ID, inst.ID)
+		assert.True(t, errors.Is(err, errors.Internal))
+		repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AssociateIP without an internet gateway only records the association", func(t *testing.T) {
+		svc, repo, instRepo, igwRepo, network := setup()
+		eip := &domain.ElasticIP{ID: uuid.New(), PublicIP: "100.64.0.7", Status: domain.EIPStatusAllocated}
+		inst := &domain.Instance{ID: uuid.New(), VpcID: &vpcID, PrivateIP: "10.0.1.5", Status: domain.StatusRunning}
+		repo.On("GetByID", mock.Anything, eip.ID).Return(eip, nil)
+		repo.On("GetByInstanceID", mock.Anything, inst.ID).Return(nil, errors.New(errors.NotFound, "not found"))
+		repo.On("Update", mock.Anything, eip).Return(nil)
+		instRepo.On("GetByID", mock.Anything, inst.ID).Return(inst, nil)
+		igwRepo.On("GetByVPC", mock.Anything, vpcID).Return(nil, errors.New(errors.NotFound, "internet gateway not found"))
+
+		_, err := svc.AssociateIP(ctx, eip.ID, inst.ID)
+		assert.NoError(t, err)
+		network.AssertNotCalled(t, "MapElasticIP", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DisassociateIP unmaps the address", func(t *testing.T) {
+		svc, repo, instRepo, igwRepo, network := setup()
+		instID := uuid.New()
+		eip := &domain.ElasticIP{ID: uuid.New(), PublicIP: "100.64.0.7", InstanceID: &instID, VpcID: &vpcID, Status: domain.EIPStatusAssociated}
+		repo.On("GetByID", mock.Anything, eip.ID).Return(eip, nil)
+		repo.On("Update", mock.Anything, eip).Return(nil)
+		instRepo.On("GetByID", mock.Anything, instID).Return(&domain.Instance{ID: instID, PrivateIP: "10.0.1.5"}, nil)
+		igwRepo.On("GetByVPC", mock.Anything, vpcID).Return(igw, nil)
+		network.On("UnmapElasticIP", mock.Anything, igw.NamespaceName(), "100.64.0.7", "10.0.1.5").Return(nil).Once()
+
+		res, err := svc.DisassociateIP(ctx, eip.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, res.InstanceID)
+		network.AssertExpectations(t)
+	})
+}
@@ -2,53 +2,105 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/poyrazk/thecloud/internal/errors"
 )
 
+// ciphertextMagic starts every ciphertext sealed with a versioned data key. It is
+// followed by the big-endian key version; together they form an 8-byte header that
+// is authenticated as additional data.
+var ciphertextMagic = []byte("TCE1")
+
+const (
+	ciphertextHeaderSize = 8
+	// legacyKeyVersion is the version of data keys created before keys were versioned.
+	// Ciphertexts sealed with them carry no header.
+	legacyKeyVersion = 1
+	rewrapBatchSize  = 100
+	// gcmOverhead is what encryptGCM adds to the data: a 12-byte nonce and a 16-byte tag.
+	gcmOverhead = 12 + 16
+)
+
 // EncryptionService implements ports.EncryptionService
 type EncryptionService struct {
-	repo      ports.EncryptionRepository
-	masterKey []byte // 32-bytes
+	repo        ports.EncryptionRepository
+	masterKey   []byte // 32-bytes
+	masterKeyID string
+	// masterKeys holds the active and every retired master key by ID, so data
+	// keys wrapped before a master key rotation can still be unwrapped.
+	masterKeys map[string][]byte
 }
 
 // NewEncryptionService constructs an EncryptionService from the repository and master key.
-func NewEncryptionService(repo ports.EncryptionRepository, masterKeyHex string) (*EncryptionService, error) {
+// Retired master keys are only used to unwrap data keys until RewrapKeys moves them
+// to the active master key.
+func NewEncryptionService(repo ports.EncryptionRepository, masterKeyHex string, retiredMasterKeys ...string) (*EncryptionService, error) {
+	key := parseMasterKey(masterKeyHex)
+	s := &EncryptionService{
+		repo:        repo,
+		masterKey:   key,
+		masterKeyID: masterKeyID(key),
+		masterKeys:  map[string][]byte{masterKeyID(key): key},
+	}
+	for _, retired := range retiredMasterKeys {
+		if retired = strings.TrimSpace(retired); retired == "" {
+			continue
+		}
+		k := parseMasterKey(retired)
+		s.masterKeys[masterKeyID(k)] = k
+	}
+	return s, nil
+}
+
+// parseMasterKey accepts a hex-encoded 32-byte key, a raw 32-byte string or any
+// passphrase, which is stretched to 32 bytes with SHA-256.
+func parseMasterKey(masterKeyHex string) []byte {
 	key, err := hex.DecodeString(masterKeyHex)
 	if err == nil && len(key) == 32 {
-		return &EncryptionService{
-			repo:      repo,
-			masterKey: key,
-		}, nil
+		return key
 	}
 
 	// Try as raw string if it's explicitly 32 bytes
 	if len(masterKeyHex) == 32 {
-		return &EncryptionService{
-			repo:      repo,
-			masterKey: []byte(masterKeyHex),
-		}, nil
+		return []byte(masterKeyHex)
 	}
 
 	// Fallback: Use SHA256 to derive a 32-byte key from the passphrase/input
 	// This ensures we always have a valid AES-256 key regardless of input length
 	hash := sha256.Sum256([]byte(masterKeyHex))
-	return &EncryptionService{
-		repo:      repo,
-		masterKey: hash[:],
-	}, nil
+	return hash[:]
+}
+
+// masterKeyID is a fingerprint of a master key that is safe to store next to the data keys it wraps.
+func masterKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
 }
 
+// CreateKey generates a new data key version for the bucket, wrapped with the active master key.
 func (s *EncryptionService) CreateKey(ctx context.Context, bucket string) (string, error) {
+	version := 1
+	latest, err := s.repo.GetKey(ctx, bucket)
+	switch {
+	case err == nil:
+		version = latest.Version + 1
+	case !errors.Is(err, errors.NotFound):
+		return "", err
+	}
+
 	// Generate new random data key (DEK)
 	dek := make([]byte, 32)
 	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
@@ -56,7 +108,7 @@ func (s *EncryptionService) CreateKey(ctx context.Context, bucket string) (strin
 	}
 
 	// Encrypt DEK with Master Key (KEK) using AES-GCM
-	encryptedDEK, err := s.encryptData(s.masterKey, dek)
+	encryptedDEK, err := encryptGCM(s.masterKey, dek, nil)
 	if err != nil {
 		return "", err
 	}
@@ -65,8 +117,10 @@ func (s *EncryptionService) CreateKey(ctx context.Context, bucket string) (strin
 	err = s.repo.SaveKey(ctx, ports.EncryptionKey{
 		ID:           keyID,
 		BucketName:   bucket,
+		Version:      version,
 		EncryptedKey: encryptedDEK,
 		Algorithm:    "AES-256-GCM",
+		MasterKeyID:  s.masterKeyID,
 	})
 	if err != nil {
 		return "", err
@@ -74,67 +128,149 @@ func (s *EncryptionService) CreateKey(ctx context.Context, bucket string) (strin
 	return keyID, nil
 }
 
-// RotateKey generates a new DEK for the bucket.
-// It relies on the repository to handle versioning (INSERT without ON CONFLICT).
+// RotateKey generates a new DEK version for the bucket. New data is sealed with it,
+// while data sealed with earlier versions keeps decrypting with those.
 func (s *EncryptionService) RotateKey(ctx context.Context, bucket string) (string, error) {
 	return s.CreateKey(ctx, bucket)
 }
 
-// Encrypt encrypts data using the bucket's active key
+// RewrapKeys re-encrypts every data key still wrapped by a retired master key with the
+// active one. Once it has run, retired master keys can be removed from the configuration.
+func (s *EncryptionService) RewrapKeys(ctx context.Context) (int, error) {
+	rewrapped := 0
+	for {
+		keys, err := s.repo.ListKeysNotWrappedBy(ctx, s.masterKeyID, rewrapBatchSize)
+		if err != nil {
+			return rewrapped, err
+		}
+		if len(keys) == 0 {
+			return rewrapped, nil
+		}
+		for _, k := range keys {
+			dek, err := s.unwrapKey(&k)
+			if err != nil {
+				// Stop rather than loop over a key no configured master key can open.
+				return rewrapped, err
+			}
+			wrapped, err := encryptGCM(s.masterKey, dek, nil)
+			if err != nil {
+				return rewrapped, err
+			}
+			if err := s.repo.UpdateWrappedKey(ctx, k.ID, wrapped, s.masterKeyID); err != nil {
+				return rewrapped, err
+			}
+			rewrapped++
+		}
+	}
+}
+
+// Encrypt encrypts data using the bucket's latest key, recording its version in the header.
 func (s *EncryptionService) Encrypt(ctx context.Context, bucket string, data []byte) ([]byte, error) {
-	// For encryption, always use the latest key (versionID="")
-	dek, err := s.getAndDecryptDEK(ctx, bucket, "")
+	keyRecord, err := s.repo.GetKey(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := s.unwrapKey(keyRecord)
 	if err != nil {
 		return nil, err
 	}
-	return s.encryptData(dek, data)
-}
 
-// Decrypt decrypts data using the bucket's active key
-func (s *EncryptionService) Decrypt(ctx context.Context, bucket string, encryptedData []byte) ([]byte, error) {
-	// For decryption, ideally we need the specific key version used for encryption.
-	// Since we don't have metadata here, we try the latest active key first.
-	// If that fails, in a real scenario we'd check object metadata.
-	// Implementing fallback logic as requested: if latest fails, search/try others?
-	// For now, let's keep it simple as per signature: try latest.
-	// NOTE: To fully support rotation, Decrypt needs the keyID from the object metadata.
-	dek, err := s.getAndDecryptDEK(ctx, bucket, "")
+	header := make([]byte, ciphertextHeaderSize)
+	copy(header, ciphertextMagic)
+	binary.BigEndian.PutUint32(header[len(ciphertextMagic):], uint32(keyRecord.Version)) // #nosec G115
+	sealed, err := encryptGCM(dek, data, header)
 	if err != nil {
 		return nil, err
 	}
-	plaintext, err := s.decryptData(dek, encryptedData)
+	return append(header, sealed...), nil
+}
+
+// Decrypt decrypts data with the key version named in its header. Data sealed before
+// keys were versioned has no header and was sealed with the bucket's first key.
+func (s *EncryptionService) Decrypt(ctx context.Context, bucket string, encryptedData []byte) ([]byte, error) {
+	if version, ok := parseCiphertextHeader(encryptedData); ok {
+		dek, err := s.keyVersion(ctx, bucket, version)
+		if err == nil {
+			header := encryptedData[:ciphertextHeaderSize]
+			if plaintext, err := decryptGCM(dek, encryptedData[ciphertextHeaderSize:], header); err == nil {
+				return plaintext, nil
+			}
+		} else if !errors.Is(err, errors.NotFound) {
+			return nil, err
+		}
+		// A legacy nonce can start with the magic bytes by chance; fall through.
+	}
+
+	dek, err := s.keyVersion(ctx, bucket, legacyKeyVersion)
 	if err != nil {
-		// Fallback logic could go here if we had a way to list all keys.
 		return nil, err
 	}
-	return plaintext, nil
+	return decryptGCM(dek, encryptedData, nil)
+}
+
+// KeyVersion reports the data key version recorded in a ciphertext header.
+func (s *EncryptionService) KeyVersion(encryptedData []byte) int {
+	if version, ok := parseCiphertextHeader(encryptedData); ok {
+		return version
+	}
+	return legacyKeyVersion
 }
 
-// getAndDecryptDEK fetches the encrypted DEK from DB and decrypts it with Master Key
-func (s *EncryptionService) getAndDecryptDEK(ctx context.Context, bucket, _ string) ([]byte, error) {
-	// TODO: Update repo.GetKey to support optional versionID filters if needed.
-	// Currently repo.GetKey likely returns the latest.
-	// If versionID is provided, we should fetch that specific key.
-	// But without changing repo interface in 'ports' (which I can't see), I assume GetKey returns the latest "active" key.
-	// If I wanted a specific key I'd need GetKeyByID(ctx, keyID).
+// LatestKeyVersion returns the version of the bucket's newest data key.
+func (s *EncryptionService) LatestKeyVersion(ctx context.Context, bucket string) (int, error) {
 	keyRecord, err := s.repo.GetKey(ctx, bucket)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
+	return keyRecord.Version, nil
+}
 
-	return s.decryptData(s.masterKey, keyRecord.EncryptedKey)
+func parseCiphertextHeader(data []byte) (int, bool) {
+	if len(data) < ciphertextHeaderSize || !bytes.HasPrefix(data, ciphertextMagic) {
+		return 0, false
+	}
+	return int(binary.BigEndian.Uint32(data[len(ciphertextMagic):ciphertextHeaderSize])), true
 }
 
-// encryptData performs AES-GCM encryption
-func (s *EncryptionService) encryptData(key, data []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
+// keyVersion fetches a data key version of the bucket and unwraps it.
+func (s *EncryptionService) keyVersion(ctx context.Context, bucket string, version int) ([]byte, error) {
+	keyRecord, err := s.repo.GetKeyVersion(ctx, bucket, version)
 	if err != nil {
-		return nil, errors.Wrap(errors.Internal, "failed to create cipher", err)
+		return nil, err
+	}
+	return s.unwrapKey(keyRecord)
+}
+
+// unwrapKey decrypts a data key with the master key that wrapped it. Keys created
+// before master keys were tracked are tried against every configured master key.
+func (s *EncryptionService) unwrapKey(k *ports.EncryptionKey) ([]byte, error) {
+	if k.MasterKeyID != "" {
+		master, ok := s.masterKeys[k.MasterKeyID]
+		if !ok {
+			return nil, errors.New(errors.Internal, fmt.Sprintf("master key %s is not configured", k.MasterKeyID))
+		}
+		return decryptGCM(master, k.EncryptedKey, nil)
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	if dek, err := decryptGCM(s.masterKey, k.EncryptedKey, nil); err == nil {
+		return dek, nil
+	}
+	for id, master := range s.masterKeys {
+		if id == s.masterKeyID {
+			continue
+		}
+		if dek, err := decryptGCM(master, k.EncryptedKey, nil); err == nil {
+			return dek, nil
+		}
+	}
+	return nil, errors.New(errors.Internal, "no configured master key can unwrap the data key")
+}
+
+// encryptGCM performs AES-GCM encryption, prefixing the ciphertext with its nonce.
+func encryptGCM(key, data, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
 	if err != nil {
-		return nil, errors.Wrap(errors.Internal, "failed to create GCM", err)
+		return nil, err
 	}
 
 	nonce := make([]byte, gcm.NonceSize())
@@ -142,19 +278,14 @@ func (s *EncryptionService) encryptData(key, data []byte) ([]byte, error) {
 		return nil, errors.Wrap(errors.Internal, "failed to generate nonce", err)
 	}
 
-	return gcm.Seal(nonce, nonce, data, nil), nil
+	return gcm.Seal(nonce, nonce, data, additionalData), nil
 }
 
-// decryptData performs AES-GCM decryption
-func (s *EncryptionService) decryptData(key, ciphertext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, errors.Wrap(errors.Internal, "failed to create cipher", err)
-	}
-
-	gcm, err := cipher.NewGCM(block)
+// decryptGCM performs AES-GCM decryption of a ciphertext produced by encryptGCM.
+func decryptGCM(key, ciphertext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
 	if err != nil {
-		return nil, errors.Wrap(errors.Internal, "failed to create GCM", err)
+		return nil, err
 	}
 
 	nonceSize := gcm.NonceSize()
@@ -163,9 +294,22 @@ func (s *EncryptionService) decryptData(key, ciphertext []byte) ([]byte, error)
 	}
 
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, additionalData)
 	if err != nil {
 		return nil, errors.Wrap(errors.Internal, "decryption failed", err)
 	}
 	return plaintext, nil
 }
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to create cipher", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to create GCM", err)
+	}
+	return gcm, nil
+}
@@ -63,6 +63,19 @@ func TestEncryptionService(t *testing.T) {
 		assert.Equal(t, plaintext, decrypted)
 	})
 
+	t.Run("DecryptAfterRotation", func(t *testing.T) {
+		_, _ = svc.CreateKey(ctx, bucket)
+		ciphertext, err := svc.Encrypt(ctx, bucket, []byte("sealed before rotation"))
+		require.NoError(t, err)
+
+		_, err = svc.RotateKey(ctx, bucket)
+		require.NoError(t, err)
+
+		decrypted, err := svc.Decrypt(ctx, bucket, ciphertext)
+		assert.NoError(t, err)
+		assert.Equal(t, "sealed before rotation", string(decrypted))
+	})
+
 	t.Run("DecryptInvalidData", func(t *testing.T) {
 		_, _ = svc.CreateKey(ctx, bucket)
 		_, err := svc.Decrypt(ctx, bucket, []byte("short"))
@@ -2,14 +2,18 @@ package services_test
 
 import (
 	"context"
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/rand"
 	"encoding/hex"
 	"testing"
 
 	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type MockEncryptionRepo struct {
@@ -28,25 +32,102 @@ func (m *MockEncryptionRepo) GetKey(ctx context.Context, bucketName string) (*po
 	return args.Get(0).(*ports.EncryptionKey), args.Error(1)
 }
 
+func (m *MockEncryptionRepo) GetKeyVersion(ctx context.Context, bucketName string, version int) (*ports.EncryptionKey, error) {
+	args := m.Called(ctx, bucketName, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ports.EncryptionKey), args.Error(1)
+}
+
+func (m *MockEncryptionRepo) ListKeysNotWrappedBy(ctx context.Context, masterKeyID string, limit int) ([]ports.EncryptionKey, error) {
+	args := m.Called(ctx, masterKeyID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ports.EncryptionKey), args.Error(1)
+}
+
+func (m *MockEncryptionRepo) UpdateWrappedKey(ctx context.Context, id string, encryptedKey []byte, masterKeyID string) error {
+	return m.Called(ctx, id, encryptedKey, masterKeyID).Error(0)
+}
+
+// memEncryptionRepo keeps key versions in memory so rotation scenarios can be
+// exercised end to end without a database.
+type memEncryptionRepo struct {
+	keys []ports.EncryptionKey
+}
+
+func (r *memEncryptionRepo) SaveKey(_ context.Context, key ports.EncryptionKey) error {
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *memEncryptionRepo) GetKey(_ context.Context, bucketName string) (*ports.EncryptionKey, error) {
+	var latest *ports.EncryptionKey
+	for i := range r.keys {
+		if r.keys[i].BucketName == bucketName && (latest == nil || r.keys[i].Version > latest.Version) {
+			latest = &r.keys[i]
+		}
+	}
+	if latest == nil {
+		return nil, errors.New(errors.NotFound, "encryption key not found")
+	}
+	k := *latest
+	return &k, nil
+}
+
+func (r *memEncryptionRepo) GetKeyVersion(_ context.Context, bucketName string, version int) (*ports.EncryptionKey, error) {
+	for _, k := range r.keys {
+		if k.BucketName == bucketName && k.Version == version {
+			return &k, nil
+		}
+	}
+	return nil, errors.New(errors.NotFound, "encryption key not found")
+}
+
+func (r *memEncryptionRepo) ListKeysNotWrappedBy(_ context.Context, masterKeyID string, limit int) ([]ports.EncryptionKey, error) {
+	var out []ports.EncryptionKey
+	for _, k := range r.keys {
+		if k.MasterKeyID != masterKeyID && len(out) < limit {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (r *memEncryptionRepo) UpdateWrappedKey(_ context.Context, id string, encryptedKey []byte, masterKeyID string) error {
+	for i := range r.keys {
+		if r.keys[i].ID == id {
+			r.keys[i].EncryptedKey = encryptedKey
+			r.keys[i].MasterKeyID = masterKeyID
+			return nil
+		}
+	}
+	return errors.New(errors.NotFound, "encryption key not found")
+}
+
+func randomHexKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return hex.EncodeToString(key)
+}
+
 func TestEncryptionService_Unit(t *testing.T) {
 	mockRepo := new(MockEncryptionRepo)
-	
-	// Create a valid 32-byte hex master key
-	masterKey := make([]byte, 32)
-	_, err := rand.Read(masterKey)
-	assert.NoError(t, err)
-	
-	masterKeyHex := hex.EncodeToString(masterKey)
 
-	svc, err := services.NewEncryptionService(mockRepo, masterKeyHex)
+	svc, err := services.NewEncryptionService(mockRepo, randomHexKey(t))
 	assert.NoError(t, err)
 
 	ctx := context.Background()
 	bucket := "my-bucket"
 
 	t.Run("CreateKey", func(t *testing.T) {
+		mockRepo.On("GetKey", mock.Anything, bucket).Return(nil, errors.New(errors.NotFound, "not found")).Once()
 		mockRepo.On("SaveKey", mock.Anything, mock.MatchedBy(func(k ports.EncryptionKey) bool {
-			return k.BucketName == bucket && len(k.EncryptedKey) > 0
+			return k.BucketName == bucket && len(k.EncryptedKey) > 0 && k.Version == 1 && k.MasterKeyID != ""
 		})).Return(nil).Once()
 
 		keyID, err := svc.CreateKey(ctx, bucket)
@@ -56,10 +137,9 @@ func TestEncryptionService_Unit(t *testing.T) {
 	})
 
 	t.Run("Encrypt_Decrypt", func(t *testing.T) {
-		// Helper: create an encrypted DEK payload using the service's logic
-		// Since encryptData is private, we can use CreateKey's logic:
-		// We mock SaveKey to capture the argument passed to it
+		// Capture the wrapped key CreateKey stores so it can be served back.
 		var savedKey ports.EncryptionKey
+		mockRepo.On("GetKey", mock.Anything, bucket).Return(nil, errors.New(errors.NotFound, "not found")).Once()
 		mockRepo.On("SaveKey", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
 			savedKey = args.Get(1).(ports.EncryptionKey)
 		}).Return(nil).Once()
@@ -67,14 +147,15 @@ func TestEncryptionService_Unit(t *testing.T) {
 		_, err := svc.CreateKey(ctx, bucket)
 		assert.NoError(t, err)
 
-		// Now mock GetKey to return this valid key
-		mockRepo.On("GetKey", mock.Anything, bucket).Return(&savedKey, nil)
+		mockRepo.On("GetKey", mock.Anything, bucket).Return(&savedKey, nil).Once()
+		mockRepo.On("GetKeyVersion", mock.Anything, bucket, 1).Return(&savedKey, nil).Once()
 
 		data := []byte("secret message")
 		encrypted, err := svc.Encrypt(ctx, bucket, data)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, encrypted)
 		assert.NotEqual(t, data, encrypted)
+		assert.Equal(t, 1, svc.KeyVersion(encrypted))
 
 		decrypted, err := svc.Decrypt(ctx, bucket, encrypted)
 		assert.NoError(t, err)
@@ -82,8 +163,9 @@ func TestEncryptionService_Unit(t *testing.T) {
 	})
 
 	t.Run("RotateKey", func(t *testing.T) {
+		mockRepo.On("GetKey", mock.Anything, bucket).Return(&ports.EncryptionKey{BucketName: bucket, Version: 4}, nil).Once()
 		mockRepo.On("SaveKey", mock.Anything, mock.MatchedBy(func(k ports.EncryptionKey) bool {
-			return k.BucketName == bucket
+			return k.BucketName == bucket && k.Version == 5
 		})).Return(nil).Once()
 
 		keyID, err := svc.RotateKey(ctx, bucket)
@@ -91,3 +173,93 @@ func TestEncryptionService_Unit(t *testing.T) {
 		assert.NotEmpty(t, keyID)
 	})
 }
+
+func TestEncryptionService_RotationKeepsOldDataReadable(t *testing.T) {
+	ctx := context.Background()
+	repo := &memEncryptionRepo{}
+	svc, err := services.NewEncryptionService(repo, randomHexKey(t))
+	require.NoError(t, err)
+
+	_, err = svc.CreateKey(ctx, "b")
+	require.NoError(t, err)
+	before, err := svc.Encrypt(ctx, "b", []byte("before rotation"))
+	require.NoError(t, err)
+
+	_, err = svc.RotateKey(ctx, "b")
+	require.NoError(t, err)
+	after, err := svc.Encrypt(ctx, "b", []byte("after rotation"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, svc.KeyVersion(before))
+	assert.Equal(t, 2, svc.KeyVersion(after))
+
+	plain, err := svc.Decrypt(ctx, "b", before)
+	require.NoError(t, err)
+	assert.Equal(t, "before rotation", string(plain))
+	plain, err = svc.Decrypt(ctx, "b", after)
+	require.NoError(t, err)
+	assert.Equal(t, "after rotation", string(plain))
+}
+
+func TestEncryptionService_DecryptLegacyCiphertext(t *testing.T) {
+	ctx := context.Background()
+	repo := &memEncryptionRepo{}
+	masterHex := randomHexKey(t)
+	svc, err := services.NewEncryptionService(repo, masterHex)
+	require.NoError(t, err)
+
+	// Legacy data keys carry no master key ID, and legacy ciphertexts no header.
+	master, _ := hex.DecodeString(masterHex)
+	dek := make([]byte, 32)
+	_, _ = rand.Read(dek)
+	repo.keys = append(repo.keys, ports.EncryptionKey{ID: "legacy", BucketName: "b", Version: 1, EncryptedKey: sealForTest(t, master, dek)})
+
+	plain, err := svc.Decrypt(ctx, "b", sealForTest(t, dek, []byte("old object")))
+	require.NoError(t, err)
+	assert.Equal(t, "old object", string(plain))
+}
+
+func TestEncryptionService_RewrapKeys(t *testing.T) {
+	ctx := context.Background()
+	repo := &memEncryptionRepo{}
+	oldMaster, newMaster := randomHexKey(t), randomHexKey(t)
+
+	oldSvc, err := services.NewEncryptionService(repo, oldMaster)
+	require.NoError(t, err)
+	_, err = oldSvc.CreateKey(ctx, "b")
+	require.NoError(t, err)
+	ciphertext, err := oldSvc.Encrypt(ctx, "b", []byte("payload"))
+	require.NoError(t, err)
+
+	// Without the retired key the new master key cannot open the bucket's data key.
+	strict, err := services.NewEncryptionService(repo, newMaster)
+	require.NoError(t, err)
+	_, err = strict.Decrypt(ctx, "b", ciphertext)
+	assert.Error(t, err)
+
+	svc, err := services.NewEncryptionService(repo, newMaster, " "+oldMaster+" ", "")
+	require.NoError(t, err)
+	n, err := svc.RewrapKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = svc.RewrapKeys(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	// After re-wrapping the retired master key is no longer needed.
+	plain, err := strict.Decrypt(ctx, "b", ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "payload", string(plain))
+}
+
+func sealForTest(t *testing.T, key, data []byte) []byte {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := make([]byte, gcm.NonceSize())
+	_, _ = rand.Read(nonce)
+	return gcm.Seal(nonce, nonce, data, nil)
+}
@@ -0,0 +1,288 @@
+// Package services implements core business workflows.
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const flowLogTracer = "flow-log-service"
+
+// FlowLogServiceParams holds the dependencies for creating a FlowLogService.
+type FlowLogServiceParams struct {
+	Repo         ports.FlowLogRepository
+	VpcRepo      ports.VpcRepository
+	SubnetRepo   ports.SubnetRepository
+	InstanceRepo ports.InstanceRepository
+	Network      ports.NetworkBackend
+	LogSvc       ports.LogService
+	AuditSvc     ports.AuditService
+	Logger       *slog.Logger
+}
+
+// FlowLogService manages flow logs and feeds the flows they capture into CloudLogs.
+// Each collection samples the bridge of every VPC that has a flow log once, then
+// ingests the records each flow log covers under the "vpc-flow-log" resource type.
+type FlowLogService struct {
+	repo         ports.FlowLogRepository
+	vpcRepo      ports.VpcRepository
+	subnetRepo   ports.SubnetRepository
+	instanceRepo ports.InstanceRepository
+	network      ports.NetworkBackend
+	logSvc       ports.LogService
+	auditSvc     ports.AuditService
+	logger       *slog.Logger
+}
+
+// NewFlowLogService constructs a FlowLogService with its dependencies.
+func NewFlowLogService(params FlowLogServiceParams) *FlowLogService {
+	return &FlowLogService{
+		repo:         params.Repo,
+		vpcRepo:      params.VpcRepo,
+		subnetRepo:   params.SubnetRepo,
+		instanceRepo: params.InstanceRepo,
+		network:      params.Network,
+		logSvc:       params.LogSvc,
+		auditSvc:     params.AuditSvc,
+		logger:       params.Logger,
+	}
+}
+
+// CreateFlowLog starts capturing the flows of a VPC, a subnet or an instance.
+func (s *FlowLogService) CreateFlowLog(ctx context.Context, resourceType domain.FlowLogResourceType, resourceID uuid.UUID, trafficType domain.FlowLogTrafficType) (*domain.FlowLog, error) {
+	ctx, span := otel.Tracer(flowLogTracer).Start(ctx, "CreateFlowLog")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("resource_type", string(resourceType)),
+		attribute.String("resource_id", resourceID.String()),
+	)
+
+	userID := appcontext.UserIDFromContext(ctx)
+	id := uuid.New()
+	fl := &domain.FlowLog{
+		ID:           id,
+		UserID:       userID,
+		TenantID:     appcontext.TenantIDFromContext(ctx),
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		TrafficType:  trafficType,
+		ARN:          fmt.Sprintf("arn:thecloud:vpc:local:%s:flow-log/%s", userID.String(), id.String()),
+		CreatedAt:    time.Now(),
+	}
+	if err := fl.Validate(); err != nil {
+		return nil, errors.New(errors.InvalidInput, err.Error())
+	}
+
+	vpcID, err := s.resolveVPC(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	fl.VPCID = vpcID
+	if err := s.repo.Create(ctx, fl); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, userID, "flow_log.create", "flow_log", id.String(), map[string]interface{}{
+		"resource_type": string(resourceType),
+		"resource_id":   resourceID.String(),
+		"traffic_type":  string(trafficType),
+	})
+	return fl, nil
+}
+
+// GetFlowLog retrieves a flow log.
+func (s *FlowLogService) GetFlowLog(ctx context.Context, id uuid.UUID) (*domain.FlowLog, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListFlowLogs returns the caller's flow logs.
+func (s *FlowLogService) ListFlowLogs(ctx context.Context) ([]*domain.FlowLog, error) {
+	return s.repo.List(ctx)
+}
+
+// DeleteFlowLog stops a capture. Records already ingested stay in CloudLogs until retention removes them.
+func (s *FlowLogService) DeleteFlowLog(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "flow_log.delete", "flow_log", id.String(), nil)
+	return nil
+}
+
+// CollectFlows samples every bridge that has a flow log and ingests the records each
+// flow log covers. A VPC that cannot be sampled is skipped so the others still get logged.
+func (s *FlowLogService) CollectFlows(ctx context.Context) error {
+	ctx, span := otel.Tracer(flowLogTracer).Start(ctx, "CollectFlows")
+	defer span.End()
+
+	logs, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	byVPC := map[uuid.UUID][]*domain.FlowLog{}
+	var vpcIDs []uuid.UUID
+	for _, fl := range logs {
+		if _, ok := byVPC[fl.VPCID]; !ok {
+			vpcIDs = append(vpcIDs, fl.VPCID)
+		}
+		byVPC[fl.VPCID] = append(byVPC[fl.VPCID], fl)
+	}
+
+	for _, vpcID := range vpcIDs {
+		if err := s.collectVPC(ctx, vpcID, byVPC[vpcID]); err != nil {
+			s.logger.Warn("failed to collect flow logs", "vpc_id", vpcID, "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *FlowLogService) collectVPC(ctx context.Context, vpcID uuid.UUID, logs []*domain.FlowLog) error {
+	// The collector runs outside any request, so act as the VPC's tenant.
+	ctx = appcontext.WithTenantID(ctx, logs[0].TenantID)
+	vpc, err := s.vpcRepo.GetByID(ctx, vpcID)
+	if err != nil {
+		return err
+	}
+	records, err := s.network.SampleFlows(ctx, vpc.NetworkID)
+	if err != nil || len(records) == 0 {
+		return err
+	}
+
+	now := time.Now()
+	var entries []*domain.LogEntry
+	for _, fl := range logs {
+		scope, err := s.scope(ctx, fl)
+		if err != nil {
+			s.logger.Warn("skipping flow log", "flow_log_id", fl.ID, "error", err)
+			continue
+		}
+		for _, rec := range records {
+			if !fl.Records(rec.Action) || (scope != nil && !flowTouches(scope, rec)) {
+				continue
+			}
+			entries = append(entries, flowLogEntry(fl, rec, now))
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return s.logSvc.IngestLogs(ctx, entries)
+}
+
+// scope returns the address ranges a flow log is limited to, or nil for a whole VPC.
+// Dual-stack subnets and instances contribute their IPv6 range or address as well.
+func (s *FlowLogService) scope(ctx context.Context, fl *domain.FlowLog) ([]*net.IPNet, error) {
+	switch fl.ResourceType {
+	case domain.FlowLogSubnet:
+		subnet, err := s.subnetRepo.GetByID(ctx, fl.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+		var scope []*net.IPNet
+		for _, cidr := range []string{subnet.CIDRBlock, subnet.IPv6CIDRBlock} {
+			if cidr == "" {
+				continue
+			}
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, err
+			}
+			scope = append(scope, network)
+		}
+		return scope, nil
+	case domain.FlowLogInstance:
+		inst, err := s.instanceRepo.GetByID(ctx, fl.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+		var scope []*net.IPNet
+		for _, addr := range []string{inst.PrivateIP, inst.PrivateIPv6} {
+			if ip := net.ParseIP(addr); ip != nil {
+				scope = append(scope, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			}
+		}
+		if len(scope) == 0 {
+			return nil, fmt.Errorf("instance %s has no private IP", inst.ID)
+		}
+		return scope, nil
+	default:
+		return nil, nil
+	}
+}
+
+// resolveVPC checks that the captured resource exists and returns the VPC it lives in.
+func (s *FlowLogService) resolveVPC(ctx context.Context, resourceType domain.FlowLogResourceType, resourceID uuid.UUID) (uuid.UUID, error) {
+	switch resourceType {
+	case domain.FlowLogSubnet:
+		subnet, err := s.subnetRepo.GetByID(ctx, resourceID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return subnet.VPCID, nil
+	case domain.FlowLogInstance:
+		inst, err := s.instanceRepo.GetByID(ctx, resourceID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if inst.VpcID == nil {
+			return uuid.Nil, errors.New(errors.InvalidInput, "instance is not attached to a VPC")
+		}
+		return *inst.VpcID, nil
+	default:
+		vpc, err := s.vpcRepo.GetByID(ctx, resourceID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return vpc.ID, nil
+	}
+}
+
+// flowTouches reports whether either end of a flow lies in scope. An end the datapath
+// only matched as a range counts when the range's base address does.
+func flowTouches(scope []*net.IPNet, rec domain.FlowRecord) bool {
+	for _, addr := range []string{rec.SrcIP, rec.DstIP} {
+		host, _, _ := strings.Cut(addr, "/")
+		ip := net.ParseIP(host)
+		if ip == nil {
+			continue
+		}
+		for _, network := range scope {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func flowLogEntry(fl *domain.FlowLog, rec domain.FlowRecord, at time.Time) *domain.LogEntry {
+	level := "INFO"
+	if rec.Action == domain.FlowReject {
+		level = "WARN"
+	}
+	message, _ := json.Marshal(rec)
+	return &domain.LogEntry{
+		ID:           uuid.New(),
+		TenantID:     fl.TenantID,
+		ResourceID:   fl.ID.String(),
+		ResourceType: domain.FlowLogResourceTypeLog,
+		Level:        level,
+		Message:      string(message),
+		Timestamp:    at,
+	}
+}
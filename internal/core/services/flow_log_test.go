@@ -0,0 +1,159 @@
+package services_test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockFlowLogRepo struct {
+	mock.Mock
+}
+
+func (m *MockFlowLogRepo) Create(ctx context.Context, fl *domain.FlowLog) error {
+	return m.Called(ctx, fl).Error(0)
+}
+func (m *MockFlowLogRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.FlowLog, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.FlowLog), args.Error(1)
+}
+func (m *MockFlowLogRepo) List(ctx context.Context) ([]*domain.FlowLog, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.FlowLog), args.Error(1)
+}
+func (m *MockFlowLogRepo) ListAll(ctx context.Context) ([]*domain.FlowLog, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.FlowLog), args.Error(1)
+}
+func (m *MockFlowLogRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func TestFlowLogService(t *testing.T) {
+	tenantID := uuid.New()
+	ctx := appcontext.WithTenantID(appcontext.WithUserID(context.Background(), uuid.New()), tenantID)
+	vpc := &domain.VPC{ID: uuid.New(), TenantID: tenantID, CIDRBlock: "10.0.0.0/16", NetworkID: "br-vpc-flow"}
+	subnet := &domain.Subnet{ID: uuid.New(), VPCID: vpc.ID, CIDRBlock: "10.0.1.0/24"}
+	inst := &domain.Instance{ID: uuid.New(), PrivateIP: "10.0.2.7", PrivateIPv6: "fd00:10:0:2::7"}
+
+	type deps struct {
+		repo    *MockFlowLogRepo
+		network *MockNetworkBackend
+		logs    *MockLogService
+	}
+	setup := func() (*services.FlowLogService, deps) {
+		d := deps{repo: new(MockFlowLogRepo), network: new(MockNetworkBackend), logs: new(MockLogService)}
+		vpcRepo := new(MockVpcRepo)
+		subnets := new(MockSubnetRepo)
+		instances := new(MockInstanceRepo)
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		vpcRepo.On("GetByID", mock.Anything, vpc.ID).Return(vpc, nil).Maybe()
+		subnets.On("GetByID", mock.Anything, subnet.ID).Return(subnet, nil).Maybe()
+		instances.On("GetByID", mock.Anything, inst.ID).Return(inst, nil).Maybe()
+		svc := services.NewFlowLogService(services.FlowLogServiceParams{
+			Repo: d.repo, VpcRepo: vpcRepo, SubnetRepo: subnets, InstanceRepo: instances,
+			Network: d.network, LogSvc: d.logs, AuditSvc: audit, Logger: slog.Default(),
+		})
+		return svc, d
+	}
+
+	t.Run("CreateFlowLog resolves the subnet's VPC", func(t *testing.T) {
+		svc, d := setup()
+		d.repo.On("Create", mock.Anything, mock.MatchedBy(func(fl *domain.FlowLog) bool {
+			return fl.VPCID == vpc.ID && fl.ResourceID == subnet.ID
+		})).Return(nil).Once()
+
+		fl, err := svc.CreateFlowLog(ctx, domain.FlowLogSubnet, subnet.ID, domain.FlowTrafficReject)
+		require.NoError(t, err)
+		assert.Contains(t, fl.ARN, "flow-log/")
+	})
+
+	t.Run("CreateFlowLog validates", func(t *testing.T) {
+		svc, _ := setup()
+		_, err := svc.CreateFlowLog(ctx, domain.FlowLogVPC, vpc.ID, "dropped")
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+	})
+
+	t.Run("CreateFlowLog requires an instance in a VPC", func(t *testing.T) {
+		svc, _ := setup()
+		_, err := svc.CreateFlowLog(ctx, domain.FlowLogInstance, inst.ID, domain.FlowTrafficAll)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+	})
+
+	t.Run("CollectFlows ingests the records each flow log covers", func(t *testing.T) {
+		svc, d := setup()
+		subnetLog := &domain.FlowLog{ID: uuid.New(), TenantID: tenantID, VPCID: vpc.ID, ResourceType: domain.FlowLogSubnet, ResourceID: subnet.ID, TrafficType: domain.FlowTrafficReject}
+		vpcLog := &domain.FlowLog{ID: uuid.New(), TenantID: tenantID, VPCID: vpc.ID, ResourceType: domain.FlowLogVPC, ResourceID: vpc.ID, TrafficType: domain.FlowTrafficAll}
+		d.repo.On("ListAll", mock.Anything).Return([]*domain.FlowLog{subnetLog, vpcLog}, nil)
+		d.network.On("SampleFlows", mock.Anything, "br-vpc-flow").Return([]domain.FlowRecord{
+			{SrcIP: "10.0.1.5", DstIP: "10.0.3.0/24", DstPort: 22, Protocol: "tcp", Packets: 4, Bytes: 240, Action: domain.FlowReject},
+			{SrcIP: "10.0.1.5", DstIP: "10.0.2.7", DstPort: 80, Protocol: "tcp", Packets: 9, Bytes: 900, Action: domain.FlowAccept},
+			{SrcIP: "10.0.4.2", DstIP: "10.0.2.7", DstPort: 5432, Protocol: "tcp", Packets: 1, Bytes: 60, Action: domain.FlowReject},
+		}, nil).Once()
+		d.logs.On("IngestLogs", mock.Anything, mock.MatchedBy(func(entries []*domain.LogEntry) bool {
+			var subnetEntries, vpcEntries int
+			for _, e := range entries {
+				assert.Equal(t, domain.FlowLogResourceTypeLog, e.ResourceType)
+				switch e.ResourceID {
+				case subnetLog.ID.String():
+					subnetEntries++
+					assert.Equal(t, "WARN", e.Level)
+					assert.Contains(t, e.Message, `"action":"reject"`)
+				case vpcLog.ID.String():
+					vpcEntries++
+				}
+			}
+			return subnetEntries == 1 && vpcEntries == 3
+		})).Return(nil).Once()
+
+		require.NoError(t, svc.CollectFlows(context.Background()))
+		d.logs.AssertExpectations(t)
+	})
+
+	t.Run("CollectFlows skips a VPC it cannot sample", func(t *testing.T) {
+		svc, d := setup()
+		d.repo.On("ListAll", mock.Anything).Return([]*domain.FlowLog{
+			{ID: uuid.New(), TenantID: tenantID, VPCID: vpc.ID, ResourceType: domain.FlowLogVPC, ResourceID: vpc.ID, TrafficType: domain.FlowTrafficAll},
+		}, nil)
+		d.network.On("SampleFlows", mock.Anything, "br-vpc-flow").Return(nil, errors.New(errors.Internal, "ovs-appctl failed")).Once()
+
+		require.NoError(t, svc.CollectFlows(context.Background()))
+		d.logs.AssertNotCalled(t, "IngestLogs", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CollectFlows matches an instance by its IPv6 address", func(t *testing.T) {
+		svc, d := setup()
+		instLog := &domain.FlowLog{ID: uuid.New(), TenantID: tenantID, VPCID: vpc.ID, ResourceType: domain.FlowLogInstance, ResourceID: inst.ID, TrafficType: domain.FlowTrafficAll}
+		d.repo.On("ListAll", mock.Anything).Return([]*domain.FlowLog{instLog}, nil)
+		d.network.On("SampleFlows", mock.Anything, "br-vpc-flow").Return([]domain.FlowRecord{
+			{SrcIP: "fd00:10:0:1::5", DstIP: "fd00:10:0:2::7", DstPort: 443, Protocol: "tcp", Packets: 2, Bytes: 180, Action: domain.FlowAccept},
+			{SrcIP: "fd00:10:0:1::5", DstIP: "fd00:10:0:3::9", DstPort: 443, Protocol: "tcp", Packets: 1, Bytes: 90, Action: domain.FlowAccept},
+		}, nil).Once()
+		d.logs.On("IngestLogs", mock.Anything, mock.MatchedBy(func(entries []*domain.LogEntry) bool {
+			return len(entries) == 1 && strings.Contains(entries[0].Message, `"dst_ip":"fd00:10:0:2::7"`)
+		})).Return(nil).Once()
+
+		require.NoError(t, svc.CollectFlows(context.Background()))
+		d.logs.AssertExpectations(t)
+	})
+}
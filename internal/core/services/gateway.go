@@ -3,10 +3,11 @@ package services
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httputil"
-	"net/url"
 	"sort"
 	"strings"
 	"sync"
@@ -16,27 +17,92 @@ import (
 	appcontext "github.com/poyrazk/thecloud/internal/core/context"
 	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
 	"github.com/poyrazk/thecloud/internal/routing"
+	"github.com/poyrazk/thecloud/pkg/ratelimit"
+	"golang.org/x/time/rate"
 )
 
+// routeLimiter is a route's per-client rate limiter and the limit it enforces.
+type routeLimiter struct {
+	rps     int
+	limiter *ratelimit.IPRateLimiter
+}
+
 // GatewayService manages API gateway routes and reverse proxies.
 type GatewayService struct {
-	repo     ports.GatewayRepository
-	proxyMu  sync.RWMutex
-	proxies  map[uuid.UUID]*httputil.ReverseProxy
-	routes   []*domain.GatewayRoute
-	matchers map[uuid.UUID]*routing.PatternMatcher
-	auditSvc ports.AuditService
+	repo          ports.GatewayRepository
+	lbRepo        ports.LBRepository
+	containerRepo ports.ContainerRepository
+	instanceRepo  ports.InstanceRepository
+	functionSvc   ports.FunctionService
+	certSvc       ports.CertificateService
+	logSvc        ports.LogService
+	tlsPort       string
+	proxyMu       sync.RWMutex
+	proxies       map[uuid.UUID]http.Handler
+	routes        []*domain.GatewayRoute
+	matchers      map[uuid.UUID]*routing.PatternMatcher
+	certs         map[string]*tls.Certificate // Route certificates by domain, for SNI
+	certOwners    map[string]uuid.UUID        // Tenant (or user) serving each SNI domain
+	auditSvc      ports.AuditService
+	logger        *slog.Logger
+
+	// refreshMu serializes RefreshRoutes so limiters and circuit breakers are
+	// carried over exactly once.
+	refreshMu sync.Mutex
+	limiters  map[uuid.UUID]*routeLimiter
+	breakers  map[uuid.UUID]*breakerSet
+
+	accessLogMu       sync.Mutex
+	accessLogs        []*domain.LogEntry
+	droppedAccessLogs int
+}
+
+// GatewayServiceParams holds the dependencies of GatewayService. The load
+// balancer, container and instance repositories resolve lb and deployment
+// upstreams, and the function service invokes function upstreams; without
+// them only url upstreams can be used. Access logs are sent to the log
+// service when one is set. The certificate service loads route certificates
+// for the HTTPS listener on TLSPort; an empty TLSPort disables HTTPS
+// redirects.
+type GatewayServiceParams struct {
+	Repo           ports.GatewayRepository
+	LBRepo         ports.LBRepository
+	ContainerRepo  ports.ContainerRepository
+	InstanceRepo   ports.InstanceRepository
+	FunctionSvc    ports.FunctionService
+	CertificateSvc ports.CertificateService
+	TLSPort        string
+	LogSvc         ports.LogService
+	AuditSvc       ports.AuditService
+	Logger         *slog.Logger
 }
 
 // NewGatewayService constructs a GatewayService and loads existing routes.
-func NewGatewayService(repo ports.GatewayRepository, auditSvc ports.AuditService) *GatewayService {
+func NewGatewayService(params GatewayServiceParams) *GatewayService {
+	logger := params.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
 	s := &GatewayService{
-		repo:     repo,
-		proxies:  make(map[uuid.UUID]*httputil.ReverseProxy),
-		routes:   make([]*domain.GatewayRoute, 0),
-		matchers: make(map[uuid.UUID]*routing.PatternMatcher),
-		auditSvc: auditSvc,
+		repo:          params.Repo,
+		lbRepo:        params.LBRepo,
+		containerRepo: params.ContainerRepo,
+		instanceRepo:  params.InstanceRepo,
+		functionSvc:   params.FunctionSvc,
+		certSvc:       params.CertificateSvc,
+		logSvc:        params.LogSvc,
+		tlsPort:       params.TLSPort,
+		proxies:       make(map[uuid.UUID]http.Handler),
+		routes:        make([]*domain.GatewayRoute, 0),
+		matchers:      make(map[uuid.UUID]*routing.PatternMatcher),
+		certs:         make(map[string]*tls.Certificate),
+		certOwners:    make(map[string]uuid.UUID),
+		auditSvc:      params.AuditSvc,
+		logger:        logger,
+		limiters:      make(map[uuid.UUID]*routeLimiter),
+		breakers:      make(map[uuid.UUID]*breakerSet),
 	}
 	// Initial load
 	_ = s.RefreshRoutes(context.Background())
@@ -61,6 +127,34 @@ func (s *GatewayService) CreateRoute(ctx context.Context, params ports.CreateRou
 		paramNames = matcher.ParamNames
 	}
 
+	plugins := params.Plugins
+	if err := plugins.Validate(); err != nil {
+		return nil, errors.New(errors.InvalidInput, err.Error())
+	}
+	if plugins.Auth != nil {
+		auth := *plugins.Auth
+		if auth.Type == domain.GatewayAuthAPIKey {
+			hashGatewayAPIKeys(&auth)
+		}
+		if auth.Type == domain.GatewayAuthJWT && auth.Algorithm == domain.JWTAlgorithmRS256 {
+			if _, err := parseRSAPublicKey(auth.PublicKey); err != nil {
+				return nil, errors.New(errors.InvalidInput, err.Error())
+			}
+		}
+		plugins.Auth = &auth
+	}
+
+	if params.CertificateID != nil {
+		if err := s.checkCertificate(ctx, *params.CertificateID); err != nil {
+			return nil, err
+		}
+	}
+
+	upstreams := append([]domain.GatewayUpstream(nil), params.Upstreams...)
+	if err := s.checkUpstreams(ctx, upstreams); err != nil {
+		return nil, err
+	}
+
 	route := &domain.GatewayRoute{
 		ID:          uuid.New(),
 		UserID:      userID,
@@ -74,8 +168,18 @@ func (s *GatewayService) CreateRoute(ctx context.Context, params ports.CreateRou
 		StripPrefix: params.StripPrefix,
 		RateLimit:   params.RateLimit,
 		Priority:    params.Priority,
+		Plugins:     plugins,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
+
+		Upstreams:     upstreams,
+		TrafficPolicy: params.TrafficPolicy,
+		TenantID:      appcontext.TenantIDFromContext(ctx),
+		CertificateID: params.CertificateID,
+		HTTPSRedirect: params.HTTPSRedirect,
+	}
+	if err := route.ValidateUpstreams(); err != nil {
+		return nil, errors.New(errors.InvalidInput, err.Error())
 	}
 
 	if err := s.repo.CreateRoute(ctx, route); err != nil {
@@ -89,6 +193,7 @@ func (s *GatewayService) CreateRoute(ctx context.Context, params ports.CreateRou
 	})
 
 	_ = s.RefreshRoutes(ctx)
+	route.Plugins = route.Plugins.Redacted()
 	return route, nil
 }
 
@@ -97,7 +202,14 @@ func (s *GatewayService) ListRoutes(ctx context.Context) ([]*domain.GatewayRoute
 	if userID == uuid.Nil {
 		return nil, fmt.Errorf("unauthorized")
 	}
-	return s.repo.ListRoutes(ctx, userID)
+	routes, err := s.repo.ListRoutes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routes {
+		r.Plugins = r.Plugins.Redacted()
+	}
+	return routes, nil
 }
 
 func (s *GatewayService) DeleteRoute(ctx context.Context, id uuid.UUID) error {
@@ -118,21 +230,48 @@ func (s *GatewayService) DeleteRoute(ctx context.Context, id uuid.UUID) error {
 }
 
 func (s *GatewayService) RefreshRoutes(ctx context.Context) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
 	routes, err := s.repo.GetAllActiveRoutes(ctx)
 	if err != nil {
 		return err
 	}
 
-	newProxies := make(map[uuid.UUID]*httputil.ReverseProxy)
+	newProxies := make(map[uuid.UUID]http.Handler)
 	newMatchers := make(map[uuid.UUID]*routing.PatternMatcher)
+	newLimiters := make(map[uuid.UUID]*routeLimiter)
+	newBreakers := make(map[uuid.UUID]*breakerSet)
 
 	for _, r := range routes {
-		proxy, err := s.createReverseProxy(r)
+		var backend http.Handler
+		if fn := r.FunctionUpstream(); fn != nil {
+			backend = s.newFunctionHandler(r, *fn.TargetID)
+		} else {
+			breakers, ok := s.breakers[r.ID]
+			if !ok || !breakers.matches(r.TrafficPolicy) {
+				breakers = newBreakerSet(r.TrafficPolicy)
+			}
+			proxy, err := s.createReverseProxy(r, breakers)
+			if err != nil {
+				s.logger.Warn("skipping gateway route with invalid upstreams", "route_id", r.ID, "error", err)
+				continue
+			}
+			newBreakers[r.ID] = breakers
+			backend = proxy
+		}
+
+		limiter := s.routeLimiter(r)
+		handler, err := buildRouteHandler(r, backend, limiter)
 		if err != nil {
+			s.logger.Warn("skipping gateway route with invalid plugins", "route_id", r.ID, "error", err)
 			continue
 		}
+		if limiter != nil {
+			newLimiters[r.ID] = &routeLimiter{rps: r.RateLimit, limiter: limiter}
+		}
 
-		newProxies[r.ID] = proxy
+		newProxies[r.ID] = s.accessLogHandler(r, s.httpsRedirectHandler(r, handler))
 		if r.PatternType == "pattern" {
 			matcher, err := routing.CompilePattern(r.PathPattern)
 			if err == nil {
@@ -142,42 +281,126 @@ func (s *GatewayService) RefreshRoutes(ctx context.Context) error {
 	}
 
 	s.sortRoutes(routes)
+	certs, certOwners := s.loadCertificates(ctx, routes)
 
 	s.proxyMu.Lock()
 	s.proxies = newProxies
 	s.routes = routes
 	s.matchers = newMatchers
+	s.certs = certs
+	s.certOwners = certOwners
 	s.proxyMu.Unlock()
 
+	// Stop the limiters of deleted routes and of routes whose limit changed.
+	for id, l := range s.limiters {
+		if kept, ok := newLimiters[id]; !ok || kept.limiter != l.limiter {
+			l.limiter.Stop()
+		}
+	}
+	s.limiters = newLimiters
+	s.breakers = newBreakers
+
 	return nil
 }
 
-func (s *GatewayService) createReverseProxy(route *domain.GatewayRoute) (*httputil.ReverseProxy, error) {
-	target, err := url.Parse(route.TargetURL)
+// routeLimiter returns the rate limiter for a route, reusing the current one
+// while its limit is unchanged so refreshes do not reset client budgets. A
+// route without a positive limit is not rate limited.
+func (s *GatewayService) routeLimiter(route *domain.GatewayRoute) *ratelimit.IPRateLimiter {
+	if route.RateLimit <= 0 {
+		return nil
+	}
+	if existing, ok := s.limiters[route.ID]; ok && existing.rps == route.RateLimit {
+		return existing.limiter
+	}
+	return ratelimit.NewIPRateLimiter(rate.Limit(route.RateLimit), route.RateLimit, s.logger)
+}
+
+// createReverseProxy builds a route's reverse proxy. The director only strips
+// the prefix; the upstream pool picks the target of each attempt.
+func (s *GatewayService) createReverseProxy(route *domain.GatewayRoute, breakers *breakerSet) (*httputil.ReverseProxy, error) {
+	pool, err := newUpstreamPool(route, breakers, s.resolveUpstream, s.logger)
 	if err != nil {
 		return nil, err
 	}
 
-	proxy := httputil.NewSingleHostReverseProxy(target)
-	originalDirector := proxy.Director
-	proxy.Director = func(req *http.Request) {
-		if route.StripPrefix {
-			prefix := route.PathPrefix
-			if route.PatternType == "pattern" {
-				prefix = routing.GetLiteralPrefix(route.PathPattern)
-			}
-			req.URL.Path = strings.TrimPrefix(req.URL.Path, "/gw"+prefix)
-			if !strings.HasPrefix(req.URL.Path, "/") {
-				req.URL.Path = "/" + req.URL.Path
+	proxy := &httputil.ReverseProxy{
+		Transport: pool,
+		Director: func(req *http.Request) {
+			req.URL.Path = routeBackendPath(route, req.URL.Path)
+			if _, ok := req.Header["User-Agent"]; !ok {
+				// Explicitly disable the default User-Agent, as NewSingleHostReverseProxy does.
+				req.Header.Set("User-Agent", "")
 			}
-		}
-		originalDirector(req)
-		req.Host = target.Host
+		},
 	}
 
 	return proxy, nil
 }
 
+// routeBackendPath returns the path a route forwards a request to, with the
+// matched prefix removed when the route strips it.
+func routeBackendPath(route *domain.GatewayRoute, path string) string {
+	if !route.StripPrefix {
+		return path
+	}
+	prefix := route.PathPrefix
+	if route.PatternType == "pattern" {
+		prefix = routing.GetLiteralPrefix(route.PathPattern)
+	}
+	path = strings.TrimPrefix(path, "/gw"+prefix)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// checkUpstreams defaults the weights of an unweighted upstream list and
+// verifies that lb and deployment upstreams belong to the caller.
+func (s *GatewayService) checkUpstreams(ctx context.Context, upstreams []domain.GatewayUpstream) error {
+	total := 0
+	for _, u := range upstreams {
+		total += u.Weight
+	}
+	for i := range upstreams {
+		if total == 0 {
+			upstreams[i].Weight = 1
+		}
+		u := upstreams[i]
+		if u.TargetID == nil {
+			continue
+		}
+		switch u.Type {
+		case domain.GatewayUpstreamLB:
+			if s.lbRepo == nil {
+				return errors.New(errors.InvalidInput, "load balancer upstreams are not supported")
+			}
+			if _, err := s.lbRepo.GetByID(ctx, *u.TargetID); err != nil {
+				return err
+			}
+		case domain.GatewayUpstreamDeployment:
+			if s.containerRepo == nil || s.instanceRepo == nil {
+				return errors.New(errors.InvalidInput, "deployment upstreams are not supported")
+			}
+			if _, err := s.containerRepo.GetDeploymentByID(ctx, *u.TargetID, appcontext.UserIDFromContext(ctx)); err != nil {
+				return err
+			}
+		case domain.GatewayUpstreamFunction:
+			if s.functionSvc == nil {
+				return errors.New(errors.InvalidInput, "function upstreams are not supported")
+			}
+			fn, err := s.functionSvc.GetFunction(ctx, *u.TargetID)
+			if err != nil {
+				return err
+			}
+			if fn.UserID != appcontext.UserIDFromContext(ctx) {
+				return errors.New(errors.NotFound, "function not found")
+			}
+		}
+	}
+	return nil
+}
+
 func (s *GatewayService) sortRoutes(routes []*domain.GatewayRoute) {
 	// Sort routes by specificity (longer literal prefixes and higher priority first)
 	sort.Slice(routes, func(i, j int) bool {
@@ -189,7 +412,7 @@ func (s *GatewayService) sortRoutes(routes []*domain.GatewayRoute) {
 
 // ProxyHandler is handled in the API layer for now
 
-func (s *GatewayService) GetProxy(method, path string) (*httputil.ReverseProxy, map[string]string, bool) {
+func (s *GatewayService) GetProxy(method, path string) (http.Handler, map[string]string, bool) {
 	s.proxyMu.RLock()
 	defer s.proxyMu.RUnlock()
 
@@ -212,8 +435,8 @@ func (s *GatewayService) GetProxy(method, path string) (*httputil.ReverseProxy,
 }
 
 func (s *GatewayService) checkRouteMatch(route *domain.GatewayRoute, method, path string) *domain.RouteMatch {
-	// 1. Method filter
-	if !s.isMethodAllowed(route, method) {
+	// 1. Method filter. CORS preflights are answered by the route's CORS policy.
+	if !s.isMethodAllowed(route, method) && (method != http.MethodOptions || route.Plugins.CORS == nil) {
 		return nil
 	}
 
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/platform"
+)
+
+// maxBufferedAccessLogs bounds the access logs held between flushes; further
+// requests are served but not logged until the next flush.
+const maxBufferedAccessLogs = 10000
+
+// accessLogFlushBatch bounds the entries ingested per call, keeping each
+// batch insert well under PostgreSQL's 65,535 bind parameter limit.
+const accessLogFlushBatch = 1000
+
+// accessRecordKey carries a request's *accessRecord through the route handler.
+type accessRecordKey struct{}
+
+// accessRecord collects what inner handlers learn about a request for its
+// access log entry.
+type accessRecord struct {
+	upstream string
+	apiKeyID string
+}
+
+// recordUpstream notes which upstream served the request's latest attempt.
+func recordUpstream(ctx context.Context, upstream string) {
+	if rec, ok := ctx.Value(accessRecordKey{}).(*accessRecord); ok {
+		rec.upstream = upstream
+	}
+}
+
+// recordAPIKey notes the API key a request authenticated with, by ID only.
+func recordAPIKey(ctx context.Context, key string) {
+	if rec, ok := ctx.Value(accessRecordKey{}).(*accessRecord); ok {
+		rec.apiKeyID = gatewayAPIKeyID(key)
+	}
+}
+
+// gatewayAPIKeyID identifies an API key in logs without revealing it.
+func gatewayAPIKeyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// accessLogHandler observes every request a route serves, including those
+// its plugins reject, and records the request duration metric and an access
+// log entry.
+func (s *GatewayService) accessLogHandler(route *domain.GatewayRoute, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &accessRecord{}
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), accessRecordKey{}, rec)))
+		elapsed := time.Since(start)
+
+		status := sw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		platform.GatewayRequestDuration.WithLabelValues(route.ID.String(), metricMethod(r.Method), strconv.Itoa(status)).Observe(elapsed.Seconds())
+
+		s.bufferAccessLog(route, &domain.GatewayAccessLog{
+			RouteID:   route.ID,
+			RouteName: route.Name,
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			Status:    status,
+			LatencyMs: float64(elapsed.Microseconds()) / 1000,
+			BytesSent: sw.bytes,
+			Upstream:  rec.upstream,
+			ClientIP:  clientIP(r),
+			APIKeyID:  rec.apiKeyID,
+		}, start)
+	})
+}
+
+// bufferAccessLog queues an access log entry for the next flush. Routes
+// created before tenants were recorded have nowhere to log to.
+func (s *GatewayService) bufferAccessLog(route *domain.GatewayRoute, entry *domain.GatewayAccessLog, at time.Time) {
+	if s.logSvc == nil || route.TenantID == uuid.Nil {
+		return
+	}
+	message, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	level := "INFO"
+	switch {
+	case entry.Status >= 500:
+		level = "ERROR"
+	case entry.Status >= 400:
+		level = "WARN"
+	}
+
+	s.accessLogMu.Lock()
+	defer s.accessLogMu.Unlock()
+	if len(s.accessLogs) >= maxBufferedAccessLogs {
+		s.droppedAccessLogs++
+		return
+	}
+	s.accessLogs = append(s.accessLogs, &domain.LogEntry{
+		ID:           uuid.New(),
+		TenantID:     route.TenantID,
+		ResourceID:   route.ID.String(),
+		ResourceType: domain.GatewayAccessLogResourceType,
+		Level:        level,
+		Message:      string(message),
+		Timestamp:    at,
+	})
+}
+
+// FlushAccessLogs ingests the access logs buffered since the last flush, in
+// batches of accessLogFlushBatch. Batches that fail to ingest are dropped
+// rather than retried, and the first failure is returned.
+func (s *GatewayService) FlushAccessLogs(ctx context.Context) error {
+	s.accessLogMu.Lock()
+	entries, dropped := s.accessLogs, s.droppedAccessLogs
+	s.accessLogs, s.droppedAccessLogs = nil, 0
+	s.accessLogMu.Unlock()
+
+	if dropped > 0 {
+		s.logger.Warn("gateway access log buffer full, entries dropped", "dropped", dropped)
+	}
+	var firstErr error
+	for start := 0; start < len(entries); start += accessLogFlushBatch {
+		end := min(start+accessLogFlushBatch, len(entries))
+		if err := s.logSvc.IngestLogs(ctx, entries[start:end]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// metricMethod bounds the method label to the standard methods.
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+// statusWriter records the status code and size of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	// Informational responses such as 103 Early Hints precede the real status.
+	if w.status == 0 && code >= 200 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush lets the reverse proxy stream responses through the writer.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,134 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/platform"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGatewayAccessLogs(t *testing.T) {
+	t.Parallel()
+	backend := newCountingUpstream(t, http.StatusOK, 0)
+	sum := sha256.Sum256([]byte("good-key"))
+	route := &domain.GatewayRoute{
+		ID:          uuid.New(),
+		Name:        "orders",
+		TenantID:    uuid.New(),
+		PathPrefix:  "/orders",
+		PathPattern: "/orders",
+		PatternType: "prefix",
+		TargetURL:   backend.URL,
+		Plugins: domain.GatewayRoutePlugins{
+			Auth: &domain.GatewayAuthConfig{Type: domain.GatewayAuthAPIKey, APIKeyHashes: []string{hex.EncodeToString(sum[:])}},
+		},
+	}
+
+	repo := new(MockGatewayRepo)
+	repo.On("GetAllActiveRoutes", mock.Anything).Return([]*domain.GatewayRoute{route}, nil)
+	logSvc := new(MockLogService)
+	svc := services.NewGatewayService(services.GatewayServiceParams{Repo: repo, LogSvc: logSvc, AuditSvc: new(MockAuditService)})
+
+	ok := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	ok.Header.Set("X-API-Key", "good-key")
+	ok.RemoteAddr = "198.51.100.4:5000"
+	assert.Equal(t, http.StatusOK, serveGateway(t, svc, ok).Code)
+	assert.Equal(t, http.StatusUnauthorized, serveGateway(t, svc, httptest.NewRequest(http.MethodGet, "/orders/2", nil)).Code)
+
+	var ingested []*domain.LogEntry
+	logSvc.On("IngestLogs", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ingested = args.Get(1).([]*domain.LogEntry)
+	}).Return(nil).Once()
+	require.NoError(t, svc.FlushAccessLogs(context.Background()))
+	require.Len(t, ingested, 2)
+
+	for _, e := range ingested {
+		assert.Equal(t, route.TenantID, e.TenantID)
+		assert.Equal(t, route.ID.String(), e.ResourceID)
+		assert.Equal(t, domain.GatewayAccessLogResourceType, e.ResourceType)
+	}
+
+	var served, rejected domain.GatewayAccessLog
+	require.NoError(t, json.Unmarshal([]byte(ingested[0].Message), &served))
+	require.NoError(t, json.Unmarshal([]byte(ingested[1].Message), &rejected))
+
+	assert.Equal(t, "INFO", ingested[0].Level)
+	assert.Equal(t, "orders", served.RouteName)
+	assert.Equal(t, http.MethodGet, served.Method)
+	assert.Equal(t, "/orders/1", served.Path)
+	assert.Equal(t, http.StatusOK, served.Status)
+	assert.Equal(t, backend.Listener.Addr().String(), served.Upstream)
+	assert.Equal(t, "198.51.100.4", served.ClientIP)
+	assert.Len(t, served.APIKeyID, 12)
+	assert.NotContains(t, ingested[0].Message, "good-key")
+	assert.Positive(t, served.BytesSent)
+
+	assert.Equal(t, "WARN", ingested[1].Level)
+	assert.Equal(t, http.StatusUnauthorized, rejected.Status)
+	assert.Empty(t, rejected.Upstream, "rejected requests never reach an upstream")
+	assert.Empty(t, rejected.APIKeyID)
+
+	var rejectedMetric dto.Metric
+	require.NoError(t, platform.GatewayRequestDuration.WithLabelValues(route.ID.String(), http.MethodGet, "401").(prometheus.Histogram).Write(&rejectedMetric))
+	assert.Equal(t, uint64(1), rejectedMetric.GetHistogram().GetSampleCount())
+
+	// Nothing is left to flush.
+	require.NoError(t, svc.FlushAccessLogs(context.Background()))
+	logSvc.AssertNumberOfCalls(t, "IngestLogs", 1)
+}
+
+func TestGatewayAccessLogsWithoutTenant(t *testing.T) {
+	t.Parallel()
+	backend := newCountingUpstream(t, http.StatusOK, 0)
+	route := &domain.GatewayRoute{ID: uuid.New(), PathPrefix: "/legacy", PathPattern: "/legacy", PatternType: "prefix", TargetURL: backend.URL}
+
+	repo := new(MockGatewayRepo)
+	repo.On("GetAllActiveRoutes", mock.Anything).Return([]*domain.GatewayRoute{route}, nil)
+	logSvc := new(MockLogService)
+	svc := services.NewGatewayService(services.GatewayServiceParams{Repo: repo, LogSvc: logSvc, AuditSvc: new(MockAuditService)})
+
+	assert.Equal(t, http.StatusOK, serveGateway(t, svc, httptest.NewRequest(http.MethodGet, "/legacy", nil)).Code)
+	require.NoError(t, svc.FlushAccessLogs(context.Background()))
+	logSvc.AssertNotCalled(t, "IngestLogs", mock.Anything, mock.Anything)
+}
+
+func TestGatewayAccessLogsFlushInBatches(t *testing.T) {
+	t.Parallel()
+	sum := sha256.Sum256([]byte("key"))
+	route := &domain.GatewayRoute{
+		ID: uuid.New(), TenantID: uuid.New(), PathPrefix: "/batch", PathPattern: "/batch", PatternType: "prefix", TargetURL: "http://unused:8080",
+		Plugins: domain.GatewayRoutePlugins{
+			Auth: &domain.GatewayAuthConfig{Type: domain.GatewayAuthAPIKey, APIKeyHashes: []string{hex.EncodeToString(sum[:])}},
+		},
+	}
+
+	repo := new(MockGatewayRepo)
+	repo.On("GetAllActiveRoutes", mock.Anything).Return([]*domain.GatewayRoute{route}, nil)
+	logSvc := new(MockLogService)
+	svc := services.NewGatewayService(services.GatewayServiceParams{Repo: repo, LogSvc: logSvc, AuditSvc: new(MockAuditService)})
+
+	// Rejected requests are logged without reaching an upstream.
+	for range 2500 {
+		serveGateway(t, svc, httptest.NewRequest(http.MethodGet, "/batch", nil))
+	}
+
+	var sizes []int
+	logSvc.On("IngestLogs", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sizes = append(sizes, len(args.Get(1).([]*domain.LogEntry)))
+	}).Return(nil)
+	require.NoError(t, svc.FlushAccessLogs(context.Background()))
+	assert.Equal(t, []int{1000, 1000, 500}, sizes)
+}
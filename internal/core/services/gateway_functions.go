@@ -0,0 +1,150 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+)
+
+// maxFunctionPayloadBytes bounds the encoded invocation event. Functions
+// receive it in an environment variable, which the kernel limits to 128 KiB.
+const maxFunctionPayloadBytes = 96 << 10
+
+// functionResponseHeaders are managed by the gateway and cannot be set by a
+// function's response.
+var functionResponseHeaders = []string{"Content-Length", "Transfer-Encoding", "Connection"}
+
+// newFunctionHandler serves a route by invoking a CloudFunction with each
+// request and writing the function's output back as the HTTP response.
+func (s *GatewayService) newFunctionHandler(route *domain.GatewayRoute, functionID uuid.UUID) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.functionSvc == nil {
+			writeGatewayError(w, http.StatusServiceUnavailable, "function upstreams are not supported")
+			return
+		}
+
+		payload, status, err := functionEvent(route, r)
+		if err != nil {
+			writeGatewayError(w, status, err.Error())
+			return
+		}
+
+		recordUpstream(r.Context(), "function:"+functionID.String())
+		inv, err := s.functionSvc.InvokeFunction(routeOwnerContext(r.Context(), route), functionID, payload, false)
+		if err != nil {
+			s.logger.Warn("gateway function invocation failed", "route_id", route.ID, "function_id", functionID, "error", err)
+			writeGatewayError(w, http.StatusBadGateway, "function invocation failed")
+			return
+		}
+		if inv.Status != "SUCCESS" {
+			if strings.Contains(inv.Logs, "Execution timed out") {
+				writeGatewayError(w, http.StatusGatewayTimeout, "function timed out")
+				return
+			}
+			writeGatewayError(w, http.StatusBadGateway, "function failed")
+			return
+		}
+
+		resp := parseFunctionOutput(inv.Logs)
+		body := []byte(resp.Body)
+		if resp.IsBase64Encoded {
+			if body, err = base64.StdEncoding.DecodeString(resp.Body); err != nil {
+				writeGatewayError(w, http.StatusBadGateway, "function returned an invalid base64 body")
+				return
+			}
+		}
+		if resp.StatusCode < 100 || resp.StatusCode > 599 {
+			writeGatewayError(w, http.StatusBadGateway, "function returned an invalid status code")
+			return
+		}
+
+		for k, v := range resp.Headers {
+			w.Header().Set(k, v)
+		}
+		for _, h := range functionResponseHeaders {
+			w.Header().Del(h)
+		}
+		applyResponsePlugins(w.Header(), route.Plugins)
+		w.WriteHeader(resp.StatusCode)
+		_, _ = w.Write(body)
+	})
+}
+
+// functionEvent encodes a request as a function invocation payload. On
+// failure it also returns the HTTP status to answer with.
+func functionEvent(route *domain.GatewayRoute, r *http.Request) ([]byte, int, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(r.Body, maxFunctionPayloadBytes+1))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, http.StatusRequestEntityTooLarge, errors.New("request body too large")
+			}
+			return nil, http.StatusBadRequest, errors.New("failed to read request body")
+		}
+	}
+
+	event := domain.GatewayFunctionEvent{
+		Method:  r.Method,
+		Path:    routeBackendPath(route, r.URL.Path),
+		Query:   r.URL.Query(),
+		Params:  appcontext.RouteParamsFromContext(r.Context()),
+		Headers: make(map[string]string, len(r.Header)),
+	}
+	for k, v := range r.Header {
+		event.Headers[k] = strings.Join(v, ",")
+	}
+	if len(event.Query) == 0 {
+		event.Query = nil
+	}
+	if utf8.Valid(body) {
+		event.Body = string(body)
+	} else {
+		event.Body = base64.StdEncoding.EncodeToString(body)
+		event.IsBase64Encoded = true
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("failed to encode function event")
+	}
+	if len(payload) > maxFunctionPayloadBytes {
+		return nil, http.StatusRequestEntityTooLarge, errors.New("request too large for a function")
+	}
+	return payload, 0, nil
+}
+
+// parseFunctionOutput maps a function's output to an HTTP response. The
+// output, or its last line when the function also logged, may be a response
+// object with a status_code; anything else is returned as a plain-text 200.
+func parseFunctionOutput(output string) domain.GatewayFunctionResponse {
+	output = strings.TrimSpace(output)
+	candidates := []string{output}
+	if i := strings.LastIndexByte(output, '\n'); i >= 0 {
+		candidates = append(candidates, strings.TrimSpace(output[i+1:]))
+	}
+	for _, c := range candidates {
+		if !strings.HasPrefix(c, "{") {
+			continue
+		}
+		var resp domain.GatewayFunctionResponse
+		if err := json.Unmarshal([]byte(c), &resp); err == nil && resp.StatusCode != 0 {
+			return resp
+		}
+	}
+	return domain.GatewayFunctionResponse{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+		Body:       output,
+	}
+}
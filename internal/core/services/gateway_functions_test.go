@@ -0,0 +1,197 @@
+package services_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubFunctionService answers invocations with a fixed invocation result and
+// records the last payload.
+type stubFunctionService struct {
+	ports.FunctionService
+	fn      *domain.Function
+	result  domain.Invocation
+	err     error
+	userID  uuid.UUID
+	payload []byte
+}
+
+func (s *stubFunctionService) GetFunction(_ context.Context, id uuid.UUID) (*domain.Function, error) {
+	if s.fn == nil || s.fn.ID != id {
+		return nil, assert.AnError
+	}
+	return s.fn, nil
+}
+
+func (s *stubFunctionService) InvokeFunction(ctx context.Context, _ uuid.UUID, payload []byte, async bool) (*domain.Invocation, error) {
+	if async {
+		return nil, assert.AnError
+	}
+	s.userID = appcontext.UserIDFromContext(ctx)
+	s.payload = payload
+	if s.err != nil {
+		return nil, s.err
+	}
+	inv := s.result
+	return &inv, nil
+}
+
+func newFunctionGateway(t *testing.T, fnSvc *stubFunctionService, route *domain.GatewayRoute) *services.GatewayService {
+	t.Helper()
+	fnID := uuid.New()
+	route.ID = uuid.New()
+	route.UserID = uuid.New()
+	route.PathPrefix = "/users"
+	route.Upstreams = []domain.GatewayUpstream{{Type: domain.GatewayUpstreamFunction, TargetID: &fnID, Weight: 1}}
+	if route.PathPattern == "" {
+		route.PathPattern = "/users"
+		route.PatternType = "prefix"
+	}
+
+	repo := new(MockGatewayRepo)
+	repo.On("GetAllActiveRoutes", mock.Anything).Return([]*domain.GatewayRoute{route}, nil)
+	return services.NewGatewayService(services.GatewayServiceParams{Repo: repo, FunctionSvc: fnSvc, AuditSvc: new(MockAuditService)})
+}
+
+func serveFunctionRoute(t *testing.T, svc *services.GatewayService, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	handler, params, ok := svc.GetProxy(req.Method, strings.TrimPrefix(req.URL.Path, "/gw"))
+	require.True(t, ok)
+	req = req.WithContext(appcontext.WithRouteParams(req.Context(), params))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestGatewayFunctionRoute(t *testing.T) {
+	t.Parallel()
+	fnSvc := &stubFunctionService{result: domain.Invocation{
+		Status: "SUCCESS",
+		Logs:   "handling request\n" + `{"status_code": 201, "headers": {"Content-Type": "application/json", "X-Fn": "1", "Content-Length": "999"}, "body": "{\"ok\":true}"}`,
+	}}
+	route := &domain.GatewayRoute{
+		PathPattern: "/users/{id}",
+		PatternType: "pattern",
+		StripPrefix: true,
+		Plugins:     domain.GatewayRoutePlugins{ResponseHeaders: &domain.GatewayHeaderRewrite{Remove: []string{"X-Fn"}}},
+	}
+	svc := newFunctionGateway(t, fnSvc, route)
+
+	req := httptest.NewRequest(http.MethodPost, "/gw/users/42?verbose=1", strings.NewReader(`{"name":"ada"}`))
+	req.Header.Set("X-Request-Id", "abc")
+	w := serveFunctionRoute(t, svc, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"ok":true}`, w.Body.String())
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Header().Get("X-Fn"), "response plugins apply to function responses")
+	assert.Equal(t, route.UserID, fnSvc.userID, "functions are invoked as the route owner")
+
+	var event domain.GatewayFunctionEvent
+	require.NoError(t, json.Unmarshal(fnSvc.payload, &event))
+	assert.Equal(t, http.MethodPost, event.Method)
+	assert.Equal(t, "/42", event.Path)
+	assert.Equal(t, map[string]string{"id": "42"}, event.Params)
+	assert.Equal(t, []string{"1"}, event.Query["verbose"])
+	assert.Equal(t, "abc", event.Headers["X-Request-Id"])
+	assert.Equal(t, `{"name":"ada"}`, event.Body)
+	assert.False(t, event.IsBase64Encoded)
+}
+
+func TestGatewayFunctionRouteOutputs(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name       string
+		inv        domain.Invocation
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{"PlainText", domain.Invocation{Status: "SUCCESS", Logs: "hello\n"}, nil, http.StatusOK, "hello"},
+		{"Base64Body", domain.Invocation{Status: "SUCCESS", Logs: `{"status_code": 200, "body": "` + base64.StdEncoding.EncodeToString([]byte{0xff, 0x00}) + `", "is_base64_encoded": true}`}, nil, http.StatusOK, "\xff\x00"},
+		{"InvalidStatus", domain.Invocation{Status: "SUCCESS", Logs: `{"status_code": 1000}`}, nil, http.StatusBadGateway, ""},
+		{"Failed", domain.Invocation{Status: "FAILED", Logs: "panic: secret stack trace"}, nil, http.StatusBadGateway, ""},
+		{"TimedOut", domain.Invocation{Status: "FAILED", Logs: "\nError: Execution timed out"}, nil, http.StatusGatewayTimeout, ""},
+		{"InvokeError", domain.Invocation{}, assert.AnError, http.StatusBadGateway, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			svc := newFunctionGateway(t, &stubFunctionService{result: tc.inv, err: tc.err}, &domain.GatewayRoute{})
+			w := serveFunctionRoute(t, svc, httptest.NewRequest(http.MethodGet, "/gw/users", nil))
+			assert.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantBody != "" {
+				assert.Equal(t, tc.wantBody, w.Body.String())
+			}
+			assert.NotContains(t, w.Body.String(), "secret", "function logs are not leaked on failure")
+		})
+	}
+}
+
+func TestGatewayFunctionRouteRequestLimits(t *testing.T) {
+	t.Parallel()
+	fnSvc := &stubFunctionService{result: domain.Invocation{Status: "SUCCESS"}}
+	svc := newFunctionGateway(t, fnSvc, &domain.GatewayRoute{})
+
+	w := serveFunctionRoute(t, svc, httptest.NewRequest(http.MethodPost, "/gw/users", strings.NewReader(strings.Repeat("a", 200<<10))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Nil(t, fnSvc.payload, "oversized requests are not invoked")
+
+	w = serveFunctionRoute(t, svc, httptest.NewRequest(http.MethodPost, "/gw/users", strings.NewReader("\xff\xfe")))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var event domain.GatewayFunctionEvent
+	require.NoError(t, json.Unmarshal(fnSvc.payload, &event))
+	assert.True(t, event.IsBase64Encoded)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("\xff\xfe")), event.Body)
+}
+
+func TestGatewayCreateFunctionRoute(t *testing.T) {
+	t.Parallel()
+	userID := uuid.New()
+	fn := &domain.Function{ID: uuid.New(), UserID: userID}
+	other := &domain.Function{ID: uuid.New(), UserID: uuid.New()}
+	repo := new(MockGatewayRepo)
+	audit := new(MockAuditService)
+	repo.On("GetAllActiveRoutes", mock.Anything).Return([]*domain.GatewayRoute{}, nil)
+	repo.On("CreateRoute", mock.Anything, mock.Anything).Return(nil)
+	audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	ctx := appcontext.WithUserID(context.Background(), userID)
+
+	svc := services.NewGatewayService(services.GatewayServiceParams{Repo: repo, AuditSvc: audit, FunctionSvc: &stubFunctionService{fn: fn}})
+	_, err := svc.CreateRoute(ctx, ports.CreateRouteParams{
+		Name: "fn", Pattern: "/fn",
+		Upstreams: []domain.GatewayUpstream{{Type: domain.GatewayUpstreamFunction, TargetID: &fn.ID}},
+	})
+	require.NoError(t, err)
+
+	svc = services.NewGatewayService(services.GatewayServiceParams{Repo: repo, AuditSvc: audit, FunctionSvc: &stubFunctionService{fn: other}})
+	_, err = svc.CreateRoute(ctx, ports.CreateRouteParams{
+		Name: "other", Pattern: "/other",
+		Upstreams: []domain.GatewayUpstream{{Type: domain.GatewayUpstreamFunction, TargetID: &other.ID}},
+	})
+	assert.Error(t, err, "functions of other users cannot be routed to")
+
+	svc = services.NewGatewayService(services.GatewayServiceParams{Repo: repo, AuditSvc: audit, FunctionSvc: &stubFunctionService{fn: fn}})
+	_, err = svc.CreateRoute(ctx, ports.CreateRouteParams{
+		Name: "mixed", Pattern: "/mixed",
+		Upstreams: []domain.GatewayUpstream{
+			{Type: domain.GatewayUpstreamFunction, TargetID: &fn.ID, Weight: 1},
+			{Type: domain.GatewayUpstreamURL, URL: "http://svc:8080", Weight: 1},
+		},
+	})
+	assert.Error(t, err, "function upstreams cannot be mixed with http upstreams")
+}
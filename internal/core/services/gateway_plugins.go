@@ -0,0 +1,439 @@
+package services
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/pkg/ratelimit"
+)
+
+// corsResponseHeaders are owned by the route's CORS policy; the upstream's
+// values are dropped so browsers see a single, consistent answer.
+var corsResponseHeaders = []string{
+	"Access-Control-Allow-Origin",
+	"Access-Control-Allow-Credentials",
+	"Access-Control-Expose-Headers",
+	"Access-Control-Allow-Methods",
+	"Access-Control-Allow-Headers",
+	"Access-Control-Max-Age",
+}
+
+// buildRouteHandler wraps a route's backend, a reverse proxy or a function
+// invoker, with its plugins. Requests pass CORS, authentication, rate
+// limiting, the body size limit and request header rewriting, in that order,
+// before they reach the backend.
+func buildRouteHandler(route *domain.GatewayRoute, backend http.Handler, limiter *ratelimit.IPRateLimiter) (http.Handler, error) {
+	plugins := route.Plugins
+
+	if proxy, ok := backend.(*httputil.ReverseProxy); ok {
+		if plugins.ResponseHeaders != nil || plugins.CORS != nil {
+			proxy.ModifyResponse = func(resp *http.Response) error {
+				applyResponsePlugins(resp.Header, plugins)
+				return nil
+			}
+		}
+		proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeGatewayError(w, http.StatusRequestEntityTooLarge, "request body too large")
+				return
+			}
+			if errors.Is(err, errNoHealthyUpstream) {
+				writeGatewayError(w, http.StatusServiceUnavailable, err.Error())
+				return
+			}
+			writeGatewayError(w, http.StatusBadGateway, "upstream unavailable")
+		}
+	}
+
+	handler := backend
+
+	if plugins.RequestHeaders != nil {
+		next := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rewriteHeaders(r.Header, plugins.RequestHeaders)
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	if plugins.MaxBodyBytes > 0 {
+		next := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > plugins.MaxBodyBytes {
+				writeGatewayError(w, http.StatusRequestEntityTooLarge, "request body too large")
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, plugins.MaxBodyBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	if limiter != nil {
+		next := handler
+		apiKeyAuth := plugins.Auth != nil && plugins.Auth.Type == domain.GatewayAuthAPIKey
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Only authenticated keys are trusted as the client identity;
+			// anything else is limited by IP. Keys are tracked by a hash of
+			// the whole key so keys sharing a prefix get separate budgets.
+			key := clientIP(r)
+			if apiKeyAuth {
+				if apiKey := r.Header.Get(apiKeyHeader(plugins.Auth)); apiKey != "" {
+					key = "apikey:" + gatewayAPIKeyID(apiKey)
+				}
+			}
+			if !limiter.GetLimiter(key).Allow() {
+				w.Header().Set("Retry-After", "1")
+				writeGatewayError(w, http.StatusTooManyRequests, "rate limit exceeded")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	if plugins.Auth != nil {
+		authenticate, err := newAuthenticator(plugins.Auth)
+		if err != nil {
+			return nil, err
+		}
+		next := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := authenticate(r); err != nil {
+				if plugins.Auth.Type == domain.GatewayAuthJWT {
+					w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				}
+				writeGatewayError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			if plugins.Auth.Type == domain.GatewayAuthAPIKey {
+				recordAPIKey(r.Context(), r.Header.Get(apiKeyHeader(plugins.Auth)))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	if plugins.CORS != nil {
+		handler = corsHandler(plugins.CORS, handler)
+	}
+
+	return handler, nil
+}
+
+// applyResponsePlugins drops backend CORS headers when the route has its own
+// policy and applies the route's response header rewrite.
+func applyResponsePlugins(h http.Header, plugins domain.GatewayRoutePlugins) {
+	if plugins.CORS != nil {
+		for _, name := range corsResponseHeaders {
+			h.Del(name)
+		}
+	}
+	rewriteHeaders(h, plugins.ResponseHeaders)
+}
+
+// corsHandler answers preflight requests itself and adds the policy's headers
+// to every response for an allowed origin.
+func corsHandler(cors *domain.GatewayCORSConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && origin != "" && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		if !corsOriginAllowed(cors, origin) {
+			if preflight {
+				writeGatewayError(w, http.StatusForbidden, "origin not allowed")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		if len(cors.AllowOrigins) == 1 && cors.AllowOrigins[0] == "*" && !cors.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if cors.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if len(cors.ExposeHeaders) > 0 {
+				h.Set("Access-Control-Expose-Headers", strings.Join(cors.ExposeHeaders, ", "))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		methods := cors.AllowMethods
+		if len(methods) == 0 {
+			methods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+		}
+		h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		if len(cors.AllowHeaders) > 0 {
+			h.Set("Access-Control-Allow-Headers", strings.Join(cors.AllowHeaders, ", "))
+		} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+			h.Set("Access-Control-Allow-Headers", requested)
+		}
+		if cors.MaxAgeSec > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(cors.MaxAgeSec))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func corsOriginAllowed(cors *domain.GatewayCORSConfig, origin string) bool {
+	for _, o := range cors.AllowOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// newAuthenticator returns a function that verifies a request's credentials.
+func newAuthenticator(auth *domain.GatewayAuthConfig) (func(*http.Request) error, error) {
+	switch auth.Type {
+	case domain.GatewayAuthAPIKey:
+		header := apiKeyHeader(auth)
+		hashes := make([][]byte, 0, len(auth.APIKeyHashes))
+		for _, h := range auth.APIKeyHashes {
+			b, err := hex.DecodeString(h)
+			if err != nil {
+				return nil, fmt.Errorf("invalid api key hash: %w", err)
+			}
+			hashes = append(hashes, b)
+		}
+		return func(r *http.Request) error {
+			key := r.Header.Get(header)
+			if key == "" {
+				return fmt.Errorf("missing api key")
+			}
+			sum := sha256.Sum256([]byte(key))
+			for _, h := range hashes {
+				if subtle.ConstantTimeCompare(sum[:], h) == 1 {
+					return nil
+				}
+			}
+			return fmt.Errorf("invalid api key")
+		}, nil
+
+	case domain.GatewayAuthJWT:
+		verifier, err := newJWTVerifier(auth)
+		if err != nil {
+			return nil, err
+		}
+		return func(r *http.Request) error {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				return fmt.Errorf("missing bearer token")
+			}
+			return verifier.verify(strings.TrimSpace(token), time.Now())
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("invalid gateway auth type: %s", auth.Type)
+	}
+}
+
+// jwtVerifier checks compact-serialized JWS tokens signed with one algorithm.
+type jwtVerifier struct {
+	algorithm string
+	secret    []byte
+	publicKey *rsa.PublicKey
+	issuer    string
+	audience  string
+}
+
+func newJWTVerifier(auth *domain.GatewayAuthConfig) (*jwtVerifier, error) {
+	v := &jwtVerifier{algorithm: auth.Algorithm, issuer: auth.Issuer, audience: auth.Audience}
+	switch auth.Algorithm {
+	case domain.JWTAlgorithmHS256:
+		v.secret = []byte(auth.Secret)
+	case domain.JWTAlgorithmRS256:
+		key, err := parseRSAPublicKey(auth.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		v.publicKey = key
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm: %s", auth.Algorithm)
+	}
+	return v, nil
+}
+
+func (v *jwtVerifier) verify(token string, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return fmt.Errorf("malformed token header")
+	}
+	// The algorithm is fixed by the route, never chosen by the token.
+	if header.Alg != v.algorithm {
+		return fmt.Errorf("unexpected token algorithm")
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("malformed token signature")
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	switch v.algorithm {
+	case domain.JWTAlgorithmHS256:
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write(signed)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return fmt.Errorf("invalid token signature")
+		}
+	case domain.JWTAlgorithmRS256:
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, digest[:], sig); err != nil {
+			return fmt.Errorf("invalid token signature")
+		}
+	}
+
+	var claims struct {
+		Issuer    string          `json:"iss"`
+		Audience  json.RawMessage `json:"aud"`
+		ExpiresAt *float64        `json:"exp"`
+		NotBefore *float64        `json:"nbf"`
+	}
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return fmt.Errorf("malformed token claims")
+	}
+	if claims.ExpiresAt != nil && now.Unix() >= int64(*claims.ExpiresAt) {
+		return fmt.Errorf("token expired")
+	}
+	if claims.NotBefore != nil && now.Unix() < int64(*claims.NotBefore) {
+		return fmt.Errorf("token not yet valid")
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return fmt.Errorf("invalid token issuer")
+	}
+	if v.audience != "" && !jwtAudienceContains(claims.Audience, v.audience) {
+		return fmt.Errorf("invalid token audience")
+	}
+	return nil
+}
+
+func decodeJWTSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// jwtAudienceContains handles both forms of the aud claim: a string or an array of strings.
+func jwtAudienceContains(raw json.RawMessage, audience string) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		return single == audience
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		for _, a := range list {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseRSAPublicKey accepts PKIX ("PUBLIC KEY") and PKCS #1 ("RSA PUBLIC KEY") PEM blocks.
+func parseRSAPublicKey(pemData string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return nil, fmt.Errorf("jwt public key is not PEM encoded")
+	}
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwt public key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("jwt public key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// hashGatewayAPIKeys replaces plain text API keys with their SHA-256 hashes.
+func hashGatewayAPIKeys(auth *domain.GatewayAuthConfig) {
+	for _, k := range auth.APIKeys {
+		sum := sha256.Sum256([]byte(k))
+		auth.APIKeyHashes = append(auth.APIKeyHashes, hex.EncodeToString(sum[:]))
+	}
+	auth.APIKeys = nil
+}
+
+func apiKeyHeader(auth *domain.GatewayAuthConfig) string {
+	if auth.Header != "" {
+		return auth.Header
+	}
+	return domain.DefaultGatewayAPIKeyHeader
+}
+
+func rewriteHeaders(h http.Header, rewrite *domain.GatewayHeaderRewrite) {
+	if rewrite == nil {
+		return
+	}
+	for _, name := range rewrite.Remove {
+		h.Del(name)
+	}
+	for name, value := range rewrite.Set {
+		h.Set(name, value)
+	}
+}
+
+// clientIP prefers the address resolved by the API router, which honours its
+// trusted proxy settings, over the raw peer address.
+func clientIP(r *http.Request) string {
+	if ip := appcontext.ClientIPFromContext(r.Context()); ip != "" {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func writeGatewayError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,262 @@
+package services_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newPluginGateway serves a single route in front of an upstream that echoes
+// what it received in response headers.
+func newPluginGateway(t *testing.T, route *domain.GatewayRoute) *services.GatewayService {
+	t.Helper()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Seen-Tenant", r.Header.Get("X-Tenant"))
+		w.Header().Set("X-Seen-Debug", r.Header.Get("X-Debug"))
+		w.Header().Set("X-Internal", "secret")
+		w.Header().Set("Access-Control-Allow-Origin", "https://upstream.example.com")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(upstream.Close)
+
+	route.ID = uuid.New()
+	route.PathPrefix = "/api"
+	route.PathPattern = "/api"
+	route.PatternType = "prefix"
+	route.TargetURL = upstream.URL
+
+	repo := new(MockGatewayRepo)
+	repo.On("GetAllActiveRoutes", mock.Anything).Return([]*domain.GatewayRoute{route}, nil)
+	return services.NewGatewayService(services.GatewayServiceParams{Repo: repo, AuditSvc: new(MockAuditService)})
+}
+
+func serveGateway(t *testing.T, svc *services.GatewayService, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	handler, _, ok := svc.GetProxy(req.Method, req.URL.Path)
+	require.True(t, ok)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	t.Helper()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestGatewayRateLimit(t *testing.T) {
+	t.Parallel()
+	svc := newPluginGateway(t, &domain.GatewayRoute{RateLimit: 1})
+
+	req := func(ip string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/api", nil)
+		return r.WithContext(appcontext.WithClientIP(r.Context(), ip))
+	}
+
+	assert.Equal(t, http.StatusOK, serveGateway(t, svc, req("10.0.0.1")).Code)
+	w := serveGateway(t, svc, req("10.0.0.1"))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, serveGateway(t, svc, req("10.0.0.2")).Code, "limits are per client")
+
+	// A refresh with the same limit keeps the client's budget.
+	require.NoError(t, svc.RefreshRoutes(context.Background()))
+	assert.Equal(t, http.StatusTooManyRequests, serveGateway(t, svc, req("10.0.0.1")).Code)
+}
+
+func TestGatewayRateLimitByAPIKey(t *testing.T) {
+	t.Parallel()
+	var hashes []string
+	for _, key := range []string{"tenant-a-key", "tenant-b-key"} {
+		sum := sha256.Sum256([]byte(key))
+		hashes = append(hashes, hex.EncodeToString(sum[:]))
+	}
+	svc := newPluginGateway(t, &domain.GatewayRoute{RateLimit: 1, Plugins: domain.GatewayRoutePlugins{
+		Auth: &domain.GatewayAuthConfig{Type: domain.GatewayAuthAPIKey, Header: "X-Route-Key", APIKeyHashes: hashes},
+	}})
+
+	req := func(key string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/api", nil)
+		r.Header.Set("X-Route-Key", key)
+		return r.WithContext(appcontext.WithClientIP(r.Context(), "10.0.0.1"))
+	}
+
+	assert.Equal(t, http.StatusOK, serveGateway(t, svc, req("tenant-a-key")).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serveGateway(t, svc, req("tenant-a-key")).Code)
+	assert.Equal(t, http.StatusOK, serveGateway(t, svc, req("tenant-b-key")).Code, "keys sharing a prefix are limited separately")
+}
+
+func TestGatewayAPIKeyAuth(t *testing.T) {
+	t.Parallel()
+	sum := sha256.Sum256([]byte("good-key"))
+	svc := newPluginGateway(t, &domain.GatewayRoute{Plugins: domain.GatewayRoutePlugins{
+		Auth: &domain.GatewayAuthConfig{Type: domain.GatewayAuthAPIKey, Header: "X-Route-Key", APIKeyHashes: []string{hex.EncodeToString(sum[:])}},
+	}})
+
+	r := httptest.NewRequest(http.MethodGet, "/api", nil)
+	assert.Equal(t, http.StatusUnauthorized, serveGateway(t, svc, r).Code)
+
+	r = httptest.NewRequest(http.MethodGet, "/api", nil)
+	r.Header.Set("X-Route-Key", "bad-key")
+	assert.Equal(t, http.StatusUnauthorized, serveGateway(t, svc, r).Code)
+
+	r = httptest.NewRequest(http.MethodGet, "/api", nil)
+	r.Header.Set("X-Route-Key", "good-key")
+	assert.Equal(t, http.StatusOK, serveGateway(t, svc, r).Code)
+}
+
+func TestGatewayJWTAuth(t *testing.T) {
+	t.Parallel()
+	svc := newPluginGateway(t, &domain.GatewayRoute{Plugins: domain.GatewayRoutePlugins{
+		Auth: &domain.GatewayAuthConfig{Type: domain.GatewayAuthJWT, Algorithm: domain.JWTAlgorithmHS256, Secret: "s3cret", Issuer: "issuer", Audience: "api"},
+	}})
+	now := time.Now().Unix()
+
+	cases := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"Valid", signHS256(t, "s3cret", map[string]interface{}{"iss": "issuer", "aud": []string{"api"}, "exp": now + 60}), http.StatusOK},
+		{"Missing", "", http.StatusUnauthorized},
+		{"WrongSecret", signHS256(t, "other", map[string]interface{}{"iss": "issuer", "aud": "api", "exp": now + 60}), http.StatusUnauthorized},
+		{"Expired", signHS256(t, "s3cret", map[string]interface{}{"iss": "issuer", "aud": "api", "exp": now - 60}), http.StatusUnauthorized},
+		{"WrongAudience", signHS256(t, "s3cret", map[string]interface{}{"iss": "issuer", "aud": "other", "exp": now + 60}), http.StatusUnauthorized},
+		{"AlgNone", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"issuer","aud":"api"}`)) + ".", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api", nil)
+			if tc.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			assert.Equal(t, tc.status, serveGateway(t, svc, r).Code)
+		})
+	}
+}
+
+func TestGatewayCORS(t *testing.T) {
+	t.Parallel()
+	svc := newPluginGateway(t, &domain.GatewayRoute{
+		Methods: []string{http.MethodGet},
+		Plugins: domain.GatewayRoutePlugins{
+			CORS: &domain.GatewayCORSConfig{
+				AllowOrigins:  []string{"https://app.example.com"},
+				AllowMethods:  []string{http.MethodGet},
+				ExposeHeaders: []string{"X-Seen-Tenant"},
+				MaxAgeSec:     600,
+			},
+			// Preflights must not need credentials.
+			Auth: &domain.GatewayAuthConfig{Type: domain.GatewayAuthJWT, Algorithm: domain.JWTAlgorithmHS256, Secret: "s3cret"},
+		},
+	})
+
+	r := httptest.NewRequest(http.MethodOptions, "/api", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w := serveGateway(t, svc, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+
+	r = httptest.NewRequest(http.MethodOptions, "/api", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+	r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	assert.Equal(t, http.StatusForbidden, serveGateway(t, svc, r).Code)
+
+	r = httptest.NewRequest(http.MethodGet, "/api", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	r.Header.Set("Authorization", "Bearer "+signHS256(t, "s3cret", map[string]interface{}{"sub": "u"}))
+	w = serveGateway(t, svc, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"https://app.example.com"}, w.Header().Values("Access-Control-Allow-Origin"), "the upstream's CORS headers are replaced")
+	assert.Equal(t, "X-Seen-Tenant", w.Header().Get("Access-Control-Expose-Headers"))
+}
+
+func TestGatewayTransformsAndBodyLimit(t *testing.T) {
+	t.Parallel()
+	svc := newPluginGateway(t, &domain.GatewayRoute{Plugins: domain.GatewayRoutePlugins{
+		RequestHeaders:  &domain.GatewayHeaderRewrite{Set: map[string]string{"X-Tenant": "acme"}, Remove: []string{"X-Debug"}},
+		ResponseHeaders: &domain.GatewayHeaderRewrite{Set: map[string]string{"X-Gateway": "thecloud"}, Remove: []string{"X-Internal"}},
+		MaxBodyBytes:    8,
+	}})
+
+	r := httptest.NewRequest(http.MethodPost, "/api", strings.NewReader("small"))
+	r.Header.Set("X-Debug", "1")
+	w := serveGateway(t, svc, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "small", w.Body.String())
+	assert.Equal(t, "acme", w.Header().Get("X-Seen-Tenant"))
+	assert.Empty(t, w.Header().Get("X-Seen-Debug"))
+	assert.Equal(t, "thecloud", w.Header().Get("X-Gateway"))
+	assert.Empty(t, w.Header().Get("X-Internal"))
+
+	r = httptest.NewRequest(http.MethodPost, "/api", strings.NewReader("far too large"))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serveGateway(t, svc, r).Code)
+
+	// Without a Content-Length the limit is enforced while streaming.
+	r = httptest.NewRequest(http.MethodPost, "/api", io.NopCloser(strings.NewReader("far too large")))
+	r.ContentLength = -1
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serveGateway(t, svc, r).Code)
+}
+
+func TestGatewayCreateRouteHashesAPIKeys(t *testing.T) {
+	t.Parallel()
+	repo := new(MockGatewayRepo)
+	audit := new(MockAuditService)
+	repo.On("GetAllActiveRoutes", mock.Anything).Return([]*domain.GatewayRoute{}, nil)
+	audit.On("Log", mock.Anything, mock.Anything, "gateway.route_create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	var stored domain.GatewayRoutePlugins
+	repo.On("CreateRoute", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*domain.GatewayRoute).Plugins
+		auth := *stored.Auth
+		stored.Auth = &auth
+	}).Return(nil)
+
+	svc := services.NewGatewayService(services.GatewayServiceParams{Repo: repo, AuditSvc: audit})
+	ctx := appcontext.WithUserID(context.Background(), uuid.New())
+
+	route, err := svc.CreateRoute(ctx, ports.CreateRouteParams{
+		Name: "secured", Pattern: "/secured", Target: "http://backend:8080",
+		Plugins: domain.GatewayRoutePlugins{Auth: &domain.GatewayAuthConfig{Type: domain.GatewayAuthAPIKey, APIKeys: []string{"plain"}}},
+	})
+	require.NoError(t, err)
+
+	sum := sha256.Sum256([]byte("plain"))
+	assert.Empty(t, stored.Auth.APIKeys)
+	assert.Equal(t, []string{hex.EncodeToString(sum[:])}, stored.Auth.APIKeyHashes)
+	assert.Empty(t, route.Plugins.Auth.APIKeyHashes, "credentials are not returned")
+
+	_, err = svc.CreateRoute(ctx, ports.CreateRouteParams{
+		Name: "bad", Pattern: "/bad", Target: "http://backend:8080",
+		Plugins: domain.GatewayRoutePlugins{Auth: &domain.GatewayAuthConfig{Type: domain.GatewayAuthJWT, Algorithm: domain.JWTAlgorithmRS256, PublicKey: "not a key"}},
+	})
+	assert.Error(t, err)
+}
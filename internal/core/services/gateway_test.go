@@ -21,7 +21,7 @@ func setupGatewayServiceTest(t *testing.T) (*services.GatewayService, *postgres.
 	auditRepo := postgres.NewAuditRepository(db)
 	auditSvc := services.NewAuditService(auditRepo)
 
-	svc := services.NewGatewayService(repo, auditSvc)
+	svc := services.NewGatewayService(services.GatewayServiceParams{Repo: repo, AuditSvc: auditSvc})
 	return svc, repo.(*postgres.PostgresGatewayRepository), ctx
 }
 
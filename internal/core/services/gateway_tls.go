@@ -0,0 +1,157 @@
+// Package services implements core business workflows.
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+)
+
+// checkCertificate verifies that a route certificate belongs to the caller,
+// has not expired, and names no domain another tenant's routes already serve.
+func (s *GatewayService) checkCertificate(ctx context.Context, id uuid.UUID) error {
+	if s.certSvc == nil {
+		return errors.New(errors.InvalidInput, "certificate management is not available")
+	}
+	cert, err := s.certSvc.GetCertificate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if cert.Status == domain.CertificateStatusExpired {
+		return errors.New(errors.InvalidInput, "certificate "+cert.Name+" has expired")
+	}
+
+	owner := appcontext.TenantIDFromContext(ctx)
+	if owner == uuid.Nil {
+		owner = appcontext.UserIDFromContext(ctx)
+	}
+	s.proxyMu.RLock()
+	defer s.proxyMu.RUnlock()
+	for _, d := range cert.Domains {
+		if domainClaimed(s.certOwners, strings.ToLower(d), owner) {
+			return errors.New(errors.Conflict, "domain "+d+" is already served by another tenant's route")
+		}
+	}
+	return nil
+}
+
+// loadCertificates decrypts the certificates attached to routes and indexes
+// them by domain, along with the owner serving each domain. The oldest route
+// claims a domain; another tenant's certificate for an overlapping domain is
+// not served. Routes whose certificate cannot be loaded are served without one.
+func (s *GatewayService) loadCertificates(ctx context.Context, routes []*domain.GatewayRoute) (map[string]*tls.Certificate, map[string]uuid.UUID) {
+	certs := make(map[string]*tls.Certificate)
+	owners := make(map[string]uuid.UUID)
+	if s.certSvc == nil {
+		return certs, owners
+	}
+
+	ordered := append([]*domain.GatewayRoute(nil), routes...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].CreatedAt.Before(ordered[j].CreatedAt)
+	})
+
+	loaded := make(map[uuid.UUID]bool)
+	for _, r := range ordered {
+		if r.CertificateID == nil || loaded[*r.CertificateID] {
+			continue
+		}
+		loaded[*r.CertificateID] = true
+
+		pair, err := s.certSvc.GetKeyPair(routeOwnerContext(ctx, r), *r.CertificateID)
+		if err != nil {
+			s.logger.Warn("failed to load gateway route certificate", "route_id", r.ID, "certificate_id", *r.CertificateID, "error", err)
+			continue
+		}
+		cert, err := tls.X509KeyPair([]byte(pair.CertificatePEM), []byte(pair.PrivateKeyPEM))
+		if err != nil {
+			s.logger.Warn("invalid gateway route certificate", "route_id", r.ID, "certificate_id", *r.CertificateID, "error", err)
+			continue
+		}
+		owner := r.TenantID
+		if owner == uuid.Nil {
+			owner = r.UserID
+		}
+		for _, d := range pair.Domains {
+			d = strings.ToLower(d)
+			if domainClaimed(owners, d, owner) {
+				s.logger.Warn("gateway route certificate domain is served by another tenant", "route_id", r.ID, "certificate_id", *r.CertificateID, "domain", d)
+				continue
+			}
+			certs[d] = &cert
+			owners[d] = owner
+		}
+	}
+	return certs, owners
+}
+
+// domainClaimed reports whether an owner other than owner serves name, or a
+// domain overlapping it through a wildcard.
+func domainClaimed(owners map[string]uuid.UUID, name string, owner uuid.UUID) bool {
+	for d, o := range owners {
+		if o != owner && (d == name || wildcardCovers(d, name) || wildcardCovers(name, d)) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardCovers reports whether the wildcard domain wild matches name.
+func wildcardCovers(wild, name string) bool {
+	parent, ok := strings.CutPrefix(wild, "*.")
+	if !ok {
+		return false
+	}
+	_, p, ok := strings.Cut(name, ".")
+	return ok && p == parent
+}
+
+// GetCertificate returns the route certificate for the client's SNI server
+// name, trying an exact domain before a wildcard one.
+func (s *GatewayService) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+
+	s.proxyMu.RLock()
+	defer s.proxyMu.RUnlock()
+
+	if cert, ok := s.certs[name]; ok {
+		return cert, nil
+	}
+	if _, parent, ok := strings.Cut(name, "."); ok {
+		if cert, ok := s.certs["*."+parent]; ok {
+			return cert, nil
+		}
+	}
+	return nil, fmt.Errorf("no certificate for server name %q", hello.ServerName)
+}
+
+// httpsRedirectHandler permanently redirects plain HTTP requests for routes
+// that require HTTPS to the gateway's HTTPS listener.
+func (s *GatewayService) httpsRedirectHandler(route *domain.GatewayRoute, next http.Handler) http.Handler {
+	if !route.HTTPSRedirect || s.tlsPort == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if s.tlsPort != "443" {
+			host = net.JoinHostPort(host, s.tlsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
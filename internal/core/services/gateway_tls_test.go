@@ -0,0 +1,168 @@
+package services_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// storedCertificate returns a certificate for domains as the repository holds
+// it, with its key "encrypted" by reversibleSecrets.
+func storedCertificate(t *testing.T, userID uuid.UUID, domains ...string) *domain.Certificate {
+	t.Helper()
+	notAfter := time.Now().Add(60 * 24 * time.Hour)
+	certPEM, keyPEM := selfSignedPEM(t, domains, notAfter)
+	return &domain.Certificate{
+		ID: uuid.New(), UserID: userID, Name: domains[0], Domains: domains,
+		Status: domain.CertificateStatusActive, NotAfter: notAfter,
+		CertificatePEM: certPEM, EncryptedPrivateKey: "enc:" + keyPEM,
+	}
+}
+
+func TestGatewayServesRouteCertificatesBySNI(t *testing.T) {
+	t.Parallel()
+	userID := uuid.New()
+	exact := storedCertificate(t, userID, "api.example.com")
+	wildcard := storedCertificate(t, userID, "*.example.com")
+
+	certRepo := new(MockCertificateRepo)
+	certRepo.On("GetByID", mock.Anything, exact.ID).Return(exact, nil)
+	certRepo.On("GetByID", mock.Anything, wildcard.ID).Return(wildcard, nil)
+	certSvc := services.NewCertificateService(services.CertificateServiceParams{Repo: certRepo, SecretSvc: reversibleSecrets{}})
+
+	repo := new(MockGatewayRepo)
+	repo.On("GetAllActiveRoutes", mock.Anything).Return([]*domain.GatewayRoute{
+		{ID: uuid.New(), UserID: userID, PathPrefix: "/api", PathPattern: "/api", PatternType: "prefix", TargetURL: "http://api:8080", CertificateID: &exact.ID},
+		{ID: uuid.New(), UserID: userID, PathPrefix: "/web", PathPattern: "/web", PatternType: "prefix", TargetURL: "http://web:8080", CertificateID: &wildcard.ID},
+	}, nil)
+	svc := services.NewGatewayService(services.GatewayServiceParams{Repo: repo, AuditSvc: new(MockAuditService), CertificateSvc: certSvc})
+
+	leaf := func(name string) string {
+		cert, err := svc.GetCertificate(&tls.ClientHelloInfo{ServerName: name})
+		require.NoError(t, err, name)
+		return cert.Leaf.DNSNames[0]
+	}
+	assert.Equal(t, "api.example.com", leaf("API.example.com"), "exact names win over wildcards")
+	assert.Equal(t, "*.example.com", leaf("shop.example.com"))
+
+	_, err := svc.GetCertificate(&tls.ClientHelloInfo{ServerName: "a.b.example.com"})
+	assert.Error(t, err, "wildcards cover a single label")
+	_, err = svc.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.org"})
+	assert.Error(t, err)
+}
+
+func TestGatewayHTTPSRedirect(t *testing.T) {
+	t.Parallel()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	t.Cleanup(upstream.Close)
+
+	repo := new(MockGatewayRepo)
+	repo.On("GetAllActiveRoutes", mock.Anything).Return([]*domain.GatewayRoute{
+		{ID: uuid.New(), PathPrefix: "/secure", PathPattern: "/secure", PatternType: "prefix", TargetURL: upstream.URL, HTTPSRedirect: true},
+		{ID: uuid.New(), PathPrefix: "/plain", PathPattern: "/plain", PatternType: "prefix", TargetURL: upstream.URL},
+	}, nil)
+	svc := services.NewGatewayService(services.GatewayServiceParams{Repo: repo, AuditSvc: new(MockAuditService), TLSPort: "8443"})
+
+	req := httptest.NewRequest(http.MethodPost, "http://gw.example.com:8000/secure/orders?page=2", nil)
+	w := serveGateway(t, svc, req)
+	assert.Equal(t, http.StatusPermanentRedirect, w.Code, "308 preserves the method and body")
+	assert.Equal(t, "https://gw.example.com:8443/secure/orders?page=2", w.Header().Get("Location"))
+
+	req = httptest.NewRequest(http.MethodGet, "https://gw.example.com:8443/secure/orders", nil)
+	req.TLS = &tls.ConnectionState{}
+	assert.Equal(t, http.StatusTeapot, serveGateway(t, svc, req).Code)
+
+	req = httptest.NewRequest(http.MethodGet, "http://gw.example.com:8000/plain", nil)
+	assert.Equal(t, http.StatusTeapot, serveGateway(t, svc, req).Code)
+}
+
+func TestGatewayCreateRouteCertificate(t *testing.T) {
+	t.Parallel()
+	userID := uuid.New()
+	ctx := appcontext.WithUserID(context.Background(), userID)
+	mine := storedCertificate(t, userID, "api.example.com")
+	theirs := storedCertificate(t, uuid.New(), "api.example.com")
+	expired := storedCertificate(t, userID, "old.example.com")
+	expired.Status = domain.CertificateStatusExpired
+
+	certRepo := new(MockCertificateRepo)
+	for _, c := range []*domain.Certificate{mine, theirs, expired} {
+		certRepo.On("GetByID", mock.Anything, c.ID).Return(c, nil)
+	}
+	repo := new(MockGatewayRepo)
+	repo.On("GetAllActiveRoutes", mock.Anything).Return([]*domain.GatewayRoute{}, nil)
+	repo.On("CreateRoute", mock.Anything, mock.Anything).Return(nil)
+	audit := new(MockAuditService)
+	audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	svc := services.NewGatewayService(services.GatewayServiceParams{
+		Repo: repo, AuditSvc: audit,
+		CertificateSvc: services.NewCertificateService(services.CertificateServiceParams{Repo: certRepo, SecretSvc: reversibleSecrets{}}),
+	})
+
+	route, err := svc.CreateRoute(ctx, ports.CreateRouteParams{Name: "api", Pattern: "/api", Target: "http://api:8080", CertificateID: &mine.ID, HTTPSRedirect: true})
+	require.NoError(t, err)
+	assert.Equal(t, &mine.ID, route.CertificateID)
+	assert.True(t, route.HTTPSRedirect)
+
+	_, err = svc.CreateRoute(ctx, ports.CreateRouteParams{Name: "x", Pattern: "/x", Target: "http://x:8080", CertificateID: &theirs.ID})
+	assert.True(t, errors.Is(err, errors.NotFound))
+	_, err = svc.CreateRoute(ctx, ports.CreateRouteParams{Name: "y", Pattern: "/y", Target: "http://y:8080", CertificateID: &expired.ID})
+	assert.True(t, errors.Is(err, errors.InvalidInput))
+}
+
+func TestGatewayCertificateDomainsAreOwnedByOneTenant(t *testing.T) {
+	t.Parallel()
+	ownerTenant, otherTenant := uuid.New(), uuid.New()
+	owner, other := uuid.New(), uuid.New()
+	served := storedCertificate(t, owner, "*.example.com")
+	clash := storedCertificate(t, other, "shop.example.com")
+	elsewhere := storedCertificate(t, other, "shop.example.org")
+
+	certRepo := new(MockCertificateRepo)
+	for _, c := range []*domain.Certificate{served, clash, elsewhere} {
+		certRepo.On("GetByID", mock.Anything, c.ID).Return(c, nil)
+	}
+	certSvc := services.NewCertificateService(services.CertificateServiceParams{Repo: certRepo, SecretSvc: reversibleSecrets{}})
+
+	// A later route of another tenant does not take over a served domain.
+	now := time.Now()
+	repo := new(MockGatewayRepo)
+	repo.On("GetAllActiveRoutes", mock.Anything).Return([]*domain.GatewayRoute{
+		{ID: uuid.New(), UserID: other, TenantID: otherTenant, PathPrefix: "/shop", PathPattern: "/shop", PatternType: "prefix", TargetURL: "http://shop:8080", CertificateID: &clash.ID, Priority: 10, CreatedAt: now},
+		{ID: uuid.New(), UserID: owner, TenantID: ownerTenant, PathPrefix: "/web", PathPattern: "/web", PatternType: "prefix", TargetURL: "http://web:8080", CertificateID: &served.ID, CreatedAt: now.Add(-time.Hour)},
+	}, nil)
+	repo.On("CreateRoute", mock.Anything, mock.Anything).Return(nil)
+	audit := new(MockAuditService)
+	audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	svc := services.NewGatewayService(services.GatewayServiceParams{Repo: repo, AuditSvc: audit, CertificateSvc: certSvc})
+
+	cert, err := svc.GetCertificate(&tls.ClientHelloInfo{ServerName: "shop.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "*.example.com", cert.Leaf.DNSNames[0])
+
+	// New routes may not attach a certificate for another tenant's domain.
+	otherCtx := appcontext.WithTenantID(appcontext.WithUserID(context.Background(), other), otherTenant)
+	_, err = svc.CreateRoute(otherCtx, ports.CreateRouteParams{Name: "shop", Pattern: "/shop2", Target: "http://shop:8080", CertificateID: &clash.ID})
+	assert.True(t, errors.Is(err, errors.Conflict))
+	_, err = svc.CreateRoute(otherCtx, ports.CreateRouteParams{Name: "org", Pattern: "/org", Target: "http://org:8080", CertificateID: &elsewhere.ID})
+	assert.NoError(t, err)
+
+	ownerCtx := appcontext.WithTenantID(appcontext.WithUserID(context.Background(), owner), ownerTenant)
+	_, err = svc.CreateRoute(ownerCtx, ports.CreateRouteParams{Name: "web2", Pattern: "/web2", Target: "http://web:8080", CertificateID: &served.ID})
+	assert.NoError(t, err, "a tenant may reuse its own domains")
+}
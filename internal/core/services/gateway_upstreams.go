@@ -0,0 +1,448 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/platform"
+)
+
+const (
+	// upstreamResolveTTL is how long resolved load balancer and deployment
+	// addresses are reused before they are looked up again.
+	upstreamResolveTTL = 10 * time.Second
+
+	defaultBreakerThreshold = 5
+	defaultBreakerReset     = 30 * time.Second
+)
+
+// errNoHealthyUpstream is returned when every upstream target is unresolvable
+// or has an open circuit.
+var errNoHealthyUpstream = errors.New("no healthy upstream")
+
+// upstreamTarget is one resolved backend address and its share of the traffic.
+type upstreamTarget struct {
+	url      *url.URL
+	weight   float64
+	breaker  *platform.CircuitBreaker
+	upstream int // index into the route's upstreams
+}
+
+// breakerSet holds the circuit breakers of a route's targets, keyed by target
+// URL. It outlives route refreshes and re-resolution so circuit state is kept.
+type breakerSet struct {
+	mu        sync.Mutex
+	threshold int
+	reset     time.Duration
+	breakers  map[string]*platform.CircuitBreaker
+}
+
+func newBreakerSet(policy domain.GatewayTrafficPolicy) *breakerSet {
+	threshold, reset := defaultBreakerThreshold, defaultBreakerReset
+	if cb := policy.CircuitBreaker; cb != nil {
+		threshold, reset = cb.FailureThreshold, time.Duration(cb.ResetTimeoutSec)*time.Second
+	}
+	return &breakerSet{threshold: threshold, reset: reset, breakers: make(map[string]*platform.CircuitBreaker)}
+}
+
+func (b *breakerSet) matches(policy domain.GatewayTrafficPolicy) bool {
+	other := newBreakerSet(policy)
+	return other.threshold == b.threshold && other.reset == b.reset
+}
+
+func (b *breakerSet) get(target string) *platform.CircuitBreaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cb, ok := b.breakers[target]
+	if !ok {
+		cb = platform.NewCircuitBreaker(b.threshold, b.reset)
+		b.breakers[target] = cb
+	}
+	return cb
+}
+
+// upstreamResolveFunc turns a load balancer or deployment upstream into target URLs.
+type upstreamResolveFunc func(ctx context.Context, route *domain.GatewayRoute, upstream domain.GatewayUpstream) ([]*url.URL, error)
+
+// upstreamPool is the reverse proxy transport of a route. Each attempt goes to
+// a weighted random target whose circuit is closed; failed idempotent requests
+// are retried on another target.
+type upstreamPool struct {
+	route     *domain.GatewayRoute
+	transport http.RoundTripper
+	resolve   upstreamResolveFunc
+	breakers  *breakerSet
+	logger    *slog.Logger
+	timeout   time.Duration
+	retries   int
+	static    bool // no upstream needs resolving
+
+	mu         sync.Mutex
+	targets    []*upstreamTarget
+	resolvedAt time.Time
+}
+
+func newUpstreamPool(route *domain.GatewayRoute, breakers *breakerSet, resolve upstreamResolveFunc, logger *slog.Logger) (*upstreamPool, error) {
+	p := &upstreamPool{
+		route:     route,
+		transport: http.DefaultTransport,
+		resolve:   resolve,
+		breakers:  breakers,
+		logger:    logger,
+		timeout:   time.Duration(route.TrafficPolicy.TimeoutMs) * time.Millisecond,
+		retries:   route.TrafficPolicy.Retries,
+		static:    true,
+	}
+	if len(route.Upstreams) == 0 {
+		// Single-target routes predate upstreams.
+		target, err := url.Parse(route.TargetURL)
+		if err != nil {
+			return nil, err
+		}
+		p.targets = []*upstreamTarget{{url: target, weight: 1, breaker: breakers.get(target.String())}}
+		p.resolvedAt = time.Now()
+		return p, nil
+	}
+	for _, u := range route.Upstreams {
+		if u.Type != domain.GatewayUpstreamURL {
+			p.static = false
+			continue
+		}
+		if _, err := url.Parse(u.URL); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// currentTargets returns the resolved targets, resolving again once the cached
+// addresses are older than upstreamResolveTTL. Static routes resolve once.
+func (p *upstreamPool) currentTargets(ctx context.Context) []*upstreamTarget {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.resolvedAt.IsZero() && (p.static || time.Since(p.resolvedAt) < upstreamResolveTTL) {
+		return p.targets
+	}
+
+	var targets []*upstreamTarget
+	for i, u := range p.route.Upstreams {
+		if u.Weight <= 0 {
+			continue
+		}
+		var urls []*url.URL
+		if u.Type == domain.GatewayUpstreamURL {
+			target, _ := url.Parse(u.URL)
+			urls = []*url.URL{target}
+		} else {
+			var err error
+			urls, err = p.resolve(ctx, p.route, u)
+			if err != nil {
+				p.logger.Warn("failed to resolve gateway upstream", "route_id", p.route.ID, "type", u.Type, "target_id", u.TargetID, "error", err)
+				// Keep serving the addresses resolved last time.
+				urls = p.previousURLs(i)
+			}
+		}
+		for _, target := range urls {
+			// Replicas of one upstream share its weight.
+			targets = append(targets, &upstreamTarget{
+				url:      target,
+				weight:   float64(u.Weight) / float64(len(urls)),
+				breaker:  p.breakers.get(target.String()),
+				upstream: i,
+			})
+		}
+	}
+
+	p.targets = targets
+	p.resolvedAt = time.Now()
+	return targets
+}
+
+// previousURLs returns the addresses an upstream resolved to last time.
+func (p *upstreamPool) previousURLs(upstream int) []*url.URL {
+	var urls []*url.URL
+	for _, t := range p.targets {
+		if t.upstream == upstream {
+			urls = append(urls, t.url)
+		}
+	}
+	return urls
+}
+
+// RoundTrip implements http.RoundTripper.
+func (p *upstreamPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	targets := p.currentTargets(req.Context())
+
+	attempts := 1
+	if retryableRequest(req) {
+		attempts += p.retries
+	}
+
+	tried := make(map[*upstreamTarget]bool)
+	open := make(map[*upstreamTarget]bool)
+	var lastResp *http.Response
+	var lastErr error
+
+	for attempt := 0; attempt < attempts; {
+		t := pickUpstreamTarget(targets, tried, open)
+		if t == nil {
+			break
+		}
+		tried[t] = true
+		recordUpstream(req.Context(), t.url.Host)
+
+		var resp *http.Response
+		err := t.breaker.Execute(func() error {
+			var err error
+			resp, err = p.roundTripTarget(req, t)
+			if err != nil {
+				return err
+			}
+			if resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout {
+				return fmt.Errorf("upstream %s returned %d", t.url.Host, resp.StatusCode)
+			}
+			return nil
+		})
+		if errors.Is(err, platform.ErrCircuitOpen) {
+			// Skipping an open circuit does not use up an attempt.
+			open[t] = true
+			continue
+		}
+		attempt++
+		if err == nil {
+			if lastResp != nil {
+				_ = lastResp.Body.Close()
+			}
+			return resp, nil
+		}
+		if resp != nil {
+			if lastResp != nil {
+				_ = lastResp.Body.Close()
+			}
+			lastResp = resp
+		}
+		lastErr = err
+	}
+
+	// Pass the last upstream error response through rather than masking it.
+	if lastResp != nil {
+		return lastResp, nil
+	}
+	if lastErr == nil {
+		lastErr = errNoHealthyUpstream
+	}
+	return nil, lastErr
+}
+
+// roundTripTarget sends one attempt to a target. The timeout bounds the wait
+// for response headers; the body may stream for as long as it takes.
+func (p *upstreamPool) roundTripTarget(req *http.Request, t *upstreamTarget) (*http.Response, error) {
+	out := upstreamRequest(req, t.url)
+	if p.timeout <= 0 {
+		return p.transport.RoundTrip(out)
+	}
+
+	ctx, cancel := context.WithCancel(out.Context())
+	timer := time.AfterFunc(p.timeout, cancel)
+	resp, err := p.transport.RoundTrip(out.WithContext(ctx))
+	if !timer.Stop() {
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		cancel()
+		return nil, fmt.Errorf("upstream %s timed out after %s", t.url.Host, p.timeout)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases an attempt's context once its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// upstreamRequest addresses a copy of the outgoing request to a target,
+// joining the target's base path and query with the request's.
+func upstreamRequest(req *http.Request, target *url.URL) *http.Request {
+	out := req.Clone(req.Context())
+	if req.GetBody != nil && req.Body != nil && req.Body != http.NoBody {
+		if body, err := req.GetBody(); err == nil {
+			out.Body = body
+		}
+	}
+	out.URL.Scheme = target.Scheme
+	out.URL.Host = target.Host
+	out.URL.Path = joinUpstreamPath(target.Path, req.URL.Path)
+	out.URL.RawPath = ""
+	switch {
+	case target.RawQuery == "":
+	case req.URL.RawQuery == "":
+		out.URL.RawQuery = target.RawQuery
+	default:
+		out.URL.RawQuery = target.RawQuery + "&" + req.URL.RawQuery
+	}
+	out.Host = target.Host
+	return out
+}
+
+func joinUpstreamPath(base, path string) string {
+	switch {
+	case base == "":
+		return path
+	case strings.HasSuffix(base, "/") && strings.HasPrefix(path, "/"):
+		return base + path[1:]
+	case !strings.HasSuffix(base, "/") && !strings.HasPrefix(path, "/"):
+		return base + "/" + path
+	default:
+		return base + path
+	}
+}
+
+// retryableRequest reports whether a request may be sent again: its method
+// must be idempotent and its body, if any, must be replayable.
+func retryableRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// pickUpstreamTarget chooses a target by weight, preferring ones not tried yet
+// for this request. Targets with an open circuit are skipped.
+func pickUpstreamTarget(targets []*upstreamTarget, tried, open map[*upstreamTarget]bool) *upstreamTarget {
+	var fresh, usable []*upstreamTarget
+	for _, t := range targets {
+		if open[t] || t.weight <= 0 {
+			continue
+		}
+		usable = append(usable, t)
+		if !tried[t] {
+			fresh = append(fresh, t)
+		}
+	}
+	if len(fresh) > 0 {
+		usable = fresh
+	}
+	if len(usable) == 0 {
+		return nil
+	}
+
+	total := 0.0
+	for _, t := range usable {
+		total += t.weight
+	}
+	n := rand.Float64() * total //nolint:gosec // traffic splitting, not security sensitive
+	for _, t := range usable {
+		n -= t.weight
+		if n < 0 {
+			return t
+		}
+	}
+	return usable[len(usable)-1]
+}
+
+// resolveUpstream looks up the addresses behind a load balancer or deployment
+// upstream, scoped to the route owner and tenant.
+func (s *GatewayService) resolveUpstream(ctx context.Context, route *domain.GatewayRoute, u domain.GatewayUpstream) ([]*url.URL, error) {
+	ctx = routeOwnerContext(ctx, route)
+
+	switch u.Type {
+	case domain.GatewayUpstreamLB:
+		if s.lbRepo == nil {
+			return nil, fmt.Errorf("load balancer upstreams are not supported")
+		}
+		lb, err := s.lbRepo.GetByID(ctx, *u.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		if lb == nil || lb.IP == "" {
+			return nil, fmt.Errorf("load balancer %s has no ip", u.TargetID)
+		}
+		port := lb.Port
+		if u.Port > 0 {
+			port = u.Port
+		}
+		return []*url.URL{{Scheme: "http", Host: net.JoinHostPort(lb.IP, strconv.Itoa(port))}}, nil
+
+	case domain.GatewayUpstreamDeployment:
+		if s.containerRepo == nil || s.instanceRepo == nil {
+			return nil, fmt.Errorf("deployment upstreams are not supported")
+		}
+		dep, err := s.containerRepo.GetDeploymentByID(ctx, *u.TargetID, route.UserID)
+		if err != nil {
+			return nil, err
+		}
+		port := u.Port
+		if port == 0 {
+			port = deploymentContainerPort(dep.Ports)
+		}
+		ids, err := s.containerRepo.GetContainers(ctx, dep.ID)
+		if err != nil {
+			return nil, err
+		}
+		var urls []*url.URL
+		for _, id := range ids {
+			inst, err := s.instanceRepo.GetByID(ctx, id)
+			if err != nil || inst.PrivateIP == "" || inst.Status != domain.StatusRunning {
+				continue
+			}
+			urls = append(urls, &url.URL{Scheme: "http", Host: net.JoinHostPort(inst.PrivateIP, strconv.Itoa(port))})
+		}
+		return urls, nil
+
+	default:
+		return nil, fmt.Errorf("invalid upstream type: %s", u.Type)
+	}
+}
+
+// routeOwnerContext scopes ctx to the user and tenant that own a route, so
+// backend lookups see exactly what the route's creator could.
+func routeOwnerContext(ctx context.Context, route *domain.GatewayRoute) context.Context {
+	ctx = appcontext.WithUserID(ctx, route.UserID)
+	if route.TenantID != uuid.Nil {
+		ctx = appcontext.WithTenantID(ctx, route.TenantID)
+	}
+	return ctx
+}
+
+// deploymentContainerPort returns the container side of a deployment's first
+// "host:container" port mapping, or 80.
+func deploymentContainerPort(ports string) int {
+	first := strings.TrimSpace(strings.Split(ports, ",")[0])
+	if first == "" {
+		return 80
+	}
+	parts := strings.Split(first, ":")
+	port, err := strconv.Atoi(strings.TrimSpace(parts[len(parts)-1]))
+	if err != nil || port <= 0 {
+		return 80
+	}
+	return port
+}
@@ -0,0 +1,233 @@
+package services_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// countingUpstream is a test backend that counts its requests.
+type countingUpstream struct {
+	*httptest.Server
+	hits atomic.Int64
+}
+
+func newCountingUpstream(t *testing.T, status int, delay time.Duration) *countingUpstream {
+	t.Helper()
+	u := &countingUpstream{}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.hits.Add(1)
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	t.Cleanup(u.Close)
+	return u
+}
+
+func newUpstreamGateway(t *testing.T, route *domain.GatewayRoute, params services.GatewayServiceParams) *services.GatewayService {
+	t.Helper()
+	route.ID = uuid.New()
+	route.PathPrefix = "/api"
+	route.PathPattern = "/api"
+	route.PatternType = "prefix"
+
+	repo := new(MockGatewayRepo)
+	repo.On("GetAllActiveRoutes", mock.Anything).Return([]*domain.GatewayRoute{route}, nil)
+	params.Repo = repo
+	params.AuditSvc = new(MockAuditService)
+	return services.NewGatewayService(params)
+}
+
+func urlUpstream(u *countingUpstream, weight int) domain.GatewayUpstream {
+	return domain.GatewayUpstream{Type: domain.GatewayUpstreamURL, URL: u.URL, Weight: weight}
+}
+
+func TestGatewayWeightedUpstreams(t *testing.T) {
+	t.Parallel()
+	stable := newCountingUpstream(t, http.StatusOK, 0)
+	canary := newCountingUpstream(t, http.StatusOK, 0)
+	drained := newCountingUpstream(t, http.StatusOK, 0)
+	svc := newUpstreamGateway(t, &domain.GatewayRoute{
+		Upstreams: []domain.GatewayUpstream{urlUpstream(stable, 3), urlUpstream(canary, 1), urlUpstream(drained, 0)},
+	}, services.GatewayServiceParams{})
+
+	for i := 0; i < 400; i++ {
+		w := serveGateway(t, svc, httptest.NewRequest(http.MethodGet, "/api/items", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "/api/items", w.Body.String())
+	}
+
+	assert.Zero(t, drained.hits.Load(), "a weight of 0 receives no traffic")
+	assert.InDelta(t, 300, stable.hits.Load(), 60)
+	assert.InDelta(t, 100, canary.hits.Load(), 60)
+}
+
+func TestGatewayRetriesIdempotentRequests(t *testing.T) {
+	t.Parallel()
+	bad := newCountingUpstream(t, http.StatusServiceUnavailable, 0)
+	good := newCountingUpstream(t, http.StatusOK, 0)
+	svc := newUpstreamGateway(t, &domain.GatewayRoute{
+		Upstreams: []domain.GatewayUpstream{urlUpstream(bad, 1), urlUpstream(good, 1)},
+		TrafficPolicy: domain.GatewayTrafficPolicy{
+			Retries:        1,
+			CircuitBreaker: &domain.GatewayCircuitBreakerConfig{FailureThreshold: 1000, ResetTimeoutSec: 60},
+		},
+	}, services.GatewayServiceParams{})
+
+	for i := 0; i < 20; i++ {
+		w := serveGateway(t, svc, httptest.NewRequest(http.MethodGet, "/api", nil))
+		assert.Equal(t, http.StatusOK, w.Code, "a failed GET is retried on the other upstream")
+	}
+
+	posts := 0
+	for i := 0; i < 40; i++ {
+		w := serveGateway(t, svc, httptest.NewRequest(http.MethodPost, "/api", strings.NewReader("x")))
+		if w.Code == http.StatusServiceUnavailable {
+			posts++
+		}
+	}
+	assert.Positive(t, posts, "POSTs are not retried, so the upstream's 503 is passed through")
+}
+
+func TestGatewayCircuitBreaker(t *testing.T) {
+	t.Parallel()
+	bad := newCountingUpstream(t, http.StatusBadGateway, 0)
+	good := newCountingUpstream(t, http.StatusOK, 0)
+	svc := newUpstreamGateway(t, &domain.GatewayRoute{
+		Upstreams:     []domain.GatewayUpstream{urlUpstream(bad, 1), urlUpstream(good, 1)},
+		TrafficPolicy: domain.GatewayTrafficPolicy{CircuitBreaker: &domain.GatewayCircuitBreakerConfig{FailureThreshold: 2, ResetTimeoutSec: 60}},
+	}, services.GatewayServiceParams{})
+
+	for i := 0; i < 50; i++ {
+		serveGateway(t, svc, httptest.NewRequest(http.MethodGet, "/api", nil))
+	}
+	assert.LessOrEqual(t, bad.hits.Load(), int64(2), "the circuit opens after two failures")
+
+	// Circuit state survives a route refresh.
+	require.NoError(t, svc.RefreshRoutes(context.Background()))
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, http.StatusOK, serveGateway(t, svc, httptest.NewRequest(http.MethodGet, "/api", nil)).Code)
+	}
+	assert.LessOrEqual(t, bad.hits.Load(), int64(2))
+}
+
+func TestGatewayNoHealthyUpstream(t *testing.T) {
+	t.Parallel()
+	bad := newCountingUpstream(t, http.StatusBadGateway, 0)
+	svc := newUpstreamGateway(t, &domain.GatewayRoute{
+		TargetURL:     bad.URL,
+		TrafficPolicy: domain.GatewayTrafficPolicy{CircuitBreaker: &domain.GatewayCircuitBreakerConfig{FailureThreshold: 1, ResetTimeoutSec: 60}},
+	}, services.GatewayServiceParams{})
+
+	assert.Equal(t, http.StatusBadGateway, serveGateway(t, svc, httptest.NewRequest(http.MethodGet, "/api", nil)).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serveGateway(t, svc, httptest.NewRequest(http.MethodGet, "/api", nil)).Code)
+	assert.Equal(t, int64(1), bad.hits.Load())
+}
+
+func TestGatewayUpstreamTimeout(t *testing.T) {
+	t.Parallel()
+	slow := newCountingUpstream(t, http.StatusOK, 300*time.Millisecond)
+	fast := newCountingUpstream(t, http.StatusOK, 0)
+
+	svc := newUpstreamGateway(t, &domain.GatewayRoute{
+		Upstreams:     []domain.GatewayUpstream{urlUpstream(slow, 1)},
+		TrafficPolicy: domain.GatewayTrafficPolicy{TimeoutMs: 50},
+	}, services.GatewayServiceParams{})
+	start := time.Now()
+	assert.Equal(t, http.StatusBadGateway, serveGateway(t, svc, httptest.NewRequest(http.MethodGet, "/api", nil)).Code)
+	assert.Less(t, time.Since(start), 250*time.Millisecond)
+
+	svc = newUpstreamGateway(t, &domain.GatewayRoute{
+		Upstreams:     []domain.GatewayUpstream{urlUpstream(slow, 1), urlUpstream(fast, 1)},
+		TrafficPolicy: domain.GatewayTrafficPolicy{TimeoutMs: 50, Retries: 1},
+	}, services.GatewayServiceParams{})
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, serveGateway(t, svc, httptest.NewRequest(http.MethodGet, "/api", nil)).Code)
+	}
+}
+
+func TestGatewayResolvesLBAndDeploymentUpstreams(t *testing.T) {
+	t.Parallel()
+	backend := newCountingUpstream(t, http.StatusOK, 0)
+	host, portStr, err := net.SplitHostPort(backend.Listener.Addr().String())
+	require.NoError(t, err)
+	port, _ := strconv.Atoi(portStr)
+
+	lbID, depID, instID := uuid.New(), uuid.New(), uuid.New()
+	lbRepo := new(MockLBRepo)
+	lbRepo.On("GetByID", mock.Anything, lbID).Return(&domain.LoadBalancer{ID: lbID, IP: host, Port: port}, nil)
+	containerRepo := new(MockContainerRepo)
+	containerRepo.On("GetDeploymentByID", mock.Anything, depID, mock.Anything).Return(&domain.Deployment{ID: depID, Ports: "8080:" + portStr}, nil)
+	containerRepo.On("GetContainers", mock.Anything, depID).Return([]uuid.UUID{instID}, nil)
+	instanceRepo := new(MockInstanceRepo)
+	instanceRepo.On("GetByID", mock.Anything, instID).Return(&domain.Instance{ID: instID, PrivateIP: host, Status: domain.StatusRunning}, nil)
+	params := services.GatewayServiceParams{LBRepo: lbRepo, ContainerRepo: containerRepo, InstanceRepo: instanceRepo}
+
+	for _, upstream := range []domain.GatewayUpstream{
+		{Type: domain.GatewayUpstreamLB, TargetID: &lbID, Weight: 1},
+		{Type: domain.GatewayUpstreamDeployment, TargetID: &depID, Weight: 1},
+	} {
+		svc := newUpstreamGateway(t, &domain.GatewayRoute{UserID: uuid.New(), Upstreams: []domain.GatewayUpstream{upstream}}, params)
+		assert.Equal(t, http.StatusOK, serveGateway(t, svc, httptest.NewRequest(http.MethodGet, "/api", nil)).Code, string(upstream.Type))
+	}
+	assert.Equal(t, int64(2), backend.hits.Load())
+}
+
+func TestGatewayCreateRouteUpstreams(t *testing.T) {
+	t.Parallel()
+	repo := new(MockGatewayRepo)
+	audit := new(MockAuditService)
+	repo.On("GetAllActiveRoutes", mock.Anything).Return([]*domain.GatewayRoute{}, nil)
+	repo.On("CreateRoute", mock.Anything, mock.Anything).Return(nil)
+	audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	lbRepo := new(MockLBRepo)
+	missing := uuid.New()
+	lbRepo.On("GetByID", mock.Anything, missing).Return(nil, assert.AnError)
+
+	svc := services.NewGatewayService(services.GatewayServiceParams{Repo: repo, AuditSvc: audit, LBRepo: lbRepo})
+	ctx := appcontext.WithTenantID(appcontext.WithUserID(context.Background(), uuid.New()), uuid.New())
+
+	route, err := svc.CreateRoute(ctx, ports.CreateRouteParams{
+		Name: "split", Pattern: "/split",
+		Upstreams: []domain.GatewayUpstream{
+			{Type: domain.GatewayUpstreamURL, URL: "http://v1:8080"},
+			{Type: domain.GatewayUpstreamURL, URL: "http://v2:8080"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, route.Upstreams[0].Weight, "unweighted upstreams share traffic equally")
+	assert.Equal(t, appcontext.TenantIDFromContext(ctx), route.TenantID)
+
+	_, err = svc.CreateRoute(ctx, ports.CreateRouteParams{Name: "none", Pattern: "/none"})
+	assert.Error(t, err, "a target or upstream is required")
+
+	_, err = svc.CreateRoute(ctx, ports.CreateRouteParams{
+		Name: "lb", Pattern: "/lb",
+		Upstreams: []domain.GatewayUpstream{{Type: domain.GatewayUpstreamLB, TargetID: &missing}},
+	})
+	assert.Error(t, err)
+
+	_, err = svc.CreateRoute(ctx, ports.CreateRouteParams{
+		Name: "retries", Pattern: "/retries", Target: "http://v1:8080",
+		TrafficPolicy: domain.GatewayTrafficPolicy{Retries: domain.MaxGatewayRetries + 1},
+	})
+	assert.Error(t, err)
+}
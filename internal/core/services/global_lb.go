@@ -13,6 +13,9 @@ import (
 	"github.com/poyrazk/thecloud/internal/errors"
 )
 
+// maxHealthCheckHistory caps how many health check results are returned per request.
+const maxHealthCheckHistory = 100
+
 // GlobalLBService coordinates multi-region traffic distribution via GeoDNS.
 type GlobalLBService struct {
 	repo     ports.GlobalLBRepository
@@ -194,13 +197,7 @@ func (s *GlobalLBService) AddEndpoint(ctx context.Context, glbID uuid.UUID, regi
 	glb.Endpoints = append(glb.Endpoints, ep)
 
 	// Refresh DNS using the already loaded (and updated) GLB
-	// We convert []*domain.GlobalEndpoint to []domain.GlobalEndpoint as expected by CreateGeoRecord
-	eps := make([]domain.GlobalEndpoint, len(glb.Endpoints))
-	for i, e := range glb.Endpoints {
-		eps[i] = *e
-	}
-
-	if err := s.geoDNS.CreateGeoRecord(ctx, glb.Hostname, eps); err != nil {
+	if err := s.geoDNS.CreateGeoRecord(ctx, glb.Hostname, glb.ServingEndpoints(glb.Endpoints)); err != nil {
 		s.logger.Error("failed to update geo dns", "hostname", glb.Hostname, "error", err)
 	}
 
@@ -243,12 +240,7 @@ func (s *GlobalLBService) RemoveEndpoint(ctx context.Context, glbID, endpointID
 	// 3. Sync DNS
 	updatedGLB, err := s.Get(ctx, glb.ID)
 	if err == nil {
-		eps := make([]domain.GlobalEndpoint, len(updatedGLB.Endpoints))
-		for i, e := range updatedGLB.Endpoints {
-			eps[i] = *e
-		}
-
-		if err := s.geoDNS.CreateGeoRecord(ctx, updatedGLB.Hostname, eps); err != nil {
+		if err := s.geoDNS.CreateGeoRecord(ctx, updatedGLB.Hostname, updatedGLB.ServingEndpoints(updatedGLB.Endpoints)); err != nil {
 			s.logger.Error("failed to update geo dns after endpoint removal", "hostname", updatedGLB.Hostname, "error", err)
 		}
 	}
@@ -267,3 +259,23 @@ func (s *GlobalLBService) ListEndpoints(ctx context.Context, glbID uuid.UUID) ([
 	}
 	return s.repo.ListEndpoints(ctx, glbID)
 }
+
+func (s *GlobalLBService) GetEndpointHealth(ctx context.Context, glbID, endpointID uuid.UUID, limit int) ([]*domain.GlobalEndpointHealthCheck, error) {
+	// Verify ownership and existence
+	if _, err := s.Get(ctx, glbID); err != nil {
+		return nil, err
+	}
+
+	ep, err := s.repo.GetEndpointByID(ctx, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	if ep == nil || ep.GlobalLBID != glbID {
+		return nil, errors.New(errors.NotFound, "endpoint not found")
+	}
+
+	if limit <= 0 || limit > maxHealthCheckHistory {
+		limit = maxHealthCheckHistory
+	}
+	return s.repo.ListHealthChecks(ctx, endpointID, limit)
+}
@@ -172,3 +172,34 @@ func TestGlobalLBRemoveEndpoint(t *testing.T) {
 		assert.Len(t, dnsRecs, 0)
 	})
 }
+
+func TestGlobalLBGetEndpointHealth(t *testing.T) {
+	t.Parallel()
+	svc, repo, _, _ := setupGlobalLBTest(t)
+	ctx := appcontext.WithUserID(context.Background(), uuid.New())
+
+	glb, err := svc.Create(ctx, "health-test", "health.test.com", domain.RoutingFailover, domain.GlobalHealthCheckConfig{Protocol: "TCP"})
+	require.NoError(t, err)
+	ip := "1.2.3.4"
+	ep, err := svc.AddEndpoint(ctx, glb.ID, "us-east-1", "IP", nil, &ip, 1, 1)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, repo.RecordHealthCheck(ctx, &domain.GlobalEndpointHealthCheck{ID: uuid.New(), EndpointID: ep.ID, GlobalLBID: glb.ID, Healthy: i%2 == 0}))
+	}
+
+	t.Run("returns newest first", func(t *testing.T) {
+		checks, err := svc.GetEndpointHealth(ctx, glb.ID, ep.ID, 2)
+		require.NoError(t, err)
+		require.Len(t, checks, 2)
+		assert.True(t, checks[0].Healthy)
+		assert.False(t, checks[1].Healthy)
+	})
+
+	t.Run("endpoint of another glb", func(t *testing.T) {
+		other, err := svc.Create(ctx, "other", "other.test.com", domain.RoutingLatency, domain.GlobalHealthCheckConfig{})
+		require.NoError(t, err)
+		_, err = svc.GetEndpointHealth(ctx, other.ID, ep.ID, 10)
+		assert.Error(t, err)
+	})
+}
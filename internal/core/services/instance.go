@@ -34,6 +34,8 @@ type InstanceService struct {
 	subnetRepo       ports.SubnetRepository
 	volumeRepo       ports.VolumeRepository
 	instanceTypeRepo ports.InstanceTypeRepository
+	natRepo          ports.NATGatewayRepository
+	sgSvc            ports.SecurityGroupService
 	compute          ports.ComputeBackend
 	network          ports.NetworkBackend
 	eventSvc         ports.EventService
@@ -55,6 +57,8 @@ type InstanceServiceParams struct {
 	SubnetRepo       ports.SubnetRepository
 	VolumeRepo       ports.VolumeRepository
 	InstanceTypeRepo ports.InstanceTypeRepository
+	NATRepo          ports.NATGatewayRepository // Optional
+	SecurityGroupSvc ports.SecurityGroupService // Optional
 	Compute          ports.ComputeBackend
 	Network          ports.NetworkBackend
 	EventSvc         ports.EventService
@@ -76,6 +80,8 @@ func NewInstanceService(params InstanceServiceParams) *InstanceService {
 		subnetRepo:       params.SubnetRepo,
 		volumeRepo:       params.VolumeRepo,
 		instanceTypeRepo: params.InstanceTypeRepo,
+		natRepo:          params.NATRepo,
+		sgSvc:            params.SecurityGroupSvc,
 		compute:          params.Compute,
 		network:          params.Network,
 		eventSvc:         params.EventSvc,
@@ -296,6 +302,7 @@ func (s *InstanceService) Provision(ctx context.Context, job domain.ProvisionJob
 	if err != nil {
 		return err
 	}
+	storedIPs := instanceAddresses(inst)
 
 	// 1. Resolve Networking
 	networkID, err := s.provisionNetwork(ctx, inst)
@@ -359,7 +366,7 @@ func (s *InstanceService) Provision(ctx context.Context, job domain.ProvisionJob
 	}
 
 	// 4. Finalize
-	return s.finalizeProvision(ctx, inst, containerID, attachedVolumes)
+	return s.finalizeProvision(ctx, inst, containerID, attachedVolumes, storedIPs)
 }
 func (s *InstanceService) provisionNetwork(ctx context.Context, inst *domain.Instance) (string, error) {
 	if s.compute.Type() == "noop" && inst.VpcID == nil && inst.SubnetID == nil {
@@ -367,17 +374,18 @@ func (s *InstanceService) provisionNetwork(ctx context.Context, inst *domain.Ins
 		return "", nil
 	}
 
-	networkID, allocatedIP, ovsPort, err := s.resolveNetworkConfig(ctx, inst.VpcID, inst.SubnetID)
+	networkID, allocatedIP, allocatedIPv6, ovsPort, err := s.resolveNetworkConfig(ctx, inst.VpcID, inst.SubnetID)
 	if err != nil {
 		return "", err
 	}
 
 	inst.PrivateIP = allocatedIP
+	inst.PrivateIPv6 = allocatedIPv6
 	inst.OvsPort = ovsPort
 	return networkID, nil
 }
 
-func (s *InstanceService) finalizeProvision(ctx context.Context, inst *domain.Instance, containerID string, attachedVolumes []*domain.Volume) error {
+func (s *InstanceService) finalizeProvision(ctx context.Context, inst *domain.Instance, containerID string, attachedVolumes []*domain.Volume, storedIPs []string) error {
 	if err := s.plumbNetwork(ctx, inst, containerID); err != nil {
 		s.logger.Warn("failed to plumb network", "error", err)
 	}
@@ -407,6 +415,13 @@ func (s *InstanceService) finalizeProvision(ctx context.Context, inst *domain.In
 		return err
 	}
 
+	// Security groups attached while the instance was pending match its old addresses.
+	if s.sgSvc != nil {
+		if err := s.sgSvc.SyncInstanceAddresses(ctx, inst.ID, storedIPs, instanceAddresses(inst)); err != nil {
+			s.logger.Warn("failed to sync security group flows", "instance_id", inst.ID, "error", err)
+		}
+	}
+
 	s.updateVolumesAfterLaunch(ctx, attachedVolumes, inst.ID)
 
 	_ = s.eventSvc.RecordEvent(ctx, "INSTANCE_LAUNCH", inst.ID.String(), "INSTANCE", map[string]interface{}{
@@ -419,11 +434,23 @@ func (s *InstanceService) finalizeProvision(ctx context.Context, inst *domain.In
 		"name":  inst.Name,
 		"image": inst.Image,
 		"ip":    inst.PrivateIP,
+		"ipv6":  inst.PrivateIPv6,
 	})
 
 	return nil
 }
 
+// instanceAddresses lists the private addresses assigned to an instance.
+func instanceAddresses(inst *domain.Instance) []string {
+	var ips []string
+	for _, ip := range []string{inst.PrivateIP, inst.PrivateIPv6} {
+		if ip != "" {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
 func (s *InstanceService) updateStatus(ctx context.Context, inst *domain.Instance, status domain.InstanceStatus) {
 	inst.Status = status
 	_ = s.repo.Update(ctx, inst)
@@ -695,6 +722,13 @@ func (s *InstanceService) updateTerminationMetrics(inst *domain.Instance) {
 }
 
 func (s *InstanceService) finalizeTermination(ctx context.Context, inst *domain.Instance) error {
+	// Remove the instance's firewall flows while its memberships are still recorded.
+	if s.sgSvc != nil {
+		if err := s.sgSvc.DetachAllFromInstance(ctx, inst.ID); err != nil {
+			s.logger.Warn("failed to detach security groups", "instance_id", inst.ID, "error", err)
+		}
+	}
+
 	if err := s.repo.Delete(ctx, inst.ID); err != nil {
 		return err
 	}
@@ -825,39 +859,69 @@ func (s *InstanceService) updateVolumesAfterLaunch(ctx context.Context, volumes
 		}
 	}
 }
-func (s *InstanceService) allocateIP(ctx context.Context, subnet *domain.Subnet) (string, error) {
+
+// allocateIP picks the first free IPv4 address in the subnet and, when the subnet
+// is dual-stack, the first free IPv6 address as well.
+func (s *InstanceService) allocateIP(ctx context.Context, subnet *domain.Subnet) (string, string, error) {
 	_, ipNet, err := net.ParseCIDR(subnet.CIDRBlock)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	instances, err := s.repo.ListBySubnet(ctx, subnet.ID)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	usedIPs := make(map[string]bool)
 	for _, inst := range instances {
-		if inst.PrivateIP != "" {
-			ip := inst.PrivateIP
-			if idx := strings.Index(ip, "/"); idx != -1 {
-				ip = ip[:idx]
+		for _, ip := range []string{inst.PrivateIP, inst.PrivateIPv6} {
+			if ip != "" {
+				usedIPs[stripPrefixLen(ip)] = true
 			}
-			usedIPs[ip] = true
 		}
 	}
-	gw := subnet.GatewayIP
-	if idx := strings.Index(gw, "/"); idx != -1 {
-		gw = gw[:idx]
+	usedIPs[stripPrefixLen(subnet.GatewayIP)] = true
+	if s.natRepo != nil {
+		nat, err := s.natRepo.GetBySubnet(ctx, subnet.ID)
+		if err == nil {
+			usedIPs[nat.PrivateIP] = true
+		} else if !errors.Is(err, errors.NotFound) {
+			return "", "", err
+		}
 	}
-	usedIPs[gw] = true
 
 	// Find first available IP
 	ip, err := s.findAvailableIP(ipNet, usedIPs)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
-	return ip, nil
+
+	if subnet.IPv6CIDRBlock == "" {
+		return ip, "", nil
+	}
+	_, ipv6Net, err := net.ParseCIDR(subnet.IPv6CIDRBlock)
+	if err != nil {
+		return "", "", err
+	}
+	// The first address of an IPv6 subnet (::1) is reserved for the gateway.
+	gw6 := make(net.IP, len(ipv6Net.IP))
+	copy(gw6, ipv6Net.IP)
+	gw6[len(gw6)-1] |= 1
+	usedIPs[gw6.String()] = true
+
+	ipv6, err := s.findAvailableIP(ipv6Net, usedIPs)
+	if err != nil {
+		return "", "", err
+	}
+	return ip, ipv6, nil
+}
+
+func stripPrefixLen(ip string) string {
+	if idx := strings.Index(ip, "/"); idx != -1 {
+		return ip[:idx]
+	}
+	return ip
 }
 
 func (s *InstanceService) isValidHostIP(ip net.IP, n *net.IPNet) bool {
@@ -886,13 +950,13 @@ func (s *InstanceService) isValidHostIP(ip net.IP, n *net.IPNet) bool {
 	return true
 }
 
-func (s *InstanceService) resolveNetworkConfig(ctx context.Context, vpcID, subnetID *uuid.UUID) (string, string, string, error) {
+func (s *InstanceService) resolveNetworkConfig(ctx context.Context, vpcID, subnetID *uuid.UUID) (string, string, string, string, error) {
 	var networkID string
 	if vpcID != nil {
 		vpc, err := s.vpcRepo.GetByID(ctx, *vpcID)
 		if err != nil {
 			s.logger.Error("failed to get VPC", "vpc_id", vpcID, "error", err)
-			return "", "", "", err
+			return "", "", "", "", err
 		}
 		networkID = vpc.NetworkID
 	}
@@ -908,27 +972,27 @@ func (s *InstanceService) resolveNetworkConfig(ctx context.Context, vpcID, subne
 		// If no subnet is configured, we let the backend assign an IP (dynamic).
 		// We return empty string here, and LaunchInstance should fetch the real IP later.
 		if subnetID == nil {
-			return networkID, "", "", nil
+			return networkID, "", "", "", nil
 		}
 	}
 
 	if subnetID == nil || s.network == nil {
-		return networkID, "", "", nil
+		return networkID, "", "", "", nil
 	}
 
 	subnet, err := s.subnetRepo.GetByID(ctx, *subnetID)
 	if err != nil {
-		return "", "", "", errors.Wrap(errors.NotFound, "subnet not found", err)
+		return "", "", "", "", errors.Wrap(errors.NotFound, "subnet not found", err)
 	}
 
 	// Dynamic IP allocation
-	allocatedIP, err := s.allocateIP(ctx, subnet)
+	allocatedIP, allocatedIPv6, err := s.allocateIP(ctx, subnet)
 	if err != nil {
-		return "", "", "", errors.Wrap(errors.ResourceLimitExceeded, "failed to allocate IP in subnet", err)
+		return "", "", "", "", errors.Wrap(errors.ResourceLimitExceeded, "failed to allocate IP in subnet", err)
 	}
 
 	ovsPort := "veth-" + uuid.New().String()[:8]
-	return networkID, allocatedIP, ovsPort, nil
+	return networkID, allocatedIP, allocatedIPv6, ovsPort, nil
 }
 
 func (s *InstanceService) resolveVolumes(ctx context.Context, volumes []domain.VolumeAttachment) ([]string, []*domain.Volume, error) {
@@ -973,7 +1037,7 @@ func (s *InstanceService) plumbNetwork(ctx context.Context, inst *domain.Instanc
 	}
 
 	if inst.SubnetID != nil {
-		return s.configureVethIP(ctx, *inst.SubnetID, vethContainer, inst.PrivateIP)
+		return s.configureVethIP(ctx, *inst.SubnetID, vethContainer, inst.PrivateIP, inst.PrivateIPv6)
 	}
 	return nil
 }
@@ -986,14 +1050,20 @@ func (s *InstanceService) attachToVpcBridge(ctx context.Context, vpcID uuid.UUID
 	return s.network.AttachVethToBridge(ctx, vpc.NetworkID, ovsPort)
 }
 
-func (s *InstanceService) configureVethIP(ctx context.Context, subnetID uuid.UUID, vethContainer, privateIP string) error {
+func (s *InstanceService) configureVethIP(ctx context.Context, subnetID uuid.UUID, vethContainer, privateIP, privateIPv6 string) error {
 	subnet, err := s.subnetRepo.GetByID(ctx, subnetID)
 	if err != nil || subnet == nil {
 		return err
 	}
 	_, ipNet, _ := net.ParseCIDR(subnet.CIDRBlock)
 	ones, _ := ipNet.Mask.Size()
-	return s.network.SetVethIP(ctx, vethContainer, privateIP, strconv.Itoa(ones))
+	if err := s.network.SetVethIP(ctx, vethContainer, privateIP, strconv.Itoa(ones)); err != nil {
+		return err
+	}
+	if privateIPv6 == "" {
+		return nil
+	}
+	return s.network.SetVethIP(ctx, vethContainer, privateIPv6, strconv.Itoa(domain.SubnetIPv6PrefixLen))
 }
 
 func (s *InstanceService) formatContainerName(id uuid.UUID) string {
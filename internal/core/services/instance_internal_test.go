@@ -9,6 +9,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/repositories/noop"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -176,3 +178,87 @@ func TestInstanceService_UpdateInstanceMetadata(t *testing.T) {
 	_, ok := inst.Metadata["old"]
 	assert.False(t, ok)
 }
+
+func TestInstanceService_AllocateIPDualStack(t *testing.T) {
+	repo := new(mockInstanceRepo)
+	svc := &InstanceService{repo: repo}
+	ctx := context.Background()
+	subnet := &domain.Subnet{
+		ID:            uuid.New(),
+		CIDRBlock:     "10.0.1.0/24",
+		IPv6CIDRBlock: "fd00:10:0:1::/64",
+		GatewayIP:     "10.0.1.1",
+	}
+
+	repo.On("ListBySubnet", ctx, subnet.ID).Return([]*domain.Instance{
+		{PrivateIP: "10.0.1.2", PrivateIPv6: "fd00:10:0:1::2"},
+	}, nil).Once()
+
+	ip, ipv6, err := svc.allocateIP(ctx, subnet)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.1.3", ip)
+	assert.Equal(t, "fd00:10:0:1::3", ipv6)
+
+	subnet.IPv6CIDRBlock = ""
+	repo.On("ListBySubnet", ctx, subnet.ID).Return([]*domain.Instance{}, nil).Once()
+	ip, ipv6, err = svc.allocateIP(ctx, subnet)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.1.2", ip)
+	assert.Empty(t, ipv6)
+}
+
+// mockNATGatewayRepo stubs the one NAT gateway lookup instance allocation makes.
+type mockNATGatewayRepo struct {
+	ports.NATGatewayRepository
+	mock.Mock
+}
+
+func (m *mockNATGatewayRepo) GetBySubnet(ctx context.Context, subnetID uuid.UUID) (*domain.NATGateway, error) {
+	args := m.Called(ctx, subnetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NATGateway), args.Error(1)
+}
+
+func TestInstanceService_AllocateIPSkipsNATGateway(t *testing.T) {
+	repo := new(mockInstanceRepo)
+	natRepo := new(mockNATGatewayRepo)
+	svc := &InstanceService{repo: repo, natRepo: natRepo}
+	ctx := context.Background()
+	subnet := &domain.Subnet{ID: uuid.New(), CIDRBlock: "10.0.1.0/30", GatewayIP: "10.0.1.1"}
+
+	repo.On("ListBySubnet", ctx, subnet.ID).Return([]*domain.Instance{}, nil)
+	natRepo.On("GetBySubnet", ctx, subnet.ID).Return(&domain.NATGateway{PrivateIP: "10.0.1.2"}, nil).Once()
+
+	_, _, err := svc.allocateIP(ctx, subnet)
+	assert.Error(t, err, "the only host address left is the NAT gateway's")
+}
+
+// mockSecurityGroupSvc stubs the address sync provisioning triggers.
+type mockSecurityGroupSvc struct {
+	ports.SecurityGroupService
+	mock.Mock
+}
+
+func (m *mockSecurityGroupSvc) SyncInstanceAddresses(ctx context.Context, instanceID uuid.UUID, previous, current []string) error {
+	return m.Called(ctx, instanceID, previous, current).Error(0)
+}
+
+func TestInstanceService_FinalizeProvisionSyncsSecurityGroups(t *testing.T) {
+	repo := new(mockInstanceRepo)
+	sgSvc := new(mockSecurityGroupSvc)
+	svc := &InstanceService{
+		repo: repo, compute: &noop.NoopComputeBackend{}, sgSvc: sgSvc,
+		eventSvc: &noop.NoopEventService{}, auditSvc: &noop.NoopAuditService{},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	ctx := context.Background()
+	inst := &domain.Instance{ID: uuid.New()}
+
+	repo.On("Update", ctx, inst).Return(nil).Once()
+	sgSvc.On("SyncInstanceAddresses", ctx, inst.ID, []string(nil), []string{"127.0.0.1"}).Return(nil).Once()
+
+	assert.NoError(t, svc.finalizeProvision(ctx, inst, "cid-1", nil, nil))
+	sgSvc.AssertExpectations(t)
+}
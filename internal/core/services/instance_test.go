@@ -392,7 +392,7 @@ func TestNetworkingCIDRExhaustion(t *testing.T) {
 	err := vpcRepo.Create(ctx, vpc)
 	require.NoError(t, err)
 
-	subnet, err := subnetSvc.CreateSubnet(ctx, vpc.ID, "tiny-subnet", "10.10.1.0/30", "us-east-1a")
+	subnet, err := subnetSvc.CreateSubnet(ctx, vpc.ID, "tiny-subnet", "10.10.1.0/30", "", "us-east-1a")
 	require.NoError(t, err)
 
 	// 2. Launch 1st instance (Should succeed in DB)
@@ -47,6 +47,15 @@ func (m *MockDNSService) UpdateRecord(ctx context.Context, id uuid.UUID, content
 func (m *MockDNSService) DeleteRecord(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
+func (m *MockDNSService) ChangeRecords(ctx context.Context, zoneID uuid.UUID, changes []domain.DNSRecordChange) ([]*domain.DNSRecord, error) {
+	return nil, nil
+}
+func (m *MockDNSService) ImportZone(ctx context.Context, zoneID uuid.UUID, zoneFile string) ([]*domain.DNSRecord, error) {
+	return nil, nil
+}
+func (m *MockDNSService) ExportZone(ctx context.Context, zoneID uuid.UUID) (string, error) {
+	return "", nil
+}
 func (m *MockDNSService) RegisterInstance(ctx context.Context, instance *domain.Instance, ip string) error {
 	args := m.Called(ctx, instance, ip)
 	return args.Error(0)
@@ -257,3 +266,56 @@ func TestInstanceService_Lifecycle_Unit(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+// MockSecurityGroupService stubs the security group hooks of the instance lifecycle.
+type MockSecurityGroupService struct {
+	ports.SecurityGroupService
+	mock.Mock
+}
+
+func (m *MockSecurityGroupService) DetachAllFromInstance(ctx context.Context, instanceID uuid.UUID) error {
+	return m.Called(ctx, instanceID).Error(0)
+}
+
+func (m *MockSecurityGroupService) SyncInstanceAddresses(ctx context.Context, instanceID uuid.UUID, previous, current []string) error {
+	return m.Called(ctx, instanceID, previous, current).Error(0)
+}
+
+func TestInstanceService_TerminateDetachesSecurityGroups(t *testing.T) {
+	repo := new(MockInstanceRepo)
+	volRepo := new(MockVolumeRepo)
+	typeRepo := new(MockInstanceTypeRepo)
+	compute := new(MockComputeBackend)
+	eventSvc := new(MockEventService)
+	auditSvc := new(MockAuditService)
+	sgSvc := new(MockSecurityGroupService)
+
+	svc := services.NewInstanceService(services.InstanceServiceParams{
+		Repo:             repo,
+		VolumeRepo:       volRepo,
+		InstanceTypeRepo: typeRepo,
+		SecurityGroupSvc: sgSvc,
+		Compute:          compute,
+		EventSvc:         eventSvc,
+		AuditSvc:         auditSvc,
+		Logger:           slog.Default(),
+	})
+
+	ctx := context.Background()
+	inst := &domain.Instance{ID: uuid.New(), UserID: uuid.New(), Status: domain.StatusRunning, ContainerID: "cid-1", InstanceType: "gone"}
+
+	var order []string
+	repo.On("GetByID", mock.Anything, inst.ID).Return(inst, nil).Once()
+	compute.On("DeleteInstance", mock.Anything, "cid-1").Return(nil).Once()
+	compute.On("Type").Return("docker").Maybe()
+	volRepo.On("ListByInstanceID", mock.Anything, inst.ID).Return([]*domain.Volume{}, nil).Once()
+	sgSvc.On("DetachAllFromInstance", mock.Anything, inst.ID).Return(nil).Once().Run(func(mock.Arguments) { order = append(order, "detach") })
+	repo.On("Delete", mock.Anything, inst.ID).Return(nil).Once().Run(func(mock.Arguments) { order = append(order, "delete") })
+	typeRepo.On("GetByID", mock.Anything, "gone").Return(nil, fmt.Errorf("not found")).Once()
+	eventSvc.On("RecordEvent", mock.Anything, "INSTANCE_TERMINATE", inst.ID.String(), "INSTANCE", mock.Anything).Return(nil).Once()
+	auditSvc.On("Log", mock.Anything, inst.UserID, "instance.terminate", "instance", inst.ID.String(), mock.Anything).Return(nil).Once()
+
+	assert.NoError(t, svc.TerminateInstance(ctx, inst.ID.String()))
+	assert.Equal(t, []string{"detach", "delete"}, order, "flows must be removed while memberships are still recorded")
+	sgSvc.AssertExpectations(t)
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	appcontext "github.com/poyrazk/thecloud/internal/core/context"
 	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/ports"
@@ -20,9 +22,33 @@ type LBWorker struct {
 	lbRepo       ports.LBRepository
 	instanceRepo ports.InstanceRepository
 	proxyAdapter ports.LBProxyAdapter
+	certSvc      ports.CertificateService
 	dialer       PortDialer
+	httpClient   *http.Client
+
+	nextCheck map[uuid.UUID]time.Time
+	states    map[lbTargetKey]*targetHealthState
+}
+
+// lbTargetKey identifies a target across worker passes.
+type lbTargetKey struct {
+	lbID       uuid.UUID
+	instanceID uuid.UUID
+	group      string
+	port       int
 }
 
+// targetHealthState tracks consecutive check outcomes for one target.
+type targetHealthState struct {
+	successes int
+	failures  int
+}
+
+// tcpHealthCheck is used for load balancers without a health check
+// configuration: every pass dials the target port and one result flips the
+// target's health.
+var tcpHealthCheck = domain.HealthCheckConfig{TimeoutSeconds: 2, HealthyThreshold: 1, UnhealthyThreshold: 1}
+
 // PortDialer defines an interface for dialing network connections.
 type PortDialer interface {
 	DialTimeout(network, address string, timeout time.Duration) (net.Conn, error)
@@ -34,13 +60,28 @@ func (d *realDialer) DialTimeout(network, address string, timeout time.Duration)
 	return net.DialTimeout(network, address, timeout)
 }
 
+// LBWorkerParams holds the dependencies for an LBWorker.
+type LBWorkerParams struct {
+	LBRepo       ports.LBRepository
+	InstanceRepo ports.InstanceRepository
+	ProxyAdapter ports.LBProxyAdapter
+	CertSvc      ports.CertificateService // Resolves HTTPS listener certificates
+}
+
 // NewLBWorker constructs an LBWorker with its dependencies.
-func NewLBWorker(lbRepo ports.LBRepository, instanceRepo ports.InstanceRepository, proxyAdapter ports.LBProxyAdapter) *LBWorker {
+func NewLBWorker(params LBWorkerParams) *LBWorker {
 	return &LBWorker{
-		lbRepo:       lbRepo,
-		instanceRepo: instanceRepo,
-		proxyAdapter: proxyAdapter,
+		lbRepo:       params.LBRepo,
+		instanceRepo: params.InstanceRepo,
+		proxyAdapter: params.ProxyAdapter,
+		certSvc:      params.CertSvc,
 		dialer:       &realDialer{},
+		httpClient: &http.Client{
+			Transport:     &http.Transport{DisableKeepAlives: true},
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		nextCheck: make(map[uuid.UUID]time.Time),
+		states:    make(map[lbTargetKey]*targetHealthState),
 	}
 }
 
@@ -102,6 +143,12 @@ func (w *LBWorker) deployLB(ctx context.Context, lb *domain.LoadBalancer) {
 		log.Printf("Worker: failed to list targets for LB %s: %v", lb.ID, err)
 		return
 	}
+	targets = w.servingTargets(ctx, lb, targets)
+
+	if err := w.resolveCertificates(ctx, lb); err != nil {
+		log.Printf("Worker: failed to resolve certificates for LB %s: %v", lb.ID, err)
+		return
+	}
 
 	_, err = w.proxyAdapter.DeployProxy(ctx, lb, targets)
 	if err != nil {
@@ -130,6 +177,13 @@ func (w *LBWorker) cleanupLB(ctx context.Context, lb *domain.LoadBalancer) {
 	} else {
 		log.Printf("Worker: LB %s fully removed", lb.ID)
 	}
+
+	delete(w.nextCheck, lb.ID)
+	for key := range w.states {
+		if key.lbID == lb.ID {
+			delete(w.states, key)
+		}
+	}
 }
 
 func (w *LBWorker) processActiveLBs(ctx context.Context) {
@@ -145,6 +199,11 @@ func (w *LBWorker) processActiveLBs(ctx context.Context) {
 			if err != nil {
 				continue
 			}
+			targets = w.servingTargets(gCtx, lb, targets)
+			if err := w.resolveCertificates(gCtx, lb); err != nil {
+				log.Printf("Worker: failed to resolve certificates for LB %s: %v", lb.ID, err)
+				continue
+			}
 			// Update configuration (e.g. if targets changed)
 			if err := w.proxyAdapter.UpdateProxyConfig(gCtx, lb, targets); err != nil {
 				log.Printf("Worker: failed to update proxy config for LB %s: %v", lb.ID, err)
@@ -153,6 +212,51 @@ func (w *LBWorker) processActiveLBs(ctx context.Context) {
 	}
 }
 
+// servingTargets returns the targets the proxy should send traffic to: only
+// healthy ones. Draining targets whose deregistration delay has passed are
+// removed from the load balancer.
+func (w *LBWorker) servingTargets(ctx context.Context, lb *domain.LoadBalancer, targets []*domain.LBTarget) []*domain.LBTarget {
+	now := time.Now()
+	serving := make([]*domain.LBTarget, 0, len(targets))
+	for _, t := range targets {
+		switch {
+		case t.Health == domain.LBTargetHealthDraining:
+			if t.DrainUntil == nil || !now.Before(*t.DrainUntil) {
+				if err := w.lbRepo.RemoveTarget(ctx, lb.ID, t.InstanceID, t.TargetGroup); err != nil {
+					log.Printf("Worker: failed to remove drained target %s from LB %s: %v", t.InstanceID, lb.ID, err)
+					continue
+				}
+				delete(w.states, lbTargetKey{lb.ID, t.InstanceID, t.TargetGroup, t.Port})
+				log.Printf("Worker: drained target %s removed from LB %s", t.InstanceID, lb.ID)
+			}
+		case t.Health == domain.LBTargetHealthHealthy:
+			serving = append(serving, t)
+		}
+	}
+	return serving
+}
+
+// resolveCertificates loads the key pairs of an LB's HTTPS listener so the
+// proxy adapter can write them alongside the proxy configuration.
+func (w *LBWorker) resolveCertificates(ctx context.Context, lb *domain.LoadBalancer) error {
+	if lb.TLS == nil {
+		return nil
+	}
+	if w.certSvc == nil {
+		return fmt.Errorf("certificate service not configured")
+	}
+	pairs := make([]*domain.CertificateKeyPair, 0, len(lb.TLS.CertificateIDs))
+	for _, id := range lb.TLS.CertificateIDs {
+		pair, err := w.certSvc.GetKeyPair(ctx, id)
+		if err != nil {
+			return fmt.Errorf("certificate %s: %w", id, err)
+		}
+		pairs = append(pairs, pair)
+	}
+	lb.TLS.Certificates = pairs
+	return nil
+}
+
 func (w *LBWorker) processHealthChecks(ctx context.Context) {
 	lbs, err := w.lbRepo.ListAll(ctx)
 	if err != nil {
@@ -168,6 +272,16 @@ func (w *LBWorker) processHealthChecks(ctx context.Context) {
 }
 
 func (w *LBWorker) checkLBHealth(ctx context.Context, lb *domain.LoadBalancer) {
+	// Configured health checks run on their own interval; the TCP fallback
+	// runs on every pass.
+	now := time.Now()
+	if lb.HealthCheck != nil {
+		if due, ok := w.nextCheck[lb.ID]; ok && now.Before(due) {
+			return
+		}
+		w.nextCheck[lb.ID] = now.Add(time.Duration(lb.HealthCheck.IntervalSeconds) * time.Second)
+	}
+
 	targets, err := w.lbRepo.ListTargets(ctx, lb.ID)
 	if err != nil {
 		return
@@ -175,6 +289,9 @@ func (w *LBWorker) checkLBHealth(ctx context.Context, lb *domain.LoadBalancer) {
 
 	changed := false
 	for _, t := range targets {
+		if t.Health == domain.LBTargetHealthDraining {
+			continue
+		}
 		if w.checkTargetHealth(ctx, lb, t) {
 			changed = true
 		}
@@ -191,16 +308,25 @@ func (w *LBWorker) checkTargetHealth(ctx context.Context, lb *domain.LoadBalance
 		return false
 	}
 
+	hc := tcpHealthCheck
+	if lb.HealthCheck != nil {
+		hc = *lb.HealthCheck
+	}
+
 	hostPort := getHostPort(inst.Ports, t.Port)
-	status := "unhealthy"
+	passed := false
 	if hostPort != "" {
-		if w.isPortOpen(hostPort) {
-			status = "healthy"
+		timeout := time.Duration(hc.TimeoutSeconds) * time.Second
+		if hc.Path != "" {
+			passed = w.isHTTPHealthy(ctx, hostPort, hc.Path, timeout)
+		} else {
+			passed = w.isPortOpen(hostPort, timeout)
 		}
 	}
 
+	status := w.applyThresholds(hc, lb.ID, t, passed)
 	if t.Health != status {
-		_ = w.lbRepo.UpdateTargetHealth(ctx, lb.ID, t.InstanceID, status)
+		_ = w.lbRepo.UpdateTargetHealth(ctx, lb.ID, t.InstanceID, t.TargetGroup, t.Port, status)
 		return true
 	}
 	return false
@@ -219,8 +345,54 @@ func getHostPort(portsStr string, targetPort int) string {
 	return ""
 }
 
-func (w *LBWorker) isPortOpen(port string) bool {
-	conn, err := w.dialer.DialTimeout("tcp", "localhost:"+port, 2*time.Second)
+// applyThresholds folds one check outcome into the target's streak and
+// returns the resulting health. A target only changes state once the
+// configured number of consecutive checks agree.
+func (w *LBWorker) applyThresholds(hc domain.HealthCheckConfig, lbID uuid.UUID, t *domain.LBTarget, passed bool) string {
+	key := lbTargetKey{lbID, t.InstanceID, t.TargetGroup, t.Port}
+	state, ok := w.states[key]
+	if !ok {
+		state = &targetHealthState{}
+		w.states[key] = state
+	}
+	if passed {
+		state.successes++
+		state.failures = 0
+	} else {
+		state.failures++
+		state.successes = 0
+	}
+
+	switch {
+	case t.Health != domain.LBTargetHealthHealthy && state.successes >= max(hc.HealthyThreshold, 1):
+		return domain.LBTargetHealthHealthy
+	case t.Health != domain.LBTargetHealthUnhealthy && state.failures >= max(hc.UnhealthyThreshold, 1):
+		return domain.LBTargetHealthUnhealthy
+	default:
+		return t.Health
+	}
+}
+
+// isHTTPHealthy requests path on the target; 2xx and 3xx responses pass.
+func (w *LBWorker) isHTTPHealthy(ctx context.Context, port, path string, timeout time.Duration) bool {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, "http://localhost:"+port+path, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("User-Agent", "thecloud-lb-health-check")
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode >= 200 && resp.StatusCode < 400
+}
+
+func (w *LBWorker) isPortOpen(port string, timeout time.Duration) bool {
+	conn, err := w.dialer.DialTimeout("tcp", "localhost:"+port, timeout)
 	if err == nil {
 		_ = conn.Close()
 		return true
@@ -4,12 +4,16 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -93,15 +97,18 @@ func (m *mockLBRepo) Delete(ctx context.Context, id uuid.UUID) error {
 func (m *mockLBRepo) AddTarget(ctx context.Context, target *domain.LBTarget) error {
 	return m.Called(ctx, target).Error(0)
 }
-func (m *mockLBRepo) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID) error {
-	return m.Called(ctx, lbID, instanceID).Error(0)
+func (m *mockLBRepo) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID, group string) error {
+	return m.Called(ctx, lbID, instanceID, group).Error(0)
 }
 func (m *mockLBRepo) ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain.LBTarget, error) {
 	args := m.Called(ctx, lbID)
 	return args.Get(0).([]*domain.LBTarget), args.Error(1)
 }
-func (m *mockLBRepo) UpdateTargetHealth(ctx context.Context, lbID, instanceID uuid.UUID, status string) error {
-	return m.Called(ctx, lbID, instanceID, status).Error(0)
+func (m *mockLBRepo) UpdateTargetHealth(ctx context.Context, lbID, instanceID uuid.UUID, group string, port int, status string) error {
+	return m.Called(ctx, lbID, instanceID, group, port, status).Error(0)
+}
+func (m *mockLBRepo) DrainTarget(ctx context.Context, lbID, instanceID uuid.UUID, group string, until time.Time) error {
+	return m.Called(ctx, lbID, instanceID, group, until).Error(0)
 }
 func (m *mockLBRepo) GetTargetsForInstance(ctx context.Context, instanceID uuid.UUID) ([]*domain.LBTarget, error) {
 	args := m.Called(ctx, instanceID)
@@ -150,7 +157,7 @@ func TestLBWorkerProcessCreatingLBs(t *testing.T) {
 	lbRepo := new(mockLBRepo)
 	instRepo := new(mockInstRepo)
 	proxy := new(MockLBProxyAdapter)
-	worker := NewLBWorker(lbRepo, instRepo, proxy)
+	worker := NewLBWorker(LBWorkerParams{LBRepo: lbRepo, InstanceRepo: instRepo, ProxyAdapter: proxy})
 
 	ctx := context.Background()
 	lbID := uuid.New()
@@ -179,7 +186,7 @@ func TestLBWorkerProcessDeletingLBs(t *testing.T) {
 	lbRepo := new(mockLBRepo)
 	instRepo := new(mockInstRepo)
 	proxy := new(MockLBProxyAdapter)
-	worker := NewLBWorker(lbRepo, instRepo, proxy)
+	worker := NewLBWorker(LBWorkerParams{LBRepo: lbRepo, InstanceRepo: instRepo, ProxyAdapter: proxy})
 
 	ctx := context.Background()
 	lbID := uuid.New()
@@ -204,7 +211,7 @@ func TestLBWorkerProcessActiveLBs(t *testing.T) {
 	lbRepo := new(mockLBRepo)
 	instRepo := new(mockInstRepo)
 	proxy := new(MockLBProxyAdapter)
-	worker := NewLBWorker(lbRepo, instRepo, proxy)
+	worker := NewLBWorker(LBWorkerParams{LBRepo: lbRepo, InstanceRepo: instRepo, ProxyAdapter: proxy})
 
 	ctx := context.Background()
 	lbID := uuid.New()
@@ -223,12 +230,53 @@ func TestLBWorkerProcessActiveLBs(t *testing.T) {
 	proxy.AssertExpectations(t)
 }
 
+// stubCertSvc serves key pairs from a map.
+type stubCertSvc struct {
+	ports.CertificateService
+	pairs map[uuid.UUID]*domain.CertificateKeyPair
+}
+
+func (s *stubCertSvc) GetKeyPair(_ context.Context, id uuid.UUID) (*domain.CertificateKeyPair, error) {
+	if pair, ok := s.pairs[id]; ok {
+		return pair, nil
+	}
+	return nil, fmt.Errorf("certificate %s not found", id)
+}
+
+func TestLBWorkerResolvesTLSCertificates(t *testing.T) {
+	t.Parallel()
+	lbRepo := new(mockLBRepo)
+	proxy := new(MockLBProxyAdapter)
+	certID := uuid.New()
+	pair := &domain.CertificateKeyPair{CertificateID: certID, Domains: []string{"app.example.com"}, CertificatePEM: "cert", PrivateKeyPEM: "key"}
+	worker := NewLBWorker(LBWorkerParams{
+		LBRepo: lbRepo, InstanceRepo: new(mockInstRepo), ProxyAdapter: proxy,
+		CertSvc: &stubCertSvc{pairs: map[uuid.UUID]*domain.CertificateKeyPair{certID: pair}},
+	})
+
+	ctx := context.Background()
+	lb := &domain.LoadBalancer{ID: uuid.New(), Status: domain.LBStatusCreating, TLS: &domain.LBTLSConfig{Port: 443, CertificateIDs: []uuid.UUID{certID}}}
+	missing := &domain.LoadBalancer{ID: uuid.New(), Status: domain.LBStatusCreating, TLS: &domain.LBTLSConfig{Port: 443, CertificateIDs: []uuid.UUID{uuid.New()}}}
+
+	lbRepo.On("ListAll", ctx).Return([]*domain.LoadBalancer{lb, missing}, nil)
+	lbRepo.On("ListTargets", mock.Anything, mock.Anything).Return([]*domain.LBTarget{}, nil)
+	proxy.On("DeployProxy", mock.Anything, lb, []*domain.LBTarget{}).Return("http://lb-url", nil)
+	lbRepo.On("Update", mock.Anything, lb).Return(nil)
+
+	worker.processCreatingLBs(ctx)
+
+	assert.Equal(t, []*domain.CertificateKeyPair{pair}, lb.TLS.Certificates)
+	proxy.AssertNotCalled(t, "DeployProxy", mock.Anything, missing, mock.Anything)
+	assert.Equal(t, domain.LBStatusCreating, missing.Status, "deployment is retried once the certificate resolves")
+	proxy.AssertExpectations(t)
+}
+
 func TestLBWorkerProcessHealthChecks(t *testing.T) {
 	t.Parallel()
 	lbRepo := new(mockLBRepo)
 	instRepo := new(mockInstRepo)
 	proxy := new(MockLBProxyAdapter)
-	worker := NewLBWorker(lbRepo, instRepo, proxy)
+	worker := NewLBWorker(LBWorkerParams{LBRepo: lbRepo, InstanceRepo: instRepo, ProxyAdapter: proxy})
 
 	ctx := context.Background()
 	lbID := uuid.New()
@@ -272,13 +320,13 @@ func TestLBWorkerIsPortOpen(t *testing.T) {
 	lbRepo := new(mockLBRepo)
 	instRepo := new(mockInstRepo)
 	proxy := new(MockLBProxyAdapter)
-	worker := NewLBWorker(lbRepo, instRepo, proxy)
+	worker := NewLBWorker(LBWorkerParams{LBRepo: lbRepo, InstanceRepo: instRepo, ProxyAdapter: proxy})
 
 	worker.dialer = &mockDialer{}
-	assert.True(t, worker.isPortOpen("8080"))
+	assert.True(t, worker.isPortOpen("8080", time.Second))
 
 	worker.dialer = &mockDialer{err: fmt.Errorf("dial failed")}
-	assert.False(t, worker.isPortOpen("8080"))
+	assert.False(t, worker.isPortOpen("8080", time.Second))
 }
 
 func TestLBWorkerCheckTargetHealthUpdates(t *testing.T) {
@@ -286,7 +334,7 @@ func TestLBWorkerCheckTargetHealthUpdates(t *testing.T) {
 	lbRepo := new(mockLBRepo)
 	instRepo := new(mockInstRepo)
 	proxy := new(MockLBProxyAdapter)
-	worker := NewLBWorker(lbRepo, instRepo, proxy)
+	worker := NewLBWorker(LBWorkerParams{LBRepo: lbRepo, InstanceRepo: instRepo, ProxyAdapter: proxy})
 	worker.dialer = &mockDialer{}
 
 	ctx := context.Background()
@@ -294,12 +342,13 @@ func TestLBWorkerCheckTargetHealthUpdates(t *testing.T) {
 	instID := uuid.New()
 
 	instRepo.On("GetByID", ctx, instID).Return(&domain.Instance{ID: instID, Ports: "8080:80"}, nil)
-	lbRepo.On("UpdateTargetHealth", ctx, lbID, instID, "healthy").Return(nil).Once()
+	lbRepo.On("UpdateTargetHealth", ctx, lbID, instID, domain.DefaultLBTargetGroup, 80, "healthy").Return(nil).Once()
 
 	changed := worker.checkTargetHealth(ctx, &domain.LoadBalancer{ID: lbID}, &domain.LBTarget{
-		InstanceID: instID,
-		Port:       80,
-		Health:     "unhealthy",
+		InstanceID:  instID,
+		Port:        80,
+		Health:      "unhealthy",
+		TargetGroup: domain.DefaultLBTargetGroup,
 	})
 
 	assert.True(t, changed)
@@ -321,7 +370,7 @@ func TestLBWorkerRun(t *testing.T) {
 	lbRepo := new(mockLBRepo)
 	instRepo := new(mockInstRepo)
 	proxy := new(MockLBProxyAdapter)
-	worker := NewLBWorker(lbRepo, instRepo, proxy)
+	worker := NewLBWorker(LBWorkerParams{LBRepo: lbRepo, InstanceRepo: instRepo, ProxyAdapter: proxy})
 
 	ctx, cancel := context.WithCancel(context.Background())
 	var wg sync.WaitGroup
@@ -331,3 +380,85 @@ func TestLBWorkerRun(t *testing.T) {
 	worker.Run(ctx, &wg)
 	wg.Wait()
 }
+
+func TestLBWorkerHTTPHealthCheckThresholds(t *testing.T) {
+	t.Parallel()
+	var healthy atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" || !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	_, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	assert.NoError(t, err)
+
+	lbRepo := new(mockLBRepo)
+	instRepo := new(mockInstRepo)
+	worker := NewLBWorker(LBWorkerParams{LBRepo: lbRepo, InstanceRepo: instRepo, ProxyAdapter: new(MockLBProxyAdapter)})
+
+	ctx := context.Background()
+	instID := uuid.New()
+	lb := &domain.LoadBalancer{ID: uuid.New(), HealthCheck: &domain.HealthCheckConfig{
+		Path: "/healthz", IntervalSeconds: 10, TimeoutSeconds: 2, HealthyThreshold: 2, UnhealthyThreshold: 3,
+	}}
+	target := &domain.LBTarget{InstanceID: instID, Port: 80, Health: domain.LBTargetHealthUnknown}
+	instRepo.On("GetByID", ctx, instID).Return(&domain.Instance{ID: instID, Ports: port + ":80"}, nil)
+	lbRepo.On("UpdateTargetHealth", ctx, lb.ID, instID, "", 80, mock.Anything).Return(nil)
+
+	healthy.Store(true)
+	assert.False(t, worker.checkTargetHealth(ctx, lb, target), "one success is below the healthy threshold")
+	assert.True(t, worker.checkTargetHealth(ctx, lb, target))
+	target.Health = domain.LBTargetHealthHealthy
+
+	healthy.Store(false)
+	assert.False(t, worker.checkTargetHealth(ctx, lb, target))
+	assert.False(t, worker.checkTargetHealth(ctx, lb, target))
+	assert.True(t, worker.checkTargetHealth(ctx, lb, target), "the third failure marks the target unhealthy")
+	lbRepo.AssertCalled(t, "UpdateTargetHealth", ctx, lb.ID, instID, "", 80, domain.LBTargetHealthHealthy)
+	lbRepo.AssertCalled(t, "UpdateTargetHealth", ctx, lb.ID, instID, "", 80, domain.LBTargetHealthUnhealthy)
+}
+
+func TestLBWorkerHealthCheckInterval(t *testing.T) {
+	t.Parallel()
+	lbRepo := new(mockLBRepo)
+	worker := NewLBWorker(LBWorkerParams{LBRepo: lbRepo, InstanceRepo: new(mockInstRepo), ProxyAdapter: new(MockLBProxyAdapter)})
+
+	ctx := context.Background()
+	lb := &domain.LoadBalancer{ID: uuid.New(), HealthCheck: &domain.HealthCheckConfig{IntervalSeconds: 30, TimeoutSeconds: 5}}
+	lbRepo.On("ListTargets", ctx, lb.ID).Return([]*domain.LBTarget{}, nil).Once()
+
+	worker.checkLBHealth(ctx, lb)
+	worker.checkLBHealth(ctx, lb)
+
+	lbRepo.AssertNumberOfCalls(t, "ListTargets", 1)
+}
+
+func TestLBWorkerServesHealthyTargetsAndPurgesDrained(t *testing.T) {
+	t.Parallel()
+	lbRepo := new(mockLBRepo)
+	proxy := new(MockLBProxyAdapter)
+	worker := NewLBWorker(LBWorkerParams{LBRepo: lbRepo, InstanceRepo: new(mockInstRepo), ProxyAdapter: proxy})
+
+	ctx := context.Background()
+	lb := &domain.LoadBalancer{ID: uuid.New(), Status: domain.LBStatusActive}
+	past := time.Now().Add(-time.Second)
+	future := time.Now().Add(time.Minute)
+	healthy := &domain.LBTarget{InstanceID: uuid.New(), Health: domain.LBTargetHealthHealthy}
+	unhealthy := &domain.LBTarget{InstanceID: uuid.New(), Health: domain.LBTargetHealthUnhealthy}
+	unknown := &domain.LBTarget{InstanceID: uuid.New(), Health: domain.LBTargetHealthUnknown}
+	draining := &domain.LBTarget{InstanceID: uuid.New(), Health: domain.LBTargetHealthDraining, DrainUntil: &future}
+	drained := &domain.LBTarget{InstanceID: uuid.New(), TargetGroup: "api", Health: domain.LBTargetHealthDraining, DrainUntil: &past}
+
+	lbRepo.On("ListAll", ctx).Return([]*domain.LoadBalancer{lb}, nil)
+	lbRepo.On("ListTargets", mock.Anything, lb.ID).Return([]*domain.LBTarget{healthy, unhealthy, unknown, draining, drained}, nil)
+	lbRepo.On("RemoveTarget", mock.Anything, lb.ID, drained.InstanceID, "api").Return(nil).Once()
+	proxy.On("UpdateProxyConfig", mock.Anything, lb, []*domain.LBTarget{healthy}).Return(nil)
+
+	worker.processActiveLBs(ctx)
+
+	lbRepo.AssertExpectations(t)
+	proxy.AssertExpectations(t)
+}
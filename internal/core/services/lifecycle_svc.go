@@ -26,7 +26,10 @@ func NewLifecycleService(repo ports.LifecycleRepository, storageRepo ports.Stora
 	}
 }
 
-func (s *LifecycleService) CreateRule(ctx context.Context, bucket string, prefix string, expirationDays int, enabled bool) (*domain.LifecycleRule, error) {
+// lifecycleScanBatch is the number of keys whose versions are loaded per query while evaluating a rule.
+const lifecycleScanBatch = 500
+
+func (s *LifecycleService) CreateRule(ctx context.Context, bucket string, rule *domain.LifecycleRule) (*domain.LifecycleRule, error) {
 	// 1. Verify bucket exists
 	b, err := s.storageRepo.GetBucket(ctx, bucket)
 	if err != nil {
@@ -39,20 +42,15 @@ func (s *LifecycleService) CreateRule(ctx context.Context, bucket string, prefix
 		return nil, errors.New(errors.Forbidden, "you don't own this bucket")
 	}
 
-	if expirationDays < 1 {
-		return nil, errors.New(errors.InvalidInput, "expiration days must be at least 1")
+	if err := rule.Validate(); err != nil {
+		return nil, errors.New(errors.InvalidInput, err.Error())
 	}
 
-	rule := &domain.LifecycleRule{
-		ID:             uuid.New(),
-		UserID:         userID,
-		BucketName:     bucket,
-		Prefix:         prefix,
-		ExpirationDays: expirationDays,
-		Enabled:        enabled,
-		CreatedAt:      time.Now().UTC(),
-		UpdatedAt:      time.Now().UTC(),
-	}
+	rule.ID = uuid.New()
+	rule.UserID = userID
+	rule.BucketName = bucket
+	rule.CreatedAt = time.Now().UTC()
+	rule.UpdatedAt = rule.CreatedAt
 
 	if err := s.repo.Create(ctx, rule); err != nil {
 		return nil, err
@@ -105,3 +103,79 @@ func (s *LifecycleService) DeleteRule(ctx context.Context, bucket string, ruleID
 
 	return s.repo.Delete(ctx, id)
 }
+
+// PreviewRule evaluates one of the bucket's rules without applying it.
+func (s *LifecycleService) PreviewRule(ctx context.Context, bucket string, ruleID string) (*domain.LifecycleReport, error) {
+	id, err := uuid.Parse(ruleID)
+	if err != nil {
+		return nil, errors.New(errors.InvalidInput, "invalid rule id")
+	}
+
+	b, err := s.storageRepo.GetBucket(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if appcontext.UserIDFromContext(ctx) != b.UserID {
+		return nil, errors.New(errors.Forbidden, "you don't own this bucket")
+	}
+
+	rule, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule.BucketName != bucket {
+		return nil, errors.New(errors.InvalidInput, "rule does not belong to the specified bucket")
+	}
+
+	return s.EvaluateRule(ctx, rule, time.Now().UTC())
+}
+
+// EvaluateRule walks every version under the rule's prefix, key by key, and collects the
+// actions the rule selects at now, followed by the multipart uploads it aborts.
+func (s *LifecycleService) EvaluateRule(ctx context.Context, rule *domain.LifecycleRule, now time.Time) (*domain.LifecycleReport, error) {
+	report := &domain.LifecycleReport{
+		RuleID:      rule.ID,
+		Bucket:      rule.BucketName,
+		EvaluatedAt: now,
+		Actions:     []domain.LifecycleAction{},
+	}
+
+	startAfter := ""
+	for {
+		versions, err := s.storageRepo.ListVersionsPage(ctx, rule.BucketName, rule.Prefix, startAfter, lifecycleScanBatch)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := 0
+		for start := 0; start < len(versions); {
+			end := start + 1
+			for end < len(versions) && versions[end].Key == versions[start].Key {
+				end++
+			}
+			report.Add(rule.PlanVersions(versions[start:end], now)...)
+			startAfter = versions[start].Key
+			keys++
+			start = end
+		}
+
+		if keys < lifecycleScanBatch {
+			break
+		}
+	}
+
+	if rule.AbortIncompleteMultipartDays > 0 {
+		cutoff := now.Add(-time.Duration(rule.AbortIncompleteMultipartDays) * 24 * time.Hour)
+		uploads, err := s.storageRepo.ListMultipartUploads(ctx, rule.BucketName, rule.Prefix, cutoff)
+		if err != nil {
+			return nil, err
+		}
+		for _, upload := range uploads {
+			if rule.AbortsUpload(upload, now) {
+				report.Add(domain.NewAbortMultipartAction(upload))
+			}
+		}
+	}
+
+	return report, nil
+}
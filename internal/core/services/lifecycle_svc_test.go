@@ -2,12 +2,15 @@ package services_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	appcontext "github.com/poyrazk/thecloud/internal/core/context"
 	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -34,7 +37,7 @@ func TestCreateRule(t *testing.T) {
 			return r.BucketName == bucketName && r.Prefix == "logs/" && r.ExpirationDays == 30
 		})).Return(nil).Once()
 
-		rule, err := svc.CreateRule(ctx, bucketName, "logs/", 30, true)
+		rule, err := svc.CreateRule(ctx, bucketName, &domain.LifecycleRule{Prefix: "logs/", ExpirationDays: 30, Enabled: true})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, rule)
@@ -54,7 +57,7 @@ func TestCreateRule(t *testing.T) {
 			UserID: userID,
 		}, nil).Once()
 
-		rule, err := svc.CreateRule(ctxOther, bucketName, "logs/", 30, true)
+		rule, err := svc.CreateRule(ctxOther, bucketName, &domain.LifecycleRule{Prefix: "logs/", ExpirationDays: 30, Enabled: true})
 
 		assert.Error(t, err)
 		assert.Nil(t, rule)
@@ -165,3 +168,129 @@ func TestDeleteRule(t *testing.T) {
 		assert.Contains(t, err.Error(), "you don't own this bucket")
 	})
 }
+
+func TestCreateRuleValidation(t *testing.T) {
+	t.Parallel()
+	mockRepo := new(MockLifecycleRepository)
+	mockStorageRepo := new(MockStorageRepo)
+	svc := services.NewLifecycleService(mockRepo, mockStorageRepo)
+
+	userID := uuid.New()
+	ctx := appcontext.WithUserID(context.Background(), userID)
+	mockStorageRepo.On("GetBucket", mock.Anything, "test-bucket").Return(&domain.Bucket{Name: "test-bucket", UserID: userID}, nil)
+
+	_, err := svc.CreateRule(ctx, "test-bucket", &domain.LifecycleRule{Prefix: "logs/", Enabled: true})
+	assert.True(t, errors.Is(err, errors.InvalidInput))
+
+	_, err = svc.CreateRule(ctx, "test-bucket", &domain.LifecycleRule{TransitionDays: 30, TransitionStorageClass: domain.StorageClassStandard})
+	assert.True(t, errors.Is(err, errors.InvalidInput))
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestEvaluateRule(t *testing.T) {
+	t.Parallel()
+	mockRepo := new(MockLifecycleRepository)
+	mockStorageRepo := new(MockStorageRepo)
+	svc := services.NewLifecycleService(mockRepo, mockStorageRepo)
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(d int) time.Time { return now.Add(-time.Duration(d) * 24 * time.Hour) }
+	rule := &domain.LifecycleRule{
+		ID:                           uuid.New(),
+		BucketName:                   "logs",
+		Prefix:                       "app/",
+		NoncurrentExpirationDays:     7,
+		AbortIncompleteMultipartDays: 3,
+		TransitionDays:               30,
+		TransitionStorageClass:       domain.StorageClassErasureCoded,
+	}
+
+	versions := []*domain.Object{
+		// a.log: current is old enough to transition; v1 became noncurrent 20 days ago.
+		{Key: "app/a.log", VersionID: "v2", IsLatest: true, CreatedAt: daysAgo(40), StorageClass: domain.StorageClassStandard},
+		{Key: "app/a.log", VersionID: "v1", CreatedAt: daysAgo(60), SizeBytes: 10, StorageClass: domain.StorageClassStandard},
+		// b.log: v1 only became noncurrent 2 days ago.
+		{Key: "app/b.log", VersionID: "v2", IsLatest: true, CreatedAt: daysAgo(2), StorageClass: domain.StorageClassStandard},
+		{Key: "app/b.log", VersionID: "v1", CreatedAt: daysAgo(5), StorageClass: domain.StorageClassStandard},
+	}
+	mockStorageRepo.On("ListVersionsPage", mock.Anything, "logs", "app/", "", 500).Return(versions, nil).Once()
+
+	upload := &domain.MultipartUpload{ID: uuid.New(), Bucket: "logs", Key: "app/big.bin", CreatedAt: daysAgo(4)}
+	mockStorageRepo.On("ListMultipartUploads", mock.Anything, "logs", "app/", daysAgo(3)).
+		Return([]*domain.MultipartUpload{upload}, nil).Once()
+
+	report, err := svc.EvaluateRule(context.Background(), rule, now)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.LifecycleAction{
+		{Type: domain.LifecycleActionTransition, Key: "app/a.log", VersionID: "v2", StorageClass: domain.StorageClassErasureCoded},
+		{Type: domain.LifecycleActionExpireNoncurrent, Key: "app/a.log", VersionID: "v1", SizeBytes: 10},
+		{Type: domain.LifecycleActionAbortMultipart, Key: "app/big.bin", UploadID: &upload.ID},
+	}, report.Actions)
+	assert.Equal(t, 1, report.NoncurrentExpired)
+	assert.Equal(t, 1, report.ObjectsTransitioned)
+	assert.Equal(t, 1, report.UploadsAborted)
+	assert.Equal(t, int64(10), report.BytesExpired)
+	mockStorageRepo.AssertExpectations(t)
+}
+
+func TestEvaluateRulePagesByKey(t *testing.T) {
+	t.Parallel()
+	mockRepo := new(MockLifecycleRepository)
+	mockStorageRepo := new(MockStorageRepo)
+	svc := services.NewLifecycleService(mockRepo, mockStorageRepo)
+
+	now := time.Now().UTC()
+	old := now.Add(-48 * time.Hour)
+	rule := &domain.LifecycleRule{ID: uuid.New(), BucketName: "logs", ExpirationDays: 1}
+
+	firstPage := make([]*domain.Object, 0, 500)
+	for i := 0; i < 500; i++ {
+		firstPage = append(firstPage, &domain.Object{Key: fmt.Sprintf("k%04d", i), VersionID: "null", IsLatest: true, CreatedAt: old})
+	}
+	mockStorageRepo.On("ListVersionsPage", mock.Anything, "logs", "", "", 500).Return(firstPage, nil).Once()
+	mockStorageRepo.On("ListVersionsPage", mock.Anything, "logs", "", "k0499", 500).
+		Return([]*domain.Object{{Key: "k0500", VersionID: "null", IsLatest: true, CreatedAt: old}}, nil).Once()
+
+	report, err := svc.EvaluateRule(context.Background(), rule, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 501, report.ObjectsExpired)
+	mockStorageRepo.AssertExpectations(t)
+}
+
+func TestPreviewRule(t *testing.T) {
+	t.Parallel()
+	mockRepo := new(MockLifecycleRepository)
+	mockStorageRepo := new(MockStorageRepo)
+	svc := services.NewLifecycleService(mockRepo, mockStorageRepo)
+
+	userID := uuid.New()
+	bucketName := "test-bucket"
+	ctx := appcontext.WithUserID(context.Background(), userID)
+	ruleID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockStorageRepo.On("GetBucket", ctx, bucketName).Return(&domain.Bucket{Name: bucketName, UserID: userID}, nil).Once()
+		mockRepo.On("Get", ctx, ruleID).Return(&domain.LifecycleRule{ID: ruleID, BucketName: bucketName, ExpirationDays: 1}, nil).Once()
+		mockStorageRepo.On("ListVersionsPage", ctx, bucketName, "", "", 500).
+			Return([]*domain.Object{{Key: "old.txt", IsLatest: true, CreatedAt: time.Now().Add(-72 * time.Hour)}}, nil).Once()
+
+		report, err := svc.PreviewRule(ctx, bucketName, ruleID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, ruleID, report.RuleID)
+		assert.Equal(t, 1, report.ObjectsExpired)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		ctxOther := appcontext.WithUserID(context.Background(), uuid.New())
+		mockStorageRepo.On("GetBucket", ctxOther, bucketName).Return(&domain.Bucket{Name: bucketName, UserID: userID}, nil).Once()
+
+		_, err := svc.PreviewRule(ctxOther, bucketName, ruleID.String())
+		assert.True(t, errors.Is(err, errors.Forbidden))
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		_, err := svc.PreviewRule(ctx, bucketName, "not-a-uuid")
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+	})
+}
@@ -26,7 +26,7 @@ func TestLifecycleService_Unit(t *testing.T) {
 		mockStorageRepo.On("GetBucket", mock.Anything, "my-bucket").Return(bucket, nil).Once()
 		mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
 
-		rule, err := svc.CreateRule(ctx, "my-bucket", "logs/", 30, true)
+		rule, err := svc.CreateRule(ctx, "my-bucket", &domain.LifecycleRule{Prefix: "logs/", ExpirationDays: 30, Enabled: true})
 		assert.NoError(t, err)
 		assert.NotNil(t, rule)
 		assert.Equal(t, 30, rule.ExpirationDays)
@@ -37,7 +37,7 @@ func TestLifecycleService_Unit(t *testing.T) {
 		bucket := &domain.Bucket{Name: "other-bucket", UserID: uuid.New()} // Different owner
 		mockStorageRepo.On("GetBucket", mock.Anything, "other-bucket").Return(bucket, nil).Once()
 
-		rule, err := svc.CreateRule(ctx, "other-bucket", "", 10, true)
+		rule, err := svc.CreateRule(ctx, "other-bucket", &domain.LifecycleRule{ExpirationDays: 10, Enabled: true})
 		assert.Error(t, err)
 		assert.Nil(t, rule)
 		// Fix: Check for uppercase "FORBIDDEN" as returned by the internal/errors package
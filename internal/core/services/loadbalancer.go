@@ -17,16 +17,27 @@ type LBService struct {
 	lbRepo       ports.LBRepository
 	vpcRepo      ports.VpcRepository
 	instanceRepo ports.InstanceRepository
+	certSvc      ports.CertificateService
 	auditSvc     ports.AuditService
 }
 
+// LBServiceParams holds the dependencies for an LBService.
+type LBServiceParams struct {
+	LBRepo       ports.LBRepository
+	VpcRepo      ports.VpcRepository
+	InstanceRepo ports.InstanceRepository
+	CertSvc      ports.CertificateService // Optional; required for HTTPS listeners
+	AuditSvc     ports.AuditService
+}
+
 // NewLBService constructs an LBService with its dependencies.
-func NewLBService(lbRepo ports.LBRepository, vpcRepo ports.VpcRepository, instanceRepo ports.InstanceRepository, auditSvc ports.AuditService) *LBService {
+func NewLBService(params LBServiceParams) *LBService {
 	return &LBService{
-		lbRepo:       lbRepo,
-		vpcRepo:      vpcRepo,
-		instanceRepo: instanceRepo,
-		auditSvc:     auditSvc,
+		lbRepo:       params.LBRepo,
+		vpcRepo:      params.VpcRepo,
+		instanceRepo: params.InstanceRepo,
+		certSvc:      params.CertSvc,
+		auditSvc:     params.AuditSvc,
 	}
 }
 
@@ -106,7 +117,169 @@ func (s *LBService) Delete(ctx context.Context, idOrName string) error {
 	return nil
 }
 
+// ConfigureTLS enables, replaces or (with a nil cfg) disables the HTTPS
+// listener of a load balancer. The proxy is redeployed so the listener port
+// is published.
+func (s *LBService) ConfigureTLS(ctx context.Context, idOrName string, cfg *domain.LBTLSConfig) (*domain.LoadBalancer, error) {
+	lb, err := s.Get(ctx, idOrName)
+	if err != nil {
+		return nil, err
+	}
+	if lb.Status == domain.LBStatusDeleted {
+		return nil, errors.New(errors.NotFound, "load balancer not found")
+	}
+
+	if cfg != nil {
+		if err := s.validateTLS(ctx, lb, cfg); err != nil {
+			return nil, err
+		}
+		cfg = &domain.LBTLSConfig{Port: cfg.Port, CertificateIDs: cfg.CertificateIDs, RedirectHTTP: cfg.RedirectHTTP}
+	}
+
+	lb.TLS = cfg
+	lb.Status = domain.LBStatusCreating
+	if err := s.lbRepo.Update(ctx, lb); err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{"enabled": cfg != nil}
+	if cfg != nil {
+		details["port"] = cfg.Port
+		details["certificates"] = len(cfg.CertificateIDs)
+	}
+	_ = s.auditSvc.Log(ctx, lb.UserID, "lb.tls_update", "loadbalancer", lb.ID.String(), details)
+
+	return lb, nil
+}
+
+func (s *LBService) validateTLS(ctx context.Context, lb *domain.LoadBalancer, cfg *domain.LBTLSConfig) error {
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		return errors.New(errors.InvalidInput, "tls port must be between 1 and 65535")
+	}
+	if cfg.Port == lb.Port {
+		return errors.New(errors.InvalidInput, "tls port must differ from the load balancer port")
+	}
+	for _, l := range lb.Listeners {
+		if l.Port == cfg.Port {
+			return errors.New(errors.InvalidInput, "tls port is used by another listener")
+		}
+	}
+	if len(cfg.CertificateIDs) == 0 {
+		return errors.New(errors.InvalidInput, "at least one certificate is required")
+	}
+	if s.certSvc == nil {
+		return errors.New(errors.InvalidInput, "certificate management is not available")
+	}
+
+	seen := make(map[uuid.UUID]bool, len(cfg.CertificateIDs))
+	for _, id := range cfg.CertificateIDs {
+		if seen[id] {
+			return errors.New(errors.InvalidInput, "duplicate certificate "+id.String())
+		}
+		seen[id] = true
+		cert, err := s.certSvc.GetCertificate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if cert.Status == domain.CertificateStatusExpired {
+			return errors.New(errors.InvalidInput, "certificate "+cert.Name+" has expired")
+		}
+	}
+	return nil
+}
+
+// ConfigureListeners replaces the load balancer's layer-7 listeners. An
+// empty list restores the single listener on the load balancer's port. The
+// proxy is redeployed so new listener ports are published.
+func (s *LBService) ConfigureListeners(ctx context.Context, idOrName string, listeners []domain.LBListener) (*domain.LoadBalancer, error) {
+	lb, err := s.Get(ctx, idOrName)
+	if err != nil {
+		return nil, err
+	}
+	if lb.Status == domain.LBStatusDeleted {
+		return nil, errors.New(errors.NotFound, "load balancer not found")
+	}
+
+	previous := lb.Listeners
+	lb.Listeners = listeners
+	if err := lb.ValidateListeners(); err != nil {
+		lb.Listeners = previous
+		return nil, errors.New(errors.InvalidInput, err.Error())
+	}
+
+	lb.Status = domain.LBStatusCreating
+	if err := s.lbRepo.Update(ctx, lb); err != nil {
+		return nil, err
+	}
+
+	rules := 0
+	for _, l := range listeners {
+		rules += len(l.Rules)
+	}
+	_ = s.auditSvc.Log(ctx, lb.UserID, "lb.listeners_update", "loadbalancer", lb.ID.String(), map[string]interface{}{
+		"listeners": len(listeners),
+		"rules":     rules,
+	})
+
+	return lb, nil
+}
+
+// ConfigureAttributes replaces the target policies of a load balancer: the
+// health check, the deregistration delay and sticky sessions. The LB worker
+// picks the change up on its next pass, so the proxy keeps serving meanwhile.
+func (s *LBService) ConfigureAttributes(ctx context.Context, idOrName string, params ports.UpdateLBAttributesParams) (*domain.LoadBalancer, error) {
+	lb, err := s.Get(ctx, idOrName)
+	if err != nil {
+		return nil, err
+	}
+	if lb.Status == domain.LBStatusDeleted {
+		return nil, errors.New(errors.NotFound, "load balancer not found")
+	}
+
+	if params.HealthCheck != nil {
+		if err := params.HealthCheck.Validate(); err != nil {
+			return nil, errors.New(errors.InvalidInput, err.Error())
+		}
+	}
+	if params.StickySessions != nil {
+		if err := params.StickySessions.Validate(); err != nil {
+			return nil, errors.New(errors.InvalidInput, err.Error())
+		}
+	}
+	if params.DeregistrationDelaySeconds < 0 || params.DeregistrationDelaySeconds > domain.MaxLBDeregistrationDelay {
+		return nil, errors.New(errors.InvalidInput, "deregistration delay must be between 0 and 3600 seconds")
+	}
+
+	lb.HealthCheck = params.HealthCheck
+	lb.DeregistrationDelaySeconds = params.DeregistrationDelaySeconds
+	lb.StickySessions = params.StickySessions
+	if err := s.lbRepo.Update(ctx, lb); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, lb.UserID, "lb.attributes_update", "loadbalancer", lb.ID.String(), map[string]interface{}{
+		"http_health_check":    params.HealthCheck != nil && params.HealthCheck.Path != "",
+		"deregistration_delay": params.DeregistrationDelaySeconds,
+		"sticky_sessions":      params.StickySessions != nil,
+	})
+
+	return lb, nil
+}
+
 func (s *LBService) AddTarget(ctx context.Context, lbID, instanceID uuid.UUID, port int, weight int) error {
+	return s.AddTargetToGroup(ctx, lbID, instanceID, port, weight, domain.DefaultLBTargetGroup)
+}
+
+// AddTargetToGroup registers an instance into a target group that listener
+// rules can route to. An empty group is the default group.
+func (s *LBService) AddTargetToGroup(ctx context.Context, lbID, instanceID uuid.UUID, port int, weight int, group string) error {
+	if group == "" {
+		group = domain.DefaultLBTargetGroup
+	}
+	if !domain.ValidTargetGroupName(group) {
+		return errors.New(errors.InvalidInput, "invalid target group name")
+	}
+
 	// Get LB
 	lb, err := s.lbRepo.GetByID(ctx, lbID)
 	if err != nil {
@@ -129,12 +302,13 @@ func (s *LBService) AddTarget(ctx context.Context, lbID, instanceID uuid.UUID, p
 	}
 
 	target := &domain.LBTarget{
-		ID:         uuid.New(),
-		LBID:       lbID,
-		InstanceID: instanceID,
-		Port:       port,
-		Weight:     weight,
-		Health:     "unknown",
+		ID:          uuid.New(),
+		LBID:        lbID,
+		InstanceID:  instanceID,
+		Port:        port,
+		Weight:      weight,
+		Health:      domain.LBTargetHealthUnknown,
+		TargetGroup: group,
 	}
 
 	if err := s.lbRepo.AddTarget(ctx, target); err != nil {
@@ -142,30 +316,84 @@ func (s *LBService) AddTarget(ctx context.Context, lbID, instanceID uuid.UUID, p
 	}
 
 	_ = s.auditSvc.Log(ctx, lb.UserID, "lb.target_add", "loadbalancer", lb.ID.String(), map[string]interface{}{
-		"instance_id": instanceID.String(),
-		"port":        port,
+		"instance_id":  instanceID.String(),
+		"port":         port,
+		"target_group": group,
 	})
 
 	return nil
 }
 
 func (s *LBService) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID) error {
+	return s.RemoveTargetFromGroup(ctx, lbID, instanceID, domain.DefaultLBTargetGroup)
+}
+
+// RemoveTargetFromGroup unregisters an instance from a target group; its
+// membership in other groups is kept. With a deregistration delay the target
+// is drained first: it stops receiving new requests and the LB worker removes
+// it once the delay has passed. Removing a draining target again removes it
+// immediately. An empty group is the default group.
+func (s *LBService) RemoveTargetFromGroup(ctx context.Context, lbID, instanceID uuid.UUID, group string) error {
+	if group == "" {
+		group = domain.DefaultLBTargetGroup
+	}
+
 	lb, err := s.lbRepo.GetByID(ctx, lbID)
 	if err != nil {
 		return err
 	}
 
-	if err := s.lbRepo.RemoveTarget(ctx, lbID, instanceID); err != nil {
+	if lb.DeregistrationDelaySeconds > 0 {
+		drained, err := s.drainTarget(ctx, lb, instanceID, group)
+		if err != nil || drained {
+			return err
+		}
+	}
+
+	if err := s.lbRepo.RemoveTarget(ctx, lbID, instanceID, group); err != nil {
 		return err
 	}
 
 	_ = s.auditSvc.Log(ctx, lb.UserID, "lb.target_remove", "loadbalancer", lb.ID.String(), map[string]interface{}{
-		"instance_id": instanceID.String(),
+		"instance_id":  instanceID.String(),
+		"target_group": group,
 	})
 
 	return nil
 }
 
+func (s *LBService) drainTarget(ctx context.Context, lb *domain.LoadBalancer, instanceID uuid.UUID, group string) (bool, error) {
+	targets, err := s.lbRepo.ListTargets(ctx, lb.ID)
+	if err != nil {
+		return false, err
+	}
+	var target *domain.LBTarget
+	for _, t := range targets {
+		if t.InstanceID == instanceID && t.TargetGroup == group {
+			target = t
+			break
+		}
+	}
+	if target == nil {
+		return false, errors.New(errors.NotFound, "target not found")
+	}
+	if target.Health == domain.LBTargetHealthDraining {
+		return false, nil
+	}
+
+	delay := time.Duration(lb.DeregistrationDelaySeconds) * time.Second
+	if err := s.lbRepo.DrainTarget(ctx, lb.ID, instanceID, group, time.Now().Add(delay)); err != nil {
+		return false, err
+	}
+
+	_ = s.auditSvc.Log(ctx, lb.UserID, "lb.target_drain", "loadbalancer", lb.ID.String(), map[string]interface{}{
+		"instance_id":   instanceID.String(),
+		"target_group":  group,
+		"drain_seconds": lb.DeregistrationDelaySeconds,
+	})
+	return true, nil
+}
+
 func (s *LBService) ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain.LBTarget, error) {
 	return s.lbRepo.ListTargets(ctx, lbID)
 }
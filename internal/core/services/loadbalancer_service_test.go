@@ -3,10 +3,14 @@ package services_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
 	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -19,7 +23,7 @@ func TestLBServiceGet(t *testing.T) {
 	vpcRepo := new(MockVpcRepo)
 	instanceRepo := new(MockInstanceRepo)
 	auditSvc := new(MockAuditService)
-	svc := services.NewLBService(lbRepo, vpcRepo, instanceRepo, auditSvc)
+	svc := services.NewLBService(services.LBServiceParams{LBRepo: lbRepo, VpcRepo: vpcRepo, InstanceRepo: instanceRepo, AuditSvc: auditSvc})
 
 	lbID := uuid.New()
 	lb := &domain.LoadBalancer{ID: lbID, Name: lbMainName}
@@ -37,7 +41,7 @@ func TestLBServiceList(t *testing.T) {
 	vpcRepo := new(MockVpcRepo)
 	instanceRepo := new(MockInstanceRepo)
 	auditSvc := new(MockAuditService)
-	svc := services.NewLBService(lbRepo, vpcRepo, instanceRepo, auditSvc)
+	svc := services.NewLBService(services.LBServiceParams{LBRepo: lbRepo, VpcRepo: vpcRepo, InstanceRepo: instanceRepo, AuditSvc: auditSvc})
 
 	lbRepo.On("List", mock.Anything).Return([]*domain.LoadBalancer{{ID: uuid.New()}}, nil).Once()
 
@@ -53,14 +57,14 @@ func TestLBServiceRemoveTarget(t *testing.T) {
 	vpcRepo := new(MockVpcRepo)
 	instanceRepo := new(MockInstanceRepo)
 	auditSvc := new(MockAuditService)
-	svc := services.NewLBService(lbRepo, vpcRepo, instanceRepo, auditSvc)
+	svc := services.NewLBService(services.LBServiceParams{LBRepo: lbRepo, VpcRepo: vpcRepo, InstanceRepo: instanceRepo, AuditSvc: auditSvc})
 
 	lbID := uuid.New()
 	instanceID := uuid.New()
 	lb := &domain.LoadBalancer{ID: lbID, Name: lbMainName, UserID: uuid.New()}
 
 	lbRepo.On("GetByID", mock.Anything, lbID).Return(lb, nil).Once()
-	lbRepo.On("RemoveTarget", mock.Anything, lbID, instanceID).Return(nil).Once()
+	lbRepo.On("RemoveTarget", mock.Anything, lbID, instanceID, domain.DefaultLBTargetGroup).Return(nil).Once()
 	auditSvc.On("Log", mock.Anything, lb.UserID, "lb.target_remove", "loadbalancer", lb.ID.String(), mock.Anything).Return(nil).Once()
 
 	err := svc.RemoveTarget(context.Background(), lbID, instanceID)
@@ -75,7 +79,7 @@ func TestLBServiceListTargets(t *testing.T) {
 	vpcRepo := new(MockVpcRepo)
 	instanceRepo := new(MockInstanceRepo)
 	auditSvc := new(MockAuditService)
-	svc := services.NewLBService(lbRepo, vpcRepo, instanceRepo, auditSvc)
+	svc := services.NewLBService(services.LBServiceParams{LBRepo: lbRepo, VpcRepo: vpcRepo, InstanceRepo: instanceRepo, AuditSvc: auditSvc})
 
 	lbID := uuid.New()
 	targets := []*domain.LBTarget{{ID: uuid.New(), LBID: lbID}}
@@ -93,7 +97,7 @@ func TestLBServiceDelete(t *testing.T) {
 	vpcRepo := new(MockVpcRepo)
 	instanceRepo := new(MockInstanceRepo)
 	auditSvc := new(MockAuditService)
-	svc := services.NewLBService(lbRepo, vpcRepo, instanceRepo, auditSvc)
+	svc := services.NewLBService(services.LBServiceParams{LBRepo: lbRepo, VpcRepo: vpcRepo, InstanceRepo: instanceRepo, AuditSvc: auditSvc})
 
 	lbID := uuid.New()
 	lb := &domain.LoadBalancer{ID: lbID, Name: lbMainName, UserID: uuid.New()}
@@ -109,3 +113,204 @@ func TestLBServiceDelete(t *testing.T) {
 	lbRepo.AssertExpectations(t)
 	auditSvc.AssertExpectations(t)
 }
+
+func TestLBServiceConfigureTLS(t *testing.T) {
+	t.Parallel()
+	lbRepo := new(MockLBRepo)
+	certRepo := new(MockCertificateRepo)
+	auditSvc := new(MockAuditService)
+	certSvc := services.NewCertificateService(services.CertificateServiceParams{Repo: certRepo, AuditSvc: auditSvc})
+	svc := services.NewLBService(services.LBServiceParams{LBRepo: lbRepo, CertSvc: certSvc, AuditSvc: auditSvc})
+
+	userID := uuid.New()
+	ctx := appcontext.WithUserID(context.Background(), userID)
+	lb := &domain.LoadBalancer{ID: uuid.New(), UserID: userID, Name: lbMainName, Port: 80, Status: domain.LBStatusActive}
+	lbRepo.On("GetByName", mock.Anything, lbMainName).Return(lb, nil)
+
+	mine := &domain.Certificate{ID: uuid.New(), UserID: userID, Name: "web", Status: domain.CertificateStatusActive}
+	expired := &domain.Certificate{ID: uuid.New(), UserID: userID, Name: "old", Status: domain.CertificateStatusExpired}
+	theirs := &domain.Certificate{ID: uuid.New(), UserID: uuid.New(), Name: "theirs"}
+	for _, c := range []*domain.Certificate{mine, expired, theirs} {
+		certRepo.On("GetByID", mock.Anything, c.ID).Return(c, nil)
+	}
+
+	invalid := map[string]*domain.LBTLSConfig{
+		"listener port reused": {Port: 80, CertificateIDs: []uuid.UUID{mine.ID}},
+		"port out of range":    {Port: 70000, CertificateIDs: []uuid.UUID{mine.ID}},
+		"no certificates":      {Port: 443},
+		"duplicate":            {Port: 443, CertificateIDs: []uuid.UUID{mine.ID, mine.ID}},
+		"expired":              {Port: 443, CertificateIDs: []uuid.UUID{expired.ID}},
+	}
+	for name, cfg := range invalid {
+		_, err := svc.ConfigureTLS(ctx, lbMainName, cfg)
+		assert.True(t, errors.Is(err, errors.InvalidInput), name)
+	}
+	_, err := svc.ConfigureTLS(ctx, lbMainName, &domain.LBTLSConfig{Port: 443, CertificateIDs: []uuid.UUID{theirs.ID}})
+	assert.True(t, errors.Is(err, errors.NotFound), "another user's certificate")
+	lbRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	lbRepo.On("Update", mock.Anything, lb).Return(nil)
+	auditSvc.On("Log", mock.Anything, userID, "lb.tls_update", "loadbalancer", lb.ID.String(), mock.Anything).Return(nil)
+
+	res, err := svc.ConfigureTLS(ctx, lbMainName, &domain.LBTLSConfig{Port: 443, CertificateIDs: []uuid.UUID{mine.ID}, RedirectHTTP: true})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.LBStatusCreating, res.Status, "the proxy is redeployed with the new listener")
+	assert.Equal(t, &domain.LBTLSConfig{Port: 443, CertificateIDs: []uuid.UUID{mine.ID}, RedirectHTTP: true}, res.TLS)
+
+	res, err = svc.ConfigureTLS(ctx, lbMainName, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, res.TLS)
+	lbRepo.AssertNumberOfCalls(t, "Update", 2)
+}
+
+func TestLBServiceConfigureListeners(t *testing.T) {
+	t.Parallel()
+	lbRepo := new(MockLBRepo)
+	auditSvc := new(MockAuditService)
+	svc := services.NewLBService(services.LBServiceParams{LBRepo: lbRepo, AuditSvc: auditSvc})
+
+	userID := uuid.New()
+	ctx := appcontext.WithUserID(context.Background(), userID)
+	lb := &domain.LoadBalancer{ID: uuid.New(), UserID: userID, Name: lbMainName, Port: 80, Status: domain.LBStatusActive,
+		TLS: &domain.LBTLSConfig{Port: 443}}
+	lbRepo.On("GetByName", mock.Anything, lbMainName).Return(lb, nil)
+
+	_, err := svc.ConfigureListeners(ctx, lbMainName, []domain.LBListener{{Port: 443}})
+	assert.True(t, errors.Is(err, errors.InvalidInput), "the https port is taken")
+	assert.Nil(t, lb.Listeners, "a rejected configuration is not kept")
+	lbRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	lbRepo.On("Update", mock.Anything, lb).Return(nil)
+	auditSvc.On("Log", mock.Anything, userID, "lb.listeners_update", "loadbalancer", lb.ID.String(),
+		map[string]interface{}{"listeners": 2, "rules": 1}).Return(nil)
+
+	listeners := []domain.LBListener{
+		{Port: 80, Rules: []domain.LBListenerRule{{Priority: 1, PathPrefix: "/api", TargetGroup: "api"}}},
+		{Port: 8080, DefaultTargetGroup: "admin"},
+	}
+	res, err := svc.ConfigureListeners(ctx, lbMainName, listeners)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.LBStatusCreating, res.Status, "the proxy is redeployed to publish new ports")
+	assert.Equal(t, listeners, res.Listeners)
+	auditSvc.AssertExpectations(t)
+}
+
+func TestLBServiceAddTargetToGroup(t *testing.T) {
+	t.Parallel()
+	lbRepo := new(MockLBRepo)
+	instRepo := new(MockInstanceRepo)
+	auditSvc := new(MockAuditService)
+	svc := services.NewLBService(services.LBServiceParams{LBRepo: lbRepo, InstanceRepo: instRepo, AuditSvc: auditSvc})
+
+	ctx := context.Background()
+	vpcID := uuid.New()
+	lb := &domain.LoadBalancer{ID: uuid.New(), VpcID: vpcID}
+	inst := &domain.Instance{ID: uuid.New(), VpcID: &vpcID}
+
+	err := svc.AddTargetToGroup(ctx, lb.ID, inst.ID, 80, 1, "Bad_Group")
+	assert.True(t, errors.Is(err, errors.InvalidInput))
+
+	lbRepo.On("GetByID", mock.Anything, lb.ID).Return(lb, nil)
+	instRepo.On("GetByID", mock.Anything, inst.ID).Return(inst, nil)
+	auditSvc.On("Log", mock.Anything, mock.Anything, "lb.target_add", "loadbalancer", lb.ID.String(), mock.Anything).Return(nil)
+	lbRepo.On("AddTarget", mock.Anything, mock.MatchedBy(func(t *domain.LBTarget) bool {
+		return t.InstanceID == inst.ID && t.TargetGroup == "api" && t.Weight == 1
+	})).Return(nil).Once()
+	lbRepo.On("AddTarget", mock.Anything, mock.MatchedBy(func(t *domain.LBTarget) bool {
+		return t.TargetGroup == domain.DefaultLBTargetGroup
+	})).Return(nil).Once()
+
+	assert.NoError(t, svc.AddTargetToGroup(ctx, lb.ID, inst.ID, 80, 0, "api"))
+	assert.NoError(t, svc.AddTarget(ctx, lb.ID, inst.ID, 80, 1))
+	lbRepo.AssertExpectations(t)
+}
+
+func TestLBServiceConfigureAttributes(t *testing.T) {
+	t.Parallel()
+	lbRepo := new(MockLBRepo)
+	auditSvc := new(MockAuditService)
+	svc := services.NewLBService(services.LBServiceParams{LBRepo: lbRepo, AuditSvc: auditSvc})
+
+	userID := uuid.New()
+	ctx := appcontext.WithUserID(context.Background(), userID)
+	lb := &domain.LoadBalancer{ID: uuid.New(), UserID: userID, Name: lbMainName, Port: 80, Status: domain.LBStatusActive}
+	lbRepo.On("GetByName", mock.Anything, lbMainName).Return(lb, nil)
+
+	_, err := svc.ConfigureAttributes(ctx, lbMainName, ports.UpdateLBAttributesParams{HealthCheck: &domain.HealthCheckConfig{Path: "health"}})
+	assert.True(t, errors.Is(err, errors.InvalidInput), "the path must be absolute")
+	_, err = svc.ConfigureAttributes(ctx, lbMainName, ports.UpdateLBAttributesParams{DeregistrationDelaySeconds: domain.MaxLBDeregistrationDelay + 1})
+	assert.True(t, errors.Is(err, errors.InvalidInput))
+	_, err = svc.ConfigureAttributes(ctx, lbMainName, ports.UpdateLBAttributesParams{StickySessions: &domain.LBStickySessions{CookieName: "bad cookie"}})
+	assert.True(t, errors.Is(err, errors.InvalidInput))
+	lbRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	lbRepo.On("Update", mock.Anything, lb).Return(nil)
+	auditSvc.On("Log", mock.Anything, userID, "lb.attributes_update", "loadbalancer", lb.ID.String(), mock.Anything).Return(nil)
+
+	res, err := svc.ConfigureAttributes(ctx, lbMainName, ports.UpdateLBAttributesParams{
+		HealthCheck:                &domain.HealthCheckConfig{Path: "/healthz"},
+		DeregistrationDelaySeconds: 30,
+		StickySessions:             &domain.LBStickySessions{},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.LBStatusActive, res.Status, "the proxy keeps serving while the worker applies the change")
+	assert.Equal(t, &domain.HealthCheckConfig{
+		Path: "/healthz", IntervalSeconds: domain.DefaultLBHealthCheckInterval, TimeoutSeconds: domain.DefaultLBHealthCheckTimeout,
+		HealthyThreshold: domain.DefaultLBHealthCheckThreshold, UnhealthyThreshold: domain.DefaultLBHealthCheckThreshold,
+	}, res.HealthCheck)
+	assert.Equal(t, &domain.LBStickySessions{CookieName: domain.DefaultLBStickyCookie, DurationSeconds: domain.DefaultLBStickyDuration}, res.StickySessions)
+	assert.Equal(t, 30, res.DeregistrationDelaySeconds)
+	auditSvc.AssertExpectations(t)
+}
+
+func TestLBServiceRemoveTargetFromGroup(t *testing.T) {
+	t.Parallel()
+	lbRepo := new(MockLBRepo)
+	auditSvc := new(MockAuditService)
+	svc := services.NewLBService(services.LBServiceParams{LBRepo: lbRepo, AuditSvc: auditSvc})
+
+	lb := &domain.LoadBalancer{ID: uuid.New(), UserID: uuid.New()}
+	instanceID := uuid.New()
+	lbRepo.On("GetByID", mock.Anything, lb.ID).Return(lb, nil).Once()
+	lbRepo.On("RemoveTarget", mock.Anything, lb.ID, instanceID, "api").Return(nil).Once()
+	auditSvc.On("Log", mock.Anything, lb.UserID, "lb.target_remove", "loadbalancer", lb.ID.String(), mock.MatchedBy(func(details map[string]interface{}) bool {
+		return details["target_group"] == "api"
+	})).Return(nil).Once()
+
+	assert.NoError(t, svc.RemoveTargetFromGroup(context.Background(), lb.ID, instanceID, "api"))
+	lbRepo.AssertExpectations(t)
+	auditSvc.AssertExpectations(t)
+}
+
+func TestLBServiceRemoveTargetDrains(t *testing.T) {
+	t.Parallel()
+	lbRepo := new(MockLBRepo)
+	auditSvc := new(MockAuditService)
+	svc := services.NewLBService(services.LBServiceParams{LBRepo: lbRepo, AuditSvc: auditSvc})
+
+	ctx := context.Background()
+	lb := &domain.LoadBalancer{ID: uuid.New(), UserID: uuid.New(), DeregistrationDelaySeconds: 30}
+	serving := &domain.LBTarget{InstanceID: uuid.New(), TargetGroup: domain.DefaultLBTargetGroup, Health: domain.LBTargetHealthHealthy}
+	draining := &domain.LBTarget{InstanceID: uuid.New(), TargetGroup: domain.DefaultLBTargetGroup, Health: domain.LBTargetHealthDraining}
+	// The draining instance still serves another group; that membership is not the one being removed.
+	other := &domain.LBTarget{InstanceID: draining.InstanceID, TargetGroup: "api", Health: domain.LBTargetHealthHealthy}
+	lbRepo.On("GetByID", mock.Anything, lb.ID).Return(lb, nil)
+	lbRepo.On("ListTargets", mock.Anything, lb.ID).Return([]*domain.LBTarget{serving, other, draining}, nil)
+
+	before := time.Now()
+	lbRepo.On("DrainTarget", mock.Anything, lb.ID, serving.InstanceID, domain.DefaultLBTargetGroup, mock.MatchedBy(func(until time.Time) bool {
+		return !until.Before(before.Add(30 * time.Second))
+	})).Return(nil).Once()
+	auditSvc.On("Log", mock.Anything, lb.UserID, "lb.target_drain", "loadbalancer", lb.ID.String(), mock.Anything).Return(nil).Once()
+	assert.NoError(t, svc.RemoveTarget(ctx, lb.ID, serving.InstanceID))
+	lbRepo.AssertNotCalled(t, "RemoveTarget", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	lbRepo.On("RemoveTarget", mock.Anything, lb.ID, draining.InstanceID, domain.DefaultLBTargetGroup).Return(nil).Once()
+	auditSvc.On("Log", mock.Anything, lb.UserID, "lb.target_remove", "loadbalancer", lb.ID.String(), mock.Anything).Return(nil).Once()
+	assert.NoError(t, svc.RemoveTarget(ctx, lb.ID, draining.InstanceID), "removing a draining target again removes it now")
+
+	err := svc.RemoveTarget(ctx, lb.ID, uuid.New())
+	assert.True(t, errors.Is(err, errors.NotFound))
+	lbRepo.AssertExpectations(t)
+	auditSvc.AssertExpectations(t)
+}
@@ -25,7 +25,7 @@ func setupLBServiceIntegrationTest(t *testing.T) (ports.LBService, *postgres.LBR
 	auditRepo := postgres.NewAuditRepository(db)
 	auditSvc := services.NewAuditService(auditRepo)
 
-	svc := services.NewLBService(lbRepo, vpcRepo, instRepo, auditSvc)
+	svc := services.NewLBService(services.LBServiceParams{LBRepo: lbRepo, VpcRepo: vpcRepo, InstanceRepo: instRepo, AuditSvc: auditSvc})
 	return svc, lbRepo, vpcRepo, instRepo, ctx
 }
 
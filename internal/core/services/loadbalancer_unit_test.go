@@ -17,7 +17,7 @@ func TestLBService_Unit(t *testing.T) {
 	mockVpcRepo := new(MockVpcRepo)
 	mockInstRepo := new(MockInstanceRepo)
 	mockAuditSvc := new(MockAuditService)
-	svc := services.NewLBService(mockRepo, mockVpcRepo, mockInstRepo, mockAuditSvc)
+	svc := services.NewLBService(services.LBServiceParams{LBRepo: mockRepo, VpcRepo: mockVpcRepo, InstanceRepo: mockInstRepo, AuditSvc: mockAuditSvc})
 
 	ctx := context.Background()
 	userID := uuid.New()
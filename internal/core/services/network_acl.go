@@ -0,0 +1,308 @@
+// Package services implements core business workflows.
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const networkACLTracer = "network-acl-service"
+
+// NetworkACLServiceParams holds the dependencies for creating a NetworkACLService.
+type NetworkACLServiceParams struct {
+	Repo       ports.NetworkACLRepository
+	VpcRepo    ports.VpcRepository
+	SubnetRepo ports.SubnetRepository
+	Network    ports.NetworkBackend
+	AuditSvc   ports.AuditService
+	Logger     *slog.Logger
+}
+
+// NetworkACLService manages subnet network ACLs. Whenever an ACL's rules or subnets change,
+// the full rule set is reapplied to the bridge of each affected subnet, where the network
+// backend compiles it into stateless flows evaluated before security groups.
+type NetworkACLService struct {
+	repo       ports.NetworkACLRepository
+	vpcRepo    ports.VpcRepository
+	subnetRepo ports.SubnetRepository
+	network    ports.NetworkBackend
+	auditSvc   ports.AuditService
+	logger     *slog.Logger
+}
+
+// NewNetworkACLService constructs a NetworkACLService with its dependencies.
+func NewNetworkACLService(params NetworkACLServiceParams) *NetworkACLService {
+	return &NetworkACLService{
+		repo:       params.Repo,
+		vpcRepo:    params.VpcRepo,
+		subnetRepo: params.SubnetRepo,
+		network:    params.Network,
+		auditSvc:   params.AuditSvc,
+		logger:     params.Logger,
+	}
+}
+
+// CreateACL adds an empty network ACL to a VPC. It filters nothing until associated with a subnet.
+func (s *NetworkACLService) CreateACL(ctx context.Context, vpcID uuid.UUID, name string) (*domain.NetworkACL, error) {
+	ctx, span := otel.Tracer(networkACLTracer).Start(ctx, "CreateACL")
+	defer span.End()
+	span.SetAttributes(attribute.String("vpc_id", vpcID.String()))
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New(errors.InvalidInput, "network ACL name is required")
+	}
+	if _, err := s.vpcRepo.GetByID(ctx, vpcID); err != nil {
+		return nil, err
+	}
+
+	userID := appcontext.UserIDFromContext(ctx)
+	id := uuid.New()
+	acl := &domain.NetworkACL{
+		ID:        id,
+		UserID:    userID,
+		TenantID:  appcontext.TenantIDFromContext(ctx),
+		VPCID:     vpcID,
+		Name:      name,
+		Rules:     []domain.NetworkACLRule{},
+		SubnetIDs: []uuid.UUID{},
+		ARN:       fmt.Sprintf("arn:thecloud:vpc:local:%s:network-acl/%s", userID.String(), id.String()),
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Create(ctx, acl); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, userID, "network_acl.create", "network_acl", id.String(), map[string]interface{}{
+		"vpc_id": vpcID.String(),
+		"name":   name,
+	})
+	return acl, nil
+}
+
+// GetACL retrieves a network ACL.
+func (s *NetworkACLService) GetACL(ctx context.Context, id uuid.UUID) (*domain.NetworkACL, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListACLs returns a VPC's network ACLs.
+func (s *NetworkACLService) ListACLs(ctx context.Context, vpcID uuid.UUID) ([]*domain.NetworkACL, error) {
+	if _, err := s.vpcRepo.GetByID(ctx, vpcID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByVPC(ctx, vpcID)
+}
+
+// DeleteACL removes a network ACL that no subnet uses.
+func (s *NetworkACLService) DeleteACL(ctx context.Context, id uuid.UUID) error {
+	ctx, span := otel.Tracer(networkACLTracer).Start(ctx, "DeleteACL")
+	defer span.End()
+	span.SetAttributes(attribute.String("acl_id", id.String()))
+
+	acl, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(acl.SubnetIDs) > 0 {
+		return errors.New(errors.Conflict, fmt.Sprintf("network ACL is associated with %d subnet(s); disassociate them first", len(acl.SubnetIDs)))
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "network_acl.delete", "network_acl", id.String(), nil)
+	return nil
+}
+
+// AddRule adds a numbered rule to an ACL and reapplies the ACL to its subnets.
+func (s *NetworkACLService) AddRule(ctx context.Context, aclID uuid.UUID, rule domain.NetworkACLRule) (*domain.NetworkACLRule, error) {
+	ctx, span := otel.Tracer(networkACLTracer).Start(ctx, "AddRule")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("acl_id", aclID.String()),
+		attribute.Int("rule_number", rule.RuleNumber),
+	)
+
+	if err := rule.Validate(); err != nil {
+		return nil, errors.New(errors.InvalidInput, err.Error())
+	}
+	_, network, _ := net.ParseCIDR(rule.CIDR)
+	rule.CIDR = network.String()
+
+	acl, err := s.repo.GetByID(ctx, aclID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.ID = uuid.New()
+	rule.ACLID = aclID
+	rule.CreatedAt = time.Now()
+	if err := s.repo.AddRule(ctx, &rule); err != nil {
+		return nil, err
+	}
+
+	acl.Rules = append(acl.Rules, rule)
+	if err := s.applyToSubnets(ctx, acl); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "network_acl.add_rule", "network_acl", aclID.String(), map[string]interface{}{
+		"rule_id":     rule.ID.String(),
+		"rule_number": rule.RuleNumber,
+		"action":      string(rule.Action),
+	})
+	return &rule, nil
+}
+
+// RemoveRule deletes a rule from an ACL and reapplies the ACL to its subnets.
+func (s *NetworkACLService) RemoveRule(ctx context.Context, aclID, ruleID uuid.UUID) error {
+	ctx, span := otel.Tracer(networkACLTracer).Start(ctx, "RemoveRule")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("acl_id", aclID.String()),
+		attribute.String("rule_id", ruleID.String()),
+	)
+
+	if err := s.repo.DeleteRule(ctx, aclID, ruleID); err != nil {
+		return err
+	}
+	acl, err := s.repo.GetByID(ctx, aclID)
+	if err != nil {
+		return err
+	}
+	if err := s.applyToSubnets(ctx, acl); err != nil {
+		return err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "network_acl.remove_rule", "network_acl", aclID.String(), map[string]interface{}{
+		"rule_id": ruleID.String(),
+	})
+	return nil
+}
+
+// AssociateSubnet applies an ACL to a subnet of the same VPC, replacing any ACL the
+// subnet used before.
+func (s *NetworkACLService) AssociateSubnet(ctx context.Context, aclID, subnetID uuid.UUID) error {
+	ctx, span := otel.Tracer(networkACLTracer).Start(ctx, "AssociateSubnet")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("acl_id", aclID.String()),
+		attribute.String("subnet_id", subnetID.String()),
+	)
+
+	acl, err := s.repo.GetByID(ctx, aclID)
+	if err != nil {
+		return err
+	}
+	subnet, err := s.subnetRepo.GetByID(ctx, subnetID)
+	if err != nil {
+		return err
+	}
+	if subnet.VPCID != acl.VPCID {
+		return errors.New(errors.InvalidInput, "subnet and network ACL belong to different VPCs")
+	}
+	vpc, err := s.vpcRepo.GetByID(ctx, acl.VPCID)
+	if err != nil {
+		return err
+	}
+
+	// Applying replaces whatever ACL flows the subnet had, so no separate cleanup of a
+	// previous association is needed.
+	if err := s.applyToSubnet(ctx, vpc.NetworkID, subnet, acl.Rules); err != nil {
+		return err
+	}
+	if err := s.repo.AssociateSubnet(ctx, aclID, subnetID); err != nil {
+		return err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "network_acl.associate", "network_acl", aclID.String(), map[string]interface{}{
+		"subnet_id": subnetID.String(),
+	})
+	return nil
+}
+
+// DisassociateSubnet stops filtering a subnet's traffic with its network ACL.
+func (s *NetworkACLService) DisassociateSubnet(ctx context.Context, subnetID uuid.UUID) error {
+	ctx, span := otel.Tracer(networkACLTracer).Start(ctx, "DisassociateSubnet")
+	defer span.End()
+	span.SetAttributes(attribute.String("subnet_id", subnetID.String()))
+
+	acl, err := s.repo.GetBySubnet(ctx, subnetID)
+	if err != nil {
+		return err
+	}
+	subnet, err := s.subnetRepo.GetByID(ctx, subnetID)
+	if err != nil {
+		return err
+	}
+	vpc, err := s.vpcRepo.GetByID(ctx, acl.VPCID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DisassociateSubnet(ctx, subnetID); err != nil {
+		return err
+	}
+	for _, cidr := range subnetRanges(subnet) {
+		if err := s.network.RemoveNetworkACL(ctx, vpc.NetworkID, cidr); err != nil {
+			s.logger.Warn("failed to remove network ACL flows", "subnet_id", subnetID, "cidr", cidr, "error", err)
+		}
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "network_acl.disassociate", "network_acl", acl.ID.String(), map[string]interface{}{
+		"subnet_id": subnetID.String(),
+	})
+	return nil
+}
+
+// applyToSubnets reapplies an ACL's current rules to every subnet using it.
+func (s *NetworkACLService) applyToSubnets(ctx context.Context, acl *domain.NetworkACL) error {
+	if len(acl.SubnetIDs) == 0 {
+		return nil
+	}
+	vpc, err := s.vpcRepo.GetByID(ctx, acl.VPCID)
+	if err != nil {
+		return err
+	}
+	for _, subnetID := range acl.SubnetIDs {
+		subnet, err := s.subnetRepo.GetByID(ctx, subnetID)
+		if err != nil {
+			return err
+		}
+		if err := s.applyToSubnet(ctx, vpc.NetworkID, subnet, acl.Rules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyToSubnet installs an ACL's rules for each of a subnet's address ranges.
+func (s *NetworkACLService) applyToSubnet(ctx context.Context, bridge string, subnet *domain.Subnet, rules []domain.NetworkACLRule) error {
+	for _, cidr := range subnetRanges(subnet) {
+		if err := s.network.ApplyNetworkACL(ctx, bridge, cidr, rules); err != nil {
+			return errors.Wrap(errors.Internal, "failed to apply network ACL", err)
+		}
+	}
+	return nil
+}
+
+// subnetRanges lists a subnet's IPv4 block and, when it is dual-stack, its IPv6 block.
+func subnetRanges(subnet *domain.Subnet) []string {
+	if subnet.IPv6CIDRBlock == "" {
+		return []string{subnet.CIDRBlock}
+	}
+	return []string{subnet.CIDRBlock, subnet.IPv6CIDRBlock}
+}
@@ -0,0 +1,210 @@
+package services_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockNetworkACLRepo struct {
+	mock.Mock
+}
+
+func (m *MockNetworkACLRepo) Create(ctx context.Context, acl *domain.NetworkACL) error {
+	return m.Called(ctx, acl).Error(0)
+}
+func (m *MockNetworkACLRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.NetworkACL, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NetworkACL), args.Error(1)
+}
+func (m *MockNetworkACLRepo) GetBySubnet(ctx context.Context, subnetID uuid.UUID) (*domain.NetworkACL, error) {
+	args := m.Called(ctx, subnetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NetworkACL), args.Error(1)
+}
+func (m *MockNetworkACLRepo) ListByVPC(ctx context.Context, vpcID uuid.UUID) ([]*domain.NetworkACL, error) {
+	args := m.Called(ctx, vpcID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.NetworkACL), args.Error(1)
+}
+func (m *MockNetworkACLRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *MockNetworkACLRepo) AddRule(ctx context.Context, rule *domain.NetworkACLRule) error {
+	return m.Called(ctx, rule).Error(0)
+}
+func (m *MockNetworkACLRepo) DeleteRule(ctx context.Context, aclID, ruleID uuid.UUID) error {
+	return m.Called(ctx, aclID, ruleID).Error(0)
+}
+func (m *MockNetworkACLRepo) AssociateSubnet(ctx context.Context, aclID, subnetID uuid.UUID) error {
+	return m.Called(ctx, aclID, subnetID).Error(0)
+}
+func (m *MockNetworkACLRepo) DisassociateSubnet(ctx context.Context, subnetID uuid.UUID) error {
+	return m.Called(ctx, subnetID).Error(0)
+}
+
+func TestNetworkACLService(t *testing.T) {
+	tenantID := uuid.New()
+	ctx := appcontext.WithTenantID(appcontext.WithUserID(context.Background(), uuid.New()), tenantID)
+	vpc := &domain.VPC{ID: uuid.New(), TenantID: tenantID, CIDRBlock: "10.0.0.0/16", NetworkID: "br-vpc-acl"}
+	subnet := &domain.Subnet{ID: uuid.New(), VPCID: vpc.ID, CIDRBlock: "10.0.1.0/24"}
+	dualSubnet := &domain.Subnet{ID: uuid.New(), VPCID: vpc.ID, CIDRBlock: "10.0.2.0/24", IPv6CIDRBlock: "fd00:10:0:2::/64"}
+
+	type deps struct {
+		repo    *MockNetworkACLRepo
+		network *MockNetworkBackend
+	}
+	setup := func() (*services.NetworkACLService, deps) {
+		d := deps{repo: new(MockNetworkACLRepo), network: new(MockNetworkBackend)}
+		vpcRepo := new(MockVpcRepo)
+		subnets := new(MockSubnetRepo)
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		vpcRepo.On("GetByID", mock.Anything, vpc.ID).Return(vpc, nil).Maybe()
+		subnets.On("GetByID", mock.Anything, subnet.ID).Return(subnet, nil).Maybe()
+		subnets.On("GetByID", mock.Anything, dualSubnet.ID).Return(dualSubnet, nil).Maybe()
+		svc := services.NewNetworkACLService(services.NetworkACLServiceParams{
+			Repo: d.repo, VpcRepo: vpcRepo, SubnetRepo: subnets, Network: d.network, AuditSvc: audit, Logger: slog.Default(),
+		})
+		return svc, d
+	}
+	newACL := func(subnets ...uuid.UUID) *domain.NetworkACL {
+		return &domain.NetworkACL{ID: uuid.New(), VPCID: vpc.ID, TenantID: tenantID, Rules: []domain.NetworkACLRule{}, SubnetIDs: subnets}
+	}
+	allowHTTPS := domain.NetworkACLRule{
+		RuleNumber: 100, Direction: domain.RuleIngress, Protocol: "tcp", PortMin: 443, PortMax: 443, CIDR: "0.0.0.0/0", Action: domain.ACLAllow,
+	}
+
+	t.Run("CreateACL", func(t *testing.T) {
+		svc, d := setup()
+		d.repo.On("Create", mock.Anything, mock.MatchedBy(func(acl *domain.NetworkACL) bool {
+			return acl.Name == "web" && acl.VPCID == vpc.ID
+		})).Return(nil).Once()
+
+		acl, err := svc.CreateACL(ctx, vpc.ID, " web ")
+		require.NoError(t, err)
+		assert.Contains(t, acl.ARN, "network-acl/")
+	})
+
+	t.Run("AddRule reapplies the ACL to its subnets", func(t *testing.T) {
+		svc, d := setup()
+		acl := newACL(subnet.ID)
+		d.repo.On("GetByID", mock.Anything, acl.ID).Return(acl, nil)
+		d.repo.On("AddRule", mock.Anything, mock.Anything).Return(nil).Once()
+		d.network.On("ApplyNetworkACL", mock.Anything, "br-vpc-acl", "10.0.1.0/24", mock.MatchedBy(func(rules []domain.NetworkACLRule) bool {
+			return len(rules) == 1 && rules[0].RuleNumber == 100
+		})).Return(nil).Once()
+
+		rule, err := svc.AddRule(ctx, acl.ID, allowHTTPS)
+		require.NoError(t, err)
+		assert.Equal(t, acl.ID, rule.ACLID)
+		d.network.AssertExpectations(t)
+	})
+
+	t.Run("AddRule canonicalizes the CIDR", func(t *testing.T) {
+		svc, d := setup()
+		acl := newACL()
+		d.repo.On("GetByID", mock.Anything, acl.ID).Return(acl, nil)
+		d.repo.On("AddRule", mock.Anything, mock.MatchedBy(func(r *domain.NetworkACLRule) bool {
+			return r.CIDR == "192.168.0.0/16"
+		})).Return(nil).Once()
+
+		rule := allowHTTPS
+		rule.CIDR = "192.168.4.7/16"
+		_, err := svc.AddRule(ctx, acl.ID, rule)
+		require.NoError(t, err)
+		d.network.AssertNotCalled(t, "ApplyNetworkACL", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AddRule validates", func(t *testing.T) {
+		svc, _ := setup()
+		rule := allowHTTPS
+		rule.RuleNumber = domain.MaxACLRuleNumber + 1
+		_, err := svc.AddRule(ctx, uuid.New(), rule)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+	})
+
+	t.Run("AssociateSubnet applies the rules", func(t *testing.T) {
+		svc, d := setup()
+		acl := newACL()
+		acl.Rules = []domain.NetworkACLRule{allowHTTPS}
+		d.repo.On("GetByID", mock.Anything, acl.ID).Return(acl, nil)
+		d.network.On("ApplyNetworkACL", mock.Anything, "br-vpc-acl", "10.0.1.0/24", acl.Rules).Return(nil).Once()
+		d.repo.On("AssociateSubnet", mock.Anything, acl.ID, subnet.ID).Return(nil).Once()
+
+		require.NoError(t, svc.AssociateSubnet(ctx, acl.ID, subnet.ID))
+		d.repo.AssertExpectations(t)
+	})
+
+	t.Run("AssociateSubnet applies the rules to both ranges of a dual-stack subnet", func(t *testing.T) {
+		svc, d := setup()
+		acl := newACL()
+		acl.Rules = []domain.NetworkACLRule{allowHTTPS}
+		d.repo.On("GetByID", mock.Anything, acl.ID).Return(acl, nil)
+		d.network.On("ApplyNetworkACL", mock.Anything, "br-vpc-acl", "10.0.2.0/24", acl.Rules).Return(nil).Once()
+		d.network.On("ApplyNetworkACL", mock.Anything, "br-vpc-acl", "fd00:10:0:2::/64", acl.Rules).Return(nil).Once()
+		d.repo.On("AssociateSubnet", mock.Anything, acl.ID, dualSubnet.ID).Return(nil).Once()
+
+		require.NoError(t, svc.AssociateSubnet(ctx, acl.ID, dualSubnet.ID))
+		d.network.AssertExpectations(t)
+	})
+
+	t.Run("AssociateSubnet rejects a subnet of another VPC", func(t *testing.T) {
+		svc, d := setup()
+		acl := newACL()
+		acl.VPCID = uuid.New()
+		d.repo.On("GetByID", mock.Anything, acl.ID).Return(acl, nil)
+
+		err := svc.AssociateSubnet(ctx, acl.ID, subnet.ID)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+	})
+
+	t.Run("DisassociateSubnet removes the flows", func(t *testing.T) {
+		svc, d := setup()
+		acl := newACL(subnet.ID)
+		d.repo.On("GetBySubnet", mock.Anything, subnet.ID).Return(acl, nil)
+		d.repo.On("DisassociateSubnet", mock.Anything, subnet.ID).Return(nil).Once()
+		d.network.On("RemoveNetworkACL", mock.Anything, "br-vpc-acl", "10.0.1.0/24").Return(nil).Once()
+
+		require.NoError(t, svc.DisassociateSubnet(ctx, subnet.ID))
+		d.network.AssertExpectations(t)
+	})
+
+	t.Run("DeleteACL refuses an associated ACL", func(t *testing.T) {
+		svc, d := setup()
+		acl := newACL(subnet.ID)
+		d.repo.On("GetByID", mock.Anything, acl.ID).Return(acl, nil)
+
+		err := svc.DeleteACL(ctx, acl.ID)
+		assert.True(t, errors.Is(err, errors.Conflict))
+		d.repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("RemoveRule reapplies the remaining rules", func(t *testing.T) {
+		svc, d := setup()
+		acl := newACL(subnet.ID)
+		ruleID := uuid.New()
+		d.repo.On("DeleteRule", mock.Anything, acl.ID, ruleID).Return(nil).Once()
+		d.repo.On("GetByID", mock.Anything, acl.ID).Return(acl, nil)
+		d.network.On("ApplyNetworkACL", mock.Anything, "br-vpc-acl", "10.0.1.0/24", []domain.NetworkACLRule{}).Return(nil).Once()
+
+		require.NoError(t, svc.RemoveRule(ctx, acl.ID, ruleID))
+		d.network.AssertExpectations(t)
+	})
+}
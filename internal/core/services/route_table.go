@@ -0,0 +1,537 @@
+// Package services implements core business workflows.
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	routeTableTracer = "route-table-service"
+
+	// mainRouteBasePriority is added to a route's prefix length for routes of a VPC's main
+	// table, which match on the whole VPC CIDR.
+	mainRouteBasePriority = 100
+	// subnetRouteBasePriority is added to a route's prefix length for routes of explicitly
+	// associated tables, so they take precedence over the main table for their subnets.
+	subnetRouteBasePriority = 200
+
+	mainRouteTableName = "main"
+)
+
+// RouteTableServiceParams holds the dependencies for creating a RouteTableService.
+type RouteTableServiceParams struct {
+	Repo       ports.RouteTableRepository
+	VpcRepo    ports.VpcRepository
+	SubnetRepo ports.SubnetRepository
+	IGWRepo    ports.InternetGatewayRepository
+	NATRepo    ports.NATGatewayRepository
+	Network    ports.NetworkBackend
+	AuditSvc   ports.AuditService
+	Logger     *slog.Logger
+}
+
+// RouteTableService manages VPC route tables and programs their routes as flows on the
+// VPC's bridge. Routes match on the source subnet and the destination range, and more
+// specific destinations get higher priorities so the longest prefix wins.
+type RouteTableService struct {
+	repo       ports.RouteTableRepository
+	vpcRepo    ports.VpcRepository
+	subnetRepo ports.SubnetRepository
+	igwRepo    ports.InternetGatewayRepository
+	natRepo    ports.NATGatewayRepository
+	network    ports.NetworkBackend
+	auditSvc   ports.AuditService
+	logger     *slog.Logger
+}
+
+// NewRouteTableService constructs a RouteTableService with its dependencies.
+func NewRouteTableService(params RouteTableServiceParams) *RouteTableService {
+	return &RouteTableService{
+		repo:       params.Repo,
+		vpcRepo:    params.VpcRepo,
+		subnetRepo: params.SubnetRepo,
+		igwRepo:    params.IGWRepo,
+		natRepo:    params.NATRepo,
+		network:    params.Network,
+		auditSvc:   params.AuditSvc,
+		logger:     params.Logger,
+	}
+}
+
+// routeSource is a source range a table's routes apply to, with the base priority of
+// the flows installed for it.
+type routeSource struct {
+	cidr         string
+	basePriority int
+}
+
+// CreateRouteTable adds a custom route table to a VPC, seeded with the VPC's local route.
+func (s *RouteTableService) CreateRouteTable(ctx context.Context, vpcID uuid.UUID, name string) (*domain.RouteTable, error) {
+	ctx, span := otel.Tracer(routeTableTracer).Start(ctx, "CreateRouteTable")
+	defer span.End()
+	span.SetAttributes(attribute.String("vpc_id", vpcID.String()))
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New(errors.InvalidInput, "route table name is required")
+	}
+	if name == mainRouteTableName {
+		return nil, errors.New(errors.InvalidInput, "route table name \"main\" is reserved")
+	}
+
+	vpc, err := s.vpcRepo.GetByID(ctx, vpcID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.mainTable(ctx, vpc); err != nil {
+		return nil, err
+	}
+
+	rt := s.newRouteTable(ctx, vpc, name, false)
+	if err := s.repo.Create(ctx, rt); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, rt.UserID, "route_table.create", "route_table", rt.ID.String(), map[string]interface{}{
+		"vpc_id": vpcID.String(),
+		"name":   name,
+	})
+
+	return rt, nil
+}
+
+// GetRouteTable retrieves a route table.
+func (s *RouteTableService) GetRouteTable(ctx context.Context, id uuid.UUID) (*domain.RouteTable, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListRouteTables returns a VPC's route tables, creating its main table on first use.
+func (s *RouteTableService) ListRouteTables(ctx context.Context, vpcID uuid.UUID) ([]*domain.RouteTable, error) {
+	vpc, err := s.vpcRepo.GetByID(ctx, vpcID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.mainTable(ctx, vpc); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByVPC(ctx, vpcID)
+}
+
+// DeleteRouteTable removes a custom route table that no subnet uses.
+func (s *RouteTableService) DeleteRouteTable(ctx context.Context, id uuid.UUID) error {
+	ctx, span := otel.Tracer(routeTableTracer).Start(ctx, "DeleteRouteTable")
+	defer span.End()
+	span.SetAttributes(attribute.String("route_table_id", id.String()))
+
+	rt, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if rt.Main {
+		return errors.New(errors.Conflict, "the main route table cannot be deleted")
+	}
+	if len(rt.SubnetIDs) > 0 {
+		return errors.New(errors.Conflict, fmt.Sprintf("route table is associated with %d subnet(s); disassociate them first", len(rt.SubnetIDs)))
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "route_table.delete", "route_table", id.String(), nil)
+	return nil
+}
+
+// AddRoute sends traffic for a destination range to an internet or NAT gateway of the
+// table's VPC.
+func (s *RouteTableService) AddRoute(ctx context.Context, routeTableID uuid.UUID, destinationCIDR string, targetType domain.RouteTargetType, targetID *uuid.UUID) (*domain.Route, error) {
+	ctx, span := otel.Tracer(routeTableTracer).Start(ctx, "AddRoute")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("route_table_id", routeTableID.String()),
+		attribute.String("destination_cidr", destinationCIDR),
+	)
+
+	route := &domain.Route{
+		ID:              uuid.New(),
+		RouteTableID:    routeTableID,
+		DestinationCIDR: destinationCIDR,
+		TargetType:      targetType,
+		TargetID:        targetID,
+		CreatedAt:       time.Now(),
+	}
+	if err := route.Validate(); err != nil {
+		return nil, errors.Wrap(errors.InvalidInput, "invalid route", err)
+	}
+	if targetType == domain.RouteTargetLocal {
+		return nil, errors.New(errors.InvalidInput, "local routes are managed automatically")
+	}
+	route.DestinationCIDR = canonicalCIDR(destinationCIDR)
+
+	rt, err := s.repo.GetByID(ctx, routeTableID)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range rt.Routes {
+		if existing.DestinationCIDR == route.DestinationCIDR {
+			return nil, errors.New(errors.Conflict, fmt.Sprintf("route table already has a route for %s", route.DestinationCIDR))
+		}
+	}
+	if err := s.validateTarget(ctx, rt, route); err != nil {
+		return nil, err
+	}
+
+	vpc, err := s.vpcRepo.GetByID(ctx, rt.VPCID)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := s.sources(ctx, rt, vpc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.installRoute(ctx, vpc.NetworkID, sources, route); err != nil {
+		s.uninstallRoute(ctx, vpc.NetworkID, sources, route)
+		return nil, errors.Wrap(errors.Internal, "failed to program route", err)
+	}
+	if err := s.repo.AddRoute(ctx, route); err != nil {
+		s.uninstallRoute(ctx, vpc.NetworkID, sources, route)
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "route_table.route_add", "route_table", rt.ID.String(), map[string]interface{}{
+		"destination_cidr": route.DestinationCIDR,
+		"target_type":      string(route.TargetType),
+	})
+
+	return route, nil
+}
+
+// RemoveRoute deletes the route for a destination range. The local route stays.
+func (s *RouteTableService) RemoveRoute(ctx context.Context, routeTableID uuid.UUID, destinationCIDR string) error {
+	ctx, span := otel.Tracer(routeTableTracer).Start(ctx, "RemoveRoute")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("route_table_id", routeTableID.String()),
+		attribute.String("destination_cidr", destinationCIDR),
+	)
+
+	rt, err := s.repo.GetByID(ctx, routeTableID)
+	if err != nil {
+		return err
+	}
+
+	dest := canonicalCIDR(destinationCIDR)
+	var route *domain.Route
+	for i := range rt.Routes {
+		if rt.Routes[i].DestinationCIDR == dest {
+			route = &rt.Routes[i]
+			break
+		}
+	}
+	if route == nil {
+		return errors.New(errors.NotFound, fmt.Sprintf("route table has no route for %s", destinationCIDR))
+	}
+	if route.TargetType == domain.RouteTargetLocal {
+		return errors.New(errors.InvalidInput, "the local route cannot be removed")
+	}
+
+	vpc, err := s.vpcRepo.GetByID(ctx, rt.VPCID)
+	if err != nil {
+		return err
+	}
+	sources, err := s.sources(ctx, rt, vpc)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteRoute(ctx, rt.ID, dest); err != nil {
+		return err
+	}
+	s.uninstallRoute(ctx, vpc.NetworkID, sources, route)
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "route_table.route_remove", "route_table", rt.ID.String(), map[string]interface{}{
+		"destination_cidr": dest,
+	})
+	return nil
+}
+
+// AssociateSubnet applies a custom route table to a subnet of the same VPC, replacing
+// any table the subnet was previously associated with.
+func (s *RouteTableService) AssociateSubnet(ctx context.Context, routeTableID, subnetID uuid.UUID) error {
+	ctx, span := otel.Tracer(routeTableTracer).Start(ctx, "AssociateSubnet")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("route_table_id", routeTableID.String()),
+		attribute.String("subnet_id", subnetID.String()),
+	)
+
+	rt, err := s.repo.GetByID(ctx, routeTableID)
+	if err != nil {
+		return err
+	}
+	if rt.Main {
+		return errors.New(errors.InvalidInput, "subnets use the main route table unless associated with another; disassociate the subnet instead")
+	}
+	subnet, err := s.subnetRepo.GetByID(ctx, subnetID)
+	if err != nil {
+		return err
+	}
+	if subnet.VPCID != rt.VPCID {
+		return errors.New(errors.InvalidInput, "subnet and route table belong to different VPCs")
+	}
+	vpc, err := s.vpcRepo.GetByID(ctx, rt.VPCID)
+	if err != nil {
+		return err
+	}
+
+	previous, err := s.repo.GetBySubnet(ctx, subnetID)
+	if err != nil && !errors.Is(err, errors.NotFound) {
+		return err
+	}
+	if previous != nil {
+		if previous.ID == rt.ID {
+			return nil
+		}
+		// The previous table's flows use the same matches and priorities, so they must
+		// go before the new ones are added.
+		s.uninstallRoutes(ctx, vpc.NetworkID, subnetSource(subnet), previous.Routes)
+	}
+
+	source := subnetSource(subnet)
+	for i := range rt.Routes {
+		if err := s.installRoute(ctx, vpc.NetworkID, source, &rt.Routes[i]); err != nil {
+			s.uninstallRoutes(ctx, vpc.NetworkID, source, rt.Routes)
+			return errors.Wrap(errors.Internal, "failed to program subnet routes", err)
+		}
+	}
+	if err := s.repo.AssociateSubnet(ctx, rt.ID, subnetID); err != nil {
+		s.uninstallRoutes(ctx, vpc.NetworkID, source, rt.Routes)
+		return err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "route_table.associate", "route_table", rt.ID.String(), map[string]interface{}{
+		"subnet_id": subnetID.String(),
+	})
+	return nil
+}
+
+// DisassociateSubnet returns a subnet to its VPC's main route table.
+func (s *RouteTableService) DisassociateSubnet(ctx context.Context, subnetID uuid.UUID) error {
+	ctx, span := otel.Tracer(routeTableTracer).Start(ctx, "DisassociateSubnet")
+	defer span.End()
+	span.SetAttributes(attribute.String("subnet_id", subnetID.String()))
+
+	rt, err := s.repo.GetBySubnet(ctx, subnetID)
+	if err != nil {
+		return err
+	}
+	subnet, err := s.subnetRepo.GetByID(ctx, subnetID)
+	if err != nil {
+		return err
+	}
+	vpc, err := s.vpcRepo.GetByID(ctx, rt.VPCID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DisassociateSubnet(ctx, subnetID); err != nil {
+		return err
+	}
+	s.uninstallRoutes(ctx, vpc.NetworkID, subnetSource(subnet), rt.Routes)
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "route_table.disassociate", "route_table", rt.ID.String(), map[string]interface{}{
+		"subnet_id": subnetID.String(),
+	})
+	return nil
+}
+
+// GetSubnetRouteTable returns the route table in effect for a subnet: its explicitly
+// associated table, or else its VPC's main table.
+func (s *RouteTableService) GetSubnetRouteTable(ctx context.Context, subnetID uuid.UUID) (*domain.RouteTable, error) {
+	subnet, err := s.subnetRepo.GetByID(ctx, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	rt, err := s.repo.GetBySubnet(ctx, subnetID)
+	if err == nil {
+		return rt, nil
+	}
+	if !errors.Is(err, errors.NotFound) {
+		return nil, err
+	}
+
+	vpc, err := s.vpcRepo.GetByID(ctx, subnet.VPCID)
+	if err != nil {
+		return nil, err
+	}
+	return s.mainTable(ctx, vpc)
+}
+
+// mainTable returns a VPC's main route table, creating it with the local route when the
+// VPC predates route tables or has not been routed yet.
+func (s *RouteTableService) mainTable(ctx context.Context, vpc *domain.VPC) (*domain.RouteTable, error) {
+	rt, err := s.repo.GetMain(ctx, vpc.ID)
+	if err == nil {
+		return rt, nil
+	}
+	if !errors.Is(err, errors.NotFound) {
+		return nil, err
+	}
+
+	rt = s.newRouteTable(ctx, vpc, mainRouteTableName, true)
+	source := []routeSource{{cidr: vpc.CIDRBlock, basePriority: mainRouteBasePriority}}
+	if err := s.installRoute(ctx, vpc.NetworkID, source, &rt.Routes[0]); err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to program local route", err)
+	}
+	if err := s.repo.Create(ctx, rt); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+func (s *RouteTableService) newRouteTable(ctx context.Context, vpc *domain.VPC, name string, main bool) *domain.RouteTable {
+	userID := appcontext.UserIDFromContext(ctx)
+	id := uuid.New()
+	now := time.Now()
+	return &domain.RouteTable{
+		ID:       id,
+		UserID:   userID,
+		TenantID: vpc.TenantID,
+		VPCID:    vpc.ID,
+		Name:     name,
+		Main:     main,
+		Routes: []domain.Route{{
+			ID:              uuid.New(),
+			RouteTableID:    id,
+			DestinationCIDR: canonicalCIDR(vpc.CIDRBlock),
+			TargetType:      domain.RouteTargetLocal,
+			CreatedAt:       now,
+		}},
+		SubnetIDs: []uuid.UUID{},
+		ARN:       fmt.Sprintf("arn:thecloud:vpc:local:%s:route-table/%s", userID.String(), id.String()),
+		CreatedAt: now,
+	}
+}
+
+// validateTarget checks that a route's gateway serves the table's VPC.
+func (s *RouteTableService) validateTarget(ctx context.Context, rt *domain.RouteTable, route *domain.Route) error {
+	switch route.TargetType {
+	case domain.RouteTargetInternetGateway:
+		igw, err := s.igwRepo.GetByID(ctx, *route.TargetID)
+		if err != nil {
+			return err
+		}
+		if igw.Status != domain.IGWStatusAttached || igw.VPCID == nil || *igw.VPCID != rt.VPCID {
+			return errors.New(errors.InvalidInput, "internet gateway is not attached to the route table's VPC")
+		}
+	case domain.RouteTargetNATGateway:
+		nat, err := s.natRepo.GetByID(ctx, *route.TargetID)
+		if err != nil {
+			return err
+		}
+		if nat.VPCID != rt.VPCID {
+			return errors.New(errors.InvalidInput, "nat gateway is not in the route table's VPC")
+		}
+		if nat.Status != domain.NATStatusAvailable {
+			return errors.New(errors.InvalidInput, fmt.Sprintf("nat gateway is %s", nat.Status))
+		}
+	}
+	return nil
+}
+
+// sources lists the ranges a table's routes apply to: the whole VPC for the main table,
+// and each associated subnet otherwise.
+func (s *RouteTableService) sources(ctx context.Context, rt *domain.RouteTable, vpc *domain.VPC) ([]routeSource, error) {
+	if rt.Main {
+		return []routeSource{{cidr: vpc.CIDRBlock, basePriority: mainRouteBasePriority}}, nil
+	}
+	sources := make([]routeSource, 0, len(rt.SubnetIDs))
+	for _, id := range rt.SubnetIDs {
+		subnet, err := s.subnetRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, subnetSource(subnet)...)
+	}
+	return sources, nil
+}
+
+func (s *RouteTableService) installRoute(ctx context.Context, bridge string, sources []routeSource, route *domain.Route) error {
+	for _, src := range sources {
+		if err := s.network.AddFlowRule(ctx, bridge, routeFlow(src, route)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uninstallRoute removes a route's flows. Failures are logged so the route can still be
+// removed when the bridge is already gone.
+func (s *RouteTableService) uninstallRoute(ctx context.Context, bridge string, sources []routeSource, route *domain.Route) {
+	for _, src := range sources {
+		match := routeFlow(src, route).Match
+		if err := s.network.DeleteFlowRule(ctx, bridge, match); err != nil {
+			s.logger.Warn("failed to delete route flow rule", "bridge", bridge, "match", match, "error", err)
+		}
+	}
+}
+
+func (s *RouteTableService) uninstallRoutes(ctx context.Context, bridge string, sources []routeSource, routes []domain.Route) {
+	for i := range routes {
+		s.uninstallRoute(ctx, bridge, sources, &routes[i])
+	}
+}
+
+func subnetSource(subnet *domain.Subnet) []routeSource {
+	return []routeSource{{cidr: subnet.CIDRBlock, basePriority: subnetRouteBasePriority}}
+}
+
+func routeFlow(src routeSource, route *domain.Route) ports.FlowRule {
+	return ports.FlowRule{
+		Priority: src.basePriority + route.PrefixLength(),
+		Match:    fmt.Sprintf("ip,nw_src=%s,nw_dst=%s", src.cidr, route.DestinationCIDR),
+		Actions:  routeActions(route),
+	}
+}
+
+func routeActions(route *domain.Route) string {
+	switch route.TargetType {
+	case domain.RouteTargetInternetGateway:
+		igw := &domain.InternetGateway{ID: *route.TargetID}
+		return gatewayActions(igw.NamespaceName(), igw.PortName())
+	case domain.RouteTargetNATGateway:
+		nat := &domain.NATGateway{ID: *route.TargetID}
+		return gatewayActions(nat.NamespaceName(), nat.PortName())
+	default:
+		return "NORMAL"
+	}
+}
+
+// gatewayActions hands a packet to a gateway namespace. Instances address it to their
+// subnet router's MAC, so it is rewritten to the gateway's own before being output.
+func gatewayActions(namespace, port string) string {
+	return fmt.Sprintf("mod_dl_dst:%s,output:%s", domain.GatewayMAC(namespace), port)
+}
+
+// canonicalCIDR normalises a range to its network address, as PostgreSQL stores it.
+func canonicalCIDR(cidr string) string {
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return cidr
+	}
+	return n.String()
+}
@@ -0,0 +1,301 @@
+package services_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRouteTableRepo struct {
+	mock.Mock
+}
+
+func (m *MockRouteTableRepo) Create(ctx context.Context, rt *domain.RouteTable) error {
+	return m.Called(ctx, rt).Error(0)
+}
+func (m *MockRouteTableRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.RouteTable, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RouteTable), args.Error(1)
+}
+func (m *MockRouteTableRepo) GetMain(ctx context.Context, vpcID uuid.UUID) (*domain.RouteTable, error) {
+	args := m.Called(ctx, vpcID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RouteTable), args.Error(1)
+}
+func (m *MockRouteTableRepo) GetBySubnet(ctx context.Context, subnetID uuid.UUID) (*domain.RouteTable, error) {
+	args := m.Called(ctx, subnetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RouteTable), args.Error(1)
+}
+func (m *MockRouteTableRepo) ListByVPC(ctx context.Context, vpcID uuid.UUID) ([]*domain.RouteTable, error) {
+	args := m.Called(ctx, vpcID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.RouteTable), args.Error(1)
+}
+func (m *MockRouteTableRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *MockRouteTableRepo) AddRoute(ctx context.Context, route *domain.Route) error {
+	return m.Called(ctx, route).Error(0)
+}
+func (m *MockRouteTableRepo) DeleteRoute(ctx context.Context, routeTableID uuid.UUID, destinationCIDR string) error {
+	return m.Called(ctx, routeTableID, destinationCIDR).Error(0)
+}
+func (m *MockRouteTableRepo) ListRoutesByTarget(ctx context.Context, targetID uuid.UUID) ([]*domain.Route, error) {
+	args := m.Called(ctx, targetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Route), args.Error(1)
+}
+func (m *MockRouteTableRepo) AssociateSubnet(ctx context.Context, routeTableID, subnetID uuid.UUID) error {
+	return m.Called(ctx, routeTableID, subnetID).Error(0)
+}
+func (m *MockRouteTableRepo) DisassociateSubnet(ctx context.Context, subnetID uuid.UUID) error {
+	return m.Called(ctx, subnetID).Error(0)
+}
+
+func TestRouteTableService(t *testing.T) {
+	tenantID := uuid.New()
+	ctx := appcontext.WithTenantID(appcontext.WithUserID(context.Background(), uuid.New()), tenantID)
+	vpc := &domain.VPC{ID: uuid.New(), TenantID: tenantID, CIDRBlock: "10.0.0.0/16", NetworkID: "br-vpc-rt"}
+	subnet := &domain.Subnet{ID: uuid.New(), VPCID: vpc.ID, CIDRBlock: "10.0.1.0/24"}
+
+	type deps struct {
+		repo    *MockRouteTableRepo
+		vpcRepo *MockVpcRepo
+		subnets *MockSubnetRepo
+		igwRepo *MockInternetGatewayRepo
+		natRepo *MockNATGatewayRepo
+		network *MockNetworkBackend
+	}
+	setup := func() (*services.RouteTableService, deps) {
+		d := deps{
+			repo:    new(MockRouteTableRepo),
+			vpcRepo: new(MockVpcRepo),
+			subnets: new(MockSubnetRepo),
+			igwRepo: new(MockInternetGatewayRepo),
+			natRepo: new(MockNATGatewayRepo),
+			network: new(MockNetworkBackend),
+		}
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		d.vpcRepo.On("GetByID", mock.Anything, vpc.ID).Return(vpc, nil).Maybe()
+		d.subnets.On("GetByID", mock.Anything, subnet.ID).Return(subnet, nil).Maybe()
+		svc := services.NewRouteTableService(services.RouteTableServiceParams{
+			Repo: d.repo, VpcRepo: d.vpcRepo, SubnetRepo: d.subnets, IGWRepo: d.igwRepo, NATRepo: d.natRepo,
+			Network: d.network, AuditSvc: audit, Logger: slog.Default(),
+		})
+		return svc, d
+	}
+	table := func(main bool) *domain.RouteTable {
+		id := uuid.New()
+		return &domain.RouteTable{
+			ID: id, VPCID: vpc.ID, TenantID: tenantID, Main: main,
+			Routes:    []domain.Route{{ID: uuid.New(), RouteTableID: id, DestinationCIDR: "10.0.0.0/16", TargetType: domain.RouteTargetLocal}},
+			SubnetIDs: []uuid.UUID{},
+		}
+	}
+	attachedIGW := func() *domain.InternetGateway {
+		return &domain.InternetGateway{ID: uuid.New(), VPCID: &vpc.ID, Status: domain.IGWStatusAttached}
+	}
+
+	t.Run("ListRouteTables creates the main table lazily", func(t *testing.T) {
+		svc, d := setup()
+		d.repo.On("GetMain", mock.Anything, vpc.ID).Return(nil, errors.New(errors.NotFound, "route table not found"))
+		d.network.On("AddFlowRule", mock.Anything, "br-vpc-rt", ports.FlowRule{
+			Priority: 116, Match: "ip,nw_src=10.0.0.0/16,nw_dst=10.0.0.0/16", Actions: "NORMAL",
+		}).Return(nil).Once()
+		d.repo.On("Create", mock.Anything, mock.MatchedBy(func(rt *domain.RouteTable) bool {
+			return rt.Main && len(rt.Routes) == 1 && rt.Routes[0].TargetType == domain.RouteTargetLocal
+		})).Return(nil).Once()
+		d.repo.On("ListByVPC", mock.Anything, vpc.ID).Return([]*domain.RouteTable{table(true)}, nil)
+
+		tables, err := svc.ListRouteTables(ctx, vpc.ID)
+		require.NoError(t, err)
+		assert.Len(t, tables, 1)
+		d.repo.AssertExpectations(t)
+		d.network.AssertExpectations(t)
+	})
+
+	t.Run("AddRoute to an internet gateway on the main table", func(t *testing.T) {
+		svc, d := setup()
+		rt := table(true)
+		igw := attachedIGW()
+		d.repo.On("GetByID", mock.Anything, rt.ID).Return(rt, nil)
+		d.igwRepo.On("GetByID", mock.Anything, igw.ID).Return(igw, nil)
+		d.network.On("AddFlowRule", mock.Anything, "br-vpc-rt", ports.FlowRule{
+			Priority: 100, Match: "ip,nw_src=10.0.0.0/16,nw_dst=0.0.0.0/0", Actions: "mod_dl_dst:" + domain.GatewayMAC(igw.NamespaceName()) + ",output:" + igw.PortName(),
+		}).Return(nil).Once()
+		d.repo.On("AddRoute", mock.Anything, mock.Anything).Return(nil)
+
+		route, err := svc.AddRoute(ctx, rt.ID, "0.0.0.0/0", domain.RouteTargetInternetGateway, &igw.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "0.0.0.0/0", route.DestinationCIDR)
+		d.network.AssertExpectations(t)
+	})
+
+	t.Run("AddRoute programs one flow per associated subnet", func(t *testing.T) {
+		svc, d := setup()
+		rt := table(false)
+		rt.SubnetIDs = []uuid.UUID{subnet.ID}
+		nat := &domain.NATGateway{ID: uuid.New(), VPCID: vpc.ID, Status: domain.NATStatusAvailable}
+		d.repo.On("GetByID", mock.Anything, rt.ID).Return(rt, nil)
+		d.natRepo.On("GetByID", mock.Anything, nat.ID).Return(nat, nil)
+		d.network.On("AddFlowRule", mock.Anything, "br-vpc-rt", ports.FlowRule{
+			Priority: 208, Match: "ip,nw_src=10.0.1.0/24,nw_dst=8.0.0.0/8", Actions: "mod_dl_dst:" + domain.GatewayMAC(nat.NamespaceName()) + ",output:" + nat.PortName(),
+		}).Return(nil).Once()
+		d.repo.On("AddRoute", mock.Anything, mock.Anything).Return(nil)
+
+		_, err := svc.AddRoute(ctx, rt.ID, "8.1.2.3/8", domain.RouteTargetNATGateway, &nat.ID)
+		require.NoError(t, err)
+		d.network.AssertExpectations(t)
+	})
+
+	t.Run("AddRoute rejects gateways of other VPCs", func(t *testing.T) {
+		svc, d := setup()
+		rt := table(true)
+		other := uuid.New()
+		igw := &domain.InternetGateway{ID: uuid.New(), VPCID: &other, Status: domain.IGWStatusAttached}
+		d.repo.On("GetByID", mock.Anything, rt.ID).Return(rt, nil)
+		d.igwRepo.On("GetByID", mock.Anything, igw.ID).Return(igw, nil)
+
+		_, err := svc.AddRoute(ctx, rt.ID, "0.0.0.0/0", domain.RouteTargetInternetGateway, &igw.ID)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+		d.network.AssertNotCalled(t, "AddFlowRule", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AddRoute rejects duplicates and local targets", func(t *testing.T) {
+		svc, d := setup()
+		rt := table(true)
+		d.repo.On("GetByID", mock.Anything, rt.ID).Return(rt, nil)
+		igwID := uuid.New()
+
+		_, err := svc.AddRoute(ctx, rt.ID, "10.0.0.0/16", domain.RouteTargetInternetGateway, &igwID)
+		assert.True(t, errors.Is(err, errors.Conflict))
+
+		_, err = svc.AddRoute(ctx, rt.ID, "10.1.0.0/16", domain.RouteTargetLocal, nil)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+	})
+
+	t.Run("AddRoute unwinds flows when the backend fails", func(t *testing.T) {
+		svc, d := setup()
+		rt := table(true)
+		igw := attachedIGW()
+		d.repo.On("GetByID", mock.Anything, rt.ID).Return(rt, nil)
+		d.igwRepo.On("GetByID", mock.Anything, igw.ID).Return(igw, nil)
+		d.network.On("AddFlowRule", mock.Anything, mock.Anything, mock.Anything).Return(assert.AnError)
+		d.network.On("DeleteFlowRule", mock.Anything, "br-vpc-rt", "ip,nw_src=10.0.0.0/16,nw_dst=0.0.0.0/0").Return(nil)
+
+		_, err := svc.AddRoute(ctx, rt.ID, "0.0.0.0/0", domain.RouteTargetInternetGateway, &igw.ID)
+		assert.True(t, errors.Is(err, errors.Internal))
+		d.repo.AssertNotCalled(t, "AddRoute", mock.Anything, mock.Anything)
+	})
+
+	t.Run("RemoveRoute keeps the local route", func(t *testing.T) {
+		svc, d := setup()
+		rt := table(true)
+		d.repo.On("GetByID", mock.Anything, rt.ID).Return(rt, nil)
+
+		err := svc.RemoveRoute(ctx, rt.ID, "10.0.0.0/16")
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+
+		err = svc.RemoveRoute(ctx, rt.ID, "0.0.0.0/0")
+		assert.True(t, errors.Is(err, errors.NotFound))
+	})
+
+	t.Run("RemoveRoute deletes the route's flows", func(t *testing.T) {
+		svc, d := setup()
+		rt := table(true)
+		igwID := uuid.New()
+		rt.Routes = append(rt.Routes, domain.Route{DestinationCIDR: "0.0.0.0/0", TargetType: domain.RouteTargetInternetGateway, TargetID: &igwID})
+		d.repo.On("GetByID", mock.Anything, rt.ID).Return(rt, nil)
+		d.repo.On("DeleteRoute", mock.Anything, rt.ID, "0.0.0.0/0").Return(nil)
+		d.network.On("DeleteFlowRule", mock.Anything, "br-vpc-rt", "ip,nw_src=10.0.0.0/16,nw_dst=0.0.0.0/0").Return(nil).Once()
+
+		require.NoError(t, svc.RemoveRoute(ctx, rt.ID, "0.0.0.0/0"))
+		d.network.AssertExpectations(t)
+	})
+
+	t.Run("AssociateSubnet replaces the previous table's flows", func(t *testing.T) {
+		svc, d := setup()
+		previous, rt := table(false), table(false)
+		d.repo.On("GetByID", mock.Anything, rt.ID).Return(rt, nil)
+		d.repo.On("GetBySubnet", mock.Anything, subnet.ID).Return(previous, nil)
+		d.repo.On("AssociateSubnet", mock.Anything, rt.ID, subnet.ID).Return(nil)
+		match := "ip,nw_src=10.0.1.0/24,nw_dst=10.0.0.0/16"
+		d.network.On("DeleteFlowRule", mock.Anything, "br-vpc-rt", match).Return(nil).Once()
+		d.network.On("AddFlowRule", mock.Anything, "br-vpc-rt", ports.FlowRule{Priority: 216, Match: match, Actions: "NORMAL"}).Return(nil).Once()
+
+		require.NoError(t, svc.AssociateSubnet(ctx, rt.ID, subnet.ID))
+		d.network.AssertExpectations(t)
+		d.repo.AssertExpectations(t)
+	})
+
+	t.Run("AssociateSubnet refuses the main table and foreign subnets", func(t *testing.T) {
+		svc, d := setup()
+		main, rt := table(true), table(false)
+		foreign := &domain.Subnet{ID: uuid.New(), VPCID: uuid.New(), CIDRBlock: "10.9.0.0/24"}
+		d.repo.On("GetByID", mock.Anything, main.ID).Return(main, nil)
+		d.repo.On("GetByID", mock.Anything, rt.ID).Return(rt, nil)
+		d.subnets.On("GetByID", mock.Anything, foreign.ID).Return(foreign, nil)
+
+		assert.True(t, errors.Is(svc.AssociateSubnet(ctx, main.ID, subnet.ID), errors.InvalidInput))
+		assert.True(t, errors.Is(svc.AssociateSubnet(ctx, rt.ID, foreign.ID), errors.InvalidInput))
+	})
+
+	t.Run("DisassociateSubnet returns the subnet to the main table", func(t *testing.T) {
+		svc, d := setup()
+		rt := table(false)
+		d.repo.On("GetBySubnet", mock.Anything, subnet.ID).Return(rt, nil)
+		d.repo.On("DisassociateSubnet", mock.Anything, subnet.ID).Return(nil)
+		d.network.On("DeleteFlowRule", mock.Anything, "br-vpc-rt", "ip,nw_src=10.0.1.0/24,nw_dst=10.0.0.0/16").Return(nil).Once()
+
+		require.NoError(t, svc.DisassociateSubnet(ctx, subnet.ID))
+		d.network.AssertExpectations(t)
+	})
+
+	t.Run("GetSubnetRouteTable falls back to the main table", func(t *testing.T) {
+		svc, d := setup()
+		main := table(true)
+		d.repo.On("GetBySubnet", mock.Anything, subnet.ID).Return(nil, errors.New(errors.NotFound, "route table not found"))
+		d.repo.On("GetMain", mock.Anything, vpc.ID).Return(main, nil)
+
+		got, err := svc.GetSubnetRouteTable(ctx, subnet.ID)
+		require.NoError(t, err)
+		assert.Equal(t, main.ID, got.ID)
+	})
+
+	t.Run("DeleteRouteTable refuses main and associated tables", func(t *testing.T) {
+		svc, d := setup()
+		main, used, unused := table(true), table(false), table(false)
+		used.SubnetIDs = []uuid.UUID{subnet.ID}
+		d.repo.On("GetByID", mock.Anything, main.ID).Return(main, nil)
+		d.repo.On("GetByID", mock.Anything, used.ID).Return(used, nil)
+		d.repo.On("GetByID", mock.Anything, unused.ID).Return(unused, nil)
+		d.repo.On("Delete", mock.Anything, unused.ID).Return(nil)
+
+		assert.True(t, errors.Is(svc.DeleteRouteTable(ctx, main.ID), errors.Conflict))
+		assert.True(t, errors.Is(svc.DeleteRouteTable(ctx, used.ID), errors.Conflict))
+		require.NoError(t, svc.DeleteRouteTable(ctx, unused.ID))
+	})
+}
@@ -17,7 +17,28 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
-const securityGroupTracer = "security-group-service"
+const (
+	securityGroupTracer = "security-group-service"
+
+	// firewallTable holds the egress stage of the stateful security groups and
+	// firewallIngressTable the ingress stage. Table 0 sends untracked IP packets from or to a
+	// group member through conntrack into the egress stage. A new connection must pass the
+	// egress rules of its source and then the ingress rules of its destination, if they are
+	// members, before it is committed and resubmitted to table 0, where the routing and
+	// peering flows forward it. Traffic of instances in no group never enters either stage.
+	firewallTable        = 10
+	firewallIngressTable = 11
+	// firewallConntrackPriority ranks the connection tracking flows above every rule.
+	firewallConntrackPriority = 65000
+	// firewallRulePriority is shared by every rule flow. Rules only ever allow traffic, so
+	// their order cannot change the outcome, and identical rules of different groups
+	// collapse into a single flow.
+	firewallRulePriority = 100
+	// firewallMemberDropPriority drops a member's new connections that no rule admitted.
+	firewallMemberDropPriority = 1
+	// arpPriority lets ARP through on every bridge with a security group.
+	arpPriority = 1000
+)
 
 // SecurityGroupService manages security group lifecycle and rules.
 type SecurityGroupService struct {
@@ -128,12 +149,38 @@ func (s *SecurityGroupService) DeleteGroup(ctx context.Context, id uuid.UUID) er
 
 	userID := appcontext.UserIDFromContext(ctx)
 
-	// In a real implementation, we should check if any instances are still attached
-	// For now, we just delete.
+	refs, err := s.repo.ListRulesReferencingGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if ref.GroupID != id {
+			return errors.New(errors.Conflict, fmt.Sprintf("security group is referenced by a rule in group %s", ref.GroupID))
+		}
+	}
+
+	sg, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	members, err := s.repo.ListGroupMemberIPs(ctx, id)
+	if err != nil {
+		return err
+	}
+	stale, err := s.groupFlows(ctx, sg, members)
+	if err != nil {
+		s.logger.Error("failed to expand security group flows", "group_id", id, "error", err)
+	}
+
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return errors.Wrap(errors.Internal, "failed to delete security group", err)
 	}
 
+	// Members lose the group's rules; those in no other group leave the firewall entirely.
+	if err := s.reconcile(ctx, sg.VPCID, stale, members); err != nil {
+		s.logger.Error("failed to remove OVS flows", "group_id", id, "error", err)
+	}
+
 	_ = s.auditSvc.Log(ctx, userID, "security_group.delete", "security_group", id.String(), nil)
 
 	return nil
@@ -150,11 +197,25 @@ func (s *SecurityGroupService) AddRule(ctx context.Context, groupID uuid.UUID, r
 		attribute.String("cidr", rule.CIDR),
 	)
 
+	if err := rule.Validate(); err != nil {
+		return nil, errors.New(errors.InvalidInput, err.Error())
+	}
+
 	sg, err := s.repo.GetByID(ctx, groupID)
 	if err != nil {
 		return nil, err
 	}
 
+	if rule.SourceGroupID != nil {
+		source, err := s.repo.GetByID(ctx, *rule.SourceGroupID)
+		if err != nil {
+			return nil, err
+		}
+		if source.VPCID != sg.VPCID {
+			return nil, errors.New(errors.InvalidInput, "source security group must be in the same VPC")
+		}
+	}
+
 	rule.ID = uuid.New()
 	rule.GroupID = groupID
 	rule.CreatedAt = time.Now()
@@ -164,6 +225,7 @@ func (s *SecurityGroupService) AddRule(ctx context.Context, groupID uuid.UUID, r
 	}
 
 	// Update OVS flows
+	sg.Rules = append(sg.Rules, rule)
 	if err := s.syncGroupFlows(ctx, sg); err != nil {
 		s.logger.Error("failed to sync OVS flows", "group_id", groupID, "error", err)
 		// We don't rollback DB here in this simple pass, but in real life we should.
@@ -194,19 +256,18 @@ func (s *SecurityGroupService) RemoveRule(ctx context.Context, ruleID uuid.UUID)
 		return err
 	}
 
-	// 3. Remove from OVS
-	// We attempt this before DB deletion. If it fails, we assume consistency check later or manual fix.
-	// In production, this should likely be a localized transaction or workflow.
-	vpc, err := s.vpcRepo.GetByID(ctx, sg.VPCID)
-	if err == nil {
-		flow := s.translateToFlow(*rule)
-		if err := s.network.DeleteFlowRule(ctx, vpc.NetworkID, flow.Match); err != nil {
-			// Log but proceed to ensure DB consistency
-			s.logger.Error("failed to delete OVS flow rule", "rule_id", ruleID, "error", err)
-		}
-	} else {
-		// VPC might be gone?
-		s.logger.Warn("vpc not found during rule deletion", "vpc_id", sg.VPCID)
+	// 3. Work out the rule's flows while its group's members are still known
+	members, err := s.repo.ListGroupMemberIPs(ctx, sg.ID)
+	if err != nil {
+		return err
+	}
+	peers, err := s.rulePeers(ctx, *rule)
+	if err != nil {
+		s.logger.Error("failed to expand security rule", "rule_id", ruleID, "error", err)
+	}
+	var stale []ports.FlowRule
+	for _, member := range members {
+		stale = append(stale, memberRuleFlows(*rule, member, peers)...)
 	}
 
 	// 4. Delete from DB
@@ -214,6 +275,12 @@ func (s *SecurityGroupService) RemoveRule(ctx context.Context, ruleID uuid.UUID)
 		return errors.Wrap(errors.Internal, "failed to delete security rule", err)
 	}
 
+	// 5. Remove from OVS the flows no other rule still needs
+	if err := s.reconcile(ctx, sg.VPCID, stale, members); err != nil {
+		// Log but proceed to ensure DB consistency
+		s.logger.Error("failed to delete OVS flow rules", "rule_id", ruleID, "error", err)
+	}
+
 	_ = s.auditSvc.Log(ctx, sg.UserID, "security_group.remove_rule", "security_group", sg.ID.String(), map[string]interface{}{
 		"rule_id": ruleID.String(),
 	})
@@ -230,6 +297,11 @@ func (s *SecurityGroupService) AttachToInstance(ctx context.Context, instanceID,
 		attribute.String("group_id", groupID.String()),
 	)
 
+	before, err := s.repo.ListGroupMemberIPs(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
 	if err := s.repo.AddInstanceToGroup(ctx, instanceID, groupID); err != nil {
 		return err
 	}
@@ -243,6 +315,13 @@ func (s *SecurityGroupService) AttachToInstance(ctx context.Context, instanceID,
 	if err := s.syncGroupFlows(ctx, sg); err != nil {
 		return err
 	}
+	after, err := s.repo.ListGroupMemberIPs(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if err := s.addReferencingFlows(ctx, sg, diffStrings(after, before)); err != nil {
+		return err
+	}
 
 	_ = s.auditSvc.Log(ctx, sg.UserID, "security_group.attach", "instance", instanceID.String(), map[string]interface{}{
 		"group_id": groupID.String(),
@@ -260,14 +339,23 @@ func (s *SecurityGroupService) DetachFromInstance(ctx context.Context, instanceI
 		attribute.String("group_id", groupID.String()),
 	)
 
+	before, err := s.repo.ListGroupMemberIPs(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
 	if err := s.repo.RemoveInstanceFromGroup(ctx, instanceID, groupID); err != nil {
 		return err
 	}
 
 	sg, err := s.repo.GetByID(ctx, groupID)
 	if err == nil {
-		// Cleanup OVS flows
-		if err := s.removeGroupFlows(ctx, sg); err != nil {
+		after, err := s.repo.ListGroupMemberIPs(ctx, groupID)
+		if err == nil {
+			// Cleanup OVS flows of the departed member only
+			err = s.releaseAddresses(ctx, sg, diffStrings(before, after))
+		}
+		if err != nil {
 			s.logger.Error("failed to remove OVS flows", "group_id", groupID, "error", err)
 		}
 	}
@@ -280,15 +368,77 @@ func (s *SecurityGroupService) DetachFromInstance(ctx context.Context, instanceI
 	return nil
 }
 
+// DetachAllFromInstance detaches every security group of an instance. Deleting the instance
+// record would drop its memberships without removing the flows they installed.
+func (s *SecurityGroupService) DetachAllFromInstance(ctx context.Context, instanceID uuid.UUID) error {
+	groups, err := s.repo.ListInstanceGroups(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		if err := s.DetachFromInstance(ctx, instanceID, g.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncInstanceAddresses re-keys the flows of an instance's security groups after its
+// addresses change: flows for addresses it gave up are removed, and the groups' flows and
+// the rules referencing them are installed for the new ones.
+func (s *SecurityGroupService) SyncInstanceAddresses(ctx context.Context, instanceID uuid.UUID, previous, current []string) error {
+	ctx, span := otel.Tracer(securityGroupTracer).Start(ctx, "SyncInstanceAddresses")
+	defer span.End()
+	span.SetAttributes(attribute.String("instance_id", instanceID.String()))
+
+	departed, added := diffStrings(previous, current), diffStrings(current, previous)
+	if len(departed) == 0 && len(added) == 0 {
+		return nil
+	}
+
+	groups, err := s.repo.ListInstanceGroups(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		// ListInstanceGroups does not load rules.
+		sg, err := s.repo.GetByID(ctx, g.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.releaseAddresses(ctx, sg, departed); err != nil {
+			return err
+		}
+		if len(added) == 0 {
+			continue
+		}
+		if err := s.syncGroupFlows(ctx, sg); err != nil {
+			return err
+		}
+		if err := s.addReferencingFlows(ctx, sg, added); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncGroupFlows installs the shared firewall flows and the flows of every member of sg.
+// Adding a flow that already exists leaves it unchanged, so this is safe to repeat.
 func (s *SecurityGroupService) syncGroupFlows(ctx context.Context, sg *domain.SecurityGroup) error {
 	vpc, err := s.vpcRepo.GetByID(ctx, sg.VPCID)
 	if err != nil {
 		return err
 	}
+	members, err := s.repo.ListGroupMemberIPs(ctx, sg.ID)
+	if err != nil {
+		return err
+	}
+	flows, err := s.groupFlows(ctx, sg, members)
+	if err != nil {
+		return err
+	}
 
-	// For each rule, generate an OVS flow
-	for _, rule := range sg.Rules {
-		flow := s.translateToFlow(rule)
+	for _, flow := range append(baseFlows(), flows...) {
 		if err := s.network.AddFlowRule(ctx, vpc.NetworkID, flow); err != nil {
 			return err
 		}
@@ -297,60 +447,343 @@ func (s *SecurityGroupService) syncGroupFlows(ctx context.Context, sg *domain.Se
 	return nil
 }
 
-func (s *SecurityGroupService) removeGroupFlows(ctx context.Context, sg *domain.SecurityGroup) error {
+// releaseAddresses removes the flows that existed because the given addresses were members
+// of sg: their own entry and rule flows, and their appearances as peers in rules naming sg
+// as their source.
+func (s *SecurityGroupService) releaseAddresses(ctx context.Context, sg *domain.SecurityGroup, departed []string) error {
+	if len(departed) == 0 {
+		return nil
+	}
+	stale, err := s.groupFlows(ctx, sg, departed)
+	if err != nil {
+		return err
+	}
+	referencing, err := s.referencingFlows(ctx, sg, departed)
+	if err != nil {
+		return err
+	}
+	return s.reconcile(ctx, sg.VPCID, append(stale, referencing...), departed)
+}
+
+// addReferencingFlows admits new members of sg in every rule that names sg as its source.
+func (s *SecurityGroupService) addReferencingFlows(ctx context.Context, sg *domain.SecurityGroup, added []string) error {
+	flows, err := s.referencingFlows(ctx, sg, added)
+	if err != nil || len(flows) == 0 {
+		return err
+	}
+
+	// Source groups share a VPC with the rules that reference them.
 	vpc, err := s.vpcRepo.GetByID(ctx, sg.VPCID)
 	if err != nil {
 		return err
 	}
+	for _, flow := range flows {
+		if err := s.network.AddFlowRule(ctx, vpc.NetworkID, flow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// referencingFlows expands every rule that names sg as its source against the given
+// addresses of sg's members, for each member of the rule's own group.
+func (s *SecurityGroupService) referencingFlows(ctx context.Context, sg *domain.SecurityGroup, ips []string) ([]ports.FlowRule, error) {
+	if len(ips) == 0 {
+		return nil, nil
+	}
+	rules, err := s.repo.ListRulesReferencingGroup(ctx, sg.ID)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
 
-	for _, rule := range sg.Rules {
-		flow := s.translateToFlow(rule)
+	peers := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		peers = append(peers, hostCIDR(ip))
+	}
+	var flows []ports.FlowRule
+	for _, rule := range rules {
+		members, err := s.repo.ListGroupMemberIPs(ctx, rule.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			flows = append(flows, memberRuleFlows(rule, member, peers)...)
+		}
+	}
+	return flows, nil
+}
+
+// reconcile deletes the stale flows that no security group in the VPC still needs. A flow
+// is shared by every group that would install an identical one, so it is only removed
+// once the last of them lets go. Deleting by match also removes more specific flows, so
+// the flows still wanted for the touched addresses are reinstalled afterwards.
+func (s *SecurityGroupService) reconcile(ctx context.Context, vpcID uuid.UUID, stale []ports.FlowRule, touched []string) error {
+	if len(stale) == 0 {
+		return nil
+	}
+	vpc, err := s.vpcRepo.GetByID(ctx, vpcID)
+	if err != nil {
+		return err
+	}
+	wanted, err := s.vpcFlows(ctx, vpcID)
+	if err != nil {
+		return err
+	}
+	kept := make(map[string]struct{}, len(wanted))
+	for _, flow := range wanted {
+		kept[flow.Match] = struct{}{}
+	}
+
+	deleted := make(map[string]struct{}, len(stale))
+	for _, flow := range stale {
+		if _, ok := kept[flow.Match]; ok {
+			continue
+		}
+		if _, ok := deleted[flow.Match]; ok {
+			continue
+		}
+		deleted[flow.Match] = struct{}{}
 		if err := s.network.DeleteFlowRule(ctx, vpc.NetworkID, flow.Match); err != nil {
-			s.logger.Error("failed to delete flow rule", "rule", rule.ID, "error", err)
+			s.logger.Error("failed to delete flow rule", "match", flow.Match, "error", err)
 		}
 	}
+	if len(deleted) == 0 {
+		return nil
+	}
 
+	for _, flow := range wanted {
+		if flowMentions(flow.Match, touched) {
+			if err := s.network.AddFlowRule(ctx, vpc.NetworkID, flow); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
-func (s *SecurityGroupService) translateToFlow(rule domain.SecurityRule) ports.FlowRule {
-	matchParts := []string{}
-
-	switch rule.Protocol {
-	case "tcp":
-		matchParts = append(matchParts, "tcp")
-	case "udp":
-		matchParts = append(matchParts, "udp")
-	case "icmp":
-		matchParts = append(matchParts, "icmp")
-	case "arp":
-		matchParts = append(matchParts, "arp")
-	}
-
-	if rule.CIDR != "" && rule.CIDR != "0.0.0.0/0" {
-		if rule.Direction == domain.RuleIngress {
-			matchParts = append(matchParts, fmt.Sprintf("nw_src=%s", rule.CIDR))
-		} else {
-			matchParts = append(matchParts, fmt.Sprintf("nw_dst=%s", rule.CIDR))
+// vpcFlows returns the member flows every security group of a VPC currently needs.
+func (s *SecurityGroupService) vpcFlows(ctx context.Context, vpcID uuid.UUID) ([]ports.FlowRule, error) {
+	groups, err := s.repo.ListByVPC(ctx, vpcID)
+	if err != nil {
+		return nil, err
+	}
+	var flows []ports.FlowRule
+	for _, g := range groups {
+		// ListByVPC does not load rules.
+		sg, err := s.repo.GetByID(ctx, g.ID)
+		if err != nil {
+			return nil, err
 		}
+		members, err := s.repo.ListGroupMemberIPs(ctx, sg.ID)
+		if err != nil {
+			return nil, err
+		}
+		groupFlows, err := s.groupFlows(ctx, sg, members)
+		if err != nil {
+			return nil, err
+		}
+		flows = append(flows, groupFlows...)
+	}
+	return flows, nil
+}
+
+// groupFlows expands sg for the given member addresses: each member's entry into the
+// firewall and its default drops, and each of sg's rules scoped to the member.
+func (s *SecurityGroupService) groupFlows(ctx context.Context, sg *domain.SecurityGroup, members []string) ([]ports.FlowRule, error) {
+	if len(members) == 0 {
+		return nil, nil
 	}
+	peers := make([][]string, len(sg.Rules))
+	for i, rule := range sg.Rules {
+		p, err := s.rulePeers(ctx, rule)
+		if err != nil {
+			return nil, err
+		}
+		peers[i] = p
+	}
+
+	var flows []ports.FlowRule
+	for _, member := range members {
+		flows = append(flows, memberFlows(member)...)
+		for i, rule := range sg.Rules {
+			flows = append(flows, memberRuleFlows(rule, member, peers[i])...)
+		}
+	}
+	return flows, nil
+}
 
-	if rule.PortMin > 0 {
-		if rule.PortMin == rule.PortMax {
+// rulePeers lists the ranges a rule admits: its CIDR, or a host range per address of the
+// source group's members.
+func (s *SecurityGroupService) rulePeers(ctx context.Context, rule domain.SecurityRule) ([]string, error) {
+	if rule.SourceGroupID == nil {
+		return []string{rule.CIDR}, nil
+	}
+
+	ips, err := s.repo.ListGroupMemberIPs(ctx, *rule.SourceGroupID)
+	if err != nil {
+		return nil, err
+	}
+	peers := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		peers = append(peers, hostCIDR(ip))
+	}
+	return peers, nil
+}
+
+// memberRuleFlows builds a rule's flows for one member against each peer range of the
+// member's address family. Ingress rules live in the ingress stage and admit the first
+// packet of a connection, committing it to conntrack so the rest of the connection and
+// its replies pass the established flows; egress rules live in the egress stage and hand
+// the packet on to the ingress stage. ARP is never filtered and yields no flows.
+func memberRuleFlows(rule domain.SecurityRule, member string, peers []string) []ports.FlowRule {
+	if rule.Protocol == "arp" {
+		return nil
+	}
+
+	v6 := isIPv6CIDR(member)
+	family := familyFields(v6)
+	table, actions := firewallIngressTable, "ct(commit),resubmit(,0)"
+	local, remote := family.dst, family.src
+	if rule.Direction == domain.RuleEgress {
+		table, actions = firewallTable, fmt.Sprintf("resubmit(,%d)", firewallIngressTable)
+		local, remote = family.src, family.dst
+	}
+
+	flows := make([]ports.FlowRule, 0, len(peers))
+	for _, peer := range peers {
+		if peer != "" && isIPv6CIDR(peer) != v6 {
+			continue
+		}
+
+		matchParts := []string{fmt.Sprintf("table=%d", table), "ct_state=+trk+new"}
+		switch rule.Protocol {
+		case "tcp", "udp", "icmp":
+			matchParts = append(matchParts, rule.Protocol+family.suffix)
+		default:
+			matchParts = append(matchParts, family.all)
+		}
+		if peer != "" && peer != "0.0.0.0/0" && peer != "::/0" {
+			matchParts = append(matchParts, fmt.Sprintf("%s=%s", remote, peer))
+		}
+		matchParts = append(matchParts, fmt.Sprintf("%s=%s", local, hostCIDR(member)))
+
+		if rule.PortMin > 0 {
 			switch rule.Protocol {
 			case "tcp", "udp":
+				// OVS doesn't support easy ranges, would need multiple flows or mask
+				// Simplified for now
 				matchParts = append(matchParts, fmt.Sprintf("tp_dst=%d", rule.PortMin))
 			}
-		} else if rule.PortMax > rule.PortMin {
-			// OVS doesn't support easy ranges, would need multiple flows or mask
-			// Simplified for now
-			matchParts = append(matchParts, fmt.Sprintf("tp_dst=%d", rule.PortMin))
+		}
+
+		flows = append(flows, ports.FlowRule{
+			Priority: firewallRulePriority,
+			Match:    strings.Join(matchParts, ","),
+			Actions:  actions,
+		})
+	}
+	return flows
+}
+
+// memberFlows send a member's untracked traffic in both directions through conntrack into
+// the firewall, and drop the new connections that none of its rules admit.
+func memberFlows(member string) []ports.FlowRule {
+	family := familyFields(isIPv6CIDR(member))
+	host := hostCIDR(member)
+	entry := fmt.Sprintf("ct(table=%d)", firewallTable)
+	return []ports.FlowRule{
+		{Priority: firewallConntrackPriority, Match: fmt.Sprintf("%s,%s=%s,ct_state=-trk", family.all, family.src, host), Actions: entry},
+		{Priority: firewallConntrackPriority, Match: fmt.Sprintf("%s,%s=%s,ct_state=-trk", family.all, family.dst, host), Actions: entry},
+		{Priority: firewallMemberDropPriority, Match: fmt.Sprintf("table=%d,%s,%s=%s", firewallTable, family.all, family.src, host), Actions: "drop"},
+		{Priority: firewallMemberDropPriority, Match: fmt.Sprintf("table=%d,%s,%s=%s", firewallIngressTable, family.all, family.dst, host), Actions: "drop"},
+	}
+}
+
+// baseFlows are the bridge-wide firewall flows all groups share. Packets of connections a
+// rule already allowed pass, so return traffic needs no rule of its own; packets of new
+// connections move from the egress to the ingress stage and are committed when neither
+// stage belongs to a member. ARP, IPv6 neighbor discovery and DHCP are never filtered.
+func baseFlows() []ports.FlowRule {
+	egress, ingress := fmt.Sprintf("table=%d", firewallTable), fmt.Sprintf("table=%d", firewallIngressTable)
+	flows := make([]ports.FlowRule, 0, 17)
+	flows = append(flows, ports.FlowRule{Priority: arpPriority, Match: "arp", Actions: "NORMAL"})
+	for _, family := range []string{"ip", "ipv6"} {
+		flows = append(flows,
+			ports.FlowRule{Priority: firewallConntrackPriority, Match: egress + "," + family + ",ct_state=+trk+est", Actions: "resubmit(,0)"},
+			ports.FlowRule{Priority: firewallConntrackPriority, Match: egress + "," + family + ",ct_state=+trk+rel", Actions: "resubmit(,0)"},
+			ports.FlowRule{Priority: firewallConntrackPriority, Match: egress + "," + family + ",ct_state=+trk+inv", Actions: "drop"},
+		)
+	}
+	// DHCPv4 and DHCPv6, client to server and back.
+	for _, dhcp := range []string{"udp,tp_src=68,tp_dst=67", "udp,tp_src=67,tp_dst=68", "udp6,tp_src=546,tp_dst=547", "udp6,tp_src=547,tp_dst=546"} {
+		flows = append(flows, ports.FlowRule{Priority: firewallConntrackPriority + 1, Match: egress + "," + dhcp, Actions: "resubmit(,0)"})
+	}
+	// Router solicitation/advertisement and neighbor solicitation/advertisement.
+	for _, icmpType := range []int{133, 134, 135, 136} {
+		flows = append(flows, ports.FlowRule{
+			Priority: firewallConntrackPriority + 1,
+			Match:    fmt.Sprintf("icmp6,icmp_type=%d", icmpType),
+			Actions:  "NORMAL",
+		})
+	}
+	return append(flows,
+		ports.FlowRule{Priority: 0, Match: egress, Actions: fmt.Sprintf("resubmit(,%d)", firewallIngressTable)},
+		ports.FlowRule{Priority: 0, Match: ingress, Actions: "ct(commit),resubmit(,0)"},
+	)
+}
+
+// firewallFamily names the OpenFlow match keywords for one IP version.
+type firewallFamily struct {
+	suffix, all string
+	src, dst    string
+}
+
+func familyFields(v6 bool) firewallFamily {
+	if v6 {
+		return firewallFamily{suffix: "6", all: "ipv6", src: "ipv6_src", dst: "ipv6_dst"}
+	}
+	return firewallFamily{suffix: "", all: "ip", src: "nw_src", dst: "nw_dst"}
+}
+
+// flowMentions reports whether a flow match names any of the addresses as a host.
+func flowMentions(match string, ips []string) bool {
+	for _, field := range strings.Split(match, ",") {
+		_, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		for _, ip := range ips {
+			if value == hostCIDR(ip) {
+				return true
+			}
 		}
 	}
+	return false
+}
 
-	return ports.FlowRule{
-		Priority: rule.Priority,
-		Match:    strings.Join(matchParts, ","),
-		Actions:  "NORMAL",
+// hostCIDR turns a single address into a host route of the right family.
+func hostCIDR(ip string) string {
+	if strings.Contains(ip, ":") {
+		return ip + "/128"
+	}
+	return ip + "/32"
+}
+
+func isIPv6CIDR(cidr string) bool {
+	return strings.Contains(cidr, ":")
+}
+
+// diffStrings returns the elements of a that are not in b.
+func diffStrings(a, b []string) []string {
+	seen := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		seen[v] = struct{}{}
+	}
+	var out []string
+	for _, v := range a {
+		if _, ok := seen[v]; !ok {
+			out = append(out, v)
+		}
 	}
+	return out
 }
@@ -3,14 +3,18 @@ package services_test
 import (
 	"context"
 	"log/slog"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
 	appcontext "github.com/poyrazk/thecloud/internal/core/context"
 	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSecurityGroupService_Unit(t *testing.T) {
@@ -40,16 +44,350 @@ func TestSecurityGroupService_Unit(t *testing.T) {
 	t.Run("AddRule", func(t *testing.T) {
 		sgID := uuid.New()
 		sg := &domain.SecurityGroup{ID: sgID, UserID: userID, VPCID: vpcID}
-		rule := domain.SecurityRule{Protocol: "tcp", PortMin: 80, PortMax: 80, Direction: domain.RuleIngress}
-		
+		rule := domain.SecurityRule{Protocol: "tcp", PortMin: 80, PortMax: 80, CIDR: "0.0.0.0/0", Direction: domain.RuleIngress}
+
 		mockRepo.On("GetByID", mock.Anything, sgID).Return(sg, nil).Once()
 		mockRepo.On("AddRule", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("ListGroupMemberIPs", mock.Anything, sgID).Return([]string{"10.0.0.5"}, nil).Once()
 		mockVpcRepo.On("GetByID", mock.Anything, vpcID).Return(&domain.VPC{ID: vpcID, NetworkID: "net-1"}, nil).Once()
-		mockNetwork.On("AddFlowRule", mock.Anything, "net-1", mock.Anything).Return(nil).Once()
+		mockNetwork.On("AddFlowRule", mock.Anything, "net-1", mock.Anything).Return(nil)
 		mockAuditSvc.On("Log", mock.Anything, userID, "security_group.add_rule", "security_group", sgID.String(), mock.Anything).Return(nil).Once()
 
 		res, err := svc.AddRule(ctx, sgID, rule)
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
+		mockNetwork.AssertCalled(t, "AddFlowRule", mock.Anything, "net-1", ports.FlowRule{
+			Priority: 100,
+			Match:    "table=11,ct_state=+trk+new,tcp,nw_dst=10.0.0.5/32,tp_dst=80",
+			Actions:  "ct(commit),resubmit(,0)",
+		})
+		mockNetwork.AssertCalled(t, "AddFlowRule", mock.Anything, "net-1", ports.FlowRule{
+			Priority: 65000,
+			Match:    "ip,nw_dst=10.0.0.5/32,ct_state=-trk",
+			Actions:  "ct(table=10)",
+		})
+		mockNetwork.AssertCalled(t, "AddFlowRule", mock.Anything, "net-1", ports.FlowRule{
+			Priority: 1,
+			Match:    "table=11,ip,nw_dst=10.0.0.5/32",
+			Actions:  "drop",
+		})
+		mockNetwork.AssertCalled(t, "AddFlowRule", mock.Anything, "net-1", ports.FlowRule{
+			Priority: 65001,
+			Match:    "table=10,udp,tp_src=68,tp_dst=67",
+			Actions:  "resubmit(,0)",
+		})
+		// Non-members pass the firewall untouched.
+		mockNetwork.AssertCalled(t, "AddFlowRule", mock.Anything, "net-1", ports.FlowRule{
+			Priority: 0,
+			Match:    "table=11",
+			Actions:  "ct(commit),resubmit(,0)",
+		})
+		mockNetwork.AssertNotCalled(t, "AddFlowRule", mock.Anything, "net-1", mock.MatchedBy(func(f ports.FlowRule) bool {
+			return f.Match == "ip,ct_state=-trk"
+		}))
+	})
+
+	t.Run("AddRule IPv6", func(t *testing.T) {
+		sgID := uuid.New()
+		sg := &domain.SecurityGroup{ID: sgID, UserID: userID, VPCID: vpcID}
+		mockRepo.On("GetByID", mock.Anything, sgID).Return(sg, nil).Twice()
+		mockRepo.On("AddRule", mock.Anything, mock.Anything).Return(nil).Twice()
+		mockRepo.On("ListGroupMemberIPs", mock.Anything, sgID).Return([]string{"fd00:10::5"}, nil).Twice()
+		mockVpcRepo.On("GetByID", mock.Anything, vpcID).Return(&domain.VPC{ID: vpcID, NetworkID: "net-1"}, nil).Twice()
+		mockAuditSvc.On("Log", mock.Anything, userID, "security_group.add_rule", "security_group", sgID.String(), mock.Anything).Return(nil).Twice()
+
+		_, err := svc.AddRule(ctx, sgID, domain.SecurityRule{Protocol: "tcp", PortMin: 443, PortMax: 443, CIDR: "::/0", Direction: domain.RuleIngress})
+		require.NoError(t, err)
+		_, err = svc.AddRule(ctx, sgID, domain.SecurityRule{Protocol: "icmp", CIDR: "fd00:10::/56", Direction: domain.RuleEgress})
+		require.NoError(t, err)
+
+		mockNetwork.AssertCalled(t, "AddFlowRule", mock.Anything, "net-1", ports.FlowRule{
+			Priority: 100,
+			Match:    "table=11,ct_state=+trk+new,tcp6,ipv6_dst=fd00:10::5/128,tp_dst=443",
+			Actions:  "ct(commit),resubmit(,0)",
+		})
+		mockNetwork.AssertCalled(t, "AddFlowRule", mock.Anything, "net-1", ports.FlowRule{
+			Priority: 100,
+			Match:    "table=10,ct_state=+trk+new,icmp6,ipv6_dst=fd00:10::/56,ipv6_src=fd00:10::5/128",
+			Actions:  "resubmit(,11)",
+		})
+		mockNetwork.AssertCalled(t, "AddFlowRule", mock.Anything, "net-1", ports.FlowRule{
+			Priority: 65000,
+			Match:    "ipv6,ipv6_src=fd00:10::5/128,ct_state=-trk",
+			Actions:  "ct(table=10)",
+		})
+		mockNetwork.AssertCalled(t, "AddFlowRule", mock.Anything, "net-1", ports.FlowRule{
+			Priority: 65001,
+			Match:    "icmp6,icmp_type=135",
+			Actions:  "NORMAL",
+		})
+	})
+
+	t.Run("AddRule rejects invalid rule", func(t *testing.T) {
+		_, err := svc.AddRule(ctx, uuid.New(), domain.SecurityRule{Protocol: "tcp", PortMin: 80, PortMax: 80, Direction: domain.RuleIngress})
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+	})
+}
+
+func TestSecurityGroupService_SourceGroupRules(t *testing.T) {
+	ctx := appcontext.WithUserID(context.Background(), uuid.New())
+	vpcID := uuid.New()
+	vpc := &domain.VPC{ID: vpcID, NetworkID: "br-vpc"}
+
+	setup := func() (*services.SecurityGroupService, *MockSecurityGroupRepo, *MockVpcRepo, *MockNetworkBackend) {
+		repo := new(MockSecurityGroupRepo)
+		vpcRepo := new(MockVpcRepo)
+		network := new(MockNetworkBackend)
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		return services.NewSecurityGroupService(repo, vpcRepo, network, audit, slog.Default()), repo, vpcRepo, network
+	}
+
+	t.Run("AddRule expands source group members", func(t *testing.T) {
+		svc, repo, vpcRepo, network := setup()
+		dbGroup := &domain.SecurityGroup{ID: uuid.New(), VPCID: vpcID}
+		appGroup := &domain.SecurityGroup{ID: uuid.New(), VPCID: vpcID}
+
+		repo.On("GetByID", mock.Anything, dbGroup.ID).Return(dbGroup, nil)
+		repo.On("GetByID", mock.Anything, appGroup.ID).Return(appGroup, nil)
+		repo.On("AddRule", mock.Anything, mock.Anything).Return(nil)
+		repo.On("ListGroupMemberIPs", mock.Anything, dbGroup.ID).Return([]string{"10.0.2.5", "fd00:10:0:2::5"}, nil)
+		repo.On("ListGroupMemberIPs", mock.Anything, appGroup.ID).Return([]string{"10.0.1.4", "10.0.1.9", "fd00:10:0:1::4"}, nil)
+		vpcRepo.On("GetByID", mock.Anything, vpcID).Return(vpc, nil)
+		network.On("AddFlowRule", mock.Anything, "br-vpc", mock.Anything).Return(nil)
+
+		rule, err := svc.AddRule(ctx, dbGroup.ID, domain.SecurityRule{
+			Direction: domain.RuleIngress, Protocol: "tcp", PortMin: 5432, PortMax: 5432, SourceGroupID: &appGroup.ID, Priority: 100,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, appGroup.ID, *rule.SourceGroupID)
+		for _, ip := range []string{"10.0.1.4", "10.0.1.9"} {
+			network.AssertCalled(t, "AddFlowRule", mock.Anything, "br-vpc", ports.FlowRule{
+				Priority: 100,
+				Match:    "table=11,ct_state=+trk+new,tcp,nw_src=" + ip + "/32,nw_dst=10.0.2.5/32,tp_dst=5432",
+				Actions:  "ct(commit),resubmit(,0)",
+			})
+		}
+		network.AssertCalled(t, "AddFlowRule", mock.Anything, "br-vpc", ports.FlowRule{
+			Priority: 100,
+			Match:    "table=11,ct_state=+trk+new,tcp6,ipv6_src=fd00:10:0:1::4/128,ipv6_dst=fd00:10:0:2::5/128,tp_dst=5432",
+			Actions:  "ct(commit),resubmit(,0)",
+		})
+		// Peers of the other address family never match.
+		network.AssertNotCalled(t, "AddFlowRule", mock.Anything, "br-vpc", mock.MatchedBy(func(f ports.FlowRule) bool {
+			return strings.Contains(f.Match, "nw_src=10.0.1.4/32,ipv6_dst")
+		}))
+	})
+
+	t.Run("AddRule rejects source group in another VPC", func(t *testing.T) {
+		svc, repo, _, _ := setup()
+		group := &domain.SecurityGroup{ID: uuid.New(), VPCID: vpcID}
+		other := &domain.SecurityGroup{ID: uuid.New(), VPCID: uuid.New()}
+		repo.On("GetByID", mock.Anything, group.ID).Return(group, nil)
+		repo.On("GetByID", mock.Anything, other.ID).Return(other, nil)
+
+		_, err := svc.AddRule(ctx, group.ID, domain.SecurityRule{
+			Direction: domain.RuleIngress, Protocol: "all", SourceGroupID: &other.ID,
+		})
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+		repo.AssertNotCalled(t, "AddRule", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AttachToInstance resyncs referencing rules", func(t *testing.T) {
+		svc, repo, vpcRepo, network := setup()
+		appGroup := &domain.SecurityGroup{ID: uuid.New(), VPCID: vpcID}
+		dbGroupID := uuid.New()
+		instanceID := uuid.New()
+		ref := domain.SecurityRule{
+			ID: uuid.New(), GroupID: dbGroupID, Direction: domain.RuleIngress, Protocol: "tcp",
+			PortMin: 5432, PortMax: 5432, SourceGroupID: &appGroup.ID, Priority: 100,
+		}
+
+		repo.On("ListGroupMemberIPs", mock.Anything, appGroup.ID).Return([]string{"10.0.1.4"}, nil).Once()
+		repo.On("AddInstanceToGroup", mock.Anything, instanceID, appGroup.ID).Return(nil)
+		repo.On("GetByID", mock.Anything, appGroup.ID).Return(appGroup, nil)
+		repo.On("ListRulesReferencingGroup", mock.Anything, appGroup.ID).Return([]domain.SecurityRule{ref}, nil)
+		repo.On("ListGroupMemberIPs", mock.Anything, appGroup.ID).Return([]string{"10.0.1.4", "10.0.1.7"}, nil)
+		repo.On("ListGroupMemberIPs", mock.Anything, dbGroupID).Return([]string{"10.0.2.5"}, nil)
+		vpcRepo.On("GetByID", mock.Anything, vpcID).Return(vpc, nil)
+		network.On("AddFlowRule", mock.Anything, "br-vpc", mock.Anything).Return(nil)
+
+		require.NoError(t, svc.AttachToInstance(ctx, instanceID, appGroup.ID))
+		network.AssertCalled(t, "AddFlowRule", mock.Anything, "br-vpc", ports.FlowRule{
+			Priority: 100,
+			Match:    "table=11,ct_state=+trk+new,tcp,nw_src=10.0.1.7/32,nw_dst=10.0.2.5/32,tp_dst=5432",
+			Actions:  "ct(commit),resubmit(,0)",
+		})
+		network.AssertCalled(t, "AddFlowRule", mock.Anything, "br-vpc", ports.FlowRule{
+			Priority: 65000,
+			Match:    "ip,nw_src=10.0.1.7/32,ct_state=-trk",
+			Actions:  "ct(table=10)",
+		})
+		network.AssertNotCalled(t, "AddFlowRule", mock.Anything, "br-vpc", mock.MatchedBy(func(f ports.FlowRule) bool {
+			return strings.Contains(f.Match, "nw_src=10.0.1.4/32,nw_dst=10.0.2.5/32")
+		}))
+	})
+
+	t.Run("DetachFromInstance removes departed member flows", func(t *testing.T) {
+		svc, repo, vpcRepo, network := setup()
+		appGroup := &domain.SecurityGroup{ID: uuid.New(), VPCID: vpcID}
+		dbGroup := &domain.SecurityGroup{ID: uuid.New(), VPCID: vpcID}
+		instanceID := uuid.New()
+		ref := domain.SecurityRule{
+			ID: uuid.New(), GroupID: dbGroup.ID, Direction: domain.RuleEgress, Protocol: "all",
+			SourceGroupID: &appGroup.ID, Priority: 100,
+		}
+		dbGroup.Rules = []domain.SecurityRule{ref}
+
+		repo.On("ListGroupMemberIPs", mock.Anything, appGroup.ID).Return([]string{"10.0.1.4"}, nil).Once()
+		repo.On("RemoveInstanceFromGroup", mock.Anything, instanceID, appGroup.ID).Return(nil)
+		repo.On("GetByID", mock.Anything, appGroup.ID).Return(appGroup, nil)
+		repo.On("GetByID", mock.Anything, dbGroup.ID).Return(dbGroup, nil)
+		repo.On("ListRulesReferencingGroup", mock.Anything, appGroup.ID).Return([]domain.SecurityRule{ref}, nil)
+		repo.On("ListGroupMemberIPs", mock.Anything, appGroup.ID).Return([]string{}, nil)
+		repo.On("ListGroupMemberIPs", mock.Anything, dbGroup.ID).Return([]string{"10.0.2.5"}, nil)
+		repo.On("ListByVPC", mock.Anything, vpcID).Return([]*domain.SecurityGroup{appGroup, dbGroup}, nil)
+		vpcRepo.On("GetByID", mock.Anything, vpcID).Return(vpc, nil)
+		network.On("DeleteFlowRule", mock.Anything, "br-vpc", mock.Anything).Return(nil)
+
+		require.NoError(t, svc.DetachFromInstance(ctx, instanceID, appGroup.ID))
+		for _, match := range []string{
+			"ip,nw_src=10.0.1.4/32,ct_state=-trk",
+			"ip,nw_dst=10.0.1.4/32,ct_state=-trk",
+			"table=10,ip,nw_src=10.0.1.4/32",
+			"table=11,ip,nw_dst=10.0.1.4/32",
+			"table=10,ct_state=+trk+new,ip,nw_dst=10.0.1.4/32,nw_src=10.0.2.5/32",
+		} {
+			network.AssertCalled(t, "DeleteFlowRule", mock.Anything, "br-vpc", match)
+		}
+		network.AssertNumberOfCalls(t, "DeleteFlowRule", 5)
+	})
+
+	t.Run("DetachFromInstance keeps flows another group still needs", func(t *testing.T) {
+		svc, repo, vpcRepo, network := setup()
+		http := domain.SecurityRule{ID: uuid.New(), Direction: domain.RuleIngress, Protocol: "tcp", PortMin: 80, PortMax: 80, CIDR: "0.0.0.0/0"}
+		ssh := domain.SecurityRule{ID: uuid.New(), Direction: domain.RuleIngress, Protocol: "tcp", PortMin: 22, PortMax: 22, CIDR: "0.0.0.0/0"}
+		webGroup := &domain.SecurityGroup{ID: uuid.New(), VPCID: vpcID, Rules: []domain.SecurityRule{http, ssh}}
+		opsGroup := &domain.SecurityGroup{ID: uuid.New(), VPCID: vpcID, Rules: []domain.SecurityRule{ssh}}
+		instanceID := uuid.New()
+
+		repo.On("ListGroupMemberIPs", mock.Anything, webGroup.ID).Return([]string{"10.0.1.4", "10.0.1.5"}, nil).Once()
+		repo.On("RemoveInstanceFromGroup", mock.Anything, instanceID, webGroup.ID).Return(nil)
+		repo.On("GetByID", mock.Anything, webGroup.ID).Return(webGroup, nil)
+		repo.On("GetByID", mock.Anything, opsGroup.ID).Return(opsGroup, nil)
+		repo.On("ListRulesReferencingGroup", mock.Anything, webGroup.ID).Return([]domain.SecurityRule{}, nil)
+		repo.On("ListGroupMemberIPs", mock.Anything, webGroup.ID).Return([]string{"10.0.1.5"}, nil)
+		repo.On("ListGroupMemberIPs", mock.Anything, opsGroup.ID).Return([]string{"10.0.1.4"}, nil)
+		repo.On("ListByVPC", mock.Anything, vpcID).Return([]*domain.SecurityGroup{webGroup, opsGroup}, nil)
+		vpcRepo.On("GetByID", mock.Anything, vpcID).Return(vpc, nil)
+		network.On("DeleteFlowRule", mock.Anything, "br-vpc", "table=11,ct_state=+trk+new,tcp,nw_dst=10.0.1.4/32,tp_dst=80").Return(nil).Once()
+		network.On("AddFlowRule", mock.Anything, "br-vpc", mock.Anything).Return(nil)
+
+		require.NoError(t, svc.DetachFromInstance(ctx, instanceID, webGroup.ID))
+		network.AssertExpectations(t)
+		network.AssertNumberOfCalls(t, "DeleteFlowRule", 1)
+		network.AssertCalled(t, "AddFlowRule", mock.Anything, "br-vpc", ports.FlowRule{
+			Priority: 100,
+			Match:    "table=11,ct_state=+trk+new,tcp,nw_dst=10.0.1.4/32,tp_dst=22",
+			Actions:  "ct(commit),resubmit(,0)",
+		})
+		network.AssertNotCalled(t, "AddFlowRule", mock.Anything, "br-vpc", mock.MatchedBy(func(f ports.FlowRule) bool {
+			return strings.Contains(f.Match, "10.0.1.5")
+		}))
+	})
+
+	t.Run("RemoveRule keeps flows an identical rule still needs", func(t *testing.T) {
+		svc, repo, vpcRepo, network := setup()
+		http := domain.SecurityRule{ID: uuid.New(), Direction: domain.RuleIngress, Protocol: "tcp", PortMin: 80, PortMax: 80, CIDR: "0.0.0.0/0"}
+		webGroup := &domain.SecurityGroup{ID: uuid.New(), VPCID: vpcID}
+		lbGroup := &domain.SecurityGroup{ID: uuid.New(), VPCID: vpcID, Rules: []domain.SecurityRule{http}}
+		rule := http
+		rule.ID, rule.GroupID = uuid.New(), webGroup.ID
+
+		repo.On("GetRuleByID", mock.Anything, rule.ID).Return(&rule, nil)
+		repo.On("GetByID", mock.Anything, webGroup.ID).Return(webGroup, nil)
+		repo.On("GetByID", mock.Anything, lbGroup.ID).Return(lbGroup, nil)
+		repo.On("ListGroupMemberIPs", mock.Anything, webGroup.ID).Return([]string{"10.0.1.4"}, nil)
+		repo.On("ListGroupMemberIPs", mock.Anything, lbGroup.ID).Return([]string{"10.0.1.4"}, nil)
+		repo.On("DeleteRule", mock.Anything, rule.ID).Return(nil)
+		repo.On("ListByVPC", mock.Anything, vpcID).Return([]*domain.SecurityGroup{webGroup, lbGroup}, nil)
+		vpcRepo.On("GetByID", mock.Anything, vpcID).Return(vpc, nil)
+
+		require.NoError(t, svc.RemoveRule(ctx, rule.ID))
+		network.AssertNotCalled(t, "DeleteFlowRule", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DeleteGroup refuses referenced group", func(t *testing.T) {
+		svc, repo, _, _ := setup()
+		groupID := uuid.New()
+		repo.On("ListRulesReferencingGroup", mock.Anything, groupID).Return([]domain.SecurityRule{{GroupID: uuid.New(), SourceGroupID: &groupID}}, nil)
+
+		err := svc.DeleteGroup(ctx, groupID)
+		assert.True(t, errors.Is(err, errors.Conflict))
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSecurityGroupService_InstanceLifecycle(t *testing.T) {
+	ctx := appcontext.WithUserID(context.Background(), uuid.New())
+	vpcID := uuid.New()
+	vpc := &domain.VPC{ID: vpcID, NetworkID: "br-vpc"}
+
+	setup := func() (*services.SecurityGroupService, *MockSecurityGroupRepo, *MockNetworkBackend) {
+		repo := new(MockSecurityGroupRepo)
+		vpcRepo := new(MockVpcRepo)
+		network := new(MockNetworkBackend)
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		vpcRepo.On("GetByID", mock.Anything, vpcID).Return(vpc, nil)
+		return services.NewSecurityGroupService(repo, vpcRepo, network, audit, slog.Default()), repo, network
+	}
+
+	t.Run("DetachAllFromInstance detaches every group", func(t *testing.T) {
+		svc, repo, network := setup()
+		instanceID := uuid.New()
+		group := &domain.SecurityGroup{ID: uuid.New(), VPCID: vpcID}
+
+		repo.On("ListInstanceGroups", mock.Anything, instanceID).Return([]*domain.SecurityGroup{group}, nil)
+		repo.On("ListGroupMemberIPs", mock.Anything, group.ID).Return([]string{"10.0.1.4"}, nil).Once()
+		repo.On("RemoveInstanceFromGroup", mock.Anything, instanceID, group.ID).Return(nil).Once()
+		repo.On("GetByID", mock.Anything, group.ID).Return(group, nil)
+		repo.On("ListGroupMemberIPs", mock.Anything, group.ID).Return([]string{}, nil)
+		repo.On("ListRulesReferencingGroup", mock.Anything, group.ID).Return([]domain.SecurityRule{}, nil)
+		repo.On("ListByVPC", mock.Anything, vpcID).Return([]*domain.SecurityGroup{group}, nil)
+		network.On("DeleteFlowRule", mock.Anything, "br-vpc", mock.Anything).Return(nil)
+
+		require.NoError(t, svc.DetachAllFromInstance(ctx, instanceID))
+		repo.AssertExpectations(t)
+		network.AssertCalled(t, "DeleteFlowRule", mock.Anything, "br-vpc", "ip,nw_src=10.0.1.4/32,ct_state=-trk")
+	})
+
+	t.Run("SyncInstanceAddresses moves flows to the new address", func(t *testing.T) {
+		svc, repo, network := setup()
+		instanceID := uuid.New()
+		ssh := domain.SecurityRule{ID: uuid.New(), Direction: domain.RuleIngress, Protocol: "tcp", PortMin: 22, PortMax: 22, CIDR: "0.0.0.0/0"}
+		group := &domain.SecurityGroup{ID: uuid.New(), VPCID: vpcID, Rules: []domain.SecurityRule{ssh}}
+
+		repo.On("ListInstanceGroups", mock.Anything, instanceID).Return([]*domain.SecurityGroup{{ID: group.ID}}, nil)
+		repo.On("GetByID", mock.Anything, group.ID).Return(group, nil)
+		repo.On("ListGroupMemberIPs", mock.Anything, group.ID).Return([]string{"10.0.1.9"}, nil)
+		repo.On("ListRulesReferencingGroup", mock.Anything, group.ID).Return([]domain.SecurityRule{}, nil)
+		repo.On("ListByVPC", mock.Anything, vpcID).Return([]*domain.SecurityGroup{group}, nil)
+		network.On("DeleteFlowRule", mock.Anything, "br-vpc", mock.Anything).Return(nil)
+		network.On("AddFlowRule", mock.Anything, "br-vpc", mock.Anything).Return(nil)
+
+		require.NoError(t, svc.SyncInstanceAddresses(ctx, instanceID, []string{"10.0.1.4"}, []string{"10.0.1.9"}))
+		network.AssertCalled(t, "DeleteFlowRule", mock.Anything, "br-vpc", "table=11,ct_state=+trk+new,tcp,nw_dst=10.0.1.4/32,tp_dst=22")
+		network.AssertCalled(t, "AddFlowRule", mock.Anything, "br-vpc", ports.FlowRule{
+			Priority: 100,
+			Match:    "table=11,ct_state=+trk+new,tcp,nw_dst=10.0.1.9/32,tp_dst=22",
+			Actions:  "ct(commit),resubmit(,0)",
+		})
+	})
+
+	t.Run("SyncInstanceAddresses ignores unchanged addresses", func(t *testing.T) {
+		svc, repo, _ := setup()
+		require.NoError(t, svc.SyncInstanceAddresses(ctx, uuid.New(), []string{"10.0.1.4"}, []string{"10.0.1.4"}))
+		repo.AssertNotCalled(t, "ListInstanceGroups", mock.Anything, mock.Anything)
 	})
 }
@@ -308,6 +308,30 @@ func (m *MockLBService) Delete(ctx context.Context, idOrName string) error {
 	args := m.Called(ctx, idOrName)
 	return args.Error(0)
 }
+func (m *MockLBService) ConfigureTLS(ctx context.Context, idOrName string, cfg *domain.LBTLSConfig) (*domain.LoadBalancer, error) {
+	args := m.Called(ctx, idOrName, cfg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoadBalancer), args.Error(1)
+}
+func (m *MockLBService) ConfigureAttributes(ctx context.Context, idOrName string, params ports.UpdateLBAttributesParams) (*domain.LoadBalancer, error) {
+	args := m.Called(ctx, idOrName, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoadBalancer), args.Error(1)
+}
+func (m *MockLBService) ConfigureListeners(ctx context.Context, idOrName string, listeners []domain.LBListener) (*domain.LoadBalancer, error) {
+	args := m.Called(ctx, idOrName, listeners)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoadBalancer), args.Error(1)
+}
+func (m *MockLBService) AddTargetToGroup(ctx context.Context, lbID, instanceID uuid.UUID, port int, weight int, group string) error {
+	return m.Called(ctx, lbID, instanceID, port, weight, group).Error(0)
+}
 func (m *MockLBService) AddTarget(ctx context.Context, lbID, instanceID uuid.UUID, port, weight int) error {
 	args := m.Called(ctx, lbID, instanceID, port, weight)
 	return args.Error(0)
@@ -316,6 +340,10 @@ func (m *MockLBService) RemoveTarget(ctx context.Context, lbID, instanceID uuid.
 	args := m.Called(ctx, lbID, instanceID)
 	return args.Error(0)
 }
+func (m *MockLBService) RemoveTargetFromGroup(ctx context.Context, lbID, instanceID uuid.UUID, group string) error {
+	args := m.Called(ctx, lbID, instanceID, group)
+	return args.Error(0)
+}
 func (m *MockLBService) ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain.LBTarget, error) {
 	args := m.Called(ctx, lbID)
 	if args.Get(0) == nil {
@@ -419,6 +447,10 @@ func (m *MockStorageRepo) SaveMeta(ctx context.Context, obj *domain.Object) erro
 	args := m.Called(ctx, obj)
 	return args.Error(0)
 }
+func (m *MockStorageRepo) SaveMetaIf(ctx context.Context, obj, current *domain.Object) error {
+	args := m.Called(ctx, obj, current)
+	return args.Error(0)
+}
 func (m *MockStorageRepo) GetMeta(ctx context.Context, bucket, key string) (*domain.Object, error) {
 	args := m.Called(ctx, bucket, key)
 	if args.Get(0) == nil {
@@ -426,21 +458,29 @@ func (m *MockStorageRepo) GetMeta(ctx context.Context, bucket, key string) (*dom
 	}
 	return args.Get(0).(*domain.Object), args.Error(1)
 }
-func (m *MockStorageRepo) List(ctx context.Context, bucket string) ([]*domain.Object, error) {
-	args := m.Called(ctx, bucket)
+func (m *MockStorageRepo) List(ctx context.Context, bucket string, opts domain.ObjectListOptions) (*domain.ObjectListResult, error) {
+	args := m.Called(ctx, bucket, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*domain.Object), args.Error(1)
+	return args.Get(0).(*domain.ObjectListResult), args.Error(1)
 }
 func (m *MockStorageRepo) SoftDelete(ctx context.Context, bucket, key string) error {
 	args := m.Called(ctx, bucket, key)
 	return args.Error(0)
 }
+func (m *MockStorageRepo) SoftDeleteIf(ctx context.Context, bucket, key string, current *domain.Object) error {
+	args := m.Called(ctx, bucket, key, current)
+	return args.Error(0)
+}
 func (m *MockStorageRepo) DeleteVersion(ctx context.Context, bucket, key, versionID string) error {
 	args := m.Called(ctx, bucket, key, versionID)
 	return args.Error(0)
 }
+func (m *MockStorageRepo) DeleteVersionIf(ctx context.Context, bucket, key, versionID string, current *domain.Object) error {
+	args := m.Called(ctx, bucket, key, versionID, current)
+	return args.Error(0)
+}
 func (m *MockStorageRepo) GetMetaByVersion(ctx context.Context, bucket, key, versionID string) (*domain.Object, error) {
 	args := m.Called(ctx, bucket, key, versionID)
 	if args.Get(0) == nil {
@@ -481,6 +521,17 @@ func (m *MockStorageRepo) GetBucket(ctx context.Context, name string) (*domain.B
 func (m *MockStorageRepo) DeleteBucket(ctx context.Context, name string) error {
 	return m.Called(ctx, name).Error(0)
 }
+func (m *MockStorageRepo) BucketHasObjects(ctx context.Context, bucket string) (bool, error) {
+	args := m.Called(ctx, bucket)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockStorageRepo) ListLockedVersions(ctx context.Context, bucket string, limit int) ([]*domain.Object, error) {
+	args := m.Called(ctx, bucket, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Object), args.Error(1)
+}
 func (m *MockStorageRepo) ListBuckets(ctx context.Context, userID string) ([]*domain.Bucket, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -491,6 +542,138 @@ func (m *MockStorageRepo) ListBuckets(ctx context.Context, userID string) ([]*do
 func (m *MockStorageRepo) SetBucketVersioning(ctx context.Context, name string, enabled bool) error {
 	return m.Called(ctx, name, enabled).Error(0)
 }
+func (m *MockStorageRepo) SetBucketStorageClass(ctx context.Context, name string, class domain.StorageClass, layout domain.ErasureLayout) error {
+	return m.Called(ctx, name, class, layout).Error(0)
+}
+
+func (m *MockStorageRepo) GetBucketPolicy(ctx context.Context, bucket string) (*domain.BucketPolicy, error) {
+	args := m.Called(ctx, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BucketPolicy), args.Error(1)
+}
+
+func (m *MockStorageRepo) PutBucketPolicy(ctx context.Context, policy *domain.BucketPolicy) error {
+	return m.Called(ctx, policy).Error(0)
+}
+
+func (m *MockStorageRepo) DeleteBucketPolicy(ctx context.Context, bucket string) error {
+	return m.Called(ctx, bucket).Error(0)
+}
+
+func (m *MockStorageRepo) GetBucketNotifications(ctx context.Context, bucket string) (*domain.BucketNotificationConfig, error) {
+	args := m.Called(ctx, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BucketNotificationConfig), args.Error(1)
+}
+
+func (m *MockStorageRepo) PutBucketNotifications(ctx context.Context, cfg *domain.BucketNotificationConfig) error {
+	return m.Called(ctx, cfg).Error(0)
+}
+
+func (m *MockStorageRepo) DeleteBucketNotifications(ctx context.Context, bucket string) error {
+	return m.Called(ctx, bucket).Error(0)
+}
+func (m *MockStorageRepo) SetBucketObjectLock(ctx context.Context, name string, cfg domain.ObjectLockConfig) error {
+	return m.Called(ctx, name, cfg).Error(0)
+}
+func (m *MockStorageRepo) SetObjectRetention(ctx context.Context, bucket, key, versionID string, retention *domain.ObjectRetention) error {
+	return m.Called(ctx, bucket, key, versionID, retention).Error(0)
+}
+func (m *MockStorageRepo) SetObjectLegalHold(ctx context.Context, bucket, key, versionID string, on bool) error {
+	return m.Called(ctx, bucket, key, versionID, on).Error(0)
+}
+
+func (m *MockStorageRepo) GetBucketReplication(ctx context.Context, bucket string) (*domain.BucketReplicationConfig, error) {
+	args := m.Called(ctx, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BucketReplicationConfig), args.Error(1)
+}
+
+func (m *MockStorageRepo) PutBucketReplication(ctx context.Context, cfg *domain.BucketReplicationConfig) error {
+	return m.Called(ctx, cfg).Error(0)
+}
+
+func (m *MockStorageRepo) DeleteBucketReplication(ctx context.Context, bucket string) error {
+	return m.Called(ctx, bucket).Error(0)
+}
+
+func (m *MockStorageRepo) EnqueueReplication(ctx context.Context, reps []*domain.ObjectReplication) error {
+	return m.Called(ctx, reps).Error(0)
+}
+
+func (m *MockStorageRepo) ListPendingReplication(ctx context.Context, limit int) ([]*domain.ObjectReplication, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ObjectReplication), args.Error(1)
+}
+
+func (m *MockStorageRepo) UpdateReplication(ctx context.Context, rep *domain.ObjectReplication) error {
+	return m.Called(ctx, rep).Error(0)
+}
+
+func (m *MockStorageRepo) ListObjectReplication(ctx context.Context, bucket, key string) ([]*domain.ObjectReplication, error) {
+	args := m.Called(ctx, bucket, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ObjectReplication), args.Error(1)
+}
+
+func (m *MockStorageRepo) ListReplicationBacklog(ctx context.Context, bucket string) ([]*domain.ReplicationBacklog, error) {
+	args := m.Called(ctx, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ReplicationBacklog), args.Error(1)
+}
+
+func (m *MockStorageRepo) SetBucketEncryption(ctx context.Context, name string, enabled bool, keyID string) error {
+	return m.Called(ctx, name, enabled, keyID).Error(0)
+}
+
+func (m *MockStorageRepo) ListObjectsBelowKeyVersion(ctx context.Context, bucket string, version, limit int) ([]*domain.Object, error) {
+	args := m.Called(ctx, bucket, version, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Object), args.Error(1)
+}
+
+func (m *MockStorageRepo) ListErasureObjects(ctx context.Context, afterID uuid.UUID, limit int) ([]*domain.Object, error) {
+	args := m.Called(ctx, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Object), args.Error(1)
+}
+
+func (m *MockStorageRepo) SetObjectACL(ctx context.Context, bucket, key, versionID string, acl domain.ObjectACL) error {
+	return m.Called(ctx, bucket, key, versionID, acl).Error(0)
+}
+
+func (m *MockStorageRepo) SetObjectTags(ctx context.Context, bucket, key, versionID string, tags map[string]string) error {
+	return m.Called(ctx, bucket, key, versionID, tags).Error(0)
+}
+
+func (m *MockStorageRepo) ListVersionsPage(ctx context.Context, bucket, prefix, startAfter string, maxKeys int) ([]*domain.Object, error) {
+	args := m.Called(ctx, bucket, prefix, startAfter, maxKeys)
+	r0, _ := args.Get(0).([]*domain.Object)
+	return r0, args.Error(1)
+}
+
+func (m *MockStorageRepo) ListMultipartUploads(ctx context.Context, bucket, prefix string, initiatedBefore time.Time) ([]*domain.MultipartUpload, error) {
+	args := m.Called(ctx, bucket, prefix, initiatedBefore)
+	r0, _ := args.Get(0).([]*domain.MultipartUpload)
+	return r0, args.Error(1)
+}
 
 func (m *MockStorageRepo) SaveMultipartUpload(ctx context.Context, upload *domain.MultipartUpload) error {
 	return m.Called(ctx, upload).Error(0)
@@ -547,6 +730,24 @@ func (m *MockFileStore) Assemble(ctx context.Context, bucket, key string, parts
 	args := m.Called(ctx, bucket, key, parts)
 	return args.Get(0).(int64), args.Error(1)
 }
+func (m *MockFileStore) WriteErasure(ctx context.Context, bucket, key string, r io.Reader, layout domain.ErasureLayout) (int64, int64, error) {
+	args := m.Called(ctx, bucket, key, r, layout)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockFileStore) ReadErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout, size int64) (io.ReadCloser, error) {
+	args := m.Called(ctx, bucket, key, layout, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+func (m *MockFileStore) DeleteErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout) error {
+	return m.Called(ctx, bucket, key, layout).Error(0)
+}
+func (m *MockFileStore) RepairErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout, size int64) (int, error) {
+	args := m.Called(ctx, bucket, key, layout, size)
+	return args.Int(0), args.Error(1)
+}
 
 // MockVolumeRepo
 type MockVolumeRepo struct {
@@ -1167,6 +1368,9 @@ func (m *MockNetworkBackend) AddPort(ctx context.Context, bridge, portName strin
 func (m *MockNetworkBackend) DeletePort(ctx context.Context, bridge, portName string) error {
 	return m.AddPort(ctx, bridge, portName)
 }
+func (m *MockNetworkBackend) CreatePatchPort(ctx context.Context, bridge, portName, peerPort string) error {
+	return m.Called(ctx, bridge, portName, peerPort).Error(0)
+}
 func (m *MockNetworkBackend) CreateVXLANTunnel(ctx context.Context, bridge string, vni int, remoteIP string) error {
 	args := m.Called(ctx, bridge, vni, remoteIP)
 	return args.Error(0)
@@ -1191,6 +1395,46 @@ func (m *MockNetworkBackend) ListFlowRules(ctx context.Context, bridge string) (
 	return args.Get(0).([]ports.FlowRule), args.Error(1)
 }
 
+func (m *MockNetworkBackend) AttachInternetGateway(ctx context.Context, bridge, name, vpcCIDR string) error {
+	return m.Called(ctx, bridge, name, vpcCIDR).Error(0)
+}
+
+func (m *MockNetworkBackend) DetachInternetGateway(ctx context.Context, bridge, name string) error {
+	return m.Called(ctx, bridge, name).Error(0)
+}
+
+func (m *MockNetworkBackend) MapElasticIP(ctx context.Context, gateway, publicIP, privateIP string) error {
+	return m.Called(ctx, gateway, publicIP, privateIP).Error(0)
+}
+
+func (m *MockNetworkBackend) UnmapElasticIP(ctx context.Context, gateway, publicIP, privateIP string) error {
+	return m.Called(ctx, gateway, publicIP, privateIP).Error(0)
+}
+
+func (m *MockNetworkBackend) CreateNATGateway(ctx context.Context, bridge, name, privateIP, publicIP, sourceCIDR string) error {
+	return m.Called(ctx, bridge, name, privateIP, publicIP, sourceCIDR).Error(0)
+}
+
+func (m *MockNetworkBackend) DeleteNATGateway(ctx context.Context, bridge, name string) error {
+	return m.Called(ctx, bridge, name).Error(0)
+}
+
+func (m *MockNetworkBackend) ApplyNetworkACL(ctx context.Context, bridge, subnetCIDR string, rules []domain.NetworkACLRule) error {
+	return m.Called(ctx, bridge, subnetCIDR, rules).Error(0)
+}
+
+func (m *MockNetworkBackend) RemoveNetworkACL(ctx context.Context, bridge, subnetCIDR string) error {
+	return m.Called(ctx, bridge, subnetCIDR).Error(0)
+}
+
+func (m *MockNetworkBackend) SampleFlows(ctx context.Context, bridge string) ([]domain.FlowRecord, error) {
+	args := m.Called(ctx, bridge)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.FlowRecord), args.Error(1)
+}
+
 func (m *MockNetworkBackend) CreateVethPair(ctx context.Context, hostEnd, containerEnd string) error {
 	args := m.Called(ctx, hostEnd, containerEnd)
 	return args.Error(0)
@@ -1385,8 +1629,8 @@ type MockVpcService struct {
 	mock.Mock
 }
 
-func (m *MockVpcService) CreateVPC(ctx context.Context, name, cidrBlock string) (*domain.VPC, error) {
-	args := m.Called(ctx, name, cidrBlock)
+func (m *MockVpcService) CreateVPC(ctx context.Context, name, cidrBlock, ipv6CIDRBlock string) (*domain.VPC, error) {
+	args := m.Called(ctx, name, cidrBlock, ipv6CIDRBlock)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -1713,8 +1957,8 @@ func (m *MockLBRepo) AddTarget(ctx context.Context, target *domain.LBTarget) err
 	args := m.Called(ctx, target)
 	return args.Error(0)
 }
-func (m *MockLBRepo) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID) error {
-	args := m.Called(ctx, lbID, instanceID)
+func (m *MockLBRepo) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID, group string) error {
+	args := m.Called(ctx, lbID, instanceID, group)
 	return args.Error(0)
 }
 func (m *MockLBRepo) ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain.LBTarget, error) {
@@ -1724,8 +1968,12 @@ func (m *MockLBRepo) ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain
 	}
 	return args.Get(0).([]*domain.LBTarget), args.Error(1)
 }
-func (m *MockLBRepo) UpdateTargetHealth(ctx context.Context, lbID, instanceID uuid.UUID, health string) error {
-	args := m.Called(ctx, lbID, instanceID, health)
+func (m *MockLBRepo) UpdateTargetHealth(ctx context.Context, lbID, instanceID uuid.UUID, group string, port int, health string) error {
+	args := m.Called(ctx, lbID, instanceID, group, port, health)
+	return args.Error(0)
+}
+func (m *MockLBRepo) DrainTarget(ctx context.Context, lbID, instanceID uuid.UUID, group string, until time.Time) error {
+	args := m.Called(ctx, lbID, instanceID, group, until)
 	return args.Error(0)
 }
 func (m *MockLBRepo) GetTargetsForInstance(ctx context.Context, instanceID uuid.UUID) ([]*domain.LBTarget, error) {
@@ -1786,7 +2034,25 @@ func (m *MockSecurityGroupRepo) RemoveInstanceFromGroup(ctx context.Context, ins
 	return m.Called(ctx, instanceID, groupID).Error(0)
 }
 func (m *MockSecurityGroupRepo) ListInstanceGroups(ctx context.Context, instanceID uuid.UUID) ([]*domain.SecurityGroup, error) {
-	return nil, nil
+	args := m.Called(ctx, instanceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.SecurityGroup), args.Error(1)
+}
+func (m *MockSecurityGroupRepo) ListGroupMemberIPs(ctx context.Context, groupID uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+func (m *MockSecurityGroupRepo) ListRulesReferencingGroup(ctx context.Context, groupID uuid.UUID) ([]domain.SecurityRule, error) {
+	args := m.Called(ctx, groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.SecurityRule), args.Error(1)
 }
 
 // MockQueueRepository
@@ -1985,6 +2251,26 @@ func (m *MockEncryptionRepository) GetKey(ctx context.Context, bucketName string
 	return args.Get(0).(*ports.EncryptionKey), args.Error(1)
 }
 
+func (m *MockEncryptionRepository) GetKeyVersion(ctx context.Context, bucketName string, version int) (*ports.EncryptionKey, error) {
+	args := m.Called(ctx, bucketName, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ports.EncryptionKey), args.Error(1)
+}
+
+func (m *MockEncryptionRepository) ListKeysNotWrappedBy(ctx context.Context, masterKeyID string, limit int) ([]ports.EncryptionKey, error) {
+	args := m.Called(ctx, masterKeyID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ports.EncryptionKey), args.Error(1)
+}
+
+func (m *MockEncryptionRepository) UpdateWrappedKey(ctx context.Context, id string, encryptedKey []byte, masterKeyID string) error {
+	return m.Called(ctx, id, encryptedKey, masterKeyID).Error(0)
+}
+
 // MockEncryptionService
 type MockEncryptionService struct {
 	mock.Mock
@@ -2006,6 +2292,15 @@ func (m *MockEncryptionService) Decrypt(ctx context.Context, bucket string, encr
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+func (m *MockEncryptionService) KeyVersion(encryptedData []byte) int {
+	return m.Called(encryptedData).Int(0)
+}
+
+func (m *MockEncryptionService) LatestKeyVersion(ctx context.Context, bucket string) (int, error) {
+	args := m.Called(ctx, bucket)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockEncryptionService) CreateKey(ctx context.Context, bucket string) (string, error) {
 	args := m.Called(ctx, bucket)
 	return args.String(0), args.Error(1)
@@ -2019,6 +2314,11 @@ func (m *MockEncryptionService) RotateKey(ctx context.Context, bucket string) (s
 	return args.Get(0).(string), args.Error(1)
 }
 
+func (m *MockEncryptionService) RewrapKeys(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
 // MockTenantRepo
 type MockTenantRepo struct {
 	mock.Mock
@@ -2255,3 +2555,47 @@ func (m *MockElasticIPRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	return m.Called(ctx, id).Error(0)
 }
 
+
+// MockCertificateRepo
+type MockCertificateRepo struct{ mock.Mock }
+
+func (m *MockCertificateRepo) Create(ctx context.Context, cert *domain.Certificate) error {
+	return m.Called(ctx, cert).Error(0)
+}
+func (m *MockCertificateRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Certificate, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Certificate), args.Error(1)
+}
+func (m *MockCertificateRepo) List(ctx context.Context, userID uuid.UUID) ([]*domain.Certificate, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Certificate), args.Error(1)
+}
+func (m *MockCertificateRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *MockCertificateRepo) ListAll(ctx context.Context) ([]*domain.Certificate, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Certificate), args.Error(1)
+}
+func (m *MockCertificateRepo) UpdateExpiryState(ctx context.Context, id uuid.UUID, status domain.CertificateStatus, alertDays *int) error {
+	return m.Called(ctx, id, status, alertDays).Error(0)
+}
+func (m *MockCertificateRepo) GetAuthority(ctx context.Context, name string) (*domain.CertificateAuthority, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CertificateAuthority), args.Error(1)
+}
+func (m *MockCertificateRepo) CreateAuthority(ctx context.Context, ca *domain.CertificateAuthority) error {
+	return m.Called(ctx, ca).Error(0)
+}
@@ -223,8 +223,9 @@ func (s *stackService) createVPC(ctx context.Context, stackID uuid.UUID, logical
 		name = fmt.Sprintf("%s-%s", logicalID, stackID.String()[:8])
 	}
 	cidr, _ := props["CIDRBlock"].(string)
+	ipv6CIDR, _ := props["IPv6CIDRBlock"].(string)
 
-	vpc, err := s.vpcSvc.CreateVPC(ctx, name, cidr)
+	vpc, err := s.vpcSvc.CreateVPC(ctx, name, cidr, ipv6CIDR)
 	if err != nil {
 		return uuid.Nil, err
 	}
@@ -63,7 +63,7 @@ Resources:
 	// Async expectations
 	vpcID := uuid.New()
 	vpc := &domain.VPC{ID: vpcID, Name: stackTestVpc}
-	vpcSvc.On("CreateVPC", mock.Anything, stackTestVpc, "").Return(vpc, nil)
+	vpcSvc.On("CreateVPC", mock.Anything, stackTestVpc, "", "").Return(vpc, nil)
 	repo.On("AddResource", mock.Anything, mock.MatchedBy(func(r *domain.StackResource) bool {
 		return r.LogicalID == "MyVPC" && r.ResourceType == "VPC"
 	})).Return(nil)
@@ -151,7 +151,7 @@ Resources:
 	// 1. VPC Success
 	vpcID := uuid.New()
 	vpc := &domain.VPC{ID: vpcID, Name: stackTestVpc}
-	vpcSvc.On("CreateVPC", mock.Anything, stackTestVpc, "").Return(vpc, nil)
+	vpcSvc.On("CreateVPC", mock.Anything, stackTestVpc, "", "").Return(vpc, nil)
 	repo.On("AddResource", mock.Anything, mock.MatchedBy(func(r *domain.StackResource) bool {
 		return r.LogicalID == "MyVPC" && r.ResourceType == "VPC"
 	})).Return(nil)
@@ -219,7 +219,7 @@ Resources:
 
 	vpcID := uuid.New()
 	vpc := &domain.VPC{ID: vpcID, Name: stackTestVpc}
-	vpcSvc.On("CreateVPC", mock.Anything, stackTestVpc, "").Return(vpc, nil)
+	vpcSvc.On("CreateVPC", mock.Anything, stackTestVpc, "", "").Return(vpc, nil)
 	repo.On("AddResource", mock.Anything, mock.MatchedBy(func(r *domain.StackResource) bool {
 		return r.LogicalID == "MyVPC" && r.ResourceType == "VPC"
 	})).Return(nil)
@@ -282,7 +282,7 @@ Resources:
 
 	vpcID := uuid.New()
 	vpc := &domain.VPC{ID: vpcID, Name: stackTestVpc}
-	vpcSvc.On("CreateVPC", mock.Anything, stackTestVpc, "").Return(vpc, nil)
+	vpcSvc.On("CreateVPC", mock.Anything, stackTestVpc, "", "").Return(vpc, nil)
 	repo.On("AddResource", mock.Anything, mock.MatchedBy(func(r *domain.StackResource) bool {
 		return r.LogicalID == "MyVPC" && r.ResourceType == "VPC"
 	})).Return(nil)
@@ -0,0 +1,220 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/poyrazk/thecloud/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageService_Encryption(t *testing.T) {
+	owner := uuid.New()
+	ctx := appcontext.WithUserID(context.Background(), owner)
+
+	type fixture struct {
+		svc    *services.StorageService
+		repo   *MockStorageRepo
+		queue  *MockTaskQueue
+		enc    *services.EncryptionService
+		keys   *memEncryptionRepo
+		master string
+		store  *InMemFileStore
+		saved  *domain.Object
+	}
+	newFixture := func(t *testing.T, bucket *domain.Bucket) *fixture {
+		f := &fixture{repo: new(MockStorageRepo), queue: new(MockTaskQueue), keys: &memEncryptionRepo{}, master: randomHexKey(t), store: NewInMemFileStore()}
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		f.repo.On("GetBucketPolicy", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "no policy")).Maybe()
+		f.repo.On("GetBucketReplication", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "no replication")).Maybe()
+		f.repo.On("GetBucketNotifications", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "none")).Maybe()
+		f.repo.On("GetBucket", mock.Anything, bucket.Name).Return(bucket, nil).Maybe()
+		f.repo.On("SaveMeta", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			f.saved = args.Get(1).(*domain.Object)
+		}).Return(nil).Maybe()
+
+		var err error
+		f.enc, err = services.NewEncryptionService(f.keys, f.master)
+		require.NoError(t, err)
+		f.svc = services.NewStorageService(f.repo, f.store, audit, f.enc, f.queue, nil, &platform.Config{})
+		return f
+	}
+	stored := func(f *fixture) []byte {
+		f.store.mu.RLock()
+		defer f.store.mu.RUnlock()
+		return f.store.files[f.saved.Bucket+"/"+f.saved.Key]
+	}
+	read := func(t *testing.T, f *fixture, ctx context.Context) (string, error) {
+		t.Helper()
+		f.repo.On("GetMeta", mock.Anything, f.saved.Bucket, f.saved.Key).Return(f.saved, nil).Once()
+		content, err := f.svc.GetObject(ctx, f.saved.Bucket, f.saved.Key, domain.GetObjectOptions{})
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = content.Body.Close() }()
+		data, err := io.ReadAll(content.Body)
+		return string(data), err
+	}
+
+	t.Run("objects sealed before a key rotation stay readable", func(t *testing.T) {
+		bucket := &domain.Bucket{Name: "vault", UserID: owner}
+		f := newFixture(t, bucket)
+		f.repo.On("SetBucketEncryption", mock.Anything, "vault", true, mock.Anything).Return(nil)
+
+		updated, err := f.svc.SetBucketEncryption(ctx, "vault", true)
+		require.NoError(t, err)
+		assert.NotEmpty(t, updated.EncryptionKeyID)
+
+		_, err = f.svc.Upload(ctx, "vault", "a.txt", strings.NewReader("first"))
+		require.NoError(t, err)
+		assert.Equal(t, domain.EncryptionSSE, f.saved.Encryption)
+		assert.Equal(t, 1, f.saved.KeyVersion)
+		assert.NotContains(t, string(stored(f)), "first")
+
+		_, err = f.svc.RotateBucketKey(ctx, "vault", false)
+		require.NoError(t, err)
+
+		got, err := read(t, f, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "first", got)
+		f.queue.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rotation can schedule re-encryption", func(t *testing.T) {
+		bucket := &domain.Bucket{Name: "vault", UserID: owner, EncryptionEnabled: true}
+		f := newFixture(t, bucket)
+		_, err := f.enc.CreateKey(ctx, "vault")
+		require.NoError(t, err)
+		f.repo.On("SetBucketEncryption", mock.Anything, "vault", true, mock.Anything).Return(nil)
+		f.queue.On("Enqueue", mock.Anything, domain.StorageReencryptionQueue, domain.StorageReencryptionJob{Bucket: "vault", UserID: owner}).Return(nil).Once()
+
+		_, err = f.svc.Upload(ctx, "vault", "a.txt", strings.NewReader("payload"))
+		require.NoError(t, err)
+		_, err = f.svc.RotateBucketKey(ctx, "vault", true)
+		require.NoError(t, err)
+		f.queue.AssertExpectations(t)
+
+		f.repo.On("ListObjectsBelowKeyVersion", mock.Anything, "vault", 2, 10).Return([]*domain.Object{f.saved}, nil).Once()
+		n, err := f.svc.ReencryptObjects(ctx, "vault", 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, 2, f.saved.KeyVersion)
+		assert.Equal(t, 2, f.enc.KeyVersion(stored(f)))
+
+		got, err := read(t, f, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "payload", got)
+	})
+
+	t.Run("only the owner manages bucket encryption", func(t *testing.T) {
+		f := newFixture(t, &domain.Bucket{Name: "vault", UserID: owner, EncryptionEnabled: true})
+		other := appcontext.WithUserID(context.Background(), uuid.New())
+
+		_, err := f.svc.SetBucketEncryption(other, "vault", false)
+		assert.True(t, errors.Is(err, errors.Forbidden))
+		_, err = f.svc.RotateBucketKey(other, "vault", true)
+		assert.True(t, errors.Is(err, errors.Forbidden))
+		f.repo.AssertNotCalled(t, "SetBucketEncryption", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("customer keys seal and open objects", func(t *testing.T) {
+		f := newFixture(t, &domain.Bucket{Name: "vault", UserID: owner, EncryptionEnabled: true})
+		key := make([]byte, domain.CustomerKeySize)
+		_, _ = rand.Read(key)
+		keyCtx := appcontext.WithCustomerKey(ctx, key)
+
+		_, err := f.svc.Upload(keyCtx, "vault", "secret.txt", strings.NewReader("customer data"))
+		require.NoError(t, err)
+		assert.Equal(t, domain.EncryptionSSEC, f.saved.Encryption)
+		assert.Equal(t, domain.CustomerKeyMD5(key), f.saved.CustomerKeyMD5)
+		assert.Zero(t, f.saved.KeyVersion)
+
+		got, err := read(t, f, keyCtx)
+		require.NoError(t, err)
+		assert.Equal(t, "customer data", got)
+
+		_, err = read(t, f, ctx)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+
+		wrong := make([]byte, domain.CustomerKeySize)
+		_, err = read(t, f, appcontext.WithCustomerKey(ctx, wrong))
+		assert.True(t, errors.Is(err, errors.Forbidden))
+	})
+
+	t.Run("HEAD reports the length GET returns", func(t *testing.T) {
+		bucket := &domain.Bucket{Name: "vault", UserID: owner}
+		f := newFixture(t, bucket)
+		f.repo.On("SetBucketEncryption", mock.Anything, "vault", true, mock.Anything).Return(nil)
+		_, err := f.svc.SetBucketEncryption(ctx, "vault", true)
+		require.NoError(t, err)
+		bucket.EncryptionEnabled = true
+		customerKey := make([]byte, domain.CustomerKeySize)
+		_, _ = rand.Read(customerKey)
+		keyCtx := appcontext.WithCustomerKey(ctx, customerKey)
+
+		check := func(t *testing.T, ctx context.Context) {
+			t.Helper()
+			f.repo.On("GetMeta", mock.Anything, "vault", f.saved.Key).Return(f.saved, nil).Once()
+			head, err := f.svc.HeadObject(ctx, "vault", f.saved.Key, domain.GetObjectOptions{})
+			require.NoError(t, err)
+			got, err := read(t, f, ctx)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(got)), head.SizeBytes)
+			assert.Greater(t, f.saved.SizeBytes, head.SizeBytes)
+		}
+
+		_, err = f.svc.Upload(ctx, "vault", "v1.txt", strings.NewReader("sealed with the first key"))
+		require.NoError(t, err)
+		check(t, ctx)
+
+		_, err = f.svc.RotateBucketKey(ctx, "vault", false)
+		require.NoError(t, err)
+		_, err = f.svc.Upload(ctx, "vault", "v2.txt", strings.NewReader("sealed with the second key"))
+		require.NoError(t, err)
+		check(t, ctx)
+
+		_, err = f.svc.Upload(keyCtx, "vault", "ssec.txt", strings.NewReader("sealed with a customer key"))
+		require.NoError(t, err)
+		check(t, keyCtx)
+
+		// Ciphertexts sealed before keys were versioned have no header.
+		master, _ := hex.DecodeString(f.master)
+		dek := make([]byte, 32)
+		_, _ = rand.Read(dek)
+		f.keys.keys = []ports.EncryptionKey{{ID: "legacy", BucketName: "vault", Version: 1, EncryptedKey: sealForTest(t, master, dek)}}
+		legacy := sealForTest(t, dek, []byte("sealed before key versions"))
+		_, err = f.store.Write(ctx, "vault", "legacy.txt", bytes.NewReader(legacy))
+		require.NoError(t, err)
+		f.saved = &domain.Object{Bucket: "vault", Key: "legacy.txt", SizeBytes: int64(len(legacy)), Encryption: domain.EncryptionSSE, KeyVersion: 1}
+		check(t, ctx)
+	})
+
+	t.Run("customer keys are refused for other objects and multipart uploads", func(t *testing.T) {
+		f := newFixture(t, &domain.Bucket{Name: "vault", UserID: owner})
+		keyCtx := appcontext.WithCustomerKey(ctx, make([]byte, domain.CustomerKeySize))
+
+		_, err := f.svc.Upload(ctx, "vault", "plain.txt", strings.NewReader("plain"))
+		require.NoError(t, err)
+		assert.Equal(t, domain.EncryptionNone, f.saved.Encryption)
+
+		_, err = read(t, f, keyCtx)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+
+		_, err = f.svc.CreateMultipartUpload(keyCtx, "vault", "big.bin")
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+	})
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+)
+
+// GetBucketNotifications returns the notification configuration of a bucket.
+func (s *StorageService) GetBucketNotifications(ctx context.Context, name string) (*domain.BucketNotificationConfig, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireBucketOwner(ctx, bucket); err != nil {
+		return nil, err
+	}
+	return s.repo.GetBucketNotifications(ctx, name)
+}
+
+// PutBucketNotifications validates and replaces the notification rules of a bucket.
+// Rules without an ID are assigned one.
+func (s *StorageService) PutBucketNotifications(ctx context.Context, name string, rules []domain.BucketNotificationRule) (*domain.BucketNotificationConfig, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireBucketOwner(ctx, bucket); err != nil {
+		return nil, err
+	}
+
+	cfg := &domain.BucketNotificationConfig{Bucket: name, Rules: rules, UpdatedAt: time.Now()}
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.New(errors.InvalidInput, err.Error())
+	}
+	for i := range cfg.Rules {
+		if cfg.Rules[i].ID == "" {
+			cfg.Rules[i].ID = uuid.NewString()
+		}
+	}
+	if err := s.repo.PutBucketNotifications(ctx, cfg); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "storage.bucket_notifications_put", "bucket", bucket.ID.String(), map[string]interface{}{
+		"name":  name,
+		"rules": len(cfg.Rules),
+	})
+
+	return cfg, nil
+}
+
+// DeleteBucketNotifications removes the notification configuration of a bucket.
+func (s *StorageService) DeleteBucketNotifications(ctx context.Context, name string) error {
+	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := requireBucketOwner(ctx, bucket); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteBucketNotifications(ctx, name); err != nil {
+		return err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "storage.bucket_notifications_delete", "bucket", bucket.ID.String(), map[string]interface{}{
+		"name": name,
+	})
+
+	return nil
+}
+
+// publishEvent queues the event for every notification rule of the bucket that selects it.
+// Delivery is asynchronous and best effort: the object operation has already succeeded.
+func (s *StorageService) publishEvent(ctx context.Context, bucket *domain.Bucket, name domain.StorageEventName, obj *domain.Object) {
+	if s.taskQueue == nil {
+		return
+	}
+	cfg, err := s.repo.GetBucketNotifications(ctx, bucket.Name)
+	if err != nil {
+		return
+	}
+
+	principal := "anonymous"
+	if userID := appcontext.UserIDFromContext(ctx); userID != uuid.Nil {
+		principal = userID.String()
+	}
+	versionID := obj.VersionID
+	if versionID == "null" {
+		versionID = ""
+	}
+	now := time.Now().UTC()
+	for _, rule := range cfg.Rules {
+		if !rule.Matches(name, obj.Key) {
+			continue
+		}
+		job := domain.StorageNotificationJob{
+			UserID:     bucket.UserID,
+			TargetType: rule.TargetType,
+			TargetID:   rule.TargetID,
+			Event: domain.StorageEvent{Records: []domain.StorageEventRecord{{
+				EventVersion: "2.1",
+				EventSource:  "thecloud:storage",
+				EventTime:    now,
+				EventName:    name,
+				UserIdentity: domain.StorageEventUser{PrincipalID: principal},
+				S3: domain.StorageEventDetails{
+					SchemaVersion:   "1.0",
+					ConfigurationID: rule.ID,
+					Bucket: domain.StorageEventBucket{
+						Name:          bucket.Name,
+						OwnerIdentity: domain.StorageEventUser{PrincipalID: bucket.UserID.String()},
+						ARN:           fmt.Sprintf("arn:thecloud:storage:local:default:bucket/%s", bucket.Name),
+					},
+					Object: domain.StorageEventObject{
+						Key:       obj.Key,
+						Size:      obj.SizeBytes,
+						ETag:      obj.ETag,
+						VersionID: versionID,
+						// Orders events on the same key; nanosecond timestamps increase per process.
+						Sequencer: fmt.Sprintf("%016X", now.UnixNano()),
+					},
+				},
+			}}},
+		}
+		_ = s.taskQueue.Enqueue(ctx, domain.StorageNotificationQueue, job)
+	}
+}
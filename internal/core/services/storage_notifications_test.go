@@ -0,0 +1,105 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/poyrazk/thecloud/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStorageService_Notifications(t *testing.T) {
+	owner := uuid.New()
+	queueID := uuid.New()
+	ctx := appcontext.WithUserID(context.Background(), owner)
+	bucket := &domain.Bucket{Name: "ingest", UserID: owner}
+	cfg := &domain.BucketNotificationConfig{Bucket: "ingest", Rules: []domain.BucketNotificationRule{{
+		ID:         "csv",
+		Events:     []domain.StorageEventName{domain.StorageEventObjectCreated},
+		Prefix:     "incoming/",
+		Suffix:     ".csv",
+		TargetType: domain.NotificationTargetQueue,
+		TargetID:   queueID,
+	}}}
+
+	newSvc := func() (*services.StorageService, *MockStorageRepo, *MockFileStore, *MockTaskQueue) {
+		repo := new(MockStorageRepo)
+		store := new(MockFileStore)
+		audit := new(MockAuditService)
+		tasks := new(MockTaskQueue)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		repo.On("GetBucketPolicy", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "no policy")).Maybe()
+		repo.On("GetBucketReplication", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "no replication")).Maybe()
+		repo.On("GetBucket", mock.Anything, "ingest").Return(bucket, nil).Maybe()
+		return services.NewStorageService(repo, store, audit, nil, tasks, nil, &platform.Config{}), repo, store, tasks
+	}
+
+	t.Run("upload matching a rule queues an event", func(t *testing.T) {
+		svc, repo, store, tasks := newSvc()
+		store.On("Write", mock.Anything, "ingest", "incoming/day1.csv", mock.Anything).Return(int64(4), nil).Once()
+		repo.On("SaveMeta", mock.Anything, mock.Anything).Return(nil).Once()
+		repo.On("GetBucketNotifications", mock.Anything, "ingest").Return(cfg, nil).Once()
+		tasks.On("Enqueue", mock.Anything, domain.StorageNotificationQueue, mock.MatchedBy(func(job domain.StorageNotificationJob) bool {
+			rec := job.Event.Records[0]
+			return job.UserID == owner && job.TargetID == queueID &&
+				rec.EventName == domain.StorageEventObjectCreatedPut && rec.S3.ConfigurationID == "csv" &&
+				rec.S3.Object.Key == "incoming/day1.csv" && rec.S3.Object.Size == 4 && rec.S3.Object.VersionID == ""
+		})).Return(nil).Once()
+
+		_, err := svc.Upload(ctx, "ingest", "incoming/day1.csv", strings.NewReader("a,b\n"))
+		assert.NoError(t, err)
+		tasks.AssertExpectations(t)
+	})
+
+	t.Run("filters skip unmatched keys and events", func(t *testing.T) {
+		svc, repo, store, tasks := newSvc()
+		store.On("Write", mock.Anything, "ingest", "incoming/readme.txt", mock.Anything).Return(int64(2), nil).Once()
+		repo.On("SaveMeta", mock.Anything, mock.Anything).Return(nil).Once()
+		repo.On("SoftDelete", mock.Anything, "ingest", "incoming/day1.csv").Return(nil).Once()
+		repo.On("GetBucketNotifications", mock.Anything, "ingest").Return(cfg, nil).Twice()
+
+		_, err := svc.Upload(ctx, "ingest", "incoming/readme.txt", strings.NewReader("hi"))
+		assert.NoError(t, err)
+		assert.NoError(t, svc.DeleteObject(ctx, "ingest", "incoming/day1.csv"))
+		tasks.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("delete emits ObjectRemoved", func(t *testing.T) {
+		svc, repo, _, tasks := newSvc()
+		removed := &domain.BucketNotificationConfig{Bucket: "ingest", Rules: []domain.BucketNotificationRule{{
+			ID: "gone", Events: []domain.StorageEventName{domain.StorageEventObjectRemoved}, TargetType: domain.NotificationTargetTopic, TargetID: uuid.New(),
+		}}}
+		repo.On("SoftDelete", mock.Anything, "ingest", "a.txt").Return(nil).Once()
+		repo.On("GetBucketNotifications", mock.Anything, "ingest").Return(removed, nil).Once()
+		tasks.On("Enqueue", mock.Anything, domain.StorageNotificationQueue, mock.MatchedBy(func(job domain.StorageNotificationJob) bool {
+			return job.TargetType == domain.NotificationTargetTopic && job.Event.Records[0].EventName == domain.StorageEventObjectRemovedDelete
+		})).Return(nil).Once()
+
+		assert.NoError(t, svc.DeleteObject(ctx, "ingest", "a.txt"))
+		tasks.AssertExpectations(t)
+	})
+
+	t.Run("PutBucketNotifications is owner only, validated and assigns ids", func(t *testing.T) {
+		svc, repo, _, _ := newSvc()
+		repo.On("PutBucketNotifications", mock.Anything, mock.Anything).Return(nil).Once()
+		rules := []domain.BucketNotificationRule{{Events: []domain.StorageEventName{domain.StorageEventObjectCreated}, TargetType: domain.NotificationTargetFunction, TargetID: uuid.New()}}
+
+		_, err := svc.PutBucketNotifications(appcontext.WithUserID(context.Background(), uuid.New()), "ingest", rules)
+		assert.True(t, errors.Is(err, errors.Forbidden))
+
+		_, err = svc.PutBucketNotifications(ctx, "ingest", []domain.BucketNotificationRule{{Events: []domain.StorageEventName{"ObjectCopied"}, TargetType: domain.NotificationTargetQueue, TargetID: queueID}})
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+
+		saved, err := svc.PutBucketNotifications(ctx, "ingest", rules)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, saved.Rules[0].ID)
+		repo.AssertExpectations(t)
+	})
+}
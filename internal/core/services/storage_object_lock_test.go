@@ -0,0 +1,161 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/poyrazk/thecloud/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStorageService_ObjectLock(t *testing.T) {
+	owner := uuid.New()
+	ctx := appcontext.WithUserID(context.Background(), owner)
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	newSvc := func(bucket *domain.Bucket) (*services.StorageService, *MockStorageRepo, *MockFileStore) {
+		repo := new(MockStorageRepo)
+		store := new(MockFileStore)
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		repo.On("GetBucketPolicy", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "no policy")).Maybe()
+		repo.On("GetBucketReplication", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "no replication")).Maybe()
+		repo.On("GetBucketNotifications", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "none")).Maybe()
+		repo.On("GetBucket", mock.Anything, bucket.Name).Return(bucket, nil).Maybe()
+		return services.NewStorageService(repo, store, audit, nil, nil, nil, &platform.Config{}), repo, store
+	}
+	lockedBucket := func() *domain.Bucket {
+		return &domain.Bucket{Name: "audit", UserID: owner, VersioningEnabled: true, ObjectLockEnabled: true}
+	}
+	version := func(mode domain.RetentionMode, until *time.Time, hold bool) *domain.Object {
+		return &domain.Object{Bucket: "audit", Key: "export.csv", VersionID: "v1", RetentionMode: mode, RetainUntil: until, LegalHold: hold}
+	}
+
+	t.Run("enabling requires versioning and the owner", func(t *testing.T) {
+		svc, repo, _ := newSvc(&domain.Bucket{Name: "audit", UserID: owner})
+		cfg := domain.ObjectLockConfig{Enabled: true, DefaultMode: domain.RetentionCompliance, DefaultDays: 2557}
+
+		_, err := svc.SetBucketObjectLock(ctx, "audit", cfg)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+		_, err = svc.SetBucketObjectLock(appcontext.WithUserID(context.Background(), uuid.New()), "audit", cfg)
+		assert.True(t, errors.Is(err, errors.Forbidden))
+		repo.AssertNotCalled(t, "SetBucketObjectLock", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("object lock cannot be disabled", func(t *testing.T) {
+		svc, repo, _ := newSvc(lockedBucket())
+		repo.On("SetBucketObjectLock", mock.Anything, "audit", mock.Anything).Return(nil).Once()
+
+		_, err := svc.SetBucketObjectLock(ctx, "audit", domain.ObjectLockConfig{})
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+		assert.True(t, errors.Is(svc.SetBucketVersioning(ctx, "audit", false), errors.InvalidInput))
+
+		updated, err := svc.SetBucketObjectLock(ctx, "audit", domain.ObjectLockConfig{Enabled: true, DefaultMode: domain.RetentionGovernance, DefaultDays: 30})
+		assert.NoError(t, err)
+		assert.Equal(t, 30, updated.DefaultRetentionDays)
+	})
+
+	t.Run("uploads receive the default retention", func(t *testing.T) {
+		bucket := lockedBucket()
+		bucket.DefaultRetentionMode = domain.RetentionCompliance
+		bucket.DefaultRetentionDays = 7
+		svc, repo, store := newSvc(bucket)
+		store.On("Write", mock.Anything, "audit", mock.Anything, mock.Anything).Return(int64(3), nil).Once()
+		repo.On("SaveMeta", mock.Anything, mock.MatchedBy(func(obj *domain.Object) bool {
+			return obj.RetentionMode == domain.RetentionCompliance && obj.RetainUntil != nil &&
+				obj.RetainUntil.Equal(obj.CreatedAt.AddDate(0, 0, 7))
+		})).Return(nil).Once()
+
+		_, err := svc.Upload(ctx, "audit", "export.csv", strings.NewReader("a,b"))
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("DeleteVersion honours retention and legal holds", func(t *testing.T) {
+		bypass := appcontext.WithGovernanceBypass(ctx)
+		tests := []struct {
+			name    string
+			ctx     context.Context
+			obj     *domain.Object
+			blocked bool
+		}{
+			{"compliance", bypass, version(domain.RetentionCompliance, &future, false), true},
+			{"governance", ctx, version(domain.RetentionGovernance, &future, false), true},
+			{"governance bypassed by owner", bypass, version(domain.RetentionGovernance, &future, false), false},
+			{"legal hold", bypass, version("", nil, true), true},
+			{"expired retention", ctx, version(domain.RetentionCompliance, &past, false), false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				svc, repo, store := newSvc(lockedBucket())
+				repo.On("GetMetaByVersion", mock.Anything, "audit", "export.csv", "v1").Return(tt.obj, nil).Once()
+				if !tt.blocked {
+					store.On("Delete", mock.Anything, "audit", mock.Anything).Return(nil).Once()
+					repo.On("DeleteVersion", mock.Anything, "audit", "export.csv", "v1").Return(nil).Once()
+				}
+
+				err := svc.DeleteVersion(tt.ctx, "audit", "export.csv", "v1")
+				if tt.blocked {
+					assert.True(t, errors.Is(err, errors.ObjectLocked), "got %v", err)
+					repo.AssertNotCalled(t, "DeleteVersion", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+					return
+				}
+				assert.NoError(t, err)
+				repo.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("DeleteObject refuses keys with locked versions", func(t *testing.T) {
+		svc, repo, _ := newSvc(lockedBucket())
+		repo.On("ListVersions", mock.Anything, "audit", "export.csv").
+			Return([]*domain.Object{version("", nil, false), version(domain.RetentionCompliance, &future, false)}, nil).Once()
+
+		err := svc.DeleteObject(ctx, "audit", "export.csv")
+		assert.True(t, errors.Is(err, errors.ObjectLocked))
+		repo.AssertNotCalled(t, "SoftDelete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("retention can be extended but not shortened", func(t *testing.T) {
+		svc, repo, _ := newSvc(lockedBucket())
+		repo.On("GetMeta", mock.Anything, "audit", "export.csv").Return(version(domain.RetentionCompliance, &future, false), nil)
+		longer := &domain.ObjectRetention{Mode: domain.RetentionCompliance, RetainUntil: future.Add(time.Hour)}
+		repo.On("SetObjectRetention", mock.Anything, "audit", "export.csv", "v1", longer).Return(nil).Once()
+
+		err := svc.PutObjectRetention(ctx, "audit", "export.csv", "", &domain.ObjectRetention{Mode: domain.RetentionCompliance, RetainUntil: future.Add(-time.Hour)})
+		assert.True(t, errors.Is(err, errors.ObjectLocked))
+		err = svc.PutObjectRetention(appcontext.WithGovernanceBypass(ctx), "audit", "export.csv", "", nil)
+		assert.True(t, errors.Is(err, errors.ObjectLocked))
+		assert.NoError(t, svc.PutObjectRetention(ctx, "audit", "export.csv", "", longer))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("retention requires object lock", func(t *testing.T) {
+		svc, _, _ := newSvc(&domain.Bucket{Name: "audit", UserID: owner, VersioningEnabled: true})
+		err := svc.PutObjectLegalHold(ctx, "audit", "export.csv", "", true)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+	})
+
+	t.Run("CleanupDeleted never purges locked versions", func(t *testing.T) {
+		svc, repo, store := newSvc(lockedBucket())
+		held := version("", nil, true)
+		expired := &domain.Object{Bucket: "audit", Key: "old.csv", VersionID: "v9"}
+		repo.On("ListDeleted", mock.Anything, 10).Return([]*domain.Object{held, expired}, nil).Once()
+		store.On("Delete", mock.Anything, "audit", mock.Anything).Return(nil).Once()
+		repo.On("HardDelete", mock.Anything, "audit", "old.csv", "v9").Return(nil).Once()
+
+		n, err := svc.CleanupDeleted(ctx, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		repo.AssertNotCalled(t, "HardDelete", mock.Anything, "audit", "export.csv", "v1")
+	})
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+)
+
+// GetBucketReplication returns the replication configuration of a bucket without destination credentials.
+func (s *StorageService) GetBucketReplication(ctx context.Context, name string) (*domain.BucketReplicationConfig, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireBucketOwner(ctx, bucket); err != nil {
+		return nil, err
+	}
+	cfg, err := s.repo.GetBucketReplication(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Redacted(), nil
+}
+
+// PutBucketReplication validates and replaces the replication rules of a bucket.
+// Rules without an ID are assigned one; rules without an API key keep the key of
+// the existing rule with the same ID, so a configuration read back can be re-applied.
+// API keys are stored encrypted with the bucket owner's key.
+func (s *StorageService) PutBucketReplication(ctx context.Context, name string, rules []domain.ReplicationRule) (*domain.BucketReplicationConfig, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireBucketOwner(ctx, bucket); err != nil {
+		return nil, err
+	}
+	if s.secretSvc == nil {
+		return nil, errors.New(errors.InvalidInput, "replication is not available: secret encryption is not configured")
+	}
+
+	cfg := &domain.BucketReplicationConfig{Bucket: name, Rules: append([]domain.ReplicationRule(nil), rules...), UpdatedAt: time.Now()}
+	supplied := make([]bool, len(cfg.Rules))
+	for i := range cfg.Rules {
+		supplied[i] = cfg.Rules[i].Destination.APIKey != ""
+	}
+	if existing, err := s.repo.GetBucketReplication(ctx, name); err == nil {
+		for i := range cfg.Rules {
+			if cfg.Rules[i].ID == "" || cfg.Rules[i].Destination.APIKey != "" {
+				continue
+			}
+			if prev := existing.Rule(cfg.Rules[i].ID); prev != nil {
+				cfg.Rules[i].Destination.APIKey = prev.Destination.APIKey
+			}
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.New(errors.InvalidInput, err.Error())
+	}
+	for i := range cfg.Rules {
+		if cfg.Rules[i].ID == "" {
+			cfg.Rules[i].ID = uuid.NewString()
+		}
+		if supplied[i] {
+			sealed, err := s.secretSvc.Encrypt(ctx, bucket.UserID, cfg.Rules[i].Destination.APIKey)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Rules[i].Destination.APIKey = sealed
+		}
+	}
+	if err := s.repo.PutBucketReplication(ctx, cfg); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "storage.bucket_replication_put", "bucket", bucket.ID.String(), map[string]interface{}{
+		"name":  name,
+		"rules": len(cfg.Rules),
+	})
+
+	return cfg.Redacted(), nil
+}
+
+// DeleteBucketReplication removes the replication configuration of a bucket.
+// Changes still waiting to be replicated are dropped and marked FAILED.
+func (s *StorageService) DeleteBucketReplication(ctx context.Context, name string) error {
+	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := requireBucketOwner(ctx, bucket); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteBucketReplication(ctx, name); err != nil {
+		return err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "storage.bucket_replication_delete", "bucket", bucket.ID.String(), map[string]interface{}{
+		"name": name,
+	})
+
+	return nil
+}
+
+// GetObjectReplication returns the replication state of every version of an object.
+func (s *StorageService) GetObjectReplication(ctx context.Context, bucket, key string) ([]*domain.ObjectReplication, error) {
+	if _, err := s.authorizedBucket(ctx, bucket, domain.StorageActionGetObject, key); err != nil {
+		return nil, err
+	}
+	return s.repo.ListObjectReplication(ctx, bucket, key)
+}
+
+// GetReplicationBacklog returns the pending and failed replications of a bucket.
+func (s *StorageService) GetReplicationBacklog(ctx context.Context, name string) (*domain.ReplicationBacklog, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireBucketOwner(ctx, bucket); err != nil {
+		return nil, err
+	}
+
+	backlogs, err := s.repo.ListReplicationBacklog(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	backlog := &domain.ReplicationBacklog{Bucket: name}
+	if len(backlogs) > 0 {
+		backlog = backlogs[0]
+	}
+	backlog.LagSeconds = backlog.Lag(time.Now()).Seconds()
+	return backlog, nil
+}
+
+// enqueueReplication queues the change for every replication rule of the bucket that selects the key.
+// Like event notifications this is best effort: the object operation has already succeeded.
+// Writes made by replication itself are not replicated again. Replication writes with the
+// destination bucket owner's credentials, so the replica mark is only honoured for the owner,
+// who could turn replication off anyway; other writers cannot keep changes off the destination.
+func (s *StorageService) enqueueReplication(ctx context.Context, bucket *domain.Bucket, op domain.ReplicationOperation, key, versionID string) {
+	if appcontext.IsReplicaWrite(ctx) && appcontext.UserIDFromContext(ctx) == bucket.UserID {
+		return
+	}
+	cfg, err := s.repo.GetBucketReplication(ctx, bucket.Name)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	var reps []*domain.ObjectReplication
+	for _, rule := range cfg.Rules {
+		if !rule.Matches(key) || (op == domain.ReplicationOperationDelete && !rule.ReplicateDeletes) {
+			continue
+		}
+		reps = append(reps, &domain.ObjectReplication{
+			Bucket:        bucket.Name,
+			Key:           key,
+			VersionID:     versionID,
+			RuleID:        rule.ID,
+			Operation:     op,
+			Status:        domain.ReplicationPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+	if len(reps) > 0 {
+		_ = s.repo.EnqueueReplication(ctx, reps)
+	}
+}
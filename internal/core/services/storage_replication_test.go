@@ -0,0 +1,165 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/poyrazk/thecloud/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStorageService_Replication(t *testing.T) {
+	owner := uuid.New()
+	ctx := appcontext.WithUserID(context.Background(), owner)
+	dest := domain.ReplicationDestination{Endpoint: "https://dr.example.com", Bucket: "backup", APIKey: "secret"}
+	config := &domain.BucketReplicationConfig{Bucket: "photos", Rules: []domain.ReplicationRule{
+		{ID: "all", Destination: dest},
+		{ID: "raw", Prefix: "raw/", Destination: dest, ReplicateDeletes: true},
+	}}
+
+	newSvc := func(cfg *domain.BucketReplicationConfig) (*services.StorageService, *MockStorageRepo, *MockFileStore) {
+		repo := new(MockStorageRepo)
+		store := new(MockFileStore)
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		repo.On("GetBucketPolicy", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "no policy")).Maybe()
+		repo.On("GetBucketNotifications", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "none")).Maybe()
+		if cfg != nil {
+			repo.On("GetBucketReplication", mock.Anything, "photos").Return(cfg, nil).Maybe()
+		} else {
+			repo.On("GetBucketReplication", mock.Anything, "photos").Return(nil, errors.New(errors.NotFound, "no replication")).Maybe()
+		}
+		repo.On("GetBucket", mock.Anything, "photos").Return(&domain.Bucket{ID: uuid.New(), Name: "photos", UserID: owner}, nil).Maybe()
+		return services.NewStorageService(repo, store, audit, nil, nil, reversibleSecrets{}, &platform.Config{}), repo, store
+	}
+
+	t.Run("put assigns ids, encrypts new api keys, keeps stored ones and redacts", func(t *testing.T) {
+		svc, repo, _ := newSvc(config)
+		repo.On("PutBucketReplication", mock.Anything, mock.MatchedBy(func(cfg *domain.BucketReplicationConfig) bool {
+			return cfg.Rules[0].Destination.APIKey == "secret" && cfg.Rules[1].ID != "" && cfg.Rules[1].Destination.APIKey == "enc:other"
+		})).Return(nil).Once()
+
+		rules := []domain.ReplicationRule{
+			{ID: "all", Destination: domain.ReplicationDestination{Endpoint: dest.Endpoint, Bucket: dest.Bucket}},
+			{Prefix: "raw/", Destination: domain.ReplicationDestination{Endpoint: dest.Endpoint, Bucket: "raw", APIKey: "other"}},
+		}
+		cfg, err := svc.PutBucketReplication(ctx, "photos", rules)
+		assert.NoError(t, err)
+		for _, r := range cfg.Rules {
+			assert.Empty(t, r.Destination.APIKey)
+		}
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("put rejects invalid rules and other users", func(t *testing.T) {
+		svc, repo, _ := newSvc(nil)
+		rules := []domain.ReplicationRule{{Destination: domain.ReplicationDestination{Endpoint: dest.Endpoint, Bucket: dest.Bucket}}}
+
+		_, err := svc.PutBucketReplication(ctx, "photos", rules)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+		_, err = svc.PutBucketReplication(appcontext.WithUserID(context.Background(), uuid.New()), "photos", config.Rules)
+		assert.True(t, errors.Is(err, errors.Forbidden))
+		repo.AssertNotCalled(t, "PutBucketReplication", mock.Anything, mock.Anything)
+	})
+
+	t.Run("put requires secret encryption", func(t *testing.T) {
+		_, repo, store := newSvc(nil)
+		svc := services.NewStorageService(repo, store, new(MockAuditService), nil, nil, nil, &platform.Config{})
+
+		_, err := svc.PutBucketReplication(ctx, "photos", config.Rules)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+		repo.AssertNotCalled(t, "PutBucketReplication", mock.Anything, mock.Anything)
+	})
+
+	t.Run("get redacts api keys", func(t *testing.T) {
+		svc, _, _ := newSvc(config)
+		cfg, err := svc.GetBucketReplication(ctx, "photos")
+		assert.NoError(t, err)
+		assert.Empty(t, cfg.Rules[0].Destination.APIKey)
+		assert.Equal(t, "secret", config.Rules[0].Destination.APIKey)
+	})
+
+	t.Run("upload enqueues matching rules", func(t *testing.T) {
+		svc, repo, store := newSvc(config)
+		store.On("Write", mock.Anything, "photos", mock.Anything, mock.Anything).Return(int64(3), nil)
+		repo.On("SaveMeta", mock.Anything, mock.Anything).Return(nil)
+		repo.On("EnqueueReplication", mock.Anything, mock.MatchedBy(func(reps []*domain.ObjectReplication) bool {
+			return len(reps) == 2 && reps[0].Operation == domain.ReplicationOperationPut &&
+				reps[0].VersionID != "" && reps[0].Status == domain.ReplicationPending
+		})).Return(nil).Once()
+		repo.On("EnqueueReplication", mock.Anything, mock.MatchedBy(func(reps []*domain.ObjectReplication) bool {
+			return len(reps) == 1 && reps[0].RuleID == "all"
+		})).Return(nil).Once()
+
+		_, err := svc.Upload(ctx, "photos", "raw/a.jpg", strings.NewReader("abc"))
+		assert.NoError(t, err)
+		_, err = svc.Upload(ctx, "photos", "b.jpg", strings.NewReader("abc"))
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("replica writes are not replicated again", func(t *testing.T) {
+		svc, repo, store := newSvc(config)
+		store.On("Write", mock.Anything, "photos", mock.Anything, mock.Anything).Return(int64(3), nil)
+		repo.On("SaveMeta", mock.Anything, mock.Anything).Return(nil)
+
+		_, err := svc.Upload(appcontext.WithReplicaWrite(ctx), "photos", "raw/a.jpg", strings.NewReader("abc"))
+		assert.NoError(t, err)
+		repo.AssertNotCalled(t, "EnqueueReplication", mock.Anything, mock.Anything)
+	})
+
+	t.Run("replica mark from other writers is ignored", func(t *testing.T) {
+		writer := uuid.New()
+		repo := new(MockStorageRepo)
+		store := new(MockFileStore)
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		repo.On("GetBucket", mock.Anything, "photos").Return(&domain.Bucket{ID: uuid.New(), Name: "photos", UserID: owner}, nil)
+		repo.On("GetBucketPolicy", mock.Anything, "photos").Return(&domain.BucketPolicy{Bucket: "photos", Statements: []domain.BucketPolicyStatement{{
+			Statement: domain.Statement{Effect: domain.EffectAllow, Action: []string{domain.StorageActionPutObject}, Resource: []string{"*"}},
+			Principal: []string{"user:" + writer.String()},
+		}}}, nil)
+		repo.On("GetBucketNotifications", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "none")).Maybe()
+		repo.On("GetBucketReplication", mock.Anything, "photos").Return(config, nil)
+		store.On("Write", mock.Anything, "photos", mock.Anything, mock.Anything).Return(int64(3), nil)
+		repo.On("SaveMeta", mock.Anything, mock.Anything).Return(nil)
+		repo.On("EnqueueReplication", mock.Anything, mock.MatchedBy(func(reps []*domain.ObjectReplication) bool {
+			return len(reps) == 2
+		})).Return(nil).Once()
+		svc := services.NewStorageService(repo, store, audit, nil, nil, reversibleSecrets{}, &platform.Config{})
+
+		writerCtx := appcontext.WithReplicaWrite(appcontext.WithUserID(context.Background(), writer))
+		_, err := svc.Upload(writerCtx, "photos", "raw/a.jpg", strings.NewReader("abc"))
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("deletes follow replicate_deletes", func(t *testing.T) {
+		svc, repo, _ := newSvc(config)
+		repo.On("SoftDelete", mock.Anything, "photos", mock.Anything).Return(nil)
+		repo.On("EnqueueReplication", mock.Anything, mock.MatchedBy(func(reps []*domain.ObjectReplication) bool {
+			return len(reps) == 1 && reps[0].RuleID == "raw" && reps[0].Operation == domain.ReplicationOperationDelete
+		})).Return(nil).Once()
+
+		assert.NoError(t, svc.DeleteObject(ctx, "photos", "raw/a.jpg"))
+		assert.NoError(t, svc.DeleteObject(ctx, "photos", "b.jpg"))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("backlog reports lag", func(t *testing.T) {
+		svc, repo, _ := newSvc(config)
+		repo.On("ListReplicationBacklog", mock.Anything, "photos").Return([]*domain.ReplicationBacklog{}, nil).Once()
+
+		backlog, err := svc.GetReplicationBacklog(ctx, "photos")
+		assert.NoError(t, err)
+		assert.Equal(t, "photos", backlog.Bucket)
+		assert.Zero(t, backlog.LagSeconds)
+	})
+}
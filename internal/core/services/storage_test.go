@@ -94,6 +94,27 @@ func (s *InMemFileStore) Assemble(ctx context.Context, bucket, key string, parts
 	return int64(buf.Len()), nil
 }
 
+// WriteErasure keeps the whole object in memory; shard placement is covered by coordinator tests.
+func (s *InMemFileStore) WriteErasure(ctx context.Context, bucket, key string, r io.Reader, layout domain.ErasureLayout) (int64, int64, error) {
+	n, err := s.Write(ctx, bucket, key, r)
+	if err != nil {
+		return 0, 0, err
+	}
+	return n, layout.StoredBytes(n), nil
+}
+
+func (s *InMemFileStore) ReadErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout, size int64) (io.ReadCloser, error) {
+	return s.Read(ctx, bucket, key)
+}
+
+func (s *InMemFileStore) DeleteErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout) error {
+	return s.Delete(ctx, bucket, key)
+}
+
+func (s *InMemFileStore) RepairErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout, size int64) (int, error) {
+	return 0, nil
+}
+
 // FailingEncryptionService wraps a real one but can fail.
 type FailingEncryptionService struct {
 	ports.EncryptionService
@@ -135,7 +156,7 @@ func setupStorageServiceIntegrationTest(t *testing.T) (ports.StorageService, por
 	require.NotNil(t, realEncSvc)
 	encSvc := &FailingEncryptionService{EncryptionService: realEncSvc}
 
-	svc := services.NewStorageService(repo, store, auditSvc, encSvc, cfg)
+	svc := services.NewStorageService(repo, store, auditSvc, encSvc, nil, nil, cfg)
 
 	return svc, repo, store, encSvc, db, ctx
 }
@@ -196,9 +217,9 @@ func TestStorageService_Integration(t *testing.T) {
 		assert.Equal(t, key, obj.Key)
 
 		// Meta
-		list, err := svc.ListObjects(ctx, bucketName)
+		list, err := svc.ListObjects(ctx, bucketName, domain.ObjectListOptions{})
 		assert.NoError(t, err)
-		assert.Len(t, list, 1)
+		assert.Len(t, list.Objects, 1)
 
 		// Download
 		r, meta, err := svc.Download(ctx, bucketName, key)
@@ -429,7 +450,7 @@ func TestStorageService_Integration(t *testing.T) {
 
 		// GeneratePresignedURL secret missing
 		badCfg := &platform.Config{SecretsEncryptionKey: "", Port: "8080"}
-		badSvc := services.NewStorageService(postgres.NewStorageRepository(db), store, services.NewAuditService(postgres.NewAuditRepository(db)), encSvc, badCfg)
+		badSvc := services.NewStorageService(postgres.NewStorageRepository(db), store, services.NewAuditService(postgres.NewAuditRepository(db)), encSvc, nil, nil, badCfg)
 		_, err = badSvc.GeneratePresignedURL(ctx, "obj-bucket", "f.txt", "GET", 0)
 		assert.Error(t, err)
 	})
@@ -2,13 +2,16 @@ package services_test
 
 import (
 	"context"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	appcontext "github.com/poyrazk/thecloud/internal/core/context"
 	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
 	"github.com/poyrazk/thecloud/internal/platform"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -19,11 +22,13 @@ func TestStorageService_Unit(t *testing.T) {
 	mockStore := new(MockFileStore)
 	mockAuditSvc := new(MockAuditService)
 	cfg := &platform.Config{SecretsEncryptionKey: "test-secret-key-32-chars-long-!!!"}
-	svc := services.NewStorageService(mockRepo, mockStore, mockAuditSvc, nil, cfg)
+	svc := services.NewStorageService(mockRepo, mockStore, mockAuditSvc, nil, nil, nil, cfg)
 
 	ctx := context.Background()
 	userID := uuid.New()
 	ctx = appcontext.WithUserID(ctx, userID)
+	mockRepo.On("GetBucketPolicy", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "no policy")).Maybe()
+	mockRepo.On("GetBucketReplication", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "no replication")).Maybe()
 
 	t.Run("CreateBucket", func(t *testing.T) {
 		mockRepo.On("CreateBucket", mock.Anything, mock.Anything).Return(nil).Once()
@@ -37,7 +42,7 @@ func TestStorageService_Unit(t *testing.T) {
 	})
 
 	t.Run("Upload", func(t *testing.T) {
-		bucket := &domain.Bucket{Name: "my-bucket", VersioningEnabled: false}
+		bucket := &domain.Bucket{Name: "my-bucket", UserID: userID, VersioningEnabled: false}
 		mockRepo.On("GetBucket", mock.Anything, "my-bucket").Return(bucket, nil).Once()
 		mockStore.On("Write", mock.Anything, "my-bucket", "test.txt", mock.Anything).Return(int64(12), nil).Once()
 		mockRepo.On("SaveMeta", mock.Anything, mock.Anything).Return(nil).Once()
@@ -48,4 +53,606 @@ func TestStorageService_Unit(t *testing.T) {
 		assert.NotNil(t, obj)
 		assert.Equal(t, int64(12), obj.SizeBytes)
 	})
+
+	t.Run("ListObjects", func(t *testing.T) {
+		opts := domain.ObjectListOptions{Prefix: "logs/", Delimiter: "/", MaxKeys: 10}
+		page := &domain.ObjectListResult{Bucket: "my-bucket", CommonPrefixes: []string{"logs/2024/"}, KeyCount: 1}
+		mockRepo.On("GetBucket", mock.Anything, "my-bucket").Return(&domain.Bucket{Name: "my-bucket", UserID: userID}, nil).Once()
+		mockRepo.On("List", mock.Anything, "my-bucket", opts).Return(page, nil).Once()
+
+		res, err := svc.ListObjects(ctx, "my-bucket", opts)
+		assert.NoError(t, err)
+		assert.Equal(t, page, res)
+	})
+
+	t.Run("ListObjects rejects invalid options", func(t *testing.T) {
+		_, err := svc.ListObjects(ctx, "my-bucket", domain.ObjectListOptions{MaxKeys: -1})
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+
+		_, err = svc.ListObjects(ctx, "my-bucket", domain.ObjectListOptions{ContinuationToken: "%%%"})
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+	})
+
+	t.Run("ExpireObject adds a delete marker on versioned buckets", func(t *testing.T) {
+		bucket := &domain.Bucket{Name: "history", UserID: userID, VersioningEnabled: true}
+		mockRepo.On("GetBucket", mock.Anything, "history").Return(bucket, nil).Once()
+		mockRepo.On("GetBucketNotifications", mock.Anything, "history").Return(nil, errors.New(errors.NotFound, "none")).Maybe()
+		mockRepo.On("GetMeta", mock.Anything, "history", "a.txt").Return(&domain.Object{Key: "a.txt", VersionID: "v1", IsLatest: true}, nil).Once()
+		mockRepo.On("SaveMeta", mock.Anything, mock.MatchedBy(func(obj *domain.Object) bool {
+			return obj.IsDeleteMarker && obj.IsLatest && obj.Key == "a.txt" && obj.VersionID != "v1"
+		})).Return(nil).Once()
+		mockAuditSvc.On("Log", mock.Anything, userID, "storage.object_delete_marker", "storage", "history/a.txt", mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, svc.ExpireObject(ctx, "history", "a.txt"))
+		mockRepo.AssertNotCalled(t, "SoftDelete", mock.Anything, "history", "a.txt")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ExpireObject deletes the key on unversioned buckets", func(t *testing.T) {
+		bucket := &domain.Bucket{Name: "my-bucket", UserID: userID}
+		mockRepo.On("GetBucket", mock.Anything, "my-bucket").Return(bucket, nil).Once()
+		mockRepo.On("GetBucketNotifications", mock.Anything, "my-bucket").Return(nil, errors.New(errors.NotFound, "none")).Maybe()
+		mockRepo.On("SoftDelete", mock.Anything, "my-bucket", "test.txt").Return(nil).Once()
+		mockAuditSvc.On("Log", mock.Anything, userID, "storage.object_delete", "storage", "my-bucket/test.txt", mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, svc.ExpireObject(ctx, "my-bucket", "test.txt"))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestStorageService_ErasureCoding(t *testing.T) {
+	owner := uuid.New()
+	ctx := appcontext.WithUserID(context.Background(), owner)
+	defaultLayout := domain.ErasureLayout{DataShards: domain.DefaultDataShards, ParityShards: domain.DefaultParityShards}
+	sixNodes := &domain.StorageCluster{Nodes: make([]domain.StorageNode, 6)}
+
+	newSvc := func() (*services.StorageService, *MockStorageRepo, *MockFileStore, *MockAuditService) {
+		repo := new(MockStorageRepo)
+		store := new(MockFileStore)
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		repo.On("GetBucketPolicy", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "no policy")).Maybe()
+		repo.On("GetBucketReplication", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "no replication")).Maybe()
+		return services.NewStorageService(repo, store, audit, nil, nil, nil, &platform.Config{}), repo, store, audit
+	}
+
+	t.Run("SetBucketStorageClass defaults layout", func(t *testing.T) {
+		svc, repo, store, _ := newSvc()
+		repo.On("GetBucket", mock.Anything, "archive").Return(&domain.Bucket{Name: "archive", UserID: owner}, nil).Once()
+		store.On("GetClusterStatus", mock.Anything).Return(sixNodes, nil).Once()
+		repo.On("SetBucketStorageClass", mock.Anything, "archive", domain.StorageClassErasureCoded, defaultLayout).Return(nil).Once()
+
+		bucket, err := svc.SetBucketStorageClass(ctx, "archive", domain.StorageClassErasureCoded, domain.ErasureLayout{})
+		assert.NoError(t, err)
+		assert.Equal(t, domain.StorageClassErasureCoded, bucket.StorageClass)
+		assert.Equal(t, 4, bucket.DataShards)
+		assert.Equal(t, 2, bucket.ParityShards)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("SetBucketStorageClass rejects undersized cluster", func(t *testing.T) {
+		svc, repo, store, _ := newSvc()
+		repo.On("GetBucket", mock.Anything, "archive").Return(&domain.Bucket{Name: "archive", UserID: owner}, nil).Once()
+		store.On("GetClusterStatus", mock.Anything).Return(&domain.StorageCluster{Nodes: make([]domain.StorageNode, 1)}, nil).Once()
+
+		_, err := svc.SetBucketStorageClass(ctx, "archive", domain.StorageClassErasureCoded, defaultLayout)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+		repo.AssertNotCalled(t, "SetBucketStorageClass", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SetBucketStorageClass rejects invalid input", func(t *testing.T) {
+		svc, repo, _, _ := newSvc()
+		repo.On("GetBucket", mock.Anything, "archive").Return(&domain.Bucket{Name: "archive", UserID: owner}, nil)
+
+		_, err := svc.SetBucketStorageClass(ctx, "archive", domain.StorageClassErasureCoded, domain.ErasureLayout{DataShards: 4})
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+
+		_, err = svc.SetBucketStorageClass(ctx, "archive", "GLACIER", domain.ErasureLayout{})
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+	})
+
+	t.Run("SetBucketStorageClass requires the bucket owner", func(t *testing.T) {
+		svc, repo, store, _ := newSvc()
+		repo.On("GetBucket", mock.Anything, "archive").Return(&domain.Bucket{Name: "archive", UserID: uuid.New()}, nil).Once()
+
+		_, err := svc.SetBucketStorageClass(ctx, "archive", domain.StorageClassErasureCoded, defaultLayout)
+		assert.True(t, errors.Is(err, errors.Forbidden))
+		store.AssertNotCalled(t, "GetClusterStatus", mock.Anything)
+		repo.AssertNotCalled(t, "SetBucketStorageClass", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SetBucketStorageClass back to standard clears layout", func(t *testing.T) {
+		svc, repo, _, _ := newSvc()
+		repo.On("GetBucket", mock.Anything, "archive").Return(&domain.Bucket{Name: "archive", UserID: owner}, nil).Once()
+		repo.On("SetBucketStorageClass", mock.Anything, "archive", domain.StorageClassStandard, domain.ErasureLayout{}).Return(nil).Once()
+
+		bucket, err := svc.SetBucketStorageClass(ctx, "archive", domain.StorageClassStandard, defaultLayout)
+		assert.NoError(t, err)
+		assert.Zero(t, bucket.DataShards)
+	})
+
+	t.Run("Upload to erasure-coded bucket", func(t *testing.T) {
+		svc, repo, store, _ := newSvc()
+		bucket := &domain.Bucket{Name: "archive", UserID: owner, StorageClass: domain.StorageClassErasureCoded, DataShards: 4, ParityShards: 2}
+		repo.On("GetBucket", mock.Anything, "archive").Return(bucket, nil).Once()
+		store.On("WriteErasure", mock.Anything, "archive", "big.bin", mock.Anything, defaultLayout).Return(int64(1000), int64(1500), nil).Once()
+		repo.On("SaveMeta", mock.Anything, mock.MatchedBy(func(o *domain.Object) bool {
+			return o.StorageClass == domain.StorageClassErasureCoded && o.StoredBytes == 1500 && o.DataShards == 4 && o.ParityShards == 2
+		})).Return(nil).Once()
+
+		obj, err := svc.Upload(ctx, "archive", "big.bin", strings.NewReader("payload"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1000), obj.SizeBytes)
+		assert.Equal(t, int64(1500), obj.StoredBytes)
+		store.AssertNotCalled(t, "Write", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Download erasure-coded object", func(t *testing.T) {
+		svc, repo, store, _ := newSvc()
+		obj := &domain.Object{Bucket: "archive", Key: "big.bin", SizeBytes: 7, StorageClass: domain.StorageClassErasureCoded, DataShards: 4, ParityShards: 2}
+		repo.On("GetMeta", mock.Anything, "archive", "big.bin").Return(obj, nil).Once()
+		store.On("ReadErasure", mock.Anything, "archive", "big.bin", defaultLayout, int64(7)).Return(io.NopCloser(strings.NewReader("payload")), nil).Once()
+		repo.On("GetBucket", mock.Anything, "archive").Return(&domain.Bucket{Name: "archive", UserID: owner}, nil).Once()
+
+		rc, got, err := svc.Download(ctx, "archive", "big.bin")
+		assert.NoError(t, err)
+		assert.Equal(t, obj, got)
+		data, _ := io.ReadAll(rc)
+		assert.Equal(t, "payload", string(data))
+	})
+
+	t.Run("TransitionObject rewrites a version as shards", func(t *testing.T) {
+		svc, repo, store, _ := newSvc()
+		obj := &domain.Object{Bucket: "logs", Key: "app.log", VersionID: "v1", SizeBytes: 7, StorageClass: domain.StorageClassStandard}
+		repo.On("GetBucket", mock.Anything, "logs").Return(&domain.Bucket{Name: "logs", UserID: owner}, nil).Once()
+		repo.On("GetMetaByVersion", mock.Anything, "logs", "app.log", "v1").Return(obj, nil).Once()
+		store.On("GetClusterStatus", mock.Anything).Return(sixNodes, nil).Once()
+		var storeKey string
+		store.On("Read", mock.Anything, "logs", mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+			storeKey = args.String(2)
+		}).Return(io.NopCloser(strings.NewReader("payload")), nil).Once()
+		store.On("WriteErasure", mock.Anything, "logs", mock.AnythingOfType("string"), mock.Anything, defaultLayout).Return(int64(7), int64(12), nil).Once()
+		repo.On("SaveMeta", mock.Anything, mock.MatchedBy(func(o *domain.Object) bool {
+			return o.VersionID == "v1" && o.StorageClass == domain.StorageClassErasureCoded && o.StoredBytes == 12
+		})).Return(nil).Once()
+		store.On("Delete", mock.Anything, "logs", mock.AnythingOfType("string")).Return(nil).Once()
+
+		err := svc.TransitionObject(ctx, "logs", "app.log", "v1", domain.StorageClassErasureCoded)
+		assert.NoError(t, err)
+		store.AssertCalled(t, "WriteErasure", mock.Anything, "logs", storeKey, mock.Anything, defaultLayout)
+		store.AssertCalled(t, "Delete", mock.Anything, "logs", storeKey)
+		assert.Equal(t, domain.StorageClassStandard, obj.StorageClass, "stored metadata is copied, not mutated")
+
+		err = svc.TransitionObject(ctx, "logs", "app.log", "v1", domain.StorageClassStandard)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+	})
+
+	t.Run("SetObjectTags validates and targets the latest version", func(t *testing.T) {
+		svc, repo, _, _ := newSvc()
+		repo.On("GetBucket", mock.Anything, "logs").Return(&domain.Bucket{Name: "logs", UserID: owner}, nil)
+		repo.On("GetMeta", mock.Anything, "logs", "app.log").Return(&domain.Object{Key: "app.log", VersionID: "v2"}, nil).Once()
+		repo.On("SetObjectTags", mock.Anything, "logs", "app.log", "v2", map[string]string{"env": "dev"}).Return(nil).Once()
+
+		assert.NoError(t, svc.SetObjectTags(ctx, "logs", "app.log", "", map[string]string{"env": "dev"}))
+		err := svc.SetObjectTags(ctx, "logs", "app.log", "", map[string]string{"": "x"})
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("RepairErasureObjects repairs each version and skips failures", func(t *testing.T) {
+		svc, repo, store, _ := newSvc()
+		after := uuid.New()
+		current := &domain.Object{ID: uuid.New(), Bucket: "archive", Key: "a.bin", VersionID: "null", SizeBytes: 7, StorageClass: domain.StorageClassErasureCoded, DataShards: 4, ParityShards: 2}
+		old := &domain.Object{ID: uuid.New(), Bucket: "archive", Key: "b.bin", VersionID: "v1", SizeBytes: 9, StorageClass: domain.StorageClassErasureCoded, DataShards: 4, ParityShards: 2}
+		repo.On("ListErasureObjects", mock.Anything, after, 10).Return([]*domain.Object{current, old}, nil).Once()
+		store.On("RepairErasure", mock.Anything, "archive", "a.bin", defaultLayout, int64(7)).Return(2, nil).Once()
+		store.On("RepairErasure", mock.Anything, "archive", "b.bin?versionId=v1", defaultLayout, int64(9)).Return(0, errors.New(errors.Internal, "too few shards")).Once()
+
+		last, repaired, err := svc.RepairErasureObjects(ctx, after, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, old.ID, last)
+		assert.Equal(t, 2, repaired)
+		store.AssertExpectations(t)
+
+		repo.On("ListErasureObjects", mock.Anything, old.ID, 10).Return([]*domain.Object{}, nil).Once()
+		last, _, err = svc.RepairErasureObjects(ctx, old.ID, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, uuid.Nil, last, "no versions remain")
+	})
+}
+
+func TestStorageService_ConditionalRequests(t *testing.T) {
+	owner := uuid.New()
+	ctx := appcontext.WithUserID(context.Background(), owner)
+	newSvc := func() (*services.StorageService, *MockStorageRepo, *MockFileStore) {
+		repo := new(MockStorageRepo)
+		store := new(MockFileStore)
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		repo.On("GetBucketPolicy", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "no policy")).Maybe()
+		repo.On("GetBucketReplication", mock.Anything, mock.Anything).Return(nil, errors.New(errors.NotFound, "no replication")).Maybe()
+		return services.NewStorageService(repo, store, audit, nil, nil, nil, &platform.Config{}), repo, store
+	}
+	drain := func(args mock.Arguments) { _, _ = io.Copy(io.Discard, args.Get(3).(io.Reader)) }
+
+	t.Run("Upload computes content hashes", func(t *testing.T) {
+		svc, repo, store := newSvc()
+		repo.On("GetBucket", mock.Anything, "b").Return(&domain.Bucket{Name: "b", UserID: owner}, nil).Once()
+		store.On("Write", mock.Anything, "b", "hello.txt", mock.Anything).Run(drain).Return(int64(11), nil).Once()
+		repo.On("SaveMeta", mock.Anything, mock.Anything).Return(nil).Once()
+
+		obj, err := svc.Upload(ctx, "b", "hello.txt", strings.NewReader("hello world"))
+		assert.NoError(t, err)
+		assert.Equal(t, "5eb63bbbe01eeed093cb22bb8f5acdc3", obj.ETag)
+		assert.Equal(t, "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9", obj.ChecksumSHA256)
+	})
+
+	t.Run("PutObject with If-None-Match * rejects existing object", func(t *testing.T) {
+		svc, repo, store := newSvc()
+		repo.On("GetBucket", mock.Anything, "b").Return(&domain.Bucket{Name: "b", UserID: owner}, nil).Once()
+		repo.On("GetMeta", mock.Anything, "b", "k").Return(&domain.Object{Key: "k", ETag: "abc"}, nil).Once()
+
+		_, err := svc.PutObject(ctx, "b", "k", strings.NewReader("x"), domain.Preconditions{IfNoneMatch: "*"})
+		assert.True(t, errors.Is(err, errors.PreconditionFailed))
+		store.AssertNotCalled(t, "Write", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("PutObject with If-None-Match * creates missing object", func(t *testing.T) {
+		svc, repo, store := newSvc()
+		repo.On("GetBucket", mock.Anything, "b").Return(&domain.Bucket{Name: "b", UserID: owner}, nil).Once()
+		repo.On("GetMeta", mock.Anything, "b", "k").Return(nil, errors.New(errors.ObjectNotFound, "not found")).Once()
+		store.On("Write", mock.Anything, "b", "k", mock.Anything).Run(drain).Return(int64(1), nil).Once()
+		repo.On("SaveMetaIf", mock.Anything, mock.Anything, (*domain.Object)(nil)).Return(nil).Once()
+
+		_, err := svc.PutObject(ctx, "b", "k", strings.NewReader("x"), domain.Preconditions{IfNoneMatch: "*"})
+		assert.NoError(t, err)
+		repo.AssertNotCalled(t, "SaveMeta", mock.Anything, mock.Anything)
+	})
+
+	t.Run("PutObject fails when the object changes before the write", func(t *testing.T) {
+		svc, repo, store := newSvc()
+		current := &domain.Object{Key: "k", ETag: "abc"}
+		repo.On("GetBucket", mock.Anything, "b").Return(&domain.Bucket{Name: "b", UserID: owner}, nil).Once()
+		repo.On("GetMeta", mock.Anything, "b", "k").Return(current, nil).Once()
+		store.On("Write", mock.Anything, "b", "k", mock.Anything).Run(drain).Return(int64(1), nil).Once()
+		repo.On("SaveMetaIf", mock.Anything, mock.Anything, current).Return(errors.New(errors.PreconditionFailed, "precondition failed")).Once()
+
+		_, err := svc.PutObject(ctx, "b", "k", strings.NewReader("x"), domain.Preconditions{IfMatch: `"abc"`})
+		assert.True(t, errors.Is(err, errors.PreconditionFailed))
+		store.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("GetObject returns the requested range", func(t *testing.T) {
+		svc, repo, store := newSvc()
+		obj := &domain.Object{Bucket: "b", Key: "k", VersionID: "null", SizeBytes: 11, ETag: "abc"}
+		repo.On("GetMeta", mock.Anything, "b", "k").Return(obj, nil).Once()
+		repo.On("GetBucket", mock.Anything, "b").Return(&domain.Bucket{Name: "b", UserID: owner}, nil).Once()
+		store.On("Read", mock.Anything, "b", "k").Return(io.NopCloser(strings.NewReader("hello world")), nil).Once()
+
+		content, err := svc.GetObject(ctx, "b", "k", domain.GetObjectOptions{Range: "bytes=6-"})
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.ByteRange{Start: 6, End: 10}, content.Range)
+		assert.Equal(t, int64(11), content.Size)
+		data, _ := io.ReadAll(content.Body)
+		assert.Equal(t, "world", string(data))
+	})
+
+	t.Run("GetObject rejects unsatisfiable range", func(t *testing.T) {
+		svc, repo, store := newSvc()
+		obj := &domain.Object{Bucket: "b", Key: "k", SizeBytes: 11}
+		repo.On("GetMeta", mock.Anything, "b", "k").Return(obj, nil).Once()
+		repo.On("GetBucket", mock.Anything, "b").Return(&domain.Bucket{Name: "b", UserID: owner}, nil).Once()
+		store.On("Read", mock.Anything, "b", "k").Return(io.NopCloser(strings.NewReader("hello world")), nil).Once()
+
+		_, err := svc.GetObject(ctx, "b", "k", domain.GetObjectOptions{Range: "bytes=20-"})
+		assert.True(t, errors.Is(err, errors.RangeNotSatisfiable))
+	})
+
+	t.Run("GetObject reports not modified", func(t *testing.T) {
+		svc, repo, store := newSvc()
+		obj := &domain.Object{Bucket: "b", Key: "k", ETag: "abc"}
+		repo.On("GetMeta", mock.Anything, "b", "k").Return(obj, nil).Once()
+		repo.On("GetBucket", mock.Anything, "b").Return(&domain.Bucket{Name: "b", UserID: owner}, nil).Once()
+
+		content, err := svc.GetObject(ctx, "b", "k", domain.GetObjectOptions{Preconditions: domain.Preconditions{IfNoneMatch: `"abc"`}})
+		assert.True(t, errors.Is(err, errors.NotModified))
+		assert.Equal(t, obj, content.Object)
+		store.AssertNotCalled(t, "Read", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DeleteObjectIf rejects stale ETag", func(t *testing.T) {
+		svc, repo, _ := newSvc()
+		repo.On("GetMeta", mock.Anything, "b", "k").Return(&domain.Object{Key: "k", ETag: "new"}, nil).Once()
+		repo.On("GetBucket", mock.Anything, "b").Return(&domain.Bucket{Name: "b", UserID: owner}, nil).Once()
+
+		err := svc.DeleteObjectIf(ctx, "b", "k", "", domain.Preconditions{IfMatch: `"old"`})
+		assert.True(t, errors.Is(err, errors.PreconditionFailed))
+		repo.AssertNotCalled(t, "SoftDelete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DeleteObjectIf deletes only the version it checked", func(t *testing.T) {
+		svc, repo, _ := newSvc()
+		current := &domain.Object{Key: "k", ETag: "abc"}
+		repo.On("GetBucket", mock.Anything, "b").Return(&domain.Bucket{Name: "b", UserID: owner}, nil).Once()
+		repo.On("GetMeta", mock.Anything, "b", "k").Return(current, nil).Once()
+		repo.On("SoftDeleteIf", mock.Anything, "b", "k", current).Return(nil).Once()
+		repo.On("GetBucketNotifications", mock.Anything, "b").Return(nil, errors.New(errors.NotFound, "none")).Maybe()
+
+		assert.NoError(t, svc.DeleteObjectIf(ctx, "b", "k", "", domain.Preconditions{IfMatch: `"abc"`}))
+		repo.AssertNotCalled(t, "SoftDelete", mock.Anything, mock.Anything, mock.Anything)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("DeleteObjectIf keeps a version that changed before the delete", func(t *testing.T) {
+		svc, repo, store := newSvc()
+		version := &domain.Object{Bucket: "b", Key: "k", VersionID: "v1", ETag: "abc"}
+		repo.On("GetBucket", mock.Anything, "b").Return(&domain.Bucket{Name: "b", UserID: owner}, nil).Once()
+		repo.On("GetMetaByVersion", mock.Anything, "b", "k", "v1").Return(version, nil).Once()
+		repo.On("DeleteVersionIf", mock.Anything, "b", "k", "v1", version).Return(errors.New(errors.PreconditionFailed, "precondition failed")).Once()
+
+		err := svc.DeleteObjectIf(ctx, "b", "k", "v1", domain.Preconditions{IfMatch: `"abc"`})
+		assert.True(t, errors.Is(err, errors.PreconditionFailed))
+		store.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("CompleteMultipartUpload sets composite ETag", func(t *testing.T) {
+		svc, repo, store := newSvc()
+		uploadID := uuid.New()
+		upload := &domain.MultipartUpload{ID: uploadID, Bucket: "b", Key: "big"}
+		parts := []*domain.Part{
+			{UploadID: uploadID, PartNumber: 1, ETag: "5d41402abc4b2a76b9719d911017c592"},
+			{UploadID: uploadID, PartNumber: 2, ETag: "7d793037a0760186574b0282f2f435e7"},
+		}
+		repo.On("GetMultipartUpload", mock.Anything, uploadID).Return(upload, nil).Once()
+		repo.On("ListParts", mock.Anything, uploadID).Return(parts, nil).Once()
+		repo.On("GetBucket", mock.Anything, "b").Return(&domain.Bucket{Name: "b", UserID: owner}, nil).Once()
+		store.On("Assemble", mock.Anything, "b", "big", mock.Anything).Return(int64(10), nil).Once()
+		repo.On("SaveMeta", mock.Anything, mock.Anything).Return(nil).Once()
+		repo.On("DeleteMultipartUpload", mock.Anything, uploadID).Return(nil).Once()
+
+		obj, err := svc.CompleteMultipartUpload(ctx, uploadID)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasSuffix(obj.ETag, "-2"))
+		assert.Len(t, obj.ETag, 34)
+	})
+}
+
+func TestStorageService_AccessControl(t *testing.T) {
+	owner := uuid.New()
+	other := uuid.New()
+	tenant := uuid.New()
+	bucket := &domain.Bucket{Name: "shared", UserID: owner}
+	obj := &domain.Object{Bucket: "shared", Key: "docs/a.txt", VersionID: "null", ACL: domain.ACLPrivate}
+
+	newSvc := func(policy *domain.BucketPolicy, b *domain.Bucket) (*services.StorageService, *MockStorageRepo) {
+		repo := new(MockStorageRepo)
+		store := new(MockFileStore)
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		repo.On("GetBucket", mock.Anything, b.Name).Return(b, nil).Maybe()
+		if policy != nil {
+			repo.On("GetBucketPolicy", mock.Anything, b.Name).Return(policy, nil).Maybe()
+		} else {
+			repo.On("GetBucketPolicy", mock.Anything, b.Name).Return(nil, errors.New(errors.NotFound, "no policy")).Maybe()
+		}
+		repo.On("GetBucketReplication", mock.Anything, b.Name).Return(nil, errors.New(errors.NotFound, "no replication")).Maybe()
+		return services.NewStorageService(repo, store, audit, nil, nil, nil, &platform.Config{}), repo
+	}
+	allow := func(action, resource string, principal ...string) *domain.BucketPolicy {
+		return &domain.BucketPolicy{Bucket: "shared", Statements: []domain.BucketPolicyStatement{{
+			Statement: domain.Statement{Effect: domain.EffectAllow, Action: []string{action}, Resource: []string{resource}},
+			Principal: principal,
+		}}}
+	}
+	userCtx := func(id uuid.UUID) context.Context { return appcontext.WithUserID(context.Background(), id) }
+
+	t.Run("non-owner without grant is forbidden", func(t *testing.T) {
+		svc, repo := newSvc(nil, bucket)
+		repo.On("GetMeta", mock.Anything, "shared", "docs/a.txt").Return(obj, nil).Once()
+
+		_, err := svc.HeadObject(userCtx(other), "shared", "docs/a.txt", domain.GetObjectOptions{})
+		assert.True(t, errors.Is(err, errors.Forbidden))
+	})
+
+	t.Run("anonymous without grant is unauthorized", func(t *testing.T) {
+		svc, repo := newSvc(nil, bucket)
+		repo.On("GetMeta", mock.Anything, "shared", "docs/a.txt").Return(obj, nil).Once()
+
+		_, err := svc.HeadObject(context.Background(), "shared", "docs/a.txt", domain.GetObjectOptions{})
+		assert.True(t, errors.Is(err, errors.Unauthorized))
+	})
+
+	t.Run("unauthorized callers do not learn whether a key exists", func(t *testing.T) {
+		svc, repo := newSvc(nil, bucket)
+		repo.On("GetMeta", mock.Anything, "shared", "missing").Return(nil, errors.New(errors.ObjectNotFound, "not found")).Once()
+
+		_, err := svc.HeadObject(userCtx(other), "shared", "missing", domain.GetObjectOptions{})
+		assert.True(t, errors.Is(err, errors.Forbidden))
+	})
+
+	t.Run("denied reads are refused before loading metadata", func(t *testing.T) {
+		policy := &domain.BucketPolicy{Bucket: "shared", Statements: []domain.BucketPolicyStatement{{
+			Statement: domain.Statement{Effect: domain.EffectDeny, Action: []string{domain.StorageActionGetObject}, Resource: []string{"*"}},
+			Principal: []string{"user:" + other.String()},
+		}}}
+		svc, repo := newSvc(policy, bucket)
+
+		_, err := svc.HeadObject(userCtx(other), "shared", "docs/a.txt", domain.GetObjectOptions{})
+		assert.True(t, errors.Is(err, errors.Forbidden))
+		repo.AssertNotCalled(t, "GetMeta", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("user grant on prefix", func(t *testing.T) {
+		svc, repo := newSvc(allow(domain.StorageActionGetObject, "docs/*", "user:"+other.String()), bucket)
+		repo.On("GetMeta", mock.Anything, "shared", "docs/a.txt").Return(obj, nil).Once()
+		repo.On("GetMeta", mock.Anything, "shared", "secret/b.txt").Return(obj, nil).Once()
+
+		_, err := svc.HeadObject(userCtx(other), "shared", "docs/a.txt", domain.GetObjectOptions{})
+		assert.NoError(t, err)
+		_, err = svc.HeadObject(userCtx(other), "shared", "secret/b.txt", domain.GetObjectOptions{})
+		assert.True(t, errors.Is(err, errors.Forbidden))
+	})
+
+	t.Run("resources match keys with a leading slash", func(t *testing.T) {
+		svc, repo := newSvc(allow(domain.StorageActionGetObject, "docs/*", "user:"+other.String()), bucket)
+		repo.On("GetMeta", mock.Anything, "shared", "/docs/a.txt").Return(obj, nil).Once()
+
+		_, err := svc.HeadObject(userCtx(other), "shared", "/docs/a.txt", domain.GetObjectOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("tenant grant for listing", func(t *testing.T) {
+		svc, repo := newSvc(allow(domain.StorageActionListBucket, "*", "tenant:"+tenant.String()), bucket)
+		repo.On("List", mock.Anything, "shared", mock.Anything).Return(&domain.ObjectListResult{Bucket: "shared"}, nil).Once()
+
+		ctx := appcontext.WithTenantID(userCtx(other), tenant)
+		_, err := svc.ListObjects(ctx, "shared", domain.ObjectListOptions{Prefix: "docs/"})
+		assert.NoError(t, err)
+		_, err = svc.ListObjects(userCtx(other), "shared", domain.ObjectListOptions{Prefix: "docs/"})
+		assert.True(t, errors.Is(err, errors.Forbidden))
+	})
+
+	t.Run("explicit deny overrides owner", func(t *testing.T) {
+		policy := &domain.BucketPolicy{Bucket: "shared", Statements: []domain.BucketPolicyStatement{{
+			Statement: domain.Statement{Effect: domain.EffectDeny, Action: []string{domain.StorageActionDeleteObject}, Resource: []string{"*"}},
+			Principal: []string{domain.PrincipalAll},
+		}}}
+		svc, repo := newSvc(policy, bucket)
+
+		err := svc.DeleteObject(userCtx(owner), "shared", "docs/a.txt")
+		assert.True(t, errors.Is(err, errors.Forbidden))
+		repo.AssertNotCalled(t, "SoftDelete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("public bucket allows anonymous reads but not writes", func(t *testing.T) {
+		public := &domain.Bucket{Name: "site", UserID: owner, IsPublic: true}
+		svc, repo := newSvc(nil, public)
+		repo.On("GetMeta", mock.Anything, "site", "index.html").Return(&domain.Object{Bucket: "site", Key: "index.html"}, nil).Once()
+
+		_, err := svc.HeadObject(context.Background(), "site", "index.html", domain.GetObjectOptions{})
+		assert.NoError(t, err)
+		_, err = svc.PutObject(context.Background(), "site", "index.html", strings.NewReader("x"), domain.Preconditions{})
+		assert.True(t, errors.Is(err, errors.Unauthorized))
+	})
+
+	t.Run("public-read ACL allows anonymous read of that object", func(t *testing.T) {
+		svc, repo := newSvc(nil, bucket)
+		shared := &domain.Object{Bucket: "shared", Key: "docs/a.txt", ACL: domain.ACLPublicRead}
+		repo.On("GetMeta", mock.Anything, "shared", "docs/a.txt").Return(shared, nil).Once()
+
+		_, err := svc.HeadObject(context.Background(), "shared", "docs/a.txt", domain.GetObjectOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("presigned access bypasses policy evaluation", func(t *testing.T) {
+		svc, repo := newSvc(nil, bucket)
+		repo.On("GetMeta", mock.Anything, "shared", "docs/a.txt").Return(obj, nil).Once()
+
+		_, err := svc.HeadObject(appcontext.WithPresignedAccess(context.Background()), "shared", "docs/a.txt", domain.GetObjectOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("PutBucketPolicy is owner only and validated", func(t *testing.T) {
+		svc, repo := newSvc(nil, bucket)
+		statements := allow(domain.StorageActionGetObject, "*", domain.PrincipalAnonymous).Statements
+		repo.On("PutBucketPolicy", mock.Anything, mock.MatchedBy(func(p *domain.BucketPolicy) bool {
+			return p.Bucket == "shared" && len(p.Statements) == 1
+		})).Return(nil).Once()
+
+		_, err := svc.PutBucketPolicy(userCtx(other), "shared", statements)
+		assert.True(t, errors.Is(err, errors.Forbidden))
+
+		_, err = svc.PutBucketPolicy(userCtx(owner), "shared", []domain.BucketPolicyStatement{{Principal: []string{"*"}}})
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+
+		policy, err := svc.PutBucketPolicy(userCtx(owner), "shared", statements)
+		assert.NoError(t, err)
+		assert.Equal(t, "shared", policy.Bucket)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("SetObjectACL targets the latest version", func(t *testing.T) {
+		svc, repo := newSvc(nil, bucket)
+		latest := &domain.Object{Bucket: "shared", Key: "docs/a.txt", VersionID: "v2"}
+		repo.On("GetMeta", mock.Anything, "shared", "docs/a.txt").Return(latest, nil).Once()
+		repo.On("SetObjectACL", mock.Anything, "shared", "docs/a.txt", "v2", domain.ACLPublicRead).Return(nil).Once()
+
+		assert.NoError(t, svc.SetObjectACL(userCtx(owner), "shared", "docs/a.txt", "", domain.ACLPublicRead))
+		err := svc.SetObjectACL(userCtx(owner), "shared", "docs/a.txt", "", domain.ObjectACL("world-writable"))
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestStorageService_DeleteBucket(t *testing.T) {
+	owner := uuid.New()
+	other := uuid.New()
+	bucket := &domain.Bucket{Name: "b", UserID: owner}
+
+	newSvc := func(policy *domain.BucketPolicy) (*services.StorageService, *MockStorageRepo) {
+		repo := new(MockStorageRepo)
+		repo.On("GetBucket", mock.Anything, "b").Return(bucket, nil).Maybe()
+		if policy != nil {
+			repo.On("GetBucketPolicy", mock.Anything, "b").Return(policy, nil).Maybe()
+		} else {
+			repo.On("GetBucketPolicy", mock.Anything, "b").Return(nil, errors.New(errors.NotFound, "no policy")).Maybe()
+		}
+		return services.NewStorageService(repo, new(MockFileStore), new(MockAuditService), nil, nil, nil, &platform.Config{}), repo
+	}
+	userCtx := func(id uuid.UUID) context.Context { return appcontext.WithUserID(context.Background(), id) }
+
+	t.Run("owner deletes an empty bucket", func(t *testing.T) {
+		svc, repo := newSvc(nil)
+		repo.On("ListLockedVersions", mock.Anything, "b", 1).Return([]*domain.Object{}, nil).Once()
+		repo.On("BucketHasObjects", mock.Anything, "b").Return(false, nil).Once()
+		repo.On("DeleteBucket", mock.Anything, "b").Return(nil).Once()
+
+		assert.NoError(t, svc.DeleteBucket(userCtx(owner), "b"))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("non-owner without grant is forbidden", func(t *testing.T) {
+		svc, repo := newSvc(nil)
+
+		err := svc.DeleteBucket(userCtx(other), "b")
+		assert.True(t, errors.Is(err, errors.Forbidden))
+		repo.AssertNotCalled(t, "DeleteBucket", mock.Anything, mock.Anything)
+	})
+
+	t.Run("policy grant allows another user", func(t *testing.T) {
+		svc, repo := newSvc(&domain.BucketPolicy{Bucket: "b", Statements: []domain.BucketPolicyStatement{{
+			Statement: domain.Statement{Effect: domain.EffectAllow, Action: []string{domain.StorageActionDeleteBucket}, Resource: []string{"*"}},
+			Principal: []string{"user:" + other.String()},
+		}}})
+		repo.On("ListLockedVersions", mock.Anything, "b", 1).Return([]*domain.Object{}, nil).Once()
+		repo.On("BucketHasObjects", mock.Anything, "b").Return(false, nil).Once()
+		repo.On("DeleteBucket", mock.Anything, "b").Return(nil).Once()
+
+		assert.NoError(t, svc.DeleteBucket(userCtx(other), "b"))
+	})
+
+	t.Run("non-empty bucket is a conflict", func(t *testing.T) {
+		svc, repo := newSvc(nil)
+		repo.On("ListLockedVersions", mock.Anything, "b", 1).Return([]*domain.Object{}, nil).Once()
+		repo.On("BucketHasObjects", mock.Anything, "b").Return(true, nil).Once()
+
+		err := svc.DeleteBucket(userCtx(owner), "b")
+		assert.True(t, errors.Is(err, errors.Conflict))
+		repo.AssertNotCalled(t, "DeleteBucket", mock.Anything, mock.Anything)
+	})
+
+	t.Run("deleted version under retention blocks deletion", func(t *testing.T) {
+		svc, repo := newSvc(nil)
+		deletedAt := time.Now().Add(-time.Hour)
+		retainUntil := time.Now().Add(24 * time.Hour)
+		repo.On("ListLockedVersions", mock.Anything, "b", 1).Return([]*domain.Object{{
+			Bucket: "b", Key: "k", VersionID: "v1", DeletedAt: &deletedAt,
+			RetentionMode: domain.RetentionCompliance, RetainUntil: &retainUntil,
+		}}, nil).Once()
+
+		err := svc.DeleteBucket(userCtx(owner), "b")
+		assert.True(t, errors.Is(err, errors.ObjectLocked))
+		repo.AssertNotCalled(t, "BucketHasObjects", mock.Anything, mock.Anything)
+		repo.AssertNotCalled(t, "DeleteBucket", mock.Anything, mock.Anything)
+	})
 }
@@ -33,7 +33,7 @@ func NewSubnetService(repo ports.SubnetRepository, vpcRepo ports.VpcRepository,
 	}
 }
 
-func (s *SubnetService) CreateSubnet(ctx context.Context, vpcID uuid.UUID, name, cidrBlock, az string) (*domain.Subnet, error) {
+func (s *SubnetService) CreateSubnet(ctx context.Context, vpcID uuid.UUID, name, cidrBlock, ipv6CIDRBlock, az string) (*domain.Subnet, error) {
 	userID := appcontext.UserIDFromContext(ctx)
 
 	// 1. Get VPC and validate CIDR range
@@ -56,6 +56,12 @@ func (s *SubnetService) CreateSubnet(ctx context.Context, vpcID uuid.UUID, name,
 		return nil, errors.New(errors.InvalidInput, "subnet CIDR must be within VPC CIDR range")
 	}
 
+	if ipv6CIDRBlock != "" {
+		if ipv6CIDRBlock, err = s.validateIPv6Block(ctx, vpc, ipv6CIDRBlock); err != nil {
+			return nil, err
+		}
+	}
+
 	// 2. Gateway IP (first usable IP in subnet)
 	gatewayIP := s.calculateGatewayIP(ip, subnetNet)
 
@@ -69,6 +75,7 @@ func (s *SubnetService) CreateSubnet(ctx context.Context, vpcID uuid.UUID, name,
 		VPCID:            vpcID,
 		Name:             name,
 		CIDRBlock:        cidrBlock,
+		IPv6CIDRBlock:    ipv6CIDRBlock,
 		AvailabilityZone: az,
 		GatewayIP:        gatewayIP,
 		ARN:              arn,
@@ -81,14 +88,50 @@ func (s *SubnetService) CreateSubnet(ctx context.Context, vpcID uuid.UUID, name,
 	}
 
 	_ = s.auditSvc.Log(ctx, userID, "subnet.create", "subnet", subnetID.String(), map[string]interface{}{
-		"vpc_id":     vpcID.String(),
-		"name":       name,
-		"cidr_block": cidrBlock,
+		"vpc_id":          vpcID.String(),
+		"name":            name,
+		"cidr_block":      cidrBlock,
+		"ipv6_cidr_block": ipv6CIDRBlock,
 	})
 
 	return subnet, nil
 }
 
+// validateIPv6Block checks that a subnet's IPv6 range is a /64 carved out of the
+// VPC's IPv6 block and not already used by another subnet, and returns it in
+// canonical form.
+func (s *SubnetService) validateIPv6Block(ctx context.Context, vpc *domain.VPC, cidr string) (string, error) {
+	if vpc.IPv6CIDRBlock == "" {
+		return "", errors.New(errors.InvalidInput, "VPC has no IPv6 CIDR block")
+	}
+	_, vpcNet, err := net.ParseCIDR(vpc.IPv6CIDRBlock)
+	if err != nil {
+		return "", errors.Wrap(errors.Internal, "invalid VPC IPv6 CIDR", err)
+	}
+
+	subnetNet, err := domain.ParseIPv6CIDR(cidr, domain.SubnetIPv6PrefixLen)
+	if err != nil {
+		return "", errors.New(errors.InvalidInput, err.Error())
+	}
+	if ones, _ := subnetNet.Mask.Size(); ones != domain.SubnetIPv6PrefixLen {
+		return "", errors.New(errors.InvalidInput, "subnet IPv6 CIDR must be a /64")
+	}
+	if !vpcNet.Contains(subnetNet.IP) {
+		return "", errors.New(errors.InvalidInput, "subnet IPv6 CIDR must be within VPC IPv6 CIDR range")
+	}
+
+	existing, err := s.repo.ListByVPC(ctx, vpc.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, other := range existing {
+		if other.IPv6CIDRBlock == subnetNet.String() {
+			return "", errors.New(errors.Conflict, fmt.Sprintf("IPv6 CIDR %s is already used by subnet %s", other.IPv6CIDRBlock, other.Name))
+		}
+	}
+	return subnetNet.String(), nil
+}
+
 func (s *SubnetService) GetSubnet(ctx context.Context, idOrName string, vpcID uuid.UUID) (*domain.Subnet, error) {
 	id, err := uuid.Parse(idOrName)
 	if err == nil {
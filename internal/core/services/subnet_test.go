@@ -53,7 +53,7 @@ func TestSubnetServiceCreateSubnetSuccess(t *testing.T) {
 	svc, repo, vpcRepo, ctx := setupSubnetServiceTest(t)
 	vpc := createTestVPC(t, ctx, vpcRepo)
 
-	subnet, err := svc.CreateSubnet(ctx, vpc.ID, "test-subnet", testutil.TestSubnetCIDR, "us-east-1a")
+	subnet, err := svc.CreateSubnet(ctx, vpc.ID, "test-subnet", testutil.TestSubnetCIDR, "", "us-east-1a")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, subnet)
@@ -70,7 +70,7 @@ func TestSubnetServiceCreateSubnetInvalidCIDR(t *testing.T) {
 	vpc := createTestVPC(t, ctx, vpcRepo)
 
 	// Outside VPC range (e.g. TestOtherCIDR)
-	subnet, err := svc.CreateSubnet(ctx, vpc.ID, "bad-subnet", testutil.TestOtherCIDR, "us-east-1a")
+	subnet, err := svc.CreateSubnet(ctx, vpc.ID, "bad-subnet", testutil.TestOtherCIDR, "", "us-east-1a")
 
 	assert.Error(t, err)
 	assert.Nil(t, subnet)
@@ -81,7 +81,7 @@ func TestSubnetServiceDeleteSubnetSuccess(t *testing.T) {
 	svc, repo, vpcRepo, ctx := setupSubnetServiceTest(t)
 	vpc := createTestVPC(t, ctx, vpcRepo)
 
-	subnet, err := svc.CreateSubnet(ctx, vpc.ID, "to-delete", testutil.TestSubnetCIDR, "az")
+	subnet, err := svc.CreateSubnet(ctx, vpc.ID, "to-delete", testutil.TestSubnetCIDR, "", "az")
 	require.NoError(t, err)
 	require.NotNil(t, subnet)
 
@@ -97,7 +97,7 @@ func TestSubnetServiceGetSubnet(t *testing.T) {
 	svc, _, vpcRepo, ctx := setupSubnetServiceTest(t)
 	vpc := createTestVPC(t, ctx, vpcRepo)
 
-	subnet, err := svc.CreateSubnet(ctx, vpc.ID, "find-me", testutil.TestSubnetCIDR, "az")
+	subnet, err := svc.CreateSubnet(ctx, vpc.ID, "find-me", testutil.TestSubnetCIDR, "", "az")
 	require.NoError(t, err)
 
 	t.Run("get by id", func(t *testing.T) {
@@ -117,9 +117,9 @@ func TestSubnetServiceListSubnets(t *testing.T) {
 	svc, _, vpcRepo, ctx := setupSubnetServiceTest(t)
 	vpc := createTestVPC(t, ctx, vpcRepo)
 
-	_, err := svc.CreateSubnet(ctx, vpc.ID, "s1", "10.0.1.0/24", "az")
+	_, err := svc.CreateSubnet(ctx, vpc.ID, "s1", "10.0.1.0/24", "", "az")
 	require.NoError(t, err)
-	_, err = svc.CreateSubnet(ctx, vpc.ID, "s2", "10.0.2.0/24", "az")
+	_, err = svc.CreateSubnet(ctx, vpc.ID, "s2", "10.0.2.0/24", "", "az")
 	require.NoError(t, err)
 
 	subnets, err := svc.ListSubnets(ctx, vpc.ID)
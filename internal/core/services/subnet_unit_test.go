@@ -29,13 +29,41 @@ func TestSubnetService_Unit(t *testing.T) {
 		repo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
 		auditSvc.On("Log", mock.Anything, userID, "subnet.create", "subnet", mock.Anything, mock.Anything).Return(nil).Once()
 
-		subnet, err := svc.CreateSubnet(ctx, vpcID, "test-subnet", "10.0.1.0/24", "us-east-1a")
+		subnet, err := svc.CreateSubnet(ctx, vpcID, "test-subnet", "10.0.1.0/24", "", "us-east-1a")
 		assert.NoError(t, err)
 		assert.NotNil(t, subnet)
 		assert.Equal(t, "10.0.1.1", subnet.GatewayIP)
 		repo.AssertExpectations(t)
 	})
 
+	t.Run("CreateDualStackSubnet", func(t *testing.T) {
+		vpcID := uuid.New()
+		vpc := &domain.VPC{ID: vpcID, CIDRBlock: "10.0.0.0/16", IPv6CIDRBlock: "fd00:10::/56"}
+		vpcRepo.On("GetByID", mock.Anything, vpcID).Return(vpc, nil).Once()
+		repo.On("ListByVPC", mock.Anything, vpcID).Return([]*domain.Subnet{{Name: "other", IPv6CIDRBlock: "fd00:10:0:1::/64"}}, nil).Once()
+		repo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+		auditSvc.On("Log", mock.Anything, userID, "subnet.create", "subnet", mock.Anything, mock.Anything).Return(nil).Once()
+
+		subnet, err := svc.CreateSubnet(ctx, vpcID, "dual", "10.0.2.0/24", "fd00:10:0:2::/64", "us-east-1a")
+		assert.NoError(t, err)
+		assert.Equal(t, "fd00:10:0:2::/64", subnet.IPv6CIDRBlock)
+
+		vpcRepo.On("GetByID", mock.Anything, vpcID).Return(vpc, nil).Once()
+		repo.On("ListByVPC", mock.Anything, vpcID).Return([]*domain.Subnet{{Name: "other", IPv6CIDRBlock: "fd00:10:0:1::/64"}}, nil).Once()
+		_, err = svc.CreateSubnet(ctx, vpcID, "dup", "10.0.3.0/24", "fd00:10:0:1::/64", "us-east-1a")
+		assert.Error(t, err)
+
+		for _, cidr := range []string{"fd00:10:0:3::/80", "fd00:99::/64", "10.0.4.0/24"} {
+			vpcRepo.On("GetByID", mock.Anything, vpcID).Return(vpc, nil).Once()
+			_, err = svc.CreateSubnet(ctx, vpcID, "bad", "10.0.4.0/24", cidr, "us-east-1a")
+			assert.Error(t, err, cidr)
+		}
+
+		vpcRepo.On("GetByID", mock.Anything, vpcID).Return(&domain.VPC{ID: vpcID, CIDRBlock: "10.0.0.0/16"}, nil).Once()
+		_, err = svc.CreateSubnet(ctx, vpcID, "v4-only-vpc", "10.0.5.0/24", "fd00:10:0:5::/64", "us-east-1a")
+		assert.Error(t, err)
+	})
+
 	t.Run("DeleteSubnet", func(t *testing.T) {
 		id := uuid.New()
 		repo.On("GetByID", mock.Anything, id).Return(&domain.Subnet{ID: id, UserID: userID}, nil).Once()
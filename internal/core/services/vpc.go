@@ -45,18 +45,26 @@ func NewVpcService(repo ports.VpcRepository, lbRepo ports.LBRepository, network
 
 // CreateVPC provisions a new VPC with an associated OVS bridge for network isolation.
 // It generates a unique VXLAN ID and persists the VPC metadata to the database.
-func (s *VpcService) CreateVPC(ctx context.Context, name, cidrBlock string) (*domain.VPC, error) {
+func (s *VpcService) CreateVPC(ctx context.Context, name, cidrBlock, ipv6CIDRBlock string) (*domain.VPC, error) {
 	ctx, span := otel.Tracer("vpc-service").Start(ctx, "CreateVPC")
 	defer span.End()
 
 	span.SetAttributes(
 		attribute.String("vpc.name", name),
 		attribute.String("vpc.cidr", cidrBlock),
+		attribute.String("vpc.ipv6_cidr", ipv6CIDRBlock),
 	)
 
 	if cidrBlock == "" {
 		cidrBlock = s.defaultCIDR
 	}
+	if ipv6CIDRBlock != "" {
+		ipv6Net, err := domain.ParseIPv6CIDR(ipv6CIDRBlock, domain.SubnetIPv6PrefixLen)
+		if err != nil {
+			return nil, errors.New(errors.InvalidInput, err.Error())
+		}
+		ipv6CIDRBlock = ipv6Net.String()
+	}
 
 	userID := appcontext.UserIDFromContext(ctx)
 	vpcID := uuid.New()
@@ -75,16 +83,17 @@ func (s *VpcService) CreateVPC(ctx context.Context, name, cidrBlock string) (*do
 
 	// 4. Persist to DB
 	vpc := &domain.VPC{
-		ID:        vpcID,
-		UserID:    userID,
-		TenantID:  appcontext.TenantIDFromContext(ctx),
-		Name:      name,
-		CIDRBlock: cidrBlock,
-		NetworkID: bridgeName,
-		VXLANID:   vxlanID,
-		Status:    "active",
-		ARN:       arn,
-		CreatedAt: time.Now(),
+		ID:            vpcID,
+		UserID:        userID,
+		TenantID:      appcontext.TenantIDFromContext(ctx),
+		Name:          name,
+		CIDRBlock:     cidrBlock,
+		IPv6CIDRBlock: ipv6CIDRBlock,
+		NetworkID:     bridgeName,
+		VXLANID:       vxlanID,
+		Status:        "active",
+		ARN:           arn,
+		CreatedAt:     time.Now(),
 	}
 
 	if err := s.repo.Create(ctx, vpc); err != nil {
@@ -97,9 +106,10 @@ func (s *VpcService) CreateVPC(ctx context.Context, name, cidrBlock string) (*do
 	}
 
 	_ = s.auditSvc.Log(ctx, vpc.UserID, "vpc.create", "vpc", vpc.ID.String(), map[string]interface{}{
-		"name":       vpc.Name,
-		"cidr_block": vpc.CIDRBlock,
-		"arn":        vpc.ARN,
+		"name":            vpc.Name,
+		"cidr_block":      vpc.CIDRBlock,
+		"ipv6_cidr_block": vpc.IPv6CIDRBlock,
+		"arn":             vpc.ARN,
 	})
 
 	return vpc, nil
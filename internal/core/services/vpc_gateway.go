@@ -0,0 +1,420 @@
+// Package services implements core business workflows.
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const vpcGatewayTracer = "vpc-gateway-service"
+
+// VPCGatewayServiceParams holds the dependencies for creating a VPCGatewayService.
+type VPCGatewayServiceParams struct {
+	IGWRepo      ports.InternetGatewayRepository
+	NATRepo      ports.NATGatewayRepository
+	RouteRepo    ports.RouteTableRepository
+	VpcRepo      ports.VpcRepository
+	SubnetRepo   ports.SubnetRepository
+	EIPRepo      ports.ElasticIPRepository
+	InstanceRepo ports.InstanceRepository
+	Network      ports.NetworkBackend
+	AuditSvc     ports.AuditService
+	Logger       *slog.Logger
+}
+
+// VPCGatewayService manages the gateways that connect VPCs to the internet: internet
+// gateways, attached one per VPC, and NAT gateways, which give private subnets
+// outbound-only access behind an Elastic IP.
+type VPCGatewayService struct {
+	igwRepo      ports.InternetGatewayRepository
+	natRepo      ports.NATGatewayRepository
+	routeRepo    ports.RouteTableRepository
+	vpcRepo      ports.VpcRepository
+	subnetRepo   ports.SubnetRepository
+	eipRepo      ports.ElasticIPRepository
+	instanceRepo ports.InstanceRepository
+	network      ports.NetworkBackend
+	auditSvc     ports.AuditService
+	logger       *slog.Logger
+}
+
+// NewVPCGatewayService constructs a VPCGatewayService with its dependencies.
+func NewVPCGatewayService(params VPCGatewayServiceParams) *VPCGatewayService {
+	return &VPCGatewayService{
+		igwRepo:      params.IGWRepo,
+		natRepo:      params.NATRepo,
+		routeRepo:    params.RouteRepo,
+		vpcRepo:      params.VpcRepo,
+		subnetRepo:   params.SubnetRepo,
+		eipRepo:      params.EIPRepo,
+		instanceRepo: params.InstanceRepo,
+		network:      params.Network,
+		auditSvc:     params.AuditSvc,
+		logger:       params.Logger,
+	}
+}
+
+// CreateInternetGateway creates a detached internet gateway.
+func (s *VPCGatewayService) CreateInternetGateway(ctx context.Context, name string) (*domain.InternetGateway, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New(errors.InvalidInput, "internet gateway name is required")
+	}
+
+	userID := appcontext.UserIDFromContext(ctx)
+	id := uuid.New()
+	igw := &domain.InternetGateway{
+		ID:        id,
+		UserID:    userID,
+		TenantID:  appcontext.TenantIDFromContext(ctx),
+		Name:      name,
+		Status:    domain.IGWStatusDetached,
+		ARN:       fmt.Sprintf("arn:thecloud:vpc:local:%s:internet-gateway/%s", userID.String(), id.String()),
+		CreatedAt: time.Now(),
+	}
+	if err := s.igwRepo.Create(ctx, igw); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, userID, "internet_gateway.create", "internet_gateway", id.String(), map[string]interface{}{
+		"name": name,
+	})
+	return igw, nil
+}
+
+// AttachInternetGateway connects a gateway to a VPC. A VPC has at most one internet
+// gateway and a gateway serves at most one VPC.
+func (s *VPCGatewayService) AttachInternetGateway(ctx context.Context, id, vpcID uuid.UUID) (*domain.InternetGateway, error) {
+	ctx, span := otel.Tracer(vpcGatewayTracer).Start(ctx, "AttachInternetGateway")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("internet_gateway_id", id.String()),
+		attribute.String("vpc_id", vpcID.String()),
+	)
+
+	igw, err := s.igwRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if igw.Status == domain.IGWStatusAttached {
+		return nil, errors.New(errors.Conflict, "internet gateway is already attached to a VPC")
+	}
+	vpc, err := s.vpcRepo.GetByID(ctx, vpcID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.igwRepo.GetByVPC(ctx, vpcID); err == nil {
+		return nil, errors.New(errors.Conflict, "VPC already has an internet gateway")
+	} else if !errors.Is(err, errors.NotFound) {
+		return nil, err
+	}
+
+	if err := s.network.AttachInternetGateway(ctx, vpc.NetworkID, igw.NamespaceName(), vpc.CIDRBlock); err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to attach internet gateway", err)
+	}
+	igw.VPCID = &vpc.ID
+	igw.Status = domain.IGWStatusAttached
+	err = s.mapElasticIPs(ctx, igw, vpc.ID)
+	if err == nil {
+		err = s.igwRepo.Update(ctx, igw)
+	}
+	if err != nil {
+		if dErr := s.network.DetachInternetGateway(ctx, vpc.NetworkID, igw.NamespaceName()); dErr != nil {
+			s.logger.Error("failed to roll back internet gateway attachment", "internet_gateway_id", id, "error", dErr)
+		}
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "internet_gateway.attach", "internet_gateway", id.String(), map[string]interface{}{
+		"vpc_id": vpcID.String(),
+	})
+	return igw, nil
+}
+
+// DetachInternetGateway disconnects a gateway from its VPC. Routes targeting the gateway
+// must be removed first.
+func (s *VPCGatewayService) DetachInternetGateway(ctx context.Context, id uuid.UUID) (*domain.InternetGateway, error) {
+	ctx, span := otel.Tracer(vpcGatewayTracer).Start(ctx, "DetachInternetGateway")
+	defer span.End()
+	span.SetAttributes(attribute.String("internet_gateway_id", id.String()))
+
+	igw, err := s.igwRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if igw.Status != domain.IGWStatusAttached || igw.VPCID == nil {
+		return nil, errors.New(errors.Conflict, "internet gateway is not attached")
+	}
+	if err := s.ensureUnrouted(ctx, id, "internet gateway"); err != nil {
+		return nil, err
+	}
+
+	vpcID := *igw.VPCID
+	vpc, err := s.vpcRepo.GetByID(ctx, vpcID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.network.DetachInternetGateway(ctx, vpc.NetworkID, igw.NamespaceName()); err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to detach internet gateway", err)
+	}
+	igw.VPCID = nil
+	igw.Status = domain.IGWStatusDetached
+	if err := s.igwRepo.Update(ctx, igw); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "internet_gateway.detach", "internet_gateway", id.String(), map[string]interface{}{
+		"vpc_id": vpcID.String(),
+	})
+	return igw, nil
+}
+
+// ListInternetGateways returns the caller's internet gateways.
+func (s *VPCGatewayService) ListInternetGateways(ctx context.Context) ([]*domain.InternetGateway, error) {
+	return s.igwRepo.List(ctx)
+}
+
+// DeleteInternetGateway removes a detached internet gateway.
+func (s *VPCGatewayService) DeleteInternetGateway(ctx context.Context, id uuid.UUID) error {
+	igw, err := s.igwRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if igw.Status == domain.IGWStatusAttached {
+		return errors.New(errors.Conflict, "internet gateway is attached; detach it first")
+	}
+	if err := s.igwRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "internet_gateway.delete", "internet_gateway", id.String(), nil)
+	return nil
+}
+
+// CreateNATGateway launches a NAT gateway in a public subnet, at most one per subnet. The
+// gateway takes the subnet's last usable address, which instance allocation then skips,
+// and masquerades traffic from the whole VPC behind the Elastic IP, which stays held by
+// the gateway until it is deleted.
+func (s *VPCGatewayService) CreateNATGateway(ctx context.Context, subnetID, elasticIPID uuid.UUID, name string) (*domain.NATGateway, error) {
+	ctx, span := otel.Tracer(vpcGatewayTracer).Start(ctx, "CreateNATGateway")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("subnet_id", subnetID.String()),
+		attribute.String("elastic_ip_id", elasticIPID.String()),
+	)
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New(errors.InvalidInput, "nat gateway name is required")
+	}
+
+	subnet, err := s.subnetRepo.GetByID(ctx, subnetID)
+	if err != nil {
+		return nil, err
+	}
+	vpc, err := s.vpcRepo.GetByID(ctx, subnet.VPCID)
+	if err != nil {
+		return nil, err
+	}
+	public, err := s.isPublicSubnet(ctx, subnet)
+	if err != nil {
+		return nil, err
+	}
+	if !public {
+		return nil, errors.New(errors.InvalidInput, "nat gateways must be placed in a public subnet, whose route table targets an internet gateway")
+	}
+
+	if _, err := s.natRepo.GetBySubnet(ctx, subnet.ID); err == nil {
+		return nil, errors.New(errors.Conflict, "subnet already has a nat gateway")
+	} else if !errors.Is(err, errors.NotFound) {
+		return nil, err
+	}
+
+	eip, err := s.eipRepo.GetByID(ctx, elasticIPID)
+	if err != nil {
+		return nil, err
+	}
+	if eip.Status != domain.EIPStatusAllocated {
+		return nil, errors.New(errors.Conflict, "elastic ip is already in use")
+	}
+
+	privateIP, err := domain.LastUsableIP(subnet.CIDRBlock)
+	if err != nil {
+		return nil, errors.Wrap(errors.InvalidInput, "subnet cannot host a nat gateway", err)
+	}
+	if err := s.ensureAddressFree(ctx, subnet.ID, privateIP); err != nil {
+		return nil, err
+	}
+
+	userID := appcontext.UserIDFromContext(ctx)
+	id := uuid.New()
+	nat := &domain.NATGateway{
+		ID:          id,
+		UserID:      userID,
+		TenantID:    vpc.TenantID,
+		VPCID:       vpc.ID,
+		SubnetID:    subnet.ID,
+		ElasticIPID: eip.ID,
+		Name:        name,
+		PublicIP:    eip.PublicIP,
+		PrivateIP:   privateIP,
+		Status:      domain.NATStatusAvailable,
+		ARN:         fmt.Sprintf("arn:thecloud:vpc:local:%s:nat-gateway/%s", userID.String(), id.String()),
+		CreatedAt:   time.Now(),
+	}
+	if err := s.natRepo.Create(ctx, nat); err != nil {
+		return nil, err
+	}
+
+	eip.Status = domain.EIPStatusAssociated
+	eip.VpcID = &vpc.ID
+	eip.InstanceID = nil
+	eip.UpdatedAt = time.Now()
+	if err := s.eipRepo.Update(ctx, eip); err != nil {
+		if dErr := s.natRepo.Delete(ctx, id); dErr != nil {
+			s.logger.Error("failed to roll back nat gateway record", "nat_gateway_id", id, "error", dErr)
+		}
+		return nil, err
+	}
+
+	if err := s.network.CreateNATGateway(ctx, vpc.NetworkID, nat.NamespaceName(), privateIP, eip.PublicIP, vpc.CIDRBlock); err != nil {
+		s.logger.Error("failed to set up nat gateway", "nat_gateway_id", id, "error", err)
+		if uErr := s.natRepo.UpdateStatus(ctx, id, domain.NATStatusFailed); uErr != nil {
+			s.logger.Error("failed to mark nat gateway as failed", "nat_gateway_id", id, "error", uErr)
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to set up nat gateway", err)
+	}
+
+	_ = s.auditSvc.Log(ctx, userID, "nat_gateway.create", "nat_gateway", id.String(), map[string]interface{}{
+		"subnet_id": subnet.ID.String(),
+		"public_ip": eip.PublicIP,
+	})
+	return nat, nil
+}
+
+// GetNATGateway retrieves a NAT gateway.
+func (s *VPCGatewayService) GetNATGateway(ctx context.Context, id uuid.UUID) (*domain.NATGateway, error) {
+	return s.natRepo.GetByID(ctx, id)
+}
+
+// ListNATGateways returns the caller's NAT gateways.
+func (s *VPCGatewayService) ListNATGateways(ctx context.Context) ([]*domain.NATGateway, error) {
+	return s.natRepo.List(ctx)
+}
+
+// DeleteNATGateway tears down a NAT gateway no route uses and returns its Elastic IP to
+// the allocated state.
+func (s *VPCGatewayService) DeleteNATGateway(ctx context.Context, id uuid.UUID) error {
+	ctx, span := otel.Tracer(vpcGatewayTracer).Start(ctx, "DeleteNATGateway")
+	defer span.End()
+	span.SetAttributes(attribute.String("nat_gateway_id", id.String()))
+
+	nat, err := s.natRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureUnrouted(ctx, id, "nat gateway"); err != nil {
+		return err
+	}
+
+	if vpc, err := s.vpcRepo.GetByID(ctx, nat.VPCID); err != nil {
+		s.logger.Warn("VPC not found during nat gateway deletion", "nat_gateway_id", id, "error", err)
+	} else if err := s.network.DeleteNATGateway(ctx, vpc.NetworkID, nat.NamespaceName()); err != nil {
+		s.logger.Warn("failed to tear down nat gateway", "nat_gateway_id", id, "error", err)
+	}
+
+	if err := s.natRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if eip, err := s.eipRepo.GetByID(ctx, nat.ElasticIPID); err != nil {
+		s.logger.Warn("elastic ip not found during nat gateway deletion", "nat_gateway_id", id, "error", err)
+	} else {
+		eip.Status = domain.EIPStatusAllocated
+		eip.VpcID = nil
+		eip.UpdatedAt = time.Now()
+		if err := s.eipRepo.Update(ctx, eip); err != nil {
+			s.logger.Error("failed to release elastic ip held by nat gateway", "nat_gateway_id", id, "elastic_ip_id", eip.ID, "error", err)
+		}
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "nat_gateway.delete", "nat_gateway", id.String(), nil)
+	return nil
+}
+
+// mapElasticIPs translates the Elastic IPs already associated with instances in a VPC on
+// its newly attached internet gateway. Elastic IPs held by NAT gateways have no instance
+// and are served by the NAT gateway itself.
+func (s *VPCGatewayService) mapElasticIPs(ctx context.Context, igw *domain.InternetGateway, vpcID uuid.UUID) error {
+	eips, err := s.eipRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, eip := range eips {
+		if eip.InstanceID == nil || eip.VpcID == nil || *eip.VpcID != vpcID {
+			continue
+		}
+		inst, err := s.instanceRepo.GetByID(ctx, *eip.InstanceID)
+		if err != nil {
+			return err
+		}
+		if err := s.network.MapElasticIP(ctx, igw.NamespaceName(), eip.PublicIP, stripPrefixLen(inst.PrivateIP)); err != nil {
+			return errors.Wrap(errors.Internal, "failed to map elastic ip "+eip.PublicIP, err)
+		}
+	}
+	return nil
+}
+
+// ensureAddressFree refuses to hand a NAT gateway an address an instance already holds,
+// which only happens when a subnet has been filled up to its last address.
+func (s *VPCGatewayService) ensureAddressFree(ctx context.Context, subnetID uuid.UUID, ip string) error {
+	instances, err := s.instanceRepo.ListBySubnet(ctx, subnetID)
+	if err != nil {
+		return err
+	}
+	for _, inst := range instances {
+		if stripPrefixLen(inst.PrivateIP) == ip {
+			return errors.New(errors.Conflict, fmt.Sprintf("address %s is held by instance %s", ip, inst.ID))
+		}
+	}
+	return nil
+}
+
+// ensureUnrouted refuses to remove a gateway that route tables still send traffic to.
+func (s *VPCGatewayService) ensureUnrouted(ctx context.Context, id uuid.UUID, kind string) error {
+	routes, err := s.routeRepo.ListRoutesByTarget(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(routes) > 0 {
+		return errors.New(errors.Conflict, fmt.Sprintf("%s is the target of %d route(s); remove them first", kind, len(routes)))
+	}
+	return nil
+}
+
+// isPublicSubnet reports whether the route table in effect for a subnet routes to an
+// internet gateway.
+func (s *VPCGatewayService) isPublicSubnet(ctx context.Context, subnet *domain.Subnet) (bool, error) {
+	rt, err := s.routeRepo.GetBySubnet(ctx, subnet.ID)
+	if errors.Is(err, errors.NotFound) {
+		rt, err = s.routeRepo.GetMain(ctx, subnet.VPCID)
+		if errors.Is(err, errors.NotFound) {
+			return false, nil
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	return rt.IsPublic(), nil
+}
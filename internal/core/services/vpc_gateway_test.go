@@ -0,0 +1,334 @@
+package services_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockInternetGatewayRepo struct {
+	mock.Mock
+}
+
+func (m *MockInternetGatewayRepo) Create(ctx context.Context, igw *domain.InternetGateway) error {
+	return m.Called(ctx, igw).Error(0)
+}
+func (m *MockInternetGatewayRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.InternetGateway, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.InternetGateway), args.Error(1)
+}
+func (m *MockInternetGatewayRepo) GetByVPC(ctx context.Context, vpcID uuid.UUID) (*domain.InternetGateway, error) {
+	args := m.Called(ctx, vpcID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.InternetGateway), args.Error(1)
+}
+func (m *MockInternetGatewayRepo) List(ctx context.Context) ([]*domain.InternetGateway, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.InternetGateway), args.Error(1)
+}
+func (m *MockInternetGatewayRepo) Update(ctx context.Context, igw *domain.InternetGateway) error {
+	return m.Called(ctx, igw).Error(0)
+}
+func (m *MockInternetGatewayRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+type MockNATGatewayRepo struct {
+	mock.Mock
+}
+
+func (m *MockNATGatewayRepo) Create(ctx context.Context, nat *domain.NATGateway) error {
+	return m.Called(ctx, nat).Error(0)
+}
+func (m *MockNATGatewayRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.NATGateway, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NATGateway), args.Error(1)
+}
+func (m *MockNATGatewayRepo) GetBySubnet(ctx context.Context, subnetID uuid.UUID) (*domain.NATGateway, error) {
+	args := m.Called(ctx, subnetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NATGateway), args.Error(1)
+}
+func (m *MockNATGatewayRepo) List(ctx context.Context) ([]*domain.NATGateway, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.NATGateway), args.Error(1)
+}
+func (m *MockNATGatewayRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.NATGatewayStatus) error {
+	return m.Called(ctx, id, status).Error(0)
+}
+func (m *MockNATGatewayRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func TestVPCGatewayService(t *testing.T) {
+	tenantID := uuid.New()
+	ctx := appcontext.WithTenantID(appcontext.WithUserID(context.Background(), uuid.New()), tenantID)
+	vpc := &domain.VPC{ID: uuid.New(), TenantID: tenantID, CIDRBlock: "10.0.0.0/16", NetworkID: "br-vpc-gw"}
+	subnet := &domain.Subnet{ID: uuid.New(), VPCID: vpc.ID, CIDRBlock: "10.0.1.0/24"}
+
+	type deps struct {
+		igwRepo   *MockInternetGatewayRepo
+		natRepo   *MockNATGatewayRepo
+		routeRepo *MockRouteTableRepo
+		eipRepo   *MockElasticIPRepo
+		instRepo  *MockInstanceRepo
+		network   *MockNetworkBackend
+	}
+	setup := func() (*services.VPCGatewayService, deps) {
+		d := deps{
+			igwRepo:   new(MockInternetGatewayRepo),
+			natRepo:   new(MockNATGatewayRepo),
+			routeRepo: new(MockRouteTableRepo),
+			eipRepo:   new(MockElasticIPRepo),
+			instRepo:  new(MockInstanceRepo),
+			network:   new(MockNetworkBackend),
+		}
+		vpcRepo := new(MockVpcRepo)
+		vpcRepo.On("GetByID", mock.Anything, vpc.ID).Return(vpc, nil).Maybe()
+		subnets := new(MockSubnetRepo)
+		subnets.On("GetByID", mock.Anything, subnet.ID).Return(subnet, nil).Maybe()
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		svc := services.NewVPCGatewayService(services.VPCGatewayServiceParams{
+			IGWRepo: d.igwRepo, NATRepo: d.natRepo, RouteRepo: d.routeRepo, VpcRepo: vpcRepo, SubnetRepo: subnets,
+			EIPRepo: d.eipRepo, InstanceRepo: d.instRepo, Network: d.network, AuditSvc: audit, Logger: slog.Default(),
+		})
+		return svc, d
+	}
+	// noNATYet lets CreateNATGateway past its one-gateway-per-subnet and address checks.
+	noNATYet := func(d deps) {
+		d.natRepo.On("GetBySubnet", mock.Anything, subnet.ID).Return(nil, errors.New(errors.NotFound, "nat gateway not found"))
+		d.instRepo.On("ListBySubnet", mock.Anything, subnet.ID).Return([]*domain.Instance{{PrivateIP: "10.0.1.2"}}, nil).Maybe()
+	}
+	publicTable := func() *domain.RouteTable {
+		igwID := uuid.New()
+		return &domain.RouteTable{ID: uuid.New(), VPCID: vpc.ID, Main: true, Routes: []domain.Route{
+			{DestinationCIDR: "10.0.0.0/16", TargetType: domain.RouteTargetLocal},
+			{DestinationCIDR: "0.0.0.0/0", TargetType: domain.RouteTargetInternetGateway, TargetID: &igwID},
+		}}
+	}
+
+	t.Run("AttachInternetGateway plugs the gateway into the bridge", func(t *testing.T) {
+		svc, d := setup()
+		igw := &domain.InternetGateway{ID: uuid.New(), Status: domain.IGWStatusDetached}
+		instID := uuid.New()
+		otherVPC := uuid.New()
+		d.igwRepo.On("GetByID", mock.Anything, igw.ID).Return(igw, nil)
+		d.igwRepo.On("GetByVPC", mock.Anything, vpc.ID).Return(nil, errors.New(errors.NotFound, "internet gateway not found"))
+		d.igwRepo.On("Update", mock.Anything, igw).Return(nil)
+		d.eipRepo.On("List", mock.Anything).Return([]*domain.ElasticIP{
+			{PublicIP: "100.64.0.7", InstanceID: &instID, VpcID: &vpc.ID, Status: domain.EIPStatusAssociated},
+			{PublicIP: "100.64.0.8", VpcID: &vpc.ID, Status: domain.EIPStatusAssociated},
+			{PublicIP: "100.64.0.9", InstanceID: &instID, VpcID: &otherVPC, Status: domain.EIPStatusAssociated},
+		}, nil)
+		d.instRepo.On("GetByID", mock.Anything, instID).Return(&domain.Instance{ID: instID, PrivateIP: "10.0.1.5"}, nil)
+		d.network.On("AttachInternetGateway", mock.Anything, "br-vpc-gw", igw.NamespaceName(), "10.0.0.0/16").Return(nil).Once()
+		d.network.On("MapElasticIP", mock.Anything, igw.NamespaceName(), "100.64.0.7", "10.0.1.5").Return(nil).Once()
+
+		got, err := svc.AttachInternetGateway(ctx, igw.ID, vpc.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.IGWStatusAttached, got.Status)
+		assert.Equal(t, vpc.ID, *got.VPCID)
+		d.network.AssertExpectations(t)
+	})
+
+	t.Run("AttachInternetGateway rolls back when an elastic ip cannot be mapped", func(t *testing.T) {
+		svc, d := setup()
+		igw := &domain.InternetGateway{ID: uuid.New(), Status: domain.IGWStatusDetached}
+		instID := uuid.New()
+		d.igwRepo.On("GetByID", mock.Anything, igw.ID).Return(igw, nil)
+		d.igwRepo.On("GetByVPC", mock.Anything, vpc.ID).Return(nil, errors.New(errors.NotFound, "internet gateway not found"))
+		d.eipRepo.On("List", mock.Anything).Return([]*domain.ElasticIP{
+			{PublicIP: "100.64.0.7", InstanceID: &instID, VpcID: &vpc.ID, Status: domain.EIPStatusAssociated},
+		}, nil)
+		d.instRepo.On("GetByID", mock.Anything, instID).Return(&domain.Instance{ID: instID, PrivateIP: "10.0.1.5"}, nil)
+		d.network.On("AttachInternetGateway", mock.Anything, "br-vpc-gw", igw.NamespaceName(), "10.0.0.0/16").Return(nil)
+		d.network.On("MapElasticIP", mock.Anything, igw.NamespaceName(), "100.64.0.7", "10.0.1.5").Return(errors.New(errors.Internal, "boom"))
+		d.network.On("DetachInternetGateway", mock.Anything, "br-vpc-gw", igw.NamespaceName()).Return(nil).Once()
+
+		_, err := svc.AttachInternetGateway(ctx, igw.ID, vpc.ID)
+		assert.True(t, errors.Is(err, errors.Internal))
+		d.igwRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		d.network.AssertExpectations(t)
+	})
+
+	t.Run("AttachInternetGateway allows one gateway per VPC", func(t *testing.T) {
+		svc, d := setup()
+		igw := &domain.InternetGateway{ID: uuid.New(), Status: domain.IGWStatusDetached}
+		d.igwRepo.On("GetByID", mock.Anything, igw.ID).Return(igw, nil)
+		d.igwRepo.On("GetByVPC", mock.Anything, vpc.ID).Return(&domain.InternetGateway{ID: uuid.New()}, nil)
+
+		_, err := svc.AttachInternetGateway(ctx, igw.ID, vpc.ID)
+		assert.True(t, errors.Is(err, errors.Conflict))
+		d.network.AssertNotCalled(t, "AttachInternetGateway", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DetachInternetGateway refuses while routes target it", func(t *testing.T) {
+		svc, d := setup()
+		igw := &domain.InternetGateway{ID: uuid.New(), VPCID: &vpc.ID, Status: domain.IGWStatusAttached}
+		d.igwRepo.On("GetByID", mock.Anything, igw.ID).Return(igw, nil)
+		d.routeRepo.On("ListRoutesByTarget", mock.Anything, igw.ID).Return([]*domain.Route{{}}, nil)
+
+		_, err := svc.DetachInternetGateway(ctx, igw.ID)
+		assert.True(t, errors.Is(err, errors.Conflict))
+	})
+
+	t.Run("DetachInternetGateway unplugs the gateway", func(t *testing.T) {
+		svc, d := setup()
+		igw := &domain.InternetGateway{ID: uuid.New(), VPCID: &vpc.ID, Status: domain.IGWStatusAttached}
+		d.igwRepo.On("GetByID", mock.Anything, igw.ID).Return(igw, nil)
+		d.igwRepo.On("Update", mock.Anything, igw).Return(nil)
+		d.routeRepo.On("ListRoutesByTarget", mock.Anything, igw.ID).Return([]*domain.Route{}, nil)
+		d.network.On("DetachInternetGateway", mock.Anything, "br-vpc-gw", igw.NamespaceName()).Return(nil).Once()
+
+		got, err := svc.DetachInternetGateway(ctx, igw.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.VPCID)
+		assert.Equal(t, domain.IGWStatusDetached, got.Status)
+	})
+
+	t.Run("DeleteInternetGateway requires a detached gateway", func(t *testing.T) {
+		svc, d := setup()
+		igw := &domain.InternetGateway{ID: uuid.New(), VPCID: &vpc.ID, Status: domain.IGWStatusAttached}
+		d.igwRepo.On("GetByID", mock.Anything, igw.ID).Return(igw, nil)
+
+		assert.True(t, errors.Is(svc.DeleteInternetGateway(ctx, igw.ID), errors.Conflict))
+		d.igwRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreateNATGateway in a public subnet", func(t *testing.T) {
+		svc, d := setup()
+		noNATYet(d)
+		eip := &domain.ElasticIP{ID: uuid.New(), PublicIP: "100.64.0.9", Status: domain.EIPStatusAllocated}
+		d.routeRepo.On("GetBySubnet", mock.Anything, subnet.ID).Return(nil, errors.New(errors.NotFound, "route table not found"))
+		d.routeRepo.On("GetMain", mock.Anything, vpc.ID).Return(publicTable(), nil)
+		d.eipRepo.On("GetByID", mock.Anything, eip.ID).Return(eip, nil)
+		d.eipRepo.On("Update", mock.Anything, mock.MatchedBy(func(e *domain.ElasticIP) bool {
+			return e.Status == domain.EIPStatusAssociated && e.InstanceID == nil && *e.VpcID == vpc.ID
+		})).Return(nil).Once()
+		d.natRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		d.network.On("CreateNATGateway", mock.Anything, "br-vpc-gw", mock.Anything, "10.0.1.254", "100.64.0.9", "10.0.0.0/16").Return(nil).Once()
+
+		nat, err := svc.CreateNATGateway(ctx, subnet.ID, eip.ID, "egress")
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.1.254", nat.PrivateIP)
+		assert.Equal(t, domain.NATStatusAvailable, nat.Status)
+		d.eipRepo.AssertExpectations(t)
+		d.network.AssertExpectations(t)
+	})
+
+	t.Run("CreateNATGateway refuses private subnets", func(t *testing.T) {
+		svc, d := setup()
+		private := publicTable()
+		private.Routes = private.Routes[:1]
+		d.routeRepo.On("GetBySubnet", mock.Anything, subnet.ID).Return(private, nil)
+
+		_, err := svc.CreateNATGateway(ctx, subnet.ID, uuid.New(), "egress")
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+		d.natRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreateNATGateway refuses an Elastic IP in use", func(t *testing.T) {
+		svc, d := setup()
+		noNATYet(d)
+		instanceID := uuid.New()
+		eip := &domain.ElasticIP{ID: uuid.New(), InstanceID: &instanceID, Status: domain.EIPStatusAssociated}
+		d.routeRepo.On("GetBySubnet", mock.Anything, subnet.ID).Return(publicTable(), nil)
+		d.eipRepo.On("GetByID", mock.Anything, eip.ID).Return(eip, nil)
+
+		_, err := svc.CreateNATGateway(ctx, subnet.ID, eip.ID, "egress")
+		assert.True(t, errors.Is(err, errors.Conflict))
+	})
+
+	t.Run("CreateNATGateway allows one gateway per subnet", func(t *testing.T) {
+		svc, d := setup()
+		d.routeRepo.On("GetBySubnet", mock.Anything, subnet.ID).Return(publicTable(), nil)
+		d.natRepo.On("GetBySubnet", mock.Anything, subnet.ID).Return(&domain.NATGateway{ID: uuid.New()}, nil)
+
+		_, err := svc.CreateNATGateway(ctx, subnet.ID, uuid.New(), "egress")
+		assert.True(t, errors.Is(err, errors.Conflict))
+		d.natRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreateNATGateway refuses an address an instance holds", func(t *testing.T) {
+		svc, d := setup()
+		eip := &domain.ElasticIP{ID: uuid.New(), PublicIP: "100.64.0.9", Status: domain.EIPStatusAllocated}
+		d.routeRepo.On("GetBySubnet", mock.Anything, subnet.ID).Return(publicTable(), nil)
+		d.natRepo.On("GetBySubnet", mock.Anything, subnet.ID).Return(nil, errors.New(errors.NotFound, "nat gateway not found"))
+		d.eipRepo.On("GetByID", mock.Anything, eip.ID).Return(eip, nil)
+		d.instRepo.On("ListBySubnet", mock.Anything, subnet.ID).Return([]*domain.Instance{{ID: uuid.New(), PrivateIP: "10.0.1.254/24"}}, nil)
+
+		_, err := svc.CreateNATGateway(ctx, subnet.ID, eip.ID, "egress")
+		assert.True(t, errors.Is(err, errors.Conflict))
+		d.natRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreateNATGateway marks the gateway failed on backend errors", func(t *testing.T) {
+		svc, d := setup()
+		noNATYet(d)
+		eip := &domain.ElasticIP{ID: uuid.New(), PublicIP: "100.64.0.9", Status: domain.EIPStatusAllocated}
+		d.routeRepo.On("GetBySubnet", mock.Anything, subnet.ID).Return(publicTable(), nil)
+		d.eipRepo.On("GetByID", mock.Anything, eip.ID).Return(eip, nil)
+		d.eipRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		d.natRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		d.natRepo.On("UpdateStatus", mock.Anything, mock.Anything, domain.NATStatusFailed).Return(nil).Once()
+		d.network.On("CreateNATGateway", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(assert.AnError)
+
+		_, err := svc.CreateNATGateway(ctx, subnet.ID, eip.ID, "egress")
+		assert.True(t, errors.Is(err, errors.Internal))
+		d.natRepo.AssertExpectations(t)
+	})
+
+	t.Run("DeleteNATGateway releases the Elastic IP", func(t *testing.T) {
+		svc, d := setup()
+		eip := &domain.ElasticIP{ID: uuid.New(), VpcID: &vpc.ID, Status: domain.EIPStatusAssociated}
+		nat := &domain.NATGateway{ID: uuid.New(), VPCID: vpc.ID, ElasticIPID: eip.ID, Status: domain.NATStatusAvailable}
+		d.natRepo.On("GetByID", mock.Anything, nat.ID).Return(nat, nil)
+		d.natRepo.On("Delete", mock.Anything, nat.ID).Return(nil)
+		d.routeRepo.On("ListRoutesByTarget", mock.Anything, nat.ID).Return([]*domain.Route{}, nil)
+		d.network.On("DeleteNATGateway", mock.Anything, "br-vpc-gw", nat.NamespaceName()).Return(nil).Once()
+		d.eipRepo.On("GetByID", mock.Anything, eip.ID).Return(eip, nil)
+		d.eipRepo.On("Update", mock.Anything, mock.MatchedBy(func(e *domain.ElasticIP) bool {
+			return e.Status == domain.EIPStatusAllocated && e.VpcID == nil
+		})).Return(nil).Once()
+
+		require.NoError(t, svc.DeleteNATGateway(ctx, nat.ID))
+		d.eipRepo.AssertExpectations(t)
+		d.network.AssertExpectations(t)
+	})
+
+	t.Run("DeleteNATGateway refuses while routes target it", func(t *testing.T) {
+		svc, d := setup()
+		nat := &domain.NATGateway{ID: uuid.New(), VPCID: vpc.ID}
+		d.natRepo.On("GetByID", mock.Anything, nat.ID).Return(nat, nil)
+		d.routeRepo.On("ListRoutesByTarget", mock.Anything, nat.ID).Return([]*domain.Route{{}, {}}, nil)
+
+		assert.True(t, errors.Is(svc.DeleteNATGateway(ctx, nat.ID), errors.Conflict))
+		d.network.AssertNotCalled(t, "DeleteNATGateway", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,362 @@
+// Package services implements core business workflows.
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	vpcPeeringTracer = "vpc-peering-service"
+
+	// peeringRoutePriority sends traffic to and from the peer VPC through the firewall
+	// tables and then the patch port. Network ACLs sit above it and still apply.
+	peeringRoutePriority = 500
+	// peeringDenyPriority drops new connections from the peer VPC that no security group
+	// rule admitted, and untracked non-IP traffic arriving over the patch port.
+	peeringDenyPriority = 1
+)
+
+// VPCPeeringService connects pairs of VPCs by wiring their OVS bridges together with
+// patch ports and routing each VPC's CIDR block across the link.
+type VPCPeeringService struct {
+	repo     ports.VPCPeeringRepository
+	vpcRepo  ports.VpcRepository
+	network  ports.NetworkBackend
+	auditSvc ports.AuditService
+	logger   *slog.Logger
+}
+
+// NewVPCPeeringService constructs a VPCPeeringService with its dependencies.
+func NewVPCPeeringService(repo ports.VPCPeeringRepository, vpcRepo ports.VpcRepository, network ports.NetworkBackend, auditSvc ports.AuditService, logger *slog.Logger) *VPCPeeringService {
+	return &VPCPeeringService{
+		repo:     repo,
+		vpcRepo:  vpcRepo,
+		network:  network,
+		auditSvc: auditSvc,
+		logger:   logger,
+	}
+}
+
+// CreatePeering records a pending peering request from one of the caller's VPCs to
+// another VPC. A VPC of a different tenant is only found when the request names that
+// tenant as its owner; uuid.Nil stands for the caller's own tenant.
+func (s *VPCPeeringService) CreatePeering(ctx context.Context, requesterVPCID, accepterVPCID, accepterTenantID uuid.UUID) (*domain.VPCPeering, error) {
+	ctx, span := otel.Tracer(vpcPeeringTracer).Start(ctx, "CreatePeering")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("requester_vpc_id", requesterVPCID.String()),
+		attribute.String("accepter_vpc_id", accepterVPCID.String()),
+	)
+
+	if requesterVPCID == accepterVPCID {
+		return nil, errors.New(errors.InvalidInput, "a VPC cannot be peered with itself")
+	}
+
+	requester, err := s.vpcRepo.GetByID(ctx, requesterVPCID)
+	if err != nil {
+		return nil, err
+	}
+	if accepterTenantID == uuid.Nil {
+		accepterTenantID = appcontext.TenantIDFromContext(ctx)
+	}
+	accepter, err := s.repo.GetVPC(ctx, accepterVPCID, accepterTenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	overlap, err := domain.CIDRsOverlap(requester.CIDRBlock, accepter.CIDRBlock)
+	if err != nil {
+		return nil, errors.Wrap(errors.InvalidInput, "invalid VPC CIDR block", err)
+	}
+	if overlap {
+		return nil, errors.New(errors.InvalidInput, fmt.Sprintf("VPC CIDR blocks %s and %s overlap", requester.CIDRBlock, accepter.CIDRBlock))
+	}
+
+	existing, err := s.repo.ListByVPC(ctx, requester.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range existing {
+		if p.RequesterVPCID == accepter.ID || p.AccepterVPCID == accepter.ID {
+			return nil, errors.New(errors.Conflict, "a peering between these VPCs already exists")
+		}
+	}
+	if err := checkPeerCIDR(existing, requester.ID, accepter.CIDRBlock, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	userID := appcontext.UserIDFromContext(ctx)
+	peeringID := uuid.New()
+	now := time.Now()
+	peering := &domain.VPCPeering{
+		ID:                peeringID,
+		RequesterVPCID:    requester.ID,
+		AccepterVPCID:     accepter.ID,
+		RequesterTenantID: requester.TenantID,
+		AccepterTenantID:  accepter.TenantID,
+		RequesterCIDR:     requester.CIDRBlock,
+		AccepterCIDR:      accepter.CIDRBlock,
+		Status:            domain.PeeringPendingAcceptance,
+		ARN:               fmt.Sprintf("arn:thecloud:vpc:local:%s:vpc-peering/%s", userID.String(), peeringID.String()),
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := s.repo.Create(ctx, peering); err != nil {
+		return nil, err
+	}
+
+	_ = s.auditSvc.Log(ctx, userID, "vpc_peering.create", "vpc_peering", peeringID.String(), map[string]interface{}{
+		"requester_vpc_id": requester.ID.String(),
+		"accepter_vpc_id":  accepter.ID.String(),
+	})
+
+	return peering, nil
+}
+
+// AcceptPeering connects the two VPCs of a pending peering. Only the tenant owning the
+// accepter VPC may accept, and the VPC is looked up with the accepter's own access, so a
+// request naming a VPC the caller cannot see is refused.
+func (s *VPCPeeringService) AcceptPeering(ctx context.Context, id uuid.UUID) (*domain.VPCPeering, error) {
+	ctx, span := otel.Tracer(vpcPeeringTracer).Start(ctx, "AcceptPeering")
+	defer span.End()
+	span.SetAttributes(attribute.String("peering_id", id.String()))
+
+	peering, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if peering.AccepterTenantID != appcontext.TenantIDFromContext(ctx) {
+		return nil, errors.New(errors.Forbidden, "only the accepter VPC's tenant can accept a peering")
+	}
+	if peering.Status != domain.PeeringPendingAcceptance {
+		return nil, errors.New(errors.Conflict, fmt.Sprintf("peering is %s, not pending acceptance", peering.Status))
+	}
+
+	accepter, err := s.vpcRepo.GetByID(ctx, peering.AccepterVPCID)
+	if err != nil {
+		return nil, err
+	}
+	if accepter.TenantID != peering.AccepterTenantID || accepter.CIDRBlock != peering.AccepterCIDR {
+		return nil, errors.New(errors.Conflict, "the accepter VPC no longer matches the peering request")
+	}
+	requester, err := s.repo.GetVPC(ctx, peering.RequesterVPCID, peering.RequesterTenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The requester could only check its own peerings; the accepter's are visible now.
+	existing, err := s.repo.ListByVPC(ctx, accepter.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPeerCIDR(existing, accepter.ID, requester.CIDRBlock, peering.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.connect(ctx, peering, requester, accepter); err != nil {
+		s.logger.Error("failed to connect peered VPCs", "peering_id", id, "error", err)
+		if uErr := s.repo.UpdateStatus(ctx, id, domain.PeeringFailed); uErr != nil {
+			s.logger.Error("failed to mark peering as failed", "peering_id", id, "error", uErr)
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to connect peered VPCs", err)
+	}
+
+	if err := s.repo.UpdateStatus(ctx, id, domain.PeeringActive); err != nil {
+		return nil, err
+	}
+	peering.Status = domain.PeeringActive
+	peering.UpdatedAt = time.Now()
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "vpc_peering.accept", "vpc_peering", id.String(), map[string]interface{}{
+		"requester_vpc_id": requester.ID.String(),
+		"accepter_vpc_id":  accepter.ID.String(),
+	})
+
+	return peering, nil
+}
+
+// GetPeering retrieves a peering connection the caller's tenant takes part in.
+func (s *VPCPeeringService) GetPeering(ctx context.Context, id uuid.UUID) (*domain.VPCPeering, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListPeerings returns every peering connection the caller's tenant takes part in.
+func (s *VPCPeeringService) ListPeerings(ctx context.Context) ([]*domain.VPCPeering, error) {
+	return s.repo.List(ctx)
+}
+
+// DeletePeering disconnects the two VPCs, if connected, and removes the peering.
+// Either side may delete, which also serves to reject a pending request.
+func (s *VPCPeeringService) DeletePeering(ctx context.Context, id uuid.UUID) error {
+	ctx, span := otel.Tracer(vpcPeeringTracer).Start(ctx, "DeletePeering")
+	defer span.End()
+	span.SetAttributes(attribute.String("peering_id", id.String()))
+
+	peering, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if peering.Status == domain.PeeringActive {
+		requester, accepter, err := s.peeredVPCs(ctx, peering)
+		if err != nil {
+			s.logger.Warn("peered VPC not found during peering deletion", "peering_id", id, "error", err)
+		} else {
+			s.disconnect(ctx, peering, requester, accepter)
+		}
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	_ = s.auditSvc.Log(ctx, appcontext.UserIDFromContext(ctx), "vpc_peering.delete", "vpc_peering", id.String(), nil)
+
+	return nil
+}
+
+func (s *VPCPeeringService) peeredVPCs(ctx context.Context, peering *domain.VPCPeering) (*domain.VPC, *domain.VPC, error) {
+	requester, err := s.repo.GetVPC(ctx, peering.RequesterVPCID, peering.RequesterTenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	accepter, err := s.repo.GetVPC(ctx, peering.AccepterVPCID, peering.AccepterTenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return requester, accepter, nil
+}
+
+// checkPeerCIDR refuses a peer CIDR that overlaps a CIDR the VPC is already peered with,
+// or has a request pending for, since both would be routed over the same bridge. The
+// peering with the given ID is skipped.
+func checkPeerCIDR(peerings []*domain.VPCPeering, vpcID uuid.UUID, peerCIDR string, skip uuid.UUID) error {
+	for _, p := range peerings {
+		if p.ID == skip || p.Status == domain.PeeringFailed {
+			continue
+		}
+		other := p.AccepterCIDR
+		if p.AccepterVPCID == vpcID {
+			other = p.RequesterCIDR
+		}
+		overlap, err := domain.CIDRsOverlap(other, peerCIDR)
+		if err != nil {
+			return errors.Wrap(errors.Internal, "invalid peered CIDR block", err)
+		}
+		if overlap {
+			return errors.New(errors.Conflict, fmt.Sprintf("CIDR block %s overlaps %s, which the VPC is already peered with", peerCIDR, other))
+		}
+	}
+	return nil
+}
+
+// connect wires the two bridges together with a pair of patch ports and installs, on each
+// bridge, a route towards the peer's CIDR that passes the firewall tables first, the same
+// for traffic arriving from the peer, and a drop for new connections from the peer that
+// no security group rule admits.
+func (s *VPCPeeringService) connect(ctx context.Context, peering *domain.VPCPeering, requester, accepter *domain.VPC) error {
+	reqPort, accPort := peeringPortNames(peering.ID)
+
+	if err := s.network.CreatePatchPort(ctx, requester.NetworkID, reqPort, accPort); err != nil {
+		return err
+	}
+	if err := s.network.CreatePatchPort(ctx, accepter.NetworkID, accPort, reqPort); err != nil {
+		s.disconnect(ctx, peering, requester, accepter)
+		return err
+	}
+
+	for _, side := range []struct {
+		bridge, port, peerCIDR string
+	}{
+		{requester.NetworkID, reqPort, accepter.CIDRBlock},
+		{accepter.NetworkID, accPort, requester.CIDRBlock},
+	} {
+		// The firewall tables may be empty if the VPC has no security groups yet. Their
+		// shared flows are never removed, so they are left in place on disconnect.
+		flows := append(baseFlows(), peeringFlows(side.port, side.peerCIDR)...)
+		for _, flow := range flows {
+			if err := s.network.AddFlowRule(ctx, side.bridge, flow); err != nil {
+				s.disconnect(ctx, peering, requester, accepter)
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// disconnect removes whatever connect installed. Failures are logged so the peering can
+// still be deleted when one of the bridges is already gone.
+func (s *VPCPeeringService) disconnect(ctx context.Context, peering *domain.VPCPeering, requester, accepter *domain.VPC) {
+	reqPort, accPort := peeringPortNames(peering.ID)
+
+	for _, side := range []struct {
+		bridge, port, peerCIDR string
+	}{
+		{requester.NetworkID, reqPort, accepter.CIDRBlock},
+		{accepter.NetworkID, accPort, requester.CIDRBlock},
+	} {
+		for _, flow := range peeringFlows(side.port, side.peerCIDR) {
+			if err := s.network.DeleteFlowRule(ctx, side.bridge, flow.Match); err != nil {
+				s.logger.Warn("failed to delete peering flow rule", "bridge", side.bridge, "match", flow.Match, "error", err)
+			}
+		}
+		if err := s.network.DeletePort(ctx, side.bridge, side.port); err != nil {
+			s.logger.Warn("failed to delete peering patch port", "bridge", side.bridge, "port", side.port, "error", err)
+		}
+	}
+}
+
+func peeringPortNames(id uuid.UUID) (requester, accepter string) {
+	short := id.String()[:8]
+	return fmt.Sprintf("pcx-%s-r", short), fmt.Sprintf("pcx-%s-a", short)
+}
+
+// peeringFlows send IP traffic crossing the patch port in either direction through
+// conntrack and the firewall tables before it is forwarded, so security group rules apply
+// whether or not an instance belongs to a group. Only tracked packets, back in table 0,
+// take the route to the peer. Matches name their table so deleting them cannot catch the
+// security group flows in the firewall tables.
+func peeringFlows(port, peerCIDR string) []ports.FlowRule {
+	firewall := fmt.Sprintf("ct(table=%d)", firewallTable)
+	return []ports.FlowRule{
+		{
+			Priority: peeringRoutePriority,
+			Match:    fmt.Sprintf("table=0,ip,nw_dst=%s,ct_state=-trk", peerCIDR),
+			Actions:  firewall,
+		},
+		{
+			Priority: peeringRoutePriority,
+			Match:    fmt.Sprintf("table=0,ip,nw_dst=%s,ct_state=+trk", peerCIDR),
+			Actions:  fmt.Sprintf("output:%s", port),
+		},
+		{
+			Priority: peeringRoutePriority,
+			Match:    fmt.Sprintf("table=0,in_port=%s,ip,ct_state=-trk", port),
+			Actions:  firewall,
+		},
+		{
+			Priority: peeringDenyPriority,
+			Match:    fmt.Sprintf("table=%d,in_port=%s,ip", firewallIngressTable, port),
+			Actions:  "drop",
+		},
+		{
+			Priority: peeringDenyPriority,
+			Match:    fmt.Sprintf("table=0,in_port=%s,ct_state=-trk", port),
+			Actions:  "drop",
+		},
+	}
+}
@@ -0,0 +1,258 @@
+package services_test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/core/services"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockVPCPeeringRepo struct {
+	mock.Mock
+}
+
+func (m *MockVPCPeeringRepo) Create(ctx context.Context, p *domain.VPCPeering) error {
+	return m.Called(ctx, p).Error(0)
+}
+func (m *MockVPCPeeringRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.VPCPeering, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.VPCPeering), args.Error(1)
+}
+func (m *MockVPCPeeringRepo) List(ctx context.Context) ([]*domain.VPCPeering, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.VPCPeering), args.Error(1)
+}
+func (m *MockVPCPeeringRepo) ListByVPC(ctx context.Context, vpcID uuid.UUID) ([]*domain.VPCPeering, error) {
+	args := m.Called(ctx, vpcID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.VPCPeering), args.Error(1)
+}
+func (m *MockVPCPeeringRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.VPCPeeringStatus) error {
+	return m.Called(ctx, id, status).Error(0)
+}
+func (m *MockVPCPeeringRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *MockVPCPeeringRepo) GetVPC(ctx context.Context, id, tenantID uuid.UUID) (*domain.VPC, error) {
+	args := m.Called(ctx, id, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.VPC), args.Error(1)
+}
+
+func TestVPCPeeringService(t *testing.T) {
+	requesterTenant, accepterTenant := uuid.New(), uuid.New()
+	requester := &domain.VPC{ID: uuid.New(), TenantID: requesterTenant, CIDRBlock: "10.0.0.0/16", NetworkID: "br-vpc-req"}
+	accepter := &domain.VPC{ID: uuid.New(), TenantID: accepterTenant, CIDRBlock: "10.1.0.0/16", NetworkID: "br-vpc-acc"}
+	requesterCtx := appcontext.WithTenantID(appcontext.WithUserID(context.Background(), uuid.New()), requesterTenant)
+	accepterCtx := appcontext.WithTenantID(appcontext.WithUserID(context.Background(), uuid.New()), accepterTenant)
+
+	setup := func() (*services.VPCPeeringService, *MockVPCPeeringRepo, *MockVpcRepo, *MockNetworkBackend) {
+		repo := new(MockVPCPeeringRepo)
+		vpcRepo := new(MockVpcRepo)
+		network := new(MockNetworkBackend)
+		audit := new(MockAuditService)
+		audit.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		return services.NewVPCPeeringService(repo, vpcRepo, network, audit, slog.Default()), repo, vpcRepo, network
+	}
+	pending := func() *domain.VPCPeering {
+		return &domain.VPCPeering{
+			ID:                uuid.New(),
+			RequesterVPCID:    requester.ID,
+			AccepterVPCID:     accepter.ID,
+			RequesterTenantID: requesterTenant,
+			AccepterTenantID:  accepterTenant,
+			RequesterCIDR:     requester.CIDRBlock,
+			AccepterCIDR:      accepter.CIDRBlock,
+			Status:            domain.PeeringPendingAcceptance,
+		}
+	}
+
+	t.Run("CreatePeering across tenants", func(t *testing.T) {
+		svc, repo, vpcRepo, _ := setup()
+		vpcRepo.On("GetByID", mock.Anything, requester.ID).Return(requester, nil)
+		repo.On("GetVPC", mock.Anything, accepter.ID, accepterTenant).Return(accepter, nil)
+		repo.On("ListByVPC", mock.Anything, requester.ID).Return([]*domain.VPCPeering{}, nil)
+		repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		p, err := svc.CreatePeering(requesterCtx, requester.ID, accepter.ID, accepterTenant)
+		require.NoError(t, err)
+		assert.Equal(t, domain.PeeringPendingAcceptance, p.Status)
+		assert.Equal(t, accepterTenant, p.AccepterTenantID)
+		assert.Equal(t, "10.1.0.0/16", p.AccepterCIDR)
+	})
+
+	t.Run("CreatePeering defaults to the caller's tenant", func(t *testing.T) {
+		svc, repo, vpcRepo, _ := setup()
+		vpcRepo.On("GetByID", mock.Anything, requester.ID).Return(requester, nil)
+		repo.On("GetVPC", mock.Anything, accepter.ID, requesterTenant).Return(nil, errors.New(errors.NotFound, "vpc not found"))
+
+		_, err := svc.CreatePeering(requesterCtx, requester.ID, accepter.ID, uuid.Nil)
+		assert.True(t, errors.Is(err, errors.NotFound))
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreatePeering rejects overlapping CIDRs", func(t *testing.T) {
+		svc, repo, vpcRepo, _ := setup()
+		overlapping := &domain.VPC{ID: uuid.New(), TenantID: accepterTenant, CIDRBlock: "10.0.128.0/17"}
+		vpcRepo.On("GetByID", mock.Anything, requester.ID).Return(requester, nil)
+		repo.On("GetVPC", mock.Anything, overlapping.ID, accepterTenant).Return(overlapping, nil)
+
+		_, err := svc.CreatePeering(requesterCtx, requester.ID, overlapping.ID, accepterTenant)
+		assert.True(t, errors.Is(err, errors.InvalidInput))
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreatePeering rejects a CIDR the requester is already peered with", func(t *testing.T) {
+		svc, repo, vpcRepo, _ := setup()
+		other := &domain.VPCPeering{
+			ID: uuid.New(), RequesterVPCID: requester.ID, AccepterVPCID: uuid.New(),
+			RequesterCIDR: requester.CIDRBlock, AccepterCIDR: "10.1.4.0/24", Status: domain.PeeringPendingAcceptance,
+		}
+		vpcRepo.On("GetByID", mock.Anything, requester.ID).Return(requester, nil)
+		repo.On("GetVPC", mock.Anything, accepter.ID, accepterTenant).Return(accepter, nil)
+		repo.On("ListByVPC", mock.Anything, requester.ID).Return([]*domain.VPCPeering{other}, nil)
+
+		_, err := svc.CreatePeering(requesterCtx, requester.ID, accepter.ID, accepterTenant)
+		assert.True(t, errors.Is(err, errors.Conflict))
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreatePeering rejects duplicates", func(t *testing.T) {
+		svc, repo, vpcRepo, _ := setup()
+		vpcRepo.On("GetByID", mock.Anything, requester.ID).Return(requester, nil)
+		repo.On("GetVPC", mock.Anything, accepter.ID, accepterTenant).Return(accepter, nil)
+		repo.On("ListByVPC", mock.Anything, requester.ID).Return([]*domain.VPCPeering{pending()}, nil)
+
+		_, err := svc.CreatePeering(requesterCtx, requester.ID, accepter.ID, accepterTenant)
+		assert.True(t, errors.Is(err, errors.Conflict))
+	})
+
+	t.Run("AcceptPeering wires both bridges", func(t *testing.T) {
+		svc, repo, vpcRepo, network := setup()
+		p := pending()
+		repo.On("GetByID", mock.Anything, p.ID).Return(p, nil)
+		repo.On("GetVPC", mock.Anything, requester.ID, requesterTenant).Return(requester, nil)
+		vpcRepo.On("GetByID", mock.Anything, accepter.ID).Return(accepter, nil)
+		repo.On("ListByVPC", mock.Anything, accepter.ID).Return([]*domain.VPCPeering{p}, nil)
+		repo.On("UpdateStatus", mock.Anything, p.ID, domain.PeeringActive).Return(nil)
+		network.On("CreatePatchPort", mock.Anything, "br-vpc-req", mock.Anything, mock.Anything).Return(nil).Once()
+		network.On("CreatePatchPort", mock.Anything, "br-vpc-acc", mock.Anything, mock.Anything).Return(nil).Once()
+		network.On("AddFlowRule", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		got, err := svc.AcceptPeering(accepterCtx, p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.PeeringActive, got.Status)
+		network.AssertExpectations(t)
+
+		reqPort := "pcx-" + p.ID.String()[:8] + "-r"
+		for _, flow := range []ports.FlowRule{
+			{Priority: 500, Match: "table=0,ip,nw_dst=10.1.0.0/16,ct_state=-trk", Actions: "ct(table=10)"},
+			{Priority: 500, Match: "table=0,ip,nw_dst=10.1.0.0/16,ct_state=+trk", Actions: "output:" + reqPort},
+			{Priority: 500, Match: "table=0,in_port=" + reqPort + ",ip,ct_state=-trk", Actions: "ct(table=10)"},
+			{Priority: 1, Match: "table=11,in_port=" + reqPort + ",ip", Actions: "drop"},
+			{Priority: 0, Match: "table=11", Actions: "ct(commit),resubmit(,0)"},
+		} {
+			network.AssertCalled(t, "AddFlowRule", mock.Anything, "br-vpc-req", flow)
+		}
+		network.AssertCalled(t, "AddFlowRule", mock.Anything, "br-vpc-acc", mock.MatchedBy(func(r ports.FlowRule) bool {
+			return r.Match == "table=0,ip,nw_dst=10.0.0.0/16,ct_state=+trk"
+		}))
+		network.AssertNotCalled(t, "AddFlowRule", mock.Anything, mock.Anything, mock.MatchedBy(func(r ports.FlowRule) bool {
+			return strings.HasPrefix(r.Actions, "output:") && strings.Contains(r.Match, "-trk")
+		}))
+	})
+
+	t.Run("AcceptPeering rejects a CIDR the accepter is already peered with", func(t *testing.T) {
+		svc, repo, vpcRepo, network := setup()
+		p := pending()
+		other := &domain.VPCPeering{
+			ID: uuid.New(), RequesterVPCID: uuid.New(), AccepterVPCID: accepter.ID,
+			RequesterCIDR: "10.0.0.0/20", AccepterCIDR: accepter.CIDRBlock, Status: domain.PeeringActive,
+		}
+		repo.On("GetByID", mock.Anything, p.ID).Return(p, nil)
+		repo.On("GetVPC", mock.Anything, requester.ID, requesterTenant).Return(requester, nil)
+		vpcRepo.On("GetByID", mock.Anything, accepter.ID).Return(accepter, nil)
+		repo.On("ListByVPC", mock.Anything, accepter.ID).Return([]*domain.VPCPeering{p, other}, nil)
+
+		_, err := svc.AcceptPeering(accepterCtx, p.ID)
+		assert.True(t, errors.Is(err, errors.Conflict))
+		network.AssertNotCalled(t, "CreatePatchPort", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AcceptPeering is reserved for the accepter", func(t *testing.T) {
+		svc, repo, _, network := setup()
+		p := pending()
+		repo.On("GetByID", mock.Anything, p.ID).Return(p, nil)
+
+		_, err := svc.AcceptPeering(requesterCtx, p.ID)
+		assert.True(t, errors.Is(err, errors.Forbidden))
+		network.AssertNotCalled(t, "CreatePatchPort", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AcceptPeering looks up the accepter VPC as the caller", func(t *testing.T) {
+		svc, repo, vpcRepo, network := setup()
+		p := pending()
+		repo.On("GetByID", mock.Anything, p.ID).Return(p, nil)
+		vpcRepo.On("GetByID", mock.Anything, accepter.ID).Return(nil, errors.New(errors.NotFound, "vpc not found"))
+
+		_, err := svc.AcceptPeering(accepterCtx, p.ID)
+		assert.True(t, errors.Is(err, errors.NotFound))
+		repo.AssertNotCalled(t, "GetVPC", mock.Anything, mock.Anything, mock.Anything)
+		network.AssertNotCalled(t, "CreatePatchPort", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AcceptPeering rolls back on backend failure", func(t *testing.T) {
+		svc, repo, vpcRepo, network := setup()
+		p := pending()
+		repo.On("GetByID", mock.Anything, p.ID).Return(p, nil)
+		repo.On("GetVPC", mock.Anything, requester.ID, requesterTenant).Return(requester, nil)
+		vpcRepo.On("GetByID", mock.Anything, accepter.ID).Return(accepter, nil)
+		repo.On("ListByVPC", mock.Anything, accepter.ID).Return([]*domain.VPCPeering{p}, nil)
+		repo.On("UpdateStatus", mock.Anything, p.ID, domain.PeeringFailed).Return(nil).Once()
+		network.On("CreatePatchPort", mock.Anything, "br-vpc-req", mock.Anything, mock.Anything).Return(nil)
+		network.On("CreatePatchPort", mock.Anything, "br-vpc-acc", mock.Anything, mock.Anything).Return(assert.AnError)
+		network.On("DeleteFlowRule", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		network.On("AddPort", mock.Anything, mock.Anything, mock.Anything).Return(nil) // DeletePort delegates to AddPort
+
+		_, err := svc.AcceptPeering(accepterCtx, p.ID)
+		assert.True(t, errors.Is(err, errors.Internal))
+		repo.AssertExpectations(t)
+		network.AssertCalled(t, "AddPort", mock.Anything, "br-vpc-req", mock.Anything)
+	})
+
+	t.Run("DeletePeering tears down an active peering", func(t *testing.T) {
+		svc, repo, _, network := setup()
+		p := pending()
+		p.Status = domain.PeeringActive
+		repo.On("GetByID", mock.Anything, p.ID).Return(p, nil)
+		repo.On("GetVPC", mock.Anything, requester.ID, requesterTenant).Return(requester, nil)
+		repo.On("GetVPC", mock.Anything, accepter.ID, accepterTenant).Return(accepter, nil)
+		repo.On("Delete", mock.Anything, p.ID).Return(nil)
+		network.On("DeleteFlowRule", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		network.On("AddPort", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		require.NoError(t, svc.DeletePeering(requesterCtx, p.ID))
+		network.AssertNumberOfCalls(t, "DeleteFlowRule", 10)
+		network.AssertNumberOfCalls(t, "AddPort", 2)
+	})
+}
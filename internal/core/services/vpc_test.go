@@ -40,7 +40,7 @@ func TestVpcServiceCreateSuccess(t *testing.T) {
 	name := "test-vpc-" + uuid.New().String()
 	cidr := testutil.TestCIDR
 
-	vpc, err := svc.CreateVPC(ctx, name, cidr)
+	vpc, err := svc.CreateVPC(ctx, name, cidr, "")
 	require.NoError(t, err)
 	require.NotNil(t, vpc)
 	assert.Equal(t, name, vpc.Name)
@@ -59,7 +59,7 @@ func TestVpcServiceCreateDBFailureRollback(t *testing.T) {
 	cancelledCtx, cancel := context.WithCancel(ctx)
 	cancel()
 
-	vpc, err := svc.CreateVPC(cancelledCtx, "fail-vpc", "")
+	vpc, err := svc.CreateVPC(cancelledCtx, "fail-vpc", "", "")
 	assert.Error(t, err)
 	assert.Nil(t, vpc)
 }
@@ -67,7 +67,7 @@ func TestVpcServiceCreateDBFailureRollback(t *testing.T) {
 func TestVpcServiceCreateDefaultCIDR(t *testing.T) {
 	svc, _, _, ctx := setupVpcServiceTest(t, "")
 
-	vpc, err := svc.CreateVPC(ctx, "default-cidr-"+uuid.New().String(), "")
+	vpc, err := svc.CreateVPC(ctx, "default-cidr-"+uuid.New().String(), "", "")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, vpc)
@@ -76,7 +76,7 @@ func TestVpcServiceCreateDefaultCIDR(t *testing.T) {
 
 func TestVpcServiceDeleteSuccess(t *testing.T) {
 	svc, repo, _, ctx := setupVpcServiceTest(t, testutil.TestCIDR)
-	vpc, err := svc.CreateVPC(ctx, "to-delete-"+uuid.New().String(), testutil.TestCIDR)
+	vpc, err := svc.CreateVPC(ctx, "to-delete-"+uuid.New().String(), testutil.TestCIDR, "")
 	require.NoError(t, err)
 
 	err = svc.DeleteVPC(ctx, vpc.ID.String())
@@ -89,7 +89,7 @@ func TestVpcServiceDeleteSuccess(t *testing.T) {
 
 func TestVpcServiceDeleteFailureWithLBs(t *testing.T) {
 	svc, _, lbRepo, ctx := setupVpcServiceTest(t, testutil.TestCIDR)
-	vpc, err := svc.CreateVPC(ctx, "in-use-"+uuid.New().String(), testutil.TestCIDR)
+	vpc, err := svc.CreateVPC(ctx, "in-use-"+uuid.New().String(), testutil.TestCIDR, "")
 	require.NoError(t, err)
 
 	// Add a Load Balancer to this VPC
@@ -110,8 +110,8 @@ func TestVpcServiceDeleteFailureWithLBs(t *testing.T) {
 
 func TestVpcServiceListSuccess(t *testing.T) {
 	svc, _, _, ctx := setupVpcServiceTest(t, testutil.TestCIDR)
-	_, _ = svc.CreateVPC(ctx, "vpc1-"+uuid.New().String(), testutil.TestCIDR)
-	_, _ = svc.CreateVPC(ctx, "vpc2-"+uuid.New().String(), testutil.TestCIDR)
+	_, _ = svc.CreateVPC(ctx, "vpc1-"+uuid.New().String(), testutil.TestCIDR, "")
+	_, _ = svc.CreateVPC(ctx, "vpc2-"+uuid.New().String(), testutil.TestCIDR, "")
 
 	result, err := svc.ListVPCs(ctx)
 
@@ -122,7 +122,7 @@ func TestVpcServiceListSuccess(t *testing.T) {
 func TestVpcServiceGetByName(t *testing.T) {
 	svc, _, _, ctx := setupVpcServiceTest(t, testutil.TestCIDR)
 	name := "my-vpc-" + uuid.New().String()
-	vpc, _ := svc.CreateVPC(ctx, name, testutil.TestCIDR)
+	vpc, _ := svc.CreateVPC(ctx, name, testutil.TestCIDR, "")
 
 	result, err := svc.GetVPC(ctx, name)
 
@@ -29,13 +29,26 @@ func TestVpcService_Unit(t *testing.T) {
 		repo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
 		auditSvc.On("Log", mock.Anything, userID, "vpc.create", "vpc", mock.Anything, mock.Anything).Return(nil).Once()
 
-		vpc, err := svc.CreateVPC(ctx, "test-vpc", "10.1.0.0/16")
+		vpc, err := svc.CreateVPC(ctx, "test-vpc", "10.1.0.0/16", "")
 		assert.NoError(t, err)
 		assert.NotNil(t, vpc)
 		assert.Equal(t, "10.1.0.0/16", vpc.CIDRBlock)
 		repo.AssertExpectations(t)
 	})
 
+	t.Run("CreateDualStackVPC", func(t *testing.T) {
+		network.On("CreateBridge", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		repo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+		auditSvc.On("Log", mock.Anything, userID, "vpc.create", "vpc", mock.Anything, mock.Anything).Return(nil).Once()
+
+		vpc, err := svc.CreateVPC(ctx, "dual-vpc", "10.2.0.0/16", "fd00:12::/56")
+		assert.NoError(t, err)
+		assert.Equal(t, "fd00:12::/56", vpc.IPv6CIDRBlock)
+
+		_, err = svc.CreateVPC(ctx, "bad-vpc", "10.3.0.0/16", "10.4.0.0/16")
+		assert.Error(t, err)
+	})
+
 	t.Run("DeleteVPC", func(t *testing.T) {
 		vpcID := uuid.New()
 		vpc := &domain.VPC{ID: vpcID, UserID: userID, NetworkID: "br-1"}
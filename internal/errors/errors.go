@@ -25,6 +25,14 @@ const (
 	BucketNotFound Type = "BUCKET_NOT_FOUND"
 	ObjectNotFound Type = "OBJECT_NOT_FOUND"
 	ObjectTooLarge Type = "OBJECT_TOO_LARGE"
+	// PreconditionFailed is returned when an If-Match/If-None-Match/If-Unmodified-Since condition does not hold.
+	PreconditionFailed Type = "PRECONDITION_FAILED"
+	// NotModified is returned for conditional reads of an object that has not changed.
+	NotModified Type = "NOT_MODIFIED"
+	// RangeNotSatisfiable is returned when a requested byte range lies outside the object.
+	RangeNotSatisfiable Type = "RANGE_NOT_SATISFIABLE"
+	// ObjectLocked is returned when a legal hold or retention period prevents a change.
+	ObjectLocked Type = "OBJECT_LOCKED"
 
 	// Networking Errors
 	InvalidPortFormat  Type = "INVALID_PORT_FORMAT"
@@ -0,0 +1,146 @@
+package httphandlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/poyrazk/thecloud/pkg/httputil"
+)
+
+// CertificateHandler handles TLS certificate HTTP endpoints.
+type CertificateHandler struct {
+	svc ports.CertificateService
+}
+
+// NewCertificateHandler constructs a CertificateHandler.
+func NewCertificateHandler(svc ports.CertificateService) *CertificateHandler {
+	return &CertificateHandler{svc: svc}
+}
+
+// CreateCertificateRequest imports a PEM certificate when certificate_pem is
+// set, and otherwise issues one for domains from the internal CA.
+type CreateCertificateRequest struct {
+	Name           string     `json:"name" binding:"required"`
+	CertificatePEM string     `json:"certificate_pem,omitempty"`
+	PrivateKeyPEM  string     `json:"private_key_pem,omitempty"`
+	Domains        []string   `json:"domains,omitempty"`
+	ValidityDays   int        `json:"validity_days,omitempty"`
+	NotifyTopicID  *uuid.UUID `json:"notify_topic_id,omitempty"`
+}
+
+// Create imports or issues a certificate.
+// @Summary Create certificate
+// @Description Imports a PEM certificate and private key, or issues a certificate for the given domains from the internal CA
+// @Tags certificates
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param request body CreateCertificateRequest true "Certificate"
+// @Success 201 {object} domain.Certificate
+// @Failure 400 {object} httputil.Response
+// @Router /certificates [post]
+func (h *CertificateHandler) Create(c *gin.Context) {
+	var req CreateCertificateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httputil.Error(c, errors.New(errors.InvalidInput, err.Error()))
+		return
+	}
+
+	cert, err := h.svc.CreateCertificate(c.Request.Context(), ports.CreateCertificateParams{
+		Name:           req.Name,
+		CertificatePEM: req.CertificatePEM,
+		PrivateKeyPEM:  req.PrivateKeyPEM,
+		Domains:        req.Domains,
+		ValidityDays:   req.ValidityDays,
+		NotifyTopicID:  req.NotifyTopicID,
+	})
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusCreated, cert)
+}
+
+// List returns the caller's certificates.
+// @Summary List certificates
+// @Tags certificates
+// @Produce json
+// @Security APIKeyAuth
+// @Success 200 {array} domain.Certificate
+// @Router /certificates [get]
+func (h *CertificateHandler) List(c *gin.Context) {
+	certs, err := h.svc.ListCertificates(c.Request.Context())
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+	httputil.Success(c, http.StatusOK, certs)
+}
+
+// Get returns a certificate.
+// @Summary Get certificate
+// @Tags certificates
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Certificate ID"
+// @Success 200 {object} domain.Certificate
+// @Failure 404 {object} httputil.Response
+// @Router /certificates/{id} [get]
+func (h *CertificateHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		httputil.Error(c, errors.New(errors.InvalidInput, "invalid certificate id"))
+		return
+	}
+
+	cert, err := h.svc.GetCertificate(c.Request.Context(), id)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+	httputil.Success(c, http.StatusOK, cert)
+}
+
+// Delete removes a certificate that no listener uses.
+// @Summary Delete certificate
+// @Tags certificates
+// @Security APIKeyAuth
+// @Param id path string true "Certificate ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} httputil.Response
+// @Failure 409 {object} httputil.Response
+// @Router /certificates/{id} [delete]
+func (h *CertificateHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		httputil.Error(c, errors.New(errors.InvalidInput, "invalid certificate id"))
+		return
+	}
+
+	if err := h.svc.DeleteCertificate(c.Request.Context(), id); err != nil {
+		httputil.Error(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetAuthority returns the caller's tenant CA certificate, which clients install
+// to trust the certificates issued to that tenant.
+// @Summary Get internal CA certificate
+// @Tags certificates
+// @Produce json
+// @Security APIKeyAuth
+// @Success 200 {object} map[string]string
+// @Router /certificates/ca [get]
+func (h *CertificateHandler) GetAuthority(c *gin.Context) {
+	pem, err := h.svc.GetAuthorityCertificate(c.Request.Context())
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+	httputil.Success(c, http.StatusOK, gin.H{"certificate_pem": pem})
+}
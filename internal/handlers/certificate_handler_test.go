@@ -0,0 +1,146 @@
+package httphandlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const certificatesPath = "/certificates"
+
+type mockCertificateService struct {
+	mock.Mock
+}
+
+func (m *mockCertificateService) CreateCertificate(ctx context.Context, params ports.CreateCertificateParams) (*domain.Certificate, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Certificate), args.Error(1)
+}
+
+func (m *mockCertificateService) GetCertificate(ctx context.Context, id uuid.UUID) (*domain.Certificate, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Certificate), args.Error(1)
+}
+
+func (m *mockCertificateService) ListCertificates(ctx context.Context) ([]*domain.Certificate, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.Certificate), args.Error(1)
+}
+
+func (m *mockCertificateService) DeleteCertificate(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockCertificateService) GetKeyPair(ctx context.Context, id uuid.UUID) (*domain.CertificateKeyPair, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CertificateKeyPair), args.Error(1)
+}
+
+func (m *mockCertificateService) GetAuthorityCertificate(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+func setupCertificateHandlerTest() (*mockCertificateService, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+	svc := new(mockCertificateService)
+	handler := NewCertificateHandler(svc)
+	r := gin.New()
+	r.POST(certificatesPath, handler.Create)
+	r.GET(certificatesPath, handler.List)
+	r.GET(certificatesPath+"/ca", handler.GetAuthority)
+	r.GET(certificatesPath+"/:id", handler.Get)
+	r.DELETE(certificatesPath+"/:id", handler.Delete)
+	return svc, r
+}
+
+func TestCertificateHandlerCreate(t *testing.T) {
+	t.Parallel()
+	svc, r := setupCertificateHandlerTest()
+	defer svc.AssertExpectations(t)
+
+	topic := uuid.New()
+	svc.On("CreateCertificate", mock.Anything, ports.CreateCertificateParams{
+		Name: "web", Domains: []string{"app.example.com"}, ValidityDays: 30, NotifyTopicID: &topic,
+	}).Return(&domain.Certificate{ID: uuid.New(), Name: "web", EncryptedPrivateKey: "secret"}, nil)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"name": "web", "domains": []string{"app.example.com"}, "validity_days": 30, "notify_topic_id": topic,
+	})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, certificatesPath, bytes.NewBuffer(body)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret", "the private key is never returned")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, certificatesPath, bytes.NewBufferString(`{"domains":["a.example.com"]}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCertificateHandlerListAndGet(t *testing.T) {
+	t.Parallel()
+	svc, r := setupCertificateHandlerTest()
+	defer svc.AssertExpectations(t)
+
+	id := uuid.New()
+	svc.On("ListCertificates", mock.Anything).Return([]*domain.Certificate{{ID: id, Name: "web"}}, nil)
+	svc.On("GetCertificate", mock.Anything, id).Return(&domain.Certificate{ID: id, Name: "web"}, nil)
+	svc.On("GetAuthorityCertificate", mock.Anything).Return("-----BEGIN CERTIFICATE-----", nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, certificatesPath, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, certificatesPath+"/"+id.String(), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, certificatesPath+"/not-a-uuid", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, certificatesPath+"/ca", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "certificate_pem")
+}
+
+func TestCertificateHandlerDelete(t *testing.T) {
+	t.Parallel()
+	svc, r := setupCertificateHandlerTest()
+	defer svc.AssertExpectations(t)
+
+	id, inUse := uuid.New(), uuid.New()
+	svc.On("DeleteCertificate", mock.Anything, id).Return(nil)
+	svc.On("DeleteCertificate", mock.Anything, inUse).Return(errors.New(errors.Conflict, "certificate is in use"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, certificatesPath+"/"+id.String(), nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, certificatesPath+"/"+inUse.String(), nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
@@ -262,3 +262,103 @@ func (h *DNSHandler) DeleteRecord(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// ChangeRecordsRequest defines the payload for an atomic batch of record changes.
+type ChangeRecordsRequest struct {
+	Changes []domain.DNSRecordChange `json:"changes" binding:"required"`
+}
+
+// ChangeRecords applies a batch of record changes to a zone.
+// @Summary Apply a batch of DNS record changes
+// @Description Applies CREATE, UPSERT and DELETE changes atomically: if any change is rejected, none are applied.
+// @Tags dns
+// @Security APIKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Zone ID"
+// @Param request body ChangeRecordsRequest true "Change Records Request"
+// @Success 200 {array} domain.DNSRecord
+// @Failure 400,404,409,500 {object} httputil.Response
+// @Router /dns/zones/{id}/changes [post]
+func (h *DNSHandler) ChangeRecords(c *gin.Context) {
+	zoneID, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req ChangeRecordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httputil.Error(c, errs.New(errs.InvalidInput, errInvalidRequestBody))
+		return
+	}
+
+	records, err := h.svc.ChangeRecords(c.Request.Context(), *zoneID, req.Changes)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, records)
+}
+
+// ZoneFileRequest carries an RFC 1035 master file.
+type ZoneFileRequest struct {
+	ZoneFile string `json:"zone_file" binding:"required"`
+}
+
+// ImportZone loads a BIND zone file into a zone.
+// @Summary Import a BIND zone file
+// @Description Each name and type in the file replaces the zone's records of that name and type. SOA and apex NS records are ignored.
+// @Tags dns
+// @Security APIKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Zone ID"
+// @Param request body ZoneFileRequest true "Zone File"
+// @Success 200 {array} domain.DNSRecord
+// @Failure 400,404,409,500 {object} httputil.Response
+// @Router /dns/zones/{id}/import [post]
+func (h *DNSHandler) ImportZone(c *gin.Context) {
+	zoneID, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req ZoneFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httputil.Error(c, errs.New(errs.InvalidInput, errInvalidRequestBody))
+		return
+	}
+
+	records, err := h.svc.ImportZone(c.Request.Context(), *zoneID, req.ZoneFile)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, records)
+}
+
+// ExportZone renders a zone as a BIND zone file.
+// @Summary Export a zone as a BIND zone file
+// @Tags dns
+// @Security APIKeyAuth
+// @Produce json
+// @Param id path string true "Zone ID"
+// @Success 200 {object} ZoneFileRequest
+// @Failure 404,500 {object} httputil.Response
+// @Router /dns/zones/{id}/export [get]
+func (h *DNSHandler) ExportZone(c *gin.Context) {
+	zoneID, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	zoneFile, err := h.svc.ExportZone(c.Request.Context(), *zoneID)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, ZoneFileRequest{ZoneFile: zoneFile})
+}
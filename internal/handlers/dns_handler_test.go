@@ -275,3 +275,85 @@ func TestDeleteRecordHandler(t *testing.T) {
 		assert.Equal(t, http.StatusNoContent, w.Code)
 	})
 }
+
+func TestChangeRecordsHandler(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		svc := mocks.NewDNSService(t)
+		handler := NewDNSHandler(svc)
+		zoneID := uuid.New()
+		r := gin.New()
+		r.POST(zonesPath+"/:id/changes", handler.ChangeRecords)
+
+		changes := []domain.DNSRecordChange{
+			{Action: domain.DNSChangeUpsert, Name: "www", Type: domain.RecordTypeA, Content: testIPAddr},
+			{Action: domain.DNSChangeDelete, Name: "old", Type: domain.RecordTypeA},
+		}
+		body, _ := json.Marshal(map[string]interface{}{"changes": changes})
+
+		records := []*domain.DNSRecord{{ID: uuid.New(), Name: "www", Type: domain.RecordTypeA}}
+		svc.On("ChangeRecords", mock.Anything, zoneID, changes).Return(records, nil)
+
+		req, _ := http.NewRequest(http.MethodPost, zonesPath+"/"+zoneID.String()+"/changes", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("missing changes", func(t *testing.T) {
+		svc := mocks.NewDNSService(t)
+		handler := NewDNSHandler(svc)
+		r := gin.New()
+		r.POST(zonesPath+"/:id/changes", handler.ChangeRecords)
+
+		req, _ := http.NewRequest(http.MethodPost, zonesPath+"/"+uuid.New().String()+"/changes", bytes.NewBufferString(`{}`))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestImportZoneHandler(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	svc := mocks.NewDNSService(t)
+	handler := NewDNSHandler(svc)
+	zoneID := uuid.New()
+	r := gin.New()
+	r.POST(zonesPath+"/:id/import", handler.ImportZone)
+
+	zoneFile := "www IN A " + testIPAddr + "\n"
+	body, _ := json.Marshal(map[string]string{"zone_file": zoneFile})
+	svc.On("ImportZone", mock.Anything, zoneID, zoneFile).Return([]*domain.DNSRecord{}, nil)
+
+	req, _ := http.NewRequest(http.MethodPost, zonesPath+"/"+zoneID.String()+"/import", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestExportZoneHandler(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	svc := mocks.NewDNSService(t)
+	handler := NewDNSHandler(svc)
+	zoneID := uuid.New()
+	r := gin.New()
+	r.GET(zonesPath+"/:id/export", handler.ExportZone)
+
+	svc.On("ExportZone", mock.Anything, zoneID).Return("$ORIGIN example.com.\n", nil)
+
+	req, _ := http.NewRequest(http.MethodGet, zonesPath+"/"+zoneID.String()+"/export", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"zone_file":"$ORIGIN example.com.\n"`)
+}
@@ -0,0 +1,123 @@
+// Package httphandlers provides HTTP handlers for the API.
+package httphandlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/pkg/httputil"
+)
+
+// FlowLogHandler handles flow log HTTP endpoints.
+type FlowLogHandler struct {
+	svc ports.FlowLogService
+}
+
+// NewFlowLogHandler constructs a FlowLogHandler.
+func NewFlowLogHandler(svc ports.FlowLogService) *FlowLogHandler {
+	return &FlowLogHandler{svc: svc}
+}
+
+// Create starts capturing a resource's flows
+// @Summary Create a flow log
+// @Description Samples the accepted and/or rejected flows of a VPC, subnet or instance into CloudLogs, searchable at /logs with resource_type=vpc-flow-log
+// @Tags vpcs
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param request body object{resource_type=string,resource_id=string,traffic_type=string} true "Flow log"
+// @Success 201 {object} domain.FlowLog
+// @Failure 400 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Failure 409 {object} httputil.Response
+// @Router /flow-logs [post]
+func (h *FlowLogHandler) Create(c *gin.Context) {
+	var req struct {
+		ResourceType domain.FlowLogResourceType `json:"resource_type" binding:"required"`
+		ResourceID   uuid.UUID                  `json:"resource_id" binding:"required"`
+		TrafficType  domain.FlowLogTrafficType  `json:"traffic_type"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.TrafficType == "" {
+		req.TrafficType = domain.FlowTrafficAll
+	}
+
+	fl, err := h.svc.CreateFlowLog(c.Request.Context(), req.ResourceType, req.ResourceID, req.TrafficType)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusCreated, fl)
+}
+
+// List returns the caller's flow logs
+// @Summary List flow logs
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Success 200 {array} domain.FlowLog
+// @Router /flow-logs [get]
+func (h *FlowLogHandler) List(c *gin.Context) {
+	logs, err := h.svc.ListFlowLogs(c.Request.Context())
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, logs)
+}
+
+// Get returns a flow log
+// @Summary Get a flow log
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Flow log ID"
+// @Success 200 {object} domain.FlowLog
+// @Failure 404 {object} httputil.Response
+// @Router /flow-logs/{id} [get]
+func (h *FlowLogHandler) Get(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	fl, err := h.svc.GetFlowLog(c.Request.Context(), *id)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, fl)
+}
+
+// Delete stops a flow log
+// @Summary Delete a flow log
+// @Description Stops capturing; records already ingested stay in CloudLogs
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Flow log ID"
+// @Success 200 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Router /flow-logs/{id} [delete]
+func (h *FlowLogHandler) Delete(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteFlowLog(c.Request.Context(), *id); err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gin.H{"message": "flow log deleted"})
+}
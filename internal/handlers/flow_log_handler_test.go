@@ -0,0 +1,108 @@
+package httphandlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockFlowLogService struct {
+	mock.Mock
+}
+
+func (m *mockFlowLogService) CreateFlowLog(ctx context.Context, resourceType domain.FlowLogResourceType, resourceID uuid.UUID, trafficType domain.FlowLogTrafficType) (*domain.FlowLog, error) {
+	args := m.Called(ctx, resourceType, resourceID, trafficType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.FlowLog), args.Error(1)
+}
+
+func (m *mockFlowLogService) GetFlowLog(ctx context.Context, id uuid.UUID) (*domain.FlowLog, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.FlowLog), args.Error(1)
+}
+
+func (m *mockFlowLogService) ListFlowLogs(ctx context.Context) ([]*domain.FlowLog, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.FlowLog), args.Error(1)
+}
+
+func (m *mockFlowLogService) DeleteFlowLog(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockFlowLogService) CollectFlows(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func setupFlowLogHandlerTest() (*mockFlowLogService, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+	svc := new(mockFlowLogService)
+	handler := NewFlowLogHandler(svc)
+
+	r := gin.New()
+	r.POST("/flow-logs", handler.Create)
+	r.GET("/flow-logs", handler.List)
+	r.GET("/flow-logs/:id", handler.Get)
+	r.DELETE("/flow-logs/:id", handler.Delete)
+	return svc, r
+}
+
+func TestFlowLogHandlerCreate(t *testing.T) {
+	t.Parallel()
+	svc, r := setupFlowLogHandlerTest()
+	subnetID := uuid.New()
+	svc.On("CreateFlowLog", mock.Anything, domain.FlowLogSubnet, subnetID, domain.FlowTrafficAll).
+		Return(&domain.FlowLog{ID: uuid.New(), ResourceType: domain.FlowLogSubnet, TrafficType: domain.FlowTrafficAll}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/flow-logs", bytes.NewBufferString(`{"resource_type":"subnet","resource_id":"`+subnetID.String()+`"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"traffic_type":"all"`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/flow-logs", bytes.NewBufferString(`{"resource_type":"subnet"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFlowLogHandlerGetListDelete(t *testing.T) {
+	t.Parallel()
+	svc, r := setupFlowLogHandlerTest()
+	id := uuid.New()
+	svc.On("ListFlowLogs", mock.Anything).Return([]*domain.FlowLog{{ID: id}}, nil)
+	svc.On("GetFlowLog", mock.Anything, id).Return(nil, errors.New(errors.NotFound, "flow log not found"))
+	svc.On("DeleteFlowLog", mock.Anything, id).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/flow-logs", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/flow-logs/"+id.String(), nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/flow-logs/"+id.String(), nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
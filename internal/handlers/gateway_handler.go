@@ -7,6 +7,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/poyrazk/thecloud/internal/errors"
 	"github.com/poyrazk/thecloud/pkg/httputil"
@@ -14,13 +16,18 @@ import (
 
 // CreateRouteRequest define the payload for creating a route.
 type CreateRouteRequest struct {
-	Name        string   `json:"name" binding:"required"`
-	PathPrefix  string   `json:"path_prefix" binding:"required"`
-	TargetURL   string   `json:"target_url" binding:"required"`
-	Methods     []string `json:"methods"`
-	StripPrefix bool     `json:"strip_prefix"`
-	RateLimit   int      `json:"rate_limit"`
-	Priority    int      `json:"priority"`
+	Name          string                      `json:"name" binding:"required"`
+	PathPrefix    string                      `json:"path_prefix" binding:"required"`
+	TargetURL     string                      `json:"target_url"`
+	Upstreams     []domain.GatewayUpstream    `json:"upstreams"`
+	TrafficPolicy domain.GatewayTrafficPolicy `json:"traffic_policy"`
+	Methods       []string                    `json:"methods"`
+	StripPrefix   bool                        `json:"strip_prefix"`
+	RateLimit     int                         `json:"rate_limit"`
+	Priority      int                         `json:"priority"`
+	Plugins       domain.GatewayRoutePlugins  `json:"plugins"`
+	CertificateID *uuid.UUID                  `json:"certificate_id"`
+	HTTPSRedirect bool                        `json:"https_redirect"`
 }
 
 // GatewayHandler handles API gateway HTTP endpoints.
@@ -57,13 +64,18 @@ func (h *GatewayHandler) CreateRoute(c *gin.Context) {
 	}
 
 	params := ports.CreateRouteParams{
-		Name:        req.Name,
-		Pattern:     req.PathPrefix,
-		Target:      req.TargetURL,
-		Methods:     req.Methods,
-		StripPrefix: req.StripPrefix,
-		RateLimit:   req.RateLimit,
-		Priority:    req.Priority,
+		Name:          req.Name,
+		Pattern:       req.PathPrefix,
+		Target:        req.TargetURL,
+		Upstreams:     req.Upstreams,
+		TrafficPolicy: req.TrafficPolicy,
+		Methods:       req.Methods,
+		StripPrefix:   req.StripPrefix,
+		RateLimit:     req.RateLimit,
+		Priority:      req.Priority,
+		Plugins:       req.Plugins,
+		CertificateID: req.CertificateID,
+		HTTPSRedirect: req.HTTPSRedirect,
 	}
 
 	route, err := h.svc.CreateRoute(c.Request.Context(), params)
@@ -138,5 +150,12 @@ func (h *GatewayHandler) Proxy(c *gin.Context) {
 		}
 	}
 
-	proxy.ServeHTTP(c.Writer, c.Request)
+	// Route rate limits key on the client IP as resolved by the router's trusted
+	// proxies; function upstreams receive the matched path parameters.
+	ctx := appcontext.WithClientIP(c.Request.Context(), c.ClientIP())
+	if len(params) > 0 {
+		ctx = appcontext.WithRouteParams(ctx, params)
+	}
+	req := c.Request.WithContext(ctx)
+	proxy.ServeHTTP(c.Writer, req)
 }
@@ -3,6 +3,7 @@ package httphandlers
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
 	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/poyrazk/thecloud/internal/errors"
@@ -47,7 +49,7 @@ func (m *mockGatewayService) CreateRoute(ctx context.Context, params ports.Creat
 	return args.Get(0).(*domain.GatewayRoute), args.Error(1)
 }
 
-func (m *mockGatewayService) GetProxy(method, path string) (*httputil.ReverseProxy, map[string]string, bool) {
+func (m *mockGatewayService) GetProxy(method, path string) (http.Handler, map[string]string, bool) {
 	args := m.Called(method, path)
 	if args.Get(0) == nil {
 		return nil, nil, args.Bool(2)
@@ -56,7 +58,7 @@ func (m *mockGatewayService) GetProxy(method, path string) (*httputil.ReversePro
 	if p := args.Get(1); p != nil {
 		params = p.(map[string]string)
 	}
-	return args.Get(0).(*httputil.ReverseProxy), params, args.Bool(2)
+	return args.Get(0).(http.Handler), params, args.Bool(2)
 }
 
 func (m *mockGatewayService) ListRoutes(ctx context.Context) ([]*domain.GatewayRoute, error) {
@@ -77,6 +79,19 @@ func (m *mockGatewayService) RefreshRoutes(ctx context.Context) error {
 	return args.Error(0)
 }
 
+func (m *mockGatewayService) FlushAccessLogs(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *mockGatewayService) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	args := m.Called(hello)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tls.Certificate), args.Error(1)
+}
+
 func setupGatewayHandlerTest(_ *testing.T) (*mockGatewayService, *GatewayHandler, *gin.Engine) {
 	gin.SetMode(gin.TestMode)
 	svc := new(mockGatewayService)
@@ -119,6 +134,114 @@ func TestGatewayHandlerCreateRoute(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, w.Code)
 }
 
+func TestGatewayHandlerCreateRouteWithPlugins(t *testing.T) {
+	t.Parallel()
+	svc, handler, r := setupGatewayHandlerTest(t)
+	defer svc.AssertExpectations(t)
+
+	r.POST(routesPath, handler.CreateRoute)
+
+	svc.On("CreateRoute", mock.Anything, mock.MatchedBy(func(p ports.CreateRouteParams) bool {
+		return p.Plugins.Auth != nil && p.Plugins.Auth.Type == domain.GatewayAuthAPIKey &&
+			p.Plugins.CORS != nil && p.Plugins.MaxBodyBytes == 2048
+	})).Return(&domain.GatewayRoute{ID: uuid.New(), Name: testRouteName}, nil)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"name":        testRouteName,
+		"path_prefix": "/api/v1",
+		"target_url":  "http://example.com",
+		"plugins": map[string]interface{}{
+			"auth":           map[string]interface{}{"type": "api_key", "api_keys": []string{"secret"}},
+			"cors":           map[string]interface{}{"allow_origins": []string{"*"}},
+			"max_body_bytes": 2048,
+		},
+	})
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", routesPath, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestGatewayHandlerCreateRouteWithUpstreams(t *testing.T) {
+	t.Parallel()
+	svc, handler, r := setupGatewayHandlerTest(t)
+	defer svc.AssertExpectations(t)
+
+	r.POST(routesPath, handler.CreateRoute)
+
+	lbID := uuid.New()
+	svc.On("CreateRoute", mock.Anything, mock.MatchedBy(func(p ports.CreateRouteParams) bool {
+		return p.Target == "" && len(p.Upstreams) == 2 &&
+			p.Upstreams[1].Type == domain.GatewayUpstreamLB && *p.Upstreams[1].TargetID == lbID &&
+			p.TrafficPolicy.Retries == 2 && p.TrafficPolicy.TimeoutMs == 500
+	})).Return(&domain.GatewayRoute{ID: uuid.New(), Name: testRouteName}, nil)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"name":        testRouteName,
+		"path_prefix": "/api/v1",
+		"upstreams": []map[string]interface{}{
+			{"type": "url", "url": "http://stable:8080", "weight": 90},
+			{"type": "lb", "target_id": lbID, "weight": 10},
+		},
+		"traffic_policy": map[string]interface{}{"retries": 2, "timeout_ms": 500},
+	})
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", routesPath, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestGatewayHandlerProxyPassesClientIP(t *testing.T) {
+	t.Parallel()
+	svc, handler, r := setupGatewayHandlerTest(t)
+	defer svc.AssertExpectations(t)
+
+	r.Any(gwProxyPath, handler.Proxy)
+
+	var seen string
+	svc.On("GetProxy", "GET", "/api").Return(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		seen = appcontext.ClientIPFromContext(req.Context())
+		w.WriteHeader(http.StatusOK)
+	}), map[string]string{}, true)
+
+	req, err := http.NewRequest(http.MethodGet, gwAPITestPath, nil)
+	assert.NoError(t, err)
+	req.RemoteAddr = "203.0.113.7:4321"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "203.0.113.7", seen)
+}
+
+func TestGatewayHandlerProxyPassesRouteParams(t *testing.T) {
+	t.Parallel()
+	svc, handler, r := setupGatewayHandlerTest(t)
+	defer svc.AssertExpectations(t)
+
+	r.Any(gwProxyPath, handler.Proxy)
+
+	var seen map[string]string
+	svc.On("GetProxy", "GET", "/api").Return(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		seen = appcontext.RouteParamsFromContext(req.Context())
+		w.WriteHeader(http.StatusOK)
+	}), map[string]string{"id": "42"}, true)
+
+	req, err := http.NewRequest(http.MethodGet, gwAPITestPath, nil)
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, map[string]string{"id": "42"}, seen)
+}
+
 func TestGatewayHandlerListRoutes(t *testing.T) {
 	t.Parallel()
 	svc, handler, r := setupGatewayHandlerTest(t)
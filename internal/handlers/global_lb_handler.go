@@ -2,6 +2,8 @@
 package httphandlers
 
 import (
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	appcontext "github.com/poyrazk/thecloud/internal/core/context"
@@ -221,6 +223,43 @@ func (h *GlobalLBHandler) RemoveEndpoint(c *gin.Context) {
 	httputil.Success(c, 204, nil)
 }
 
+// GetEndpointHealth handles GET /global-lb/:id/endpoints/:epID/health
+// @Summary Get an endpoint's health check history
+// @Tags global-lb
+// @Security APIKeyAuth
+// @Produce json
+// @Param id path string true "Global LB ID"
+// @Param epID path string true "Endpoint ID"
+// @Param limit query int false "Maximum number of results (default 20, max 100)"
+// @Success 200 {array} domain.GlobalEndpointHealthCheck
+// @Failure 400 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Failure 500 {object} httputil.Response
+// @Router /global-lb/{id}/endpoints/{epID}/health [get]
+func (h *GlobalLBHandler) GetEndpointHealth(c *gin.Context) {
+	glbID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		httputil.Error(c, errors.New(errors.InvalidInput, "invalid global lb id"))
+		return
+	}
+
+	epID, err := uuid.Parse(c.Param("epID"))
+	if err != nil {
+		httputil.Error(c, errors.New(errors.InvalidInput, "invalid endpoint id"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	checks, err := h.svc.GetEndpointHealth(c.Request.Context(), glbID, epID, limit)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, 200, checks)
+}
+
 func (h *GlobalLBHandler) RegisterRoutes(router *gin.RouterGroup) {
 	group := router.Group("/global-lb")
 	{
@@ -231,5 +270,6 @@ func (h *GlobalLBHandler) RegisterRoutes(router *gin.RouterGroup) {
 
 		group.POST("/:id/endpoints", h.AddEndpoint)
 		group.DELETE("/:id/endpoints/:epID", h.RemoveEndpoint)
+		group.GET("/:id/endpoints/:epID/health", h.GetEndpointHealth)
 	}
 }
@@ -65,6 +65,14 @@ func (m *mockGlobalLBService) ListEndpoints(ctx context.Context, glbID uuid.UUID
 	return args.Get(0).([]*domain.GlobalEndpoint), args.Error(1)
 }
 
+func (m *mockGlobalLBService) GetEndpointHealth(ctx context.Context, glbID, endpointID uuid.UUID, limit int) ([]*domain.GlobalEndpointHealthCheck, error) {
+	args := m.Called(ctx, glbID, endpointID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.GlobalEndpointHealthCheck), args.Error(1)
+}
+
 // TestGlobalLBHandlerCreate verifies the behavior of the Create endpoint for Global Load Balancers.
 func TestGlobalLBHandlerCreate(t *testing.T) {
 	t.Parallel()
@@ -110,3 +118,41 @@ func TestGlobalLBHandlerCreate(t *testing.T) {
 		assert.Equal(t, glb.ID, resp.Data.ID)
 	})
 }
+
+func TestGlobalLBHandlerGetEndpointHealth(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		svc := new(mockGlobalLBService)
+		handler := NewGlobalLBHandler(svc)
+		glbID, epID := uuid.New(), uuid.New()
+
+		checks := []*domain.GlobalEndpointHealthCheck{{ID: uuid.New(), EndpointID: epID, GlobalLBID: glbID, Healthy: true}}
+		svc.On("GetEndpointHealth", mock.Anything, glbID, epID, 5).Return(checks, nil)
+
+		r := gin.New()
+		r.GET("/global-lb/:id/endpoints/:epID/health", handler.GetEndpointHealth)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/global-lb/"+glbID.String()+"/endpoints/"+epID.String()+"/health?limit=5", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data []domain.GlobalEndpointHealthCheck `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Len(t, resp.Data, 1)
+	})
+
+	t.Run("invalid endpoint id", func(t *testing.T) {
+		svc := new(mockGlobalLBService)
+		handler := NewGlobalLBHandler(svc)
+
+		r := gin.New()
+		r.GET("/global-lb/:id/endpoints/:epID/health", handler.GetEndpointHealth)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/global-lb/"+uuid.New().String()+"/endpoints/bad/health", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
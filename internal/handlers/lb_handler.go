@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/poyrazk/thecloud/internal/errors"
 	"github.com/poyrazk/thecloud/pkg/httputil"
@@ -36,6 +37,30 @@ type AddTargetRequest struct {
 	InstanceID string `json:"instance_id" binding:"required"`
 	Port       int    `json:"port" binding:"required"`
 	Weight     int    `json:"weight"`
+	// TargetGroup names the group listener rules route to; empty is "default".
+	TargetGroup string `json:"target_group"`
+}
+
+// ConfigureTLSRequest is the payload for a load balancer's HTTPS listener.
+type ConfigureTLSRequest struct {
+	Port           int         `json:"port" binding:"required"`
+	CertificateIDs []uuid.UUID `json:"certificate_ids" binding:"required"`
+	RedirectHTTP   bool        `json:"redirect_http"`
+}
+
+// ConfigureListenersRequest is the payload for a load balancer's layer-7
+// listeners. An empty list restores the single default listener.
+type ConfigureListenersRequest struct {
+	Listeners []domain.LBListener `json:"listeners"`
+}
+
+// UpdateLBAttributesRequest is the payload for a load balancer's target
+// policies. Omitting health_check selects the TCP port check and omitting
+// sticky_sessions disables session affinity.
+type UpdateLBAttributesRequest struct {
+	HealthCheck                *domain.HealthCheckConfig `json:"health_check"`
+	DeregistrationDelaySeconds int                       `json:"deregistration_delay_seconds"`
+	StickySessions             *domain.LBStickySessions  `json:"sticky_sessions"`
 }
 
 // Create creates a load balancer
@@ -172,7 +197,12 @@ func (h *LBHandler) AddTarget(c *gin.Context) {
 		return
 	}
 
-	if err := h.svc.AddTarget(c.Request.Context(), lbID, instID, req.Port, req.Weight); err != nil {
+	if req.TargetGroup != "" {
+		err = h.svc.AddTargetToGroup(c.Request.Context(), lbID, instID, req.Port, req.Weight, req.TargetGroup)
+	} else {
+		err = h.svc.AddTarget(c.Request.Context(), lbID, instID, req.Port, req.Weight)
+	}
+	if err != nil {
 		httputil.Error(c, err)
 		return
 	}
@@ -188,6 +218,7 @@ func (h *LBHandler) AddTarget(c *gin.Context) {
 // @Security APIKeyAuth
 // @Param id path string true "LB ID"
 // @Param instanceId path string true "Instance ID"
+// @Param target_group query string false "Target group to remove the instance from (default \"default\")"
 // @Success 200 {object} httputil.Response
 // @Failure 404 {object} httputil.Response
 // @Router /lb/{id}/targets/{instanceId} [delete]
@@ -206,7 +237,12 @@ func (h *LBHandler) RemoveTarget(c *gin.Context) {
 		return
 	}
 
-	if err := h.svc.RemoveTarget(c.Request.Context(), lbID, instID); err != nil {
+	if group := c.Query("target_group"); group != "" {
+		err = h.svc.RemoveTargetFromGroup(c.Request.Context(), lbID, instID, group)
+	} else {
+		err = h.svc.RemoveTarget(c.Request.Context(), lbID, instID)
+	}
+	if err != nil {
 		httputil.Error(c, err)
 		return
 	}
@@ -238,3 +274,113 @@ func (h *LBHandler) ListTargets(c *gin.Context) {
 	}
 	httputil.Success(c, http.StatusOK, targets)
 }
+
+// ConfigureTLS enables or replaces a load balancer's HTTPS listener
+// @Summary Configure the HTTPS listener
+// @Description Terminates TLS on the given port with the given certificates, selected by SNI. The proxy is redeployed.
+// @Tags loadbalancers
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "LB ID"
+// @Param request body ConfigureTLSRequest true "HTTPS listener"
+// @Success 202 {object} domain.LoadBalancer
+// @Failure 400 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Router /lb/{id}/tls [put]
+func (h *LBHandler) ConfigureTLS(c *gin.Context) {
+	var req ConfigureTLSRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httputil.Error(c, errors.New(errors.InvalidInput, "invalid request body"))
+		return
+	}
+
+	lb, err := h.svc.ConfigureTLS(c.Request.Context(), c.Param("id"), &domain.LBTLSConfig{
+		Port:           req.Port,
+		CertificateIDs: req.CertificateIDs,
+		RedirectHTTP:   req.RedirectHTTP,
+	})
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+	httputil.Success(c, http.StatusAccepted, lb)
+}
+
+// DisableTLS removes a load balancer's HTTPS listener
+// @Summary Disable the HTTPS listener
+// @Tags loadbalancers
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "LB ID"
+// @Success 202 {object} domain.LoadBalancer
+// @Failure 404 {object} httputil.Response
+// @Router /lb/{id}/tls [delete]
+func (h *LBHandler) DisableTLS(c *gin.Context) {
+	lb, err := h.svc.ConfigureTLS(c.Request.Context(), c.Param("id"), nil)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+	httputil.Success(c, http.StatusAccepted, lb)
+}
+
+// ConfigureListeners replaces a load balancer's layer-7 listeners
+// @Summary Configure listeners and routing rules
+// @Description Replaces the HTTP listeners and the host, path and header rules that route requests to target groups. The proxy is redeployed.
+// @Tags loadbalancers
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "LB ID"
+// @Param request body ConfigureListenersRequest true "Listeners"
+// @Success 202 {object} domain.LoadBalancer
+// @Failure 400 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Router /lb/{id}/listeners [put]
+func (h *LBHandler) ConfigureListeners(c *gin.Context) {
+	var req ConfigureListenersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httputil.Error(c, errors.New(errors.InvalidInput, "invalid request body"))
+		return
+	}
+
+	lb, err := h.svc.ConfigureListeners(c.Request.Context(), c.Param("id"), req.Listeners)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+	httputil.Success(c, http.StatusAccepted, lb)
+}
+
+// ConfigureAttributes replaces a load balancer's target policies
+// @Summary Configure health checks, draining and sticky sessions
+// @Description Replaces the target health check, the deregistration delay used to drain removed targets and cookie-based sticky sessions.
+// @Tags loadbalancers
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "LB ID"
+// @Param request body UpdateLBAttributesRequest true "Attributes"
+// @Success 200 {object} domain.LoadBalancer
+// @Failure 400 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Router /lb/{id}/attributes [put]
+func (h *LBHandler) ConfigureAttributes(c *gin.Context) {
+	var req UpdateLBAttributesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httputil.Error(c, errors.New(errors.InvalidInput, "invalid request body"))
+		return
+	}
+
+	lb, err := h.svc.ConfigureAttributes(c.Request.Context(), c.Param("id"), ports.UpdateLBAttributesParams{
+		HealthCheck:                req.HealthCheck,
+		DeregistrationDelaySeconds: req.DeregistrationDelaySeconds,
+		StickySessions:             req.StickySessions,
+	})
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+	httputil.Success(c, http.StatusOK, lb)
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -51,6 +53,34 @@ func (m *mockLBService) Delete(ctx context.Context, idOrName string) error {
 	return args.Error(0)
 }
 
+func (m *mockLBService) ConfigureTLS(ctx context.Context, idOrName string, cfg *domain.LBTLSConfig) (*domain.LoadBalancer, error) {
+	args := m.Called(ctx, idOrName, cfg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoadBalancer), args.Error(1)
+}
+
+func (m *mockLBService) ConfigureAttributes(ctx context.Context, idOrName string, params ports.UpdateLBAttributesParams) (*domain.LoadBalancer, error) {
+	args := m.Called(ctx, idOrName, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoadBalancer), args.Error(1)
+}
+func (m *mockLBService) ConfigureListeners(ctx context.Context, idOrName string, listeners []domain.LBListener) (*domain.LoadBalancer, error) {
+	args := m.Called(ctx, idOrName, listeners)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoadBalancer), args.Error(1)
+}
+
+func (m *mockLBService) AddTargetToGroup(ctx context.Context, lbID, instanceID uuid.UUID, port, weight int, group string) error {
+	args := m.Called(ctx, lbID, instanceID, port, weight, group)
+	return args.Error(0)
+}
+
 func (m *mockLBService) AddTarget(ctx context.Context, lbID, instanceID uuid.UUID, port, weight int) error {
 	args := m.Called(ctx, lbID, instanceID, port, weight)
 	return args.Error(0)
@@ -61,6 +91,11 @@ func (m *mockLBService) RemoveTarget(ctx context.Context, lbID, instanceID uuid.
 	return args.Error(0)
 }
 
+func (m *mockLBService) RemoveTargetFromGroup(ctx context.Context, lbID, instanceID uuid.UUID, group string) error {
+	args := m.Called(ctx, lbID, instanceID, group)
+	return args.Error(0)
+}
+
 func (m *mockLBService) ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain.LBTarget, error) {
 	args := m.Called(ctx, lbID)
 	return args.Get(0).([]*domain.LBTarget), args.Error(1)
@@ -190,6 +225,7 @@ func TestLBHandlerRemoveTarget(t *testing.T) {
 	lbID := uuid.New()
 	instID := uuid.New()
 	svc.On("RemoveTarget", mock.Anything, lbID, instID).Return(nil)
+	svc.On("RemoveTargetFromGroup", mock.Anything, lbID, instID, "api").Return(nil)
 
 	req := httptest.NewRequest(http.MethodDelete, lbPath+"/"+lbID.String()+"/targets/"+instID.String(), nil)
 	w := httptest.NewRecorder()
@@ -197,6 +233,13 @@ func TestLBHandlerRemoveTarget(t *testing.T) {
 	r.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, lbPath+"/"+lbID.String()+"/targets/"+instID.String()+"?target_group=api", nil)
+	w = httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
 }
 
 func TestLBHandlerListTargets(t *testing.T) {
@@ -217,3 +260,118 @@ func TestLBHandlerListTargets(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+func TestLBHandlerConfigureTLS(t *testing.T) {
+	t.Parallel()
+	svc, handler, r := setupLBHandlerTest(t)
+	defer svc.AssertExpectations(t)
+
+	r.PUT(lbPath+"/:id/tls", handler.ConfigureTLS)
+	r.DELETE(lbPath+"/:id/tls", handler.DisableTLS)
+
+	id := uuid.New().String()
+	certID := uuid.New()
+	cfg := &domain.LBTLSConfig{Port: 443, CertificateIDs: []uuid.UUID{certID}, RedirectHTTP: true}
+	svc.On("ConfigureTLS", mock.Anything, id, cfg).Return(&domain.LoadBalancer{Name: testLBName, TLS: cfg}, nil)
+	svc.On("ConfigureTLS", mock.Anything, id, (*domain.LBTLSConfig)(nil)).Return(&domain.LoadBalancer{Name: testLBName}, nil)
+
+	body, err := json.Marshal(map[string]interface{}{"port": 443, "certificate_ids": []string{certID.String()}, "redirect_http": true})
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, lbPath+"/"+id+"/tls", bytes.NewBuffer(body)))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, lbPath+"/"+id+"/tls", bytes.NewBufferString(`{"port":443}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, lbPath+"/"+id+"/tls", nil))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+}
+
+func TestLBHandlerAddTargetToGroup(t *testing.T) {
+	t.Parallel()
+	svc, handler, r := setupLBHandlerTest(t)
+	defer svc.AssertExpectations(t)
+
+	r.POST(lbPath+"/:id/targets", handler.AddTarget)
+
+	lbID := uuid.New()
+	instID := uuid.New()
+	svc.On("AddTargetToGroup", mock.Anything, lbID, instID, 8080, 0, "api").Return(nil)
+
+	body, err := json.Marshal(map[string]interface{}{"instance_id": instID.String(), "port": 8080, "target_group": "api"})
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, lbPath+"/"+lbID.String()+"/targets", bytes.NewBuffer(body)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestLBHandlerConfigureListeners(t *testing.T) {
+	t.Parallel()
+	svc, handler, r := setupLBHandlerTest(t)
+	defer svc.AssertExpectations(t)
+
+	r.PUT(lbPath+"/:id/listeners", handler.ConfigureListeners)
+
+	id := uuid.New().String()
+	listeners := []domain.LBListener{{
+		Port: 80,
+		Rules: []domain.LBListenerRule{{
+			Priority: 1, Host: "api.example.com", Header: &domain.LBHeaderMatch{Name: "X-Canary", Value: "1"}, TargetGroup: "canary",
+		}},
+	}}
+	svc.On("ConfigureListeners", mock.Anything, id, listeners).Return(&domain.LoadBalancer{Name: testLBName, Listeners: listeners}, nil)
+	svc.On("ConfigureListeners", mock.Anything, "bad", []domain.LBListener{{Port: 0}}).
+		Return(nil, errors.New(errors.InvalidInput, "listener port 0 must be between 1 and 65535"))
+
+	body, err := json.Marshal(map[string]interface{}{"listeners": listeners})
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, lbPath+"/"+id+"/listeners", bytes.NewBuffer(body)))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"target_group":"canary"`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, lbPath+"/bad/listeners", bytes.NewBufferString(`{"listeners":[{"port":0}]}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, lbPath+"/"+id+"/listeners", bytes.NewBufferString(`{"listeners":"x"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestLBHandlerConfigureAttributes(t *testing.T) {
+	t.Parallel()
+	svc, handler, r := setupLBHandlerTest(t)
+	defer svc.AssertExpectations(t)
+
+	r.PUT(lbPath+"/:id/attributes", handler.ConfigureAttributes)
+
+	id := uuid.New().String()
+	params := ports.UpdateLBAttributesParams{
+		HealthCheck:                &domain.HealthCheckConfig{Path: "/healthz", HealthyThreshold: 3},
+		DeregistrationDelaySeconds: 30,
+		StickySessions:             &domain.LBStickySessions{CookieName: "SESSION"},
+	}
+	svc.On("ConfigureAttributes", mock.Anything, id, params).Return(&domain.LoadBalancer{
+		Name: testLBName, HealthCheck: params.HealthCheck, DeregistrationDelaySeconds: 30, StickySessions: params.StickySessions,
+	}, nil)
+	svc.On("ConfigureAttributes", mock.Anything, "bad", ports.UpdateLBAttributesParams{DeregistrationDelaySeconds: -1}).
+		Return(nil, errors.New(errors.InvalidInput, "deregistration delay must be between 0 and 3600 seconds"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, lbPath+"/"+id+"/attributes", bytes.NewBufferString(
+		`{"health_check":{"path":"/healthz","healthy_threshold":3},"deregistration_delay_seconds":30,"sticky_sessions":{"cookie_name":"SESSION"}}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"deregistration_delay_seconds":30`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, lbPath+"/bad/attributes", bytes.NewBufferString(`{"deregistration_delay_seconds":-1}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, lbPath+"/"+id+"/attributes", bytes.NewBufferString(`{"health_check":"x"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
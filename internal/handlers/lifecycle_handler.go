@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/poyrazk/thecloud/internal/errors"
 	"github.com/poyrazk/thecloud/pkg/httputil"
@@ -22,7 +23,7 @@ func NewLifecycleHandler(svc ports.LifecycleService) *LifecycleHandler {
 
 // CreateRule adds a new lifecycle rule to the bucket
 // @Summary Create lifecycle rule
-// @Description Adds an expiration rule for objects with a specific prefix
+// @Description Adds a rule that expires, transitions or cleans up objects matching a prefix and tags
 // @Tags lifecycle
 // @Accept json
 // @Produce json
@@ -35,35 +36,44 @@ func NewLifecycleHandler(svc ports.LifecycleService) *LifecycleHandler {
 func (h *LifecycleHandler) CreateRule(c *gin.Context) {
 	bucket := c.Param("bucket")
 	var req struct {
-		Prefix         string `json:"prefix"`
-		ExpirationDays int    `json:"expiration_days" binding:"required"`
-		Enabled        bool   `json:"enabled"`
+		Prefix                       string              `json:"prefix"`
+		Tags                         map[string]string   `json:"tags"`
+		ExpirationDays               int                 `json:"expiration_days"`
+		NoncurrentExpirationDays     int                 `json:"noncurrent_expiration_days"`
+		NoncurrentVersionsToKeep     int                 `json:"noncurrent_versions_to_keep"`
+		AbortIncompleteMultipartDays int                 `json:"abort_incomplete_multipart_days"`
+		TransitionDays               int                 `json:"transition_days"`
+		TransitionStorageClass       domain.StorageClass `json:"transition_storage_class"`
+		Enabled                      bool                `json:"enabled"`
 	}
-	// Default enabled to true if not provided is tricky with bool unmarshal (defaults to false).
-	// Let's assume user must specify or we handle logic.
-	// Actually struct tag `default:"true"` isn't standard in gin binding without validator.
-	// Logic: default Enabled to true?
-	// The problem is `false` is zero value.
-	// Let's use pointer for Enabled or just default logic.
-
 	if err := c.ShouldBindJSON(&req); err != nil {
 		httputil.Error(c, errors.New(errors.InvalidInput, "invalid request body"))
 		return
 	}
 
-	// Default Enable to true? No, let's respect the input. If they send nothing, it's false.
-	// Users usually want to enable it immediately.
-	// But let's assume if they don't provide it, it's false (safe).
-	// Actually implementation plans said "Enabled: true" default in SQL.
-	// I'll stick to input.
+	rule := &domain.LifecycleRule{
+		Prefix:                       req.Prefix,
+		Tags:                         req.Tags,
+		ExpirationDays:               req.ExpirationDays,
+		NoncurrentExpirationDays:     req.NoncurrentExpirationDays,
+		NoncurrentVersionsToKeep:     req.NoncurrentVersionsToKeep,
+		AbortIncompleteMultipartDays: req.AbortIncompleteMultipartDays,
+		TransitionDays:               req.TransitionDays,
+		TransitionStorageClass:       req.TransitionStorageClass,
+		Enabled:                      req.Enabled,
+	}
+	if err := rule.Validate(); err != nil {
+		httputil.Error(c, errors.New(errors.InvalidInput, err.Error()))
+		return
+	}
 
-	rule, err := h.svc.CreateRule(c.Request.Context(), bucket, req.Prefix, req.ExpirationDays, req.Enabled)
+	created, err := h.svc.CreateRule(c.Request.Context(), bucket, rule)
 	if err != nil {
 		httputil.Error(c, err)
 		return
 	}
 
-	httputil.Success(c, http.StatusCreated, rule)
+	httputil.Success(c, http.StatusCreated, created)
 }
 
 // ListRules lists all lifecycle rules for a bucket
@@ -106,3 +116,24 @@ func (h *LifecycleHandler) DeleteRule(c *gin.Context) {
 
 	httputil.Success(c, http.StatusNoContent, nil)
 }
+
+// PreviewRule reports what a lifecycle rule would do if it ran now
+// @Summary Preview lifecycle rule
+// @Description Dry run: lists the objects a rule would expire or transition and the uploads it would abort, without changing anything
+// @Tags lifecycle
+// @Produce json
+// @Security APIKeyAuth
+// @Param bucket path string true "Bucket name"
+// @Param id path string true "Rule ID"
+// @Success 200 {object} domain.LifecycleReport
+// @Failure 400 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Router /storage/buckets/{bucket}/lifecycle/{id}/preview [get]
+func (h *LifecycleHandler) PreviewRule(c *gin.Context) {
+	report, err := h.svc.PreviewRule(c.Request.Context(), c.Param("bucket"), c.Param("id"))
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+	httputil.Success(c, http.StatusOK, report)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -20,8 +21,8 @@ type mockLifecycleService struct {
 	mock.Mock
 }
 
-func (m *mockLifecycleService) CreateRule(ctx context.Context, bucket string, prefix string, expirationDays int, enabled bool) (*domain.LifecycleRule, error) {
-	args := m.Called(ctx, bucket, prefix, expirationDays, enabled)
+func (m *mockLifecycleService) CreateRule(ctx context.Context, bucket string, rule *domain.LifecycleRule) (*domain.LifecycleRule, error) {
+	args := m.Called(ctx, bucket, rule)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -41,6 +42,22 @@ func (m *mockLifecycleService) DeleteRule(ctx context.Context, bucket string, ru
 	return args.Error(0)
 }
 
+func (m *mockLifecycleService) PreviewRule(ctx context.Context, bucket string, ruleID string) (*domain.LifecycleReport, error) {
+	args := m.Called(ctx, bucket, ruleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LifecycleReport), args.Error(1)
+}
+
+func (m *mockLifecycleService) EvaluateRule(ctx context.Context, rule *domain.LifecycleRule, now time.Time) (*domain.LifecycleReport, error) {
+	args := m.Called(ctx, rule, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LifecycleReport), args.Error(1)
+}
+
 const (
 	testBucketName    = "test-bucket"
 	testLifecyclePath = "/storage/buckets/test-bucket/lifecycle"
@@ -78,10 +95,39 @@ func TestLifecycleHandlerCreateRule(t *testing.T) {
 			},
 			setupMock: func(m *mockLifecycleService) {
 				rule := &domain.LifecycleRule{ID: uuid.New(), BucketName: testBucketName, Prefix: testPrefix, ExpirationDays: 30, Enabled: true}
-				m.On("CreateRule", mock.Anything, testBucketName, testPrefix, 30, true).Return(rule, nil).Once()
+				m.On("CreateRule", mock.Anything, testBucketName, mock.MatchedBy(func(r *domain.LifecycleRule) bool {
+					return r.Prefix == testPrefix && r.ExpirationDays == 30 && r.Enabled
+				})).Return(rule, nil).Once()
 			},
 			expectedStatus: http.StatusCreated,
 		},
+		{
+			name: "Noncurrent And Multipart Actions",
+			body: map[string]interface{}{
+				"prefix":                          testPrefix,
+				"tags":                            map[string]string{"tier": "scratch"},
+				"noncurrent_versions_to_keep":     3,
+				"abort_incomplete_multipart_days": 7,
+				"enabled":                         true,
+			},
+			setupMock: func(m *mockLifecycleService) {
+				m.On("CreateRule", mock.Anything, testBucketName, mock.MatchedBy(func(r *domain.LifecycleRule) bool {
+					return r.NoncurrentVersionsToKeep == 3 && r.AbortIncompleteMultipartDays == 7 && r.Tags["tier"] == "scratch"
+				})).Return(&domain.LifecycleRule{ID: uuid.New()}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "Invalid Transition Class",
+			body: map[string]interface{}{
+				"transition_days":          30,
+				"transition_storage_class": "STANDARD",
+			},
+			setupMock: func(m *mockLifecycleService) {
+				// No calls expected
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name: "Invalid Input",
 			body: map[string]interface{}{},
@@ -98,7 +144,7 @@ func TestLifecycleHandlerCreateRule(t *testing.T) {
 				"enabled":         true,
 			},
 			setupMock: func(m *mockLifecycleService) {
-				m.On("CreateRule", mock.Anything, testBucketName, testPrefix, 30, true).Return(nil, errors.New(errors.Internal, "error")).Once()
+				m.On("CreateRule", mock.Anything, testBucketName, mock.Anything).Return(nil, errors.New(errors.Internal, "error")).Once()
 			},
 			expectedStatus: http.StatusInternalServerError,
 		},
@@ -174,3 +220,35 @@ func TestLifecycleHandlerDeleteRule(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 }
+
+func TestLifecycleHandlerPreviewRule(t *testing.T) {
+	t.Parallel()
+	svc, handler, r := setupLifecycleHandlerTest(t)
+	r.GET("/storage/buckets/:bucket/lifecycle/:id/preview", handler.PreviewRule)
+
+	ruleID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		report := &domain.LifecycleReport{RuleID: ruleID, Bucket: testBucketName}
+		report.Add(domain.LifecycleAction{Type: domain.LifecycleActionExpire, Key: "logs/old.log", SizeBytes: 42})
+		svc.On("PreviewRule", mock.Anything, testBucketName, ruleID.String()).Return(report, nil).Once()
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", testLifecyclePath+"/"+ruleID.String()+"/preview", nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"objects_expired":1`)
+		assert.Contains(t, w.Body.String(), `"bytes_expired":42`)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		svc.On("PreviewRule", mock.Anything, testBucketName, ruleID.String()).Return(nil, errors.New(errors.Forbidden, "you don't own this bucket")).Once()
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", testLifecyclePath+"/"+ruleID.String()+"/preview", nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,263 @@
+// Package httphandlers provides HTTP handlers for the API.
+package httphandlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/pkg/httputil"
+)
+
+// NetworkACLHandler handles network ACL HTTP endpoints.
+type NetworkACLHandler struct {
+	svc ports.NetworkACLService
+}
+
+// NewNetworkACLHandler constructs a NetworkACLHandler.
+func NewNetworkACLHandler(svc ports.NetworkACLService) *NetworkACLHandler {
+	return &NetworkACLHandler{svc: svc}
+}
+
+// Create adds a network ACL to a VPC
+// @Summary Create a network ACL
+// @Description Creates an empty network ACL in a VPC. It filters nothing until associated with a subnet.
+// @Tags vpcs
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "VPC ID"
+// @Param request body object{name=string} true "Network ACL"
+// @Success 201 {object} domain.NetworkACL
+// @Failure 400 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Router /vpcs/{id}/network-acls [post]
+func (h *NetworkACLHandler) Create(c *gin.Context) {
+	vpcID, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	acl, err := h.svc.CreateACL(c.Request.Context(), *vpcID, req.Name)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusCreated, acl)
+}
+
+// List returns a VPC's network ACLs
+// @Summary List network ACLs
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "VPC ID"
+// @Success 200 {array} domain.NetworkACL
+// @Failure 404 {object} httputil.Response
+// @Router /vpcs/{id}/network-acls [get]
+func (h *NetworkACLHandler) List(c *gin.Context) {
+	vpcID, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	acls, err := h.svc.ListACLs(c.Request.Context(), *vpcID)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, acls)
+}
+
+// Get returns a network ACL with its rules and subnets
+// @Summary Get a network ACL
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Network ACL ID"
+// @Success 200 {object} domain.NetworkACL
+// @Failure 404 {object} httputil.Response
+// @Router /network-acls/{id} [get]
+func (h *NetworkACLHandler) Get(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	acl, err := h.svc.GetACL(c.Request.Context(), *id)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, acl)
+}
+
+// Delete removes a network ACL
+// @Summary Delete a network ACL
+// @Description Deletes a network ACL that is not associated with any subnet
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Network ACL ID"
+// @Success 200 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Failure 409 {object} httputil.Response
+// @Router /network-acls/{id} [delete]
+func (h *NetworkACLHandler) Delete(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteACL(c.Request.Context(), *id); err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gin.H{"message": "network ACL deleted"})
+}
+
+// AddRule adds a numbered rule to a network ACL
+// @Summary Add a network ACL rule
+// @Description Adds a stateless allow or deny rule. Rules are evaluated in ascending number order per direction.
+// @Tags vpcs
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Network ACL ID"
+// @Param request body object{rule_number=int,direction=string,protocol=string,port_min=int,port_max=int,cidr=string,action=string} true "Rule"
+// @Success 201 {object} domain.NetworkACLRule
+// @Failure 400 {object} httputil.Response
+// @Failure 409 {object} httputil.Response
+// @Router /network-acls/{id}/rules [post]
+func (h *NetworkACLHandler) AddRule(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		RuleNumber int                  `json:"rule_number" binding:"required"`
+		Direction  domain.RuleDirection `json:"direction" binding:"required"`
+		Protocol   string               `json:"protocol" binding:"required"`
+		PortMin    int                  `json:"port_min"`
+		PortMax    int                  `json:"port_max"`
+		CIDR       string               `json:"cidr" binding:"required"`
+		Action     domain.ACLAction     `json:"action" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.svc.AddRule(c.Request.Context(), *id, domain.NetworkACLRule{
+		RuleNumber: req.RuleNumber,
+		Direction:  req.Direction,
+		Protocol:   req.Protocol,
+		PortMin:    req.PortMin,
+		PortMax:    req.PortMax,
+		CIDR:       req.CIDR,
+		Action:     req.Action,
+	})
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusCreated, rule)
+}
+
+// RemoveRule deletes a rule from a network ACL
+// @Summary Remove a network ACL rule
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Network ACL ID"
+// @Param rule_id path string true "Rule ID"
+// @Success 200 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Router /network-acls/{id}/rules/{rule_id} [delete]
+func (h *NetworkACLHandler) RemoveRule(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+	ruleID, ok := parseUUID(c, "rule_id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.RemoveRule(c.Request.Context(), *id, *ruleID); err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gin.H{"message": "rule removed"})
+}
+
+// Associate applies a network ACL to a subnet
+// @Summary Associate a subnet
+// @Description Makes the network ACL the one filtering a subnet of the same VPC, replacing any previous one
+// @Tags vpcs
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Network ACL ID"
+// @Param request body object{subnet_id=string} true "Subnet"
+// @Success 200 {object} httputil.Response
+// @Failure 400 {object} httputil.Response
+// @Router /network-acls/{id}/associations [post]
+func (h *NetworkACLHandler) Associate(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		SubnetID uuid.UUID `json:"subnet_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.AssociateSubnet(c.Request.Context(), *id, req.SubnetID); err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gin.H{"message": "subnet associated"})
+}
+
+// Disassociate stops filtering a subnet with its network ACL
+// @Summary Disassociate a subnet
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Subnet ID"
+// @Success 200 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Router /subnets/{id}/network-acl [delete]
+func (h *NetworkACLHandler) Disassociate(c *gin.Context) {
+	subnetID, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DisassociateSubnet(c.Request.Context(), *subnetID); err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gin.H{"message": "subnet disassociated"})
+}
@@ -0,0 +1,156 @@
+package httphandlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockNetworkACLService struct {
+	mock.Mock
+}
+
+func (m *mockNetworkACLService) CreateACL(ctx context.Context, vpcID uuid.UUID, name string) (*domain.NetworkACL, error) {
+	args := m.Called(ctx, vpcID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NetworkACL), args.Error(1)
+}
+
+func (m *mockNetworkACLService) GetACL(ctx context.Context, id uuid.UUID) (*domain.NetworkACL, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NetworkACL), args.Error(1)
+}
+
+func (m *mockNetworkACLService) ListACLs(ctx context.Context, vpcID uuid.UUID) ([]*domain.NetworkACL, error) {
+	args := m.Called(ctx, vpcID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.NetworkACL), args.Error(1)
+}
+
+func (m *mockNetworkACLService) DeleteACL(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockNetworkACLService) AddRule(ctx context.Context, aclID uuid.UUID, rule domain.NetworkACLRule) (*domain.NetworkACLRule, error) {
+	args := m.Called(ctx, aclID, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NetworkACLRule), args.Error(1)
+}
+
+func (m *mockNetworkACLService) RemoveRule(ctx context.Context, aclID, ruleID uuid.UUID) error {
+	return m.Called(ctx, aclID, ruleID).Error(0)
+}
+
+func (m *mockNetworkACLService) AssociateSubnet(ctx context.Context, aclID, subnetID uuid.UUID) error {
+	return m.Called(ctx, aclID, subnetID).Error(0)
+}
+
+func (m *mockNetworkACLService) DisassociateSubnet(ctx context.Context, subnetID uuid.UUID) error {
+	return m.Called(ctx, subnetID).Error(0)
+}
+
+func setupNetworkACLHandlerTest() (*mockNetworkACLService, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+	svc := new(mockNetworkACLService)
+	handler := NewNetworkACLHandler(svc)
+
+	r := gin.New()
+	r.POST("/vpcs/:id/network-acls", handler.Create)
+	r.GET("/vpcs/:id/network-acls", handler.List)
+	r.GET("/network-acls/:id", handler.Get)
+	r.DELETE("/network-acls/:id", handler.Delete)
+	r.POST("/network-acls/:id/rules", handler.AddRule)
+	r.DELETE("/network-acls/:id/rules/:rule_id", handler.RemoveRule)
+	r.POST("/network-acls/:id/associations", handler.Associate)
+	r.DELETE("/subnets/:id/network-acl", handler.Disassociate)
+	return svc, r
+}
+
+func TestNetworkACLHandlerCreateAndDelete(t *testing.T) {
+	t.Parallel()
+	svc, r := setupNetworkACLHandlerTest()
+	vpcID, id := uuid.New(), uuid.New()
+	svc.On("CreateACL", mock.Anything, vpcID, "web").Return(&domain.NetworkACL{ID: id, Name: "web"}, nil)
+	svc.On("ListACLs", mock.Anything, vpcID).Return([]*domain.NetworkACL{{ID: id, Name: "web"}}, nil)
+	svc.On("DeleteACL", mock.Anything, id).Return(errors.New(errors.Conflict, "network ACL is associated with 1 subnet(s)"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/vpcs/"+vpcID.String()+"/network-acls", bytes.NewBufferString(`{"name":"web"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/vpcs/"+vpcID.String()+"/network-acls", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"web"`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/network-acls/"+id.String(), nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestNetworkACLHandlerRules(t *testing.T) {
+	t.Parallel()
+	svc, r := setupNetworkACLHandlerTest()
+	id, ruleID := uuid.New(), uuid.New()
+	want := domain.NetworkACLRule{
+		RuleNumber: 100, Direction: domain.RuleIngress, Protocol: "tcp", PortMin: 443, PortMax: 443, CIDR: "0.0.0.0/0", Action: domain.ACLAllow,
+	}
+	svc.On("AddRule", mock.Anything, id, want).Return(&want, nil)
+	svc.On("RemoveRule", mock.Anything, id, ruleID).Return(nil)
+
+	w := httptest.NewRecorder()
+	body := `{"rule_number":100,"direction":"ingress","protocol":"tcp","port_min":443,"port_max":443,"cidr":"0.0.0.0/0","action":"allow"}`
+	req, _ := http.NewRequest(http.MethodPost, "/network-acls/"+id.String()+"/rules", bytes.NewBufferString(body))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/network-acls/"+id.String()+"/rules", bytes.NewBufferString(`{"rule_number":100}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/network-acls/"+id.String()+"/rules/"+ruleID.String(), nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestNetworkACLHandlerAssociations(t *testing.T) {
+	t.Parallel()
+	svc, r := setupNetworkACLHandlerTest()
+	id, subnetID := uuid.New(), uuid.New()
+	svc.On("AssociateSubnet", mock.Anything, id, subnetID).Return(nil)
+	svc.On("DisassociateSubnet", mock.Anything, subnetID).Return(errors.New(errors.NotFound, "subnet has no network ACL"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/network-acls/"+id.String()+"/associations", bytes.NewBufferString(`{"subnet_id":"`+subnetID.String()+`"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/subnets/"+subnetID.String()+"/network-acl", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,278 @@
+// Package httphandlers provides HTTP handlers for the API.
+package httphandlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/pkg/httputil"
+)
+
+// RouteTableHandler handles route table HTTP endpoints.
+type RouteTableHandler struct {
+	svc ports.RouteTableService
+}
+
+// NewRouteTableHandler constructs a RouteTableHandler.
+func NewRouteTableHandler(svc ports.RouteTableService) *RouteTableHandler {
+	return &RouteTableHandler{svc: svc}
+}
+
+// Create adds a route table to a VPC
+// @Summary Create a route table
+// @Description Creates a custom route table in a VPC, seeded with the VPC's local route
+// @Tags vpcs
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "VPC ID"
+// @Param request body object{name=string} true "Route table"
+// @Success 201 {object} domain.RouteTable
+// @Failure 400 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Router /vpcs/{id}/route-tables [post]
+func (h *RouteTableHandler) Create(c *gin.Context) {
+	vpcID, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rt, err := h.svc.CreateRouteTable(c.Request.Context(), *vpcID, req.Name)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusCreated, rt)
+}
+
+// List returns a VPC's route tables
+// @Summary List route tables
+// @Description Lists a VPC's route tables, main table first
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "VPC ID"
+// @Success 200 {array} domain.RouteTable
+// @Failure 404 {object} httputil.Response
+// @Router /vpcs/{id}/route-tables [get]
+func (h *RouteTableHandler) List(c *gin.Context) {
+	vpcID, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	tables, err := h.svc.ListRouteTables(c.Request.Context(), *vpcID)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, tables)
+}
+
+// Get returns a route table
+// @Summary Get a route table
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Route table ID"
+// @Success 200 {object} domain.RouteTable
+// @Failure 404 {object} httputil.Response
+// @Router /route-tables/{id} [get]
+func (h *RouteTableHandler) Get(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	rt, err := h.svc.GetRouteTable(c.Request.Context(), *id)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, rt)
+}
+
+// Delete removes a route table
+// @Summary Delete a route table
+// @Description Deletes a custom route table no subnet is associated with
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Route table ID"
+// @Success 200 {object} httputil.Response
+// @Failure 409 {object} httputil.Response
+// @Router /route-tables/{id} [delete]
+func (h *RouteTableHandler) Delete(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteRouteTable(c.Request.Context(), *id); err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gin.H{"message": "route table deleted"})
+}
+
+// AddRoute adds a route to a table
+// @Summary Add a route
+// @Description Sends traffic for a destination range to an internet gateway or NAT gateway of the table's VPC
+// @Tags vpcs
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Route table ID"
+// @Param request body object{destination_cidr=string,target_type=string,target_id=string} true "Route"
+// @Success 201 {object} domain.Route
+// @Failure 400 {object} httputil.Response
+// @Failure 409 {object} httputil.Response
+// @Router /route-tables/{id}/routes [post]
+func (h *RouteTableHandler) AddRoute(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		DestinationCIDR string                 `json:"destination_cidr" binding:"required"`
+		TargetType      domain.RouteTargetType `json:"target_type" binding:"required"`
+		TargetID        *uuid.UUID             `json:"target_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route, err := h.svc.AddRoute(c.Request.Context(), *id, req.DestinationCIDR, req.TargetType, req.TargetID)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusCreated, route)
+}
+
+// RemoveRoute removes a route from a table
+// @Summary Remove a route
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Route table ID"
+// @Param destination query string true "Destination CIDR of the route"
+// @Success 200 {object} httputil.Response
+// @Failure 400 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Router /route-tables/{id}/routes [delete]
+func (h *RouteTableHandler) RemoveRoute(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+	destination := c.Query("destination")
+	if destination == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "destination query parameter is required"})
+		return
+	}
+
+	if err := h.svc.RemoveRoute(c.Request.Context(), *id, destination); err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gin.H{"message": "route removed"})
+}
+
+// Associate applies a route table to a subnet
+// @Summary Associate a subnet
+// @Description Makes the route table the one used by a subnet of the same VPC
+// @Tags vpcs
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Route table ID"
+// @Param request body object{subnet_id=string} true "Subnet"
+// @Success 200 {object} httputil.Response
+// @Failure 400 {object} httputil.Response
+// @Router /route-tables/{id}/associations [post]
+func (h *RouteTableHandler) Associate(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		SubnetID uuid.UUID `json:"subnet_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.AssociateSubnet(c.Request.Context(), *id, req.SubnetID); err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gin.H{"message": "subnet associated"})
+}
+
+// GetForSubnet returns the route table in effect for a subnet
+// @Summary Get a subnet's route table
+// @Description Returns the subnet's associated route table, or its VPC's main table
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Subnet ID"
+// @Success 200 {object} domain.RouteTable
+// @Failure 404 {object} httputil.Response
+// @Router /subnets/{id}/route-table [get]
+func (h *RouteTableHandler) GetForSubnet(c *gin.Context) {
+	subnetID, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	rt, err := h.svc.GetSubnetRouteTable(c.Request.Context(), *subnetID)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, rt)
+}
+
+// Disassociate returns a subnet to its VPC's main route table
+// @Summary Disassociate a subnet
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Subnet ID"
+// @Success 200 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Router /subnets/{id}/route-table [delete]
+func (h *RouteTableHandler) Disassociate(c *gin.Context) {
+	subnetID, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DisassociateSubnet(c.Request.Context(), *subnetID); err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gin.H{"message": "subnet disassociated"})
+}
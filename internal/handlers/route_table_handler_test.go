@@ -0,0 +1,169 @@
+package httphandlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockRouteTableService struct {
+	mock.Mock
+}
+
+func (m *mockRouteTableService) CreateRouteTable(ctx context.Context, vpcID uuid.UUID, name string) (*domain.RouteTable, error) {
+	args := m.Called(ctx, vpcID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RouteTable), args.Error(1)
+}
+
+func (m *mockRouteTableService) GetRouteTable(ctx context.Context, id uuid.UUID) (*domain.RouteTable, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RouteTable), args.Error(1)
+}
+
+func (m *mockRouteTableService) ListRouteTables(ctx context.Context, vpcID uuid.UUID) ([]*domain.RouteTable, error) {
+	args := m.Called(ctx, vpcID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.RouteTable), args.Error(1)
+}
+
+func (m *mockRouteTableService) DeleteRouteTable(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockRouteTableService) AddRoute(ctx context.Context, routeTableID uuid.UUID, destinationCIDR string, targetType domain.RouteTargetType, targetID *uuid.UUID) (*domain.Route, error) {
+	args := m.Called(ctx, routeTableID, destinationCIDR, targetType, targetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Route), args.Error(1)
+}
+
+func (m *mockRouteTableService) RemoveRoute(ctx context.Context, routeTableID uuid.UUID, destinationCIDR string) error {
+	return m.Called(ctx, routeTableID, destinationCIDR).Error(0)
+}
+
+func (m *mockRouteTableService) AssociateSubnet(ctx context.Context, routeTableID, subnetID uuid.UUID) error {
+	return m.Called(ctx, routeTableID, subnetID).Error(0)
+}
+
+func (m *mockRouteTableService) DisassociateSubnet(ctx context.Context, subnetID uuid.UUID) error {
+	return m.Called(ctx, subnetID).Error(0)
+}
+
+func (m *mockRouteTableService) GetSubnetRouteTable(ctx context.Context, subnetID uuid.UUID) (*domain.RouteTable, error) {
+	args := m.Called(ctx, subnetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RouteTable), args.Error(1)
+}
+
+func setupRouteTableHandlerTest() (*mockRouteTableService, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+	svc := new(mockRouteTableService)
+	handler := NewRouteTableHandler(svc)
+
+	r := gin.New()
+	r.POST("/vpcs/:id/route-tables", handler.Create)
+	r.GET("/vpcs/:id/route-tables", handler.List)
+	r.GET("/route-tables/:id", handler.Get)
+	r.DELETE("/route-tables/:id", handler.Delete)
+	r.POST("/route-tables/:id/routes", handler.AddRoute)
+	r.DELETE("/route-tables/:id/routes", handler.RemoveRoute)
+	r.POST("/route-tables/:id/associations", handler.Associate)
+	r.GET("/subnets/:id/route-table", handler.GetForSubnet)
+	r.DELETE("/subnets/:id/route-table", handler.Disassociate)
+	return svc, r
+}
+
+func TestRouteTableHandlerCreateAndList(t *testing.T) {
+	t.Parallel()
+	svc, r := setupRouteTableHandlerTest()
+	vpcID := uuid.New()
+	svc.On("CreateRouteTable", mock.Anything, vpcID, "private").Return(&domain.RouteTable{ID: uuid.New(), Name: "private"}, nil)
+	svc.On("ListRouteTables", mock.Anything, vpcID).Return([]*domain.RouteTable{{Name: "main", Main: true}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/vpcs/"+vpcID.String()+"/route-tables", bytes.NewBufferString(`{"name":"private"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/vpcs/"+vpcID.String()+"/route-tables", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"main":true`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/vpcs/not-a-uuid/route-tables", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRouteTableHandlerRoutes(t *testing.T) {
+	t.Parallel()
+	svc, r := setupRouteTableHandlerTest()
+	id, igwID := uuid.New(), uuid.New()
+	svc.On("AddRoute", mock.Anything, id, "0.0.0.0/0", domain.RouteTargetInternetGateway, &igwID).
+		Return(&domain.Route{DestinationCIDR: "0.0.0.0/0", TargetType: domain.RouteTargetInternetGateway}, nil)
+	svc.On("RemoveRoute", mock.Anything, id, "10.0.0.0/16").Return(errors.New(errors.InvalidInput, "the local route cannot be removed"))
+
+	body, _ := json.Marshal(map[string]string{"destination_cidr": "0.0.0.0/0", "target_type": "internet-gateway", "target_id": igwID.String()})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/route-tables/"+id.String()+"/routes", bytes.NewBuffer(body))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/route-tables/"+id.String()+"/routes?destination=10.0.0.0/16", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/route-tables/"+id.String()+"/routes", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestRouteTableHandlerAssociations(t *testing.T) {
+	t.Parallel()
+	svc, r := setupRouteTableHandlerTest()
+	id, subnetID := uuid.New(), uuid.New()
+	svc.On("AssociateSubnet", mock.Anything, id, subnetID).Return(nil)
+	svc.On("GetSubnetRouteTable", mock.Anything, subnetID).Return(&domain.RouteTable{ID: id}, nil)
+	svc.On("DisassociateSubnet", mock.Anything, subnetID).Return(errors.New(errors.NotFound, "subnet has no route table association"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/route-tables/"+id.String()+"/associations", bytes.NewBufferString(`{"subnet_id":"`+subnetID.String()+`"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/subnets/"+subnetID.String()+"/route-table", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/subnets/"+subnetID.String()+"/route-table", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -75,6 +75,16 @@ func (m *mockSecurityGroupService) DetachFromInstance(c context.Context, i uuid.
 	return args.Error(0)
 }
 
+func (m *mockSecurityGroupService) DetachAllFromInstance(ctx context.Context, instanceID uuid.UUID) error {
+	args := m.Called(ctx, instanceID)
+	return args.Error(0)
+}
+
+func (m *mockSecurityGroupService) SyncInstanceAddresses(ctx context.Context, instanceID uuid.UUID, previous, current []string) error {
+	args := m.Called(ctx, instanceID, previous, current)
+	return args.Error(0)
+}
+
 func (m *mockSecurityGroupService) RemoveRule(ctx context.Context, ruleID uuid.UUID) error {
 	args := m.Called(ctx, ruleID)
 	return args.Error(0)
@@ -36,7 +36,7 @@ func TestStorageHandlerErrors(t *testing.T) { //nolint:gocyclo
 			method: http.MethodPut,
 			path:   "/storage/b1/key1",
 			setupMock: func(m *mockStorageService) {
-				m.On("Upload", mock.Anything, "b1", "/key1", mock.Anything).
+				m.On("PutObject", mock.Anything, "b1", "/key1", mock.Anything, mock.Anything).
 					Return(nil, internalerrors.New(internalerrors.Internal, "upload failed"))
 			},
 			checkCode: http.StatusInternalServerError,
@@ -46,8 +46,8 @@ func TestStorageHandlerErrors(t *testing.T) { //nolint:gocyclo
 			method: http.MethodGet,
 			path:   "/storage/b1/key1",
 			setupMock: func(m *mockStorageService) {
-				m.On("Download", mock.Anything, "b1", "/key1").
-					Return(nil, nil, internalerrors.New(internalerrors.Internal, "download failed"))
+				m.On("GetObject", mock.Anything, "b1", "/key1", mock.Anything).
+					Return(nil, internalerrors.New(internalerrors.Internal, "download failed"))
 			},
 			checkCode: http.StatusInternalServerError,
 		},
@@ -2,6 +2,8 @@ package httphandlers
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -12,6 +14,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
 	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/errors"
 	"github.com/poyrazk/thecloud/internal/platform"
@@ -40,17 +43,47 @@ func (m *mockStorageService) Download(ctx context.Context, bucket, key string) (
 	return args.Get(0).(io.ReadCloser), args.Get(1).(*domain.Object), args.Error(2)
 }
 
-func (m *mockStorageService) ListObjects(ctx context.Context, bucket string) ([]*domain.Object, error) {
-	args := m.Called(ctx, bucket)
+func (m *mockStorageService) PutObject(ctx context.Context, bucket, key string, r io.Reader, cond domain.Preconditions) (*domain.Object, error) {
+	args := m.Called(ctx, bucket, key, r, cond)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*domain.Object), args.Error(1)
+	return args.Get(0).(*domain.Object), args.Error(1)
+}
+
+func (m *mockStorageService) GetObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.ObjectContent, error) {
+	args := m.Called(ctx, bucket, key, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ObjectContent), args.Error(1)
+}
+
+func (m *mockStorageService) HeadObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.Object, error) {
+	args := m.Called(ctx, bucket, key, opts)
+	obj, _ := args.Get(0).(*domain.Object)
+	return obj, args.Error(1)
+}
+
+func (m *mockStorageService) DeleteObjectIf(ctx context.Context, bucket, key, versionID string, cond domain.Preconditions) error {
+	args := m.Called(ctx, bucket, key, versionID, cond)
+	return args.Error(0)
+}
+
+func (m *mockStorageService) ListObjects(ctx context.Context, bucket string, opts domain.ObjectListOptions) (*domain.ObjectListResult, error) {
+	args := m.Called(ctx, bucket, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ObjectListResult), args.Error(1)
 }
 
 func (m *mockStorageService) DeleteObject(ctx context.Context, bucket, key string) error {
 	return m.Called(ctx, bucket, key).Error(0)
 }
+func (m *mockStorageService) ExpireObject(ctx context.Context, bucket, key string) error {
+	return m.Called(ctx, bucket, key).Error(0)
+}
 
 func (m *mockStorageService) CreateBucket(ctx context.Context, name string, isPublic bool) (*domain.Bucket, error) {
 	args := m.Called(ctx, name, isPublic)
@@ -104,6 +137,119 @@ func (m *mockStorageService) SetBucketVersioning(ctx context.Context, name strin
 	return m.Called(ctx, name, enabled).Error(0)
 }
 
+func (m *mockStorageService) GetBucketPolicy(ctx context.Context, bucket string) (*domain.BucketPolicy, error) {
+	args := m.Called(ctx, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BucketPolicy), args.Error(1)
+}
+func (m *mockStorageService) PutBucketPolicy(ctx context.Context, bucket string, statements []domain.BucketPolicyStatement) (*domain.BucketPolicy, error) {
+	args := m.Called(ctx, bucket, statements)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BucketPolicy), args.Error(1)
+}
+func (m *mockStorageService) DeleteBucketPolicy(ctx context.Context, bucket string) error {
+	return m.Called(ctx, bucket).Error(0)
+}
+func (m *mockStorageService) GetBucketNotifications(ctx context.Context, bucket string) (*domain.BucketNotificationConfig, error) {
+	args := m.Called(ctx, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BucketNotificationConfig), args.Error(1)
+}
+func (m *mockStorageService) PutBucketNotifications(ctx context.Context, bucket string, rules []domain.BucketNotificationRule) (*domain.BucketNotificationConfig, error) {
+	args := m.Called(ctx, bucket, rules)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BucketNotificationConfig), args.Error(1)
+}
+func (m *mockStorageService) DeleteBucketNotifications(ctx context.Context, bucket string) error {
+	return m.Called(ctx, bucket).Error(0)
+}
+func (m *mockStorageService) SetBucketObjectLock(ctx context.Context, bucket string, cfg domain.ObjectLockConfig) (*domain.Bucket, error) {
+	args := m.Called(ctx, bucket, cfg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Bucket), args.Error(1)
+}
+func (m *mockStorageService) PutObjectRetention(ctx context.Context, bucket, key, versionID string, retention *domain.ObjectRetention) error {
+	return m.Called(ctx, bucket, key, versionID, retention).Error(0)
+}
+func (m *mockStorageService) PutObjectLegalHold(ctx context.Context, bucket, key, versionID string, on bool) error {
+	return m.Called(ctx, bucket, key, versionID, on).Error(0)
+}
+func (m *mockStorageService) GetBucketReplication(ctx context.Context, bucket string) (*domain.BucketReplicationConfig, error) {
+	args := m.Called(ctx, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BucketReplicationConfig), args.Error(1)
+}
+func (m *mockStorageService) PutBucketReplication(ctx context.Context, bucket string, rules []domain.ReplicationRule) (*domain.BucketReplicationConfig, error) {
+	args := m.Called(ctx, bucket, rules)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BucketReplicationConfig), args.Error(1)
+}
+func (m *mockStorageService) DeleteBucketReplication(ctx context.Context, bucket string) error {
+	return m.Called(ctx, bucket).Error(0)
+}
+func (m *mockStorageService) GetObjectReplication(ctx context.Context, bucket, key string) ([]*domain.ObjectReplication, error) {
+	args := m.Called(ctx, bucket, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ObjectReplication), args.Error(1)
+}
+func (m *mockStorageService) GetReplicationBacklog(ctx context.Context, bucket string) (*domain.ReplicationBacklog, error) {
+	args := m.Called(ctx, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ReplicationBacklog), args.Error(1)
+}
+func (m *mockStorageService) SetBucketEncryption(ctx context.Context, bucket string, enabled bool) (*domain.Bucket, error) {
+	args := m.Called(ctx, bucket, enabled)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Bucket), args.Error(1)
+}
+func (m *mockStorageService) RotateBucketKey(ctx context.Context, bucket string, reencrypt bool) (*domain.Bucket, error) {
+	args := m.Called(ctx, bucket, reencrypt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Bucket), args.Error(1)
+}
+func (m *mockStorageService) ReencryptObjects(ctx context.Context, bucket string, limit int) (int, error) {
+	args := m.Called(ctx, bucket, limit)
+	return args.Int(0), args.Error(1)
+}
+func (m *mockStorageService) SetObjectACL(ctx context.Context, bucket, key, versionID string, acl domain.ObjectACL) error {
+	return m.Called(ctx, bucket, key, versionID, acl).Error(0)
+}
+func (m *mockStorageService) SetObjectTags(ctx context.Context, bucket, key, versionID string, tags map[string]string) error {
+	return m.Called(ctx, bucket, key, versionID, tags).Error(0)
+}
+func (m *mockStorageService) TransitionObject(ctx context.Context, bucket, key, versionID string, class domain.StorageClass) error {
+	return m.Called(ctx, bucket, key, versionID, class).Error(0)
+}
+func (m *mockStorageService) SetBucketStorageClass(ctx context.Context, name string, class domain.StorageClass, layout domain.ErasureLayout) (*domain.Bucket, error) {
+	args := m.Called(ctx, name, class, layout)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Bucket), args.Error(1)
+}
+
 func (m *mockStorageService) CreateMultipartUpload(ctx context.Context, bucket, key string) (*domain.MultipartUpload, error) {
 	args := m.Called(ctx, bucket, key)
 	if args.Get(0) == nil {
@@ -137,6 +283,10 @@ func (m *mockStorageService) CleanupDeleted(ctx context.Context, limit int) (int
 	return args.Int(0), args.Error(1)
 }
 
+func (m *mockStorageService) RepairErasureObjects(ctx context.Context, afterID uuid.UUID, limit int) (uuid.UUID, int, error) {
+	return uuid.Nil, 0, nil
+}
+
 func (m *mockStorageService) GeneratePresignedURL(ctx context.Context, bucket, key, method string, expiry time.Duration) (*domain.PresignedURL, error) {
 	args := m.Called(ctx, bucket, key, method, expiry)
 	if args.Get(0) == nil {
@@ -171,6 +321,7 @@ const (
 	multipartComplPath = "/storage/multipart/complete/:id"
 	multipartAbortPath = "/storage/multipart/abort/:id"
 	versioningPath     = "/storage/buckets/:bucket/versioning"
+	storageClassPath   = "/storage/buckets/:bucket/storage-class"
 	versionsPath       = "/storage/versions/:bucket/*key"
 	presignPath        = "/storage/presign/:bucket/*key"
 	presignedPath      = "/storage/presigned/:bucket/*key"
@@ -238,7 +389,7 @@ func TestStorageHandlerUpload(t *testing.T) {
 	r.PUT(bucketKeyPath, handler.Upload)
 
 	obj := &domain.Object{Key: testTxtKey}
-	mockSvc.On("Upload", mock.Anything, "b1", testTxtPath, mock.Anything).Return(obj, nil)
+	mockSvc.On("PutObject", mock.Anything, "b1", testTxtPath, mock.Anything, domain.Preconditions{}).Return(obj, nil)
 
 	req := httptest.NewRequest(http.MethodPut, testTxtFullURL, strings.NewReader("hello"))
 	w := httptest.NewRecorder()
@@ -253,8 +404,8 @@ func TestStorageHandlerList(t *testing.T) {
 	mockSvc, handler, r := setupStorageHandlerTest()
 	r.GET(bucketPath, handler.List)
 
-	objects := []*domain.Object{{Key: testTxtKey}}
-	mockSvc.On("ListObjects", mock.Anything, "b1").Return(objects, nil)
+	result := &domain.ObjectListResult{Bucket: "b1", Objects: []*domain.Object{{Key: testTxtKey}}, KeyCount: 1}
+	mockSvc.On("ListObjects", mock.Anything, "b1", domain.ObjectListOptions{}).Return(result, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/storage/b1", nil)
 	w := httptest.NewRecorder()
@@ -264,6 +415,51 @@ func TestStorageHandlerList(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestStorageHandlerListWithOptions(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.GET(bucketPath, handler.List)
+
+	opts := domain.ObjectListOptions{Prefix: "photos/", Delimiter: "/", StartAfter: "photos/a", ContinuationToken: "tok", MaxKeys: 2}
+	result := &domain.ObjectListResult{
+		Bucket:                "b1",
+		Prefix:                "photos/",
+		Delimiter:             "/",
+		MaxKeys:               2,
+		KeyCount:              2,
+		Objects:               []*domain.Object{{Key: "photos/b.jpg"}},
+		CommonPrefixes:        []string{"photos/2024/"},
+		IsTruncated:           true,
+		NextContinuationToken: "next",
+	}
+	mockSvc.On("ListObjects", mock.Anything, "b1", opts).Return(result, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/storage/b1?prefix=photos/&delimiter=/&start_after=photos/a&continuation_token=tok&max_keys=2", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data domain.ObjectListResult `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"photos/2024/"}, resp.Data.CommonPrefixes)
+	assert.True(t, resp.Data.IsTruncated)
+	assert.Equal(t, "next", resp.Data.NextContinuationToken)
+}
+
+func TestStorageHandlerListInvalidMaxKeys(t *testing.T) {
+	t.Parallel()
+	_, handler, r := setupStorageHandlerTest()
+	r.GET(bucketPath, handler.List)
+
+	req := httptest.NewRequest(http.MethodGet, "/storage/b1?max_keys=abc", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestStorageHandlerDeleteBucket(t *testing.T) {
 	t.Parallel()
 	mockSvc, handler, r := setupStorageHandlerTest()
@@ -284,7 +480,7 @@ func TestStorageHandlerDelete(t *testing.T) {
 	mockSvc, handler, r := setupStorageHandlerTest()
 	r.DELETE(bucketKeyPath, handler.Delete)
 
-	mockSvc.On("DeleteObject", mock.Anything, "b1", testTxtPath).Return(nil)
+	mockSvc.On("DeleteObjectIf", mock.Anything, "b1", testTxtPath, "", domain.Preconditions{}).Return(nil)
 
 	req := httptest.NewRequest(http.MethodDelete, testTxtFullURL, nil)
 	w := httptest.NewRecorder()
@@ -303,7 +499,7 @@ func TestStorageHandlerDownload(t *testing.T) {
 	reader := io.NopCloser(strings.NewReader(content))
 	obj := &domain.Object{Key: testTxtKey, SizeBytes: int64(len(content)), ContentType: "text/plain"}
 
-	mockSvc.On("Download", mock.Anything, "b1", testTxtPath).Return(reader, obj, nil)
+	mockSvc.On("GetObject", mock.Anything, "b1", testTxtPath, domain.GetObjectOptions{}).Return(&domain.ObjectContent{Object: obj, Body: reader, Size: obj.SizeBytes}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, testTxtFullURL, nil)
 	w := httptest.NewRecorder()
@@ -393,6 +589,31 @@ func TestStorageHandlerVersioning(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestStorageHandlerSetBucketStorageClass(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PATCH(storageClassPath, handler.SetBucketStorageClass)
+
+	layout := domain.ErasureLayout{DataShards: 4, ParityShards: 2}
+	mockSvc.On("SetBucketStorageClass", mock.Anything, "b1", domain.StorageClassErasureCoded, layout).
+		Return(&domain.Bucket{Name: "b1", StorageClass: domain.StorageClassErasureCoded, DataShards: 4, ParityShards: 2}, nil)
+
+	body := `{"storage_class":"ERASURE_CODED","data_shards":4,"parity_shards":2}`
+	req := httptest.NewRequest(http.MethodPatch, "/storage/buckets/b1/storage-class", strings.NewReader(body))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"storage_class":"ERASURE_CODED"`)
+
+	// Missing storage class
+	req = httptest.NewRequest(http.MethodPatch, "/storage/buckets/b1/storage-class", strings.NewReader(`{}`))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestStorageHandlerPresigned(t *testing.T) {
 	t.Parallel()
 	mockSvc, handler, r := setupStorageHandlerTest()
@@ -419,7 +640,7 @@ func TestStorageHandlerPresigned(t *testing.T) {
 	content := "presigned content"
 	reader := io.NopCloser(strings.NewReader(content))
 	obj := &domain.Object{Key: testTxtKey, SizeBytes: int64(len(content)), ContentType: "text/plain"}
-	mockSvc.On("Download", mock.Anything, "b1", testTxtPath).Return(reader, obj, nil)
+	mockSvc.On("GetObject", mock.Anything, "b1", testTxtPath, domain.GetObjectOptions{}).Return(&domain.ObjectContent{Object: obj, Body: reader, Size: obj.SizeBytes}, nil)
 
 	req = httptest.NewRequest(http.MethodGet, u.String(), nil)
 	w = httptest.NewRecorder()
@@ -438,7 +659,7 @@ func TestStorageHandlerPresignedUpload(t *testing.T) {
 	u, _ := url.Parse(signedURL)
 
 	obj := &domain.Object{Key: testTxtKey}
-	mockSvc.On("Upload", mock.Anything, "b1", testTxtPath, mock.Anything).Return(obj, nil)
+	mockSvc.On("PutObject", mock.Anything, "b1", testTxtPath, mock.Anything, domain.Preconditions{}).Return(obj, nil)
 
 	req := httptest.NewRequest(http.MethodPut, u.String(), strings.NewReader("uploaded content"))
 	w := httptest.NewRecorder()
@@ -452,7 +673,7 @@ func TestStorageHandlerUploadError(t *testing.T) {
 	mockSvc, handler, r := setupStorageHandlerTest()
 	r.PUT(bucketKeyPath, handler.Upload)
 
-	mockSvc.On("Upload", mock.Anything, "b1", testTxtPath, mock.Anything).Return(nil, errors.New(errors.Internal, "upload failed"))
+	mockSvc.On("PutObject", mock.Anything, "b1", testTxtPath, mock.Anything, domain.Preconditions{}).Return(nil, errors.New(errors.Internal, "upload failed"))
 
 	req := httptest.NewRequest(http.MethodPut, testTxtFullURL, strings.NewReader("hello"))
 	w := httptest.NewRecorder()
@@ -466,7 +687,7 @@ func TestStorageHandlerListError(t *testing.T) {
 	mockSvc, handler, r := setupStorageHandlerTest()
 	r.GET(bucketPath, handler.List)
 
-	mockSvc.On("ListObjects", mock.Anything, "b1").Return(nil, errors.New(errors.Internal, "list failed"))
+	mockSvc.On("ListObjects", mock.Anything, "b1", mock.Anything).Return(nil, errors.New(errors.Internal, "list failed"))
 
 	req := httptest.NewRequest(http.MethodGet, "/storage/b1", nil)
 	w := httptest.NewRecorder()
@@ -480,7 +701,7 @@ func TestStorageHandlerDeleteError(t *testing.T) {
 	mockSvc, handler, r := setupStorageHandlerTest()
 	r.DELETE(bucketKeyPath, handler.Delete)
 
-	mockSvc.On("DeleteObject", mock.Anything, "b1", testTxtPath).Return(errors.New(errors.Internal, "delete failed"))
+	mockSvc.On("DeleteObjectIf", mock.Anything, "b1", testTxtPath, "", domain.Preconditions{}).Return(errors.New(errors.Internal, "delete failed"))
 
 	req := httptest.NewRequest(http.MethodDelete, testTxtFullURL, nil)
 	w := httptest.NewRecorder()
@@ -651,3 +872,643 @@ func TestStorageHandlerPresignedMissingSecret(t *testing.T) {
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestStorageHandlerDownloadRange(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.GET(bucketKeyPath, handler.Download)
+
+	obj := &domain.Object{Key: testTxtKey, SizeBytes: 11, ETag: "abc123", ContentType: "text/plain", CreatedAt: time.Now()}
+	content := &domain.ObjectContent{
+		Object: obj,
+		Body:   io.NopCloser(strings.NewReader("world")),
+		Size:   11,
+		Range:  &domain.ByteRange{Start: 6, End: 10},
+	}
+	mockSvc.On("GetObject", mock.Anything, "b1", testTxtPath, domain.GetObjectOptions{Range: "bytes=6-"}).Return(content, nil)
+
+	req := httptest.NewRequest(http.MethodGet, testTxtFullURL, nil)
+	req.Header.Set("Range", "bytes=6-")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "bytes 6-10/11", w.Header().Get("Content-Range"))
+	assert.Equal(t, "5", w.Header().Get("Content-Length"))
+	assert.Equal(t, `"abc123"`, w.Header().Get("ETag"))
+	assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+	assert.Equal(t, "world", w.Body.String())
+}
+
+func TestStorageHandlerDownloadNotModified(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.GET(bucketKeyPath, handler.Download)
+
+	obj := &domain.Object{Key: testTxtKey, ETag: "abc123", CreatedAt: time.Now()}
+	opts := domain.GetObjectOptions{Preconditions: domain.Preconditions{IfNoneMatch: `"abc123"`}}
+	mockSvc.On("GetObject", mock.Anything, "b1", testTxtPath, opts).
+		Return(&domain.ObjectContent{Object: obj}, errors.New(errors.NotModified, "object not modified"))
+
+	req := httptest.NewRequest(http.MethodGet, testTxtFullURL, nil)
+	req.Header.Set("If-None-Match", `"abc123"`)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, `"abc123"`, w.Header().Get("ETag"))
+	assert.Empty(t, w.Body.String())
+}
+
+func TestStorageHandlerDownloadRangeNotSatisfiable(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.GET(bucketKeyPath, handler.Download)
+
+	mockSvc.On("GetObject", mock.Anything, "b1", testTxtPath, domain.GetObjectOptions{Range: "bytes=100-"}).
+		Return(nil, errors.New(errors.RangeNotSatisfiable, "range not satisfiable"))
+
+	req := httptest.NewRequest(http.MethodGet, testTxtFullURL, nil)
+	req.Header.Set("Range", "bytes=100-")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+}
+
+func TestStorageHandlerHead(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.HEAD(bucketKeyPath, handler.Head)
+
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	obj := &domain.Object{Key: testTxtKey, SizeBytes: 42, ETag: "abc123", ContentType: "text/plain", CreatedAt: modified}
+	mockSvc.On("HeadObject", mock.Anything, "b1", testTxtPath, domain.GetObjectOptions{}).Return(obj, nil)
+
+	req := httptest.NewRequest(http.MethodHead, testTxtFullURL, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "42", w.Header().Get("Content-Length"))
+	assert.Equal(t, `"abc123"`, w.Header().Get("ETag"))
+	assert.Equal(t, modified.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+	assert.Empty(t, w.Body.String())
+}
+
+func TestStorageHandlerUploadPreconditionFailed(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT(bucketKeyPath, handler.Upload)
+
+	mockSvc.On("PutObject", mock.Anything, "b1", testTxtPath, mock.Anything, domain.Preconditions{IfNoneMatch: "*"}).
+		Return(nil, errors.New(errors.PreconditionFailed, "precondition failed"))
+
+	req := httptest.NewRequest(http.MethodPut, testTxtFullURL, strings.NewReader("data"))
+	req.Header.Set("If-None-Match", "*")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func TestStorageHandlerDeleteIfMatch(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.DELETE(bucketKeyPath, handler.Delete)
+
+	since := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	cond := domain.Preconditions{IfMatch: `"abc123"`, IfUnmodifiedSince: &since}
+	mockSvc.On("DeleteObjectIf", mock.Anything, "b1", testTxtPath, "v1", cond).Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, testTxtFullURL+"?versionId=v1", nil)
+	req.Header.Set("If-Match", `"abc123"`)
+	req.Header.Set("If-Unmodified-Since", since.Format(http.TimeFormat))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestStorageHandlerPutBucketPolicy(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT("/storage/buckets/:bucket/policy", handler.PutBucketPolicy)
+
+	statements := []domain.BucketPolicyStatement{{
+		Statement: domain.Statement{Effect: domain.EffectAllow, Action: []string{domain.StorageActionGetObject}, Resource: []string{"public/*"}},
+		Principal: []string{domain.PrincipalAnonymous},
+	}}
+	mockSvc.On("PutBucketPolicy", mock.Anything, "b1", statements).
+		Return(&domain.BucketPolicy{Bucket: "b1", Statements: statements}, nil)
+
+	body := `{"statements":[{"effect":"Allow","action":["storage:GetObject"],"resource":["public/*"],"principal":["anonymous"]}]}`
+	req := httptest.NewRequest(http.MethodPut, "/storage/buckets/b1/policy", strings.NewReader(body))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestStorageHandlerGetBucketPolicyForbidden(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.GET("/storage/buckets/:bucket/policy", handler.GetBucketPolicy)
+
+	mockSvc.On("GetBucketPolicy", mock.Anything, "b1").Return(nil, errors.New(errors.Forbidden, "you don't own this bucket"))
+
+	req := httptest.NewRequest(http.MethodGet, "/storage/buckets/b1/policy", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestStorageHandlerDeleteBucketPolicy(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.DELETE("/storage/buckets/:bucket/policy", handler.DeleteBucketPolicy)
+
+	mockSvc.On("DeleteBucketPolicy", mock.Anything, "b1").Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/storage/buckets/b1/policy", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestStorageHandlerPutBucketNotifications(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT("/storage/buckets/:bucket/notifications", handler.PutBucketNotifications)
+
+	queueID := uuid.New()
+	rules := []domain.BucketNotificationRule{{
+		ID:         "uploads",
+		Events:     []domain.StorageEventName{domain.StorageEventObjectCreated},
+		Prefix:     "incoming/",
+		TargetType: domain.NotificationTargetQueue,
+		TargetID:   queueID,
+	}}
+	mockSvc.On("PutBucketNotifications", mock.Anything, "b1", rules).
+		Return(&domain.BucketNotificationConfig{Bucket: "b1", Rules: rules}, nil)
+
+	body := `{"rules":[{"id":"uploads","events":["ObjectCreated:*"],"prefix":"incoming/","target_type":"QUEUE","target_id":"` + queueID.String() + `"}]}`
+	req := httptest.NewRequest(http.MethodPut, "/storage/buckets/b1/notifications", strings.NewReader(body))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestStorageHandlerPutBucketNotificationsInvalid(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT("/storage/buckets/:bucket/notifications", handler.PutBucketNotifications)
+
+	mockSvc.On("PutBucketNotifications", mock.Anything, "b1", mock.Anything).
+		Return(nil, errors.New(errors.InvalidInput, "rule 0: unknown event"))
+
+	req := httptest.NewRequest(http.MethodPut, "/storage/buckets/b1/notifications", strings.NewReader(`{"rules":[{"events":["ObjectCopied"]}]}`))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestStorageHandlerGetBucketNotificationsNotFound(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.GET("/storage/buckets/:bucket/notifications", handler.GetBucketNotifications)
+
+	mockSvc.On("GetBucketNotifications", mock.Anything, "b1").Return(nil, errors.New(errors.NotFound, "bucket has no notification configuration"))
+
+	req := httptest.NewRequest(http.MethodGet, "/storage/buckets/b1/notifications", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestStorageHandlerDeleteBucketNotifications(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.DELETE("/storage/buckets/:bucket/notifications", handler.DeleteBucketNotifications)
+
+	mockSvc.On("DeleteBucketNotifications", mock.Anything, "b1").Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/storage/buckets/b1/notifications", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestStorageHandlerSetBucketObjectLock(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT("/storage/buckets/:bucket/object-lock", handler.SetBucketObjectLock)
+
+	cfg := domain.ObjectLockConfig{Enabled: true, DefaultMode: domain.RetentionCompliance, DefaultDays: 30}
+	mockSvc.On("SetBucketObjectLock", mock.Anything, "b1", cfg).
+		Return(&domain.Bucket{Name: "b1", VersioningEnabled: true, ObjectLockEnabled: true}, nil)
+
+	body := `{"enabled":true,"default_mode":"COMPLIANCE","default_days":30}`
+	req := httptest.NewRequest(http.MethodPut, "/storage/buckets/b1/object-lock", strings.NewReader(body))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestStorageHandlerPutObjectRetention(t *testing.T) {
+	t.Parallel()
+	until := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("set", func(t *testing.T) {
+		mockSvc, handler, r := setupStorageHandlerTest()
+		r.PUT("/storage/retention/:bucket/*key", handler.PutObjectRetention)
+		mockSvc.On("PutObjectRetention", mock.Anything, "b1", testTxtPath, "v1",
+			&domain.ObjectRetention{Mode: domain.RetentionGovernance, RetainUntil: until}).Return(nil)
+
+		body := `{"mode":"GOVERNANCE","retain_until":"2030-01-01T00:00:00Z","version_id":"v1"}`
+		req := httptest.NewRequest(http.MethodPut, "/storage/retention/b1/test.txt", strings.NewReader(body))
+		req.Header.Set(headerContentType, contentTypeJSON)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockSvc.AssertExpectations(t)
+	})
+
+	t.Run("remove with bypass", func(t *testing.T) {
+		mockSvc, handler, r := setupStorageHandlerTest()
+		r.PUT("/storage/retention/:bucket/*key", handler.PutObjectRetention)
+		bypassed := mock.MatchedBy(func(ctx context.Context) bool { return appcontext.HasGovernanceBypass(ctx) })
+		mockSvc.On("PutObjectRetention", bypassed, "b1", testTxtPath, "", (*domain.ObjectRetention)(nil)).Return(nil)
+
+		req := httptest.NewRequest(http.MethodPut, "/storage/retention/b1/test.txt", strings.NewReader(`{}`))
+		req.Header.Set(headerContentType, contentTypeJSON)
+		req.Header.Set("X-Bypass-Governance-Retention", "true")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockSvc.AssertExpectations(t)
+	})
+
+	t.Run("mode without date", func(t *testing.T) {
+		_, handler, r := setupStorageHandlerTest()
+		r.PUT("/storage/retention/:bucket/*key", handler.PutObjectRetention)
+
+		req := httptest.NewRequest(http.MethodPut, "/storage/retention/b1/test.txt", strings.NewReader(`{"mode":"COMPLIANCE"}`))
+		req.Header.Set(headerContentType, contentTypeJSON)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestStorageHandlerPutObjectLegalHold(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT("/storage/legal-hold/:bucket/*key", handler.PutObjectLegalHold)
+
+	mockSvc.On("PutObjectLegalHold", mock.Anything, "b1", testTxtPath, "", true).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/storage/legal-hold/b1/test.txt", strings.NewReader(`{"legal_hold":true}`))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestStorageHandlerDeleteLocked(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.DELETE(bucketKeyPath, handler.Delete)
+
+	mockSvc.On("DeleteObjectIf", mock.Anything, "b1", testTxtPath, "v1", domain.Preconditions{}).
+		Return(errors.New(errors.ObjectLocked, "object version is under COMPLIANCE retention"))
+
+	req := httptest.NewRequest(http.MethodDelete, testTxtFullURL+"?versionId=v1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestStorageHandlerSetObjectACL(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT("/storage/acl/:bucket/*key", handler.SetObjectACL)
+
+	mockSvc.On("SetObjectACL", mock.Anything, "b1", testTxtPath, "v1", domain.ACLPublicRead).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/storage/acl/b1/test.txt", strings.NewReader(`{"acl":"public-read","version_id":"v1"}`))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestStorageHandlerUploadWithACL(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT(bucketKeyPath, handler.Upload)
+
+	obj := &domain.Object{Key: testTxtKey, VersionID: "null", ACL: domain.ACLPrivate}
+	mockSvc.On("PutObject", mock.Anything, "b1", testTxtPath, mock.Anything, domain.Preconditions{}).Return(obj, nil)
+	mockSvc.On("SetObjectACL", mock.Anything, "b1", testTxtPath, "null", domain.ACLPublicRead).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPut, testTxtFullURL, strings.NewReader("data"))
+	req.Header.Set("X-Object-Acl", "public-read")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"acl":"public-read"`)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestStorageHandlerUploadRejectsUnknownACL(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT(bucketKeyPath, handler.Upload)
+
+	req := httptest.NewRequest(http.MethodPut, testTxtFullURL, strings.NewReader("data"))
+	req.Header.Set("X-Object-Acl", "public-read-write")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockSvc.AssertNotCalled(t, "PutObject", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStorageHandlerSetObjectTags(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT("/storage/tags/:bucket/*key", handler.SetObjectTags)
+
+	tags := map[string]string{"env": "dev"}
+	mockSvc.On("SetObjectTags", mock.Anything, "b1", testTxtPath, "", tags).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/storage/tags/b1/test.txt", strings.NewReader(`{"tags":{"env":"dev"}}`))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"env":"dev"`)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestStorageHandlerUploadWithTags(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT(bucketKeyPath, handler.Upload)
+
+	obj := &domain.Object{Key: testTxtKey, VersionID: "null", ACL: domain.ACLPrivate}
+	mockSvc.On("PutObject", mock.Anything, "b1", testTxtPath, mock.Anything, domain.Preconditions{}).Return(obj, nil)
+	mockSvc.On("SetObjectTags", mock.Anything, "b1", testTxtPath, "null", map[string]string{"env": "dev", "team": "web"}).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPut, testTxtFullURL, strings.NewReader("data"))
+	req.Header.Set("X-Object-Tagging", "env=dev&team=web")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"team":"web"`)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestStorageHandlerUploadRejectsDuplicateTags(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT(bucketKeyPath, handler.Upload)
+
+	req := httptest.NewRequest(http.MethodPut, testTxtFullURL, strings.NewReader("data"))
+	req.Header.Set("X-Object-Tagging", "env=dev&env=prod")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockSvc.AssertNotCalled(t, "PutObject", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStorageHandlerPutBucketReplication(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT("/storage/buckets/:bucket/replication", handler.PutBucketReplication)
+
+	rules := []domain.ReplicationRule{{
+		ID:               "dr",
+		Prefix:           "raw/",
+		Destination:      domain.ReplicationDestination{Endpoint: "https://dr.example.com", Bucket: "backup", APIKey: "secret"},
+		ReplicateDeletes: true,
+	}}
+	redacted := rules[0]
+	redacted.Destination.APIKey = ""
+	mockSvc.On("PutBucketReplication", mock.Anything, "b1", rules).
+		Return(&domain.BucketReplicationConfig{Bucket: "b1", Rules: []domain.ReplicationRule{redacted}}, nil)
+
+	body := `{"rules":[{"id":"dr","prefix":"raw/","destination":{"endpoint":"https://dr.example.com","bucket":"backup","api_key":"secret"},"replicate_deletes":true}]}`
+	req := httptest.NewRequest(http.MethodPut, "/storage/buckets/b1/replication", strings.NewReader(body))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret")
+	mockSvc.AssertExpectations(t)
+}
+
+func TestStorageHandlerPutBucketReplicationMissingRules(t *testing.T) {
+	t.Parallel()
+	_, handler, r := setupStorageHandlerTest()
+	r.PUT("/storage/buckets/:bucket/replication", handler.PutBucketReplication)
+
+	req := httptest.NewRequest(http.MethodPut, "/storage/buckets/b1/replication", strings.NewReader(`{}`))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestStorageHandlerDeleteBucketReplication(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.DELETE("/storage/buckets/:bucket/replication", handler.DeleteBucketReplication)
+
+	mockSvc.On("DeleteBucketReplication", mock.Anything, "b1").Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/storage/buckets/b1/replication", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestStorageHandlerGetReplicationBacklog(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.GET("/storage/buckets/:bucket/replication/status", handler.GetReplicationBacklog)
+
+	mockSvc.On("GetReplicationBacklog", mock.Anything, "b1").
+		Return(&domain.ReplicationBacklog{Bucket: "b1", Pending: 4, LagSeconds: 12.5}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/storage/buckets/b1/replication/status", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"pending":4`)
+}
+
+func TestStorageHandlerGetObjectReplication(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.GET("/storage/replication/:bucket/*key", handler.GetObjectReplication)
+
+	mockSvc.On("GetObjectReplication", mock.Anything, "b1", testTxtPath).Return([]*domain.ObjectReplication{
+		{Bucket: "b1", Key: testTxtPath, VersionID: "v1", RuleID: "dr", Operation: domain.ReplicationOperationPut, Status: domain.ReplicationCompleted},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/storage/replication/b1/"+testTxtKey, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"COMPLETED"`)
+}
+
+func TestStorageHandlerUploadReplica(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT(bucketKeyPath, handler.Upload)
+
+	mockSvc.On("PutObject", mock.MatchedBy(func(ctx context.Context) bool {
+		return appcontext.IsReplicaWrite(ctx)
+	}), "b1", testTxtPath, mock.Anything, domain.Preconditions{}).Return(&domain.Object{Key: testTxtKey}, nil)
+
+	req := httptest.NewRequest(http.MethodPut, testTxtFullURL, strings.NewReader("data"))
+	req.Header.Set("X-Object-Replica", "true")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestStorageHandlerSetBucketEncryption(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.PUT("/storage/buckets/:bucket/encryption", handler.SetBucketEncryption)
+	mockSvc.On("SetBucketEncryption", mock.Anything, "b1", true).
+		Return(&domain.Bucket{Name: "b1", EncryptionEnabled: true, EncryptionKeyID: "k1"}, nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/storage/buckets/b1/encryption", strings.NewReader(`{"enabled":true}`))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/storage/buckets/b1/encryption", strings.NewReader(`{}`))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestStorageHandlerRotateBucketKey(t *testing.T) {
+	t.Parallel()
+	mockSvc, handler, r := setupStorageHandlerTest()
+	r.POST("/storage/buckets/:bucket/encryption/rotate", handler.RotateBucketKey)
+	mockSvc.On("RotateBucketKey", mock.Anything, "b1", true).Return(&domain.Bucket{Name: "b1", EncryptionEnabled: true}, nil).Once()
+	mockSvc.On("RotateBucketKey", mock.Anything, "b1", false).Return(&domain.Bucket{Name: "b1", EncryptionEnabled: true}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/storage/buckets/b1/encryption/rotate", strings.NewReader(`{"reencrypt":true}`))
+	req.Header.Set(headerContentType, contentTypeJSON)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/storage/buckets/b1/encryption/rotate", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestStorageHandlerCustomerKey(t *testing.T) {
+	t.Parallel()
+	key := strings.Repeat("k", domain.CustomerKeySize)
+	keyB64 := base64.StdEncoding.EncodeToString([]byte(key))
+
+	t.Run("upload", func(t *testing.T) {
+		mockSvc, handler, r := setupStorageHandlerTest()
+		r.PUT(bucketKeyPath, handler.Upload)
+		obj := &domain.Object{Key: testTxtKey, Encryption: domain.EncryptionSSEC, CustomerKeyMD5: domain.CustomerKeyMD5([]byte(key))}
+		mockSvc.On("PutObject", mock.MatchedBy(func(ctx context.Context) bool {
+			return string(appcontext.CustomerKeyFromContext(ctx)) == key
+		}), "b1", testTxtPath, mock.Anything, domain.Preconditions{}).Return(obj, nil)
+
+		req := httptest.NewRequest(http.MethodPut, testTxtFullURL, strings.NewReader("data"))
+		req.Header.Set("X-Server-Side-Encryption-Customer-Key", keyB64)
+		req.Header.Set("X-Server-Side-Encryption-Customer-Key-MD5", domain.CustomerKeyMD5([]byte(key)))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "SSE-C", w.Header().Get("X-Server-Side-Encryption"))
+		assert.Equal(t, obj.CustomerKeyMD5, w.Header().Get("X-Server-Side-Encryption-Customer-Key-MD5"))
+	})
+
+	t.Run("download", func(t *testing.T) {
+		mockSvc, handler, r := setupStorageHandlerTest()
+		r.GET(bucketKeyPath, handler.Download)
+		obj := &domain.Object{Key: testTxtKey, SizeBytes: 4, Encryption: domain.EncryptionSSEC}
+		mockSvc.On("GetObject", mock.MatchedBy(func(ctx context.Context) bool {
+			return string(appcontext.CustomerKeyFromContext(ctx)) == key
+		}), "b1", testTxtPath, domain.GetObjectOptions{}).
+			Return(&domain.ObjectContent{Object: obj, Body: io.NopCloser(strings.NewReader("data")), Size: 4}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, testTxtFullURL, nil)
+		req.Header.Set("X-Server-Side-Encryption-Customer-Key", keyB64)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "data", w.Body.String())
+	})
+
+	t.Run("mismatched digest", func(t *testing.T) {
+		mockSvc, handler, r := setupStorageHandlerTest()
+		r.PUT(bucketKeyPath, handler.Upload)
+
+		req := httptest.NewRequest(http.MethodPut, testTxtFullURL, strings.NewReader("data"))
+		req.Header.Set("X-Server-Side-Encryption-Customer-Key", keyB64)
+		req.Header.Set("X-Server-Side-Encryption-Customer-Key-MD5", "AAAAAAAAAAAAAAAAAAAAAA==")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockSvc.AssertNotCalled(t, "PutObject", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
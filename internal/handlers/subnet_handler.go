@@ -34,6 +34,7 @@ func (h *SubnetHandler) Create(c *gin.Context) {
 	var req struct {
 		Name             string `json:"name" binding:"required"`
 		CIDRBlock        string `json:"cidr_block" binding:"required"`
+		IPv6CIDRBlock    string `json:"ipv6_cidr_block"`
 		AvailabilityZone string `json:"availability_zone"`
 	}
 
@@ -42,7 +43,7 @@ func (h *SubnetHandler) Create(c *gin.Context) {
 		return
 	}
 
-	subnet, err := h.svc.CreateSubnet(c.Request.Context(), vpcID, req.Name, req.CIDRBlock, req.AvailabilityZone)
+	subnet, err := h.svc.CreateSubnet(c.Request.Context(), vpcID, req.Name, req.CIDRBlock, req.IPv6CIDRBlock, req.AvailabilityZone)
 	if err != nil {
 		httputil.Error(c, err)
 		return
@@ -27,8 +27,8 @@ type mockSubnetService struct {
 	mock.Mock
 }
 
-func (m *mockSubnetService) CreateSubnet(ctx context.Context, vpcID uuid.UUID, name, cidrBlock, az string) (*domain.Subnet, error) {
-	args := m.Called(ctx, vpcID, name, cidrBlock, az)
+func (m *mockSubnetService) CreateSubnet(ctx context.Context, vpcID uuid.UUID, name, cidrBlock, ipv6CIDRBlock, az string) (*domain.Subnet, error) {
+	args := m.Called(ctx, vpcID, name, cidrBlock, ipv6CIDRBlock, az)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -72,7 +72,7 @@ func TestSubnetHandlerCreateSuccess(t *testing.T) {
 		CIDRBlock: testutil.TestSubnetCIDR,
 	}
 
-	svc.On("CreateSubnet", mock.Anything, vpcID, testSubnetName, testutil.TestSubnetCIDR, "us-east-1a").Return(expectedSubnet, nil)
+	svc.On("CreateSubnet", mock.Anything, vpcID, testSubnetName, testutil.TestSubnetCIDR, "", "us-east-1a").Return(expectedSubnet, nil)
 
 	reqBody := map[string]string{
 		"name":              testSubnetName,
@@ -143,7 +143,7 @@ func TestSubnetHandlerCreateServiceError(t *testing.T) {
 
 	vpcID := uuid.New()
 
-	svc.On("CreateSubnet", mock.Anything, vpcID, testSubnetName, testutil.TestSubnetCIDR, mock.Anything).Return(nil, assert.AnError)
+	svc.On("CreateSubnet", mock.Anything, vpcID, testSubnetName, testutil.TestSubnetCIDR, mock.Anything, mock.Anything).Return(nil, assert.AnError)
 
 	reqBody := map[string]string{
 		"name":       testSubnetName,
@@ -0,0 +1,246 @@
+// Package httphandlers provides HTTP handlers for the API.
+package httphandlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/pkg/httputil"
+)
+
+// VPCGatewayHandler handles internet gateway and NAT gateway HTTP endpoints.
+type VPCGatewayHandler struct {
+	svc ports.VPCGatewayService
+}
+
+// NewVPCGatewayHandler constructs a VPCGatewayHandler.
+func NewVPCGatewayHandler(svc ports.VPCGatewayService) *VPCGatewayHandler {
+	return &VPCGatewayHandler{svc: svc}
+}
+
+// CreateInternetGateway creates an internet gateway
+// @Summary Create an internet gateway
+// @Description Creates a detached internet gateway
+// @Tags vpcs
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param request body object{name=string} true "Internet gateway"
+// @Success 201 {object} domain.InternetGateway
+// @Failure 400 {object} httputil.Response
+// @Router /internet-gateways [post]
+func (h *VPCGatewayHandler) CreateInternetGateway(c *gin.Context) {
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	igw, err := h.svc.CreateInternetGateway(c.Request.Context(), req.Name)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusCreated, igw)
+}
+
+// ListInternetGateways returns internet gateways
+// @Summary List internet gateways
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Success 200 {array} domain.InternetGateway
+// @Router /internet-gateways [get]
+func (h *VPCGatewayHandler) ListInternetGateways(c *gin.Context) {
+	gateways, err := h.svc.ListInternetGateways(c.Request.Context())
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gateways)
+}
+
+// AttachInternetGateway attaches an internet gateway to a VPC
+// @Summary Attach an internet gateway
+// @Description Connects the gateway to a VPC; a VPC has at most one internet gateway
+// @Tags vpcs
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Internet gateway ID"
+// @Param request body object{vpc_id=string} true "VPC"
+// @Success 200 {object} domain.InternetGateway
+// @Failure 409 {object} httputil.Response
+// @Router /internet-gateways/{id}/attach [post]
+func (h *VPCGatewayHandler) AttachInternetGateway(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+	var req struct {
+		VPCID uuid.UUID `json:"vpc_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	igw, err := h.svc.AttachInternetGateway(c.Request.Context(), *id, req.VPCID)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, igw)
+}
+
+// DetachInternetGateway detaches an internet gateway from its VPC
+// @Summary Detach an internet gateway
+// @Description Disconnects the gateway from its VPC; routes targeting it must be removed first
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Internet gateway ID"
+// @Success 200 {object} domain.InternetGateway
+// @Failure 409 {object} httputil.Response
+// @Router /internet-gateways/{id}/detach [post]
+func (h *VPCGatewayHandler) DetachInternetGateway(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	igw, err := h.svc.DetachInternetGateway(c.Request.Context(), *id)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, igw)
+}
+
+// DeleteInternetGateway deletes an internet gateway
+// @Summary Delete an internet gateway
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Internet gateway ID"
+// @Success 200 {object} httputil.Response
+// @Failure 409 {object} httputil.Response
+// @Router /internet-gateways/{id} [delete]
+func (h *VPCGatewayHandler) DeleteInternetGateway(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteInternetGateway(c.Request.Context(), *id); err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gin.H{"message": "internet gateway deleted"})
+}
+
+// CreateNATGateway creates a NAT gateway
+// @Summary Create a NAT gateway
+// @Description Launches a NAT gateway in a public subnet behind an allocated Elastic IP
+// @Tags vpcs
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param request body object{name=string,subnet_id=string,elastic_ip_id=string} true "NAT gateway"
+// @Success 201 {object} domain.NATGateway
+// @Failure 400 {object} httputil.Response
+// @Failure 409 {object} httputil.Response
+// @Router /nat-gateways [post]
+func (h *VPCGatewayHandler) CreateNATGateway(c *gin.Context) {
+	var req struct {
+		Name        string    `json:"name" binding:"required"`
+		SubnetID    uuid.UUID `json:"subnet_id" binding:"required"`
+		ElasticIPID uuid.UUID `json:"elastic_ip_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	nat, err := h.svc.CreateNATGateway(c.Request.Context(), req.SubnetID, req.ElasticIPID, req.Name)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusCreated, nat)
+}
+
+// ListNATGateways returns NAT gateways
+// @Summary List NAT gateways
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Success 200 {array} domain.NATGateway
+// @Router /nat-gateways [get]
+func (h *VPCGatewayHandler) ListNATGateways(c *gin.Context) {
+	gateways, err := h.svc.ListNATGateways(c.Request.Context())
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gateways)
+}
+
+// GetNATGateway returns a NAT gateway
+// @Summary Get a NAT gateway
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "NAT gateway ID"
+// @Success 200 {object} domain.NATGateway
+// @Failure 404 {object} httputil.Response
+// @Router /nat-gateways/{id} [get]
+func (h *VPCGatewayHandler) GetNATGateway(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	nat, err := h.svc.GetNATGateway(c.Request.Context(), *id)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, nat)
+}
+
+// DeleteNATGateway deletes a NAT gateway
+// @Summary Delete a NAT gateway
+// @Description Tears the gateway down and returns its Elastic IP to the allocated state
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "NAT gateway ID"
+// @Success 200 {object} httputil.Response
+// @Failure 409 {object} httputil.Response
+// @Router /nat-gateways/{id} [delete]
+func (h *VPCGatewayHandler) DeleteNATGateway(c *gin.Context) {
+	id, ok := parseUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteNATGateway(c.Request.Context(), *id); err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gin.H{"message": "nat gateway deleted"})
+}
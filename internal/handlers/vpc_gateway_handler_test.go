@@ -0,0 +1,176 @@
+package httphandlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockVPCGatewayService struct {
+	mock.Mock
+}
+
+func (m *mockVPCGatewayService) CreateInternetGateway(ctx context.Context, name string) (*domain.InternetGateway, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.InternetGateway), args.Error(1)
+}
+
+func (m *mockVPCGatewayService) AttachInternetGateway(ctx context.Context, id, vpcID uuid.UUID) (*domain.InternetGateway, error) {
+	args := m.Called(ctx, id, vpcID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.InternetGateway), args.Error(1)
+}
+
+func (m *mockVPCGatewayService) DetachInternetGateway(ctx context.Context, id uuid.UUID) (*domain.InternetGateway, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.InternetGateway), args.Error(1)
+}
+
+func (m *mockVPCGatewayService) ListInternetGateways(ctx context.Context) ([]*domain.InternetGateway, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.InternetGateway), args.Error(1)
+}
+
+func (m *mockVPCGatewayService) DeleteInternetGateway(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockVPCGatewayService) CreateNATGateway(ctx context.Context, subnetID, elasticIPID uuid.UUID, name string) (*domain.NATGateway, error) {
+	args := m.Called(ctx, subnetID, elasticIPID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NATGateway), args.Error(1)
+}
+
+func (m *mockVPCGatewayService) GetNATGateway(ctx context.Context, id uuid.UUID) (*domain.NATGateway, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NATGateway), args.Error(1)
+}
+
+func (m *mockVPCGatewayService) ListNATGateways(ctx context.Context) ([]*domain.NATGateway, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.NATGateway), args.Error(1)
+}
+
+func (m *mockVPCGatewayService) DeleteNATGateway(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func setupVPCGatewayHandlerTest() (*mockVPCGatewayService, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+	svc := new(mockVPCGatewayService)
+	handler := NewVPCGatewayHandler(svc)
+
+	r := gin.New()
+	r.POST("/internet-gateways", handler.CreateInternetGateway)
+	r.GET("/internet-gateways", handler.ListInternetGateways)
+	r.POST("/internet-gateways/:id/attach", handler.AttachInternetGateway)
+	r.POST("/internet-gateways/:id/detach", handler.DetachInternetGateway)
+	r.DELETE("/internet-gateways/:id", handler.DeleteInternetGateway)
+	r.POST("/nat-gateways", handler.CreateNATGateway)
+	r.GET("/nat-gateways", handler.ListNATGateways)
+	r.GET("/nat-gateways/:id", handler.GetNATGateway)
+	r.DELETE("/nat-gateways/:id", handler.DeleteNATGateway)
+	return svc, r
+}
+
+func TestVPCGatewayHandlerInternetGateways(t *testing.T) {
+	t.Parallel()
+	svc, r := setupVPCGatewayHandlerTest()
+	id, vpcID := uuid.New(), uuid.New()
+	svc.On("CreateInternetGateway", mock.Anything, "igw").Return(&domain.InternetGateway{ID: id, Status: domain.IGWStatusDetached}, nil)
+	svc.On("AttachInternetGateway", mock.Anything, id, vpcID).Return(&domain.InternetGateway{ID: id, VPCID: &vpcID, Status: domain.IGWStatusAttached}, nil)
+	svc.On("DetachInternetGateway", mock.Anything, id).Return(nil, errors.New(errors.Conflict, "internet gateway is the target of 1 route(s)"))
+	svc.On("ListInternetGateways", mock.Anything).Return([]*domain.InternetGateway{}, nil)
+	svc.On("DeleteInternetGateway", mock.Anything, id).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/internet-gateways", bytes.NewBufferString(`{"name":"igw"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/internet-gateways/"+id.String()+"/attach", bytes.NewBufferString(`{"vpc_id":"`+vpcID.String()+`"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "attached")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/internet-gateways/"+id.String()+"/detach", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/internet-gateways"},
+		{http.MethodDelete, "/internet-gateways/" + id.String()},
+	} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(tc.method, tc.path, nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, tc.path)
+	}
+	svc.AssertExpectations(t)
+}
+
+func TestVPCGatewayHandlerNATGateways(t *testing.T) {
+	t.Parallel()
+	svc, r := setupVPCGatewayHandlerTest()
+	id, subnetID, eipID := uuid.New(), uuid.New(), uuid.New()
+	svc.On("CreateNATGateway", mock.Anything, subnetID, eipID, "egress").
+		Return(&domain.NATGateway{ID: id, PrivateIP: "10.0.1.254", Status: domain.NATStatusAvailable}, nil)
+	svc.On("ListNATGateways", mock.Anything).Return([]*domain.NATGateway{}, nil)
+	svc.On("GetNATGateway", mock.Anything, id).Return(&domain.NATGateway{ID: id}, nil)
+	svc.On("DeleteNATGateway", mock.Anything, id).Return(nil)
+
+	body, _ := json.Marshal(map[string]string{"name": "egress", "subnet_id": subnetID.String(), "elastic_ip_id": eipID.String()})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/nat-gateways", bytes.NewBuffer(body))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "10.0.1.254")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/nat-gateways", bytes.NewBufferString(`{"name":"egress"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/nat-gateways"},
+		{http.MethodGet, "/nat-gateways/" + id.String()},
+		{http.MethodDelete, "/nat-gateways/" + id.String()},
+	} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(tc.method, tc.path, nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, tc.path)
+	}
+	svc.AssertExpectations(t)
+}
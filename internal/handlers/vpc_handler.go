@@ -26,15 +26,16 @@ func NewVpcHandler(svc ports.VpcService) *VpcHandler {
 // @Accept json
 // @Produce json
 // @Security APIKeyAuth
-// @Param request body object{name=string} true "VPC creation request"
+// @Param request body object{name=string,cidr_block=string,ipv6_cidr_block=string} true "VPC creation request"
 // @Success 201 {object} domain.VPC
 // @Failure 400 {object} httputil.Response
 // @Failure 500 {object} httputil.Response
 // @Router /vpcs [post]
 func (h *VpcHandler) Create(c *gin.Context) {
 	var req struct {
-		Name      string `json:"name" binding:"required"`
-		CIDRBlock string `json:"cidr_block"`
+		Name          string `json:"name" binding:"required"`
+		CIDRBlock     string `json:"cidr_block"`
+		IPv6CIDRBlock string `json:"ipv6_cidr_block"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -42,7 +43,7 @@ func (h *VpcHandler) Create(c *gin.Context) {
 		return
 	}
 
-	vpc, err := h.svc.CreateVPC(c.Request.Context(), req.Name, req.CIDRBlock)
+	vpc, err := h.svc.CreateVPC(c.Request.Context(), req.Name, req.CIDRBlock, req.IPv6CIDRBlock)
 	if err != nil {
 		httputil.Error(c, err)
 		return
@@ -25,8 +25,8 @@ type mockVpcService struct {
 	mock.Mock
 }
 
-func (m *mockVpcService) CreateVPC(ctx context.Context, name, cidrBlock string) (*domain.VPC, error) {
-	args := m.Called(ctx, name, cidrBlock)
+func (m *mockVpcService) CreateVPC(ctx context.Context, name, cidrBlock, ipv6CIDRBlock string) (*domain.VPC, error) {
+	args := m.Called(ctx, name, cidrBlock, ipv6CIDRBlock)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -70,7 +70,7 @@ func TestVpcHandlerCreate(t *testing.T) {
 	r.POST(vpcsPath, handler.Create)
 
 	vpc := &domain.VPC{ID: uuid.New(), Name: testVpcName}
-	svc.On("CreateVPC", mock.Anything, testVpcName, testutil.TestCIDR).Return(vpc, nil)
+	svc.On("CreateVPC", mock.Anything, testVpcName, testutil.TestCIDR, "").Return(vpc, nil)
 
 	body, err := json.Marshal(map[string]string{"name": testVpcName, "cidr_block": testutil.TestCIDR})
 	assert.NoError(t, err)
@@ -82,6 +82,27 @@ func TestVpcHandlerCreate(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, w.Code)
 }
 
+func TestVpcHandlerCreateDualStack(t *testing.T) {
+	t.Parallel()
+	svc, handler, r := setupVpcHandlerTest(t)
+	defer svc.AssertExpectations(t)
+
+	r.POST(vpcsPath, handler.Create)
+
+	vpc := &domain.VPC{ID: uuid.New(), Name: testVpcName, IPv6CIDRBlock: "fd00:10::/56"}
+	svc.On("CreateVPC", mock.Anything, testVpcName, testutil.TestCIDR, "fd00:10::/56").Return(vpc, nil)
+
+	body, err := json.Marshal(map[string]string{"name": testVpcName, "cidr_block": testutil.TestCIDR, "ipv6_cidr_block": "fd00:10::/56"})
+	assert.NoError(t, err)
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", vpcsPath, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"ipv6_cidr_block":"fd00:10::/56"`)
+}
+
 func TestVpcHandlerCreateErrors(t *testing.T) {
 	t.Parallel()
 	svc, handler, r := setupVpcHandlerTest(t)
@@ -95,7 +116,7 @@ func TestVpcHandlerCreateErrors(t *testing.T) {
 	})
 
 	t.Run("ServiceError", func(t *testing.T) {
-		svc.On("CreateVPC", mock.Anything, "err-vpc", "").Return(nil, assert.AnError)
+		svc.On("CreateVPC", mock.Anything, "err-vpc", "", "").Return(nil, assert.AnError)
 		body, _ := json.Marshal(map[string]string{"name": "err-vpc"})
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("POST", vpcsPath, bytes.NewBuffer(body))
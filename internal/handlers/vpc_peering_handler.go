@@ -0,0 +1,157 @@
+// Package httphandlers provides HTTP handlers for the API.
+package httphandlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/pkg/httputil"
+)
+
+// VPCPeeringHandler handles VPC peering HTTP endpoints.
+type VPCPeeringHandler struct {
+	svc ports.VPCPeeringService
+}
+
+// NewVPCPeeringHandler constructs a VPCPeeringHandler.
+func NewVPCPeeringHandler(svc ports.VPCPeeringService) *VPCPeeringHandler {
+	return &VPCPeeringHandler{svc: svc}
+}
+
+// Create requests a peering connection
+// @Summary Request a VPC peering
+// @Description Requests a peering connection from one of the caller's VPCs to another VPC, possibly owned by another tenant
+// @Tags vpcs
+// @Accept json
+// @Produce json
+// @Security APIKeyAuth
+// @Param request body object{requester_vpc_id=string,accepter_vpc_id=string,accepter_tenant_id=string} true "Peering request; accepter_tenant_id names the owner of a VPC in another tenant"
+// @Success 201 {object} domain.VPCPeering
+// @Failure 400 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Failure 409 {object} httputil.Response
+// @Router /vpc-peerings [post]
+func (h *VPCPeeringHandler) Create(c *gin.Context) {
+	var req struct {
+		RequesterVPCID uuid.UUID `json:"requester_vpc_id" binding:"required"`
+		AccepterVPCID  uuid.UUID `json:"accepter_vpc_id" binding:"required"`
+		// AccepterTenantID names the owner of the accepter VPC; empty means the caller's tenant.
+		AccepterTenantID uuid.UUID `json:"accepter_tenant_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	peering, err := h.svc.CreatePeering(c.Request.Context(), req.RequesterVPCID, req.AccepterVPCID, req.AccepterTenantID)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusCreated, peering)
+}
+
+// List returns peering connections
+// @Summary List VPC peerings
+// @Description Lists the peering connections the caller's tenant takes part in
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Success 200 {array} domain.VPCPeering
+// @Failure 500 {object} httputil.Response
+// @Router /vpc-peerings [get]
+func (h *VPCPeeringHandler) List(c *gin.Context) {
+	peerings, err := h.svc.ListPeerings(c.Request.Context())
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, peerings)
+}
+
+// Get returns a peering connection
+// @Summary Get a VPC peering
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Peering ID"
+// @Success 200 {object} domain.VPCPeering
+// @Failure 404 {object} httputil.Response
+// @Router /vpc-peerings/{id} [get]
+func (h *VPCPeeringHandler) Get(c *gin.Context) {
+	id, ok := parsePeeringID(c)
+	if !ok {
+		return
+	}
+
+	peering, err := h.svc.GetPeering(c.Request.Context(), id)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, peering)
+}
+
+// Accept accepts a pending peering connection
+// @Summary Accept a VPC peering
+// @Description Connects the two VPCs; only the accepter VPC's tenant may accept
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Peering ID"
+// @Success 200 {object} domain.VPCPeering
+// @Failure 403 {object} httputil.Response
+// @Failure 409 {object} httputil.Response
+// @Router /vpc-peerings/{id}/accept [post]
+func (h *VPCPeeringHandler) Accept(c *gin.Context) {
+	id, ok := parsePeeringID(c)
+	if !ok {
+		return
+	}
+
+	peering, err := h.svc.AcceptPeering(c.Request.Context(), id)
+	if err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, peering)
+}
+
+// Delete removes a peering connection
+// @Summary Delete a VPC peering
+// @Description Disconnects the two VPCs; either side may delete, which also rejects a pending request
+// @Tags vpcs
+// @Produce json
+// @Security APIKeyAuth
+// @Param id path string true "Peering ID"
+// @Success 200 {object} httputil.Response
+// @Failure 404 {object} httputil.Response
+// @Router /vpc-peerings/{id} [delete]
+func (h *VPCPeeringHandler) Delete(c *gin.Context) {
+	id, ok := parsePeeringID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeletePeering(c.Request.Context(), id); err != nil {
+		httputil.Error(c, err)
+		return
+	}
+
+	httputil.Success(c, http.StatusOK, gin.H{"message": "vpc peering deleted"})
+}
+
+func parsePeeringID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid peering id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
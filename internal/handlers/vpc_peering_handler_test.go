@@ -0,0 +1,132 @@
+package httphandlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockVPCPeeringService struct {
+	mock.Mock
+}
+
+func (m *mockVPCPeeringService) CreatePeering(ctx context.Context, requesterVPCID, accepterVPCID, accepterTenantID uuid.UUID) (*domain.VPCPeering, error) {
+	args := m.Called(ctx, requesterVPCID, accepterVPCID, accepterTenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.VPCPeering), args.Error(1)
+}
+
+func (m *mockVPCPeeringService) AcceptPeering(ctx context.Context, id uuid.UUID) (*domain.VPCPeering, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.VPCPeering), args.Error(1)
+}
+
+func (m *mockVPCPeeringService) GetPeering(ctx context.Context, id uuid.UUID) (*domain.VPCPeering, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.VPCPeering), args.Error(1)
+}
+
+func (m *mockVPCPeeringService) ListPeerings(ctx context.Context) ([]*domain.VPCPeering, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.VPCPeering), args.Error(1)
+}
+
+func (m *mockVPCPeeringService) DeletePeering(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func setupVPCPeeringHandlerTest() (*mockVPCPeeringService, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+	svc := new(mockVPCPeeringService)
+	handler := NewVPCPeeringHandler(svc)
+
+	r := gin.New()
+	r.POST("/vpc-peerings", handler.Create)
+	r.GET("/vpc-peerings", handler.List)
+	r.GET("/vpc-peerings/:id", handler.Get)
+	r.POST("/vpc-peerings/:id/accept", handler.Accept)
+	r.DELETE("/vpc-peerings/:id", handler.Delete)
+	return svc, r
+}
+
+func TestVPCPeeringHandlerCreate(t *testing.T) {
+	t.Parallel()
+	svc, r := setupVPCPeeringHandlerTest()
+	requester, accepter, accepterTenant := uuid.New(), uuid.New(), uuid.New()
+	svc.On("CreatePeering", mock.Anything, requester, accepter, accepterTenant).
+		Return(&domain.VPCPeering{ID: uuid.New(), Status: domain.PeeringPendingAcceptance}, nil)
+
+	body, _ := json.Marshal(map[string]string{
+		"requester_vpc_id": requester.String(), "accepter_vpc_id": accepter.String(), "accepter_tenant_id": accepterTenant.String(),
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/vpc-peerings", bytes.NewBuffer(body))
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "pending-acceptance")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/vpc-peerings", bytes.NewBufferString(`{"requester_vpc_id":"nope"}`))
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestVPCPeeringHandlerAccept(t *testing.T) {
+	t.Parallel()
+	svc, r := setupVPCPeeringHandlerTest()
+	id := uuid.New()
+	svc.On("AcceptPeering", mock.Anything, id).Return(nil, errors.New(errors.Forbidden, "only the accepter VPC's tenant can accept a peering"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/vpc-peerings/"+id.String()+"/accept", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/vpc-peerings/not-a-uuid/accept", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestVPCPeeringHandlerListGetDelete(t *testing.T) {
+	t.Parallel()
+	svc, r := setupVPCPeeringHandlerTest()
+	id := uuid.New()
+	svc.On("ListPeerings", mock.Anything).Return([]*domain.VPCPeering{{ID: id}}, nil)
+	svc.On("GetPeering", mock.Anything, id).Return(&domain.VPCPeering{ID: id}, nil)
+	svc.On("DeletePeering", mock.Anything, id).Return(nil)
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/vpc-peerings"},
+		{http.MethodGet, "/vpc-peerings/" + id.String()},
+		{http.MethodDelete, "/vpc-peerings/" + id.String()},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(tc.method, tc.path, nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, tc.path)
+	}
+	svc.AssertExpectations(t)
+}
@@ -33,12 +33,25 @@ type Config struct {
 	PowerDNSAPIURL       string
 	PowerDNSAPIKey       string
 	PowerDNSServerID     string
+	DNSBackend           string // "powerdns" or "embedded"
+	DNSListenAddr        string
+	DNSVPCListeners      string // comma-separated vpc-id=addr pairs for the embedded DNS server
 	LibvirtURI           string
 	DockerDefaultNetwork string
 	FirecrackerBinary    string
 	FirecrackerKernel    string
 	FirecrackerRootfs    string
 	FirecrackerMockMode  bool
+
+	// StorageMasterKey wraps bucket data keys. It defaults to SecretsEncryptionKey.
+	StorageMasterKey string
+	// StorageRetiredMasterKeys is a comma-separated list of previous master keys. They are
+	// only used to re-wrap data keys with StorageMasterKey after a master key rotation.
+	StorageRetiredMasterKeys string
+
+	// GatewayTLSPort is the port the API gateway serves HTTPS on, using the
+	// certificates attached to gateway routes. Empty disables the listener.
+	GatewayTLSPort string
 }
 
 // NewConfig loads configuration from the environment with defaults.
@@ -70,12 +83,20 @@ func NewConfig() (*Config, error) {
 		PowerDNSAPIURL:       getEnv("POWERDNS_API_URL", "http://localhost:8081"),
 		PowerDNSAPIKey:       getEnv("POWERDNS_API_KEY", "thecloud-dns-secret"),
 		PowerDNSServerID:     getEnv("POWERDNS_SERVER_ID", "localhost"),
+		DNSBackend:           getEnv("DNS_BACKEND", "powerdns"),
+		DNSListenAddr:        getEnv("DNS_LISTEN_ADDR", ":5353"),
+		DNSVPCListeners:      getEnv("DNS_VPC_LISTENERS", ""),
 		LibvirtURI:           getEnv("LIBVIRT_URI", ""),
 		DockerDefaultNetwork: getEnv("DOCKER_DEFAULT_NETWORK", "cloud-network"),
 		FirecrackerBinary:    getEnv("FIRECRACKER_BINARY", "/usr/local/bin/firecracker"),
 		FirecrackerKernel:    getEnv("FIRECRACKER_KERNEL", "/var/lib/thecloud/vmlinux"),
 		FirecrackerRootfs:    getEnv("FIRECRACKER_ROOTFS", "/var/lib/thecloud/rootfs.ext4"),
 		FirecrackerMockMode:  getEnv("FIRECRACKER_MOCK_MODE", "false") == "true",
+
+		StorageMasterKey:         getEnv("STORAGE_MASTER_KEY", os.Getenv("SECRETS_ENCRYPTION_KEY")),
+		StorageRetiredMasterKeys: getEnv("STORAGE_RETIRED_MASTER_KEYS", ""),
+
+		GatewayTLSPort: getEnv("GATEWAY_TLS_PORT", ""),
 	}, nil
 }
 
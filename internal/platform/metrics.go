@@ -37,6 +37,13 @@ var (
 		Help: "Total requests proxied by load balancers",
 	}, []string{"lb_id"})
 
+	// API Gateway metrics
+	GatewayRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "thecloud_gateway_request_duration_seconds",
+		Help:    "Duration of requests served by gateway routes in seconds",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route_id", "method", "status"})
+
 	// HTTP metrics
 	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "thecloud_http_requests_total",
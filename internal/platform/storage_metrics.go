@@ -52,4 +52,40 @@ var (
 		},
 		[]string{"bucket"},
 	)
+
+	// StorageReplicationOperations counts replication attempts by operation and result
+	StorageReplicationOperations = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "storage_replication_operations_total",
+			Help: "Total bucket replication attempts",
+		},
+		[]string{"operation", "status"}, // status: "success", "retry", "failed"
+	)
+
+	// StorageReplicationPending counts changes waiting to be replicated per bucket (gauge)
+	StorageReplicationPending = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "storage_replication_pending_objects",
+			Help: "Number of object changes waiting to be replicated per bucket",
+		},
+		[]string{"bucket"},
+	)
+
+	// StorageReplicationFailed counts changes that exhausted their replication attempts per bucket (gauge)
+	StorageReplicationFailed = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "storage_replication_failed_objects",
+			Help: "Number of object changes whose replication failed per bucket",
+		},
+		[]string{"bucket"},
+	)
+
+	// StorageReplicationLag measures the age of the oldest pending replication per bucket (gauge)
+	StorageReplicationLag = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "storage_replication_lag_seconds",
+			Help: "Age of the oldest object change waiting to be replicated per bucket",
+		},
+		[]string{"bucket"},
+	)
 )
@@ -15,6 +15,10 @@ func TestStorageMetricsRegistered(t *testing.T) {
 	StorageBytesTransferred.WithLabelValues("upload").Add(1)
 	StorageBucketObjects.WithLabelValues("bucket-a").Set(1)
 	StorageBucketBytes.WithLabelValues("bucket-a").Set(1)
+	StorageReplicationOperations.WithLabelValues("PUT", "success").Inc()
+	StorageReplicationPending.WithLabelValues("bucket-a").Set(1)
+	StorageReplicationFailed.WithLabelValues("bucket-a").Set(0)
+	StorageReplicationLag.WithLabelValues("bucket-a").Set(1)
 
 	families, err := prometheus.DefaultGatherer.Gather()
 	if err != nil {
@@ -22,11 +26,15 @@ func TestStorageMetricsRegistered(t *testing.T) {
 	}
 
 	expected := map[string][]string{
-		"storage_operations_total":           {"operation", "bucket", "status"},
-		"storage_operation_duration_seconds": {"operation", "bucket"},
-		"storage_bytes_transferred_total":    {"direction"},
-		"storage_bucket_objects_total":       {"bucket"},
-		"storage_bucket_bytes_total":         {"bucket"},
+		"storage_operations_total":             {"operation", "bucket", "status"},
+		"storage_operation_duration_seconds":   {"operation", "bucket"},
+		"storage_bytes_transferred_total":      {"direction"},
+		"storage_bucket_objects_total":         {"bucket"},
+		"storage_bucket_bytes_total":           {"bucket"},
+		"storage_replication_operations_total": {"operation", "status"},
+		"storage_replication_pending_objects":  {"bucket"},
+		"storage_replication_failed_objects":   {"bucket"},
+		"storage_replication_lag_seconds":      {"bucket"},
 	}
 
 	for name, labels := range expected {
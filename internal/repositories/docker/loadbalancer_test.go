@@ -2,6 +2,7 @@ package docker
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -149,4 +150,76 @@ func TestLBProxyAdapter_GenerateNginxConfig(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Contains(t, conf, "least_conn;")
 	})
+
+	t.Run("https listener", func(t *testing.T) {
+		certID := uuid.New()
+		lb.TLS = &domain.LBTLSConfig{
+			Port:         443,
+			RedirectHTTP: true,
+			Certificates: []*domain.CertificateKeyPair{{CertificateID: certID, Domains: []string{"example.com", "*.example.com"}}},
+		}
+		defer func() { lb.TLS = nil }()
+
+		conf, err := adapter.generateNginxConfig(ctx, lb, targets)
+		assert.NoError(t, err)
+		assert.Contains(t, conf, "return 301 https://$host$request_uri;")
+		assert.Contains(t, conf, "listen 443 ssl default_server;")
+		assert.Contains(t, conf, "server_name example.com *.example.com;")
+		assert.Contains(t, conf, "ssl_certificate /etc/nginx/certs/"+certID.String()+".crt;")
+		assert.Contains(t, conf, "ssl_certificate_key /etc/nginx/certs/"+certID.String()+".key;")
+		assert.Equal(t, 1, strings.Count(conf, "proxy_pass http://backend;"), "only the https listener proxies")
+	})
+
+	t.Run("listener rules", func(t *testing.T) {
+		grouped := []*domain.LBTarget{
+			{InstanceID: inst1ID, Port: 8080, Weight: 1},
+			{InstanceID: inst2ID, Port: 9090, Weight: 1, TargetGroup: "api"},
+		}
+		lb.Listeners = []domain.LBListener{
+			{Port: 80, Rules: []domain.LBListenerRule{
+				{Priority: 20, Host: "*.example.com", Header: &domain.LBHeaderMatch{Name: "X-Canary", Value: "1.0"}, TargetGroup: "canary"},
+				{Priority: 10, PathPrefix: "/api/v1", TargetGroup: "api"},
+			}},
+			{Port: 8081, DefaultTargetGroup: "api"},
+		}
+		defer func() { lb.Listeners = nil }()
+
+		conf, err := adapter.generateNginxConfig(ctx, lb, grouped)
+		assert.NoError(t, err)
+		assert.Contains(t, conf, "upstream backend {")
+		assert.Contains(t, conf, "upstream backend_api {")
+		assert.NotContains(t, conf, "upstream backend_canary", "empty groups get no upstream")
+
+		assert.Contains(t, conf, "map $uri $lb_80_r0_c0 {")
+		assert.Contains(t, conf, `"~^/api/v1" 1;`)
+		assert.Contains(t, conf, `map $host $lb_80_r1_c0 {`)
+		assert.Contains(t, conf, `"~^[^.]+\.example\.com$" 1;`)
+		assert.Contains(t, conf, `map $http_x_canary $lb_80_r1_c1 {`)
+		assert.Contains(t, conf, `"~^1\.0$" 1;`)
+		assert.Contains(t, conf, `map "$lb_80_r1_c0$lb_80_r1_c1" $lb_80_r1 {`)
+		assert.Contains(t, conf, `"11" 1;`)
+		assert.Contains(t, conf, `map "$lb_80_r0$lb_80_r1" $lb_80_pool {`)
+		assert.Contains(t, conf, `default "backend";`)
+		assert.Contains(t, conf, `"~^1" "backend_api";`, "the lowest priority rule is checked first")
+		assert.Contains(t, conf, `"~^01" "";`)
+		assert.Contains(t, conf, "proxy_pass http://$lb_80_pool;")
+
+		assert.Contains(t, conf, "listen 8081;")
+		assert.Contains(t, conf, "proxy_pass http://backend_api;")
+	})
+
+	t.Run("sticky sessions and draining", func(t *testing.T) {
+		lb.StickySessions = &domain.LBStickySessions{CookieName: "SESSION", DurationSeconds: 3600}
+		lb.DeregistrationDelaySeconds = 30
+		defer func() { lb.StickySessions, lb.DeregistrationDelaySeconds = nil, 0 }()
+
+		conf, err := adapter.generateNginxConfig(ctx, lb, targets)
+		assert.NoError(t, err)
+		assert.Contains(t, conf, "worker_shutdown_timeout 30s;")
+		assert.Contains(t, conf, "map $cookie_SESSION $lb_sticky {")
+		assert.Contains(t, conf, `"" $request_id;`)
+		assert.Contains(t, conf, "hash $lb_sticky consistent;")
+		assert.NotContains(t, conf, "least_conn;", "sticky sessions replace the balancing algorithm")
+		assert.Contains(t, conf, `add_header Set-Cookie "SESSION=$lb_sticky; Path=/; Max-Age=3600; HttpOnly" always;`)
+	})
 }
@@ -135,3 +135,25 @@ func (s *LocalFileStore) Assemble(ctx context.Context, bucket, key string, parts
 
 	return totalSize, nil
 }
+
+const errErasureUnsupported = "erasure-coded storage requires the distributed object storage backend"
+
+// WriteErasure is not supported by the single-node local store.
+func (s *LocalFileStore) WriteErasure(ctx context.Context, bucket, key string, r io.Reader, layout domain.ErasureLayout) (int64, int64, error) {
+	return 0, 0, errors.New(errors.NotImplemented, errErasureUnsupported)
+}
+
+// ReadErasure is not supported by the single-node local store.
+func (s *LocalFileStore) ReadErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout, size int64) (io.ReadCloser, error) {
+	return nil, errors.New(errors.NotImplemented, errErasureUnsupported)
+}
+
+// DeleteErasure is not supported by the single-node local store.
+func (s *LocalFileStore) DeleteErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout) error {
+	return errors.New(errors.NotImplemented, errErasureUnsupported)
+}
+
+// RepairErasure is not supported by the single-node local store.
+func (s *LocalFileStore) RepairErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout, size int64) (int, error) {
+	return 0, errors.New(errors.NotImplemented, errErasureUnsupported)
+}
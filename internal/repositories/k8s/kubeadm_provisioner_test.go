@@ -144,20 +144,43 @@ func (m *MockSecurityGroupService) DetachFromInstance(ctx context.Context, insta
 	return m.Called(ctx, instanceID, groupID).Error(0)
 }
 
+func (m *MockSecurityGroupService) DetachAllFromInstance(ctx context.Context, instanceID uuid.UUID) error {
+	return m.Called(ctx, instanceID).Error(0)
+}
+
+func (m *MockSecurityGroupService) SyncInstanceAddresses(ctx context.Context, instanceID uuid.UUID, previous, current []string) error {
+	return m.Called(ctx, instanceID, previous, current).Error(0)
+}
+
 type MockStorageService struct{ mock.Mock }
 
+func (m *MockStorageService) PutObject(ctx context.Context, bucket, key string, r io.Reader, cond domain.Preconditions) (*domain.Object, error) {
+	return nil, nil
+}
+func (m *MockStorageService) GetObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.ObjectContent, error) {
+	return nil, nil
+}
+func (m *MockStorageService) HeadObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.Object, error) {
+	return nil, nil
+}
+func (m *MockStorageService) DeleteObjectIf(ctx context.Context, bucket, key, versionID string, cond domain.Preconditions) error {
+	return nil
+}
 func (m *MockStorageService) Upload(ctx context.Context, bucket, key string, r io.Reader) (*domain.Object, error) {
 	return nil, nil
 }
 func (m *MockStorageService) Download(ctx context.Context, bucket, key string) (io.ReadCloser, *domain.Object, error) {
 	return nil, nil, nil
 }
-func (m *MockStorageService) ListObjects(ctx context.Context, bucket string) ([]*domain.Object, error) {
+func (m *MockStorageService) ListObjects(ctx context.Context, bucket string, opts domain.ObjectListOptions) (*domain.ObjectListResult, error) {
 	return nil, nil
 }
 func (m *MockStorageService) DeleteObject(ctx context.Context, bucket, key string) error {
 	return nil
 }
+func (m *MockStorageService) ExpireObject(ctx context.Context, bucket, key string) error {
+	return nil
+}
 func (m *MockStorageService) DownloadVersion(ctx context.Context, bucket, key, versionID string) (io.ReadCloser, *domain.Object, error) {
 	return nil, nil, nil
 }
@@ -185,6 +208,69 @@ func (m *MockStorageService) GetClusterStatus(ctx context.Context) (*domain.Stor
 func (m *MockStorageService) SetBucketVersioning(ctx context.Context, name string, enabled bool) error {
 	return nil
 }
+func (m *MockStorageService) SetBucketStorageClass(ctx context.Context, name string, class domain.StorageClass, layout domain.ErasureLayout) (*domain.Bucket, error) {
+	return nil, nil
+}
+func (m *MockStorageService) GetBucketPolicy(ctx context.Context, bucket string) (*domain.BucketPolicy, error) {
+	return nil, nil
+}
+func (m *MockStorageService) PutBucketPolicy(ctx context.Context, bucket string, statements []domain.BucketPolicyStatement) (*domain.BucketPolicy, error) {
+	return nil, nil
+}
+func (m *MockStorageService) DeleteBucketPolicy(ctx context.Context, bucket string) error {
+	return nil
+}
+func (m *MockStorageService) GetBucketNotifications(ctx context.Context, bucket string) (*domain.BucketNotificationConfig, error) {
+	return nil, nil
+}
+func (m *MockStorageService) PutBucketNotifications(ctx context.Context, bucket string, rules []domain.BucketNotificationRule) (*domain.BucketNotificationConfig, error) {
+	return nil, nil
+}
+func (m *MockStorageService) DeleteBucketNotifications(ctx context.Context, bucket string) error {
+	return nil
+}
+func (m *MockStorageService) SetBucketObjectLock(ctx context.Context, bucket string, cfg domain.ObjectLockConfig) (*domain.Bucket, error) {
+	return &domain.Bucket{Name: bucket, ObjectLockEnabled: cfg.Enabled}, nil
+}
+func (m *MockStorageService) PutObjectRetention(ctx context.Context, bucket, key, versionID string, retention *domain.ObjectRetention) error {
+	return nil
+}
+func (m *MockStorageService) PutObjectLegalHold(ctx context.Context, bucket, key, versionID string, on bool) error {
+	return nil
+}
+func (m *MockStorageService) GetBucketReplication(ctx context.Context, bucket string) (*domain.BucketReplicationConfig, error) {
+	return nil, nil
+}
+func (m *MockStorageService) PutBucketReplication(ctx context.Context, bucket string, rules []domain.ReplicationRule) (*domain.BucketReplicationConfig, error) {
+	return nil, nil
+}
+func (m *MockStorageService) DeleteBucketReplication(ctx context.Context, bucket string) error {
+	return nil
+}
+func (m *MockStorageService) GetObjectReplication(ctx context.Context, bucket, key string) ([]*domain.ObjectReplication, error) {
+	return nil, nil
+}
+func (m *MockStorageService) GetReplicationBacklog(ctx context.Context, bucket string) (*domain.ReplicationBacklog, error) {
+	return nil, nil
+}
+func (m *MockStorageService) SetBucketEncryption(ctx context.Context, bucket string, enabled bool) (*domain.Bucket, error) {
+	return nil, nil
+}
+func (m *MockStorageService) RotateBucketKey(ctx context.Context, bucket string, reencrypt bool) (*domain.Bucket, error) {
+	return nil, nil
+}
+func (m *MockStorageService) ReencryptObjects(ctx context.Context, bucket string, limit int) (int, error) {
+	return 0, nil
+}
+func (m *MockStorageService) SetObjectACL(ctx context.Context, bucket, key, versionID string, acl domain.ObjectACL) error {
+	return nil
+}
+func (m *MockStorageService) SetObjectTags(ctx context.Context, bucket, key, versionID string, tags map[string]string) error {
+	return nil
+}
+func (m *MockStorageService) TransitionObject(ctx context.Context, bucket, key, versionID string, class domain.StorageClass) error {
+	return nil
+}
 func (m *MockStorageService) GeneratePresignedURL(ctx context.Context, bucket, key, method string, expiry time.Duration) (*domain.PresignedURL, error) {
 	return nil, nil
 }
@@ -205,6 +291,10 @@ func (m *MockStorageService) CleanupDeleted(ctx context.Context, limit int) (int
 	return 0, nil
 }
 
+func (m *MockStorageService) RepairErasureObjects(ctx context.Context, afterID uuid.UUID, limit int) (uuid.UUID, int, error) {
+	return uuid.Nil, 0, nil
+}
+
 type MockLBService struct{ mock.Mock }
 
 func (m *MockLBService) Create(ctx context.Context, name string, vpcID uuid.UUID, port int, algo string, idempotencyKey string) (*domain.LoadBalancer, error) {
@@ -224,12 +314,27 @@ func (m *MockLBService) List(ctx context.Context) ([]*domain.LoadBalancer, error
 func (m *MockLBService) Delete(ctx context.Context, idOrName string) error {
 	return m.Called(ctx, idOrName).Error(0)
 }
+func (m *MockLBService) ConfigureTLS(ctx context.Context, idOrName string, cfg *domain.LBTLSConfig) (*domain.LoadBalancer, error) {
+	return nil, nil
+}
+func (m *MockLBService) ConfigureListeners(ctx context.Context, idOrName string, listeners []domain.LBListener) (*domain.LoadBalancer, error) {
+	return nil, nil
+}
+func (m *MockLBService) ConfigureAttributes(ctx context.Context, idOrName string, params ports.UpdateLBAttributesParams) (*domain.LoadBalancer, error) {
+	return nil, nil
+}
+func (m *MockLBService) AddTargetToGroup(ctx context.Context, lbID, instanceID uuid.UUID, port int, weight int, group string) error {
+	return m.Called(ctx, lbID, instanceID, port, weight, group).Error(0)
+}
 func (m *MockLBService) AddTarget(ctx context.Context, lbID, instanceID uuid.UUID, port int, weight int) error {
 	return m.Called(ctx, lbID, instanceID, port, weight).Error(0)
 }
 func (m *MockLBService) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID) error {
 	return nil
 }
+func (m *MockLBService) RemoveTargetFromGroup(ctx context.Context, lbID, instanceID uuid.UUID, group string) error {
+	return nil
+}
 func (m *MockLBService) ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain.LBTarget, error) {
 	return nil, nil
 }
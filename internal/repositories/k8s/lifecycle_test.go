@@ -67,8 +67,8 @@ func TestK8sProvisionerLifecycle(t *testing.T) {
 
 	// Core Services
 	sgSvc := services.NewSecurityGroupService(sgRepo, vpcRepo, netBackend, auditSvc, logger)
-	storageSvc := services.NewStorageService(storageRepo, nil, auditSvc, nil, &platform.Config{})
-	lbSvc := services.NewLBService(lbRepo, vpcRepo, instanceRepo, auditSvc)
+	storageSvc := services.NewStorageService(storageRepo, nil, auditSvc, nil, nil, nil, &platform.Config{})
+	lbSvc := services.NewLBService(services.LBServiceParams{LBRepo: lbRepo, VpcRepo: vpcRepo, InstanceRepo: instanceRepo, AuditSvc: auditSvc})
 
 	// InstanceService: The real one!
 	// We use a SyncTaskQueue to make the provisioning synchronous in the test.
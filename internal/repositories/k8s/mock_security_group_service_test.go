@@ -58,3 +58,11 @@ func (m *MockSecurityGroupService) AttachToInstance(ctx context.Context, instanc
 func (m *MockSecurityGroupService) DetachFromInstance(ctx context.Context, instanceID, groupID uuid.UUID) error {
 	return m.Called(ctx, instanceID, groupID).Error(0)
 }
+
+func (m *MockSecurityGroupService) DetachAllFromInstance(ctx context.Context, instanceID uuid.UUID) error {
+	return m.Called(ctx, instanceID).Error(0)
+}
+
+func (m *MockSecurityGroupService) SyncInstanceAddresses(ctx context.Context, instanceID uuid.UUID, previous, current []string) error {
+	return m.Called(ctx, instanceID, previous, current).Error(0)
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"text/template"
 
 	"github.com/google/uuid"
@@ -18,6 +20,7 @@ import (
 const (
 	nginxConfigFileName = "nginx.conf"
 	nginxPidFileName    = "nginx.pid"
+	nginxCertDirName    = "certs"
 )
 
 // LBProxyAdapter manages host-level Nginx load balancer proxies.
@@ -40,11 +43,17 @@ func (a *LBProxyAdapter) DeployProxy(ctx context.Context, lb *domain.LoadBalance
 		return "", err
 	}
 
-	configDir := filepath.Join("/tmp", "thecloud", "lb", lb.ID.String())
+	configDir := lbConfigDir(lb.ID)
 	if err := os.MkdirAll(configDir, 0750); err != nil {
 		return "", err
 	}
 
+	// Redeploying (e.g. to change the HTTPS listener) replaces a running proxy.
+	a.stopNginx(ctx, configDir)
+
+	if err := writeCertificates(filepath.Join(configDir, nginxCertDirName), lb.TLS); err != nil {
+		return "", err
+	}
 	configPath := filepath.Join(configDir, nginxConfigFileName)
 	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
 		return "", err
@@ -62,18 +71,26 @@ func (a *LBProxyAdapter) DeployProxy(ctx context.Context, lb *domain.LoadBalance
 }
 
 func (a *LBProxyAdapter) RemoveProxy(ctx context.Context, lbID uuid.UUID) error {
-	configDir := filepath.Join("/tmp", "thecloud", "lb", lbID.String())
-	pidPath := filepath.Join(configDir, nginxPidFileName)
+	configDir := lbConfigDir(lbID)
+	a.stopNginx(ctx, configDir)
+	_ = os.RemoveAll(configDir)
+	return nil
+}
 
-	if _, err := os.Stat(pidPath); err == nil {
-		// Stop nginx: nginx -s stop -c ...
-		configPath := filepath.Join(configDir, nginxConfigFileName)
-		cmd := a.execCommandContext(ctx, "nginx", "-c", configPath, "-g", fmt.Sprintf("pid %s;", pidPath), "-s", "stop")
-		_ = cmd.Run()
+// stopNginx stops the nginx instance running from configDir, if any.
+func (a *LBProxyAdapter) stopNginx(ctx context.Context, configDir string) {
+	pidPath := filepath.Join(configDir, nginxPidFileName)
+	if _, err := os.Stat(pidPath); err != nil {
+		return
 	}
+	// Stop nginx: nginx -s stop -c ...
+	configPath := filepath.Join(configDir, nginxConfigFileName)
+	cmd := a.execCommandContext(ctx, "nginx", "-c", configPath, "-g", fmt.Sprintf("pid %s;", pidPath), "-s", "stop")
+	_ = cmd.Run()
+}
 
-	_ = os.RemoveAll(configDir)
-	return nil
+func lbConfigDir(lbID uuid.UUID) string {
+	return filepath.Join("/tmp", "thecloud", "lb", lbID.String())
 }
 
 func (a *LBProxyAdapter) UpdateProxyConfig(ctx context.Context, lb *domain.LoadBalancer, targets []*domain.LBTarget) error {
@@ -82,7 +99,10 @@ func (a *LBProxyAdapter) UpdateProxyConfig(ctx context.Context, lb *domain.LoadB
 		return err
 	}
 
-	configDir := filepath.Join("/tmp", "thecloud", "lb", lb.ID.String())
+	configDir := lbConfigDir(lb.ID)
+	if err := writeCertificates(filepath.Join(configDir, nginxCertDirName), lb.TLS); err != nil {
+		return err
+	}
 	configPath := filepath.Join(configDir, nginxConfigFileName)
 	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
 		return err
@@ -96,33 +116,96 @@ func (a *LBProxyAdapter) UpdateProxyConfig(ctx context.Context, lb *domain.LoadB
 
 func (a *LBProxyAdapter) generateNginxConfig(ctx context.Context, lb *domain.LoadBalancer, targets []*domain.LBTarget) (string, error) {
 	tmplRaw := `
+{{define "proxy"}}
+            proxy_set_header Host $host;
+            proxy_set_header X-Real-IP $remote_addr;
+            {{if .ForwardProto}}proxy_set_header X-Forwarded-Proto $scheme;{{end}}
+            {{with .Sticky}}add_header Set-Cookie "{{.CookieName}}=$lb_sticky; Path=/; Max-Age={{.DurationSeconds}}; HttpOnly" always;{{end}}
+{{end}}
+{{define "location"}}
+        location / {
+            {{if .Pool}}
+            if (${{.Pool}} = "") {
+                return 503 "No targets available";
+            }
+            proxy_pass http://${{.Pool}};
+            {{template "proxy" .}}
+            {{else if .Default}}
+            proxy_pass http://{{.Default}};
+            {{template "proxy" .}}
+            {{else}}
+            return 503 "No targets available";
+            {{end}}
+        }
+{{end}}
 user root;
+{{if .ShutdownTimeout}}worker_shutdown_timeout {{.ShutdownTimeout}}s;{{end}}
 events {
     worker_connections 1024;
 }
 
 http {
-    {{if .Targets}}
-    upstream backend {
+    {{range .Upstreams}}
+    upstream {{.Name}} {
         {{range .Targets}}
         server {{.IP}}:{{.Port}} weight={{.Weight}};
         {{end}}
-        {{if .LeastConn}}least_conn;{{end}}
+        {{if $.Sticky}}hash $lb_sticky consistent;{{else if $.LeastConn}}least_conn;{{end}}
     }
     {{end}}
 
+    {{with .Sticky}}
+    map $cookie_{{.CookieName}} $lb_sticky {
+        "" $request_id;
+        default $cookie_{{.CookieName}};
+    }
+    {{end}}
+
+    {{range .Listeners}}{{if .Pool}}
+    {{range .Rules}}{{range .Conditions}}
+    map {{.Source}} ${{.Var}} {
+        default 0;
+        "{{.Pattern}}" 1;
+    }
+    {{end}}
+    map "{{.Key}}" ${{.Var}} {
+        default 0;
+        "{{.Match}}" 1;
+    }
+    {{end}}
+    map "{{.PoolKey}}" ${{.Pool}} {
+        default "{{.Default}}";
+        {{range .Rules}}
+        "~^{{.Prefix}}" "{{.Upstream}}";
+        {{end}}
+    }
+    {{end}}{{end}}
+
+    {{range .Listeners}}
     server {
         listen {{.Port}};
+        {{if .Redirect}}
         location / {
-            {{if .Targets}}
-            proxy_pass http://backend;
-            proxy_set_header Host $host;
-            proxy_set_header X-Real-IP $remote_addr;
-            {{else}}
-            return 503 "No targets available";
-            {{end}}
+            return 301 https://$host{{$.TLS.RedirectPort}}$request_uri;
         }
+        {{else}}
+        {{template "location" .}}
+        {{end}}
+    }
+    {{end}}
+
+    {{if .TLS}}
+    {{range .TLS.Certs}}
+    server {
+        listen {{$.TLS.Port}} ssl{{if .Default}} default_server{{end}};
+        server_name {{.ServerNames}};
+        ssl_certificate {{.Cert}};
+        ssl_certificate_key {{.Key}};
+        ssl_protocols TLSv1.2 TLSv1.3;
+        {{template "location" $.TLS.Routing}}
     }
+    {{end}}
+    {{end}}
 }
 `
 	type targetInfo struct {
@@ -130,29 +213,87 @@ http {
 		Port   int
 		Weight int
 	}
+	type certInfo struct {
+		Cert        string
+		Key         string
+		ServerNames string
+		Default     bool
+	}
+	type tlsInfo struct {
+		Port         int
+		RedirectHTTP bool
+		RedirectPort string // ":<port>" unless the HTTPS listener is on 443
+		Certs        []certInfo
+		Routing      listenerRoutes // The primary listener's routing
+	}
+	type upstreamInfo struct {
+		Name    string
+		Targets []targetInfo
+	}
 	type data struct {
-		Port      int
 		LeastConn bool
-		Targets   []targetInfo
+		// Sticky pins clients to a target by hashing a cookie; new
+		// clients are assigned by request ID and receive the cookie.
+		Sticky *domain.LBStickySessions
+		// ShutdownTimeout lets old workers finish in-flight requests
+		// for the deregistration delay after a reload.
+		ShutdownTimeout int
+		Upstreams       []upstreamInfo
+		Listeners       []listenerRoutes
+		TLS             *tlsInfo
 	}
 
 	d := data{
-		Port:      lb.Port,
-		LeastConn: lb.Algorithm == "least-conn",
+		LeastConn:       lb.Algorithm == "least-conn",
+		Sticky:          lb.StickySessions,
+		ShutdownTimeout: lb.DeregistrationDelaySeconds,
 	}
 
+	groups := make(map[string]int)
 	for _, t := range targets {
 		ip, err := a.compute.GetInstanceIP(ctx, t.InstanceID.String())
 		if err != nil {
 			continue
 		}
-		d.Targets = append(d.Targets, targetInfo{
+		group := t.TargetGroup
+		if group == "" {
+			group = domain.DefaultLBTargetGroup
+		}
+		i, ok := groups[group]
+		if !ok {
+			i = len(d.Upstreams)
+			groups[group] = i
+			d.Upstreams = append(d.Upstreams, upstreamInfo{Name: upstreamName(group)})
+		}
+		d.Upstreams[i].Targets = append(d.Upstreams[i].Targets, targetInfo{
 			IP:     ip,
 			Port:   t.Port,
 			Weight: t.Weight,
 		})
 	}
 
+	d.Listeners = buildListenerRoutes(lb, func(group string) bool {
+		_, ok := groups[group]
+		return ok
+	})
+
+	if lb.TLS != nil {
+		certDir := filepath.Join(lbConfigDir(lb.ID), nginxCertDirName)
+		d.TLS = &tlsInfo{Port: lb.TLS.Port, RedirectHTTP: lb.TLS.RedirectHTTP, Routing: d.Listeners[0]}
+		d.TLS.Routing.Redirect = false
+		if lb.TLS.Port != 443 {
+			d.TLS.RedirectPort = fmt.Sprintf(":%d", lb.TLS.Port)
+		}
+		for i, c := range lb.TLS.Certificates {
+			d.TLS.Certs = append(d.TLS.Certs, certInfo{
+				Cert:        filepath.Join(certDir, c.CertificateID.String()+".crt"),
+				Key:         filepath.Join(certDir, c.CertificateID.String()+".key"),
+				ServerNames: strings.Join(c.Domains, " "),
+				Default:     i == 0,
+			})
+		}
+	}
+
 	tmpl, err := template.New("nginx").Parse(tmplRaw)
 	if err != nil {
 		return "", err
@@ -165,3 +306,124 @@ http {
 
 	return buf.String(), nil
 }
+
+// listenerRoutes is the nginx routing of one plain HTTP listener. Without
+// rules the listener proxies to Default directly; with rules, maps evaluate
+// each rule's conditions and select the upstream into the Pool variable.
+type listenerRoutes struct {
+	Port         int
+	Redirect     bool                     // Redirect to the HTTPS listener instead of proxying
+	ForwardProto bool                     // Set X-Forwarded-Proto
+	Sticky       *domain.LBStickySessions // Issue the sticky session cookie
+	Default      string                   // Upstream of the default target group; empty if it has no targets
+	Pool         string                   // Variable holding the selected upstream; empty without rules
+	PoolKey      string
+	Rules        []ruleRoutes
+}
+
+type ruleRoutes struct {
+	Var        string
+	Key        string // Concatenated condition variables
+	Match      string // Key value when every condition matches
+	Prefix     string // Pool key prefix selecting this rule: the rules before it unmatched
+	Upstream   string
+	Conditions []conditionRoutes
+}
+
+type conditionRoutes struct {
+	Var     string
+	Source  string
+	Pattern string
+}
+
+// upstreamName returns the nginx upstream of a target group. The default
+// group keeps the name used before target groups existed.
+func upstreamName(group string) string {
+	if group == domain.DefaultLBTargetGroup {
+		return "backend"
+	}
+	return "backend_" + group
+}
+
+// buildListenerRoutes renders the load balancer's effective listeners for
+// the nginx template. hasTargets reports whether a target group has targets;
+// requests routed to an empty group get a 503. Listener values are validated
+// by the service, so they are safe to place in quoted nginx strings.
+func buildListenerRoutes(lb *domain.LoadBalancer, hasTargets func(group string) bool) []listenerRoutes {
+	upstream := func(group string) string {
+		if !hasTargets(group) {
+			return ""
+		}
+		return upstreamName(group)
+	}
+
+	listeners := lb.EffectiveListeners()
+	routes := make([]listenerRoutes, 0, len(listeners))
+	for i, l := range listeners {
+		lr := listenerRoutes{
+			Port:         l.Port,
+			Redirect:     i == 0 && lb.TLS != nil && lb.TLS.RedirectHTTP,
+			ForwardProto: lb.TLS != nil,
+			Sticky:       lb.StickySessions,
+			Default:      upstream(l.DefaultTargetGroup),
+		}
+		if len(l.Rules) > 0 {
+			lr.Pool = fmt.Sprintf("lb_%d_pool", l.Port)
+		}
+		for j, r := range l.Rules {
+			rr := ruleRoutes{
+				Var:      fmt.Sprintf("lb_%d_r%d", l.Port, j),
+				Prefix:   strings.Repeat("0", j) + "1",
+				Upstream: upstream(r.TargetGroup),
+			}
+			add := func(source, pattern string) {
+				c := conditionRoutes{Var: fmt.Sprintf("%s_c%d", rr.Var, len(rr.Conditions)), Source: source, Pattern: pattern}
+				rr.Conditions = append(rr.Conditions, c)
+				rr.Key += "$" + c.Var
+				rr.Match += "1"
+			}
+			if r.Host != "" {
+				host := regexp.QuoteMeta(r.Host)
+				if strings.HasPrefix(r.Host, "*.") {
+					host = "[^.]+" + regexp.QuoteMeta(r.Host[1:])
+				}
+				add("$host", "~^"+host+"$")
+			}
+			if r.PathPrefix != "" {
+				add("$uri", "~^"+regexp.QuoteMeta(r.PathPrefix))
+			}
+			if r.Header != nil {
+				header := "$http_" + strings.ReplaceAll(strings.ToLower(r.Header.Name), "-", "_")
+				add(header, "~^"+regexp.QuoteMeta(r.Header.Value)+"$")
+			}
+			lr.PoolKey += "$" + rr.Var
+			lr.Rules = append(lr.Rules, rr)
+		}
+		routes = append(routes, lr)
+	}
+	return routes
+}
+
+// writeCertificates writes the HTTPS listener's certificates and keys to dir
+// for nginx, replacing any from a previous configuration.
+func writeCertificates(dir string, cfg *domain.LBTLSConfig) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if cfg == nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	for _, c := range cfg.Certificates {
+		base := filepath.Join(dir, c.CertificateID.String())
+		if err := os.WriteFile(base+".crt", []byte(c.CertificatePEM), 0600); err != nil {
+			return err
+		}
+		if err := os.WriteFile(base+".key", []byte(c.PrivateKeyPEM), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
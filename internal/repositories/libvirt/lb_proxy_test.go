@@ -120,4 +120,76 @@ func TestLBProxyAdapter(t *testing.T) {
 		_, err = os.Stat(configDir)
 		assert.True(t, os.IsNotExist(err))
 	})
+
+	t.Run("DeployProxyWithTLS", func(t *testing.T) {
+		certID := uuid.New()
+		tlsLB := &domain.LoadBalancer{
+			ID:        uuid.New(),
+			Port:      80,
+			Algorithm: "round-robin",
+			TLS: &domain.LBTLSConfig{
+				Port: 8443,
+				Certificates: []*domain.CertificateKeyPair{{
+					CertificateID: certID, Domains: []string{"app.example.com"}, CertificatePEM: "CERT", PrivateKeyPEM: "KEY",
+				}},
+			},
+		}
+		configDir := filepath.Join("/tmp", "thecloud", "lb", tlsLB.ID.String())
+		defer func() { _ = os.RemoveAll(configDir) }()
+
+		_, err := adapter.DeployProxy(ctx, tlsLB, targets)
+		assert.NoError(t, err)
+
+		keyPath := filepath.Join(configDir, "certs", certID.String()+".key")
+		key, err := os.ReadFile(filepath.Clean(keyPath))
+		assert.NoError(t, err)
+		assert.Equal(t, "KEY", string(key))
+		info, err := os.Stat(keyPath)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		content, _ := os.ReadFile(filepath.Clean(filepath.Join(configDir, "nginx.conf")))
+		assert.Contains(t, string(content), "listen 8443 ssl default_server;")
+		assert.Contains(t, string(content), "server_name app.example.com;")
+		assert.Contains(t, string(content), "ssl_certificate_key "+keyPath+";")
+		assert.NotContains(t, string(content), "return 301")
+	})
+
+	t.Run("ListenerRules", func(t *testing.T) {
+		apiTarget := &domain.LBTarget{InstanceID: uuid.New(), Port: 9000, Weight: 1, TargetGroup: "api"}
+		mc.On("GetInstanceIP", mock.Anything, apiTarget.InstanceID.String()).Return("10.0.0.2", nil)
+		ruledLB := &domain.LoadBalancer{
+			ID:   uuid.New(),
+			Port: 80,
+			Listeners: []domain.LBListener{{
+				Port:  80,
+				Rules: []domain.LBListenerRule{{Priority: 1, Host: "api.example.com", TargetGroup: "api"}},
+			}},
+		}
+
+		conf, err := adapter.generateNginxConfig(ctx, ruledLB, append([]*domain.LBTarget{apiTarget}, targets...))
+		assert.NoError(t, err)
+		assert.Contains(t, conf, "server 10.0.0.2:9000 weight=1;")
+		assert.Contains(t, conf, "upstream backend_api {")
+		assert.Contains(t, conf, `"~^api\.example\.com$" 1;`)
+		assert.Contains(t, conf, `"~^1" "backend_api";`)
+		assert.Contains(t, conf, `default "backend";`)
+		assert.Contains(t, conf, "proxy_pass http://$lb_80_pool;")
+	})
+
+	t.Run("StickySessions", func(t *testing.T) {
+		stickyLB := &domain.LoadBalancer{
+			ID:                         uuid.New(),
+			Port:                       80,
+			StickySessions:             &domain.LBStickySessions{CookieName: "SESSION", DurationSeconds: 600},
+			DeregistrationDelaySeconds: 15,
+		}
+
+		conf, err := adapter.generateNginxConfig(ctx, stickyLB, targets)
+		assert.NoError(t, err)
+		assert.Contains(t, conf, "worker_shutdown_timeout 15s;")
+		assert.Contains(t, conf, "map $cookie_SESSION $lb_sticky {")
+		assert.Contains(t, conf, "hash $lb_sticky consistent;")
+		assert.Contains(t, conf, `add_header Set-Cookie "SESSION=$lb_sticky; Path=/; Max-Age=600; HttpOnly" always;`)
+	})
 }
@@ -3,6 +3,7 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/poyrazk/thecloud/internal/core/domain"
@@ -11,8 +12,9 @@ import (
 
 // MockGlobalLBRepo is a mock implementation of the GlobalLBRepository port.
 type MockGlobalLBRepo struct {
-	GLBs      map[uuid.UUID]*domain.GlobalLoadBalancer
-	Endpoints map[uuid.UUID][]*domain.GlobalEndpoint
+	GLBs         map[uuid.UUID]*domain.GlobalLoadBalancer
+	Endpoints    map[uuid.UUID][]*domain.GlobalEndpoint
+	HealthChecks []*domain.GlobalEndpointHealthCheck
 }
 
 // NewMockGlobalLBRepo creates a new instance of MockGlobalLBRepo.
@@ -113,5 +115,39 @@ func (m *MockGlobalLBRepo) UpdateEndpointHealth(ctx context.Context, epID uuid.U
 	return nil
 }
 
+func (m *MockGlobalLBRepo) ListAll(ctx context.Context) ([]*domain.GlobalLoadBalancer, error) {
+	list := make([]*domain.GlobalLoadBalancer, 0, len(m.GLBs))
+	for _, glb := range m.GLBs {
+		list = append(list, glb)
+	}
+	return list, nil
+}
+
+func (m *MockGlobalLBRepo) RecordHealthCheck(ctx context.Context, check *domain.GlobalEndpointHealthCheck) error {
+	m.HealthChecks = append(m.HealthChecks, check)
+	return nil
+}
+
+func (m *MockGlobalLBRepo) ListHealthChecks(ctx context.Context, endpointID uuid.UUID, limit int) ([]*domain.GlobalEndpointHealthCheck, error) {
+	var list []*domain.GlobalEndpointHealthCheck
+	for i := len(m.HealthChecks) - 1; i >= 0 && len(list) < limit; i-- {
+		if m.HealthChecks[i].EndpointID == endpointID {
+			list = append(list, m.HealthChecks[i])
+		}
+	}
+	return list, nil
+}
+
+func (m *MockGlobalLBRepo) DeleteHealthChecksBefore(ctx context.Context, before time.Time) error {
+	var kept []*domain.GlobalEndpointHealthCheck
+	for _, hc := range m.HealthChecks {
+		if !hc.CheckedAt.Before(before) {
+			kept = append(kept, hc)
+		}
+	}
+	m.HealthChecks = kept
+	return nil
+}
+
 // Ensure interface satisfaction
 var _ ports.GlobalLBRepository = (*MockGlobalLBRepo)(nil)
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/poyrazk/thecloud/internal/core/domain"
@@ -70,12 +71,17 @@ func (m *MockLBRepo) ListAll(ctx context.Context) ([]*domain.LoadBalancer, error
 	return m.List(ctx)
 }
 
-func (m *MockLBRepo) AddTarget(ctx context.Context, target *domain.LBTarget) error       { return nil }
-func (m *MockLBRepo) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID) error { return nil }
+func (m *MockLBRepo) AddTarget(ctx context.Context, target *domain.LBTarget) error { return nil }
+func (m *MockLBRepo) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID, group string) error {
+	return nil
+}
 func (m *MockLBRepo) ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain.LBTarget, error) {
 	return nil, nil
 }
-func (m *MockLBRepo) UpdateTargetHealth(ctx context.Context, lbID, instanceID uuid.UUID, health string) error {
+func (m *MockLBRepo) UpdateTargetHealth(ctx context.Context, lbID, instanceID uuid.UUID, group string, port int, health string) error {
+	return nil
+}
+func (m *MockLBRepo) DrainTarget(ctx context.Context, lbID, instanceID uuid.UUID, group string, until time.Time) error {
 	return nil
 }
 func (m *MockLBRepo) GetTargetsForInstance(ctx context.Context, instanceID uuid.UUID) ([]*domain.LBTarget, error) {
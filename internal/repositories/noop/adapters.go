@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
 	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/ports"
 )
@@ -281,10 +282,23 @@ func (s *NoopStorageService) Upload(ctx context.Context, bucket, key string, r i
 func (s *NoopStorageService) Download(ctx context.Context, bucket, key string) (io.ReadCloser, *domain.Object, error) {
 	return io.NopCloser(strings.NewReader("data")), &domain.Object{Bucket: bucket, Key: key}, nil
 }
-func (s *NoopStorageService) ListObjects(ctx context.Context, bucket string) ([]*domain.Object, error) {
-	return []*domain.Object{}, nil
+func (s *NoopStorageService) PutObject(ctx context.Context, bucket, key string, r io.Reader, cond domain.Preconditions) (*domain.Object, error) {
+	return &domain.Object{Key: key}, nil
+}
+func (s *NoopStorageService) GetObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.ObjectContent, error) {
+	return &domain.ObjectContent{Object: &domain.Object{Bucket: bucket, Key: key}, Body: io.NopCloser(strings.NewReader("data")), Size: 4}, nil
+}
+func (s *NoopStorageService) HeadObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.Object, error) {
+	return &domain.Object{Bucket: bucket, Key: key}, nil
+}
+func (s *NoopStorageService) DeleteObjectIf(ctx context.Context, bucket, key, versionID string, cond domain.Preconditions) error {
+	return nil
+}
+func (s *NoopStorageService) ListObjects(ctx context.Context, bucket string, opts domain.ObjectListOptions) (*domain.ObjectListResult, error) {
+	return &domain.ObjectListResult{Bucket: bucket, Objects: []*domain.Object{}}, nil
 }
 func (s *NoopStorageService) DeleteObject(ctx context.Context, bucket, key string) error { return nil }
+func (s *NoopStorageService) ExpireObject(ctx context.Context, bucket, key string) error { return nil }
 func (s *NoopStorageService) DownloadVersion(ctx context.Context, bucket, key, versionID string) (io.ReadCloser, *domain.Object, error) {
 	return io.NopCloser(strings.NewReader("data")), &domain.Object{Bucket: bucket, Key: key, VersionID: versionID}, nil
 }
@@ -307,9 +321,72 @@ func (s *NoopStorageService) ListBuckets(ctx context.Context) ([]*domain.Bucket,
 func (s *NoopStorageService) SetBucketVersioning(ctx context.Context, name string, enabled bool) error {
 	return nil
 }
+func (s *NoopStorageService) SetBucketStorageClass(ctx context.Context, name string, class domain.StorageClass, layout domain.ErasureLayout) (*domain.Bucket, error) {
+	return &domain.Bucket{Name: name, StorageClass: class, DataShards: layout.DataShards, ParityShards: layout.ParityShards}, nil
+}
 func (s *NoopStorageService) GetClusterStatus(ctx context.Context) (*domain.StorageCluster, error) {
 	return &domain.StorageCluster{}, nil
 }
+func (s *NoopStorageService) GetBucketPolicy(ctx context.Context, bucket string) (*domain.BucketPolicy, error) {
+	return &domain.BucketPolicy{Bucket: bucket}, nil
+}
+func (s *NoopStorageService) PutBucketPolicy(ctx context.Context, bucket string, statements []domain.BucketPolicyStatement) (*domain.BucketPolicy, error) {
+	return &domain.BucketPolicy{Bucket: bucket, Statements: statements}, nil
+}
+func (s *NoopStorageService) DeleteBucketPolicy(ctx context.Context, bucket string) error {
+	return nil
+}
+func (s *NoopStorageService) GetBucketNotifications(ctx context.Context, bucket string) (*domain.BucketNotificationConfig, error) {
+	return &domain.BucketNotificationConfig{Bucket: bucket}, nil
+}
+func (s *NoopStorageService) PutBucketNotifications(ctx context.Context, bucket string, rules []domain.BucketNotificationRule) (*domain.BucketNotificationConfig, error) {
+	return &domain.BucketNotificationConfig{Bucket: bucket, Rules: rules}, nil
+}
+func (s *NoopStorageService) DeleteBucketNotifications(ctx context.Context, bucket string) error {
+	return nil
+}
+func (s *NoopStorageService) SetBucketObjectLock(ctx context.Context, bucket string, cfg domain.ObjectLockConfig) (*domain.Bucket, error) {
+	return &domain.Bucket{Name: bucket, ObjectLockEnabled: cfg.Enabled, DefaultRetentionMode: cfg.DefaultMode, DefaultRetentionDays: cfg.DefaultDays}, nil
+}
+func (s *NoopStorageService) PutObjectRetention(ctx context.Context, bucket, key, versionID string, retention *domain.ObjectRetention) error {
+	return nil
+}
+func (s *NoopStorageService) PutObjectLegalHold(ctx context.Context, bucket, key, versionID string, on bool) error {
+	return nil
+}
+func (s *NoopStorageService) GetBucketReplication(ctx context.Context, bucket string) (*domain.BucketReplicationConfig, error) {
+	return &domain.BucketReplicationConfig{Bucket: bucket}, nil
+}
+func (s *NoopStorageService) PutBucketReplication(ctx context.Context, bucket string, rules []domain.ReplicationRule) (*domain.BucketReplicationConfig, error) {
+	return &domain.BucketReplicationConfig{Bucket: bucket, Rules: rules}, nil
+}
+func (s *NoopStorageService) DeleteBucketReplication(ctx context.Context, bucket string) error {
+	return nil
+}
+func (s *NoopStorageService) GetObjectReplication(ctx context.Context, bucket, key string) ([]*domain.ObjectReplication, error) {
+	return nil, nil
+}
+func (s *NoopStorageService) GetReplicationBacklog(ctx context.Context, bucket string) (*domain.ReplicationBacklog, error) {
+	return &domain.ReplicationBacklog{Bucket: bucket}, nil
+}
+func (s *NoopStorageService) SetBucketEncryption(ctx context.Context, bucket string, enabled bool) (*domain.Bucket, error) {
+	return &domain.Bucket{Name: bucket}, nil
+}
+func (s *NoopStorageService) RotateBucketKey(ctx context.Context, bucket string, reencrypt bool) (*domain.Bucket, error) {
+	return &domain.Bucket{Name: bucket}, nil
+}
+func (s *NoopStorageService) ReencryptObjects(ctx context.Context, bucket string, limit int) (int, error) {
+	return 0, nil
+}
+func (s *NoopStorageService) SetObjectACL(ctx context.Context, bucket, key, versionID string, acl domain.ObjectACL) error {
+	return nil
+}
+func (s *NoopStorageService) SetObjectTags(ctx context.Context, bucket, key, versionID string, tags map[string]string) error {
+	return nil
+}
+func (s *NoopStorageService) TransitionObject(ctx context.Context, bucket, key, versionID string, class domain.StorageClass) error {
+	return nil
+}
 func (s *NoopStorageService) CreateMultipartUpload(ctx context.Context, bucket, key string) (*domain.MultipartUpload, error) {
 	return &domain.MultipartUpload{Bucket: bucket, Key: key}, nil
 }
@@ -325,6 +402,9 @@ func (s *NoopStorageService) AbortMultipartUpload(ctx context.Context, uploadID
 func (s *NoopStorageService) CleanupDeleted(ctx context.Context, limit int) (int, error) {
 	return 0, nil
 }
+func (s *NoopStorageService) RepairErasureObjects(ctx context.Context, afterID uuid.UUID, limit int) (uuid.UUID, int, error) {
+	return uuid.Nil, 0, nil
+}
 func (s *NoopStorageService) GeneratePresignedURL(ctx context.Context, bucket, key, method string, expiry time.Duration) (*domain.PresignedURL, error) {
 	return &domain.PresignedURL{}, nil
 }
@@ -343,12 +423,27 @@ func (s *NoopLBService) List(ctx context.Context) ([]*domain.LoadBalancer, error
 	return []*domain.LoadBalancer{}, nil
 }
 func (s *NoopLBService) Delete(ctx context.Context, id string) error { return nil }
+func (s *NoopLBService) ConfigureTLS(ctx context.Context, id string, cfg *domain.LBTLSConfig) (*domain.LoadBalancer, error) {
+	return &domain.LoadBalancer{TLS: cfg}, nil
+}
+func (s *NoopLBService) ConfigureListeners(ctx context.Context, id string, listeners []domain.LBListener) (*domain.LoadBalancer, error) {
+	return &domain.LoadBalancer{Listeners: listeners}, nil
+}
+func (s *NoopLBService) ConfigureAttributes(ctx context.Context, id string, params ports.UpdateLBAttributesParams) (*domain.LoadBalancer, error) {
+	return &domain.LoadBalancer{HealthCheck: params.HealthCheck, DeregistrationDelaySeconds: params.DeregistrationDelaySeconds, StickySessions: params.StickySessions}, nil
+}
+func (s *NoopLBService) AddTargetToGroup(ctx context.Context, lbID, instanceID uuid.UUID, port int, weight int, group string) error {
+	return nil
+}
 func (s *NoopLBService) AddTarget(ctx context.Context, lbID, instanceID uuid.UUID, port int, weight int) error {
 	return nil
 }
 func (s *NoopLBService) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID) error {
 	return nil
 }
+func (s *NoopLBService) RemoveTargetFromGroup(ctx context.Context, lbID, instanceID uuid.UUID, group string) error {
+	return nil
+}
 func (s *NoopLBService) ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain.LBTarget, error) {
 	return []*domain.LBTarget{}, nil
 }
@@ -420,6 +515,18 @@ func (s *NoopFileStore) GetClusterStatus(ctx context.Context) (*domain.StorageCl
 func (s *NoopFileStore) Assemble(ctx context.Context, bucket, key string, parts []string) (int64, error) {
 	return 0, nil
 }
+func (s *NoopFileStore) WriteErasure(ctx context.Context, bucket, key string, r io.Reader, layout domain.ErasureLayout) (int64, int64, error) {
+	return 0, 0, nil
+}
+func (s *NoopFileStore) ReadErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout, size int64) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("")), nil
+}
+func (s *NoopFileStore) DeleteErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout) error {
+	return nil
+}
+func (s *NoopFileStore) RepairErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout, size int64) (int, error) {
+	return 0, nil
+}
 
 type NoopDatabaseRepository struct{}
 
@@ -521,13 +628,16 @@ func (r *NoopLBRepository) Delete(ctx context.Context, id uuid.UUID) error
 func (r *NoopLBRepository) AddTarget(ctx context.Context, target *domain.LBTarget) error {
 	return nil
 }
-func (r *NoopLBRepository) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID) error {
+func (r *NoopLBRepository) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID, group string) error {
 	return nil
 }
 func (r *NoopLBRepository) ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain.LBTarget, error) {
 	return []*domain.LBTarget{}, nil
 }
-func (r *NoopLBRepository) UpdateTargetHealth(ctx context.Context, lbID, instanceID uuid.UUID, health string) error {
+func (r *NoopLBRepository) UpdateTargetHealth(ctx context.Context, lbID, instanceID uuid.UUID, group string, port int, health string) error {
+	return nil
+}
+func (r *NoopLBRepository) DrainTarget(ctx context.Context, lbID, instanceID uuid.UUID, group string, until time.Time) error {
 	return nil
 }
 func (r *NoopLBRepository) GetTargetsForInstance(ctx context.Context, instanceID uuid.UUID) ([]*domain.LBTarget, error) {
@@ -537,16 +647,25 @@ func (r *NoopLBRepository) GetTargetsForInstance(ctx context.Context, instanceID
 type NoopStorageRepository struct{}
 
 func (r *NoopStorageRepository) SaveMeta(ctx context.Context, obj *domain.Object) error { return nil }
+func (r *NoopStorageRepository) SaveMetaIf(ctx context.Context, obj, current *domain.Object) error {
+	return nil
+}
 func (r *NoopStorageRepository) GetMeta(ctx context.Context, bucket, key string) (*domain.Object, error) {
 	return &domain.Object{Bucket: bucket, Key: key}, nil
 }
-func (r *NoopStorageRepository) List(ctx context.Context, bucket string) ([]*domain.Object, error) {
-	return []*domain.Object{}, nil
+func (r *NoopStorageRepository) List(ctx context.Context, bucket string, opts domain.ObjectListOptions) (*domain.ObjectListResult, error) {
+	return &domain.ObjectListResult{Bucket: bucket, Objects: []*domain.Object{}}, nil
 }
 func (r *NoopStorageRepository) SoftDelete(ctx context.Context, bucket, key string) error { return nil }
+func (r *NoopStorageRepository) SoftDeleteIf(ctx context.Context, bucket, key string, current *domain.Object) error {
+	return nil
+}
 func (r *NoopStorageRepository) DeleteVersion(ctx context.Context, bucket, key, ver string) error {
 	return nil
 }
+func (r *NoopStorageRepository) DeleteVersionIf(ctx context.Context, bucket, key, ver string, current *domain.Object) error {
+	return nil
+}
 func (r *NoopStorageRepository) GetMetaByVersion(ctx context.Context, bucket, key, ver string) (*domain.Object, error) {
 	return &domain.Object{}, nil
 }
@@ -561,15 +680,96 @@ func (r *NoopStorageRepository) HardDelete(ctx context.Context, bucket, key, ver
 }
 func (r *NoopStorageRepository) CreateBucket(ctx context.Context, b *domain.Bucket) error { return nil }
 func (r *NoopStorageRepository) GetBucket(ctx context.Context, name string) (*domain.Bucket, error) {
-	return &domain.Bucket{Name: name}, nil
+	return &domain.Bucket{Name: name, UserID: appcontext.UserIDFromContext(ctx)}, nil
 }
 func (r *NoopStorageRepository) DeleteBucket(ctx context.Context, name string) error { return nil }
+func (r *NoopStorageRepository) BucketHasObjects(ctx context.Context, bucket string) (bool, error) {
+	return false, nil
+}
+func (r *NoopStorageRepository) ListLockedVersions(ctx context.Context, bucket string, limit int) ([]*domain.Object, error) {
+	return []*domain.Object{}, nil
+}
 func (r *NoopStorageRepository) ListBuckets(ctx context.Context, uid string) ([]*domain.Bucket, error) {
 	return []*domain.Bucket{}, nil
 }
 func (r *NoopStorageRepository) SetBucketVersioning(ctx context.Context, name string, enabled bool) error {
 	return nil
 }
+func (r *NoopStorageRepository) SetBucketStorageClass(ctx context.Context, name string, class domain.StorageClass, layout domain.ErasureLayout) error {
+	return nil
+}
+func (r *NoopStorageRepository) GetBucketPolicy(ctx context.Context, bucket string) (*domain.BucketPolicy, error) {
+	return nil, nil
+}
+func (r *NoopStorageRepository) PutBucketPolicy(ctx context.Context, policy *domain.BucketPolicy) error {
+	return nil
+}
+func (r *NoopStorageRepository) DeleteBucketPolicy(ctx context.Context, bucket string) error {
+	return nil
+}
+func (r *NoopStorageRepository) GetBucketNotifications(ctx context.Context, bucket string) (*domain.BucketNotificationConfig, error) {
+	return nil, nil
+}
+func (r *NoopStorageRepository) PutBucketNotifications(ctx context.Context, cfg *domain.BucketNotificationConfig) error {
+	return nil
+}
+func (r *NoopStorageRepository) DeleteBucketNotifications(ctx context.Context, bucket string) error {
+	return nil
+}
+func (r *NoopStorageRepository) SetBucketObjectLock(ctx context.Context, name string, cfg domain.ObjectLockConfig) error {
+	return nil
+}
+func (r *NoopStorageRepository) SetObjectRetention(ctx context.Context, bucket, key, versionID string, retention *domain.ObjectRetention) error {
+	return nil
+}
+func (r *NoopStorageRepository) SetObjectLegalHold(ctx context.Context, bucket, key, versionID string, on bool) error {
+	return nil
+}
+func (r *NoopStorageRepository) GetBucketReplication(ctx context.Context, bucket string) (*domain.BucketReplicationConfig, error) {
+	return &domain.BucketReplicationConfig{Bucket: bucket}, nil
+}
+func (r *NoopStorageRepository) PutBucketReplication(ctx context.Context, cfg *domain.BucketReplicationConfig) error {
+	return nil
+}
+func (r *NoopStorageRepository) DeleteBucketReplication(ctx context.Context, bucket string) error {
+	return nil
+}
+func (r *NoopStorageRepository) EnqueueReplication(ctx context.Context, reps []*domain.ObjectReplication) error {
+	return nil
+}
+func (r *NoopStorageRepository) ListPendingReplication(ctx context.Context, limit int) ([]*domain.ObjectReplication, error) {
+	return nil, nil
+}
+func (r *NoopStorageRepository) UpdateReplication(ctx context.Context, rep *domain.ObjectReplication) error {
+	return nil
+}
+func (r *NoopStorageRepository) ListObjectReplication(ctx context.Context, bucket, key string) ([]*domain.ObjectReplication, error) {
+	return nil, nil
+}
+func (r *NoopStorageRepository) ListReplicationBacklog(ctx context.Context, bucket string) ([]*domain.ReplicationBacklog, error) {
+	return nil, nil
+}
+func (r *NoopStorageRepository) SetBucketEncryption(ctx context.Context, name string, enabled bool, keyID string) error {
+	return nil
+}
+func (r *NoopStorageRepository) ListObjectsBelowKeyVersion(ctx context.Context, bucket string, version, limit int) ([]*domain.Object, error) {
+	return nil, nil
+}
+func (r *NoopStorageRepository) ListErasureObjects(ctx context.Context, afterID uuid.UUID, limit int) ([]*domain.Object, error) {
+	return nil, nil
+}
+func (r *NoopStorageRepository) SetObjectACL(ctx context.Context, bucket, key, versionID string, acl domain.ObjectACL) error {
+	return nil
+}
+func (r *NoopStorageRepository) SetObjectTags(ctx context.Context, bucket, key, versionID string, tags map[string]string) error {
+	return nil
+}
+func (r *NoopStorageRepository) ListVersionsPage(ctx context.Context, bucket, prefix, startAfter string, maxKeys int) ([]*domain.Object, error) {
+	return []*domain.Object{}, nil
+}
+func (r *NoopStorageRepository) ListMultipartUploads(ctx context.Context, bucket, prefix string, initiatedBefore time.Time) ([]*domain.MultipartUpload, error) {
+	return []*domain.MultipartUpload{}, nil
+}
 func (r *NoopStorageRepository) SaveMultipartUpload(ctx context.Context, u *domain.MultipartUpload) error {
 	return nil
 }
@@ -145,8 +145,8 @@ func TestNoopStorage(t *testing.T) {
 	if obj, err := store.GetMeta(ctx, "b", "k"); err != nil || obj == nil {
 		t.Fatalf("GetMeta err=%v obj=%v", err, obj)
 	}
-	if list, err := store.List(ctx, "b"); err != nil || len(list) != 0 {
-		t.Fatalf("List err=%v len=%d", err, len(list))
+	if list, err := store.List(ctx, "b", domain.ObjectListOptions{}); err != nil || len(list.Objects) != 0 {
+		t.Fatalf("List err=%v list=%v", err, list)
 	}
 	if err := store.SoftDelete(ctx, "b", "k"); err != nil {
 		t.Fatalf("SoftDelete error: %v", err)
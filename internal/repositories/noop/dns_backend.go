@@ -39,3 +39,7 @@ func (b *NoopDNSBackend) DeleteRecords(ctx context.Context, zoneName string, nam
 func (b *NoopDNSBackend) ListRecords(ctx context.Context, zoneName string) ([]ports.RecordSet, error) {
 	return []ports.RecordSet{}, nil
 }
+
+func (b *NoopDNSBackend) ReplaceRecordSets(ctx context.Context, zoneName string, records []ports.RecordSet) error {
+	return nil
+}
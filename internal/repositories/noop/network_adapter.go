@@ -6,6 +6,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/ports"
 )
 
@@ -43,6 +44,11 @@ func (n *NoopNetworkAdapter) DeletePort(ctx context.Context, bridge, portName st
 	return nil
 }
 
+func (n *NoopNetworkAdapter) CreatePatchPort(ctx context.Context, bridge, portName, peerPort string) error {
+	n.logger.Warn("noop network adapter: CreatePatchPort called but not implemented")
+	return nil
+}
+
 func (n *NoopNetworkAdapter) CreateVXLANTunnel(ctx context.Context, bridge string, vni int, remoteIP string) error {
 	n.logger.Warn("noop network adapter: CreateVXLANTunnel called but not implemented")
 	return nil
@@ -67,6 +73,50 @@ func (n *NoopNetworkAdapter) ListFlowRules(ctx context.Context, bridge string) (
 	return []ports.FlowRule{}, nil
 }
 
+func (n *NoopNetworkAdapter) AttachInternetGateway(ctx context.Context, bridge, name, vpcCIDR string) error {
+	n.logger.Warn("noop network adapter: AttachInternetGateway called but not implemented")
+	return nil
+}
+
+func (n *NoopNetworkAdapter) DetachInternetGateway(ctx context.Context, bridge, name string) error {
+	n.logger.Warn("noop network adapter: DetachInternetGateway called but not implemented")
+	return nil
+}
+
+func (n *NoopNetworkAdapter) MapElasticIP(ctx context.Context, gateway, publicIP, privateIP string) error {
+	n.logger.Warn("noop network adapter: MapElasticIP called but not implemented")
+	return nil
+}
+
+func (n *NoopNetworkAdapter) UnmapElasticIP(ctx context.Context, gateway, publicIP, privateIP string) error {
+	n.logger.Warn("noop network adapter: UnmapElasticIP called but not implemented")
+	return nil
+}
+
+func (n *NoopNetworkAdapter) CreateNATGateway(ctx context.Context, bridge, name, privateIP, publicIP, sourceCIDR string) error {
+	n.logger.Warn("noop network adapter: CreateNATGateway called but not implemented")
+	return nil
+}
+
+func (n *NoopNetworkAdapter) DeleteNATGateway(ctx context.Context, bridge, name string) error {
+	n.logger.Warn("noop network adapter: DeleteNATGateway called but not implemented")
+	return nil
+}
+
+func (n *NoopNetworkAdapter) ApplyNetworkACL(ctx context.Context, bridge, subnetCIDR string, rules []domain.NetworkACLRule) error {
+	n.logger.Warn("noop network adapter: ApplyNetworkACL called but not implemented")
+	return nil
+}
+
+func (n *NoopNetworkAdapter) RemoveNetworkACL(ctx context.Context, bridge, subnetCIDR string) error {
+	n.logger.Warn("noop network adapter: RemoveNetworkACL called but not implemented")
+	return nil
+}
+
+func (n *NoopNetworkAdapter) SampleFlows(ctx context.Context, bridge string) ([]domain.FlowRecord, error) {
+	return []domain.FlowRecord{}, nil
+}
+
 func (n *NoopNetworkAdapter) CreateVethPair(ctx context.Context, hostEnd, containerEnd string) error {
 	n.logger.Warn("noop network adapter: CreateVethPair called but not implemented")
 	return nil
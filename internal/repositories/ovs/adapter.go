@@ -5,10 +5,12 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"os/exec"
 	"regexp"
 	"strings"
 
+	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/ports"
 	"github.com/poyrazk/thecloud/internal/errors"
 )
@@ -25,6 +27,7 @@ type OvsAdapter struct {
 	ofctlPath string // Path to ovs-ofctl
 	logger    *slog.Logger
 	exec      execer
+	sampler   flowSampler
 }
 
 type execer interface {
@@ -141,6 +144,24 @@ func (a *OvsAdapter) DeletePort(ctx context.Context, bridge, portName string) er
 	return nil
 }
 
+func (a *OvsAdapter) CreatePatchPort(ctx context.Context, bridge, portName, peerPort string) error {
+	if !bridgeNameRegex.MatchString(bridge) || !bridgeNameRegex.MatchString(portName) || !bridgeNameRegex.MatchString(peerPort) {
+		return errors.New(errors.InvalidInput, "invalid bridge or port name")
+	}
+
+	cmd := a.exec.CommandContext(ctx, a.ovsPath,
+		"add-port", bridge, portName,
+		"--", "set", "interface", portName,
+		"type=patch",
+		fmt.Sprintf("options:peer=%s", peerPort),
+	)
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(errors.Internal, "failed to create patch port", err)
+	}
+
+	return nil
+}
+
 func (a *OvsAdapter) CreateVXLANTunnel(ctx context.Context, bridge string, vni int, remoteIP string) error {
 	if !bridgeNameRegex.MatchString(bridge) {
 		return errors.New(errors.InvalidInput, invalidBridgeNameMsg)
@@ -205,6 +226,190 @@ func (a *OvsAdapter) ListFlowRules(_ context.Context, _ string) ([]ports.FlowRul
 	return []ports.FlowRule{}, nil
 }
 
+// AttachInternetGateway builds the gateway as a network namespace with two legs: one veth
+// pair into the VPC bridge, whose inner end carries the MAC route flows rewrite packets to,
+// and one towards the host. Only Elastic IPs mapped with MapElasticIP are forwarded;
+// everything else is dropped.
+func (a *OvsAdapter) AttachInternetGateway(ctx context.Context, bridge, name, vpcCIDR string) error {
+	if !bridgeNameRegex.MatchString(bridge) || !bridgeNameRegex.MatchString(name) {
+		return errors.New(errors.InvalidInput, "invalid bridge or gateway name")
+	}
+	if _, _, err := net.ParseCIDR(vpcCIDR); err != nil {
+		return errors.New(errors.InvalidInput, "invalid VPC CIDR block")
+	}
+
+	inner, bridgePort, outer, hostPort := name+"-i", name+"-b", name+"-x", name+"-h"
+	inNS := func(args ...string) []string { return append([]string{"ip", "netns", "exec", name}, args...) }
+	steps := [][]string{
+		{"ip", "netns", "add", name},
+		{"ip", "link", "add", bridgePort, "type", "veth", "peer", "name", inner},
+		{"ip", "link", "set", inner, "netns", name},
+		{a.ovsPath, "add-port", bridge, bridgePort},
+		{"ip", "link", "set", bridgePort, "up"},
+		inNS("ip", "link", "set", inner, "address", domain.GatewayMAC(name)),
+		inNS("ip", "link", "set", inner, "up"),
+		inNS("ip", "route", "add", vpcCIDR, "dev", inner),
+		{"ip", "link", "add", hostPort, "type", "veth", "peer", "name", outer},
+		{"ip", "link", "set", outer, "netns", name},
+		{"ip", "link", "set", hostPort, "up"},
+		{"sysctl", "-w", "net.ipv4.conf." + hostPort + ".proxy_arp=1"},
+		inNS("ip", "link", "set", outer, "up"),
+		inNS("ip", "route", "add", "default", "dev", outer),
+		inNS("sysctl", "-w", "net.ipv4.ip_forward=1"),
+		inNS("iptables", "-P", "FORWARD", "DROP"),
+		inNS("iptables", "-A", "FORWARD", "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "-j", "ACCEPT"),
+	}
+
+	for _, step := range steps {
+		if err := a.exec.CommandContext(ctx, step[0], step[1:]...).Run(); err != nil {
+			if cleanupErr := a.DetachInternetGateway(ctx, bridge, name); cleanupErr != nil {
+				a.logger.Warn("failed to clean up partial internet gateway", "name", name, "error", cleanupErr)
+			}
+			return errors.Wrap(errors.Internal, fmt.Sprintf("failed to attach internet gateway (%s)", strings.Join(step, " ")), err)
+		}
+	}
+
+	return nil
+}
+
+// DetachInternetGateway removes everything AttachInternetGateway and MapElasticIP set up.
+func (a *OvsAdapter) DetachInternetGateway(ctx context.Context, bridge, name string) error {
+	return a.deleteGatewayNamespace(ctx, bridge, name)
+}
+
+// MapElasticIP gives privateIP a one-to-one translation to publicIP on an internet
+// gateway: the gateway answers for publicIP on its outer leg, DNATs inbound traffic to the
+// instance and SNATs the instance's outbound traffic.
+func (a *OvsAdapter) MapElasticIP(ctx context.Context, gateway, publicIP, privateIP string) error {
+	steps, err := a.elasticIPSteps(gateway, publicIP, privateIP, "add", "-A")
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		if err := a.exec.CommandContext(ctx, step[0], step[1:]...).Run(); err != nil {
+			if cleanupErr := a.UnmapElasticIP(ctx, gateway, publicIP, privateIP); cleanupErr != nil {
+				a.logger.Warn("failed to clean up partial elastic ip mapping", "gateway", gateway, "public_ip", publicIP, "error", cleanupErr)
+			}
+			return errors.Wrap(errors.Internal, fmt.Sprintf("failed to map elastic ip (%s)", strings.Join(step, " ")), err)
+		}
+	}
+
+	return nil
+}
+
+// UnmapElasticIP undoes MapElasticIP. Every step is attempted so a partial mapping is
+// removed as far as possible; the first failure is reported.
+func (a *OvsAdapter) UnmapElasticIP(ctx context.Context, gateway, publicIP, privateIP string) error {
+	steps, err := a.elasticIPSteps(gateway, publicIP, privateIP, "del", "-D")
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, step := range steps {
+		if err := a.exec.CommandContext(ctx, step[0], step[1:]...).Run(); err != nil && firstErr == nil {
+			firstErr = errors.Wrap(errors.Internal, fmt.Sprintf("failed to unmap elastic ip (%s)", strings.Join(step, " ")), err)
+		}
+	}
+
+	return firstErr
+}
+
+// elasticIPSteps lists the commands that add or delete an Elastic IP translation, with
+// ipOp being the ip(8) verb and ruleOp the iptables flag.
+func (a *OvsAdapter) elasticIPSteps(gateway, publicIP, privateIP, ipOp, ruleOp string) ([][]string, error) {
+	if !bridgeNameRegex.MatchString(gateway) {
+		return nil, errors.New(errors.InvalidInput, "invalid gateway name")
+	}
+	if net.ParseIP(publicIP) == nil || net.ParseIP(privateIP) == nil {
+		return nil, errors.New(errors.InvalidInput, "invalid elastic ip addresses")
+	}
+
+	outer, hostPort := gateway+"-x", gateway+"-h"
+	inNS := func(args ...string) []string { return append([]string{"ip", "netns", "exec", gateway}, args...) }
+	return [][]string{
+		inNS("ip", "addr", ipOp, publicIP+"/32", "dev", outer),
+		inNS("iptables", "-t", "nat", ruleOp, "PREROUTING", "-i", outer, "-d", publicIP, "-j", "DNAT", "--to-destination", privateIP),
+		inNS("iptables", "-t", "nat", ruleOp, "POSTROUTING", "-o", outer, "-s", privateIP, "-j", "SNAT", "--to-source", publicIP),
+		inNS("iptables", ruleOp, "FORWARD", "-i", outer, "-d", privateIP, "-j", "ACCEPT"),
+		inNS("iptables", ruleOp, "FORWARD", "-o", outer, "-s", privateIP, "-j", "ACCEPT"),
+		{"ip", "route", ipOp, publicIP + "/32", "dev", hostPort},
+	}, nil
+}
+
+// CreateNATGateway builds the gateway as a network namespace with two legs: one veth pair
+// into the VPC bridge carrying privateIP, and one towards the host carrying publicIP.
+// Traffic from sourceCIDR is SNATed to publicIP, and connections opened from outside are dropped.
+func (a *OvsAdapter) CreateNATGateway(ctx context.Context, bridge, name, privateIP, publicIP, sourceCIDR string) error {
+	if !bridgeNameRegex.MatchString(bridge) || !bridgeNameRegex.MatchString(name) {
+		return errors.New(errors.InvalidInput, "invalid bridge or gateway name")
+	}
+	_, source, err := net.ParseCIDR(sourceCIDR)
+	if err != nil || net.ParseIP(privateIP) == nil || net.ParseIP(publicIP) == nil {
+		return errors.New(errors.InvalidInput, "invalid NAT gateway addresses")
+	}
+	prefix, _ := source.Mask.Size()
+
+	inner, bridgePort, outer, hostPort := name+"-i", name+"-b", name+"-x", name+"-h"
+	inNS := func(args ...string) []string { return append([]string{"ip", "netns", "exec", name}, args...) }
+	steps := [][]string{
+		{"ip", "netns", "add", name},
+		{"ip", "link", "add", bridgePort, "type", "veth", "peer", "name", inner},
+		{"ip", "link", "set", inner, "netns", name},
+		{a.ovsPath, "add-port", bridge, bridgePort},
+		{"ip", "link", "set", bridgePort, "up"},
+		inNS("ip", "link", "set", inner, "address", domain.GatewayMAC(name)),
+		inNS("ip", "addr", "add", fmt.Sprintf("%s/%d", privateIP, prefix), "dev", inner),
+		inNS("ip", "link", "set", inner, "up"),
+		{"ip", "link", "add", hostPort, "type", "veth", "peer", "name", outer},
+		{"ip", "link", "set", outer, "netns", name},
+		{"ip", "link", "set", hostPort, "up"},
+		{"ip", "route", "add", publicIP + "/32", "dev", hostPort},
+		inNS("ip", "addr", "add", publicIP+"/32", "dev", outer),
+		inNS("ip", "link", "set", outer, "up"),
+		inNS("ip", "route", "add", "default", "dev", outer),
+		inNS("sysctl", "-w", "net.ipv4.ip_forward=1"),
+		inNS("iptables", "-t", "nat", "-A", "POSTROUTING", "-s", sourceCIDR, "-o", outer, "-j", "SNAT", "--to-source", publicIP),
+		inNS("iptables", "-A", "FORWARD", "-i", outer, "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "-j", "ACCEPT"),
+		inNS("iptables", "-A", "FORWARD", "-i", outer, "-j", "DROP"),
+	}
+
+	for _, step := range steps {
+		if err := a.exec.CommandContext(ctx, step[0], step[1:]...).Run(); err != nil {
+			if cleanupErr := a.DeleteNATGateway(ctx, bridge, name); cleanupErr != nil {
+				a.logger.Warn("failed to clean up partial NAT gateway", "name", name, "error", cleanupErr)
+			}
+			return errors.Wrap(errors.Internal, fmt.Sprintf("failed to create NAT gateway (%s)", strings.Join(step, " ")), err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteNATGateway removes everything CreateNATGateway may have set up.
+func (a *OvsAdapter) DeleteNATGateway(ctx context.Context, bridge, name string) error {
+	return a.deleteGatewayNamespace(ctx, bridge, name)
+}
+
+// deleteGatewayNamespace tears down a gateway namespace and its legs. Deleting the host
+// end of each veth pair removes its peer inside the namespace as well, and deleting the
+// namespace drops its addresses and iptables rules.
+func (a *OvsAdapter) deleteGatewayNamespace(ctx context.Context, bridge, name string) error {
+	if !bridgeNameRegex.MatchString(bridge) || !bridgeNameRegex.MatchString(name) {
+		return errors.New(errors.InvalidInput, "invalid bridge or gateway name")
+	}
+
+	_ = a.exec.CommandContext(ctx, a.ovsPath, "--if-exists", "del-port", bridge, name+"-b").Run()
+	_ = a.exec.CommandContext(ctx, "ip", "link", "del", name+"-b").Run()
+	_ = a.exec.CommandContext(ctx, "ip", "link", "del", name+"-h").Run()
+	if err := a.exec.CommandContext(ctx, "ip", "netns", "del", name).Run(); err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete gateway namespace", err)
+	}
+
+	return nil
+}
+
 func (a *OvsAdapter) CreateVethPair(ctx context.Context, hostEnd, containerEnd string) error {
 	cmd := a.exec.CommandContext(ctx, "ip", "link", "add", hostEnd, "type", "veth", "peer", "name", containerEnd)
 	if err := cmd.Run(); err != nil {
@@ -154,6 +154,110 @@ func TestOvsAdapterDeleteFlowRule(t *testing.T) {
 	})
 }
 
+func TestOvsAdapterCreatePatchPort(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		fx := &fakeExecer{cmd: &fakeCmd{}}
+		a := &OvsAdapter{ovsPath: ovsVsctlPath, logger: slog.Default(), exec: fx}
+
+		err := a.CreatePatchPort(context.Background(), "br0", "pp-a", "pp-b")
+		require.NoError(t, err)
+		require.Equal(t, 1, fx.cmd.runHits)
+	})
+
+	t.Run("invalid peer", func(t *testing.T) {
+		fx := &fakeExecer{cmd: &fakeCmd{}}
+		a := &OvsAdapter{ovsPath: ovsVsctlPath, logger: slog.Default(), exec: fx}
+
+		err := a.CreatePatchPort(context.Background(), "br0", "pp-a", "pp b")
+		require.Error(t, err)
+		require.True(t, apperrors.Is(err, apperrors.InvalidInput))
+	})
+}
+
+func TestOvsAdapterInternetGateway(t *testing.T) {
+	fx := &fakeExecer{cmd: &fakeCmd{}}
+	a := &OvsAdapter{ovsPath: ovsVsctlPath, logger: slog.Default(), exec: fx}
+
+	require.NoError(t, a.AttachInternetGateway(context.Background(), "br0", "igw-1", "10.0.0.0/16"))
+	require.Equal(t, 17, fx.cmd.runHits)
+	require.NoError(t, a.DetachInternetGateway(context.Background(), "br0", "igw-1"))
+
+	err := a.AttachInternetGateway(context.Background(), "br0", "igw 1", "10.0.0.0/16")
+	require.True(t, apperrors.Is(err, apperrors.InvalidInput))
+	err = a.AttachInternetGateway(context.Background(), "br0", "igw-1", "nope")
+	require.True(t, apperrors.Is(err, apperrors.InvalidInput))
+
+	t.Run("failure cleans up", func(t *testing.T) {
+		fx := &fakeExecer{cmd: &fakeCmd{runErr: errors.New("boom")}}
+		a := &OvsAdapter{ovsPath: ovsVsctlPath, logger: slog.Default(), exec: fx}
+
+		err := a.AttachInternetGateway(context.Background(), "br0", "igw-1", "10.0.0.0/16")
+		require.True(t, apperrors.Is(err, apperrors.Internal))
+		require.Equal(t, 5, fx.cmd.runHits) // the failed step plus four cleanup commands
+	})
+}
+
+func TestOvsAdapterElasticIPMapping(t *testing.T) {
+	t.Run("map and unmap", func(t *testing.T) {
+		fx := &fakeExecer{cmd: &fakeCmd{}}
+		a := &OvsAdapter{ovsPath: ovsVsctlPath, logger: slog.Default(), exec: fx}
+
+		require.NoError(t, a.MapElasticIP(context.Background(), "igw-1", "100.64.0.9", "10.0.1.5"))
+		require.Equal(t, 6, fx.cmd.runHits)
+		require.NoError(t, a.UnmapElasticIP(context.Background(), "igw-1", "100.64.0.9", "10.0.1.5"))
+		require.Equal(t, 12, fx.cmd.runHits)
+	})
+
+	t.Run("failed map is rolled back", func(t *testing.T) {
+		fx := &fakeExecer{cmd: &fakeCmd{runErr: errors.New("boom")}}
+		a := &OvsAdapter{ovsPath: ovsVsctlPath, logger: slog.Default(), exec: fx}
+
+		err := a.MapElasticIP(context.Background(), "igw-1", "100.64.0.9", "10.0.1.5")
+		require.True(t, apperrors.Is(err, apperrors.Internal))
+		require.Equal(t, 7, fx.cmd.runHits) // the failed step plus every unmap step
+	})
+
+	t.Run("invalid addresses", func(t *testing.T) {
+		fx := &fakeExecer{cmd: &fakeCmd{}}
+		a := &OvsAdapter{ovsPath: ovsVsctlPath, logger: slog.Default(), exec: fx}
+
+		err := a.MapElasticIP(context.Background(), "igw-1", "100.64.0.9", "10.0.1.5; reboot")
+		require.True(t, apperrors.Is(err, apperrors.InvalidInput))
+		require.Zero(t, fx.cmd.runHits)
+	})
+}
+
+func TestOvsAdapterNATGateway(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		fx := &fakeExecer{cmd: &fakeCmd{}}
+		a := &OvsAdapter{ovsPath: ovsVsctlPath, logger: slog.Default(), exec: fx}
+
+		err := a.CreateNATGateway(context.Background(), "br0", "nat-1", "10.0.0.254", "100.64.0.9", "10.0.0.0/16")
+		require.NoError(t, err)
+		require.Greater(t, fx.cmd.runHits, 10)
+
+		require.NoError(t, a.DeleteNATGateway(context.Background(), "br0", "nat-1"))
+	})
+
+	t.Run("failure cleans up", func(t *testing.T) {
+		fx := &fakeExecer{cmd: &fakeCmd{runErr: errors.New("boom")}}
+		a := &OvsAdapter{ovsPath: ovsVsctlPath, logger: slog.Default(), exec: fx}
+
+		err := a.CreateNATGateway(context.Background(), "br0", "nat-1", "10.0.0.254", "100.64.0.9", "10.0.0.0/16")
+		require.True(t, apperrors.Is(err, apperrors.Internal))
+		require.Equal(t, 5, fx.cmd.runHits) // the failed step plus four cleanup commands
+	})
+
+	t.Run("invalid addresses", func(t *testing.T) {
+		fx := &fakeExecer{cmd: &fakeCmd{}}
+		a := &OvsAdapter{ovsPath: ovsVsctlPath, logger: slog.Default(), exec: fx}
+
+		err := a.CreateNATGateway(context.Background(), "br0", "nat-1", "nope", "100.64.0.9", "10.0.0.0/16")
+		require.True(t, apperrors.Is(err, apperrors.InvalidInput))
+		require.Zero(t, fx.cmd.runHits)
+	})
+}
+
 func TestOvsAdapterCreateVXLANTunnel(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		fx := &fakeExecer{cmd: &fakeCmd{}}
@@ -0,0 +1,68 @@
+package ovs
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	apperrors "github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dpFlowDump = `recirc_id(0),in_port(3),eth_type(0x0800),ipv4(src=10.0.1.5,dst=10.0.2.7,proto=6,frag=no),tcp(dst=80), packets:5, bytes:370, used:1.2s, flags:S, actions:ct(zone=0),recirc(0x2)
+recirc_id(0x2),in_port(3),ct_state(+new+trk),eth_type(0x0800),ipv4(src=10.0.1.5,dst=10.0.2.0/255.255.255.0,proto=6,frag=no),tcp(src=40000,dst=80), packets:5, bytes:370, used:1.2s, flags:S, actions:drop
+recirc_id(0x2),in_port(4),ct_state(-new+est+trk),eth_type(0x0800),ipv4(src=10.0.2.9,dst=10.0.1.5,proto=17,frag=no),udp(src=53,dst=0x8000/0xc000), packets:3, bytes:300, used:0.4s, actions:3
+recirc_id(0),in_port(3),eth_type(0x0806),arp(sip=10.0.1.5), packets:1, bytes:42, used:2.0s, actions:4`
+
+func TestParseDatapathFlow(t *testing.T) {
+	_, _, _, ok := parseDatapathFlow("recirc_id(0),ipv4(src=10.0.1.5,dst=10.0.2.7,proto=6), packets:1, bytes:60, used:0.1s, actions:ct(zone=0),recirc(0x2)")
+	assert.False(t, ok, "intermediate conntrack flows are not terminal")
+
+	_, rec, counter, ok := parseDatapathFlow("recirc_id(0x2),ipv4(src=10.0.1.5,dst=10.0.2.0/255.255.255.0,proto=6,frag=no),tcp(src=40000,dst=80), packets:5, bytes:370, used:1.2s, actions:drop")
+	require.True(t, ok)
+	assert.Equal(t, domain.FlowRecord{
+		SrcIP: "10.0.1.5", DstIP: "10.0.2.0/24", SrcPort: 40000, DstPort: 80, Protocol: "tcp", Action: domain.FlowReject,
+	}, rec)
+	assert.Equal(t, flowCounter{packets: 5, bytes: 370}, counter)
+
+	_, rec, _, ok = parseDatapathFlow("recirc_id(0x2),ipv4(src=10.0.2.9,dst=10.0.1.5,proto=17,frag=no),udp(src=53,dst=0x8000/0xc000), packets:3, bytes:300, used:0.4s, actions:3")
+	require.True(t, ok)
+	assert.Equal(t, domain.FlowAccept, rec.Action)
+	assert.Equal(t, 0x8000, rec.DstPort)
+
+	_, rec, counter, ok = parseDatapathFlow("recirc_id(0x2),in_port(3),ct_state(+new+trk),eth_type(0x86dd),ipv6(src=fd00:10:0:1::5,dst=fd00:10:0:2::/ffff:ffff:ffff:ffff::,label=0,proto=6,tclass=0,hlimit=64,frag=no),tcp(src=40000,dst=443), packets:2, bytes:180, used:0.3s, actions:4")
+	require.True(t, ok)
+	assert.Equal(t, domain.FlowRecord{
+		SrcIP: "fd00:10:0:1::5", DstIP: "fd00:10:0:2::/64", SrcPort: 40000, DstPort: 443, Protocol: "tcp", Action: domain.FlowAccept,
+	}, rec)
+	assert.Equal(t, flowCounter{packets: 2, bytes: 180}, counter)
+}
+
+func TestSampleFlowsReportsDeltas(t *testing.T) {
+	fx := &fakeExecer{cmd: &fakeCmd{out: []byte(dpFlowDump)}}
+	a := &OvsAdapter{logger: slog.Default(), exec: fx}
+
+	records, err := a.SampleFlows(context.Background(), "br-vpc")
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, uint64(5), records[0].Packets)
+	assert.Equal(t, domain.FlowReject, records[0].Action)
+	assert.Equal(t, "udp", records[1].Protocol)
+
+	// Only the dropped flow saw new packets since the previous sample.
+	fx.cmd.out = []byte(`recirc_id(0x2),in_port(3),ct_state(+new+trk),eth_type(0x0800),ipv4(src=10.0.1.5,dst=10.0.2.0/255.255.255.0,proto=6,frag=no),tcp(src=40000,dst=80), packets:8, bytes:592, used:0.1s, flags:S, actions:drop
+recirc_id(0x2),in_port(4),ct_state(-new+est+trk),eth_type(0x0800),ipv4(src=10.0.2.9,dst=10.0.1.5,proto=17,frag=no),udp(src=53,dst=0x8000/0xc000), packets:3, bytes:300, used:5.4s, actions:3`)
+	records, err = a.SampleFlows(context.Background(), "br-vpc")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, uint64(3), records[0].Packets)
+	assert.Equal(t, uint64(222), records[0].Bytes)
+}
+
+func TestSampleFlowsInvalidBridge(t *testing.T) {
+	a := &OvsAdapter{logger: slog.Default(), exec: &fakeExecer{cmd: &fakeCmd{}}}
+	_, err := a.SampleFlows(context.Background(), badBridge)
+	assert.True(t, apperrors.Is(err, apperrors.InvalidInput))
+}
@@ -0,0 +1,191 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"strings"
+
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+)
+
+const (
+	// aclPriorityTop is the priority of ACL rule number 0; rule N gets aclPriorityTop-N, so
+	// the whole band sits above the conntrack entry flow of the security group stage.
+	aclPriorityTop = 65500
+	// aclDefaultDenyPriority drops subnet traffic that no ACL rule matched.
+	aclDefaultDenyPriority = 65050
+)
+
+// Each packet is checked once per direction. A rule that allows traffic sets the
+// direction's bit in reg0 and resubmits the packet to table 0, where the ACL flows no longer
+// match it and the rest of the pipeline (security groups, routing) takes over.
+const (
+	aclEgressBit  = 0
+	aclIngressBit = 1
+)
+
+func (a *OvsAdapter) ApplyNetworkACL(ctx context.Context, bridge, subnetCIDR string, rules []domain.NetworkACLRule) error {
+	if !bridgeNameRegex.MatchString(bridge) {
+		return errors.New(errors.InvalidInput, invalidBridgeNameMsg)
+	}
+	flows, err := compileNetworkACL(subnetCIDR, rules)
+	if err != nil {
+		return err
+	}
+
+	if err := a.RemoveNetworkACL(ctx, bridge, subnetCIDR); err != nil {
+		return err
+	}
+	for _, flow := range flows {
+		if err := a.exec.CommandContext(ctx, a.ofctlPath, "add-flow", bridge, flow).Run(); err != nil {
+			return errors.Wrap(errors.Internal, "failed to add network ACL flow", err)
+		}
+	}
+	return nil
+}
+
+func (a *OvsAdapter) RemoveNetworkACL(ctx context.Context, bridge, subnetCIDR string) error {
+	if !bridgeNameRegex.MatchString(bridge) {
+		return errors.New(errors.InvalidInput, invalidBridgeNameMsg)
+	}
+
+	match := fmt.Sprintf("cookie=%#x/-1", aclCookie(subnetCIDR))
+	if err := a.exec.CommandContext(ctx, a.ofctlPath, "del-flows", bridge, match).Run(); err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete network ACL flows", err)
+	}
+	return nil
+}
+
+// compileNetworkACL turns a subnet's ACL rules into ovs-ofctl flow specs tagged with the
+// subnet's cookie, plus a default deny for each direction. Only rules of the subnet
+// range's address family apply; a dual-stack subnet is compiled once per range.
+func compileNetworkACL(subnetCIDR string, rules []domain.NetworkACLRule) ([]string, error) {
+	subnetIP, _, err := net.ParseCIDR(subnetCIDR)
+	if err != nil {
+		return nil, errors.New(errors.InvalidInput, "invalid subnet CIDR")
+	}
+	cookie := aclCookie(subnetCIDR)
+	v6 := subnetIP.To4() == nil
+	fam := ipv4Fields
+	if v6 {
+		fam = ipv6Fields
+	}
+
+	var flows []string
+	if v6 {
+		// Neighbor discovery must reach the subnet whatever the rules say, as ARP does.
+		for _, icmpType := range []int{135, 136} {
+			for _, local := range []string{fam.src, fam.dst} {
+				flows = append(flows, fmt.Sprintf("cookie=%#x,priority=%d,icmp6,icmp_type=%d,%s=%s,actions=NORMAL",
+					cookie, aclPriorityTop+1, icmpType, local, subnetCIDR))
+			}
+		}
+	}
+
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, errors.New(errors.InvalidInput, err.Error())
+		}
+		ruleIP, _, _ := net.ParseCIDR(rule.CIDR)
+		if (ruleIP.To4() == nil) != v6 {
+			continue
+		}
+
+		bit, local, peer := aclIngressBit, fam.dst, fam.src
+		if rule.Direction == domain.RuleEgress {
+			bit, local, peer = aclEgressBit, fam.src, fam.dst
+		}
+
+		proto := fam.protocol(rule.Protocol)
+		match := []string{aclUncheckedMatch(bit), proto, fmt.Sprintf("%s=%s", local, subnetCIDR)}
+		if rule.CIDR != "0.0.0.0/0" && rule.CIDR != "::/0" {
+			match = append(match, fmt.Sprintf("%s=%s", peer, rule.CIDR))
+		}
+
+		actions := "drop"
+		if rule.Action == domain.ACLAllow {
+			actions = fmt.Sprintf("load:1->NXM_NX_REG0[%d],resubmit(,0)", bit)
+		}
+
+		ports := []string{""}
+		if rule.Protocol == "tcp" || rule.Protocol == "udp" {
+			ports = portRangeMatches(rule.PortMin, rule.PortMax)
+		}
+		for _, port := range ports {
+			m := match
+			if port != "" {
+				m = append(append([]string{}, match...), "tp_dst="+port)
+			}
+			flows = append(flows, fmt.Sprintf("cookie=%#x,priority=%d,%s,actions=%s",
+				cookie, aclPriorityTop-rule.RuleNumber, strings.Join(m, ","), actions))
+		}
+	}
+
+	for _, d := range []struct {
+		bit   int
+		local string
+	}{{aclIngressBit, fam.dst}, {aclEgressBit, fam.src}} {
+		flows = append(flows, fmt.Sprintf("cookie=%#x,priority=%d,%s,%s,%s=%s,actions=drop",
+			cookie, aclDefaultDenyPriority, aclUncheckedMatch(d.bit), fam.protocol("all"), d.local, subnetCIDR))
+	}
+	return flows, nil
+}
+
+// addressFamily names the OpenFlow match fields for one IP version.
+type addressFamily struct {
+	suffix   string
+	src, dst string
+}
+
+var (
+	ipv4Fields = addressFamily{suffix: "", src: "nw_src", dst: "nw_dst"}
+	ipv6Fields = addressFamily{suffix: "6", src: "ipv6_src", dst: "ipv6_dst"}
+)
+
+// protocol maps a rule protocol to the family's match keyword, e.g. tcp to tcp6.
+func (f addressFamily) protocol(proto string) string {
+	if proto == "all" {
+		if f.suffix == "" {
+			return "ip"
+		}
+		return "ipv6"
+	}
+	return proto + f.suffix
+}
+
+// aclUncheckedMatch matches packets whose direction bit is not yet set in reg0.
+func aclUncheckedMatch(bit int) string {
+	return fmt.Sprintf("reg0=0/%#x", 1<<bit)
+}
+
+// aclCookie derives the flow cookie identifying a subnet's ACL flows on its bridge.
+func aclCookie(subnetCIDR string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("nacl:" + subnetCIDR))
+	return h.Sum64()
+}
+
+// portRangeMatches splits a port range into the fewest tp_dst value/mask pairs covering
+// it, since OpenFlow cannot match ranges directly. A full range matches any port.
+func portRangeMatches(lo, hi int) []string {
+	if lo <= 1 && hi >= 65535 {
+		return []string{""}
+	}
+	var out []string
+	for lo <= hi {
+		size := 1
+		for lo%(size*2) == 0 && lo+size*2-1 <= hi {
+			size *= 2
+		}
+		if size == 1 {
+			out = append(out, fmt.Sprintf("%d", lo))
+		} else {
+			out = append(out, fmt.Sprintf("%#x/%#x", lo, 0xffff&^(size-1)))
+		}
+		lo += size
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	apperrors "github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortRangeMatches(t *testing.T) {
+	require.Equal(t, []string{"443"}, portRangeMatches(443, 443))
+	require.Equal(t, []string{""}, portRangeMatches(1, 65535))
+	require.Equal(t, []string{"0x400/0xfc00", "0x800/0xf800", "0x1000/0xf000", "0x2000/0xe000", "0x4000/0xc000", "0x8000/0x8000"}, portRangeMatches(1024, 65535))
+	require.Equal(t, []string{"0x50/0xfffe", "82"}, portRangeMatches(80, 82))
+}
+
+func TestCompileNetworkACL(t *testing.T) {
+	rules := []domain.NetworkACLRule{
+		{RuleNumber: 100, Direction: domain.RuleIngress, Protocol: "tcp", PortMin: 443, PortMax: 443, CIDR: "0.0.0.0/0", Action: domain.ACLAllow},
+		{RuleNumber: 50, Direction: domain.RuleEgress, Protocol: "all", CIDR: "10.9.0.0/16", Action: domain.ACLDeny},
+	}
+
+	flows, err := compileNetworkACL("10.0.1.0/24", rules)
+	require.NoError(t, err)
+
+	cookie := fmt.Sprintf("cookie=%#x", aclCookie("10.0.1.0/24"))
+	require.Equal(t, []string{
+		cookie + ",priority=65400,reg0=0/0x2,tcp,nw_dst=10.0.1.0/24,tp_dst=443,actions=load:1->NXM_NX_REG0[1],resubmit(,0)",
+		cookie + ",priority=65450,reg0=0/0x1,ip,nw_src=10.0.1.0/24,nw_dst=10.9.0.0/16,actions=drop",
+		cookie + ",priority=65050,reg0=0/0x2,ip,nw_dst=10.0.1.0/24,actions=drop",
+		cookie + ",priority=65050,reg0=0/0x1,ip,nw_src=10.0.1.0/24,actions=drop",
+	}, flows)
+
+	_, err = compileNetworkACL("10.0.1.0/24", []domain.NetworkACLRule{{RuleNumber: 1, Direction: domain.RuleIngress, Protocol: "tcp", PortMin: 1, PortMax: 2, CIDR: "0.0.0.0/0,actions=NORMAL", Action: domain.ACLAllow}})
+	require.True(t, apperrors.Is(err, apperrors.InvalidInput))
+}
+
+func TestCompileNetworkACLIPv6(t *testing.T) {
+	rules := []domain.NetworkACLRule{
+		{RuleNumber: 100, Direction: domain.RuleIngress, Protocol: "tcp", PortMin: 443, PortMax: 443, CIDR: "::/0", Action: domain.ACLAllow},
+		{RuleNumber: 110, Direction: domain.RuleIngress, Protocol: "tcp", PortMin: 22, PortMax: 22, CIDR: "0.0.0.0/0", Action: domain.ACLAllow},
+		{RuleNumber: 50, Direction: domain.RuleEgress, Protocol: "icmp", CIDR: "fd00:99::/48", Action: domain.ACLDeny},
+	}
+
+	subnet := "fd00:10:0:1::/64"
+	flows, err := compileNetworkACL(subnet, rules)
+	require.NoError(t, err)
+
+	cookie := fmt.Sprintf("cookie=%#x", aclCookie(subnet))
+	require.Equal(t, []string{
+		cookie + ",priority=65501,icmp6,icmp_type=135,ipv6_src=fd00:10:0:1::/64,actions=NORMAL",
+		cookie + ",priority=65501,icmp6,icmp_type=135,ipv6_dst=fd00:10:0:1::/64,actions=NORMAL",
+		cookie + ",priority=65501,icmp6,icmp_type=136,ipv6_src=fd00:10:0:1::/64,actions=NORMAL",
+		cookie + ",priority=65501,icmp6,icmp_type=136,ipv6_dst=fd00:10:0:1::/64,actions=NORMAL",
+		cookie + ",priority=65400,reg0=0/0x2,tcp6,ipv6_dst=fd00:10:0:1::/64,tp_dst=443,actions=load:1->NXM_NX_REG0[1],resubmit(,0)",
+		cookie + ",priority=65450,reg0=0/0x1,icmp6,ipv6_src=fd00:10:0:1::/64,ipv6_dst=fd00:99::/48,actions=drop",
+		cookie + ",priority=65050,reg0=0/0x2,ipv6,ipv6_dst=fd00:10:0:1::/64,actions=drop",
+		cookie + ",priority=65050,reg0=0/0x1,ipv6,ipv6_src=fd00:10:0:1::/64,actions=drop",
+	}, flows)
+}
+
+func TestOvsAdapterApplyNetworkACL(t *testing.T) {
+	fx := &fakeExecer{cmd: &fakeCmd{}}
+	a := &OvsAdapter{ofctlPath: ovsOfctlPath, logger: slog.Default(), exec: fx}
+
+	rules := []domain.NetworkACLRule{
+		{RuleNumber: 100, Direction: domain.RuleIngress, Protocol: "all", CIDR: "0.0.0.0/0", Action: domain.ACLAllow},
+	}
+	require.NoError(t, a.ApplyNetworkACL(context.Background(), "br0", "10.0.1.0/24", rules))
+	require.Equal(t, 4, fx.cmd.runHits) // del-flows, one rule, two default denies
+
+	err := a.ApplyNetworkACL(context.Background(), badBridge, "10.0.1.0/24", rules)
+	require.True(t, apperrors.Is(err, apperrors.InvalidInput))
+}
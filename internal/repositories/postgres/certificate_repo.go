@@ -0,0 +1,150 @@
+// Package postgres provides PostgreSQL-backed repository implementations.
+package postgres
+
+import (
+	"context"
+	stdlib_errors "errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+)
+
+const certificateColumns = `id, user_id, tenant_id, name, domains, source, status, issuer, serial_number, not_before, not_after, certificate_pem, encrypted_private_key, notify_topic_id, expiry_alert_days, created_at`
+
+// CertificateRepository provides PostgreSQL-backed TLS certificate persistence.
+type CertificateRepository struct {
+	db DB
+}
+
+// NewCertificateRepository creates a CertificateRepository using the provided DB.
+func NewCertificateRepository(db DB) *CertificateRepository {
+	return &CertificateRepository{db: db}
+}
+
+func (r *CertificateRepository) Create(ctx context.Context, cert *domain.Certificate) error {
+	var tenantID *uuid.UUID
+	if cert.TenantID != uuid.Nil {
+		tenantID = &cert.TenantID
+	}
+	query := `
+		INSERT INTO certificates (` + certificateColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+	_, err := r.db.Exec(ctx, query,
+		cert.ID, cert.UserID, tenantID, cert.Name, cert.Domains, string(cert.Source), string(cert.Status),
+		cert.Issuer, cert.SerialNumber, cert.NotBefore, cert.NotAfter, cert.CertificatePEM, cert.EncryptedPrivateKey,
+		cert.NotifyTopicID, cert.ExpiryAlertDays, cert.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to create certificate", err)
+	}
+	return nil
+}
+
+func (r *CertificateRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Certificate, error) {
+	query := `SELECT ` + certificateColumns + ` FROM certificates WHERE id = $1`
+	return r.scanCertificate(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *CertificateRepository) List(ctx context.Context, userID uuid.UUID) ([]*domain.Certificate, error) {
+	query := `SELECT ` + certificateColumns + ` FROM certificates WHERE user_id = $1 ORDER BY created_at DESC`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list certificates", err)
+	}
+	return r.scanCertificates(rows)
+}
+
+func (r *CertificateRepository) ListAll(ctx context.Context) ([]*domain.Certificate, error) {
+	query := `SELECT ` + certificateColumns + ` FROM certificates ORDER BY not_after`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list certificates", err)
+	}
+	return r.scanCertificates(rows)
+}
+
+func (r *CertificateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	cmd, err := r.db.Exec(ctx, `DELETE FROM certificates WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete certificate", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "certificate not found")
+	}
+	return nil
+}
+
+func (r *CertificateRepository) UpdateExpiryState(ctx context.Context, id uuid.UUID, status domain.CertificateStatus, alertDays *int) error {
+	query := `UPDATE certificates SET status = $1, expiry_alert_days = $2 WHERE id = $3`
+	if _, err := r.db.Exec(ctx, query, string(status), alertDays, id); err != nil {
+		return errors.Wrap(errors.Internal, "failed to update certificate expiry state", err)
+	}
+	return nil
+}
+
+func (r *CertificateRepository) GetAuthority(ctx context.Context, name string) (*domain.CertificateAuthority, error) {
+	query := `SELECT name, certificate_pem, encrypted_private_key, not_after, created_at FROM certificate_authorities WHERE name = $1`
+	var ca domain.CertificateAuthority
+	err := r.db.QueryRow(ctx, query, name).Scan(&ca.Name, &ca.CertificatePEM, &ca.EncryptedPrivateKey, &ca.NotAfter, &ca.CreatedAt)
+	if err != nil {
+		if stdlib_errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New(errors.NotFound, "certificate authority not found")
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to get certificate authority", err)
+	}
+	return &ca, nil
+}
+
+func (r *CertificateRepository) CreateAuthority(ctx context.Context, ca *domain.CertificateAuthority) error {
+	query := `
+		INSERT INTO certificate_authorities (name, certificate_pem, encrypted_private_key, not_after, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO NOTHING
+	`
+	if _, err := r.db.Exec(ctx, query, ca.Name, ca.CertificatePEM, ca.EncryptedPrivateKey, ca.NotAfter, ca.CreatedAt); err != nil {
+		return errors.Wrap(errors.Internal, "failed to create certificate authority", err)
+	}
+	return nil
+}
+
+func (r *CertificateRepository) scanCertificate(row pgx.Row) (*domain.Certificate, error) {
+	var cert domain.Certificate
+	var tenantID *uuid.UUID
+	var source, status string
+	err := row.Scan(
+		&cert.ID, &cert.UserID, &tenantID, &cert.Name, &cert.Domains, &source, &status,
+		&cert.Issuer, &cert.SerialNumber, &cert.NotBefore, &cert.NotAfter, &cert.CertificatePEM, &cert.EncryptedPrivateKey,
+		&cert.NotifyTopicID, &cert.ExpiryAlertDays, &cert.CreatedAt,
+	)
+	if err != nil {
+		if stdlib_errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New(errors.NotFound, "certificate not found")
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to scan certificate", err)
+	}
+	if tenantID != nil {
+		cert.TenantID = *tenantID
+	}
+	cert.Source = domain.CertificateSource(source)
+	cert.Status = domain.CertificateStatus(status)
+	return &cert, nil
+}
+
+func (r *CertificateRepository) scanCertificates(rows pgx.Rows) ([]*domain.Certificate, error) {
+	defer rows.Close()
+	var certs []*domain.Certificate
+	for rows.Next() {
+		cert, err := r.scanCertificate(rows)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to iterate certificates", err)
+	}
+	return certs, nil
+}
@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	theclouderrors "github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var certificateTestColumns = []string{
+	"id", "user_id", "tenant_id", "name", "domains", "source", "status", "issuer", "serial_number",
+	"not_before", "not_after", "certificate_pem", "encrypted_private_key", "notify_topic_id", "expiry_alert_days", "created_at",
+}
+
+func TestCertificateRepositoryCreate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewCertificateRepository(mock)
+	now := time.Now()
+	cert := &domain.Certificate{
+		ID: uuid.New(), UserID: uuid.New(), Name: "web", Domains: []string{"app.example.com"},
+		Source: domain.CertificateSourceIssued, Status: domain.CertificateStatusActive, Issuer: "CN=ca",
+		SerialNumber: "01", NotBefore: now, NotAfter: now.Add(time.Hour), CertificatePEM: "cert",
+		EncryptedPrivateKey: "enc", CreatedAt: now,
+	}
+
+	mock.ExpectExec("INSERT INTO certificates").
+		WithArgs(cert.ID, cert.UserID, (*uuid.UUID)(nil), cert.Name, cert.Domains, "issued", "ACTIVE",
+			cert.Issuer, cert.SerialNumber, cert.NotBefore, cert.NotAfter, cert.CertificatePEM, cert.EncryptedPrivateKey,
+			cert.NotifyTopicID, cert.ExpiryAlertDays, cert.CreatedAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	require.NoError(t, repo.Create(context.Background(), cert))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCertificateRepositoryGetByID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewCertificateRepository(mock)
+	id, userID, tenantID, topic := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	alertDays := 7
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT id, user_id, tenant_id, name, domains.+ FROM certificates WHERE id").
+		WithArgs(id).
+		WillReturnRows(pgxmock.NewRows(certificateTestColumns).AddRow(
+			id, userID, &tenantID, "web", []string{"app.example.com"}, "imported", "EXPIRING", "CN=ca", "01",
+			now, now.Add(time.Hour), "cert", "enc", &topic, &alertDays, now,
+		))
+
+	cert, err := repo.GetByID(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, tenantID, cert.TenantID)
+	assert.Equal(t, domain.CertificateSourceImported, cert.Source)
+	assert.Equal(t, domain.CertificateStatusExpiring, cert.Status)
+	assert.Equal(t, &topic, cert.NotifyTopicID)
+	assert.Equal(t, &alertDays, cert.ExpiryAlertDays)
+
+	mock.ExpectQuery("SELECT .+ FROM certificates WHERE id").
+		WithArgs(id).
+		WillReturnError(pgx.ErrNoRows)
+	_, err = repo.GetByID(context.Background(), id)
+	assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+}
+
+func TestCertificateRepositoryDeleteAndUpdate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewCertificateRepository(mock)
+	id := uuid.New()
+	alertDays := 1
+
+	mock.ExpectExec("UPDATE certificates SET status").
+		WithArgs("EXPIRING", &alertDays, id).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	require.NoError(t, repo.UpdateExpiryState(context.Background(), id, domain.CertificateStatusExpiring, &alertDays))
+
+	mock.ExpectExec("DELETE FROM certificates").WithArgs(id).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	err = repo.Delete(context.Background(), id)
+	assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCertificateRepositoryGetAuthority(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewCertificateRepository(mock)
+	mock.ExpectQuery("SELECT name, certificate_pem, encrypted_private_key, not_after, created_at FROM certificate_authorities").
+		WithArgs("internal").
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = repo.GetAuthority(context.Background(), "internal")
+	assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+}
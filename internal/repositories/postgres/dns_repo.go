@@ -293,3 +293,52 @@ func (r *DNSRepository) DeleteRecordsByInstance(ctx context.Context, instanceID
 	}
 	return nil
 }
+
+// ApplyRecordChanges deletes and creates records of a zone in one transaction.
+// The zone row is locked so concurrent batches on the same zone are serialized,
+// and the batch fails if any record to delete is already gone.
+func (r *DNSRepository) ApplyRecordChanges(ctx context.Context, zoneID uuid.UUID, deletes []uuid.UUID, creates []*domain.DNSRecord) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to start transaction", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var locked uuid.UUID
+	if err := tx.QueryRow(ctx, `SELECT id FROM dns_zones WHERE id = $1 FOR UPDATE`, zoneID).Scan(&locked); err != nil {
+		if stdlib_errors.Is(err, pgx.ErrNoRows) {
+			return errors.New(errors.NotFound, "dns zone not found")
+		}
+		return errors.Wrap(errors.Internal, "failed to lock dns zone", err)
+	}
+
+	if len(deletes) > 0 {
+		tag, err := tx.Exec(ctx, `DELETE FROM dns_records WHERE zone_id = $1 AND id = ANY($2)`, zoneID, deletes)
+		if err != nil {
+			return errors.Wrap(errors.Internal, "failed to delete dns records", err)
+		}
+		if tag.RowsAffected() != int64(len(deletes)) {
+			return errors.New(errors.Conflict, "dns records changed concurrently, retry the batch")
+		}
+	}
+
+	query := `
+		INSERT INTO dns_records (
+			id, zone_id, name, type, content, ttl, priority, 
+			disabled, auto_managed, instance_id, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	for _, record := range creates {
+		if _, err := tx.Exec(ctx, query,
+			record.ID, zoneID, record.Name, record.Type, record.Content, record.TTL, record.Priority,
+			record.Disabled, record.AutoManaged, record.InstanceID, record.CreatedAt, record.UpdatedAt,
+		); err != nil {
+			return errors.Wrap(errors.Internal, "failed to create dns record", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(errors.Internal, "failed to commit dns record changes", err)
+	}
+	return nil
+}
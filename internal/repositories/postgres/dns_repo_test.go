@@ -119,3 +119,53 @@ func TestDNSRepositoryDeleteRecord(t *testing.T) {
 	err = repo.DeleteRecord(context.Background(), id)
 	assert.NoError(t, err)
 }
+
+func TestDNSRepositoryApplyRecordChanges(t *testing.T) {
+	zoneID, oldID := uuid.New(), uuid.New()
+	record := &domain.DNSRecord{
+		ID: uuid.New(), ZoneID: zoneID, Name: "www", Type: domain.RecordTypeA, Content: "10.0.0.1", TTL: 300,
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}
+
+	t.Run("commits deletes and creates together", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM dns_zones WHERE id = \\$1 FOR UPDATE").
+			WithArgs(zoneID).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(zoneID))
+		mock.ExpectExec("DELETE FROM dns_records WHERE zone_id = \\$1 AND id = ANY\\(\\$2\\)").
+			WithArgs(zoneID, []uuid.UUID{oldID}).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectExec("INSERT INTO dns_records").
+			WithArgs(record.ID, zoneID, record.Name, record.Type, record.Content, record.TTL, record.Priority, record.Disabled, record.AutoManaged, record.InstanceID, record.CreatedAt, record.UpdatedAt).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+		mock.ExpectRollback()
+
+		err = NewDNSRepository(mock).ApplyRecordChanges(context.Background(), zoneID, []uuid.UUID{oldID}, []*domain.DNSRecord{record})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back when a record to delete is gone", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM dns_zones WHERE id = \\$1 FOR UPDATE").
+			WithArgs(zoneID).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(zoneID))
+		mock.ExpectExec("DELETE FROM dns_records WHERE zone_id = \\$1 AND id = ANY\\(\\$2\\)").
+			WithArgs(zoneID, []uuid.UUID{oldID}).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		mock.ExpectRollback()
+
+		err = NewDNSRepository(mock).ApplyRecordChanges(context.Background(), zoneID, []uuid.UUID{oldID}, []*domain.DNSRecord{record})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
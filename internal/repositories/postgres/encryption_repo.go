@@ -20,26 +20,37 @@ func NewEncryptionRepository(db DB) *EncryptionRepository {
 	return &EncryptionRepository{db: db}
 }
 
+// SaveKey stores a new data key version. Versions are never overwritten, so data
+// sealed with an earlier version stays decryptable after a rotation.
 func (r *EncryptionRepository) SaveKey(ctx context.Context, key ports.EncryptionKey) error {
 	query := `
-		INSERT INTO encryption_keys (id, bucket_name, encrypted_key, algorithm)
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (bucket_name) DO UPDATE SET
-			id = EXCLUDED.id,
-			encrypted_key = EXCLUDED.encrypted_key,
-			algorithm = EXCLUDED.algorithm
+		INSERT INTO encryption_keys (id, bucket_name, version, encrypted_key, algorithm, master_key_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	_, err := r.db.Exec(ctx, query, key.ID, key.BucketName, key.EncryptedKey, key.Algorithm)
+	_, err := r.db.Exec(ctx, query, key.ID, key.BucketName, key.Version, key.EncryptedKey, key.Algorithm, key.MasterKeyID)
 	if err != nil {
 		return cerr.Wrap(cerr.Internal, "failed to save encryption key", err)
 	}
 	return nil
 }
 
+const encryptionKeyColumns = `id, bucket_name, version, encrypted_key, algorithm, master_key_id, created_at`
+
+// GetKey returns the latest data key version of a bucket.
 func (r *EncryptionRepository) GetKey(ctx context.Context, bucketName string) (*ports.EncryptionKey, error) {
-	query := `SELECT id, bucket_name, encrypted_key, algorithm FROM encryption_keys WHERE bucket_name = $1`
+	query := `SELECT ` + encryptionKeyColumns + ` FROM encryption_keys WHERE bucket_name = $1 ORDER BY version DESC LIMIT 1`
+	return r.getKey(ctx, query, bucketName)
+}
+
+// GetKeyVersion returns a specific data key version of a bucket.
+func (r *EncryptionRepository) GetKeyVersion(ctx context.Context, bucketName string, version int) (*ports.EncryptionKey, error) {
+	query := `SELECT ` + encryptionKeyColumns + ` FROM encryption_keys WHERE bucket_name = $1 AND version = $2`
+	return r.getKey(ctx, query, bucketName, version)
+}
+
+func (r *EncryptionRepository) getKey(ctx context.Context, query string, args ...interface{}) (*ports.EncryptionKey, error) {
 	var k ports.EncryptionKey
-	err := r.db.QueryRow(ctx, query, bucketName).Scan(&k.ID, &k.BucketName, &k.EncryptedKey, &k.Algorithm)
+	err := r.db.QueryRow(ctx, query, args...).Scan(&k.ID, &k.BucketName, &k.Version, &k.EncryptedKey, &k.Algorithm, &k.MasterKeyID, &k.CreatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, cerr.New(cerr.NotFound, "encryption key not found")
@@ -48,3 +59,35 @@ func (r *EncryptionRepository) GetKey(ctx context.Context, bucketName string) (*
 	}
 	return &k, nil
 }
+
+// ListKeysNotWrappedBy returns data keys wrapped by any master key other than masterKeyID.
+func (r *EncryptionRepository) ListKeysNotWrappedBy(ctx context.Context, masterKeyID string, limit int) ([]ports.EncryptionKey, error) {
+	query := `SELECT ` + encryptionKeyColumns + ` FROM encryption_keys WHERE master_key_id <> $1 ORDER BY created_at LIMIT $2`
+	rows, err := r.db.Query(ctx, query, masterKeyID, limit)
+	if err != nil {
+		return nil, cerr.Wrap(cerr.Internal, "failed to list encryption keys", err)
+	}
+	defer rows.Close()
+
+	var keys []ports.EncryptionKey
+	for rows.Next() {
+		var k ports.EncryptionKey
+		if err := rows.Scan(&k.ID, &k.BucketName, &k.Version, &k.EncryptedKey, &k.Algorithm, &k.MasterKeyID, &k.CreatedAt); err != nil {
+			return nil, cerr.Wrap(cerr.Internal, "failed to scan encryption key", err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// UpdateWrappedKey replaces the wrapped form of a data key after re-wrapping it.
+func (r *EncryptionRepository) UpdateWrappedKey(ctx context.Context, id string, encryptedKey []byte, masterKeyID string) error {
+	cmd, err := r.db.Exec(ctx, `UPDATE encryption_keys SET encrypted_key = $1, master_key_id = $2 WHERE id = $3`, encryptedKey, masterKeyID, id)
+	if err != nil {
+		return cerr.Wrap(cerr.Internal, "failed to update encryption key", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return cerr.New(cerr.NotFound, "encryption key not found")
+	}
+	return nil
+}
@@ -3,18 +3,24 @@ package postgres
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/pashagolub/pgxmock/v3"
 	"github.com/poyrazk/thecloud/internal/core/ports"
+	cerr "github.com/poyrazk/thecloud/internal/errors"
 	"github.com/stretchr/testify/assert"
 )
 
 const (
-	testEncKey    = "secret"
-	testAlgorithm = "AES256"
+	testEncKey      = "secret"
+	testAlgorithm   = "AES256"
+	testMasterKeyID = "0011223344556677"
 )
 
+var encryptionKeyTestColumns = []string{"id", "bucket_name", "version", "encrypted_key", "algorithm", "master_key_id", "created_at"}
+
 func TestEncryptionRepository(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -28,12 +34,14 @@ func TestEncryptionRepository(t *testing.T) {
 		key := ports.EncryptionKey{
 			ID:           uuid.New().String(),
 			BucketName:   bucketName,
+			Version:      2,
 			EncryptedKey: []byte(testEncKey),
 			Algorithm:    testAlgorithm,
+			MasterKeyID:  testMasterKeyID,
 		}
 
 		mock.ExpectExec("INSERT INTO encryption_keys").
-			WithArgs(key.ID, key.BucketName, key.EncryptedKey, key.Algorithm).
+			WithArgs(key.ID, key.BucketName, key.Version, key.EncryptedKey, key.Algorithm, key.MasterKeyID).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		err := repo.SaveKey(ctx, key)
@@ -47,14 +55,63 @@ func TestEncryptionRepository(t *testing.T) {
 		repo := NewEncryptionRepository(mock)
 		id := uuid.New().String()
 
-		mock.ExpectQuery("SELECT id, bucket_name, encrypted_key, algorithm FROM encryption_keys").
+		mock.ExpectQuery("SELECT .* FROM encryption_keys WHERE bucket_name = \\$1 ORDER BY version DESC").
 			WithArgs(bucketName).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "bucket_name", "encrypted_key", "algorithm"}).
-				AddRow(id, bucketName, []byte(testEncKey), testAlgorithm))
+			WillReturnRows(pgxmock.NewRows(encryptionKeyTestColumns).
+				AddRow(id, bucketName, 3, []byte(testEncKey), testAlgorithm, testMasterKeyID, time.Now()))
 
 		key, err := repo.GetKey(ctx, bucketName)
 		assert.NoError(t, err)
 		assert.NotNil(t, key)
 		assert.Equal(t, id, key.ID)
+		assert.Equal(t, 3, key.Version)
+	})
+
+	t.Run("GetKeyVersionNotFound", func(t *testing.T) {
+		t.Parallel()
+		mock, _ := pgxmock.NewPool()
+		defer mock.Close()
+		repo := NewEncryptionRepository(mock)
+
+		mock.ExpectQuery("SELECT .* FROM encryption_keys WHERE bucket_name = \\$1 AND version = \\$2").
+			WithArgs(bucketName, 1).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, err := repo.GetKeyVersion(ctx, bucketName, 1)
+		assert.True(t, cerr.Is(err, cerr.NotFound))
+	})
+
+	t.Run("ListKeysNotWrappedBy", func(t *testing.T) {
+		t.Parallel()
+		mock, _ := pgxmock.NewPool()
+		defer mock.Close()
+		repo := NewEncryptionRepository(mock)
+
+		mock.ExpectQuery("SELECT .* FROM encryption_keys WHERE master_key_id <> \\$1").
+			WithArgs(testMasterKeyID, 100).
+			WillReturnRows(pgxmock.NewRows(encryptionKeyTestColumns).
+				AddRow("k1", bucketName, 1, []byte(testEncKey), testAlgorithm, "", time.Now()))
+
+		keys, err := repo.ListKeysNotWrappedBy(ctx, testMasterKeyID, 100)
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+		assert.Empty(t, keys[0].MasterKeyID)
+	})
+
+	t.Run("UpdateWrappedKey", func(t *testing.T) {
+		t.Parallel()
+		mock, _ := pgxmock.NewPool()
+		defer mock.Close()
+		repo := NewEncryptionRepository(mock)
+
+		mock.ExpectExec("UPDATE encryption_keys SET encrypted_key").
+			WithArgs([]byte(testEncKey), testMasterKeyID, "k1").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("UPDATE encryption_keys SET encrypted_key").
+			WithArgs([]byte(testEncKey), testMasterKeyID, "missing").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		assert.NoError(t, repo.UpdateWrappedKey(ctx, "k1", []byte(testEncKey), testMasterKeyID))
+		assert.True(t, cerr.Is(repo.UpdateWrappedKey(ctx, "missing", []byte(testEncKey), testMasterKeyID), cerr.NotFound))
 	})
 }
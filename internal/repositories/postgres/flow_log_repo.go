@@ -0,0 +1,109 @@
+// Package postgres provides PostgreSQL-backed repository implementations.
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+)
+
+const flowLogColumns = `id, user_id, tenant_id, vpc_id, resource_type, resource_id, traffic_type, arn, created_at`
+
+// FlowLogRepository provides a PostgreSQL implementation for flow log configurations.
+type FlowLogRepository struct {
+	db DB
+}
+
+// NewFlowLogRepository creates a new FlowLogRepository.
+func NewFlowLogRepository(db DB) *FlowLogRepository {
+	return &FlowLogRepository{db: db}
+}
+
+// Create inserts a flow log. A second flow log for the same resource and traffic type is a conflict.
+func (r *FlowLogRepository) Create(ctx context.Context, fl *domain.FlowLog) error {
+	query := `INSERT INTO flow_logs (` + flowLogColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (resource_id, traffic_type) DO NOTHING`
+	cmd, err := r.db.Exec(ctx, query, fl.ID, fl.UserID, fl.TenantID, fl.VPCID, string(fl.ResourceType),
+		fl.ResourceID, string(fl.TrafficType), fl.ARN, fl.CreatedAt)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to create flow log", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.Conflict, "a flow log with this traffic type already exists for the resource")
+	}
+	return nil
+}
+
+// GetByID retrieves a flow log of the caller's tenant.
+func (r *FlowLogRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.FlowLog, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + flowLogColumns + ` FROM flow_logs WHERE id = $1 AND tenant_id = $2`
+	return r.scanFlowLog(r.db.QueryRow(ctx, query, id, tenantID))
+}
+
+// List returns the caller's tenant's flow logs.
+func (r *FlowLogRepository) List(ctx context.Context) ([]*domain.FlowLog, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + flowLogColumns + ` FROM flow_logs WHERE tenant_id = $1 ORDER BY created_at`
+	rows, err := r.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list flow logs", err)
+	}
+	return r.scanFlowLogs(rows)
+}
+
+// ListAll returns every flow log across tenants.
+func (r *FlowLogRepository) ListAll(ctx context.Context) ([]*domain.FlowLog, error) {
+	query := `SELECT ` + flowLogColumns + ` FROM flow_logs ORDER BY vpc_id, created_at`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list all flow logs", err)
+	}
+	return r.scanFlowLogs(rows)
+}
+
+// Delete removes a flow log of the caller's tenant.
+func (r *FlowLogRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	cmd, err := r.db.Exec(ctx, `DELETE FROM flow_logs WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete flow log", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "flow log not found")
+	}
+	return nil
+}
+
+func (r *FlowLogRepository) scanFlowLogs(rows pgx.Rows) ([]*domain.FlowLog, error) {
+	defer rows.Close()
+	var logs []*domain.FlowLog
+	for rows.Next() {
+		fl, err := r.scanFlowLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, fl)
+	}
+	return logs, rows.Err()
+}
+
+func (r *FlowLogRepository) scanFlowLog(row pgx.Row) (*domain.FlowLog, error) {
+	var fl domain.FlowLog
+	var resourceType, trafficType string
+	err := row.Scan(&fl.ID, &fl.UserID, &fl.TenantID, &fl.VPCID, &resourceType, &fl.ResourceID, &trafficType, &fl.ARN, &fl.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.NotFound, "flow log not found")
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to scan flow log", err)
+	}
+	fl.ResourceType = domain.FlowLogResourceType(resourceType)
+	fl.TrafficType = domain.FlowLogTrafficType(trafficType)
+	return &fl, nil
+}
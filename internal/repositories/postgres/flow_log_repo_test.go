@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	theclouderrors "github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlowLogRepository(t *testing.T) {
+	tenantID := uuid.New()
+	ctx := appcontext.WithTenantID(context.Background(), tenantID)
+	fl := &domain.FlowLog{
+		ID: uuid.New(), UserID: uuid.New(), TenantID: tenantID, VPCID: uuid.New(),
+		ResourceType: domain.FlowLogSubnet, ResourceID: uuid.New(), TrafficType: domain.FlowTrafficReject,
+		ARN: "arn", CreatedAt: time.Now(),
+	}
+	columns := []string{"id", "user_id", "tenant_id", "vpc_id", "resource_type", "resource_id", "traffic_type", "arn", "created_at"}
+
+	t.Run("Create", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("INSERT INTO flow_logs").
+			WithArgs(fl.ID, fl.UserID, fl.TenantID, fl.VPCID, "subnet", fl.ResourceID, "reject", fl.ARN, fl.CreatedAt).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, NewFlowLogRepository(mock).Create(ctx, fl))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Create duplicate", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("INSERT INTO flow_logs").
+			WithArgs(fl.ID, fl.UserID, fl.TenantID, fl.VPCID, "subnet", fl.ResourceID, "reject", fl.ARN, fl.CreatedAt).
+			WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+		err = NewFlowLogRepository(mock).Create(ctx, fl)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.Conflict))
+	})
+
+	t.Run("GetByID", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectQuery("SELECT .* FROM flow_logs WHERE id = \\$1 AND tenant_id = \\$2").
+			WithArgs(fl.ID, tenantID).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(fl.ID, fl.UserID, fl.TenantID, fl.VPCID, "subnet", fl.ResourceID, "reject", fl.ARN, fl.CreatedAt))
+
+		got, err := NewFlowLogRepository(mock).GetByID(ctx, fl.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.FlowLogSubnet, got.ResourceType)
+		assert.Equal(t, domain.FlowTrafficReject, got.TrafficType)
+	})
+
+	t.Run("GetByID not found", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectQuery("FROM flow_logs").
+			WithArgs(fl.ID, tenantID).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, err = NewFlowLogRepository(mock).GetByID(ctx, fl.ID)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("ListAll", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectQuery("SELECT .* FROM flow_logs ORDER BY vpc_id").
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(fl.ID, fl.UserID, fl.TenantID, fl.VPCID, "vpc", fl.VPCID, "all", fl.ARN, fl.CreatedAt))
+
+		logs, err := NewFlowLogRepository(mock).ListAll(context.Background())
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		assert.Equal(t, domain.FlowTrafficAll, logs[0].TrafficType)
+	})
+
+	t.Run("Delete not found", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("DELETE FROM flow_logs").
+			WithArgs(fl.ID, tenantID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		err = NewFlowLogRepository(mock).Delete(ctx, fl.ID)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+}
@@ -3,13 +3,17 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
 )
 
+const gatewayRouteColumns = `id, user_id, name, path_prefix, path_pattern, pattern_type, param_names, target_url, methods, strip_prefix, rate_limit, priority, plugins, upstreams, traffic_policy, tenant_id, certificate_id, https_redirect, created_at, updated_at`
+
 // PostgresGatewayRepository provides PostgreSQL-backed gateway route persistence.
 type PostgresGatewayRepository struct {
 	db DB
@@ -21,14 +25,32 @@ func NewPostgresGatewayRepository(db DB) ports.GatewayRepository {
 }
 
 func (r *PostgresGatewayRepository) CreateRoute(ctx context.Context, route *domain.GatewayRoute) error {
+	plugins, err := json.Marshal(route.Plugins)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to encode gateway route plugins", err)
+	}
+	upstreams := route.Upstreams
+	if upstreams == nil {
+		upstreams = []domain.GatewayUpstream{}
+	}
+	upstreamsJSON, err := json.Marshal(upstreams)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to encode gateway route upstreams", err)
+	}
+	policy, err := json.Marshal(route.TrafficPolicy)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to encode gateway route traffic policy", err)
+	}
+	var tenantID *uuid.UUID
+	if route.TenantID != uuid.Nil {
+		tenantID = &route.TenantID
+	}
+
 	query := `
-		INSERT INTO gateway_routes (
-			id, user_id, name, path_prefix, path_pattern, pattern_type, 
-			param_names, target_url, methods, strip_prefix, rate_limit, priority, created_at, updated_at
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		INSERT INTO gateway_routes (` + gatewayRouteColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 	`
-	_, err := r.db.Exec(ctx, query,
+	_, err = r.db.Exec(ctx, query,
 		route.ID,
 		route.UserID,
 		route.Name,
@@ -41,6 +63,12 @@ func (r *PostgresGatewayRepository) CreateRoute(ctx context.Context, route *doma
 		route.StripPrefix,
 		route.RateLimit,
 		route.Priority,
+		plugins,
+		upstreamsJSON,
+		policy,
+		tenantID,
+		route.CertificateID,
+		route.HTTPSRedirect,
 		route.CreatedAt,
 		route.UpdatedAt,
 	)
@@ -48,12 +76,12 @@ func (r *PostgresGatewayRepository) CreateRoute(ctx context.Context, route *doma
 }
 
 func (r *PostgresGatewayRepository) GetRouteByID(ctx context.Context, id, userID uuid.UUID) (*domain.GatewayRoute, error) {
-	query := `SELECT id, user_id, name, path_prefix, path_pattern, pattern_type, param_names, target_url, methods, strip_prefix, rate_limit, priority, created_at, updated_at FROM gateway_routes WHERE id = $1 AND user_id = $2`
+	query := `SELECT ` + gatewayRouteColumns + ` FROM gateway_routes WHERE id = $1 AND user_id = $2`
 	return r.scanRoute(r.db.QueryRow(ctx, query, id, userID))
 }
 
 func (r *PostgresGatewayRepository) ListRoutes(ctx context.Context, userID uuid.UUID) ([]*domain.GatewayRoute, error) {
-	query := `SELECT id, user_id, name, path_prefix, path_pattern, pattern_type, param_names, target_url, methods, strip_prefix, rate_limit, priority, created_at, updated_at FROM gateway_routes WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT ` + gatewayRouteColumns + ` FROM gateway_routes WHERE user_id = $1 ORDER BY created_at DESC`
 	rows, err := r.db.Query(ctx, query, userID)
 	if err != nil {
 		return nil, err
@@ -68,7 +96,7 @@ func (r *PostgresGatewayRepository) DeleteRoute(ctx context.Context, id uuid.UUI
 }
 
 func (r *PostgresGatewayRepository) GetAllActiveRoutes(ctx context.Context) ([]*domain.GatewayRoute, error) {
-	query := `SELECT id, user_id, name, path_prefix, path_pattern, pattern_type, param_names, target_url, methods, strip_prefix, rate_limit, priority, created_at, updated_at FROM gateway_routes`
+	query := `SELECT ` + gatewayRouteColumns + ` FROM gateway_routes`
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, err
@@ -78,6 +106,8 @@ func (r *PostgresGatewayRepository) GetAllActiveRoutes(ctx context.Context) ([]*
 
 func (r *PostgresGatewayRepository) scanRoute(row pgx.Row) (*domain.GatewayRoute, error) {
 	var route domain.GatewayRoute
+	var plugins, upstreams, policy []byte
+	var tenantID *uuid.UUID
 	err := row.Scan(
 		&route.ID,
 		&route.UserID,
@@ -91,12 +121,36 @@ func (r *PostgresGatewayRepository) scanRoute(row pgx.Row) (*domain.GatewayRoute
 		&route.StripPrefix,
 		&route.RateLimit,
 		&route.Priority,
+		&plugins,
+		&upstreams,
+		&policy,
+		&tenantID,
+		&route.CertificateID,
+		&route.HTTPSRedirect,
 		&route.CreatedAt,
 		&route.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if len(plugins) > 0 {
+		if err := json.Unmarshal(plugins, &route.Plugins); err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to decode gateway route plugins", err)
+		}
+	}
+	if len(upstreams) > 0 {
+		if err := json.Unmarshal(upstreams, &route.Upstreams); err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to decode gateway route upstreams", err)
+		}
+	}
+	if len(policy) > 0 {
+		if err := json.Unmarshal(policy, &route.TrafficPolicy); err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to decode gateway route traffic policy", err)
+		}
+	}
+	if tenantID != nil {
+		route.TenantID = *tenantID
+	}
 	return &route, nil
 }
 
@@ -36,8 +36,18 @@ func TestPostgresGatewayRepository(t *testing.T) {
 			StripPrefix: true,
 			RateLimit:   100,
 			Priority:    5,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+			Plugins: domain.GatewayRoutePlugins{
+				CORS:         &domain.GatewayCORSConfig{AllowOrigins: []string{"https://app.example.com"}},
+				MaxBodyBytes: 1024,
+			},
+			Upstreams: []domain.GatewayUpstream{
+				{Type: domain.GatewayUpstreamURL, URL: "http://v1:80", Weight: 90},
+				{Type: domain.GatewayUpstreamURL, URL: "http://v2:80", Weight: 10},
+			},
+			TrafficPolicy: domain.GatewayTrafficPolicy{Retries: 2, TimeoutMs: 500},
+			TenantID:      appcontext.TenantIDFromContext(ctx),
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
 		}
 
 		err := repo.CreateRoute(ctx, route)
@@ -46,5 +56,16 @@ func TestPostgresGatewayRepository(t *testing.T) {
 		routes, err := repo.ListRoutes(ctx, userID)
 		require.NoError(t, err)
 		assert.NotEmpty(t, routes)
+
+		fetched, err := repo.GetRouteByID(ctx, route.ID, userID)
+		require.NoError(t, err)
+		require.NotNil(t, fetched.Plugins.CORS)
+		assert.Equal(t, []string{"https://app.example.com"}, fetched.Plugins.CORS.AllowOrigins)
+		assert.Equal(t, int64(1024), fetched.Plugins.MaxBodyBytes)
+		assert.Equal(t, route.Upstreams, fetched.Upstreams)
+		assert.Equal(t, 2, fetched.TrafficPolicy.Retries)
+		assert.Equal(t, route.TenantID, fetched.TenantID)
+		assert.Nil(t, fetched.CertificateID)
+		assert.False(t, fetched.HTTPSRedirect)
 	})
 }
@@ -185,6 +185,87 @@ func (r *globalLBRepository) UpdateEndpointHealth(ctx context.Context, epID uuid
 	return err
 }
 
+func (r *globalLBRepository) ListAll(ctx context.Context) ([]*domain.GlobalLoadBalancer, error) {
+	query := `
+		SELECT id, user_id, tenant_id, name, hostname, policy,
+		health_check_protocol, health_check_port, health_check_path,
+		health_check_interval, health_check_timeout, health_check_healthy_count, health_check_unhealthy_count,
+		status, created_at, updated_at
+		FROM global_load_balancers
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*domain.GlobalLoadBalancer
+	for rows.Next() {
+		glb, err := scanGlobalLB(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, glb)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.Internal, "iteration error", err)
+	}
+
+	return list, nil
+}
+
+func (r *globalLBRepository) RecordHealthCheck(ctx context.Context, check *domain.GlobalEndpointHealthCheck) error {
+	query := `
+		INSERT INTO global_lb_health_checks (
+			id, endpoint_id, global_lb_id, healthy, latency_ms, status_code, error, checked_at
+		) VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), NULLIF($7, ''), $8)
+	`
+	_, err := r.db.Exec(ctx, query,
+		check.ID, check.EndpointID, check.GlobalLBID, check.Healthy,
+		check.LatencyMs, check.StatusCode, check.Error, check.CheckedAt,
+	)
+	return err
+}
+
+func (r *globalLBRepository) ListHealthChecks(ctx context.Context, endpointID uuid.UUID, limit int) ([]*domain.GlobalEndpointHealthCheck, error) {
+	query := `
+		SELECT id, endpoint_id, global_lb_id, healthy, latency_ms,
+		       COALESCE(status_code, 0), COALESCE(error, ''), checked_at
+		FROM global_lb_health_checks
+		WHERE endpoint_id = $1
+		ORDER BY checked_at DESC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, endpointID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*domain.GlobalEndpointHealthCheck
+	for rows.Next() {
+		var hc domain.GlobalEndpointHealthCheck
+		if err := rows.Scan(
+			&hc.ID, &hc.EndpointID, &hc.GlobalLBID, &hc.Healthy, &hc.LatencyMs,
+			&hc.StatusCode, &hc.Error, &hc.CheckedAt,
+		); err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to scan health check", err)
+		}
+		list = append(list, &hc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.Internal, "iteration error", err)
+	}
+
+	return list, nil
+}
+
+func (r *globalLBRepository) DeleteHealthChecksBefore(ctx context.Context, before time.Time) error {
+	query := `DELETE FROM global_lb_health_checks WHERE checked_at < $1`
+	_, err := r.db.Exec(ctx, query, before)
+	return err
+}
+
 // Helpers
 
 type scanner interface {
@@ -120,6 +120,37 @@ func TestGlobalLBRepositoryIntegration(t *testing.T) {
 		assert.False(t, epsUpdated[0].Healthy)
 	})
 
+	t.Run("HealthCheckHistory", func(t *testing.T) {
+		eps, err := repo.ListEndpoints(ctx, glbID)
+		require.NoError(t, err)
+		require.NotEmpty(t, eps)
+		epID := eps[0].ID
+
+		old := time.Now().Add(-48 * time.Hour)
+		require.NoError(t, repo.RecordHealthCheck(ctx, &domain.GlobalEndpointHealthCheck{
+			ID: uuid.New(), EndpointID: epID, GlobalLBID: glbID, Healthy: true, LatencyMs: 3, CheckedAt: old,
+		}))
+		require.NoError(t, repo.RecordHealthCheck(ctx, &domain.GlobalEndpointHealthCheck{
+			ID: uuid.New(), EndpointID: epID, GlobalLBID: glbID, Healthy: false, StatusCode: 503, Error: "unexpected status 503", CheckedAt: time.Now(),
+		}))
+
+		checks, err := repo.ListHealthChecks(ctx, epID, 10)
+		require.NoError(t, err)
+		require.Len(t, checks, 2)
+		assert.False(t, checks[0].Healthy)
+		assert.Equal(t, 503, checks[0].StatusCode)
+		assert.Equal(t, 0, checks[1].StatusCode)
+
+		require.NoError(t, repo.DeleteHealthChecksBefore(ctx, time.Now().Add(-24*time.Hour)))
+		checks, err = repo.ListHealthChecks(ctx, epID, 10)
+		require.NoError(t, err)
+		assert.Len(t, checks, 1)
+
+		all, err := repo.ListAll(ctx)
+		require.NoError(t, err)
+		assert.NotEmpty(t, all)
+	})
+
 	t.Run("Delete", func(t *testing.T) {
 		err := repo.Delete(ctx, glbID, userID)
 		require.NoError(t, err)
@@ -28,15 +28,15 @@ func (r *InstanceRepository) Create(ctx context.Context, inst *domain.Instance)
 	query := `
 		INSERT INTO instances (
 			id, user_id, tenant_id, name, image, container_id, status, ports, vpc_id, subnet_id, 
-			private_ip, ovs_port, instance_type, volume_binds, env, cmd, cpu_limit, memory_limit, disk_limit,
+			private_ip, private_ipv6, ovs_port, instance_type, volume_binds, env, cmd, cpu_limit, memory_limit, disk_limit,
 			metadata, labels, ssh_key_id,
 			version, created_at, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, '')::inet, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, '')::inet, NULLIF($12, '')::inet, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26)
 	`
 	_, err := r.db.Exec(ctx, query,
 		inst.ID, inst.UserID, inst.TenantID, inst.Name, inst.Image, inst.ContainerID, string(inst.Status), inst.Ports, inst.VpcID, inst.SubnetID,
-		inst.PrivateIP, inst.OvsPort, inst.InstanceType, inst.VolumeBinds, inst.Env, inst.Cmd, inst.CPULimit, inst.MemoryLimit, inst.DiskLimit,
+		inst.PrivateIP, inst.PrivateIPv6, inst.OvsPort, inst.InstanceType, inst.VolumeBinds, inst.Env, inst.Cmd, inst.CPULimit, inst.MemoryLimit, inst.DiskLimit,
 		inst.Metadata, inst.Labels, inst.SSHKeyID,
 		inst.Version, inst.CreatedAt, inst.UpdatedAt,
 	)
@@ -50,7 +50,7 @@ func (r *InstanceRepository) Create(ctx context.Context, inst *domain.Instance)
 func (r *InstanceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Instance, error) {
 	tenantID := appcontext.TenantIDFromContext(ctx)
 	query := `
-		SELECT id, user_id, tenant_id, name, image, COALESCE(container_id, ''), status, COALESCE(ports, ''), vpc_id, subnet_id, COALESCE(private_ip::text, ''), COALESCE(ovs_port, ''), COALESCE(instance_type, ''), 
+		SELECT id, user_id, tenant_id, name, image, COALESCE(container_id, ''), status, COALESCE(ports, ''), vpc_id, subnet_id, COALESCE(private_ip::text, ''), COALESCE(private_ipv6::text, ''), COALESCE(ovs_port, ''), COALESCE(instance_type, ''), 
 		       volume_binds, env, cmd, COALESCE(cpu_limit, 0), COALESCE(memory_limit, 0), COALESCE(disk_limit, 0),
 		       COALESCE(metadata, '{}'::jsonb), COALESCE(labels, '{}'::jsonb), ssh_key_id,
 		       version, created_at, updated_at
@@ -64,7 +64,7 @@ func (r *InstanceRepository) scanInstance(row pgx.Row) (*domain.Instance, error)
 	var inst domain.Instance
 	var status string
 	err := row.Scan(
-		&inst.ID, &inst.UserID, &inst.TenantID, &inst.Name, &inst.Image, &inst.ContainerID, &status, &inst.Ports, &inst.VpcID, &inst.SubnetID, &inst.PrivateIP, &inst.OvsPort, &inst.InstanceType,
+		&inst.ID, &inst.UserID, &inst.TenantID, &inst.Name, &inst.Image, &inst.ContainerID, &status, &inst.Ports, &inst.VpcID, &inst.SubnetID, &inst.PrivateIP, &inst.PrivateIPv6, &inst.OvsPort, &inst.InstanceType,
 		&inst.VolumeBinds, &inst.Env, &inst.Cmd, &inst.CPULimit, &inst.MemoryLimit, &inst.DiskLimit,
 		&inst.Metadata, &inst.Labels, &inst.SSHKeyID,
 		&inst.Version, &inst.CreatedAt, &inst.UpdatedAt,
@@ -83,7 +83,7 @@ func (r *InstanceRepository) scanInstance(row pgx.Row) (*domain.Instance, error)
 func (r *InstanceRepository) GetByName(ctx context.Context, name string) (*domain.Instance, error) {
 	tenantID := appcontext.TenantIDFromContext(ctx)
 	query := `
-		SELECT id, user_id, tenant_id, name, image, COALESCE(container_id, ''), status, COALESCE(ports, ''), vpc_id, subnet_id, COALESCE(private_ip::text, ''), COALESCE(ovs_port, ''), COALESCE(instance_type, ''), 
+		SELECT id, user_id, tenant_id, name, image, COALESCE(container_id, ''), status, COALESCE(ports, ''), vpc_id, subnet_id, COALESCE(private_ip::text, ''), COALESCE(private_ipv6::text, ''), COALESCE(ovs_port, ''), COALESCE(instance_type, ''), 
 		       volume_binds, env, cmd, COALESCE(cpu_limit, 0), COALESCE(memory_limit, 0), COALESCE(disk_limit, 0),
 		       COALESCE(metadata, '{}'::jsonb), COALESCE(labels, '{}'::jsonb), ssh_key_id,
 		       version, created_at, updated_at
@@ -97,7 +97,7 @@ func (r *InstanceRepository) GetByName(ctx context.Context, name string) (*domai
 func (r *InstanceRepository) List(ctx context.Context) ([]*domain.Instance, error) {
 	tenantID := appcontext.TenantIDFromContext(ctx)
 	query := `
-		SELECT id, user_id, tenant_id, name, image, COALESCE(container_id, ''), status, COALESCE(ports, ''), vpc_id, subnet_id, COALESCE(private_ip::text, ''), COALESCE(ovs_port, ''), COALESCE(instance_type, ''), 
+		SELECT id, user_id, tenant_id, name, image, COALESCE(container_id, ''), status, COALESCE(ports, ''), vpc_id, subnet_id, COALESCE(private_ip::text, ''), COALESCE(private_ipv6::text, ''), COALESCE(ovs_port, ''), COALESCE(instance_type, ''), 
 		       volume_binds, env, cmd, COALESCE(cpu_limit, 0), COALESCE(memory_limit, 0), COALESCE(disk_limit, 0),
 		       COALESCE(metadata, '{}'::jsonb), COALESCE(labels, '{}'::jsonb), ssh_key_id,
 		       version, created_at, updated_at
@@ -114,7 +114,7 @@ func (r *InstanceRepository) List(ctx context.Context) ([]*domain.Instance, erro
 
 func (r *InstanceRepository) ListAll(ctx context.Context) ([]*domain.Instance, error) {
 	query := `
-		SELECT id, user_id, tenant_id, name, image, COALESCE(container_id, ''), status, COALESCE(ports, ''), vpc_id, subnet_id, COALESCE(private_ip::text, ''), COALESCE(ovs_port, ''), COALESCE(instance_type, ''), 
+		SELECT id, user_id, tenant_id, name, image, COALESCE(container_id, ''), status, COALESCE(ports, ''), vpc_id, subnet_id, COALESCE(private_ip::text, ''), COALESCE(private_ipv6::text, ''), COALESCE(ovs_port, ''), COALESCE(instance_type, ''), 
 		       volume_binds, env, cmd, COALESCE(cpu_limit, 0), COALESCE(memory_limit, 0), COALESCE(disk_limit, 0),
 		       COALESCE(metadata, '{}'::jsonb), COALESCE(labels, '{}'::jsonb), ssh_key_id,
 		       version, created_at, updated_at
@@ -133,13 +133,13 @@ func (r *InstanceRepository) Update(ctx context.Context, inst *domain.Instance)
 	// Implements Optimistic Locking via 'version'
 	query := `
 		UPDATE instances
-		SET name = $1, status = $2, version = version + 1, updated_at = $3, container_id = $4, ports = $5, vpc_id = $6, subnet_id = $7, private_ip = NULLIF($8, '')::inet, ovs_port = $9, instance_type = $10,
-		    volume_binds = $11, env = $12, cmd = $13, cpu_limit = $14, memory_limit = $15, disk_limit = $16,
-		    metadata = $17, labels = $18, ssh_key_id = $19
-		WHERE id = $20 AND version = $21 AND tenant_id = $22
+		SET name = $1, status = $2, version = version + 1, updated_at = $3, container_id = $4, ports = $5, vpc_id = $6, subnet_id = $7, private_ip = NULLIF($8, '')::inet, private_ipv6 = NULLIF($9, '')::inet, ovs_port = $10, instance_type = $11,
+		    volume_binds = $12, env = $13, cmd = $14, cpu_limit = $15, memory_limit = $16, disk_limit = $17,
+		    metadata = $18, labels = $19, ssh_key_id = $20
+		WHERE id = $21 AND version = $22 AND tenant_id = $23
 	`
 	now := time.Now()
-	cmd, err := r.db.Exec(ctx, query, inst.Name, string(inst.Status), now, inst.ContainerID, inst.Ports, inst.VpcID, inst.SubnetID, inst.PrivateIP, inst.OvsPort, inst.InstanceType,
+	cmd, err := r.db.Exec(ctx, query, inst.Name, string(inst.Status), now, inst.ContainerID, inst.Ports, inst.VpcID, inst.SubnetID, inst.PrivateIP, inst.PrivateIPv6, inst.OvsPort, inst.InstanceType,
 		inst.VolumeBinds, inst.Env, inst.Cmd, inst.CPULimit, inst.MemoryLimit, inst.DiskLimit,
 		inst.Metadata, inst.Labels, inst.SSHKeyID,
 		inst.ID, inst.Version, inst.TenantID)
@@ -160,7 +160,7 @@ func (r *InstanceRepository) Update(ctx context.Context, inst *domain.Instance)
 func (r *InstanceRepository) ListBySubnet(ctx context.Context, subnetID uuid.UUID) ([]*domain.Instance, error) {
 	tenantID := appcontext.TenantIDFromContext(ctx)
 	query := `
-		SELECT id, user_id, tenant_id, name, image, COALESCE(container_id, ''), status, COALESCE(ports, ''), vpc_id, subnet_id, COALESCE(private_ip::text, ''), COALESCE(ovs_port, ''), COALESCE(instance_type, ''), 
+		SELECT id, user_id, tenant_id, name, image, COALESCE(container_id, ''), status, COALESCE(ports, ''), vpc_id, subnet_id, COALESCE(private_ip::text, ''), COALESCE(private_ipv6::text, ''), COALESCE(ovs_port, ''), COALESCE(instance_type, ''), 
 		       volume_binds, env, cmd, COALESCE(cpu_limit, 0), COALESCE(memory_limit, 0), COALESCE(disk_limit, 0),
 		       COALESCE(metadata, '{}'::jsonb), COALESCE(labels, '{}'::jsonb), ssh_key_id,
 		       version, created_at, updated_at
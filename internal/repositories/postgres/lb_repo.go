@@ -3,6 +3,8 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -23,11 +25,24 @@ func NewLBRepository(db DB) *LBRepository {
 
 func (r *LBRepository) Create(ctx context.Context, lb *domain.LoadBalancer) error {
 	query := `
-		INSERT INTO load_balancers (id, user_id, idempotency_key, name, vpc_id, port, algorithm, ip, status, version, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO load_balancers (id, user_id, idempotency_key, name, vpc_id, port, algorithm, ip, status, version, created_at, tls, listeners, health_check, sticky_sessions, deregistration_delay)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	`
-	_, err := r.db.Exec(ctx, query,
-		lb.ID, lb.UserID, lb.IdempotencyKey, lb.Name, lb.VpcID, lb.Port, lb.Algorithm, lb.IP, lb.Status, lb.Version, lb.CreatedAt,
+	tlsJSON, err := encodeLBTLS(lb.TLS)
+	if err != nil {
+		return err
+	}
+	listenersJSON, err := encodeLBListeners(lb.Listeners)
+	if err != nil {
+		return err
+	}
+	healthJSON, stickyJSON, err := encodeLBTargetPolicies(lb)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(ctx, query,
+		lb.ID, lb.UserID, lb.IdempotencyKey, lb.Name, lb.VpcID, lb.Port, lb.Algorithm, lb.IP, lb.Status, lb.Version, lb.CreatedAt, tlsJSON, listenersJSON,
+		healthJSON, stickyJSON, lb.DeregistrationDelaySeconds,
 	)
 	if err != nil {
 		// Check for unique constraint violation on idempotency_key
@@ -39,7 +54,7 @@ func (r *LBRepository) Create(ctx context.Context, lb *domain.LoadBalancer) erro
 func (r *LBRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.LoadBalancer, error) {
 	userID := appcontext.UserIDFromContext(ctx)
 	query := `
-		SELECT id, user_id, COALESCE(idempotency_key, ''), name, vpc_id, port, algorithm, COALESCE(ip, ''), status, version, created_at
+		SELECT id, user_id, COALESCE(idempotency_key, ''), name, vpc_id, port, algorithm, COALESCE(ip, ''), status, version, created_at, tls, listeners, health_check, sticky_sessions, deregistration_delay
 		FROM load_balancers
 		WHERE id = $1 AND user_id = $2
 	`
@@ -49,7 +64,7 @@ func (r *LBRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.LoadB
 func (r *LBRepository) GetByName(ctx context.Context, name string) (*domain.LoadBalancer, error) {
 	userID := appcontext.UserIDFromContext(ctx)
 	query := `
-		SELECT id, user_id, COALESCE(idempotency_key, ''), name, vpc_id, port, algorithm, COALESCE(ip, ''), status, version, created_at
+		SELECT id, user_id, COALESCE(idempotency_key, ''), name, vpc_id, port, algorithm, COALESCE(ip, ''), status, version, created_at, tls, listeners, health_check, sticky_sessions, deregistration_delay
 		FROM load_balancers
 		WHERE name = $1 AND user_id = $2
 	`
@@ -62,7 +77,7 @@ func (r *LBRepository) GetByIdempotencyKey(ctx context.Context, key string) (*do
 	}
 	userID := appcontext.UserIDFromContext(ctx)
 	query := `
-		SELECT id, user_id, idempotency_key, name, vpc_id, port, algorithm, COALESCE(ip, ''), status, version, created_at
+		SELECT id, user_id, idempotency_key, name, vpc_id, port, algorithm, COALESCE(ip, ''), status, version, created_at, tls, listeners, health_check, sticky_sessions, deregistration_delay
 		FROM load_balancers
 		WHERE idempotency_key = $1 AND user_id = $2
 	`
@@ -72,7 +87,7 @@ func (r *LBRepository) GetByIdempotencyKey(ctx context.Context, key string) (*do
 func (r *LBRepository) List(ctx context.Context) ([]*domain.LoadBalancer, error) {
 	userID := appcontext.UserIDFromContext(ctx)
 	query := `
-		SELECT id, user_id, COALESCE(idempotency_key, ''), name, vpc_id, port, algorithm, COALESCE(ip, ''), status, version, created_at
+		SELECT id, user_id, COALESCE(idempotency_key, ''), name, vpc_id, port, algorithm, COALESCE(ip, ''), status, version, created_at, tls, listeners, health_check, sticky_sessions, deregistration_delay
 		FROM load_balancers
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -86,7 +101,7 @@ func (r *LBRepository) List(ctx context.Context) ([]*domain.LoadBalancer, error)
 
 func (r *LBRepository) ListAll(ctx context.Context) ([]*domain.LoadBalancer, error) {
 	query := `
-		SELECT id, user_id, COALESCE(idempotency_key, ''), name, vpc_id, port, algorithm, COALESCE(ip, ''), status, version, created_at
+		SELECT id, user_id, COALESCE(idempotency_key, ''), name, vpc_id, port, algorithm, COALESCE(ip, ''), status, version, created_at, tls, listeners, health_check, sticky_sessions, deregistration_delay
 		FROM load_balancers
 		ORDER BY created_at DESC
 	`
@@ -100,8 +115,10 @@ func (r *LBRepository) ListAll(ctx context.Context) ([]*domain.LoadBalancer, err
 func (r *LBRepository) scanLB(row pgx.Row) (*domain.LoadBalancer, error) {
 	var lb domain.LoadBalancer
 	var status string
+	var tlsJSON, listenersJSON, healthJSON, stickyJSON []byte
 	err := row.Scan(
-		&lb.ID, &lb.UserID, &lb.IdempotencyKey, &lb.Name, &lb.VpcID, &lb.Port, &lb.Algorithm, &lb.IP, &status, &lb.Version, &lb.CreatedAt,
+		&lb.ID, &lb.UserID, &lb.IdempotencyKey, &lb.Name, &lb.VpcID, &lb.Port, &lb.Algorithm, &lb.IP, &status, &lb.Version, &lb.CreatedAt, &tlsJSON, &listenersJSON,
+		&healthJSON, &stickyJSON, &lb.DeregistrationDelaySeconds,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -110,9 +127,69 @@ func (r *LBRepository) scanLB(row pgx.Row) (*domain.LoadBalancer, error) {
 		return nil, errors.Wrap(errors.Internal, "failed to scan load balancer", err)
 	}
 	lb.Status = domain.LBStatus(status)
+	if len(tlsJSON) > 0 {
+		if err := json.Unmarshal(tlsJSON, &lb.TLS); err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to decode load balancer tls config", err)
+		}
+	}
+	if len(listenersJSON) > 0 {
+		if err := json.Unmarshal(listenersJSON, &lb.Listeners); err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to decode load balancer listeners", err)
+		}
+	}
+	if len(healthJSON) > 0 {
+		if err := json.Unmarshal(healthJSON, &lb.HealthCheck); err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to decode load balancer health check", err)
+		}
+	}
+	if len(stickyJSON) > 0 {
+		if err := json.Unmarshal(stickyJSON, &lb.StickySessions); err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to decode load balancer sticky sessions", err)
+		}
+	}
 	return &lb, nil
 }
 
+// encodeLBTLS encodes a TLS config for storage; nil disables HTTPS.
+func encodeLBTLS(cfg *domain.LBTLSConfig) ([]byte, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to encode load balancer tls config", err)
+	}
+	return data, nil
+}
+
+// encodeLBListeners encodes additional listeners for storage; none is NULL.
+func encodeLBListeners(listeners []domain.LBListener) ([]byte, error) {
+	if len(listeners) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(listeners)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to encode load balancer listeners", err)
+	}
+	return data, nil
+}
+
+// encodeLBTargetPolicies encodes the health check and sticky session
+// settings for storage; unset settings are NULL.
+func encodeLBTargetPolicies(lb *domain.LoadBalancer) (health, sticky []byte, err error) {
+	if lb.HealthCheck != nil {
+		if health, err = json.Marshal(lb.HealthCheck); err != nil {
+			return nil, nil, errors.Wrap(errors.Internal, "failed to encode load balancer health check", err)
+		}
+	}
+	if lb.StickySessions != nil {
+		if sticky, err = json.Marshal(lb.StickySessions); err != nil {
+			return nil, nil, errors.Wrap(errors.Internal, "failed to encode load balancer sticky sessions", err)
+		}
+	}
+	return health, sticky, nil
+}
+
 func (r *LBRepository) scanLBs(rows pgx.Rows) ([]*domain.LoadBalancer, error) {
 	defer rows.Close()
 	var lbs []*domain.LoadBalancer
@@ -129,10 +206,24 @@ func (r *LBRepository) scanLBs(rows pgx.Rows) ([]*domain.LoadBalancer, error) {
 func (r *LBRepository) Update(ctx context.Context, lb *domain.LoadBalancer) error {
 	query := `
 		UPDATE load_balancers
-		SET name = $1, port = $2, algorithm = $3, ip = $4, status = $5, version = version + 1
-		WHERE id = $6 AND version = $7 AND user_id = $8
+		SET name = $1, port = $2, algorithm = $3, ip = $4, status = $5, tls = $6, listeners = $7,
+			health_check = $8, sticky_sessions = $9, deregistration_delay = $10, version = version + 1
+		WHERE id = $11 AND version = $12 AND user_id = $13
 	`
-	cmd, err := r.db.Exec(ctx, query, lb.Name, lb.Port, lb.Algorithm, lb.IP, lb.Status, lb.ID, lb.Version, lb.UserID)
+	tlsJSON, err := encodeLBTLS(lb.TLS)
+	if err != nil {
+		return err
+	}
+	listenersJSON, err := encodeLBListeners(lb.Listeners)
+	if err != nil {
+		return err
+	}
+	healthJSON, stickyJSON, err := encodeLBTargetPolicies(lb)
+	if err != nil {
+		return err
+	}
+	cmd, err := r.db.Exec(ctx, query, lb.Name, lb.Port, lb.Algorithm, lb.IP, lb.Status, tlsJSON, listenersJSON,
+		healthJSON, stickyJSON, lb.DeregistrationDelaySeconds, lb.ID, lb.Version, lb.UserID)
 	if err != nil {
 		return errors.Wrap(errors.Internal, "failed to update load balancer", err)
 	}
@@ -158,22 +249,26 @@ func (r *LBRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 func (r *LBRepository) AddTarget(ctx context.Context, target *domain.LBTarget) error {
 	query := `
-		INSERT INTO lb_targets (id, lb_id, instance_id, port, weight, health)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO lb_targets (id, lb_id, instance_id, port, weight, health, target_group)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
+	group := target.TargetGroup
+	if group == "" {
+		group = domain.DefaultLBTargetGroup
+	}
 	_, err := r.db.Exec(ctx, query,
-		target.ID, target.LBID, target.InstanceID, target.Port, target.Weight, target.Health,
+		target.ID, target.LBID, target.InstanceID, target.Port, target.Weight, target.Health, group,
 	)
 	if err != nil {
-		// Handle unique constraint on (lb_id, instance_id)
+		// Handle unique constraint on (lb_id, instance_id, target_group, port)
 		return errors.Wrap(errors.Internal, "failed to add load balancer target", err)
 	}
 	return nil
 }
 
-func (r *LBRepository) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID) error {
-	query := `DELETE FROM lb_targets WHERE lb_id = $1 AND instance_id = $2`
-	cmd, err := r.db.Exec(ctx, query, lbID, instanceID)
+func (r *LBRepository) RemoveTarget(ctx context.Context, lbID, instanceID uuid.UUID, group string) error {
+	query := `DELETE FROM lb_targets WHERE lb_id = $1 AND instance_id = $2 AND target_group = $3`
+	cmd, err := r.db.Exec(ctx, query, lbID, instanceID, group)
 	if err != nil {
 		return errors.Wrap(errors.Internal, "failed to remove load balancer target", err)
 	}
@@ -183,9 +278,25 @@ func (r *LBRepository) RemoveTarget(ctx context.Context, lbID, instanceID uuid.U
 	return nil
 }
 
+func (r *LBRepository) DrainTarget(ctx context.Context, lbID, instanceID uuid.UUID, group string, until time.Time) error {
+	query := `
+		UPDATE lb_targets
+		SET health = $1, drain_until = $2
+		WHERE lb_id = $3 AND instance_id = $4 AND target_group = $5
+	`
+	cmd, err := r.db.Exec(ctx, query, domain.LBTargetHealthDraining, until, lbID, instanceID, group)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to drain load balancer target", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "target not found")
+	}
+	return nil
+}
+
 func (r *LBRepository) ListTargets(ctx context.Context, lbID uuid.UUID) ([]*domain.LBTarget, error) {
 	query := `
-		SELECT id, lb_id, instance_id, port, weight, health
+		SELECT id, lb_id, instance_id, port, weight, health, target_group, drain_until
 		FROM lb_targets
 		WHERE lb_id = $1
 	`
@@ -196,13 +307,13 @@ func (r *LBRepository) ListTargets(ctx context.Context, lbID uuid.UUID) ([]*doma
 	return r.scanTargets(rows)
 }
 
-func (r *LBRepository) UpdateTargetHealth(ctx context.Context, lbID, instanceID uuid.UUID, health string) error {
+func (r *LBRepository) UpdateTargetHealth(ctx context.Context, lbID, instanceID uuid.UUID, group string, port int, health string) error {
 	query := `
 		UPDATE lb_targets
 		SET health = $1
-		WHERE lb_id = $2 AND instance_id = $3
+		WHERE lb_id = $2 AND instance_id = $3 AND target_group = $4 AND port = $5 AND health <> $6
 	`
-	_, err := r.db.Exec(ctx, query, health, lbID, instanceID)
+	_, err := r.db.Exec(ctx, query, health, lbID, instanceID, group, port, domain.LBTargetHealthDraining)
 	if err != nil {
 		return errors.Wrap(errors.Internal, "failed to update target health", err)
 	}
@@ -211,7 +322,7 @@ func (r *LBRepository) UpdateTargetHealth(ctx context.Context, lbID, instanceID
 
 func (r *LBRepository) GetTargetsForInstance(ctx context.Context, instanceID uuid.UUID) ([]*domain.LBTarget, error) {
 	query := `
-		SELECT id, lb_id, instance_id, port, weight, health
+		SELECT id, lb_id, instance_id, port, weight, health, target_group, drain_until
 		FROM lb_targets
 		WHERE instance_id = $1
 	`
@@ -224,7 +335,7 @@ func (r *LBRepository) GetTargetsForInstance(ctx context.Context, instanceID uui
 
 func (r *LBRepository) scanTarget(row pgx.Row) (*domain.LBTarget, error) {
 	var t domain.LBTarget
-	err := row.Scan(&t.ID, &t.LBID, &t.InstanceID, &t.Port, &t.Weight, &t.Health)
+	err := row.Scan(&t.ID, &t.LBID, &t.InstanceID, &t.Port, &t.Weight, &t.Health, &t.TargetGroup, &t.DrainUntil)
 	if err != nil {
 		return nil, errors.Wrap(errors.Internal, "failed to scan load balancer target", err)
 	}
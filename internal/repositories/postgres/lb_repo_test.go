@@ -16,7 +16,7 @@ import (
 )
 
 const (
-	lbQueryPattern = "SELECT id, user_id, COALESCE.+idempotency_key.+name, vpc_id, port, algorithm, COALESCE.+ip.+status, version, created_at FROM load_balancers"
+	lbQueryPattern = "SELECT id, user_id, COALESCE.+idempotency_key.+name, vpc_id, port, algorithm, COALESCE.+ip.+status, version, created_at, tls, listeners, health_check, sticky_sessions, deregistration_delay FROM load_balancers"
 	errDbMessage   = "db error"
 	errNotFound    = "not found"
 )
@@ -42,7 +42,7 @@ func TestLBRepositoryCreate(t *testing.T) {
 		}
 
 		mock.ExpectExec("INSERT INTO load_balancers").
-			WithArgs(lb.ID, lb.UserID, lb.IdempotencyKey, lb.Name, lb.VpcID, lb.Port, lb.Algorithm, lb.IP, lb.Status, lb.Version, lb.CreatedAt).
+			WithArgs(lb.ID, lb.UserID, lb.IdempotencyKey, lb.Name, lb.VpcID, lb.Port, lb.Algorithm, lb.IP, lb.Status, lb.Version, lb.CreatedAt, []byte(nil), []byte(nil), []byte(nil), []byte(nil), 0).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		err = repo.Create(context.Background(), lb)
@@ -76,16 +76,28 @@ func TestLBRepositoryGetByID(t *testing.T) {
 		userID := uuid.New()
 		ctx := appcontext.WithUserID(context.Background(), userID)
 		now := time.Now()
+		certID := uuid.New()
 
 		mock.ExpectQuery(lbQueryPattern).
 			WithArgs(id, userID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "idempotency_key", "name", "vpc_id", "port", "algorithm", "ip", "status", "version", "created_at"}).
-				AddRow(id, userID, "key-1", "lb-1", uuid.New(), 80, "round_robin", "10.0.0.1", string(domain.LBStatusActive), 1, now))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "idempotency_key", "name", "vpc_id", "port", "algorithm", "ip", "status", "version", "created_at", "tls", "listeners", "health_check", "sticky_sessions", "deregistration_delay"}).
+				AddRow(id, userID, "key-1", "lb-1", uuid.New(), 80, "round_robin", "10.0.0.1", string(domain.LBStatusActive), 1, now, []byte(`{"port":443,"certificate_ids":["`+certID.String()+`"],"redirect_http":true}`),
+					[]byte(`[{"port":8080,"default_target_group":"api","rules":[{"priority":1,"path_prefix":"/admin","target_group":"admin"}]}]`),
+					[]byte(`{"path":"/healthz","interval_seconds":10,"timeout_seconds":5,"healthy_threshold":2,"unhealthy_threshold":3}`),
+					[]byte(`{"cookie_name":"SESSION","duration_seconds":3600}`), 30))
 
 		lb, err := repo.GetByID(ctx, id)
 		assert.NoError(t, err)
 		assert.NotNil(t, lb)
 		assert.Equal(t, id, lb.ID)
+		assert.Equal(t, &domain.LBTLSConfig{Port: 443, CertificateIDs: []uuid.UUID{certID}, RedirectHTTP: true}, lb.TLS)
+		assert.Equal(t, []domain.LBListener{{
+			Port: 8080, DefaultTargetGroup: "api",
+			Rules: []domain.LBListenerRule{{Priority: 1, PathPrefix: "/admin", TargetGroup: "admin"}},
+		}}, lb.Listeners)
+		assert.Equal(t, &domain.HealthCheckConfig{Path: "/healthz", IntervalSeconds: 10, TimeoutSeconds: 5, HealthyThreshold: 2, UnhealthyThreshold: 3}, lb.HealthCheck)
+		assert.Equal(t, &domain.LBStickySessions{CookieName: "SESSION", DurationSeconds: 3600}, lb.StickySessions)
+		assert.Equal(t, 30, lb.DeregistrationDelaySeconds)
 	})
 
 	t.Run(errNotFound, func(t *testing.T) {
@@ -122,8 +134,8 @@ func TestLBRepositoryList(t *testing.T) {
 
 		mock.ExpectQuery(lbQueryPattern).
 			WithArgs(userID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "idempotency_key", "name", "vpc_id", "port", "algorithm", "ip", "status", "version", "created_at"}).
-				AddRow(uuid.New(), userID, "key-1", "lb-1", uuid.New(), 80, "round_robin", "10.0.0.1", string(domain.LBStatusActive), 1, now))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "idempotency_key", "name", "vpc_id", "port", "algorithm", "ip", "status", "version", "created_at", "tls", "listeners", "health_check", "sticky_sessions", "deregistration_delay"}).
+				AddRow(uuid.New(), userID, "key-1", "lb-1", uuid.New(), 80, "round_robin", "10.0.0.1", string(domain.LBStatusActive), 1, now, nil, nil, nil, nil, 0))
 
 		lbs, err := repo.List(ctx)
 		assert.NoError(t, err)
@@ -167,7 +179,7 @@ func TestLBRepositoryUpdate(t *testing.T) {
 		}
 
 		mock.ExpectExec("UPDATE load_balancers").
-			WithArgs(lb.Name, lb.Port, lb.Algorithm, lb.IP, lb.Status, lb.ID, lb.Version, lb.UserID).
+			WithArgs(lb.Name, lb.Port, lb.Algorithm, lb.IP, lb.Status, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), lb.DeregistrationDelaySeconds, lb.ID, lb.Version, lb.UserID).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
 		err = repo.Update(context.Background(), lb)
@@ -188,7 +200,7 @@ func TestLBRepositoryUpdate(t *testing.T) {
 		}
 
 		mock.ExpectExec("UPDATE load_balancers").
-			WithArgs(lb.Name, lb.Port, lb.Algorithm, lb.IP, lb.Status, lb.ID, lb.Version, lb.UserID).
+			WithArgs(lb.Name, lb.Port, lb.Algorithm, lb.IP, lb.Status, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), lb.DeregistrationDelaySeconds, lb.ID, lb.Version, lb.UserID).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
 
 		err = repo.Update(context.Background(), lb)
@@ -256,7 +268,7 @@ func TestLBRepositoryAddTarget(t *testing.T) {
 		}
 
 		mock.ExpectExec("INSERT INTO lb_targets").
-			WithArgs(target.ID, target.LBID, target.InstanceID, target.Port, target.Weight, target.Health).
+			WithArgs(target.ID, target.LBID, target.InstanceID, target.Port, target.Weight, target.Health, domain.DefaultLBTargetGroup).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		err = repo.AddTarget(context.Background(), target)
@@ -274,11 +286,11 @@ func TestLBRepositoryRemoveTarget(t *testing.T) {
 		lbID := uuid.New()
 		instanceID := uuid.New()
 
-		mock.ExpectExec("DELETE FROM lb_targets").
-			WithArgs(lbID, instanceID).
+		mock.ExpectExec("DELETE FROM lb_targets WHERE lb_id = \\$1 AND instance_id = \\$2 AND target_group = \\$3").
+			WithArgs(lbID, instanceID, "api").
 			WillReturnResult(pgxmock.NewResult("DELETE", 1))
 
-		err = repo.RemoveTarget(context.Background(), lbID, instanceID)
+		err = repo.RemoveTarget(context.Background(), lbID, instanceID, "api")
 		assert.NoError(t, err)
 	})
 
@@ -292,10 +304,10 @@ func TestLBRepositoryRemoveTarget(t *testing.T) {
 		instanceID := uuid.New()
 
 		mock.ExpectExec("DELETE FROM lb_targets").
-			WithArgs(lbID, instanceID).
+			WithArgs(lbID, instanceID, domain.DefaultLBTargetGroup).
 			WillReturnResult(pgxmock.NewResult("DELETE", 0))
 
-		err = repo.RemoveTarget(context.Background(), lbID, instanceID)
+		err = repo.RemoveTarget(context.Background(), lbID, instanceID, domain.DefaultLBTargetGroup)
 		assert.Error(t, err)
 	})
 }
@@ -309,14 +321,15 @@ func TestLBRepositoryListTargets(t *testing.T) {
 		repo := NewLBRepository(mock)
 		lbID := uuid.New()
 
-		mock.ExpectQuery("SELECT id, lb_id, instance_id, port, weight, health FROM lb_targets").
+		mock.ExpectQuery("SELECT id, lb_id, instance_id, port, weight, health, target_group, drain_until FROM lb_targets").
 			WithArgs(lbID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "lb_id", "instance_id", "port", "weight", "health"}).
-				AddRow(uuid.New(), lbID, uuid.New(), 80, 1, "healthy"))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "lb_id", "instance_id", "port", "weight", "health", "target_group", "drain_until"}).
+				AddRow(uuid.New(), lbID, uuid.New(), 80, 1, "healthy", "api", nil))
 
 		targets, err := repo.ListTargets(context.Background(), lbID)
 		assert.NoError(t, err)
 		assert.Len(t, targets, 1)
+		assert.Equal(t, "api", targets[0].TargetGroup)
 	})
 }
 
@@ -332,10 +345,45 @@ func TestLBRepositoryUpdateTargetHealth(t *testing.T) {
 		health := "unhealthy"
 
 		mock.ExpectExec("UPDATE lb_targets").
-			WithArgs(health, lbID, instanceID).
+			WithArgs(health, lbID, instanceID, "api", 8080, domain.LBTargetHealthDraining).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateTargetHealth(context.Background(), lbID, instanceID, "api", 8080, health)
+		assert.NoError(t, err)
+	})
+}
+
+func TestLBRepositoryDrainTarget(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewLBRepository(mock)
+		lbID := uuid.New()
+		instanceID := uuid.New()
+		until := time.Now().Add(30 * time.Second)
+
+		mock.ExpectExec("UPDATE lb_targets").
+			WithArgs(domain.LBTargetHealthDraining, until, lbID, instanceID, "api").
 			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
-		err = repo.UpdateTargetHealth(context.Background(), lbID, instanceID, health)
+		err = repo.DrainTarget(context.Background(), lbID, instanceID, "api", until)
+		assert.NoError(t, err)
+	})
+
+	t.Run(errNotFound, func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
 		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewLBRepository(mock)
+
+		mock.ExpectExec("UPDATE lb_targets").
+			WithArgs(domain.LBTargetHealthDraining, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		err = repo.DrainTarget(context.Background(), uuid.New(), uuid.New(), domain.DefaultLBTargetGroup, time.Now())
+		assert.Error(t, err)
 	})
 }
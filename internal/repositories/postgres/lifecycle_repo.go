@@ -3,6 +3,7 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -11,6 +12,9 @@ import (
 	"github.com/poyrazk/thecloud/internal/errors"
 )
 
+const lifecycleRuleColumns = `id, user_id, bucket_name, prefix, tags, expiration_days, noncurrent_expiration_days, noncurrent_versions_to_keep,
+		abort_incomplete_multipart_days, transition_days, transition_storage_class, enabled, created_at, updated_at`
+
 // LifecycleRepository stores lifecycle rules in Postgres.
 type LifecycleRepository struct {
 	db DB
@@ -22,11 +26,22 @@ func NewLifecycleRepository(db DB) *LifecycleRepository {
 }
 
 func (r *LifecycleRepository) Create(ctx context.Context, rule *domain.LifecycleRule) error {
+	tags := rule.Tags
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to encode lifecycle rule tags", err)
+	}
+
 	query := `
-		INSERT INTO lifecycle_rules (id, user_id, bucket_name, prefix, expiration_days, enabled, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO lifecycle_rules (` + lifecycleRuleColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
-	_, err := r.db.Exec(ctx, query, rule.ID, rule.UserID, rule.BucketName, rule.Prefix, rule.ExpirationDays, rule.Enabled, rule.CreatedAt, rule.UpdatedAt)
+	_, err = r.db.Exec(ctx, query, rule.ID, rule.UserID, rule.BucketName, rule.Prefix, tagsJSON, rule.ExpirationDays,
+		rule.NoncurrentExpirationDays, rule.NoncurrentVersionsToKeep, rule.AbortIncompleteMultipartDays,
+		rule.TransitionDays, string(rule.TransitionStorageClass), rule.Enabled, rule.CreatedAt, rule.UpdatedAt)
 	if err != nil {
 		return errors.Wrap(errors.Internal, "failed to create lifecycle rule", err)
 	}
@@ -35,28 +50,21 @@ func (r *LifecycleRepository) Create(ctx context.Context, rule *domain.Lifecycle
 
 func (r *LifecycleRepository) Get(ctx context.Context, id uuid.UUID) (*domain.LifecycleRule, error) {
 	userId := appcontext.UserIDFromContext(ctx)
-	query := `
-		SELECT id, user_id, bucket_name, prefix, expiration_days, enabled, created_at, updated_at
-		FROM lifecycle_rules
-		WHERE id = $1 AND user_id = $2
-	`
-	var rule domain.LifecycleRule
-	err := r.db.QueryRow(ctx, query, id, userId).Scan(
-		&rule.ID, &rule.UserID, &rule.BucketName, &rule.Prefix, &rule.ExpirationDays, &rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt,
-	)
+	query := `SELECT ` + lifecycleRuleColumns + ` FROM lifecycle_rules WHERE id = $1 AND user_id = $2`
+	rule, err := scanLifecycleRule(r.db.QueryRow(ctx, query, id, userId))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, errors.New(errors.NotFound, "lifecycle rule not found")
 		}
 		return nil, errors.Wrap(errors.Internal, "failed to get lifecycle rule", err)
 	}
-	return &rule, nil
+	return rule, nil
 }
 
 func (r *LifecycleRepository) List(ctx context.Context, bucketName string) ([]*domain.LifecycleRule, error) {
 	userId := appcontext.UserIDFromContext(ctx)
 	query := `
-		SELECT id, user_id, bucket_name, prefix, expiration_days, enabled, created_at, updated_at
+		SELECT ` + lifecycleRuleColumns + `
 		FROM lifecycle_rules
 		WHERE bucket_name = $1 AND user_id = $2
 		ORDER BY created_at DESC
@@ -67,15 +75,7 @@ func (r *LifecycleRepository) List(ctx context.Context, bucketName string) ([]*d
 	}
 	defer rows.Close()
 
-	var rules []*domain.LifecycleRule
-	for rows.Next() {
-		var rule domain.LifecycleRule
-		if err := rows.Scan(&rule.ID, &rule.UserID, &rule.BucketName, &rule.Prefix, &rule.ExpirationDays, &rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
-			return nil, errors.Wrap(errors.Internal, "failed to scan lifecycle rule", err)
-		}
-		rules = append(rules, &rule)
-	}
-	return rules, nil
+	return scanLifecycleRules(rows)
 }
 
 func (r *LifecycleRepository) Delete(ctx context.Context, id uuid.UUID) error {
@@ -94,24 +94,44 @@ func (r *LifecycleRepository) Delete(ctx context.Context, id uuid.UUID) error {
 // GetEnabledRules retrieves all enabled lifecycle rules across the system.
 // This is intended for background workers and does not filter by user.
 func (r *LifecycleRepository) GetEnabledRules(ctx context.Context) ([]*domain.LifecycleRule, error) {
-	query := `
-		SELECT id, user_id, bucket_name, prefix, expiration_days, enabled, created_at, updated_at
-		FROM lifecycle_rules
-		WHERE enabled = TRUE
-	`
+	query := `SELECT ` + lifecycleRuleColumns + ` FROM lifecycle_rules WHERE enabled = TRUE`
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, errors.Wrap(errors.Internal, "failed to list enabled lifecycle rules", err)
 	}
 	defer rows.Close()
 
+	return scanLifecycleRules(rows)
+}
+
+func scanLifecycleRule(row pgx.Row) (*domain.LifecycleRule, error) {
+	var rule domain.LifecycleRule
+	var tags []byte
+	var transitionClass string
+	if err := row.Scan(
+		&rule.ID, &rule.UserID, &rule.BucketName, &rule.Prefix, &tags, &rule.ExpirationDays,
+		&rule.NoncurrentExpirationDays, &rule.NoncurrentVersionsToKeep, &rule.AbortIncompleteMultipartDays,
+		&rule.TransitionDays, &transitionClass, &rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	rule.TransitionStorageClass = domain.StorageClass(transitionClass)
+	if len(tags) > 0 {
+		if err := json.Unmarshal(tags, &rule.Tags); err != nil {
+			return nil, err
+		}
+	}
+	return &rule, nil
+}
+
+func scanLifecycleRules(rows pgx.Rows) ([]*domain.LifecycleRule, error) {
 	var rules []*domain.LifecycleRule
 	for rows.Next() {
-		var rule domain.LifecycleRule
-		if err := rows.Scan(&rule.ID, &rule.UserID, &rule.BucketName, &rule.Prefix, &rule.ExpirationDays, &rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
+		rule, err := scanLifecycleRule(rows)
+		if err != nil {
 			return nil, errors.Wrap(errors.Internal, "failed to scan lifecycle rule", err)
 		}
-		rules = append(rules, &rule)
+		rules = append(rules, rule)
 	}
 	return rules, nil
 }
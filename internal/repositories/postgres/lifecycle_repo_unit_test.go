@@ -17,6 +17,9 @@ const (
 	testLifecyclePrefix  = "logs/"
 )
 
+var lifecycleRuleTestColumns = []string{"id", "user_id", "bucket_name", "prefix", "tags", "expiration_days", "noncurrent_expiration_days",
+	"noncurrent_versions_to_keep", "abort_incomplete_multipart_days", "transition_days", "transition_storage_class", "enabled", "created_at", "updated_at"}
+
 func TestLifecycleRepository(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -30,18 +33,22 @@ func TestLifecycleRepository(t *testing.T) {
 		defer mock.Close()
 		repo := NewLifecycleRepository(mock)
 		rule := &domain.LifecycleRule{
-			ID:             uuid.New(),
-			UserID:         userID,
-			BucketName:     bucketName,
-			Prefix:         testLifecyclePrefix,
-			ExpirationDays: 30,
-			Enabled:        true,
-			CreatedAt:      time.Now(),
-			UpdatedAt:      time.Now(),
+			ID:                     uuid.New(),
+			UserID:                 userID,
+			BucketName:             bucketName,
+			Prefix:                 testLifecyclePrefix,
+			Tags:                   map[string]string{"tier": "scratch"},
+			ExpirationDays:         30,
+			TransitionDays:         7,
+			TransitionStorageClass: domain.StorageClassErasureCoded,
+			Enabled:                true,
+			CreatedAt:              time.Now(),
+			UpdatedAt:              time.Now(),
 		}
 
 		mock.ExpectExec("INSERT INTO lifecycle_rules").
-			WithArgs(rule.ID, rule.UserID, rule.BucketName, rule.Prefix, rule.ExpirationDays, rule.Enabled, rule.CreatedAt, rule.UpdatedAt).
+			WithArgs(rule.ID, rule.UserID, rule.BucketName, rule.Prefix, []byte(`{"tier":"scratch"}`), rule.ExpirationDays,
+				0, 0, 0, 7, "ERASURE_CODED", rule.Enabled, rule.CreatedAt, rule.UpdatedAt).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		err := repo.Create(ctx, rule)
@@ -57,12 +64,16 @@ func TestLifecycleRepository(t *testing.T) {
 
 		mock.ExpectQuery(selectLifecycleRules).
 			WithArgs(id, userID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "bucket_name", "prefix", "expiration_days", "enabled", "created_at", "updated_at"}).
-				AddRow(id, userID, bucketName, testLifecyclePrefix, 30, true, time.Now(), time.Now()))
+			WillReturnRows(pgxmock.NewRows(lifecycleRuleTestColumns).
+				AddRow(id, userID, bucketName, testLifecyclePrefix, []byte(`{"tier":"scratch"}`), 30, 14, 2, 7, 0, "", true, time.Now(), time.Now()))
 
 		rule, err := repo.Get(ctx, id)
 		assert.NoError(t, err)
 		assert.NotNil(t, rule)
+		assert.Equal(t, "scratch", rule.Tags["tier"])
+		assert.Equal(t, 14, rule.NoncurrentExpirationDays)
+		assert.Equal(t, 2, rule.NoncurrentVersionsToKeep)
+		assert.Equal(t, 7, rule.AbortIncompleteMultipartDays)
 	})
 
 	t.Run("Delete", func(t *testing.T) {
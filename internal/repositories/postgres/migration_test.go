@@ -108,3 +108,80 @@ func TestMigrationRollback(t *testing.T) {
 		}
 	})
 }
+
+func TestMigrationEncryptionBackfill(t *testing.T) {
+	db := SetupDB(t)
+	defer db.Close()
+	ctx := context.Background()
+	conn, err := db.Acquire(ctx)
+	require.NoError(t, err)
+	defer conn.Release()
+
+	schema := "migration_test_" + strings.ReplaceAll(uuid.NewString(), "-", "_")
+	_, err = conn.Exec(ctx, "CREATE SCHEMA "+schema)
+	require.NoError(t, err)
+	defer func() {
+		_, _ = conn.Exec(ctx, "DROP SCHEMA IF EXISTS "+schema+" CASCADE")
+	}()
+	_, err = conn.Exec(ctx, "SET search_path TO "+schema+", public")
+	require.NoError(t, err)
+	_, err = conn.Exec(ctx, "CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"")
+	require.NoError(t, err)
+
+	files, err := os.ReadDir("migrations")
+	require.NoError(t, err)
+	var before []string
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".up.sql") && f.Name() < "100_" {
+			before = append(before, f.Name())
+		}
+	}
+	sort.Strings(before)
+	runUp := func(name string) {
+		content, err := os.ReadFile(filepath.Join("migrations", name))
+		require.NoError(t, err)
+		sql := strings.Split(string(content), "-- +goose Down")[0]
+		_, err = conn.Exec(ctx, strings.TrimPrefix(sql, "-- +goose Up"))
+		require.NoError(t, err, "migration %s", name)
+	}
+	for _, m := range before {
+		runUp(m)
+	}
+
+	userID := uuid.New()
+	_, err = conn.Exec(ctx, `INSERT INTO users (id, email, password_hash) VALUES ($1, $2, 'x')`, userID, userID.String()+"@example.com")
+	require.NoError(t, err)
+	_, err = conn.Exec(ctx, `INSERT INTO buckets (name, user_id, encryption_enabled) VALUES ('sealed', $1, TRUE)`, userID)
+	require.NoError(t, err)
+	_, err = conn.Exec(ctx, `INSERT INTO encryption_keys (id, bucket_name, encrypted_key, created_at) VALUES ('k1', 'sealed', '\x00', NOW() - INTERVAL '1 hour')`)
+	require.NoError(t, err)
+
+	objects := map[string]struct {
+		etag string
+		age  string
+	}{
+		"single":    {"5eb63bbbe01eeed093cb22bb8f5acdc3", "0"},
+		"multipart": {"5eb63bbbe01eeed093cb22bb8f5acdc3-2", "0"},
+		"legacy":    {"", "0"},
+		"early":     {"5eb63bbbe01eeed093cb22bb8f5acdc3", "2 hours"},
+	}
+	for key, o := range objects {
+		_, err = conn.Exec(ctx, `INSERT INTO objects (arn, bucket, key, size_bytes, etag, created_at)
+			VALUES ($1, 'sealed', $2, 1, $3, NOW() - $4::interval)`, "arn:sealed/"+key, key, o.etag, o.age)
+		require.NoError(t, err)
+	}
+
+	runUp("100_version_encryption_keys.up.sql")
+
+	sealed := map[string]string{}
+	rows, err := conn.Query(ctx, `SELECT key, encryption FROM objects WHERE bucket = 'sealed'`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var key, encryption string
+		require.NoError(t, rows.Scan(&key, &encryption))
+		sealed[key] = encryption
+	}
+	require.NoError(t, rows.Err())
+
+	require.Equal(t, map[string]string{"single": "SSE", "multipart": "", "legacy": "", "early": ""}, sealed)
+}
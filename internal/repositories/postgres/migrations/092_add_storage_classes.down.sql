@@ -0,0 +1,11 @@
+-- +goose Down
+ALTER TABLE objects
+    DROP COLUMN IF EXISTS stored_bytes,
+    DROP COLUMN IF EXISTS parity_shards,
+    DROP COLUMN IF EXISTS data_shards,
+    DROP COLUMN IF EXISTS storage_class;
+
+ALTER TABLE buckets
+    DROP COLUMN IF EXISTS parity_shards,
+    DROP COLUMN IF EXISTS data_shards,
+    DROP COLUMN IF EXISTS storage_class;
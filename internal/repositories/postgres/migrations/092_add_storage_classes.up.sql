@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE buckets
+    ADD COLUMN IF NOT EXISTS storage_class VARCHAR(32) NOT NULL DEFAULT 'STANDARD',
+    ADD COLUMN IF NOT EXISTS data_shards INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS parity_shards INT NOT NULL DEFAULT 0;
+
+ALTER TABLE objects
+    ADD COLUMN IF NOT EXISTS storage_class VARCHAR(32) NOT NULL DEFAULT 'STANDARD',
+    ADD COLUMN IF NOT EXISTS data_shards INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS parity_shards INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS stored_bytes BIGINT NOT NULL DEFAULT 0;
+
+-- Replicated objects written before storage classes existed report their logical size
+UPDATE objects SET stored_bytes = size_bytes WHERE stored_bytes = 0;
@@ -0,0 +1,2 @@
+-- +goose Down
+DROP INDEX IF EXISTS idx_objects_listing;
@@ -0,0 +1,5 @@
+-- +goose Up
+-- Supports prefix listings that seek by key in bytewise order.
+CREATE INDEX IF NOT EXISTS idx_objects_listing
+    ON objects (bucket, user_id, key COLLATE "C")
+    WHERE is_latest = TRUE AND deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- +goose Down
+ALTER TABLE objects
+    DROP COLUMN IF EXISTS checksum_sha256,
+    DROP COLUMN IF EXISTS etag;
@@ -0,0 +1,4 @@
+-- +goose Up
+ALTER TABLE objects
+    ADD COLUMN IF NOT EXISTS etag VARCHAR(80) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS checksum_sha256 VARCHAR(64) NOT NULL DEFAULT '';
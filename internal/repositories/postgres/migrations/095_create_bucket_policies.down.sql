@@ -0,0 +1,3 @@
+-- +goose Down
+ALTER TABLE objects DROP COLUMN IF EXISTS acl;
+DROP TABLE IF EXISTS bucket_policies;
@@ -0,0 +1,8 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS bucket_policies (
+    bucket VARCHAR(255) PRIMARY KEY REFERENCES buckets(name) ON DELETE CASCADE,
+    statements JSONB NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS acl VARCHAR(32) NOT NULL DEFAULT 'private';
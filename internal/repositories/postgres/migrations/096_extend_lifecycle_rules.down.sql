@@ -0,0 +1,14 @@
+-- +goose Down
+DROP INDEX IF EXISTS idx_multipart_uploads_bucket_created;
+
+ALTER TABLE objects DROP COLUMN IF EXISTS tags;
+
+ALTER TABLE lifecycle_rules ALTER COLUMN expiration_days DROP DEFAULT;
+
+ALTER TABLE lifecycle_rules
+    DROP COLUMN IF EXISTS transition_storage_class,
+    DROP COLUMN IF EXISTS transition_days,
+    DROP COLUMN IF EXISTS abort_incomplete_multipart_days,
+    DROP COLUMN IF EXISTS noncurrent_versions_to_keep,
+    DROP COLUMN IF EXISTS noncurrent_expiration_days,
+    DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE lifecycle_rules
+    ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS noncurrent_expiration_days INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS noncurrent_versions_to_keep INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS abort_incomplete_multipart_days INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS transition_days INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS transition_storage_class VARCHAR(32) NOT NULL DEFAULT '';
+
+-- Rules may now consist of noncurrent-version or multipart actions only.
+ALTER TABLE lifecycle_rules ALTER COLUMN expiration_days SET DEFAULT 0;
+
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_multipart_uploads_bucket_created ON multipart_uploads(bucket, created_at);
@@ -0,0 +1,2 @@
+-- +goose Down
+DROP TABLE IF EXISTS bucket_notifications;
@@ -0,0 +1,6 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS bucket_notifications (
+    bucket VARCHAR(255) PRIMARY KEY REFERENCES buckets(name) ON DELETE CASCADE,
+    rules JSONB NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,10 @@
+-- +goose Down
+ALTER TABLE objects
+    DROP COLUMN IF EXISTS legal_hold,
+    DROP COLUMN IF EXISTS retain_until,
+    DROP COLUMN IF EXISTS retention_mode;
+
+ALTER TABLE buckets
+    DROP COLUMN IF EXISTS default_retention_days,
+    DROP COLUMN IF EXISTS default_retention_mode,
+    DROP COLUMN IF EXISTS object_lock_enabled;
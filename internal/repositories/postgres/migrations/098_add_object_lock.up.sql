@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE buckets
+    ADD COLUMN IF NOT EXISTS object_lock_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS default_retention_mode VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS default_retention_days INT NOT NULL DEFAULT 0;
+
+ALTER TABLE objects
+    ADD COLUMN IF NOT EXISTS retention_mode VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS retain_until TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS legal_hold BOOLEAN NOT NULL DEFAULT FALSE;
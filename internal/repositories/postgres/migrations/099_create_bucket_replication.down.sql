@@ -0,0 +1,3 @@
+-- +goose Down
+DROP TABLE IF EXISTS object_replication;
+DROP TABLE IF EXISTS bucket_replication;
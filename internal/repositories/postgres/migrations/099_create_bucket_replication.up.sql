@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS bucket_replication (
+    bucket VARCHAR(255) PRIMARY KEY REFERENCES buckets(name) ON DELETE CASCADE,
+    rules JSONB NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS object_replication (
+    bucket VARCHAR(255) NOT NULL REFERENCES buckets(name) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    version_id VARCHAR(64) NOT NULL DEFAULT '',
+    rule_id VARCHAR(64) NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    replicated_at TIMESTAMPTZ,
+    PRIMARY KEY (bucket, key, version_id, rule_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_object_replication_pending
+    ON object_replication (next_attempt_at, created_at) WHERE status = 'PENDING';
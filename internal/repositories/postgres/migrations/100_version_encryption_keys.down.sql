@@ -0,0 +1,13 @@
+-- +goose Down
+ALTER TABLE objects
+    DROP COLUMN IF EXISTS customer_key_md5,
+    DROP COLUMN IF EXISTS key_version,
+    DROP COLUMN IF EXISTS encryption;
+
+DROP INDEX IF EXISTS idx_encryption_keys_bucket_version;
+DELETE FROM encryption_keys k
+WHERE k.version < (SELECT MAX(version) FROM encryption_keys WHERE bucket_name = k.bucket_name);
+ALTER TABLE encryption_keys
+    DROP COLUMN IF EXISTS master_key_id,
+    DROP COLUMN IF EXISTS version;
+ALTER TABLE encryption_keys ADD CONSTRAINT encryption_keys_bucket_name_key UNIQUE (bucket_name);
@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE encryption_keys DROP CONSTRAINT IF EXISTS encryption_keys_bucket_name_key;
+ALTER TABLE encryption_keys
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS master_key_id VARCHAR(64) NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_encryption_keys_bucket_version ON encryption_keys (bucket_name, version);
+
+ALTER TABLE objects
+    ADD COLUMN IF NOT EXISTS encryption VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS key_version INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS customer_key_md5 VARCHAR(32) NOT NULL DEFAULT '';
+
+-- Objects only record whether they were sealed from here on. Before this, uploads to an
+-- encrypted bucket were sealed with its only data key, so a single-part object is SSE
+-- when that key existed at write time; multipart uploads were stored as uploaded.
+-- Objects written before ETags were recorded cannot be told apart and stay plaintext.
+UPDATE objects o SET encryption = 'SSE', key_version = 1
+FROM buckets b
+JOIN encryption_keys k ON k.bucket_name = b.name
+WHERE b.name = o.bucket AND b.encryption_enabled AND o.etag <> '' AND o.etag NOT LIKE '%-%'
+    AND o.created_at >= k.created_at;
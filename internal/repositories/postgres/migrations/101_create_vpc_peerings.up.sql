@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS vpc_peerings (
+    id UUID PRIMARY KEY,
+    requester_vpc_id UUID NOT NULL REFERENCES vpcs(id) ON DELETE CASCADE,
+    accepter_vpc_id UUID NOT NULL REFERENCES vpcs(id) ON DELETE CASCADE,
+    requester_tenant_id UUID NOT NULL,
+    accepter_tenant_id UUID NOT NULL,
+    requester_cidr CIDR NOT NULL,
+    accepter_cidr CIDR NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending-acceptance',
+    arn VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (requester_vpc_id <> accepter_vpc_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_vpc_peerings_requester ON vpc_peerings(requester_vpc_id);
+CREATE INDEX IF NOT EXISTS idx_vpc_peerings_accepter ON vpc_peerings(accepter_vpc_id);
+CREATE INDEX IF NOT EXISTS idx_vpc_peerings_requester_tenant ON vpc_peerings(requester_tenant_id);
+CREATE INDEX IF NOT EXISTS idx_vpc_peerings_accepter_tenant ON vpc_peerings(accepter_tenant_id);
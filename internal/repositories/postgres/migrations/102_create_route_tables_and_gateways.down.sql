@@ -0,0 +1,6 @@
+-- +goose Down
+DROP TABLE IF EXISTS nat_gateways;
+DROP TABLE IF EXISTS internet_gateways;
+DROP TABLE IF EXISTS route_table_associations;
+DROP TABLE IF EXISTS routes;
+DROP TABLE IF EXISTS route_tables;
@@ -0,0 +1,66 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS route_tables (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    tenant_id UUID NOT NULL,
+    vpc_id UUID NOT NULL REFERENCES vpcs(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    is_main BOOLEAN NOT NULL DEFAULT FALSE,
+    arn VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(vpc_id, name)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_route_tables_main ON route_tables(vpc_id) WHERE is_main;
+CREATE INDEX IF NOT EXISTS idx_route_tables_tenant ON route_tables(tenant_id);
+
+CREATE TABLE IF NOT EXISTS routes (
+    id UUID PRIMARY KEY,
+    route_table_id UUID NOT NULL REFERENCES route_tables(id) ON DELETE CASCADE,
+    destination_cidr CIDR NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(route_table_id, destination_cidr)
+);
+
+CREATE INDEX IF NOT EXISTS idx_routes_target ON routes(target_id) WHERE target_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS route_table_associations (
+    subnet_id UUID PRIMARY KEY REFERENCES subnets(id) ON DELETE CASCADE,
+    route_table_id UUID NOT NULL REFERENCES route_tables(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_route_table_associations_table ON route_table_associations(route_table_id);
+
+CREATE TABLE IF NOT EXISTS internet_gateways (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    tenant_id UUID NOT NULL,
+    vpc_id UUID UNIQUE REFERENCES vpcs(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'detached',
+    arn VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_internet_gateways_tenant ON internet_gateways(tenant_id);
+
+CREATE TABLE IF NOT EXISTS nat_gateways (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    tenant_id UUID NOT NULL,
+    vpc_id UUID NOT NULL REFERENCES vpcs(id) ON DELETE CASCADE,
+    subnet_id UUID NOT NULL REFERENCES subnets(id) ON DELETE CASCADE,
+    elastic_ip_id UUID NOT NULL REFERENCES elastic_ips(id),
+    name VARCHAR(255) NOT NULL,
+    public_ip VARCHAR(45) NOT NULL,
+    private_ip VARCHAR(45) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    arn VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_nat_gateways_tenant ON nat_gateways(tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_nat_gateways_eip ON nat_gateways(elastic_ip_id);
@@ -0,0 +1,3 @@
+-- +goose Down
+DROP INDEX IF EXISTS idx_security_rules_source_group_id;
+ALTER TABLE security_rules DROP COLUMN IF EXISTS source_group_id;
@@ -0,0 +1,6 @@
+-- +goose Up
+
+-- Rules may name a peer security group instead of a CIDR; such rules store an empty cidr.
+ALTER TABLE security_rules ADD COLUMN IF NOT EXISTS source_group_id UUID REFERENCES security_groups(id);
+
+CREATE INDEX IF NOT EXISTS idx_security_rules_source_group_id ON security_rules(source_group_id);
@@ -0,0 +1,4 @@
+-- +goose Down
+DROP TABLE IF EXISTS network_acl_associations;
+DROP TABLE IF EXISTS network_acl_rules;
+DROP TABLE IF EXISTS network_acls;
@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS network_acls (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    tenant_id UUID NOT NULL,
+    vpc_id UUID NOT NULL REFERENCES vpcs(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    arn VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(vpc_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_network_acls_tenant ON network_acls(tenant_id);
+
+CREATE TABLE IF NOT EXISTS network_acl_rules (
+    id UUID PRIMARY KEY,
+    acl_id UUID NOT NULL REFERENCES network_acls(id) ON DELETE CASCADE,
+    rule_number INT NOT NULL,
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('ingress', 'egress')),
+    protocol VARCHAR(10) NOT NULL,
+    port_min INT NOT NULL DEFAULT 0,
+    port_max INT NOT NULL DEFAULT 0,
+    cidr CIDR NOT NULL,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('allow', 'deny')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(acl_id, direction, rule_number)
+);
+
+CREATE TABLE IF NOT EXISTS network_acl_associations (
+    subnet_id UUID PRIMARY KEY REFERENCES subnets(id) ON DELETE CASCADE,
+    acl_id UUID NOT NULL REFERENCES network_acls(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_network_acl_associations_acl ON network_acl_associations(acl_id);
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS flow_logs (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    tenant_id UUID NOT NULL,
+    vpc_id UUID NOT NULL REFERENCES vpcs(id) ON DELETE CASCADE,
+    resource_type VARCHAR(20) NOT NULL CHECK (resource_type IN ('vpc', 'subnet', 'instance')),
+    resource_id UUID NOT NULL,
+    traffic_type VARCHAR(10) NOT NULL CHECK (traffic_type IN ('accept', 'reject', 'all')),
+    arn VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(resource_id, traffic_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_flow_logs_tenant ON flow_logs(tenant_id);
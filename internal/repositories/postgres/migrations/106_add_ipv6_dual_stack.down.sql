@@ -0,0 +1,5 @@
+-- +goose Down
+DROP INDEX IF EXISTS idx_subnets_ipv6_cidr;
+ALTER TABLE instances DROP COLUMN IF EXISTS private_ipv6;
+ALTER TABLE subnets DROP COLUMN IF EXISTS ipv6_cidr_block;
+ALTER TABLE vpcs DROP COLUMN IF EXISTS ipv6_cidr_block;
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE vpcs ADD COLUMN IF NOT EXISTS ipv6_cidr_block CIDR;
+ALTER TABLE subnets ADD COLUMN IF NOT EXISTS ipv6_cidr_block CIDR;
+ALTER TABLE instances ADD COLUMN IF NOT EXISTS private_ipv6 INET;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subnets_ipv6_cidr ON subnets(vpc_id, ipv6_cidr_block) WHERE ipv6_cidr_block IS NOT NULL;
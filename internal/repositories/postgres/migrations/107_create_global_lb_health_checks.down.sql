@@ -0,0 +1,2 @@
+-- +goose Down
+DROP TABLE IF EXISTS global_lb_health_checks;
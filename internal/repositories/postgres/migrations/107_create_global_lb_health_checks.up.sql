@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS global_lb_health_checks (
+    id UUID PRIMARY KEY,
+    endpoint_id UUID NOT NULL REFERENCES global_lb_endpoints(id) ON DELETE CASCADE,
+    global_lb_id UUID NOT NULL REFERENCES global_load_balancers(id) ON DELETE CASCADE,
+    healthy BOOLEAN NOT NULL,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    status_code INT,
+    error TEXT,
+    checked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_global_lb_health_checks_endpoint ON global_lb_health_checks(endpoint_id, checked_at DESC);
+CREATE INDEX IF NOT EXISTS idx_global_lb_health_checks_checked_at ON global_lb_health_checks(checked_at);
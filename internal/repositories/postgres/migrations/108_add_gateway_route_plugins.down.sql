@@ -0,0 +1,2 @@
+-- +goose Down
+ALTER TABLE gateway_routes DROP COLUMN IF EXISTS plugins;
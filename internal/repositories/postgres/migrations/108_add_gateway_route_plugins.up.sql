@@ -0,0 +1,2 @@
+-- +goose Up
+ALTER TABLE gateway_routes ADD COLUMN IF NOT EXISTS plugins JSONB NOT NULL DEFAULT '{}';
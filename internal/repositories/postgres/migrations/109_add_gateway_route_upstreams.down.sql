@@ -0,0 +1,4 @@
+-- +goose Down
+ALTER TABLE gateway_routes DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE gateway_routes DROP COLUMN IF EXISTS traffic_policy;
+ALTER TABLE gateway_routes DROP COLUMN IF EXISTS upstreams;
@@ -0,0 +1,4 @@
+-- +goose Up
+ALTER TABLE gateway_routes ADD COLUMN IF NOT EXISTS upstreams JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE gateway_routes ADD COLUMN IF NOT EXISTS traffic_policy JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE gateway_routes ADD COLUMN IF NOT EXISTS tenant_id UUID;
@@ -0,0 +1,9 @@
+-- +goose Down
+DELETE FROM role_permissions WHERE permission IN (
+    'certificate:create',
+    'certificate:read',
+    'certificate:delete'
+) AND role_id = '00000000-0000-0000-0000-000000000002';
+
+DROP TABLE IF EXISTS certificate_authorities;
+DROP TABLE IF EXISTS certificates;
@@ -0,0 +1,39 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS certificates (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID,
+    name VARCHAR(128) NOT NULL,
+    domains TEXT[] NOT NULL DEFAULT '{}',
+    source VARCHAR(16) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    issuer TEXT NOT NULL DEFAULT '',
+    serial_number VARCHAR(64) NOT NULL DEFAULT '',
+    not_before TIMESTAMPTZ NOT NULL,
+    not_after TIMESTAMPTZ NOT NULL,
+    certificate_pem TEXT NOT NULL,
+    encrypted_private_key TEXT NOT NULL,
+    notify_topic_id UUID,
+    expiry_alert_days INT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_certificates_user ON certificates(user_id);
+CREATE INDEX IF NOT EXISTS idx_certificates_not_after ON certificates(not_after);
+
+CREATE TABLE IF NOT EXISTS certificate_authorities (
+    name VARCHAR(64) PRIMARY KEY,
+    certificate_pem TEXT NOT NULL,
+    encrypted_private_key TEXT NOT NULL,
+    not_after TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT '00000000-0000-0000-0000-000000000002', p
+FROM (VALUES
+    ('certificate:create'),
+    ('certificate:read'),
+    ('certificate:delete')
+) AS permissions(p)
+ON CONFLICT (role_id, permission) DO NOTHING;
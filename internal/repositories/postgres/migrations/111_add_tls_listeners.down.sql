@@ -0,0 +1,4 @@
+-- +goose Down
+ALTER TABLE gateway_routes DROP COLUMN IF EXISTS https_redirect;
+ALTER TABLE gateway_routes DROP COLUMN IF EXISTS certificate_id;
+ALTER TABLE load_balancers DROP COLUMN IF EXISTS tls;
@@ -0,0 +1,4 @@
+-- +goose Up
+ALTER TABLE load_balancers ADD COLUMN IF NOT EXISTS tls JSONB;
+ALTER TABLE gateway_routes ADD COLUMN IF NOT EXISTS certificate_id UUID REFERENCES certificates(id) ON DELETE RESTRICT;
+ALTER TABLE gateway_routes ADD COLUMN IF NOT EXISTS https_redirect BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+-- +goose Down
+DROP INDEX IF EXISTS idx_lb_targets_member;
+ALTER TABLE lb_targets ADD CONSTRAINT lb_targets_lb_id_instance_id_key UNIQUE (lb_id, instance_id);
+ALTER TABLE lb_targets DROP COLUMN IF EXISTS target_group;
+ALTER TABLE load_balancers DROP COLUMN IF EXISTS listeners;
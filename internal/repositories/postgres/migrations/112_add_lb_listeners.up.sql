@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE load_balancers ADD COLUMN IF NOT EXISTS listeners JSONB;
+ALTER TABLE lb_targets ADD COLUMN IF NOT EXISTS target_group VARCHAR(64) NOT NULL DEFAULT 'default';
+-- An instance can serve several target groups, and one group on several ports.
+ALTER TABLE lb_targets DROP CONSTRAINT IF EXISTS lb_targets_lb_id_instance_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lb_targets_member ON lb_targets(lb_id, instance_id, target_group, port);
@@ -0,0 +1,5 @@
+-- +goose Down
+ALTER TABLE lb_targets DROP COLUMN IF EXISTS drain_until;
+ALTER TABLE load_balancers DROP COLUMN IF EXISTS deregistration_delay;
+ALTER TABLE load_balancers DROP COLUMN IF EXISTS sticky_sessions;
+ALTER TABLE load_balancers DROP COLUMN IF EXISTS health_check;
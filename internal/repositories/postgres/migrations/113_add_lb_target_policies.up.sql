@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE load_balancers ADD COLUMN IF NOT EXISTS health_check JSONB;
+ALTER TABLE load_balancers ADD COLUMN IF NOT EXISTS sticky_sessions JSONB;
+ALTER TABLE load_balancers ADD COLUMN IF NOT EXISTS deregistration_delay INT NOT NULL DEFAULT 0;
+ALTER TABLE lb_targets ADD COLUMN IF NOT EXISTS drain_until TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+-- +goose Up
+CREATE UNIQUE INDEX IF NOT EXISTS idx_nat_gateways_subnet ON nat_gateways(subnet_id);
@@ -0,0 +1,5 @@
+-- +goose Down
+DROP INDEX IF EXISTS idx_objects_listing;
+CREATE INDEX IF NOT EXISTS idx_objects_listing
+    ON objects (bucket, user_id, key COLLATE "C")
+    WHERE is_latest = TRUE AND deleted_at IS NULL;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Listings are scoped by bucket only, so user_id in the key columns kept the
+-- planner from seeking by key.
+DROP INDEX IF EXISTS idx_objects_listing;
+CREATE INDEX IF NOT EXISTS idx_objects_listing
+    ON objects (bucket, key COLLATE "C")
+    WHERE is_latest = TRUE AND deleted_at IS NULL;
@@ -0,0 +1,2 @@
+-- +goose Down
+ALTER TABLE objects DROP COLUMN IF EXISTS is_delete_marker;
@@ -0,0 +1,3 @@
+-- +goose Up
+-- A delete marker is a version without data that hides the key when it is current.
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS is_delete_marker BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,205 @@
+// Package postgres provides PostgreSQL-backed repository implementations.
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+)
+
+const networkACLColumns = `id, user_id, tenant_id, vpc_id, name, arn, created_at`
+
+// NetworkACLRepository provides a PostgreSQL implementation for subnet network ACLs.
+type NetworkACLRepository struct {
+	db DB
+}
+
+// NewNetworkACLRepository creates a new NetworkACLRepository.
+func NewNetworkACLRepository(db DB) *NetworkACLRepository {
+	return &NetworkACLRepository{db: db}
+}
+
+// Create inserts an empty network ACL.
+func (r *NetworkACLRepository) Create(ctx context.Context, acl *domain.NetworkACL) error {
+	query := `INSERT INTO network_acls (id, user_id, tenant_id, vpc_id, name, arn, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	if _, err := r.db.Exec(ctx, query, acl.ID, acl.UserID, acl.TenantID, acl.VPCID, acl.Name, acl.ARN, acl.CreatedAt); err != nil {
+		return errors.Wrap(errors.Internal, "failed to create network ACL", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a network ACL of the caller's tenant with its rules and subnets.
+func (r *NetworkACLRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.NetworkACL, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + networkACLColumns + ` FROM network_acls WHERE id = $1 AND tenant_id = $2`
+	acl, err := r.scanNetworkACL(r.db.QueryRow(ctx, query, id, tenantID))
+	if err != nil {
+		return nil, err
+	}
+	return acl, r.loadDetails(ctx, acl)
+}
+
+// GetBySubnet retrieves the network ACL associated with a subnet.
+func (r *NetworkACLRepository) GetBySubnet(ctx context.Context, subnetID uuid.UUID) (*domain.NetworkACL, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT n.id, n.user_id, n.tenant_id, n.vpc_id, n.name, n.arn, n.created_at
+		FROM network_acls n JOIN network_acl_associations a ON a.acl_id = n.id
+		WHERE a.subnet_id = $1 AND n.tenant_id = $2`
+	acl, err := r.scanNetworkACL(r.db.QueryRow(ctx, query, subnetID, tenantID))
+	if err != nil {
+		return nil, err
+	}
+	return acl, r.loadDetails(ctx, acl)
+}
+
+// ListByVPC returns every network ACL of a VPC.
+func (r *NetworkACLRepository) ListByVPC(ctx context.Context, vpcID uuid.UUID) ([]*domain.NetworkACL, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + networkACLColumns + ` FROM network_acls WHERE vpc_id = $1 AND tenant_id = $2 ORDER BY created_at`
+	rows, err := r.db.Query(ctx, query, vpcID, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list network ACLs", err)
+	}
+	defer rows.Close()
+
+	var acls []*domain.NetworkACL
+	for rows.Next() {
+		acl, err := r.scanNetworkACL(rows)
+		if err != nil {
+			return nil, err
+		}
+		acls = append(acls, acl)
+	}
+	rows.Close()
+
+	for _, acl := range acls {
+		if err := r.loadDetails(ctx, acl); err != nil {
+			return nil, err
+		}
+	}
+	return acls, nil
+}
+
+// Delete removes a network ACL; its rules go with it.
+func (r *NetworkACLRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	cmd, err := r.db.Exec(ctx, `DELETE FROM network_acls WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete network ACL", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "network ACL not found")
+	}
+	return nil
+}
+
+// AddRule appends a rule to an ACL. A rule number already used in the same direction is a conflict.
+func (r *NetworkACLRepository) AddRule(ctx context.Context, rule *domain.NetworkACLRule) error {
+	query := `INSERT INTO network_acl_rules (id, acl_id, rule_number, direction, protocol, port_min, port_max, cidr, action, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8::cidr, $9, $10)
+		ON CONFLICT (acl_id, direction, rule_number) DO NOTHING`
+	cmd, err := r.db.Exec(ctx, query, rule.ID, rule.ACLID, rule.RuleNumber, string(rule.Direction), rule.Protocol,
+		rule.PortMin, rule.PortMax, rule.CIDR, string(rule.Action), rule.CreatedAt)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to add network ACL rule", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.Conflict, fmt.Sprintf("%s rule %d already exists", rule.Direction, rule.RuleNumber))
+	}
+	return nil
+}
+
+// DeleteRule removes a rule from an ACL of the caller's tenant.
+func (r *NetworkACLRepository) DeleteRule(ctx context.Context, aclID, ruleID uuid.UUID) error {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `DELETE FROM network_acl_rules WHERE id = $1 AND acl_id = $2
+		AND acl_id IN (SELECT id FROM network_acls WHERE tenant_id = $3)`
+	cmd, err := r.db.Exec(ctx, query, ruleID, aclID, tenantID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete network ACL rule", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "network ACL rule not found")
+	}
+	return nil
+}
+
+// AssociateSubnet makes an ACL the one used by a subnet, replacing any previous association.
+func (r *NetworkACLRepository) AssociateSubnet(ctx context.Context, aclID, subnetID uuid.UUID) error {
+	query := `INSERT INTO network_acl_associations (subnet_id, acl_id, created_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (subnet_id) DO UPDATE SET acl_id = EXCLUDED.acl_id, created_at = NOW()`
+	if _, err := r.db.Exec(ctx, query, subnetID, aclID); err != nil {
+		return errors.Wrap(errors.Internal, "failed to associate subnet", err)
+	}
+	return nil
+}
+
+// DisassociateSubnet removes a subnet's ACL association.
+func (r *NetworkACLRepository) DisassociateSubnet(ctx context.Context, subnetID uuid.UUID) error {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `DELETE FROM network_acl_associations WHERE subnet_id = $1
+		AND acl_id IN (SELECT id FROM network_acls WHERE tenant_id = $2)`
+	cmd, err := r.db.Exec(ctx, query, subnetID, tenantID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to disassociate subnet", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "subnet has no network ACL")
+	}
+	return nil
+}
+
+func (r *NetworkACLRepository) loadDetails(ctx context.Context, acl *domain.NetworkACL) error {
+	rows, err := r.db.Query(ctx, `SELECT id, acl_id, rule_number, direction, protocol, port_min, port_max, cidr::text, action, created_at
+		FROM network_acl_rules WHERE acl_id = $1 ORDER BY direction, rule_number`, acl.ID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to load network ACL rules", err)
+	}
+	acl.Rules = []domain.NetworkACLRule{}
+	for rows.Next() {
+		var rule domain.NetworkACLRule
+		var direction, action string
+		if err := rows.Scan(&rule.ID, &rule.ACLID, &rule.RuleNumber, &direction, &rule.Protocol,
+			&rule.PortMin, &rule.PortMax, &rule.CIDR, &action, &rule.CreatedAt); err != nil {
+			rows.Close()
+			return errors.Wrap(errors.Internal, "failed to scan network ACL rule", err)
+		}
+		rule.Direction = domain.RuleDirection(direction)
+		rule.Action = domain.ACLAction(action)
+		acl.Rules = append(acl.Rules, rule)
+	}
+	rows.Close()
+
+	rows, err = r.db.Query(ctx, `SELECT subnet_id FROM network_acl_associations WHERE acl_id = $1 ORDER BY created_at`, acl.ID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to load network ACL associations", err)
+	}
+	defer rows.Close()
+	acl.SubnetIDs = []uuid.UUID{}
+	for rows.Next() {
+		var subnetID uuid.UUID
+		if err := rows.Scan(&subnetID); err != nil {
+			return errors.Wrap(errors.Internal, "failed to scan network ACL association", err)
+		}
+		acl.SubnetIDs = append(acl.SubnetIDs, subnetID)
+	}
+	return nil
+}
+
+func (r *NetworkACLRepository) scanNetworkACL(row pgx.Row) (*domain.NetworkACL, error) {
+	var acl domain.NetworkACL
+	err := row.Scan(&acl.ID, &acl.UserID, &acl.TenantID, &acl.VPCID, &acl.Name, &acl.ARN, &acl.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.NotFound, "network ACL not found")
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to scan network ACL", err)
+	}
+	return &acl, nil
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	theclouderrors "github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkACLRepository(t *testing.T) {
+	tenantID := uuid.New()
+	ctx := appcontext.WithTenantID(context.Background(), tenantID)
+	now := time.Now()
+	acl := &domain.NetworkACL{
+		ID: uuid.New(), UserID: uuid.New(), TenantID: tenantID, VPCID: uuid.New(),
+		Name: "web", ARN: "arn", CreatedAt: now,
+	}
+	rule := domain.NetworkACLRule{
+		ID: uuid.New(), ACLID: acl.ID, RuleNumber: 100, Direction: domain.RuleIngress, Protocol: "tcp",
+		PortMin: 443, PortMax: 443, CIDR: "0.0.0.0/0", Action: domain.ACLAllow, CreatedAt: now,
+	}
+	subnetID := uuid.New()
+
+	t.Run("Create", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("INSERT INTO network_acls").
+			WithArgs(acl.ID, acl.UserID, acl.TenantID, acl.VPCID, acl.Name, acl.ARN, acl.CreatedAt).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, NewNetworkACLRepository(mock).Create(ctx, acl))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetByID loads rules and subnets", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectQuery("SELECT id, user_id, tenant_id, vpc_id, name, arn, created_at FROM network_acls").
+			WithArgs(acl.ID, tenantID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "vpc_id", "name", "arn", "created_at"}).
+				AddRow(acl.ID, acl.UserID, acl.TenantID, acl.VPCID, acl.Name, acl.ARN, acl.CreatedAt))
+		mock.ExpectQuery("FROM network_acl_rules").
+			WithArgs(acl.ID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "acl_id", "rule_number", "direction", "protocol", "port_min", "port_max", "cidr", "action", "created_at"}).
+				AddRow(rule.ID, acl.ID, 100, "ingress", "tcp", 443, 443, "0.0.0.0/0", "allow", now))
+		mock.ExpectQuery("SELECT subnet_id FROM network_acl_associations").
+			WithArgs(acl.ID).
+			WillReturnRows(pgxmock.NewRows([]string{"subnet_id"}).AddRow(subnetID))
+
+		got, err := NewNetworkACLRepository(mock).GetByID(ctx, acl.ID)
+		require.NoError(t, err)
+		require.Len(t, got.Rules, 1)
+		assert.Equal(t, domain.ACLAllow, got.Rules[0].Action)
+		assert.Equal(t, []uuid.UUID{subnetID}, got.SubnetIDs)
+	})
+
+	t.Run("GetBySubnet not found", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectQuery("FROM network_acls n JOIN network_acl_associations").
+			WithArgs(subnetID, tenantID).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, err = NewNetworkACLRepository(mock).GetBySubnet(ctx, subnetID)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("AddRule rejects a duplicate rule number", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("INSERT INTO network_acl_rules").
+			WithArgs(rule.ID, acl.ID, 100, "ingress", "tcp", 443, 443, "0.0.0.0/0", "allow", now).
+			WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+		err = NewNetworkACLRepository(mock).AddRule(ctx, &rule)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.Conflict))
+	})
+
+	t.Run("DeleteRule not found", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("DELETE FROM network_acl_rules").
+			WithArgs(rule.ID, acl.ID, tenantID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		err = NewNetworkACLRepository(mock).DeleteRule(ctx, acl.ID, rule.ID)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("AssociateSubnet upserts", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("INSERT INTO network_acl_associations").
+			WithArgs(subnetID, acl.ID).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, NewNetworkACLRepository(mock).AssociateSubnet(ctx, acl.ID, subnetID))
+	})
+
+	t.Run("DisassociateSubnet without association", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("DELETE FROM network_acl_associations").
+			WithArgs(subnetID, tenantID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		err = NewNetworkACLRepository(mock).DisassociateSubnet(ctx, subnetID)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("DELETE FROM network_acls").
+			WithArgs(acl.ID, tenantID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		require.NoError(t, NewNetworkACLRepository(mock).Delete(ctx, acl.ID))
+	})
+}
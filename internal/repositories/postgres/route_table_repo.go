@@ -0,0 +1,261 @@
+// Package postgres provides PostgreSQL-backed repository implementations.
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+)
+
+const routeTableColumns = `id, user_id, tenant_id, vpc_id, name, is_main, arn, created_at`
+
+// RouteTableRepository provides a PostgreSQL implementation for VPC route tables.
+type RouteTableRepository struct {
+	db DB
+}
+
+// NewRouteTableRepository creates a new RouteTableRepository.
+func NewRouteTableRepository(db DB) *RouteTableRepository {
+	return &RouteTableRepository{db: db}
+}
+
+// Create inserts a route table and its initial routes in one transaction.
+func (r *RouteTableRepository) Create(ctx context.Context, rt *domain.RouteTable) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to start transaction", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query := `INSERT INTO route_tables (id, user_id, tenant_id, vpc_id, name, is_main, arn, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	if _, err := tx.Exec(ctx, query, rt.ID, rt.UserID, rt.TenantID, rt.VPCID, rt.Name, rt.Main, rt.ARN, rt.CreatedAt); err != nil {
+		return errors.Wrap(errors.Internal, "failed to create route table", err)
+	}
+	for i := range rt.Routes {
+		if err := insertRoute(ctx, tx, &rt.Routes[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(errors.Internal, "failed to commit route table", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a route table of the caller's tenant with its routes and subnets.
+func (r *RouteTableRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.RouteTable, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + routeTableColumns + ` FROM route_tables WHERE id = $1 AND tenant_id = $2`
+	rt, err := r.scanRouteTable(r.db.QueryRow(ctx, query, id, tenantID))
+	if err != nil {
+		return nil, err
+	}
+	return rt, r.loadDetails(ctx, rt)
+}
+
+// GetMain retrieves a VPC's main route table.
+func (r *RouteTableRepository) GetMain(ctx context.Context, vpcID uuid.UUID) (*domain.RouteTable, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + routeTableColumns + ` FROM route_tables WHERE vpc_id = $1 AND is_main AND tenant_id = $2`
+	rt, err := r.scanRouteTable(r.db.QueryRow(ctx, query, vpcID, tenantID))
+	if err != nil {
+		return nil, err
+	}
+	return rt, r.loadDetails(ctx, rt)
+}
+
+// GetBySubnet retrieves the route table explicitly associated with a subnet.
+func (r *RouteTableRepository) GetBySubnet(ctx context.Context, subnetID uuid.UUID) (*domain.RouteTable, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT t.id, t.user_id, t.tenant_id, t.vpc_id, t.name, t.is_main, t.arn, t.created_at
+		FROM route_tables t JOIN route_table_associations a ON a.route_table_id = t.id
+		WHERE a.subnet_id = $1 AND t.tenant_id = $2`
+	rt, err := r.scanRouteTable(r.db.QueryRow(ctx, query, subnetID, tenantID))
+	if err != nil {
+		return nil, err
+	}
+	return rt, r.loadDetails(ctx, rt)
+}
+
+// ListByVPC returns every route table of a VPC, main table first.
+func (r *RouteTableRepository) ListByVPC(ctx context.Context, vpcID uuid.UUID) ([]*domain.RouteTable, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + routeTableColumns + ` FROM route_tables WHERE vpc_id = $1 AND tenant_id = $2 ORDER BY is_main DESC, created_at`
+	rows, err := r.db.Query(ctx, query, vpcID, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list route tables", err)
+	}
+	defer rows.Close()
+
+	var tables []*domain.RouteTable
+	for rows.Next() {
+		rt, err := r.scanRouteTable(rows)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, rt)
+	}
+	rows.Close()
+
+	for _, rt := range tables {
+		if err := r.loadDetails(ctx, rt); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// Delete removes a route table; its routes and associations go with it.
+func (r *RouteTableRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	cmd, err := r.db.Exec(ctx, `DELETE FROM route_tables WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete route table", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "route table not found")
+	}
+	return nil
+}
+
+// AddRoute appends a route to a table.
+func (r *RouteTableRepository) AddRoute(ctx context.Context, route *domain.Route) error {
+	return insertRoute(ctx, r.db, route)
+}
+
+// DeleteRoute removes the route for a destination from a table.
+func (r *RouteTableRepository) DeleteRoute(ctx context.Context, routeTableID uuid.UUID, destinationCIDR string) error {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `DELETE FROM routes WHERE route_table_id = $1 AND destination_cidr = $2::cidr
+		AND route_table_id IN (SELECT id FROM route_tables WHERE tenant_id = $3)`
+	cmd, err := r.db.Exec(ctx, query, routeTableID, destinationCIDR, tenantID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete route", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "route not found")
+	}
+	return nil
+}
+
+// ListRoutesByTarget returns every route sending traffic to a gateway.
+func (r *RouteTableRepository) ListRoutesByTarget(ctx context.Context, targetID uuid.UUID) ([]*domain.Route, error) {
+	query := `SELECT id, route_table_id, destination_cidr::text, target_type, target_id, created_at FROM routes WHERE target_id = $1`
+	rows, err := r.db.Query(ctx, query, targetID)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list routes", err)
+	}
+	defer rows.Close()
+
+	var routes []*domain.Route
+	for rows.Next() {
+		route, err := scanRoute(rows)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// AssociateSubnet makes a table the one used by a subnet, replacing any previous association.
+func (r *RouteTableRepository) AssociateSubnet(ctx context.Context, routeTableID, subnetID uuid.UUID) error {
+	query := `INSERT INTO route_table_associations (subnet_id, route_table_id, created_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (subnet_id) DO UPDATE SET route_table_id = EXCLUDED.route_table_id, created_at = NOW()`
+	if _, err := r.db.Exec(ctx, query, subnetID, routeTableID); err != nil {
+		return errors.Wrap(errors.Internal, "failed to associate subnet", err)
+	}
+	return nil
+}
+
+// DisassociateSubnet returns a subnet to its VPC's main table.
+func (r *RouteTableRepository) DisassociateSubnet(ctx context.Context, subnetID uuid.UUID) error {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `DELETE FROM route_table_associations WHERE subnet_id = $1
+		AND route_table_id IN (SELECT id FROM route_tables WHERE tenant_id = $2)`
+	cmd, err := r.db.Exec(ctx, query, subnetID, tenantID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to disassociate subnet", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "subnet has no route table association")
+	}
+	return nil
+}
+
+func (r *RouteTableRepository) loadDetails(ctx context.Context, rt *domain.RouteTable) error {
+	rows, err := r.db.Query(ctx, `SELECT id, route_table_id, destination_cidr::text, target_type, target_id, created_at
+		FROM routes WHERE route_table_id = $1 ORDER BY created_at`, rt.ID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to load routes", err)
+	}
+	rt.Routes = []domain.Route{}
+	for rows.Next() {
+		route, err := scanRoute(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		rt.Routes = append(rt.Routes, *route)
+	}
+	rows.Close()
+
+	rows, err = r.db.Query(ctx, `SELECT subnet_id FROM route_table_associations WHERE route_table_id = $1 ORDER BY created_at`, rt.ID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to load route table associations", err)
+	}
+	defer rows.Close()
+	rt.SubnetIDs = []uuid.UUID{}
+	for rows.Next() {
+		var subnetID uuid.UUID
+		if err := rows.Scan(&subnetID); err != nil {
+			return errors.Wrap(errors.Internal, "failed to scan route table association", err)
+		}
+		rt.SubnetIDs = append(rt.SubnetIDs, subnetID)
+	}
+	return nil
+}
+
+func (r *RouteTableRepository) scanRouteTable(row pgx.Row) (*domain.RouteTable, error) {
+	var rt domain.RouteTable
+	err := row.Scan(&rt.ID, &rt.UserID, &rt.TenantID, &rt.VPCID, &rt.Name, &rt.Main, &rt.ARN, &rt.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.NotFound, "route table not found")
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to scan route table", err)
+	}
+	return &rt, nil
+}
+
+// routeExecer is satisfied by both the pool and a transaction.
+type routeExecer interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
+func insertRoute(ctx context.Context, db routeExecer, route *domain.Route) error {
+	query := `INSERT INTO routes (id, route_table_id, destination_cidr, target_type, target_id, created_at)
+		VALUES ($1, $2, $3::cidr, $4, $5, $6)`
+	_, err := db.Exec(ctx, query, route.ID, route.RouteTableID, route.DestinationCIDR, string(route.TargetType), route.TargetID, route.CreatedAt)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to add route", err)
+	}
+	return nil
+}
+
+func scanRoute(row pgx.Row) (*domain.Route, error) {
+	var route domain.Route
+	var targetType string
+	if err := row.Scan(&route.ID, &route.RouteTableID, &route.DestinationCIDR, &targetType, &route.TargetID, &route.CreatedAt); err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to scan route", err)
+	}
+	route.TargetType = domain.RouteTargetType(targetType)
+	return &route, nil
+}
@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	theclouderrors "github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	selectRouteTable = "SELECT id, user_id, tenant_id, vpc_id, name, is_main"
+	selectRoutes     = "SELECT id, route_table_id, destination_cidr::text"
+	selectAssocs     = "SELECT subnet_id FROM route_table_associations"
+)
+
+func routeTableRows(rt *domain.RouteTable) *pgxmock.Rows {
+	return pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "vpc_id", "name", "is_main", "arn", "created_at"}).
+		AddRow(rt.ID, rt.UserID, rt.TenantID, rt.VPCID, rt.Name, rt.Main, rt.ARN, rt.CreatedAt)
+}
+
+func routeRows(routes ...domain.Route) *pgxmock.Rows {
+	rows := pgxmock.NewRows([]string{"id", "route_table_id", "destination_cidr", "target_type", "target_id", "created_at"})
+	for _, r := range routes {
+		rows.AddRow(r.ID, r.RouteTableID, r.DestinationCIDR, string(r.TargetType), r.TargetID, r.CreatedAt)
+	}
+	return rows
+}
+
+func TestRouteTableRepository(t *testing.T) {
+	tenantID := uuid.New()
+	ctx := appcontext.WithTenantID(context.Background(), tenantID)
+	now := time.Now()
+	rt := &domain.RouteTable{
+		ID: uuid.New(), UserID: uuid.New(), TenantID: tenantID, VPCID: uuid.New(),
+		Name: "main", Main: true, ARN: "arn", CreatedAt: now,
+	}
+	local := domain.Route{ID: uuid.New(), RouteTableID: rt.ID, DestinationCIDR: "10.0.0.0/16", TargetType: domain.RouteTargetLocal, CreatedAt: now}
+	igwID := uuid.New()
+	internet := domain.Route{ID: uuid.New(), RouteTableID: rt.ID, DestinationCIDR: "0.0.0.0/0", TargetType: domain.RouteTargetInternetGateway, TargetID: &igwID, CreatedAt: now}
+
+	t.Run("Create inserts the table and its routes in one transaction", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		created := *rt
+		created.Routes = []domain.Route{local}
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO route_tables").
+			WithArgs(rt.ID, rt.UserID, rt.TenantID, rt.VPCID, rt.Name, true, rt.ARN, rt.CreatedAt).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec("INSERT INTO routes").
+			WithArgs(local.ID, rt.ID, local.DestinationCIDR, "local", local.TargetID, local.CreatedAt).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+		mock.ExpectRollback()
+
+		require.NoError(t, NewRouteTableRepository(mock).Create(ctx, &created))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Create rolls back when a route fails", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		created := *rt
+		created.Routes = []domain.Route{local}
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO route_tables").
+			WithArgs(rt.ID, rt.UserID, rt.TenantID, rt.VPCID, rt.Name, true, rt.ARN, rt.CreatedAt).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec("INSERT INTO routes").
+			WithArgs(local.ID, rt.ID, local.DestinationCIDR, "local", local.TargetID, local.CreatedAt).
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		err = NewRouteTableRepository(mock).Create(ctx, &created)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.Internal))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetMain loads routes and associations", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		subnetID := uuid.New()
+		mock.ExpectQuery(selectRouteTable).WithArgs(rt.VPCID, tenantID).WillReturnRows(routeTableRows(rt))
+		mock.ExpectQuery(selectRoutes).WithArgs(rt.ID).WillReturnRows(routeRows(local, internet))
+		mock.ExpectQuery(selectAssocs).WithArgs(rt.ID).WillReturnRows(pgxmock.NewRows([]string{"subnet_id"}).AddRow(subnetID))
+
+		got, err := NewRouteTableRepository(mock).GetMain(ctx, rt.VPCID)
+		require.NoError(t, err)
+		require.Len(t, got.Routes, 2)
+		assert.Equal(t, domain.RouteTargetInternetGateway, got.Routes[1].TargetType)
+		assert.Equal(t, igwID, *got.Routes[1].TargetID)
+		assert.Equal(t, []uuid.UUID{subnetID}, got.SubnetIDs)
+		assert.True(t, got.IsPublic())
+	})
+
+	t.Run("GetBySubnet returns NotFound without an association", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		subnetID := uuid.New()
+		mock.ExpectQuery("FROM route_tables t JOIN route_table_associations").WithArgs(subnetID, tenantID).WillReturnError(pgx.ErrNoRows)
+
+		_, err = NewRouteTableRepository(mock).GetBySubnet(ctx, subnetID)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("ListByVPC", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectQuery(selectRouteTable).WithArgs(rt.VPCID, tenantID).WillReturnRows(routeTableRows(rt))
+		mock.ExpectQuery(selectRoutes).WithArgs(rt.ID).WillReturnRows(routeRows(local))
+		mock.ExpectQuery(selectAssocs).WithArgs(rt.ID).WillReturnRows(pgxmock.NewRows([]string{"subnet_id"}))
+
+		tables, err := NewRouteTableRepository(mock).ListByVPC(ctx, rt.VPCID)
+		require.NoError(t, err)
+		require.Len(t, tables, 1)
+		assert.Len(t, tables[0].Routes, 1)
+		assert.Empty(t, tables[0].SubnetIDs)
+	})
+
+	t.Run("DeleteRoute", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("DELETE FROM routes").WithArgs(rt.ID, "0.0.0.0/0", tenantID).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectExec("DELETE FROM routes").WithArgs(rt.ID, "8.8.8.0/24", tenantID).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		repo := NewRouteTableRepository(mock)
+		require.NoError(t, repo.DeleteRoute(ctx, rt.ID, "0.0.0.0/0"))
+		err = repo.DeleteRoute(ctx, rt.ID, "8.8.8.0/24")
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("ListRoutesByTarget", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectQuery(selectRoutes).WithArgs(igwID).WillReturnRows(routeRows(internet))
+
+		routes, err := NewRouteTableRepository(mock).ListRoutesByTarget(ctx, igwID)
+		require.NoError(t, err)
+		assert.Len(t, routes, 1)
+	})
+
+	t.Run("AssociateSubnet upserts", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		subnetID := uuid.New()
+		mock.ExpectExec("ON CONFLICT \\(subnet_id\\) DO UPDATE").WithArgs(subnetID, rt.ID).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, NewRouteTableRepository(mock).AssociateSubnet(ctx, rt.ID, subnetID))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DisassociateSubnet", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		subnetID := uuid.New()
+		mock.ExpectExec("DELETE FROM route_table_associations").WithArgs(subnetID, tenantID).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		err = NewRouteTableRepository(mock).DisassociateSubnet(ctx, subnetID)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+}
@@ -107,10 +107,10 @@ func (r *SecurityGroupRepository) scanSecurityGroups(rows pgx.Rows) ([]*domain.S
 
 func (r *SecurityGroupRepository) AddRule(ctx context.Context, rule *domain.SecurityRule) error {
 	query := `
-		INSERT INTO security_rules (id, group_id, direction, protocol, port_min, port_max, cidr, priority, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO security_rules (id, group_id, direction, protocol, port_min, port_max, cidr, source_group_id, priority, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
-	_, err := r.db.Exec(ctx, query, rule.ID, rule.GroupID, rule.Direction, rule.Protocol, rule.PortMin, rule.PortMax, rule.CIDR, rule.Priority, rule.CreatedAt)
+	_, err := r.db.Exec(ctx, query, rule.ID, rule.GroupID, rule.Direction, rule.Protocol, rule.PortMin, rule.PortMax, rule.CIDR, rule.SourceGroupID, rule.Priority, rule.CreatedAt)
 	if err != nil {
 		return errors.Wrap(errors.Internal, "failed to add security rule", err)
 	}
@@ -120,7 +120,7 @@ func (r *SecurityGroupRepository) AddRule(ctx context.Context, rule *domain.Secu
 func (r *SecurityGroupRepository) GetRuleByID(ctx context.Context, ruleID uuid.UUID) (*domain.SecurityRule, error) {
 	tenantID := appcontext.TenantIDFromContext(ctx)
 	query := `
-		SELECT sr.id, sr.group_id, sr.direction, sr.protocol, sr.port_min, sr.port_max, sr.cidr, sr.priority, sr.created_at 
+		SELECT sr.id, sr.group_id, sr.direction, sr.protocol, sr.port_min, sr.port_max, sr.cidr, sr.source_group_id, sr.priority, sr.created_at 
 		FROM security_rules sr
 		JOIN security_groups sg ON sr.group_id = sg.id
 		WHERE sr.id = $1 AND sg.tenant_id = $2
@@ -243,8 +243,43 @@ func (r *SecurityGroupRepository) ListInstanceGroups(ctx context.Context, instan
 	return r.scanSecurityGroups(rows)
 }
 
+func (r *SecurityGroupRepository) ListGroupMemberIPs(ctx context.Context, groupID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT host(a.addr)
+		FROM instances i
+		JOIN instance_security_groups isg ON i.id = isg.instance_id
+		CROSS JOIN LATERAL (VALUES (i.private_ip), (i.private_ipv6)) AS a(addr)
+		WHERE isg.group_id = $1 AND a.addr IS NOT NULL
+		ORDER BY family(a.addr), a.addr
+	`
+	rows, err := r.db.Query(ctx, query, groupID)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list security group members", err)
+	}
+	defer rows.Close()
+
+	var ips []string
+	for rows.Next() {
+		var ip string
+		if err := rows.Scan(&ip); err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to scan security group member", err)
+		}
+		ips = append(ips, ip)
+	}
+	return ips, rows.Err()
+}
+
+func (r *SecurityGroupRepository) ListRulesReferencingGroup(ctx context.Context, groupID uuid.UUID) ([]domain.SecurityRule, error) {
+	query := `SELECT id, group_id, direction, protocol, port_min, port_max, cidr, source_group_id, priority, created_at FROM security_rules WHERE source_group_id = $1`
+	rows, err := r.db.Query(ctx, query, groupID)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list referencing security rules", err)
+	}
+	return r.scanSecurityRules(rows)
+}
+
 func (r *SecurityGroupRepository) getRulesForGroup(ctx context.Context, groupID uuid.UUID) ([]domain.SecurityRule, error) {
-	query := `SELECT id, group_id, direction, protocol, port_min, port_max, cidr, priority, created_at FROM security_rules WHERE group_id = $1 ORDER BY priority DESC`
+	query := `SELECT id, group_id, direction, protocol, port_min, port_max, cidr, source_group_id, priority, created_at FROM security_rules WHERE group_id = $1 ORDER BY priority DESC`
 	rows, err := r.db.Query(ctx, query, groupID)
 	if err != nil {
 		return nil, err
@@ -255,7 +290,7 @@ func (r *SecurityGroupRepository) getRulesForGroup(ctx context.Context, groupID
 func (r *SecurityGroupRepository) scanSecurityRule(row pgx.Row) (domain.SecurityRule, error) {
 	var rule domain.SecurityRule
 	var direction string
-	if err := row.Scan(&rule.ID, &rule.GroupID, &direction, &rule.Protocol, &rule.PortMin, &rule.PortMax, &rule.CIDR, &rule.Priority, &rule.CreatedAt); err != nil {
+	if err := row.Scan(&rule.ID, &rule.GroupID, &direction, &rule.Protocol, &rule.PortMin, &rule.PortMax, &rule.CIDR, &rule.SourceGroupID, &rule.Priority, &rule.CreatedAt); err != nil {
 		return domain.SecurityRule{}, err
 	}
 	rule.Direction = domain.RuleDirection(direction)
@@ -19,7 +19,7 @@ import (
 const (
 	testSgName         = "test-sg"
 	selectSg           = "SELECT id, user_id, tenant_id, vpc_id, name, description, arn, created_at FROM security_groups"
-	selectRule         = "SELECT id, group_id, direction, protocol, port_min, port_max, cidr, priority, created_at FROM security_rules"
+	selectRule         = "SELECT id, group_id, direction, protocol, port_min, port_max, cidr, source_group_id, priority, created_at FROM security_rules"
 	selectInstanceUser = "SELECT tenant_id FROM instances WHERE id = \\$1"
 	selectSgUser       = "SELECT tenant_id FROM security_groups WHERE id = \\$1"
 )
@@ -88,8 +88,8 @@ func TestSecurityGroupRepositoryGetByID(t *testing.T) {
 
 		mock.ExpectQuery(selectRule).
 			WithArgs(id).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "group_id", "direction", "protocol", "port_min", "port_max", "cidr", "priority", "created_at"}).
-				AddRow(uuid.New(), id, string(domain.RuleIngress), "tcp", 80, 80, testutil.TestAnyCIDR, 100, now))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "group_id", "direction", "protocol", "port_min", "port_max", "cidr", "source_group_id", "priority", "created_at"}).
+				AddRow(uuid.New(), id, string(domain.RuleIngress), "tcp", 80, 80, testutil.TestAnyCIDR, nil, 100, now))
 
 		sg, err := repo.GetByID(ctx, id)
 		assert.NoError(t, err)
@@ -145,8 +145,8 @@ func TestSecurityGroupRepositoryGetByName(t *testing.T) {
 
 		mock.ExpectQuery(selectRule).
 			WithArgs(id).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "group_id", "direction", "protocol", "port_min", "port_max", "cidr", "priority", "created_at"}).
-				AddRow(uuid.New(), id, string(domain.RuleIngress), "tcp", 80, 80, testutil.TestAnyCIDR, 100, now))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "group_id", "direction", "protocol", "port_min", "port_max", "cidr", "source_group_id", "priority", "created_at"}).
+				AddRow(uuid.New(), id, string(domain.RuleIngress), "tcp", 80, 80, testutil.TestAnyCIDR, nil, 100, now))
 
 		sg, err := repo.GetByName(ctx, vpcID, name)
 		assert.NoError(t, err)
@@ -244,7 +244,7 @@ func TestSecurityGroupRepositoryAddRule(t *testing.T) {
 		}
 
 		mock.ExpectExec("INSERT INTO security_rules").
-			WithArgs(rule.ID, rule.GroupID, rule.Direction, rule.Protocol, rule.PortMin, rule.PortMax, rule.CIDR, rule.Priority, rule.CreatedAt).
+			WithArgs(rule.ID, rule.GroupID, rule.Direction, rule.Protocol, rule.PortMin, rule.PortMax, rule.CIDR, rule.SourceGroupID, rule.Priority, rule.CreatedAt).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		err = repo.AddRule(context.Background(), rule)
@@ -321,8 +321,8 @@ func TestSecurityGroupRepositoryGetRuleByID(t *testing.T) {
 		ctx := appcontext.WithTenantID(appcontext.WithUserID(context.Background(), userID), tenantID)
 		now := time.Now()
 
-		rows := pgxmock.NewRows([]string{"id", "group_id", "direction", "protocol", "port_min", "port_max", "cidr", "priority", "created_at"}).
-			AddRow(ruleID, uuid.New(), "ingress", "tcp", 80, 80, "0.0.0.0/0", 100, now)
+		rows := pgxmock.NewRows([]string{"id", "group_id", "direction", "protocol", "port_min", "port_max", "cidr", "source_group_id", "priority", "created_at"}).
+			AddRow(ruleID, uuid.New(), "ingress", "tcp", 80, 80, "0.0.0.0/0", nil, 100, now)
 
 		mock.ExpectQuery("SELECT .* FROM security_rules").
 			WithArgs(ruleID, tenantID).
@@ -575,3 +575,40 @@ func TestSecurityGroupRepositoryListInstanceGroups(t *testing.T) {
 		assert.Nil(t, groups)
 	})
 }
+
+func TestSecurityGroupRepositoryListGroupMemberIPs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewSecurityGroupRepository(mock)
+	groupID := uuid.New()
+
+	mock.ExpectQuery("SELECT host\\(a.addr\\)").
+		WithArgs(groupID).
+		WillReturnRows(pgxmock.NewRows([]string{"host"}).AddRow("10.0.1.4").AddRow("10.0.1.9").AddRow("fd00:10:0:1::4"))
+
+	ips, err := repo.ListGroupMemberIPs(context.Background(), groupID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.1.4", "10.0.1.9", "fd00:10:0:1::4"}, ips)
+}
+
+func TestSecurityGroupRepositoryListRulesReferencingGroup(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewSecurityGroupRepository(mock)
+	groupID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(selectRule + " WHERE source_group_id = \\$1").
+		WithArgs(groupID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "group_id", "direction", "protocol", "port_min", "port_max", "cidr", "source_group_id", "priority", "created_at"}).
+			AddRow(uuid.New(), uuid.New(), "ingress", "tcp", 5432, 5432, "", &groupID, 100, now))
+
+	rules, err := repo.ListRulesReferencingGroup(context.Background(), groupID)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 1)
+	assert.Equal(t, groupID, *rules[0].SourceGroupID)
+}
@@ -3,11 +3,13 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/errors"
 )
@@ -23,28 +25,113 @@ func NewStorageRepository(db DB) *StorageRepository {
 }
 
 func (r *StorageRepository) SaveMeta(ctx context.Context, obj *domain.Object) error {
+	return saveObjectMeta(ctx, r.db, obj)
+}
+
+// SaveMetaIf saves a new latest version of an object only if the key's latest version
+// is still current, or the key still has no object when current is nil.
+func (r *StorageRepository) SaveMetaIf(ctx context.Context, obj *domain.Object, current *domain.Object) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to start transaction", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockLatestObject(ctx, tx, obj.Bucket, obj.Key, current); err != nil {
+		return err
+	}
+	if err := saveObjectMeta(ctx, tx, obj); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(errors.Internal, "failed to commit object metadata", err)
+	}
+	return nil
+}
+
+// objectExecer is satisfied by both the pool and a transaction.
+type objectExecer interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
+// lockLatestObject serializes conditional writes of a key for the rest of tx and checks
+// that the key's latest version is still current (nil: the key has no object). Versions
+// are compared by ETag and modification time, which is what the conditions were
+// evaluated against.
+func lockLatestObject(ctx context.Context, tx pgx.Tx, bucket, key string, current *domain.Object) error {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, bucket+"/"+key); err != nil {
+		return errors.Wrap(errors.Internal, "failed to lock object", err)
+	}
+
+	query := `
+		SELECT etag, created_at
+		FROM objects
+		WHERE bucket = $1 AND key = $2 AND deleted_at IS NULL AND is_latest = TRUE AND NOT is_delete_marker
+		FOR UPDATE
+	`
+	var etag string
+	var createdAt time.Time
+	err := tx.QueryRow(ctx, query, bucket, key).Scan(&etag, &createdAt)
+	if err != nil && err != pgx.ErrNoRows {
+		return errors.Wrap(errors.Internal, "failed to lock latest object version", err)
+	}
+	if current == nil {
+		if err == nil {
+			return errors.New(errors.PreconditionFailed, "precondition failed")
+		}
+		return nil
+	}
+	if err == pgx.ErrNoRows || etag != current.ETag || !createdAt.Equal(current.CreatedAt) {
+		return errors.New(errors.PreconditionFailed, "precondition failed")
+	}
+	return nil
+}
+
+func saveObjectMeta(ctx context.Context, db objectExecer, obj *domain.Object) error {
 	// If this is the new latest version, mark previous one as not latest
 	if obj.IsLatest {
 		updateQuery := `UPDATE objects SET is_latest = FALSE WHERE bucket = $1 AND key = $2 AND is_latest = TRUE`
-		_, err := r.db.Exec(ctx, updateQuery, obj.Bucket, obj.Key)
+		_, err := db.Exec(ctx, updateQuery, obj.Bucket, obj.Key)
 		if err != nil {
 			return errors.Wrap(errors.Internal, "failed to update previous latest", err)
 		}
 	}
 
 	query := `
-		INSERT INTO objects (id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, content_type, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO objects (id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, storage_class, data_shards, parity_shards, stored_bytes, etag, checksum_sha256, acl, tags, retention_mode, retain_until, legal_hold, encryption, key_version, customer_key_md5, content_type, created_at, is_delete_marker)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25)
 		ON CONFLICT (bucket, key, version_id) DO UPDATE SET
 			size_bytes = EXCLUDED.size_bytes,
+			storage_class = EXCLUDED.storage_class,
+			data_shards = EXCLUDED.data_shards,
+			parity_shards = EXCLUDED.parity_shards,
+			stored_bytes = EXCLUDED.stored_bytes,
+			etag = EXCLUDED.etag,
+			checksum_sha256 = EXCLUDED.checksum_sha256,
+			acl = EXCLUDED.acl,
+			tags = EXCLUDED.tags,
+			retention_mode = EXCLUDED.retention_mode,
+			retain_until = EXCLUDED.retain_until,
+			legal_hold = EXCLUDED.legal_hold,
+			encryption = EXCLUDED.encryption,
+			key_version = EXCLUDED.key_version,
+			customer_key_md5 = EXCLUDED.customer_key_md5,
 			content_type = EXCLUDED.content_type,
 			created_at = EXCLUDED.created_at,
+			is_delete_marker = EXCLUDED.is_delete_marker,
 			deleted_at = NULL,
 			is_latest = EXCLUDED.is_latest,
 			user_id = EXCLUDED.user_id
 	`
-	_, err := r.db.Exec(ctx, query,
-		obj.ID, obj.UserID, obj.ARN, obj.Bucket, obj.Key, obj.VersionID, obj.IsLatest, obj.SizeBytes, obj.ContentType, obj.CreatedAt,
+	tags, err := marshalObjectTags(obj.Tags)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(ctx, query,
+		obj.ID, obj.UserID, obj.ARN, obj.Bucket, obj.Key, obj.VersionID, obj.IsLatest, obj.SizeBytes,
+		storageClassOrDefault(obj.StorageClass), obj.DataShards, obj.ParityShards, obj.StoredBytes, obj.ETag, obj.ChecksumSHA256,
+		aclOrDefault(obj.ACL), tags, string(obj.RetentionMode), obj.RetainUntil, obj.LegalHold,
+		string(obj.Encryption), obj.KeyVersion, obj.CustomerKeyMD5, obj.ContentType, obj.CreatedAt, obj.IsDeleteMarker,
 	)
 	if err != nil {
 		return errors.Wrap(errors.Internal, "failed to save object metadata", err)
@@ -53,19 +140,17 @@ func (r *StorageRepository) SaveMeta(ctx context.Context, obj *domain.Object) er
 }
 
 func (r *StorageRepository) GetMeta(ctx context.Context, bucket, key string) (*domain.Object, error) {
-	userID := appcontext.UserIDFromContext(ctx)
 	query := `
-		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, content_type, created_at, deleted_at
+		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, storage_class, data_shards, parity_shards, stored_bytes, etag, checksum_sha256, acl, tags, retention_mode, retain_until, legal_hold, encryption, key_version, customer_key_md5, content_type, created_at, deleted_at, is_delete_marker
 		FROM objects
-		WHERE bucket = $1 AND key = $2 AND deleted_at IS NULL AND user_id = $3 AND is_latest = TRUE
+		WHERE bucket = $1 AND key = $2 AND deleted_at IS NULL AND is_latest = TRUE AND NOT is_delete_marker
 	`
-	return r.scanObject(r.db.QueryRow(ctx, query, bucket, key, userID))
+	return r.scanObject(r.db.QueryRow(ctx, query, bucket, key))
 }
 
 func (r *StorageRepository) DeleteVersion(ctx context.Context, bucket, key, versionID string) error {
-	userID := appcontext.UserIDFromContext(ctx)
-	query := `DELETE FROM objects WHERE bucket = $1 AND key = $2 AND version_id = $3 AND user_id = $4`
-	cmd, err := r.db.Exec(ctx, query, bucket, key, versionID, userID)
+	query := `DELETE FROM objects WHERE bucket = $1 AND key = $2 AND version_id = $3`
+	cmd, err := r.db.Exec(ctx, query, bucket, key, versionID)
 	if err != nil {
 		return errors.Wrap(errors.Internal, "failed to delete object version", err)
 	}
@@ -75,40 +160,116 @@ func (r *StorageRepository) DeleteVersion(ctx context.Context, bucket, key, vers
 	return nil
 }
 
+// DeleteVersionIf permanently deletes a version only if it is still current, so a
+// version overwritten since its conditions were evaluated is kept.
+func (r *StorageRepository) DeleteVersionIf(ctx context.Context, bucket, key, versionID string, current *domain.Object) error {
+	query := `
+		DELETE FROM objects
+		WHERE bucket = $1 AND key = $2 AND version_id = $3 AND deleted_at IS NULL AND etag = $4 AND created_at = $5
+	`
+	cmd, err := r.db.Exec(ctx, query, bucket, key, versionID, current.ETag, current.CreatedAt)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete object version", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.PreconditionFailed, "precondition failed")
+	}
+	return nil
+}
+
 func (r *StorageRepository) GetMetaByVersion(ctx context.Context, bucket, key, versionID string) (*domain.Object, error) {
-	userID := appcontext.UserIDFromContext(ctx)
 	query := `
-		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, content_type, created_at, deleted_at
+		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, storage_class, data_shards, parity_shards, stored_bytes, etag, checksum_sha256, acl, tags, retention_mode, retain_until, legal_hold, encryption, key_version, customer_key_md5, content_type, created_at, deleted_at, is_delete_marker
 		FROM objects
-		WHERE bucket = $1 AND key = $2 AND version_id = $3 AND deleted_at IS NULL AND user_id = $4
+		WHERE bucket = $1 AND key = $2 AND version_id = $3 AND deleted_at IS NULL
 	`
-	return r.scanObject(r.db.QueryRow(ctx, query, bucket, key, versionID, userID))
+	return r.scanObject(r.db.QueryRow(ctx, query, bucket, key, versionID))
 }
 
-func (r *StorageRepository) List(ctx context.Context, bucket string) ([]*domain.Object, error) {
-	userID := appcontext.UserIDFromContext(ctx)
+// listSkipSuffix sorts after any realistic key continuation, so seeking past
+// prefix+listSkipSuffix skips every key grouped under a common prefix.
+const listSkipSuffix = string(utf8.MaxRune)
+
+func (r *StorageRepository) List(ctx context.Context, bucket string, opts domain.ObjectListOptions) (*domain.ObjectListResult, error) {
+	marker, err := opts.Marker()
+	if err != nil {
+		return nil, errors.New(errors.InvalidInput, err.Error())
+	}
+
+	limit := opts.Limit()
+	result := &domain.ObjectListResult{
+		Bucket:    bucket,
+		Prefix:    opts.Prefix,
+		Delimiter: opts.Delimiter,
+		MaxKeys:   limit,
+		Objects:   []*domain.Object{},
+	}
+
+	// Keys are compared bytewise (COLLATE "C") so that ordering matches the
+	// continuation tokens handed out to clients.
 	query := `
-		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, content_type, created_at, deleted_at
+		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, storage_class, data_shards, parity_shards, stored_bytes, etag, checksum_sha256, acl, tags, retention_mode, retain_until, legal_hold, encryption, key_version, customer_key_md5, content_type, created_at, deleted_at, is_delete_marker
 		FROM objects
-		WHERE bucket = $1 AND deleted_at IS NULL AND user_id = $2 AND is_latest = TRUE
-		ORDER BY created_at DESC
+		WHERE bucket = $1 AND deleted_at IS NULL AND is_latest = TRUE AND NOT is_delete_marker
+			AND starts_with(key, $2) AND key COLLATE "C" > $3
+		ORDER BY key COLLATE "C"
+		LIMIT $4
 	`
-	rows, err := r.db.Query(ctx, query, bucket, userID)
-	if err != nil {
-		return nil, errors.Wrap(errors.Internal, "failed to list objects", err)
+
+	last := marker
+	seek := marker
+	batch := limit + 1
+	for {
+		rows, err := r.db.Query(ctx, query, bucket, opts.Prefix, seek, batch)
+		if err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to list objects", err)
+		}
+		objects, err := r.scanObjects(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objects {
+			seek = obj.Key
+			entry := obj.Key
+			prefix := opts.CommonPrefix(obj.Key)
+			if prefix != "" {
+				seek = prefix + listSkipSuffix
+				if prefix == last {
+					continue
+				}
+				entry = prefix
+			}
+
+			if result.KeyCount == limit {
+				result.IsTruncated = true
+				result.NextContinuationToken = domain.EncodeContinuationToken(last)
+				return result, nil
+			}
+
+			if prefix != "" {
+				result.CommonPrefixes = append(result.CommonPrefixes, prefix)
+			} else {
+				result.Objects = append(result.Objects, obj)
+			}
+			result.KeyCount++
+			last = entry
+		}
+
+		if len(objects) < batch {
+			return result, nil
+		}
 	}
-	return r.scanObjects(rows)
 }
 
 func (r *StorageRepository) ListVersions(ctx context.Context, bucket, key string) ([]*domain.Object, error) {
-	userID := appcontext.UserIDFromContext(ctx)
 	query := `
-		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, content_type, created_at, deleted_at
+		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, storage_class, data_shards, parity_shards, stored_bytes, etag, checksum_sha256, acl, tags, retention_mode, retain_until, legal_hold, encryption, key_version, customer_key_md5, content_type, created_at, deleted_at, is_delete_marker
 		FROM objects
-		WHERE bucket = $1 AND key = $2 AND deleted_at IS NULL AND user_id = $3
+		WHERE bucket = $1 AND key = $2 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
-	rows, err := r.db.Query(ctx, query, bucket, key, userID)
+	rows, err := r.db.Query(ctx, query, bucket, key)
 	if err != nil {
 		return nil, errors.Wrap(errors.Internal, "failed to list versions", err)
 	}
@@ -117,9 +278,9 @@ func (r *StorageRepository) ListVersions(ctx context.Context, bucket, key string
 
 func (r *StorageRepository) ListDeleted(ctx context.Context, limit int) ([]*domain.Object, error) {
 	query := `
-		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, content_type, created_at, deleted_at
+		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, storage_class, data_shards, parity_shards, stored_bytes, etag, checksum_sha256, acl, tags, retention_mode, retain_until, legal_hold, encryption, key_version, customer_key_md5, content_type, created_at, deleted_at, is_delete_marker
 		FROM objects
-		WHERE deleted_at IS NOT NULL
+		WHERE deleted_at IS NOT NULL AND NOT legal_hold AND (retain_until IS NULL OR retain_until <= NOW())
 		LIMIT $1
 	`
 	rows, err := r.db.Query(ctx, query, limit)
@@ -129,6 +290,38 @@ func (r *StorageRepository) ListDeleted(ctx context.Context, limit int) ([]*doma
 	return r.scanObjects(rows)
 }
 
+// ListObjectsBelowKeyVersion returns SSE object versions sealed with a data key older than version.
+func (r *StorageRepository) ListObjectsBelowKeyVersion(ctx context.Context, bucket string, version, limit int) ([]*domain.Object, error) {
+	query := `
+		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, storage_class, data_shards, parity_shards, stored_bytes, etag, checksum_sha256, acl, tags, retention_mode, retain_until, legal_hold, encryption, key_version, customer_key_md5, content_type, created_at, deleted_at, is_delete_marker
+		FROM objects
+		WHERE bucket = $1 AND encryption = 'SSE' AND key_version < $2 AND deleted_at IS NULL
+		ORDER BY created_at
+		LIMIT $3
+	`
+	rows, err := r.db.Query(ctx, query, bucket, version, limit)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list objects to re-encrypt", err)
+	}
+	return r.scanObjects(rows)
+}
+
+// ListErasureObjects returns live erasure-coded object versions after afterID, in ID order.
+func (r *StorageRepository) ListErasureObjects(ctx context.Context, afterID uuid.UUID, limit int) ([]*domain.Object, error) {
+	query := `
+		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, storage_class, data_shards, parity_shards, stored_bytes, etag, checksum_sha256, acl, tags, retention_mode, retain_until, legal_hold, encryption, key_version, customer_key_md5, content_type, created_at, deleted_at, is_delete_marker
+		FROM objects
+		WHERE storage_class = $1 AND deleted_at IS NULL AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`
+	rows, err := r.db.Query(ctx, query, domain.StorageClassErasureCoded, afterID, limit)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list erasure-coded objects", err)
+	}
+	return r.scanObjects(rows)
+}
+
 func (r *StorageRepository) HardDelete(ctx context.Context, bucket, key, versionID string) error {
 	query := `DELETE FROM objects WHERE bucket = $1 AND key = $2 AND version_id = $3`
 	_, err := r.db.Exec(ctx, query, bucket, key, versionID)
@@ -140,8 +333,13 @@ func (r *StorageRepository) HardDelete(ctx context.Context, bucket, key, version
 
 func (r *StorageRepository) scanObject(row pgx.Row) (*domain.Object, error) {
 	var obj domain.Object
+	var storageClass, acl, retentionMode, encryption string
+	var tags []byte
 	err := row.Scan(
-		&obj.ID, &obj.UserID, &obj.ARN, &obj.Bucket, &obj.Key, &obj.VersionID, &obj.IsLatest, &obj.SizeBytes, &obj.ContentType, &obj.CreatedAt, &obj.DeletedAt,
+		&obj.ID, &obj.UserID, &obj.ARN, &obj.Bucket, &obj.Key, &obj.VersionID, &obj.IsLatest, &obj.SizeBytes,
+		&storageClass, &obj.DataShards, &obj.ParityShards, &obj.StoredBytes, &obj.ETag, &obj.ChecksumSHA256, &acl, &tags,
+		&retentionMode, &obj.RetainUntil, &obj.LegalHold, &encryption, &obj.KeyVersion, &obj.CustomerKeyMD5,
+		&obj.ContentType, &obj.CreatedAt, &obj.DeletedAt, &obj.IsDeleteMarker,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -149,6 +347,15 @@ func (r *StorageRepository) scanObject(row pgx.Row) (*domain.Object, error) {
 		}
 		return nil, errors.Wrap(errors.Internal, "failed to scan object metadata", err)
 	}
+	obj.StorageClass = domain.StorageClass(storageClass)
+	obj.ACL = domain.ObjectACL(acl)
+	obj.RetentionMode = domain.RetentionMode(retentionMode)
+	obj.Encryption = domain.ObjectEncryption(encryption)
+	if len(tags) > 0 {
+		if err := json.Unmarshal(tags, &obj.Tags); err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to decode object tags", err)
+		}
+	}
 	return &obj, nil
 }
 
@@ -166,13 +373,36 @@ func (r *StorageRepository) scanObjects(rows pgx.Rows) ([]*domain.Object, error)
 }
 
 func (r *StorageRepository) SoftDelete(ctx context.Context, bucket, key string) error {
-	userID := appcontext.UserIDFromContext(ctx)
+	return softDeleteObject(ctx, r.db, bucket, key)
+}
+
+// SoftDeleteIf soft deletes an object only if its latest version is still current.
+func (r *StorageRepository) SoftDeleteIf(ctx context.Context, bucket, key string, current *domain.Object) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to start transaction", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := lockLatestObject(ctx, tx, bucket, key, current); err != nil {
+		return err
+	}
+	if err := softDeleteObject(ctx, tx, bucket, key); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(errors.Internal, "failed to commit object delete", err)
+	}
+	return nil
+}
+
+func softDeleteObject(ctx context.Context, db objectExecer, bucket, key string) error {
 	query := `
 		UPDATE objects
 		SET deleted_at = $1
-		WHERE bucket = $2 AND key = $3 AND deleted_at IS NULL AND user_id = $4
+		WHERE bucket = $2 AND key = $3 AND deleted_at IS NULL
 	`
-	cmd, err := r.db.Exec(ctx, query, time.Now(), bucket, key, userID)
+	cmd, err := db.Exec(ctx, query, time.Now(), bucket, key)
 	if err != nil {
 		return errors.Wrap(errors.Internal, "failed to soft delete object", err)
 	}
@@ -185,10 +415,13 @@ func (r *StorageRepository) SoftDelete(ctx context.Context, bucket, key string)
 // CreateBucket creates a new bucket.
 func (r *StorageRepository) CreateBucket(ctx context.Context, bucket *domain.Bucket) error {
 	query := `
-		INSERT INTO buckets (id, name, user_id, is_public, versioning_enabled, encryption_enabled, encryption_key_id, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO buckets (id, name, user_id, is_public, versioning_enabled, encryption_enabled, encryption_key_id, storage_class, data_shards, parity_shards,
+			object_lock_enabled, default_retention_mode, default_retention_days, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
-	_, err := r.db.Exec(ctx, query, bucket.ID, bucket.Name, bucket.UserID, bucket.IsPublic, bucket.VersioningEnabled, bucket.EncryptionEnabled, bucket.EncryptionKeyID, bucket.CreatedAt)
+	_, err := r.db.Exec(ctx, query, bucket.ID, bucket.Name, bucket.UserID, bucket.IsPublic, bucket.VersioningEnabled, bucket.EncryptionEnabled, bucket.EncryptionKeyID,
+		storageClassOrDefault(bucket.StorageClass), bucket.DataShards, bucket.ParityShards,
+		bucket.ObjectLockEnabled, string(bucket.DefaultRetentionMode), bucket.DefaultRetentionDays, bucket.CreatedAt)
 	if err != nil {
 		return errors.Wrap(errors.Internal, "failed to create bucket", err)
 	}
@@ -198,14 +431,17 @@ func (r *StorageRepository) CreateBucket(ctx context.Context, bucket *domain.Buc
 // GetBucket retrieves a bucket by name.
 func (r *StorageRepository) GetBucket(ctx context.Context, name string) (*domain.Bucket, error) {
 	query := `
-		SELECT id, name, user_id, is_public, versioning_enabled, encryption_enabled, encryption_key_id, created_at
+		SELECT id, name, user_id, is_public, versioning_enabled, encryption_enabled, encryption_key_id, storage_class, data_shards, parity_shards,
+			object_lock_enabled, default_retention_mode, default_retention_days, created_at
 		FROM buckets
 		WHERE name = $1
 	`
 	var bucket domain.Bucket
+	var storageClass, retentionMode string
 	err := r.db.QueryRow(ctx, query, name).Scan(
 		&bucket.ID, &bucket.Name, &bucket.UserID, &bucket.IsPublic, &bucket.VersioningEnabled,
-		&bucket.EncryptionEnabled, &bucket.EncryptionKeyID, &bucket.CreatedAt,
+		&bucket.EncryptionEnabled, &bucket.EncryptionKeyID, &storageClass, &bucket.DataShards, &bucket.ParityShards,
+		&bucket.ObjectLockEnabled, &retentionMode, &bucket.DefaultRetentionDays, &bucket.CreatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -213,6 +449,8 @@ func (r *StorageRepository) GetBucket(ctx context.Context, name string) (*domain
 		}
 		return nil, errors.Wrap(errors.Internal, "failed to get bucket", err)
 	}
+	bucket.StorageClass = domain.StorageClass(storageClass)
+	bucket.DefaultRetentionMode = domain.RetentionMode(retentionMode)
 	return &bucket, nil
 }
 
@@ -225,6 +463,45 @@ func (r *StorageRepository) SetBucketVersioning(ctx context.Context, name string
 	return nil
 }
 
+// SetBucketEncryption updates the server-side encryption setting and current data key of a bucket.
+func (r *StorageRepository) SetBucketEncryption(ctx context.Context, name string, enabled bool, keyID string) error {
+	query := `UPDATE buckets SET encryption_enabled = $1, encryption_key_id = $2 WHERE name = $3`
+	cmd, err := r.db.Exec(ctx, query, enabled, keyID, name)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to set bucket encryption", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.ObjectNotFound, "bucket not found")
+	}
+	return nil
+}
+
+// SetBucketStorageClass updates the storage class and erasure layout of a bucket.
+func (r *StorageRepository) SetBucketStorageClass(ctx context.Context, name string, class domain.StorageClass, layout domain.ErasureLayout) error {
+	query := `UPDATE buckets SET storage_class = $1, data_shards = $2, parity_shards = $3 WHERE name = $4`
+	cmd, err := r.db.Exec(ctx, query, string(class), layout.DataShards, layout.ParityShards, name)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to set bucket storage class", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.ObjectNotFound, "bucket not found")
+	}
+	return nil
+}
+
+// SetBucketObjectLock updates the object lock setting and default retention of a bucket.
+func (r *StorageRepository) SetBucketObjectLock(ctx context.Context, name string, cfg domain.ObjectLockConfig) error {
+	query := `UPDATE buckets SET object_lock_enabled = $1, default_retention_mode = $2, default_retention_days = $3 WHERE name = $4`
+	cmd, err := r.db.Exec(ctx, query, cfg.Enabled, string(cfg.DefaultMode), cfg.DefaultDays, name)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to set bucket object lock", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.ObjectNotFound, "bucket not found")
+	}
+	return nil
+}
+
 // DeleteBucket deletes a bucket by name.
 func (r *StorageRepository) DeleteBucket(ctx context.Context, name string) error {
 	query := `DELETE FROM buckets WHERE name = $1`
@@ -238,10 +515,36 @@ func (r *StorageRepository) DeleteBucket(ctx context.Context, name string) error
 	return nil
 }
 
+// BucketHasObjects reports whether a bucket still holds any object version or delete marker.
+func (r *StorageRepository) BucketHasObjects(ctx context.Context, bucket string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM objects WHERE bucket = $1 AND deleted_at IS NULL)`
+	if err := r.db.QueryRow(ctx, query, bucket).Scan(&exists); err != nil {
+		return false, errors.Wrap(errors.Internal, "failed to check bucket objects", err)
+	}
+	return exists, nil
+}
+
+// ListLockedVersions returns versions of a bucket under a legal hold or retention, deleted ones
+// included, i.e. the rows ListDeleted leaves in place.
+func (r *StorageRepository) ListLockedVersions(ctx context.Context, bucket string, limit int) ([]*domain.Object, error) {
+	query := `
+		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, storage_class, data_shards, parity_shards, stored_bytes, etag, checksum_sha256, acl, tags, retention_mode, retain_until, legal_hold, encryption, key_version, customer_key_md5, content_type, created_at, deleted_at, is_delete_marker
+		FROM objects
+		WHERE bucket = $1 AND (legal_hold OR retain_until > NOW())
+		LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, bucket, limit)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list locked objects", err)
+	}
+	return r.scanObjects(rows)
+}
+
 // ListBuckets list buckets for a user.
 func (r *StorageRepository) ListBuckets(ctx context.Context, userID string) ([]*domain.Bucket, error) {
 	query := `
-		SELECT id, name, user_id, is_public, versioning_enabled, created_at
+		SELECT id, name, user_id, is_public, versioning_enabled, storage_class, data_shards, parity_shards, object_lock_enabled, created_at
 		FROM buckets
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -255,9 +558,11 @@ func (r *StorageRepository) ListBuckets(ctx context.Context, userID string) ([]*
 	var buckets []*domain.Bucket
 	for rows.Next() {
 		var b domain.Bucket
-		if err := rows.Scan(&b.ID, &b.Name, &b.UserID, &b.IsPublic, &b.VersioningEnabled, &b.CreatedAt); err != nil {
+		var storageClass string
+		if err := rows.Scan(&b.ID, &b.Name, &b.UserID, &b.IsPublic, &b.VersioningEnabled, &storageClass, &b.DataShards, &b.ParityShards, &b.ObjectLockEnabled, &b.CreatedAt); err != nil {
 			return nil, err
 		}
+		b.StorageClass = domain.StorageClass(storageClass)
 		buckets = append(buckets, &b)
 	}
 	return buckets, nil
@@ -308,3 +613,389 @@ func (r *StorageRepository) ListParts(ctx context.Context, uploadID uuid.UUID) (
 	}
 	return parts, nil
 }
+
+// GetBucketPolicy returns the policy attached to a bucket.
+func (r *StorageRepository) GetBucketPolicy(ctx context.Context, bucket string) (*domain.BucketPolicy, error) {
+	query := `SELECT bucket, statements, updated_at FROM bucket_policies WHERE bucket = $1`
+	var policy domain.BucketPolicy
+	var statementsJSON []byte
+	err := r.db.QueryRow(ctx, query, bucket).Scan(&policy.Bucket, &statementsJSON, &policy.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.NotFound, "bucket policy not found")
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to get bucket policy", err)
+	}
+	if err := json.Unmarshal(statementsJSON, &policy.Statements); err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to unmarshal bucket policy", err)
+	}
+	return &policy, nil
+}
+
+// PutBucketPolicy creates or replaces the policy attached to a bucket.
+func (r *StorageRepository) PutBucketPolicy(ctx context.Context, policy *domain.BucketPolicy) error {
+	statementsJSON, err := json.Marshal(policy.Statements)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to marshal bucket policy", err)
+	}
+	query := `
+		INSERT INTO bucket_policies (bucket, statements, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (bucket) DO UPDATE SET statements = EXCLUDED.statements, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := r.db.Exec(ctx, query, policy.Bucket, statementsJSON, policy.UpdatedAt); err != nil {
+		return errors.Wrap(errors.Internal, "failed to save bucket policy", err)
+	}
+	return nil
+}
+
+// DeleteBucketPolicy removes the policy attached to a bucket.
+func (r *StorageRepository) DeleteBucketPolicy(ctx context.Context, bucket string) error {
+	cmd, err := r.db.Exec(ctx, `DELETE FROM bucket_policies WHERE bucket = $1`, bucket)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete bucket policy", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "bucket policy not found")
+	}
+	return nil
+}
+
+// GetBucketNotifications returns the notification configuration of a bucket.
+func (r *StorageRepository) GetBucketNotifications(ctx context.Context, bucket string) (*domain.BucketNotificationConfig, error) {
+	query := `SELECT bucket, rules, updated_at FROM bucket_notifications WHERE bucket = $1`
+	var cfg domain.BucketNotificationConfig
+	var rulesJSON []byte
+	err := r.db.QueryRow(ctx, query, bucket).Scan(&cfg.Bucket, &rulesJSON, &cfg.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.NotFound, "bucket notification configuration not found")
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to get bucket notifications", err)
+	}
+	if err := json.Unmarshal(rulesJSON, &cfg.Rules); err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to unmarshal bucket notifications", err)
+	}
+	return &cfg, nil
+}
+
+// PutBucketNotifications creates or replaces the notification configuration of a bucket.
+func (r *StorageRepository) PutBucketNotifications(ctx context.Context, cfg *domain.BucketNotificationConfig) error {
+	rulesJSON, err := json.Marshal(cfg.Rules)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to marshal bucket notifications", err)
+	}
+	query := `
+		INSERT INTO bucket_notifications (bucket, rules, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (bucket) DO UPDATE SET rules = EXCLUDED.rules, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := r.db.Exec(ctx, query, cfg.Bucket, rulesJSON, cfg.UpdatedAt); err != nil {
+		return errors.Wrap(errors.Internal, "failed to save bucket notifications", err)
+	}
+	return nil
+}
+
+// DeleteBucketNotifications removes the notification configuration of a bucket.
+func (r *StorageRepository) DeleteBucketNotifications(ctx context.Context, bucket string) error {
+	cmd, err := r.db.Exec(ctx, `DELETE FROM bucket_notifications WHERE bucket = $1`, bucket)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete bucket notifications", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "bucket notification configuration not found")
+	}
+	return nil
+}
+
+// SetObjectACL changes the canned ACL of an object version.
+func (r *StorageRepository) SetObjectACL(ctx context.Context, bucket, key, versionID string, acl domain.ObjectACL) error {
+	query := `UPDATE objects SET acl = $1 WHERE bucket = $2 AND key = $3 AND version_id = $4 AND deleted_at IS NULL`
+	cmd, err := r.db.Exec(ctx, query, string(acl), bucket, key, versionID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to set object acl", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.ObjectNotFound, "object not found")
+	}
+	return nil
+}
+
+// SetObjectTags replaces the tags of an object version.
+func (r *StorageRepository) SetObjectTags(ctx context.Context, bucket, key, versionID string, tags map[string]string) error {
+	tagsJSON, err := marshalObjectTags(tags)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE objects SET tags = $1 WHERE bucket = $2 AND key = $3 AND version_id = $4 AND deleted_at IS NULL`
+	cmd, err := r.db.Exec(ctx, query, tagsJSON, bucket, key, versionID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to set object tags", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.ObjectNotFound, "object not found")
+	}
+	return nil
+}
+
+// SetObjectRetention replaces the retention of an object version. A nil retention removes it.
+func (r *StorageRepository) SetObjectRetention(ctx context.Context, bucket, key, versionID string, retention *domain.ObjectRetention) error {
+	var mode string
+	var until *time.Time
+	if retention != nil {
+		mode, until = string(retention.Mode), &retention.RetainUntil
+	}
+	query := `UPDATE objects SET retention_mode = $1, retain_until = $2 WHERE bucket = $3 AND key = $4 AND version_id = $5 AND deleted_at IS NULL`
+	cmd, err := r.db.Exec(ctx, query, mode, until, bucket, key, versionID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to set object retention", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.ObjectNotFound, "object not found")
+	}
+	return nil
+}
+
+// SetObjectLegalHold places or releases a legal hold on an object version.
+func (r *StorageRepository) SetObjectLegalHold(ctx context.Context, bucket, key, versionID string, on bool) error {
+	query := `UPDATE objects SET legal_hold = $1 WHERE bucket = $2 AND key = $3 AND version_id = $4 AND deleted_at IS NULL`
+	cmd, err := r.db.Exec(ctx, query, on, bucket, key, versionID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to set object legal hold", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.ObjectNotFound, "object not found")
+	}
+	return nil
+}
+
+// GetBucketReplication returns the replication configuration of a bucket.
+func (r *StorageRepository) GetBucketReplication(ctx context.Context, bucket string) (*domain.BucketReplicationConfig, error) {
+	query := `SELECT bucket, rules, updated_at FROM bucket_replication WHERE bucket = $1`
+	var cfg domain.BucketReplicationConfig
+	var rulesJSON []byte
+	err := r.db.QueryRow(ctx, query, bucket).Scan(&cfg.Bucket, &rulesJSON, &cfg.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.NotFound, "bucket replication configuration not found")
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to get bucket replication", err)
+	}
+	if err := json.Unmarshal(rulesJSON, &cfg.Rules); err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to unmarshal bucket replication", err)
+	}
+	return &cfg, nil
+}
+
+// PutBucketReplication creates or replaces the replication configuration of a bucket.
+func (r *StorageRepository) PutBucketReplication(ctx context.Context, cfg *domain.BucketReplicationConfig) error {
+	rulesJSON, err := json.Marshal(cfg.Rules)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to marshal bucket replication", err)
+	}
+	query := `
+		INSERT INTO bucket_replication (bucket, rules, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (bucket) DO UPDATE SET rules = EXCLUDED.rules, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := r.db.Exec(ctx, query, cfg.Bucket, rulesJSON, cfg.UpdatedAt); err != nil {
+		return errors.Wrap(errors.Internal, "failed to save bucket replication", err)
+	}
+	return nil
+}
+
+// DeleteBucketReplication removes the replication configuration of a bucket.
+func (r *StorageRepository) DeleteBucketReplication(ctx context.Context, bucket string) error {
+	cmd, err := r.db.Exec(ctx, `DELETE FROM bucket_replication WHERE bucket = $1`, bucket)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete bucket replication", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "bucket replication configuration not found")
+	}
+	return nil
+}
+
+// EnqueueReplication records pending replications. Re-enqueuing a change (an overwritten
+// unversioned object, or a key deleted again) restarts it from scratch.
+func (r *StorageRepository) EnqueueReplication(ctx context.Context, reps []*domain.ObjectReplication) error {
+	query := `
+		INSERT INTO object_replication (bucket, key, version_id, rule_id, operation, status, attempts, last_error, next_attempt_at, created_at, replicated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 0, '', $7, $8, NULL)
+		ON CONFLICT (bucket, key, version_id, rule_id) DO UPDATE SET
+			operation = EXCLUDED.operation,
+			status = EXCLUDED.status,
+			attempts = 0,
+			last_error = '',
+			next_attempt_at = EXCLUDED.next_attempt_at,
+			created_at = EXCLUDED.created_at,
+			replicated_at = NULL
+	`
+	for _, rep := range reps {
+		_, err := r.db.Exec(ctx, query, rep.Bucket, rep.Key, rep.VersionID, rep.RuleID, string(rep.Operation),
+			string(rep.Status), rep.NextAttemptAt, rep.CreatedAt)
+		if err != nil {
+			return errors.Wrap(errors.Internal, "failed to enqueue replication", err)
+		}
+	}
+	return nil
+}
+
+const replicationColumns = `bucket, key, version_id, rule_id, operation, status, attempts, last_error, next_attempt_at, created_at, replicated_at`
+
+// ListPendingReplication returns due pending replications in the order the changes were made.
+func (r *StorageRepository) ListPendingReplication(ctx context.Context, limit int) ([]*domain.ObjectReplication, error) {
+	query := `SELECT ` + replicationColumns + ` FROM object_replication
+		WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+		ORDER BY created_at
+		LIMIT $1`
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list pending replication", err)
+	}
+	return scanObjectReplications(rows)
+}
+
+// UpdateReplication saves the status of a replication after an attempt.
+func (r *StorageRepository) UpdateReplication(ctx context.Context, rep *domain.ObjectReplication) error {
+	query := `
+		UPDATE object_replication
+		SET status = $1, attempts = $2, last_error = $3, next_attempt_at = $4, replicated_at = $5
+		WHERE bucket = $6 AND key = $7 AND version_id = $8 AND rule_id = $9
+	`
+	_, err := r.db.Exec(ctx, query, string(rep.Status), rep.Attempts, rep.LastError, rep.NextAttemptAt, rep.ReplicatedAt,
+		rep.Bucket, rep.Key, rep.VersionID, rep.RuleID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to update replication", err)
+	}
+	return nil
+}
+
+// ListObjectReplication returns the replication state of a key, newest change first.
+func (r *StorageRepository) ListObjectReplication(ctx context.Context, bucket, key string) ([]*domain.ObjectReplication, error) {
+	query := `SELECT ` + replicationColumns + ` FROM object_replication
+		WHERE bucket = $1 AND key = $2
+		ORDER BY created_at DESC, rule_id`
+	rows, err := r.db.Query(ctx, query, bucket, key)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list object replication", err)
+	}
+	return scanObjectReplications(rows)
+}
+
+// ListReplicationBacklog counts pending and failed replications per bucket.
+func (r *StorageRepository) ListReplicationBacklog(ctx context.Context, bucket string) ([]*domain.ReplicationBacklog, error) {
+	query := `
+		SELECT bucket,
+			COUNT(*) FILTER (WHERE status = 'PENDING'),
+			COUNT(*) FILTER (WHERE status = 'FAILED'),
+			MIN(created_at) FILTER (WHERE status = 'PENDING')
+		FROM object_replication
+		WHERE status <> 'COMPLETED' AND ($1 = '' OR bucket = $1)
+		GROUP BY bucket
+		ORDER BY bucket
+	`
+	rows, err := r.db.Query(ctx, query, bucket)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list replication backlog", err)
+	}
+	defer rows.Close()
+
+	var backlogs []*domain.ReplicationBacklog
+	for rows.Next() {
+		var b domain.ReplicationBacklog
+		if err := rows.Scan(&b.Bucket, &b.Pending, &b.Failed, &b.OldestPending); err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to scan replication backlog", err)
+		}
+		backlogs = append(backlogs, &b)
+	}
+	return backlogs, nil
+}
+
+func scanObjectReplications(rows pgx.Rows) ([]*domain.ObjectReplication, error) {
+	defer rows.Close()
+	var reps []*domain.ObjectReplication
+	for rows.Next() {
+		var rep domain.ObjectReplication
+		var operation, status string
+		err := rows.Scan(&rep.Bucket, &rep.Key, &rep.VersionID, &rep.RuleID, &operation, &status, &rep.Attempts,
+			&rep.LastError, &rep.NextAttemptAt, &rep.CreatedAt, &rep.ReplicatedAt)
+		if err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to scan object replication", err)
+		}
+		rep.Operation = domain.ReplicationOperation(operation)
+		rep.Status = domain.ReplicationStatus(status)
+		reps = append(reps, &rep)
+	}
+	return reps, nil
+}
+
+// ListVersionsPage returns every live version of up to maxKeys keys under prefix that sort
+// after startAfter, ordered by key and then newest first.
+func (r *StorageRepository) ListVersionsPage(ctx context.Context, bucket, prefix, startAfter string, maxKeys int) ([]*domain.Object, error) {
+	query := `
+		SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, storage_class, data_shards, parity_shards, stored_bytes, etag, checksum_sha256, acl, tags, retention_mode, retain_until, legal_hold, encryption, key_version, customer_key_md5, content_type, created_at, deleted_at, is_delete_marker
+		FROM objects
+		WHERE bucket = $1 AND deleted_at IS NULL AND key IN (
+			SELECT DISTINCT key COLLATE "C" FROM objects
+			WHERE bucket = $1 AND deleted_at IS NULL AND starts_with(key, $2) AND key COLLATE "C" > $3
+			ORDER BY key COLLATE "C"
+			LIMIT $4
+		)
+		ORDER BY key COLLATE "C", created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, bucket, prefix, startAfter, maxKeys)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list object versions", err)
+	}
+	return r.scanObjects(rows)
+}
+
+// ListMultipartUploads returns the uploads under prefix in a bucket started before initiatedBefore.
+func (r *StorageRepository) ListMultipartUploads(ctx context.Context, bucket, prefix string, initiatedBefore time.Time) ([]*domain.MultipartUpload, error) {
+	query := `
+		SELECT id, user_id, bucket, key, created_at FROM multipart_uploads
+		WHERE bucket = $1 AND starts_with(key, $2) AND created_at < $3
+		ORDER BY created_at
+	`
+	rows, err := r.db.Query(ctx, query, bucket, prefix, initiatedBefore)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list multipart uploads", err)
+	}
+	defer rows.Close()
+
+	var uploads []*domain.MultipartUpload
+	for rows.Next() {
+		var u domain.MultipartUpload
+		if err := rows.Scan(&u.ID, &u.UserID, &u.Bucket, &u.Key, &u.CreatedAt); err != nil {
+			return nil, errors.Wrap(errors.Internal, "failed to scan multipart upload", err)
+		}
+		uploads = append(uploads, &u)
+	}
+	return uploads, rows.Err()
+}
+
+func marshalObjectTags(tags map[string]string) ([]byte, error) {
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to encode object tags", err)
+	}
+	return data, nil
+}
+
+func aclOrDefault(acl domain.ObjectACL) string {
+	if acl == "" {
+		return string(domain.ACLPrivate)
+	}
+	return string(acl)
+}
+
+func storageClassOrDefault(class domain.StorageClass) string {
+	if class == "" {
+		return string(domain.StorageClassStandard)
+	}
+	return string(class)
+}
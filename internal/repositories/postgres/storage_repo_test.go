@@ -34,7 +34,7 @@ func TestStorageRepository_SaveMeta(t *testing.T) {
 		}
 
 		mock.ExpectExec("INSERT INTO objects").
-			WithArgs(obj.ID, obj.UserID, obj.ARN, obj.Bucket, obj.Key, obj.VersionID, obj.IsLatest, obj.SizeBytes, obj.ContentType, obj.CreatedAt).
+			WithArgs(obj.ID, obj.UserID, obj.ARN, obj.Bucket, obj.Key, obj.VersionID, obj.IsLatest, obj.SizeBytes, "STANDARD", obj.DataShards, obj.ParityShards, obj.StoredBytes, obj.ETag, obj.ChecksumSHA256, "private", []byte("{}"), "", obj.RetainUntil, false, "", 0, "", obj.ContentType, obj.CreatedAt, false).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		err = repo.SaveMeta(context.Background(), obj)
@@ -59,6 +59,63 @@ func TestStorageRepository_SaveMeta(t *testing.T) {
 	})
 }
 
+func TestStorageRepository_SaveMetaIf(t *testing.T) {
+	createdAt := time.Now()
+	current := &domain.Object{Bucket: "mybucket", Key: "mykey", ETag: "abc", CreatedAt: createdAt}
+	obj := &domain.Object{ID: uuid.New(), Bucket: "mybucket", Key: "mykey", VersionID: "null", IsLatest: true, CreatedAt: time.Now()}
+
+	t.Run("current version unchanged", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").WithArgs("mybucket/mykey").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectQuery("SELECT etag, created_at FROM objects .* FOR UPDATE").WithArgs("mybucket", "mykey").
+			WillReturnRows(pgxmock.NewRows([]string{"etag", "created_at"}).AddRow("abc", createdAt))
+		mock.ExpectExec("UPDATE objects SET is_latest = FALSE").WithArgs("mybucket", "mykey").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("INSERT INTO objects").
+			WithArgs(obj.ID, obj.UserID, obj.ARN, obj.Bucket, obj.Key, obj.VersionID, obj.IsLatest, obj.SizeBytes, "STANDARD", obj.DataShards, obj.ParityShards, obj.StoredBytes, obj.ETag, obj.ChecksumSHA256, "private", []byte("{}"), "", obj.RetainUntil, false, "", 0, "", obj.ContentType, obj.CreatedAt, false).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, NewStorageRepository(mock).SaveMetaIf(context.Background(), obj, current))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("current version replaced", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").WithArgs("mybucket/mykey").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectQuery("SELECT etag, created_at FROM objects").WithArgs("mybucket", "mykey").
+			WillReturnRows(pgxmock.NewRows([]string{"etag", "created_at"}).AddRow("def", time.Now()))
+		mock.ExpectRollback()
+
+		err = NewStorageRepository(mock).SaveMetaIf(context.Background(), obj, current)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.PreconditionFailed))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("key created meanwhile", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").WithArgs("mybucket/mykey").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectQuery("SELECT etag, created_at FROM objects").WithArgs("mybucket", "mykey").
+			WillReturnRows(pgxmock.NewRows([]string{"etag", "created_at"}).AddRow("abc", createdAt))
+		mock.ExpectRollback()
+
+		err = NewStorageRepository(mock).SaveMetaIf(context.Background(), obj, nil)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.PreconditionFailed))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestStorageRepository_GetMeta(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mock, err := pgxmock.NewPool()
@@ -71,10 +128,10 @@ func TestStorageRepository_GetMeta(t *testing.T) {
 		ctx := appcontext.WithUserID(context.Background(), userID)
 		now := time.Now()
 
-		mock.ExpectQuery("SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, content_type, created_at, deleted_at FROM objects").
-			WithArgs("mybucket", "mykey", userID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "arn", "bucket", "key", "version_id", "is_latest", "size_bytes", "content_type", "created_at", "deleted_at"}).
-				AddRow(id, userID, "arn", "mybucket", "mykey", "v1", true, int64(1024), "text/plain", now, nil))
+		mock.ExpectQuery("SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, storage_class, data_shards, parity_shards, stored_bytes, etag, checksum_sha256, acl, tags, retention_mode, retain_until, legal_hold, encryption, key_version, customer_key_md5, content_type, created_at, deleted_at, is_delete_marker FROM objects").
+			WithArgs("mybucket", "mykey").
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "arn", "bucket", "key", "version_id", "is_latest", "size_bytes", "storage_class", "data_shards", "parity_shards", "stored_bytes", "etag", "checksum_sha256", "acl", "tags", "retention_mode", "retain_until", "legal_hold", "encryption", "key_version", "customer_key_md5", "content_type", "created_at", "deleted_at", "is_delete_marker"}).
+				AddRow(id, userID, "arn", "mybucket", "mykey", "v1", true, int64(1024), "STANDARD", 0, 0, int64(1024), "etag", "", "private", []byte("{}"), "", nil, false, "", 0, "", "text/plain", now, nil, false))
 
 		obj, err := repo.GetMeta(ctx, "mybucket", "mykey")
 		assert.NoError(t, err)
@@ -92,7 +149,7 @@ func TestStorageRepository_GetMeta(t *testing.T) {
 		ctx := appcontext.WithUserID(context.Background(), userID)
 
 		mock.ExpectQuery("SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, content_type, created_at FROM objects").
-			WithArgs("mybucket", "mykey", userID).
+			WithArgs("mybucket", "mykey").
 			WillReturnError(pgx.ErrNoRows)
 
 		obj, err := repo.GetMeta(ctx, "mybucket", "mykey")
@@ -114,7 +171,7 @@ func TestStorageRepository_GetMeta(t *testing.T) {
 		ctx := appcontext.WithUserID(context.Background(), userID)
 
 		mock.ExpectQuery("SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, content_type, created_at FROM objects").
-			WithArgs("mybucket", "mykey", userID).
+			WithArgs("mybucket", "mykey").
 			WillReturnError(errors.New("db error"))
 
 		obj, err := repo.GetMeta(ctx, "mybucket", "mykey")
@@ -123,7 +180,19 @@ func TestStorageRepository_GetMeta(t *testing.T) {
 	})
 }
 
+var objectColumns = []string{"id", "user_id", "arn", "bucket", "key", "version_id", "is_latest", "size_bytes", "storage_class", "data_shards", "parity_shards", "stored_bytes", "etag", "checksum_sha256", "acl", "tags", "retention_mode", "retain_until", "legal_hold", "encryption", "key_version", "customer_key_md5", "content_type", "created_at", "deleted_at", "is_delete_marker"}
+
+func objectRows(userID uuid.UUID, keys ...string) *pgxmock.Rows {
+	rows := pgxmock.NewRows(objectColumns)
+	for _, key := range keys {
+		rows.AddRow(uuid.New(), userID, "arn", "mybucket", key, "v1", true, int64(1024), "STANDARD", 0, 0, int64(1024), "etag", "", "private", []byte("{}"), "", nil, false, "", 0, "", "text/plain", time.Now(), nil, false)
+	}
+	return rows
+}
+
 func TestStorageRepository_List(t *testing.T) {
+	const listQuery = "SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, storage_class, data_shards, parity_shards, stored_bytes, etag, checksum_sha256, acl, tags, retention_mode, retain_until, legal_hold, encryption, key_version, customer_key_md5, content_type, created_at, deleted_at, is_delete_marker FROM objects"
+
 	t.Run("success", func(t *testing.T) {
 		mock, err := pgxmock.NewPool()
 		assert.NoError(t, err)
@@ -132,16 +201,94 @@ func TestStorageRepository_List(t *testing.T) {
 		repo := NewStorageRepository(mock)
 		userID := uuid.New()
 		ctx := appcontext.WithUserID(context.Background(), userID)
-		now := time.Now()
 
-		mock.ExpectQuery("SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, content_type, created_at, deleted_at FROM objects").
-			WithArgs("mybucket", userID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "arn", "bucket", "key", "version_id", "is_latest", "size_bytes", "content_type", "created_at", "deleted_at"}).
-				AddRow(uuid.New(), userID, "arn", "mybucket", "mykey", "v1", true, int64(1024), "text/plain", now, nil))
+		mock.ExpectQuery(listQuery).
+			WithArgs("mybucket", "", "", domain.DefaultMaxKeys+1).
+			WillReturnRows(objectRows(userID, "mykey"))
+
+		res, err := repo.List(ctx, "mybucket", domain.ObjectListOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, res.Objects, 1)
+		assert.False(t, res.IsTruncated)
+		assert.Equal(t, 1, res.KeyCount)
+	})
+
+	t.Run("truncated page", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		userID := uuid.New()
+		ctx := appcontext.WithUserID(context.Background(), userID)
+
+		mock.ExpectQuery(listQuery).
+			WithArgs("mybucket", "logs/", "logs/a", 3).
+			WillReturnRows(objectRows(userID, "logs/b", "logs/c", "logs/d"))
+
+		res, err := repo.List(ctx, "mybucket", domain.ObjectListOptions{Prefix: "logs/", StartAfter: "logs/a", MaxKeys: 2})
+		assert.NoError(t, err)
+		assert.Len(t, res.Objects, 2)
+		assert.True(t, res.IsTruncated)
+		assert.Equal(t, domain.EncodeContinuationToken("logs/c"), res.NextContinuationToken)
+	})
+
+	t.Run("delimiter groups common prefixes", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		userID := uuid.New()
+		ctx := appcontext.WithUserID(context.Background(), userID)
+
+		// The first batch fills up inside "a/", so the next query seeks past the whole prefix.
+		mock.ExpectQuery(listQuery).
+			WithArgs("mybucket", "", "", 3).
+			WillReturnRows(objectRows(userID, "a/1", "a/2", "a/3"))
+		mock.ExpectQuery(listQuery).
+			WithArgs("mybucket", "", "a/"+listSkipSuffix, 3).
+			WillReturnRows(objectRows(userID, "b.txt", "c/1"))
+
+		res, err := repo.List(ctx, "mybucket", domain.ObjectListOptions{Delimiter: "/", MaxKeys: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a/"}, res.CommonPrefixes)
+		assert.Len(t, res.Objects, 1)
+		assert.Equal(t, "b.txt", res.Objects[0].Key)
+		assert.True(t, res.IsTruncated)
+		assert.Equal(t, domain.EncodeContinuationToken("b.txt"), res.NextContinuationToken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("continuation skips returned prefix", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		userID := uuid.New()
+		ctx := appcontext.WithUserID(context.Background(), userID)
+
+		mock.ExpectQuery(listQuery).
+			WithArgs("mybucket", "", "a/", domain.DefaultMaxKeys+1).
+			WillReturnRows(objectRows(userID, "a/1", "a/2", "b.txt"))
+
+		token := domain.EncodeContinuationToken("a/")
+		res, err := repo.List(ctx, "mybucket", domain.ObjectListOptions{Delimiter: "/", ContinuationToken: token})
+		assert.NoError(t, err)
+		assert.Empty(t, res.CommonPrefixes)
+		assert.Len(t, res.Objects, 1)
+		assert.False(t, res.IsTruncated)
+	})
 
-		objects, err := repo.List(ctx, "mybucket")
+	t.Run("invalid token", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
 		assert.NoError(t, err)
-		assert.Len(t, objects, 1)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		_, err = repo.List(context.Background(), "mybucket", domain.ObjectListOptions{ContinuationToken: "!!"})
+		assert.True(t, theclouderrors.Is(err, theclouderrors.InvalidInput))
 	})
 
 	t.Run("db error", func(t *testing.T) {
@@ -153,13 +300,12 @@ func TestStorageRepository_List(t *testing.T) {
 		userID := uuid.New()
 		ctx := appcontext.WithUserID(context.Background(), userID)
 
-		mock.ExpectQuery("SELECT id, user_id, arn, bucket, key, version_id, is_latest, size_bytes, content_type, created_at FROM objects").
-			WithArgs("mybucket", userID).
+		mock.ExpectQuery(listQuery).
 			WillReturnError(errors.New("db error"))
 
-		objects, err := repo.List(ctx, "mybucket")
+		res, err := repo.List(ctx, "mybucket", domain.ObjectListOptions{})
 		assert.Error(t, err)
-		assert.Nil(t, objects)
+		assert.Nil(t, res)
 	})
 }
 
@@ -175,8 +321,8 @@ func TestStorageRepository_SoftDelete(t *testing.T) {
 		bucket := "mybucket"
 		key := "mykey"
 
-		mock.ExpectExec("UPDATE objects SET deleted_at = \\$1 WHERE bucket = \\$2 AND key = \\$3 AND deleted_at IS NULL AND user_id = \\$4").
-			WithArgs(pgxmock.AnyArg(), bucket, key, userID).
+		mock.ExpectExec("UPDATE objects SET deleted_at = \\$1 WHERE bucket = \\$2 AND key = \\$3 AND deleted_at IS NULL").
+			WithArgs(pgxmock.AnyArg(), bucket, key).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
 		err = repo.SoftDelete(ctx, bucket, key)
@@ -194,8 +340,8 @@ func TestStorageRepository_SoftDelete(t *testing.T) {
 		bucket := "mybucket"
 		key := "mykey"
 
-		mock.ExpectExec("UPDATE objects SET deleted_at = \\$1 WHERE bucket = \\$2 AND key = \\$3 AND deleted_at IS NULL AND user_id = \\$4").
-			WithArgs(pgxmock.AnyArg(), bucket, key, userID).
+		mock.ExpectExec("UPDATE objects SET deleted_at = \\$1 WHERE bucket = \\$2 AND key = \\$3 AND deleted_at IS NULL").
+			WithArgs(pgxmock.AnyArg(), bucket, key).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
 
 		err = repo.SoftDelete(ctx, bucket, key)
@@ -217,11 +363,480 @@ func TestStorageRepository_SoftDelete(t *testing.T) {
 		bucket := "mybucket"
 		key := "mykey"
 
-		mock.ExpectExec("UPDATE objects SET deleted_at = \\$1 WHERE bucket = \\$2 AND key = \\$3 AND deleted_at IS NULL AND user_id = \\$4").
-			WithArgs(pgxmock.AnyArg(), bucket, key, userID).
+		mock.ExpectExec("UPDATE objects SET deleted_at = \\$1 WHERE bucket = \\$2 AND key = \\$3 AND deleted_at IS NULL").
+			WithArgs(pgxmock.AnyArg(), bucket, key).
 			WillReturnError(errors.New("db error"))
 
 		err = repo.SoftDelete(ctx, bucket, key)
 		assert.Error(t, err)
 	})
 }
+
+func TestStorageRepository_SoftDeleteIf(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").WithArgs("mybucket/mykey").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery("SELECT etag, created_at FROM objects").WithArgs("mybucket", "mykey").
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	err = NewStorageRepository(mock).SoftDeleteIf(context.Background(), "mybucket", "mykey", &domain.Object{ETag: "abc"})
+	assert.True(t, theclouderrors.Is(err, theclouderrors.PreconditionFailed))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorageRepository_DeleteVersionIf(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	version := &domain.Object{ETag: "abc", CreatedAt: time.Now()}
+	mock.ExpectExec("DELETE FROM objects WHERE .* AND etag = \\$4 AND created_at = \\$5").
+		WithArgs("mybucket", "mykey", "v1", "abc", version.CreatedAt).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	err = NewStorageRepository(mock).DeleteVersionIf(context.Background(), "mybucket", "mykey", "v1", version)
+	assert.True(t, theclouderrors.Is(err, theclouderrors.PreconditionFailed))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorageRepository_SetBucketStorageClass(t *testing.T) {
+	layout := domain.ErasureLayout{DataShards: 4, ParityShards: 2}
+
+	t.Run("success", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		mock.ExpectExec("UPDATE buckets SET storage_class = \\$1, data_shards = \\$2, parity_shards = \\$3 WHERE name = \\$4").
+			WithArgs("ERASURE_CODED", 4, 2, "archive").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.SetBucketStorageClass(context.Background(), "archive", domain.StorageClassErasureCoded, layout)
+		assert.NoError(t, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		mock.ExpectExec("UPDATE buckets SET storage_class").
+			WithArgs("ERASURE_CODED", 4, 2, "missing").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		err = repo.SetBucketStorageClass(context.Background(), "missing", domain.StorageClassErasureCoded, layout)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.ObjectNotFound))
+	})
+
+	t.Run("db error", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		mock.ExpectExec("UPDATE buckets SET storage_class").
+			WillReturnError(errors.New("db error"))
+
+		err = repo.SetBucketStorageClass(context.Background(), "archive", domain.StorageClassErasureCoded, layout)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorageRepository_BucketPolicy(t *testing.T) {
+	statements := []domain.BucketPolicyStatement{{
+		Statement: domain.Statement{Effect: domain.EffectAllow, Action: []string{domain.StorageActionGetObject}, Resource: []string{"public/*"}},
+		Principal: []string{domain.PrincipalAll},
+	}}
+
+	t.Run("put", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		now := time.Now()
+		mock.ExpectExec("INSERT INTO bucket_policies").
+			WithArgs("b1", pgxmock.AnyArg(), now).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		err = repo.PutBucketPolicy(context.Background(), &domain.BucketPolicy{Bucket: "b1", Statements: statements, UpdatedAt: now})
+		assert.NoError(t, err)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		now := time.Now()
+		mock.ExpectQuery("SELECT bucket, statements, updated_at FROM bucket_policies").
+			WithArgs("b1").
+			WillReturnRows(pgxmock.NewRows([]string{"bucket", "statements", "updated_at"}).
+				AddRow("b1", []byte(`[{"effect":"Allow","action":["storage:GetObject"],"resource":["public/*"],"principal":["*"]}]`), now))
+
+		policy, err := repo.GetBucketPolicy(context.Background(), "b1")
+		assert.NoError(t, err)
+		assert.Equal(t, statements, policy.Statements)
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		mock.ExpectQuery("SELECT bucket, statements, updated_at FROM bucket_policies").
+			WithArgs("b1").
+			WillReturnError(pgx.ErrNoRows)
+
+		_, err = repo.GetBucketPolicy(context.Background(), "b1")
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("delete missing", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		mock.ExpectExec("DELETE FROM bucket_policies").
+			WithArgs("b1").
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		err = repo.DeleteBucketPolicy(context.Background(), "b1")
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+}
+
+func TestStorageRepository_SetObjectACL(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewStorageRepository(mock)
+	mock.ExpectExec("UPDATE objects SET acl = \\$1").
+		WithArgs("public-read", "b1", "k", "null").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE objects SET acl = \\$1").
+		WithArgs("public-read", "b1", "gone", "null").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	assert.NoError(t, repo.SetObjectACL(context.Background(), "b1", "k", "null", domain.ACLPublicRead))
+	err = repo.SetObjectACL(context.Background(), "b1", "gone", "null", domain.ACLPublicRead)
+	assert.True(t, theclouderrors.Is(err, theclouderrors.ObjectNotFound))
+}
+
+func TestStorageRepository_SetObjectTags(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewStorageRepository(mock)
+	mock.ExpectExec("UPDATE objects SET tags = \\$1").
+		WithArgs([]byte(`{"env":"dev"}`), "b1", "k", "null").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE objects SET tags = \\$1").
+		WithArgs([]byte("{}"), "b1", "gone", "null").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	assert.NoError(t, repo.SetObjectTags(context.Background(), "b1", "k", "null", map[string]string{"env": "dev"}))
+	err = repo.SetObjectTags(context.Background(), "b1", "gone", "null", nil)
+	assert.True(t, theclouderrors.Is(err, theclouderrors.ObjectNotFound))
+}
+
+func TestStorageRepository_ListVersionsPage(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewStorageRepository(mock)
+	userID := uuid.New()
+	retainUntil := time.Now().Add(time.Hour)
+	rows := pgxmock.NewRows(objectColumns).
+		AddRow(uuid.New(), userID, "arn", "mybucket", "logs/a", "v2", true, int64(10), "STANDARD", 0, 0, int64(10), "etag", "", "private", []byte(`{"env":"dev"}`), "COMPLIANCE", &retainUntil, true, "", 0, "", "text/plain", time.Now(), nil, false).
+		AddRow(uuid.New(), userID, "arn", "mybucket", "logs/a", "v1", false, int64(8), "STANDARD", 0, 0, int64(8), "etag", "", "private", []byte("{}"), "", nil, false, "", 0, "", "text/plain", time.Now(), nil, false)
+	mock.ExpectQuery("SELECT .* FROM objects").
+		WithArgs("mybucket", "logs/", "", 100).
+		WillReturnRows(rows)
+
+	versions, err := repo.ListVersionsPage(context.Background(), "mybucket", "logs/", "", 100)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, "dev", versions[0].Tags["env"])
+	assert.Equal(t, domain.RetentionCompliance, versions[0].RetentionMode)
+	assert.True(t, versions[0].LegalHold)
+	assert.False(t, versions[1].IsLatest)
+	assert.Nil(t, versions[1].RetainUntil)
+}
+
+func TestStorageRepository_ObjectLock(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewStorageRepository(mock)
+	until := time.Now().Add(24 * time.Hour)
+	cfg := domain.ObjectLockConfig{Enabled: true, DefaultMode: domain.RetentionGovernance, DefaultDays: 30}
+
+	mock.ExpectExec("UPDATE buckets SET object_lock_enabled").
+		WithArgs(true, "GOVERNANCE", 30, "audit").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE buckets SET object_lock_enabled").
+		WithArgs(true, "GOVERNANCE", 30, "missing").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectExec("UPDATE objects SET retention_mode").
+		WithArgs("COMPLIANCE", &until, "audit", "k", "v1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE objects SET retention_mode").
+		WithArgs("", (*time.Time)(nil), "audit", "k", "v1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE objects SET legal_hold").
+		WithArgs(true, "audit", "gone", "v1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	assert.NoError(t, repo.SetBucketObjectLock(context.Background(), "audit", cfg))
+	err = repo.SetBucketObjectLock(context.Background(), "missing", cfg)
+	assert.True(t, theclouderrors.Is(err, theclouderrors.ObjectNotFound))
+	assert.NoError(t, repo.SetObjectRetention(context.Background(), "audit", "k", "v1", &domain.ObjectRetention{Mode: domain.RetentionCompliance, RetainUntil: until}))
+	assert.NoError(t, repo.SetObjectRetention(context.Background(), "audit", "k", "v1", nil))
+	err = repo.SetObjectLegalHold(context.Background(), "audit", "gone", "v1", true)
+	assert.True(t, theclouderrors.Is(err, theclouderrors.ObjectNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorageRepository_Encryption(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewStorageRepository(mock)
+	mock.ExpectExec("UPDATE buckets SET encryption_enabled").
+		WithArgs(true, "key-2", "vault").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE buckets SET encryption_enabled").
+		WithArgs(false, "", "missing").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	rows := pgxmock.NewRows(objectColumns).
+		AddRow(uuid.New(), uuid.New(), "arn", "vault", "a.txt", "null", true, int64(40), "STANDARD", 0, 0, int64(40), "etag", "", "private", []byte("{}"), "", nil, false, "SSE", 1, "", "text/plain", time.Now(), nil, false)
+	mock.ExpectQuery("SELECT .* FROM objects WHERE bucket = \\$1 AND encryption = 'SSE' AND key_version < \\$2").
+		WithArgs("vault", 2, 100).
+		WillReturnRows(rows)
+
+	assert.NoError(t, repo.SetBucketEncryption(context.Background(), "vault", true, "key-2"))
+	err = repo.SetBucketEncryption(context.Background(), "missing", false, "")
+	assert.True(t, theclouderrors.Is(err, theclouderrors.ObjectNotFound))
+
+	objects, err := repo.ListObjectsBelowKeyVersion(context.Background(), "vault", 2, 100)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+	assert.Equal(t, domain.EncryptionSSE, objects[0].Encryption)
+	assert.Equal(t, 1, objects[0].KeyVersion)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorageRepository_ListErasureObjects(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewStorageRepository(mock)
+	after := uuid.New()
+	rows := pgxmock.NewRows(objectColumns).
+		AddRow(uuid.New(), uuid.New(), "arn", "archive", "big.bin", "null", true, int64(1000), "ERASURE_CODED", 4, 2, int64(1500), "etag", "", "private", []byte("{}"), "", nil, false, "", 0, "", "application/octet-stream", time.Now(), nil, false)
+	mock.ExpectQuery("SELECT .* FROM objects WHERE storage_class = \\$1 AND deleted_at IS NULL AND id > \\$2 ORDER BY id").
+		WithArgs(domain.StorageClassErasureCoded, after, 100).
+		WillReturnRows(rows)
+
+	objects, err := repo.ListErasureObjects(context.Background(), after, 100)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+	assert.True(t, objects[0].IsErasureCoded())
+	assert.Equal(t, 4, objects[0].DataShards)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorageRepository_ListMultipartUploads(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewStorageRepository(mock)
+	cutoff := time.Now().Add(-7 * 24 * time.Hour)
+	mock.ExpectQuery("SELECT id, user_id, bucket, key, created_at FROM multipart_uploads").
+		WithArgs("mybucket", "", cutoff).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "bucket", "key", "created_at"}).
+			AddRow(uuid.New(), uuid.New(), "mybucket", "big.bin", cutoff.Add(-time.Hour)))
+
+	uploads, err := repo.ListMultipartUploads(context.Background(), "mybucket", "", cutoff)
+	assert.NoError(t, err)
+	assert.Len(t, uploads, 1)
+	assert.Equal(t, "big.bin", uploads[0].Key)
+}
+
+func TestStorageRepository_BucketNotifications(t *testing.T) {
+	targetID := uuid.New()
+	rules := []domain.BucketNotificationRule{{
+		ID:         "ingest",
+		Events:     []domain.StorageEventName{domain.StorageEventObjectCreated},
+		Prefix:     "incoming/",
+		TargetType: domain.NotificationTargetQueue,
+		TargetID:   targetID,
+	}}
+
+	t.Run("put", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		now := time.Now()
+		mock.ExpectExec("INSERT INTO bucket_notifications").
+			WithArgs("b1", pgxmock.AnyArg(), now).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		err = repo.PutBucketNotifications(context.Background(), &domain.BucketNotificationConfig{Bucket: "b1", Rules: rules, UpdatedAt: now})
+		assert.NoError(t, err)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		rulesJSON := []byte(`[{"id":"ingest","events":["ObjectCreated:*"],"prefix":"incoming/","target_type":"QUEUE","target_id":"` + targetID.String() + `"}]`)
+		mock.ExpectQuery("SELECT bucket, rules, updated_at FROM bucket_notifications").
+			WithArgs("b1").
+			WillReturnRows(pgxmock.NewRows([]string{"bucket", "rules", "updated_at"}).AddRow("b1", rulesJSON, time.Now()))
+
+		cfg, err := repo.GetBucketNotifications(context.Background(), "b1")
+		assert.NoError(t, err)
+		assert.Equal(t, rules, cfg.Rules)
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		mock.ExpectQuery("SELECT bucket, rules, updated_at FROM bucket_notifications").
+			WithArgs("b1").
+			WillReturnError(pgx.ErrNoRows)
+
+		_, err = repo.GetBucketNotifications(context.Background(), "b1")
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("delete missing", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		mock.ExpectExec("DELETE FROM bucket_notifications").
+			WithArgs("b1").
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		err = repo.DeleteBucketNotifications(context.Background(), "b1")
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+}
+
+func TestStorageRepository_Replication(t *testing.T) {
+	now := time.Now()
+	columns := []string{"bucket", "key", "version_id", "rule_id", "operation", "status", "attempts", "last_error", "next_attempt_at", "created_at", "replicated_at"}
+
+	t.Run("get config", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		rulesJSON := []byte(`[{"id":"r1","prefix":"raw/","destination":{"endpoint":"https://dr.example.com","bucket":"backup","api_key":"k"},"replicate_deletes":true}]`)
+		mock.ExpectQuery("SELECT bucket, rules, updated_at FROM bucket_replication").
+			WithArgs("b1").
+			WillReturnRows(pgxmock.NewRows([]string{"bucket", "rules", "updated_at"}).AddRow("b1", rulesJSON, now))
+
+		cfg, err := repo.GetBucketReplication(context.Background(), "b1")
+		assert.NoError(t, err)
+		assert.Len(t, cfg.Rules, 1)
+		assert.Equal(t, "k", cfg.Rules[0].Destination.APIKey)
+		assert.True(t, cfg.Rules[0].ReplicateDeletes)
+	})
+
+	t.Run("get missing config", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		mock.ExpectQuery("SELECT bucket, rules, updated_at FROM bucket_replication").
+			WithArgs("b1").
+			WillReturnError(pgx.ErrNoRows)
+
+		_, err = repo.GetBucketReplication(context.Background(), "b1")
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("enqueue", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		mock.ExpectExec("INSERT INTO object_replication").
+			WithArgs("b1", "a.jpg", "v1", "r1", "PUT", "PENDING", now, now).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		err = repo.EnqueueReplication(context.Background(), []*domain.ObjectReplication{{
+			Bucket: "b1", Key: "a.jpg", VersionID: "v1", RuleID: "r1",
+			Operation: domain.ReplicationOperationPut, Status: domain.ReplicationPending, NextAttemptAt: now, CreatedAt: now,
+		}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("list pending", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		mock.ExpectQuery("SELECT bucket, key, version_id.* FROM object_replication").
+			WithArgs(100).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow("b1", "a.jpg", "v1", "r1", "PUT", "PENDING", 2, "timeout", now, now, nil))
+
+		reps, err := repo.ListPendingReplication(context.Background(), 100)
+		assert.NoError(t, err)
+		assert.Len(t, reps, 1)
+		assert.Equal(t, domain.ReplicationOperationPut, reps[0].Operation)
+		assert.Equal(t, 2, reps[0].Attempts)
+		assert.Nil(t, reps[0].ReplicatedAt)
+	})
+
+	t.Run("backlog", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		assert.NoError(t, err)
+		defer mock.Close()
+
+		repo := NewStorageRepository(mock)
+		oldest := now.Add(-time.Minute)
+		mock.ExpectQuery("FROM object_replication").
+			WithArgs("").
+			WillReturnRows(pgxmock.NewRows([]string{"bucket", "pending", "failed", "oldest"}).AddRow("b1", 3, 1, &oldest))
+
+		backlogs, err := repo.ListReplicationBacklog(context.Background(), "")
+		assert.NoError(t, err)
+		assert.Len(t, backlogs, 1)
+		assert.Equal(t, 3, backlogs[0].Pending)
+		assert.Equal(t, 1, backlogs[0].Failed)
+		assert.Equal(t, oldest, *backlogs[0].OldestPending)
+	})
+}
@@ -23,10 +23,10 @@ func NewSubnetRepository(db DB) *SubnetRepository {
 
 func (r *SubnetRepository) Create(ctx context.Context, subnet *domain.Subnet) error {
 	query := `
-		INSERT INTO subnets (id, user_id, vpc_id, name, cidr_block, availability_zone, gateway_ip, arn, status, created_at)
-		VALUES ($1, $2, $3, $4, NULLIF($5, '')::cidr, $6, NULLIF($7, '')::inet, $8, $9, $10)
+		INSERT INTO subnets (id, user_id, vpc_id, name, cidr_block, ipv6_cidr_block, availability_zone, gateway_ip, arn, status, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::cidr, NULLIF($6, '')::cidr, $7, NULLIF($8, '')::inet, $9, $10, $11)
 	`
-	_, err := r.db.Exec(ctx, query, subnet.ID, subnet.UserID, subnet.VPCID, subnet.Name, subnet.CIDRBlock, subnet.AvailabilityZone, subnet.GatewayIP, subnet.ARN, subnet.Status, subnet.CreatedAt)
+	_, err := r.db.Exec(ctx, query, subnet.ID, subnet.UserID, subnet.VPCID, subnet.Name, subnet.CIDRBlock, subnet.IPv6CIDRBlock, subnet.AvailabilityZone, subnet.GatewayIP, subnet.ARN, subnet.Status, subnet.CreatedAt)
 	if err != nil {
 		return errors.Wrap(errors.Internal, "failed to create subnet", err)
 	}
@@ -35,19 +35,19 @@ func (r *SubnetRepository) Create(ctx context.Context, subnet *domain.Subnet) er
 
 func (r *SubnetRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Subnet, error) {
 	userID := appcontext.UserIDFromContext(ctx)
-	query := `SELECT id, user_id, vpc_id, name, cidr_block::text, availability_zone, COALESCE(gateway_ip::text, ''), arn, status, created_at FROM subnets WHERE id = $1 AND user_id = $2`
+	query := `SELECT id, user_id, vpc_id, name, cidr_block::text, COALESCE(ipv6_cidr_block::text, ''), availability_zone, COALESCE(gateway_ip::text, ''), arn, status, created_at FROM subnets WHERE id = $1 AND user_id = $2`
 	return r.scanSubnet(r.db.QueryRow(ctx, query, id, userID))
 }
 
 func (r *SubnetRepository) GetByName(ctx context.Context, vpcID uuid.UUID, name string) (*domain.Subnet, error) {
 	userID := appcontext.UserIDFromContext(ctx)
-	query := `SELECT id, user_id, vpc_id, name, cidr_block::text, availability_zone, COALESCE(gateway_ip::text, ''), arn, status, created_at FROM subnets WHERE vpc_id = $1 AND name = $2 AND user_id = $3`
+	query := `SELECT id, user_id, vpc_id, name, cidr_block::text, COALESCE(ipv6_cidr_block::text, ''), availability_zone, COALESCE(gateway_ip::text, ''), arn, status, created_at FROM subnets WHERE vpc_id = $1 AND name = $2 AND user_id = $3`
 	return r.scanSubnet(r.db.QueryRow(ctx, query, vpcID, name, userID))
 }
 
 func (r *SubnetRepository) ListByVPC(ctx context.Context, vpcID uuid.UUID) ([]*domain.Subnet, error) {
 	userID := appcontext.UserIDFromContext(ctx)
-	query := `SELECT id, user_id, vpc_id, name, cidr_block::text, availability_zone, COALESCE(gateway_ip::text, ''), arn, status, created_at FROM subnets WHERE vpc_id = $1 AND user_id = $2 ORDER BY created_at DESC`
+	query := `SELECT id, user_id, vpc_id, name, cidr_block::text, COALESCE(ipv6_cidr_block::text, ''), availability_zone, COALESCE(gateway_ip::text, ''), arn, status, created_at FROM subnets WHERE vpc_id = $1 AND user_id = $2 ORDER BY created_at DESC`
 	rows, err := r.db.Query(ctx, query, vpcID, userID)
 	if err != nil {
 		return nil, errors.Wrap(errors.Internal, "failed to list subnets", err)
@@ -57,7 +57,7 @@ func (r *SubnetRepository) ListByVPC(ctx context.Context, vpcID uuid.UUID) ([]*d
 
 func (r *SubnetRepository) scanSubnet(row pgx.Row) (*domain.Subnet, error) {
 	var s domain.Subnet
-	err := row.Scan(&s.ID, &s.UserID, &s.VPCID, &s.Name, &s.CIDRBlock, &s.AvailabilityZone, &s.GatewayIP, &s.ARN, &s.Status, &s.CreatedAt)
+	err := row.Scan(&s.ID, &s.UserID, &s.VPCID, &s.Name, &s.CIDRBlock, &s.IPv6CIDRBlock, &s.AvailabilityZone, &s.GatewayIP, &s.ARN, &s.Status, &s.CreatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, errors.New(errors.NotFound, "subnet not found")
@@ -19,7 +19,7 @@ import (
 const (
 	testSubnetName    = "subnet-1"
 	testAZ            = "us-east-1a"
-	selectSubnet      = "SELECT id, user_id, vpc_id, name, cidr_block::text, availability_zone, COALESCE\\(gateway_ip::text, ''\\), arn, status, created_at FROM subnets"
+	selectSubnet      = "SELECT id, user_id, vpc_id, name, cidr_block::text, COALESCE\\(ipv6_cidr_block::text, ''\\), availability_zone, COALESCE\\(gateway_ip::text, ''\\), arn, status, created_at FROM subnets"
 	deleteSubnetQuery = "DELETE FROM subnets"
 )
 
@@ -44,7 +44,7 @@ func TestSubnetRepositoryCreate(t *testing.T) {
 		}
 
 		mock.ExpectExec("INSERT INTO subnets").
-			WithArgs(s.ID, s.UserID, s.VPCID, s.Name, s.CIDRBlock, s.AvailabilityZone, s.GatewayIP, s.ARN, s.Status, s.CreatedAt).
+			WithArgs(s.ID, s.UserID, s.VPCID, s.Name, s.CIDRBlock, s.IPv6CIDRBlock, s.AvailabilityZone, s.GatewayIP, s.ARN, s.Status, s.CreatedAt).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		err = repo.Create(context.Background(), s)
@@ -83,8 +83,8 @@ func TestSubnetRepositoryGetByID(t *testing.T) {
 
 		mock.ExpectQuery(selectSubnet).
 			WithArgs(id, userID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "vpc_id", "name", "cidr_block", "availability_zone", "gateway_ip", "arn", "status", "created_at"}).
-				AddRow(id, userID, uuid.New(), testSubnetName, testutil.TestSubnetCIDR, testAZ, testutil.TestGatewayIP, "arn", "available", now))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "vpc_id", "name", "cidr_block", "ipv6_cidr_block", "availability_zone", "gateway_ip", "arn", "status", "created_at"}).
+				AddRow(id, userID, uuid.New(), testSubnetName, testutil.TestSubnetCIDR, "", testAZ, testutil.TestGatewayIP, "arn", "available", now))
 
 		s, err := repo.GetByID(ctx, id)
 		assert.NoError(t, err)
@@ -103,7 +103,7 @@ func TestSubnetRepositoryGetByID(t *testing.T) {
 		userID := uuid.New()
 		ctx := appcontext.WithUserID(context.Background(), userID)
 
-		mock.ExpectQuery("SELECT id, user_id, vpc_id, name, cidr_block::text, availability_zone, COALESCE\\(gateway_ip::text, ''\\), arn, status, created_at FROM subnets").
+		mock.ExpectQuery("SELECT id, user_id, vpc_id, name, cidr_block::text, COALESCE\\(ipv6_cidr_block::text, ''\\), availability_zone, COALESCE\\(gateway_ip::text, ''\\), arn, status, created_at FROM subnets").
 			WithArgs(id, userID).
 			WillReturnError(pgx.ErrNoRows)
 
@@ -152,13 +152,14 @@ func TestSubnetRepositoryGetByName(t *testing.T) {
 
 		mock.ExpectQuery(selectSubnet).
 			WithArgs(vpcID, name, userID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "vpc_id", "name", "cidr_block", "availability_zone", "gateway_ip", "arn", "status", "created_at"}).
-				AddRow(id, userID, vpcID, name, testutil.TestSubnetCIDR, testAZ, testutil.TestGatewayIP, "arn", "available", now))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "vpc_id", "name", "cidr_block", "ipv6_cidr_block", "availability_zone", "gateway_ip", "arn", "status", "created_at"}).
+				AddRow(id, userID, vpcID, name, testutil.TestSubnetCIDR, "fd00:10:0:1::/64", testAZ, testutil.TestGatewayIP, "arn", "available", now))
 
 		s, err := repo.GetByName(ctx, vpcID, name)
 		assert.NoError(t, err)
 		assert.NotNil(t, s)
 		assert.Equal(t, id, s.ID)
+		assert.Equal(t, "fd00:10:0:1::/64", s.IPv6CIDRBlock)
 
 	})
 
@@ -201,8 +202,8 @@ func TestSubnetRepositoryListByVPC(t *testing.T) {
 
 		mock.ExpectQuery(selectSubnet).
 			WithArgs(vpcID, userID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "vpc_id", "name", "cidr_block", "availability_zone", "gateway_ip", "arn", "status", "created_at"}).
-				AddRow(uuid.New(), userID, vpcID, testSubnetName, testutil.TestSubnetCIDR, testAZ, testutil.TestGatewayIP, "arn", "available", now))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "vpc_id", "name", "cidr_block", "ipv6_cidr_block", "availability_zone", "gateway_ip", "arn", "status", "created_at"}).
+				AddRow(uuid.New(), userID, vpcID, testSubnetName, testutil.TestSubnetCIDR, "", testAZ, testutil.TestGatewayIP, "arn", "available", now))
 
 		subnets, err := repo.ListByVPC(ctx, vpcID)
 		assert.NoError(t, err)
@@ -242,8 +243,8 @@ func TestSubnetRepositoryListByVPC(t *testing.T) {
 
 		mock.ExpectQuery(selectSubnet).
 			WithArgs(vpcID, userID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "vpc_id", "name", "cidr_block", "availability_zone", "gateway_ip", "arn", "status", "created_at"}).
-				AddRow("invalid-uuid", userID, vpcID, testSubnetName, testutil.TestSubnetCIDR, testAZ, testutil.TestGatewayIP, "arn", "available", now))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "vpc_id", "name", "cidr_block", "ipv6_cidr_block", "availability_zone", "gateway_ip", "arn", "status", "created_at"}).
+				AddRow("invalid-uuid", userID, vpcID, testSubnetName, testutil.TestSubnetCIDR, "", testAZ, testutil.TestGatewayIP, "arn", "available", now))
 
 		subnets, err := repo.ListByVPC(ctx, vpcID)
 		assert.Error(t, err)
@@ -0,0 +1,212 @@
+// Package postgres provides PostgreSQL-backed repository implementations.
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+)
+
+const (
+	internetGatewayColumns = `id, user_id, tenant_id, vpc_id, name, status, arn, created_at`
+	natGatewayColumns      = `id, user_id, tenant_id, vpc_id, subnet_id, elastic_ip_id, name, public_ip, private_ip, status, arn, created_at`
+)
+
+// InternetGatewayRepository provides a PostgreSQL implementation for internet gateways.
+type InternetGatewayRepository struct {
+	db DB
+}
+
+// NewInternetGatewayRepository creates a new InternetGatewayRepository.
+func NewInternetGatewayRepository(db DB) *InternetGatewayRepository {
+	return &InternetGatewayRepository{db: db}
+}
+
+// Create inserts a new internet gateway.
+func (r *InternetGatewayRepository) Create(ctx context.Context, igw *domain.InternetGateway) error {
+	query := `INSERT INTO internet_gateways (id, user_id, tenant_id, vpc_id, name, status, arn, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.db.Exec(ctx, query, igw.ID, igw.UserID, igw.TenantID, igw.VPCID, igw.Name, string(igw.Status), igw.ARN, igw.CreatedAt)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to create internet gateway", err)
+	}
+	return nil
+}
+
+// GetByID retrieves an internet gateway of the caller's tenant.
+func (r *InternetGatewayRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.InternetGateway, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + internetGatewayColumns + ` FROM internet_gateways WHERE id = $1 AND tenant_id = $2`
+	return r.scan(r.db.QueryRow(ctx, query, id, tenantID))
+}
+
+// GetByVPC retrieves the internet gateway attached to a VPC.
+func (r *InternetGatewayRepository) GetByVPC(ctx context.Context, vpcID uuid.UUID) (*domain.InternetGateway, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + internetGatewayColumns + ` FROM internet_gateways WHERE vpc_id = $1 AND tenant_id = $2`
+	return r.scan(r.db.QueryRow(ctx, query, vpcID, tenantID))
+}
+
+// List returns every internet gateway of the caller's tenant.
+func (r *InternetGatewayRepository) List(ctx context.Context) ([]*domain.InternetGateway, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + internetGatewayColumns + ` FROM internet_gateways WHERE tenant_id = $1 ORDER BY created_at DESC`
+	rows, err := r.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list internet gateways", err)
+	}
+	defer rows.Close()
+
+	var gateways []*domain.InternetGateway
+	for rows.Next() {
+		igw, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		gateways = append(gateways, igw)
+	}
+	return gateways, nil
+}
+
+// Update records a gateway's attachment and status.
+func (r *InternetGatewayRepository) Update(ctx context.Context, igw *domain.InternetGateway) error {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `UPDATE internet_gateways SET vpc_id = $1, status = $2 WHERE id = $3 AND tenant_id = $4`
+	cmd, err := r.db.Exec(ctx, query, igw.VPCID, string(igw.Status), igw.ID, tenantID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to update internet gateway", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "internet gateway not found")
+	}
+	return nil
+}
+
+// Delete removes an internet gateway.
+func (r *InternetGatewayRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	cmd, err := r.db.Exec(ctx, `DELETE FROM internet_gateways WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete internet gateway", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "internet gateway not found")
+	}
+	return nil
+}
+
+func (r *InternetGatewayRepository) scan(row pgx.Row) (*domain.InternetGateway, error) {
+	var igw domain.InternetGateway
+	var status string
+	err := row.Scan(&igw.ID, &igw.UserID, &igw.TenantID, &igw.VPCID, &igw.Name, &status, &igw.ARN, &igw.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.NotFound, "internet gateway not found")
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to scan internet gateway", err)
+	}
+	igw.Status = domain.InternetGatewayStatus(status)
+	return &igw, nil
+}
+
+// NATGatewayRepository provides a PostgreSQL implementation for NAT gateways.
+type NATGatewayRepository struct {
+	db DB
+}
+
+// NewNATGatewayRepository creates a new NATGatewayRepository.
+func NewNATGatewayRepository(db DB) *NATGatewayRepository {
+	return &NATGatewayRepository{db: db}
+}
+
+// Create inserts a new NAT gateway.
+func (r *NATGatewayRepository) Create(ctx context.Context, nat *domain.NATGateway) error {
+	query := `INSERT INTO nat_gateways (id, user_id, tenant_id, vpc_id, subnet_id, elastic_ip_id, name, public_ip, private_ip, status, arn, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	_, err := r.db.Exec(ctx, query, nat.ID, nat.UserID, nat.TenantID, nat.VPCID, nat.SubnetID, nat.ElasticIPID,
+		nat.Name, nat.PublicIP, nat.PrivateIP, string(nat.Status), nat.ARN, nat.CreatedAt)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to create nat gateway", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a NAT gateway of the caller's tenant.
+func (r *NATGatewayRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.NATGateway, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + natGatewayColumns + ` FROM nat_gateways WHERE id = $1 AND tenant_id = $2`
+	return r.scan(r.db.QueryRow(ctx, query, id, tenantID))
+}
+
+// GetBySubnet retrieves the NAT gateway placed in a subnet of the caller's tenant.
+func (r *NATGatewayRepository) GetBySubnet(ctx context.Context, subnetID uuid.UUID) (*domain.NATGateway, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + natGatewayColumns + ` FROM nat_gateways WHERE subnet_id = $1 AND tenant_id = $2`
+	return r.scan(r.db.QueryRow(ctx, query, subnetID, tenantID))
+}
+
+// List returns every NAT gateway of the caller's tenant.
+func (r *NATGatewayRepository) List(ctx context.Context) ([]*domain.NATGateway, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + natGatewayColumns + ` FROM nat_gateways WHERE tenant_id = $1 ORDER BY created_at DESC`
+	rows, err := r.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list nat gateways", err)
+	}
+	defer rows.Close()
+
+	var gateways []*domain.NATGateway
+	for rows.Next() {
+		nat, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		gateways = append(gateways, nat)
+	}
+	return gateways, nil
+}
+
+// UpdateStatus records a NAT gateway's lifecycle state.
+func (r *NATGatewayRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.NATGatewayStatus) error {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	cmd, err := r.db.Exec(ctx, `UPDATE nat_gateways SET status = $1 WHERE id = $2 AND tenant_id = $3`, string(status), id, tenantID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to update nat gateway", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "nat gateway not found")
+	}
+	return nil
+}
+
+// Delete removes a NAT gateway.
+func (r *NATGatewayRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	cmd, err := r.db.Exec(ctx, `DELETE FROM nat_gateways WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete nat gateway", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "nat gateway not found")
+	}
+	return nil
+}
+
+func (r *NATGatewayRepository) scan(row pgx.Row) (*domain.NATGateway, error) {
+	var nat domain.NATGateway
+	var status string
+	err := row.Scan(&nat.ID, &nat.UserID, &nat.TenantID, &nat.VPCID, &nat.SubnetID, &nat.ElasticIPID,
+		&nat.Name, &nat.PublicIP, &nat.PrivateIP, &status, &nat.ARN, &nat.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.NotFound, "nat gateway not found")
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to scan nat gateway", err)
+	}
+	nat.Status = domain.NATGatewayStatus(status)
+	return &nat, nil
+}
@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	theclouderrors "github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternetGatewayRepository(t *testing.T) {
+	tenantID := uuid.New()
+	ctx := appcontext.WithTenantID(context.Background(), tenantID)
+	vpcID := uuid.New()
+	igw := &domain.InternetGateway{
+		ID: uuid.New(), UserID: uuid.New(), TenantID: tenantID, VPCID: &vpcID,
+		Name: "igw", Status: domain.IGWStatusAttached, ARN: "arn", CreatedAt: time.Now(),
+	}
+	rows := func() *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "vpc_id", "name", "status", "arn", "created_at"}).
+			AddRow(igw.ID, igw.UserID, igw.TenantID, igw.VPCID, igw.Name, string(igw.Status), igw.ARN, igw.CreatedAt)
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("INSERT INTO internet_gateways").
+			WithArgs(igw.ID, igw.UserID, igw.TenantID, igw.VPCID, igw.Name, "attached", igw.ARN, igw.CreatedAt).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, NewInternetGatewayRepository(mock).Create(ctx, igw))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetByVPC", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectQuery("FROM internet_gateways WHERE vpc_id").WithArgs(vpcID, tenantID).WillReturnRows(rows())
+		mock.ExpectQuery("FROM internet_gateways WHERE vpc_id").WithArgs(vpcID, tenantID).WillReturnError(pgx.ErrNoRows)
+
+		repo := NewInternetGatewayRepository(mock)
+		got, err := repo.GetByVPC(ctx, vpcID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.IGWStatusAttached, got.Status)
+		assert.Equal(t, vpcID, *got.VPCID)
+
+		_, err = repo.GetByVPC(ctx, vpcID)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("List", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectQuery("FROM internet_gateways WHERE tenant_id").WithArgs(tenantID).WillReturnRows(rows())
+
+		gateways, err := NewInternetGatewayRepository(mock).List(ctx)
+		require.NoError(t, err)
+		assert.Len(t, gateways, 1)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("UPDATE internet_gateways").WithArgs(igw.VPCID, "attached", igw.ID, tenantID).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		err = NewInternetGatewayRepository(mock).Update(ctx, igw)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("DELETE FROM internet_gateways").WithArgs(igw.ID, tenantID).WillReturnError(assert.AnError)
+
+		err = NewInternetGatewayRepository(mock).Delete(ctx, igw.ID)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.Internal))
+	})
+}
+
+func TestNATGatewayRepository(t *testing.T) {
+	tenantID := uuid.New()
+	ctx := appcontext.WithTenantID(context.Background(), tenantID)
+	nat := &domain.NATGateway{
+		ID: uuid.New(), UserID: uuid.New(), TenantID: tenantID, VPCID: uuid.New(), SubnetID: uuid.New(), ElasticIPID: uuid.New(),
+		Name: "nat", PublicIP: "100.64.0.9", PrivateIP: "10.0.1.254", Status: domain.NATStatusAvailable, ARN: "arn", CreatedAt: time.Now(),
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("INSERT INTO nat_gateways").
+			WithArgs(nat.ID, nat.UserID, nat.TenantID, nat.VPCID, nat.SubnetID, nat.ElasticIPID,
+				nat.Name, nat.PublicIP, nat.PrivateIP, "available", nat.ARN, nat.CreatedAt).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, NewNATGatewayRepository(mock).Create(ctx, nat))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetByID", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectQuery("FROM nat_gateways WHERE id").WithArgs(nat.ID, tenantID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "vpc_id", "subnet_id", "elastic_ip_id",
+				"name", "public_ip", "private_ip", "status", "arn", "created_at"}).
+				AddRow(nat.ID, nat.UserID, nat.TenantID, nat.VPCID, nat.SubnetID, nat.ElasticIPID,
+					nat.Name, nat.PublicIP, nat.PrivateIP, "failed", nat.ARN, nat.CreatedAt))
+		mock.ExpectQuery("FROM nat_gateways WHERE id").WithArgs(nat.ID, tenantID).WillReturnError(pgx.ErrNoRows)
+
+		repo := NewNATGatewayRepository(mock)
+		got, err := repo.GetByID(ctx, nat.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.NATStatusFailed, got.Status)
+		assert.Equal(t, nat.PrivateIP, got.PrivateIP)
+
+		_, err = repo.GetByID(ctx, nat.ID)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("GetBySubnet", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectQuery("FROM nat_gateways WHERE subnet_id").WithArgs(nat.SubnetID, tenantID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "vpc_id", "subnet_id", "elastic_ip_id",
+				"name", "public_ip", "private_ip", "status", "arn", "created_at"}).
+				AddRow(nat.ID, nat.UserID, nat.TenantID, nat.VPCID, nat.SubnetID, nat.ElasticIPID,
+					nat.Name, nat.PublicIP, nat.PrivateIP, "available", nat.ARN, nat.CreatedAt))
+
+		got, err := NewNATGatewayRepository(mock).GetBySubnet(ctx, nat.SubnetID)
+		require.NoError(t, err)
+		assert.Equal(t, nat.ID, got.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("UPDATE nat_gateways SET status").WithArgs("failed", nat.ID, tenantID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		require.NoError(t, NewNATGatewayRepository(mock).UpdateStatus(ctx, nat.ID, domain.NATStatusFailed))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("DELETE FROM nat_gateways").WithArgs(nat.ID, tenantID).WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		err = NewNATGatewayRepository(mock).Delete(ctx, nat.ID)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+}
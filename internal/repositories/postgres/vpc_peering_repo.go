@@ -0,0 +1,146 @@
+// Package postgres provides PostgreSQL-backed repository implementations.
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/errors"
+)
+
+const vpcPeeringColumns = `id, requester_vpc_id, accepter_vpc_id, requester_tenant_id, accepter_tenant_id,
+	requester_cidr::text, accepter_cidr::text, status, arn, created_at, updated_at`
+
+// VPCPeeringRepository provides a PostgreSQL implementation for VPC peering connections.
+// A peering is visible to the tenants on both of its sides.
+type VPCPeeringRepository struct {
+	db DB
+}
+
+// NewVPCPeeringRepository creates a new VPCPeeringRepository.
+func NewVPCPeeringRepository(db DB) *VPCPeeringRepository {
+	return &VPCPeeringRepository{db: db}
+}
+
+// Create inserts a new peering connection.
+func (r *VPCPeeringRepository) Create(ctx context.Context, p *domain.VPCPeering) error {
+	query := `
+		INSERT INTO vpc_peerings (id, requester_vpc_id, accepter_vpc_id, requester_tenant_id, accepter_tenant_id,
+			requester_cidr, accepter_cidr, status, arn, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6::cidr, $7::cidr, $8, $9, $10, $11)
+	`
+	_, err := r.db.Exec(ctx, query, p.ID, p.RequesterVPCID, p.AccepterVPCID, p.RequesterTenantID, p.AccepterTenantID,
+		p.RequesterCIDR, p.AccepterCIDR, string(p.Status), p.ARN, p.CreatedAt, p.UpdatedAt)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to create vpc peering", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a peering connection the caller's tenant takes part in.
+func (r *VPCPeeringRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.VPCPeering, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + vpcPeeringColumns + ` FROM vpc_peerings
+		WHERE id = $1 AND (requester_tenant_id = $2 OR accepter_tenant_id = $2)`
+	return r.scanPeering(r.db.QueryRow(ctx, query, id, tenantID))
+}
+
+// List returns every peering connection the caller's tenant takes part in.
+func (r *VPCPeeringRepository) List(ctx context.Context) ([]*domain.VPCPeering, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + vpcPeeringColumns + ` FROM vpc_peerings
+		WHERE requester_tenant_id = $1 OR accepter_tenant_id = $1 ORDER BY created_at DESC`
+	rows, err := r.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list vpc peerings", err)
+	}
+	return r.scanPeerings(rows)
+}
+
+// ListByVPC returns the peering connections on either side of a VPC.
+func (r *VPCPeeringRepository) ListByVPC(ctx context.Context, vpcID uuid.UUID) ([]*domain.VPCPeering, error) {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `SELECT ` + vpcPeeringColumns + ` FROM vpc_peerings
+		WHERE (requester_vpc_id = $1 OR accepter_vpc_id = $1) AND (requester_tenant_id = $2 OR accepter_tenant_id = $2)
+		ORDER BY created_at DESC`
+	rows, err := r.db.Query(ctx, query, vpcID, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(errors.Internal, "failed to list vpc peerings", err)
+	}
+	return r.scanPeerings(rows)
+}
+
+// UpdateStatus records a peering connection's new lifecycle state.
+func (r *VPCPeeringRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.VPCPeeringStatus) error {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `UPDATE vpc_peerings SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND (requester_tenant_id = $3 OR accepter_tenant_id = $3)`
+	cmd, err := r.db.Exec(ctx, query, string(status), id, tenantID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to update vpc peering", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "vpc peering not found")
+	}
+	return nil
+}
+
+// Delete removes a peering connection.
+func (r *VPCPeeringRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tenantID := appcontext.TenantIDFromContext(ctx)
+	query := `DELETE FROM vpc_peerings WHERE id = $1 AND (requester_tenant_id = $2 OR accepter_tenant_id = $2)`
+	cmd, err := r.db.Exec(ctx, query, id, tenantID)
+	if err != nil {
+		return errors.Wrap(errors.Internal, "failed to delete vpc peering", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New(errors.NotFound, "vpc peering not found")
+	}
+	return nil
+}
+
+// GetVPC retrieves a VPC owned by the given tenant, which need not be the caller's, for
+// resolving the remote side of a peering.
+func (r *VPCPeeringRepository) GetVPC(ctx context.Context, id, tenantID uuid.UUID) (*domain.VPC, error) {
+	query := `SELECT id, user_id, tenant_id, name, COALESCE(cidr_block::text, ''), COALESCE(ipv6_cidr_block::text, ''), network_id, vxlan_id, status, arn, created_at FROM vpcs WHERE id = $1 AND tenant_id = $2`
+	var vpc domain.VPC
+	err := r.db.QueryRow(ctx, query, id, tenantID).Scan(&vpc.ID, &vpc.UserID, &vpc.TenantID, &vpc.Name, &vpc.CIDRBlock, &vpc.IPv6CIDRBlock, &vpc.NetworkID, &vpc.VXLANID, &vpc.Status, &vpc.ARN, &vpc.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.NotFound, "vpc not found")
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to scan vpc", err)
+	}
+	return &vpc, nil
+}
+
+func (r *VPCPeeringRepository) scanPeering(row pgx.Row) (*domain.VPCPeering, error) {
+	var p domain.VPCPeering
+	var status string
+	err := row.Scan(&p.ID, &p.RequesterVPCID, &p.AccepterVPCID, &p.RequesterTenantID, &p.AccepterTenantID,
+		&p.RequesterCIDR, &p.AccepterCIDR, &status, &p.ARN, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.NotFound, "vpc peering not found")
+		}
+		return nil, errors.Wrap(errors.Internal, "failed to scan vpc peering", err)
+	}
+	p.Status = domain.VPCPeeringStatus(status)
+	return &p, nil
+}
+
+func (r *VPCPeeringRepository) scanPeerings(rows pgx.Rows) ([]*domain.VPCPeering, error) {
+	defer rows.Close()
+	var peerings []*domain.VPCPeering
+	for rows.Next() {
+		p, err := r.scanPeering(rows)
+		if err != nil {
+			return nil, err
+		}
+		peerings = append(peerings, p)
+	}
+	return peerings, nil
+}
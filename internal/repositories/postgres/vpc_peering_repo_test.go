@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	theclouderrors "github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const selectVpcPeering = "SELECT id, requester_vpc_id, accepter_vpc_id"
+
+func vpcPeeringRows(p *domain.VPCPeering) *pgxmock.Rows {
+	return pgxmock.NewRows([]string{"id", "requester_vpc_id", "accepter_vpc_id", "requester_tenant_id", "accepter_tenant_id",
+		"requester_cidr", "accepter_cidr", "status", "arn", "created_at", "updated_at"}).
+		AddRow(p.ID, p.RequesterVPCID, p.AccepterVPCID, p.RequesterTenantID, p.AccepterTenantID,
+			p.RequesterCIDR, p.AccepterCIDR, string(p.Status), p.ARN, p.CreatedAt, p.UpdatedAt)
+}
+
+func TestVPCPeeringRepository(t *testing.T) {
+	tenantID := uuid.New()
+	ctx := appcontext.WithTenantID(context.Background(), tenantID)
+	now := time.Now()
+	peering := &domain.VPCPeering{
+		ID:                uuid.New(),
+		RequesterVPCID:    uuid.New(),
+		AccepterVPCID:     uuid.New(),
+		RequesterTenantID: tenantID,
+		AccepterTenantID:  uuid.New(),
+		RequesterCIDR:     "10.0.0.0/16",
+		AccepterCIDR:      "10.1.0.0/16",
+		Status:            domain.PeeringPendingAcceptance,
+		ARN:               "arn",
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("INSERT INTO vpc_peerings").
+			WithArgs(peering.ID, peering.RequesterVPCID, peering.AccepterVPCID, peering.RequesterTenantID, peering.AccepterTenantID,
+				peering.RequesterCIDR, peering.AccepterCIDR, string(peering.Status), peering.ARN, peering.CreatedAt, peering.UpdatedAt).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, NewVPCPeeringRepository(mock).Create(ctx, peering))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GetByID is visible to either tenant", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectQuery(selectVpcPeering).WithArgs(peering.ID, tenantID).WillReturnRows(vpcPeeringRows(peering))
+		mock.ExpectQuery(selectVpcPeering).WithArgs(peering.ID, tenantID).WillReturnError(pgx.ErrNoRows)
+
+		repo := NewVPCPeeringRepository(mock)
+		got, err := repo.GetByID(ctx, peering.ID)
+		require.NoError(t, err)
+		assert.Equal(t, peering.AccepterCIDR, got.AccepterCIDR)
+		assert.Equal(t, domain.PeeringPendingAcceptance, got.Status)
+
+		_, err = repo.GetByID(ctx, peering.ID)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("ListByVPC", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectQuery(selectVpcPeering).WithArgs(peering.RequesterVPCID, tenantID).WillReturnRows(vpcPeeringRows(peering))
+
+		got, err := NewVPCPeeringRepository(mock).ListByVPC(ctx, peering.RequesterVPCID)
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("UPDATE vpc_peerings SET status").WithArgs("active", peering.ID, tenantID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("UPDATE vpc_peerings SET status").WithArgs("active", peering.ID, tenantID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		repo := NewVPCPeeringRepository(mock)
+		require.NoError(t, repo.UpdateStatus(ctx, peering.ID, domain.PeeringActive))
+		err = repo.UpdateStatus(ctx, peering.ID, domain.PeeringActive)
+		assert.True(t, theclouderrors.Is(err, theclouderrors.NotFound))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		mock.ExpectExec("DELETE FROM vpc_peerings").WithArgs(peering.ID, tenantID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		require.NoError(t, NewVPCPeeringRepository(mock).Delete(ctx, peering.ID))
+	})
+
+	t.Run("GetVPC scopes by the named tenant", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		vpcID := uuid.New()
+		mock.ExpectQuery(selectVpc).WithArgs(vpcID, peering.AccepterTenantID).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "name", "cidr_block", "ipv6_cidr_block", "network_id", "vxlan_id", "status", "arn", "created_at"}).
+				AddRow(vpcID, uuid.New(), peering.AccepterTenantID, "shared", "10.1.0.0/16", "fd00:11::/56", "br-vpc-1", 101, "active", "arn", now))
+
+		vpc, err := NewVPCPeeringRepository(mock).GetVPC(ctx, vpcID, peering.AccepterTenantID)
+		require.NoError(t, err)
+		assert.Equal(t, peering.AccepterTenantID, vpc.TenantID)
+		assert.Equal(t, "fd00:11::/56", vpc.IPv6CIDRBlock)
+	})
+}
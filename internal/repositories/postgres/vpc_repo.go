@@ -24,10 +24,10 @@ func NewVpcRepository(db DB) *VpcRepository {
 // Create inserts a new VPC record into the database.
 func (r *VpcRepository) Create(ctx context.Context, vpc *domain.VPC) error {
 	query := `
-		INSERT INTO vpcs (id, user_id, tenant_id, name, cidr_block, network_id, vxlan_id, status, arn, created_at)
-		VALUES ($1, $2, $3, $4, NULLIF($5, '')::cidr, $6, $7, $8, $9, $10)
+		INSERT INTO vpcs (id, user_id, tenant_id, name, cidr_block, ipv6_cidr_block, network_id, vxlan_id, status, arn, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::cidr, NULLIF($6, '')::cidr, $7, $8, $9, $10, $11)
 	`
-	_, err := r.db.Exec(ctx, query, vpc.ID, vpc.UserID, vpc.TenantID, vpc.Name, vpc.CIDRBlock, vpc.NetworkID, vpc.VXLANID, vpc.Status, vpc.ARN, vpc.CreatedAt)
+	_, err := r.db.Exec(ctx, query, vpc.ID, vpc.UserID, vpc.TenantID, vpc.Name, vpc.CIDRBlock, vpc.IPv6CIDRBlock, vpc.NetworkID, vpc.VXLANID, vpc.Status, vpc.ARN, vpc.CreatedAt)
 	if err != nil {
 		return errors.Wrap(errors.Internal, "failed to create vpc", err)
 	}
@@ -37,21 +37,21 @@ func (r *VpcRepository) Create(ctx context.Context, vpc *domain.VPC) error {
 // GetByID retrieves a single VPC by its UUID and ensures it belongs to the authenticated user.
 func (r *VpcRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.VPC, error) {
 	tenantID := appcontext.TenantIDFromContext(ctx)
-	query := `SELECT id, user_id, tenant_id, name, COALESCE(cidr_block::text, ''), network_id, vxlan_id, status, arn, created_at FROM vpcs WHERE id = $1 AND tenant_id = $2`
+	query := `SELECT id, user_id, tenant_id, name, COALESCE(cidr_block::text, ''), COALESCE(ipv6_cidr_block::text, ''), network_id, vxlan_id, status, arn, created_at FROM vpcs WHERE id = $1 AND tenant_id = $2`
 	return r.scanVPC(r.db.QueryRow(ctx, query, id, tenantID))
 }
 
 // GetByName retrieves a single VPC by its name and ensures it belongs to the authenticated user.
 func (r *VpcRepository) GetByName(ctx context.Context, name string) (*domain.VPC, error) {
 	tenantID := appcontext.TenantIDFromContext(ctx)
-	query := `SELECT id, user_id, tenant_id, name, COALESCE(cidr_block::text, ''), network_id, vxlan_id, status, arn, created_at FROM vpcs WHERE name = $1 AND tenant_id = $2`
+	query := `SELECT id, user_id, tenant_id, name, COALESCE(cidr_block::text, ''), COALESCE(ipv6_cidr_block::text, ''), network_id, vxlan_id, status, arn, created_at FROM vpcs WHERE name = $1 AND tenant_id = $2`
 	return r.scanVPC(r.db.QueryRow(ctx, query, name, tenantID))
 }
 
 // List returns all VPCs belonging to the authenticated user.
 func (r *VpcRepository) List(ctx context.Context) ([]*domain.VPC, error) {
 	tenantID := appcontext.TenantIDFromContext(ctx)
-	query := `SELECT id, user_id, tenant_id, name, COALESCE(cidr_block::text, ''), network_id, vxlan_id, status, arn, created_at FROM vpcs WHERE tenant_id = $1 ORDER BY created_at DESC`
+	query := `SELECT id, user_id, tenant_id, name, COALESCE(cidr_block::text, ''), COALESCE(ipv6_cidr_block::text, ''), network_id, vxlan_id, status, arn, created_at FROM vpcs WHERE tenant_id = $1 ORDER BY created_at DESC`
 	rows, err := r.db.Query(ctx, query, tenantID)
 	if err != nil {
 		return nil, errors.Wrap(errors.Internal, "failed to list vpcs", err)
@@ -61,7 +61,7 @@ func (r *VpcRepository) List(ctx context.Context) ([]*domain.VPC, error) {
 
 func (r *VpcRepository) scanVPC(row pgx.Row) (*domain.VPC, error) {
 	var vpc domain.VPC
-	err := row.Scan(&vpc.ID, &vpc.UserID, &vpc.TenantID, &vpc.Name, &vpc.CIDRBlock, &vpc.NetworkID, &vpc.VXLANID, &vpc.Status, &vpc.ARN, &vpc.CreatedAt)
+	err := row.Scan(&vpc.ID, &vpc.UserID, &vpc.TenantID, &vpc.Name, &vpc.CIDRBlock, &vpc.IPv6CIDRBlock, &vpc.NetworkID, &vpc.VXLANID, &vpc.Status, &vpc.ARN, &vpc.CreatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, errors.New(errors.NotFound, "vpc not found")
@@ -18,7 +18,7 @@ import (
 
 const (
 	testVpcName = "test-vpc"
-	selectVpc   = "SELECT id, user_id, tenant_id, name, COALESCE\\(cidr_block::text, ''\\), network_id, vxlan_id, status, arn, created_at FROM vpcs"
+	selectVpc   = "SELECT id, user_id, tenant_id, name, COALESCE\\(cidr_block::text, ''\\), COALESCE\\(ipv6_cidr_block::text, ''\\), network_id, vxlan_id, status, arn, created_at FROM vpcs"
 )
 
 func TestVpcRepositoryCreate(t *testing.T) {
@@ -44,7 +44,7 @@ func TestVpcRepositoryCreate(t *testing.T) {
 		}
 
 		mock.ExpectExec("INSERT INTO vpcs").
-			WithArgs(vpc.ID, vpc.UserID, vpc.TenantID, vpc.Name, vpc.CIDRBlock, vpc.NetworkID, vpc.VXLANID, vpc.Status, vpc.ARN, vpc.CreatedAt).
+			WithArgs(vpc.ID, vpc.UserID, vpc.TenantID, vpc.Name, vpc.CIDRBlock, vpc.IPv6CIDRBlock, vpc.NetworkID, vpc.VXLANID, vpc.Status, vpc.ARN, vpc.CreatedAt).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		err = repo.Create(context.Background(), vpc)
@@ -87,8 +87,8 @@ func TestVpcRepositoryGetByID(t *testing.T) {
 
 		mock.ExpectQuery(selectVpc).
 			WithArgs(id, tenantID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "name", "cidr_block", "network_id", "vxlan_id", "status", "arn", "created_at"}).
-				AddRow(id, userID, tenantID, testVpcName, testutil.TestCIDR, "net-1", 100, "available", "arn", now))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "name", "cidr_block", "ipv6_cidr_block", "network_id", "vxlan_id", "status", "arn", "created_at"}).
+				AddRow(id, userID, tenantID, testVpcName, testutil.TestCIDR, "", "net-1", 100, "available", "arn", now))
 
 		vpc, err := repo.GetByID(ctx, id)
 		assert.NoError(t, err)
@@ -165,8 +165,8 @@ func TestVpcRepositoryGetByName(t *testing.T) {
 
 		mock.ExpectQuery(selectVpc).
 			WithArgs(name, tenantID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "name", "cidr_block", "network_id", "vxlan_id", "status", "arn", "created_at"}).
-				AddRow(id, userID, tenantID, name, testutil.TestCIDR, "net-1", 100, "available", "arn", now))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "name", "cidr_block", "ipv6_cidr_block", "network_id", "vxlan_id", "status", "arn", "created_at"}).
+				AddRow(id, userID, tenantID, name, testutil.TestCIDR, "", "net-1", 100, "available", "arn", now))
 
 		vpc, err := repo.GetByName(ctx, name)
 		assert.NoError(t, err)
@@ -212,8 +212,8 @@ func TestVpcRepositoryList(t *testing.T) {
 
 		mock.ExpectQuery(selectVpc).
 			WithArgs(tenantID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "name", "cidr_block", "network_id", "vxlan_id", "status", "arn", "created_at"}).
-				AddRow(uuid.New(), userID, tenantID, testVpcName, testutil.TestCIDR, "net-1", 100, "available", "arn", now))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "name", "cidr_block", "ipv6_cidr_block", "network_id", "vxlan_id", "status", "arn", "created_at"}).
+				AddRow(uuid.New(), userID, tenantID, testVpcName, testutil.TestCIDR, "", "net-1", 100, "available", "arn", now))
 
 		vpcs, err := repo.List(ctx)
 		assert.NoError(t, err)
@@ -255,8 +255,8 @@ func TestVpcRepositoryList(t *testing.T) {
 		// Return a row with incompatible types to force scan error
 		mock.ExpectQuery(selectVpc).
 			WithArgs(tenantID).
-			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "name", "cidr_block", "network_id", "vxlan_id", "status", "arn", "created_at"}).
-				AddRow("invalid-uuid", userID, tenantID, testVpcName, testutil.TestCIDR, "net-1", 100, "available", "arn", now))
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "tenant_id", "name", "cidr_block", "ipv6_cidr_block", "network_id", "vxlan_id", "status", "arn", "created_at"}).
+				AddRow("invalid-uuid", userID, tenantID, testVpcName, testutil.TestCIDR, "", "net-1", 100, "available", "arn", now))
 
 		vpcs, err := repo.List(ctx)
 		assert.Error(t, err)
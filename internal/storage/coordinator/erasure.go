@@ -0,0 +1,319 @@
+// Package coordinator manages distributed storage coordination.
+package coordinator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/klauspost/reedsolomon"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/platform"
+	pb "github.com/poyrazk/thecloud/internal/storage/protocol"
+)
+
+// shardPathFormat is the node-local key under which the i-th shard of an object is stored.
+const shardPathFormat = ".shards/%s/shard-%d"
+
+func shardKey(key string, idx int) string {
+	return fmt.Sprintf(shardPathFormat, key, idx)
+}
+
+// shardNodes returns the ring nodes holding each shard; shard i always lives on nodes[i].
+func (c *Coordinator) shardNodes(bucket, key string, layout domain.ErasureLayout) ([]string, error) {
+	if err := layout.Validate(); err != nil {
+		return nil, err
+	}
+	nodes := c.ring.GetNodes(bucket+"/"+key, layout.TotalShards())
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%s", errNoNodesAvailable)
+	}
+	if len(nodes) < layout.TotalShards() {
+		return nil, fmt.Errorf("erasure layout %d+%d needs %d storage nodes, only %d available",
+			layout.DataShards, layout.ParityShards, layout.TotalShards(), len(nodes))
+	}
+	return nodes, nil
+}
+
+// WriteErasure encodes data into DataShards+ParityShards Reed-Solomon shards and
+// stores one shard per ring node. The write succeeds once DataShards+1 shards are
+// persisted so that the object still survives one further node loss; missing
+// shards are rebuilt by read repair.
+func (c *Coordinator) WriteErasure(ctx context.Context, bucket, key string, r io.Reader, layout domain.ErasureLayout) (int64, int64, error) {
+	nodes, err := c.shardNodes(bucket, key, layout)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	size := int64(len(data))
+
+	shards, err := encodeShards(data, layout)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ts := time.Now().UnixNano()
+	stored, lastErr := c.storeShards(ctx, bucket, key, nodes, shards, ts)
+
+	quorum := erasureWriteQuorum(layout)
+	if stored < quorum {
+		platform.StorageOperations.WithLabelValues("cluster_ec_write", bucket, "quorum_failure").Inc()
+		return 0, 0, fmt.Errorf("erasure write quorum failed (%d/%d): %v", stored, quorum, lastErr)
+	}
+
+	platform.StorageOperations.WithLabelValues("cluster_ec_write", bucket, "success").Inc()
+	return size, layout.StoredBytes(size), nil
+}
+
+// ReadErasure fetches shards from their nodes and rebuilds the object from any
+// DataShards of them. Missing, stale or truncated shards are repaired in the background.
+func (c *Coordinator) ReadErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout, size int64) (io.ReadCloser, error) {
+	nodes, err := c.shardNodes(bucket, key, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	shards, ts, missing := c.collectShards(ctx, bucket, key, nodes, layout.ShardSize(size))
+	if len(nodes)-len(missing) < layout.DataShards {
+		platform.StorageOperations.WithLabelValues("cluster_ec_read", bucket, "not_found").Inc()
+		return nil, fmt.Errorf("object not found: only %d of %d required shards available", len(nodes)-len(missing), layout.DataShards)
+	}
+
+	enc, err := reedsolomon.New(layout.DataShards, layout.ParityShards)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > 0 && len(missing) > 0 {
+		if err := enc.Reconstruct(shards); err != nil {
+			platform.StorageOperations.WithLabelValues("cluster_ec_read", bucket, "reconstruct_failure").Inc()
+			return nil, fmt.Errorf("failed to reconstruct object: %w", err)
+		}
+		platform.StorageOperations.WithLabelValues("cluster_ec_read", bucket, "degraded").Inc()
+	}
+
+	if len(missing) > 0 {
+		repair := make(map[int][]byte, len(missing))
+		for _, idx := range missing {
+			repair[idx] = shards[idx]
+		}
+		go c.repairShards(context.Background(), bucket, key, nodes, repair, ts)
+	}
+
+	var buf bytes.Buffer
+	if size > 0 {
+		if err := enc.Join(&buf, shards, int(size)); err != nil {
+			return nil, fmt.Errorf("failed to join shards: %w", err)
+		}
+	}
+
+	platform.StorageOperations.WithLabelValues("cluster_ec_read", bucket, "success").Inc()
+	return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+}
+
+// RepairErasure rebuilds missing or stale shards of an object and writes them back
+// to the nodes that own them. It returns the number of shards rewritten.
+func (c *Coordinator) RepairErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout, size int64) (int, error) {
+	nodes, err := c.shardNodes(bucket, key, layout)
+	if err != nil {
+		return 0, err
+	}
+
+	shards, ts, missing := c.collectShards(ctx, bucket, key, nodes, layout.ShardSize(size))
+	if len(missing) == 0 {
+		return 0, nil
+	}
+	if len(nodes)-len(missing) < layout.DataShards {
+		return 0, fmt.Errorf("cannot repair: only %d of %d required shards available", len(nodes)-len(missing), layout.DataShards)
+	}
+
+	if size > 0 {
+		enc, err := reedsolomon.New(layout.DataShards, layout.ParityShards)
+		if err != nil {
+			return 0, err
+		}
+		if err := enc.Reconstruct(shards); err != nil {
+			return 0, fmt.Errorf("failed to reconstruct shards: %w", err)
+		}
+	}
+
+	repair := make(map[int][]byte, len(missing))
+	for _, idx := range missing {
+		repair[idx] = shards[idx]
+	}
+	return c.repairShards(ctx, bucket, key, nodes, repair, ts), nil
+}
+
+// DeleteErasure removes every shard of an object. Like Delete, it only fails when
+// no shard could be removed.
+func (c *Coordinator) DeleteErasure(ctx context.Context, bucket, key string, layout domain.ErasureLayout) error {
+	nodes, err := c.shardNodes(bucket, key, layout)
+	if err != nil {
+		return err
+	}
+
+	successCount := 0
+	for idx, nodeID := range nodes {
+		client, ok := c.clients[nodeID]
+		if !ok {
+			continue
+		}
+		if _, err := client.Delete(ctx, &pb.DeleteRequest{Bucket: bucket, Key: shardKey(key, idx)}); err == nil {
+			successCount++
+		}
+	}
+
+	if successCount == 0 {
+		platform.StorageOperations.WithLabelValues("cluster_ec_delete", bucket, "failure").Inc()
+		return fmt.Errorf("failed to delete any shard")
+	}
+
+	platform.StorageOperations.WithLabelValues("cluster_ec_delete", bucket, "success").Inc()
+	return nil
+}
+
+func erasureWriteQuorum(layout domain.ErasureLayout) int {
+	quorum := layout.DataShards + 1
+	if quorum > layout.TotalShards() {
+		quorum = layout.TotalShards()
+	}
+	return quorum
+}
+
+func encodeShards(data []byte, layout domain.ErasureLayout) ([][]byte, error) {
+	if len(data) == 0 {
+		// Reed-Solomon cannot split empty input; every shard of an empty object is empty.
+		return make([][]byte, layout.TotalShards()), nil
+	}
+
+	enc, err := reedsolomon.New(layout.DataShards, layout.ParityShards)
+	if err != nil {
+		return nil, err
+	}
+	shards, err := enc.Split(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split object into shards: %w", err)
+	}
+	if err := enc.Encode(shards); err != nil {
+		return nil, fmt.Errorf("failed to compute parity shards: %w", err)
+	}
+	return shards, nil
+}
+
+func (c *Coordinator) storeShards(ctx context.Context, bucket, key string, nodes []string, shards [][]byte, ts int64) (int, error) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	successCount := 0
+	var lastErr error
+
+	for idx, nodeID := range nodes {
+		client, ok := c.clients[nodeID]
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, cl pb.StorageNodeClient) {
+			defer wg.Done()
+			_, err := cl.Store(ctx, &pb.StoreRequest{Bucket: bucket, Key: shardKey(key, i), Data: shards[i], Timestamp: ts})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				lastErr = err
+			} else {
+				successCount++
+			}
+		}(idx, client)
+	}
+	wg.Wait()
+
+	return successCount, lastErr
+}
+
+type shardResult struct {
+	idx       int
+	data      []byte
+	timestamp int64
+	ok        bool
+}
+
+// collectShards fetches every shard in parallel. Shards that are unreachable,
+// absent, of the wrong size or older than the newest generation are reported as
+// missing and left nil so that the encoder can reconstruct them.
+func (c *Coordinator) collectShards(ctx context.Context, bucket, key string, nodes []string, shardSize int64) ([][]byte, int64, []int) {
+	results := make(chan shardResult, len(nodes))
+	var wg sync.WaitGroup
+
+	for idx, nodeID := range nodes {
+		client, ok := c.clients[nodeID]
+		if !ok {
+			results <- shardResult{idx: idx}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, cl pb.StorageNodeClient) {
+			defer wg.Done()
+			resp, err := cl.Retrieve(ctx, &pb.RetrieveRequest{Bucket: bucket, Key: shardKey(key, i)})
+			if err != nil || !resp.Found || int64(len(resp.Data)) != shardSize {
+				results <- shardResult{idx: i}
+				return
+			}
+			results <- shardResult{idx: i, data: resp.Data, timestamp: resp.Timestamp, ok: true}
+		}(idx, client)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	collected := make([]shardResult, len(nodes))
+	var latest int64
+	for res := range results {
+		collected[res.idx] = res
+		if res.ok && res.timestamp > latest {
+			latest = res.timestamp
+		}
+	}
+
+	shards := make([][]byte, len(nodes))
+	var missing []int
+	for i, res := range collected {
+		if !res.ok || res.timestamp < latest {
+			missing = append(missing, i)
+			continue
+		}
+		shards[i] = res.data
+		if shards[i] == nil {
+			shards[i] = []byte{}
+		}
+	}
+	return shards, latest, missing
+}
+
+func (c *Coordinator) repairShards(ctx context.Context, bucket, key string, nodes []string, shards map[int][]byte, ts int64) int {
+	repaired := 0
+	for idx, data := range shards {
+		client, ok := c.clients[nodes[idx]]
+		if !ok {
+			continue
+		}
+		if data == nil {
+			data = []byte{}
+		}
+		if _, err := client.Store(ctx, &pb.StoreRequest{Bucket: bucket, Key: shardKey(key, idx), Data: data, Timestamp: ts}); err == nil {
+			repaired++
+		}
+	}
+	if repaired > 0 {
+		platform.StorageOperations.WithLabelValues("cluster_ec_repair", bucket, "success").Add(float64(repaired))
+	}
+	return repaired
+}
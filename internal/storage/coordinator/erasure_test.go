@@ -0,0 +1,278 @@
+package coordinator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	pb "github.com/poyrazk/thecloud/internal/storage/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const (
+	ecBucket = "archive"
+	ecKey    = "backup.tar"
+)
+
+// memNodeClient is an in-memory storage node used to exercise shard placement.
+type memNodeClient struct {
+	pb.StorageNodeClient
+	mu   sync.Mutex
+	data map[string]*pb.RetrieveResponse
+	down bool
+}
+
+func newMemNodeClient() *memNodeClient {
+	return &memNodeClient{data: make(map[string]*pb.RetrieveResponse)}
+}
+
+func (m *memNodeClient) Store(ctx context.Context, in *pb.StoreRequest, opts ...grpc.CallOption) (*pb.StoreResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return nil, errors.New("node down")
+	}
+	m.data[in.Bucket+"/"+in.Key] = &pb.RetrieveResponse{Found: true, Data: append([]byte(nil), in.Data...), Timestamp: in.Timestamp}
+	return &pb.StoreResponse{Success: true}, nil
+}
+
+func (m *memNodeClient) Retrieve(ctx context.Context, in *pb.RetrieveRequest, opts ...grpc.CallOption) (*pb.RetrieveResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return nil, errors.New("node down")
+	}
+	if resp, ok := m.data[in.Bucket+"/"+in.Key]; ok {
+		return resp, nil
+	}
+	return &pb.RetrieveResponse{Found: false}, nil
+}
+
+func (m *memNodeClient) Delete(ctx context.Context, in *pb.DeleteRequest, opts ...grpc.CallOption) (*pb.DeleteResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return nil, errors.New("node down")
+	}
+	delete(m.data, in.Bucket+"/"+in.Key)
+	return &pb.DeleteResponse{Success: true}, nil
+}
+
+func (m *memNodeClient) GetClusterStatus(ctx context.Context, in *pb.Empty, opts ...grpc.CallOption) (*pb.ClusterStatusResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *memNodeClient) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.data)
+}
+
+func (m *memNodeClient) setDown(down bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.down = down
+}
+
+func (m *memNodeClient) wipe() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = make(map[string]*pb.RetrieveResponse)
+}
+
+func setupErasureCluster(t *testing.T, nodeCount int) (*Coordinator, map[string]*memNodeClient) {
+	t.Helper()
+	ring := NewConsistentHashRing(10)
+	clients := make(map[string]pb.StorageNodeClient, nodeCount)
+	nodes := make(map[string]*memNodeClient, nodeCount)
+	for i := 1; i <= nodeCount; i++ {
+		id := fmt.Sprintf("node-%d", i)
+		ring.AddNode(id)
+		n := newMemNodeClient()
+		nodes[id] = n
+		clients[id] = n
+	}
+	coord := NewCoordinator(ring, clients, 3)
+	t.Cleanup(coord.Stop)
+	return coord, nodes
+}
+
+func ecPayload() []byte {
+	return bytes.Repeat([]byte("erasure-coded-archive-"), 100)
+}
+
+func TestCoordinatorWriteErasureSpreadsShards(t *testing.T) {
+	coord, nodes := setupErasureCluster(t, 6)
+	layout := domain.ErasureLayout{DataShards: 4, ParityShards: 2}
+	data := ecPayload()
+
+	size, stored, err := coord.WriteErasure(context.Background(), ecBucket, ecKey, bytes.NewReader(data), layout)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), size)
+	assert.Equal(t, layout.StoredBytes(int64(len(data))), stored)
+	assert.Less(t, stored, int64(len(data))*3, "erasure coding should use less space than three replicas")
+
+	for id, n := range nodes {
+		assert.Equal(t, 1, n.count(), "node %s should hold exactly one shard", id)
+	}
+}
+
+func TestCoordinatorWriteErasureNotEnoughNodes(t *testing.T) {
+	coord, _ := setupErasureCluster(t, 3)
+
+	_, _, err := coord.WriteErasure(context.Background(), ecBucket, ecKey, bytes.NewReader(ecPayload()), domain.ErasureLayout{DataShards: 4, ParityShards: 2})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "needs 6 storage nodes")
+}
+
+func TestCoordinatorWriteErasureQuorumFailure(t *testing.T) {
+	coord, nodes := setupErasureCluster(t, 6)
+	layout := domain.ErasureLayout{DataShards: 4, ParityShards: 2}
+
+	down := 0
+	for _, n := range nodes {
+		if down == 2 {
+			break
+		}
+		n.setDown(true)
+		down++
+	}
+
+	_, _, err := coord.WriteErasure(context.Background(), ecBucket, ecKey, bytes.NewReader(ecPayload()), layout)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "erasure write quorum failed")
+}
+
+func TestCoordinatorReadErasure(t *testing.T) {
+	layout := domain.ErasureLayout{DataShards: 4, ParityShards: 2}
+	data := ecPayload()
+
+	tests := []struct {
+		name     string
+		lose     int
+		wantErr  bool
+		repaired int
+	}{
+		{name: "all shards healthy", lose: 0},
+		{name: "degraded read with one shard lost", lose: 1, repaired: 1},
+		{name: "degraded read with parity-many shards lost", lose: 2, repaired: 2},
+		{name: "too many shards lost", lose: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, nodes := setupErasureCluster(t, 6)
+			_, _, err := coord.WriteErasure(context.Background(), ecBucket, ecKey, bytes.NewReader(data), layout)
+			require.NoError(t, err)
+
+			var wiped []*memNodeClient
+			for _, n := range nodes {
+				if len(wiped) == tt.lose {
+					break
+				}
+				n.wipe()
+				wiped = append(wiped, n)
+			}
+
+			rc, err := coord.ReadErasure(context.Background(), ecBucket, ecKey, layout, int64(len(data)))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			got, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+
+			// Read repair runs asynchronously and rewrites the lost shards.
+			assert.Eventually(t, func() bool {
+				for _, n := range wiped {
+					if n.count() != 1 {
+						return false
+					}
+				}
+				return true
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestCoordinatorReadErasureNodeDown(t *testing.T) {
+	coord, nodes := setupErasureCluster(t, 6)
+	layout := domain.ErasureLayout{DataShards: 4, ParityShards: 2}
+	data := ecPayload()
+
+	_, _, err := coord.WriteErasure(context.Background(), ecBucket, ecKey, bytes.NewReader(data), layout)
+	require.NoError(t, err)
+
+	nodes["node-1"].setDown(true)
+	nodes["node-2"].setDown(true)
+
+	rc, err := coord.ReadErasure(context.Background(), ecBucket, ecKey, layout, int64(len(data)))
+	require.NoError(t, err)
+	got, _ := io.ReadAll(rc)
+	assert.Equal(t, data, got)
+}
+
+func TestCoordinatorRepairErasure(t *testing.T) {
+	coord, nodes := setupErasureCluster(t, 6)
+	layout := domain.ErasureLayout{DataShards: 4, ParityShards: 2}
+	data := ecPayload()
+
+	_, _, err := coord.WriteErasure(context.Background(), ecBucket, ecKey, bytes.NewReader(data), layout)
+	require.NoError(t, err)
+
+	repaired, err := coord.RepairErasure(context.Background(), ecBucket, ecKey, layout, int64(len(data)))
+	require.NoError(t, err)
+	assert.Equal(t, 0, repaired)
+
+	nodes["node-3"].wipe()
+	repaired, err = coord.RepairErasure(context.Background(), ecBucket, ecKey, layout, int64(len(data)))
+	require.NoError(t, err)
+	assert.Equal(t, 1, repaired)
+	assert.Equal(t, 1, nodes["node-3"].count())
+
+	// With the repaired shard back, the object survives losing two other nodes.
+	nodes["node-1"].setDown(true)
+	nodes["node-2"].setDown(true)
+	rc, err := coord.ReadErasure(context.Background(), ecBucket, ecKey, layout, int64(len(data)))
+	require.NoError(t, err)
+	got, _ := io.ReadAll(rc)
+	assert.Equal(t, data, got)
+}
+
+func TestCoordinatorErasureEmptyObject(t *testing.T) {
+	coord, _ := setupErasureCluster(t, 6)
+	layout := domain.ErasureLayout{DataShards: 4, ParityShards: 2}
+
+	size, stored, err := coord.WriteErasure(context.Background(), ecBucket, ecKey, bytes.NewReader(nil), layout)
+	require.NoError(t, err)
+	assert.Zero(t, size)
+	assert.Zero(t, stored)
+
+	rc, err := coord.ReadErasure(context.Background(), ecBucket, ecKey, layout, 0)
+	require.NoError(t, err)
+	got, _ := io.ReadAll(rc)
+	assert.Empty(t, got)
+}
+
+func TestCoordinatorDeleteErasure(t *testing.T) {
+	coord, nodes := setupErasureCluster(t, 6)
+	layout := domain.ErasureLayout{DataShards: 4, ParityShards: 2}
+
+	_, _, err := coord.WriteErasure(context.Background(), ecBucket, ecKey, bytes.NewReader(ecPayload()), layout)
+	require.NoError(t, err)
+
+	require.NoError(t, coord.DeleteErasure(context.Background(), ecBucket, ecKey, layout))
+	for id, n := range nodes {
+		assert.Zero(t, n.count(), "node %s should hold no shards", id)
+	}
+}
@@ -0,0 +1,144 @@
+// Package workers provides background worker implementations.
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+)
+
+const defaultCertificateExpiryInterval = time.Hour
+
+// certificateExpiryAlert is the CloudNotify message sent as a certificate
+// approaches or passes its expiry.
+type certificateExpiryAlert struct {
+	Event         string    `json:"event"` // "certificate.expiring" | "certificate.expired"
+	CertificateID string    `json:"certificate_id"`
+	Name          string    `json:"name"`
+	Domains       []string  `json:"domains"`
+	NotAfter      time.Time `json:"not_after"`
+	DaysRemaining int       `json:"days_remaining"`
+}
+
+// CertificateExpiryWorker keeps certificate statuses current and alerts the
+// certificate's notification topic as expiry approaches.
+type CertificateExpiryWorker struct {
+	repo      ports.CertificateRepository
+	notifySvc ports.NotifyService
+	logger    *slog.Logger
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// NewCertificateExpiryWorker constructs a CertificateExpiryWorker.
+func NewCertificateExpiryWorker(repo ports.CertificateRepository, notifySvc ports.NotifyService, logger *slog.Logger) *CertificateExpiryWorker {
+	return &CertificateExpiryWorker{
+		repo:      repo,
+		notifySvc: notifySvc,
+		logger:    logger,
+		interval:  defaultCertificateExpiryInterval,
+		now:       time.Now,
+	}
+}
+
+func (w *CertificateExpiryWorker) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	w.logger.Info("certificate expiry worker started", "interval", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.checkCertificates(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("certificate expiry worker stopping")
+			return
+		case <-ticker.C:
+			w.checkCertificates(ctx)
+		}
+	}
+}
+
+func (w *CertificateExpiryWorker) checkCertificates(ctx context.Context) {
+	certs, err := w.repo.ListAll(ctx)
+	if err != nil {
+		w.logger.Error("failed to list certificates", "error", err)
+		return
+	}
+	now := w.now()
+	for _, cert := range certs {
+		w.checkCertificate(ctx, cert, now)
+	}
+}
+
+func (w *CertificateExpiryWorker) checkCertificate(ctx context.Context, cert *domain.Certificate, now time.Time) {
+	status := domain.CertificateStatusAt(cert.NotAfter, now)
+	alertDays := cert.ExpiryAlertDays
+
+	if threshold, ok := crossedExpiryThreshold(cert.NotAfter, now); ok && (alertDays == nil || threshold < *alertDays) {
+		if err := w.alert(ctx, cert, status, now); err != nil {
+			w.logger.Error("failed to send certificate expiry alert", "certificate_id", cert.ID, "error", err)
+		} else {
+			alertDays = &threshold
+		}
+	}
+
+	if status == cert.Status && alertDays == cert.ExpiryAlertDays {
+		return
+	}
+	if err := w.repo.UpdateExpiryState(ctx, cert.ID, status, alertDays); err != nil {
+		w.logger.Error("failed to update certificate expiry state", "certificate_id", cert.ID, "error", err)
+	}
+}
+
+// crossedExpiryThreshold returns the smallest entry of
+// domain.CertificateExpiryAlertDays that the time left before notAfter has
+// fallen to.
+func crossedExpiryThreshold(notAfter, now time.Time) (int, bool) {
+	remaining := notAfter.Sub(now)
+	threshold, crossed := 0, false
+	for _, days := range domain.CertificateExpiryAlertDays {
+		if remaining <= time.Duration(days)*24*time.Hour && (!crossed || days < threshold) {
+			threshold, crossed = days, true
+		}
+	}
+	return threshold, crossed
+}
+
+// alert publishes an expiry alert to the certificate's topic, if it has one.
+func (w *CertificateExpiryWorker) alert(ctx context.Context, cert *domain.Certificate, status domain.CertificateStatus, now time.Time) error {
+	w.logger.Warn("certificate nearing expiry", "certificate_id", cert.ID, "name", cert.Name, "not_after", cert.NotAfter)
+	if cert.NotifyTopicID == nil || w.notifySvc == nil {
+		return nil
+	}
+
+	event := "certificate.expiring"
+	if status == domain.CertificateStatusExpired {
+		event = "certificate.expired"
+	}
+	body, err := json.Marshal(certificateExpiryAlert{
+		Event:         event,
+		CertificateID: cert.ID.String(),
+		Name:          cert.Name,
+		Domains:       cert.Domains,
+		NotAfter:      cert.NotAfter,
+		DaysRemaining: max(0, int(cert.NotAfter.Sub(now).Hours()/24)),
+	})
+	if err != nil {
+		return err
+	}
+
+	ownerCtx := appcontext.WithUserID(ctx, cert.UserID)
+	if cert.TenantID != uuid.Nil {
+		ownerCtx = appcontext.WithTenantID(ownerCtx, cert.TenantID)
+	}
+	return w.notifySvc.Publish(ownerCtx, *cert.NotifyTopicID, string(body))
+}
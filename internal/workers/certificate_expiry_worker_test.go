@@ -0,0 +1,111 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCertificateRepo struct {
+	ports.CertificateRepository
+	certs []*domain.Certificate
+}
+
+func (r *fakeCertificateRepo) ListAll(context.Context) ([]*domain.Certificate, error) {
+	return r.certs, nil
+}
+
+func (r *fakeCertificateRepo) UpdateExpiryState(_ context.Context, id uuid.UUID, status domain.CertificateStatus, alertDays *int) error {
+	for _, c := range r.certs {
+		if c.ID == id {
+			c.Status, c.ExpiryAlertDays = status, alertDays
+		}
+	}
+	return nil
+}
+
+type publishedAlert struct {
+	userID uuid.UUID
+	topic  uuid.UUID
+	alert  certificateExpiryAlert
+}
+
+type fakeCertificateNotifyService struct {
+	ports.NotifyService
+	published []publishedAlert
+}
+
+func (n *fakeCertificateNotifyService) Publish(ctx context.Context, topicID uuid.UUID, message string) error {
+	var alert certificateExpiryAlert
+	if err := json.Unmarshal([]byte(message), &alert); err != nil {
+		return err
+	}
+	n.published = append(n.published, publishedAlert{userID: appcontext.UserIDFromContext(ctx), topic: topicID, alert: alert})
+	return nil
+}
+
+func TestCertificateExpiryWorkerAlerts(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	topic := uuid.New()
+	cert := &domain.Certificate{
+		ID: uuid.New(), UserID: uuid.New(), Name: "web", Domains: []string{"app.example.com"},
+		Status: domain.CertificateStatusActive, NotAfter: start.Add(45 * 24 * time.Hour), NotifyTopicID: &topic,
+	}
+	silent := &domain.Certificate{ID: uuid.New(), Name: "quiet", Status: domain.CertificateStatusActive, NotAfter: start.Add(5 * 24 * time.Hour)}
+	repo := &fakeCertificateRepo{certs: []*domain.Certificate{cert, silent}}
+	notify := &fakeCertificateNotifyService{}
+
+	w := NewCertificateExpiryWorker(repo, notify, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+	check := func(at time.Time) {
+		w.now = func() time.Time { return at }
+		w.checkCertificates(ctx)
+	}
+
+	check(start)
+	assert.Empty(t, notify.published)
+	assert.Nil(t, cert.ExpiryAlertDays)
+
+	// 20 days left: the 30-day alert fires once.
+	check(start.Add(25 * 24 * time.Hour))
+	check(start.Add(26 * 24 * time.Hour))
+	require.Len(t, notify.published, 1)
+	sent := notify.published[0]
+	assert.Equal(t, topic, sent.topic)
+	assert.Equal(t, cert.UserID, sent.userID, "alerts are published as the certificate owner")
+	assert.Equal(t, "certificate.expiring", sent.alert.Event)
+	assert.Equal(t, 20, sent.alert.DaysRemaining)
+	assert.Equal(t, domain.CertificateStatusExpiring, cert.Status)
+	require.NotNil(t, cert.ExpiryAlertDays)
+	assert.Equal(t, 30, *cert.ExpiryAlertDays)
+
+	// A certificate without a topic still has its status tracked.
+	assert.Equal(t, domain.CertificateStatusExpired, silent.Status)
+	require.NotNil(t, silent.ExpiryAlertDays)
+	assert.Equal(t, 0, *silent.ExpiryAlertDays)
+
+	// Several thresholds passed between checks send a single alert.
+	check(start.Add(44*24*time.Hour + time.Hour))
+	require.Len(t, notify.published, 2)
+	assert.Equal(t, 1, *cert.ExpiryAlertDays)
+
+	check(start.Add(46 * 24 * time.Hour))
+	require.Len(t, notify.published, 3)
+	assert.Equal(t, "certificate.expired", notify.published[2].alert.Event)
+	assert.Equal(t, 0, notify.published[2].alert.DaysRemaining)
+	assert.Equal(t, domain.CertificateStatusExpired, cert.Status)
+
+	check(start.Add(50 * 24 * time.Hour))
+	assert.Len(t, notify.published, 3)
+}
@@ -0,0 +1,71 @@
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+)
+
+// ErasureRepairWorker periodically walks every erasure-coded object and
+// rebuilds shards lost with a storage node. Degraded reads repair the objects
+// they touch; this worker covers the ones nobody reads.
+type ErasureRepairWorker struct {
+	storageSvc ports.StorageService
+	logger     *slog.Logger
+	interval   time.Duration
+	batchSize  int
+}
+
+// NewErasureRepairWorker constructs an ErasureRepairWorker.
+func NewErasureRepairWorker(storageSvc ports.StorageService, logger *slog.Logger) *ErasureRepairWorker {
+	return &ErasureRepairWorker{
+		storageSvc: storageSvc,
+		logger:     logger,
+		interval:   6 * time.Hour,
+		batchSize:  100,
+	}
+}
+
+func (w *ErasureRepairWorker) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	w.logger.Info("erasure repair worker started")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("erasure repair worker stopping")
+			return
+		case <-ticker.C:
+			w.repair(ctx)
+		}
+	}
+}
+
+// repair makes one pass over all erasure-coded objects.
+func (w *ErasureRepairWorker) repair(ctx context.Context) {
+	total := 0
+	after := uuid.Nil
+	for ctx.Err() == nil {
+		last, repaired, err := w.storageSvc.RepairErasureObjects(ctx, after, w.batchSize)
+		if err != nil {
+			w.logger.Error("failed to repair erasure-coded objects", "error", err)
+			break
+		}
+		total += repaired
+		if last == uuid.Nil {
+			break
+		}
+		after = last
+	}
+
+	if total > 0 {
+		w.logger.Info("erasure repair completed", "shards_repaired", total)
+	}
+}
@@ -0,0 +1,38 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestErasureRepairWorker_Repair(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("WalksEveryBatch", func(t *testing.T) {
+		svc := new(mockStorageService)
+		worker := NewErasureRepairWorker(svc, logger)
+		worker.batchSize = 2
+		first, second := uuid.New(), uuid.New()
+		svc.On("RepairErasureObjects", mock.Anything, uuid.Nil, 2).Return(first, 3, nil).Once()
+		svc.On("RepairErasureObjects", mock.Anything, first, 2).Return(second, 0, nil).Once()
+		svc.On("RepairErasureObjects", mock.Anything, second, 2).Return(uuid.Nil, 0, nil).Once()
+
+		worker.repair(context.Background())
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("StopsOnError", func(t *testing.T) {
+		svc := new(mockStorageService)
+		worker := NewErasureRepairWorker(svc, logger)
+		svc.On("RepairErasureObjects", mock.Anything, uuid.Nil, worker.batchSize).Return(uuid.Nil, 0, errors.New("db down")).Once()
+
+		worker.repair(context.Background())
+		svc.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,50 @@
+// Package workers provides background worker implementations.
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/poyrazk/thecloud/internal/core/ports"
+)
+
+const defaultFlowLogInterval = 1 * time.Minute
+
+// FlowLogWorker periodically samples VPC bridges and ingests flow records into CloudLogs.
+type FlowLogWorker struct {
+	flowLogSvc ports.FlowLogService
+	logger     *slog.Logger
+	interval   time.Duration
+}
+
+// NewFlowLogWorker constructs a FlowLogWorker.
+func NewFlowLogWorker(flowLogSvc ports.FlowLogService, logger *slog.Logger) *FlowLogWorker {
+	return &FlowLogWorker{
+		flowLogSvc: flowLogSvc,
+		logger:     logger,
+		interval:   defaultFlowLogInterval,
+	}
+}
+
+// Run collects flows on every tick until the context is cancelled.
+func (w *FlowLogWorker) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	w.logger.Info("flow log worker started", "interval", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("flow log worker stopping")
+			return
+		case <-ticker.C:
+			if err := w.flowLogSvc.CollectFlows(ctx); err != nil {
+				w.logger.Error("failed to collect flow logs", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockFlowLogService struct {
+	mock.Mock
+}
+
+func (m *mockFlowLogService) CreateFlowLog(ctx context.Context, resourceType domain.FlowLogResourceType, resourceID uuid.UUID, trafficType domain.FlowLogTrafficType) (*domain.FlowLog, error) {
+	args := m.Called(ctx, resourceType, resourceID, trafficType)
+	return args.Get(0).(*domain.FlowLog), args.Error(1)
+}
+func (m *mockFlowLogService) GetFlowLog(ctx context.Context, id uuid.UUID) (*domain.FlowLog, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*domain.FlowLog), args.Error(1)
+}
+func (m *mockFlowLogService) ListFlowLogs(ctx context.Context) ([]*domain.FlowLog, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.FlowLog), args.Error(1)
+}
+func (m *mockFlowLogService) DeleteFlowLog(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *mockFlowLogService) CollectFlows(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func TestFlowLogWorker_Run(t *testing.T) {
+	mockSvc := new(mockFlowLogService)
+	worker := &FlowLogWorker{
+		flowLogSvc: mockSvc,
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		interval:   10 * time.Millisecond,
+	}
+
+	// A failed collection must not stop the loop.
+	mockSvc.On("CollectFlows", mock.Anything).Return(errors.New("ovs unavailable")).Once()
+	mockSvc.On("CollectFlows", mock.Anything).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go worker.Run(ctx, &wg)
+
+	time.Sleep(35 * time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	mockSvc.AssertExpectations(t)
+	assert.GreaterOrEqual(t, len(mockSvc.Calls), 2)
+}
+
+func TestNewFlowLogWorker(t *testing.T) {
+	worker := NewFlowLogWorker(new(mockFlowLogService), slog.Default())
+	assert.Equal(t, defaultFlowLogInterval, worker.interval)
+}
@@ -0,0 +1,61 @@
+// Package workers provides background worker implementations.
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/poyrazk/thecloud/internal/core/ports"
+)
+
+const (
+	defaultGatewayAccessLogInterval = 5 * time.Second
+	gatewayAccessLogFlushTimeout    = 10 * time.Second
+)
+
+// GatewayAccessLogWorker periodically ingests the gateway's buffered access logs into CloudLogs.
+type GatewayAccessLogWorker struct {
+	gatewaySvc ports.GatewayService
+	logger     *slog.Logger
+	interval   time.Duration
+}
+
+// NewGatewayAccessLogWorker constructs a GatewayAccessLogWorker.
+func NewGatewayAccessLogWorker(gatewaySvc ports.GatewayService, logger *slog.Logger) *GatewayAccessLogWorker {
+	return &GatewayAccessLogWorker{
+		gatewaySvc: gatewaySvc,
+		logger:     logger,
+		interval:   defaultGatewayAccessLogInterval,
+	}
+}
+
+// Run flushes access logs on every tick, and once more when the context is
+// cancelled so requests served during shutdown are not lost.
+func (w *GatewayAccessLogWorker) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	w.logger.Info("gateway access log worker started", "interval", w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), gatewayAccessLogFlushTimeout)
+			w.flush(flushCtx)
+			cancel()
+			w.logger.Info("gateway access log worker stopping")
+			return
+		case <-ticker.C:
+			w.flush(ctx)
+		}
+	}
+}
+
+func (w *GatewayAccessLogWorker) flush(ctx context.Context) {
+	if err := w.gatewaySvc.FlushAccessLogs(ctx); err != nil {
+		w.logger.Error("failed to flush gateway access logs", "error", err)
+	}
+}
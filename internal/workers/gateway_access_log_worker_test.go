@@ -0,0 +1,58 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockGatewayAccessLogService struct {
+	ports.GatewayService
+	mock.Mock
+	lastCtxErr error
+}
+
+func (m *mockGatewayAccessLogService) FlushAccessLogs(ctx context.Context) error {
+	m.lastCtxErr = ctx.Err()
+	return m.Called(ctx).Error(0)
+}
+
+func TestGatewayAccessLogWorker_Run(t *testing.T) {
+	mockSvc := new(mockGatewayAccessLogService)
+	worker := &GatewayAccessLogWorker{
+		gatewaySvc: mockSvc,
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		interval:   10 * time.Millisecond,
+	}
+
+	// A failed flush must not stop the loop.
+	mockSvc.On("FlushAccessLogs", mock.Anything).Return(errors.New("log store unavailable")).Once()
+	mockSvc.On("FlushAccessLogs", mock.Anything).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go worker.Run(ctx, &wg)
+
+	time.Sleep(35 * time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	calls := 0
+	for _, c := range mockSvc.Calls {
+		if c.Method == "FlushAccessLogs" {
+			calls++
+		}
+	}
+	assert.GreaterOrEqual(t, calls, 3, "flushes keep running after an error and once more on shutdown")
+
+	assert.NoError(t, mockSvc.lastCtxErr, "the shutdown flush runs on a fresh context")
+}
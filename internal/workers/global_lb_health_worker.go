@@ -0,0 +1,314 @@
+package workers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+)
+
+const (
+	globalLBHealthTick           = 5 * time.Second
+	globalLBHealthHistoryTTL     = 24 * time.Hour
+	globalLBHealthPruneInterval  = time.Hour
+	defaultGlobalLBProbeInterval = 30 * time.Second
+	defaultGlobalLBProbeTimeout  = 5 * time.Second
+	defaultGlobalLBThreshold     = 2
+)
+
+// endpointProbeState tracks consecutive probe outcomes for one endpoint.
+type endpointProbeState struct {
+	successes int
+	failures  int
+}
+
+// GlobalLBHealthWorker actively probes Global Load Balancer endpoints and keeps
+// their GeoDNS records limited to endpoints that pass their health checks.
+type GlobalLBHealthWorker struct {
+	repo   ports.GlobalLBRepository
+	lbRepo ports.LBRepository
+	geoDNS ports.GeoDNSBackend
+	logger *slog.Logger
+
+	tick time.Duration
+
+	nextProbe map[uuid.UUID]time.Time
+	states    map[uuid.UUID]*endpointProbeState
+	lastPrune time.Time
+}
+
+// NewGlobalLBHealthWorker constructs a GlobalLBHealthWorker.
+func NewGlobalLBHealthWorker(repo ports.GlobalLBRepository, lbRepo ports.LBRepository, geoDNS ports.GeoDNSBackend, logger *slog.Logger) *GlobalLBHealthWorker {
+	return &GlobalLBHealthWorker{
+		repo:      repo,
+		lbRepo:    lbRepo,
+		geoDNS:    geoDNS,
+		logger:    logger.With("worker", "global_lb_health"),
+		tick:      globalLBHealthTick,
+		nextProbe: make(map[uuid.UUID]time.Time),
+		states:    make(map[uuid.UUID]*endpointProbeState),
+	}
+}
+
+// Run starts the health check loop. Each Global Load Balancer is probed on its
+// own configured interval; the tick only bounds how late a probe can start.
+func (w *GlobalLBHealthWorker) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	w.logger.Info("starting global lb health worker", "tick", w.tick)
+	ticker := time.NewTicker(w.tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("stopping global lb health worker")
+			return
+		case <-ticker.C:
+			w.checkAll(ctx, time.Now())
+		}
+	}
+}
+
+func (w *GlobalLBHealthWorker) checkAll(ctx context.Context, now time.Time) {
+	glbs, err := w.repo.ListAll(ctx)
+	if err != nil {
+		w.logger.Error("failed to list global load balancers", "error", err)
+		return
+	}
+
+	for _, glb := range glbs {
+		if glb.HealthCheck.Protocol == "" || glb.Status != "ACTIVE" {
+			continue
+		}
+		if due, ok := w.nextProbe[glb.ID]; ok && now.Before(due) {
+			continue
+		}
+		w.nextProbe[glb.ID] = now.Add(probeInterval(glb.HealthCheck))
+		// Regional LB lookups are scoped to the owner.
+		w.checkGLB(appcontext.WithUserID(ctx, glb.UserID), glb)
+	}
+
+	if now.Sub(w.lastPrune) >= globalLBHealthPruneInterval {
+		w.lastPrune = now
+		if err := w.repo.DeleteHealthChecksBefore(ctx, now.Add(-globalLBHealthHistoryTTL)); err != nil {
+			w.logger.Error("failed to prune global lb health history", "error", err)
+		}
+	}
+}
+
+// checkGLB probes every endpoint of a Global Load Balancer concurrently, applies
+// the healthy/unhealthy thresholds and republishes DNS if any endpoint flipped.
+func (w *GlobalLBHealthWorker) checkGLB(ctx context.Context, glb *domain.GlobalLoadBalancer) {
+	endpoints, err := w.repo.ListEndpoints(ctx, glb.ID)
+	if err != nil {
+		w.logger.Error("failed to list global lb endpoints", "glb_id", glb.ID, "error", err)
+		return
+	}
+
+	results := make([]*domain.GlobalEndpointHealthCheck, len(endpoints))
+	var wg sync.WaitGroup
+	for i, ep := range endpoints {
+		wg.Add(1)
+		go func(i int, ep *domain.GlobalEndpoint) {
+			defer wg.Done()
+			results[i] = w.probe(ctx, glb, ep)
+		}(i, ep)
+	}
+	wg.Wait()
+
+	changed := false
+	for i, ep := range endpoints {
+		result := results[i]
+		if err := w.repo.RecordHealthCheck(ctx, result); err != nil {
+			w.logger.Warn("failed to record health check", "endpoint_id", ep.ID, "error", err)
+		}
+
+		healthy := w.applyThresholds(glb.HealthCheck, ep, result.Healthy)
+		if healthy != ep.Healthy {
+			changed = true
+			w.logger.Info("global lb endpoint health changed",
+				"glb_id", glb.ID, "endpoint_id", ep.ID, "region", ep.Region, "healthy", healthy, "error", result.Error)
+		}
+		ep.Healthy = healthy
+		if err := w.repo.UpdateEndpointHealth(ctx, ep.ID, healthy); err != nil {
+			w.logger.Error("failed to update endpoint health", "endpoint_id", ep.ID, "error", err)
+		}
+	}
+
+	if changed {
+		if err := w.geoDNS.CreateGeoRecord(ctx, glb.Hostname, glb.ServingEndpoints(endpoints)); err != nil {
+			w.logger.Error("failed to republish geo dns records", "hostname", glb.Hostname, "error", err)
+		}
+	}
+}
+
+// applyThresholds folds one probe outcome into the endpoint's streak and
+// returns its resulting health state.
+func (w *GlobalLBHealthWorker) applyThresholds(hc domain.GlobalHealthCheckConfig, ep *domain.GlobalEndpoint, success bool) bool {
+	state, ok := w.states[ep.ID]
+	if !ok {
+		state = &endpointProbeState{}
+		w.states[ep.ID] = state
+	}
+	if success {
+		state.successes++
+		state.failures = 0
+	} else {
+		state.failures++
+		state.successes = 0
+	}
+
+	switch {
+	case !ep.Healthy && state.successes >= threshold(hc.HealthyCount):
+		return true
+	case ep.Healthy && state.failures >= threshold(hc.UnhealthyCount):
+		return false
+	default:
+		return ep.Healthy
+	}
+}
+
+func (w *GlobalLBHealthWorker) probe(ctx context.Context, glb *domain.GlobalLoadBalancer, ep *domain.GlobalEndpoint) *domain.GlobalEndpointHealthCheck {
+	result := &domain.GlobalEndpointHealthCheck{
+		ID:         uuid.New(),
+		EndpointID: ep.ID,
+		GlobalLBID: glb.ID,
+		CheckedAt:  time.Now(),
+	}
+
+	host, err := w.endpointHost(ctx, ep)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	hc := glb.HealthCheck
+	timeout := defaultGlobalLBProbeTimeout
+	if hc.TimeoutSec > 0 {
+		timeout = time.Duration(hc.TimeoutSec) * time.Second
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	protocol := strings.ToUpper(hc.Protocol)
+	addr := net.JoinHostPort(host, strconv.Itoa(probePort(protocol, hc.Port)))
+
+	start := time.Now()
+	switch protocol {
+	case "HTTP", "HTTPS":
+		result.StatusCode, err = probeHTTP(probeCtx, protocol, addr, glb.Hostname, hc.Path)
+		if err == nil && (result.StatusCode < 200 || result.StatusCode >= 400) {
+			err = fmt.Errorf("unexpected status %d", result.StatusCode)
+		}
+	case "TCP":
+		var conn net.Conn
+		conn, err = (&net.Dialer{}).DialContext(probeCtx, "tcp", addr)
+		if err == nil {
+			_ = conn.Close()
+		}
+	default:
+		err = fmt.Errorf("unsupported health check protocol %q", hc.Protocol)
+	}
+	result.LatencyMs = time.Since(start).Milliseconds()
+
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Healthy = true
+	return result
+}
+
+// endpointHost resolves the address to probe: the static IP, or the regional
+// load balancer's IP for LB endpoints.
+func (w *GlobalLBHealthWorker) endpointHost(ctx context.Context, ep *domain.GlobalEndpoint) (string, error) {
+	switch ep.TargetType {
+	case "IP":
+		if ep.TargetIP == nil || *ep.TargetIP == "" {
+			return "", fmt.Errorf("endpoint has no target ip")
+		}
+		return *ep.TargetIP, nil
+	case "LB":
+		if ep.TargetID == nil {
+			return "", fmt.Errorf("endpoint has no target load balancer")
+		}
+		lb, err := w.lbRepo.GetByID(ctx, *ep.TargetID)
+		if err != nil {
+			return "", fmt.Errorf("target load balancer lookup failed: %w", err)
+		}
+		if lb == nil || lb.IP == "" {
+			return "", fmt.Errorf("target load balancer has no ip")
+		}
+		return lb.IP, nil
+	default:
+		return "", fmt.Errorf("unsupported target type %q", ep.TargetType)
+	}
+}
+
+// probeHTTP issues a GET with the Global Load Balancer's hostname as the Host
+// header. Certificates are not verified: the probe checks that the endpoint
+// serves, and endpoints are addressed by IP.
+func probeHTTP(ctx context.Context, protocol, addr, hostname, path string) (int, error) {
+	if path == "" {
+		path = "/"
+	} else if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.ToLower(protocol)+"://"+addr+path, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Host = hostname
+	req.Header.Set("User-Agent", "thecloud-global-lb-health-check")
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true, ServerName: hostname}, //nolint:gosec // reachability probe, see above
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func probeInterval(hc domain.GlobalHealthCheckConfig) time.Duration {
+	if hc.IntervalSec > 0 {
+		return time.Duration(hc.IntervalSec) * time.Second
+	}
+	return defaultGlobalLBProbeInterval
+}
+
+func probePort(protocol string, port int) int {
+	switch {
+	case port > 0:
+		return port
+	case protocol == "HTTPS":
+		return 443
+	default:
+		return 80
+	}
+}
+
+func threshold(n int) int {
+	if n > 0 {
+		return n
+	}
+	return defaultGlobalLBThreshold
+}
@@ -0,0 +1,158 @@
+package workers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/repositories/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupGlobalLBHealthWorker(t *testing.T, hc domain.GlobalHealthCheckConfig) (*GlobalLBHealthWorker, *mock.MockGlobalLBRepo, *mock.MockGeoDNS, *domain.GlobalLoadBalancer) {
+	t.Helper()
+	repo := mock.NewMockGlobalLBRepo()
+	geoDNS := mock.NewMockGeoDNS().(*mock.MockGeoDNS)
+	w := NewGlobalLBHealthWorker(repo, mock.NewMockLBRepo(), geoDNS, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	glb := &domain.GlobalLoadBalancer{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		Hostname:    "api.global.test",
+		Policy:      domain.RoutingFailover,
+		Status:      "ACTIVE",
+		HealthCheck: hc,
+	}
+	repo.GLBs[glb.ID] = glb
+	return w, repo, geoDNS, glb
+}
+
+func addTestEndpoint(repo *mock.MockGlobalLBRepo, glb *domain.GlobalLoadBalancer, region string, priority int) *domain.GlobalEndpoint {
+	ip := "127.0.0.1"
+	ep := &domain.GlobalEndpoint{ID: uuid.New(), GlobalLBID: glb.ID, Region: region, TargetType: "IP", TargetIP: &ip, Priority: priority, Healthy: true}
+	repo.Endpoints[glb.ID] = append(repo.Endpoints[glb.ID], ep)
+	return ep
+}
+
+func TestGlobalLBHealthWorkerHTTPFailover(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/healthz", r.URL.Path)
+		assert.Equal(t, "api.global.test", r.Host)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+
+	w, repo, geoDNS, glb := setupGlobalLBHealthWorker(t, domain.GlobalHealthCheckConfig{
+		Protocol: "HTTP", Port: port, Path: "healthz", IntervalSec: 1, TimeoutSec: 1, HealthyCount: 2, UnhealthyCount: 2,
+	})
+
+	// The primary is a regional LB; taking its IP away makes its probes fail.
+	lbRepo := w.lbRepo.(*mock.MockLBRepo)
+	lbID := uuid.New()
+	lbRepo.LBs[lbID] = &domain.LoadBalancer{ID: lbID, IP: "127.0.0.1"}
+	primary := &domain.GlobalEndpoint{ID: uuid.New(), GlobalLBID: glb.ID, Region: "us-east-1", TargetType: "LB", TargetID: &lbID, Priority: 1, Healthy: true}
+	repo.Endpoints[glb.ID] = append(repo.Endpoints[glb.ID], primary)
+	secondary := addTestEndpoint(repo, glb, "eu-west-1", 2)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	w.checkAll(ctx, now)
+	assert.True(t, primary.Healthy)
+	assert.True(t, secondary.Healthy)
+	assert.Len(t, repo.HealthChecks, 2)
+
+	// Within the interval nothing is probed again.
+	w.checkAll(ctx, now.Add(100*time.Millisecond))
+	assert.Len(t, repo.HealthChecks, 2)
+
+	lbRepo.LBs[lbID].IP = ""
+	w.checkAll(ctx, now.Add(time.Second))
+	assert.True(t, primary.Healthy, "one failure is below the unhealthy threshold")
+	w.checkAll(ctx, now.Add(2*time.Second))
+	assert.False(t, primary.Healthy)
+
+	// FAILOVER now answers with the secondary only.
+	records := geoDNS.Records[glb.Hostname]
+	require.Len(t, records, 1)
+	assert.Equal(t, "eu-west-1", records[0].Region)
+
+	checks, _ := repo.ListHealthChecks(ctx, primary.ID, 1)
+	require.Len(t, checks, 1)
+	assert.False(t, checks[0].Healthy)
+	assert.NotEmpty(t, checks[0].Error)
+
+	lbRepo.LBs[lbID].IP = "127.0.0.1"
+	w.checkAll(ctx, now.Add(3*time.Second))
+	assert.False(t, primary.Healthy, "one success is below the healthy threshold")
+	w.checkAll(ctx, now.Add(4*time.Second))
+	assert.True(t, primary.Healthy)
+	records = geoDNS.Records[glb.Hostname]
+	require.Len(t, records, 1)
+	assert.Equal(t, "us-east-1", records[0].Region)
+}
+
+func TestGlobalLBHealthWorkerHTTPStatus(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+
+	w, repo, _, glb := setupGlobalLBHealthWorker(t, domain.GlobalHealthCheckConfig{Protocol: "HTTP", Port: port, TimeoutSec: 1})
+	ep := addTestEndpoint(repo, glb, "us-east-1", 1)
+
+	w.checkAll(context.Background(), time.Now())
+
+	checks, _ := repo.ListHealthChecks(context.Background(), ep.ID, 10)
+	require.Len(t, checks, 1)
+	assert.False(t, checks[0].Healthy)
+	assert.Equal(t, http.StatusServiceUnavailable, checks[0].StatusCode)
+}
+
+func TestGlobalLBHealthWorkerTCP(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	w, repo, geoDNS, glb := setupGlobalLBHealthWorker(t, domain.GlobalHealthCheckConfig{
+		Protocol: "TCP", Port: port, TimeoutSec: 1, UnhealthyCount: 1,
+	})
+	ep := addTestEndpoint(repo, glb, "us-east-1", 1)
+	ctx := context.Background()
+
+	w.checkAll(ctx, time.Now())
+	assert.True(t, ep.Healthy)
+	assert.Empty(t, geoDNS.Records, "no change, no republish")
+
+	require.NoError(t, ln.Close())
+	w.checkAll(ctx, time.Now().Add(time.Minute))
+	assert.False(t, ep.Healthy)
+	assert.Contains(t, geoDNS.Records, glb.Hostname)
+	assert.Empty(t, geoDNS.Records[glb.Hostname])
+}
+
+func TestGlobalLBHealthWorkerSkipsUnconfigured(t *testing.T) {
+	t.Parallel()
+
+	w, repo, _, glb := setupGlobalLBHealthWorker(t, domain.GlobalHealthCheckConfig{})
+	addTestEndpoint(repo, glb, "us-east-1", 1)
+
+	w.checkAll(context.Background(), time.Now())
+	assert.Empty(t, repo.HealthChecks)
+}
@@ -3,19 +3,21 @@ package workers
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
-	"strings"
 	"sync"
 	"time"
 
 	appcontext "github.com/poyrazk/thecloud/internal/core/context"
 	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
 )
 
 // LifecycleWorker periodically enforces bucket lifecycle rules.
 type LifecycleWorker struct {
 	lifecycleRepo ports.LifecycleRepository
+	lifecycleSvc  ports.LifecycleService
 	storageSvc    ports.StorageService
 	storageRepo   ports.StorageRepository
 	logger        *slog.Logger
@@ -23,9 +25,10 @@ type LifecycleWorker struct {
 }
 
 // NewLifecycleWorker constructs a LifecycleWorker.
-func NewLifecycleWorker(lifecycleRepo ports.LifecycleRepository, storageSvc ports.StorageService, storageRepo ports.StorageRepository, logger *slog.Logger) *LifecycleWorker {
+func NewLifecycleWorker(lifecycleRepo ports.LifecycleRepository, lifecycleSvc ports.LifecycleService, storageSvc ports.StorageService, storageRepo ports.StorageRepository, logger *slog.Logger) *LifecycleWorker {
 	return &LifecycleWorker{
 		lifecycleRepo: lifecycleRepo,
+		lifecycleSvc:  lifecycleSvc,
 		storageSvc:    storageSvc,
 		storageRepo:   storageRepo,
 		logger:        logger,
@@ -73,37 +76,47 @@ func (w *LifecycleWorker) processRule(ctx context.Context, rule *domain.Lifecycl
 	// Context with rule owner's ID to pass permission checks
 	ruleCtx := appcontext.WithUserID(ctx, rule.UserID)
 
-	// List all objects in bucket
-	// Note: For production, this should support pagination and prefix filtering at DB level
-	objects, err := w.storageSvc.ListObjects(ruleCtx, rule.BucketName)
+	report, err := w.lifecycleSvc.EvaluateRule(ruleCtx, rule, time.Now().UTC())
 	if err != nil {
-		logger.Error("failed to list objects for lifecycle", "error", err)
+		logger.Error("failed to evaluate lifecycle rule", "error", err)
 		return
 	}
 
-	expiration := time.Duration(rule.ExpirationDays) * 24 * time.Hour
-	now := time.Now().UTC()
-	deletedCount := 0
-
-	for _, obj := range objects {
-		// Filter by prefix
-		if rule.Prefix != "" && !strings.HasPrefix(obj.Key, rule.Prefix) {
+	applied := 0
+	for _, action := range report.Actions {
+		if err := w.apply(ruleCtx, rule.BucketName, action); err != nil {
+			// Retention or a legal hold placed after evaluation still protects the version.
+			if errors.Is(err, errors.ObjectLocked) {
+				logger.Info("skipping locked object", "key", action.Key, "version_id", action.VersionID)
+				continue
+			}
+			logger.Error("failed to apply lifecycle action", "action", action.Type, "key", action.Key, "version_id", action.VersionID, "error", err)
 			continue
 		}
+		applied++
+	}
 
-		// Check expiration
-		age := now.Sub(obj.CreatedAt)
-		if age > expiration {
-			logger.Info("expiring object", "key", obj.Key, "age_days", int(age.Hours()/24))
-			if err := w.storageSvc.DeleteObject(ruleCtx, rule.BucketName, obj.Key); err != nil {
-				logger.Error("failed to delete expired object", "key", obj.Key, "error", err)
-			} else {
-				deletedCount++
-			}
-		}
+	if applied > 0 {
+		logger.Info("lifecycle rule execution completed",
+			"applied", applied,
+			"expired", report.ObjectsExpired,
+			"noncurrent_expired", report.NoncurrentExpired,
+			"transitioned", report.ObjectsTransitioned,
+			"uploads_aborted", report.UploadsAborted)
 	}
+}
 
-	if deletedCount > 0 {
-		logger.Info("lifecycle rule execution completed", "deleted_count", deletedCount)
+func (w *LifecycleWorker) apply(ctx context.Context, bucket string, action domain.LifecycleAction) error {
+	switch action.Type {
+	case domain.LifecycleActionExpire:
+		return w.storageSvc.ExpireObject(ctx, bucket, action.Key)
+	case domain.LifecycleActionExpireNoncurrent:
+		return w.storageSvc.DeleteVersion(ctx, bucket, action.Key, action.VersionID)
+	case domain.LifecycleActionTransition:
+		return w.storageSvc.TransitionObject(ctx, bucket, action.Key, action.VersionID, action.StorageClass)
+	case domain.LifecycleActionAbortMultipart:
+		return w.storageSvc.AbortMultipartUpload(ctx, *action.UploadID)
+	default:
+		return fmt.Errorf("unknown lifecycle action %q", action.Type)
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
 	"github.com/poyrazk/thecloud/internal/core/domain"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,29 +31,71 @@ func (f *fakeLifecycleRepo) GetEnabledRules(ctx context.Context) ([]*domain.Life
 	return f.rules, f.err
 }
 
+type fakeLifecycleService struct {
+	report    *domain.LifecycleReport
+	err       error
+	evaluated []uuid.UUID
+}
+
+func (f *fakeLifecycleService) CreateRule(ctx context.Context, bucket string, rule *domain.LifecycleRule) (*domain.LifecycleRule, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleService) ListRules(ctx context.Context, bucket string) ([]*domain.LifecycleRule, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleService) DeleteRule(ctx context.Context, bucket string, ruleID string) error {
+	return nil
+}
+func (f *fakeLifecycleService) PreviewRule(ctx context.Context, bucket string, ruleID string) (*domain.LifecycleReport, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleService) EvaluateRule(ctx context.Context, rule *domain.LifecycleRule, now time.Time) (*domain.LifecycleReport, error) {
+	f.evaluated = append(f.evaluated, appcontext.UserIDFromContext(ctx))
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.report, nil
+}
+
 type fakeLifecycleStorageService struct {
-	objects      []*domain.Object
-	listErr      error
-	deleteErr    error
-	deletedKeys  []string
-	deletedMutex sync.Mutex
+	expireErr       error
+	expiredKeys     []string
+	deletedVersions []string
+	transitioned    []string
+	aborted         []uuid.UUID
+	mu              sync.Mutex
 }
 
-func (f *fakeLifecycleStorageService) ListObjects(ctx context.Context, bucket string) ([]*domain.Object, error) {
-	return f.objects, f.listErr
+func (f *fakeLifecycleStorageService) ListObjects(ctx context.Context, bucket string, opts domain.ObjectListOptions) (*domain.ObjectListResult, error) {
+	return &domain.ObjectListResult{Bucket: bucket}, nil
 }
 func (f *fakeLifecycleStorageService) DeleteObject(ctx context.Context, bucket, key string) error {
-	f.deletedMutex.Lock()
-	defer f.deletedMutex.Unlock()
-	f.deletedKeys = append(f.deletedKeys, key)
-	return f.deleteErr
+	return nil
+}
+func (f *fakeLifecycleStorageService) ExpireObject(ctx context.Context, bucket, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.expiredKeys = append(f.expiredKeys, key)
+	return f.expireErr
 }
-func (f *fakeLifecycleStorageService) DeletedKeys() []string {
-	f.deletedMutex.Lock()
-	defer f.deletedMutex.Unlock()
-	return append([]string(nil), f.deletedKeys...)
+func (f *fakeLifecycleStorageService) ExpiredKeys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.expiredKeys...)
 }
 
+func (f *fakeLifecycleStorageService) PutObject(ctx context.Context, bucket, key string, r io.Reader, cond domain.Preconditions) (*domain.Object, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) GetObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.ObjectContent, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) HeadObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.Object, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) DeleteObjectIf(ctx context.Context, bucket, key, versionID string, cond domain.Preconditions) error {
+	return nil
+}
 func (f *fakeLifecycleStorageService) Upload(ctx context.Context, bucket, key string, r io.Reader) (*domain.Object, error) {
 	return nil, nil
 }
@@ -66,6 +109,9 @@ func (f *fakeLifecycleStorageService) ListVersions(ctx context.Context, bucket,
 	return nil, nil
 }
 func (f *fakeLifecycleStorageService) DeleteVersion(ctx context.Context, bucket, key, versionID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deletedVersions = append(f.deletedVersions, key+"@"+versionID)
 	return nil
 }
 func (f *fakeLifecycleStorageService) CreateBucket(ctx context.Context, name string, isPublic bool) (*domain.Bucket, error) {
@@ -83,6 +129,72 @@ func (f *fakeLifecycleStorageService) ListBuckets(ctx context.Context) ([]*domai
 func (f *fakeLifecycleStorageService) SetBucketVersioning(ctx context.Context, name string, enabled bool) error {
 	return nil
 }
+func (f *fakeLifecycleStorageService) SetBucketStorageClass(ctx context.Context, name string, class domain.StorageClass, layout domain.ErasureLayout) (*domain.Bucket, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) GetBucketPolicy(ctx context.Context, bucket string) (*domain.BucketPolicy, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) PutBucketPolicy(ctx context.Context, bucket string, statements []domain.BucketPolicyStatement) (*domain.BucketPolicy, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) DeleteBucketPolicy(ctx context.Context, bucket string) error {
+	return nil
+}
+func (f *fakeLifecycleStorageService) GetBucketNotifications(ctx context.Context, bucket string) (*domain.BucketNotificationConfig, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) PutBucketNotifications(ctx context.Context, bucket string, rules []domain.BucketNotificationRule) (*domain.BucketNotificationConfig, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) DeleteBucketNotifications(ctx context.Context, bucket string) error {
+	return nil
+}
+func (f *fakeLifecycleStorageService) SetBucketObjectLock(ctx context.Context, bucket string, cfg domain.ObjectLockConfig) (*domain.Bucket, error) {
+	return &domain.Bucket{Name: bucket, ObjectLockEnabled: cfg.Enabled}, nil
+}
+func (f *fakeLifecycleStorageService) PutObjectRetention(ctx context.Context, bucket, key, versionID string, retention *domain.ObjectRetention) error {
+	return nil
+}
+func (f *fakeLifecycleStorageService) PutObjectLegalHold(ctx context.Context, bucket, key, versionID string, on bool) error {
+	return nil
+}
+func (f *fakeLifecycleStorageService) GetBucketReplication(ctx context.Context, bucket string) (*domain.BucketReplicationConfig, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) PutBucketReplication(ctx context.Context, bucket string, rules []domain.ReplicationRule) (*domain.BucketReplicationConfig, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) DeleteBucketReplication(ctx context.Context, bucket string) error {
+	return nil
+}
+func (f *fakeLifecycleStorageService) GetObjectReplication(ctx context.Context, bucket, key string) ([]*domain.ObjectReplication, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) GetReplicationBacklog(ctx context.Context, bucket string) (*domain.ReplicationBacklog, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) SetBucketEncryption(ctx context.Context, bucket string, enabled bool) (*domain.Bucket, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) RotateBucketKey(ctx context.Context, bucket string, reencrypt bool) (*domain.Bucket, error) {
+	return nil, nil
+}
+func (f *fakeLifecycleStorageService) ReencryptObjects(ctx context.Context, bucket string, limit int) (int, error) {
+	return 0, nil
+}
+func (f *fakeLifecycleStorageService) SetObjectACL(ctx context.Context, bucket, key, versionID string, acl domain.ObjectACL) error {
+	return nil
+}
+func (f *fakeLifecycleStorageService) SetObjectTags(ctx context.Context, bucket, key, versionID string, tags map[string]string) error {
+	return nil
+}
+func (f *fakeLifecycleStorageService) TransitionObject(ctx context.Context, bucket, key, versionID string, class domain.StorageClass) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.transitioned = append(f.transitioned, key+"@"+versionID+":"+string(class))
+	return nil
+}
 func (f *fakeLifecycleStorageService) GetClusterStatus(ctx context.Context) (*domain.StorageCluster, error) {
 	return nil, nil
 }
@@ -96,119 +208,92 @@ func (f *fakeLifecycleStorageService) CompleteMultipartUpload(ctx context.Contex
 	return nil, nil
 }
 func (f *fakeLifecycleStorageService) AbortMultipartUpload(ctx context.Context, uploadID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.aborted = append(f.aborted, uploadID)
 	return nil
 }
 func (f *fakeLifecycleStorageService) CleanupDeleted(ctx context.Context, limit int) (int, error) {
 	return 0, nil
 }
+func (f *fakeLifecycleStorageService) RepairErasureObjects(ctx context.Context, afterID uuid.UUID, limit int) (uuid.UUID, int, error) {
+	return uuid.Nil, 0, nil
+}
 func (f *fakeLifecycleStorageService) GeneratePresignedURL(ctx context.Context, bucket, key, method string, expiry time.Duration) (*domain.PresignedURL, error) {
 	return nil, nil
 }
 
-func TestLifecycleWorkerProcessRulesDeletesExpired(t *testing.T) {
-	repo := &fakeLifecycleRepo{
-		rules: []*domain.LifecycleRule{
-			{
-				ID:             uuid.New(),
-				BucketName:     "logs",
-				Prefix:         "app/",
-				ExpirationDays: 1,
-				UserID:         uuid.New(),
-			},
-		},
-	}
-
-	old := time.Now().UTC().Add(-48 * time.Hour)
-	newer := time.Now().UTC().Add(-12 * time.Hour)
-
-	storageSvc := &fakeLifecycleStorageService{
-		objects: []*domain.Object{
-			{Key: "app/old.log", CreatedAt: old},
-			{Key: "app/new.log", CreatedAt: newer},
-			{Key: "other/old.log", CreatedAt: old},
-		},
-	}
-
-	worker := &LifecycleWorker{
+func newTestLifecycleWorker(repo *fakeLifecycleRepo, lifecycleSvc *fakeLifecycleService, storageSvc *fakeLifecycleStorageService) *LifecycleWorker {
+	return &LifecycleWorker{
 		lifecycleRepo: repo,
+		lifecycleSvc:  lifecycleSvc,
 		storageSvc:    storageSvc,
-		storageRepo:   nil,
 		logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
 	}
+}
+
+func TestLifecycleWorkerProcessRulesAppliesActions(t *testing.T) {
+	owner := uuid.New()
+	repo := &fakeLifecycleRepo{
+		rules: []*domain.LifecycleRule{{ID: uuid.New(), BucketName: "logs", Prefix: "app/", ExpirationDays: 1, UserID: owner}},
+	}
+	uploadID := uuid.New()
+	lifecycleSvc := &fakeLifecycleService{report: &domain.LifecycleReport{Actions: []domain.LifecycleAction{
+		{Type: domain.LifecycleActionExpire, Key: "app/old.log", VersionID: "null"},
+		{Type: domain.LifecycleActionExpireNoncurrent, Key: "app/a.log", VersionID: "v1"},
+		{Type: domain.LifecycleActionTransition, Key: "app/b.log", VersionID: "v3", StorageClass: domain.StorageClassErasureCoded},
+		{Type: domain.LifecycleActionAbortMultipart, Key: "app/big.bin", UploadID: &uploadID},
+	}}}
+	storageSvc := &fakeLifecycleStorageService{}
 
-	worker.processRules(context.Background())
+	newTestLifecycleWorker(repo, lifecycleSvc, storageSvc).processRules(context.Background())
 
-	deleted := storageSvc.DeletedKeys()
-	assert.Equal(t, []string{"app/old.log"}, deleted)
+	assert.Equal(t, []uuid.UUID{owner}, lifecycleSvc.evaluated, "rules are evaluated as their owner")
+	assert.Equal(t, []string{"app/old.log"}, storageSvc.ExpiredKeys())
+	assert.Equal(t, []string{"app/a.log@v1"}, storageSvc.deletedVersions)
+	assert.Equal(t, []string{"app/b.log@v3:ERASURE_CODED"}, storageSvc.transitioned)
+	assert.Equal(t, []uuid.UUID{uploadID}, storageSvc.aborted)
 }
 
-func TestLifecycleWorkerProcessRulesListError(t *testing.T) {
+func TestLifecycleWorkerProcessRulesEvaluateError(t *testing.T) {
 	repo := &fakeLifecycleRepo{rules: []*domain.LifecycleRule{{ID: uuid.New(), BucketName: "logs", UserID: uuid.New()}}}
-	storageSvc := &fakeLifecycleStorageService{listErr: io.EOF}
-
-	worker := &LifecycleWorker{
-		lifecycleRepo: repo,
-		storageSvc:    storageSvc,
-		storageRepo:   nil,
-		logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
-	}
+	lifecycleSvc := &fakeLifecycleService{err: io.EOF}
+	storageSvc := &fakeLifecycleStorageService{}
 
-	worker.processRules(context.Background())
+	newTestLifecycleWorker(repo, lifecycleSvc, storageSvc).processRules(context.Background())
 
-	assert.Empty(t, storageSvc.DeletedKeys())
+	assert.Empty(t, storageSvc.ExpiredKeys())
 }
 
 func TestLifecycleWorkerProcessRulesRepoError(t *testing.T) {
 	repo := &fakeLifecycleRepo{err: io.EOF}
+	lifecycleSvc := &fakeLifecycleService{}
 	storageSvc := &fakeLifecycleStorageService{}
 
-	worker := &LifecycleWorker{
-		lifecycleRepo: repo,
-		storageSvc:    storageSvc,
-		storageRepo:   nil,
-		logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
-	}
-
-	worker.processRules(context.Background())
+	newTestLifecycleWorker(repo, lifecycleSvc, storageSvc).processRules(context.Background())
 
-	assert.Empty(t, storageSvc.DeletedKeys())
+	assert.Empty(t, lifecycleSvc.evaluated)
+	assert.Empty(t, storageSvc.ExpiredKeys())
 }
 
 func TestLifecycleWorkerProcessRulesDeleteError(t *testing.T) {
-	repo := &fakeLifecycleRepo{
-		rules: []*domain.LifecycleRule{
-			{
-				ID:             uuid.New(),
-				BucketName:     "logs",
-				ExpirationDays: 1,
-				UserID:         uuid.New(),
-			},
-		},
-	}
-
-	old := time.Now().UTC().Add(-48 * time.Hour)
-	storageSvc := &fakeLifecycleStorageService{
-		objects:   []*domain.Object{{Key: "old.log", CreatedAt: old}},
-		deleteErr: io.EOF,
-	}
-
-	worker := &LifecycleWorker{
-		lifecycleRepo: repo,
-		storageSvc:    storageSvc,
-		storageRepo:   nil,
-		logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
-	}
+	repo := &fakeLifecycleRepo{rules: []*domain.LifecycleRule{{ID: uuid.New(), BucketName: "logs", ExpirationDays: 1, UserID: uuid.New()}}}
+	lifecycleSvc := &fakeLifecycleService{report: &domain.LifecycleReport{Actions: []domain.LifecycleAction{
+		{Type: domain.LifecycleActionExpire, Key: "old.log"},
+		{Type: domain.LifecycleActionExpire, Key: "older.log"},
+	}}}
+	storageSvc := &fakeLifecycleStorageService{expireErr: io.EOF}
 
-	worker.processRules(context.Background())
+	newTestLifecycleWorker(repo, lifecycleSvc, storageSvc).processRules(context.Background())
 
-	deleted := storageSvc.DeletedKeys()
-	assert.Len(t, deleted, 1)
+	// A failed action does not stop the remaining ones.
+	assert.Equal(t, []string{"old.log", "older.log"}, storageSvc.ExpiredKeys())
 }
 
 func TestLifecycleWorkerRun(t *testing.T) {
 	repo := &fakeLifecycleRepo{}
 	storageSvc := &fakeLifecycleStorageService{}
-	worker := NewLifecycleWorker(repo, storageSvc, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	worker := NewLifecycleWorker(repo, &fakeLifecycleService{}, storageSvc, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
 	worker.interval = 10 * time.Millisecond
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -32,18 +32,33 @@ func (f *fakeStorageService) StatusCalls() int {
 	return f.statusCalls
 }
 
+func (f *fakeStorageService) PutObject(ctx context.Context, bucket, key string, r io.Reader, cond domain.Preconditions) (*domain.Object, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) GetObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.ObjectContent, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) HeadObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.Object, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) DeleteObjectIf(ctx context.Context, bucket, key, versionID string, cond domain.Preconditions) error {
+	return nil
+}
 func (f *fakeStorageService) Upload(ctx context.Context, bucket, key string, r io.Reader) (*domain.Object, error) {
 	return nil, nil
 }
 func (f *fakeStorageService) Download(ctx context.Context, bucket, key string) (io.ReadCloser, *domain.Object, error) {
 	return nil, nil, nil
 }
-func (f *fakeStorageService) ListObjects(ctx context.Context, bucket string) ([]*domain.Object, error) {
+func (f *fakeStorageService) ListObjects(ctx context.Context, bucket string, opts domain.ObjectListOptions) (*domain.ObjectListResult, error) {
 	return nil, nil
 }
 func (f *fakeStorageService) DeleteObject(ctx context.Context, bucket, key string) error {
 	return nil
 }
+func (f *fakeStorageService) ExpireObject(ctx context.Context, bucket, key string) error {
+	return nil
+}
 func (f *fakeStorageService) DownloadVersion(ctx context.Context, bucket, key, versionID string) (io.ReadCloser, *domain.Object, error) {
 	return nil, nil, nil
 }
@@ -68,6 +83,69 @@ func (f *fakeStorageService) ListBuckets(ctx context.Context) ([]*domain.Bucket,
 func (f *fakeStorageService) SetBucketVersioning(ctx context.Context, name string, enabled bool) error {
 	return nil
 }
+func (f *fakeStorageService) SetBucketStorageClass(ctx context.Context, name string, class domain.StorageClass, layout domain.ErasureLayout) (*domain.Bucket, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) GetBucketPolicy(ctx context.Context, bucket string) (*domain.BucketPolicy, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) PutBucketPolicy(ctx context.Context, bucket string, statements []domain.BucketPolicyStatement) (*domain.BucketPolicy, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) DeleteBucketPolicy(ctx context.Context, bucket string) error {
+	return nil
+}
+func (f *fakeStorageService) GetBucketNotifications(ctx context.Context, bucket string) (*domain.BucketNotificationConfig, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) PutBucketNotifications(ctx context.Context, bucket string, rules []domain.BucketNotificationRule) (*domain.BucketNotificationConfig, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) DeleteBucketNotifications(ctx context.Context, bucket string) error {
+	return nil
+}
+func (f *fakeStorageService) SetBucketObjectLock(ctx context.Context, bucket string, cfg domain.ObjectLockConfig) (*domain.Bucket, error) {
+	return &domain.Bucket{Name: bucket, ObjectLockEnabled: cfg.Enabled}, nil
+}
+func (f *fakeStorageService) PutObjectRetention(ctx context.Context, bucket, key, versionID string, retention *domain.ObjectRetention) error {
+	return nil
+}
+func (f *fakeStorageService) PutObjectLegalHold(ctx context.Context, bucket, key, versionID string, on bool) error {
+	return nil
+}
+func (f *fakeStorageService) GetBucketReplication(ctx context.Context, bucket string) (*domain.BucketReplicationConfig, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) PutBucketReplication(ctx context.Context, bucket string, rules []domain.ReplicationRule) (*domain.BucketReplicationConfig, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) DeleteBucketReplication(ctx context.Context, bucket string) error {
+	return nil
+}
+func (f *fakeStorageService) GetObjectReplication(ctx context.Context, bucket, key string) ([]*domain.ObjectReplication, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) GetReplicationBacklog(ctx context.Context, bucket string) (*domain.ReplicationBacklog, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) SetBucketEncryption(ctx context.Context, bucket string, enabled bool) (*domain.Bucket, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) RotateBucketKey(ctx context.Context, bucket string, reencrypt bool) (*domain.Bucket, error) {
+	return nil, nil
+}
+func (f *fakeStorageService) ReencryptObjects(ctx context.Context, bucket string, limit int) (int, error) {
+	return 0, nil
+}
+func (f *fakeStorageService) SetObjectACL(ctx context.Context, bucket, key, versionID string, acl domain.ObjectACL) error {
+	return nil
+}
+func (f *fakeStorageService) SetObjectTags(ctx context.Context, bucket, key, versionID string, tags map[string]string) error {
+	return nil
+}
+func (f *fakeStorageService) TransitionObject(ctx context.Context, bucket, key, versionID string, class domain.StorageClass) error {
+	return nil
+}
 func (f *fakeStorageService) CreateMultipartUpload(ctx context.Context, bucket, key string) (*domain.MultipartUpload, error) {
 	return nil, nil
 }
@@ -83,6 +161,9 @@ func (f *fakeStorageService) AbortMultipartUpload(ctx context.Context, uploadID
 func (f *fakeStorageService) CleanupDeleted(ctx context.Context, limit int) (int, error) {
 	return 0, nil
 }
+func (f *fakeStorageService) RepairErasureObjects(ctx context.Context, afterID uuid.UUID, limit int) (uuid.UUID, int, error) {
+	return uuid.Nil, 0, nil
+}
 func (f *fakeStorageService) GeneratePresignedURL(ctx context.Context, bucket, key, method string, expiry time.Duration) (*domain.PresignedURL, error) {
 	return nil, nil
 }
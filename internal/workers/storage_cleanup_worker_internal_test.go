@@ -21,10 +21,20 @@ func (m *mockStorageService) CleanupDeleted(ctx context.Context, limit int) (int
 	return args.Int(0), args.Error(1)
 }
 
+func (m *mockStorageService) RepairErasureObjects(ctx context.Context, afterID uuid.UUID, limit int) (uuid.UUID, int, error) {
+	args := m.Called(ctx, afterID, limit)
+	return args.Get(0).(uuid.UUID), args.Int(1), args.Error(2)
+}
+
+func (m *mockStorageService) PutObject(ctx context.Context, bucket, key string, r io.Reader, cond domain.Preconditions) (*domain.Object, error) { return nil, nil }
+func (m *mockStorageService) GetObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.ObjectContent, error) { return nil, nil }
+func (m *mockStorageService) HeadObject(ctx context.Context, bucket, key string, opts domain.GetObjectOptions) (*domain.Object, error) { return nil, nil }
+func (m *mockStorageService) DeleteObjectIf(ctx context.Context, bucket, key, versionID string, cond domain.Preconditions) error { return nil }
 func (m *mockStorageService) Upload(ctx context.Context, bucket, key string, r io.Reader) (*domain.Object, error) { return nil, nil }
 func (m *mockStorageService) Download(ctx context.Context, bucket, key string) (io.ReadCloser, *domain.Object, error) { return nil, nil, nil }
-func (m *mockStorageService) ListObjects(ctx context.Context, bucket string) ([]*domain.Object, error) { return nil, nil }
+func (m *mockStorageService) ListObjects(ctx context.Context, bucket string, opts domain.ObjectListOptions) (*domain.ObjectListResult, error) { return nil, nil }
 func (m *mockStorageService) DeleteObject(ctx context.Context, bucket, key string) error { return nil }
+func (m *mockStorageService) ExpireObject(ctx context.Context, bucket, key string) error { return nil }
 func (m *mockStorageService) DownloadVersion(ctx context.Context, bucket, key, versionID string) (io.ReadCloser, *domain.Object, error) { return nil, nil, nil }
 func (m *mockStorageService) ListVersions(ctx context.Context, bucket, key string) ([]*domain.Object, error) { return nil, nil }
 func (m *mockStorageService) DeleteVersion(ctx context.Context, bucket, key, versionID string) error { return nil }
@@ -33,6 +43,57 @@ func (m *mockStorageService) GetBucket(ctx context.Context, name string) (*domai
 func (m *mockStorageService) DeleteBucket(ctx context.Context, name string) error { return nil }
 func (m *mockStorageService) ListBuckets(ctx context.Context) ([]*domain.Bucket, error) { return nil, nil }
 func (m *mockStorageService) SetBucketVersioning(ctx context.Context, name string, enabled bool) error { return nil }
+func (m *mockStorageService) SetBucketStorageClass(ctx context.Context, name string, class domain.StorageClass, layout domain.ErasureLayout) (*domain.Bucket, error) { return nil, nil }
+func (m *mockStorageService) GetBucketPolicy(ctx context.Context, bucket string) (*domain.BucketPolicy, error) { return nil, nil }
+func (m *mockStorageService) PutBucketPolicy(ctx context.Context, bucket string, statements []domain.BucketPolicyStatement) (*domain.BucketPolicy, error) { return nil, nil }
+func (m *mockStorageService) DeleteBucketPolicy(ctx context.Context, bucket string) error { return nil }
+func (m *mockStorageService) GetBucketNotifications(ctx context.Context, bucket string) (*domain.BucketNotificationConfig, error) {
+	return nil, nil
+}
+func (m *mockStorageService) PutBucketNotifications(ctx context.Context, bucket string, rules []domain.BucketNotificationRule) (*domain.BucketNotificationConfig, error) {
+	return nil, nil
+}
+func (m *mockStorageService) DeleteBucketNotifications(ctx context.Context, bucket string) error { return nil }
+func (m *mockStorageService) SetBucketObjectLock(ctx context.Context, bucket string, cfg domain.ObjectLockConfig) (*domain.Bucket, error) {
+	return nil, nil
+}
+func (m *mockStorageService) PutObjectRetention(ctx context.Context, bucket, key, versionID string, retention *domain.ObjectRetention) error {
+	return nil
+}
+func (m *mockStorageService) PutObjectLegalHold(ctx context.Context, bucket, key, versionID string, on bool) error {
+	return nil
+}
+func (m *mockStorageService) GetBucketReplication(ctx context.Context, bucket string) (*domain.BucketReplicationConfig, error) {
+	return nil, nil
+}
+func (m *mockStorageService) PutBucketReplication(ctx context.Context, bucket string, rules []domain.ReplicationRule) (*domain.BucketReplicationConfig, error) {
+	return nil, nil
+}
+func (m *mockStorageService) DeleteBucketReplication(ctx context.Context, bucket string) error {
+	return nil
+}
+func (m *mockStorageService) GetObjectReplication(ctx context.Context, bucket, key string) ([]*domain.ObjectReplication, error) {
+	return nil, nil
+}
+func (m *mockStorageService) GetReplicationBacklog(ctx context.Context, bucket string) (*domain.ReplicationBacklog, error) {
+	return nil, nil
+}
+func (m *mockStorageService) SetBucketEncryption(ctx context.Context, bucket string, enabled bool) (*domain.Bucket, error) {
+	return nil, nil
+}
+func (m *mockStorageService) RotateBucketKey(ctx context.Context, bucket string, reencrypt bool) (*domain.Bucket, error) {
+	return nil, nil
+}
+func (m *mockStorageService) ReencryptObjects(ctx context.Context, bucket string, limit int) (int, error) {
+	return 0, nil
+}
+func (m *mockStorageService) SetObjectACL(ctx context.Context, bucket, key, versionID string, acl domain.ObjectACL) error { return nil }
+func (m *mockStorageService) SetObjectTags(ctx context.Context, bucket, key, versionID string, tags map[string]string) error {
+	return nil
+}
+func (m *mockStorageService) TransitionObject(ctx context.Context, bucket, key, versionID string, class domain.StorageClass) error {
+	return nil
+}
 func (m *mockStorageService) GetClusterStatus(ctx context.Context) (*domain.StorageCluster, error) { return nil, nil }
 func (m *mockStorageService) CreateMultipartUpload(ctx context.Context, bucket, key string) (*domain.MultipartUpload, error) { return nil, nil }
 func (m *mockStorageService) UploadPart(ctx context.Context, uploadID uuid.UUID, partNumber int, r io.Reader) (*domain.Part, error) { return nil, nil }
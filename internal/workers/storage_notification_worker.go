@@ -0,0 +1,95 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+)
+
+// StorageNotificationWorker delivers queued bucket events to queues, topics and functions.
+type StorageNotificationWorker struct {
+	taskQueue ports.TaskQueue
+	queueSvc  ports.QueueService
+	notifySvc ports.NotifyService
+	fnSvc     ports.FunctionService
+	logger    *slog.Logger
+}
+
+// NewStorageNotificationWorker constructs a StorageNotificationWorker.
+func NewStorageNotificationWorker(taskQueue ports.TaskQueue, queueSvc ports.QueueService, notifySvc ports.NotifyService, fnSvc ports.FunctionService, logger *slog.Logger) *StorageNotificationWorker {
+	return &StorageNotificationWorker{
+		taskQueue: taskQueue,
+		queueSvc:  queueSvc,
+		notifySvc: notifySvc,
+		fnSvc:     fnSvc,
+		logger:    logger,
+	}
+}
+
+func (w *StorageNotificationWorker) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	w.logger.Info("starting storage notification worker")
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("stopping storage notification worker")
+			return
+		default:
+			msg, err := w.taskQueue.Dequeue(ctx, domain.StorageNotificationQueue)
+			if err != nil {
+				time.Sleep(1 * time.Second)
+				continue
+			}
+			if msg == "" {
+				continue
+			}
+
+			var job domain.StorageNotificationJob
+			if err := json.Unmarshal([]byte(msg), &job); err != nil {
+				w.logger.Error("failed to unmarshal storage notification", "error", err)
+				continue
+			}
+			if err := w.deliver(ctx, job); err != nil {
+				w.logger.Warn("failed to deliver storage notification",
+					"target_type", job.TargetType, "target_id", job.TargetID, "error", err)
+			}
+		}
+	}
+}
+
+// deliver sends the event to its target, acting as the bucket owner.
+func (w *StorageNotificationWorker) deliver(ctx context.Context, job domain.StorageNotificationJob) error {
+	body, err := json.Marshal(job.Event)
+	if err != nil {
+		return err
+	}
+
+	ctx = appcontext.WithUserID(ctx, job.UserID)
+	switch job.TargetType {
+	case domain.NotificationTargetQueue:
+		_, err := w.queueSvc.SendMessage(ctx, job.TargetID, string(body))
+		return err
+	case domain.NotificationTargetTopic:
+		return w.notifySvc.Publish(ctx, job.TargetID, string(body))
+	case domain.NotificationTargetFunction:
+		// Functions are looked up by ID alone, so ownership is checked here.
+		fn, err := w.fnSvc.GetFunction(ctx, job.TargetID)
+		if err != nil {
+			return err
+		}
+		if fn.UserID != job.UserID {
+			return fmt.Errorf("function %s does not belong to the bucket owner", job.TargetID)
+		}
+		_, err = w.fnSvc.InvokeFunction(ctx, job.TargetID, body, true)
+		return err
+	}
+	return fmt.Errorf("unknown notification target type %q", job.TargetType)
+}
@@ -0,0 +1,144 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEventQueueService struct {
+	ports.QueueService
+	user uuid.UUID
+	sent map[uuid.UUID][]string
+}
+
+func (f *fakeEventQueueService) SendMessage(ctx context.Context, queueID uuid.UUID, body string) (*domain.Message, error) {
+	f.user = appcontext.UserIDFromContext(ctx)
+	if f.sent == nil {
+		f.sent = map[uuid.UUID][]string{}
+	}
+	f.sent[queueID] = append(f.sent[queueID], body)
+	return &domain.Message{}, nil
+}
+
+type fakeEventNotifyService struct {
+	ports.NotifyService
+	published map[uuid.UUID]string
+}
+
+func (f *fakeEventNotifyService) Publish(ctx context.Context, topicID uuid.UUID, body string) error {
+	if f.published == nil {
+		f.published = map[uuid.UUID]string{}
+	}
+	f.published[topicID] = body
+	return nil
+}
+
+type fakeEventFunctionService struct {
+	ports.FunctionService
+	functions map[uuid.UUID]*domain.Function
+	invoked   map[uuid.UUID]bool
+}
+
+func (f *fakeEventFunctionService) GetFunction(ctx context.Context, id uuid.UUID) (*domain.Function, error) {
+	fn, ok := f.functions[id]
+	if !ok {
+		return nil, assert.AnError
+	}
+	return fn, nil
+}
+
+func (f *fakeEventFunctionService) InvokeFunction(ctx context.Context, id uuid.UUID, payload []byte, async bool) (*domain.Invocation, error) {
+	if f.invoked == nil {
+		f.invoked = map[uuid.UUID]bool{}
+	}
+	f.invoked[id] = async
+	return &domain.Invocation{}, nil
+}
+
+func notificationJob(owner uuid.UUID, targetType domain.NotificationTargetType, target uuid.UUID) domain.StorageNotificationJob {
+	return domain.StorageNotificationJob{
+		UserID:     owner,
+		TargetType: targetType,
+		TargetID:   target,
+		Event: domain.StorageEvent{Records: []domain.StorageEventRecord{{
+			EventName: domain.StorageEventObjectCreatedPut,
+			S3:        domain.StorageEventDetails{Object: domain.StorageEventObject{Key: "a.txt"}},
+		}}},
+	}
+}
+
+func TestStorageNotificationWorkerDeliver(t *testing.T) {
+	owner := uuid.New()
+	queues := &fakeEventQueueService{}
+	topics := &fakeEventNotifyService{}
+	ownFn, otherFn := uuid.New(), uuid.New()
+	functions := &fakeEventFunctionService{functions: map[uuid.UUID]*domain.Function{
+		ownFn:   {ID: ownFn, UserID: owner},
+		otherFn: {ID: otherFn, UserID: uuid.New()},
+	}}
+	w := NewStorageNotificationWorker(&fakeTaskQueue{}, queues, topics, functions, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	t.Run("queue", func(t *testing.T) {
+		queueID := uuid.New()
+		require.NoError(t, w.deliver(ctx, notificationJob(owner, domain.NotificationTargetQueue, queueID)))
+		require.Len(t, queues.sent[queueID], 1)
+		assert.Equal(t, owner, queues.user)
+
+		var event domain.StorageEvent
+		require.NoError(t, json.Unmarshal([]byte(queues.sent[queueID][0]), &event))
+		assert.Equal(t, "a.txt", event.Records[0].S3.Object.Key)
+	})
+
+	t.Run("topic", func(t *testing.T) {
+		topicID := uuid.New()
+		require.NoError(t, w.deliver(ctx, notificationJob(owner, domain.NotificationTargetTopic, topicID)))
+		assert.Contains(t, topics.published[topicID], "ObjectCreated:Put")
+	})
+
+	t.Run("function", func(t *testing.T) {
+		require.NoError(t, w.deliver(ctx, notificationJob(owner, domain.NotificationTargetFunction, ownFn)))
+		assert.True(t, functions.invoked[ownFn])
+	})
+
+	t.Run("function of another user", func(t *testing.T) {
+		err := w.deliver(ctx, notificationJob(owner, domain.NotificationTargetFunction, otherFn))
+		assert.ErrorContains(t, err, "does not belong")
+		assert.NotContains(t, functions.invoked, otherFn)
+	})
+
+	t.Run("unknown target type", func(t *testing.T) {
+		assert.Error(t, w.deliver(ctx, notificationJob(owner, "WEBHOOK", uuid.New())))
+	})
+}
+
+func TestStorageNotificationWorkerRun(t *testing.T) {
+	queueID := uuid.New()
+	msg, err := json.Marshal(notificationJob(uuid.New(), domain.NotificationTargetQueue, queueID))
+	require.NoError(t, err)
+
+	queues := &fakeEventQueueService{}
+	tq := &fakeTaskQueue{messages: []string{"not json", string(msg)}}
+	w := NewStorageNotificationWorker(tq, queues, &fakeEventNotifyService{}, &fakeEventFunctionService{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go w.Run(ctx, &wg)
+	wg.Wait()
+
+	assert.Len(t, queues.sent[queueID], 1)
+}
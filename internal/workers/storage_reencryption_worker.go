@@ -0,0 +1,90 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+)
+
+const reencryptionBatchSize = 100
+
+// StorageReencryptionWorker moves encryption keys and objects off superseded keys: on start it
+// re-wraps data keys still wrapped by a retired master key, then it re-encrypts the objects of
+// buckets whose data key was rotated with re-encryption requested.
+type StorageReencryptionWorker struct {
+	taskQueue  ports.TaskQueue
+	storageSvc ports.StorageService
+	encryptSvc ports.EncryptionService
+	logger     *slog.Logger
+}
+
+// NewStorageReencryptionWorker constructs a StorageReencryptionWorker.
+func NewStorageReencryptionWorker(taskQueue ports.TaskQueue, storageSvc ports.StorageService, encryptSvc ports.EncryptionService, logger *slog.Logger) *StorageReencryptionWorker {
+	return &StorageReencryptionWorker{
+		taskQueue:  taskQueue,
+		storageSvc: storageSvc,
+		encryptSvc: encryptSvc,
+		logger:     logger,
+	}
+}
+
+func (w *StorageReencryptionWorker) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	w.logger.Info("starting storage re-encryption worker")
+
+	if n, err := w.encryptSvc.RewrapKeys(ctx); err != nil {
+		w.logger.Error("failed to re-wrap data keys with the active master key", "rewrapped", n, "error", err)
+	} else if n > 0 {
+		w.logger.Info("re-wrapped data keys with the active master key", "rewrapped", n)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("stopping storage re-encryption worker")
+			return
+		default:
+			msg, err := w.taskQueue.Dequeue(ctx, domain.StorageReencryptionQueue)
+			if err != nil {
+				time.Sleep(1 * time.Second)
+				continue
+			}
+			if msg == "" {
+				continue
+			}
+
+			var job domain.StorageReencryptionJob
+			if err := json.Unmarshal([]byte(msg), &job); err != nil {
+				w.logger.Error("failed to unmarshal re-encryption job", "error", err)
+				continue
+			}
+			n, err := w.reencrypt(ctx, job)
+			if err != nil {
+				w.logger.Warn("failed to re-encrypt bucket", "bucket", job.Bucket, "reencrypted", n, "error", err)
+				continue
+			}
+			w.logger.Info("re-encrypted bucket", "bucket", job.Bucket, "reencrypted", n)
+		}
+	}
+}
+
+// reencrypt rewrites the bucket's objects in batches, acting as the bucket owner, until
+// none are left on an older data key.
+func (w *StorageReencryptionWorker) reencrypt(ctx context.Context, job domain.StorageReencryptionJob) (int, error) {
+	ctx = appcontext.WithUserID(ctx, job.UserID)
+	total := 0
+	for ctx.Err() == nil {
+		n, err := w.storageSvc.ReencryptObjects(ctx, job.Bucket, reencryptionBatchSize)
+		total += n
+		if err != nil || n == 0 {
+			return total, err
+		}
+	}
+	return total, ctx.Err()
+}
@@ -0,0 +1,93 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeReencryptStorageService struct {
+	ports.StorageService
+	mu       sync.Mutex
+	batches  []int // objects left to rewrite per call
+	calls    int
+	user     uuid.UUID
+	buckets  []string
+	failWith error
+}
+
+func (f *fakeReencryptStorageService) ReencryptObjects(ctx context.Context, bucket string, limit int) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.user = appcontext.UserIDFromContext(ctx)
+	f.buckets = append(f.buckets, bucket)
+	if f.failWith != nil {
+		return 0, f.failWith
+	}
+	if f.calls >= len(f.batches) {
+		return 0, nil
+	}
+	n := f.batches[f.calls]
+	f.calls++
+	return n, nil
+}
+
+type fakeRewrapEncryptionService struct {
+	ports.EncryptionService
+	rewrapped bool
+}
+
+func (f *fakeRewrapEncryptionService) RewrapKeys(ctx context.Context) (int, error) {
+	f.rewrapped = true
+	return 2, nil
+}
+
+func TestStorageReencryptionWorkerReencrypt(t *testing.T) {
+	owner := uuid.New()
+	storage := &fakeReencryptStorageService{batches: []int{100, 100, 7}}
+	w := NewStorageReencryptionWorker(&fakeTaskQueue{}, storage, &fakeRewrapEncryptionService{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	n, err := w.reencrypt(context.Background(), domain.StorageReencryptionJob{Bucket: "vault", UserID: owner})
+	require.NoError(t, err)
+	assert.Equal(t, 207, n)
+	assert.Equal(t, owner, storage.user)
+	assert.Len(t, storage.buckets, 4)
+
+	storage = &fakeReencryptStorageService{failWith: assert.AnError}
+	w = NewStorageReencryptionWorker(&fakeTaskQueue{}, storage, &fakeRewrapEncryptionService{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	_, err = w.reencrypt(context.Background(), domain.StorageReencryptionJob{Bucket: "vault", UserID: owner})
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestStorageReencryptionWorkerRun(t *testing.T) {
+	msg, err := json.Marshal(domain.StorageReencryptionJob{Bucket: "vault", UserID: uuid.New()})
+	require.NoError(t, err)
+
+	storage := &fakeReencryptStorageService{batches: []int{3}}
+	enc := &fakeRewrapEncryptionService{}
+	tq := &fakeTaskQueue{messages: []string{"not json", string(msg)}}
+	w := NewStorageReencryptionWorker(tq, storage, enc, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go w.Run(ctx, &wg)
+	wg.Wait()
+
+	assert.True(t, enc.rewrapped)
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	assert.Equal(t, []string{"vault", "vault"}, storage.buckets)
+}
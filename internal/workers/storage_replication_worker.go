@@ -0,0 +1,170 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/poyrazk/thecloud/internal/platform"
+)
+
+const replicationBatchSize = 100
+
+// StorageReplicationWorker copies queued object changes to their replication destinations
+// and publishes replication lag metrics.
+type StorageReplicationWorker struct {
+	repo       ports.StorageRepository
+	storageSvc ports.StorageService
+	secretSvc  ports.SecretService
+	client     ports.ReplicationClient
+	logger     *slog.Logger
+	interval   time.Duration
+}
+
+// NewStorageReplicationWorker constructs a StorageReplicationWorker.
+func NewStorageReplicationWorker(repo ports.StorageRepository, storageSvc ports.StorageService, secretSvc ports.SecretService, client ports.ReplicationClient, logger *slog.Logger) *StorageReplicationWorker {
+	return &StorageReplicationWorker{
+		repo:       repo,
+		storageSvc: storageSvc,
+		secretSvc:  secretSvc,
+		client:     client,
+		logger:     logger,
+		interval:   10 * time.Second,
+	}
+}
+
+func (w *StorageReplicationWorker) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	w.logger.Info("starting storage replication worker")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("stopping storage replication worker")
+			return
+		case <-ticker.C:
+			// Drain full batches straight away so a backlog is not paced by the ticker.
+			for ctx.Err() == nil {
+				if w.processBatch(ctx) < replicationBatchSize {
+					break
+				}
+			}
+			w.updateMetrics(ctx)
+		}
+	}
+}
+
+// processBatch replicates one batch of due changes and returns how many it handled.
+func (w *StorageReplicationWorker) processBatch(ctx context.Context) int {
+	reps, err := w.repo.ListPendingReplication(ctx, replicationBatchSize)
+	if err != nil {
+		w.logger.Error("failed to list pending replication", "error", err)
+		return 0
+	}
+
+	// Buckets and configurations are looked up once per batch.
+	buckets := make(map[string]*domain.Bucket)
+	configs := make(map[string]*domain.BucketReplicationConfig)
+	for _, rep := range reps {
+		err := w.replicate(ctx, rep, buckets, configs)
+		now := time.Now()
+		switch {
+		case err == nil:
+			rep.RecordSuccess(now)
+			platform.StorageReplicationOperations.WithLabelValues(string(rep.Operation), "success").Inc()
+		case errors.Is(err, errors.ObjectNotFound) || errors.Is(err, errors.NotFound) || errors.Is(err, errors.InvalidInput):
+			// The source version or the rule is gone, or the version cannot be read
+			// without a customer key (SSE-C); retrying cannot succeed.
+			rep.Attempts++
+			rep.LastError = err.Error()
+			rep.Status = domain.ReplicationFailed
+			platform.StorageReplicationOperations.WithLabelValues(string(rep.Operation), "failed").Inc()
+		default:
+			rep.RecordFailure(err, now)
+			result := "retry"
+			if rep.Status == domain.ReplicationFailed {
+				result = "failed"
+			}
+			platform.StorageReplicationOperations.WithLabelValues(string(rep.Operation), result).Inc()
+			w.logger.Warn("failed to replicate object change",
+				"bucket", rep.Bucket, "key", rep.Key, "version_id", rep.VersionID, "rule_id", rep.RuleID,
+				"attempts", rep.Attempts, "error", err)
+		}
+		if err := w.repo.UpdateReplication(ctx, rep); err != nil {
+			w.logger.Error("failed to update replication status", "bucket", rep.Bucket, "key", rep.Key, "error", err)
+		}
+	}
+	return len(reps)
+}
+
+// replicate applies one change to its destination, reading the source as the bucket owner.
+func (w *StorageReplicationWorker) replicate(ctx context.Context, rep *domain.ObjectReplication, buckets map[string]*domain.Bucket, configs map[string]*domain.BucketReplicationConfig) error {
+	bucket, ok := buckets[rep.Bucket]
+	if !ok {
+		var err error
+		if bucket, err = w.repo.GetBucket(ctx, rep.Bucket); err != nil {
+			return err
+		}
+		buckets[rep.Bucket] = bucket
+	}
+	cfg, ok := configs[rep.Bucket]
+	if !ok {
+		var err error
+		if cfg, err = w.repo.GetBucketReplication(ctx, rep.Bucket); err != nil {
+			return err
+		}
+		configs[rep.Bucket] = cfg
+	}
+	rule := cfg.Rule(rep.RuleID)
+	if rule == nil {
+		return errors.New(errors.NotFound, fmt.Sprintf("replication rule %s no longer exists", rep.RuleID))
+	}
+
+	dest := rule.Destination
+	apiKey, err := w.secretSvc.Decrypt(ctx, bucket.UserID, dest.APIKey)
+	if err != nil {
+		return err
+	}
+	dest.APIKey = apiKey
+
+	if rep.Operation == domain.ReplicationOperationDelete {
+		return w.client.DeleteObject(ctx, dest, rep.Key)
+	}
+
+	ownerCtx := appcontext.WithUserID(ctx, bucket.UserID)
+	body, obj, err := w.storageSvc.DownloadVersion(ownerCtx, rep.Bucket, rep.Key, rep.VersionID)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = body.Close() }()
+	return w.client.PutObject(ctx, dest, rep.Key, body, obj.SizeBytes, obj.ContentType)
+}
+
+// updateMetrics publishes the replication backlog of every bucket.
+func (w *StorageReplicationWorker) updateMetrics(ctx context.Context) {
+	backlogs, err := w.repo.ListReplicationBacklog(ctx, "")
+	if err != nil {
+		w.logger.Error("failed to load replication backlog", "error", err)
+		return
+	}
+
+	// Buckets that caught up drop out of the backlog, so start from a clean slate.
+	platform.StorageReplicationPending.Reset()
+	platform.StorageReplicationFailed.Reset()
+	platform.StorageReplicationLag.Reset()
+	now := time.Now()
+	for _, b := range backlogs {
+		platform.StorageReplicationPending.WithLabelValues(b.Bucket).Set(float64(b.Pending))
+		platform.StorageReplicationFailed.WithLabelValues(b.Bucket).Set(float64(b.Failed))
+		platform.StorageReplicationLag.WithLabelValues(b.Bucket).Set(b.Lag(now).Seconds())
+	}
+}
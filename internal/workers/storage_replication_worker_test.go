@@ -0,0 +1,154 @@
+package workers
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	appcontext "github.com/poyrazk/thecloud/internal/core/context"
+	"github.com/poyrazk/thecloud/internal/core/domain"
+	"github.com/poyrazk/thecloud/internal/core/ports"
+	"github.com/poyrazk/thecloud/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeReplicationRepo struct {
+	ports.StorageRepository
+	bucket  *domain.Bucket
+	config  *domain.BucketReplicationConfig
+	pending []*domain.ObjectReplication
+	updated []*domain.ObjectReplication
+}
+
+func (f *fakeReplicationRepo) GetBucket(ctx context.Context, name string) (*domain.Bucket, error) {
+	return f.bucket, nil
+}
+func (f *fakeReplicationRepo) GetBucketReplication(ctx context.Context, bucket string) (*domain.BucketReplicationConfig, error) {
+	return f.config, nil
+}
+func (f *fakeReplicationRepo) ListPendingReplication(ctx context.Context, limit int) ([]*domain.ObjectReplication, error) {
+	reps := f.pending
+	f.pending = nil
+	return reps, nil
+}
+func (f *fakeReplicationRepo) UpdateReplication(ctx context.Context, rep *domain.ObjectReplication) error {
+	f.updated = append(f.updated, rep)
+	return nil
+}
+
+type fakeReplicationStorageService struct {
+	ports.StorageService
+	owners []uuid.UUID
+}
+
+func (f *fakeReplicationStorageService) DownloadVersion(ctx context.Context, bucket, key, versionID string) (io.ReadCloser, *domain.Object, error) {
+	f.owners = append(f.owners, appcontext.UserIDFromContext(ctx))
+	if versionID == "gone" {
+		return nil, nil, errors.New(errors.ObjectNotFound, "version not found")
+	}
+	if versionID == "ssec" {
+		return nil, nil, errors.New(errors.InvalidInput, "object is encrypted with a customer-provided key")
+	}
+	return io.NopCloser(strings.NewReader("data")), &domain.Object{Key: key, SizeBytes: 4, ContentType: "text/plain"}, nil
+}
+
+// fakeReplicationSecrets "encrypts" by prefixing with the owner's ID.
+type fakeReplicationSecrets struct {
+	ports.SecretService
+}
+
+func (fakeReplicationSecrets) Decrypt(ctx context.Context, userID uuid.UUID, cipherText string) (string, error) {
+	plain, ok := strings.CutPrefix(cipherText, userID.String()+":")
+	if !ok {
+		return "", errors.New(errors.Internal, "failed to decrypt")
+	}
+	return plain, nil
+}
+
+type fakeReplicationClient struct {
+	puts    []string
+	deletes []string
+	keys    []string
+	err     error
+}
+
+func (f *fakeReplicationClient) PutObject(ctx context.Context, dest domain.ReplicationDestination, key string, r io.Reader, size int64, contentType string) error {
+	if f.err != nil {
+		return f.err
+	}
+	body, _ := io.ReadAll(r)
+	f.puts = append(f.puts, dest.Bucket+"/"+key+":"+string(body))
+	f.keys = append(f.keys, dest.APIKey)
+	return nil
+}
+func (f *fakeReplicationClient) DeleteObject(ctx context.Context, dest domain.ReplicationDestination, key string) error {
+	f.deletes = append(f.deletes, dest.Bucket+"/"+key)
+	f.keys = append(f.keys, dest.APIKey)
+	return f.err
+}
+
+func newTestReplicationWorker(repo *fakeReplicationRepo, client *fakeReplicationClient) (*StorageReplicationWorker, *fakeReplicationStorageService) {
+	svc := &fakeReplicationStorageService{}
+	return NewStorageReplicationWorker(repo, svc, fakeReplicationSecrets{}, client, slog.New(slog.NewTextHandler(io.Discard, nil))), svc
+}
+
+func TestStorageReplicationWorkerProcessBatch(t *testing.T) {
+	owner := uuid.New()
+	newRepo := func(reps ...*domain.ObjectReplication) *fakeReplicationRepo {
+		return &fakeReplicationRepo{
+			bucket: &domain.Bucket{Name: "photos", UserID: owner},
+			config: &domain.BucketReplicationConfig{Rules: []domain.ReplicationRule{
+				{ID: "r1", Destination: domain.ReplicationDestination{Endpoint: "https://dr.example.com", Bucket: "backup", APIKey: owner.String() + ":k"}},
+			}},
+			pending: reps,
+		}
+	}
+	rep := func(op domain.ReplicationOperation, versionID, ruleID string) *domain.ObjectReplication {
+		return &domain.ObjectReplication{Bucket: "photos", Key: "a.jpg", VersionID: versionID, RuleID: ruleID, Operation: op, Status: domain.ReplicationPending}
+	}
+
+	t.Run("copies puts and deletes as the bucket owner", func(t *testing.T) {
+		repo := newRepo(rep(domain.ReplicationOperationPut, "v1", "r1"), rep(domain.ReplicationOperationDelete, "", "r1"))
+		client := &fakeReplicationClient{}
+		w, svc := newTestReplicationWorker(repo, client)
+
+		assert.Equal(t, 2, w.processBatch(context.Background()))
+		assert.Equal(t, []string{"backup/a.jpg:data"}, client.puts)
+		assert.Equal(t, []string{"backup/a.jpg"}, client.deletes)
+		assert.Equal(t, []uuid.UUID{owner}, svc.owners)
+		assert.Equal(t, []string{"k", "k"}, client.keys, "the stored key is decrypted")
+		for _, r := range repo.updated {
+			assert.Equal(t, domain.ReplicationCompleted, r.Status)
+			assert.NotNil(t, r.ReplicatedAt)
+		}
+	})
+
+	t.Run("transient failures are retried", func(t *testing.T) {
+		repo := newRepo(rep(domain.ReplicationOperationPut, "v1", "r1"))
+		w, _ := newTestReplicationWorker(repo, &fakeReplicationClient{err: stderrors.New("destination returned 503")})
+
+		w.processBatch(context.Background())
+		assert.Len(t, repo.updated, 1)
+		assert.Equal(t, domain.ReplicationPending, repo.updated[0].Status)
+		assert.Equal(t, 1, repo.updated[0].Attempts)
+		assert.Contains(t, repo.updated[0].LastError, "503")
+	})
+
+	t.Run("missing sources, rules and customer keys fail permanently", func(t *testing.T) {
+		repo := newRepo(rep(domain.ReplicationOperationPut, "gone", "r1"), rep(domain.ReplicationOperationPut, "v1", "removed"),
+			rep(domain.ReplicationOperationPut, "ssec", "r1"))
+		client := &fakeReplicationClient{}
+		w, _ := newTestReplicationWorker(repo, client)
+
+		w.processBatch(context.Background())
+		assert.Len(t, repo.updated, 3)
+		for _, r := range repo.updated {
+			assert.Equal(t, domain.ReplicationFailed, r.Status)
+		}
+		assert.Empty(t, client.puts)
+	})
+}
@@ -72,6 +72,10 @@ func Error(c *gin.Context, err error) {
 		errors.BucketNotFound:        http.StatusNotFound,
 		errors.ObjectNotFound:        http.StatusNotFound,
 		errors.ObjectTooLarge:        http.StatusRequestEntityTooLarge,
+		errors.PreconditionFailed:    http.StatusPreconditionFailed,
+		errors.NotModified:           http.StatusNotModified,
+		errors.RangeNotSatisfiable:   http.StatusRequestedRangeNotSatisfiable,
+		errors.ObjectLocked:          http.StatusForbidden,
 		errors.InstanceNotRunning:    http.StatusConflict,
 		errors.PortConflict:          http.StatusConflict,
 		errors.TooManyPorts:          http.StatusConflict,
@@ -18,6 +18,8 @@ type IPRateLimiter struct {
 	rate   rate.Limit
 	burst  int
 	logger *slog.Logger
+	stop   chan struct{}
+	once   sync.Once
 }
 
 // NewIPRateLimiter creates a new rate limiter manager
@@ -27,6 +29,7 @@ func NewIPRateLimiter(r rate.Limit, b int, logger *slog.Logger) *IPRateLimiter {
 		rate:   r,
 		burst:  b,
 		logger: logger,
+		stop:   make(chan struct{}),
 	}
 
 	// Periodic cleanup of old entries
@@ -49,10 +52,22 @@ func (i *IPRateLimiter) GetLimiter(key string) *rate.Limiter {
 	return limiter
 }
 
+// Stop ends the cleanup loop. Limiters created per resource must be stopped
+// when the resource goes away.
+func (i *IPRateLimiter) Stop() {
+	i.once.Do(func() { close(i.stop) })
+}
+
 // cleanupLoop removes old entries (rudimentary GC)
 func (i *IPRateLimiter) cleanupLoop() {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
 	for {
-		time.Sleep(10 * time.Minute)
+		select {
+		case <-i.stop:
+			return
+		case <-ticker.C:
+		}
 		i.mu.Lock()
 		// Start fresh every cleanup cycle for simplicity
 		// A production robust implementation would track last access time
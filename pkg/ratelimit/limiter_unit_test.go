@@ -72,3 +72,9 @@ func TestMiddlewareUsesClientIPWhenNoKey(t *testing.T) {
 	_, exists := limiter.ips["192.168.1.10"]
 	assert.True(t, exists)
 }
+
+func TestStopIsIdempotent(t *testing.T) {
+	limiter := NewIPRateLimiter(rate.Limit(1), 1, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	limiter.Stop()
+	limiter.Stop()
+}
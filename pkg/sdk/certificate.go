@@ -0,0 +1,76 @@
+// Package sdk provides the official Go SDK for the platform.
+package sdk
+
+import (
+	"fmt"
+	"time"
+)
+
+// Certificate describes a TLS certificate that load balancer and gateway
+// HTTPS listeners serve. Its private key is never returned.
+type Certificate struct {
+	ID             string    `json:"id"`
+	UserID         string    `json:"user_id"`
+	Name           string    `json:"name"`
+	Domains        []string  `json:"domains"`
+	Source         string    `json:"source"`
+	Status         string    `json:"status"`
+	Issuer         string    `json:"issuer"`
+	SerialNumber   string    `json:"serial_number"`
+	NotBefore      time.Time `json:"not_before"`
+	NotAfter       time.Time `json:"not_after"`
+	CertificatePEM string    `json:"certificate_pem"`
+	NotifyTopicID  string    `json:"notify_topic_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// CreateCertificateInput imports a PEM certificate when CertificatePEM is
+// set, and otherwise issues a certificate for Domains from the internal CA.
+type CreateCertificateInput struct {
+	Name           string   `json:"name"`
+	CertificatePEM string   `json:"certificate_pem,omitempty"`
+	PrivateKeyPEM  string   `json:"private_key_pem,omitempty"`
+	Domains        []string `json:"domains,omitempty"`
+	ValidityDays   int      `json:"validity_days,omitempty"`
+	NotifyTopicID  string   `json:"notify_topic_id,omitempty"`
+}
+
+func (c *Client) CreateCertificate(input CreateCertificateInput) (*Certificate, error) {
+	var resp Response[Certificate]
+	if err := c.post("/certificates", input, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+func (c *Client) ListCertificates() ([]Certificate, error) {
+	var resp Response[[]Certificate]
+	if err := c.get("/certificates", &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+func (c *Client) GetCertificate(id string) (*Certificate, error) {
+	var resp Response[Certificate]
+	if err := c.get(fmt.Sprintf("/certificates/%s", id), &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+func (c *Client) DeleteCertificate(id string) error {
+	return c.delete(fmt.Sprintf("/certificates/%s", id), nil)
+}
+
+// GetCertificateAuthority returns the internal CA certificate in PEM form,
+// for installing into clients' trust stores.
+func (c *Client) GetCertificateAuthority() (string, error) {
+	var resp Response[struct {
+		CertificatePEM string `json:"certificate_pem"`
+	}]
+	if err := c.get("/certificates/ca", &resp); err != nil {
+		return "", err
+	}
+	return resp.Data.CertificatePEM, nil
+}
@@ -0,0 +1,79 @@
+package sdk_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/poyrazk/thecloud/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificateSDK(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/api/v1/certificates" && r.Method == http.MethodPost:
+			var body sdk.CreateCertificateInput
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"id": "cert-1", "name": body.Name, "domains": body.Domains, "source": "issued"},
+			})
+		case r.URL.Path == "/api/v1/certificates" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]interface{}{{"id": "cert-1", "name": "web"}},
+			})
+		case r.URL.Path == "/api/v1/certificates/ca" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"certificate_pem": "-----BEGIN CERTIFICATE-----"},
+			})
+		case r.URL.Path == "/api/v1/certificates/cert-1" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"id": "cert-1", "name": "web", "status": "ACTIVE"},
+			})
+		case r.URL.Path == "/api/v1/certificates/cert-1" && r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := sdk.NewClient(ts.URL+"/api/v1", "test-api-key")
+
+	t.Run("CreateCertificate", func(t *testing.T) {
+		cert, err := client.CreateCertificate(sdk.CreateCertificateInput{Name: "web", Domains: []string{"app.example.com"}})
+		require.NoError(t, err)
+		assert.Equal(t, "cert-1", cert.ID)
+		assert.Equal(t, []string{"app.example.com"}, cert.Domains)
+	})
+
+	t.Run("ListCertificates", func(t *testing.T) {
+		certs, err := client.ListCertificates()
+		assert.NoError(t, err)
+		assert.Len(t, certs, 1)
+	})
+
+	t.Run("GetCertificate", func(t *testing.T) {
+		cert, err := client.GetCertificate("cert-1")
+		require.NoError(t, err)
+		assert.Equal(t, "ACTIVE", cert.Status)
+
+		_, err = client.GetCertificate("missing")
+		assert.Error(t, err)
+	})
+
+	t.Run("GetCertificateAuthority", func(t *testing.T) {
+		pem, err := client.GetCertificateAuthority()
+		assert.NoError(t, err)
+		assert.Equal(t, "-----BEGIN CERTIFICATE-----", pem)
+	})
+
+	t.Run("DeleteCertificate", func(t *testing.T) {
+		assert.NoError(t, client.DeleteCertificate("cert-1"))
+	})
+}
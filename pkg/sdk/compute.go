@@ -17,6 +17,7 @@ type Instance struct {
 	VpcID        string            `json:"vpc_id,omitempty"`
 	SubnetID     string            `json:"subnet_id,omitempty"`
 	PrivateIP    string            `json:"private_ip,omitempty"`
+	PrivateIPv6  string            `json:"private_ipv6,omitempty"`
 	ContainerID  string            `json:"container_id"`
 	Version      int               `json:"version"`
 	Metadata     map[string]string `json:"metadata,omitempty"`
@@ -86,3 +86,40 @@ func (c *Client) CreateDNSRecord(zoneID uuid.UUID, name string, recordType domai
 func (c *Client) DeleteDNSRecord(recordID string) error {
 	return c.delete(fmt.Sprintf("/dns/records/%s", recordID), nil)
 }
+
+// ChangeDNSRecords applies a batch of record changes to a zone atomically.
+func (c *Client) ChangeDNSRecords(zoneID string, changes []domain.DNSRecordChange) ([]domain.DNSRecord, error) {
+	payload := map[string]interface{}{
+		"changes": changes,
+	}
+
+	var resp struct {
+		Data []domain.DNSRecord `json:"data"`
+	}
+	err := c.post(fmt.Sprintf("/dns/zones/%s/changes", zoneID), payload, &resp)
+	return resp.Data, err
+}
+
+// ImportDNSZone loads a BIND zone file into a zone.
+func (c *Client) ImportDNSZone(zoneID, zoneFile string) ([]domain.DNSRecord, error) {
+	payload := map[string]string{
+		"zone_file": zoneFile,
+	}
+
+	var resp struct {
+		Data []domain.DNSRecord `json:"data"`
+	}
+	err := c.post(fmt.Sprintf("/dns/zones/%s/import", zoneID), payload, &resp)
+	return resp.Data, err
+}
+
+// ExportDNSZone returns a zone rendered as a BIND zone file.
+func (c *Client) ExportDNSZone(zoneID string) (string, error) {
+	var resp struct {
+		Data struct {
+			ZoneFile string `json:"zone_file"`
+		} `json:"data"`
+	}
+	err := c.get(fmt.Sprintf("/dns/zones/%s/export", zoneID), &resp)
+	return resp.Data.ZoneFile, err
+}
@@ -0,0 +1,54 @@
+// Package sdk provides the official Go SDK for the platform.
+package sdk
+
+import (
+	"fmt"
+	"time"
+)
+
+// FlowLog captures the accepted and/or rejected flows of a VPC, subnet or instance into CloudLogs.
+// Its records are searchable with resource type FlowLogResourceType and the flow log's ID.
+type FlowLog struct {
+	ID           string    `json:"id"`
+	VPCID        string    `json:"vpc_id"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	TrafficType  string    `json:"traffic_type"`
+	ARN          string    `json:"arn"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// FlowLogResourceType is the CloudLogs resource type of flow records.
+const FlowLogResourceType = "vpc-flow-log"
+
+// CreateFlowLog starts capturing flows. resourceType is "vpc", "subnet" or "instance";
+// trafficType is "accept", "reject" or "all".
+func (c *Client) CreateFlowLog(resourceType, resourceID, trafficType string) (*FlowLog, error) {
+	var resp Response[*FlowLog]
+	body := map[string]string{
+		"resource_type": resourceType,
+		"resource_id":   resourceID,
+		"traffic_type":  trafficType,
+	}
+	err := c.post("/flow-logs", body, &resp)
+	return resp.Data, err
+}
+
+// ListFlowLogs returns the caller's flow logs.
+func (c *Client) ListFlowLogs() ([]*FlowLog, error) {
+	var resp Response[[]*FlowLog]
+	err := c.get("/flow-logs", &resp)
+	return resp.Data, err
+}
+
+// GetFlowLog retrieves a flow log.
+func (c *Client) GetFlowLog(id string) (*FlowLog, error) {
+	var resp Response[*FlowLog]
+	err := c.get(fmt.Sprintf("/flow-logs/%s", id), &resp)
+	return resp.Data, err
+}
+
+// DeleteFlowLog stops a flow log. Records already ingested are kept.
+func (c *Client) DeleteFlowLog(id string) error {
+	return c.delete(fmt.Sprintf("/flow-logs/%s", id), nil)
+}
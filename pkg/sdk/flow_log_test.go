@@ -0,0 +1,53 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/poyrazk/thecloud/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientFlowLogs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(contentType, testutil.TestContentTypeAppJSON)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/flow-logs":
+			var req map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			assert.Equal(t, "subnet", req["resource_type"])
+			assert.Equal(t, "reject", req["traffic_type"])
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(Response[*FlowLog]{Data: &FlowLog{ID: "fl-1", ResourceID: req["resource_id"], TrafficType: req["traffic_type"]}})
+		case r.Method == http.MethodGet && r.URL.Path == "/flow-logs":
+			_ = json.NewEncoder(w).Encode(Response[[]*FlowLog]{Data: []*FlowLog{{ID: "fl-1"}}})
+		case r.Method == http.MethodGet && r.URL.Path == "/flow-logs/fl-1":
+			_ = json.NewEncoder(w).Encode(Response[*FlowLog]{Data: &FlowLog{ID: "fl-1", ResourceType: "subnet"}})
+		case r.Method == http.MethodDelete && r.URL.Path == "/flow-logs/fl-1":
+			_ = json.NewEncoder(w).Encode(Response[map[string]string]{Data: map[string]string{"message": "flow log deleted"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, testAPIKey)
+
+	fl, err := client.CreateFlowLog("subnet", "sub-1", "reject")
+	require.NoError(t, err)
+	assert.Equal(t, "sub-1", fl.ResourceID)
+
+	logs, err := client.ListFlowLogs()
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+
+	fl, err = client.GetFlowLog("fl-1")
+	require.NoError(t, err)
+	assert.Equal(t, "subnet", fl.ResourceType)
+
+	require.NoError(t, client.DeleteFlowLog("fl-1"))
+	assert.Error(t, client.DeleteFlowLog("missing"))
+}
@@ -5,32 +5,109 @@ import "fmt"
 
 // GatewayRoute describes an API gateway route.
 type GatewayRoute struct {
-	ID          string   `json:"id"`
-	UserID      string   `json:"user_id"`
-	Name        string   `json:"name"`
-	PathPrefix  string   `json:"path_prefix"`
-	PathPattern string   `json:"path_pattern"`
-	PatternType string   `json:"pattern_type"`
-	ParamNames  []string `json:"param_names"`
-	TargetURL   string   `json:"target_url"`
-	Methods     []string `json:"methods"`
-	StripPrefix bool     `json:"strip_prefix"`
-	RateLimit   int      `json:"rate_limit"`
-	Priority    int      `json:"priority"`
-	CreatedAt   string   `json:"created_at"`
-	UpdatedAt   string   `json:"updated_at"`
+	ID            string               `json:"id"`
+	UserID        string               `json:"user_id"`
+	Name          string               `json:"name"`
+	PathPrefix    string               `json:"path_prefix"`
+	PathPattern   string               `json:"path_pattern"`
+	PatternType   string               `json:"pattern_type"`
+	ParamNames    []string             `json:"param_names"`
+	TargetURL     string               `json:"target_url"`
+	Upstreams     []GatewayUpstream    `json:"upstreams,omitempty"`
+	TrafficPolicy GatewayTrafficPolicy `json:"traffic_policy"`
+	Methods       []string             `json:"methods"`
+	StripPrefix   bool                 `json:"strip_prefix"`
+	RateLimit     int                  `json:"rate_limit"`
+	Priority      int                  `json:"priority"`
+	Plugins       GatewayRoutePlugins  `json:"plugins"`
+	CertificateID string               `json:"certificate_id,omitempty"`
+	HTTPSRedirect bool                 `json:"https_redirect"`
+	CreatedAt     string               `json:"created_at"`
+	UpdatedAt     string               `json:"updated_at"`
+}
+
+// GatewayUpstream is one weighted backend of a gateway route: a static URL
+// ("url"), a load balancer ("lb"), a container deployment ("deployment") or a
+// CloudFunction ("function"), which must be the route's only upstream.
+type GatewayUpstream struct {
+	Type     string `json:"type"`
+	URL      string `json:"url,omitempty"`
+	TargetID string `json:"target_id,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Weight   int    `json:"weight"`
+}
+
+// GatewayTrafficPolicy configures retries, per-attempt timeouts and circuit
+// breaking for a gateway route's upstreams.
+type GatewayTrafficPolicy struct {
+	Retries        int                          `json:"retries,omitempty"`
+	TimeoutMs      int                          `json:"timeout_ms,omitempty"`
+	CircuitBreaker *GatewayCircuitBreakerConfig `json:"circuit_breaker,omitempty"`
+}
+
+// GatewayCircuitBreakerConfig controls when an upstream is taken out of rotation.
+type GatewayCircuitBreakerConfig struct {
+	FailureThreshold int `json:"failure_threshold"`
+	ResetTimeoutSec  int `json:"reset_timeout_sec"`
+}
+
+// GatewayRoutePlugins configures authentication, CORS, header rewriting and
+// body size limits for a gateway route.
+type GatewayRoutePlugins struct {
+	Auth            *GatewayAuthConfig    `json:"auth,omitempty"`
+	CORS            *GatewayCORSConfig    `json:"cors,omitempty"`
+	RequestHeaders  *GatewayHeaderRewrite `json:"request_headers,omitempty"`
+	ResponseHeaders *GatewayHeaderRewrite `json:"response_headers,omitempty"`
+	MaxBodyBytes    int64                 `json:"max_body_bytes,omitempty"`
+}
+
+// GatewayAuthConfig authenticates route callers with API keys ("api_key") or
+// JSON Web Tokens ("jwt"). Keys and secrets are never returned by the API.
+type GatewayAuthConfig struct {
+	Type      string   `json:"type"`
+	Header    string   `json:"header,omitempty"`
+	APIKeys   []string `json:"api_keys,omitempty"`
+	Algorithm string   `json:"algorithm,omitempty"`
+	Secret    string   `json:"secret,omitempty"`
+	PublicKey string   `json:"public_key,omitempty"`
+	Issuer    string   `json:"issuer,omitempty"`
+	Audience  string   `json:"audience,omitempty"`
+}
+
+// GatewayCORSConfig is the CORS policy of a gateway route.
+type GatewayCORSConfig struct {
+	AllowOrigins     []string `json:"allow_origins"`
+	AllowMethods     []string `json:"allow_methods,omitempty"`
+	AllowHeaders     []string `json:"allow_headers,omitempty"`
+	ExposeHeaders    []string `json:"expose_headers,omitempty"`
+	AllowCredentials bool     `json:"allow_credentials,omitempty"`
+	MaxAgeSec        int      `json:"max_age_sec,omitempty"`
+}
+
+// GatewayHeaderRewrite sets and removes request or response headers.
+type GatewayHeaderRewrite struct {
+	Set    map[string]string `json:"set,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+}
+
+// CreateGatewayRouteRequest holds the parameters for creating a gateway route.
+type CreateGatewayRouteRequest struct {
+	Name          string               `json:"name"`
+	PathPrefix    string               `json:"path_prefix"`
+	TargetURL     string               `json:"target_url,omitempty"`
+	Upstreams     []GatewayUpstream    `json:"upstreams,omitempty"`
+	TrafficPolicy GatewayTrafficPolicy `json:"traffic_policy"`
+	Methods       []string             `json:"methods"`
+	StripPrefix   bool                 `json:"strip_prefix"`
+	RateLimit     int                  `json:"rate_limit"`
+	Priority      int                  `json:"priority"`
+	Plugins       GatewayRoutePlugins  `json:"plugins"`
+	CertificateID string               `json:"certificate_id,omitempty"`
+	HTTPSRedirect bool                 `json:"https_redirect,omitempty"`
 }
 
 func (c *Client) CreateGatewayRoute(name, prefix, target string, methods []string, strip bool, rateLimit int, priority int) (*GatewayRoute, error) {
-	req := struct {
-		Name        string   `json:"name"`
-		PathPrefix  string   `json:"path_prefix"`
-		TargetURL   string   `json:"target_url"`
-		Methods     []string `json:"methods"`
-		StripPrefix bool     `json:"strip_prefix"`
-		RateLimit   int      `json:"rate_limit"`
-		Priority    int      `json:"priority"`
-	}{
+	return c.CreateGatewayRouteWithRequest(CreateGatewayRouteRequest{
 		Name:        name,
 		PathPrefix:  prefix,
 		TargetURL:   target,
@@ -38,8 +115,12 @@ func (c *Client) CreateGatewayRoute(name, prefix, target string, methods []strin
 		StripPrefix: strip,
 		RateLimit:   rateLimit,
 		Priority:    priority,
-	}
+	})
+}
 
+// CreateGatewayRouteWithRequest creates a gateway route, including its
+// upstreams, traffic policy and plugins.
+func (c *Client) CreateGatewayRouteWithRequest(req CreateGatewayRouteRequest) (*GatewayRoute, error) {
 	var route GatewayRoute
 	err := c.post("/gateway/routes", req, &route)
 	return &route, err
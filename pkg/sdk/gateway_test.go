@@ -57,6 +57,64 @@ func TestGatewayCreateRoute(t *testing.T) {
 	assert.Equal(t, expectedRoute.ID, route.ID)
 }
 
+func TestGatewayCreateRouteWithPlugins(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CreateGatewayRouteRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.NotNil(t, req.Plugins.Auth)
+		assert.Equal(t, "api_key", req.Plugins.Auth.Type)
+		assert.Equal(t, []string{"https://app.example.com"}, req.Plugins.CORS.AllowOrigins)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(GatewayRoute{ID: testRouteID, Plugins: req.Plugins})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, testAPIKey)
+	route, err := client.CreateGatewayRouteWithRequest(CreateGatewayRouteRequest{
+		Name:       "secured",
+		PathPrefix: "/secured",
+		TargetURL:  "http://backend:8080",
+		Plugins: GatewayRoutePlugins{
+			Auth: &GatewayAuthConfig{Type: "api_key", APIKeys: []string{"k"}},
+			CORS: &GatewayCORSConfig{AllowOrigins: []string{"https://app.example.com"}},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, testRouteID, route.ID)
+}
+
+func TestGatewayCreateRouteWithUpstreams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.NotContains(t, body, "target_url")
+		upstreams := body["upstreams"].([]interface{})
+		assert.Len(t, upstreams, 2)
+		assert.Equal(t, "lb", upstreams[1].(map[string]interface{})["type"])
+		assert.Equal(t, float64(2), body["traffic_policy"].(map[string]interface{})["retries"])
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(GatewayRoute{ID: testRouteID})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, testAPIKey)
+	route, err := client.CreateGatewayRouteWithRequest(CreateGatewayRouteRequest{
+		Name:       "canary",
+		PathPrefix: "/canary",
+		Upstreams: []GatewayUpstream{
+			{Type: "url", URL: "http://stable:8080", Weight: 90},
+			{Type: "lb", TargetID: "lb-1", Weight: 10},
+		},
+		TrafficPolicy: GatewayTrafficPolicy{Retries: 2, TimeoutMs: 500},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, testRouteID, route.ID)
+}
+
 func TestGatewayListRoutes(t *testing.T) {
 	expectedRoutes := []GatewayRoute{
 		{ID: testRouteID, Name: testRouteID},
@@ -3,6 +3,8 @@ package sdk
 
 import (
 	"fmt"
+	"net/url"
+	"time"
 )
 
 // LBStatus represents the lifecycle state of a load balancer.
@@ -10,23 +12,85 @@ type LBStatus string
 
 // LoadBalancer describes a load balancer resource.
 type LoadBalancer struct {
-	ID             string   `json:"id"`
-	IdempotencyKey string   `json:"idempotency_key,omitempty"`
-	Name           string   `json:"name"`
-	VpcID          string   `json:"vpc_id"`
+	ID             string       `json:"id"`
+	IdempotencyKey string       `json:"idempotency_key,omitempty"`
+	Name           string       `json:"name"`
+	VpcID          string       `json:"vpc_id"`
+	Port           int          `json:"port"`
+	Algorithm      string       `json:"algorithm"`
+	Status         LBStatus     `json:"status"`
+	TLS            *LBTLSConfig `json:"tls,omitempty"`
+	Listeners      []LBListener `json:"listeners,omitempty"`
+
+	HealthCheck                *LBHealthCheck    `json:"health_check,omitempty"`
+	DeregistrationDelaySeconds int               `json:"deregistration_delay_seconds"`
+	StickySessions             *LBStickySessions `json:"sticky_sessions,omitempty"`
+}
+
+// LBHealthCheck configures target health checks. An empty Path checks that
+// the target port accepts connections; otherwise 2xx and 3xx responses pass.
+type LBHealthCheck struct {
+	Path               string `json:"path"`
+	IntervalSeconds    int    `json:"interval_seconds"`
+	TimeoutSeconds     int    `json:"timeout_seconds"`
+	HealthyThreshold   int    `json:"healthy_threshold"`
+	UnhealthyThreshold int    `json:"unhealthy_threshold"`
+}
+
+// LBStickySessions pins each client to one target with a cookie.
+type LBStickySessions struct {
+	CookieName      string `json:"cookie_name"`
+	DurationSeconds int    `json:"duration_seconds"`
+}
+
+// LBAttributes holds a load balancer's target policies. A nil HealthCheck
+// selects the TCP port check and a nil StickySessions disables affinity.
+type LBAttributes struct {
+	HealthCheck                *LBHealthCheck    `json:"health_check,omitempty"`
+	DeregistrationDelaySeconds int               `json:"deregistration_delay_seconds"`
+	StickySessions             *LBStickySessions `json:"sticky_sessions,omitempty"`
+}
+
+// LBListener is a plain HTTP listener that routes requests to target groups
+// by its rules, in priority order, falling back to DefaultTargetGroup.
+type LBListener struct {
+	Port               int              `json:"port"`
+	DefaultTargetGroup string           `json:"default_target_group,omitempty"`
+	Rules              []LBListenerRule `json:"rules,omitempty"`
+}
+
+// LBListenerRule routes requests matching all of its conditions to a target group.
+type LBListenerRule struct {
+	Priority    int            `json:"priority"`
+	Host        string         `json:"host,omitempty"`
+	PathPrefix  string         `json:"path_prefix,omitempty"`
+	Header      *LBHeaderMatch `json:"header,omitempty"`
+	TargetGroup string         `json:"target_group"`
+}
+
+// LBHeaderMatch matches requests whose header Name equals Value.
+type LBHeaderMatch struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// LBTLSConfig describes a load balancer's HTTPS listener.
+type LBTLSConfig struct {
 	Port           int      `json:"port"`
-	Algorithm      string   `json:"algorithm"`
-	Status         LBStatus `json:"status"`
+	CertificateIDs []string `json:"certificate_ids"`
+	RedirectHTTP   bool     `json:"redirect_http"`
 }
 
 // LBTarget describes a load balancer target.
 type LBTarget struct {
-	ID         string `json:"id"`
-	LBID       string `json:"lb_id"`
-	InstanceID string `json:"instance_id"`
-	Port       int    `json:"port"`
-	Weight     int    `json:"weight"`
-	Health     string `json:"health"`
+	ID          string     `json:"id"`
+	LBID        string     `json:"lb_id"`
+	InstanceID  string     `json:"instance_id"`
+	Port        int        `json:"port"`
+	Weight      int        `json:"weight"`
+	Health      string     `json:"health"`
+	TargetGroup string     `json:"target_group"`
+	DrainUntil  *time.Time `json:"drain_until,omitempty"`
 }
 
 func (c *Client) CreateLB(name, vpcID string, port int, algo string) (*LoadBalancer, error) {
@@ -74,10 +138,29 @@ func (c *Client) AddLBTarget(lbID, instanceID string, port, weight int) error {
 	return c.post(fmt.Sprintf("/lb/%s/targets", lbID), req, nil)
 }
 
+// AddLBTargetToGroup registers an instance into a target group that
+// listener rules route to.
+func (c *Client) AddLBTargetToGroup(lbID, instanceID string, port, weight int, group string) error {
+	req := map[string]interface{}{
+		"instance_id":  instanceID,
+		"port":         port,
+		"weight":       weight,
+		"target_group": group,
+	}
+
+	return c.post(fmt.Sprintf("/lb/%s/targets", lbID), req, nil)
+}
+
 func (c *Client) RemoveLBTarget(lbID, instanceID string) error {
 	return c.delete(fmt.Sprintf("/lb/%s/targets/%s", lbID, instanceID), nil)
 }
 
+// RemoveLBTargetFromGroup unregisters an instance from one target group,
+// keeping its membership in the others.
+func (c *Client) RemoveLBTargetFromGroup(lbID, instanceID, group string) error {
+	return c.delete(fmt.Sprintf("/lb/%s/targets/%s?target_group=%s", lbID, instanceID, url.QueryEscape(group)), nil)
+}
+
 func (c *Client) ListLBTargets(lbID string) ([]LBTarget, error) {
 	var resp Response[[]LBTarget]
 	if err := c.get(fmt.Sprintf("/lb/%s/targets", lbID), &resp); err != nil {
@@ -85,3 +168,43 @@ func (c *Client) ListLBTargets(lbID string) ([]LBTarget, error) {
 	}
 	return resp.Data, nil
 }
+
+// ConfigureLBTLS enables or replaces a load balancer's HTTPS listener.
+func (c *Client) ConfigureLBTLS(id string, cfg LBTLSConfig) (*LoadBalancer, error) {
+	var resp Response[LoadBalancer]
+	if err := c.put(fmt.Sprintf("/lb/%s/tls", id), cfg, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// DisableLBTLS removes a load balancer's HTTPS listener.
+func (c *Client) DisableLBTLS(id string) (*LoadBalancer, error) {
+	var resp Response[LoadBalancer]
+	if err := c.delete(fmt.Sprintf("/lb/%s/tls", id), &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// ConfigureLBListeners replaces a load balancer's listeners and routing
+// rules. An empty list restores the single default listener.
+func (c *Client) ConfigureLBListeners(id string, listeners []LBListener) (*LoadBalancer, error) {
+	req := map[string]interface{}{"listeners": listeners}
+
+	var resp Response[LoadBalancer]
+	if err := c.put(fmt.Sprintf("/lb/%s/listeners", id), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// ConfigureLBAttributes replaces a load balancer's health check, deregistration
+// delay and sticky session settings.
+func (c *Client) ConfigureLBAttributes(id string, attrs LBAttributes) (*LoadBalancer, error) {
+	var resp Response[LoadBalancer]
+	if err := c.put(fmt.Sprintf("/lb/%s/attributes", id), attrs, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
@@ -31,6 +31,15 @@ func newLoadBalancerTestServer(t *testing.T) *httptest.Server {
 		if handleLBTargets(w, r) {
 			return
 		}
+		if handleLBTLS(w, r) {
+			return
+		}
+		if handleLBListeners(w, r) {
+			return
+		}
+		if handleLBAttributes(w, r) {
+			return
+		}
 		w.WriteHeader(http.StatusNotFound)
 	}))
 }
@@ -83,6 +92,65 @@ func handleLBTargets(w http.ResponseWriter, r *http.Request) bool {
 	return false
 }
 
+func handleLBTLS(w http.ResponseWriter, r *http.Request) bool {
+	if r.URL.Path != lbPathPrefix+lbID+"/tls" {
+		return false
+	}
+	lb := LoadBalancer{ID: lbID, Name: lbName, Status: "CREATING"}
+	switch r.Method {
+	case http.MethodPut:
+		var cfg LBTLSConfig
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return true
+		}
+		lb.TLS = &cfg
+	case http.MethodDelete:
+	default:
+		return false
+	}
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(Response[LoadBalancer]{Data: lb})
+	return true
+}
+
+func handleLBListeners(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPut || r.URL.Path != lbPathPrefix+lbID+"/listeners" {
+		return false
+	}
+	var req struct {
+		Listeners []LBListener `json:"listeners"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return true
+	}
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(Response[LoadBalancer]{
+		Data: LoadBalancer{ID: lbID, Name: lbName, Status: "CREATING", Listeners: req.Listeners},
+	})
+	return true
+}
+
+func handleLBAttributes(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPut || r.URL.Path != lbPathPrefix+lbID+"/attributes" {
+		return false
+	}
+	var req LBAttributes
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return true
+	}
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(Response[LoadBalancer]{
+		Data: LoadBalancer{
+			ID: lbID, Name: lbName, Status: "ACTIVE",
+			HealthCheck: req.HealthCheck, DeregistrationDelaySeconds: req.DeregistrationDelaySeconds, StickySessions: req.StickySessions,
+		},
+	})
+	return true
+}
+
 func TestClientLoadBalancer(t *testing.T) {
 	server := newLoadBalancerTestServer(t)
 	defer server.Close()
@@ -118,6 +186,11 @@ func TestClientLoadBalancer(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("AddLBTargetToGroup", func(t *testing.T) {
+		err := client.AddLBTargetToGroup(lbID, lbInstanceID, 80, 1, "api")
+		assert.NoError(t, err)
+	})
+
 	t.Run("RemoveLBTarget", func(t *testing.T) {
 		err := client.RemoveLBTarget(lbID, lbInstanceID)
 		assert.NoError(t, err)
@@ -128,6 +201,42 @@ func TestClientLoadBalancer(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, targets, 1)
 	})
+
+	t.Run("ConfigureLBTLS", func(t *testing.T) {
+		cfg := LBTLSConfig{Port: 443, CertificateIDs: []string{"cert-1"}, RedirectHTTP: true}
+		lb, err := client.ConfigureLBTLS(lbID, cfg)
+		assert.NoError(t, err)
+		assert.Equal(t, &cfg, lb.TLS)
+	})
+
+	t.Run("DisableLBTLS", func(t *testing.T) {
+		lb, err := client.DisableLBTLS(lbID)
+		assert.NoError(t, err)
+		assert.Nil(t, lb.TLS)
+	})
+
+	t.Run("ConfigureLBListeners", func(t *testing.T) {
+		listeners := []LBListener{{
+			Port:  80,
+			Rules: []LBListenerRule{{Priority: 1, PathPrefix: "/api", Header: &LBHeaderMatch{Name: "X-Canary", Value: "1"}, TargetGroup: "api"}},
+		}}
+		lb, err := client.ConfigureLBListeners(lbID, listeners)
+		assert.NoError(t, err)
+		assert.Equal(t, listeners, lb.Listeners)
+	})
+
+	t.Run("ConfigureLBAttributes", func(t *testing.T) {
+		attrs := LBAttributes{
+			HealthCheck:                &LBHealthCheck{Path: "/healthz", HealthyThreshold: 2},
+			DeregistrationDelaySeconds: 30,
+			StickySessions:             &LBStickySessions{CookieName: "SESSION"},
+		}
+		lb, err := client.ConfigureLBAttributes(lbID, attrs)
+		assert.NoError(t, err)
+		assert.Equal(t, attrs.HealthCheck, lb.HealthCheck)
+		assert.Equal(t, 30, lb.DeregistrationDelaySeconds)
+		assert.Equal(t, "SESSION", lb.StickySessions.CookieName)
+	})
 }
 
 func TestClientLoadBalancerErrors(t *testing.T) {
@@ -158,4 +267,13 @@ func TestClientLoadBalancerErrors(t *testing.T) {
 
 	_, err = client.ListLBTargets(lbID)
 	assert.Error(t, err)
+
+	_, err = client.ConfigureLBTLS(lbID, LBTLSConfig{Port: 443})
+	assert.Error(t, err)
+
+	_, err = client.ConfigureLBListeners(lbID, nil)
+	assert.Error(t, err)
+
+	_, err = client.ConfigureLBAttributes(lbID, LBAttributes{})
+	assert.Error(t, err)
 }
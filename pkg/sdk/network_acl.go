@@ -0,0 +1,86 @@
+// Package sdk provides the official Go SDK for the platform.
+package sdk
+
+import (
+	"fmt"
+	"time"
+)
+
+// NetworkACLRule is a numbered, stateless allow or deny entry of a network ACL.
+type NetworkACLRule struct {
+	ID         string    `json:"id,omitempty"`
+	ACLID      string    `json:"acl_id,omitempty"`
+	RuleNumber int       `json:"rule_number"`
+	Direction  string    `json:"direction"`
+	Protocol   string    `json:"protocol"`
+	PortMin    int       `json:"port_min,omitempty"`
+	PortMax    int       `json:"port_max,omitempty"`
+	CIDR       string    `json:"cidr"`
+	Action     string    `json:"action"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NetworkACL is a subnet-level stateless firewall.
+type NetworkACL struct {
+	ID        string           `json:"id"`
+	VPCID     string           `json:"vpc_id"`
+	Name      string           `json:"name"`
+	Rules     []NetworkACLRule `json:"rules"`
+	SubnetIDs []string         `json:"subnet_ids"`
+	ARN       string           `json:"arn"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// Network ACL rule actions.
+const (
+	NetworkACLAllow = "allow"
+	NetworkACLDeny  = "deny"
+)
+
+// CreateNetworkACL adds an empty network ACL to a VPC.
+func (c *Client) CreateNetworkACL(vpcID, name string) (*NetworkACL, error) {
+	var resp Response[*NetworkACL]
+	err := c.post(fmt.Sprintf("/vpcs/%s/network-acls", vpcID), map[string]string{"name": name}, &resp)
+	return resp.Data, err
+}
+
+// ListNetworkACLs returns a VPC's network ACLs.
+func (c *Client) ListNetworkACLs(vpcID string) ([]*NetworkACL, error) {
+	var resp Response[[]*NetworkACL]
+	err := c.get(fmt.Sprintf("/vpcs/%s/network-acls", vpcID), &resp)
+	return resp.Data, err
+}
+
+// GetNetworkACL retrieves a network ACL with its rules and subnets.
+func (c *Client) GetNetworkACL(id string) (*NetworkACL, error) {
+	var resp Response[*NetworkACL]
+	err := c.get(fmt.Sprintf("/network-acls/%s", id), &resp)
+	return resp.Data, err
+}
+
+// DeleteNetworkACL removes a network ACL no subnet uses.
+func (c *Client) DeleteNetworkACL(id string) error {
+	return c.delete(fmt.Sprintf("/network-acls/%s", id), nil)
+}
+
+// AddNetworkACLRule adds a numbered rule to a network ACL.
+func (c *Client) AddNetworkACLRule(aclID string, rule NetworkACLRule) (*NetworkACLRule, error) {
+	var resp Response[*NetworkACLRule]
+	err := c.post(fmt.Sprintf("/network-acls/%s/rules", aclID), rule, &resp)
+	return resp.Data, err
+}
+
+// RemoveNetworkACLRule deletes a rule from a network ACL.
+func (c *Client) RemoveNetworkACLRule(aclID, ruleID string) error {
+	return c.delete(fmt.Sprintf("/network-acls/%s/rules/%s", aclID, ruleID), nil)
+}
+
+// AssociateNetworkACL applies a network ACL to a subnet, replacing any previous one.
+func (c *Client) AssociateNetworkACL(aclID, subnetID string) error {
+	return c.post(fmt.Sprintf("/network-acls/%s/associations", aclID), map[string]string{"subnet_id": subnetID}, nil)
+}
+
+// DisassociateNetworkACL stops filtering a subnet with its network ACL.
+func (c *Client) DisassociateNetworkACL(subnetID string) error {
+	return c.delete(fmt.Sprintf("/subnets/%s/network-acl", subnetID), nil)
+}
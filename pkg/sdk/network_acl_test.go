@@ -0,0 +1,69 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/poyrazk/thecloud/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientNetworkACLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(contentType, testutil.TestContentTypeAppJSON)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/vpcs/vpc-1/network-acls":
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(Response[*NetworkACL]{Data: &NetworkACL{ID: "acl-1", Name: "web"}})
+		case r.Method == http.MethodGet && r.URL.Path == "/vpcs/vpc-1/network-acls":
+			_ = json.NewEncoder(w).Encode(Response[[]*NetworkACL]{Data: []*NetworkACL{{ID: "acl-1", SubnetIDs: []string{"sub-1"}}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/network-acls/acl-1/rules":
+			var req NetworkACLRule
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			assert.Equal(t, 100, req.RuleNumber)
+			assert.Equal(t, NetworkACLDeny, req.Action)
+			req.ID = "rule-1"
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(Response[*NetworkACLRule]{Data: &req})
+		case r.Method == http.MethodPost && r.URL.Path == "/network-acls/acl-1/associations":
+			var req map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			assert.Equal(t, "sub-1", req["subnet_id"])
+			_ = json.NewEncoder(w).Encode(Response[map[string]string]{Data: map[string]string{"message": "subnet associated"}})
+		case r.Method == http.MethodDelete && r.URL.Path == "/network-acls/acl-1/rules/rule-1",
+			r.Method == http.MethodDelete && r.URL.Path == "/subnets/sub-1/network-acl",
+			r.Method == http.MethodDelete && r.URL.Path == "/network-acls/acl-1":
+			_ = json.NewEncoder(w).Encode(Response[map[string]string]{Data: map[string]string{"message": "ok"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, testAPIKey)
+
+	acl, err := client.CreateNetworkACL("vpc-1", "web")
+	require.NoError(t, err)
+	assert.Equal(t, "acl-1", acl.ID)
+
+	acls, err := client.ListNetworkACLs("vpc-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sub-1"}, acls[0].SubnetIDs)
+
+	rule, err := client.AddNetworkACLRule("acl-1", NetworkACLRule{
+		RuleNumber: 100, Direction: "ingress", Protocol: "tcp", PortMin: 22, PortMax: 22, CIDR: "0.0.0.0/0", Action: NetworkACLDeny,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "rule-1", rule.ID)
+
+	require.NoError(t, client.AssociateNetworkACL("acl-1", "sub-1"))
+	require.NoError(t, client.RemoveNetworkACLRule("acl-1", "rule-1"))
+	require.NoError(t, client.DisassociateNetworkACL("sub-1"))
+	require.NoError(t, client.DeleteNetworkACL("acl-1"))
+
+	_, err = client.GetNetworkACL("missing")
+	assert.Error(t, err)
+}
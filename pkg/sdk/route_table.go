@@ -0,0 +1,96 @@
+// Package sdk provides the official Go SDK for the platform.
+package sdk
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Route sends traffic for a destination range to a target.
+type Route struct {
+	ID              string    `json:"id"`
+	RouteTableID    string    `json:"route_table_id"`
+	DestinationCIDR string    `json:"destination_cidr"`
+	TargetType      string    `json:"target_type"`
+	TargetID        string    `json:"target_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// RouteTable holds the routes applied to traffic leaving its associated subnets.
+type RouteTable struct {
+	ID        string    `json:"id"`
+	VPCID     string    `json:"vpc_id"`
+	Name      string    `json:"name"`
+	Main      bool      `json:"main"`
+	Routes    []Route   `json:"routes"`
+	SubnetIDs []string  `json:"subnet_ids"`
+	ARN       string    `json:"arn"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Route target types accepted by AddRoute.
+const (
+	RouteTargetInternetGateway = "internet-gateway"
+	RouteTargetNATGateway      = "nat-gateway"
+)
+
+// CreateRouteTable adds a custom route table to a VPC.
+func (c *Client) CreateRouteTable(vpcID, name string) (*RouteTable, error) {
+	var resp Response[*RouteTable]
+	err := c.post(fmt.Sprintf("/vpcs/%s/route-tables", vpcID), map[string]string{"name": name}, &resp)
+	return resp.Data, err
+}
+
+// ListRouteTables returns a VPC's route tables, main table first.
+func (c *Client) ListRouteTables(vpcID string) ([]*RouteTable, error) {
+	var resp Response[[]*RouteTable]
+	err := c.get(fmt.Sprintf("/vpcs/%s/route-tables", vpcID), &resp)
+	return resp.Data, err
+}
+
+// GetRouteTable retrieves a single route table.
+func (c *Client) GetRouteTable(id string) (*RouteTable, error) {
+	var resp Response[*RouteTable]
+	err := c.get(fmt.Sprintf("/route-tables/%s", id), &resp)
+	return resp.Data, err
+}
+
+// DeleteRouteTable removes a custom route table no subnet uses.
+func (c *Client) DeleteRouteTable(id string) error {
+	return c.delete(fmt.Sprintf("/route-tables/%s", id), nil)
+}
+
+// AddRoute sends traffic for a destination range to an internet or NAT gateway.
+func (c *Client) AddRoute(routeTableID, destinationCIDR, targetType, targetID string) (*Route, error) {
+	var resp Response[*Route]
+	body := map[string]string{
+		"destination_cidr": destinationCIDR,
+		"target_type":      targetType,
+		"target_id":        targetID,
+	}
+	err := c.post(fmt.Sprintf("/route-tables/%s/routes", routeTableID), body, &resp)
+	return resp.Data, err
+}
+
+// RemoveRoute deletes the route for a destination range.
+func (c *Client) RemoveRoute(routeTableID, destinationCIDR string) error {
+	return c.delete(fmt.Sprintf("/route-tables/%s/routes?destination=%s", routeTableID, url.QueryEscape(destinationCIDR)), nil)
+}
+
+// AssociateRouteTable applies a route table to a subnet.
+func (c *Client) AssociateRouteTable(routeTableID, subnetID string) error {
+	return c.post(fmt.Sprintf("/route-tables/%s/associations", routeTableID), map[string]string{"subnet_id": subnetID}, nil)
+}
+
+// GetSubnetRouteTable returns the route table in effect for a subnet.
+func (c *Client) GetSubnetRouteTable(subnetID string) (*RouteTable, error) {
+	var resp Response[*RouteTable]
+	err := c.get(fmt.Sprintf("/subnets/%s/route-table", subnetID), &resp)
+	return resp.Data, err
+}
+
+// DisassociateRouteTable returns a subnet to its VPC's main route table.
+func (c *Client) DisassociateRouteTable(subnetID string) error {
+	return c.delete(fmt.Sprintf("/subnets/%s/route-table", subnetID), nil)
+}
@@ -0,0 +1,69 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/poyrazk/thecloud/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRouteTables(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(contentType, testutil.TestContentTypeAppJSON)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/vpcs/vpc-1/route-tables":
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(Response[*RouteTable]{Data: &RouteTable{ID: "rt-1", Name: "private"}})
+		case r.Method == http.MethodGet && r.URL.Path == "/vpcs/vpc-1/route-tables":
+			_ = json.NewEncoder(w).Encode(Response[[]*RouteTable]{Data: []*RouteTable{{ID: "rt-0", Main: true}, {ID: "rt-1"}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/route-tables/rt-1/routes":
+			var req map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			assert.Equal(t, "0.0.0.0/0", req["destination_cidr"])
+			assert.Equal(t, RouteTargetNATGateway, req["target_type"])
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(Response[*Route]{Data: &Route{DestinationCIDR: "0.0.0.0/0", TargetType: RouteTargetNATGateway, TargetID: req["target_id"]}})
+		case r.Method == http.MethodDelete && r.URL.Path == "/route-tables/rt-1/routes":
+			assert.Equal(t, "0.0.0.0/0", r.URL.Query().Get("destination"))
+			_ = json.NewEncoder(w).Encode(Response[map[string]string]{Data: map[string]string{"message": "route removed"}})
+		case r.Method == http.MethodPost && r.URL.Path == "/route-tables/rt-1/associations":
+			_ = json.NewEncoder(w).Encode(Response[map[string]string]{Data: map[string]string{"message": "subnet associated"}})
+		case r.Method == http.MethodGet && r.URL.Path == "/subnets/sub-1/route-table":
+			_ = json.NewEncoder(w).Encode(Response[*RouteTable]{Data: &RouteTable{ID: "rt-1"}})
+		case r.Method == http.MethodDelete && r.URL.Path == "/subnets/sub-1/route-table",
+			r.Method == http.MethodDelete && r.URL.Path == "/route-tables/rt-1":
+			_ = json.NewEncoder(w).Encode(Response[map[string]string]{Data: map[string]string{"message": "ok"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, testAPIKey)
+
+	rt, err := client.CreateRouteTable("vpc-1", "private")
+	require.NoError(t, err)
+	assert.Equal(t, "rt-1", rt.ID)
+
+	tables, err := client.ListRouteTables("vpc-1")
+	require.NoError(t, err)
+	assert.True(t, tables[0].Main)
+
+	route, err := client.AddRoute("rt-1", "0.0.0.0/0", RouteTargetNATGateway, "nat-1")
+	require.NoError(t, err)
+	assert.Equal(t, "nat-1", route.TargetID)
+
+	require.NoError(t, client.RemoveRoute("rt-1", "0.0.0.0/0"))
+	require.NoError(t, client.AssociateRouteTable("rt-1", "sub-1"))
+
+	rt, err = client.GetSubnetRouteTable("sub-1")
+	require.NoError(t, err)
+	assert.Equal(t, "rt-1", rt.ID)
+
+	require.NoError(t, client.DisassociateRouteTable("sub-1"))
+	require.NoError(t, client.DeleteRouteTable("rt-1"))
+}
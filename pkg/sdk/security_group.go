@@ -17,15 +17,17 @@ type SecurityGroup struct {
 	CreatedAt   time.Time      `json:"created_at"`
 }
 
-// SecurityRule describes an ingress or egress rule.
+// SecurityRule describes an ingress or egress rule. The peer is either CIDR or the
+// instances of the security group named by SourceGroupID.
 type SecurityRule struct {
-	ID        string `json:"id"`
-	Direction string `json:"direction"`
-	Protocol  string `json:"protocol"`
-	PortMin   int    `json:"port_min"`
-	PortMax   int    `json:"port_max"`
-	CIDR      string `json:"cidr"`
-	Priority  int    `json:"priority"`
+	ID            string `json:"id"`
+	Direction     string `json:"direction"`
+	Protocol      string `json:"protocol"`
+	PortMin       int    `json:"port_min"`
+	PortMax       int    `json:"port_max"`
+	CIDR          string `json:"cidr"`
+	SourceGroupID string `json:"source_group_id,omitempty"`
+	Priority      int    `json:"priority"`
 }
 
 func (c *Client) CreateSecurityGroup(vpcID, name, description string) (*SecurityGroup, error) {
@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -36,9 +38,14 @@ func TestClientListObjects(t *testing.T) {
 		assert.Equal(t, storagePathPrefix+bucket, r.URL.Path)
 		assert.Equal(t, http.MethodGet, r.Method)
 
+		// Serve one object per page to exercise pagination.
+		page := ObjectList{Bucket: bucket, Objects: expectedObjects[:1], IsTruncated: true, NextContinuationToken: "page-2"}
+		if r.URL.Query().Get("continuation_token") == "page-2" {
+			page = ObjectList{Bucket: bucket, Objects: expectedObjects[1:]}
+		}
+
 		w.Header().Set(storageContentType, storageApplicationJSON)
-		resp := Response[[]Object]{Data: expectedObjects}
-		_ = json.NewEncoder(w).Encode(resp)
+		_ = json.NewEncoder(w).Encode(Response[ObjectList]{Data: page})
 	}))
 	defer server.Close()
 
@@ -48,6 +55,37 @@ func TestClientListObjects(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, objects, 2)
 	assert.Equal(t, expectedObjects[0].Key, objects[0].Key)
+	assert.Equal(t, expectedObjects[1].Key, objects[1].Key)
+}
+
+func TestClientListObjectsPage(t *testing.T) {
+	bucket := storageTestBucket
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "photos/", q.Get("prefix"))
+		assert.Equal(t, "/", q.Get("delimiter"))
+		assert.Equal(t, "photos/a", q.Get("start_after"))
+		assert.Equal(t, "tok", q.Get("continuation_token"))
+		assert.Equal(t, "5", q.Get("max_keys"))
+
+		w.Header().Set(storageContentType, storageApplicationJSON)
+		page := ObjectList{Bucket: bucket, CommonPrefixes: []string{"photos/2024/"}, KeyCount: 1}
+		_ = json.NewEncoder(w).Encode(Response[ObjectList]{Data: page})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	page, err := client.ListObjectsPage(bucket, ListObjectsOptions{
+		Prefix:            "photos/",
+		Delimiter:         "/",
+		StartAfter:        "photos/a",
+		ContinuationToken: "tok",
+		MaxKeys:           5,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"photos/2024/"}, page.CommonPrefixes)
 }
 
 func TestClientUploadObject(t *testing.T) {
@@ -311,6 +349,35 @@ func TestClientSetBucketVersioning(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestClientSetBucketStorageClass(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, storageBucketsPath+storageBucketName+"/storage-class", r.URL.Path)
+		assert.Equal(t, http.MethodPatch, r.Method)
+
+		var payload struct {
+			StorageClass string `json:"storage_class"`
+			DataShards   int    `json:"data_shards"`
+			ParityShards int    `json:"parity_shards"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		assert.NoError(t, err)
+		assert.Equal(t, StorageClassErasureCoded, payload.StorageClass)
+		assert.Equal(t, 4, payload.DataShards)
+		assert.Equal(t, 2, payload.ParityShards)
+
+		w.Header().Set(storageContentType, storageApplicationJSON)
+		_ = json.NewEncoder(w).Encode(Response[Bucket]{Data: Bucket{Name: storageBucketName, StorageClass: payload.StorageClass, DataShards: 4, ParityShards: 2}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	bucket, err := client.SetBucketStorageClass(storageBucketName, StorageClassErasureCoded, 4, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, StorageClassErasureCoded, bucket.StorageClass)
+	assert.Equal(t, 4, bucket.DataShards)
+}
+
 func TestClientGetStorageClusterStatus(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/storage/cluster/status", r.URL.Path)
@@ -401,6 +468,51 @@ func TestClientLifecycleRules(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestClientPutAndPreviewLifecycleRule(t *testing.T) {
+	bucket := storageTestBucket
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(storageContentType, storageApplicationJSON)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == storageBucketsPath+bucket+"/lifecycle":
+			var payload LifecycleRule
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			assert.Equal(t, 3, payload.NoncurrentVersionsToKeep)
+			assert.Equal(t, 30, payload.TransitionDays)
+			assert.Equal(t, StorageClassErasureCoded, payload.TransitionStorageClass)
+			assert.Equal(t, "dev", payload.Tags["env"])
+			payload.ID = storageRuleID
+			_ = json.NewEncoder(w).Encode(Response[LifecycleRule]{Data: payload})
+		case r.Method == http.MethodGet && r.URL.Path == storageBucketsPath+bucket+"/lifecycle/"+storageRuleID+"/preview":
+			_ = json.NewEncoder(w).Encode(Response[LifecycleReport]{Data: LifecycleReport{
+				RuleID:         storageRuleID,
+				Actions:        []LifecycleAction{{Type: "EXPIRE", Key: "logs/a", SizeBytes: 42}},
+				ObjectsExpired: 1,
+				BytesExpired:   42,
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	rule, err := client.PutLifecycleRule(bucket, LifecycleRule{
+		Tags:                     map[string]string{"env": "dev"},
+		NoncurrentVersionsToKeep: 3,
+		TransitionDays:           30,
+		TransitionStorageClass:   StorageClassErasureCoded,
+		Enabled:                  true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, storageRuleID, rule.ID)
+
+	report, err := client.PreviewLifecycleRule(bucket, storageRuleID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.ObjectsExpired)
+	assert.Equal(t, int64(42), report.BytesExpired)
+	assert.Len(t, report.Actions, 1)
+}
+
 func TestClientStorageListErrors(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -442,3 +554,343 @@ func TestClientStorageCreateErrors(t *testing.T) {
 	_, err = client.CreateLifecycleRule("bucket", "logs/", 7, true)
 	assert.Error(t, err)
 }
+
+func TestClientDownloadObjectRange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, storagePathPrefix+storageTestBucket+"/"+storageTestKey, r.URL.Path)
+		assert.Equal(t, "bytes=2-5", r.Header.Get("Range"))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte("llo "))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	rc, err := client.DownloadObjectRange(storageTestBucket, storageTestKey, 2, 5)
+	require.NoError(t, err)
+	defer func() { _ = rc.Close() }()
+
+	body, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "llo ", string(body))
+}
+
+func TestClientDownloadObjectRangeNotSatisfiable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bytes=100-", r.Header.Get("Range"))
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	_, err := client.DownloadObjectRange(storageTestBucket, storageTestKey, 100, -1)
+	assert.Error(t, err)
+}
+
+func TestClientHeadObject(t *testing.T) {
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+		w.Header().Set("ETag", `"abc123"`)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Content-Length", "42")
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	head, err := client.HeadObject(storageTestBucket, storageTestKey)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", head.ETag)
+	assert.Equal(t, "text/plain", head.ContentType)
+	assert.Equal(t, int64(42), head.ContentLength)
+	assert.True(t, modified.Equal(head.LastModified))
+}
+
+func TestClientBucketPolicy(t *testing.T) {
+	bucket := storageTestBucket
+	statements := []BucketPolicyStatement{{
+		Effect:    "Allow",
+		Action:    []string{"storage:GetObject"},
+		Resource:  []string{"public/*"},
+		Principal: []string{"anonymous"},
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == storageBucketsPath+bucket+"/policy":
+			var payload struct {
+				Statements []BucketPolicyStatement `json:"statements"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			assert.Equal(t, statements, payload.Statements)
+
+			w.Header().Set(storageContentType, storageApplicationJSON)
+			_ = json.NewEncoder(w).Encode(Response[BucketPolicy]{Data: BucketPolicy{Bucket: bucket, Statements: payload.Statements}})
+		case r.Method == http.MethodGet && r.URL.Path == storageBucketsPath+bucket+"/policy":
+			w.Header().Set(storageContentType, storageApplicationJSON)
+			_ = json.NewEncoder(w).Encode(Response[BucketPolicy]{Data: BucketPolicy{Bucket: bucket, Statements: statements}})
+		case r.Method == http.MethodDelete && r.URL.Path == storageBucketsPath+bucket+"/policy":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	put, err := client.PutBucketPolicy(bucket, statements)
+	require.NoError(t, err)
+	assert.Equal(t, bucket, put.Bucket)
+
+	policy, err := client.GetBucketPolicy(bucket)
+	require.NoError(t, err)
+	assert.Equal(t, statements, policy.Statements)
+
+	assert.NoError(t, client.DeleteBucketPolicy(bucket))
+}
+
+func TestClientBucketNotifications(t *testing.T) {
+	bucket := storageTestBucket
+	rules := []BucketNotificationRule{{
+		Events:     []string{StorageEventObjectCreated},
+		Suffix:     ".csv",
+		TargetType: NotificationTargetQueue,
+		TargetID:   "7f1c1c8e-0f7e-4a4e-9f59-3d2f8d7b1a10",
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == storageBucketsPath+bucket+"/notifications":
+			var payload struct {
+				Rules []BucketNotificationRule `json:"rules"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			assert.Equal(t, rules, payload.Rules)
+
+			saved := payload.Rules
+			saved[0].ID = storageRuleID
+			w.Header().Set(storageContentType, storageApplicationJSON)
+			_ = json.NewEncoder(w).Encode(Response[BucketNotificationConfig]{Data: BucketNotificationConfig{Bucket: bucket, Rules: saved}})
+		case r.Method == http.MethodGet && r.URL.Path == storageBucketsPath+bucket+"/notifications":
+			w.Header().Set(storageContentType, storageApplicationJSON)
+			_ = json.NewEncoder(w).Encode(Response[BucketNotificationConfig]{Data: BucketNotificationConfig{Bucket: bucket, Rules: rules}})
+		case r.Method == http.MethodDelete && r.URL.Path == storageBucketsPath+bucket+"/notifications":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	put, err := client.PutBucketNotifications(bucket, rules)
+	require.NoError(t, err)
+	assert.Equal(t, storageRuleID, put.Rules[0].ID)
+
+	cfg, err := client.GetBucketNotifications(bucket)
+	require.NoError(t, err)
+	assert.Equal(t, rules, cfg.Rules)
+
+	assert.NoError(t, client.DeleteBucketNotifications(bucket))
+}
+
+func TestClientBucketReplication(t *testing.T) {
+	bucket := storageTestBucket
+	rules := []ReplicationRule{{
+		Prefix:           "raw/",
+		Destination:      ReplicationDestination{Endpoint: "https://dr.example.com", Bucket: "backup", APIKey: "secret"},
+		ReplicateDeletes: true,
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(storageContentType, storageApplicationJSON)
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == storageBucketsPath+bucket+"/replication":
+			var payload struct {
+				Rules []ReplicationRule `json:"rules"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			assert.Equal(t, rules, payload.Rules)
+
+			saved := payload.Rules
+			saved[0].ID = storageRuleID
+			saved[0].Destination.APIKey = ""
+			_ = json.NewEncoder(w).Encode(Response[BucketReplicationConfig]{Data: BucketReplicationConfig{Bucket: bucket, Rules: saved}})
+		case r.Method == http.MethodGet && r.URL.Path == storageBucketsPath+bucket+"/replication/status":
+			_ = json.NewEncoder(w).Encode(Response[ReplicationBacklog]{Data: ReplicationBacklog{Bucket: bucket, Pending: 2, LagSeconds: 30}})
+		case r.Method == http.MethodGet && r.URL.Path == "/storage/replication/"+bucket+"/"+storageTestKey:
+			_ = json.NewEncoder(w).Encode(Response[[]ObjectReplication]{Data: []ObjectReplication{
+				{Bucket: bucket, Key: storageTestKey, RuleID: storageRuleID, Operation: "PUT", Status: ReplicationCompleted},
+			}})
+		case r.Method == http.MethodDelete && r.URL.Path == storageBucketsPath+bucket+"/replication":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	put, err := client.PutBucketReplication(bucket, rules)
+	require.NoError(t, err)
+	assert.Equal(t, storageRuleID, put.Rules[0].ID)
+	assert.Empty(t, put.Rules[0].Destination.APIKey)
+
+	backlog, err := client.GetReplicationBacklog(bucket)
+	require.NoError(t, err)
+	assert.Equal(t, 2, backlog.Pending)
+
+	reps, err := client.GetObjectReplication(bucket, storageTestKey)
+	require.NoError(t, err)
+	assert.Len(t, reps, 1)
+	assert.Equal(t, ReplicationCompleted, reps[0].Status)
+
+	assert.NoError(t, client.DeleteBucketReplication(bucket))
+}
+
+func TestClientSetObjectACL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/storage/acl/"+storageTestBucket+"/"+storageTestKey, r.URL.Path)
+
+		var payload map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, ObjectACLPublicRead, payload["acl"])
+		assert.NotContains(t, payload, "version_id")
+
+		w.Header().Set(storageContentType, storageApplicationJSON)
+		_ = json.NewEncoder(w).Encode(Response[map[string]string]{Data: map[string]string{"acl": payload["acl"]}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	assert.NoError(t, client.SetObjectACL(storageTestBucket, storageTestKey, ObjectACLPublicRead, ""))
+}
+
+func TestClientSetObjectTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/storage/tags/"+storageTestBucket+"/"+storageTestKey, r.URL.Path)
+
+		var payload struct {
+			Tags      map[string]string `json:"tags"`
+			VersionID string            `json:"version_id"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "dev", payload.Tags["env"])
+		assert.Equal(t, "v1", payload.VersionID)
+
+		w.Header().Set(storageContentType, storageApplicationJSON)
+		_ = json.NewEncoder(w).Encode(Response[map[string]interface{}]{Data: map[string]interface{}{"tags": payload.Tags}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	assert.NoError(t, client.SetObjectTags(storageTestBucket, storageTestKey, map[string]string{"env": "dev"}, "v1"))
+}
+
+func TestClientObjectLock(t *testing.T) {
+	until := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == storageBucketsPath+storageTestBucket+"/object-lock":
+			var cfg ObjectLockConfig
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&cfg))
+			assert.Equal(t, ObjectLockConfig{Enabled: true, DefaultMode: RetentionCompliance, DefaultDays: 30}, cfg)
+
+			w.Header().Set(storageContentType, storageApplicationJSON)
+			_ = json.NewEncoder(w).Encode(Response[Bucket]{Data: Bucket{
+				Name: storageTestBucket, ObjectLockEnabled: true, DefaultRetentionMode: cfg.DefaultMode, DefaultRetentionDays: cfg.DefaultDays,
+			}})
+		case r.Method == http.MethodPut && r.URL.Path == "/storage/retention/"+storageTestBucket+"/"+storageTestKey:
+			assert.Equal(t, "true", r.Header.Get("X-Bypass-Governance-Retention"))
+			var payload struct {
+				Mode        string     `json:"mode"`
+				RetainUntil *time.Time `json:"retain_until"`
+				VersionID   string     `json:"version_id"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			assert.Equal(t, RetentionGovernance, payload.Mode)
+			assert.True(t, until.Equal(*payload.RetainUntil))
+			assert.Equal(t, "v1", payload.VersionID)
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPut && r.URL.Path == "/storage/legal-hold/"+storageTestBucket+"/"+storageTestKey:
+			var payload map[string]any
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			assert.Equal(t, true, payload["legal_hold"])
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodDelete && r.URL.Path == "/storage/"+storageTestBucket+"/"+storageTestKey:
+			assert.Equal(t, "true", r.Header.Get("X-Bypass-Governance-Retention"))
+			assert.Equal(t, "v1", r.URL.Query().Get("versionId"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	bucket, err := client.SetBucketObjectLock(storageTestBucket, ObjectLockConfig{Enabled: true, DefaultMode: RetentionCompliance, DefaultDays: 30})
+	require.NoError(t, err)
+	assert.True(t, bucket.ObjectLockEnabled)
+	assert.Equal(t, 30, bucket.DefaultRetentionDays)
+
+	assert.NoError(t, client.PutObjectRetention(storageTestBucket, storageTestKey, RetentionGovernance, &until, "v1", true))
+	assert.NoError(t, client.PutObjectLegalHold(storageTestBucket, storageTestKey, true, ""))
+	assert.NoError(t, client.DeleteObjectVersionBypassingGovernance(storageTestBucket, storageTestKey, "v1"))
+}
+
+func TestClientBucketEncryption(t *testing.T) {
+	bucket := storageTestBucket
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(storageContentType, storageApplicationJSON)
+		var payload map[string]bool
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == storageBucketsPath+bucket+"/encryption":
+			_ = json.NewEncoder(w).Encode(Response[Bucket]{Data: Bucket{Name: bucket, EncryptionEnabled: payload["enabled"], EncryptionKeyID: "k1"}})
+		case r.Method == http.MethodPost && r.URL.Path == storageBucketsPath+bucket+"/encryption/rotate":
+			assert.True(t, payload["reencrypt"])
+			_ = json.NewEncoder(w).Encode(Response[Bucket]{Data: Bucket{Name: bucket, EncryptionEnabled: true, EncryptionKeyID: "k2"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	b, err := client.SetBucketEncryption(bucket, true)
+	require.NoError(t, err)
+	assert.True(t, b.EncryptionEnabled)
+
+	b, err = client.RotateBucketKey(bucket, true)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", b.EncryptionKeyID)
+}
+
+func TestClientObjectCustomerKey(t *testing.T) {
+	customerKey := []byte(strings.Repeat("c", 32))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, err := base64.StdEncoding.DecodeString(r.Header.Get("X-Server-Side-Encryption-Customer-Key"))
+		if err != nil || string(key) != string(customerKey) || r.Header.Get("X-Server-Side-Encryption-Customer-Key-MD5") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Method == http.MethodPut {
+			w.Header().Set(storageContentType, storageApplicationJSON)
+			_ = json.NewEncoder(w).Encode(Response[Object]{Data: Object{Key: storageTestKey, Encryption: "SSE-C"}})
+			return
+		}
+		_, _ = w.Write([]byte("secret"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, storageAPIKey)
+	obj, err := client.UploadObjectWithCustomerKey(storageTestBucket, storageTestKey, strings.NewReader("secret"), customerKey)
+	require.NoError(t, err)
+	assert.Equal(t, "SSE-C", obj.Encryption)
+
+	body, err := client.DownloadObjectWithCustomerKey(storageTestBucket, storageTestKey, customerKey)
+	require.NoError(t, err)
+	defer func() { _ = body.Close() }()
+	data, _ := io.ReadAll(body)
+	assert.Equal(t, "secret", string(data))
+}
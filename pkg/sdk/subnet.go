@@ -8,14 +8,15 @@ import (
 
 // Subnet describes a VPC subnet.
 type Subnet struct {
-	ID        string    `json:"id"`
-	VpcID     string    `json:"vpc_id"`
-	Name      string    `json:"name"`
-	CIDRBlock string    `json:"cidr_block"`
-	AZ        string    `json:"availability_zone"`
-	GatewayIP string    `json:"gateway_ip"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            string    `json:"id"`
+	VpcID         string    `json:"vpc_id"`
+	Name          string    `json:"name"`
+	CIDRBlock     string    `json:"cidr_block"`
+	IPv6CIDRBlock string    `json:"ipv6_cidr_block,omitempty"`
+	AZ            string    `json:"availability_zone"`
+	GatewayIP     string    `json:"gateway_ip"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 func (c *Client) ListSubnets(vpcID string) ([]*Subnet, error) {
@@ -25,12 +26,21 @@ func (c *Client) ListSubnets(vpcID string) ([]*Subnet, error) {
 }
 
 func (c *Client) CreateSubnet(vpcID, name, cidr, az string) (*Subnet, error) {
+	return c.CreateDualStackSubnet(vpcID, name, cidr, "", az)
+}
+
+// CreateDualStackSubnet creates a subnet with an IPv6 /64 taken from the VPC's IPv6
+// range. An empty ipv6CIDR creates an IPv4-only subnet.
+func (c *Client) CreateDualStackSubnet(vpcID, name, cidr, ipv6CIDR, az string) (*Subnet, error) {
 	var resp Response[*Subnet]
 	body := map[string]string{
 		"name":              name,
 		"cidr_block":        cidr,
 		"availability_zone": az,
 	}
+	if ipv6CIDR != "" {
+		body["ipv6_cidr_block"] = ipv6CIDR
+	}
 	err := c.post(fmt.Sprintf("/vpcs/%s/subnets", vpcID), body, &resp)
 	return resp.Data, err
 }
@@ -8,14 +8,15 @@ import (
 
 // VPC describes a virtual private cloud.
 type VPC struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	CIDRBlock string    `json:"cidr_block"`
-	NetworkID string    `json:"network_id"`
-	VXLANID   int       `json:"vxlan_id"`
-	Status    string    `json:"status"`
-	ARN       string    `json:"arn"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	CIDRBlock     string    `json:"cidr_block"`
+	IPv6CIDRBlock string    `json:"ipv6_cidr_block,omitempty"`
+	NetworkID     string    `json:"network_id"`
+	VXLANID       int       `json:"vxlan_id"`
+	Status        string    `json:"status"`
+	ARN           string    `json:"arn"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 func (c *Client) ListVPCs() ([]VPC, error) {
@@ -27,10 +28,19 @@ func (c *Client) ListVPCs() ([]VPC, error) {
 }
 
 func (c *Client) CreateVPC(name, cidrBlock string) (*VPC, error) {
+	return c.CreateDualStackVPC(name, cidrBlock, "")
+}
+
+// CreateDualStackVPC creates a VPC with an IPv6 range alongside its IPv4 one.
+// An empty ipv6CIDRBlock creates an IPv4-only VPC.
+func (c *Client) CreateDualStackVPC(name, cidrBlock, ipv6CIDRBlock string) (*VPC, error) {
 	body := map[string]string{
 		"name":       name,
 		"cidr_block": cidrBlock,
 	}
+	if ipv6CIDRBlock != "" {
+		body["ipv6_cidr_block"] = ipv6CIDRBlock
+	}
 	var res Response[VPC]
 	if err := c.post("/vpcs", body, &res); err != nil {
 		return nil, err
@@ -0,0 +1,95 @@
+// Package sdk provides the official Go SDK for the platform.
+package sdk
+
+import (
+	"fmt"
+	"time"
+)
+
+// InternetGateway connects a VPC to the internet.
+type InternetGateway struct {
+	ID        string    `json:"id"`
+	VPCID     string    `json:"vpc_id,omitempty"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	ARN       string    `json:"arn"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NATGateway gives private subnets outbound-only internet access.
+type NATGateway struct {
+	ID          string    `json:"id"`
+	VPCID       string    `json:"vpc_id"`
+	SubnetID    string    `json:"subnet_id"`
+	ElasticIPID string    `json:"elastic_ip_id"`
+	Name        string    `json:"name"`
+	PublicIP    string    `json:"public_ip"`
+	PrivateIP   string    `json:"private_ip"`
+	Status      string    `json:"status"`
+	ARN         string    `json:"arn"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CreateInternetGateway creates a detached internet gateway.
+func (c *Client) CreateInternetGateway(name string) (*InternetGateway, error) {
+	var resp Response[*InternetGateway]
+	err := c.post("/internet-gateways", map[string]string{"name": name}, &resp)
+	return resp.Data, err
+}
+
+// ListInternetGateways returns the caller's internet gateways.
+func (c *Client) ListInternetGateways() ([]*InternetGateway, error) {
+	var resp Response[[]*InternetGateway]
+	err := c.get("/internet-gateways", &resp)
+	return resp.Data, err
+}
+
+// AttachInternetGateway connects an internet gateway to a VPC.
+func (c *Client) AttachInternetGateway(id, vpcID string) (*InternetGateway, error) {
+	var resp Response[*InternetGateway]
+	err := c.post(fmt.Sprintf("/internet-gateways/%s/attach", id), map[string]string{"vpc_id": vpcID}, &resp)
+	return resp.Data, err
+}
+
+// DetachInternetGateway disconnects an internet gateway from its VPC.
+func (c *Client) DetachInternetGateway(id string) (*InternetGateway, error) {
+	var resp Response[*InternetGateway]
+	err := c.post(fmt.Sprintf("/internet-gateways/%s/detach", id), nil, &resp)
+	return resp.Data, err
+}
+
+// DeleteInternetGateway removes a detached internet gateway.
+func (c *Client) DeleteInternetGateway(id string) error {
+	return c.delete(fmt.Sprintf("/internet-gateways/%s", id), nil)
+}
+
+// CreateNATGateway launches a NAT gateway in a public subnet behind an Elastic IP.
+func (c *Client) CreateNATGateway(name, subnetID, elasticIPID string) (*NATGateway, error) {
+	var resp Response[*NATGateway]
+	body := map[string]string{
+		"name":          name,
+		"subnet_id":     subnetID,
+		"elastic_ip_id": elasticIPID,
+	}
+	err := c.post("/nat-gateways", body, &resp)
+	return resp.Data, err
+}
+
+// ListNATGateways returns the caller's NAT gateways.
+func (c *Client) ListNATGateways() ([]*NATGateway, error) {
+	var resp Response[[]*NATGateway]
+	err := c.get("/nat-gateways", &resp)
+	return resp.Data, err
+}
+
+// GetNATGateway retrieves a single NAT gateway.
+func (c *Client) GetNATGateway(id string) (*NATGateway, error) {
+	var resp Response[*NATGateway]
+	err := c.get(fmt.Sprintf("/nat-gateways/%s", id), &resp)
+	return resp.Data, err
+}
+
+// DeleteNATGateway tears down a NAT gateway and frees its Elastic IP.
+func (c *Client) DeleteNATGateway(id string) error {
+	return c.delete(fmt.Sprintf("/nat-gateways/%s", id), nil)
+}
@@ -0,0 +1,60 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/poyrazk/thecloud/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientVPCGateways(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(contentType, testutil.TestContentTypeAppJSON)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/internet-gateways":
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(Response[*InternetGateway]{Data: &InternetGateway{ID: "igw-1", Status: "detached"}})
+		case r.Method == http.MethodPost && r.URL.Path == "/internet-gateways/igw-1/attach":
+			var req map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			_ = json.NewEncoder(w).Encode(Response[*InternetGateway]{Data: &InternetGateway{ID: "igw-1", VPCID: req["vpc_id"], Status: "attached"}})
+		case r.Method == http.MethodPost && r.URL.Path == "/nat-gateways":
+			var req map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			assert.Equal(t, "eip-1", req["elastic_ip_id"])
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(Response[*NATGateway]{Data: &NATGateway{ID: "nat-1", SubnetID: req["subnet_id"], Status: "available"}})
+		case r.Method == http.MethodGet && r.URL.Path == "/nat-gateways":
+			_ = json.NewEncoder(w).Encode(Response[[]*NATGateway]{Data: []*NATGateway{{ID: "nat-1"}}})
+		case r.Method == http.MethodDelete && r.URL.Path == "/nat-gateways/nat-1":
+			_ = json.NewEncoder(w).Encode(Response[map[string]string]{Data: map[string]string{"message": "nat gateway deleted"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, testAPIKey)
+
+	igw, err := client.CreateInternetGateway("igw")
+	require.NoError(t, err)
+	assert.Equal(t, "detached", igw.Status)
+
+	igw, err = client.AttachInternetGateway("igw-1", "vpc-1")
+	require.NoError(t, err)
+	assert.Equal(t, "vpc-1", igw.VPCID)
+
+	nat, err := client.CreateNATGateway("egress", "sub-1", "eip-1")
+	require.NoError(t, err)
+	assert.Equal(t, "sub-1", nat.SubnetID)
+
+	list, err := client.ListNATGateways()
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, client.DeleteNATGateway("nat-1"))
+}
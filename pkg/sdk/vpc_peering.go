@@ -0,0 +1,64 @@
+// Package sdk provides the official Go SDK for the platform.
+package sdk
+
+import (
+	"fmt"
+	"time"
+)
+
+// VPCPeering describes a peering connection between two VPCs.
+type VPCPeering struct {
+	ID                string    `json:"id"`
+	RequesterVPCID    string    `json:"requester_vpc_id"`
+	AccepterVPCID     string    `json:"accepter_vpc_id"`
+	RequesterTenantID string    `json:"requester_tenant_id"`
+	AccepterTenantID  string    `json:"accepter_tenant_id"`
+	RequesterCIDR     string    `json:"requester_cidr"`
+	AccepterCIDR      string    `json:"accepter_cidr"`
+	Status            string    `json:"status"`
+	ARN               string    `json:"arn"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// CreateVPCPeering requests a peering from one of the caller's VPCs to another VPC. A VPC
+// of another tenant must be named together with that tenant's ID; pass "" for a VPC of the
+// caller's own tenant.
+func (c *Client) CreateVPCPeering(requesterVPCID, accepterVPCID, accepterTenantID string) (*VPCPeering, error) {
+	var resp Response[*VPCPeering]
+	body := map[string]string{
+		"requester_vpc_id": requesterVPCID,
+		"accepter_vpc_id":  accepterVPCID,
+	}
+	if accepterTenantID != "" {
+		body["accepter_tenant_id"] = accepterTenantID
+	}
+	err := c.post("/vpc-peerings", body, &resp)
+	return resp.Data, err
+}
+
+// AcceptVPCPeering accepts a pending peering addressed to one of the caller's VPCs.
+func (c *Client) AcceptVPCPeering(id string) (*VPCPeering, error) {
+	var resp Response[*VPCPeering]
+	err := c.post(fmt.Sprintf("/vpc-peerings/%s/accept", id), nil, &resp)
+	return resp.Data, err
+}
+
+// ListVPCPeerings returns the peerings on either side of the caller's VPCs.
+func (c *Client) ListVPCPeerings() ([]*VPCPeering, error) {
+	var resp Response[[]*VPCPeering]
+	err := c.get("/vpc-peerings", &resp)
+	return resp.Data, err
+}
+
+// GetVPCPeering retrieves a single peering.
+func (c *Client) GetVPCPeering(id string) (*VPCPeering, error) {
+	var resp Response[*VPCPeering]
+	err := c.get(fmt.Sprintf("/vpc-peerings/%s", id), &resp)
+	return resp.Data, err
+}
+
+// DeleteVPCPeering disconnects two peered VPCs or rejects a pending request.
+func (c *Client) DeleteVPCPeering(id string) error {
+	return c.delete(fmt.Sprintf("/vpc-peerings/%s", id), nil)
+}
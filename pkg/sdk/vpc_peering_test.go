@@ -0,0 +1,53 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/poyrazk/thecloud/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientVPCPeering(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(contentType, testutil.TestContentTypeAppJSON)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/vpc-peerings":
+			var req map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			assert.Equal(t, "vpc-a", req["requester_vpc_id"])
+			assert.Equal(t, "vpc-b", req["accepter_vpc_id"])
+			assert.Equal(t, "tenant-b", req["accepter_tenant_id"])
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(Response[*VPCPeering]{Data: &VPCPeering{ID: "pcx-1", Status: "pending-acceptance"}})
+		case r.Method == http.MethodPost && r.URL.Path == "/vpc-peerings/pcx-1/accept":
+			_ = json.NewEncoder(w).Encode(Response[*VPCPeering]{Data: &VPCPeering{ID: "pcx-1", Status: "active"}})
+		case r.Method == http.MethodGet && r.URL.Path == "/vpc-peerings":
+			_ = json.NewEncoder(w).Encode(Response[[]*VPCPeering]{Data: []*VPCPeering{{ID: "pcx-1"}}})
+		case r.Method == http.MethodDelete && r.URL.Path == "/vpc-peerings/pcx-1":
+			_ = json.NewEncoder(w).Encode(Response[map[string]string]{Data: map[string]string{"message": "vpc peering deleted"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, testAPIKey)
+
+	p, err := client.CreateVPCPeering("vpc-a", "vpc-b", "tenant-b")
+	require.NoError(t, err)
+	assert.Equal(t, "pending-acceptance", p.Status)
+
+	p, err = client.AcceptVPCPeering("pcx-1")
+	require.NoError(t, err)
+	assert.Equal(t, "active", p.Status)
+
+	list, err := client.ListVPCPeerings()
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	assert.NoError(t, client.DeleteVPCPeering("pcx-1"))
+}
@@ -65,6 +65,25 @@ func TestClientCreateVPC(t *testing.T) {
 	assert.Equal(t, testVpcName, vpc.Name)
 }
 
+func TestClientCreateDualStackVPC(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, "fd00:10::/56", body["ipv6_cidr_block"])
+
+		w.Header().Set(contentType, testutil.TestContentTypeAppJSON)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(Response[VPC]{Data: VPC{ID: "vpc-1", IPv6CIDRBlock: body["ipv6_cidr_block"]}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, testAPIKey)
+	vpc, err := client.CreateDualStackVPC(testVpcName, testutil.TestCIDR, "fd00:10::/56")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "fd00:10::/56", vpc.IPv6CIDRBlock)
+}
+
 func TestClientGetVPC(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/vpcs/vpc-1", r.URL.Path)